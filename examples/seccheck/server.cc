@@ -168,6 +168,20 @@ const std::vector<Callback> dispatchers = [] {
       unpackSyscall<::gvisor::syscall::Listen>;
   result[::gvisor::common::MESSAGE_SYSCALL_PTRACE] =
       unpackSyscall<::gvisor::syscall::Ptrace>;
+  result[::gvisor::common::MESSAGE_SYSCALL_RENAME] =
+      unpackSyscall<::gvisor::syscall::Rename>;
+  result[::gvisor::common::MESSAGE_SYSCALL_LINK] =
+      unpackSyscall<::gvisor::syscall::Link>;
+  result[::gvisor::common::MESSAGE_SYSCALL_CHMOD] =
+      unpackSyscall<::gvisor::syscall::Chmod>;
+  result[::gvisor::common::MESSAGE_SYSCALL_CHOWN] =
+      unpackSyscall<::gvisor::syscall::Chown>;
+  result[::gvisor::common::MESSAGE_SYSCALL_MOUNT] =
+      unpackSyscall<::gvisor::syscall::Mount>;
+  result[::gvisor::common::MESSAGE_SYSCALL_UMOUNT] =
+      unpackSyscall<::gvisor::syscall::Umount>;
+  result[::gvisor::common::MESSAGE_SENTRY_PACKET_DROP] =
+      unpack<::gvisor::sentry::PacketDropInfo>;
   return result;
 }();
 // LINT.ThenChange(../../pkg/sentry/seccheck/points/common.proto)
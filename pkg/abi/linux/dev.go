@@ -57,6 +57,10 @@ const (
 	// UNIX98_PTY_REPLICA_MAJOR is the initial major device number for
 	// Unix98 PTY replicas.
 	UNIX98_PTY_REPLICA_MAJOR = 136
+
+	// UNIX98_PTY_MAJOR_COUNT is the number of major device numbers used by
+	// each of Unix98 PTY masters and replicas.
+	UNIX98_PTY_MAJOR_COUNT = 8
 )
 
 // Minor device numbers for TTYAUX_MAJOR.
@@ -87,6 +87,13 @@ const (
 	// AT_HWCAP2 is an extension of AT_HWCAP.
 	AT_HWCAP2 = 26
 
+	// AT_RSEQ_FEATURE_SIZE is the size of the rseq fields supported by the
+	// kernel.
+	AT_RSEQ_FEATURE_SIZE = 27
+
+	// AT_RSEQ_ALIGN is the required alignment of the rseq area.
+	AT_RSEQ_ALIGN = 28
+
 	// AT_EXECFN is the path used to execute the program.
 	AT_EXECFN = 31
 
@@ -94,6 +101,14 @@ const (
 	AT_SYSINFO_EHDR = 33
 )
 
+// AT_VECTOR_SIZE is the maximum number of words in the auxiliary vector saved
+// for a process, including the terminating AT_NULL entry. This is the value
+// for x86-64 with IA-32 emulation, which is the largest of the supported
+// architectures.
+//
+// See include/linux/mm_types.h:AT_VECTOR_SIZE.
+const AT_VECTOR_SIZE = 2 * (3 + 22 + 1)
+
 // ELF ET_CORE and ptrace GETREGSET/SETREGSET register set types.
 //
 // See include/uapi/linux/elf.h.
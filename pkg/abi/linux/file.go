@@ -43,6 +43,29 @@ const (
 	O_TMPFILE  = 020000000 // __O_TMPFILE in Linux
 )
 
+// Flags for OpenHow.Resolve, from include/uapi/linux/openat2.h.
+const (
+	RESOLVE_NO_XDEV       = 0x01
+	RESOLVE_NO_MAGICLINKS = 0x02
+	RESOLVE_NO_SYMLINKS   = 0x04
+	RESOLVE_BENEATH       = 0x08
+	RESOLVE_IN_ROOT       = 0x10
+	RESOLVE_CACHED        = 0x20
+
+	// Sizeof first published struct open_how.
+	OPEN_HOW_SIZE_VER0 = 24
+)
+
+// OpenHow is struct open_how, from include/uapi/linux/openat2.h.
+//
+// +marshal
+type OpenHow struct {
+	_       structs.HostLayout
+	Flags   uint64
+	Mode    uint64
+	Resolve uint64
+}
+
 // Constants for file mode (struct file::f_mode in Linux).
 const (
 	// FMODE_READ indicates the file is open for reading.
@@ -149,8 +172,22 @@ const (
 	MOUNT_ATTR_IDMAP       = 0x00100000
 	MOUNT_ATTR_NOSYMFOLLOW = 0x00200000
 	AT_RECURSIVE           = 0x8000
+
+	// Sizeof first published struct mount_attr.
+	MOUNT_ATTR_SIZE_VER0 = 32
 )
 
+// MountAttr is struct mount_attr, from include/uapi/linux/mount.h.
+//
+// +marshal
+type MountAttr struct {
+	_           structs.HostLayout
+	AttrSet     uint64
+	AttrClr     uint64
+	Propagation uint64
+	UsernsFD    uint64
+}
+
 // Constants for open_tree(2).
 const (
 	OPEN_TREE_CLONE     = (1 << 0)
@@ -325,22 +362,24 @@ const (
 
 // Mask values for statx.
 const (
-	STATX_TYPE        = 0x00000001
-	STATX_MODE        = 0x00000002
-	STATX_NLINK       = 0x00000004
-	STATX_UID         = 0x00000008
-	STATX_GID         = 0x00000010
-	STATX_ATIME       = 0x00000020
-	STATX_MTIME       = 0x00000040
-	STATX_CTIME       = 0x00000080
-	STATX_INO         = 0x00000100
-	STATX_SIZE        = 0x00000200
-	STATX_BLOCKS      = 0x00000400
-	STATX_BASIC_STATS = 0x000007ff
-	STATX_BTIME       = 0x00000800
-	STATX_MNT_ID      = 0x00001000
-	STATX_ALL         = 0x00000fff
-	STATX__RESERVED   = 0x80000000
+	STATX_TYPE          = 0x00000001
+	STATX_MODE          = 0x00000002
+	STATX_NLINK         = 0x00000004
+	STATX_UID           = 0x00000008
+	STATX_GID           = 0x00000010
+	STATX_ATIME         = 0x00000020
+	STATX_MTIME         = 0x00000040
+	STATX_CTIME         = 0x00000080
+	STATX_INO           = 0x00000100
+	STATX_SIZE          = 0x00000200
+	STATX_BLOCKS        = 0x00000400
+	STATX_BASIC_STATS   = 0x000007ff
+	STATX_BTIME         = 0x00000800
+	STATX_MNT_ID        = 0x00001000
+	STATX_DIOALIGN      = 0x00002000
+	STATX_MNT_ID_UNIQUE = 0x00004000
+	STATX_ALL           = 0x00000fff
+	STATX__RESERVED     = 0x80000000
 )
 
 // Bitmasks for Statx.Attributes and Statx.AttributesMask, from
@@ -380,12 +419,14 @@ type Statx struct {
 	DevMajor       uint32
 	DevMinor       uint32
 	MntID          uint64
+	DioMemAlign    uint32
+	DioOffsetAlign uint32
 }
 
 // String implements fmt.Stringer.String.
 func (s *Statx) String() string {
-	return fmt.Sprintf("Statx{Mask: %#x, Mode: %s, UID: %d, GID: %d, Ino: %d, DevMajor: %d, DevMinor: %d, Size: %d, Blocks: %d, Blksize: %d, Nlink: %d, Atime: %s, Btime: %s, Ctime: %s, Mtime: %s, Attributes: %d, AttributesMask: %d, RdevMajor: %d, RdevMinor: %d, MntId: %d}",
-		s.Mask, FileMode(s.Mode), s.UID, s.GID, s.Ino, s.DevMajor, s.DevMinor, s.Size, s.Blocks, s.Blksize, s.Nlink, s.Atime.ToTime(), s.Btime.ToTime(), s.Ctime.ToTime(), s.Mtime.ToTime(), s.Attributes, s.AttributesMask, s.RdevMajor, s.RdevMinor, s.MntID)
+	return fmt.Sprintf("Statx{Mask: %#x, Mode: %s, UID: %d, GID: %d, Ino: %d, DevMajor: %d, DevMinor: %d, Size: %d, Blocks: %d, Blksize: %d, Nlink: %d, Atime: %s, Btime: %s, Ctime: %s, Mtime: %s, Attributes: %d, AttributesMask: %d, RdevMajor: %d, RdevMinor: %d, MntId: %d, DioMemAlign: %d, DioOffsetAlign: %d}",
+		s.Mask, FileMode(s.Mode), s.UID, s.GID, s.Ino, s.DevMajor, s.DevMinor, s.Size, s.Blocks, s.Blksize, s.Nlink, s.Atime.ToTime(), s.Btime.ToTime(), s.Ctime.ToTime(), s.Mtime.ToTime(), s.Attributes, s.AttributesMask, s.RdevMajor, s.RdevMinor, s.MntID, s.DioMemAlign, s.DioOffsetAlign)
 }
 
 // SizeOfStatx is the size of a Statx struct.
@@ -502,10 +543,15 @@ const (
 	F_ADD_SEALS           = F_LINUX_SPECIFIC_BASE + 9
 	F_GET_SEALS           = F_LINUX_SPECIFIC_BASE + 10
 
-	F_SEAL_SEAL   = 0x0001 // Prevent further seals from being set.
-	F_SEAL_SHRINK = 0x0002 // Prevent file from shrinking.
-	F_SEAL_GROW   = 0x0004 // Prevent file from growing.
-	F_SEAL_WRITE  = 0x0008 // Prevent writes.
+	F_SEAL_SEAL         = 0x0001 // Prevent further seals from being set.
+	F_SEAL_SHRINK       = 0x0002 // Prevent file from shrinking.
+	F_SEAL_GROW         = 0x0004 // Prevent file from growing.
+	F_SEAL_WRITE        = 0x0008 // Prevent writes.
+	F_SEAL_FUTURE_WRITE = 0x0010 // Prevent future writes while mapped.
+	F_SEAL_EXEC         = 0x0020 // Prevent chmod modifying exec bits.
+
+	// F_ALL_SEALS is the set of all seals. Source: mm/memfd.c
+	F_ALL_SEALS = F_SEAL_SEAL | F_SEAL_SHRINK | F_SEAL_GROW | F_SEAL_WRITE | F_SEAL_FUTURE_WRITE | F_SEAL_EXEC
 )
 
 // Constants related to fallocate(2). Source: include/uapi/linux/falloc.h
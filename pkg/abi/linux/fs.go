@@ -31,6 +31,7 @@ const (
 	DEVPTS_SUPER_MAGIC    = 0x00001cd1
 	EXT_SUPER_MAGIC       = 0xef53
 	FUSE_SUPER_MAGIC      = 0x65735546
+	HUGETLBFS_MAGIC       = 0x958458f6
 	MQUEUE_MAGIC          = 0x19800202
 	NSFS_MAGIC            = 0x6e736673
 	OVERLAYFS_SUPER_MAGIC = 0x794c7630
@@ -156,6 +156,12 @@ func IOC_SIZE(nr uint32) uint32 {
 	return (nr >> IOC_SIZESHIFT) & ((1 << IOC_SIZEBITS) - 1)
 }
 
+// IOC_DIR outputs the result of IOC_DIR macro in
+// include/uapi/asm-generic/ioctl.h.
+func IOC_DIR(nr uint32) uint32 {
+	return (nr >> IOC_DIRSHIFT) & ((1 << IOC_DIRBITS) - 1)
+}
+
 // TCFLSH queue selector arguments.
 const (
 	TCIFLUSH  = 0
@@ -16,9 +16,18 @@ package linux
 
 // ioctl(2) request numbers from linux/if_tun.h
 var (
-	TUNSETIFF     = IOW('T', 202, 4)
-	TUNSETPERSIST = IOW('T', 203, 4)
-	TUNGETIFF     = IOR('T', 210, 4)
+	TUNSETIFF       = IOW('T', 202, 4)
+	TUNSETPERSIST   = IOW('T', 203, 4)
+	TUNGETFEATURES  = IOR('T', 207, 4)
+	TUNGETIFF       = IOR('T', 210, 4)
+	TUNGETVNETHDRSZ = IOR('T', 215, 4)
+	TUNSETVNETHDRSZ = IOW('T', 216, 4)
+
+	// struct sock_fprog is 16 bytes on all supported architectures.
+	TUNATTACHFILTER    = IOW('T', 213, 16)
+	TUNDETACHFILTER    = IOW('T', 214, 16)
+	TUNSETSTEERINGEBPF = IOR('T', 224, 4)
+	TUNSETFILTEREBPF   = IOR('T', 225, 4)
 )
 
 // Flags from net/if_tun.h
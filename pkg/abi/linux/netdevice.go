@@ -101,13 +101,131 @@ var SizeOfIFConf = (*IFConf)(nil).SizeBytes()
 // +marshal
 type EthtoolCmd uint32
 
+// SIOCETHTOOL commands, from <linux/ethtool.h>.
 const (
+	// ETHTOOL_GSET is the command to SIOCETHTOOL to query link settings.
+	ETHTOOL_GSET EthtoolCmd = 0x1
+
+	// ETHTOOL_GDRVINFO is the command to SIOCETHTOOL to query driver
+	// information.
+	ETHTOOL_GDRVINFO EthtoolCmd = 0x3
+
+	// ETHTOOL_GLINK is the command to SIOCETHTOOL to query the link status.
+	ETHTOOL_GLINK EthtoolCmd = 0xa
+
+	// ETHTOOL_GRINGPARAM is the command to SIOCETHTOOL to query the ring
+	// sizes.
+	ETHTOOL_GRINGPARAM EthtoolCmd = 0x10
+
 	// ETHTOOL_GFEATURES is the command to SIOCETHTOOL to query device
 	// features.
-	// See: <linux/ethtool.h>
 	ETHTOOL_GFEATURES EthtoolCmd = 0x3a
 )
 
+// Link settings reported by ETHTOOL_GSET, from <linux/ethtool.h>.
+const (
+	SPEED_10000     = 10000
+	DUPLEX_FULL     = 0x1
+	PORT_OTHER      = 0xff
+	XCVR_INTERNAL   = 0x0
+	AUTONEG_DISABLE = 0x0
+)
+
+// Netdevice feature bits, from include/linux/netdev_features.h. Only the
+// bits that have kept their position across kernel versions are listed.
+const (
+	NETIF_F_SG_BIT          = 0
+	NETIF_F_IP_CSUM_BIT     = 1
+	NETIF_F_HW_CSUM_BIT     = 3
+	NETIF_F_IPV6_CSUM_BIT   = 4
+	NETIF_F_HIGHDMA_BIT     = 5
+	NETIF_F_FRAGLIST_BIT    = 6
+	NETIF_F_GSO_BIT         = 11
+	NETIF_F_LLTX_BIT        = 12
+	NETIF_F_NETNS_LOCAL_BIT = 13
+	NETIF_F_GRO_BIT         = 14
+	NETIF_F_TSO_BIT         = 16
+	NETIF_F_TSO6_BIT        = 20
+)
+
+// ETHTOOL_DEV_FEATURE_WORDS is the number of EthtoolGetFeaturesBlocks needed
+// to hold all netdevice features.
+const ETHTOOL_DEV_FEATURE_WORDS = 2
+
+// EthtoolSettings is the link settings structure returned by ETHTOOL_GSET,
+// struct ethtool_cmd in <linux/ethtool.h>.
+//
+// +marshal
+type EthtoolSettings struct {
+	_             structs.HostLayout
+	Cmd           uint32
+	Supported     uint32
+	Advertising   uint32
+	Speed         uint16
+	Duplex        uint8
+	Port          uint8
+	PhyAddress    uint8
+	Transceiver   uint8
+	Autoneg       uint8
+	MDIOSupport   uint8
+	MaxTxPkt      uint32
+	MaxRxPkt      uint32
+	SpeedHi       uint16
+	EthTpMDIX     uint8
+	EthTpMDIXCtrl uint8
+	LpAdvertising uint32
+	Reserved      [2]uint32
+}
+
+// EthtoolDrvInfo is the driver information returned by ETHTOOL_GDRVINFO.
+// See: <linux/ethtool.h>
+//
+// +marshal
+type EthtoolDrvInfo struct {
+	_           structs.HostLayout
+	Cmd         uint32
+	Driver      [32]byte
+	Version     [32]byte
+	FWVersion   [32]byte
+	BusInfo     [32]byte
+	EROMVersion [32]byte
+	Reserved2   [12]byte
+	NPrivFlags  uint32
+	NStats      uint32
+	TestInfoLen uint32
+	EEDumpLen   uint32
+	RegDumpLen  uint32
+}
+
+// EthtoolValue is used by SIOCETHTOOL commands that get or set a single
+// value, such as ETHTOOL_GLINK.
+// See: <linux/ethtool.h>
+//
+// +marshal
+type EthtoolValue struct {
+	_    structs.HostLayout
+	Cmd  uint32
+	Data uint32
+}
+
+// EthtoolRingParam is the ring size structure returned by
+// ETHTOOL_GRINGPARAM.
+// See: <linux/ethtool.h>
+//
+// +marshal
+type EthtoolRingParam struct {
+	_                 structs.HostLayout
+	Cmd               uint32
+	RxMaxPending      uint32
+	RxMiniMaxPending  uint32
+	RxJumboMaxPending uint32
+	TxMaxPending      uint32
+	RxPending         uint32
+	RxMiniPending     uint32
+	RxJumboPending    uint32
+	TxPending         uint32
+}
+
 // EthtoolGFeatures is used to return a list of device features.
 // See: <linux/ethtool.h>
 //
@@ -188,6 +188,40 @@ const (
 	VETH_INFO_PEER = 1
 )
 
+// VXLAN attributes, from uapi/linux/if_link.h.
+const (
+	IFLA_VXLAN_UNSPEC     = 0
+	IFLA_VXLAN_ID         = 1
+	IFLA_VXLAN_GROUP      = 2
+	IFLA_VXLAN_LINK       = 3
+	IFLA_VXLAN_LOCAL      = 4
+	IFLA_VXLAN_TTL        = 5
+	IFLA_VXLAN_TOS        = 6
+	IFLA_VXLAN_LEARNING   = 7
+	IFLA_VXLAN_AGEING     = 8
+	IFLA_VXLAN_LIMIT      = 9
+	IFLA_VXLAN_PORT_RANGE = 10
+	IFLA_VXLAN_PROXY      = 11
+	IFLA_VXLAN_RSC        = 12
+	IFLA_VXLAN_L2MISS     = 13
+	IFLA_VXLAN_L3MISS     = 14
+	IFLA_VXLAN_PORT       = 15
+	IFLA_VXLAN_GROUP6     = 16
+	IFLA_VXLAN_LOCAL6     = 17
+)
+
+// Geneve attributes, from uapi/linux/if_link.h.
+const (
+	IFLA_GENEVE_UNSPEC           = 0
+	IFLA_GENEVE_ID               = 1
+	IFLA_GENEVE_REMOTE           = 2
+	IFLA_GENEVE_TTL              = 3
+	IFLA_GENEVE_TOS              = 4
+	IFLA_GENEVE_PORT             = 5
+	IFLA_GENEVE_COLLECT_METADATA = 6
+	IFLA_GENEVE_REMOTE6          = 7
+)
+
 // InterfaceAddrMessage is struct ifaddrmsg, from uapi/linux/if_addr.h.
 //
 // +marshal
@@ -216,6 +250,49 @@ const (
 	IFA_FLAGS     = 8
 )
 
+// Interface address flags, from uapi/linux/if_addr.h.
+const (
+	IFA_F_SECONDARY      = 0x01
+	IFA_F_TEMPORARY      = IFA_F_SECONDARY
+	IFA_F_NODAD          = 0x02
+	IFA_F_OPTIMISTIC     = 0x04
+	IFA_F_DADFAILED      = 0x08
+	IFA_F_HOMEADDRESS    = 0x10
+	IFA_F_DEPRECATED     = 0x20
+	IFA_F_TENTATIVE      = 0x40
+	IFA_F_PERMANENT      = 0x80
+	IFA_F_MANAGETEMPADDR = 0x100
+	IFA_F_NOPREFIXROUTE  = 0x200
+	IFA_F_MCAUTOJOIN     = 0x400
+	IFA_F_STABLE_PRIVACY = 0x800
+)
+
+// INFINITY_LIFE_TIME is the lifetime of an address that never expires, from
+// include/net/addrconf.h.
+const INFINITY_LIFE_TIME = 0xFFFFFFFF
+
+// InterfaceAddrCacheInfo is struct ifa_cacheinfo, from uapi/linux/if_addr.h.
+//
+// +marshal
+type InterfaceAddrCacheInfo struct {
+	_ structs.HostLayout
+
+	// Preferred is the remaining preferred lifetime in seconds.
+	Preferred uint32
+
+	// Valid is the remaining valid lifetime in seconds.
+	Valid uint32
+
+	// CreatedStamp is the creation time in hundredths of seconds.
+	CreatedStamp uint32
+
+	// UpdatedStamp is the last update time in hundredths of seconds.
+	UpdatedStamp uint32
+}
+
+// SizeOfInterfaceAddrCacheInfo is the size of InterfaceAddrCacheInfo.
+const SizeOfInterfaceAddrCacheInfo = 16
+
 // Device types, from uapi/linux/if_arp.h.
 const (
 	ARPHRD_NONE     = 65534
@@ -0,0 +1,152 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linux
+
+import (
+	"structs"
+)
+
+// Event types for PerfEventAttr.Type, from include/uapi/linux/perf_event.h.
+const (
+	PERF_TYPE_HARDWARE   = 0
+	PERF_TYPE_SOFTWARE   = 1
+	PERF_TYPE_TRACEPOINT = 2
+	PERF_TYPE_HW_CACHE   = 3
+	PERF_TYPE_RAW        = 4
+	PERF_TYPE_BREAKPOINT = 5
+)
+
+// Software event IDs for PerfEventAttr.Config when PerfEventAttr.Type is
+// PERF_TYPE_SOFTWARE, from include/uapi/linux/perf_event.h.
+const (
+	PERF_COUNT_SW_CPU_CLOCK        = 0
+	PERF_COUNT_SW_TASK_CLOCK       = 1
+	PERF_COUNT_SW_PAGE_FAULTS      = 2
+	PERF_COUNT_SW_CONTEXT_SWITCHES = 3
+	PERF_COUNT_SW_CPU_MIGRATIONS   = 4
+	PERF_COUNT_SW_PAGE_FAULTS_MIN  = 5
+	PERF_COUNT_SW_PAGE_FAULTS_MAJ  = 6
+	PERF_COUNT_SW_ALIGNMENT_FAULTS = 7
+	PERF_COUNT_SW_EMULATION_FAULTS = 8
+	PERF_COUNT_SW_DUMMY            = 9
+	PERF_COUNT_SW_BPF_OUTPUT       = 10
+	PERF_COUNT_SW_CGROUP_SWITCHES  = 11
+)
+
+// Bits in PerfEventAttr.ReadFormat, from include/uapi/linux/perf_event.h.
+const (
+	PERF_FORMAT_TOTAL_TIME_ENABLED = 1 << 0
+	PERF_FORMAT_TOTAL_TIME_RUNNING = 1 << 1
+	PERF_FORMAT_ID                 = 1 << 2
+	PERF_FORMAT_GROUP              = 1 << 3
+	PERF_FORMAT_LOST               = 1 << 4
+	PERF_FORMAT_MAX                = 1 << 5
+)
+
+// Bits in PerfEventAttr.Flags. These correspond to the bitfields that follow
+// read_format in struct perf_event_attr.
+const (
+	PERF_ATTR_DISABLED       = 1 << 0
+	PERF_ATTR_INHERIT        = 1 << 1
+	PERF_ATTR_PINNED         = 1 << 2
+	PERF_ATTR_EXCLUSIVE      = 1 << 3
+	PERF_ATTR_EXCLUDE_USER   = 1 << 4
+	PERF_ATTR_EXCLUDE_KERNEL = 1 << 5
+	PERF_ATTR_EXCLUDE_HV     = 1 << 6
+	PERF_ATTR_EXCLUDE_IDLE   = 1 << 7
+	PERF_ATTR_MMAP           = 1 << 8
+	PERF_ATTR_COMM           = 1 << 9
+	PERF_ATTR_FREQ           = 1 << 10
+	PERF_ATTR_INHERIT_STAT   = 1 << 11
+	PERF_ATTR_ENABLE_ON_EXEC = 1 << 12
+	PERF_ATTR_TASK           = 1 << 13
+	PERF_ATTR_WATERMARK      = 1 << 14
+)
+
+// Flags for perf_event_open(2), from include/uapi/linux/perf_event.h.
+const (
+	PERF_FLAG_FD_NO_GROUP = 1 << 0
+	PERF_FLAG_FD_OUTPUT   = 1 << 1
+	PERF_FLAG_PID_CGROUP  = 1 << 2
+	PERF_FLAG_FD_CLOEXEC  = 1 << 3
+)
+
+// PERF_IOC_FLAG_GROUP is the argument to PERF_EVENT_IOC_ENABLE,
+// PERF_EVENT_IOC_DISABLE and PERF_EVENT_IOC_RESET that applies the operation
+// to all events in the group.
+const PERF_IOC_FLAG_GROUP = 1 << 0
+
+// ioctl(2) requests for perf event files, from
+// include/uapi/linux/perf_event.h.
+var (
+	PERF_EVENT_IOC_ENABLE            = IO('$', 0)
+	PERF_EVENT_IOC_DISABLE           = IO('$', 1)
+	PERF_EVENT_IOC_REFRESH           = IO('$', 2)
+	PERF_EVENT_IOC_RESET             = IO('$', 3)
+	PERF_EVENT_IOC_PERIOD            = IOW('$', 4, 8)
+	PERF_EVENT_IOC_SET_OUTPUT        = IO('$', 5)
+	PERF_EVENT_IOC_SET_FILTER        = IOW('$', 6, 8)
+	PERF_EVENT_IOC_ID                = IOR('$', 7, 8)
+	PERF_EVENT_IOC_SET_BPF           = IOW('$', 8, 4)
+	PERF_EVENT_IOC_PAUSE_OUTPUT      = IOW('$', 9, 4)
+	PERF_EVENT_IOC_QUERY_BPF         = IOWR('$', 10, 8)
+	PERF_EVENT_IOC_MODIFY_ATTRIBUTES = IOW('$', 11, 8)
+)
+
+// Sizes of versions of struct perf_event_attr.
+const (
+	PERF_ATTR_SIZE_VER0 = 64
+	PERF_ATTR_SIZE_VER8 = 136
+)
+
+// PerfEventAttr is equivalent to struct perf_event_attr, from
+// include/uapi/linux/perf_event.h, as of PERF_ATTR_SIZE_VER8.
+//
+// +marshal
+type PerfEventAttr struct {
+	_      structs.HostLayout
+	Type   uint32
+	Size   uint32
+	Config uint64
+
+	// SamplePeriod is sample_freq if Flags contains PERF_ATTR_FREQ.
+	SamplePeriod uint64
+	SampleType   uint64
+	ReadFormat   uint64
+	Flags        uint64
+
+	// WakeupEvents is wakeup_watermark if Flags contains
+	// PERF_ATTR_WATERMARK.
+	WakeupEvents uint32
+	BPType       uint32
+
+	// Config1 is also bp_addr, kprobe_func and uprobe_path.
+	Config1 uint64
+
+	// Config2 is also bp_len, kprobe_addr and probe_offset.
+	Config2          uint64
+	BranchSampleType uint64
+	SampleRegsUser   uint64
+	SampleStackUser  uint32
+	ClockID          int32
+	SampleRegsIntr   uint64
+	AuxWatermark     uint32
+	SampleMaxStack   uint16
+	_                uint16
+	AuxSampleSize    uint32
+	_                uint32
+	SigData          uint64
+	Config3          uint64
+}
@@ -192,6 +192,30 @@ const (
 	ARCH_SET_CPUID = 0x1012
 )
 
+// PrctlMMMap is equivalent to struct prctl_mm_map, from
+// include/uapi/linux/prctl.h, used by prctl(PR_SET_MM, PR_SET_MM_MAP).
+//
+// +marshal
+type PrctlMMMap struct {
+	StartCode  uint64
+	EndCode    uint64
+	StartData  uint64
+	EndData    uint64
+	StartBrk   uint64
+	Brk        uint64
+	StartStack uint64
+	ArgStart   uint64
+	ArgEnd     uint64
+	EnvStart   uint64
+	EnvEnd     uint64
+	Auxv       uint64
+	AuxvSize   uint32
+	ExeFD      uint32
+}
+
+// SizeOfPrctlMMMap is the size of a PrctlMMMap struct.
+var SizeOfPrctlMMMap = (*PrctlMMMap)(nil).SizeBytes()
+
 // Flags for prctl(PR_SET_DUMPABLE), defined in include/linux/sched/coredump.h.
 const (
 	SUID_DUMP_DISABLE = 0
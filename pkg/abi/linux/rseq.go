@@ -118,19 +118,43 @@ type RSeq struct {
 	// Flags are the critical section flags that apply to all critical
 	// sections on this thread, defined above.
 	Flags uint32
+
+	// NodeID contains the NUMA node ID of the current CPU if rseq is
+	// initialized.
+	//
+	// This field should only be read by the thread which registered this
+	// structure, and must be read atomically.
+	NodeID uint32
+
+	// MMCID contains the current thread's concurrency ID. Linux assigns
+	// concurrency IDs uniquely among the threads concurrently running in
+	// the same address space, within the range of possible CPUs.
+	//
+	// This field should only be read by the thread which registered this
+	// structure, and must be read atomically.
+	MMCID uint32
 }
 
 const (
-	// SizeOfRSeq is the size of RSeq.
+	// SizeOfRSeq is the size of RSeq as originally defined, which is also
+	// the minimum length that may be registered.
 	//
-	// Note that RSeq is naively 24 bytes. However, it has 32-byte
-	// alignment, which in C increases sizeof to 32. That is the size that
-	// the Linux kernel uses.
+	// Note that the original RSeq is naively 24 bytes. However, it has
+	// 32-byte alignment, which in C increases sizeof to 32. That is the size
+	// that the Linux kernel uses.
 	SizeOfRSeq = 32
 
+	// RSeqFeatureSize is the size of the fields of RSeq that the kernel
+	// supports, i.e. offsetof(struct rseq, end). It is reported to
+	// userspace in AT_RSEQ_FEATURE_SIZE.
+	RSeqFeatureSize = 28
+
 	// AlignOfRSeq is the standard alignment of RSeq.
 	AlignOfRSeq = 32
 
 	// OffsetOfRSeqCriticalSection is the offset of RSeqCriticalSection in RSeq.
 	OffsetOfRSeqCriticalSection = 8
+
+	// OffsetOfRSeqNodeID is the offset of NodeID in RSeq.
+	OffsetOfRSeqNodeID = 20
 )
@@ -0,0 +1,44 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sentry
+
+import "gvisor.dev/gvisor/pkg/abi/linux"
+
+// GuestAPIDevicePath is the path of the guest API device, through which
+// trusted agents in the sandbox request sandbox services.
+const GuestAPIDevicePath = "/dev/gvisor"
+
+// GuestAPIVersionLen is the size of the buffer filled by
+// GVISOR_IOC_VERSION. The version is NUL-terminated.
+const GuestAPIVersionLen = 64
+
+// Ioctls of the guest API device. All of them except GVISOR_IOC_VERSION
+// require CAP_SYS_ADMIN in the root user namespace of the sandbox.
+var (
+	// GVISOR_IOC_VERSION copies the version of the runtime to a
+	// GuestAPIVersionLen-byte buffer.
+	GVISOR_IOC_VERSION = linux.IOR('G', 0, GuestAPIVersionLen)
+
+	// GVISOR_IOC_FLUSH_CACHES returns clean page cache and free memory to
+	// the host.
+	GVISOR_IOC_FLUSH_CACHES = linux.IO('G', 1)
+
+	// GVISOR_IOC_READY signals that the container of the caller is ready.
+	GVISOR_IOC_READY = linux.IO('G', 2)
+
+	// GVISOR_IOC_CHECKPOINT requests a checkpoint of the sandbox, as writing
+	// to /proc/gvisor/checkpoint does.
+	GVISOR_IOC_CHECKPOINT = linux.IO('G', 3)
+)
@@ -0,0 +1,123 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compressio
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// codec compresses and decompresses individual chunks. Chunks are compressed
+// independently, so codecs must be safe for concurrent use by workers.
+type codec interface {
+	// compress writes the compressed contents of src to dst.
+	compress(dst io.Writer, src *bytes.Buffer) error
+
+	// decompress writes the decompressed contents of src to dst.
+	decompress(dst *bytes.Buffer, src *bytes.Buffer) error
+
+	// close releases resources held by the codec.
+	close()
+}
+
+// flateCodec is a codec using compress/flate.
+type flateCodec struct {
+	level int
+}
+
+// compress implements codec.compress.
+func (c flateCodec) compress(dst io.Writer, src *bytes.Buffer) error {
+	fw, err := flate.NewWriter(dst, c.level)
+	if err != nil {
+		return err
+	}
+	if _, err := io.CopyN(fw, src, int64(src.Len())); err != nil {
+		return err
+	}
+	return fw.Close()
+}
+
+// decompress implements codec.decompress.
+func (flateCodec) decompress(dst *bytes.Buffer, src *bytes.Buffer) error {
+	_, err := io.Copy(dst, flate.NewReader(src))
+	return err
+}
+
+// close implements codec.close.
+func (flateCodec) close() {}
+
+// MinZstdLevel and MaxZstdLevel bound the levels accepted by NewZstdWriter.
+const (
+	MinZstdLevel = 1
+	MaxZstdLevel = 22
+)
+
+// zstdCodec is a codec using zstd. Each chunk is a single zstd frame.
+type zstdCodec struct {
+	// Only one of enc and dec is set, depending on the direction of the
+	// stream.
+	enc *zstd.Encoder
+	dec *zstd.Decoder
+}
+
+func newZstdCodec(compress bool, level int) (*zstdCodec, error) {
+	if !compress {
+		dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(parallelism()))
+		if err != nil {
+			return nil, err
+		}
+		return &zstdCodec{dec: dec}, nil
+	}
+	if level < MinZstdLevel || level > MaxZstdLevel {
+		return nil, fmt.Errorf("invalid zstd compression level %d, must be between %d and %d", level, MinZstdLevel, MaxZstdLevel)
+	}
+	// EncodeAll and DecodeAll may be called concurrently, so workers can
+	// share the Encoder and Decoder.
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)), zstd.WithEncoderConcurrency(parallelism()))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdCodec{enc: enc}, nil
+}
+
+// compress implements codec.compress.
+func (c *zstdCodec) compress(dst io.Writer, src *bytes.Buffer) error {
+	_, err := dst.Write(c.enc.EncodeAll(src.Bytes(), nil))
+	return err
+}
+
+// decompress implements codec.decompress.
+func (c *zstdCodec) decompress(dst *bytes.Buffer, src *bytes.Buffer) error {
+	out, err := c.dec.DecodeAll(src.Bytes(), dst.AvailableBuffer())
+	if err != nil {
+		return err
+	}
+	_, err = dst.Write(out)
+	return err
+}
+
+// close implements codec.close.
+func (c *zstdCodec) close() {
+	if c.enc != nil {
+		c.enc.Close()
+	}
+	if c.dec != nil {
+		c.dec.Close()
+	}
+}
@@ -42,11 +42,14 @@
 //
 // so the stream integrity cannot be compromised by switching and mixing
 // compressed chunks.
+//
+// Chunks are compressed independently, using flate (NewWriter) or zstd
+// (NewZstdWriter). The stream does not record which was used, so it must be
+// read by the corresponding reader.
 package compressio
 
 import (
 	"bytes"
-	"compress/flate"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/binary"
@@ -55,6 +58,7 @@ import (
 	"io"
 	"runtime"
 
+	"gvisor.dev/gvisor/pkg/gomaxprocs"
 	"gvisor.dev/gvisor/pkg/sync"
 )
 
@@ -138,7 +142,7 @@ type worker struct {
 }
 
 // work is the main work routine; see worker.
-func (w *worker) work(compress bool, level int) {
+func (w *worker) work(compress bool, cd codec) {
 	defer close(w.output)
 
 	var h hash.Hash
@@ -154,18 +158,7 @@ func (w *worker) work(compress bool, level int) {
 			}
 
 			// Encode this slice.
-			fw, err := flate.NewWriter(mw, level)
-			if err != nil {
-				w.output <- result{c, err}
-				continue
-			}
-
-			// Encode the input.
-			if _, err := io.CopyN(fw, c.uncompressed, int64(c.uncompressed.Len())); err != nil {
-				w.output <- result{c, err}
-				continue
-			}
-			if err := fw.Close(); err != nil {
+			if err := cd.compress(mw, c.uncompressed); err != nil {
 				w.output <- result{c, err}
 				continue
 			}
@@ -194,10 +187,7 @@ func (w *worker) work(compress bool, level int) {
 			}
 
 			// Decode this slice.
-			fr := flate.NewReader(c.compressed)
-
-			// Decode the input.
-			if _, err := io.Copy(c.uncompressed, fr); err != nil {
+			if err := cd.decompress(c.uncompressed, c.compressed); err != nil {
 				w.output <- result{c, err}
 				continue
 			}
@@ -274,12 +264,30 @@ type pool struct {
 	// itself as worker refers to it and that would stop pool from being
 	// GCed.
 	hashPool *hashPool
+
+	// codec compresses and decompresses chunks.
+	codec codec
+
+	// maxprocs is the number of temporary GOMAXPROCS added for workers.
+	maxprocs int
+}
+
+// parallelism returns the number of chunks that may be compressed or
+// decompressed in parallel.
+//
+// This is independent of GOMAXPROCS, which may be limited to the number of
+// CPUs available to the application (e.g. in a sandbox), since the
+// application is not running while checkpoint images are being written or
+// read.
+func parallelism() int {
+	return runtime.NumCPU()
 }
 
 // init initializes the worker pool.
 //
 // This should only be called once.
-func (p *pool) init(key []byte, workers int, compress bool, level int) {
+func (p *pool) init(key []byte, workers int, compress bool, cd codec) {
+	p.codec = cd
 	if len(key) > 0 {
 		p.hashPool = &hashPool{key: key}
 	}
@@ -290,8 +298,11 @@ func (p *pool) init(key []byte, workers int, compress bool, level int) {
 			input:    make(chan *chunk, 1),
 			output:   make(chan result, 1),
 		}
-		go p.workers[i].work(compress, level) // S/R-SAFE: In save path only.
+		go p.workers[i].work(compress, cd) // S/R-SAFE: In save path only.
 	}
+	// Allow workers to run in parallel even if GOMAXPROCS is low.
+	p.maxprocs = parallelism()
+	gomaxprocs.Add(p.maxprocs)
 	runtime.SetFinalizer(p, (*pool).stop)
 }
 
@@ -312,6 +323,12 @@ func (p *pool) stop() {
 	}
 	p.workers = nil
 	p.hashPool = nil
+	gomaxprocs.Add(-p.maxprocs)
+	p.maxprocs = 0
+	if p.codec != nil {
+		p.codec.close()
+		p.codec = nil
+	}
 }
 
 // handleResult calls the callback.
@@ -377,17 +394,31 @@ type Reader struct {
 
 var _ io.Reader = (*Reader)(nil)
 
-// NewReader returns a new compressed reader. If key is non-nil, the data stream
-// is assumed to contain expected hash values, which will be compared against
-// hash values computed from the compressed bytes. See package comments for
-// details.
+// NewReader returns a new compressed reader for a stream written by NewWriter.
+// If key is non-nil, the data stream is assumed to contain expected hash
+// values, which will be compared against hash values computed from the
+// compressed bytes. See package comments for details.
 func NewReader(in io.ReadCloser, key []byte) (*Reader, error) {
+	return newReader(in, key, flateCodec{})
+}
+
+// NewZstdReader is equivalent to NewReader, but for a stream written by
+// NewZstdWriter.
+func NewZstdReader(in io.ReadCloser, key []byte) (*Reader, error) {
+	cd, err := newZstdCodec(false /* compress */, 0)
+	if err != nil {
+		return nil, err
+	}
+	return newReader(in, key, cd)
+}
+
+func newReader(in io.ReadCloser, key []byte, cd codec) (*Reader, error) {
 	r := &Reader{
 		in: in,
 	}
 
 	// Use double buffering for read.
-	r.init(key, 2*runtime.GOMAXPROCS(0), false, 0)
+	r.init(key, 2*parallelism(), false, cd)
 
 	if _, err := io.ReadFull(in, r.scratch[:4]); err != nil {
 		return nil, err
@@ -580,6 +611,9 @@ func (r *Reader) Read(p []byte) (int, error) {
 
 // Close implements io.Closer.Close.
 func (r *Reader) Close() error {
+	r.mu.Lock()
+	r.stop()
+	r.mu.Unlock()
 	return r.in.Close()
 }
 
@@ -606,8 +640,23 @@ var _ io.Writer = (*Writer)(nil)
 //
 // The recommended chunkSize is on the order of 1M. Extra memory may be
 // buffered (in the form of read-ahead, or buffered writes), and is limited to
-// O(chunkSize * [1+GOMAXPROCS]).
+// O(chunkSize * [1+NumCPU]).
 func NewWriter(out io.Writer, key []byte, chunkSize uint32, level int) (*Writer, error) {
+	return newWriter(out, key, chunkSize, flateCodec{level: level})
+}
+
+// NewZstdWriter is equivalent to NewWriter, but compresses chunks using zstd
+// at the given level. level uses the same scale as the zstd command line
+// tool (1 to 22); higher levels compress better but more slowly.
+func NewZstdWriter(out io.Writer, key []byte, chunkSize uint32, level int) (*Writer, error) {
+	cd, err := newZstdCodec(true /* compress */, level)
+	if err != nil {
+		return nil, err
+	}
+	return newWriter(out, key, chunkSize, cd)
+}
+
+func newWriter(out io.Writer, key []byte, chunkSize uint32, cd codec) (*Writer, error) {
 	w := &Writer{
 		pool: pool{
 			chunkSize: chunkSize,
@@ -615,7 +664,7 @@ func NewWriter(out io.Writer, key []byte, chunkSize uint32, level int) (*Writer,
 		},
 		out: out,
 	}
-	w.init(key, 1+runtime.GOMAXPROCS(0), true, level)
+	w.init(key, 1+parallelism(), true, cd)
 
 	binary.BigEndian.PutUint32(w.scratch[:], chunkSize)
 	if _, err := w.out.Write(w.scratch[:4]); err != nil {
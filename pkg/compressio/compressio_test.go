@@ -207,6 +207,68 @@ func TestCompress(t *testing.T) {
 	}
 }
 
+func TestCompressZstd(t *testing.T) {
+	data := initTest(t, 1024*1024)
+	for _, level := range []int{MinZstdLevel, 3, 11} {
+		for _, key := range [][]byte{nil, hashKey} {
+			for _, corruptData := range []bool{false, true} {
+				if key == nil && corruptData {
+					continue
+				}
+				doTest(t, testOpts{
+					Name: fmt.Sprintf("zstd level=%d, key=%s, corruptData=%v", level, string(key), corruptData),
+					Data: data,
+					NewWriter: func(b *bytes.Buffer) (io.WriteCloser, error) {
+						return NewZstdWriter(b, key, 16*1024, level)
+					},
+					NewReader: func(b *bytes.Buffer) (io.Reader, error) {
+						return NewZstdReader(io.NopCloser(b), key)
+					},
+					CorruptData: corruptData,
+				})
+			}
+		}
+	}
+
+	for _, level := range []int{MinZstdLevel - 1, MaxZstdLevel + 1} {
+		if _, err := NewZstdWriter(&bytes.Buffer{}, nil, 1024, level); err == nil {
+			t.Errorf("NewZstdWriter(level=%d) got nil error, want non-nil", level)
+		}
+	}
+}
+
+func TestReaderCloseBeforeEOF(t *testing.T) {
+	data := initTest(t, 1024*1024)
+	var compressed bytes.Buffer
+	w, err := NewWriter(&compressed, hashKey, 4*1024, flate.BestSpeed)
+	if err != nil {
+		t.Fatalf("NewWriter got err %v, expected nil", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("Write got err %v, expected nil", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close got err %v, expected nil", err)
+	}
+
+	// Closing a Reader with read-ahead in progress should stop its workers
+	// without blocking.
+	r, err := NewReader(io.NopCloser(&compressed), hashKey)
+	if err != nil {
+		t.Fatalf("NewReader got err %v, expected nil", err)
+	}
+	buf := make([]byte, 10*1024)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatalf("Read got err %v, expected nil", err)
+	}
+	if !bytes.Equal(buf, data[:len(buf)]) {
+		t.Errorf("Read got wrong data")
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("Close got err %v, expected nil", err)
+	}
+}
+
 const (
 	benchDataSize = 600 * 1024 * 1024
 )
@@ -0,0 +1,399 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package grpcserver serves the sandbox control methods over gRPC.
+//
+// Methods are those registered with the urpc control server. Their arguments
+// and results are protobuf messages generated from the urpc argument and
+// result types by package schema, which clients get with the Schema method.
+// See control.proto.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
+	pb "gvisor.dev/gvisor/pkg/control/grpcserver/control_go_proto"
+	"gvisor.dev/gvisor/pkg/control/grpcserver/schema"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sync"
+	"gvisor.dev/gvisor/pkg/urpc"
+)
+
+// chunkSize is the maximum size of a Chunk sent by CallStream.
+const chunkSize = 64 << 10
+
+// minWatchInterval is the minimum interval accepted by Watch.
+const minWatchInterval = 100 * time.Millisecond
+
+// Invoker invokes control methods in-process. It is implemented by
+// server.Server and urpc.Server.
+type Invoker interface {
+	// Methods returns the sorted names of all methods.
+	Methods() []string
+
+	// MethodTypes returns the argument and result types of a method.
+	MethodTypes(method string) (arg, result reflect.Type, ok bool)
+
+	// Invoke calls a method with the JSON encoding of its argument.
+	Invoke(method string, arg []byte, files []*os.File) ([]byte, []*os.File, error)
+}
+
+// Server is a gRPC control server.
+type Server struct {
+	pb.UnimplementedControlServer
+
+	invoker Invoker
+	grpc    *grpc.Server
+	lis     *connListener
+
+	// schemaMu protects the fields below.
+	schemaMu sync.Mutex
+
+	// schema holds the messages of schemaMethods. It is regenerated when
+	// the methods of invoker change.
+	schema        *schema.Schema
+	schemaMethods []string
+}
+
+// New returns a new Server that serves the methods of invoker. Connections are
+// passed to it with Handle.
+func New(invoker Invoker) *Server {
+	s := &Server{
+		invoker: invoker,
+		grpc:    grpc.NewServer(),
+		lis:     newConnListener(),
+	}
+	pb.RegisterControlServer(s.grpc, s)
+	go func() { // S/R-SAFE: does not impact state directly.
+		if err := s.grpc.Serve(s.lis); err != nil {
+			log.Warningf("control: gRPC server stopped: %v", err)
+		}
+	}()
+	return s
+}
+
+// Handle serves the gRPC client on conn. If authorize is not nil, each call is
+// first checked with it. It is suitable for server.Server.SetGRPCHandler.
+func (s *Server) Handle(conn net.Conn, authorize urpc.AuthorizeFunc) {
+	if authorize != nil {
+		conn = &authConn{Conn: conn, authorize: authorize}
+	}
+	if !s.lis.push(conn) {
+		conn.Close()
+	}
+}
+
+// Stop closes all connections and stops serving. It cancels in-flight calls.
+func (s *Server) Stop() {
+	s.grpc.Stop()
+}
+
+// Schema implements pb.ControlServer.Schema.
+func (s *Server) Schema(ctx context.Context, _ *pb.SchemaRequest) (*pb.SchemaResponse, error) {
+	sc, methods, err := s.getSchema()
+	if err != nil {
+		return nil, err
+	}
+	authorize := authorizerOf(ctx)
+	res := &pb.SchemaResponse{File: sc.File()}
+	for _, method := range methods {
+		if authorize != nil && authorize(method) != nil {
+			continue
+		}
+		arg, _ := sc.Arg(method)
+		result, _ := sc.Result(method)
+		res.Methods = append(res.Methods, &pb.MethodSchema{
+			Name:       method,
+			ArgType:    string(arg.FullName()),
+			ResultType: string(result.FullName()),
+		})
+	}
+	return res, nil
+}
+
+// Call implements pb.ControlServer.Call.
+func (s *Server) Call(ctx context.Context, req *pb.CallRequest) (*pb.CallResponse, error) {
+	res, err := s.invoke(ctx, req, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CallResponse{Result: res}, nil
+}
+
+// CallStream implements pb.ControlServer.CallStream.
+func (s *Server) CallStream(req *pb.CallRequest, stream pb.Control_CallStreamServer) error {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return status.Errorf(codes.Internal, "creating pipe: %v", err)
+	}
+	defer r.Close()
+
+	done := make(chan error, 1)
+	go func() { // S/R-SAFE: does not impact state directly.
+		// w is closed by invoke once the method returns.
+		_, err := s.invoke(stream.Context(), req, []*os.File{w})
+		done <- err
+	}()
+
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&pb.Chunk{Data: buf[:n]}); err != nil {
+				// Unblock the method by closing the read end.
+				r.Close()
+				<-done
+				return err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			r.Close()
+			<-done
+			return status.Errorf(codes.Internal, "reading output: %v", err)
+		}
+	}
+	return <-done
+}
+
+// Watch implements pb.ControlServer.Watch.
+func (s *Server) Watch(req *pb.WatchRequest, stream pb.Control_WatchServer) error {
+	if req.GetCall() == nil {
+		return status.Error(codes.InvalidArgument, "call must be set")
+	}
+	interval := time.Duration(req.GetIntervalNs())
+	if interval < minWatchInterval {
+		interval = minWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		res, err := s.invoke(stream.Context(), req.GetCall(), nil)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&pb.CallResponse{Result: res}); err != nil {
+			return err
+		}
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// getSchema returns the schema of the invoker's methods, along with their
+// names.
+func (s *Server) getSchema() (*schema.Schema, []string, error) {
+	methods := s.invoker.Methods()
+
+	s.schemaMu.Lock()
+	defer s.schemaMu.Unlock()
+	if s.schema != nil && slices.Equal(methods, s.schemaMethods) {
+		return s.schema, s.schemaMethods, nil
+	}
+	ms := make([]schema.Method, 0, len(methods))
+	for _, method := range methods {
+		arg, result, ok := s.invoker.MethodTypes(method)
+		if !ok {
+			return nil, nil, status.Errorf(codes.Internal, "no types for method %q", method)
+		}
+		ms = append(ms, schema.Method{Name: method, Arg: arg, Result: result})
+	}
+	sc, err := schema.New(ms)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "generating schema: %v", err)
+	}
+	s.schema = sc
+	s.schemaMethods = methods
+	return sc, methods, nil
+}
+
+// invoke calls the method described by req on behalf of the client of ctx and
+// converts errors to gRPC status errors. Files returned by the method cannot
+// be passed to gRPC clients, so they are closed. files are closed once the
+// method returns, or if it isn't called.
+func (s *Server) invoke(ctx context.Context, req *pb.CallRequest, files []*os.File) (*anypb.Any, error) {
+	invoked := false
+	defer func() {
+		if !invoked {
+			for _, f := range files {
+				f.Close()
+			}
+		}
+	}()
+
+	method := req.GetMethod()
+	if authorize := authorizerOf(ctx); authorize != nil {
+		if err := authorize(method); err != nil {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+	}
+	sc, _, err := s.getSchema()
+	if err != nil {
+		return nil, err
+	}
+	argType, ok := sc.Arg(method)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, urpc.ErrUnknownMethod.Error())
+	}
+	arg := dynamicpb.NewMessage(argType)
+	if a := req.GetArg(); a != nil {
+		if got := a.MessageName(); got != argType.FullName() {
+			return nil, status.Errorf(codes.InvalidArgument, "argument of %s must be a %s, got %s", method, argType.FullName(), got)
+		}
+		if err := proto.Unmarshal(a.GetValue(), arg); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "decoding argument: %v", err)
+		}
+	}
+	argJSON, err := sc.ToJSON(arg)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "encoding argument: %v", err)
+	}
+
+	invoked = true
+	resJSON, fs, err := s.invoker.Invoke(method, argJSON, files)
+	for _, f := range fs {
+		f.Close()
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resultType, _ := sc.Result(method)
+	res, err := sc.FromJSON(resJSON, resultType)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "decoding result: %v", err)
+	}
+	out, err := anypb.New(res)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "encoding result: %v", err)
+	}
+	return out, nil
+}
+
+// authorizerOf returns the AuthorizeFunc of the client of ctx, if any.
+func authorizerOf(ctx context.Context) urpc.AuthorizeFunc {
+	if p, ok := peer.FromContext(ctx); ok {
+		if a, ok := p.Addr.(*authAddr); ok {
+			return a.authorize
+		}
+	}
+	return nil
+}
+
+// toStatus converts an error returned by Invoker.Invoke to a gRPC status.
+func toStatus(err error) error {
+	var re urpc.RemoteError
+	switch {
+	case errors.Is(err, urpc.ErrUnknownMethod):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, urpc.ErrStopped):
+		return status.Error(codes.Unavailable, fmt.Sprintf("control server %v", err))
+	case errors.As(err, &re):
+		return status.Error(codes.Unknown, re.Message)
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// authConn is a connection whose calls are checked by authorize. gRPC exposes
+// the connection's remote address to handlers, so it carries authorize.
+type authConn struct {
+	net.Conn
+	authorize urpc.AuthorizeFunc
+}
+
+// RemoteAddr implements net.Conn.RemoteAddr.
+func (c *authConn) RemoteAddr() net.Addr {
+	return &authAddr{authorize: c.authorize}
+}
+
+// authAddr is the remote address of an authConn.
+type authAddr struct {
+	authorize urpc.AuthorizeFunc
+}
+
+// Network implements net.Addr.Network.
+func (*authAddr) Network() string {
+	return "unix"
+}
+
+// String implements net.Addr.String.
+func (*authAddr) String() string {
+	return "control-client"
+}
+
+// connListener is a net.Listener that accepts connections passed to push.
+type connListener struct {
+	conns chan net.Conn
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newConnListener() *connListener {
+	return &connListener{
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+	}
+}
+
+// push queues conn to be accepted. It returns false if the listener is closed.
+func (l *connListener) push(conn net.Conn) bool {
+	select {
+	case l.conns <- conn:
+		return true
+	case <-l.closed:
+		return false
+	}
+}
+
+// Accept implements net.Listener.Accept.
+func (l *connListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close implements net.Listener.Close.
+func (l *connListener) Close() error {
+	l.closeOnce.Do(func() { close(l.closed) })
+	return nil
+}
+
+// Addr implements net.Listener.Addr.
+func (l *connListener) Addr() net.Addr {
+	return &net.UnixAddr{Net: "unix", Name: "control"}
+}
@@ -0,0 +1,242 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
+	pb "gvisor.dev/gvisor/pkg/control/grpcserver/control_go_proto"
+	"gvisor.dev/gvisor/pkg/urpc"
+)
+
+type test struct{}
+
+type outputArgs struct {
+	urpc.FilePayload
+	Data string
+}
+
+func (test) Echo(arg *string, res *string) error {
+	*res = *arg
+	return nil
+}
+
+func (test) Fail(_ *struct{}, _ *struct{}) error {
+	return errors.New("failed")
+}
+
+func (test) Output(arg *outputArgs, _ *struct{}) error {
+	if len(arg.Files) != 1 {
+		return errors.New("no output file")
+	}
+	_, err := arg.Files[0].WriteString(arg.Data)
+	return err
+}
+
+func newClient(t *testing.T, authorize urpc.AuthorizeFunc) pb.ControlClient {
+	t.Helper()
+	u := urpc.NewServer()
+	u.Register(test{})
+	s := New(u)
+	t.Cleanup(s.Stop)
+
+	dial := func(context.Context, string) (net.Conn, error) {
+		client, server := net.Pipe()
+		s.Handle(server, authorize)
+		return client, nil
+	}
+	cc, err := grpc.NewClient("passthrough:///control",
+		grpc.WithContextDialer(dial),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.NewClient failed: %v", err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return pb.NewControlClient(cc)
+}
+
+// methods returns the argument and result messages of each method the client
+// may call, as described by the Schema method.
+func methods(t *testing.T, c pb.ControlClient) map[string][2]protoreflect.MessageDescriptor {
+	t.Helper()
+	resp, err := c.Schema(context.Background(), &pb.SchemaRequest{})
+	if err != nil {
+		t.Fatalf("Schema failed: %v", err)
+	}
+	fd, err := protodesc.NewFile(resp.GetFile(), protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("protodesc.NewFile failed: %v", err)
+	}
+	lookup := func(name string) protoreflect.MessageDescriptor {
+		md := fd.Messages().ByName(protoreflect.FullName(name).Name())
+		if md == nil {
+			t.Fatalf("no message %q in schema", name)
+		}
+		return md
+	}
+	ms := make(map[string][2]protoreflect.MessageDescriptor)
+	for _, m := range resp.GetMethods() {
+		ms[m.GetName()] = [2]protoreflect.MessageDescriptor{lookup(m.GetArgType()), lookup(m.GetResultType())}
+	}
+	return ms
+}
+
+// newArg returns the argument of method with the field named name set to v.
+func newArg(t *testing.T, md protoreflect.MessageDescriptor, name protoreflect.Name, v string) *anypb.Any {
+	t.Helper()
+	m := dynamicpb.NewMessage(md)
+	m.Set(md.Fields().ByName(name), protoreflect.ValueOfString(v))
+	a, err := anypb.New(m)
+	if err != nil {
+		t.Fatalf("anypb.New failed: %v", err)
+	}
+	return a
+}
+
+// resultValue returns the value field of a wrapped method result.
+func resultValue(t *testing.T, md protoreflect.MessageDescriptor, res *pb.CallResponse) string {
+	t.Helper()
+	m := dynamicpb.NewMessage(md)
+	if err := res.GetResult().UnmarshalTo(m); err != nil {
+		t.Fatalf("UnmarshalTo failed: %v", err)
+	}
+	return m.Get(md.Fields().ByName("value")).String()
+}
+
+func TestSchema(t *testing.T) {
+	c := newClient(t, nil)
+	ms := methods(t, c)
+	for _, method := range []string{"test.Echo", "test.Fail", "test.Output"} {
+		if _, ok := ms[method]; !ok {
+			t.Errorf("no schema for %q", method)
+		}
+	}
+	if fd := ms["test.Output"][0].Fields().ByName("data"); fd == nil || fd.Kind() != protoreflect.StringKind {
+		t.Errorf("test.Output argument has no string field data")
+	}
+}
+
+func TestCall(t *testing.T) {
+	c := newClient(t, nil)
+	echo := methods(t, c)["test.Echo"]
+	res, err := c.Call(context.Background(), &pb.CallRequest{Method: "test.Echo", Arg: newArg(t, echo[0], "value", "hello")})
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if got := resultValue(t, echo[1], res); got != "hello" {
+		t.Errorf("got result %q, want %q", got, "hello")
+	}
+}
+
+func TestCallErrors(t *testing.T) {
+	c := newClient(t, nil)
+	output := methods(t, c)["test.Output"]
+	for _, tc := range []struct {
+		method string
+		arg    *anypb.Any
+		code   codes.Code
+	}{
+		{method: "test.Missing", code: codes.Unimplemented},
+		{method: "test.Fail", code: codes.Unknown},
+		{method: "test.Echo", arg: newArg(t, output[0], "data", "hello"), code: codes.InvalidArgument},
+	} {
+		_, err := c.Call(context.Background(), &pb.CallRequest{Method: tc.method, Arg: tc.arg})
+		if got := status.Code(err); got != tc.code {
+			t.Errorf("Call(%q) got code %v, want %v (err: %v)", tc.method, got, tc.code, err)
+		}
+	}
+}
+
+func TestCallStream(t *testing.T) {
+	c := newClient(t, nil)
+	output := methods(t, c)["test.Output"]
+	stream, err := c.CallStream(context.Background(), &pb.CallRequest{Method: "test.Output", Arg: newArg(t, output[0], "data", "some output")})
+	if err != nil {
+		t.Fatalf("CallStream failed: %v", err)
+	}
+	var got []byte
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		got = append(got, chunk.GetData()...)
+	}
+	if string(got) != "some output" {
+		t.Errorf("got output %q, want %q", got, "some output")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	c := newClient(t, nil)
+	echo := methods(t, c)["test.Echo"]
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := c.Watch(ctx, &pb.WatchRequest{
+		Call: &pb.CallRequest{Method: "test.Echo", Arg: newArg(t, echo[0], "value", "tick")},
+	})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		res, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		if got := resultValue(t, echo[1], res); got != "tick" {
+			t.Errorf("got result %q, want %q", got, "tick")
+		}
+	}
+}
+
+func TestCallAuthorize(t *testing.T) {
+	c := newClient(t, func(method string) error {
+		if method != "test.Echo" {
+			return errors.New("denied")
+		}
+		return nil
+	})
+	ms := methods(t, c)
+	if _, ok := ms["test.Fail"]; ok {
+		t.Errorf("Schema lists unauthorized method test.Fail")
+	}
+	echo, ok := ms["test.Echo"]
+	if !ok {
+		t.Fatalf("Schema doesn't list authorized method test.Echo")
+	}
+	if _, err := c.Call(context.Background(), &pb.CallRequest{Method: "test.Echo", Arg: newArg(t, echo[0], "value", "hello")}); err != nil {
+		t.Errorf("Call(test.Echo) failed: %v", err)
+	}
+	_, err := c.Call(context.Background(), &pb.CallRequest{Method: "test.Fail"})
+	if got := status.Code(err); got != codes.PermissionDenied {
+		t.Errorf("Call(test.Fail) got code %v, want %v (err: %v)", got, codes.PermissionDenied, err)
+	}
+}
@@ -0,0 +1,736 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schema generates protobuf messages for the arguments and results of
+// urpc control methods, and converts between those messages and the JSON
+// encoding used by urpc.
+//
+// Messages are generated from the Go types of each method's argument and
+// result, following their encoding/json representation: each exported field
+// becomes a message field whose JSON name is the field's JSON key, structs
+// become messages, slices and arrays become repeated fields, and maps with
+// string or integer keys become map fields. Values that have no direct
+// protobuf equivalent, such as interfaces, nested slices or types with custom
+// JSON encodings, are carried as google.protobuf.Value. Arguments and results
+// that aren't structs are wrapped in a message with a single field, "value".
+//
+// Field numbers follow the order of the Go struct fields, so they may change
+// between releases. Clients should get the schema from the sandbox they talk
+// to rather than compiling it in.
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// FileName is the name of the generated proto file.
+	FileName = "gvisor/control/methods.proto"
+
+	// Package is the proto package of the generated messages.
+	Package = "gvisor.control.methods"
+
+	// valueName is the full name of google.protobuf.Value.
+	valueName = "google.protobuf.Value"
+)
+
+// Method describes a control method.
+type Method struct {
+	// Name is the method name, e.g. "Lifecycle.Pause".
+	Name string
+
+	// Arg is the type of the method's argument, i.e. the type that the
+	// method's first parameter points to.
+	Arg reflect.Type
+
+	// Result is the type of the method's result, i.e. the type that the
+	// method's second parameter points to.
+	Result reflect.Type
+}
+
+// Schema holds the generated messages for a set of control methods.
+type Schema struct {
+	file    protoreflect.FileDescriptor
+	proto   *descriptorpb.FileDescriptorProto
+	methods map[string]methodMessages
+	wrapped map[protoreflect.FullName]bool
+}
+
+// methodMessages are the messages of a method.
+type methodMessages struct {
+	arg    protoreflect.MessageDescriptor
+	result protoreflect.MessageDescriptor
+}
+
+// New generates the messages for methods.
+func New(methods []Method) (*Schema, error) {
+	b := builder{
+		file: &descriptorpb.FileDescriptorProto{
+			Name:    proto.String(FileName),
+			Package: proto.String(Package),
+			Syntax:  proto.String("proto3"),
+		},
+		named:   make(map[reflect.Type]string),
+		names:   make(map[string]bool),
+		wrapped: make(map[string]bool),
+	}
+	names := make(map[string][2]string, len(methods))
+	for _, m := range methods {
+		hint := sanitize(m.Name)
+		names[m.Name] = [2]string{
+			b.topLevel(m.Arg, hint+"_Arg"),
+			b.topLevel(m.Result, hint+"_Result"),
+		}
+	}
+	if b.usesValue {
+		b.file.Dependency = []string{structpb.File_google_protobuf_struct_proto.Path()}
+	}
+
+	file, err := protodesc.NewFile(b.file, protoregistry.GlobalFiles)
+	if err != nil {
+		return nil, fmt.Errorf("building descriptors: %w", err)
+	}
+	s := &Schema{
+		file:    file,
+		proto:   b.file,
+		methods: make(map[string]methodMessages, len(names)),
+		wrapped: make(map[protoreflect.FullName]bool, len(b.wrapped)),
+	}
+	msgs := file.Messages()
+	for method, n := range names {
+		s.methods[method] = methodMessages{
+			arg:    msgs.ByName(protoreflect.Name(n[0])),
+			result: msgs.ByName(protoreflect.Name(n[1])),
+		}
+	}
+	for name := range b.wrapped {
+		s.wrapped[protoreflect.FullName(Package+"."+name)] = true
+	}
+	return s, nil
+}
+
+// File returns the descriptor of the generated proto file. It depends on
+// google/protobuf/struct.proto if any message uses google.protobuf.Value.
+func (s *Schema) File() *descriptorpb.FileDescriptorProto {
+	return proto.Clone(s.proto).(*descriptorpb.FileDescriptorProto)
+}
+
+// Arg returns the message of method's argument.
+func (s *Schema) Arg(method string) (protoreflect.MessageDescriptor, bool) {
+	m, ok := s.methods[method]
+	return m.arg, ok
+}
+
+// Result returns the message of method's result.
+func (s *Schema) Result(method string) (protoreflect.MessageDescriptor, bool) {
+	m, ok := s.methods[method]
+	return m.result, ok
+}
+
+// ToJSON returns the urpc JSON encoding of m, which must be one of the
+// argument or result messages of s.
+func (s *Schema) ToJSON(m protoreflect.Message) ([]byte, error) {
+	var v any
+	if md := m.Descriptor(); s.wrapped[md.FullName()] {
+		fd := md.Fields().ByNumber(1)
+		if m.Has(fd) {
+			v = fieldToJSON(fd, m.Get(fd))
+		} else if !fd.IsList() && !fd.IsMap() && fd.Kind() != protoreflect.MessageKind {
+			v = fd.Default().Interface()
+		}
+	} else {
+		v = messageToJSON(m)
+	}
+	return json.Marshal(v)
+}
+
+// FromJSON decodes data, the urpc JSON encoding of a value of the type of md,
+// which must be one of the argument or result messages of s, into a new
+// message.
+func (s *Schema) FromJSON(data []byte, md protoreflect.MessageDescriptor) (*dynamicpb.Message, error) {
+	m := dynamicpb.NewMessage(md)
+	if s.wrapped[md.FullName()] {
+		if err := setFieldFromJSON(m, md.Fields().ByNumber(1), data); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+	if err := messageFromJSON(data, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// builder builds the proto file of a Schema.
+type builder struct {
+	file *descriptorpb.FileDescriptorProto
+
+	// named maps named Go struct types to their messages.
+	named map[reflect.Type]string
+
+	// names is the set of message names in use.
+	names map[string]bool
+
+	// wrapped is the set of messages that wrap a non-struct argument or
+	// result.
+	wrapped map[string]bool
+
+	// usesValue is true if any field is a google.protobuf.Value.
+	usesValue bool
+}
+
+// topLevel returns the name of the message for a method's argument or result
+// of type t.
+func (b *builder) topLevel(t reflect.Type, hint string) string {
+	if t.Kind() == reflect.Struct && !hasCustomJSON(t) {
+		return b.message(t, hint)
+	}
+	name := b.uniqueName(hint)
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	b.file.MessageType = append(b.file.MessageType, msg)
+	b.addField(msg, name, "value", "value", t)
+	b.wrapped[name] = true
+	return name
+}
+
+// message returns the name of the message for the struct type t. hint is
+// used to name t's message if t is unnamed.
+func (b *builder) message(t reflect.Type, hint string) string {
+	if name, ok := b.named[t]; ok {
+		return name
+	}
+	if t.Name() != "" {
+		pkg := t.PkgPath()
+		hint = sanitize(pkg[strings.LastIndex(pkg, "/")+1:] + "_" + t.Name())
+	}
+	name := b.uniqueName(hint)
+	if t.Name() != "" {
+		// Register the message before adding its fields, which may refer
+		// back to it.
+		b.named[t] = name
+	}
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	b.file.MessageType = append(b.file.MessageType, msg)
+	b.addStructFields(msg, name, t, make(map[string]bool), make(map[string]bool))
+	return name
+}
+
+// addStructFields adds the fields of the struct type t, as encoded by
+// encoding/json, to msg. jsonNames and protoNames are the JSON keys and field
+// names already used by msg.
+func (b *builder) addStructFields(msg *descriptorpb.DescriptorProto, msgName string, t reflect.Type, jsonNames, protoNames map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		key, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && key == "" {
+			// Fields of embedded structs are promoted, even if the
+			// embedded type isn't exported.
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !hasCustomJSON(ft) {
+				b.addStructFields(msg, msgName, ft, jsonNames, protoNames)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if key == "" {
+			key = f.Name
+		}
+		if jsonNames[key] {
+			// encoding/json would only encode one of the fields.
+			continue
+		}
+		jsonNames[key] = true
+
+		name := fieldName(key)
+		for n := 2; protoNames[name]; n++ {
+			name = fmt.Sprintf("%s_%d", fieldName(key), n)
+		}
+		protoNames[name] = true
+
+		ft := f.Type
+		if hasOption(opts, "string") && isQuotable(deref(ft)) {
+			// The value is encoded as a JSON string.
+			ft = reflect.TypeFor[string]()
+		}
+		b.addField(msg, msgName+"_"+f.Name, name, key, ft)
+	}
+}
+
+// addField adds a field named name, with JSON name key, for values of type t
+// to msg. hint is used to name messages for unnamed types.
+func (b *builder) addField(msg *descriptorpb.DescriptorProto, hint, name, key string, t reflect.Type) {
+	fd := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(key),
+		Number:   proto.Int32(int32(len(msg.Field) + 1)),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+	msg.Field = append(msg.Field, fd)
+
+	t = deref(t)
+	switch {
+	case hasCustomJSON(t):
+	case (t.Kind() == reflect.Slice && !isBytes(t)) || t.Kind() == reflect.Array:
+		if elem := deref(t.Elem()); isScalarOrStruct(elem) {
+			fd.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+			b.setType(fd, elem, hint)
+			return
+		}
+	case t.Kind() == reflect.Map:
+		key, ok := mapKeyType(t.Key())
+		if !ok {
+			break
+		}
+		entry := &descriptorpb.DescriptorProto{
+			Name:    proto.String(mapEntryName(name)),
+			Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+		}
+		valueType, valueTypeName := b.singular(deref(t.Elem()), hint)
+		entry.Field = []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     proto.String("key"),
+				JsonName: proto.String("key"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     key.Enum(),
+			},
+			{
+				Name:     proto.String("value"),
+				JsonName: proto.String("value"),
+				Number:   proto.Int32(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     valueType.Enum(),
+				TypeName: valueTypeName,
+			},
+		}
+		msg.NestedType = append(msg.NestedType, entry)
+		fd.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		fd.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+		fd.TypeName = proto.String("." + Package + "." + msg.GetName() + "." + entry.GetName())
+		return
+	}
+	b.setType(fd, t, hint)
+}
+
+// setType sets the type of fd to that of a non-repeated field holding values
+// of type t.
+func (b *builder) setType(fd *descriptorpb.FieldDescriptorProto, t reflect.Type, hint string) {
+	typ, typeName := b.singular(t, hint)
+	fd.Type = typ.Enum()
+	fd.TypeName = typeName
+}
+
+// singular returns the type of a non-repeated field holding values of type t.
+func (b *builder) singular(t reflect.Type, hint string) (descriptorpb.FieldDescriptorProto_Type, *string) {
+	t = deref(t)
+	if !hasCustomJSON(t) {
+		switch t.Kind() {
+		case reflect.Bool:
+			return descriptorpb.FieldDescriptorProto_TYPE_BOOL, nil
+		case reflect.Int8, reflect.Int16, reflect.Int32:
+			return descriptorpb.FieldDescriptorProto_TYPE_INT32, nil
+		case reflect.Int, reflect.Int64:
+			return descriptorpb.FieldDescriptorProto_TYPE_INT64, nil
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			return descriptorpb.FieldDescriptorProto_TYPE_UINT32, nil
+		case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+			return descriptorpb.FieldDescriptorProto_TYPE_UINT64, nil
+		case reflect.Float32:
+			return descriptorpb.FieldDescriptorProto_TYPE_FLOAT, nil
+		case reflect.Float64:
+			return descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, nil
+		case reflect.String:
+			return descriptorpb.FieldDescriptorProto_TYPE_STRING, nil
+		case reflect.Slice:
+			if isBytes(t) {
+				return descriptorpb.FieldDescriptorProto_TYPE_BYTES, nil
+			}
+		case reflect.Struct:
+			return descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, proto.String("." + Package + "." + b.message(t, hint))
+		}
+	}
+	b.usesValue = true
+	return descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, proto.String("." + valueName)
+}
+
+// uniqueName returns name, or name with a suffix if name is already in use.
+func (b *builder) uniqueName(name string) string {
+	unique := name
+	for n := 2; b.names[unique]; n++ {
+		unique = fmt.Sprintf("%s_%d", name, n)
+	}
+	b.names[unique] = true
+	return unique
+}
+
+var (
+	jsonMarshaler = reflect.TypeFor[json.Marshaler]()
+	textMarshaler = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// hasCustomJSON returns true if values of type t don't use the default
+// encoding/json representation of t's kind.
+func hasCustomJSON(t reflect.Type) bool {
+	for _, t := range []reflect.Type{t, reflect.PointerTo(t)} {
+		if t.Implements(jsonMarshaler) || t.Implements(textMarshaler) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBytes returns true if t is a byte slice, which encoding/json encodes as a
+// base64 string.
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && !hasCustomJSON(t.Elem())
+}
+
+// isScalarOrStruct returns true if t can be the element type of a repeated
+// field.
+func isScalarOrStruct(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice:
+		return isBytes(t)
+	case reflect.Array, reflect.Map, reflect.Interface:
+		return false
+	default:
+		return true
+	}
+}
+
+// isQuotable returns true if encoding/json encodes values of type t as JSON
+// strings when their field has the "string" option.
+func isQuotable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// mapKeyType returns the type of the keys of a map field for Go map keys of
+// type t, if t can be a map key.
+func mapKeyType(t reflect.Type) (descriptorpb.FieldDescriptorProto_Type, bool) {
+	if t.Implements(textMarshaler) {
+		return 0, false
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return descriptorpb.FieldDescriptorProto_TYPE_STRING, true
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return descriptorpb.FieldDescriptorProto_TYPE_INT32, true
+	case reflect.Int, reflect.Int64:
+		return descriptorpb.FieldDescriptorProto_TYPE_INT64, true
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return descriptorpb.FieldDescriptorProto_TYPE_UINT32, true
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return descriptorpb.FieldDescriptorProto_TYPE_UINT64, true
+	default:
+		return 0, false
+	}
+}
+
+// deref returns the type that t points to, if t is a pointer.
+func deref(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// hasOption returns true if the comma-separated JSON tag options opts contain
+// opt.
+func hasOption(opts, opt string) bool {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitize replaces characters that aren't valid in proto identifiers with
+// underscores.
+func sanitize(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if !(c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')) {
+			b[i] = '_'
+		}
+	}
+	if len(b) == 0 || ('0' <= b[0] && b[0] <= '9') {
+		return "_" + string(b)
+	}
+	return string(b)
+}
+
+// fieldName converts the JSON key of a field to a snake_case proto field
+// name, e.g. "ContainerID" to "container_id".
+func fieldName(key string) string {
+	r := []rune(sanitize(key))
+	var b strings.Builder
+	for i, c := range r {
+		if unicode.IsUpper(c) {
+			prevLower := i > 0 && (unicode.IsLower(r[i-1]) || unicode.IsDigit(r[i-1]))
+			nextLower := i > 0 && i+1 < len(r) && unicode.IsUpper(r[i-1]) && unicode.IsLower(r[i+1])
+			if prevLower || nextLower {
+				b.WriteByte('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// mapEntryName returns the name of the entry message of the map field name,
+// as protoc names it.
+func mapEntryName(name string) string {
+	var b strings.Builder
+	upperNext := true
+	for _, c := range name {
+		switch {
+		case c == '_':
+			upperNext = true
+		case upperNext:
+			b.WriteRune(unicode.ToUpper(c))
+			upperNext = false
+		default:
+			b.WriteRune(c)
+		}
+	}
+	b.WriteString("Entry")
+	return b.String()
+}
+
+// messageToJSON returns the value that encodes m's fields as JSON.
+func messageToJSON(m protoreflect.Message) any {
+	obj := make(map[string]any)
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		obj[fd.JSONName()] = fieldToJSON(fd, v)
+		return true
+	})
+	return obj
+}
+
+// fieldToJSON returns the value that encodes the value v of field fd as JSON.
+func fieldToJSON(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch {
+	case fd.IsList():
+		l := v.List()
+		arr := make([]any, l.Len())
+		for i := range arr {
+			arr[i] = singularToJSON(fd, l.Get(i))
+		}
+		return arr
+	case fd.IsMap():
+		obj := make(map[string]any)
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			obj[k.String()] = singularToJSON(fd.MapValue(), v)
+			return true
+		})
+		return obj
+	default:
+		return singularToJSON(fd, v)
+	}
+}
+
+// singularToJSON returns the value that encodes v, a single value of field
+// fd, as JSON.
+func singularToJSON(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	if fd.Kind() != protoreflect.MessageKind {
+		return v.Interface()
+	}
+	if fd.Message().FullName() == valueName {
+		data, err := protojson.Marshal(v.Message().Interface())
+		if err != nil {
+			// Values are always valid JSON.
+			panic(fmt.Sprintf("encoding %s: %v", valueName, err))
+		}
+		return json.RawMessage(data)
+	}
+	return messageToJSON(v.Message())
+}
+
+// messageFromJSON sets the fields of m from the JSON object data. Keys that
+// don't match a field are ignored, as by encoding/json.
+func messageFromJSON(data []byte, m protoreflect.Message) error {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	fields := m.Descriptor().Fields()
+	for key, v := range obj {
+		fd := fields.ByJSONName(key)
+		if fd == nil {
+			continue
+		}
+		if err := setFieldFromJSON(m, fd, v); err != nil {
+			return fmt.Errorf("field %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// setFieldFromJSON sets field fd of m from its JSON encoding data.
+func setFieldFromJSON(m protoreflect.Message, fd protoreflect.FieldDescriptor, data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	switch {
+	case fd.IsList():
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		l := m.Mutable(fd).List()
+		for _, item := range items {
+			v, err := singularFromJSON(fd, item, l.NewElement)
+			if err != nil {
+				return err
+			}
+			l.Append(v)
+		}
+	case fd.IsMap():
+		var entries map[string]json.RawMessage
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return err
+		}
+		mm := m.Mutable(fd).Map()
+		for k, item := range entries {
+			key, err := mapKeyFromJSON(fd.MapKey(), k)
+			if err != nil {
+				return err
+			}
+			v, err := singularFromJSON(fd.MapValue(), item, mm.NewValue)
+			if err != nil {
+				return err
+			}
+			mm.Set(key, v)
+		}
+	default:
+		v, err := singularFromJSON(fd, data, func() protoreflect.Value { return m.NewField(fd) })
+		if err != nil {
+			return err
+		}
+		m.Set(fd, v)
+	}
+	return nil
+}
+
+// singularFromJSON decodes a single value of field fd from its JSON encoding
+// data. newMessage returns a new value for message fields.
+func singularFromJSON(fd protoreflect.FieldDescriptor, data []byte, newMessage func() protoreflect.Value) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		v := newMessage()
+		if fd.Message().FullName() == valueName {
+			return v, protojson.Unmarshal(data, v.Message().Interface())
+		}
+		if string(data) == "null" {
+			return v, nil
+		}
+		return v, messageFromJSON(data, v.Message())
+	case protoreflect.BoolKind:
+		var b bool
+		err := json.Unmarshal(data, &b)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.Int32Kind:
+		var i int32
+		err := json.Unmarshal(data, &i)
+		return protoreflect.ValueOfInt32(i), err
+	case protoreflect.Int64Kind:
+		var i int64
+		err := json.Unmarshal(data, &i)
+		return protoreflect.ValueOfInt64(i), err
+	case protoreflect.Uint32Kind:
+		var i uint32
+		err := json.Unmarshal(data, &i)
+		return protoreflect.ValueOfUint32(i), err
+	case protoreflect.Uint64Kind:
+		var i uint64
+		err := json.Unmarshal(data, &i)
+		return protoreflect.ValueOfUint64(i), err
+	case protoreflect.FloatKind:
+		var f float32
+		err := json.Unmarshal(data, &f)
+		return protoreflect.ValueOfFloat32(f), err
+	case protoreflect.DoubleKind:
+		var f float64
+		err := json.Unmarshal(data, &f)
+		return protoreflect.ValueOfFloat64(f), err
+	case protoreflect.StringKind:
+		var s string
+		err := json.Unmarshal(data, &s)
+		return protoreflect.ValueOfString(s), err
+	case protoreflect.BytesKind:
+		var b []byte
+		err := json.Unmarshal(data, &b)
+		return protoreflect.ValueOfBytes(b), err
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported kind %v", fd.Kind())
+	}
+}
+
+// mapKeyFromJSON decodes a map key of field fd from its JSON object key k.
+func mapKeyFromJSON(fd protoreflect.FieldDescriptor, k string) (protoreflect.MapKey, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(k).MapKey(), nil
+	case protoreflect.Int32Kind:
+		i, err := strconv.ParseInt(k, 10, 32)
+		return protoreflect.ValueOfInt32(int32(i)).MapKey(), err
+	case protoreflect.Int64Kind:
+		i, err := strconv.ParseInt(k, 10, 64)
+		return protoreflect.ValueOfInt64(i).MapKey(), err
+	case protoreflect.Uint32Kind:
+		i, err := strconv.ParseUint(k, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(i)).MapKey(), err
+	case protoreflect.Uint64Kind:
+		i, err := strconv.ParseUint(k, 10, 64)
+		return protoreflect.ValueOfUint64(i).MapKey(), err
+	default:
+		return protoreflect.MapKey{}, fmt.Errorf("unsupported map key kind %v", fd.Kind())
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+type inner struct {
+	Name string
+	Next *inner `json:"next,omitempty"`
+}
+
+type embedded struct {
+	Embedded int32
+}
+
+type args struct {
+	embedded
+	ContainerID string
+	Count       int64             `json:"count"`
+	Flags       []uint16          `json:"flags"`
+	Data        []byte            `json:"data"`
+	Inner       inner             `json:"inner"`
+	Inners      []*inner          `json:"inners"`
+	Labels      map[string]string `json:"labels"`
+	ByPID       map[int32]inner   `json:"by_pid"`
+	Matrix      [][]int           `json:"matrix"`
+	Any         any               `json:"any"`
+	When        time.Time         `json:"when"`
+	Quoted      int64             `json:"quoted,string"`
+	Hidden      string            `json:"-"`
+	unexported  int
+}
+
+func newSchema(t *testing.T) *Schema {
+	t.Helper()
+	s, err := New([]Method{
+		{Name: "test.Args", Arg: reflect.TypeFor[args](), Result: reflect.TypeFor[struct{}]()},
+		{Name: "test.Scalar", Arg: reflect.TypeFor[string](), Result: reflect.TypeFor[[]int32]()},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return s
+}
+
+func TestMessages(t *testing.T) {
+	s := newSchema(t)
+
+	md, ok := s.Arg("test.Args")
+	if !ok {
+		t.Fatalf("no argument message for test.Args")
+	}
+	if got, want := md.FullName(), protoreflect.FullName(Package+".schema_args"); got != want {
+		t.Errorf("got argument message %q, want %q", got, want)
+	}
+	for _, tc := range []struct {
+		json string
+		name protoreflect.Name
+		kind protoreflect.Kind
+		list bool
+		mp   bool
+	}{
+		{json: "Embedded", name: "embedded", kind: protoreflect.Int32Kind},
+		{json: "ContainerID", name: "container_id", kind: protoreflect.StringKind},
+		{json: "count", name: "count", kind: protoreflect.Int64Kind},
+		{json: "flags", name: "flags", kind: protoreflect.Uint32Kind, list: true},
+		{json: "data", name: "data", kind: protoreflect.BytesKind},
+		{json: "inner", name: "inner", kind: protoreflect.MessageKind},
+		{json: "inners", name: "inners", kind: protoreflect.MessageKind, list: true},
+		{json: "labels", name: "labels", kind: protoreflect.MessageKind, mp: true},
+		{json: "by_pid", name: "by_pid", kind: protoreflect.MessageKind, mp: true},
+		{json: "matrix", name: "matrix", kind: protoreflect.MessageKind},
+		{json: "any", name: "any", kind: protoreflect.MessageKind},
+		{json: "when", name: "when", kind: protoreflect.MessageKind},
+		{json: "quoted", name: "quoted", kind: protoreflect.StringKind},
+	} {
+		fd := md.Fields().ByJSONName(tc.json)
+		if fd == nil {
+			t.Errorf("no field for %q", tc.json)
+			continue
+		}
+		if fd.Name() != tc.name || fd.Kind() != tc.kind || fd.IsList() != tc.list || fd.IsMap() != tc.mp {
+			t.Errorf("field for %q: got name %q kind %v list %t map %t, want name %q kind %v list %t map %t", tc.json, fd.Name(), fd.Kind(), fd.IsList(), fd.IsMap(), tc.name, tc.kind, tc.list, tc.mp)
+		}
+	}
+	if got, want := md.Fields().Len(), 13; got != want {
+		t.Errorf("got %d fields, want %d", got, want)
+	}
+	if got := md.Fields().ByJSONName("matrix").Message().FullName(); got != valueName {
+		t.Errorf("got matrix message %q, want %q", got, valueName)
+	}
+	if got := md.Fields().ByJSONName("when").Message().FullName(); got != valueName {
+		t.Errorf("got when message %q, want %q", got, valueName)
+	}
+	next := md.Fields().ByJSONName("inner").Message().Fields().ByJSONName("next")
+	if next == nil || next.Message() != md.Fields().ByJSONName("inner").Message() {
+		t.Errorf("inner.next doesn't refer back to inner")
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	s := newSchema(t)
+	md, _ := s.Arg("test.Args")
+
+	want := args{
+		embedded:    embedded{Embedded: 7},
+		ContainerID: "abc",
+		Count:       1 << 40,
+		Flags:       []uint16{1, 2},
+		Data:        []byte("data"),
+		Inner:       inner{Name: "a", Next: &inner{Name: "b"}},
+		Inners:      []*inner{{Name: "c"}},
+		Labels:      map[string]string{"k": "v"},
+		ByPID:       map[int32]inner{42: {Name: "d"}},
+		Matrix:      [][]int{{1, 2}, {3}},
+		Any:         "any",
+		When:        time.Unix(1000, 0).UTC(),
+		Quoted:      5,
+	}
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	m, err := s.FromJSON(data, md)
+	if err != nil {
+		t.Fatalf("FromJSON(%s) failed: %v", data, err)
+	}
+	out, err := s.ToJSON(m)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	var got args
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("json.Unmarshal(%s) failed: %v", out, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v after round trip through %s, want %+v", got, out, want)
+	}
+}
+
+func TestWrapped(t *testing.T) {
+	s := newSchema(t)
+
+	md, _ := s.Arg("test.Scalar")
+	m, err := s.FromJSON([]byte(`"hello"`), md)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	if got := m.Get(md.Fields().ByName("value")).String(); got != "hello" {
+		t.Errorf("got value %q, want %q", got, "hello")
+	}
+	out, err := s.ToJSON(m)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	if string(out) != `"hello"` {
+		t.Errorf("got JSON %s, want %q", out, "hello")
+	}
+
+	// Unset scalars are encoded as their zero value.
+	out, err = s.ToJSON(newMessage(t, s, md))
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	if string(out) != `""` {
+		t.Errorf("got JSON %s for unset value, want %q", out, "")
+	}
+
+	rd, _ := s.Result("test.Scalar")
+	m, err = s.FromJSON([]byte(`[1,2,3]`), rd)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	out, err = s.ToJSON(m)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	if string(out) != `[1,2,3]` {
+		t.Errorf("got JSON %s, want [1,2,3]", out)
+	}
+}
+
+func newMessage(t *testing.T, s *Schema, md protoreflect.MessageDescriptor) protoreflect.Message {
+	t.Helper()
+	m, err := s.FromJSON([]byte("null"), md)
+	if err != nil {
+		t.Fatalf("FromJSON(null) failed: %v", err)
+	}
+	return m
+}
@@ -0,0 +1,208 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// Policy restricts the control methods that peers other than the sandbox
+// owner may call.
+//
+// The control server runs in the sandbox's user namespace, where the host
+// credentials of peers can't be recovered: SO_PEERCRED reports every peer
+// whose UID isn't mapped into the namespace as the overflow UID. Policy is
+// therefore enforced by the host kernel when peers connect. Each grant has its
+// own control socket, which runsc creates outside of the sandbox and makes
+// accessible only to the grant's UID or GID (see CreateGrantSocket), and the
+// control server only allows the grant's methods on it. The main control
+// socket is only accessible to the sandbox owner and host root, which may call
+// any method.
+type Policy struct {
+	// grants holds the UID grants sorted by UID, followed by the GID grants
+	// sorted by GID. There is at most one grant per UID or GID.
+	grants []Grant
+}
+
+// Grant is a set of control methods granted to the peers with a host UID or
+// GID. The GID matches both primary and supplementary groups, since the host
+// kernel checks group access to the grant's socket against both.
+type Grant struct {
+	// UID and GID are the host IDs that the grant applies to. Exactly one of
+	// them is valid; the other is -1.
+	UID int64
+	GID int64
+
+	// methods are the method patterns granted. A pattern is either a method
+	// name ("Lifecycle.Pause"), all methods of an object ("Usage.*"), or all
+	// methods ("*").
+	methods []string
+}
+
+// ParsePolicy parses a policy of the form "RULE[;RULE...]". Each rule is
+// "uid=ID:METHODS" or "gid=ID:METHODS", where METHODS is a comma-separated
+// list of method patterns. Rules for the same UID or GID are merged. For
+// example:
+//
+//	uid=1000:Usage.*,Metrics.*;gid=2000:containerManager.Event
+func ParsePolicy(s string) (*Policy, error) {
+	p := &Policy{}
+	for _, r := range strings.Split(s, ";") {
+		if r == "" {
+			continue
+		}
+		who, methods, ok := strings.Cut(r, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule %q: missing ':'", r)
+		}
+		key, val, ok := strings.Cut(who, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule %q: peer must be uid=ID or gid=ID", r)
+		}
+		id, err := strconv.ParseUint(val, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %q: invalid ID %q: %w", r, val, err)
+		}
+		var g *Grant
+		switch key {
+		case "uid":
+			g = p.grant(int64(id), -1)
+		case "gid":
+			g = p.grant(-1, int64(id))
+		default:
+			return nil, fmt.Errorf("invalid rule %q: peer must be uid=ID or gid=ID", r)
+		}
+		n := len(g.methods)
+		for _, m := range strings.Split(methods, ",") {
+			if m == "" {
+				continue
+			}
+			if !validPattern(m) {
+				return nil, fmt.Errorf("invalid rule %q: invalid method pattern %q", r, m)
+			}
+			g.methods = append(g.methods, m)
+		}
+		if len(g.methods) == n {
+			return nil, fmt.Errorf("invalid rule %q: no methods", r)
+		}
+	}
+	sort.Slice(p.grants, func(i, j int) bool {
+		a, b := &p.grants[i], &p.grants[j]
+		if (a.UID >= 0) != (b.UID >= 0) {
+			return a.UID >= 0
+		}
+		if a.UID != b.UID {
+			return a.UID < b.UID
+		}
+		return a.GID < b.GID
+	})
+	return p, nil
+}
+
+// grant returns the grant for uid or gid, adding it if needed.
+func (p *Policy) grant(uid, gid int64) *Grant {
+	for i := range p.grants {
+		if g := &p.grants[i]; g.UID == uid && g.GID == gid {
+			return g
+		}
+	}
+	p.grants = append(p.grants, Grant{UID: uid, GID: gid})
+	return &p.grants[len(p.grants)-1]
+}
+
+// Grants returns the grants of the policy, in a stable order. The sandbox
+// receives the grants' sockets in this order.
+func (p *Policy) Grants() []Grant {
+	return p.grants
+}
+
+// SocketSuffix returns the suffix appended to the path of the main control
+// socket to name the grant's socket.
+func (g *Grant) SocketSuffix() string {
+	if g.UID >= 0 {
+		return fmt.Sprintf(".uid-%d", g.UID)
+	}
+	return fmt.Sprintf(".gid-%d", g.GID)
+}
+
+// Authorize returns nil if the grant allows method. It is an
+// urpc.AuthorizeFunc.
+func (g *Grant) Authorize(method string) error {
+	for _, m := range g.methods {
+		if matchMethod(m, method) {
+			return nil
+		}
+	}
+	if g.UID >= 0 {
+		return fmt.Errorf("permission denied: uid %d may not call %s", g.UID, method)
+	}
+	return fmt.Errorf("permission denied: gid %d may not call %s", g.GID, method)
+}
+
+// CreateGrantSocket creates the socket for g at path, like CreateSocket, and
+// restricts access to it to the peers that g applies to. It must be called
+// outside of the sandbox's user namespace. Unless the caller already is g.UID
+// or a member of g.GID, changing the owner of the socket requires CAP_CHOWN.
+func CreateGrantSocket(path string, g *Grant) (int, error) {
+	fd, err := CreateSocket(path)
+	if err != nil {
+		return -1, err
+	}
+	// The socket doesn't accept connections until the control server starts
+	// listening, so its permissions can be changed without racing with peers.
+	// Change the mode first, as only the owner may change it. The caller keeps
+	// access to group sockets, as it may call any method anyway.
+	uid, gid, mode := int(g.UID), -1, os.FileMode(0600)
+	if g.GID >= 0 {
+		uid, gid, mode = -1, int(g.GID), 0660
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		unix.Close(fd)
+		os.Remove(path)
+		return -1, fmt.Errorf("restricting control socket %q: %w", path, err)
+	}
+	if err := os.Lchown(path, uid, gid); err != nil {
+		unix.Close(fd)
+		os.Remove(path)
+		return -1, fmt.Errorf("restricting control socket %q: %w", path, err)
+	}
+	return fd, nil
+}
+
+// validPattern returns true if pattern is a valid method pattern.
+func validPattern(pattern string) bool {
+	if pattern == "*" {
+		return true
+	}
+	obj, _ := strings.CutSuffix(pattern, ".*")
+	return obj != "" && !strings.Contains(obj, "*")
+}
+
+// matchMethod returns true if method matches pattern.
+func matchMethod(pattern, method string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if obj, ok := strings.CutSuffix(pattern, ".*"); ok {
+		return strings.HasPrefix(method, obj+".")
+	}
+	return pattern == method
+}
@@ -0,0 +1,300 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/unet"
+	"gvisor.dev/gvisor/pkg/urpc"
+)
+
+func TestParsePolicyErrors(t *testing.T) {
+	for _, s := range []string{
+		"uid=1000",
+		"pid=1000:Usage.*",
+		"uid=foo:Usage.*",
+		"uid=1000:",
+		"uid=1000:Usage*",
+		"uid=1000:*.Usage",
+	} {
+		if _, err := ParsePolicy(s); err == nil {
+			t.Errorf("ParsePolicy(%q) succeeded, want error", s)
+		}
+	}
+}
+
+func TestParsePolicyGrants(t *testing.T) {
+	p, err := ParsePolicy("gid=2000:*;uid=1000:Usage.*;gid=10:Usage.*;uid=1000:containerManager.Event")
+	if err != nil {
+		t.Fatalf("ParsePolicy failed: %v", err)
+	}
+	var got []string
+	for _, g := range p.Grants() {
+		got = append(got, g.SocketSuffix()+"="+strings.Join(g.methods, ","))
+	}
+	want := []string{
+		".uid-1000=Usage.*,containerManager.Event",
+		".gid-10=Usage.*",
+		".gid-2000=*",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("ParsePolicy grants = %q, want %q", got, want)
+	}
+}
+
+func TestGrantAuthorize(t *testing.T) {
+	p, err := ParsePolicy("uid=1000:Usage.*,containerManager.Event;gid=2000:*")
+	if err != nil {
+		t.Fatalf("ParsePolicy failed: %v", err)
+	}
+	uid, gid := &p.Grants()[0], &p.Grants()[1]
+	for _, tc := range []struct {
+		name   string
+		grant  *Grant
+		method string
+		want   bool
+	}{
+		{name: "object", grant: uid, method: "Usage.Collect", want: true},
+		{name: "method", grant: uid, method: "containerManager.Event", want: true},
+		{name: "denied", grant: uid, method: "containerManager.Checkpoint", want: false},
+		{name: "object prefix", grant: uid, method: "UsageX.Collect", want: false},
+		{name: "all", grant: gid, method: "containerManager.Checkpoint", want: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.grant.Authorize(tc.method)
+			if got := err == nil; got != tc.want {
+				t.Errorf("Authorize(%q) = %v, want allowed=%t", tc.method, err, tc.want)
+			}
+		})
+	}
+}
+
+// PolicyTest is registered with the control server started by
+// TestPolicyUserNamespace.
+type PolicyTest struct{}
+
+// Allowed returns the UID of the control server.
+func (*PolicyTest) Allowed(_ *struct{}, uid *int) error {
+	*uid = os.Getuid()
+	return nil
+}
+
+// Denied returns the UID of the control server.
+func (*PolicyTest) Denied(_ *struct{}, uid *int) error {
+	*uid = os.Getuid()
+	return nil
+}
+
+const (
+	// policyServerEnv is set to the policy when TestPolicyUserNamespace runs
+	// as the control server.
+	policyServerEnv = "CONTROL_POLICY_TEST_SERVER"
+
+	// policyClientEnv is set to "PATH:METHOD" when TestPolicyUserNamespace
+	// runs as a client calling METHOD on the control socket at PATH.
+	policyClientEnv = "CONTROL_POLICY_TEST_CLIENT"
+)
+
+// TestPolicyUserNamespace serves the policy from a user namespace that only
+// maps the overflow UID, like the sandbox does, and checks that the policy is
+// enforced on the host credentials of clients.
+func TestPolicyUserNamespace(t *testing.T) {
+	if policy, ok := os.LookupEnv(policyServerEnv); ok {
+		servePolicyTest(policy)
+		return
+	}
+	if target, ok := os.LookupEnv(policyClientEnv); ok {
+		path, method, _ := strings.Cut(target, ":")
+		if _, err := callPolicyTest(path, method); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Let other users reach the sockets, so that the permissions of the
+	// sockets themselves are tested.
+	dir := t.TempDir()
+	for _, d := range []string{filepath.Dir(dir), dir} {
+		if err := os.Chmod(d, 0711); err != nil {
+			t.Fatalf("chmod %q: %v", d, err)
+		}
+	}
+	const grantGID = 4242
+	policy := fmt.Sprintf("gid=%d:PolicyTest.Allowed", os.Getgid())
+	if os.Getuid() == 0 {
+		// Root can give the grant to a group that isn't its own, so that
+		// unprivileged clients can be run below.
+		policy = fmt.Sprintf("gid=%d:PolicyTest.Allowed", grantGID)
+	}
+	p, err := ParsePolicy(policy)
+	if err != nil {
+		t.Fatalf("ParsePolicy(%q) failed: %v", policy, err)
+	}
+	grant := &p.Grants()[0]
+	path := filepath.Join(dir, "control.sock")
+	grantPath := path + grant.SocketSuffix()
+
+	fd, err := CreateSocket(path)
+	if err != nil {
+		t.Fatalf("CreateSocket(%q) failed: %v", path, err)
+	}
+	files := []*os.File{os.NewFile(uintptr(fd), "control")}
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatalf("chmod %q: %v", path, err)
+	}
+	grantFD, err := CreateGrantSocket(grantPath, grant)
+	if err != nil {
+		t.Fatalf("CreateGrantSocket(%q) failed: %v", grantPath, err)
+	}
+	files = append(files, os.NewFile(uintptr(grantFD), "control-grant"))
+
+	cmd := exec.Command("/proc/self/exe", "-test.run=^TestPolicyUserNamespace$")
+	cmd.Env = append(os.Environ(), policyServerEnv+"="+policy)
+	cmd.ExtraFiles = files
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:  unix.CLONE_NEWUSER,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: overflowID, HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: overflowID, HostID: os.Getgid(), Size: 1}},
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatalf("StdinPipe failed: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("unable to start control server in a user namespace: %v", err)
+	}
+	for _, f := range files {
+		f.Close()
+	}
+	defer func() {
+		stdin.Close()
+		cmd.Wait()
+	}()
+
+	// The owner may call any method on the main socket, even though it isn't
+	// mapped into the server's user namespace.
+	var uid int
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		uid, err = callPolicyTest(path, "PolicyTest.Denied")
+		if !errors.Is(err, unix.ECONNREFUSED) || time.Since(start) > 10*time.Second {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("owner call on the main socket failed: %v", err)
+	}
+	if uid != overflowID {
+		t.Errorf("control server runs as uid %d, want %d", uid, overflowID)
+	}
+
+	if os.Getuid() != 0 {
+		// Only the grant's methods may be called on its socket.
+		if _, err := callPolicyTest(grantPath, "PolicyTest.Allowed"); err != nil {
+			t.Errorf("granted call failed: %v", err)
+		}
+		if _, err := callPolicyTest(grantPath, "PolicyTest.Denied"); err == nil {
+			t.Errorf("call of a method that isn't granted succeeded")
+		}
+		return
+	}
+
+	// Run clients as another user, with and without the grant's group as a
+	// supplementary group.
+	for _, tc := range []struct {
+		name   string
+		groups []uint32
+		path   string
+		method string
+		want   bool
+	}{
+		{name: "main socket", groups: []uint32{grantGID}, path: path, method: "PolicyTest.Allowed", want: false},
+		{name: "not in group", path: grantPath, method: "PolicyTest.Allowed", want: false},
+		{name: "supplementary group", groups: []uint32{grantGID}, path: grantPath, method: "PolicyTest.Allowed", want: true},
+		{name: "method not granted", groups: []uint32{grantGID}, path: grantPath, method: "PolicyTest.Denied", want: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client := exec.Command("/proc/self/exe", "-test.run=^TestPolicyUserNamespace$")
+			client.Env = append(os.Environ(), policyClientEnv+"="+tc.path+":"+tc.method)
+			client.SysProcAttr = &syscall.SysProcAttr{
+				Credential: &syscall.Credential{Uid: overflowID, Gid: overflowID, Groups: tc.groups},
+			}
+			out, err := client.CombinedOutput()
+			var exitErr *exec.ExitError
+			if err != nil && !errors.As(err, &exitErr) {
+				t.Skipf("unable to run client as uid %d: %v", overflowID, err)
+			}
+			if got := err == nil; got != tc.want {
+				t.Errorf("client with groups %v calling %s on %s: err = %v, output %q, want success=%t", tc.groups, tc.method, tc.path, err, out, tc.want)
+			}
+		})
+	}
+}
+
+// overflowID is the UID and GID that the control server runs as in
+// TestPolicyUserNamespace.
+const overflowID = 65534
+
+// servePolicyTest serves PolicyTest with policy on the sockets passed by
+// TestPolicyUserNamespace, until stdin is closed.
+func servePolicyTest(policy string) {
+	p, err := ParsePolicy(policy)
+	if err != nil {
+		panic(err)
+	}
+	srv, err := CreateFromFD(3)
+	if err != nil {
+		panic(err)
+	}
+	grants := p.Grants()
+	for i := range grants {
+		if err := srv.AddRestrictedFD(4+i, grants[i].Authorize); err != nil {
+			panic(err)
+		}
+	}
+	srv.Register(&PolicyTest{})
+	if err := srv.StartServing(); err != nil {
+		panic(err)
+	}
+	io.Copy(io.Discard, os.Stdin)
+	os.Exit(0)
+}
+
+// callPolicyTest calls method on the control socket at path, and returns the
+// UID of the server.
+func callPolicyTest(path, method string) (int, error) {
+	socket, err := unet.Connect(path, false)
+	if err != nil {
+		return 0, err
+	}
+	client := urpc.NewClient(socket)
+	defer client.Close()
+	var uid int
+	err = client.Call(method, &struct{}{}, &uid)
+	return uid, err
+}
@@ -22,13 +22,16 @@ package server
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sync/atomic"
 	"time"
 
 	"golang.org/x/sys/unix"
 	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/log"
 	"gvisor.dev/gvisor/pkg/sync"
 	"gvisor.dev/gvisor/pkg/unet"
 	"gvisor.dev/gvisor/pkg/urpc"
@@ -37,6 +40,11 @@ import (
 // curUID is the unix user ID of the user that the control server is running as.
 var curUID = os.Getuid()
 
+// http2PrefaceStart is the first byte of the HTTP/2 client connection preface
+// ("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"), which every gRPC client sends first.
+// urpc calls are JSON objects and therefore never start with it.
+const http2PrefaceStart = 'P'
+
 // Server is a basic control server.
 type Server struct {
 	// socket is our bound socket.
@@ -45,10 +53,26 @@ type Server struct {
 	// server is our rpc server.
 	server atomic.Pointer[urpc.Server]
 
-	// wg waits for the accept loop to terminate.
+	// grpcHandler, if not nil, is passed connections from gRPC clients. It is
+	// set before the server starts serving and is immutable afterwards.
+	grpcHandler func(net.Conn, urpc.AuthorizeFunc)
+
+	// restricted are additional sockets whose clients may only call some
+	// methods. It is set before the server starts serving and is immutable
+	// afterwards.
+	restricted []restrictedSocket
+
+	// wg waits for the accept loops to terminate.
 	wg sync.WaitGroup
 }
 
+// restrictedSocket is a bound socket whose clients may only call the methods
+// allowed by authorize.
+type restrictedSocket struct {
+	socket    *unet.ServerSocket
+	authorize urpc.AuthorizeFunc
+}
+
 // New returns a new bound control server.
 func New(socket *unet.ServerSocket) *Server {
 	s := &Server{
@@ -71,8 +95,18 @@ func (s *Server) FD() int {
 	return s.socket.FD()
 }
 
-// Wait waits for the main server goroutine to exit. This should be
-// called after a call to Serve.
+// RestrictedFDs returns the file descriptors of the sockets added with
+// AddRestrictedFD.
+func (s *Server) RestrictedFDs() []int {
+	fds := make([]int, 0, len(s.restricted))
+	for _, r := range s.restricted {
+		fds = append(fds, r.socket.FD())
+	}
+	return fds
+}
+
+// Wait waits for the server goroutines to exit. This should be called after a
+// call to Serve.
 func (s *Server) Wait() {
 	s.wg.Wait()
 }
@@ -81,6 +115,9 @@ func (s *Server) Wait() {
 // and the server should not be used afterwards.
 func (s *Server) Stop(timeout time.Duration) {
 	s.socket.Close()
+	for _, r := range s.restricted {
+		r.socket.Close()
+	}
 	s.Wait()
 
 	// This will cause existing clients to be terminated safely. If the
@@ -88,36 +125,53 @@ func (s *Server) Stop(timeout time.Duration) {
 	s.server.Load().Stop(timeout)
 }
 
-// StartServing starts listening for connect and spawns the main service
-// goroutine for handling incoming control requests. StartServing does not
-// block; to wait for the control server to exit, call Wait.
+// StartServing starts listening for connect and spawns the service goroutines
+// for handling incoming control requests. StartServing does not block; to
+// wait for the control server to exit, call Wait.
 func (s *Server) StartServing() error {
 	// Actually start listening.
 	if err := s.socket.Listen(); err != nil {
 		return err
 	}
+	for _, r := range s.restricted {
+		if err := r.socket.Listen(); err != nil {
+			return err
+		}
+	}
 
-	s.wg.Add(1)
+	s.wg.Add(1 + len(s.restricted))
 	go func() { // S/R-SAFE: does not impact state directly.
-		s.serve()
+		s.serve(s.socket, nil)
 		s.wg.Done()
 	}()
+	for _, r := range s.restricted {
+		go func() { // S/R-SAFE: does not impact state directly.
+			s.serve(r.socket, r.authorize)
+			s.wg.Done()
+		}()
+	}
 
 	return nil
 }
 
-// serve is the body of the main service goroutine. It handles incoming control
-// connections and dispatches requests to registered objects.
-func (s *Server) serve() {
+// serve is the body of a service goroutine. It handles incoming control
+// connections on socket and dispatches requests to registered objects. If
+// authorize is not nil, clients may only call the methods it allows.
+func (s *Server) serve(socket *unet.ServerSocket, authorize urpc.AuthorizeFunc) {
 	for {
 		// Accept clients.
-		conn, err := s.socket.Accept()
+		conn, err := socket.Accept()
 		if err != nil {
 			return
 		}
 
 		// Handle the connection non-blockingly.
-		s.server.Load().StartHandling(conn)
+		if s.grpcHandler != nil {
+			// Peeking blocks until the client sends something.
+			go s.dispatch(conn, authorize) // S/R-SAFE: does not impact state directly.
+		} else {
+			s.server.Load().StartHandlingWithAuthorizer(conn, authorize)
+		}
 	}
 }
 
@@ -126,6 +180,73 @@ func (s *Server) Register(obj any) {
 	s.server.Load().Register(obj)
 }
 
+// Methods returns the sorted names of all methods registered with the server.
+func (s *Server) Methods() []string {
+	return s.server.Load().Methods()
+}
+
+// MethodTypes returns the argument and result types of a registered method.
+// See urpc.Server.MethodTypes.
+func (s *Server) MethodTypes(method string) (arg, result reflect.Type, ok bool) {
+	return s.server.Load().MethodTypes(method)
+}
+
+// Invoke calls a registered method in-process. See urpc.Server.Invoke.
+func (s *Server) Invoke(method string, arg []byte, files []*os.File) ([]byte, []*os.File, error) {
+	return s.server.Load().Invoke(method, arg, files)
+}
+
+// SetGRPCHandler makes the server pass connections from gRPC clients to h,
+// while urpc clients keep being served as before. Clients are told apart by
+// the first byte they send. h must check each call with the given
+// AuthorizeFunc, if not nil. It must be called before StartServing.
+func (s *Server) SetGRPCHandler(h func(net.Conn, urpc.AuthorizeFunc)) {
+	s.grpcHandler = h
+}
+
+// AddRestrictedFD makes the server also serve clients on the bound socket fd,
+// which may only call the methods allowed by authorize. See Policy. It must be
+// called before StartServing.
+func (s *Server) AddRestrictedFD(fd int, authorize urpc.AuthorizeFunc) error {
+	socket, err := unet.NewServerSocket(fd)
+	if err != nil {
+		return err
+	}
+	s.restricted = append(s.restricted, restrictedSocket{
+		socket:    socket,
+		authorize: authorize,
+	})
+	return nil
+}
+
+// dispatch hands conn over to the gRPC handler or to the urpc server depending
+// on the first byte sent by the client. If authorize is not nil, the client
+// may only call the methods it allows.
+func (s *Server) dispatch(conn *unet.Socket, authorize urpc.AuthorizeFunc) {
+	var b [1]byte
+	if _, err := conn.Peek(b[:]); err != nil {
+		conn.Close()
+		return
+	}
+	if b[0] != http2PrefaceStart {
+		s.server.Load().StartHandlingWithAuthorizer(conn, authorize)
+		return
+	}
+
+	fd, err := conn.Release()
+	if err != nil {
+		return
+	}
+	f := os.NewFile(uintptr(fd), "control-grpc")
+	nc, err := net.FileConn(f)
+	f.Close()
+	if err != nil {
+		log.Warningf("control: unable to serve gRPC client: %v", err)
+		return
+	}
+	s.grpcHandler(nc, authorize)
+}
+
 // CreateFromFD creates a new control bound to the given 'fd'. It has no
 // registered interfaces and will not start serving until StartServing is
 // called.
@@ -25,7 +25,8 @@ import (
 )
 
 // Enable core tagging. If this returns with no error, all threads in the
-// current thread group will be run in a core tagged thread. Only available on
+// current thread group will be run in a core tagged thread, and threads and
+// processes created afterwards inherit the same core tag. Only available on
 // linux kernel >= 5.14.
 func Enable() error {
 	// Set core tag on current thread group.
@@ -35,7 +36,7 @@ func Enable() error {
 	// cookie=nullptr is required for PR_SCHED_CORE_CREATE.
 	if _, _, errno := unix.Syscall6(unix.SYS_PRCTL, unix.PR_SCHED_CORE,
 		unix.PR_SCHED_CORE_CREATE, 0 /*pid*/, linux.PR_SCHED_CORE_SCOPE_THREAD_GROUP, 0, 0); errno != 0 {
-		return fmt.Errorf("failed to core tag sentry: %w", errno)
+		return fmt.Errorf("failed to core tag process: %w", errno)
 	}
 	return nil
 }
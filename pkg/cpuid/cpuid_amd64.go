@@ -212,6 +212,28 @@ func (fs FeatureSet) PhysicalAddressBits() uint32 {
 	return physBits - ((memEncBX >> amdPhysAddrReductionShift) & amdPhysAddrReductionMask)
 }
 
+// amdIBPB is the bit in CPUID.(EAX=0x80000008):EBX that indicates support
+// for the indirect branch prediction barrier on AMD CPUs.
+const amdIBPB = 1 << 12
+
+// HasIBPB returns true if fs supports the indirect branch prediction barrier
+// command of IA32_PRED_CMD. Intel CPUs enumerate it together with IBRS as
+// SPEC_CTRL, while AMD CPUs enumerate it separately. Compare Linux's
+// arch/x86/kernel/cpu/common.c:init_speculation_control().
+//
+//go:nosplit
+func (fs FeatureSet) HasIBPB() bool {
+	if fs.HasFeature(X86FeatureSPEC_CTRL) {
+		return true
+	}
+	maxExtended, _, _, _ := fs.query(extendedFunctionInfo)
+	if maxExtended < uint32(addressSizes) {
+		return false
+	}
+	_, bx, _, _ := fs.query(addressSizes)
+	return bx&amdIBPB != 0
+}
+
 // CacheType describes the type of a cache, as returned in eax[4:0] for eax=4.
 type CacheType uint8
 
@@ -326,7 +348,10 @@ func (fs FeatureSet) HasFeature(feature Feature) bool {
 // WriteCPUInfoTo is to generate a section of one cpu in /proc/cpuinfo. This is
 // a minimal /proc/cpuinfo, it is missing some fields like "microcode" that are
 // not always printed in Linux. Several fields are simply made up.
-func (fs FeatureSet) WriteCPUInfoTo(cpu, numCPU uint, w io.Writer) {
+//
+// cpu is core coreID of the physicalID'th package, which has packageCPUs
+// cores.
+func (fs FeatureSet) WriteCPUInfoTo(cpu, physicalID, coreID, packageCPUs uint, w io.Writer) {
 	// Avoid many redundant calls here, since this can occasionally appear
 	// in the hot path. Read all basic information up front, see above.
 	ax, _, _, _ := fs.query(featureInfo)
@@ -346,10 +371,10 @@ func (fs FeatureSet) WriteCPUInfoTo(cpu, numCPU uint, w io.Writer) {
 	// 8192 KB is selected because it is a reasonable size that will be effectively usable on
 	// lightly loaded machines - most machines have 1-4MB of L3 cache per core.
 	fmt.Fprintf(w, "cache size\t: 8192 KB\n")
-	fmt.Fprintf(w, "physical id\t: 0\n") // Pretend all CPUs are in the same socket.
-	fmt.Fprintf(w, "siblings\t: %d\n", numCPU)
-	fmt.Fprintf(w, "core id\t\t: %d\n", cpu)
-	fmt.Fprintf(w, "cpu cores\t: %d\n", numCPU) // Pretend each CPU is a distinct core (rather than a hyperthread).
+	fmt.Fprintf(w, "physical id\t: %d\n", physicalID)
+	fmt.Fprintf(w, "siblings\t: %d\n", packageCPUs)
+	fmt.Fprintf(w, "core id\t\t: %d\n", coreID)
+	fmt.Fprintf(w, "cpu cores\t: %d\n", packageCPUs) // Pretend each CPU is a distinct core (rather than a hyperthread).
 	fmt.Fprintf(w, "apicid\t\t: %d\n", cpu)
 	fmt.Fprintf(w, "initial apicid\t: %d\n", cpu)
 	fmt.Fprintf(w, "fpu\t\t: yes\n")
@@ -76,6 +76,11 @@ func (ep *Endpoint) Destroy() {
 	ep.sockfd = -1
 }
 
+// FD returns the socket FD owned by ep.
+func (ep *Endpoint) FD() int {
+	return int(ep.sockfd)
+}
+
 // Shutdown causes concurrent and future calls to ep.SendFD(), ep.RecvFD(), and
 // ep.RecvFDNonblock(), as well as the same calls in the connected Endpoint, to
 // unblock and return errors. It does not wait for concurrent calls to return.
@@ -149,6 +149,18 @@ func (n *notifier) hasFD(fd int32) bool {
 	return ok
 }
 
+// fds returns the FDs in the list of observed FDs.
+func (n *notifier) fds() []int32 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	fds := make([]int32, 0, len(n.fdMap))
+	for fd := range n.fdMap {
+		fds = append(fds, fd)
+	}
+	return fds
+}
+
 // waitAndNotify run is its own goroutine and loops waiting for io event
 // notifications from the epoll object. Once notifications arrive, they are
 // dispatched to the registered queue.
@@ -232,6 +244,16 @@ func HasFD(fd int32) bool {
 	return shared.notifier.hasFD(fd)
 }
 
+// FDs returns the host FD of the epoll instance used to observe FDs, and the
+// list of observed FDs.
+func FDs() (int, []int32) {
+	ensureSharedNotifier()
+	if shared.initErr != nil {
+		return -1, nil
+	}
+	return shared.notifier.epFD, shared.notifier.fds()
+}
+
 // Pause suspends notifications until Resume is called.
 func Pause() {
 	ensureSharedNotifier()
@@ -191,6 +191,20 @@ func (c *Client) watchdog() {
 	c.sockComm.destroy()
 }
 
+// HostFDs returns the host FDs held by c: its main socket, and the sockets
+// used to donate FDs on its channels.
+func (c *Client) HostFDs() []int {
+	fds := []int{c.sockComm.FD()}
+	c.channelsMu.Lock()
+	defer c.channelsMu.Unlock()
+	for _, ch := range c.channels {
+		if fd := ch.fdChan.FD(); fd >= 0 {
+			fds = append(fds, fd)
+		}
+	}
+	return fds
+}
+
 func (c *Client) shutdownActiveChans() {
 	c.channelsMu.Lock()
 	defer c.channelsMu.Unlock()
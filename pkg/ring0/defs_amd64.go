@@ -204,6 +204,14 @@ type SwitchArchOpts struct {
 	//
 	// Per pagetables_x86.go, a zero PCID implies a flush.
 	KernelPCID uint16
+
+	// IBPB indicates that an indirect branch prediction barrier should be
+	// issued before switching, assuming that it is supported.
+	IBPB bool
+
+	// FlushL1D indicates that the L1 data cache should be flushed before
+	// switching, assuming that it is supported.
+	FlushL1D bool
 }
 
 func init() {
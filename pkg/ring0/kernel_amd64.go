@@ -258,6 +258,14 @@ func (c *CPU) SwitchToUser(switchOpts SwitchOpts) (vector Vector) {
 	regs.Cs = uint64(Ucode64) // Required for iret.
 	regs.Ss = uint64(Udata)   // Ditto.
 
+	// Apply speculative execution mitigations.
+	if switchOpts.IBPB && hasIBPB {
+		wrmsr(_MSR_PRED_CMD, _PRED_CMD_IBPB)
+	}
+	if switchOpts.FlushL1D && hasFlushL1D {
+		wrmsr(_MSR_FLUSH_CMD, _FLUSH_CMD_L1D)
+	}
+
 	// Perform the switch.
 	needIRET := uint64(0)
 	if switchOpts.FullRestore {
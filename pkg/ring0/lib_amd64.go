@@ -89,6 +89,8 @@ var (
 	hasXSAVE      bool
 	hasFSGSBASE   bool
 	hasLA57       bool
+	hasIBPB       bool
+	hasFlushL1D   bool
 	validXCR0Mask uintptr
 	localXCR0     uintptr
 )
@@ -116,6 +118,8 @@ func Init(fs cpuid.FeatureSet) {
 	hasXSAVE = fs.UseXsave()
 	hasFSGSBASE = fs.HasFeature(cpuid.X86FeatureFSGSBase)
 	hasLA57 = fs.HasFeature(cpuid.X86FeatureLA57)
+	hasIBPB = fs.HasIBPB()
+	hasFlushL1D = fs.HasFeature(cpuid.X86FeatureFLUSH_L1D)
 	validXCR0Mask = uintptr(fs.ValidXCR0Mask())
 	if hasXSAVE {
 		XCR0DisabledMask := uintptr((1 << 9) | (1 << 17) | (1 << 18))
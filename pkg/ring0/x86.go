@@ -59,9 +59,14 @@ const (
 	_MSR_SYSCALL_MASK  = 0xc0000084
 	_MSR_PLATFORM_INFO = 0xce
 	_MSR_MISC_FEATURES = 0x140
+	_MSR_PRED_CMD      = 0x49
+	_MSR_FLUSH_CMD     = 0x10b
 
 	_PLATFORM_INFO_CPUID_FAULT = 1 << 31
 
+	_PRED_CMD_IBPB = 0x1
+	_FLUSH_CMD_L1D = 0x1
+
 	_MISC_FEATURE_CPUID_TRAP = 0x1
 )
 
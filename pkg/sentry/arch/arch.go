@@ -263,6 +263,14 @@ type MmapLayout struct {
 	// MaxAddr is the highest mappable address.
 	MaxAddr hostarch.Addr
 
+	// MaxHighAddr is the highest mappable address for mappings that
+	// explicitly request addresses above MaxAddr, either with MAP_FIXED or
+	// with a hint address above MaxAddr. This supports large address spaces,
+	// such as those provided by 5-level paging on x86-64, without exposing
+	// high addresses to applications that don't expect them. If MaxHighAddr
+	// <= MaxAddr, no mappings are permitted above MaxAddr.
+	MaxHighAddr hostarch.Addr
+
 	// BottomUpBase is the lowest address that may be returned for a
 	// MmapBottomUp mmap.
 	BottomUpBase hostarch.Addr
@@ -281,6 +289,13 @@ type MmapLayout struct {
 	MaxStackRand uint64
 }
 
+// MaxMappableAddr returns the highest address that may be mapped, including
+// addresses that are only available to mappings that request them
+// explicitly.
+func (m *MmapLayout) MaxMappableAddr() hostarch.Addr {
+	return max(m.MaxAddr, m.MaxHighAddr)
+}
+
 // Valid returns true if this layout is valid.
 func (m *MmapLayout) Valid() bool {
 	if m.MinAddr > m.MaxAddr {
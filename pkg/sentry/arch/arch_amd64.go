@@ -39,6 +39,12 @@ const (
 	// for a 64-bit process.
 	maxAddr64 hostarch.Addr = (1 << 47) - hostarch.PageSize
 
+	// maxHighAddr64 is the maximum userspace address with 5-level paging.
+	// It is TASK_SIZE_MAX in Linux when LA57 is enabled. Addresses above
+	// maxAddr64 are only used by mappings that request them explicitly; see
+	// Documentation/arch/x86/x86_64/5level-paging.rst.
+	maxHighAddr64 hostarch.Addr = (1 << 56) - hostarch.PageSize
+
 	// maxStackRand64 is the maximum randomization to apply to the stack.
 	// It is defined by arch/x86/mm/mmap.c:stack_maxrandom_size in Linux.
 	maxStackRand64 = 16 << 30 // 16 GB
@@ -203,6 +209,11 @@ func (c *Context64) NewMmapLayout(min, max hostarch.Addr, r *limits.LimitSet) (M
 	if !ok {
 		return MmapLayout{}, unix.EINVAL
 	}
+	highMax := max
+	if highMax > maxHighAddr64 {
+		highMax = maxHighAddr64
+	}
+	highMax = highMax.RoundDown()
 	if max > maxAddr64 {
 		max = maxAddr64
 	}
@@ -254,6 +265,9 @@ func (c *Context64) NewMmapLayout(min, max hostarch.Addr, r *limits.LimitSet) (M
 		// randomization to avoiding eating into the gap.
 		MaxStackRand: uint64(maxRand),
 	}
+	if highMax > max {
+		l.MaxHighAddr = highMax
+	}
 
 	// Final sanity check on the layout.
 	if !l.Valid() {
@@ -0,0 +1,148 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package control
+
+import (
+	"fmt"
+)
+
+// The control API is the set of urpc methods served by the sandbox's control
+// server, e.g. "Lifecycle.StartContainer" or "containerManager.Checkpoint".
+// Tools that talk to the sandbox directly (shims, agents) should not rely on
+// method errors to detect what a given runsc release supports. Instead they
+// should call API.Handshake to agree on an API version and API.Capabilities to
+// list the methods that are available, along with their versions.
+//
+// APIVersion is bumped whenever methods are added to, or removed from, the
+// control API. A method's version, in methodVersions, is bumped whenever its
+// arguments, results or semantics change in a way clients must be aware of.
+const (
+	// APIVersion is the current version of the control API.
+	APIVersion = 16
+
+	// MinAPIVersion is the oldest version of the control API that the server
+	// still implements.
+	MinAPIVersion = 1
+)
+
+// defaultMethodVersion is the version of methods not listed in
+// methodVersions.
+const defaultMethodVersion = 1
+
+// methodVersions holds the version of each control method whose version is
+// not defaultMethodVersion, keyed by the method's "Type.Method" name.
+var methodVersions = map[string]int{
+	// Version 2 pauses or resumes only the given container if
+	// ContainerArgs.ContainerID is set.
+	"Lifecycle.Pause":  2,
+	"Lifecycle.Resume": 2,
+}
+
+// API includes RPC stubs for control API version negotiation and capability
+// discovery.
+type API struct {
+	// Methods returns the names of all methods registered with the control
+	// server. It is called on every Capabilities request, so it reflects
+	// handlers registered after API itself.
+	Methods func() []string
+}
+
+// HandshakeArgs are the arguments to API.Handshake.
+type HandshakeArgs struct {
+	// MinVersion is the oldest API version the client supports.
+	MinVersion int `json:"min_version"`
+
+	// MaxVersion is the newest API version the client supports.
+	MaxVersion int `json:"max_version"`
+}
+
+// HandshakeResult is the result of API.Handshake.
+type HandshakeResult struct {
+	// Version is the API version selected for the session. It is the newest
+	// version supported by both the client and the server.
+	Version int `json:"version"`
+}
+
+// Handshake selects the newest API version supported by both the client and
+// the server. It returns an error if the supported ranges don't overlap.
+func (*API) Handshake(args *HandshakeArgs, out *HandshakeResult) error {
+	if args.MinVersion > args.MaxVersion {
+		return fmt.Errorf("invalid client version range [%d, %d]", args.MinVersion, args.MaxVersion)
+	}
+	version := min(args.MaxVersion, APIVersion)
+	if version < args.MinVersion || version < MinAPIVersion {
+		return fmt.Errorf("no common control API version: client supports [%d, %d], server supports [%d, %d]", args.MinVersion, args.MaxVersion, MinAPIVersion, APIVersion)
+	}
+	out.Version = version
+	return nil
+}
+
+// MethodCapability describes a single control method.
+type MethodCapability struct {
+	// Name is the method name, in the form "Type.Method".
+	Name string `json:"name"`
+
+	// Version is the version of the method.
+	Version int `json:"version"`
+}
+
+// CapabilitiesResult is the result of API.Capabilities.
+type CapabilitiesResult struct {
+	// APIVersion is the server's control API version.
+	APIVersion int `json:"api_version"`
+
+	// MinAPIVersion is the oldest control API version the server implements.
+	MinAPIVersion int `json:"min_api_version"`
+
+	// Methods lists all methods served, sorted by name.
+	Methods []MethodCapability `json:"methods"`
+}
+
+// Capabilities lists the control methods served by the sandbox along with
+// their versions.
+func (a *API) Capabilities(_ *struct{}, out *CapabilitiesResult) error {
+	out.APIVersion = APIVersion
+	out.MinAPIVersion = MinAPIVersion
+	out.Methods = nil
+	if a.Methods == nil {
+		return nil
+	}
+	for _, name := range a.Methods() {
+		out.Methods = append(out.Methods, MethodCapability{
+			Name:    name,
+			Version: MethodVersion(name),
+		})
+	}
+	return nil
+}
+
+// MethodVersion returns the version of the given control method.
+func MethodVersion(name string) int {
+	if v, ok := methodVersions[name]; ok {
+		return v
+	}
+	return defaultMethodVersion
+}
+
+// HasMethod returns true if r lists the method with the given name at version
+// minVersion or newer.
+func (r *CapabilitiesResult) HasMethod(name string, minVersion int) bool {
+	for _, m := range r.Methods {
+		if m.Name == name {
+			return m.Version >= minVersion
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package control
+
+import (
+	"testing"
+)
+
+func TestHandshake(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		args    HandshakeArgs
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "exact",
+			args: HandshakeArgs{MinVersion: APIVersion, MaxVersion: APIVersion},
+			want: APIVersion,
+		},
+		{
+			name: "newer client",
+			args: HandshakeArgs{MinVersion: MinAPIVersion, MaxVersion: APIVersion + 10},
+			want: APIVersion,
+		},
+		{
+			name:    "client too new",
+			args:    HandshakeArgs{MinVersion: APIVersion + 1, MaxVersion: APIVersion + 10},
+			wantErr: true,
+		},
+		{
+			name:    "client too old",
+			args:    HandshakeArgs{MinVersion: 0, MaxVersion: MinAPIVersion - 1},
+			wantErr: true,
+		},
+		{
+			name:    "invalid range",
+			args:    HandshakeArgs{MinVersion: 2, MaxVersion: 1},
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var out HandshakeResult
+			err := (&API{}).Handshake(&tc.args, &out)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Handshake(%+v) succeeded with version %d, want error", tc.args, out.Version)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Handshake(%+v): %v", tc.args, err)
+			}
+			if out.Version != tc.want {
+				t.Errorf("Handshake(%+v) = %d, want %d", tc.args, out.Version, tc.want)
+			}
+		})
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	api := &API{
+		Methods: func() []string {
+			return []string{"API.Capabilities", "Lifecycle.StartContainer"}
+		},
+	}
+	var out CapabilitiesResult
+	if err := api.Capabilities(nil, &out); err != nil {
+		t.Fatalf("Capabilities: %v", err)
+	}
+	if out.APIVersion != APIVersion || out.MinAPIVersion != MinAPIVersion {
+		t.Errorf("got versions [%d, %d], want [%d, %d]", out.MinAPIVersion, out.APIVersion, MinAPIVersion, APIVersion)
+	}
+	if !out.HasMethod("Lifecycle.StartContainer", 1) {
+		t.Errorf("Lifecycle.StartContainer missing from %+v", out.Methods)
+	}
+	if out.HasMethod("Lifecycle.StartContainer", defaultMethodVersion+1) {
+		t.Errorf("Lifecycle.StartContainer reported at version > %d", defaultMethodVersion)
+	}
+	if got := MethodVersion("Lifecycle.Pause"); got != 2 {
+		t.Errorf("MethodVersion(Lifecycle.Pause) = %d, want 2", got)
+	}
+	if out.HasMethod("Lifecycle.Unknown", 1) {
+		t.Errorf("unexpected Lifecycle.Unknown in %+v", out.Methods)
+	}
+}
@@ -0,0 +1,439 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package control
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/fd"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/fsimpl/host"
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
+	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+	"gvisor.dev/gvisor/pkg/sync"
+	"gvisor.dev/gvisor/pkg/usermem"
+	"gvisor.dev/gvisor/pkg/waiter"
+)
+
+// maxExecSessionHistory is the amount of recent output of an exec session
+// that is replayed to clients attaching to it.
+const maxExecSessionHistory = 64 * 1024
+
+// ExecSessionKey identifies the exec session of a process by its container
+// and its PID in the container's PID namespace.
+type ExecSessionKey struct {
+	ContainerID string
+	PID         kernel.ThreadID
+}
+
+// ExecSessions tracks the exec sessions of a sandbox. See ExecArgs.Detachable.
+type ExecSessions struct {
+	mu sync.Mutex
+
+	// sessions are the sessions whose pseudo-terminal is still open.
+	//
+	// +checklocks:mu
+	sessions map[ExecSessionKey]*ExecSession
+}
+
+// NewExecSessions returns an ExecSessions without sessions.
+func NewExecSessions() *ExecSessions {
+	return &ExecSessions{sessions: make(map[ExecSessionKey]*ExecSession)}
+}
+
+// Get returns the exec session of a process.
+func (s *ExecSessions) Get(key ExecSessionKey) (*ExecSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[key]
+	if !ok {
+		return nil, fmt.Errorf("no exec session for PID %d in container %q", key.PID, key.ContainerID)
+	}
+	return session, nil
+}
+
+// start registers session as the session of key and starts relaying its
+// output. The session is unregistered once its pseudo-terminal hangs up.
+func (s *ExecSessions) start(key ExecSessionKey, session *ExecSession) {
+	s.mu.Lock()
+	s.sessions[key] = session
+	s.mu.Unlock()
+	go func() { // S/R-SAFE: exec sessions are not restored.
+		session.run()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.sessions[key] == session {
+			delete(s.sessions, key)
+		}
+	}()
+}
+
+// ExecSession holds the pseudo-terminal of an exec'd process, which clients
+// attach to and detach from, like "docker attach", while the process runs.
+// Output written while no client is attached is kept, up to
+// maxExecSessionHistory bytes, and replayed to the next client, instead of
+// the process losing its terminal when its client goes away.
+type ExecSession struct {
+	ctx  context.Context
+	desc string
+
+	// master is the master end of the pseudo-terminal.
+	master *vfs.FileDescription
+
+	// writeMu serializes writes to clients, so that output replayed to a
+	// client isn't interleaved with new output.
+	writeMu sync.Mutex
+
+	mu sync.Mutex
+
+	// history is the most recent output.
+	//
+	// +checklocks:mu
+	history []byte
+
+	// client is the attached client, if any.
+	//
+	// +checklocks:mu
+	client *execSessionClient
+
+	// ended is set once the pseudo-terminal hung up and master is released.
+	//
+	// +checklocks:mu
+	ended bool
+}
+
+// execSessionClient is a host terminal attached to an exec session.
+type execSessionClient struct {
+	// host is the host terminal.
+	host *vfs.FileDescription
+
+	// hostFD is the host file descriptor of host, which is owned by host.
+	hostFD int
+
+	// outCh is notified when host becomes writable.
+	outCh <-chan struct{}
+
+	// done is closed when the client is detached.
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// stop detaches the client.
+func (c *execSessionClient) stop() {
+	c.stopOnce.Do(func() { close(c.done) })
+}
+
+// newExecSession allocates a pseudo-terminal in the devpts of mntns for a new
+// exec session. It returns the replica end of the pseudo-terminal along with
+// the session, which must be started with ExecSessions.start or released.
+func newExecSession(ctx context.Context, k *kernel.Kernel, mntns *vfs.MountNamespace, creds *auth.Credentials, desc string) (*vfs.FileDescription, *ProcessTTY, error) {
+	master, replica, tty, err := openPty(ctx, k, mntns, creds)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := &ExecSession{
+		ctx:    k.SupervisorContext(),
+		desc:   desc,
+		master: master,
+	}
+	return replica, &ProcessTTY{tty: tty, session: s}, nil
+}
+
+// release releases a session that wasn't started.
+func (s *ExecSession) release() {
+	s.master.DecRef(s.ctx)
+}
+
+// run keeps the output of the pseudo-terminal and relays it to the attached
+// client until the pseudo-terminal hangs up.
+func (s *ExecSession) run() {
+	defer s.end()
+
+	e, ch := waiter.NewChannelEntry(waiter.ReadableEvents | waiter.EventHUp | waiter.EventErr)
+	if err := s.master.EventRegister(&e); err != nil {
+		log.Warningf("Error registering for %s events: %v", s.desc, err)
+		return
+	}
+	defer s.master.EventUnregister(&e)
+
+	var buf [4096]byte
+	for {
+		n, err := s.master.Read(s.ctx, usermem.BytesIOSequence(buf[:]), vfs.ReadOptions{})
+		if n > 0 {
+			s.publish(buf[:n])
+		}
+		switch {
+		case err == nil:
+		case linuxerr.Equals(linuxerr.ErrWouldBlock, err):
+			// The master reports a hang up once all replicas are closed and
+			// its output is drained.
+			if s.master.Readiness(waiter.EventHUp)&waiter.EventHUp != 0 {
+				log.Debugf("Finished relaying %s", s.desc)
+				return
+			}
+			<-ch
+		default:
+			log.Debugf("Finished relaying %s: %v", s.desc, err)
+			return
+		}
+	}
+}
+
+// publish adds b to the history and writes it to the attached client. The
+// client is detached if writing fails.
+func (s *ExecSession) publish(b []byte) {
+	s.mu.Lock()
+	s.history = append(s.history, b...)
+	if over := len(s.history) - maxExecSessionHistory; over > 0 {
+		s.history = s.history[over:]
+	}
+	c := s.client
+	s.mu.Unlock()
+	if c == nil {
+		return
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if !writeAll(s.ctx, c.host, b, c.outCh, c.done, s.desc) {
+		c.stop()
+	}
+}
+
+// end detaches the client and releases the pseudo-terminal once it hung up.
+func (s *ExecSession) end() {
+	s.mu.Lock()
+	s.ended = true
+	c := s.client
+	s.mu.Unlock()
+	if c != nil {
+		c.stop()
+	}
+	s.master.DecRef(s.ctx)
+}
+
+// Attach attaches the host terminal hostFD to the session, which must not
+// have a client already. The terminal is put in raw mode and sent the recent
+// output of the session, then it is relayed until the client types
+// detachKeys, the terminal goes away or the session ends. Attach takes
+// ownership of hostFD and returns true if the session is still running when
+// the client is detached.
+func (s *ExecSession) Attach(k *kernel.Kernel, hostFD *fd.FD, detachKeys []byte) (bool, error) {
+	// The host file description is shared with the client, so restore its
+	// flags once done, in particular O_NONBLOCK that is set by host.NewFD.
+	flags, err := unix.FcntlInt(uintptr(hostFD.FD()), unix.F_GETFL, 0)
+	if err != nil {
+		hostFD.Close()
+		return false, fmt.Errorf("getting client terminal flags: %w", err)
+	}
+	hostFile, err := host.NewFD(s.ctx, k.HostMount(), hostFD.FD(), &host.NewFDOptions{})
+	if err != nil {
+		hostFD.Close()
+		return false, fmt.Errorf("importing client terminal: %w", err)
+	}
+	defer hostFile.DecRef(s.ctx)
+	c := &execSessionClient{
+		host:   hostFile,
+		hostFD: hostFD.Release(),
+		done:   make(chan struct{}),
+	}
+	defer c.stop()
+	defer func() {
+		if _, err := unix.FcntlInt(uintptr(c.hostFD), unix.F_SETFL, flags); err != nil {
+			log.Debugf("Failed to restore client terminal flags: %v", err)
+		}
+	}()
+
+	termios, err := setRawMode(c.hostFD)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := unix.IoctlSetTermios(c.hostFD, unix.TCSETS, termios); err != nil {
+			log.Debugf("Failed to restore client terminal attributes: %v", err)
+		}
+	}()
+	out, outCh := waiter.NewChannelEntry(waiter.WritableEvents | waiter.EventHUp | waiter.EventErr)
+	if err := hostFile.EventRegister(&out); err != nil {
+		return false, err
+	}
+	defer hostFile.EventUnregister(&out)
+	c.outCh = outCh
+
+	s.writeMu.Lock()
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		s.writeMu.Unlock()
+		return false, fmt.Errorf("%s has ended", s.desc)
+	}
+	if s.client != nil {
+		s.mu.Unlock()
+		s.writeMu.Unlock()
+		return false, fmt.Errorf("%s already has a client attached", s.desc)
+	}
+	s.client = c
+	// The client writes to master until it is detached, which may happen
+	// after the session ended.
+	s.master.IncRef()
+	history := bytes.Clone(s.history)
+	if err := copyWindowSize(s.master, c.hostFD); err != nil {
+		log.Warningf("Failed to copy the client terminal window size: %v", err)
+	}
+	s.mu.Unlock()
+	log.Infof("Client attached to %s", s.desc)
+	replayed := writeAll(s.ctx, c.host, history, c.outCh, c.done, s.desc)
+	s.writeMu.Unlock()
+
+	detached := replayed && s.relayInput(c, detachKeys)
+	c.stop()
+
+	s.mu.Lock()
+	s.client = nil
+	running := !s.ended
+	s.mu.Unlock()
+	s.master.DecRef(s.ctx)
+	switch {
+	case detached:
+		log.Infof("Client detached from %s", s.desc)
+	case running:
+		log.Infof("Client of %s went away", s.desc)
+	}
+	return running, nil
+}
+
+// relayInput relays the input of client c to the pseudo-terminal. It returns
+// true if the client typed detachKeys.
+func (s *ExecSession) relayInput(c *execSessionClient, detachKeys []byte) bool {
+	in, inCh := waiter.NewChannelEntry(waiter.ReadableEvents | waiter.EventHUp | waiter.EventErr)
+	if err := c.host.EventRegister(&in); err != nil {
+		log.Warningf("Error registering for client events of %s: %v", s.desc, err)
+		return false
+	}
+	defer c.host.EventUnregister(&in)
+	out, outCh := waiter.NewChannelEntry(waiter.WritableEvents | waiter.EventHUp | waiter.EventErr)
+	if err := s.master.EventRegister(&out); err != nil {
+		log.Warningf("Error registering for %s events: %v", s.desc, err)
+		return false
+	}
+	defer s.master.EventUnregister(&out)
+
+	keys := detachKeysMatcher{keys: detachKeys}
+	var buf [4096]byte
+	for {
+		n, err := c.host.Read(s.ctx, usermem.BytesIOSequence(buf[:]), vfs.ReadOptions{})
+		if n > 0 {
+			input, detach := keys.feed(buf[:n])
+			if !writeAll(s.ctx, s.master, input, outCh, c.done, s.desc) {
+				return false
+			}
+			if detach {
+				return true
+			}
+		}
+		switch {
+		case err == nil:
+		case linuxerr.Equals(linuxerr.ErrWouldBlock, err):
+			select {
+			case <-inCh:
+			case <-c.done:
+				return false
+			}
+		default:
+			// EOF or EIO once the other side of the client terminal is closed.
+			log.Debugf("Finished relaying client input of %s: %v", s.desc, err)
+			return false
+		}
+	}
+}
+
+// syncWindowSize copies the window size of the attached client terminal, if
+// any, to the pseudo-terminal.
+func (s *ExecSession) syncWindowSize() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client == nil || s.ended {
+		return nil
+	}
+	return copyWindowSize(s.master, s.client.hostFD)
+}
+
+// detachKeysMatcher finds a sequence of detach keys in client input.
+type detachKeysMatcher struct {
+	keys []byte
+
+	// matched is the number of keys at the end of the input so far that
+	// match the start of keys. They are held back until the sequence is
+	// either complete or broken.
+	matched int
+}
+
+// feed processes the input b. It returns the input to relay, and true if the
+// detach keys were typed, in which case the input following them is dropped.
+func (m *detachKeysMatcher) feed(b []byte) ([]byte, bool) {
+	if len(m.keys) == 0 {
+		return b, false
+	}
+	out := make([]byte, 0, m.matched+len(b))
+	for _, c := range b {
+		if c != m.keys[m.matched] {
+			// Relay the keys that were held back, c may start a new sequence.
+			out = append(out, m.keys[:m.matched]...)
+			m.matched = 0
+			if c != m.keys[0] {
+				out = append(out, c)
+				continue
+			}
+		}
+		m.matched++
+		if m.matched == len(m.keys) {
+			m.matched = 0
+			return out, true
+		}
+	}
+	return out, false
+}
+
+// ParseDetachKeys parses a sequence of detach keys in the format used by
+// Docker: comma-separated keys, each either a single character or "ctrl-<c>"
+// where <c> is a letter or one of '@', '[', '\\', ']', '^' and '_'.
+func ParseDetachKeys(s string) ([]byte, error) {
+	var keys []byte
+	for _, key := range strings.Split(s, ",") {
+		if len(key) == 1 {
+			keys = append(keys, key[0])
+			continue
+		}
+		name, ok := strings.CutPrefix(strings.ToLower(key), "ctrl-")
+		if !ok || len(name) != 1 {
+			return nil, fmt.Errorf("invalid detach key %q", key)
+		}
+		switch c := name[0]; {
+		case c >= 'a' && c <= 'z':
+			keys = append(keys, c-'a'+1)
+		case c == '@' || (c >= '[' && c <= '_'):
+			keys = append(keys, c-'@')
+		default:
+			return nil, fmt.Errorf("invalid detach key %q", key)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package control
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseDetachKeys(t *testing.T) {
+	for _, tc := range []struct {
+		keys string
+		want []byte
+	}{
+		{keys: "ctrl-p,ctrl-q", want: []byte{16, 17}},
+		{keys: "CTRL-A", want: []byte{1}},
+		{keys: "ctrl-@,ctrl-[,ctrl-\\,ctrl-],ctrl-^,ctrl-_", want: []byte{0, 27, 28, 29, 30, 31}},
+		{keys: "a,ctrl-z,-", want: []byte{'a', 26, '-'}},
+	} {
+		got, err := ParseDetachKeys(tc.keys)
+		if err != nil {
+			t.Errorf("ParseDetachKeys(%q) failed: %v", tc.keys, err)
+			continue
+		}
+		if !bytes.Equal(got, tc.want) {
+			t.Errorf("ParseDetachKeys(%q) = %v, want %v", tc.keys, got, tc.want)
+		}
+	}
+
+	for _, keys := range []string{"", "ctrl-", "ctrl-1", "alt-a", "ab", "a,,b"} {
+		if got, err := ParseDetachKeys(keys); err == nil {
+			t.Errorf("ParseDetachKeys(%q) = %v, want error", keys, got)
+		}
+	}
+}
+
+func TestDetachKeysMatcher(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		keys   string
+		inputs []string
+		// want is the relayed input, up to the detach keys if detach is true.
+		want   string
+		detach bool
+	}{
+		{
+			name:   "no keys",
+			inputs: []string{"\x10\x11"},
+			want:   "\x10\x11",
+		},
+		{
+			name:   "no match",
+			keys:   "\x10\x11",
+			inputs: []string{"ls -l\r"},
+			want:   "ls -l\r",
+		},
+		{
+			name:   "match",
+			keys:   "\x10\x11",
+			inputs: []string{"ls\x10\x11dropped"},
+			want:   "ls",
+			detach: true,
+		},
+		{
+			name:   "match across reads",
+			keys:   "\x10\x11",
+			inputs: []string{"ls\x10", "\x11"},
+			want:   "ls",
+			detach: true,
+		},
+		{
+			name:   "broken sequence",
+			keys:   "\x10\x11",
+			inputs: []string{"\x10", "a\x11"},
+			want:   "\x10a\x11",
+		},
+		{
+			name:   "restarted sequence",
+			keys:   "\x10\x11",
+			inputs: []string{"\x10\x10\x11"},
+			want:   "\x10",
+			detach: true,
+		},
+		{
+			name:   "single key",
+			keys:   "\x1d",
+			inputs: []string{"a\x1d"},
+			want:   "a",
+			detach: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := detachKeysMatcher{keys: []byte(tc.keys)}
+			var got []byte
+			detach := false
+			for _, input := range tc.inputs {
+				out, d := m.feed([]byte(input))
+				got = append(got, out...)
+				if d {
+					detach = true
+					break
+				}
+			}
+			if string(got) != tc.want || detach != tc.detach {
+				t.Errorf("relayed %q, detach = %t, want %q, detach = %t", got, detach, tc.want, tc.detach)
+			}
+		})
+	}
+}
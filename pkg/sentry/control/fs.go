@@ -90,6 +90,54 @@ func (f *Fs) TarRootfsUpperLayer(o *TarRootfsUpperLayerOpts, _ *struct{}) error
 	return nil
 }
 
+// RootfsUpperUsageOpts contains options for the RootfsUpperUsage RPC.
+type RootfsUpperUsageOpts struct {
+	// ContainerID identifies which container's rootfs upper layer should be
+	// inspected.
+	ContainerID string `json:"container_id"`
+
+	// Top is the number of largest files to list. If 0, no files are listed.
+	Top int `json:"top"`
+}
+
+// RootfsUpperUsage is the result of the RootfsUpperUsage RPC.
+type RootfsUpperUsage struct {
+	// Usage is the space used by the rootfs upper layer.
+	Usage vfs.UpperLayerUsage `json:"usage"`
+
+	// LargestFiles are the files in the rootfs upper layer that use the most
+	// space, in decreasing order of space used.
+	LargestFiles []vfs.FileUsage `json:"largest_files,omitempty"`
+}
+
+// RootfsUpperUsage is a RPC stub which reports the space used by the rootfs
+// upper layer and the files using the most of it. When the rootfs is not an
+// overlayfs, it returns an error.
+func (f *Fs) RootfsUpperUsage(o *RootfsUpperUsageOpts, out *RootfsUpperUsage) error {
+	ctx := f.Kernel.SupervisorContext()
+	mntns, err := f.mountNamespaceForContainer(o.ContainerID)
+	if err != nil {
+		return err
+	}
+	defer mntns.DecRef(ctx)
+
+	root := mntns.Root(ctx)
+	defer root.DecRef(ctx)
+	ur, ok := root.Mount().Filesystem().Impl().(vfs.UpperLayerUsageReporter)
+	if !ok {
+		return fmt.Errorf("rootfs is not an overlayfs")
+	}
+	if out.Usage, err = ur.UpperLayerUsage(ctx); err != nil {
+		return fmt.Errorf("failed to get rootfs upper layer usage: %v", err)
+	}
+	if o.Top > 0 {
+		if out.LargestFiles, err = ur.UpperLayerLargestFiles(ctx, o.Top); err != nil {
+			return fmt.Errorf("failed to list largest files in rootfs upper layer: %v", err)
+		}
+	}
+	return nil
+}
+
 // CatOpts contains options for the Cat RPC call.
 type CatOpts struct {
 	// Files are the filesystem paths for the files to cat.
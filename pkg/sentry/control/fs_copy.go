@@ -0,0 +1,352 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package control
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/fspath"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+	"gvisor.dev/gvisor/pkg/urpc"
+	"gvisor.dev/gvisor/pkg/usermem"
+)
+
+// CopyOutOpts contains options for the CopyOut RPC.
+type CopyOutOpts struct {
+	// ContainerID identifies which container's filesystem to copy from.
+	ContainerID string `json:"container_id"`
+
+	// Path is the absolute path of the file or directory to copy.
+	Path string `json:"path"`
+
+	// FilePayload contains the destination for the tar archive.
+	urpc.FilePayload
+}
+
+// CopyOut is a RPC stub which writes a tar archive of a file or directory tree
+// of a container to the payload file. Files are read through the sentry VFS,
+// so the archive has the contents seen by the container, including those of
+// overlays and tmpfs mounts. Entries are named relative to the parent of
+// o.Path. Symlinks are archived as is, and special files are skipped.
+func (f *Fs) CopyOut(o *CopyOutOpts, _ *struct{}) error {
+	if len(o.FilePayload.Files) != 1 {
+		return ErrInvalidFiles
+	}
+	output := o.FilePayload.Files[0]
+	defer output.Close()
+
+	p := path.Clean(o.Path)
+	if !path.IsAbs(p) {
+		return fmt.Errorf("path must be absolute: %q", o.Path)
+	}
+
+	ctx := f.Kernel.SupervisorContext()
+	mntns, err := f.mountNamespaceForContainer(o.ContainerID)
+	if err != nil {
+		return err
+	}
+	defer mntns.DecRef(ctx)
+	root := mntns.Root(ctx)
+	defer root.DecRef(ctx)
+
+	c := &vfsCopier{
+		ctx:   ctx,
+		vfs:   f.Kernel.VFS(),
+		creds: auth.NewRootCredentials(f.Kernel.RootUserNamespace()),
+		root:  root,
+		tw:    tar.NewWriter(output),
+	}
+	name := path.Base(p)
+	if name == "/" {
+		name = "."
+	}
+	if err := c.add(p, name); err != nil {
+		return err
+	}
+	return c.tw.Close()
+}
+
+// vfsCopier copies files between a VFS tree and tar archives.
+type vfsCopier struct {
+	ctx   context.Context
+	vfs   *vfs.VirtualFilesystem
+	creds *auth.Credentials
+	root  vfs.VirtualDentry
+
+	// tw is the archive written by add.
+	tw *tar.Writer
+}
+
+func (c *vfsCopier) pathOp(p string) *vfs.PathOperation {
+	return &vfs.PathOperation{
+		Root:  c.root,
+		Start: c.root,
+		Path:  fspath.Parse(p),
+	}
+}
+
+// add archives the file at p, and its children if it's a directory, as name.
+func (c *vfsCopier) add(p, name string) error {
+	stat, err := c.vfs.StatAt(c.ctx, c.creds, c.pathOp(p), &vfs.StatOptions{
+		Mask: linux.STATX_TYPE | linux.STATX_MODE | linux.STATX_UID | linux.STATX_GID | linux.STATX_SIZE | linux.STATX_MTIME,
+	})
+	if err != nil {
+		return fmt.Errorf("stat %q: %w", p, err)
+	}
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    int64(stat.Mode &^ linux.FileTypeMask),
+		Uid:     int(stat.UID),
+		Gid:     int(stat.GID),
+		ModTime: time.Unix(stat.Mtime.Sec, int64(stat.Mtime.Nsec)),
+	}
+
+	switch stat.Mode & linux.FileTypeMask {
+	case linux.ModeDirectory:
+		hdr.Typeflag = tar.TypeDir
+		hdr.Name += "/"
+		if err := c.tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		names, err := c.readDir(p)
+		if err != nil {
+			return err
+		}
+		for _, child := range names {
+			if err := c.add(path.Join(p, child), path.Join(name, child)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case linux.ModeRegular:
+		fd, err := c.vfs.OpenAt(c.ctx, c.creds, c.pathOp(p), &vfs.OpenOptions{
+			Flags: linux.O_RDONLY | linux.O_NOFOLLOW,
+		})
+		if err != nil {
+			return fmt.Errorf("open %q: %w", p, err)
+		}
+		defer fd.DecRef(c.ctx)
+		hdr.Typeflag = tar.TypeReg
+		hdr.Size = int64(stat.Size)
+		if err := c.tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(c.tw, &fdReader{ctx: c.ctx, fd: fd}, hdr.Size); err != nil {
+			return fmt.Errorf("read %q: %w", p, err)
+		}
+		return nil
+
+	case linux.ModeSymlink:
+		target, err := c.vfs.ReadlinkAt(c.ctx, c.creds, c.pathOp(p))
+		if err != nil {
+			return fmt.Errorf("readlink %q: %w", p, err)
+		}
+		hdr.Typeflag = tar.TypeSymlink
+		hdr.Linkname = target
+		return c.tw.WriteHeader(hdr)
+
+	default:
+		log.Warningf("Not copying %q: unsupported file type %#o", p, stat.Mode&linux.FileTypeMask)
+		return nil
+	}
+}
+
+// readDir returns the sorted names of the children of directory p.
+func (c *vfsCopier) readDir(p string) ([]string, error) {
+	fd, err := c.vfs.OpenAt(c.ctx, c.creds, c.pathOp(p), &vfs.OpenOptions{
+		Flags: linux.O_RDONLY | linux.O_DIRECTORY | linux.O_NOFOLLOW,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open %q: %w", p, err)
+	}
+	defer fd.DecRef(c.ctx)
+
+	var names []string
+	if err := fd.IterDirents(c.ctx, vfs.IterDirentsCallbackFunc(func(dirent vfs.Dirent) error {
+		if dirent.Name != "." && dirent.Name != ".." {
+			names = append(names, dirent.Name)
+		}
+		return nil
+	})); err != nil {
+		return nil, fmt.Errorf("read directory %q: %w", p, err)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// CopyInOpts contains options for the CopyIn RPC.
+type CopyInOpts struct {
+	// ContainerID identifies which container's filesystem to copy to.
+	ContainerID string `json:"container_id"`
+
+	// Path is the absolute destination path. If it's an existing directory,
+	// the archive is extracted into it. Otherwise, the top-level entry of the
+	// archive is extracted as Path.
+	Path string `json:"path"`
+
+	// FilePayload contains the source tar archive.
+	urpc.FilePayload
+}
+
+// CopyIn is a RPC stub which extracts a tar archive, as written by CopyOut,
+// from the payload file into a container. Files are written through the
+// sentry VFS, so they are visible to the container even if they are in an
+// overlay or tmpfs mount.
+func (f *Fs) CopyIn(o *CopyInOpts, _ *struct{}) error {
+	if len(o.FilePayload.Files) != 1 {
+		return ErrInvalidFiles
+	}
+	input := o.FilePayload.Files[0]
+	defer input.Close()
+
+	p := path.Clean(o.Path)
+	if !path.IsAbs(p) {
+		return fmt.Errorf("path must be absolute: %q", o.Path)
+	}
+
+	ctx := f.Kernel.SupervisorContext()
+	mntns, err := f.mountNamespaceForContainer(o.ContainerID)
+	if err != nil {
+		return err
+	}
+	defer mntns.DecRef(ctx)
+	root := mntns.Root(ctx)
+	defer root.DecRef(ctx)
+
+	c := &vfsCopier{
+		ctx:   ctx,
+		vfs:   f.Kernel.VFS(),
+		creds: auth.NewRootCredentials(f.Kernel.RootUserNamespace()),
+		root:  root,
+	}
+	dir, rename := p, ""
+	pop := c.pathOp(p)
+	pop.FollowFinalSymlink = true
+	stat, err := c.vfs.StatAt(ctx, c.creds, pop, &vfs.StatOptions{Mask: linux.STATX_TYPE})
+	if err != nil || stat.Mode&linux.FileTypeMask != linux.ModeDirectory {
+		if err != nil && !linuxerr.Equals(linuxerr.ENOENT, err) {
+			return fmt.Errorf("stat %q: %w", p, err)
+		}
+		dir, rename = path.Dir(p), path.Base(p)
+	}
+
+	tr := tar.NewReader(input)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading archive: %w", err)
+		}
+		name, err := CopyEntryName(hdr.Name, rename)
+		if err != nil {
+			return err
+		}
+		if err := c.extract(path.Join(dir, name), hdr, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// extract creates the file described by hdr at p.
+func (c *vfsCopier) extract(p string, hdr *tar.Header, r io.Reader) error {
+	mode := linux.FileMode(hdr.Mode & 07777)
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		if err := c.vfs.MkdirAt(c.ctx, c.creds, c.pathOp(p), &vfs.MkdirOptions{Mode: mode}); err != nil && !linuxerr.Equals(linuxerr.EEXIST, err) {
+			return fmt.Errorf("mkdir %q: %w", p, err)
+		}
+
+	case tar.TypeReg:
+		fd, err := c.vfs.OpenAt(c.ctx, c.creds, c.pathOp(p), &vfs.OpenOptions{
+			Flags: linux.O_WRONLY | linux.O_CREAT | linux.O_TRUNC | linux.O_NOFOLLOW,
+			Mode:  mode,
+		})
+		if err != nil {
+			return fmt.Errorf("open %q: %w", p, err)
+		}
+		_, err = io.Copy(&fdWriter{ctx: c.ctx, fd: fd}, r)
+		fd.DecRef(c.ctx)
+		if err != nil {
+			return fmt.Errorf("write %q: %w", p, err)
+		}
+
+	case tar.TypeSymlink:
+		if err := c.vfs.SymlinkAt(c.ctx, c.creds, c.pathOp(p), hdr.Linkname); err != nil {
+			return fmt.Errorf("symlink %q: %w", p, err)
+		}
+		return nil
+
+	default:
+		log.Warningf("Not copying %q: unsupported tar entry type %q", hdr.Name, hdr.Typeflag)
+		return nil
+	}
+
+	if err := c.vfs.SetStatAt(c.ctx, c.creds, c.pathOp(p), &vfs.SetStatOptions{
+		Stat: linux.Statx{
+			Mask:  linux.STATX_MODE | linux.STATX_UID | linux.STATX_GID | linux.STATX_MTIME,
+			Mode:  uint16(mode),
+			UID:   uint32(hdr.Uid),
+			GID:   uint32(hdr.Gid),
+			Mtime: linux.NsecToStatxTimestamp(hdr.ModTime.UnixNano()),
+		},
+	}); err != nil {
+		return fmt.Errorf("set attributes of %q: %w", p, err)
+	}
+	return nil
+}
+
+// fdWriter provides an io.Writer interface for a vfs.FileDescription.
+type fdWriter struct {
+	ctx context.Context
+	fd  *vfs.FileDescription
+}
+
+// Write implements io.Writer.Write.
+func (f *fdWriter) Write(p []byte) (int, error) {
+	n, err := f.fd.Write(f.ctx, usermem.BytesIOSequence(p), vfs.WriteOptions{})
+	return int(n), err
+}
+
+// CopyEntryName returns the path, relative to the destination directory, at
+// which the tar entry name of a copy is extracted. If rename is not empty, the
+// top-level entry is renamed to it. It returns an error if name escapes the
+// destination directory.
+func CopyEntryName(name, rename string) (string, error) {
+	clean := path.Clean(strings.TrimLeft(name, "/"))
+	if clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid archive entry %q", name)
+	}
+	if rename == "" {
+		return clean, nil
+	}
+	if _, rest, ok := strings.Cut(clean, "/"); ok {
+		return path.Join(rename, rest), nil
+	}
+	return rename, nil
+}
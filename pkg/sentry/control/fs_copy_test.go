@@ -0,0 +1,49 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package control
+
+import "testing"
+
+func TestCopyEntryName(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		rename string
+		want   string
+	}{
+		{name: "dir/", want: "dir"},
+		{name: "dir/a/b", want: "dir/a/b"},
+		{name: "/dir/a", want: "dir/a"},
+		{name: "./dir/a", want: "dir/a"},
+		{name: "dir/", rename: "other", want: "other"},
+		{name: "dir/a/b", rename: "other", want: "other/a/b"},
+		{name: "file", rename: "other", want: "other"},
+		{name: "dir/a/../b", rename: "other", want: "other/b"},
+	} {
+		got, err := CopyEntryName(tc.name, tc.rename)
+		if err != nil {
+			t.Errorf("CopyEntryName(%q, %q) failed: %v", tc.name, tc.rename, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("CopyEntryName(%q, %q) = %q, want %q", tc.name, tc.rename, got, tc.want)
+		}
+	}
+
+	for _, name := range []string{"..", "../a", "dir/../../a"} {
+		if got, err := CopyEntryName(name, ""); err == nil {
+			t.Errorf("CopyEntryName(%q) = %q, want error", name, got)
+		}
+	}
+}
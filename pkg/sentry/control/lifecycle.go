@@ -564,14 +564,22 @@ func (l *Lifecycle) ResumeExternalNetworking(*ContainerArgs, *struct{}) error {
 	return nil
 }
 
-// Pause pauses the container.
-func (l *Lifecycle) Pause(*ContainerArgs, *struct{}) error {
+// Pause pauses the container. If no container ID is given, all containers
+// are paused.
+func (l *Lifecycle) Pause(args *ContainerArgs, _ *struct{}) error {
+	if args != nil && args.ContainerID != "" {
+		return l.Kernel.PauseContainer(args.ContainerID)
+	}
 	l.Kernel.Pause()
 	return nil
 }
 
-// Resume resumes the container.
-func (l *Lifecycle) Resume(*ContainerArgs, *struct{}) error {
+// Resume resumes the container. If no container ID is given, it resumes all
+// containers paused without a container ID.
+func (l *Lifecycle) Resume(args *ContainerArgs, _ *struct{}) error {
+	if args != nil && args.ContainerID != "" {
+		return l.Kernel.UnpauseContainer(args.ContainerID)
+	}
 	l.Kernel.Unpause()
 	return nil
 }
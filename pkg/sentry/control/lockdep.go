@@ -0,0 +1,91 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package control
+
+import (
+	"fmt"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/sync"
+	"gvisor.dev/gvisor/pkg/sync/locking"
+	"gvisor.dev/gvisor/pkg/urpc"
+)
+
+// Lockdep includes RPC stubs to inspect the lock validator. The validator is
+// only available in binaries built with the lockdep tag; otherwise all
+// methods return locking.ErrLockdepDisabled.
+type Lockdep struct {
+	// mu serializes contention profiles.
+	mu sync.Mutex
+}
+
+// LockContentionOpts contains options for lock contention profiles.
+type LockContentionOpts struct {
+	// FilePayload is the destination for the profiling output.
+	urpc.FilePayload
+
+	// Duration is the duration of the profile.
+	Duration time.Duration `json:"duration"`
+}
+
+// Contention records lock contention statistics for the given duration and
+// writes them to the output file, one lock class per line, sorted by
+// decreasing wait time.
+func (l *Lockdep) Contention(o *LockContentionOpts, _ *struct{}) error {
+	if len(o.FilePayload.Files) < 1 {
+		return nil // Allowed.
+	}
+	output := o.FilePayload.Files[0]
+	defer output.Close()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := locking.EnableContentionProfiling(true); err != nil {
+		return err
+	}
+	time.Sleep(o.Duration)
+	stats, err := locking.Contention()
+	locking.EnableContentionProfiling(false)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(output, "%-12s %-12s %-16s %s\n", "ACQUIRED", "CONTENDED", "WAIT", "CLASS"); err != nil {
+		return err
+	}
+	for _, s := range stats {
+		if _, err := fmt.Fprintf(output, "%-12d %-12d %-16v %s\n", s.Acquisitions, s.Contentions, s.Wait, s.Class); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LockGraphOpts contains options for the lock ordering graph.
+type LockGraphOpts struct {
+	// FilePayload is the destination for the graph.
+	urpc.FilePayload
+}
+
+// Graph writes the lock ordering graph observed so far in DOT format.
+func (l *Lockdep) Graph(o *LockGraphOpts, _ *struct{}) error {
+	if len(o.FilePayload.Files) < 1 {
+		return nil // Allowed.
+	}
+	output := o.FilePayload.Files[0]
+	defer output.Close()
+	return locking.WriteDOT(output)
+}
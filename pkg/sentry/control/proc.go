@@ -27,6 +27,7 @@ import (
 	"gvisor.dev/gvisor/pkg/abi/linux"
 	"gvisor.dev/gvisor/pkg/bpf"
 	"gvisor.dev/gvisor/pkg/cleanup"
+	"gvisor.dev/gvisor/pkg/context"
 	"gvisor.dev/gvisor/pkg/fd"
 	"gvisor.dev/gvisor/pkg/log"
 	"gvisor.dev/gvisor/pkg/sentry/fdimport"
@@ -36,6 +37,7 @@ import (
 	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
 	"gvisor.dev/gvisor/pkg/sentry/ktime"
 	"gvisor.dev/gvisor/pkg/sentry/limits"
+	"gvisor.dev/gvisor/pkg/sentry/mm"
 	"gvisor.dev/gvisor/pkg/sentry/usage"
 	"gvisor.dev/gvisor/pkg/sentry/vfs"
 	"gvisor.dev/gvisor/pkg/urpc"
@@ -44,6 +46,10 @@ import (
 // Proc includes task-related functions.
 type Proc struct {
 	Kernel *kernel.Kernel
+
+	// ExecSessions, if not nil, holds the sessions of processes started with
+	// ExecArgs.Detachable.
+	ExecSessions *ExecSessions
 }
 
 // FilePayload aids to ensure that payload files and guest file descriptors are
@@ -134,6 +140,18 @@ type ExecArgs struct {
 	// StdioIsPty indicates that FDs 0, 1, and 2 are connected to a host pty FD.
 	StdioIsPty bool
 
+	// Devpts, if StdioIsPty is set, gives the process a pseudo-terminal of
+	// the sandbox's devpts that is relayed to the host pty, instead of the
+	// host pty itself. This gives each interactive session its own terminal
+	// and job control in the sandbox.
+	Devpts bool
+
+	// Detachable gives the process a pseudo-terminal of the sandbox's devpts
+	// held by an exec session, which clients attach to and detach from with
+	// ExecSession.Attach while the process runs. The file payload must not
+	// contain FDs 0, 1 and 2, which are connected to the pseudo-terminal.
+	Detachable bool
+
 	// SupportTTYs indicates whether TTYs other than the console TTY should be
 	// imported as TTYs.
 	SupportTTYs bool
@@ -187,14 +205,14 @@ func (proc *Proc) Exec(args *ExecArgs, waitStatus *uint32) error {
 
 // ExecAsync runs a new task, but doesn't wait for it to finish. It is defined
 // as a function rather than a method to avoid exposing execAsync as an RPC.
-func ExecAsync(proc *Proc, args *ExecArgs) (*kernel.ThreadGroup, kernel.ThreadID, *host.TTYFileDescription, error) {
+func ExecAsync(proc *Proc, args *ExecArgs) (*kernel.ThreadGroup, kernel.ThreadID, *ProcessTTY, error) {
 	return proc.execAsync(args)
 }
 
 // execAsync runs a new task, but doesn't wait for it to finish. It returns the
-// newly created thread group and its PID. If the stdio FDs are TTYs, then a
-// TTYFileOperations that wraps the TTY is also returned.
-func (proc *Proc) execAsync(args *ExecArgs) (*kernel.ThreadGroup, kernel.ThreadID, *host.TTYFileDescription, error) {
+// newly created thread group and its PID. If the stdio FDs are TTYs, then the
+// controlling terminal of the process is also returned.
+func (proc *Proc) execAsync(args *ExecArgs) (*kernel.ThreadGroup, kernel.ThreadID, *ProcessTTY, error) {
 	creds := auth.NewUserCredentials(
 		args.KUID,
 		args.KGID,
@@ -285,6 +303,23 @@ func (proc *Proc) execAsync(args *ExecArgs) (*kernel.ThreadGroup, kernel.ThreadI
 		initArgs.Filename = resolved
 	}
 
+	if args.Detachable {
+		if proc.ExecSessions == nil {
+			return nil, 0, nil, fmt.Errorf("exec sessions are not supported")
+		}
+		for appFD := range fdMap {
+			if appFD < 3 {
+				return nil, 0, nil, fmt.Errorf("FD %d can't be passed to a detachable process", appFD)
+			}
+		}
+	}
+
+	// The host pty is imported for stdin, see fdimport.Import.
+	hostTTYFD := -1
+	if f, ok := fdMap[0]; ok && args.StdioIsPty && args.Devpts {
+		hostTTYFD = f.FD()
+	}
+
 	opts := fdimport.ImportOptions{
 		Console: args.StdioIsPty,
 		// Exec sessions are not restorable because the caller will not be present after the restore.
@@ -299,8 +334,27 @@ func (proc *Proc) execAsync(args *ExecArgs) (*kernel.ThreadGroup, kernel.ThreadI
 		return nil, 0, nil, err
 	}
 
-	if ttyFile != nil {
-		initArgs.TTY = ttyFile.TTY()
+	processTTY := NewHostProcessTTY(ttyFile)
+	if ttyFile != nil && hostTTYFD >= 0 {
+		processTTY, err = proc.installPtyMux(ctx, fdTable, initArgs.MountNamespace, creds, hostTTYFD)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+	}
+	if args.Detachable {
+		processTTY, err = proc.installExecSession(ctx, fdTable, initArgs.MountNamespace, creds, args.ContainerID)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+	}
+	sessionCu := cleanup.Make(func() {
+		if processTTY != nil && processTTY.session != nil {
+			processTTY.session.release()
+		}
+	})
+	defer sessionCu.Clean()
+	if processTTY != nil {
+		initArgs.TTY = processTTY.TTY()
 	}
 
 	// Set cgroups to the new exec task if cgroups are mounted.
@@ -334,7 +388,56 @@ func (proc *Proc) execAsync(args *ExecArgs) (*kernel.ThreadGroup, kernel.ThreadI
 	// Start the newly created process.
 	proc.Kernel.StartProcess(tg)
 
-	return tg, tid, ttyFile, nil
+	if processTTY != nil && processTTY.session != nil {
+		sessionCu.Release()
+		proc.ExecSessions.start(ExecSessionKey{ContainerID: args.ContainerID, PID: tid}, processTTY.session)
+	}
+	return tg, tid, processTTY, nil
+}
+
+// installPtyMux replaces the host pty of stdio in fdTable with a sentry
+// pseudo-terminal that is relayed to it.
+func (proc *Proc) installPtyMux(ctx context.Context, fdTable *kernel.FDTable, mntns *vfs.MountNamespace, creds *auth.Credentials, hostTTYFD int) (*ProcessTTY, error) {
+	hostFile, _ := fdTable.Get(0)
+	if hostFile == nil {
+		return nil, fmt.Errorf("host pty not found")
+	}
+	replica, processTTY, err := newPtyMux(ctx, proc.Kernel, mntns, creds, hostFile, hostTTYFD)
+	if err != nil {
+		return nil, err
+	}
+	defer replica.DecRef(ctx)
+	for appFD := int32(0); appFD < 3; appFD++ {
+		df, err := fdTable.NewFDAt(ctx, appFD, replica, kernel.FDFlags{})
+		if err != nil {
+			return nil, err
+		}
+		if df != nil {
+			df.DecRef(ctx)
+		}
+	}
+	return processTTY, nil
+}
+
+// installExecSession connects stdio in fdTable to the pseudo-terminal of a new
+// exec session.
+func (proc *Proc) installExecSession(ctx context.Context, fdTable *kernel.FDTable, mntns *vfs.MountNamespace, creds *auth.Credentials, cid string) (*ProcessTTY, error) {
+	replica, processTTY, err := newExecSession(ctx, proc.Kernel, mntns, creds, fmt.Sprintf("exec session in container %q", cid))
+	if err != nil {
+		return nil, err
+	}
+	defer replica.DecRef(ctx)
+	for appFD := int32(0); appFD < 3; appFD++ {
+		df, err := fdTable.NewFDAt(ctx, appFD, replica, kernel.FDFlags{})
+		if err != nil {
+			processTTY.session.release()
+			return nil, err
+		}
+		if df != nil {
+			df.DecRef(ctx)
+		}
+	}
+	return processTTY, nil
 }
 
 // PsArgs is the set of arguments to ps.
@@ -472,6 +575,129 @@ func Processes(k *kernel.Kernel, containerID string, out *[]*Process) error {
 	return nil
 }
 
+// TaskUsage is the resource usage of a task (i.e. thread).
+type TaskUsage struct {
+	// PID is the ID of the task's thread group.
+	PID kernel.ThreadID `json:"pid"`
+	// TID is the ID of the task.
+	TID kernel.ThreadID `json:"tid"`
+	// State is the task's state, as a single letter as in ps(1).
+	State string `json:"state"`
+	// UserTime and SysTime are the CPU time spent by the task.
+	UserTime time.Duration `json:"user_time"`
+	SysTime  time.Duration `json:"sys_time"`
+	// RSS is the resident set size of the task's address space in bytes,
+	// which is shared by the tasks of a thread group.
+	RSS uint64 `json:"rss"`
+	// Cmd is the task's name.
+	Cmd string `json:"cmd"`
+	// CPU is the CPU utilization of the task in percent since the previous
+	// sample, as computed by TaskUsages.ComputeCPU. It isn't set by the
+	// sandbox.
+	CPU float64 `json:"cpu"`
+}
+
+// TaskUsages is a sample of the resource usage of the tasks in a container.
+type TaskUsages struct {
+	// Time is the sandbox's monotonic time when the sample was taken, in
+	// nanoseconds.
+	Time int64 `json:"time"`
+	// Tasks are the tasks, sorted by PID and then TID.
+	Tasks []*TaskUsage `json:"tasks"`
+}
+
+// TaskUsageStats retrieves the resource usage of the tasks running in the
+// sandbox with the given container id. All tasks are returned if
+// 'containerID' is empty.
+func TaskUsageStats(k *kernel.Kernel, containerID string, out *TaskUsages) error {
+	pidns := k.TaskSet().Root
+	out.Time = k.MonotonicClock().Now().Nanoseconds()
+	out.Tasks = nil
+	for _, t := range pidns.Tasks() {
+		tid := pidns.IDOfTask(t)
+		// If t has already been reaped ignore it.
+		if tid == 0 {
+			continue
+		}
+		if containerID != "" && containerID != t.ContainerID() {
+			continue
+		}
+		var m *mm.MemoryManager
+		t.WithMuLocked(func(t *kernel.Task) {
+			m = t.MemoryManager()
+		})
+		var rss uint64
+		if m != nil {
+			rss = m.ResidentSetSize()
+		}
+		stats := t.CPUStats()
+		out.Tasks = append(out.Tasks, &TaskUsage{
+			PID:      pidns.IDOfThreadGroup(t.ThreadGroup()),
+			TID:      tid,
+			State:    t.StateStatus()[:1],
+			UserTime: stats.UserTime,
+			SysTime:  stats.SysTime,
+			RSS:      rss,
+			Cmd:      t.Name(),
+		})
+	}
+	sort.Slice(out.Tasks, func(i, j int) bool {
+		if out.Tasks[i].PID != out.Tasks[j].PID {
+			return out.Tasks[i].PID < out.Tasks[j].PID
+		}
+		return out.Tasks[i].TID < out.Tasks[j].TID
+	})
+	return nil
+}
+
+// ComputeCPU sets the CPU utilization of the tasks in u from the CPU time they
+// spent since prev. Tasks that aren't in prev started after it was taken, so
+// all of their CPU time counts.
+func (u *TaskUsages) ComputeCPU(prev *TaskUsages) {
+	elapsed := time.Duration(u.Time - prev.Time)
+	prevTasks := make(map[kernel.ThreadID]*TaskUsage, len(prev.Tasks))
+	for _, t := range prev.Tasks {
+		prevTasks[t.TID] = t
+	}
+	for _, t := range u.Tasks {
+		t.CPU = 0
+		if elapsed <= 0 {
+			continue
+		}
+		cpu := t.UserTime + t.SysTime
+		if p, ok := prevTasks[t.TID]; ok {
+			cpu -= p.UserTime + p.SysTime
+		}
+		t.CPU = max(float64(cpu)*100/float64(elapsed), 0)
+	}
+}
+
+// TaskUsagesToTable prints a table with the following format, with the tasks
+// sorted by decreasing CPU utilization:
+// PID       TID       S   %CPU     TIME        RSS       CMD
+// 1         1         R   98.5     12.345s     2048      busy
+//
+// RSS is in KiB.
+func TaskUsagesToTable(u *TaskUsages) string {
+	tasks := append([]*TaskUsage(nil), u.Tasks...)
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].CPU > tasks[j].CPU })
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 10, 1, 3, ' ', 0)
+	fmt.Fprint(tw, "PID\tTID\tS\t%CPU\tTIME\tRSS\tCMD")
+	for _, t := range tasks {
+		fmt.Fprintf(tw, "\n%d\t%d\t%s\t%.1f\t%s\t%d\t%s",
+			t.PID,
+			t.TID,
+			t.State,
+			t.CPU,
+			(t.UserTime + t.SysTime).Round(time.Millisecond),
+			t.RSS/1024,
+			t.Cmd)
+	}
+	tw.Flush()
+	return buf.String()
+}
+
 // formatStartTime formats startTime depending on the current time:
 //   - If startTime was today, HH:MM is used.
 //   - If startTime was not today but was this year, MonDD is used (e.g. Jan02)
@@ -166,3 +166,42 @@ func TestPercentCPU(t *testing.T) {
 		}
 	}
 }
+
+func TestTaskUsagesComputeCPU(t *testing.T) {
+	prev := &TaskUsages{
+		Time: 1e9,
+		Tasks: []*TaskUsage{
+			{PID: 1, TID: 1, UserTime: 1e9, SysTime: 1e9},
+			{PID: 1, TID: 2, UserTime: 5e8},
+			{PID: 3, TID: 3, UserTime: 1e9},
+		},
+	}
+	cur := &TaskUsages{
+		Time: 3e9,
+		Tasks: []*TaskUsage{
+			// 1s of CPU time in 2s.
+			{PID: 1, TID: 1, UserTime: 15e8, SysTime: 15e8},
+			// No CPU time.
+			{PID: 1, TID: 2, UserTime: 5e8},
+			// New task: all of its CPU time counts.
+			{PID: 4, TID: 4, UserTime: 2e9, SysTime: 1e9},
+		},
+	}
+	cur.ComputeCPU(prev)
+	for i, want := range []float64{50, 0, 150} {
+		if got := cur.Tasks[i].CPU; got != want {
+			t.Errorf("task %d: CPU = %v, want %v", cur.Tasks[i].TID, got, want)
+		}
+	}
+
+	want := "PID       TID       S         %CPU      TIME      RSS       CMD\n" +
+		"4         4         R         150.0     3s        4         new\n" +
+		"1         1         S         50.0      3s        0         app\n" +
+		"1         2         S         0.0       500ms     0         app"
+	cur.Tasks[0].State, cur.Tasks[0].Cmd = "S", "app"
+	cur.Tasks[1].State, cur.Tasks[1].Cmd = "S", "app"
+	cur.Tasks[2].State, cur.Tasks[2].Cmd, cur.Tasks[2].RSS = "R", "new", 4096
+	if got := TaskUsagesToTable(cur); got != want {
+		t.Errorf("TaskUsagesToTable() =\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package control
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/cleanup"
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/fspath"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/fsimpl/devpts"
+	"gvisor.dev/gvisor/pkg/sentry/fsimpl/host"
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
+	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+	"gvisor.dev/gvisor/pkg/usermem"
+	"gvisor.dev/gvisor/pkg/waiter"
+)
+
+// ProcessTTY is the controlling terminal of a process started by ExecAsync.
+type ProcessTTY struct {
+	tty *kernel.TTY
+
+	// mux is set if tty is a sentry pseudo-terminal relayed to a host
+	// terminal. See ExecArgs.Devpts.
+	mux *ptyMux
+
+	// session is set if tty is the pseudo-terminal of an exec session. See
+	// ExecArgs.Detachable.
+	session *ExecSession
+}
+
+// NewHostProcessTTY returns the ProcessTTY of a host terminal, or nil if f is
+// nil.
+func NewHostProcessTTY(f *host.TTYFileDescription) *ProcessTTY {
+	if f == nil {
+		return nil
+	}
+	return &ProcessTTY{tty: f.TTY()}
+}
+
+// TTY returns the controlling terminal.
+func (p *ProcessTTY) TTY() *kernel.TTY {
+	return p.tty
+}
+
+// SyncWindowSize copies the window size of the host terminal to the sentry
+// pseudo-terminal, which signals SIGWINCH to its foreground process group if
+// the size changed. It returns false if the process uses the host terminal
+// directly, in which case there is nothing to copy.
+func (p *ProcessTTY) SyncWindowSize() (bool, error) {
+	switch {
+	case p.mux != nil:
+		return true, p.mux.syncWindowSize()
+	case p.session != nil:
+		return true, p.session.syncWindowSize()
+	default:
+		return false, nil
+	}
+}
+
+// ptyMux relays a host terminal to the master end of a sentry
+// pseudo-terminal, whose replica end is given to an exec'd process. Each
+// interactive session then has a terminal of its own in the sandbox, with the
+// sentry line discipline providing job control, instead of all sessions
+// sharing host terminals that the sentry doesn't know apart.
+//
+// The host terminal is put in raw mode while relayed, since input and output
+// are processed by the sentry terminal.
+type ptyMux struct {
+	ctx context.Context
+
+	// host is the host terminal.
+	host *vfs.FileDescription
+
+	// hostFD is the host file descriptor of host, which is owned by host.
+	hostFD int
+
+	// hostTermios is the termios of the host terminal before it was put in
+	// raw mode.
+	hostTermios *unix.Termios
+
+	// master is the master end of the sentry pseudo-terminal.
+	master *vfs.FileDescription
+
+	// done is closed when relaying stops.
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// newPtyMux allocates a pseudo-terminal in the devpts of mntns and starts
+// relaying it to the host terminal hostFile, whose host file descriptor is
+// hostFD. It returns the replica end of the pseudo-terminal along with the
+// mux. newPtyMux takes ownership of a reference on hostFile.
+func newPtyMux(ctx context.Context, k *kernel.Kernel, mntns *vfs.MountNamespace, creds *auth.Credentials, hostFile *vfs.FileDescription, hostFD int) (*vfs.FileDescription, *ProcessTTY, error) {
+	hostCu := cleanup.Make(func() { hostFile.DecRef(ctx) })
+	defer hostCu.Clean()
+
+	master, replica, tty, err := openPty(ctx, k, mntns, creds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m := &ptyMux{
+		ctx:    k.SupervisorContext(),
+		host:   hostFile,
+		hostFD: hostFD,
+		master: master,
+		done:   make(chan struct{}),
+	}
+	if err := m.syncWindowSize(); err != nil {
+		log.Warningf("Failed to copy the host terminal window size: %v", err)
+	}
+	if m.hostTermios, err = setRawMode(hostFD); err != nil {
+		replica.DecRef(ctx)
+		master.DecRef(ctx)
+		return nil, nil, err
+	}
+
+	hostCu.Release()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { // S/R-SAFE: exec sessions are not restored.
+		defer wg.Done()
+		m.relay(m.master, m.host, "host terminal input")
+	}()
+	go func() { // S/R-SAFE: exec sessions are not restored.
+		defer wg.Done()
+		m.relay(m.host, m.master, "pseudo-terminal output")
+	}()
+	go func() { // S/R-SAFE: exec sessions are not restored.
+		wg.Wait()
+		m.release()
+	}()
+	return replica, &ProcessTTY{tty: tty, mux: m}, nil
+}
+
+// openPty allocates a pseudo-terminal in the devpts of mntns. It returns the
+// master end, opened non-blocking, and the replica end with its terminal.
+func openPty(ctx context.Context, k *kernel.Kernel, mntns *vfs.MountNamespace, creds *auth.Credentials) (master, replica *vfs.FileDescription, tty *kernel.TTY, err error) {
+	root := mntns.Root(ctx)
+	defer root.DecRef(ctx)
+	master, err = k.VFS().OpenAt(ctx, creds, &vfs.PathOperation{
+		Root:               root,
+		Start:              root,
+		Path:               fspath.Parse("/dev/ptmx"),
+		FollowFinalSymlink: true,
+	}, &vfs.OpenOptions{Flags: linux.O_RDWR | linux.O_NOCTTY | linux.O_NONBLOCK})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("opening /dev/ptmx: %w", err)
+	}
+	replica, tty, err = devpts.OpenPeer(ctx, creds, master, linux.O_RDWR|linux.O_NOCTTY)
+	if err != nil {
+		master.DecRef(ctx)
+		return nil, nil, nil, fmt.Errorf("opening pseudo-terminal replica: %w", err)
+	}
+	return master, replica, tty, nil
+}
+
+// setRawMode puts the host terminal hostFD in raw mode. It returns the
+// previous termios, which the caller must restore.
+func setRawMode(hostFD int) (*unix.Termios, error) {
+	termios, err := unix.IoctlGetTermios(hostFD, unix.TCGETS)
+	if err != nil {
+		return nil, fmt.Errorf("getting host terminal attributes: %w", err)
+	}
+	raw := *termios
+	makeRaw(&raw)
+	if err := unix.IoctlSetTermios(hostFD, unix.TCSETS, &raw); err != nil {
+		return nil, fmt.Errorf("setting host terminal to raw mode: %w", err)
+	}
+	return termios, nil
+}
+
+// copyWindowSize copies the window size of the host terminal hostFD to the
+// pseudo-terminal master.
+func copyWindowSize(master *vfs.FileDescription, hostFD int) error {
+	ws, err := unix.IoctlGetWinsize(hostFD, unix.TIOCGWINSZ)
+	if err != nil {
+		return err
+	}
+	return devpts.SetWindowSize(master, linux.Winsize{
+		Row:    ws.Row,
+		Col:    ws.Col,
+		Xpixel: ws.Xpixel,
+		Ypixel: ws.Ypixel,
+	})
+}
+
+// makeRaw sets t to raw mode, like cfmakeraw(3).
+func makeRaw(t *unix.Termios) {
+	t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	t.Oflag &^= unix.OPOST
+	t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	t.Cflag &^= unix.CSIZE | unix.PARENB
+	t.Cflag |= unix.CS8
+	t.Cc[unix.VMIN] = 1
+	t.Cc[unix.VTIME] = 0
+}
+
+// syncWindowSize copies the window size of the host terminal to the
+// pseudo-terminal.
+func (m *ptyMux) syncWindowSize() error {
+	return copyWindowSize(m.master, m.hostFD)
+}
+
+// relay copies src to dst until either end goes away.
+func (m *ptyMux) relay(dst, src *vfs.FileDescription, desc string) {
+	defer m.stop()
+
+	in, inCh := waiter.NewChannelEntry(waiter.ReadableEvents | waiter.EventHUp | waiter.EventErr)
+	if err := src.EventRegister(&in); err != nil {
+		log.Warningf("Error registering for %s events: %v", desc, err)
+		return
+	}
+	defer src.EventUnregister(&in)
+	out, outCh := waiter.NewChannelEntry(waiter.WritableEvents | waiter.EventHUp | waiter.EventErr)
+	if err := dst.EventRegister(&out); err != nil {
+		log.Warningf("Error registering for %s events: %v", desc, err)
+		return
+	}
+	defer dst.EventUnregister(&out)
+
+	var buf [4096]byte
+	for {
+		n, err := src.Read(m.ctx, usermem.BytesIOSequence(buf[:]), vfs.ReadOptions{})
+		if n > 0 && !writeAll(m.ctx, dst, buf[:n], outCh, m.done, desc) {
+			return
+		}
+		switch {
+		case err == nil:
+		case linuxerr.Equals(linuxerr.ErrWouldBlock, err):
+			// The pseudo-terminal master reports a hang up once all replicas
+			// are closed and its output is drained.
+			if src.Readiness(waiter.EventHUp)&waiter.EventHUp != 0 {
+				log.Debugf("Finished relaying %s", desc)
+				return
+			}
+			select {
+			case <-inCh:
+			case <-m.done:
+				return
+			}
+		default:
+			// EOF or EIO once the other side of the host terminal is closed.
+			log.Debugf("Finished relaying %s: %v", desc, err)
+			return
+		}
+	}
+}
+
+// writeAll writes b to dst, waiting on outCh for dst to be writable. It
+// returns false if relaying must stop, either because writing failed or
+// because done was closed.
+func writeAll(ctx context.Context, dst *vfs.FileDescription, b []byte, outCh, done <-chan struct{}, desc string) bool {
+	for len(b) > 0 {
+		n, err := dst.Write(ctx, usermem.BytesIOSequence(b), vfs.WriteOptions{})
+		b = b[n:]
+		switch {
+		case err == nil:
+		case linuxerr.Equals(linuxerr.ErrWouldBlock, err):
+			select {
+			case <-outCh:
+			case <-done:
+				return false
+			}
+		default:
+			log.Debugf("Error writing %s: %v", desc, err)
+			return false
+		}
+	}
+	return true
+}
+
+// stop makes both directions stop relaying.
+func (m *ptyMux) stop() {
+	m.stopOnce.Do(func() { close(m.done) })
+}
+
+// release restores the host terminal and closes both ends once relaying has
+// stopped. Closing the master hangs up the session of the pseudo-terminal.
+func (m *ptyMux) release() {
+	if err := unix.IoctlSetTermios(m.hostFD, unix.TCSETS, m.hostTermios); err != nil {
+		log.Debugf("Failed to restore host terminal attributes: %v", err)
+	}
+	m.master.DecRef(m.ctx)
+	m.host.DecRef(m.ctx)
+}
@@ -0,0 +1,29 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package control
+
+import (
+	"gvisor.dev/gvisor/pkg/sentry/runtimestats"
+)
+
+// Runtime includes RPC stubs to retrieve Go runtime statistics of the sentry.
+type Runtime struct{}
+
+// Stats sets `out` to the current Go runtime statistics, e.g. goroutine
+// count, heap usage, GC pauses and scheduler latencies.
+func (*Runtime) Stats(_ *struct{}, out *runtimestats.Stats) error {
+	*out = runtimestats.Read()
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package guestapi implements the guest API device, /dev/gvisor, through which
+// trusted agents in the sandbox request sandbox services.
+package guestapi
+
+import (
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/abi/sentry"
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/sentry/arch"
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
+	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+	"gvisor.dev/gvisor/pkg/usermem"
+)
+
+// guestAPIDevice implements vfs.Device for /dev/gvisor.
+//
+// +stateify savable
+type guestAPIDevice struct{}
+
+// Open implements vfs.Device.Open.
+func (guestAPIDevice) Open(ctx context.Context, mnt *vfs.Mount, vfsd *vfs.Dentry, opts vfs.OpenOptions) (*vfs.FileDescription, error) {
+	// The device may have been registered before a restore with the guest
+	// API disabled.
+	if k := kernel.KernelFromContext(ctx); k == nil || k.GuestAPIHandler() == nil {
+		return nil, linuxerr.ENXIO
+	}
+	fd := &guestAPIFD{}
+	if err := fd.vfsfd.Init(fd, opts.Flags, auth.CredentialsFromContext(ctx), mnt, vfsd, &vfs.FileDescriptionOptions{
+		UseDentryMetadata: true,
+	}); err != nil {
+		return nil, err
+	}
+	return &fd.vfsfd, nil
+}
+
+// guestAPIFD implements vfs.FileDescriptionImpl for /dev/gvisor.
+//
+// +stateify savable
+type guestAPIFD struct {
+	vfsfd vfs.FileDescription
+	vfs.FileDescriptionDefaultImpl
+	vfs.DentryMetadataFileDescriptionImpl
+	vfs.NoLockFD
+}
+
+// Release implements vfs.FileDescriptionImpl.Release.
+func (fd *guestAPIFD) Release(context.Context) {}
+
+// Ioctl implements vfs.FileDescriptionImpl.Ioctl.
+func (fd *guestAPIFD) Ioctl(ctx context.Context, uio usermem.IO, sysno uintptr, args arch.SyscallArguments) (uintptr, error) {
+	t := kernel.TaskFromContext(ctx)
+	if t == nil {
+		panic("Ioctl should be called from a task context")
+	}
+	k := t.Kernel()
+	h := k.GuestAPIHandler()
+	if h == nil {
+		return 0, linuxerr.ENXIO
+	}
+
+	request := args[1].Uint()
+	if request == sentry.GVISOR_IOC_VERSION {
+		var buf [sentry.GuestAPIVersionLen]byte
+		// Keep the terminating NUL.
+		copy(buf[:len(buf)-1], h.GuestAPIVersion())
+		_, err := t.CopyOutBytes(args[2].Pointer(), buf[:])
+		return 0, err
+	}
+
+	// Other requests affect the whole sandbox.
+	if !t.Credentials().HasCapabilityIn(linux.CAP_SYS_ADMIN, k.RootUserNamespace()) {
+		return 0, linuxerr.EPERM
+	}
+	switch request {
+	case sentry.GVISOR_IOC_FLUSH_CACHES:
+		n, err := k.MemoryFile().Reclaim(0)
+		if err != nil {
+			return 0, err
+		}
+		ctx.Infof("Guest API: flushed caches of container %q, %d bytes returned to the host", t.ContainerID(), n)
+		return 0, nil
+
+	case sentry.GVISOR_IOC_READY:
+		ctx.Infof("Guest API: container %q is ready", t.ContainerID())
+		h.GuestReady(t.ContainerID())
+		return 0, nil
+
+	case sentry.GVISOR_IOC_CHECKPOINT:
+		saver := k.Saver()
+		if saver == nil {
+			return 0, linuxerr.ENXIO
+		}
+		ctx.Infof("Guest API: checkpoint requested by container %q", t.ContainerID())
+		return 0, saver.SaveAsync()
+
+	default:
+		return 0, linuxerr.ENOTTY
+	}
+}
+
+// Register registers the guest API device in vfsObj.
+func Register(vfsObj *vfs.VirtualFilesystem) error {
+	major, err := vfsObj.GetDynamicCharDevMajor()
+	if err != nil {
+		return err
+	}
+	return vfsObj.RegisterDevice(vfs.CharDevice, major, 0, guestAPIDevice{}, &vfs.RegisterDeviceOptions{
+		GroupName: "gvisor",
+		Pathname:  "gvisor",
+		FilePerms: 0600,
+	})
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hostdev implements a generic proxy for host character devices that
+// are listed in the container spec but have no device-specific implementation
+// in the sentry.
+//
+// Reads and writes are forwarded to the host device. ioctls are forwarded only
+// if they are in the device's allowlist; see allowedIoctls. Unlike a denylist,
+// which would forward any ioctl not known to be dangerous, this means that
+// devices without allowlisted ioctls only support reads and writes: the
+// sentry can only forward an ioctl whose argument layout it knows, since the
+// argument must be copied between application memory and the host driver,
+// and seccomp filters must name each forwarded request.
+package hostdev
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/devutil"
+	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/fdnotifier"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+	"gvisor.dev/gvisor/pkg/sync"
+)
+
+const deviceGroupName = "hostdev"
+
+var (
+	// deviceMajor is the dynamically-allocated major device number shared by
+	// all proxied host devices. Host device numbers can't be used directly
+	// since they may collide with devices implemented by the sentry (e.g.
+	// /dev/fuse).
+	deviceMajor        uint32
+	deviceMajorInit    sync.Once
+	deviceMajorInitErr error
+
+	// minorsMu protects minors.
+	minorsMu sync.Mutex
+
+	// minors maps the path of each registered device, relative to /dev, to its
+	// minor device number.
+	minors = make(map[string]uint32)
+)
+
+const (
+	// hostFDBase and maxHostFDs delimit the range of host FD numbers that
+	// proxied devices are moved into when opened, so that seccomp filters can
+	// allow ioctls on them only. The sentry allocates the lowest available FD
+	// numbers, so its other FDs don't reach this range in practice.
+	hostFDBase = 1 << 18
+	maxHostFDs = 1 << 10
+
+	// MinFDLimit is the RLIMIT_NOFILE that the sentry needs to be able to
+	// open as many proxied devices as possible. Opening a proxied device
+	// fails if RLIMIT_NOFILE is lower than hostFDBase.
+	MinFDLimit = hostFDBase + maxHostFDs
+)
+
+// hostDevice implements vfs.Device for a proxied host character device.
+//
+// +stateify savable
+type hostDevice struct {
+	// relpath is the path of the device relative to /dev. Immutable.
+	relpath string
+}
+
+// Open implements vfs.Device.Open.
+func (dev *hostDevice) Open(ctx context.Context, mnt *vfs.Mount, vfsd *vfs.Dentry, opts vfs.OpenOptions) (*vfs.FileDescription, error) {
+	devClient := devutil.GoferClientFromContext(ctx)
+	if devClient == nil {
+		log.Warningf("devutil.CtxDevGoferClient is not set")
+		return nil, linuxerr.ENOENT
+	}
+	openedFD, err := devClient.OpenAt(ctx, dev.relpath, opts.Flags)
+	if err != nil {
+		ctx.Warningf("hostDevice: failed to open device %s: %v", dev.relpath, err)
+		return nil, err
+	}
+	// Move the host FD into the range that seccomp filters allow ioctls on.
+	hostFD, err := unix.FcntlInt(uintptr(openedFD), unix.F_DUPFD_CLOEXEC, hostFDBase)
+	unix.Close(openedFD)
+	if err != nil {
+		ctx.Warningf("hostDevice: failed to move FD of device %s to %d or above, RLIMIT_NOFILE may be too low: %v", dev.relpath, hostFDBase, err)
+		return nil, linuxerr.EMFILE
+	}
+	if hostFD >= hostFDBase+maxHostFDs {
+		unix.Close(hostFD)
+		ctx.Warningf("hostDevice: too many proxied host devices open")
+		return nil, linuxerr.ENFILE
+	}
+	// Blocking is implemented by the sentry using fdnotifier, regardless of
+	// O_NONBLOCK.
+	if err := unix.SetNonblock(hostFD, true); err != nil {
+		unix.Close(hostFD)
+		return nil, err
+	}
+	fd := &hostdevFD{
+		hostFD: int32(hostFD),
+		ioctls: allowedIoctls[dev.relpath],
+	}
+	if err := fd.vfsfd.Init(fd, opts.Flags, auth.CredentialsFromContext(ctx), mnt, vfsd, &vfs.FileDescriptionOptions{
+		UseDentryMetadata: true,
+	}); err != nil {
+		unix.Close(hostFD)
+		return nil, err
+	}
+	if err := fdnotifier.AddFD(int32(hostFD), &fd.queue); err != nil {
+		unix.Close(hostFD)
+		return nil, err
+	}
+	return &fd.vfsfd, nil
+}
+
+// Register registers a proxy for the host character device at devPath, which
+// must be in /dev, and returns the device numbers that the sandbox should use
+// for it. Registering the same path more than once returns the same device
+// numbers.
+func Register(vfsObj *vfs.VirtualFilesystem, devPath string) (major, minor uint32, err error) {
+	relpath, ok := strings.CutPrefix(path.Clean(devPath), "/dev/")
+	if !ok {
+		return 0, 0, fmt.Errorf("host device path %q is not in /dev", devPath)
+	}
+	deviceMajorInit.Do(func() {
+		deviceMajor, deviceMajorInitErr = vfsObj.GetDynamicCharDevMajor()
+	})
+	if deviceMajorInitErr != nil {
+		return 0, 0, deviceMajorInitErr
+	}
+
+	minorsMu.Lock()
+	defer minorsMu.Unlock()
+	if minor, ok := minors[relpath]; ok {
+		return deviceMajor, minor, nil
+	}
+	minor = uint32(len(minors))
+	if err := vfsObj.RegisterDevice(vfs.CharDevice, deviceMajor, minor, &hostDevice{
+		relpath: relpath,
+	}, &vfs.RegisterDeviceOptions{
+		GroupName: deviceGroupName,
+	}); err != nil {
+		return 0, 0, err
+	}
+	minors[relpath] = minor
+	return deviceMajor, minor, nil
+}
+
+// hostIoctl describes an ioctl that may be forwarded to a host device.
+type hostIoctl struct {
+	// size is the size of the argument that the host driver copies in or out.
+	// It may differ from the size encoded in the request number, e.g.
+	// TUNSETIFF encodes sizeof(int) but copies a struct ifreq.
+	size uint32
+
+	// dir is the combination of linux.IOC_READ and linux.IOC_WRITE that
+	// describes how the host driver accesses the argument. It may differ
+	// from the direction encoded in the request number, e.g. TUNSETIFF
+	// writes the interface name back.
+	dir uint32
+}
+
+// ifreqSize is the size of struct ifreq.
+var ifreqSize = uint32((*linux.IFReq)(nil).SizeBytes())
+
+// allowedIoctls maps the path of each device, relative to /dev, to the ioctls
+// that may be forwarded to it, keyed by request number. Each of them has been
+// vetted to take a pointer to an argument of a fixed size that contains no
+// pointers and no file descriptors, so that it can be copied through a buffer
+// in the sentry. No ioctls are forwarded to devices that aren't listed.
+//
+// When adding an ioctl here, also check that the host driver doesn't resolve
+// anything in the calling process, i.e. the sentry.
+var allowedIoctls = map[string]map[uint32]hostIoctl{
+	"net/tun": {
+		linux.TUNSETIFF:       {size: ifreqSize, dir: linux.IOC_READ | linux.IOC_WRITE},
+		linux.TUNGETIFF:       {size: ifreqSize, dir: linux.IOC_READ},
+		linux.TUNGETFEATURES:  {size: 4, dir: linux.IOC_READ},
+		linux.TUNGETVNETHDRSZ: {size: 4, dir: linux.IOC_READ},
+		linux.TUNSETVNETHDRSZ: {size: 4, dir: linux.IOC_WRITE},
+	},
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostdev
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/fdnotifier"
+	"gvisor.dev/gvisor/pkg/sentry/arch"
+	"gvisor.dev/gvisor/pkg/sentry/hostfd"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+	"gvisor.dev/gvisor/pkg/usermem"
+	"gvisor.dev/gvisor/pkg/waiter"
+)
+
+// hostdevFD implements vfs.FileDescriptionImpl for a proxied host character
+// device.
+//
+// hostdevFD is not savable; the state of host devices can't be saved.
+type hostdevFD struct {
+	vfsfd vfs.FileDescription
+	vfs.FileDescriptionDefaultImpl
+	vfs.DentryMetadataFileDescriptionImpl
+	vfs.NoLockFD
+
+	// hostFD is the host FD, in [hostFDBase, hostFDBase+maxHostFDs).
+	hostFD int32
+	queue  waiter.Queue
+
+	// ioctls are the ioctls that may be forwarded to the device. Immutable.
+	ioctls map[uint32]hostIoctl
+}
+
+// Release implements vfs.FileDescriptionImpl.Release.
+func (fd *hostdevFD) Release(context.Context) {
+	fdnotifier.RemoveFD(fd.hostFD)
+	unix.Close(int(fd.hostFD))
+}
+
+// HostFD implements vfs.HostFDProvider.HostFD. This allows a FUSE filesystem
+// to be served by a daemon on the host through a proxied /dev/fuse.
+func (fd *hostdevFD) HostFD() int {
+	return int(fd.hostFD)
+}
+
+// EventRegister implements waiter.Waitable.EventRegister.
+func (fd *hostdevFD) EventRegister(e *waiter.Entry) error {
+	fd.queue.EventRegister(e)
+	if err := fdnotifier.UpdateFD(fd.hostFD); err != nil {
+		fd.queue.EventUnregister(e)
+		return err
+	}
+	return nil
+}
+
+// EventUnregister implements waiter.Waitable.EventUnregister.
+func (fd *hostdevFD) EventUnregister(e *waiter.Entry) {
+	fd.queue.EventUnregister(e)
+	if err := fdnotifier.UpdateFD(fd.hostFD); err != nil {
+		panic(fmt.Sprint("UpdateFD:", err))
+	}
+}
+
+// Readiness implements waiter.Waitable.Readiness.
+func (fd *hostdevFD) Readiness(mask waiter.EventMask) waiter.EventMask {
+	return fdnotifier.NonBlockingPoll(fd.hostFD, mask)
+}
+
+// Epollable implements vfs.FileDescriptionImpl.Epollable.
+func (fd *hostdevFD) Epollable() bool {
+	return true
+}
+
+// Read implements vfs.FileDescriptionImpl.Read.
+func (fd *hostdevFD) Read(ctx context.Context, dst usermem.IOSequence, opts vfs.ReadOptions) (int64, error) {
+	if opts.Flags != 0 {
+		return 0, linuxerr.EOPNOTSUPP
+	}
+	reader := hostfd.GetReadWriterAt(fd.hostFD, -1 /* offset */, 0 /* flags */)
+	n, err := dst.CopyOutFrom(ctx, reader)
+	hostfd.PutReadWriterAt(reader)
+	if isBlockError(err) {
+		err = linuxerr.ErrWouldBlock
+	}
+	return n, err
+}
+
+// Write implements vfs.FileDescriptionImpl.Write.
+func (fd *hostdevFD) Write(ctx context.Context, src usermem.IOSequence, opts vfs.WriteOptions) (int64, error) {
+	if opts.Flags != 0 {
+		return 0, linuxerr.EOPNOTSUPP
+	}
+	writer := hostfd.GetReadWriterAt(fd.hostFD, -1 /* offset */, 0 /* flags */)
+	n, err := src.CopyInTo(ctx, writer)
+	hostfd.PutReadWriterAt(writer)
+	if isBlockError(err) {
+		err = linuxerr.ErrWouldBlock
+	}
+	return n, err
+}
+
+// Ioctl implements vfs.FileDescriptionImpl.Ioctl.
+func (fd *hostdevFD) Ioctl(ctx context.Context, uio usermem.IO, sysno uintptr, args arch.SyscallArguments) (uintptr, error) {
+	cmd := args[1].Uint()
+	ioc, ok := fd.ioctls[cmd]
+	if !ok {
+		ctx.Debugf("hostdev: refusing to forward ioctl %#x", cmd)
+		return 0, linuxerr.ENOTTY
+	}
+	argPtr := args[2].Pointer()
+	dir := ioc.dir
+	buf := make([]byte, ioc.size)
+	if dir&linux.IOC_WRITE != 0 {
+		if _, err := uio.CopyIn(ctx, argPtr, buf, usermem.IOOpts{}); err != nil {
+			return 0, err
+		}
+	}
+	n, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd.hostFD), uintptr(cmd), uintptr(unsafe.Pointer(&buf[0])))
+	if errno != 0 {
+		return 0, errno
+	}
+	if dir&linux.IOC_READ != 0 {
+		if _, err := uio.CopyOut(ctx, argPtr, buf, usermem.IOOpts{}); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+func isBlockError(err error) bool {
+	return linuxerr.Equals(linuxerr.EAGAIN, err) || linuxerr.Equals(linuxerr.EWOULDBLOCK, err)
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostdev
+
+import (
+	"testing"
+
+	"gvisor.dev/gvisor/pkg/abi/linux"
+)
+
+func TestAllowedIoctls(t *testing.T) {
+	for path, ioctls := range allowedIoctls {
+		for cmd, ioc := range ioctls {
+			if ioc.size == 0 {
+				t.Errorf("%s: ioctl %#x has no argument size", path, cmd)
+			}
+			if ioc.dir == linux.IOC_NONE || ioc.dir&^(linux.IOC_READ|linux.IOC_WRITE) != 0 {
+				t.Errorf("%s: ioctl %#x has invalid direction %#x", path, cmd, ioc.dir)
+			}
+		}
+	}
+}
+
+func TestDeniedIoctls(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		path string
+		cmd  uint32
+	}{
+		// Takes a file descriptor.
+		{name: "FUSE_DEV_IOC_CLONE", path: "fuse", cmd: linux.FUSE_DEV_IOC_CLONE},
+		// Take structures containing pointers or file descriptors.
+		{name: "TUNATTACHFILTER", path: "net/tun", cmd: linux.TUNATTACHFILTER},
+		{name: "TUNSETSTEERINGEBPF", path: "net/tun", cmd: linux.TUNSETSTEERINGEBPF},
+		{name: "TUNSETFILTEREBPF", path: "net/tun", cmd: linux.TUNSETFILTEREBPF},
+		// Takes its argument by value.
+		{name: "TUNSETPERSIST", path: "net/tun", cmd: linux.TUNSETPERSIST},
+		// Unknown devices get no ioctls.
+		{name: "unknown device", path: "foo", cmd: linux.IOWR('x', 1, 64)},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if _, ok := allowedIoctls[test.path][test.cmd]; ok {
+				t.Errorf("ioctl %#x is allowed for %s", test.cmd, test.path)
+			}
+		})
+	}
+}
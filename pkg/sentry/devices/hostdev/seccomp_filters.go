@@ -0,0 +1,56 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostdev
+
+import (
+	"sort"
+
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/seccomp"
+)
+
+// Filters returns seccomp-bpf filters for this package.
+func Filters() seccomp.SyscallRules {
+	// Only allowlisted ioctls are forwarded, and only to host FDs in the
+	// range that proxied devices are moved into.
+	var cmds []uint32
+	seen := make(map[uint32]struct{})
+	for _, ioctls := range allowedIoctls {
+		for cmd := range ioctls {
+			if _, ok := seen[cmd]; !ok {
+				seen[cmd] = struct{}{}
+				cmds = append(cmds, cmd)
+			}
+		}
+	}
+	// Sort the commands so that the rules are deterministic, as required to
+	// precompile them.
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i] < cmds[j] })
+	var ioctlRules []seccomp.SyscallRule
+	for _, cmd := range cmds {
+		ioctlRules = append(ioctlRules, seccomp.And{
+			seccomp.PerArg{
+				seccomp.GreaterThanOrEqual(hostFDBase),
+				seccomp.EqualTo(cmd),
+			},
+			seccomp.PerArg{
+				seccomp.LessThan(hostFDBase + maxHostFDs),
+			},
+		})
+	}
+	return seccomp.MakeSyscallRules(map[uintptr]seccomp.SyscallRule{
+		unix.SYS_IOCTL: seccomp.Or(ioctlRules),
+	})
+}
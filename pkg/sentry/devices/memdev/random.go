@@ -17,8 +17,8 @@ package memdev
 import (
 	"gvisor.dev/gvisor/pkg/atomicbitops"
 	"gvisor.dev/gvisor/pkg/context"
-	"gvisor.dev/gvisor/pkg/rand"
 	"gvisor.dev/gvisor/pkg/safemem"
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
 	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
 	"gvisor.dev/gvisor/pkg/sentry/vfs"
 	"gvisor.dev/gvisor/pkg/usermem"
@@ -67,12 +67,12 @@ func (fd *randomFD) Release(context.Context) {
 
 // PRead implements vfs.FileDescriptionImpl.PRead.
 func (fd *randomFD) PRead(ctx context.Context, dst usermem.IOSequence, offset int64, opts vfs.ReadOptions) (int64, error) {
-	return dst.CopyOutFrom(ctx, safemem.FromIOReader{rand.Reader})
+	return dst.CopyOutFrom(ctx, safemem.FromIOReader{kernel.KernelFromContext(ctx).EntropyPool()})
 }
 
 // Read implements vfs.FileDescriptionImpl.Read.
 func (fd *randomFD) Read(ctx context.Context, dst usermem.IOSequence, opts vfs.ReadOptions) (int64, error) {
-	n, err := dst.CopyOutFrom(ctx, safemem.FromIOReader{rand.Reader})
+	n, err := dst.CopyOutFrom(ctx, safemem.FromIOReader{kernel.KernelFromContext(ctx).EntropyPool()})
 	fd.off.Add(n)
 	return n, err
 }
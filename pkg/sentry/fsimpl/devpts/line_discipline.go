@@ -230,17 +230,24 @@ func (l *lineDiscipline) windowSize(t *kernel.Task, args arch.SyscallArguments)
 }
 
 func (l *lineDiscipline) setWindowSize(t *kernel.Task, args arch.SyscallArguments) error {
+	var size linux.Winsize
+	if _, err := size.CopyIn(t, args[2].Pointer()); err != nil {
+		return err
+	}
+	l.storeWindowSize(size)
+	return nil
+}
+
+// storeWindowSize sets the terminal size, signaling SIGWINCH to the
+// foreground process group of the replica if it changed.
+func (l *lineDiscipline) storeWindowSize(size linux.Winsize) {
 	l.sizeMu.Lock()
 	defer l.sizeMu.Unlock()
 	oldSize := l.size
-	_, err := l.size.CopyIn(t, args[2].Pointer())
-	if err != nil {
-		return err
-	}
+	l.size = size
 	if oldSize != l.size {
 		l.terminal.replicaKTTY.SignalForegroundProcessGroup(kernel.SignalInfoPriv(linux.SIGWINCH))
 	}
-	return nil
 }
 
 func (l *lineDiscipline) masterReadiness() waiter.EventMask {
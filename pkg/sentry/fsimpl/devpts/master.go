@@ -235,32 +235,7 @@ func (mfd *masterFileDescription) Ioctl(ctx context.Context, io usermem.IO, sysn
 		return 0, err
 	case linux.TIOCGPTPEER:
 		flags := args[2].Uint()
-		n := mfd.t.n
-		masterMnt := mfd.vfsfd.Mount()
-
-		// devpts files should always be in kernfs mounts on gVisor, but better safe than sorry.
-		if _, ok := masterMnt.Root().Impl().(*kernfs.Dentry); !ok {
-			return 0, linuxerr.EINVAL
-		}
-
-		// Lookup the replica's dentry.
-		// Since devpts can't be written to, userspace shouldn't be able to replace the file underneath us.
-		rootVD := vfs.MakeVirtualDentry(masterMnt, masterMnt.Root())
-		pop := vfs.PathOperation{
-			Root:  rootVD,
-			Start: rootVD,
-			Path:  fspath.Parse(strconv.FormatUint(uint64(n), 10)),
-		}
-		// We use a combination of GetDentryAt() and OpenTTY(), rather than OpenAt(), to avoid
-		// DAC permission checks, which TIOCGPTPEER exists in part to skip.
-		replicaD, err := t.Kernel().VFS().GetDentryAt(t, t.Credentials(), &pop, &vfs.GetDentryOptions{})
-		if err != nil {
-			return 0, err
-		}
-		defer replicaD.DecRef(t)
-		replica, err := mfd.t.OpenTTY(t, masterMnt, replicaD.Dentry(), vfs.OpenOptions{
-			Flags: flags & ^uint32(linux.O_CLOEXEC),
-		})
+		replica, err := mfd.openPeer(t, t.Credentials(), flags & ^uint32(linux.O_CLOEXEC))
 		if err != nil {
 			return 0, err
 		}
@@ -279,6 +254,60 @@ func (mfd *masterFileDescription) Ioctl(ctx context.Context, io usermem.IO, sysn
 	}
 }
 
+// openPeer opens the replica end of the terminal.
+func (mfd *masterFileDescription) openPeer(ctx context.Context, creds *auth.Credentials, flags uint32) (*vfs.FileDescription, error) {
+	masterMnt := mfd.vfsfd.Mount()
+
+	// devpts files should always be in kernfs mounts on gVisor, but better safe than sorry.
+	if _, ok := masterMnt.Root().Impl().(*kernfs.Dentry); !ok {
+		return nil, linuxerr.EINVAL
+	}
+
+	// Lookup the replica's dentry.
+	// Since devpts can't be written to, userspace shouldn't be able to replace the file underneath us.
+	rootVD := vfs.MakeVirtualDentry(masterMnt, masterMnt.Root())
+	pop := vfs.PathOperation{
+		Root:  rootVD,
+		Start: rootVD,
+		Path:  fspath.Parse(strconv.FormatUint(uint64(mfd.t.n), 10)),
+	}
+	// We use a combination of GetDentryAt() and OpenTTY(), rather than OpenAt(), to avoid
+	// DAC permission checks, which TIOCGPTPEER exists in part to skip.
+	replicaD, err := masterMnt.Filesystem().VirtualFilesystem().GetDentryAt(ctx, creds, &pop, &vfs.GetDentryOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer replicaD.DecRef(ctx)
+	return mfd.t.OpenTTY(ctx, masterMnt, replicaD.Dentry(), vfs.OpenOptions{Flags: flags})
+}
+
+// OpenPeer opens the replica end of the terminal whose master end is master,
+// like ioctl(TIOCGPTPEER), and returns it along with the replica's TTY. Unlike
+// the ioctl, it may be called without a task, in which case the replica does
+// not become anyone's controlling terminal.
+func OpenPeer(ctx context.Context, creds *auth.Credentials, master *vfs.FileDescription, flags uint32) (*vfs.FileDescription, *kernel.TTY, error) {
+	mfd, ok := master.Impl().(*masterFileDescription)
+	if !ok {
+		return nil, nil, linuxerr.ENOTTY
+	}
+	replica, err := mfd.openPeer(ctx, creds, flags)
+	if err != nil {
+		return nil, nil, err
+	}
+	return replica, mfd.t.replicaKTTY, nil
+}
+
+// SetWindowSize sets the window size of the terminal whose master end is
+// master, like ioctl(TIOCSWINSZ), but without a task.
+func SetWindowSize(master *vfs.FileDescription, size linux.Winsize) error {
+	mfd, ok := master.Impl().(*masterFileDescription)
+	if !ok {
+		return linuxerr.ENOTTY
+	}
+	mfd.t.ld.storeWindowSize(size)
+	return nil
+}
+
 // SetStat implements vfs.FileDescriptionImpl.SetStat.
 func (mfd *masterFileDescription) SetStat(ctx context.Context, opts vfs.SetStatOptions) error {
 	creds := auth.CredentialsFromContext(ctx)
@@ -19,6 +19,7 @@ import (
 	"gvisor.dev/gvisor/pkg/context"
 	"gvisor.dev/gvisor/pkg/errors/linuxerr"
 	"gvisor.dev/gvisor/pkg/sentry/kernel"
+	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
 	"gvisor.dev/gvisor/pkg/sentry/vfs"
 )
 
@@ -50,9 +51,12 @@ var _ kernel.TTYOperations = (*Terminal)(nil)
 
 // OpenTTY implements kernel.TTYOperations.OpenTTY.
 func (t *Terminal) OpenTTY(ctx context.Context, mnt *vfs.Mount, vfsd *vfs.Dentry, opts vfs.OpenOptions) (*vfs.FileDescription, error) {
+	// The sentry itself opens replicas for processes that it starts, see
+	// OpenPeer. There is no controlling terminal to set in that case.
 	tsk := kernel.TaskFromContext(ctx)
-	if tsk == nil {
-		return nil, linuxerr.EIO
+	creds := auth.CredentialsFromContext(ctx)
+	if tsk != nil {
+		creds = tsk.Credentials()
 	}
 	t.root.mu.Lock()
 	ri, ok := t.root.replicas[t.replicaKTTY.Index()]
@@ -64,10 +68,10 @@ func (t *Terminal) OpenTTY(ctx context.Context, mnt *vfs.Mount, vfsd *vfs.Dentry
 		inode: ri,
 	}
 	fd.LockFD.Init(&ri.locks)
-	if err := fd.vfsfd.Init(fd, opts.Flags, tsk.Credentials(), mnt, vfsd, &vfs.FileDescriptionOptions{}); err != nil {
+	if err := fd.vfsfd.Init(fd, opts.Flags, creds, mnt, vfsd, &vfs.FileDescriptionOptions{}); err != nil {
 		return nil, err
 	}
-	if opts.Flags&linux.O_NOCTTY == 0 {
+	if tsk != nil && opts.Flags&linux.O_NOCTTY == 0 {
 		// Opening a replica sets the process' controlling TTY when
 		// possible. An error indicates it cannot be set, and is
 		// ignored silently. See Linux tty_open().
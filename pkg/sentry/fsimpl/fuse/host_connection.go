@@ -19,16 +19,51 @@ import (
 	"gvisor.dev/gvisor/pkg/abi/linux"
 	"gvisor.dev/gvisor/pkg/context"
 	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/hostarch"
 	"gvisor.dev/gvisor/pkg/log"
 	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
 	"gvisor.dev/gvisor/pkg/sync"
 )
 
-var respBufPool = sync.Pool{
-	New: func() any {
-		b := make([]byte, linux.FUSE_MIN_READ_BUFFER)
-		return &b
-	},
+// hostOpcodes is the set of requests that may be forwarded to a FUSE server
+// on the host. It is limited to the operations implemented by the sentry's
+// FUSE client, so that the sandbox cannot exercise other parts of the
+// protocol (e.g. FUSE_IOCTL) in a server that runs outside of it.
+var hostOpcodes = map[linux.FUSEOpcode]struct{}{
+	linux.FUSE_LOOKUP:       {},
+	linux.FUSE_FORGET:       {},
+	linux.FUSE_GETATTR:      {},
+	linux.FUSE_SETATTR:      {},
+	linux.FUSE_READLINK:     {},
+	linux.FUSE_SYMLINK:      {},
+	linux.FUSE_MKNOD:        {},
+	linux.FUSE_MKDIR:        {},
+	linux.FUSE_UNLINK:       {},
+	linux.FUSE_RMDIR:        {},
+	linux.FUSE_RENAME:       {},
+	linux.FUSE_LINK:         {},
+	linux.FUSE_OPEN:         {},
+	linux.FUSE_READ:         {},
+	linux.FUSE_WRITE:        {},
+	linux.FUSE_STATFS:       {},
+	linux.FUSE_RELEASE:      {},
+	linux.FUSE_FSYNC:        {},
+	linux.FUSE_SETXATTR:     {},
+	linux.FUSE_GETXATTR:     {},
+	linux.FUSE_LISTXATTR:    {},
+	linux.FUSE_REMOVEXATTR:  {},
+	linux.FUSE_FLUSH:        {},
+	linux.FUSE_INIT:         {},
+	linux.FUSE_OPENDIR:      {},
+	linux.FUSE_READDIR:      {},
+	linux.FUSE_RELEASEDIR:   {},
+	linux.FUSE_FSYNCDIR:     {},
+	linux.FUSE_ACCESS:       {},
+	linux.FUSE_CREATE:       {},
+	linux.FUSE_INTERRUPT:    {},
+	linux.FUSE_DESTROY:      {},
+	linux.FUSE_BATCH_FORGET: {},
+	linux.FUSE_FALLOCATE:    {},
 }
 
 // hostConnection implements fuseConn for the host FD passthrough path.
@@ -48,20 +83,51 @@ type hostConnection struct {
 
 	// writeMu serializes write operations on hostFD.
 	writeMu sync.Mutex
+
+	// opcodes is the set of opcodes that may be sent to the server. Requests
+	// with any other opcode fail with ENOSYS without reaching the server.
+	// Immutable.
+	opcodes map[linux.FUSEOpcode]struct{}
+
+	// respBufSize is the size of the buffer needed to read any response from
+	// the server. It is set by startReader from the limits negotiated in
+	// FUSE_INIT.
+	respBufSize int
+
+	// respBufPool holds buffers of respBufSize bytes for readLoop.
+	respBufPool sync.Pool
 }
 
 // newHostConnection creates a hostConnection that communicates over hostFD.
 func newHostConnection(conn *connection, hostFD int32) *hostConnection {
 	return &hostConnection{
-		conn:   conn,
-		hostFD: hostFD,
+		conn:    conn,
+		hostFD:  hostFD,
+		opcodes: hostOpcodes,
+	}
+}
+
+// responseBufferSize returns the size of the largest response the server may
+// send, given the limits negotiated in FUSE_INIT. The largest responses are
+// replies to FUSE_READ, which are bounded by both maxRead and maxPages (see
+// fileDescription.readLocked).
+func (hc *hostConnection) responseBufferSize() int {
+	maxData := uint64(hc.conn.maxPages) << hostarch.PageShift
+	if maxRead := uint64(hc.conn.maxRead); maxRead < maxData {
+		maxData = maxRead
 	}
+	return max(int(linux.FUSE_MIN_READ_BUFFER), int(linux.SizeOfFUSEHeaderOut)+int(maxData))
 }
 
 // startReader launches the background goroutine that reads responses from the
 // host FD and dispatches them to waiting callers. Must be called after the
 // FUSE_INIT handshake completes.
 func (hc *hostConnection) startReader() {
+	hc.respBufSize = hc.responseBufferSize()
+	hc.respBufPool.New = func() any {
+		b := make([]byte, hc.respBufSize)
+		return &b
+	}
 	go hc.readLoop()
 }
 
@@ -69,17 +135,17 @@ func (hc *hostConnection) startReader() {
 // corresponding callers via the connection's completions map.
 func (hc *hostConnection) readLoop() {
 	for {
-		bufp := respBufPool.Get().(*[]byte)
-		respBuf := (*bufp)[:linux.FUSE_MIN_READ_BUFFER]
+		bufp := hc.respBufPool.Get().(*[]byte)
+		respBuf := *bufp
 
 		n, err := unix.Read(int(hc.hostFD), respBuf)
 		if err != nil || n == 0 {
-			respBufPool.Put(bufp)
+			hc.respBufPool.Put(bufp)
 			hc.abortPending()
 			return
 		}
 		if n < int(linux.SizeOfFUSEHeaderOut) {
-			respBufPool.Put(bufp)
+			hc.respBufPool.Put(bufp)
 			log.Warningf("fuse host connection: short read %d bytes, need at least %d", n, linux.SizeOfFUSEHeaderOut)
 			continue
 		}
@@ -88,7 +154,7 @@ func (hc *hostConnection) readLoop() {
 		hdr.UnmarshalUnsafe(respBuf[:linux.SizeOfFUSEHeaderOut])
 
 		if hdr.Len > uint32(n) {
-			respBufPool.Put(bufp)
+			hc.respBufPool.Put(bufp)
 			log.Warningf("fuse host connection: response says %d bytes but only read %d", hdr.Len, n)
 			continue
 		}
@@ -98,8 +164,12 @@ func (hc *hostConnection) readLoop() {
 		if ok {
 			delete(hc.conn.completions, hdr.Unique)
 			fut.hdr = &hdr
-			copy(fut.buf[:], respBuf[:hdr.Len])
-			fut.data = fut.buf[:hdr.Len]
+			if int(hdr.Len) <= len(fut.buf) {
+				copy(fut.buf[:], respBuf[:hdr.Len])
+				fut.data = fut.buf[:hdr.Len]
+			} else {
+				fut.data = append([]byte(nil), respBuf[:hdr.Len]...)
+			}
 			select {
 			case hc.conn.fullQueueCh <- struct{}{}:
 			default:
@@ -108,7 +178,13 @@ func (hc *hostConnection) readLoop() {
 			close(fut.ch)
 		}
 		hc.conn.mu.Unlock()
-		respBufPool.Put(bufp)
+		if !ok {
+			// Notifications (unique 0) are not supported, and replies to
+			// unknown requests are unexpected; in both cases the message is
+			// dropped rather than being allowed to wake an unrelated caller.
+			log.Debugf("fuse host connection: dropping message with unique %d, error %d", hdr.Unique, hdr.Error)
+		}
+		hc.respBufPool.Put(bufp)
 	}
 }
 
@@ -128,6 +204,11 @@ func (hc *hostConnection) abortPending() {
 // request to the host FD, and blocks until the reader goroutine dispatches
 // the matching response.
 func (hc *hostConnection) call(ctx context.Context, r *Request) (*Response, error) {
+	if _, ok := hc.opcodes[r.hdr.Opcode]; !ok {
+		log.Debugf("fuse host connection: refusing to forward request with opcode %v", r.hdr.Opcode)
+		return nil, linuxerr.ENOSYS
+	}
+
 	hc.conn.mu.Lock()
 	if !hc.conn.connected {
 		hc.conn.mu.Unlock()
@@ -31,6 +31,13 @@ import (
 // The connection is pre-initialized and the reader goroutine is started.
 func newTestHostConnection(t *testing.T) (*hostConnection, int, func()) {
 	t.Helper()
+	return newTestHostConnectionMaxRead(t, 4096)
+}
+
+// newTestHostConnectionMaxRead is like newTestHostConnection, but uses the
+// given max_read mount option.
+func newTestHostConnectionMaxRead(t *testing.T, maxRead uint32) (*hostConnection, int, func()) {
+	t.Helper()
 
 	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_SEQPACKET, 0)
 	if err != nil {
@@ -39,7 +46,7 @@ func newTestHostConnection(t *testing.T) (*hostConnection, int, func()) {
 
 	fsopts := filesystemOptions{
 		maxActiveRequests: maxActiveRequestsDefault,
-		maxRead:           4096,
+		maxRead:           maxRead,
 	}
 	conn, err := newFUSEConnectionOpts(&fsopts)
 	if err != nil {
@@ -55,6 +62,7 @@ func newTestHostConnection(t *testing.T) (*hostConnection, int, func()) {
 	conn.mu.Unlock()
 
 	hc := newHostConnection(conn, int32(fds[0]))
+	hc.opcodes = map[linux.FUSEOpcode]struct{}{echoTestOpcode: {}}
 	hc.startReader()
 
 	cleanup := func() {
@@ -385,3 +393,106 @@ func TestHostConnectionNotConnected(t *testing.T) {
 		t.Fatalf("expected ENOTCONN, got %v", err)
 	}
 }
+
+func TestHostConnectionFilteredOpcode(t *testing.T) {
+	s := setup(t)
+	defer s.Destroy()
+
+	hc, serverFD, cleanup := newTestHostConnection(t)
+	defer cleanup()
+
+	creds := auth.CredentialsFromContext(s.Ctx)
+	testObj := primitive.Uint32(0)
+	req := hc.conn.NewRequest(creds, 1, 1, linux.FUSE_IOCTL, &testObj)
+
+	if _, err := hc.Call(s.Ctx, req); !linuxerr.Equals(linuxerr.ENOSYS, err) {
+		t.Fatalf("expected ENOSYS, got %v", err)
+	}
+
+	// The request must not have reached the server.
+	buf := make([]byte, linux.FUSE_MIN_READ_BUFFER)
+	if n, _, err := unix.Recvfrom(serverFD, buf, unix.MSG_DONTWAIT); err != unix.EAGAIN {
+		t.Fatalf("server Recvfrom: got (%d, %v), want EAGAIN", n, err)
+	}
+
+	hc.conn.mu.Lock()
+	defer hc.conn.mu.Unlock()
+	if hc.conn.numActiveRequests != 0 {
+		t.Errorf("numActiveRequests: got %d, want 0", hc.conn.numActiveRequests)
+	}
+}
+
+func TestHostOpcodes(t *testing.T) {
+	for _, op := range []linux.FUSEOpcode{linux.FUSE_INIT, linux.FUSE_LOOKUP, linux.FUSE_READ, linux.FUSE_WRITE, linux.FUSE_READDIR} {
+		if _, ok := hostOpcodes[op]; !ok {
+			t.Errorf("opcode %d is not forwarded to host servers", op)
+		}
+	}
+	for _, op := range []linux.FUSEOpcode{linux.FUSE_IOCTL, linux.FUSE_POLL, linux.FUSE_NOTIFY_REPLY, linux.FUSE_BMAP} {
+		if _, ok := hostOpcodes[op]; ok {
+			t.Errorf("opcode %d is forwarded to host servers", op)
+		}
+	}
+}
+
+func TestHostConnectionLargeResponse(t *testing.T) {
+	s := setup(t)
+	defer s.Destroy()
+
+	const maxRead = 64 * 1024
+	hc, serverFD, cleanup := newTestHostConnectionMaxRead(t, maxRead)
+	defer cleanup()
+
+	if want := int(linux.SizeOfFUSEHeaderOut) + maxRead; hc.respBufSize != want {
+		t.Fatalf("respBufSize: got %d, want %d", hc.respBufSize, want)
+	}
+
+	// Reply with a payload that doesn't fit in futureResponse.buf, as the
+	// server would for a large FUSE_READ.
+	payload := make([]byte, maxRead)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, linux.FUSE_MIN_READ_BUFFER)
+		n, err := unix.Read(serverFD, buf)
+		if err != nil || n < int(linux.SizeOfFUSEHeaderIn) {
+			t.Errorf("server Read: got (%d, %v)", n, err)
+			return
+		}
+		var reqHdr linux.FUSEHeaderIn
+		reqHdr.UnmarshalUnsafe(buf[:linux.SizeOfFUSEHeaderIn])
+
+		respBuf := make([]byte, int(linux.SizeOfFUSEHeaderOut)+len(payload))
+		respHdr := linux.FUSEHeaderOut{
+			Len:    uint32(len(respBuf)),
+			Unique: reqHdr.Unique,
+		}
+		respHdr.MarshalUnsafe(respBuf[:linux.SizeOfFUSEHeaderOut])
+		copy(respBuf[linux.SizeOfFUSEHeaderOut:], payload)
+		if _, err := unix.Write(serverFD, respBuf); err != nil {
+			t.Errorf("server Write: %v", err)
+		}
+	}()
+
+	creds := auth.CredentialsFromContext(s.Ctx)
+	testObj := primitive.Uint32(0)
+	req := hc.conn.NewRequest(creds, 1, 1, echoTestOpcode, &testObj)
+	resp, err := hc.Call(s.Ctx, req)
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	<-done
+
+	got := resp.data[linux.SizeOfFUSEHeaderOut:]
+	if len(got) != len(payload) {
+		t.Fatalf("payload length: got %d, want %d", len(got), len(payload))
+	}
+	for i := range got {
+		if got[i] != payload[i] {
+			t.Fatalf("payload[%d]: got %d, want %d", i, got[i], payload[i])
+		}
+	}
+}
@@ -146,7 +146,9 @@ type futureResponse struct {
 	async bool
 
 	// buf is a fixed-size buffer for response data. The host connection
-	// path slices data from this buffer to avoid a per-response allocation.
+	// path slices data from this buffer to avoid a per-response allocation;
+	// responses that don't fit (e.g. large FUSE_READ replies) are copied into
+	// a separate allocation instead.
 	buf [linux.FUSE_MIN_READ_BUFFER]byte
 }
 
@@ -1877,6 +1877,7 @@ func (fs *filesystem) MountOptions() string {
 	} else {
 		optsKV = append(optsKV, mopt{moptDcache, fs.opts.dcache})
 	}
+	optsKV = append(optsKV, mopt{moptReadahead, fs.opts.readahead})
 	switch fs.opts.interop {
 	case InteropModeExclusive:
 		optsKV = append(optsKV, mopt{moptCache, cacheFSCache})
@@ -1900,6 +1901,9 @@ func (fs *filesystem) MountOptions() string {
 	if fs.opts.overlayfsStaleRead {
 		optsKV = append(optsKV, mopt{moptOverlayfsStaleRead, nil})
 	}
+	if fs.opts.sharePageCache {
+		optsKV = append(optsKV, mopt{moptSharePageCache, nil})
+	}
 	if fs.opts.directfs.enabled {
 		optsKV = append(optsKV, mopt{moptDirectfs, nil})
 	}
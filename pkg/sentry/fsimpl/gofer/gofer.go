@@ -85,11 +85,13 @@ const (
 	moptDfltGID                  = "dfltgid"
 	moptCache                    = "cache"
 	moptDcache                   = "dcache"
+	moptReadahead                = "readahead"
 	moptForcePageCache           = "force_page_cache"
 	moptLimitHostFDTranslation   = "limit_host_fd_translation"
 	moptOverlayfsStaleRead       = "overlayfs_stale_read"
 	moptDisableFileHandleSharing = "disable_file_handle_sharing"
 	moptDisableFifoOpen          = "disable_fifo_open"
+	moptSharePageCache           = "share_page_cache"
 
 	// Directfs options.
 	moptDirectfs = "directfs"
@@ -103,7 +105,7 @@ const (
 )
 
 // SupportedMountOptions is the set of mount options that can be set externally.
-var SupportedMountOptions = []string{moptOverlayfsStaleRead, moptDisableFileHandleSharing, moptDcache}
+var SupportedMountOptions = []string{moptOverlayfsStaleRead, moptDisableFileHandleSharing, moptDcache, moptReadahead, moptSharePageCache}
 
 const (
 	defaultMaxCachedDentries  = 1000
@@ -301,6 +303,11 @@ type filesystemOptions struct {
 	// effective only if globalDentryCache is not being used.
 	dcache uint64
 
+	// readahead is the maximum number of bytes read speculatively for
+	// sequential reads of regular files. If readahead is 0, sequential reads
+	// are not detected, and only a fixed, small amount of data is read ahead.
+	readahead uint64
+
 	// If forcePageCache is true, host FDs may not be used for application
 	// memory mappings even if available; instead, the client must perform its
 	// own caching of regular file pages. This is primarily useful for testing.
@@ -323,6 +330,11 @@ type filesystemOptions struct {
 	// series, only memory mappings are incoherent.)
 	overlayfsStaleRead bool
 
+	// If sharePageCache is true, pages cached for regular files that are not
+	// open for writing may be shared with identical files in other gofer
+	// filesystems. See shared_cache.go.
+	sharePageCache bool
+
 	// If regularFilesUseSpecialFileFD is true, application FDs representing
 	// regular files will use distinct file handles for each FD, in the same
 	// way that application FDs representing "special files" such as sockets
@@ -506,6 +518,18 @@ func (fstype FilesystemType) GetFilesystem(ctx context.Context, vfsObj *vfs.Virt
 		}
 	}
 
+	// Parse the maximum readahead window.
+	fsopts.readahead = defaultMaxReadahead
+	if readaheadStr, ok := mopts[moptReadahead]; ok {
+		delete(mopts, moptReadahead)
+		readahead, err := strconv.ParseUint(readaheadStr, 10, 64)
+		if err != nil {
+			ctx.Warningf("gofer.FilesystemType.GetFilesystem: invalid readahead: %s=%s", moptReadahead, readaheadStr)
+			return nil, nil, linuxerr.EINVAL
+		}
+		fsopts.readahead = readahead
+	}
+
 	// Parse the default UID and GID.
 	fsopts.dfltuid = _V9FS_DEFUID
 	if dfltuidstr, ok := mopts[moptDfltUID]; ok {
@@ -552,6 +576,10 @@ func (fstype FilesystemType) GetFilesystem(ctx context.Context, vfsObj *vfs.Virt
 		delete(mopts, moptOverlayfsStaleRead)
 		fsopts.overlayfsStaleRead = true
 	}
+	if _, ok := mopts[moptSharePageCache]; ok {
+		delete(mopts, moptSharePageCache)
+		fsopts.sharePageCache = true
+	}
 	if _, ok := mopts[moptDirectfs]; ok {
 		delete(mopts, moptDirectfs)
 		fsopts.directfs.enabled = true
@@ -808,6 +836,60 @@ func (d *dentry) releaseExtraRefsRecursiveLocked(ctx context.Context) {
 	}
 }
 
+// HostFDs implements vfs.FilesystemImplHostFDsExtension.HostFDs. Host FDs
+// held by dentries that aren't reachable from the root, such as deleted files
+// that are still open, aren't included.
+func (fs *filesystem) HostFDs(fn func(fd int, owner string)) {
+	for _, fd := range fs.client.HostFDs() {
+		fn(fd, "gofer connection")
+	}
+	fs.renameMu.RLock()
+	if fs.root != nil {
+		fs.root.hostFDsRecursive(fn)
+	}
+	fs.renameMu.RUnlock()
+	fs.syncMu.Lock()
+	defer fs.syncMu.Unlock()
+	for sffd := fs.specialFileFDs.Front(); sffd != nil; sffd = sffd.Next() {
+		if sffd.handle.fd >= 0 {
+			fn(int(sffd.handle.fd), "special file handle")
+		}
+	}
+}
+
+// hostFDsRecursive calls fn for each host FD held by the inodes of the tree
+// with root d.
+//
+// Precondition: d.inode.fs.renameMu is locked.
+func (d *dentry) hostFDsRecursive(fn func(fd int, owner string)) {
+	if dfi, ok := d.inode.impl.(*directfsInode); ok {
+		d.inode.handleMu.RLock()
+		controlFD := dfi.controlFD
+		d.inode.handleMu.RUnlock()
+		if controlFD >= 0 {
+			fn(controlFD, "directfs control FD")
+		}
+	}
+	for _, fd := range []int32{d.inode.readFD.Load(), d.inode.writeFD.Load()} {
+		if fd >= 0 {
+			fn(int(fd), "file handle")
+		}
+	}
+	if d.isDir() {
+		var children []*dentry
+		d.childrenMu.Lock()
+		for _, child := range d.children {
+			children = append(children, child)
+		}
+		d.childrenMu.Unlock()
+		for _, child := range children {
+			if child != nil {
+				child.hostFDsRecursive(fn)
+			}
+		}
+	}
+}
+
 // inoKey is the key used to identify the inode backing the dentry.
 // +stateify savable
 type inoKey struct {
@@ -945,6 +1027,16 @@ type inode struct {
 	// tracks dirty segments in cache. dirty is protected by dataMu.
 	dirty fsutil.DirtySet
 
+	// If this inode represents a regular file whose cached pages are shared
+	// with identical files, sharedCache indexes the shared pages. See
+	// shared_cache.go. sharedCache is protected by dataMu.
+	sharedCache *sharedFileCache `state:"nosave"`
+
+	// If noSharedCache is true, this inode's cached pages may never be
+	// shared, since the file has been opened for writing or truncated.
+	// noSharedCache is protected by dataMu.
+	noSharedCache bool
+
 	// If this inode represents a deleted regular file, savedDeletedData is used
 	// to store file data for save/restore.
 	savedDeletedData []byte
@@ -960,6 +1052,15 @@ type inode struct {
 	// translations to mmapFile continue to work correctly until invalidated.
 	mmapFile fsutil.MmapCachedFile
 
+	// writableMappings is the number of writable memory mappings of this
+	// inode.
+	writableMappings atomicbitops.Int64
+
+	// hostFDReadOnlyTranslations is true if read-only translations of mmapFile
+	// may have been returned while mmapFD < 0. See
+	// inode.mayTranslateReadOnlyFromHostFD.
+	hostFDReadOnlyTranslations atomicbitops.Bool `state:"nosave"`
+
 	// If this inode represents a symbolic link, InteropModeShared is not in
 	// effect, and haveTarget is true, target is the symlink target. haveTarget
 	// and target are protected by dataMu.
@@ -1045,6 +1146,10 @@ func (i *inode) destroy(ctx context.Context, d *dentry) {
 		i.cache.DropAll(mf)
 		i.dirty.RemoveAllAndAccount()
 	}
+	if i.sharedCache != nil {
+		sharedCaches.detach(i.sharedCache)
+		i.sharedCache = nil
+	}
 
 	i.dataMu.Unlock()
 
@@ -1271,6 +1376,13 @@ func (d *dentry) statTo(stat *linux.Statx) {
 	// This is consistent with regularFileFD.Seek(), which treats regular files
 	// as having no holes.
 	stat.Blocks = (stat.Size + 511) / 512
+	if d.inode.isRegularFile() {
+		// O_DIRECT is not passed to the remote file, so O_DIRECT I/O goes
+		// through sentry buffers and has no alignment requirements.
+		stat.Mask |= linux.STATX_DIOALIGN
+		stat.DioMemAlign = 1
+		stat.DioOffsetAlign = 1
+	}
 	stat.Atime = linux.NsecToStatxTimestamp(d.inode.atime.Load())
 	if d.inode.btimeValid.Load() {
 		stat.Mask |= linux.STATX_BTIME
@@ -1474,12 +1586,19 @@ func (i *inode) updateSizeLocked(newSize uint64) {
 func (i *inode) updateSizeAndUnlockDataMuLocked(newSize uint64) {
 	oldSize := i.size.RacyLoad()
 	i.size.Store(newSize)
+	// Truncation modifies cached pages in place, and cached pages are shared
+	// only between files of the same size, so they must no longer be shared.
+	unshareRelease := func() {}
+	if newSize != oldSize {
+		unshareRelease = i.unshareCacheLocked()
+	}
 	// i.dataMu must be unlocked to lock i.mapsMu and invalidate mappings
 	// below. This allows concurrent calls to Read/Translate/etc. These
 	// functions synchronize with truncation by refusing to use cache
 	// contents beyond the new i.size. (We are still holding i.metadataMu,
 	// so we can't race with Write or another truncate.)
 	i.dataMu.Unlock()
+	unshareRelease()
 	if newSize < oldSize {
 		oldpgend, _ := hostarch.PageRoundUp(oldSize)
 		newpgend, _ := hostarch.PageRoundUp(newSize)
@@ -2050,6 +2169,11 @@ func (d *dentry) removeXattr(ctx context.Context, creds *auth.Credentials, name
 //   - d.isRegularFile() || d.isDir().
 //   - fs.renameMu is locked.
 func (d *dentry) ensureSharedHandle(ctx context.Context, read, write, trunc bool) error {
+	if (write || trunc) && d.inode.fs.opts.sharePageCache {
+		// Writes modify cached pages in place, so they must not be shared.
+		d.inode.unshareCache()
+	}
+
 	// O_TRUNC unconditionally requires us to obtain a new handle (opened with
 	// O_TRUNC).
 	d.inode.handleMu.Lock()
@@ -2157,6 +2281,12 @@ func (d *dentry) ensureSharedHandle(ctx context.Context, read, write, trunc bool
 				invalidateTranslations = readHandleWasOk
 				d.inode.mmapFD.Store(h.fd)
 				d.inode.mmapFile.SetFD(int(h.fd))
+			} else if d.inode.mmapFile.FD() < 0 {
+				// The new FD can't be used for all memory mappings, since
+				// it isn't coherent with writes through the sentry page
+				// cache, but it can be used for read-only mappings of clean
+				// pages; see inode.mayTranslateReadOnlyFromHostFD.
+				d.inode.mmapFile.SetFD(int(h.fd))
 			}
 		} else if openWritable && d.inode.writeFD.RacyLoad() < 0 {
 			d.inode.writeFD.Store(h.fd)
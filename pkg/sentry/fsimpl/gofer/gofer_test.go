@@ -19,9 +19,11 @@ import (
 	"testing"
 
 	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/hostarch"
 	"gvisor.dev/gvisor/pkg/lisafs"
 	"gvisor.dev/gvisor/pkg/sentry/contexttest"
 	"gvisor.dev/gvisor/pkg/sentry/ktime"
+	"gvisor.dev/gvisor/pkg/sentry/memmap"
 	"gvisor.dev/gvisor/pkg/sentry/pgalloc"
 )
 
@@ -194,3 +196,66 @@ func TestXattrCacheList(t *testing.T) {
 		t.Errorf("getList() = (%v, %v), want (%v, true)", list, found, wantList)
 	}
 }
+
+type testMappingSpace struct {
+	inv []hostarch.AddrRange
+}
+
+// Invalidate implements memmap.MappingSpace.Invalidate.
+func (ms *testMappingSpace) Invalidate(ar hostarch.AddrRange, opts memmap.InvalidateOpts) {
+	ms.inv = append(ms.inv, ar)
+}
+
+// TestAddWritableMappingInvalidatesHostFDTranslations checks that read-only
+// translations that may map the host FD are invalidated when the file gains
+// its first writable mapping.
+func TestAddWritableMappingInvalidatesHostFDTranslations(t *testing.T) {
+	ctx := contexttest.Context(t)
+	fs := filesystem{
+		mf:          pgalloc.MemoryFileFromContext(ctx),
+		inoByKey:    make(map[inoKey]uint64),
+		inodeByKey:  make(map[inoKey]*inode),
+		clock:       ktime.RealtimeClockFromContext(ctx),
+		dentryCache: &dentryCache{maxCachedDentries: 0},
+		client:      &lisafs.Client{},
+	}
+	d, err := fs.newLisafsDentry(ctx, &lisafs.Inode{
+		ControlFD: 1,
+		Stat: lisafs.Statx{
+			Mask: linux.STATX_TYPE | linux.STATX_MODE | linux.STATX_SIZE,
+			Mode: linux.S_IFREG | 0666,
+			Size: 2 * hostarch.PageSize,
+		},
+	})
+	if err != nil {
+		t.Fatalf("fs.newDentry(): %v", err)
+	}
+
+	roAR := hostarch.AddrRange{0x10000, 0x10000 + 2*hostarch.PageSize}
+	rwAR := hostarch.AddrRange{0x20000, 0x20000 + 2*hostarch.PageSize}
+	roMS := &testMappingSpace{}
+	rwMS := &testMappingSpace{}
+	if err := d.AddMapping(ctx, roMS, roAR, 0, false /* writable */); err != nil {
+		t.Fatalf("AddMapping(read-only): %v", err)
+	}
+	d.inode.hostFDReadOnlyTranslations.Store(true)
+
+	if err := d.AddMapping(ctx, rwMS, rwAR, 0, true /* writable */); err != nil {
+		t.Fatalf("AddMapping(writable): %v", err)
+	}
+	if want := []hostarch.AddrRange{roAR}; !slices.Equal(roMS.inv, want) {
+		t.Errorf("read-only mapping invalidations after first writable mapping: got %v, want %v", roMS.inv, want)
+	}
+
+	// Additional writable mappings don't need to invalidate anything, since
+	// no translations of the host FD are returned while writable mappings
+	// exist.
+	roMS.inv = nil
+	rwMS.inv = nil
+	if err := d.AddMapping(ctx, rwMS, rwAR, 0, true /* writable */); err != nil {
+		t.Fatalf("AddMapping(writable): %v", err)
+	}
+	if len(roMS.inv) != 0 || len(rwMS.inv) != 0 {
+		t.Errorf("invalidations after second writable mapping: got %v and %v, want none", roMS.inv, rwMS.inv)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gofer
+
+import (
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/hostarch"
+	"gvisor.dev/gvisor/pkg/sentry/memmap"
+	"gvisor.dev/gvisor/pkg/sync"
+)
+
+const (
+	// defaultReadahead is the amount of data read into the page cache beyond
+	// what is required, for accesses that are not known to be sequential.
+	defaultReadahead = 64 << 10 // 64 KB, chosen arbitrarily
+
+	// defaultMaxReadahead is the default upper bound on the readahead window
+	// for sequential reads, used unless overridden by the "readahead" mount
+	// option.
+	defaultMaxReadahead = 2 << 20 // 2 MB
+)
+
+// readaheadState tracks reads through a regular file FD to detect sequential
+// access, and sizes speculative reads accordingly. Compare Linux's struct
+// file_ra_state.
+type readaheadState struct {
+	mu sync.Mutex
+
+	// prevEnd is the offset at which the previous read ended.
+	//
+	// +checklocks:mu
+	prevEnd uint64
+
+	// window is the size of the readahead window in bytes. It is 0 if reads
+	// have not been sequential.
+	//
+	// +checklocks:mu
+	window uint64
+
+	// hintEnd is the end of the range for which a host readahead hint was
+	// last issued.
+	//
+	// +checklocks:mu
+	hintEnd uint64
+}
+
+// readahead describes speculative reading to perform on behalf of a read.
+type readahead struct {
+	// window is the maximum number of bytes to read into the page cache at
+	// once, unless the read requires more. If window is 0, defaultReadahead
+	// is used.
+	window uint64
+
+	// hint is a range of the file, following the read, that the host should
+	// be advised will be read soon. hint is empty if no hint should be given.
+	hint memmap.MappableRange
+}
+
+// read records a read of length bytes at offset off, and returns the
+// readahead to perform for it. maxWindow is the maximum readahead window; if
+// it is 0, adaptive readahead is disabled.
+func (ra *readaheadState) read(off, length, maxWindow uint64) readahead {
+	if maxWindow == 0 || length == 0 {
+		return readahead{}
+	}
+	end := off + length
+	if end < off {
+		return readahead{}
+	}
+
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	if off != ra.prevEnd {
+		// Random access; start over.
+		ra.prevEnd = end
+		ra.window = 0
+		ra.hintEnd = 0
+		return readahead{}
+	}
+	ra.prevEnd = end
+
+	// Grow the window geometrically while reads remain sequential, starting
+	// from a window large enough to cover a few reads of this size.
+	if ra.window == 0 {
+		ra.window = max(defaultReadahead, 4*length)
+	} else {
+		ra.window *= 2
+	}
+	ra.window = min(ra.window, maxWindow)
+	if r, ok := hostarch.PageRoundUp(ra.window); ok {
+		ra.window = r
+	}
+
+	// Only issue another hint once the reader has consumed half of the
+	// previously hinted range, so that sequential reads don't each cost a
+	// host syscall.
+	res := readahead{window: ra.window}
+	hintEnd := end + ra.window
+	if hintEnd < end {
+		return res
+	}
+	if ra.hintEnd <= end || ra.hintEnd-end < ra.window/2 {
+		res.hint = memmap.MappableRange{Start: max(end, ra.hintEnd), End: hintEnd}
+		ra.hintEnd = hintEnd
+	}
+	return res
+}
+
+// fillRange returns the range to read into the page cache given the required
+// range and the optional range that may be read.
+func (r readahead) fillRange(required, optional memmap.MappableRange) memmap.MappableRange {
+	if r.window == 0 {
+		return maxFillRange(required, optional)
+	}
+	return fillRangeWithReadahead(required, optional, r.window)
+}
+
+// adviseHost advises the host that r.hint will be read soon through hostFD,
+// then clears r.hint so that the advice is given at most once per read. Errors
+// are ignored, since the advice is only a performance hint.
+func (r *readahead) adviseHost(hostFD int32) {
+	if hostFD < 0 || r.hint.Length() == 0 {
+		return
+	}
+	_ = unix.Fadvise(int(hostFD), int64(r.hint.Start), int64(r.hint.Length()), unix.FADV_WILLNEED)
+	r.hint = memmap.MappableRange{}
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gofer
+
+import (
+	"testing"
+
+	"gvisor.dev/gvisor/pkg/sentry/memmap"
+)
+
+func TestReadaheadSequential(t *testing.T) {
+	const (
+		readSize  = 4 << 10
+		maxWindow = 1 << 20
+	)
+	var ra readaheadState
+	var prevWindow uint64
+	for i := uint64(0); i < 16; i++ {
+		r := ra.read(i*readSize, readSize, maxWindow)
+		if r.window < prevWindow {
+			t.Fatalf("read %d: window shrank from %d to %d", i, prevWindow, r.window)
+		}
+		if r.window > maxWindow {
+			t.Fatalf("read %d: window %d exceeds maximum %d", i, r.window, maxWindow)
+		}
+		prevWindow = r.window
+	}
+	if prevWindow != maxWindow {
+		t.Errorf("window after sequential reads got %d, want %d", prevWindow, maxWindow)
+	}
+
+	// A random read resets the window.
+	if r := ra.read(100*maxWindow, readSize, maxWindow); r.window != 0 || r.hint.Length() != 0 {
+		t.Errorf("random read got %+v, want no readahead", r)
+	}
+}
+
+func TestReadaheadHints(t *testing.T) {
+	const (
+		readSize  = 64 << 10
+		maxWindow = 256 << 10
+	)
+	var ra readaheadState
+	hints := 0
+	var hintEnd uint64
+	for i := uint64(0); i < 64; i++ {
+		off := i * readSize
+		r := ra.read(off, readSize, maxWindow)
+		if r.hint.Length() == 0 {
+			continue
+		}
+		hints++
+		if r.hint.Start < off+readSize {
+			t.Errorf("read %d: hint %v overlaps the read", i, r.hint)
+		}
+		if r.hint.Start < hintEnd {
+			t.Errorf("read %d: hint %v overlaps the previous hint ending at %#x", i, r.hint, hintEnd)
+		}
+		hintEnd = r.hint.End
+	}
+	// Hints should be batched rather than issued for every read.
+	if hints == 0 || hints >= 64 {
+		t.Errorf("got %d hints for 64 sequential reads", hints)
+	}
+}
+
+func TestReadaheadDisabled(t *testing.T) {
+	var ra readaheadState
+	for i := uint64(0); i < 4; i++ {
+		if r := ra.read(i*4096, 4096, 0); r != (readahead{}) {
+			t.Fatalf("read %d with readahead disabled got %+v", i, r)
+		}
+	}
+}
+
+func TestFillRangeWithReadahead(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		required memmap.MappableRange
+		optional memmap.MappableRange
+		max      uint64
+		want     memmap.MappableRange
+	}{
+		{
+			name:     "optional fits",
+			required: memmap.MappableRange{0x1000, 0x2000},
+			optional: memmap.MappableRange{0, 0x10000},
+			max:      0x10000,
+			want:     memmap.MappableRange{0, 0x10000},
+		},
+		{
+			name:     "readahead from required",
+			required: memmap.MappableRange{0x1000, 0x2000},
+			optional: memmap.MappableRange{0, 0x100000},
+			max:      0x20000,
+			want:     memmap.MappableRange{0x1000, 0x21000},
+		},
+		{
+			name:     "required exceeds max",
+			required: memmap.MappableRange{0, 0x40000},
+			optional: memmap.MappableRange{0, 0x100000},
+			max:      0x20000,
+			want:     memmap.MappableRange{0, 0x40000},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := fillRangeWithReadahead(test.required, test.optional, test.max); got != test.want {
+				t.Errorf("fillRangeWithReadahead(%v, %v, %#x) = %v, want %v", test.required, test.optional, test.max, got, test.want)
+			}
+		})
+	}
+}
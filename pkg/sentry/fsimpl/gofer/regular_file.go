@@ -48,6 +48,9 @@ type regularFileFD struct {
 	// off is the file offset. off is protected by mu.
 	mu  sync.Mutex `state:"nosave"`
 	off int64
+
+	// ra tracks reads through this FD for readahead.
+	ra readaheadState `state:"nosave"`
 }
 
 func newRegularFileFD(mnt *vfs.Mount, d *dentry, flags uint32, creds *auth.Credentials) (*regularFileFD, error) {
@@ -158,6 +161,7 @@ func (fd *regularFileFD) PRead(ctx context.Context, dst usermem.IOSequence, offs
 		}
 	} else {
 		rw := getDentryReadWriter(ctx, d, offset)
+		rw.readahead = fd.ra.read(uint64(offset), uint64(dst.NumBytes()), d.inode.fs.opts.readahead)
 		n, readErr = dst.CopyOutFrom(ctx, rw)
 		putDentryReadWriter(rw)
 		if d.inode.fs.opts.interop != InteropModeShared {
@@ -324,10 +328,11 @@ func (fd *regularFileFD) Write(ctx context.Context, src usermem.IOSequence, opts
 }
 
 type dentryReadWriter struct {
-	ctx    context.Context
-	d      *dentry
-	off    uint64
-	direct bool
+	ctx       context.Context
+	d         *dentry
+	off       uint64
+	direct    bool
+	readahead readahead
 }
 
 var dentryReadWriterPool = sync.Pool{
@@ -342,6 +347,7 @@ func getDentryReadWriter(ctx context.Context, d *dentry, offset int64) *dentryRe
 	rw.d = d
 	rw.off = uint64(offset)
 	rw.direct = false
+	rw.readahead = readahead{}
 	return rw
 }
 
@@ -366,6 +372,8 @@ func (rw *dentryReadWriter) ReadToBlocks(dsts safemem.BlockSeq) (uint64, error)
 	defer rw.d.inode.handleMu.RUnlock()
 	h := rw.d.inode.readHandle()
 	if (rw.d.inode.mmapFD.RacyLoad() >= 0 && !rw.d.inode.fs.opts.forcePageCache) || rw.d.inode.fs.opts.interop == InteropModeShared || rw.direct {
+		// We don't cache file contents, but the host might.
+		rw.readahead.adviseHost(h.fd)
 		n, err := h.readToBlocksAt(rw.ctx, dsts, rw.off)
 		rw.off += n
 		return n, err
@@ -429,12 +437,20 @@ func (rw *dentryReadWriter) ReadToBlocks(dsts safemem.BlockSeq) (uint64, error)
 					End:   gapEnd,
 				}
 				optMR := gap.Range()
-				_, err := rw.d.inode.cache.Fill(rw.ctx, reqMR, maxFillRange(reqMR, optMR), rw.d.inode.size.Load(), mf, pgalloc.AllocOpts{
+				if rw.d.inode.importSharedPagesLocked(optMR, memCgID) {
+					// Pages cached for an identical file may satisfy the
+					// read without filling.
+					seg, gap = rw.d.inode.cache.Find(rw.off)
+					continue
+				}
+				fillMR := rw.readahead.fillRange(reqMR, optMR)
+				_, err := rw.d.inode.cache.Fill(rw.ctx, reqMR, fillMR, rw.d.inode.size.Load(), mf, pgalloc.AllocOpts{
 					Kind:    usage.PageCache,
 					MemCgID: memCgID,
 					Mode:    pgalloc.AllocateAndWritePopulate,
 				}, h.readToBlocksAt)
 				mf.MarkEvictable(rw.d.inode, pgalloc.EvictableRange{Start: optMR.Start, End: optMR.End})
+				rw.d.inode.publishSharedPagesLocked(fillMR, memCgID)
 				seg, gap = rw.d.inode.cache.Find(rw.off)
 				if !seg.Ok() {
 					return done, err
@@ -445,6 +461,7 @@ func (rw *dentryReadWriter) ReadToBlocks(dsts safemem.BlockSeq) (uint64, error)
 				// into it again in a later iteration of this loop.
 			} else {
 				// Read directly from the file.
+				rw.readahead.adviseHost(h.fd)
 				gapDsts := dsts.TakeFirst64(gapMR.Length())
 				n, err := h.readToBlocksAt(rw.ctx, gapDsts, gapMR.Start)
 				done += n
@@ -562,9 +579,10 @@ exitLoop:
 		// The remote file's size will implicitly be extended to the correct
 		// value when we write back to it.
 	}
-	// If InteropModeWritethrough is in effect, flush written data back to the
+	// If InteropModeWritethrough is in effect, or if the host FD may be mapped
+	// directly by read-only translations, flush written data back to the
 	// remote filesystem.
-	if rw.d.inode.fs.opts.interop == InteropModeWritethrough && done != 0 {
+	if (rw.d.inode.fs.opts.interop == InteropModeWritethrough || rw.d.inode.hostFDReadOnlyTranslations.Load()) && done != 0 {
 		if err := fsutil.SyncDirty(rw.ctx, memmap.MappableRange{
 			Start: start,
 			End:   rw.off,
@@ -698,9 +716,20 @@ func (d *dentry) AddMapping(ctx context.Context, ms memmap.MappingSpace, ar host
 	// Do this unconditionally since whether we have a host FD can change
 	// across save/restore.
 	d.inode.mmapFile.AddMapping(ar, offset)
+	invalidate := false
+	if writable {
+		invalidate = d.inode.writableMappings.Add(1) == 1 && d.inode.hostFDReadOnlyTranslations.Load()
+	}
 	d.inode.mapsMu.Lock()
 	defer d.inode.mapsMu.Unlock()
 	mapped := d.inode.mappings.AddMapping(ms, ar, offset, writable)
+	if invalidate {
+		// Existing read-only translations may map the host FD, which won't
+		// observe writes through the new writable mapping until they are
+		// written back. Invalidate them so that they are retranslated from
+		// the page cache.
+		d.inode.mappings.InvalidateAll(memmap.InvalidateOpts{})
+	}
 	if d.inode.fs.mayCachePagesInMemoryFile() {
 		// d.Evict() will refuse to evict memory-mapped pages, so tell the
 		// MemoryFile to not bother trying.
@@ -715,6 +744,9 @@ func (d *dentry) AddMapping(ctx context.Context, ms memmap.MappingSpace, ar host
 // RemoveMapping implements memmap.Mappable.RemoveMapping.
 func (d *dentry) RemoveMapping(ctx context.Context, ms memmap.MappingSpace, ar hostarch.AddrRange, offset uint64, writable bool) {
 	d.inode.mmapFile.RemoveMapping(ar, offset)
+	if writable {
+		d.inode.writableMappings.Add(-1)
+	}
 	d.inode.mapsMu.Lock()
 	defer d.inode.mapsMu.Unlock()
 	unmapped := d.inode.mappings.RemoveMapping(ms, ar, offset, writable)
@@ -744,18 +776,10 @@ func (d *dentry) Translate(ctx context.Context, required, optional memmap.Mappab
 	d.inode.handleMu.RLock()
 	defer d.inode.handleMu.RUnlock()
 	if d.inode.mmapFD.RacyLoad() >= 0 && !d.inode.fs.opts.forcePageCache {
-		mr := optional
-		if d.inode.fs.opts.limitHostFDTranslation {
-			mr = maxFillRange(required, optional)
-		}
-		return []memmap.Translation{
-			{
-				Source: mr,
-				File:   &d.inode.mmapFile,
-				Offset: mr.Start,
-				Perms:  hostarch.AnyAccess,
-			},
-		}, nil
+		return d.inode.hostFDTranslations(required, optional, hostarch.AnyAccess), nil
+	}
+	if !at.Write && d.inode.mayTranslateReadOnlyFromHostFD(optional) {
+		return d.inode.hostFDTranslations(required, optional, hostarch.ReadExecute), nil
 	}
 
 	memCgID := pgalloc.MemoryCgroupIDFromContext(ctx)
@@ -779,11 +803,14 @@ func (d *dentry) Translate(ctx context.Context, required, optional memmap.Mappab
 
 	mf := d.inode.fs.mf
 	h := d.inode.readHandle()
-	_, cerr := d.inode.cache.Fill(ctx, required, maxFillRange(required, optional), d.inode.size.Load(), mf, pgalloc.AllocOpts{
+	fillMR := maxFillRange(required, optional)
+	d.inode.importSharedPagesLocked(fillMR, memCgID)
+	_, cerr := d.inode.cache.Fill(ctx, required, fillMR, d.inode.size.Load(), mf, pgalloc.AllocOpts{
 		Kind:    usage.PageCache,
 		MemCgID: memCgID,
 		Mode:    pgalloc.AllocateAndWritePopulate,
 	}, h.readToBlocksAt)
+	d.inode.publishSharedPagesLocked(fillMR, memCgID)
 
 	var ts []memmap.Translation
 	var translatedEnd uint64
@@ -818,8 +845,59 @@ func (d *dentry) Translate(ctx context.Context, required, optional memmap.Mappab
 	return ts, nil
 }
 
+// hostFDTranslations returns translations of optional to d.inode.mmapFile.
+//
+// Preconditions: i.handleMu must be locked.
+func (i *inode) hostFDTranslations(required, optional memmap.MappableRange, perms hostarch.AccessType) []memmap.Translation {
+	mr := optional
+	if i.fs.opts.limitHostFDTranslation {
+		mr = maxFillRange(required, optional)
+	}
+	return []memmap.Translation{
+		{
+			Source: mr,
+			File:   &i.mmapFile,
+			Offset: mr.Start,
+			Perms:  perms,
+		},
+	}
+}
+
+// mayTranslateReadOnlyFromHostFD returns true if read-only translations of mr
+// may map the readable host FD in i.mmapFile directly, even though i.mmapFD < 0
+// requires other translations to use the sentry page cache. This is the case
+// for files that have a readable host FD (e.g. provided by directfs) but were
+// also opened for writing with a separate handle, and avoids copying large
+// executables through the page cache when they are mapped read-only.
+//
+// The host FD is coherent with the page cache only where the latter has no
+// dirty pages. Once the host FD has been mapped, writes through the page
+// cache are written back immediately, as under InteropModeWritethrough;
+// writes through writable memory mappings are not visible through the host
+// FD until written back, so no such translations are returned while i has
+// writable mappings, and existing ones are invalidated when i gains its first
+// writable mapping.
+//
+// Preconditions: i.handleMu must be locked.
+func (i *inode) mayTranslateReadOnlyFromHostFD(mr memmap.MappableRange) bool {
+	if i.fs.opts.forcePageCache || i.fs.opts.interop == InteropModeShared || i.mmapFile.FD() < 0 || i.writableMappings.Load() != 0 {
+		return false
+	}
+	// Set hostFDReadOnlyTranslations before checking for dirty pages, so that
+	// writes that dirty pages after the check will write them back.
+	i.hostFDReadOnlyTranslations.Store(true)
+	i.dataMu.RLock()
+	defer i.dataMu.RUnlock()
+	return i.dirty.IsEmptyRange(mr)
+}
+
 func maxFillRange(required, optional memmap.MappableRange) memmap.MappableRange {
-	const maxReadahead = 64 << 10 // 64 KB, chosen arbitrarily
+	return fillRangeWithReadahead(required, optional, defaultReadahead)
+}
+
+// fillRangeWithReadahead returns a subset of optional that contains required
+// and is at most maxReadahead bytes long, unless required is longer.
+func fillRangeWithReadahead(required, optional memmap.MappableRange, maxReadahead uint64) memmap.MappableRange {
 	if required.Length() >= maxReadahead {
 		return required
 	}
@@ -887,5 +965,10 @@ func (i *inode) Evict(ctx context.Context, er pgalloc.EvictableRange) {
 		}
 		i.cache.Drop(mgapMR, mf)
 		i.dirty.KeepClean(mgapMR)
+		if i.sharedCache != nil {
+			// Allow the evicted pages to be freed once no other inode
+			// caches them.
+			sharedCaches.forget(i.sharedCache, mgapMR)
+		}
 	}
 }
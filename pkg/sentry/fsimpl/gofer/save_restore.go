@@ -85,6 +85,12 @@ func (fs *filesystem) PrepareSave(ctx context.Context) error {
 		return err
 	}
 
+	// Shared page cache indexes are not saved; release their pages so that
+	// they are not saved either.
+	if fs.opts.sharePageCache {
+		sharedCaches.dropAll()
+	}
+
 	return fs.root.prepareSaveRecursive(ctx)
 }
 
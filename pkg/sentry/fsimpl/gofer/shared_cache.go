@@ -0,0 +1,276 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gofer
+
+import (
+	"gvisor.dev/gvisor/pkg/sentry/fsutil"
+	"gvisor.dev/gvisor/pkg/sentry/memmap"
+	"gvisor.dev/gvisor/pkg/sentry/pgalloc"
+	"gvisor.dev/gvisor/pkg/sync"
+)
+
+// Page cache sharing
+//
+// Containers in a sandbox are often started from images with many identical
+// files (e.g. shared libraries in common base layers), which are served by
+// distinct gofer mounts. Without sharing, each such file is cached once per
+// mount. If the "share_page_cache" mount option is set, cached pages of
+// regular files are instead indexed by the identity of the host file (device
+// and inode number) and its version (size and modification time), so that
+// inodes in any filesystem with the same key can reuse pages that another
+// inode has already read.
+//
+// Since cached pages are modified in place by writes and truncation, pages
+// are only shared between inodes that have no write handle. Before an inode
+// is opened for writing or truncated, it stops sharing permanently and drops
+// its cache, so that it only ever modifies private pages (see
+// inode.unshareCacheLocked).
+//
+// Files mounted read-only from the same EROFS image don't need this: EROFS
+// maps the image file directly, so identical files within the image are
+// already shared through the host page cache.
+
+// sharedCacheKey identifies a version of a host file's contents.
+type sharedCacheKey struct {
+	inoKey
+	size  uint64
+	mtime int64
+
+	// mf is the MemoryFile in which pages are cached, since pages can only be
+	// shared between inodes using the same MemoryFile.
+	mf *pgalloc.MemoryFile
+}
+
+// sharedFileCache indexes the pages cached for a sharedCacheKey.
+type sharedFileCache struct {
+	key sharedCacheKey
+
+	// users is the number of inodes attached to this sharedFileCache. users
+	// is protected by sharedCaches.mu.
+	users int
+
+	// cache maps offsets into the file to offsets into key.mf, holding a
+	// reference on each page. cache is protected by sharedCaches.mu.
+	cache fsutil.FileRangeSet
+}
+
+// sharedCacheRegistry maps sharedCacheKeys to sharedFileCaches.
+type sharedCacheRegistry struct {
+	// mu is the innermost lock in the gofer package, ordered after
+	// inode.dataMu.
+	mu sync.Mutex
+
+	// +checklocks:mu
+	files map[sharedCacheKey]*sharedFileCache
+}
+
+// sharedCaches is the global sharedCacheRegistry, shared by all gofer
+// filesystems with the "share_page_cache" mount option.
+var sharedCaches = sharedCacheRegistry{
+	files: make(map[sharedCacheKey]*sharedFileCache),
+}
+
+// attach returns the sharedFileCache for key, creating it if necessary, and
+// adds a user to it.
+func (r *sharedCacheRegistry) attach(key sharedCacheKey) *sharedFileCache {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sf, ok := r.files[key]
+	if !ok {
+		sf = &sharedFileCache{key: key}
+		r.files[key] = sf
+	}
+	sf.users++
+	return sf
+}
+
+// detach removes a user from sf, releasing its pages if it has no remaining
+// users.
+func (r *sharedCacheRegistry) detach(sf *sharedFileCache) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sf.users--
+	if sf.users > 0 {
+		return
+	}
+	sf.cache.DropAll(sf.key.mf)
+	if r.files[sf.key] == sf {
+		delete(r.files, sf.key)
+	}
+}
+
+// forget drops sf's references on pages in mr, so that they can be freed once
+// no inode caches them.
+func (r *sharedCacheRegistry) forget(sf *sharedFileCache, mr memmap.MappableRange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sf.cache.Drop(mr, sf.key.mf)
+}
+
+// importPages inserts references to pages cached by sf in mr into cache, at
+// offsets that cache does not already contain. It returns true if any pages
+// were inserted.
+func (r *sharedCacheRegistry) importPages(sf *sharedFileCache, cache *fsutil.FileRangeSet, mr memmap.MappableRange, memCgID uint32) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return copyCachedPages(cache, &sf.cache, mr, sf.key.mf, memCgID)
+}
+
+// publishPages inserts references to pages cached in cache in mr into sf, at
+// offsets that sf does not already contain.
+func (r *sharedCacheRegistry) publishPages(sf *sharedFileCache, cache *fsutil.FileRangeSet, mr memmap.MappableRange, memCgID uint32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copyCachedPages(&sf.cache, cache, mr, sf.key.mf, memCgID)
+}
+
+// dropAll drops the registry's references on all pages, without detaching any
+// users. It is called before save, since the registry is not saved.
+func (r *sharedCacheRegistry) dropAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, sf := range r.files {
+		sf.cache.DropAll(sf.key.mf)
+	}
+}
+
+// copyCachedPages inserts references to pages that src maps in mr into dst,
+// for offsets that dst does not already map. It returns true if any pages were
+// inserted.
+func copyCachedPages(dst, src *fsutil.FileRangeSet, mr memmap.MappableRange, mf *pgalloc.MemoryFile, memCgID uint32) bool {
+	copied := false
+	for seg := src.LowerBoundSegment(mr.Start); seg.Ok() && seg.Start() < mr.End; seg = seg.NextSegment() {
+		segMR := seg.Range().Intersect(mr)
+		for gap := dst.LowerBoundGap(segMR.Start); gap.Ok() && gap.Start() < segMR.End; {
+			gapMR := gap.Range().Intersect(segMR)
+			if gapMR.Length() == 0 {
+				gap = gap.NextGap()
+				continue
+			}
+			fr := seg.FileRangeOf(gapMR)
+			mf.IncRef(fr, memCgID)
+			gap = dst.Insert(gap, gapMR, fr.Start).NextGap()
+			copied = true
+		}
+	}
+	return copied
+}
+
+// sharedCacheLocked returns the sharedFileCache that i's cached pages may be
+// shared through, or nil if they may not be shared.
+//
+// Preconditions:
+//   - i.handleMu must be locked.
+//   - i.dataMu must be locked for writing.
+func (i *inode) sharedCacheLocked() *sharedFileCache {
+	if i.sharedCache != nil || i.noSharedCache {
+		return i.sharedCache
+	}
+	if !i.fs.opts.sharePageCache || i.fs.opts.interop == InteropModeShared || i.isSynthetic() || !i.isRegularFile() || i.isWriteHandleOk() {
+		return nil
+	}
+	i.sharedCache = sharedCaches.attach(sharedCacheKey{
+		inoKey: i.inoKey,
+		size:   i.size.Load(),
+		mtime:  i.mtime.Load(),
+		mf:     i.fs.mf,
+	})
+	return i.sharedCache
+}
+
+// importSharedPagesLocked fills the parts of mr that i.cache does not contain
+// with pages previously cached for identical files, if i.cache is shared. It
+// returns true if i.cache was modified.
+//
+// Preconditions:
+//   - i.handleMu must be locked.
+//   - i.dataMu must be locked for writing.
+func (i *inode) importSharedPagesLocked(mr memmap.MappableRange, memCgID uint32) bool {
+	sf := i.sharedCacheLocked()
+	if sf == nil {
+		return false
+	}
+	if !sharedCaches.importPages(sf, &i.cache, mr, memCgID) {
+		return false
+	}
+	i.fs.mf.MarkEvictable(i, pgalloc.EvictableRange{Start: mr.Start, End: mr.End})
+	return true
+}
+
+// publishSharedPagesLocked makes pages in i.cache in mr available to identical
+// files, if i.cache is shared.
+//
+// Preconditions:
+//   - i.handleMu must be locked.
+//   - i.dataMu must be locked for writing.
+func (i *inode) publishSharedPagesLocked(mr memmap.MappableRange, memCgID uint32) {
+	if sf := i.sharedCache; sf != nil {
+		sharedCaches.publishPages(sf, &i.cache, mr, memCgID)
+	}
+}
+
+// unshareCacheLocked permanently stops i from sharing cached pages. Since
+// pages in i.cache may be used by other inodes, they are removed from i.cache
+// rather than modified in place. Translations returned by i.Translate may still
+// refer to them, so the caller must call the returned function, which
+// invalidates those translations before releasing the pages, after unlocking
+// i.dataMu.
+//
+// Preconditions: i.dataMu must be locked for writing.
+func (i *inode) unshareCacheLocked() func() {
+	i.noSharedCache = true
+	sf := i.sharedCache
+	if sf == nil {
+		return func() {}
+	}
+	i.sharedCache = nil
+
+	// Pages cached while sharing cannot be dirty, since i had no write handle.
+	mf := i.fs.mf
+	var frs []memmap.FileRange
+	for seg := i.cache.FirstSegment(); seg.Ok(); seg = seg.NextSegment() {
+		frs = append(frs, seg.FileRange())
+	}
+	mf.MarkAllUnevictable(i)
+	i.cache.RemoveAll()
+	sharedCaches.detach(sf)
+
+	return func() {
+		if len(frs) == 0 {
+			return
+		}
+		i.mapsMu.Lock()
+		i.mappings.InvalidateAll(memmap.InvalidateOpts{})
+		i.mapsMu.Unlock()
+		for _, fr := range frs {
+			mf.DecRef(fr)
+		}
+	}
+}
+
+// unshareCache is equivalent to unshareCacheLocked, but locks i.dataMu and
+// releases dropped pages itself.
+//
+// Preconditions: i.mapsMu, i.handleMu and i.dataMu must be unlocked.
+func (i *inode) unshareCache() {
+	i.dataMu.Lock()
+	if i.noSharedCache {
+		i.dataMu.Unlock()
+		return
+	}
+	release := i.unshareCacheLocked()
+	i.dataMu.Unlock()
+	release()
+}
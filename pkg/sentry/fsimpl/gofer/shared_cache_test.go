@@ -0,0 +1,72 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gofer
+
+import (
+	"testing"
+
+	"gvisor.dev/gvisor/pkg/hostarch"
+	"gvisor.dev/gvisor/pkg/sentry/contexttest"
+	"gvisor.dev/gvisor/pkg/sentry/fsutil"
+	"gvisor.dev/gvisor/pkg/sentry/memmap"
+	"gvisor.dev/gvisor/pkg/sentry/pgalloc"
+	"gvisor.dev/gvisor/pkg/sentry/usage"
+)
+
+func TestCopyCachedPages(t *testing.T) {
+	ctx := contexttest.Context(t)
+	mf := pgalloc.MemoryFileFromContext(ctx)
+
+	// src caches pages [0, 2) and [3, 4); dst caches page 1.
+	var src, dst fsutil.FileRangeSet
+	insert := func(s *fsutil.FileRangeSet, mr memmap.MappableRange) memmap.FileRange {
+		fr, err := mf.Allocate(mr.Length(), pgalloc.AllocOpts{Kind: usage.PageCache})
+		if err != nil {
+			t.Fatalf("Allocate failed: %v", err)
+		}
+		if !s.TryInsertRange(mr, fr.Start).Ok() {
+			t.Fatalf("failed to insert %v", mr)
+		}
+		return fr
+	}
+	page := func(start, end uint64) memmap.MappableRange {
+		return memmap.MappableRange{Start: start * hostarch.PageSize, End: end * hostarch.PageSize}
+	}
+	srcFR := insert(&src, page(0, 2))
+	insert(&src, page(3, 4))
+	dstFR := insert(&dst, page(1, 2))
+	defer src.DropAll(mf)
+	defer dst.DropAll(mf)
+
+	if !copyCachedPages(&dst, &src, page(0, 3), mf, 0) {
+		t.Fatalf("copyCachedPages returned false, want true")
+	}
+	// Page 0 should be shared with src, page 1 should be unchanged, and page 3
+	// should not be copied since it is outside the copied range.
+	if seg := dst.FindSegment(0); !seg.Ok() || seg.FileRangeOf(page(0, 1)).Start != srcFR.Start {
+		t.Errorf("page 0 not shared with src")
+	}
+	if seg := dst.FindSegment(page(1, 2).Start); !seg.Ok() || seg.FileRangeOf(page(1, 2)).Start != dstFR.Start {
+		t.Errorf("page 1 was replaced")
+	}
+	if dst.FindSegment(page(3, 4).Start).Ok() {
+		t.Errorf("page 3 copied from outside the copied range")
+	}
+
+	// Copying again should be a no-op.
+	if copyCachedPages(&dst, &src, page(0, 3), mf, 0) {
+		t.Errorf("second copyCachedPages returned true, want false")
+	}
+}
@@ -436,6 +436,10 @@ func (i *inode) GID() auth.KGID {
 	return auth.KGID(i.virtualOwner.gid.Load())
 }
 
+// hostStatxMask is the set of statx fields that are passed through from the
+// host.
+const hostStatxMask = linux.STATX_ALL | linux.STATX_DIOALIGN
+
 // Stat implements kernfs.Inode.Stat.
 func (i *inode) Stat(ctx context.Context, vfsfs *vfs.Filesystem, opts vfs.StatOptions) (linux.Statx, error) {
 	if opts.Mask&linux.STATX__RESERVED != 0 {
@@ -446,7 +450,7 @@ func (i *inode) Stat(ctx context.Context, vfsfs *vfs.Filesystem, opts vfs.StatOp
 	}
 
 	// Limit our host call only to known flags.
-	mask := opts.Mask & linux.STATX_ALL
+	mask := opts.Mask & hostStatxMask
 	var s unix.Statx_t
 	err := unix.Statx(i.hostFD, "", int(unix.AT_EMPTY_PATH|opts.Sync), int(mask), &s)
 	if linuxerr.Equals(linuxerr.ENOSYS, err) {
@@ -475,7 +479,7 @@ func (i *inode) Stat(ctx context.Context, vfsfs *vfs.Filesystem, opts vfs.StatOp
 	// Copy other fields that were returned by the host. RdevMajor/RdevMinor
 	// are never copied (and therefore left as zero), so as not to expose host
 	// device numbers.
-	ls.Mask |= s.Mask & linux.STATX_ALL
+	ls.Mask |= s.Mask & hostStatxMask
 	if s.Mask&linux.STATX_TYPE != 0 {
 		if i.virtualOwner.enabled {
 			ls.Mode |= uint16(i.virtualOwner.atomicMode()) & linux.S_IFMT
@@ -525,6 +529,12 @@ func (i *inode) Stat(ctx context.Context, vfsfs *vfs.Filesystem, opts vfs.StatOp
 	if s.Mask&linux.STATX_BLOCKS != 0 {
 		ls.Blocks = s.Blocks
 	}
+	if s.Mask&linux.STATX_DIOALIGN != 0 {
+		// Host FDs are read and written directly, so O_DIRECT alignment
+		// requirements of the host file apply.
+		ls.DioMemAlign = s.Dio_mem_align
+		ls.DioOffsetAlign = s.Dio_offset_align
+	}
 
 	return ls, nil
 }
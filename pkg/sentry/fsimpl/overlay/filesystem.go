@@ -1883,3 +1883,16 @@ func (fs *filesystem) MountOptions() string {
 	}
 	return vd.Mount().Filesystem().Impl().MountOptions()
 }
+
+// Quotas implements vfs.QuotaFilesystem.Quotas. Since all usage of an overlay
+// is charged to its upper layer, the overlay reports the upper layer's
+// quotas.
+func (fs *filesystem) Quotas() *vfs.QuotaSet {
+	if !fs.opts.UpperRoot.Ok() {
+		return nil
+	}
+	if qfs, ok := fs.opts.UpperRoot.Mount().Filesystem().Impl().(vfs.QuotaFilesystem); ok {
+		return qfs.Quotas()
+	}
+	return nil
+}
@@ -550,6 +550,37 @@ func (fs *filesystem) TarUpperLayer(ctx context.Context, outFD *os.File) error {
 	return ts.TarUpperLayer(ctx, outFD)
 }
 
+// UpperLayerUsage implements vfs.UpperLayerUsageReporter.UpperLayerUsage.
+func (fs *filesystem) UpperLayerUsage(ctx context.Context) (vfs.UpperLayerUsage, error) {
+	ur, err := fs.upperLayerUsageReporter()
+	if err != nil {
+		return vfs.UpperLayerUsage{}, err
+	}
+	return ur.UpperLayerUsage(ctx)
+}
+
+// UpperLayerLargestFiles implements
+// vfs.UpperLayerUsageReporter.UpperLayerLargestFiles.
+func (fs *filesystem) UpperLayerLargestFiles(ctx context.Context, n int) ([]vfs.FileUsage, error) {
+	ur, err := fs.upperLayerUsageReporter()
+	if err != nil {
+		return nil, err
+	}
+	return ur.UpperLayerLargestFiles(ctx, n)
+}
+
+func (fs *filesystem) upperLayerUsageReporter() (vfs.UpperLayerUsageReporter, error) {
+	if !fs.opts.UpperRoot.Ok() {
+		return nil, fmt.Errorf("overlay has no upper layer")
+	}
+	upperFS := fs.opts.UpperRoot.Mount().Filesystem()
+	ur, ok := upperFS.Impl().(vfs.UpperLayerUsageReporter)
+	if !ok {
+		return nil, fmt.Errorf("upper layer is of type %q, which does not implement vfs.UpperLayerUsageReporter", upperFS.FilesystemType().Name())
+	}
+	return ur, nil
+}
+
 // dentry implements vfs.DentryImpl.
 //
 // +stateify savable
@@ -0,0 +1,379 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package perfevent implements perf event file descriptions for software
+// events, whose counters are maintained by the sentry.
+package perfevent
+
+import (
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/hostarch"
+	"gvisor.dev/gvisor/pkg/marshal/primitive"
+	"gvisor.dev/gvisor/pkg/sentry/arch"
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
+	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
+	"gvisor.dev/gvisor/pkg/sentry/ktime"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+	"gvisor.dev/gvisor/pkg/sync"
+	"gvisor.dev/gvisor/pkg/usermem"
+)
+
+// supportedReadFormat is the set of PerfEventAttr.ReadFormat bits supported
+// by Event.Read.
+const supportedReadFormat = linux.PERF_FORMAT_TOTAL_TIME_ENABLED | linux.PERF_FORMAT_TOTAL_TIME_RUNNING | linux.PERF_FORMAT_ID | linux.PERF_FORMAT_GROUP | linux.PERF_FORMAT_LOST
+
+// Event implements vfs.FileDescriptionImpl for perf event fds counting a
+// software event of a task.
+//
+// Software events never need to be multiplexed, so an event's running time
+// always equals its enabled time.
+//
+// +stateify savable
+type Event struct {
+	vfsfd vfs.FileDescription
+	vfs.FileDescriptionDefaultImpl
+	vfs.DentryMetadataFileDescriptionImpl
+	vfs.NoLockFD
+
+	// attr is the attribute the event was opened with. attr is immutable.
+	attr linux.PerfEventAttr
+
+	// id is the event's unique ID. id is immutable.
+	id uint64
+
+	// target is the counted task. If attr.Flags contains PERF_ATTR_INHERIT,
+	// the event counts all threads of target's thread group instead. target
+	// is immutable.
+	target *kernel.Task
+
+	// clock measures the time the event is enabled. clock is immutable.
+	clock ktime.Clock
+
+	// leader is the group leader of the event, which may be the event
+	// itself. leader is immutable.
+	leader *Event
+
+	// mu protects the fields below in all events of the group. Only the
+	// leader's mu is used.
+	mu sync.Mutex `state:"nosave"`
+
+	// siblings are the events in the group other than the leader. siblings
+	// is only used in the leader.
+	siblings []*Event
+
+	// enabled is true if the event is enabled.
+	enabled bool
+
+	// count is the value of the counter as of the last call to syncLocked.
+	count uint64
+
+	// timeEnabled is the total time in which the event was active, as of the
+	// last call to syncLocked.
+	timeEnabled int64
+
+	// base is the value of the event's source at the last call to
+	// syncLocked, or when the event became active.
+	base uint64
+
+	// since is the time of the last call to syncLocked, or when the event
+	// became active.
+	since int64
+}
+
+var _ vfs.FileDescriptionImpl = (*Event)(nil)
+
+// Supported returns true if the given software event can be counted.
+func Supported(config uint64) bool {
+	return config <= linux.PERF_COUNT_SW_DUMMY
+}
+
+// New returns a new perf event fd that counts the software event described by
+// attr in target. If leader is not nil, the event joins its group.
+//
+// Preconditions:
+//   - attr.Type == PERF_TYPE_SOFTWARE.
+//   - Supported(attr.Config).
+//   - leader is nil or a group leader counting target.
+func New(ctx context.Context, vfsObj *vfs.VirtualFilesystem, attr *linux.PerfEventAttr, target *kernel.Task, leader *Event, flags uint32) (*vfs.FileDescription, error) {
+	vd := vfsObj.NewAnonVirtualDentry("[perf_event]")
+	defer vd.DecRef(ctx)
+	e := &Event{
+		attr:   *attr,
+		id:     target.Kernel().UniqueID(),
+		target: target,
+		clock:  target.Kernel().MonotonicClock(),
+		leader: leader,
+		// Enabling on exec is approximated by enabling immediately; the
+		// counters of the exec'ing task are usually negligible before it.
+		enabled: attr.Flags&linux.PERF_ATTR_DISABLED == 0 || attr.Flags&linux.PERF_ATTR_ENABLE_ON_EXEC != 0,
+	}
+	if e.leader == nil {
+		e.leader = e
+	}
+	if err := e.vfsfd.Init(e, flags, auth.CredentialsFromContext(ctx), vd.Mount(), vd.Dentry(), &vfs.FileDescriptionOptions{
+		UseDentryMetadata: true,
+		DenyPRead:         true,
+		DenyPWrite:        true,
+	}); err != nil {
+		return nil, err
+	}
+
+	e.leader.mu.Lock()
+	defer e.leader.mu.Unlock()
+	if e.activeLocked() {
+		e.base = e.source()
+		e.since = e.now()
+	}
+	if e.leader != e {
+		// Siblings keep their leader alive, like Linux.
+		e.leader.vfsfd.IncRef()
+		e.leader.siblings = append(e.leader.siblings, e)
+	}
+	return &e.vfsfd, nil
+}
+
+// IsLeader returns true if e is the leader of its group.
+func (e *Event) IsLeader() bool {
+	return e.leader == e
+}
+
+// Target returns the task counted by e.
+func (e *Event) Target() *kernel.Task {
+	return e.target
+}
+
+// Release implements vfs.FileDescriptionImpl.Release.
+func (e *Event) Release(ctx context.Context) {
+	if e.IsLeader() {
+		return
+	}
+	e.leader.mu.Lock()
+	for i, s := range e.leader.siblings {
+		if s == e {
+			e.leader.siblings = append(e.leader.siblings[:i], e.leader.siblings[i+1:]...)
+			break
+		}
+	}
+	e.leader.mu.Unlock()
+	e.leader.vfsfd.DecRef(ctx)
+}
+
+// now returns the current time in nanoseconds.
+func (e *Event) now() int64 {
+	return e.clock.Now().Nanoseconds()
+}
+
+// source returns the current value of the counted quantity.
+func (e *Event) source() uint64 {
+	inherit := e.attr.Flags&linux.PERF_ATTR_INHERIT != 0
+	switch e.attr.Config {
+	case linux.PERF_COUNT_SW_CPU_CLOCK, linux.PERF_COUNT_SW_TASK_CLOCK:
+		stats := e.target.CPUStats()
+		if inherit {
+			stats = e.target.ThreadGroup().CPUStats()
+		}
+		var ns int64
+		if e.attr.Flags&linux.PERF_ATTR_EXCLUDE_USER == 0 {
+			ns += stats.UserTime.Nanoseconds()
+		}
+		if e.attr.Flags&linux.PERF_ATTR_EXCLUDE_KERNEL == 0 {
+			ns += stats.SysTime.Nanoseconds()
+		}
+		return uint64(ns)
+	case linux.PERF_COUNT_SW_CONTEXT_SWITCHES:
+		if inherit {
+			return e.target.ThreadGroup().CPUStats().VoluntarySwitches
+		}
+		return e.target.CPUStats().VoluntarySwitches
+	case linux.PERF_COUNT_SW_PAGE_FAULTS, linux.PERF_COUNT_SW_PAGE_FAULTS_MIN:
+		// The sentry never has to read pages from storage to handle a fault
+		// in a way visible to the application, so all faults are minor.
+		if inherit {
+			return e.target.ThreadGroup().PageFaults()
+		}
+		return e.target.PageFaults()
+	default:
+		// CPU migrations, major, alignment and emulation faults don't occur
+		// in the sentry; dummy events never count.
+		return 0
+	}
+}
+
+// activeLocked returns true if e is counting. Events only count while they
+// and their group leader are enabled.
+//
+// Preconditions: e.leader.mu must be locked.
+func (e *Event) activeLocked() bool {
+	return e.enabled && e.leader.enabled
+}
+
+// syncLocked folds the events counted since the last call into e.count and
+// e.timeEnabled.
+//
+// Preconditions: e.leader.mu must be locked.
+func (e *Event) syncLocked(now int64) {
+	if !e.activeLocked() {
+		return
+	}
+	v := e.source()
+	// Counters are monotonic, but the CPU clocks may transiently appear to
+	// go backwards when split into user and system time.
+	if v > e.base {
+		e.count += v - e.base
+		e.base = v
+	}
+	e.timeEnabled += now - e.since
+	e.since = now
+}
+
+// membersLocked returns all events in e's group.
+//
+// Preconditions: e.leader.mu must be locked.
+func (e *Event) membersLocked() []*Event {
+	return append([]*Event{e.leader}, e.leader.siblings...)
+}
+
+// setEnabled enables or disables e, or all events in e's group if group is
+// true.
+func (e *Event) setEnabled(enabled, group bool) {
+	e.leader.mu.Lock()
+	defer e.leader.mu.Unlock()
+	// Enabling or disabling the leader affects whether all events in the
+	// group are active.
+	members := e.membersLocked()
+	now := e.now()
+	for _, m := range members {
+		m.syncLocked(now)
+	}
+	if group {
+		for _, m := range members {
+			m.enabled = enabled
+		}
+	} else {
+		e.enabled = enabled
+	}
+	for _, m := range members {
+		if m.activeLocked() {
+			m.base = m.source()
+			m.since = now
+		}
+	}
+}
+
+// reset sets the counter of e, or of all events in e's group if group is
+// true, to zero.
+func (e *Event) reset(group bool) {
+	e.leader.mu.Lock()
+	defer e.leader.mu.Unlock()
+	events := []*Event{e}
+	if group {
+		events = e.membersLocked()
+	}
+	now := e.now()
+	for _, m := range events {
+		m.syncLocked(now)
+		m.count = 0
+	}
+}
+
+// Read implements vfs.FileDescriptionImpl.Read.
+func (e *Event) Read(ctx context.Context, dst usermem.IOSequence, opts vfs.ReadOptions) (int64, error) {
+	format := e.attr.ReadFormat
+	e.leader.mu.Lock()
+	events := []*Event{e}
+	if format&linux.PERF_FORMAT_GROUP != 0 {
+		events = e.membersLocked()
+	}
+	now := e.now()
+	for _, m := range events {
+		m.syncLocked(now)
+	}
+	var buf []uint64
+	if format&linux.PERF_FORMAT_GROUP != 0 {
+		buf = append(buf, uint64(len(events)))
+	} else {
+		buf = append(buf, e.count)
+	}
+	if format&linux.PERF_FORMAT_TOTAL_TIME_ENABLED != 0 {
+		buf = append(buf, uint64(e.timeEnabled))
+	}
+	if format&linux.PERF_FORMAT_TOTAL_TIME_RUNNING != 0 {
+		buf = append(buf, uint64(e.timeEnabled))
+	}
+	for _, m := range events {
+		if format&linux.PERF_FORMAT_GROUP != 0 {
+			buf = append(buf, m.count)
+		}
+		if format&linux.PERF_FORMAT_ID != 0 {
+			buf = append(buf, m.id)
+		}
+		if format&linux.PERF_FORMAT_LOST != 0 {
+			// Counting events never lose samples.
+			buf = append(buf, 0)
+		}
+	}
+	e.leader.mu.Unlock()
+
+	if dst.NumBytes() < int64(len(buf)*8) {
+		return 0, linuxerr.ENOSPC
+	}
+	b := make([]byte, len(buf)*8)
+	for i, v := range buf {
+		hostarch.ByteOrder.PutUint64(b[i*8:], v)
+	}
+	n, err := dst.CopyOut(ctx, b)
+	return int64(n), err
+}
+
+// Ioctl implements vfs.FileDescriptionImpl.Ioctl.
+func (e *Event) Ioctl(ctx context.Context, uio usermem.IO, sysno uintptr, args arch.SyscallArguments) (uintptr, error) {
+	switch cmd := args[1].Uint(); cmd {
+	case linux.PERF_EVENT_IOC_ENABLE, linux.PERF_EVENT_IOC_DISABLE, linux.PERF_EVENT_IOC_RESET:
+		group := args[2].Uint64()&linux.PERF_IOC_FLAG_GROUP != 0
+		switch cmd {
+		case linux.PERF_EVENT_IOC_ENABLE:
+			e.setEnabled(true, group)
+		case linux.PERF_EVENT_IOC_DISABLE:
+			e.setEnabled(false, group)
+		default:
+			e.reset(group)
+		}
+		return 0, nil
+
+	case linux.PERF_EVENT_IOC_ID:
+		iocc := usermem.IOCopyContext{
+			IO:  uio,
+			Ctx: ctx,
+		}
+		_, err := primitive.CopyUint64Out(&iocc, args[2].Pointer(), e.id)
+		return 0, err
+
+	case linux.PERF_EVENT_IOC_SET_OUTPUT:
+		// There is no ring buffer to redirect; only removing the redirection
+		// is meaningful.
+		if args[2].Int() != -1 {
+			return 0, linuxerr.EINVAL
+		}
+		return 0, nil
+
+	case linux.PERF_EVENT_IOC_REFRESH, linux.PERF_EVENT_IOC_PERIOD, linux.PERF_EVENT_IOC_SET_FILTER, linux.PERF_EVENT_IOC_SET_BPF, linux.PERF_EVENT_IOC_PAUSE_OUTPUT, linux.PERF_EVENT_IOC_QUERY_BPF, linux.PERF_EVENT_IOC_MODIFY_ATTRIBUTES:
+		// Sampling, filters and BPF programs are unsupported.
+		return 0, linuxerr.EINVAL
+
+	default:
+		return 0, linuxerr.ENOTTY
+	}
+}
@@ -30,9 +30,7 @@ import (
 )
 
 func (fs *filesystem) newMaxKeySizeFile(ctx context.Context, k *kernel.Kernel, creds *auth.Credentials) kernfs.Inode {
-	s := &maxKeySize{maxKeys: &k.MaxKeySetSize}
-	s.Init(ctx, creds, linux.UNNAMED_MAJOR, fs.devMinor, fs.NextIno(), s, 0644)
-	return s
+	return fs.newSysctlInode(ctx, creds, "kernel.keys.maxkeys", 0644, &maxKeySize{maxKeys: &k.MaxKeySetSize})
 }
 
 // maxKeySize implements vfs.WritableDynamicBytesSource for
@@ -0,0 +1,77 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"bytes"
+
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
+	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+	"gvisor.dev/gvisor/pkg/usermem"
+)
+
+// sysctlInode is the inode of a writable /proc/sys file.
+type sysctlInode interface {
+	dynamicInode
+	vfs.WritableDynamicBytesSource
+}
+
+// newSysctlInode initializes inode as the writable sysctl key.
+func (fs *filesystem) newSysctlInode(ctx context.Context, creds *auth.Credentials, key string, perm linux.FileMode, inode sysctlInode) dynamicInode {
+	inode.Init(ctx, creds, linux.UNNAMED_MAJOR, fs.devMinor, fs.NextIno(), &sysctlData{key: key, src: inode}, perm)
+	return inode
+}
+
+// sysctlData implements vfs.WritableDynamicBytesSource for writable
+// /proc/sys files. It records values written by tasks in the kernel's
+// SysctlTable.
+//
+// +stateify savable
+type sysctlData struct {
+	// key is the sysctl name in dotted notation, e.g. "net.ipv4.tcp_sack".
+	key string
+
+	// src is the data source of the file.
+	src vfs.WritableDynamicBytesSource
+}
+
+var _ vfs.WritableDynamicBytesSource = (*sysctlData)(nil)
+
+// Generate implements vfs.DynamicBytesSource.Generate.
+func (d *sysctlData) Generate(ctx context.Context, buf *bytes.Buffer) error {
+	return d.src.Generate(ctx, buf)
+}
+
+// Write implements vfs.WritableDynamicBytesSource.Write.
+func (d *sysctlData) Write(ctx context.Context, fd *vfs.FileDescription, src usermem.IOSequence, offset int64) (int64, error) {
+	n, err := d.src.Write(ctx, fd, src, offset)
+	if err != nil {
+		return n, err
+	}
+	t := kernel.TaskFromContext(ctx)
+	if t == nil {
+		// Writes by the sentry itself are recorded by the writer.
+		return n, nil
+	}
+	// Record the value as it reads back, which is how it took effect.
+	var buf bytes.Buffer
+	if err := d.src.Generate(ctx, &buf); err == nil {
+		t.Kernel().Sysctls().Set(t.ContainerID(), d.key, buf.String())
+	}
+	return n, nil
+}
@@ -800,8 +800,8 @@ type taskStatData struct {
 
 	task *kernel.Task
 
-	// If tgstats is true, accumulate fault stats (not implemented) and CPU
-	// time across all tasks in t's thread group.
+	// If tgstats is true, accumulate fault stats and CPU time across all
+	// tasks in t's thread group.
 	tgstats bool
 
 	// pidns is the PID namespace associated with the proc filesystem that
@@ -825,16 +825,16 @@ func (s *taskStatData) Generate(ctx context.Context, buf *bytes.Buffer) error {
 	fmt.Fprintf(buf, "%d ", s.pidns.IDOfSession(s.task.ThreadGroup().Session()))
 	fmt.Fprintf(buf, "0 0 " /* tty_nr tpgid */)
 	fmt.Fprintf(buf, "0 " /* flags */)
-	fmt.Fprintf(buf, "0 0 0 0 " /* minflt cminflt majflt cmajflt */)
 	var cputime usage.CPUStats
 	if s.tgstats {
 		cputime = s.task.ThreadGroup().CPUStats()
 	} else {
 		cputime = s.task.CPUStats()
 	}
+	childCPUTime := s.task.ThreadGroup().JoinedChildCPUStats()
+	fmt.Fprintf(buf, "%d %d %d %d ", cputime.MinorFaults, childCPUTime.MinorFaults, cputime.MajorFaults, childCPUTime.MajorFaults)
 	fmt.Fprintf(buf, "%d %d ", linux.ClockTFromDuration(cputime.UserTime), linux.ClockTFromDuration(cputime.SysTime))
-	cputime = s.task.ThreadGroup().JoinedChildCPUStats()
-	fmt.Fprintf(buf, "%d %d ", linux.ClockTFromDuration(cputime.UserTime), linux.ClockTFromDuration(cputime.SysTime))
+	fmt.Fprintf(buf, "%d %d ", linux.ClockTFromDuration(childCPUTime.UserTime), linux.ClockTFromDuration(childCPUTime.SysTime))
 	fmt.Fprintf(buf, "%d %d ", s.task.Priority(), s.task.Niceness())
 	fmt.Fprintf(buf, "%d ", s.task.ThreadGroup().Count())
 
@@ -855,7 +855,19 @@ func (s *taskStatData) Generate(ctx context.Context, buf *bytes.Buffer) error {
 	// rsslim.
 	fmt.Fprintf(buf, "%d ", s.task.ThreadGroup().Limits().Get(limits.Rss).Cur)
 
-	fmt.Fprintf(buf, "0 0 0 0 0 " /* startcode endcode startstack kstkesp kstkeip */)
+	// Memory layout is only visible to readers that can trace the task.
+	// Otherwise, Linux reports 1 for the code bounds of tasks that have an mm
+	// and 0 for everything else; see fs/proc/array.c:do_task_stat().
+	var layout mm.Layout
+	if m := getMM(s.task); m != nil {
+		if kernel.ContextCanTrace(ctx, s.task, false) {
+			layout = m.Layout()
+		} else {
+			layout.StartCode, layout.EndCode = 1, 1
+		}
+	}
+	fmt.Fprintf(buf, "%d %d %d ", layout.StartCode, layout.EndCode, layout.StartStack)
+	fmt.Fprintf(buf, "0 0 " /* kstkesp kstkeip */)
 	fmt.Fprintf(buf, "0 0 0 0 0 " /* signal blocked sigignore sigcatch wchan */)
 	fmt.Fprintf(buf, "0 0 " /* nswap cnswap */)
 	terminationSignal := linux.Signal(0)
@@ -864,8 +876,11 @@ func (s *taskStatData) Generate(ctx context.Context, buf *bytes.Buffer) error {
 	}
 	fmt.Fprintf(buf, "%d ", terminationSignal)
 	fmt.Fprintf(buf, "0 0 0 " /* processor rt_priority policy */)
-	fmt.Fprintf(buf, "0 0 0 " /* delayacct_blkio_ticks guest_time cguest_time */)
-	fmt.Fprintf(buf, "0 0 0 0 0 0 0 " /* start_data end_data start_brk arg_start arg_end env_start env_end */)
+	// Like Linux, delayacct_blkio_ticks is per task even for the thread
+	// group.
+	fmt.Fprintf(buf, "%d ", linux.ClockTFromDuration(s.task.CPUStats().BlkioDelay))
+	fmt.Fprintf(buf, "0 0 " /* guest_time cguest_time */)
+	fmt.Fprintf(buf, "%d %d %d %d %d %d %d ", layout.StartData, layout.EndData, layout.StartBrk, layout.ArgStart, layout.ArgEnd, layout.EnvStart, layout.EnvEnd)
 	fmt.Fprintf(buf, "0\n" /* exit_code */)
 
 	return nil
@@ -1047,17 +1062,71 @@ func (s *statusFD) Generate(ctx context.Context, buf *bytes.Buffer) error {
 	fmt.Fprintf(buf, "CapBnd:\t%016x\n", creds.BoundingCaps)
 	fmt.Fprintf(buf, "CapAmb:\t%016x\n", creds.AmbientCaps)
 	fmt.Fprintf(buf, "Seccomp:\t%d\n", s.task.SeccompMode())
-	// We unconditionally report a single NUMA node. See
-	// pkg/sentry/syscalls/linux/sys_mempolicy.go.
-	fmt.Fprintf(buf, "Mems_allowed:\t1\n")
-	fmt.Fprintf(buf, "Mems_allowed_list:\t0\n")
-	fmt.Fprintf(buf, "voluntary_ctxt_switches:\t%d\n", s.task.CPUStats().VoluntarySwitches)
-	// Involuntary context switches are unsupported because Go runtime
-	// preemption events are not exposed to gVisor.
-	fmt.Fprintf(buf, "nonvoluntary_ctxt_switches:\t0\n")
+	cpus := s.task.Kernel().ApplicationCores()
+	cpuMask := s.task.CPUMask()
+	cpuAllowed := func(cpu uint) bool { return cpuMask[cpu/8]&(1<<(cpu%8)) != 0 }
+	buf.WriteString("Cpus_allowed:\t")
+	writeBitmap(buf, cpus, cpuAllowed)
+	buf.WriteString("\nCpus_allowed_list:\t")
+	writeBitmapList(buf, cpus, cpuAllowed)
+	// Every node is allowed, since memory policies are recorded but don't
+	// affect placement. See pkg/sentry/syscalls/linux/sys_mempolicy.go.
+	nodes := s.task.Kernel().NUMANodes()
+	nodeAllowed := func(uint) bool { return true }
+	buf.WriteString("\nMems_allowed:\t")
+	writeBitmap(buf, nodes, nodeAllowed)
+	buf.WriteString("\nMems_allowed_list:\t")
+	writeBitmapList(buf, nodes, nodeAllowed)
+	buf.WriteString("\n")
+	cputime := s.task.CPUStats()
+	fmt.Fprintf(buf, "voluntary_ctxt_switches:\t%d\n", cputime.VoluntarySwitches)
+	// Only preemption by the sentry is counted; preemption by the Go runtime
+	// or the host kernel is not visible to gVisor.
+	fmt.Fprintf(buf, "nonvoluntary_ctxt_switches:\t%d\n", cputime.InvoluntarySwitches)
 	return nil
 }
 
+// writeBitmap writes the n-bit bitmap in which bit i is set if isSet(i) to
+// buf, as hexadecimal 32-bit words separated by commas, most significant
+// first. See lib/bitmap-str.c:bitmap_print_to_pagebuf().
+func writeBitmap(buf *bytes.Buffer, n uint, isSet func(uint) bool) {
+	for word := int((n+31)/32) - 1; word >= 0; word-- {
+		var val uint32
+		for bit := uint(0); bit < 32; bit++ {
+			if i := uint(word)*32 + bit; i < n && isSet(i) {
+				val |= 1 << bit
+			}
+		}
+		if word == int((n+31)/32)-1 {
+			// The most significant word is only as wide as its bits.
+			fmt.Fprintf(buf, "%0*x", int((n-uint(word)*32+3)/4), val)
+		} else {
+			fmt.Fprintf(buf, ",%08x", val)
+		}
+	}
+}
+
+// writeBitmapList writes the set bits of the n-bit bitmap in which bit i is
+// set if isSet(i) to buf, as a comma-separated list of ranges.
+func writeBitmapList(buf *bytes.Buffer, n uint, isSet func(uint) bool) {
+	sep := ""
+	for i := uint(0); i < n; i++ {
+		if !isSet(i) {
+			continue
+		}
+		start := i
+		for i+1 < n && isSet(i+1) {
+			i++
+		}
+		if start == i {
+			fmt.Fprintf(buf, "%s%d", sep, start)
+		} else {
+			fmt.Fprintf(buf, "%s%d-%d", sep, start, i)
+		}
+		sep = ","
+	}
+}
+
 // ioUsage is the /proc/[pid]/io and /proc/[pid]/task/[tid]/io data provider.
 type ioUsage interface {
 	// IOUsage returns the io usage data.
@@ -56,15 +56,15 @@ func (fs *filesystem) newTaskNetDir(ctx context.Context, task *kernel.Task) kern
 			packet    = "sk       RefCnt Type Proto  Iface R Rmem   User   Inode\n"
 			protocols = "protocol  size sockets  memory press maxhdr  slab module     cl co di ac io in de sh ss gs se re sp bi br ha uh gp em\n"
 			ptype     = "Type Device      Function\n"
-			upd6      = "  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
 		)
 		psched := fmt.Sprintf("%08x %08x %08x %08x\n", uint64(time.Microsecond/time.Nanosecond), 64, 1000000, uint64(time.Second/time.Nanosecond))
 
 		// TODO(gvisor.dev/issue/1833): Make sure file contents reflect the task
 		// network namespace.
 		contents = map[string]kernfs.Inode{
-			"dev":  fs.newInode(ctx, root, 0444, &netDevData{stack: stack}),
-			"snmp": fs.newInode(ctx, root, 0444, &netSnmpData{stack: stack}),
+			"dev":      fs.newInode(ctx, root, 0444, &netDevData{stack: stack}),
+			"snmp":     fs.newInode(ctx, root, 0444, &netSnmpData{stack: stack}),
+			"sockstat": fs.newInode(ctx, root, 0444, &netSockstatData{kernel: k, stack: stack}),
 
 			// The following files are simple stubs until they are implemented in
 			// netstack, if the file contains a header the stub is just the header
@@ -89,7 +89,9 @@ func (fs *filesystem) newTaskNetDir(ctx context.Context, task *kernel.Task) kern
 			contents["if_inet6"] = fs.newInode(ctx, root, 0444, &ifinet6{stack: stack})
 			contents["ipv6_route"] = fs.newInode(ctx, root, 0444, newStaticFile(""))
 			contents["tcp6"] = fs.newInode(ctx, root, 0444, &netTCP6Data{kernel: k})
-			contents["udp6"] = fs.newInode(ctx, root, 0444, newStaticFile(upd6))
+			contents["snmp6"] = fs.newInode(ctx, root, 0444, &netSnmp6Data{stack: stack})
+			contents["sockstat6"] = fs.newInode(ctx, root, 0444, &netSockstat6Data{kernel: k})
+			contents["udp6"] = fs.newInode(ctx, root, 0444, &netUDP6Data{kernel: k})
 		}
 	}
 
@@ -504,27 +506,13 @@ func (d *netTCP6Data) Generate(ctx context.Context, buf *bytes.Buffer) error {
 	return commonGenerateTCP(ctx, buf, d.kernel, linux.AF_INET6)
 }
 
-// netUDPData implements vfs.DynamicBytesSource for /proc/net/udp.
-//
-// +stateify savable
-type netUDPData struct {
-	kernfs.DynamicBytesFile
-
-	kernel *kernel.Kernel
-}
-
-var _ dynamicInode = (*netUDPData)(nil)
-
-// Generate implements vfs.DynamicBytesSource.Generate.
-func (d *netUDPData) Generate(ctx context.Context, buf *bytes.Buffer) error {
+func commonGenerateUDP(ctx context.Context, buf *bytes.Buffer, k *kernel.Kernel, family int) error {
 	// t may be nil here if our caller is not part of a task goroutine. This can
 	// happen for example if we're here for "sentryctl cat". When t is nil,
 	// degrade gracefully and retrieve what we can.
 	t := kernel.TaskFromContext(ctx)
 
-	buf.WriteString("  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops             \n")
-
-	for _, se := range d.kernel.ListSockets() {
+	for _, se := range k.ListSockets() {
 		s := se.Sock
 		if !s.TryIncRef() {
 			// Racing with socket destruction, this is ok.
@@ -534,34 +522,35 @@ func (d *netUDPData) Generate(ctx context.Context, buf *bytes.Buffer) error {
 		if !ok {
 			panic(fmt.Sprintf("Found non-socket file in socket table: %+v", s))
 		}
-		if family, stype, _ := sops.Type(); family != linux.AF_INET || stype != linux.SOCK_DGRAM {
+		if fa, stype, _ := sops.Type(); fa != family || stype != linux.SOCK_DGRAM {
 			s.DecRef(ctx)
-			// Not udp4 socket.
+			// Not a udp socket of this family.
 			continue
 		}
 
-		// For Linux's implementation, see net/ipv4/udp.c:udp4_format_sock().
+		// For Linux's implementation, see net/ipv4/udp.c:udp4_format_sock()
+		// and net/ipv6/datagram.c:__ip6_dgram_sock_seq_show().
 
 		// Field: sl; entry number.
 		fmt.Fprintf(buf, "%5d: ", se.ID)
 
 		// Field: local_adddress.
-		var localAddr linux.SockAddrInet
+		var localAddr linux.SockAddr
 		if t != nil {
 			if local, _, err := sops.GetSockName(t); err == nil {
-				localAddr = *local.(*linux.SockAddrInet)
+				localAddr = local
 			}
 		}
-		writeInetAddr(buf, linux.AF_INET, &localAddr)
+		writeInetAddr(buf, family, localAddr)
 
 		// Field: rem_address.
-		var remoteAddr linux.SockAddrInet
+		var remoteAddr linux.SockAddr
 		if t != nil {
 			if remote, _, err := sops.GetPeerName(t); err == nil {
-				remoteAddr = *remote.(*linux.SockAddrInet)
+				remoteAddr = remote
 			}
 		}
-		writeInetAddr(buf, linux.AF_INET, &remoteAddr)
+		writeInetAddr(buf, family, remoteAddr)
 
 		// Field: state; socket state.
 		fmt.Fprintf(buf, "%02X ", sops.State())
@@ -616,6 +605,40 @@ func (d *netUDPData) Generate(ctx context.Context, buf *bytes.Buffer) error {
 	return nil
 }
 
+// netUDPData implements vfs.DynamicBytesSource for /proc/net/udp.
+//
+// +stateify savable
+type netUDPData struct {
+	kernfs.DynamicBytesFile
+
+	kernel *kernel.Kernel
+}
+
+var _ dynamicInode = (*netUDPData)(nil)
+
+// Generate implements vfs.DynamicBytesSource.Generate.
+func (d *netUDPData) Generate(ctx context.Context, buf *bytes.Buffer) error {
+	buf.WriteString("  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops             \n")
+	return commonGenerateUDP(ctx, buf, d.kernel, linux.AF_INET)
+}
+
+// netUDP6Data implements vfs.DynamicBytesSource for /proc/net/udp6.
+//
+// +stateify savable
+type netUDP6Data struct {
+	kernfs.DynamicBytesFile
+
+	kernel *kernel.Kernel
+}
+
+var _ dynamicInode = (*netUDP6Data)(nil)
+
+// Generate implements vfs.DynamicBytesSource.Generate.
+func (d *netUDP6Data) Generate(ctx context.Context, buf *bytes.Buffer) error {
+	buf.WriteString("  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops\n")
+	return commonGenerateUDP(ctx, buf, d.kernel, linux.AF_INET6)
+}
+
 // netSnmpData implements vfs.DynamicBytesSource for /proc/net/snmp.
 //
 // +stateify savable
@@ -710,6 +733,176 @@ func (d *netSnmpData) Generate(ctx context.Context, buf *bytes.Buffer) error {
 	return nil
 }
 
+// netSnmp6Data implements vfs.DynamicBytesSource for /proc/net/snmp6.
+//
+// +stateify savable
+type netSnmp6Data struct {
+	kernfs.DynamicBytesFile
+
+	stack inet.Stack
+}
+
+var _ dynamicInode = (*netSnmp6Data)(nil)
+
+// snmp6IP holds the field names of inet.StatSNMPIP6, in order.
+var snmp6IP = []string{
+	"Ip6InReceives", "Ip6InHdrErrors", "Ip6InTooBigErrors", "Ip6InNoRoutes",
+	"Ip6InAddrErrors", "Ip6InUnknownProtos", "Ip6InTruncatedPkts",
+	"Ip6InDiscards", "Ip6InDelivers", "Ip6OutForwDatagrams", "Ip6OutRequests",
+	"Ip6OutDiscards", "Ip6OutNoRoutes", "Ip6ReasmTimeout", "Ip6ReasmReqds",
+	"Ip6ReasmOKs", "Ip6ReasmFails", "Ip6FragOKs", "Ip6FragFails",
+	"Ip6FragCreates",
+}
+
+// snmp6ICMPTypes holds the per-type ICMPv6 counter names that make up the
+// tail of inet.StatSNMPICMP6, once for received and once for sent messages.
+var snmp6ICMPTypes = []string{
+	"DestUnreachs", "PktTooBigs", "TimeExcds", "ParmProblems", "Echos",
+	"EchoReplies", "GroupMembQueries", "GroupMembResponses",
+	"GroupMembReductions", "RouterSolicits", "RouterAdvertisements",
+	"NeighborSolicits", "NeighborAdvertisements", "Redirects", "MLDv2Reports",
+}
+
+// Generate implements vfs.DynamicBytesSource.Generate.
+// See Linux's net/ipv6/proc.c:snmp6_seq_show.
+func (d *netSnmp6Data) Generate(ctx context.Context, buf *bytes.Buffer) error {
+	var ip inet.StatSNMPIP6
+	if err := d.stack.Statistics(&ip, "Ip6"); err != nil {
+		log.Warningf("Failed to retrieve Ip6 of /proc/net/snmp6: %v", err)
+	}
+	for i, name := range snmp6IP {
+		fmt.Fprintf(buf, "%-32s\t%d\n", name, ip[i])
+	}
+
+	var icmp inet.StatSNMPICMP6
+	if err := d.stack.Statistics(&icmp, "Icmp6"); err != nil {
+		log.Warningf("Failed to retrieve Icmp6 of /proc/net/snmp6: %v", err)
+	}
+	names := []string{"Icmp6InMsgs", "Icmp6InErrors", "Icmp6OutMsgs", "Icmp6OutErrors", "Icmp6InCsumErrors"}
+	for _, dir := range []string{"In", "Out"} {
+		for _, t := range snmp6ICMPTypes {
+			names = append(names, "Icmp6"+dir+t)
+		}
+	}
+	for i, name := range names {
+		fmt.Fprintf(buf, "%-32s\t%d\n", name, icmp[i])
+	}
+	return nil
+}
+
+// sockCounts holds the number of open sockets of a single address family,
+// as reported by /proc/net/sockstat and /proc/net/sockstat6.
+type sockCounts struct {
+	tcpInUse    int
+	tcpTimeWait int
+	tcpAlloc    int
+	udpInUse    int
+	rawInUse    int
+}
+
+// countSockets tallies the sockets in k's socket table by family. It also
+// returns the total number of sockets of all families.
+func countSockets(ctx context.Context, k *kernel.Kernel) (map[int]*sockCounts, int) {
+	counts := map[int]*sockCounts{
+		linux.AF_INET:  {},
+		linux.AF_INET6: {},
+	}
+	used := 0
+	for _, se := range k.ListSockets() {
+		s := se.Sock
+		if !s.TryIncRef() {
+			// Racing with socket destruction, this is ok.
+			continue
+		}
+		used++
+		sops, ok := s.Impl().(socket.Socket)
+		if !ok {
+			panic(fmt.Sprintf("Found non-socket file in socket table: %+v", s))
+		}
+		family, stype, _ := sops.Type()
+		c, ok := counts[family]
+		if !ok {
+			s.DecRef(ctx)
+			continue
+		}
+		switch stype {
+		case linux.SOCK_STREAM:
+			c.tcpAlloc++
+			switch sops.State() {
+			case linux.TCP_TIME_WAIT:
+				c.tcpTimeWait++
+			case linux.TCP_CLOSE:
+			default:
+				c.tcpInUse++
+			}
+		case linux.SOCK_DGRAM:
+			c.udpInUse++
+		case linux.SOCK_RAW:
+			c.rawInUse++
+		}
+		s.DecRef(ctx)
+	}
+	return counts, used
+}
+
+// netSockstatData implements vfs.DynamicBytesSource for /proc/net/sockstat.
+//
+// +stateify savable
+type netSockstatData struct {
+	kernfs.DynamicBytesFile
+
+	kernel *kernel.Kernel
+	stack  inet.Stack
+}
+
+var _ dynamicInode = (*netSockstatData)(nil)
+
+// Generate implements vfs.DynamicBytesSource.Generate.
+// See Linux's net/ipv4/proc.c:sockstat_seq_show.
+func (d *netSockstatData) Generate(ctx context.Context, buf *bytes.Buffer) error {
+	counts, used := countSockets(ctx, d.kernel)
+	v4, v6 := counts[linux.AF_INET], counts[linux.AF_INET6]
+
+	var tcpMem inet.StatTCPMem
+	if err := d.stack.Statistics(&tcpMem, "TcpMem"); err != nil {
+		log.Debugf("Failed to retrieve TCP memory usage for /proc/net/sockstat: %v", err)
+	}
+
+	fmt.Fprintf(buf, "sockets: used %d\n", used)
+	// Like Linux, the TIME_WAIT and allocated TCP counts include IPv6 sockets.
+	fmt.Fprintf(buf, "TCP: inuse %d orphan %d tw %d alloc %d mem %d\n", v4.tcpInUse, 0, v4.tcpTimeWait+v6.tcpTimeWait, v4.tcpAlloc+v6.tcpAlloc, tcpMem)
+	fmt.Fprintf(buf, "UDP: inuse %d mem %d\n", v4.udpInUse, 0)
+	fmt.Fprintf(buf, "UDPLITE: inuse %d\n", 0)
+	fmt.Fprintf(buf, "RAW: inuse %d\n", v4.rawInUse)
+	fmt.Fprintf(buf, "FRAG: inuse %d memory %d\n", 0, 0)
+	return nil
+}
+
+// netSockstat6Data implements vfs.DynamicBytesSource for /proc/net/sockstat6.
+//
+// +stateify savable
+type netSockstat6Data struct {
+	kernfs.DynamicBytesFile
+
+	kernel *kernel.Kernel
+}
+
+var _ dynamicInode = (*netSockstat6Data)(nil)
+
+// Generate implements vfs.DynamicBytesSource.Generate.
+// See Linux's net/ipv6/proc.c:sockstat6_seq_show.
+func (d *netSockstat6Data) Generate(ctx context.Context, buf *bytes.Buffer) error {
+	counts, _ := countSockets(ctx, d.kernel)
+	v6 := counts[linux.AF_INET6]
+
+	fmt.Fprintf(buf, "TCP6: inuse %d\n", v6.tcpInUse)
+	fmt.Fprintf(buf, "UDP6: inuse %d\n", v6.udpInUse)
+	fmt.Fprintf(buf, "UDPLITE6: inuse %d\n", 0)
+	fmt.Fprintf(buf, "RAW6: inuse %d\n", v6.rawInUse)
+	fmt.Fprintf(buf, "FRAG6: inuse %d memory %d\n", 0, 0)
+	return nil
+}
+
 // netRouteData implements vfs.DynamicBytesSource for /proc/net/route.
 //
 // +stateify savable
@@ -96,6 +96,11 @@ func (fs *filesystem) newTasksInode(ctx context.Context, k *kernel.Kernel, pidns
 		"uptime":         fs.newInode(ctx, root, 0444, &uptimeData{}),
 		"version":        fs.newInode(ctx, root, 0444, &versionData{}),
 	}
+	contents["pressure"] = fs.newStaticDir(ctx, root, map[string]kernfs.Inode{
+		"cpu":    fs.newInode(ctx, root, 0444, &pressureData{resource: "cpu"}),
+		"io":     fs.newInode(ctx, root, 0444, &pressureData{resource: "io"}),
+		"memory": fs.newInode(ctx, root, 0444, &pressureData{resource: "memory"}),
+	})
 	// If fakeCgroupControllers are provided, don't create a cgroupfs backed
 	// /proc/cgroup as it will not match the fake controllers.
 	if len(internalData.Cgroups) == 0 {
@@ -285,8 +290,12 @@ func newStaticFileSetStat(data string) *staticFileSetStat {
 func cpuInfoData(k *kernel.Kernel) string {
 	features := k.FeatureSet()
 	var buf bytes.Buffer
+	// Each NUMA node is reported as a distinct physical package, consistent
+	// with sysfs.
 	for i, max := uint(0), k.ApplicationCores(); i < max; i++ {
-		features.WriteCPUInfoTo(i, max, &buf)
+		node := k.CPUNode(int32(i))
+		start, end := k.NUMANodeCPUs(node)
+		features.WriteCPUInfoTo(i, node, i-start, end-start, &buf)
 	}
 	return buf.String()
 }
@@ -282,11 +282,52 @@ var _ dynamicInode = (*loadavgData)(nil)
 
 // Generate implements vfs.DynamicBytesSource.Generate.
 func (*loadavgData) Generate(ctx context.Context, buf *bytes.Buffer) error {
-	// TODO(b/62345059): Include real data in fields.
-	// Column 1-3: CPU and IO utilization of the last 1, 5, and 10 minute periods.
-	// Column 4-5: currently running processes and the total number of processes.
+	// Column 1-3: CPU and IO utilization of the last 1, 5, and 15 minute periods.
+	// Column 4-5: currently running tasks and the total number of tasks.
 	// Column 6: the last process ID used.
-	fmt.Fprintf(buf, "%.2f %.2f %.2f %d/%d %d\n", 0.00, 0.00, 0.00, 0, 0, 0)
+	t := kernel.TaskFromContext(ctx)
+	if t == nil {
+		fmt.Fprintf(buf, "%.2f %.2f %.2f %d/%d %d\n", 0.00, 0.00, 0.00, 0, 0, 0)
+		return nil
+	}
+	// Statistics cover only the tasks in the reader's container.
+	s := t.Kernel().ContainerSchedStats(t.ContainerID())
+	fmt.Fprintf(buf, "%.2f %.2f %.2f %d/%d %d\n", s.LoadAvg[0], s.LoadAvg[1], s.LoadAvg[2], s.Runnable, s.Tasks, t.PIDNamespace().LastTID())
+	return nil
+}
+
+// pressureData backs the files in /proc/pressure.
+//
+// +stateify savable
+type pressureData struct {
+	dynamicBytesFileSetAttr
+
+	// resource is the name of the file: "cpu", "io" or "memory".
+	resource string
+}
+
+var _ dynamicInode = (*pressureData)(nil)
+
+// Generate implements vfs.DynamicBytesSource.Generate.
+func (d *pressureData) Generate(ctx context.Context, buf *bytes.Buffer) error {
+	var ps kernel.PressureStats
+	if t := kernel.TaskFromContext(ctx); t != nil {
+		s := t.Kernel().ContainerSchedStats(t.ContainerID())
+		switch d.resource {
+		case "cpu":
+			ps = s.CPU
+		case "io":
+			ps = s.IO
+		case "memory":
+			ps = s.Memory
+		}
+	}
+	for _, l := range []struct {
+		name string
+		v    kernel.PressureValues
+	}{{"some", ps.Some}, {"full", ps.Full}} {
+		fmt.Fprintf(buf, "%s avg10=%.2f avg60=%.2f avg300=%.2f total=%d\n", l.name, l.v.Avg[0], l.v.Avg[1], l.v.Avg[2], l.v.Total.Microseconds())
+	}
 	return nil
 }
 
@@ -442,7 +483,11 @@ var _ dynamicInode = (*cmdLineData)(nil)
 
 // Generate implements vfs.DynamicByteSource.Generate.
 func (*cmdLineData) Generate(ctx context.Context, buf *bytes.Buffer) error {
-	fmt.Fprintf(buf, "BOOT_IMAGE=/vmlinuz-%s-gvisor quiet\n", kernelVersion(ctx).Release)
+	fmt.Fprintf(buf, "BOOT_IMAGE=/vmlinuz-%s-gvisor quiet", kernelVersion(ctx).Release)
+	if cmdLine := kernel.KernelFromContext(ctx).CmdLine(); cmdLine != "" {
+		fmt.Fprintf(buf, " %s", cmdLine)
+	}
+	buf.WriteString("\n")
 	return nil
 }
 
@@ -456,7 +501,7 @@ func kernelVersion(ctx context.Context) kernel.Version {
 		// this file.
 		panic("Attempted to read version before initial Task is available")
 	}
-	return init.Leader().SyscallTable().Version
+	return init.Leader().KernelVersion()
 }
 
 // devicesData backs /proc/devices.
@@ -58,7 +58,7 @@ func (fs *filesystem) newSysDir(ctx context.Context, root *auth.Credentials, k *
 			"pid_max":            fs.newInode(ctx, root, 0644, newStaticFile(fmt.Sprintf("%d\n", kernel.TasksLimit))),
 			"randomize_va_space": fs.newInode(ctx, root, 0644, newStaticFile("2\n")),
 			"random": fs.newStaticDir(ctx, root, map[string]kernfs.Inode{
-				"boot_id": fs.newInode(ctx, root, 0444, newStaticFile(randUUID())),
+				"boot_id": fs.newInode(ctx, root, 0444, &bootIDData{}),
 				"uuid":    fs.newInode(ctx, root, 0444, &uuidData{}),
 			}),
 			"sem":    fs.newInode(ctx, root, 0444, newStaticFile(fmt.Sprintf("%d\t%d\t%d\t%d\n", linux.SEMMSL, linux.SEMMNS, linux.SEMOPM, linux.SEMMNI))),
@@ -74,12 +74,12 @@ func (fs *filesystem) newSysDir(ctx context.Context, root *auth.Credentials, k *
 			"keys": fs.newStaticDir(ctx, root, map[string]kernfs.Inode{
 				"maxkeys": fs.newMaxKeySizeFile(ctx, k, root),
 			}),
-			"osrelease": fs.newInode(ctx, root, 0444, newStaticFile(version.LinuxRelease)),
+			"osrelease": fs.newInode(ctx, root, 0444, &osReleaseData{}),
 			"ostype":    fs.newInode(ctx, root, 0444, newStaticFile(version.LinuxSysname)),
-			"version":   fs.newInode(ctx, root, 0444, newStaticFile(version.LinuxVersion)),
+			"version":   fs.newInode(ctx, root, 0444, &osVersionData{}),
 		}),
 		"fs": fs.newStaticDir(ctx, root, map[string]kernfs.Inode{
-			"nr_open":       fs.newInode(ctx, root, 0644, &atomicInt32File{val: &k.MaxFDLimit, min: 8, max: kernel.MaxFdLimit}),
+			"nr_open":       fs.newSysctlInode(ctx, root, "fs.nr_open", 0644, &atomicInt32File{val: &k.MaxFDLimit, min: 8, max: kernel.MaxFdLimit}),
 			"pipe-max-size": fs.newInode(ctx, root, 0644, newStaticFile(fmt.Sprintf("%d\n", pipe.MaximumPipeSize))),
 		}),
 		"vm": fs.newStaticDir(ctx, root, map[string]kernfs.Inode{
@@ -101,11 +101,13 @@ func (fs *filesystem) newSysNetDir(ctx context.Context, root *auth.Credentials,
 		contents = map[string]kernfs.Inode{
 			"ipv4": fs.newStaticDir(ctx, root, map[string]kernfs.Inode{
 				"ip_forward":          fs.newInode(ctx, root, 0444, &ipForwarding{stack: stack}),
-				"ip_local_port_range": fs.newInode(ctx, root, 0644, &portRange{stack: stack}),
-				"tcp_recovery":        fs.newInode(ctx, root, 0644, &tcpRecoveryData{stack: stack}),
-				"tcp_rmem":            fs.newInode(ctx, root, 0644, &tcpMemData{stack: stack, dir: tcpRMem}),
-				"tcp_sack":            fs.newInode(ctx, root, 0644, &tcpSackData{stack: stack}),
-				"tcp_wmem":            fs.newInode(ctx, root, 0644, &tcpMemData{stack: stack, dir: tcpWMem}),
+				"ip_local_port_range": fs.newSysctlInode(ctx, root, "net.ipv4.ip_local_port_range", 0644, &portRange{stack: stack}),
+				"tcp_mem":             fs.newSysctlInode(ctx, root, "net.ipv4.tcp_mem", 0644, &tcpMemLimitsData{stack: stack}),
+				"tcp_moderate_rcvbuf": fs.newSysctlInode(ctx, root, "net.ipv4.tcp_moderate_rcvbuf", 0644, &tcpModerateRcvBufData{stack: stack}),
+				"tcp_recovery":        fs.newSysctlInode(ctx, root, "net.ipv4.tcp_recovery", 0644, &tcpRecoveryData{stack: stack}),
+				"tcp_rmem":            fs.newSysctlInode(ctx, root, "net.ipv4.tcp_rmem", 0644, &tcpMemData{stack: stack, dir: tcpRMem}),
+				"tcp_sack":            fs.newSysctlInode(ctx, root, "net.ipv4.tcp_sack", 0644, &tcpSackData{stack: stack}),
+				"tcp_wmem":            fs.newSysctlInode(ctx, root, "net.ipv4.tcp_wmem", 0644, &tcpMemData{stack: stack, dir: tcpWMem}),
 
 				// The following files are simple stubs until they are implemented in
 				// netstack, most of these files are configuration related. We use the
@@ -116,6 +118,11 @@ func (fs *filesystem) newSysNetDir(ctx context.Context, root *auth.Credentials,
 				"ip_nonlocal_bind":        fs.newInode(ctx, root, 0444, newStaticFile("0")),
 				"ip_no_pmtu_disc":         fs.newInode(ctx, root, 0444, newStaticFile("1")),
 
+				// Netstack neither restricts binding to low ports nor creating
+				// ICMP datagram sockets.
+				"ip_unprivileged_port_start": fs.newInode(ctx, root, 0444, newStaticFile("0")),
+				"ping_group_range":           fs.newInode(ctx, root, 0444, newStaticFile("0\t2147483647")),
+
 				// tcp_allowed_congestion_control tell the user what they are able to
 				// do as an unprivledged process so we leave it empty.
 				"tcp_allowed_congestion_control":   fs.newInode(ctx, root, 0444, newStaticFile("")),
@@ -223,6 +230,23 @@ func (*domainnameData) Generate(ctx context.Context, buf *bytes.Buffer) error {
 	return nil
 }
 
+// bootIDData implements vfs.DynamicBytesSource for
+// /proc/sys/kernel/random/boot_id.
+//
+// +stateify savable
+type bootIDData struct {
+	kernfs.DynamicBytesFile
+}
+
+var _ dynamicInode = (*bootIDData)(nil)
+
+// Generate implements vfs.DynamicBytesSource.Generate.
+func (*bootIDData) Generate(ctx context.Context, buf *bytes.Buffer) error {
+	buf.WriteString(kernel.KernelFromContext(ctx).BootID())
+	buf.WriteString("\n")
+	return nil
+}
+
 // uuidData implements vfs.DynamicBytesSource for /proc/sys/kernel/random/uuid.
 //
 // +stateify savable
@@ -335,6 +359,106 @@ func (d *tcpRecoveryData) Write(ctx context.Context, _ *vfs.FileDescription, src
 	return n, nil
 }
 
+// tcpMemLimitsData implements vfs.WritableDynamicBytesSource for
+// /proc/sys/net/ipv4/tcp_mem.
+//
+// +stateify savable
+type tcpMemLimitsData struct {
+	kernfs.DynamicBytesFile
+
+	stack inet.Stack `state:"wait"`
+
+	// mu protects against concurrent reads/writes to FDs based on the dentry
+	// backing this byte source.
+	mu sync.Mutex `state:"nosave"`
+}
+
+var _ vfs.WritableDynamicBytesSource = (*tcpMemLimitsData)(nil)
+
+// Generate implements vfs.DynamicBytesSource.Generate.
+func (d *tcpMemLimitsData) Generate(ctx context.Context, buf *bytes.Buffer) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	limits, err := d.stack.TCPMemoryLimits()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(buf, "%d\t%d\t%d\n", limits.Min, limits.Pressure, limits.Max)
+	return err
+}
+
+// Write implements vfs.WritableDynamicBytesSource.Write.
+func (d *tcpMemLimitsData) Write(ctx context.Context, _ *vfs.FileDescription, src usermem.IOSequence, offset int64) (int64, error) {
+	if offset != 0 {
+		// No need to handle partial writes thus far.
+		return 0, linuxerr.EINVAL
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	limits, err := d.stack.TCPMemoryLimits()
+	if err != nil {
+		return 0, err
+	}
+	buf := []int32{int32(limits.Min), int32(limits.Pressure), int32(limits.Max)}
+	n, err := ParseInt32Vec(ctx, src, buf)
+	if err != nil || n == 0 {
+		return 0, err
+	}
+	newLimits := inet.TCPMemoryLimits{
+		Min:      int(buf[0]),
+		Pressure: int(buf[1]),
+		Max:      int(buf[2]),
+	}
+	if err := d.stack.SetTCPMemoryLimits(newLimits); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// tcpModerateRcvBufData implements vfs.WritableDynamicBytesSource for
+// /proc/sys/net/ipv4/tcp_moderate_rcvbuf.
+//
+// +stateify savable
+type tcpModerateRcvBufData struct {
+	kernfs.DynamicBytesFile
+
+	stack inet.Stack `state:"wait"`
+}
+
+var _ vfs.WritableDynamicBytesSource = (*tcpModerateRcvBufData)(nil)
+
+// Generate implements vfs.DynamicBytesSource.Generate.
+func (d *tcpModerateRcvBufData) Generate(ctx context.Context, buf *bytes.Buffer) error {
+	enabled, err := d.stack.TCPModerateReceiveBuffer()
+	if err != nil {
+		return err
+	}
+	val := "0\n"
+	if enabled {
+		val = "1\n"
+	}
+	_, err = buf.WriteString(val)
+	return err
+}
+
+// Write implements vfs.WritableDynamicBytesSource.Write.
+func (d *tcpModerateRcvBufData) Write(ctx context.Context, _ *vfs.FileDescription, src usermem.IOSequence, offset int64) (int64, error) {
+	if offset != 0 {
+		// No need to handle partial writes thus far.
+		return 0, linuxerr.EINVAL
+	}
+	buf := make([]int32, 1)
+	n, err := ParseInt32Vec(ctx, src, buf)
+	if err != nil || n == 0 {
+		return 0, err
+	}
+	if err := d.stack.SetTCPModerateReceiveBuffer(buf[0] != 0); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
 // tcpMemData implements vfs.WritableDynamicBytesSource for
 // /proc/sys/net/ipv4/tcp_rmem and /proc/sys/net/ipv4/tcp_wmem.
 //
@@ -559,6 +683,38 @@ func (f *atomicInt32File) Write(ctx context.Context, _ *vfs.FileDescription, src
 	return n, nil
 }
 
+// osReleaseData implements vfs.DynamicBytesSource for
+// /proc/sys/kernel/osrelease.
+//
+// +stateify savable
+type osReleaseData struct {
+	kernfs.DynamicBytesFile
+}
+
+var _ dynamicInode = (*osReleaseData)(nil)
+
+// Generate implements vfs.DynamicBytesSource.Generate.
+func (*osReleaseData) Generate(ctx context.Context, buf *bytes.Buffer) error {
+	buf.WriteString(kernelVersion(ctx).Release)
+	return nil
+}
+
+// osVersionData implements vfs.DynamicBytesSource for
+// /proc/sys/kernel/version.
+//
+// +stateify savable
+type osVersionData struct {
+	kernfs.DynamicBytesFile
+}
+
+var _ dynamicInode = (*osVersionData)(nil)
+
+// Generate implements vfs.DynamicBytesSource.Generate.
+func (*osVersionData) Generate(ctx context.Context, buf *bytes.Buffer) error {
+	buf.WriteString(kernelVersion(ctx).Version)
+	return nil
+}
+
 // randUUID returns a string containing a randomly-generated UUID followed by a
 // newline.
 func randUUID() string {
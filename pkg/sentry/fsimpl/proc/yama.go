@@ -30,9 +30,7 @@ import (
 )
 
 func (fs *filesystem) newYAMAPtraceScopeFile(ctx context.Context, k *kernel.Kernel, creds *auth.Credentials) kernfs.Inode {
-	s := &yamaPtraceScope{level: &k.YAMAPtraceScope}
-	s.Init(ctx, creds, linux.UNNAMED_MAJOR, fs.devMinor, fs.NextIno(), s, 0644)
-	return s
+	return fs.newSysctlInode(ctx, creds, "kernel.yama.ptrace_scope", 0644, &yamaPtraceScope{level: &k.YAMAPtraceScope})
 }
 
 // yamaPtraceScope implements vfs.WritableDynamicBytesSource for
@@ -125,7 +125,8 @@ func (fsType FilesystemType) GetFilesystem(ctx context.Context, vfsObj *vfs.Virt
 	}
 	devicesSub := map[string]kernfs.Inode{
 		"system": fs.newDir(ctx, creds, defaultSysDirMode, map[string]kernfs.Inode{
-			"cpu": cpuDir(ctx, fs, creds),
+			"cpu":  cpuDir(ctx, fs, creds),
+			"node": nodeDir(ctx, fs, creds),
 		}),
 	}
 
@@ -216,27 +217,86 @@ func cpuDir(ctx context.Context, fs *filesystem, creds *auth.Credentials) kernfs
 	k := kernel.KernelFromContext(ctx)
 	maxCPUCores := k.ApplicationCores()
 	children := map[string]kernfs.Inode{
-		"online":   fs.newCPUFile(ctx, creds, maxCPUCores, defaultSysMode),
+		"offline":  fs.newCPUOnlineFile(ctx, creds, k, false /* online */, defaultSysMode),
+		"online":   fs.newCPUOnlineFile(ctx, creds, k, true /* online */, defaultSysMode),
 		"possible": fs.newCPUFile(ctx, creds, maxCPUCores, defaultSysMode),
 		"present":  fs.newCPUFile(ctx, creds, maxCPUCores, defaultSysMode),
 	}
-	// For consistency with /proc/cpuinfo, pretend all CPUs are in the same
-	// socket and each CPU is a distinct core.
-	fullMask := fullCPUMask(maxCPUCores) + "\n"
+	// For consistency with /proc/cpuinfo, pretend each NUMA node is a
+	// distinct socket and each CPU is a distinct core.
 	for i := uint(0); i < maxCPUCores; i++ {
+		node := k.CPUNode(int32(i))
+		start, end := k.NUMANodeCPUs(node)
 		oneMask := oneCPUMask(i, maxCPUCores) + "\n"
-		children[fmt.Sprintf("cpu%d", i)] = fs.newDir(ctx, creds, defaultSysDirMode, map[string]kernfs.Inode{
+		packageMask := rangeCPUMask(start, end, maxCPUCores) + "\n"
+		cpuChildren := map[string]kernfs.Inode{
+			fmt.Sprintf("node%d", node): kernfs.NewStaticSymlink(ctx, creds, linux.UNNAMED_MAJOR, fs.devMinor, fs.NextIno(), fmt.Sprintf("../../node/node%d", node)),
 			"topology": fs.newDir(ctx, creds, defaultSysDirMode, map[string]kernfs.Inode{
-				"core_cpus":       fs.newStaticFile(ctx, creds, defaultSysMode, oneMask),
-				"core_siblings":   fs.newStaticFile(ctx, creds, defaultSysMode, fullMask),
-				"package_cpus":    fs.newStaticFile(ctx, creds, defaultSysMode, fullMask),
-				"thread_siblings": fs.newStaticFile(ctx, creds, defaultSysMode, oneMask),
+				"core_cpus":           fs.newStaticFile(ctx, creds, defaultSysMode, oneMask),
+				"core_id":             fs.newStaticFile(ctx, creds, defaultSysMode, fmt.Sprintf("%d\n", i-start)),
+				"core_siblings":       fs.newStaticFile(ctx, creds, defaultSysMode, packageMask),
+				"package_cpus":        fs.newStaticFile(ctx, creds, defaultSysMode, packageMask),
+				"physical_package_id": fs.newStaticFile(ctx, creds, defaultSysMode, fmt.Sprintf("%d\n", node)),
+				"thread_siblings":     fs.newStaticFile(ctx, creds, defaultSysMode, oneMask),
 			}),
+		}
+		// Like Linux on most architectures, CPU 0 can't be taken offline
+		// and therefore has no online file.
+		if i != 0 {
+			cpuChildren["online"] = fs.newCPUStateFile(ctx, creds, k, i, defaultSysMode)
+		}
+		children[fmt.Sprintf("cpu%d", i)] = fs.newDir(ctx, creds, defaultSysDirMode, cpuChildren)
+	}
+	return fs.newDir(ctx, creds, defaultSysDirMode, children)
+}
+
+// nodeDir returns /sys/devices/system/node, which describes the sandbox's
+// virtual NUMA topology. See kernel.Kernel.CPUNode.
+func nodeDir(ctx context.Context, fs *filesystem, creds *auth.Credentials) kernfs.Inode {
+	k := kernel.KernelFromContext(ctx)
+	maxCPUCores := k.ApplicationCores()
+	numNodes := k.NUMANodes()
+	allNodes := rangeList(0, numNodes) + "\n"
+	children := map[string]kernfs.Inode{
+		"has_cpu":           fs.newStaticFile(ctx, creds, defaultSysMode, allNodes),
+		"has_memory":        fs.newStaticFile(ctx, creds, defaultSysMode, allNodes),
+		"has_normal_memory": fs.newStaticFile(ctx, creds, defaultSysMode, allNodes),
+		"online":            fs.newStaticFile(ctx, creds, defaultSysMode, allNodes),
+		"possible":          fs.newStaticFile(ctx, creds, defaultSysMode, allNodes),
+	}
+	for node := uint(0); node < numNodes; node++ {
+		start, end := k.NUMANodeCPUs(node)
+		children[fmt.Sprintf("node%d", node)] = fs.newDir(ctx, creds, defaultSysDirMode, map[string]kernfs.Inode{
+			"cpulist":  fs.newStaticFile(ctx, creds, defaultSysMode, rangeList(start, end)+"\n"),
+			"cpumap":   fs.newStaticFile(ctx, creds, defaultSysMode, rangeCPUMask(start, end, maxCPUCores)+"\n"),
+			"distance": fs.newStaticFile(ctx, creds, defaultSysMode, nodeDistances(node, numNodes)+"\n"),
 		})
 	}
 	return fs.newDir(ctx, creds, defaultSysDirMode, children)
 }
 
+// nodeDistances returns the contents of /sys/devices/system/node/node*/distance
+// for the given node, using the ACPI SLIT defaults of 10 for local accesses and
+// 20 for remote accesses.
+func nodeDistances(node, numNodes uint) string {
+	distances := make([]string, numNodes)
+	for i := range distances {
+		distances[i] = "20"
+	}
+	distances[node] = "10"
+	return strings.Join(distances, " ")
+}
+
+// rangeList returns a "list format ASCII string", consistent with Linux's
+// lib/bitmap.c:bitmap_print_to_pagebuf(list=true), representing the
+// non-empty range [start, end).
+func rangeList(start, end uint) string {
+	if end-start == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d-%d", start, end-1)
+}
+
 // fullCPUMask returns a "hex format ASCII string", consistent with Linux's
 // include/linux/cpumask.h:cpumap_print_to_pagebuf(list=false) =>
 // lib/bitmap.c:bitmap_print_to_pagebuf(list=false), representing a CPU bitmask
@@ -266,13 +326,26 @@ func fullCPUMask(cores uint) string {
 //
 // Preconditions: i < cores.
 func oneCPUMask(i, cores uint) string {
+	return rangeCPUMask(i, i+1, cores)
+}
+
+// rangeCPUMask returns a "hex format ASCII string", consistent with Linux's
+// include/linux/cpumask.h:cpumap_print_to_pagebuf(list=false) =>
+// lib/bitmap.c:bitmap_print_to_pagebuf(list=false), representing a CPU bitmask
+// for `cores` CPUs in which CPUs [start, end) are set.
+//
+// Preconditions: start <= end <= cores.
+func rangeCPUMask(start, end, cores uint) string {
 	var (
 		b   strings.Builder
 		sep string
 	)
+	// word returns the 32 bits of the mask starting at CPU `cores`.
 	word := func() (w uint32) {
-		if cores <= i {
-			w = uint32(1) << (i - cores)
+		for bit := uint(0); bit < 32; bit++ {
+			if cpu := cores + bit; cpu >= start && cpu < end {
+				w |= uint32(1) << bit
+			}
 		}
 		return
 	}
@@ -439,7 +512,7 @@ type cpuFile struct {
 
 // Generate implements vfs.DynamicBytesSource.Generate.
 func (c *cpuFile) Generate(ctx context.Context, buf *bytes.Buffer) error {
-	fmt.Fprintf(buf, "0-%d\n", c.maxCores-1)
+	fmt.Fprintf(buf, "%s\n", rangeList(0, c.maxCores))
 	return nil
 }
 
@@ -449,6 +522,66 @@ func (fs *filesystem) newCPUFile(ctx context.Context, creds *auth.Credentials, m
 	return c
 }
 
+// cpuOnlineFile implements kernfs.Inode for /sys/devices/system/cpu/online
+// and /sys/devices/system/cpu/offline.
+//
+// +stateify savable
+type cpuOnlineFile struct {
+	implStatFS
+	kernfs.DynamicBytesFile
+
+	k *kernel.Kernel
+
+	// online is true for the online file and false for the offline file.
+	online bool
+}
+
+// Generate implements vfs.DynamicBytesSource.Generate.
+func (c *cpuOnlineFile) Generate(ctx context.Context, buf *bytes.Buffer) error {
+	online := c.k.OnlineCPUs()
+	if c.online {
+		buf.WriteString(rangeList(0, online))
+	} else if possible := c.k.ApplicationCores(); online < possible {
+		buf.WriteString(rangeList(online, possible))
+	}
+	buf.WriteString("\n")
+	return nil
+}
+
+func (fs *filesystem) newCPUOnlineFile(ctx context.Context, creds *auth.Credentials, k *kernel.Kernel, online bool, mode linux.FileMode) kernfs.Inode {
+	c := &cpuOnlineFile{k: k, online: online}
+	c.DynamicBytesFile.Init(ctx, creds, linux.UNNAMED_MAJOR, fs.devMinor, fs.NextIno(), c, mode)
+	return c
+}
+
+// cpuStateFile implements kernfs.Inode for
+// /sys/devices/system/cpu/cpu*/online.
+//
+// +stateify savable
+type cpuStateFile struct {
+	implStatFS
+	kernfs.DynamicBytesFile
+
+	k   *kernel.Kernel
+	cpu uint
+}
+
+// Generate implements vfs.DynamicBytesSource.Generate.
+func (c *cpuStateFile) Generate(ctx context.Context, buf *bytes.Buffer) error {
+	if c.k.CPUOnline(c.cpu) {
+		buf.WriteString("1\n")
+	} else {
+		buf.WriteString("0\n")
+	}
+	return nil
+}
+
+func (fs *filesystem) newCPUStateFile(ctx context.Context, creds *auth.Credentials, k *kernel.Kernel, cpu uint, mode linux.FileMode) kernfs.Inode {
+	c := &cpuStateFile{k: k, cpu: cpu}
+	c.DynamicBytesFile.Init(ctx, creds, linux.UNNAMED_MAJOR, fs.devMinor, fs.NextIno(), c, mode)
+	return c
+}
+
 // +stateify savable
 type implStatFS struct{}
 
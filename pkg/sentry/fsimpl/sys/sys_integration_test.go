@@ -67,22 +67,63 @@ func TestReadCPUFile(t *testing.T) {
 	maxCPUCores := k.ApplicationCores()
 
 	expected := fmt.Sprintf("0-%d\n", maxCPUCores-1)
+	if maxCPUCores == 1 {
+		expected = "0\n"
+	}
 
 	for _, fname := range []string{"online", "possible", "present"} {
-		pop := s.PathOpAtRoot(fmt.Sprintf("devices/system/cpu/%s", fname))
-		fd, err := s.VFS.OpenAt(s.Ctx, s.Creds, pop, &vfs.OpenOptions{})
-		if err != nil {
-			t.Fatalf("OpenAt(pop:%+v) = %+v failed: %v", pop, fd, err)
+		if diff := cmp.Diff(expected, readFile(t, s, fmt.Sprintf("devices/system/cpu/%s", fname))); diff != "" {
+			t.Fatalf("Read returned unexpected data:\n--- want\n+++ got\n%v", diff)
 		}
-		defer fd.DecRef(s.Ctx)
-		content, err := s.ReadToEnd(fd)
-		if err != nil {
-			t.Fatalf("Read failed: %v", err)
+	}
+	if diff := cmp.Diff("\n", readFile(t, s, "devices/system/cpu/offline")); diff != "" {
+		t.Fatalf("Read returned unexpected data:\n--- want\n+++ got\n%v", diff)
+	}
+}
+
+func TestCPUHotplug(t *testing.T) {
+	s := newTestSystem(t, "" /*pciTestDir*/)
+	defer s.Destroy()
+	k := kernel.KernelFromContext(s.Ctx)
+	maxCPUCores := k.ApplicationCores()
+	if maxCPUCores < 2 {
+		t.Skipf("CPU hotplug requires at least 2 CPUs, have %d", maxCPUCores)
+	}
+	defer k.SetOnlineCPUs(s.Ctx, maxCPUCores)
+
+	k.SetOnlineCPUs(s.Ctx, 1)
+	for path, want := range map[string]string{
+		"devices/system/cpu/online":      "0\n",
+		"devices/system/cpu/offline":     fmt.Sprintf("1-%d\n", maxCPUCores-1),
+		"devices/system/cpu/cpu1/online": "0\n",
+	} {
+		if maxCPUCores == 2 && path == "devices/system/cpu/offline" {
+			want = "1\n"
 		}
-		if diff := cmp.Diff(expected, content); diff != "" {
-			t.Fatalf("Read returned unexpected data:\n--- want\n+++ got\n%v", diff)
+		if diff := cmp.Diff(want, readFile(t, s, path)); diff != "" {
+			t.Errorf("Read %s returned unexpected data:\n--- want\n+++ got\n%v", path, diff)
 		}
 	}
+
+	k.SetOnlineCPUs(s.Ctx, maxCPUCores)
+	if diff := cmp.Diff("1\n", readFile(t, s, "devices/system/cpu/cpu1/online")); diff != "" {
+		t.Errorf("Read returned unexpected data:\n--- want\n+++ got\n%v", diff)
+	}
+}
+
+func readFile(t *testing.T, s *testutil.System, path string) string {
+	t.Helper()
+	pop := s.PathOpAtRoot(path)
+	fd, err := s.VFS.OpenAt(s.Ctx, s.Creds, pop, &vfs.OpenOptions{})
+	if err != nil {
+		t.Fatalf("OpenAt(pop:%+v) = %+v failed: %v", pop, fd, err)
+	}
+	defer fd.DecRef(s.Ctx)
+	content, err := s.ReadToEnd(fd)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	return content
 }
 
 func TestSysRootContainsExpectedEntries(t *testing.T) {
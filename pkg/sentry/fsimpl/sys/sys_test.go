@@ -74,3 +74,43 @@ func TestOneCPUMask(t *testing.T) {
 		}
 	}
 }
+
+func TestRangeCPUMask(t *testing.T) {
+	for _, test := range []struct {
+		start uint
+		end   uint
+		cores uint
+		want  string
+	}{
+		{0, 0, 4, "0"},
+		{0, 4, 4, "f"},
+		{1, 3, 4, "6"},
+		{2, 4, 5, "0c"},
+		{0, 16, 32, "0000ffff"},
+		{16, 32, 32, "ffff0000"},
+		{30, 34, 40, "03,c0000000"},
+		{32, 64, 64, "ffffffff,00000000"},
+		{0, 65, 65, "1,ffffffff,ffffffff"},
+	} {
+		if got := rangeCPUMask(test.start, test.end, test.cores); got != test.want {
+			t.Errorf("rangeCPUMask(%d, %d, %d): got %s, want %s", test.start, test.end, test.cores, got, test.want)
+		}
+	}
+}
+
+func TestRangeList(t *testing.T) {
+	for _, test := range []struct {
+		start uint
+		end   uint
+		want  string
+	}{
+		{0, 1, "0"},
+		{3, 4, "3"},
+		{0, 2, "0-1"},
+		{4, 8, "4-7"},
+	} {
+		if got := rangeList(test.start, test.end); got != test.want {
+			t.Errorf("rangeList(%d, %d): got %s, want %s", test.start, test.end, got, test.want)
+		}
+	}
+}
@@ -137,6 +137,11 @@ func (tfd *TimerFileDescription) ResumeTimer() {
 	tfd.timer.Resume()
 }
 
+// Timer returns the associated Timer.
+func (tfd *TimerFileDescription) Timer() ktime.Timer {
+	return tfd.timer
+}
+
 // Release implements vfs.FileDescriptionImpl.Release.
 func (tfd *TimerFileDescription) Release(context.Context) {
 	tfd.timer.Destroy()
@@ -323,6 +323,9 @@ func (fs *filesystem) MknodAt(ctx context.Context, rp *vfs.ResolvingPath, opts v
 		switch opts.Mode.FileType() {
 		case linux.S_IFREG:
 			childInode, err = fs.newRegularFile(creds.EffectiveKUID, creds.EffectiveKGID, opts.Mode, parentDir)
+			if err == nil {
+				childInode.impl.(*regularFile).mountSizeLimit = fs.mountSizeLimitLocked(rp.Mount())
+			}
 		case linux.S_IFIFO:
 			childInode, err = fs.newNamedPipe(creds.EffectiveKUID, creds.EffectiveKGID, opts.Mode, parentDir)
 		case linux.S_IFBLK, linux.S_IFCHR:
@@ -430,6 +433,7 @@ afterTrailingSymlink:
 		if err != nil {
 			return nil, err
 		}
+		childInode.impl.(*regularFile).mountSizeLimit = fs.mountSizeLimitLocked(rp.Mount())
 		child := fs.newDentry(childInode)
 		parentDir.insertChildLocked(child, name)
 		child.IncRef()
@@ -1008,16 +1012,9 @@ func (fs *filesystem) MountOptions() string {
 	return fs.mopts
 }
 
-// adjustPageAcct adjusts the accounting done against filesystem size limit in
-// case there is any discrepancy between the number of pages reserved vs the
-// number of pages actually allocated.
-func (fs *filesystem) adjustPageAcct(reserved, alloced uint64) {
-	if reserved < alloced {
-		panic(fmt.Sprintf("More pages were allocated than the pages reserved: reserved=%d, alloced=%d", reserved, alloced))
-	}
-	if pagesDiff := reserved - alloced; pagesDiff > 0 {
-		fs.unaccountPages(pagesDiff)
-	}
+// Quotas implements vfs.QuotaFilesystem.Quotas.
+func (fs *filesystem) Quotas() *vfs.QuotaSet {
+	return fs.quotas
 }
 
 // accountPagesPartial increases the pagesUsed if tmpfs is mounted with size
@@ -0,0 +1,49 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tmpfs
+
+import (
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+)
+
+// HugetlbfsName is the name of the hugetlbfs filesystem.
+const HugetlbfsName = "hugetlbfs"
+
+// HugetlbfsFilesystemType implements vfs.FilesystemType for hugetlbfs, a
+// variant of tmpfs whose regular files are backed by huge pages. It exists
+// for applications (e.g. DPDK) that mount hugetlbfs explicitly and map files
+// from it.
+//
+// +stateify savable
+type HugetlbfsFilesystemType struct{}
+
+// Name implements vfs.FilesystemType.Name.
+func (HugetlbfsFilesystemType) Name() string {
+	return HugetlbfsName
+}
+
+// Release implements vfs.FilesystemType.Release.
+func (HugetlbfsFilesystemType) Release(ctx context.Context) {}
+
+// GetFilesystem implements vfs.FilesystemType.GetFilesystem.
+func (fstype HugetlbfsFilesystemType) GetFilesystem(ctx context.Context, vfsObj *vfs.VirtualFilesystem, creds *auth.Credentials, source string, opts vfs.GetFilesystemOptions) (*vfs.Filesystem, *vfs.Dentry, error) {
+	tmpfsOpts, _ := opts.InternalData.(FilesystemOpts)
+	tmpfsOpts.FilesystemType = &fstype
+	tmpfsOpts.Hugetlb = true
+	opts.InternalData = tmpfsOpts
+	return FilesystemType{}.GetFilesystem(ctx, vfsObj, creds, source, opts)
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tmpfs
+
+import (
+	"fmt"
+	"slices"
+
+	"gvisor.dev/gvisor/pkg/atomicbitops"
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/hostarch"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+)
+
+// mountSizeLimit limits the size of the regular files created through one
+// mount of a filesystem, in addition to the size limit of the filesystem.
+// This allows each container of a pod sharing a tmpfs, such as /dev/shm, to
+// be held to its own size limit.
+//
+// +stateify savable
+type mountSizeLimit struct {
+	// mount is the mount that the limit applies to. mount is immutable, and
+	// only used for comparisons, so it holds no reference; the limit is
+	// removed from filesystem.mountSizeLimits when mount is released.
+	mount *vfs.Mount
+
+	// maxSizeInPages is the size limit in pages. maxSizeInPages is immutable.
+	maxSizeInPages uint64
+
+	// pagesUsed is the number of pages used by files subject to the limit.
+	pagesUsed atomicbitops.Uint64
+}
+
+// accountPagesPartial charges up to pagesInc pages to l, and returns the
+// number of pages charged.
+func (l *mountSizeLimit) accountPagesPartial(pagesInc uint64) uint64 {
+	for {
+		pagesUsed := l.pagesUsed.Load()
+		if l.maxSizeInPages <= pagesUsed {
+			return 0
+		}
+		toInc := min(pagesInc, l.maxSizeInPages-pagesUsed)
+		if l.pagesUsed.CompareAndSwap(pagesUsed, pagesUsed+toInc) {
+			return toInc
+		}
+	}
+}
+
+// unaccountPages reverses a previous charge to l.
+func (l *mountSizeLimit) unaccountPages(pagesDec uint64) {
+	for {
+		pagesUsed := l.pagesUsed.Load()
+		if pagesUsed < pagesDec {
+			panic(fmt.Sprintf("Deallocating more pages than allocated: mountSizeLimit.pagesUsed = %d, pagesDec = %d", pagesUsed, pagesDec))
+		}
+		if l.pagesUsed.CompareAndSwap(pagesUsed, pagesUsed-pagesDec) {
+			return
+		}
+	}
+}
+
+// mountSizeLimitLocked returns the size limit of files created through mnt,
+// or nil if there is none.
+//
+// Preconditions: fs.mu must be locked.
+func (fs *filesystem) mountSizeLimitLocked(mnt *vfs.Mount) *mountSizeLimit {
+	for _, l := range fs.mountSizeLimits {
+		if l.mount == mnt {
+			return l
+		}
+	}
+	return nil
+}
+
+// ReleaseMount implements vfs.MountReleaser.ReleaseMount. Files created through
+// mnt remain subject to its limit.
+func (fs *filesystem) ReleaseMount(ctx context.Context, mnt *vfs.Mount) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.mountSizeLimits = slices.DeleteFunc(fs.mountSizeLimits, func(l *mountSizeLimit) bool {
+		return l.mount == mnt
+	})
+}
+
+// SetMountSizeLimit limits the total size of the regular files subsequently
+// created through mnt, which must be a tmpfs mount, to size, which has the
+// format of the "size" mount option. Files created through other mounts of
+// the same filesystem aren't subject to the limit.
+func SetMountSizeLimit(mnt *vfs.Mount, size string) error {
+	fs, ok := mnt.Filesystem().Impl().(*filesystem)
+	if !ok {
+		return fmt.Errorf("mount is not a tmpfs mount")
+	}
+	bytes, _, err := parseSize(size)
+	if err != nil {
+		return fmt.Errorf("invalid size %q: %w", size, err)
+	}
+	pages, ok := hostarch.ToPagesRoundUp(bytes)
+	if !ok {
+		return fmt.Errorf("size %q overflows", size)
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.mountSizeLimitLocked(mnt) != nil {
+		return fmt.Errorf("mount already has a size limit")
+	}
+	fs.mountSizeLimits = append(fs.mountSizeLimits, &mountSizeLimit{
+		mount:          mnt,
+		maxSizeInPages: pages,
+	})
+	return nil
+}
+
+// MountSizeUsage returns the number of bytes used by the regular files created
+// through mnt and the size limit set by SetMountSizeLimit. ok is false if mnt
+// isn't a tmpfs mount with a size limit.
+func MountSizeUsage(mnt *vfs.Mount) (usage, limit uint64, ok bool) {
+	fs, ok := mnt.Filesystem().Impl().(*filesystem)
+	if !ok {
+		return 0, 0, false
+	}
+	fs.mu.RLock()
+	l := fs.mountSizeLimitLocked(mnt)
+	fs.mu.RUnlock()
+	if l == nil {
+		return 0, 0, false
+	}
+	return l.pagesUsed.Load() * hostarch.PageSize, l.maxSizeInPages * hostarch.PageSize, true
+}
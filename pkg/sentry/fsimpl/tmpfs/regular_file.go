@@ -92,6 +92,10 @@ type regularFile struct {
 	// huge is true if pages in this file may be hugepage-backed.
 	huge bool
 
+	// mountSizeLimit is the size limit of the mount through which the file
+	// was created, or nil if there is none. mountSizeLimit is immutable.
+	mountSizeLimit *mountSizeLimit
+
 	// size is the size of data.
 	//
 	// Protected by both dataMu and inode.mu; reading it requires holding
@@ -105,6 +109,7 @@ func (fs *filesystem) newRegularFile(kuid auth.KUID, kgid auth.KGID, mode linux.
 	file := &regularFile{
 		memoryUsageKind: fs.usage,
 		seals:           linux.F_SEAL_SEAL,
+		huge:            fs.hugetlb,
 	}
 	err := file.inode.init(file, fs, kuid, kgid, linux.S_IFREG|mode, parentDir)
 	if err != nil {
@@ -250,10 +255,101 @@ func (rf *regularFile) truncateNoTimeUpdateLocked(newSize uint64) error {
 	rf.dataMu.Lock()
 	decPages := rf.data.Truncate(newSize, rf.inode.fs.mf)
 	rf.dataMu.Unlock()
-	rf.inode.fs.unaccountPages(decPages)
+	rf.unaccountPages(decPages)
+	return nil
+}
+
+// accountPages charges pagesInc pages to the filesystem size limit, the size
+// limit of the mount the file was created through, and the file owner's
+// quotas. It returns ENOSPC if the filesystem or mount is full, and EDQUOT if
+// a quota is exceeded.
+func (rf *regularFile) accountPages(pagesInc uint64) error {
+	fs := rf.inode.fs
+	if !fs.accountPages(pagesInc) {
+		return linuxerr.ENOSPC
+	}
+	if l := rf.mountSizeLimit; l != nil && pagesInc != 0 {
+		if charged := l.accountPagesPartial(pagesInc); charged != pagesInc {
+			l.unaccountPages(charged)
+			fs.unaccountPages(pagesInc)
+			return linuxerr.ENOSPC
+		}
+	}
+	if fs.quotas != nil {
+		if _, err := fs.quotas.ChargeSpace(fs.clock.Now().Seconds(), &rf.inode.quota, pagesInc*hostarch.PageSize, false /* partial */); err != nil {
+			rf.unaccountSizePages(pagesInc)
+			return err
+		}
+	}
 	return nil
 }
 
+// accountPagesPartial is equivalent to accountPages, but charges as many
+// pages as possible, up to pagesInc. It returns the number of pages charged,
+// and an error if none could be.
+func (rf *regularFile) accountPagesPartial(pagesInc uint64) (uint64, error) {
+	fs := rf.inode.fs
+	pagesReserved := fs.accountPagesPartial(pagesInc)
+	if pagesReserved == 0 {
+		return 0, linuxerr.ENOSPC
+	}
+	if l := rf.mountSizeLimit; l != nil {
+		charged := l.accountPagesPartial(pagesReserved)
+		fs.unaccountPages(pagesReserved - charged)
+		if charged == 0 {
+			return 0, linuxerr.ENOSPC
+		}
+		pagesReserved = charged
+	}
+	if fs.quotas != nil {
+		charged, err := fs.quotas.ChargeSpace(fs.clock.Now().Seconds(), &rf.inode.quota, pagesReserved*hostarch.PageSize, true /* partial */)
+		// Only whole pages may be charged.
+		if rem := charged % hostarch.PageSize; rem != 0 {
+			fs.quotas.UnchargeSpace(&rf.inode.quota, rem)
+			charged -= rem
+		}
+		if charged == 0 {
+			rf.unaccountSizePages(pagesReserved)
+			if err == nil {
+				err = linuxerr.EDQUOT
+			}
+			return 0, err
+		}
+		rf.unaccountSizePages(pagesReserved - charged/hostarch.PageSize)
+		pagesReserved = charged / hostarch.PageSize
+	}
+	return pagesReserved, nil
+}
+
+// unaccountSizePages reverses a charge of pagesDec pages to the filesystem
+// and mount size limits.
+func (rf *regularFile) unaccountSizePages(pagesDec uint64) {
+	rf.inode.fs.unaccountPages(pagesDec)
+	if l := rf.mountSizeLimit; l != nil {
+		l.unaccountPages(pagesDec)
+	}
+}
+
+// unaccountPages reverses a previous call to accountPages or
+// accountPagesPartial.
+func (rf *regularFile) unaccountPages(pagesDec uint64) {
+	fs := rf.inode.fs
+	rf.unaccountSizePages(pagesDec)
+	if fs.quotas != nil {
+		fs.quotas.UnchargeSpace(&rf.inode.quota, pagesDec*hostarch.PageSize)
+	}
+}
+
+// adjustPageAcct adjusts the accounting done by accountPages in case there is
+// any discrepancy between the number of pages reserved vs the number of pages
+// actually allocated.
+func (rf *regularFile) adjustPageAcct(reserved, alloced uint64) {
+	if reserved < alloced {
+		panic(fmt.Sprintf("More pages were allocated than the pages reserved: reserved=%d, alloced=%d", reserved, alloced))
+	}
+	rf.unaccountPages(reserved - alloced)
+}
+
 // AddMapping implements memmap.Mappable.AddMapping.
 func (rf *regularFile) AddMapping(ctx context.Context, ms memmap.MappingSpace, ar hostarch.AddrRange, offset uint64, writable bool) error {
 	rf.mapsMu.Lock()
@@ -261,7 +357,10 @@ func (rf *regularFile) AddMapping(ctx context.Context, ms memmap.MappingSpace, a
 	rf.dataMu.RLock()
 	defer rf.dataMu.RUnlock()
 
-	// Reject writable mapping if F_SEAL_WRITE is set.
+	// Reject writable mapping if F_SEAL_WRITE is set. F_SEAL_FUTURE_WRITE
+	// only prevents new writable mappings, which is checked by
+	// ConfigureMMap; mappings that existed before the seal was added may
+	// still be copied by fork or mremap.
 	if rf.seals&linux.F_SEAL_WRITE != 0 && writable {
 		return linuxerr.EPERM
 	}
@@ -335,25 +434,41 @@ func (rf *regularFile) Translate(ctx context.Context, required, optional memmap.
 	// the mount's size limit. This matches gofer.maxFillRange().
 	const maxFillBytes = 64 << 10 // 64 KiB
 	fillRange := maxOptionalRange(required, optional, maxFillBytes)
-	pagesToFill := rf.data.PagesToFill(required, fillRange)
-	if !rf.inode.fs.accountPages(pagesToFill) {
+	fillRequired := required
+	if rf.inode.fs.hugetlb {
+		// hugetlbfs files are populated a huge page at a time, so that each
+		// allocation can be backed by a hugepage-aligned MemoryFile range.
+		// hugetlbfs file sizes are normally hugepage-aligned (see
+		// ConfigureMMap and inode.setStat), but the size may change
+		// concurrently, so never fill past pgend. Compare Linux's
+		// mm/hugetlb.c:hugetlb_fault().
+		fillRequired.Start = hostarch.HugePageRoundDown(required.Start)
+		end, ok := hostarch.HugePageRoundUp(required.End)
+		if !ok || end > pgend {
+			end = pgend
+		}
+		fillRequired.End = end
+		fillRange = fillRequired
+	}
+	pagesToFill := rf.data.PagesToFill(fillRequired, fillRange)
+	if err := rf.accountPages(pagesToFill); err != nil {
 		// If we can not accommodate pagesToFill pages, then retry with just
 		// the required range. Because fillRange may be larger than required.
 		// Only error out if even the required range can not be allocated for.
-		pagesToFill = rf.data.PagesToFill(required, required)
-		if !rf.inode.fs.accountPages(pagesToFill) {
-			return nil, &memmap.BusError{linuxerr.ENOSPC}
+		pagesToFill = rf.data.PagesToFill(fillRequired, fillRequired)
+		if err := rf.accountPages(pagesToFill); err != nil {
+			return nil, &memmap.BusError{err}
 		}
-		fillRange = required
+		fillRange = fillRequired
 	}
-	pagesAlloced, cerr := rf.data.Fill(ctx, required, fillRange, rf.size.RacyLoad(), rf.inode.fs.mf, pgalloc.AllocOpts{
+	pagesAlloced, cerr := rf.data.Fill(ctx, fillRequired, fillRange, rf.size.RacyLoad(), rf.inode.fs.mf, pgalloc.AllocOpts{
 		Kind:    rf.memoryUsageKind,
 		MemCgID: memCgID,
 		Huge:    mayHuge,
 	}, nil)
 	// rf.data.Fill() may fail mid-way. We still want to account any pages that
 	// were allocated, irrespective of an error.
-	rf.inode.fs.adjustPageAcct(pagesToFill, pagesAlloced)
+	rf.adjustPageAcct(pagesToFill, pagesAlloced)
 
 	// translateRange bounds how far Translate extends the returned translation
 	// over pages that are *already* present, beyond the allocation-bounded
@@ -441,10 +556,24 @@ func (fd *regularFileFD) Allocate(ctx context.Context, mode, offset, length uint
 	f.inode.mu.Lock()
 	defer f.inode.mu.Unlock()
 	end := offset + length
+	pgStart := hostarch.PageRoundDown(offset)
 	pgEnd, ok := hostarch.PageRoundUp(end)
+	if f.inode.fs.hugetlb {
+		// Compare Linux's fs/hugetlbfs/inode.c:hugetlbfs_fallocate().
+		pgStart = hostarch.HugePageRoundDown(offset)
+		pgEnd, ok = hostarch.HugePageRoundUp(end)
+	}
 	if !ok {
 		return linuxerr.EFBIG
 	}
+	// Check F_SEAL_GROW before allocating any pages, as in Linux's
+	// mm/shmem.c:shmem_fallocate().
+	f.dataMu.RLock()
+	growSealed := f.seals&linux.F_SEAL_GROW != 0 && end > f.size.RacyLoad()
+	f.dataMu.RUnlock()
+	if growSealed {
+		return linuxerr.EPERM
+	}
 	// Allocate in chunks for the following reasons:
 	// 1. Size limit may permit really large fallocate, which can take a long
 	//    time to execute on the host. This can cause watchdog to timeout and
@@ -452,7 +581,7 @@ func (fd *regularFileFD) Allocate(ctx context.Context, mode, offset, length uint
 	// 2. Linux allocates folios iteratively while checking for interrupts. In
 	//    gVisor, we need to manually check for interrupts between chunks.
 	const chunkSize = 4 << 30 // 4 GiB
-	for curPgStart := hostarch.PageRoundDown(offset); curPgStart < pgEnd; {
+	for curPgStart := pgStart; curPgStart < pgEnd; {
 		curPgEnd := pgEnd
 		newSize := end
 		if curPgEnd-curPgStart > chunkSize {
@@ -489,8 +618,8 @@ func (rf *regularFile) allocateLocked(ctx context.Context, mode, newSize uint64,
 	// specified by offset and len are guaranteed not to fail because of
 	// lack of disk space."  - fallocate(2)
 	pagesToFill := rf.data.PagesToFill(required, required)
-	if !rf.inode.fs.accountPages(pagesToFill) {
-		return linuxerr.ENOSPC
+	if err := rf.accountPages(pagesToFill); err != nil {
+		return err
 	}
 	// Given our definitions in pgalloc, fallocate(2) semantics imply that pages
 	// in the MemoryFile must be committed, in addition to being allocated.
@@ -512,7 +641,7 @@ func (rf *regularFile) allocateLocked(ctx context.Context, mode, newSize uint64,
 	}, nil /* r */)
 	// f.data.Fill() may fail mid-way. We still want to account any pages that
 	// were allocated, irrespective of an error.
-	rf.inode.fs.adjustPageAcct(pagesToFill, pagesAlloced)
+	rf.adjustPageAcct(pagesToFill, pagesAlloced)
 	if err != nil && err != io.EOF {
 		return err
 	}
@@ -585,11 +714,18 @@ func (fd *regularFileFD) pwrite(ctx context.Context, src usermem.IOSequence, off
 		return 0, offset, linuxerr.EOPNOTSUPP
 	}
 
+	f := fd.inode().impl.(*regularFile)
+	if f.inode.fs.hugetlb {
+		// hugetlbfs files can only be modified through mappings. Compare
+		// Linux's fs/hugetlbfs/inode.c:hugetlbfs_file_operations, which has
+		// no write_iter.
+		return 0, offset, linuxerr.EINVAL
+	}
+
 	srclen := src.NumBytes()
 	if srclen == 0 {
 		return 0, offset, nil
 	}
-	f := fd.inode().impl.(*regularFile)
 	f.inode.mu.Lock()
 	defer f.inode.mu.Unlock()
 	// If the file is opened with O_APPEND, update offset to file size.
@@ -663,9 +799,51 @@ func (fd *regularFileFD) ConfigureMMap(ctx context.Context, opts *memmap.MMapOpt
 	if file.initiallyUnlinked {
 		opts.NameMut = memmap.NameMutAnonShmem
 	}
+	if file.inode.fs.hugetlb {
+		if err := file.configureHugetlbMMap(opts); err != nil {
+			return err
+		}
+	}
+	if !opts.Private {
+		file.dataMu.RLock()
+		seals := file.seals
+		file.dataMu.RUnlock()
+		if seals&writeSeals != 0 {
+			// New shared mappings of a write-sealed file can't be writable,
+			// and can't be made writable by mprotect.
+			if opts.Perms.Write {
+				return linuxerr.EPERM
+			}
+			opts.MaxPerms.Write = false
+		}
+	}
 	return vfs.GenericConfigureMMap(&fd.vfsfd, file, opts)
 }
 
+// configureHugetlbMMap checks that opts is valid for a hugetlbfs file, and
+// grows the file to cover opts if the mapping is writable. Compare Linux's
+// fs/hugetlbfs/inode.c:hugetlbfs_file_mmap().
+func (rf *regularFile) configureHugetlbMMap(opts *memmap.MMapOpts) error {
+	if !hostarch.IsHugePageAligned(opts.Offset) {
+		return linuxerr.EINVAL
+	}
+	end, ok := hostarch.HugePageRoundUp(opts.Offset + opts.Length)
+	if !ok || end < opts.Offset {
+		return linuxerr.ENOMEM
+	}
+	if !opts.Perms.Write {
+		return nil
+	}
+	rf.inode.mu.Lock()
+	defer rf.inode.mu.Unlock()
+	rf.dataMu.Lock()
+	defer rf.dataMu.Unlock()
+	if end <= rf.size.RacyLoad() {
+		return nil
+	}
+	return rf.growLocked(end)
+}
+
 // offsetPageEnd returns the file offset rounded up to the nearest
 // page boundary. offsetPageEnd panics if rounding up causes overflow,
 // which shouldn't be possible given that offset is an int64.
@@ -787,7 +965,7 @@ func (rw *regularFileReadWriter) WriteFromBlocks(srcs safemem.BlockSeq) (uint64,
 
 	// Check if seals prevent either file growth or all writes.
 	switch {
-	case rw.file.seals&linux.F_SEAL_WRITE != 0: // Write sealed
+	case rw.file.seals&writeSeals != 0: // Write sealed
 		return 0, linuxerr.EPERM
 	case end > rw.file.size.RacyLoad() && rw.file.seals&linux.F_SEAL_GROW != 0: // Grow sealed
 		// When growth is sealed, Linux effectively allows writes which would
@@ -846,10 +1024,10 @@ func (rw *regularFileReadWriter) WriteFromBlocks(srcs safemem.BlockSeq) (uint64,
 			// Allocate memory for the write.
 			gapMR := gap.Range().Intersect(pgMR)
 			pagesToFill := gapMR.Length() / hostarch.PageSize
-			pagesReserved := fs.accountPagesPartial(pagesToFill)
+			pagesReserved, err := rw.file.accountPagesPartial(pagesToFill)
 			if pagesReserved == 0 {
 				if done == 0 {
-					retErr = linuxerr.ENOSPC
+					retErr = err
 					goto exitLoop
 				}
 				retErr = nil
@@ -878,7 +1056,7 @@ func (rw *regularFileReadWriter) WriteFromBlocks(srcs safemem.BlockSeq) (uint64,
 			})
 			if err != nil {
 				retErr = err
-				fs.unaccountPages(pagesReserved)
+				rw.file.unaccountPages(pagesReserved)
 				goto exitLoop
 			}
 
@@ -925,6 +1103,12 @@ func (rw *regularFileReadWriter) writeToMF(fr memmap.FileRange, srcs safemem.Blo
 	return safemem.CopySeq(ims, srcs)
 }
 
+// writeSeals is the set of seals that prevent writes to a file by write(2) and
+// new writable shared mappings. F_SEAL_FUTURE_WRITE differs from F_SEAL_WRITE
+// only in that it may be added while writable shared mappings exist, which
+// may continue to be used to modify the file.
+const writeSeals = linux.F_SEAL_WRITE | linux.F_SEAL_FUTURE_WRITE
+
 // GetSeals returns the current set of seals on a memfd inode.
 func GetSeals(fd *vfs.FileDescription) (uint32, error) {
 	f, ok := fd.Impl().(*regularFileFD)
@@ -943,6 +1127,9 @@ func AddSeals(fd *vfs.FileDescription, val uint32) error {
 	if !ok {
 		return linuxerr.EINVAL
 	}
+	if val&^linux.F_ALL_SEALS != 0 {
+		return linuxerr.EINVAL
+	}
 	rf := f.inode().impl.(*regularFile)
 	rf.mapsMu.Lock()
 	defer rf.mapsMu.Unlock()
@@ -955,6 +1142,7 @@ func AddSeals(fd *vfs.FileDescription, val uint32) error {
 	}
 
 	// F_SEAL_WRITE can only be added if there are no active writable maps.
+	// F_SEAL_FUTURE_WRITE has no such restriction.
 	if rf.seals&linux.F_SEAL_WRITE == 0 && val&linux.F_SEAL_WRITE != 0 {
 		if rf.writableMappingPages > 0 {
 			return linuxerr.EBUSY
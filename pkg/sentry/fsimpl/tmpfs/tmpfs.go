@@ -66,6 +66,19 @@ const (
 	optUID      = "uid"
 	optGID      = "gid"
 	optNoSwap   = "noswap"
+
+	// Quota options; see Documentation/filesystems/tmpfs.rst.
+	optQuota                  = "quota"
+	optUsrQuota               = "usrquota"
+	optGrpQuota               = "grpquota"
+	optUsrQuotaBlockHardlimit = "usrquota_block_hardlimit"
+	optUsrQuotaInodeHardlimit = "usrquota_inode_hardlimit"
+	optGrpQuotaBlockHardlimit = "grpquota_block_hardlimit"
+	optGrpQuotaInodeHardlimit = "grpquota_inode_hardlimit"
+
+	// Accepted only by hugetlbfs.
+	optPageSize = "pagesize"
+	optMinSize  = "min_size"
 )
 
 // FilesystemType implements vfs.FilesystemType.
@@ -129,8 +142,22 @@ type filesystem struct {
 	// tmpfs mount will allow. It is immutable.
 	allowXattrPrefix map[string]struct{}
 
+	// hugetlb is true if this filesystem implements hugetlbfs semantics:
+	// regular files are backed by huge pages, must be sized in multiples of
+	// the huge page size, and can't be written with write(2). hugetlb is
+	// immutable.
+	hugetlb bool
+
 	// ovlWhiteout is the shared overlay whiteout device. It is protected by mu.
 	ovlWhiteout *deviceFile
+
+	// quotas tracks per-user and per-group usage. quotas is nil if the
+	// filesystem was mounted without quota options. quotas is immutable.
+	quotas *vfs.QuotaSet
+
+	// mountSizeLimits are the size limits of files created through specific
+	// mounts of the filesystem. It is protected by mu.
+	mountSizeLimits []*mountSizeLimit
 }
 
 // Name implements vfs.FilesystemType.Name.
@@ -185,6 +212,10 @@ type FilesystemOpts struct {
 	// TarWrite.
 	SourceTar             io.ReadCloser
 	SourceTarFSCheckpoint bool
+
+	// Hugetlb causes the filesystem to behave like hugetlbfs. See
+	// HugetlbfsFilesystemType.
+	Hugetlb bool
 }
 
 // Amount of total physical RAM, in bytes. It is immutable after initialization.
@@ -230,6 +261,7 @@ func (fstype FilesystemType) GetFilesystem(ctx context.Context, vfsObj *vfs.Virt
 	}
 	rootFileType := uint16(linux.S_IFDIR)
 	disableDefaultSizeLimit := false
+	hugetlb := false
 	newFSType := vfs.FilesystemType(&fstype)
 
 	// By default we support only "trusted" and "user" namespaces. Linux
@@ -251,6 +283,7 @@ func (fstype FilesystemType) GetFilesystem(ctx context.Context, vfsObj *vfs.Virt
 			newFSType = tmpfsOpts.FilesystemType
 		}
 		disableDefaultSizeLimit = tmpfsOpts.DisableDefaultSizeLimit
+		hugetlb = tmpfsOpts.Hugetlb
 		if tmpfsOpts.MemoryFile != nil {
 			mf = tmpfsOpts.MemoryFile
 		}
@@ -273,11 +306,25 @@ func (fstype FilesystemType) GetFilesystem(ctx context.Context, vfsObj *vfs.Virt
 	rootKGID := creds.EffectiveKGID
 
 	printedOptsMap := make(map[string]string)
+	var (
+		usrQuota, grpQuota       bool
+		usrQuotaLim, grpQuotaLim vfs.QuotaLimits
+	)
 
 	for _, opt := range vfs.GenericParseMountOptionsOrdered(opts.Data) {
 		key := opt.Key
 		value := opt.Value
 
+		switch key {
+		case optQuota, optUsrQuota, optGrpQuota, optUsrQuotaBlockHardlimit, optUsrQuotaInodeHardlimit, optGrpQuotaBlockHardlimit, optGrpQuotaInodeHardlimit:
+			// Quotas apply to every user of the filesystem, so, as in Linux,
+			// they can only be configured from the root user namespace.
+			if creds.UserNamespace != creds.UserNamespace.Root() {
+				ctx.Warningf("tmpfs.FilesystemType.GetFilesystem: quotas are unsupported in user namespaces: %s", key)
+				return nil, nil, linuxerr.EINVAL
+			}
+		}
+
 		switch key {
 		case optSize:
 			maxSizeInBytes, _, err := parseSize(value)
@@ -368,6 +415,64 @@ func (fstype FilesystemType) GetFilesystem(ctx context.Context, vfsObj *vfs.Virt
 		case optNoSwap:
 			// Accept, but ignore, noswap.
 
+		case optQuota, optUsrQuota, optGrpQuota:
+			if hugetlb {
+				ctx.Warningf("tmpfs.FilesystemType.GetFilesystem: unknown option: %s", key)
+				return nil, nil, linuxerr.EINVAL
+			}
+			usrQuota = usrQuota || key != optGrpQuota
+			grpQuota = grpQuota || key != optUsrQuota
+
+		case optUsrQuotaBlockHardlimit, optGrpQuotaBlockHardlimit:
+			limit, percentageSpecified, err := parseSize(value)
+			if hugetlb || err != nil || percentageSpecified || limit == 0 {
+				ctx.Warningf("tmpfs.FilesystemType.GetFilesystem: invalid %s: %q", key, value)
+				return nil, nil, linuxerr.EINVAL
+			}
+			if key == optUsrQuotaBlockHardlimit {
+				usrQuota = true
+				usrQuotaLim.SpaceHard = limit
+			} else {
+				grpQuota = true
+				grpQuotaLim.SpaceHard = limit
+			}
+			printedOptsMap[key] = fmt.Sprintf("%s=%d", key, limit)
+
+		case optUsrQuotaInodeHardlimit, optGrpQuotaInodeHardlimit:
+			limit, percentageSpecified, err := parseSize(value)
+			if hugetlb || err != nil || percentageSpecified || limit == 0 {
+				ctx.Warningf("tmpfs.FilesystemType.GetFilesystem: invalid %s: %q", key, value)
+				return nil, nil, linuxerr.EINVAL
+			}
+			if key == optUsrQuotaInodeHardlimit {
+				usrQuota = true
+				usrQuotaLim.InodesHard = limit
+			} else {
+				grpQuota = true
+				grpQuotaLim.InodesHard = limit
+			}
+			printedOptsMap[key] = fmt.Sprintf("%s=%d", key, limit)
+
+		case optPageSize:
+			if !hugetlb {
+				ctx.Warningf("tmpfs.FilesystemType.GetFilesystem: unknown option: %s", key)
+				return nil, nil, linuxerr.EINVAL
+			}
+			// Only the default huge page size is supported.
+			pageSize, percentageSpecified, err := parseSize(value)
+			if err != nil || percentageSpecified || pageSize != hostarch.HugePageSize {
+				ctx.Warningf("tmpfs.FilesystemType.GetFilesystem: unsupported pagesize: %q", value)
+				return nil, nil, linuxerr.EINVAL
+			}
+
+		case optMinSize:
+			if !hugetlb {
+				ctx.Warningf("tmpfs.FilesystemType.GetFilesystem: unknown option: %s", key)
+				return nil, nil, linuxerr.EINVAL
+			}
+			// Accept, but ignore, min_size; huge pages are never reserved in
+			// advance.
+
 		default:
 			ctx.Warningf("tmpfs.FilesystemType.GetFilesystem: unknown option: %s", key)
 			return nil, nil, linuxerr.EINVAL
@@ -380,6 +485,20 @@ func (fstype FilesystemType) GetFilesystem(ctx context.Context, vfsObj *vfs.Virt
 			printedOpts = append(printedOpts, val)
 		}
 	}
+	if usrQuota {
+		printedOpts = append(printedOpts, optUsrQuota)
+	}
+	if grpQuota {
+		printedOpts = append(printedOpts, optGrpQuota)
+	}
+	for _, key := range []string{optUsrQuotaBlockHardlimit, optUsrQuotaInodeHardlimit, optGrpQuotaBlockHardlimit, optGrpQuotaInodeHardlimit} {
+		if val, ok := printedOptsMap[key]; ok {
+			printedOpts = append(printedOpts, val)
+		}
+	}
+	if hugetlb {
+		printedOpts = append(printedOpts, fmt.Sprintf("%s=%dM", optPageSize, hostarch.HugePageSize>>20))
+	}
 
 	devMinor, err := vfsObj.GetAnonBlockDevMinor()
 	if err != nil {
@@ -400,6 +519,10 @@ func (fstype FilesystemType) GetFilesystem(ctx context.Context, vfsObj *vfs.Virt
 		maxSizeInPages:   maxSizeInPages,
 		maxInodes:        maxInodes,
 		allowXattrPrefix: allowXattrPrefix,
+		hugetlb:          hugetlb,
+	}
+	if usrQuota || grpQuota {
+		fs.quotas = vfs.NewQuotaSet(usrQuota, grpQuota, usrQuotaLim, grpQuotaLim)
 	}
 	fs.vfsfs.Init(vfsObj, newFSType, &fs)
 	if tmpfsOptsOk && tmpfsOpts.MaxFilenameLen > 0 {
@@ -522,6 +645,18 @@ func (fs *filesystem) statFS() linux.Statfs {
 		st.FilesFree = fs.maxInodes - inodesUsed
 	}
 
+	if fs.hugetlb {
+		// Report usage in units of huge pages, as Linux's
+		// fs/hugetlbfs/inode.c:hugetlbfs_statfs() does.
+		const pagesPerHugePage = hostarch.HugePageSize / hostarch.PageSize
+		st.Type = linux.HUGETLBFS_MAGIC
+		st.BlockSize = hostarch.HugePageSize
+		st.FragmentSize = hostarch.HugePageSize
+		st.Blocks /= pagesPerHugePage
+		st.BlocksFree /= pagesPerHugePage
+		st.BlocksAvailable /= pagesPerHugePage
+	}
+
 	return st
 }
 
@@ -648,6 +783,10 @@ type inode struct {
 	// Inotify watches for this inode.
 	watches vfs.Watches
 
+	// quota records the usage charged to fs.quotas for this inode. It is
+	// protected by fs.quotas' mutex, and unused if fs.quotas is nil.
+	quota vfs.QuotaCharge
+
 	impl any // immutable
 }
 
@@ -673,6 +812,13 @@ func (i *inode) init(impl any, fs *filesystem, kuid auth.KUID, kgid auth.KGID, m
 		}
 	}
 
+	if fs.quotas != nil {
+		if err := fs.quotas.ChargeInode(fs.clock.Now().Seconds(), &i.quota, kuid, kgid); err != nil {
+			fs.unaccountInode()
+			return err
+		}
+	}
+
 	i.fs = fs
 	i.mode = atomicbitops.FromUint32(uint32(mode))
 	i.uid = atomicbitops.FromUint32(uint32(kuid))
@@ -750,10 +896,16 @@ func (i *inode) decRef(ctx context.Context) {
 			// metadata.
 			pagesDec := impl.data.DropAll(i.fs.mf)
 			impl.inode.fs.unaccountPages(pagesDec)
+			if impl.mountSizeLimit != nil {
+				impl.mountSizeLimit.unaccountPages(pagesDec)
+			}
 		}
 
 		// Account for deletion of the inode itself
 		i.fs.unaccountInode()
+		if i.fs.quotas != nil {
+			i.fs.quotas.Release(&i.quota)
+		}
 	})
 }
 
@@ -791,6 +943,11 @@ func (i *inode) statTo(stat *linux.Statx) {
 		// TODO(jamieliu): This should be impl.data.Span() / 512, but this is
 		// too expensive to compute here. Cache it in regularFile.
 		stat.Blocks = allocatedBlocksForSize(stat.Size)
+		// O_DIRECT I/O is served from the page cache like any other I/O, so
+		// it has no alignment requirements.
+		stat.Mask |= linux.STATX_DIOALIGN
+		stat.DioMemAlign = 1
+		stat.DioOffsetAlign = 1
 	case *directory:
 		stat.Size = direntSize * (2 + uint64(impl.numChildren.Load()))
 		// stat.Blocks is 0.
@@ -828,9 +985,23 @@ func (i *inode) setStat(ctx context.Context, creds *auth.Credentials, opts *vfs.
 		needsCtimeBump bool
 	)
 	mask := stat.Mask
+	if rf, ok := i.impl.(*regularFile); ok && mask&linux.STATX_MODE != 0 {
+		// F_SEAL_EXEC prevents changes to the file's exec bits; compare
+		// Linux's mm/shmem.c:shmem_setattr().
+		rf.dataMu.RLock()
+		execSealed := rf.seals&linux.F_SEAL_EXEC != 0
+		rf.dataMu.RUnlock()
+		if execSealed && (uint16(i.mode.Load())^stat.Mode)&0111 != 0 {
+			return linuxerr.EPERM
+		}
+	}
 	if mask&linux.STATX_SIZE != 0 {
 		switch impl := i.impl.(type) {
 		case *regularFile:
+			// Compare Linux's fs/hugetlbfs/inode.c:hugetlbfs_setattr().
+			if i.fs.hugetlb && !hostarch.IsHugePageAligned(stat.Size) {
+				return linuxerr.EINVAL
+			}
 			if err := impl.truncateNoTimeUpdateLocked(stat.Size); err != nil {
 				return err
 			}
@@ -842,6 +1013,20 @@ func (i *inode) setStat(ctx context.Context, creds *auth.Credentials, opts *vfs.
 			return linuxerr.EINVAL
 		}
 	}
+	if i.fs.quotas != nil && mask&(linux.STATX_UID|linux.STATX_GID) != 0 {
+		// Compare Linux's mm/shmem.c:shmem_setattr() => dquot_transfer().
+		newUID := auth.KUID(i.uid.Load())
+		if mask&linux.STATX_UID != 0 {
+			newUID = auth.KUID(stat.UID)
+		}
+		newGID := auth.KGID(i.gid.Load())
+		if mask&linux.STATX_GID != 0 {
+			newGID = auth.KGID(stat.GID)
+		}
+		if err := i.fs.quotas.Transfer(i.fs.clock.Now().Seconds(), &i.quota, newUID, newGID); err != nil {
+			return err
+		}
+	}
 	if mask&linux.STATX_UID != 0 {
 		i.uid.Store(stat.UID)
 		needsCtimeBump = true
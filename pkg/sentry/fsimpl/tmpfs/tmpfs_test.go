@@ -364,3 +364,156 @@ func TestMountOptionsEnforcement(t *testing.T) {
 		t.Fatalf("second write got err %v, want ENOSPC", err)
 	}
 }
+
+func TestMountSizeLimit(t *testing.T) {
+	ctx := contexttest.Context(t)
+	creds := auth.CredentialsFromContext(ctx)
+	vfsObj, root, cleanup, err := newTmpfsRoot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	// Limit files created through a second mount of the filesystem to 1 page.
+	limited := vfsObj.NewDisconnectedMount(root.Mount().Filesystem(), root.Dentry(), &vfs.MountOptions{})
+	if err := SetMountSizeLimit(limited, "4k"); err != nil {
+		t.Fatalf("SetMountSizeLimit failed: %v", err)
+	}
+	limitedRoot := vfs.MakeVirtualDentry(limited, limited.Root())
+
+	for _, tc := range []struct {
+		name       string
+		root       vfs.VirtualDentry
+		wantENOSPC bool
+	}{
+		{name: "limited", root: limitedRoot, wantENOSPC: true},
+		{name: "unlimited", root: root},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fd, err := vfsObj.OpenAt(ctx, creds, &vfs.PathOperation{
+				Root:  tc.root,
+				Start: tc.root,
+				Path:  fspath.Parse(tc.name),
+			}, &vfs.OpenOptions{
+				Flags: linux.O_RDWR | linux.O_CREAT | linux.O_EXCL,
+				Mode:  0644,
+			})
+			if err != nil {
+				t.Fatalf("failed to create file: %v", err)
+			}
+			defer fd.DecRef(ctx)
+
+			data := make([]byte, 2*hostarch.PageSize)
+			if _, err := fd.Write(ctx, usermem.BytesIOSequence(data[:hostarch.PageSize]), vfs.WriteOptions{}); err != nil {
+				t.Fatalf("first write failed: %v", err)
+			}
+			_, err = fd.Write(ctx, usermem.BytesIOSequence(data[hostarch.PageSize:]), vfs.WriteOptions{})
+			if gotENOSPC := linuxerr.Equals(linuxerr.ENOSPC, err); gotENOSPC != tc.wantENOSPC || (err != nil && !gotENOSPC) {
+				t.Errorf("second write got err %v, want ENOSPC: %t", err, tc.wantENOSPC)
+			}
+			// Only the file created through the limited mount is charged to
+			// its limit.
+			if used, limit, _ := MountSizeUsage(limited); used != hostarch.PageSize || limit != hostarch.PageSize {
+				t.Errorf("MountSizeUsage got (%d, %d), want (%d, %d)", used, limit, hostarch.PageSize, hostarch.PageSize)
+			}
+		})
+	}
+
+	// The limit is forgotten once the mount is released.
+	fs := root.Mount().Filesystem().Impl().(*filesystem)
+	limited.DecRef(ctx)
+	fs.mu.RLock()
+	n := len(fs.mountSizeLimits)
+	fs.mu.RUnlock()
+	if n != 0 {
+		t.Errorf("got %d mount size limits after releasing the mount, want 0", n)
+	}
+}
+
+func TestHugetlbfs(t *testing.T) {
+	ctx := contexttest.Context(t)
+	creds := auth.CredentialsFromContext(ctx)
+
+	vfsObj := &vfs.VirtualFilesystem{}
+	if err := vfsObj.Init(ctx); err != nil {
+		t.Fatalf("VFS init: %v", err)
+	}
+	vfsObj.MustRegisterFilesystemType(HugetlbfsName, HugetlbfsFilesystemType{}, &vfs.RegisterFilesystemTypeOptions{
+		AllowUserMount: true,
+	})
+
+	if mntns, err := vfsObj.NewMountNamespace(ctx, creds, "", HugetlbfsName, &vfs.MountOptions{
+		GetFilesystemOptions: vfs.GetFilesystemOptions{
+			Data: fmt.Sprintf("pagesize=%d", hostarch.PageSize),
+		},
+	}, nil); err == nil {
+		mntns.DecRef(ctx)
+		t.Errorf("mount with pagesize=%d succeeded, want EINVAL", hostarch.PageSize)
+	}
+
+	mntns, err := vfsObj.NewMountNamespace(ctx, creds, "", HugetlbfsName, &vfs.MountOptions{
+		GetFilesystemOptions: vfs.GetFilesystemOptions{
+			Data: fmt.Sprintf("pagesize=%dk,min_size=0", hostarch.HugePageSize>>10),
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to create hugetlbfs mount: %v", err)
+	}
+	defer mntns.DecRef(ctx)
+	root := mntns.Root(ctx)
+	defer root.DecRef(ctx)
+
+	statfs, err := vfsObj.StatFSAt(ctx, creds, &vfs.PathOperation{
+		Root:  root,
+		Start: root,
+	})
+	if err != nil {
+		t.Fatalf("StatFS failed: %v", err)
+	}
+	if statfs.Type != linux.HUGETLBFS_MAGIC {
+		t.Errorf("got statfs type %#x, want %#x", statfs.Type, linux.HUGETLBFS_MAGIC)
+	}
+	if statfs.BlockSize != hostarch.HugePageSize {
+		t.Errorf("got statfs block size %d, want %d", statfs.BlockSize, hostarch.HugePageSize)
+	}
+
+	fd, err := vfsObj.OpenAt(ctx, creds, &vfs.PathOperation{
+		Root:  root,
+		Start: root,
+		Path:  fspath.Parse("test-file"),
+	}, &vfs.OpenOptions{
+		Flags: linux.O_RDWR | linux.O_CREAT | linux.O_EXCL,
+		Mode:  0644,
+	})
+	if err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	defer fd.DecRef(ctx)
+
+	if _, err := fd.Write(ctx, usermem.BytesIOSequence([]byte("a")), vfs.WriteOptions{}); !linuxerr.Equals(linuxerr.EINVAL, err) {
+		t.Errorf("write got err %v, want EINVAL", err)
+	}
+
+	for _, tc := range []struct {
+		size       uint64
+		wantEINVAL bool
+	}{
+		{size: hostarch.PageSize, wantEINVAL: true},
+		{size: hostarch.HugePageSize},
+		{size: 0},
+	} {
+		err := fd.SetStat(ctx, vfs.SetStatOptions{
+			Stat: linux.Statx{
+				Mask: linux.STATX_SIZE,
+				Size: tc.size,
+			},
+		})
+		if tc.wantEINVAL {
+			if !linuxerr.Equals(linuxerr.EINVAL, err) {
+				t.Errorf("truncate to %d got err %v, want EINVAL", tc.size, err)
+			}
+		} else if err != nil {
+			t.Errorf("truncate to %d failed: %v", tc.size, err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tmpfs
+
+import (
+	"container/heap"
+	"sort"
+
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/hostarch"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+)
+
+// UpperLayerUsage implements vfs.UpperLayerUsageReporter.UpperLayerUsage. A
+// tmpfs is its own upper layer.
+func (fs *filesystem) UpperLayerUsage(ctx context.Context) (vfs.UpperLayerUsage, error) {
+	return vfs.UpperLayerUsage{
+		Bytes:  fs.pagesUsed.Load() * hostarch.PageSize,
+		Inodes: fs.inodesUsed.Load(),
+	}, nil
+}
+
+// UpperLayerLargestFiles implements
+// vfs.UpperLayerUsageReporter.UpperLayerLargestFiles.
+func (fs *filesystem) UpperLayerLargestFiles(ctx context.Context, n int) ([]vfs.FileUsage, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	h := make(fileUsageHeap, 0, n)
+	seen := make(map[uint64]struct{})
+
+	fs.mu.RLock()
+	fs.root.collectLargestFiles("", n, &h, seen)
+	fs.mu.RUnlock()
+
+	files := []vfs.FileUsage(h)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Bytes > files[j].Bytes
+	})
+	return files, nil
+}
+
+// collectLargestFiles adds the regular files in the tree rooted at d to h,
+// retaining only the n files that use the most space. seen contains the inode
+// numbers of files that have already been visited, so that files with
+// multiple links are counted once.
+//
+// Preconditions: filesystem.mu must be locked.
+func (d *dentry) collectLargestFiles(path string, n int, h *fileUsageHeap, seen map[uint64]struct{}) {
+	switch impl := d.inode.impl.(type) {
+	case *directory:
+		for name, child := range impl.childMap {
+			child.collectLargestFiles(path+"/"+name, n, h, seen)
+		}
+	case *regularFile:
+		if _, ok := seen[d.inode.ino]; ok {
+			return
+		}
+		seen[d.inode.ino] = struct{}{}
+		if path == "" {
+			// The filesystem root is a regular file.
+			path = "/"
+		}
+		impl.dataMu.RLock()
+		fu := vfs.FileUsage{
+			Path:  path,
+			Size:  impl.size.RacyLoad(),
+			Bytes: impl.data.Span(),
+		}
+		impl.dataMu.RUnlock()
+		if fu.Bytes == 0 {
+			return
+		}
+		if h.Len() < n {
+			heap.Push(h, fu)
+		} else if (*h)[0].Bytes < fu.Bytes {
+			(*h)[0] = fu
+			heap.Fix(h, 0)
+		}
+	}
+}
+
+// fileUsageHeap is a min-heap of vfs.FileUsage ordered by Bytes. It
+// implements heap.Interface.
+type fileUsageHeap []vfs.FileUsage
+
+// Len implements sort.Interface.Len.
+func (h fileUsageHeap) Len() int { return len(h) }
+
+// Less implements sort.Interface.Less.
+func (h fileUsageHeap) Less(i, j int) bool { return h[i].Bytes < h[j].Bytes }
+
+// Swap implements sort.Interface.Swap.
+func (h fileUsageHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+// Push implements heap.Interface.Push.
+func (h *fileUsageHeap) Push(x any) { *h = append(*h, x.(vfs.FileUsage)) }
+
+// Pop implements heap.Interface.Pop.
+func (h *fileUsageHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tmpfs
+
+import (
+	"bytes"
+	"testing"
+
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/fspath"
+	"gvisor.dev/gvisor/pkg/hostarch"
+	"gvisor.dev/gvisor/pkg/sentry/contexttest"
+	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+	"gvisor.dev/gvisor/pkg/usermem"
+)
+
+func TestUpperLayerLargestFiles(t *testing.T) {
+	ctx := contexttest.Context(t)
+	creds := auth.CredentialsFromContext(ctx)
+	vfsObj, root, cleanup, err := newTmpfsRoot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	pop := func(path string) *vfs.PathOperation {
+		return &vfs.PathOperation{Root: root, Start: root, Path: fspath.Parse(path)}
+	}
+	if err := vfsObj.MkdirAt(ctx, creds, pop("dir"), &vfs.MkdirOptions{Mode: linux.ModeDirectory | 0755}); err != nil {
+		t.Fatalf("MkdirAt failed: %v", err)
+	}
+	for _, f := range []struct {
+		path  string
+		pages int
+	}{
+		{"small", 1},
+		{"dir/large", 3},
+		{"dir/medium", 2},
+		{"empty", 0},
+	} {
+		fd, err := vfsObj.OpenAt(ctx, creds, pop(f.path), &vfs.OpenOptions{
+			Flags: linux.O_RDWR | linux.O_CREAT | linux.O_EXCL,
+			Mode:  linux.ModeRegular | 0644,
+		})
+		if err != nil {
+			t.Fatalf("OpenAt(%q) failed: %v", f.path, err)
+		}
+		data := bytes.Repeat([]byte{'a'}, f.pages*hostarch.PageSize)
+		if _, err := fd.Write(ctx, usermem.BytesIOSequence(data), vfs.WriteOptions{}); err != nil {
+			t.Fatalf("Write(%q) failed: %v", f.path, err)
+		}
+		fd.DecRef(ctx)
+	}
+	if err := vfsObj.LinkAt(ctx, creds, pop("dir/large"), pop("link")); err != nil {
+		t.Fatalf("LinkAt failed: %v", err)
+	}
+
+	ur := root.Mount().Filesystem().Impl().(vfs.UpperLayerUsageReporter)
+	usage, err := ur.UpperLayerUsage(ctx)
+	if err != nil {
+		t.Fatalf("UpperLayerUsage failed: %v", err)
+	}
+	if want := uint64(6 * hostarch.PageSize); usage.Bytes != want {
+		t.Errorf("UpperLayerUsage got %d bytes, want %d", usage.Bytes, want)
+	}
+
+	files, err := ur.UpperLayerLargestFiles(ctx, 2)
+	if err != nil {
+		t.Fatalf("UpperLayerLargestFiles failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("UpperLayerLargestFiles got %d files, want 2: %+v", len(files), files)
+	}
+	// The hard link may be reported instead of dir/large.
+	if got := files[0].Path; got != "/dir/large" && got != "/link" {
+		t.Errorf("largest file got %q, want /dir/large or /link", got)
+	}
+	if got, want := files[0].Bytes, uint64(3*hostarch.PageSize); got != want {
+		t.Errorf("largest file got %d bytes, want %d", got, want)
+	}
+	if got, want := files[1].Path, "/dir/medium"; got != want {
+		t.Errorf("second largest file got %q, want %q", got, want)
+	}
+}
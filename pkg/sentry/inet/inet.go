@@ -85,6 +85,21 @@ type Stack interface {
 	// SetTCPRecovery attempts to change TCP loss detection algorithm.
 	SetTCPRecovery(recovery TCPLossRecovery) error
 
+	// TCPMemoryLimits returns the limits on memory used by all TCP sockets.
+	TCPMemoryLimits() (TCPMemoryLimits, error)
+
+	// SetTCPMemoryLimits attempts to change the limits on memory used by all
+	// TCP sockets.
+	SetTCPMemoryLimits(limits TCPMemoryLimits) error
+
+	// TCPModerateReceiveBuffer returns true if TCP receive buffer auto-tuning
+	// is enabled.
+	TCPModerateReceiveBuffer() (bool, error)
+
+	// SetTCPModerateReceiveBuffer attempts to enable or disable TCP receive
+	// buffer auto-tuning.
+	SetTCPModerateReceiveBuffer(enabled bool) error
+
 	// Statistics reports stack statistics.
 	Statistics(stat any, arg string) error
 
@@ -161,8 +176,9 @@ type Interface struct {
 	// MTU is the maximum transmission unit.
 	MTU uint32
 
-	// Features are the device features queried from the host at
-	// stack creation time. These are immutable after startup.
+	// Features are the device features, as reported by ETHTOOL_GFEATURES.
+	// For host interfaces, they are queried from the host at stack creation
+	// time and are immutable after startup.
 	Features []linux.EthtoolGetFeaturesBlock
 
 	// Master is the index of the main controlling interface in a bonded setup.
@@ -182,6 +198,20 @@ type InterfaceAddr struct {
 
 	// Addr is the actual address.
 	Addr []byte
+
+	// Lifetimes are the remaining lifetimes of the address. If nil, the
+	// address never expires.
+	Lifetimes *InterfaceAddrLifetimes
+}
+
+// InterfaceAddrLifetimes contains the remaining lifetimes of an address in
+// seconds. A lifetime of linux.INFINITY_LIFE_TIME never expires.
+type InterfaceAddrLifetimes struct {
+	// Preferred is the time until the address is deprecated.
+	Preferred uint32
+
+	// Valid is the time until the address is removed.
+	Valid uint32
 }
 
 // TCPBufferSize contains settings controlling TCP buffer sizing.
@@ -198,6 +228,21 @@ type TCPBufferSize struct {
 	Max int
 }
 
+// TCPMemoryLimits contains the limits on memory used by all TCP sockets, in
+// pages. See tcp_mem in tcp(7).
+//
+// +stateify savable
+type TCPMemoryLimits struct {
+	// Min is the usage below which TCP leaves memory pressure.
+	Min int
+
+	// Pressure is the usage above which TCP enters memory pressure.
+	Pressure int
+
+	// Max is the maximum usage.
+	Max int
+}
+
 // StatDev describes one line of /proc/net/dev, i.e., stats for one network
 // interface.
 type StatDev [16]uint64
@@ -264,6 +309,16 @@ type StatSNMPUDP [8]uint64
 // StatSNMPUDPLite describes UdpLite line of /proc/net/snmp.
 type StatSNMPUDPLite [8]uint64
 
+// StatSNMPIP6 describes the Ip6 lines of /proc/net/snmp6.
+type StatSNMPIP6 [20]uint64
+
+// StatSNMPICMP6 describes the Icmp6 lines of /proc/net/snmp6.
+type StatSNMPICMP6 [35]uint64
+
+// StatTCPMem describes the TCP memory usage, in pages, reported by
+// /proc/net/sockstat.
+type StatTCPMem uint64
+
 // TCPLossRecovery indicates TCP loss detection and recovery methods to use.
 type TCPLossRecovery int32
 
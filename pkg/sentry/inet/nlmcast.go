@@ -22,6 +22,12 @@ import (
 const (
 	routeProtocol       = linux.NETLINK_ROUTE
 	routeLinkMcastGroup = linux.RTNLGRP_LINK
+
+	ueventProtocol = linux.NETLINK_KOBJECT_UEVENT
+
+	// UeventKernelMcastGroup is the NETLINK_KOBJECT_UEVENT multicast group
+	// to which the kernel sends uevents.
+	UeventKernelMcastGroup = 1
 )
 
 // InterfaceEventSubscriber allows clients to subscribe to events published by an inet.Stack.
@@ -58,6 +64,11 @@ type NetlinkSocket interface {
 	// HandleInterfaceDeleteEvent is called on NetlinkSockets that are members of the RTNLGRP_LINK
 	// multicast group when an interface is deleted.
 	HandleInterfaceDeleteEvent(context.Context, int32, Interface)
+
+	// HandleUevent is called on NETLINK_KOBJECT_UEVENT NetlinkSockets that
+	// are members of the kernel uevent multicast group when the kernel sends
+	// a uevent. The argument is the complete uevent message.
+	HandleUevent(context.Context, []byte)
 }
 
 // McastTable holds multicast group membership information for netlink netlinkSocket.
@@ -136,6 +147,14 @@ func (m *McastTable) OnInterfaceDeleteEvent(ctx context.Context, idx int32, i In
 	})
 }
 
+// OnUevent relays a kernel uevent to members of the kernel uevent multicast
+// group.
+func (m *McastTable) OnUevent(ctx context.Context, msg []byte) {
+	m.ForEachMcastSock(ueventProtocol, UeventKernelMcastGroup, func(s NetlinkSocket) {
+		s.HandleUevent(ctx, msg)
+	})
+}
+
 // NewNetlinkMcastTable creates a new McastTable.
 func NewNetlinkMcastTable() *McastTable {
 	return &McastTable{
@@ -40,6 +40,8 @@ type TestStack struct {
 	TCPSendBufSize    TCPBufferSize
 	TCPSACKFlag       bool
 	Recovery          TCPLossRecovery
+	TCPMemLimits      TCPMemoryLimits
+	TCPModerateRcvBuf bool
 	IPForwarding      bool
 }
 
@@ -158,6 +160,28 @@ func (s *TestStack) SetTCPRecovery(recovery TCPLossRecovery) error {
 	return nil
 }
 
+// TCPMemoryLimits implements Stack.
+func (s *TestStack) TCPMemoryLimits() (TCPMemoryLimits, error) {
+	return s.TCPMemLimits, nil
+}
+
+// SetTCPMemoryLimits implements Stack.
+func (s *TestStack) SetTCPMemoryLimits(limits TCPMemoryLimits) error {
+	s.TCPMemLimits = limits
+	return nil
+}
+
+// TCPModerateReceiveBuffer implements Stack.
+func (s *TestStack) TCPModerateReceiveBuffer() (bool, error) {
+	return s.TCPModerateRcvBuf, nil
+}
+
+// SetTCPModerateReceiveBuffer implements Stack.
+func (s *TestStack) SetTCPModerateReceiveBuffer(enabled bool) error {
+	s.TCPModerateRcvBuf = enabled
+	return nil
+}
+
 // Statistics implements Stack.
 func (s *TestStack) Statistics(stat any, arg string) error {
 	return nil
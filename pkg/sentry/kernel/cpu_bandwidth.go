@@ -0,0 +1,233 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernel
+
+import (
+	"fmt"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/abi/linux"
+)
+
+// Sentry-level CPU bandwidth control
+//
+// CPU bandwidth control limits the CPU time that the tasks of a container may
+// use in each period, like the CFS bandwidth controller of a host cgroup
+// (cpu.cfs_quota_us and cpu.cfs_period_us). It is enforced by the sentry, so
+// it also works when host cgroups are unavailable, e.g. in rootless sandboxes.
+//
+// CPU time is accounted to containers by the CPU clock ticker, with the same
+// approximation as task CPU clocks. Once a container has used its quota in
+// the current period, its tasks that are running application code are
+// interrupted, and tasks wait for the next period before (re-)entering
+// application code. Tasks executing in the sentry (e.g. in system calls) are
+// not preempted, so throttling is approximate.
+
+const (
+	// MinCPUBandwidthPeriod and MaxCPUBandwidthPeriod bound the period of CPU
+	// bandwidth control, as for cpu.cfs_period_us.
+	MinCPUBandwidthPeriod = time.Millisecond
+	MaxCPUBandwidthPeriod = time.Second
+
+	// DefaultCPUBandwidthPeriod is the default period of CPU bandwidth
+	// control, as for cpu.cfs_period_us.
+	DefaultCPUBandwidthPeriod = 100 * time.Millisecond
+)
+
+// CPUBandwidthStats are statistics about the CPU bandwidth control of a
+// container, analogous to the cpu.stat file of a host cgroup.
+type CPUBandwidthStats struct {
+	// Quota and Period are the CPU bandwidth limit of the container.
+	Quota  time.Duration
+	Period time.Duration
+
+	// Periods is the number of periods that have elapsed since the limit was
+	// set.
+	Periods uint64
+
+	// ThrottledPeriods is the number of periods in which the container used
+	// its quota and was throttled.
+	ThrottledPeriods uint64
+
+	// ThrottledTime is the total time for which the container was throttled.
+	ThrottledTime time.Duration
+
+	// Usage is the total CPU time accounted to the container since the limit
+	// was set.
+	Usage time.Duration
+}
+
+// cpuBandwidth is the CPU bandwidth control state of a container. Times are in
+// nanoseconds of the kernel's monotonic clock.
+type cpuBandwidth struct {
+	stats CPUBandwidthStats
+
+	// periodStart is the start time of the current period.
+	periodStart int64
+
+	// used is the CPU time accounted to the container in the current period.
+	used time.Duration
+
+	// throttled is true if the container has used its quota in the current
+	// period. If so, throttledSince is the time at which it was throttled.
+	throttled      bool
+	throttledSince int64
+}
+
+// advance moves b to the period containing now.
+func (b *cpuBandwidth) advance(now int64) {
+	period := int64(b.stats.Period)
+	if now < b.periodStart+period {
+		return
+	}
+	if b.throttled {
+		// Throttling ends at the end of the period in which it started.
+		b.stats.ThrottledTime += time.Duration(b.periodStart + period - b.throttledSince)
+		b.throttled = false
+	}
+	n := (now - b.periodStart) / period
+	b.periodStart += n * period
+	b.stats.Periods += uint64(n)
+	b.used = 0
+}
+
+// charge accounts dt of CPU time to the container at time now. It returns
+// true if this caused the container to be throttled.
+func (b *cpuBandwidth) charge(now int64, dt time.Duration) bool {
+	b.advance(now)
+	b.used += dt
+	b.stats.Usage += dt
+	if b.throttled || b.used < b.stats.Quota {
+		return false
+	}
+	b.throttled = true
+	b.throttledSince = now
+	b.stats.ThrottledPeriods++
+	return true
+}
+
+// throttledFor returns the time until the container may run application code
+// again, or 0 if it is not throttled at time now.
+func (b *cpuBandwidth) throttledFor(now int64) time.Duration {
+	b.advance(now)
+	if !b.throttled {
+		return 0
+	}
+	return time.Duration(b.periodStart + int64(b.stats.Period) - now)
+}
+
+// statsAt returns b's statistics as of time now.
+func (b *cpuBandwidth) statsAt(now int64) CPUBandwidthStats {
+	b.advance(now)
+	stats := b.stats
+	if b.throttled {
+		stats.ThrottledTime += time.Duration(now - b.throttledSince)
+	}
+	return stats
+}
+
+// SetContainerCPUBandwidth limits the tasks of the given container to quota
+// of CPU time in each period. If quota is not positive, the limit is removed.
+// If period is zero, DefaultCPUBandwidthPeriod is used. Statistics restart
+// from zero whenever the limit is changed.
+//
+// Limits are not saved; they must be set again after restore.
+func (k *Kernel) SetContainerCPUBandwidth(cid string, quota, period time.Duration) error {
+	if period == 0 {
+		period = DefaultCPUBandwidthPeriod
+	}
+	if quota > 0 && (period < MinCPUBandwidthPeriod || period > MaxCPUBandwidthPeriod) {
+		return fmt.Errorf("CPU bandwidth period %v is outside of [%v, %v]", period, MinCPUBandwidthPeriod, MaxCPUBandwidthPeriod)
+	}
+	now := k.MonotonicClock().Now().Nanoseconds()
+	k.cpuBandwidthMu.Lock()
+	defer k.cpuBandwidthMu.Unlock()
+	if quota <= 0 {
+		delete(k.cpuBandwidth, cid)
+	} else {
+		if k.cpuBandwidth == nil {
+			k.cpuBandwidth = make(map[string]*cpuBandwidth)
+		}
+		k.cpuBandwidth[cid] = &cpuBandwidth{
+			stats:       CPUBandwidthStats{Quota: quota, Period: period},
+			periodStart: now,
+		}
+	}
+	k.cpuBandwidthEnabled.Store(len(k.cpuBandwidth) != 0)
+	return nil
+}
+
+// ContainerCPUBandwidthStats returns the CPU bandwidth control statistics of
+// the given container. It returns false if the container has no limit.
+func (k *Kernel) ContainerCPUBandwidthStats(cid string) (CPUBandwidthStats, bool) {
+	now := k.MonotonicClock().Now().Nanoseconds()
+	k.cpuBandwidthMu.Lock()
+	defer k.cpuBandwidthMu.Unlock()
+	b, ok := k.cpuBandwidth[cid]
+	if !ok {
+		return CPUBandwidthStats{}, false
+	}
+	return b.statsAt(now), true
+}
+
+// chargeCPUBandwidth accounts a CPU clock tick to the containers of ran, and
+// interrupts tasks in tasks that are running application code in containers
+// that became throttled as a result.
+//
+// Preconditions: The caller must be the CPU clock ticker goroutine.
+func (k *Kernel) chargeCPUBandwidth(tasks, ran []*Task) {
+	now := k.MonotonicClock().Now().Nanoseconds()
+	var throttled map[string]struct{}
+	k.cpuBandwidthMu.Lock()
+	for _, t := range ran {
+		b, ok := k.cpuBandwidth[t.ContainerID()]
+		if !ok || !b.charge(now, linux.ClockTick) {
+			continue
+		}
+		if throttled == nil {
+			throttled = make(map[string]struct{})
+		}
+		throttled[t.ContainerID()] = struct{}{}
+	}
+	k.cpuBandwidthMu.Unlock()
+
+	if throttled == nil {
+		return
+	}
+	for _, t := range tasks {
+		if _, ok := throttled[t.ContainerID()]; ok && t.TaskGoroutineState() == TaskGoroutineRunningApp {
+			// Only interrupt the platform context: the task re-enters runApp,
+			// which waits for the next period.
+			t.p.Interrupt()
+		}
+	}
+}
+
+// cpuBandwidthThrottledFor returns the time until t may run application code
+// again under its container's CPU bandwidth limit, or 0 if it may run now.
+func (t *Task) cpuBandwidthThrottledFor() time.Duration {
+	k := t.k
+	if !k.cpuBandwidthEnabled.Load() {
+		return 0
+	}
+	now := k.MonotonicClock().Now().Nanoseconds()
+	k.cpuBandwidthMu.Lock()
+	defer k.cpuBandwidthMu.Unlock()
+	b, ok := k.cpuBandwidth[t.ContainerID()]
+	if !ok {
+		return 0
+	}
+	return b.throttledFor(now)
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCPUBandwidth(t *testing.T) {
+	const ms = int64(time.Millisecond)
+	b := cpuBandwidth{
+		stats: CPUBandwidthStats{Quota: 20 * time.Millisecond, Period: 100 * time.Millisecond},
+	}
+
+	// Use the quota in the first period.
+	if b.charge(5*ms, 10*time.Millisecond) {
+		t.Errorf("charge below quota throttled the container")
+	}
+	if d := b.throttledFor(6 * ms); d != 0 {
+		t.Errorf("throttledFor = %v below quota, want 0", d)
+	}
+	if !b.charge(10*ms, 10*time.Millisecond) {
+		t.Errorf("charge reaching quota didn't throttle the container")
+	}
+	if b.charge(11*ms, 10*time.Millisecond) {
+		t.Errorf("charge while throttled reported throttling again")
+	}
+	if got, want := b.throttledFor(40*ms), 60*time.Millisecond; got != want {
+		t.Errorf("throttledFor = %v, want %v", got, want)
+	}
+	if got, want := b.statsAt(40*ms).ThrottledTime, 30*time.Millisecond; got != want {
+		t.Errorf("ThrottledTime while throttled = %v, want %v", got, want)
+	}
+
+	// The next period starts unthrottled.
+	if d := b.throttledFor(100 * ms); d != 0 {
+		t.Errorf("throttledFor = %v in a new period, want 0", d)
+	}
+
+	// Skip a few periods.
+	stats := b.statsAt(350 * ms)
+	want := CPUBandwidthStats{
+		Quota:            20 * time.Millisecond,
+		Period:           100 * time.Millisecond,
+		Periods:          3,
+		ThrottledPeriods: 1,
+		ThrottledTime:    90 * time.Millisecond,
+		Usage:            30 * time.Millisecond,
+	}
+	if stats != want {
+		t.Errorf("statsAt = %+v, want %+v", stats, want)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernel
+
+import (
+	"bytes"
+	"fmt"
+
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/log"
+)
+
+// CPU hotplug.
+//
+// The set of possible CPUs, [0, ApplicationCores), is fixed when the kernel is
+// initialized. When the sandbox's CPU limit changes, CPUs at the end of that
+// range are taken offline or brought back online so that the number of online
+// CPUs reported in sysfs follows the limit. This is purely informational:
+// offline CPUs remain in tasks' affinity masks and may still be reported by
+// getcpu(2).
+
+// OnlineCPUs returns the number of online CPUs. CPUs [0, OnlineCPUs()) are
+// online.
+func (k *Kernel) OnlineCPUs() uint {
+	return uint(k.onlineCPUs.Load())
+}
+
+// CPUOnline returns true if the given CPU is online.
+func (k *Kernel) CPUOnline(cpu uint) bool {
+	return cpu < k.OnlineCPUs()
+}
+
+// SetOnlineCPUs sets the number of online CPUs to n, clamped to
+// [1, ApplicationCores], and sends a uevent for each CPU whose state changed.
+func (k *Kernel) SetOnlineCPUs(ctx context.Context, n uint) {
+	if n < 1 {
+		n = 1
+	}
+	if n > k.applicationCores {
+		log.Infof("Online CPUs %d exceed ApplicationCores, using %d", n, k.applicationCores)
+		n = k.applicationCores
+	}
+	old := uint(k.onlineCPUs.Swap(uint32(n)))
+	if old == n {
+		return
+	}
+	log.Infof("Online CPUs changed from %d to %d", old, n)
+	for cpu := old; cpu < n; cpu++ {
+		k.SendUevent(ctx, "online", fmt.Sprintf("/devices/system/cpu/cpu%d", cpu), "cpu")
+	}
+	for cpu := old; cpu > n; cpu-- {
+		k.SendUevent(ctx, "offline", fmt.Sprintf("/devices/system/cpu/cpu%d", cpu-1), "cpu")
+	}
+}
+
+// SendUevent broadcasts a kernel uevent with the given action for the device
+// at devpath, relative to /sys, to NETLINK_KOBJECT_UEVENT sockets in the root
+// network namespace.
+//
+// See lib/kobject_uevent.c:kobject_uevent_env.
+func (k *Kernel) SendUevent(ctx context.Context, action, devpath, subsystem string) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s@%s\x00", action, devpath)
+	fmt.Fprintf(&buf, "ACTION=%s\x00", action)
+	fmt.Fprintf(&buf, "DEVPATH=%s\x00", devpath)
+	fmt.Fprintf(&buf, "SUBSYSTEM=%s\x00", subsystem)
+	fmt.Fprintf(&buf, "SEQNUM=%d\x00", k.ueventSeqnum.Add(1))
+	k.rootNetworkNamespace.NetlinkMcastTable().OnUevent(ctx, buf.Bytes())
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernel
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	mrand "math/rand/v2"
+
+	"gvisor.dev/gvisor/pkg/atomicbitops"
+	"gvisor.dev/gvisor/pkg/rand"
+	"gvisor.dev/gvisor/pkg/sync"
+)
+
+// EntropyPool is the source of the random bytes returned by getrandom(2) and
+// read from /dev/random and /dev/urandom.
+//
+// By default, bytes are read from the host. If the pool is seeded, it instead
+// returns a stream derived from the seed, so that tests that depend on random
+// data are reproducible. A seeded pool must never be used in production.
+//
+// +stateify savable
+type EntropyPool struct {
+	// seed is the seed of a deterministic pool. If seed is nil, the pool
+	// reads from the host. seed is immutable.
+	seed []byte
+
+	// generation is incremented each time the pool is reseeded, which
+	// happens on restore so that sandboxes restored from the same image do
+	// not share random state. The VDSO discards its per-thread getrandom(2)
+	// state when generation changes. generation is never zero.
+	generation atomicbitops.Uint64
+
+	// mu protects rng.
+	mu sync.Mutex `state:"nosave"`
+
+	// rng generates the stream of a deterministic pool. It is nil if seed is
+	// nil.
+	rng *mrand.ChaCha8 `state:"nosave"`
+}
+
+// NewEntropyPool returns a new EntropyPool. If seed is not empty, the pool
+// returns a deterministic stream derived from it.
+func NewEntropyPool(seed []byte) *EntropyPool {
+	p := &EntropyPool{}
+	if len(seed) != 0 {
+		p.seed = append([]byte(nil), seed...)
+	}
+	p.generation.Store(1)
+	p.rekey()
+	return p
+}
+
+// rekey derives a new key for a deterministic pool from its seed and
+// generation.
+func (p *EntropyPool) rekey() {
+	if p.seed == nil {
+		return
+	}
+	h := sha256.New()
+	h.Write(p.seed)
+	binary.Write(h, binary.LittleEndian, p.generation.Load())
+	var key [32]byte
+	copy(key[:], h.Sum(nil))
+	p.mu.Lock()
+	p.rng = mrand.NewChaCha8(key)
+	p.mu.Unlock()
+}
+
+// afterLoad is invoked by stateify.
+func (p *EntropyPool) afterLoad(context.Context) {
+	p.generation.Add(1)
+	p.rekey()
+}
+
+// Generation returns the current generation of the pool.
+func (p *EntropyPool) Generation() uint64 {
+	return p.generation.Load()
+}
+
+// Deterministic returns true if the pool was seeded.
+func (p *EntropyPool) Deterministic() bool {
+	return p.seed != nil
+}
+
+// Read implements io.Reader.Read. Like Linux, reads of up to a page are
+// always completed fully.
+func (p *EntropyPool) Read(b []byte) (int, error) {
+	if p.seed == nil {
+		return rand.Reader.Read(b)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.rng.Read(b)
+}
@@ -96,6 +96,10 @@ type UserCounters struct {
 	uid auth.KUID
 
 	rlimitNProc atomicbitops.Uint64
+
+	// unixInflight is the number of files the user has in flight in
+	// SCM_RIGHTS messages. See UnixInflight.
+	unixInflight atomicbitops.Uint64
 }
 
 // incRLimitNProc increments the rlimitNProc counter.
@@ -178,10 +182,12 @@ type Kernel struct {
 	rootUserNamespace    *auth.UserNamespace
 	rootNetworkNamespace *inet.Namespace
 	applicationCores     uint
+	numaNodes            uint
 	useHostCores         bool
 	extraAuxv            []arch.AuxEntry
 	vdso                 *loader.VDSO
 	vdsoParams           *VDSOParamPage
+	entropy              *EntropyPool
 	rootUTSNamespace     *UTSNamespace
 	rootIPCNamespace     *IPCNamespace
 	rootCgroupNamespace  *CgroupNamespace
@@ -203,6 +209,17 @@ type Kernel struct {
 	// syslog is the kernel log.
 	syslog syslog
 
+	// sysctls records the sysctls set by containers.
+	sysctls SysctlTable
+
+	// versionOverride replaces the non-empty fields of the version advertised
+	// by syscall tables. It is immutable.
+	versionOverride Version
+
+	// cmdLine holds additional kernel command-line parameters. It is
+	// immutable.
+	cmdLine string
+
 	runningTasksMu runningTasksMutex `state:"nosave"`
 
 	// runningTasks is the total count of tasks currently in
@@ -221,6 +238,35 @@ type Kernel struct {
 	// is repopulated as tasks re-enter uninterruptible sleep.
 	blockedTasks atomicbitops.Int64 `state:"nosave"`
 
+	// schedStatsMu protects schedStats.
+	schedStatsMu sync.Mutex `state:"nosave"`
+
+	// schedStats maps container IDs to the scheduler statistics of their
+	// tasks. It is not saved; statistics restart from zero after restore.
+	schedStats map[string]*SchedStats `state:"nosave"`
+
+	// cpuBandwidthMu protects cpuBandwidth.
+	cpuBandwidthMu sync.Mutex `state:"nosave"`
+
+	// cpuBandwidth maps container IDs to the state of their sentry-level CPU
+	// bandwidth control (see cpu_bandwidth.go). It is not saved; limits are
+	// set again after restore.
+	cpuBandwidth map[string]*cpuBandwidth `state:"nosave"`
+
+	// cpuBandwidthEnabled is true if cpuBandwidth is not empty, so that tasks
+	// don't need to lock cpuBandwidthMu before running application code
+	// otherwise.
+	cpuBandwidthEnabled atomicbitops.Bool `state:"nosave"`
+
+	// pageMergingMu protects pageMergers, and excludes same-page merging
+	// during save.
+	pageMergingMu sync.Mutex `state:"nosave"`
+
+	// pageMergers is the state of same-page merging (see page_merging.go) for
+	// each container, keyed by container ID. They are not saved, since the
+	// references they hold are released before save.
+	pageMergers map[string]*mm.PageMerger `state:"nosave"`
+
 	// runningTasksCond is signaled when runningTasks is incremented from 0 to 1.
 	//
 	// Invariant: runningTasksCond.L == &runningTasksMu.
@@ -278,6 +324,13 @@ type Kernel struct {
 	userCPUClock    atomicbitops.Int64
 	userSysCPUClock atomicbitops.Int64
 
+	// preciseCPUClocks is true if task and thread group CPU clocks measure
+	// the time that tasks spend running with the cycle clock, rather than
+	// being sampled by the CPU clock ticker. See SetPreciseCPUClocks.
+	//
+	// preciseCPUClocks is set before any task runs.
+	preciseCPUClocks bool `state:"nosave"`
+
 	// uniqueID is used to generate unique identifiers.
 	//
 	// uniqueID is mutable, and is accessed using atomic memory operations.
@@ -391,6 +444,18 @@ type Kernel struct {
 	// used by processes.
 	MaxFDLimit atomicbitops.Int32
 
+	// unixInflight tracks files in flight in SCM_RIGHTS messages.
+	unixInflight UnixInflight
+
+	// onlineCPUs is the number of application CPUs that are online. CPUs
+	// [0, onlineCPUs) are online; the remaining CPUs up to applicationCores
+	// are offline. See SetOnlineCPUs.
+	onlineCPUs atomicbitops.Uint32
+
+	// ueventSeqnum is the sequence number of the last uevent sent by the
+	// kernel.
+	ueventSeqnum atomicbitops.Uint64
+
 	// devGofers maps containers (using its name) to its device gofer client.
 	devGofers   map[string]*devutil.GoferClient `state:"nosave"`
 	devGofersMu sync.Mutex                      `state:"nosave"`
@@ -421,6 +486,11 @@ type Kernel struct {
 	// when checkpoint/restore are done. It's protected by checkpointMu.
 	checkpointGen CheckpointGeneration
 
+	// bootID is the UUID reported by /proc/sys/kernel/random/boot_id. It is
+	// regenerated when the kernel is restored as a clone; see
+	// RegenerateIdentity. It's protected by checkpointMu.
+	bootID string
+
 	// SaveRestoreExecConfig stores configuration options for the save/restore
 	// exec binary.
 	SaveRestoreExecConfig *SaveRestoreExecConfig
@@ -450,6 +520,68 @@ type Kernel struct {
 	// DomainNamePoller is notified when the system domainname changes in *any*
 	// UTS namespace.
 	DomainNamePoller vfs.DynamicBytesPoller
+
+	// taskPanicHook, if not nil, is called by task goroutines that panic,
+	// before the panic crashes the sentry. It's immutable once tasks start
+	// running.
+	taskPanicHook func(t *Task, r any) `state:"nosave"`
+
+	// guestAPIHandler, if not nil, serves the requests of the guest API
+	// device that the sentry can't serve by itself. It's immutable once tasks
+	// start running.
+	guestAPIHandler GuestAPIHandler `state:"nosave"`
+}
+
+// SetTaskPanicHook sets a function that is called with the panicking task and
+// the value passed to panic when a task goroutine panics, before the panic
+// crashes the sentry. It must be called before any task starts running.
+func (k *Kernel) SetTaskPanicHook(hook func(t *Task, r any)) {
+	k.taskPanicHook = hook
+}
+
+// GuestAPIHandler serves the requests of trusted agents in the sandbox, made
+// through the guest API device, that the sentry can't serve by itself.
+type GuestAPIHandler interface {
+	// GuestAPIVersion returns the version of the runtime running the sandbox.
+	GuestAPIVersion() string
+
+	// GuestReady is called when an agent signals that the container it runs
+	// in is ready.
+	GuestReady(containerID string)
+}
+
+// SetGuestAPIHandler sets the handler of the guest API device. The device
+// can't be opened if there is no handler. It must be called before any task
+// starts running.
+func (k *Kernel) SetGuestAPIHandler(h GuestAPIHandler) {
+	k.guestAPIHandler = h
+}
+
+// GuestAPIHandler returns the handler of the guest API device, or nil if the
+// guest API is disabled.
+func (k *Kernel) GuestAPIHandler() GuestAPIHandler {
+	return k.guestAPIHandler
+}
+
+// SetPreciseCPUClocks makes task and thread group CPU clocks measure the time
+// that tasks spend running application and sentry code with the platform's
+// cycle clock, converted to nanoseconds with the frequency calibrated by the
+// Timekeeper. Otherwise, CPU clocks are advanced in whole CPU clock ticks by
+// sampling running tasks. It must be called before any task starts running.
+func (k *Kernel) SetPreciseCPUClocks() {
+	k.tasks.mu.RLock()
+	defer k.tasks.mu.RUnlock()
+	k.preciseCPUClocks = true
+	// Measured CPU time is added to the CPU time accumulated so far, which
+	// is non-zero after restore.
+	for t := range k.tasks.Root.tids {
+		t.appCPUBase = t.appCPUClock.Now().Nanoseconds()
+		t.appSysCPUBase = t.appSysCPUClock.Now().Nanoseconds()
+	}
+	for tg := range k.tasks.Root.tgids {
+		tg.appCPUBase = tg.appCPUClock.Now().Nanoseconds()
+		tg.appSysCPUBase = tg.appSysCPUClock.Now().Nanoseconds()
+	}
 }
 
 // InitKernelArgs holds arguments to Init.
@@ -473,6 +605,11 @@ type InitKernelArgs struct {
 	// most significant bit in cpu_possible_mask + 1.
 	ApplicationCores uint
 
+	// NUMANodes is the number of virtual NUMA nodes that application CPUs
+	// are divided between. If it is 0, a single node is used. See
+	// Kernel.CPUNode.
+	NUMANodes uint
+
 	// If UseHostCores is true, Task.CPU() returns the task goroutine's CPU
 	// instead of a virtualized CPU number, and Task.CopyToCPUMask() is a
 	// no-op. If ApplicationCores is less than hostcpu.MaxPossibleCPU(), it
@@ -489,6 +626,10 @@ type InitKernelArgs struct {
 	// VdsoParams is the VDSO parameter page manager.
 	VdsoParams *VDSOParamPage
 
+	// EntropySeed, if not empty, makes the kernel's EntropyPool return a
+	// deterministic stream derived from it. It is only for tests.
+	EntropySeed []byte
+
 	// RootUTSNamespace is the root UTS namespace.
 	RootUTSNamespace *UTSNamespace
 
@@ -503,6 +644,19 @@ type InitKernelArgs struct {
 	// unlimited.
 	MaxFDLimit int32
 
+	// MaxUnixInflightFDs is the maximum number of files that can be in
+	// flight in SCM_RIGHTS messages across the sandbox. If it is zero, only
+	// the per-user RLIMIT_NOFILE limit applies.
+	MaxUnixInflightFDs int64
+
+	// VersionOverride replaces the version advertised by syscall tables.
+	// Only its non-empty fields are used.
+	VersionOverride Version
+
+	// CmdLine holds additional kernel command-line parameters reported in
+	// /proc/cmdline.
+	CmdLine string
+
 	// Cgroup2FSInit initializes the cgroup2fs filesystem singleton.
 	Cgroup2FSInit func(ctx context.Context, k *Kernel, vfsObj *vfs.VirtualFilesystem) (*vfs.Filesystem, error)
 }
@@ -567,9 +721,24 @@ func (k *Kernel) Init(args InitKernelArgs) error {
 		}
 	}
 
+	if err := k.initNUMANodes(args.NUMANodes); err != nil {
+		return err
+	}
+	k.onlineCPUs.Store(uint32(k.applicationCores))
+
 	k.extraAuxv = args.ExtraAuxv
 	k.vdso = args.Vdso
 	k.vdsoParams = args.VdsoParams
+	k.entropy = NewEntropyPool(args.EntropySeed)
+	if k.entropy.Deterministic() {
+		log.Warningf("Entropy pool is seeded; random data is predictable")
+	}
+	if k.vdsoParams != nil {
+		if err := k.vdsoParams.SetRNGGeneration(k.entropy.Generation()); err != nil {
+			return fmt.Errorf("failed to initialize VDSO getrandom: %w", err)
+		}
+	}
+	k.bootID = k.newBootID()
 	k.futexes = futex.NewManager()
 	k.netlinkPorts = port.New()
 	k.ptraceExceptions = make(map[*Task]*Task)
@@ -579,6 +748,9 @@ func (k *Kernel) Init(args InitKernelArgs) error {
 		args.MaxFDLimit = MaxFdLimit
 	}
 	k.MaxFDLimit.Store(args.MaxFDLimit)
+	k.unixInflight.init(args.MaxUnixInflightFDs)
+	k.versionOverride = args.VersionOverride
+	k.cmdLine = args.CmdLine
 	k.containerNames = make(map[string]string)
 	k.CheckpointWait.k = k
 
@@ -748,6 +920,12 @@ func (k *Kernel) SaveTo(ctx context.Context, stateFile, pagesMetadata io.WriteCl
 		if err := k.vfs.PrepareSave(vfsCtx); err != nil {
 			return err
 		}
+		// Release references held by same-page merging, which is not saved,
+		// and prevent it from running until save is complete.
+		k.pageMergingMu.Lock()
+		defer k.pageMergingMu.Unlock()
+		k.releaseMergedPagesLocked()
+
 		// Mark all to-be-saved MemoryFiles as savable to inform kernel save below.
 		k.mf.MarkSavable()
 		for _, mf := range mfsToSave {
@@ -984,6 +1162,12 @@ func (k *Kernel) LoadFrom(ctx context.Context, r io.Reader, asyncMFLoader *Async
 	log.Infof("Kernel load took [%s].", time.Since(kernelStart))
 	timeline.Reached("Kernel loaded")
 
+	// onlineCPUs is 0 in kernels restored from checkpoints taken before it
+	// was added, in which all CPUs were online.
+	if k.onlineCPUs.Load() == 0 {
+		k.onlineCPUs.Store(uint32(k.applicationCores))
+	}
+
 	if asyncMFLoader == nil {
 		mfStart := time.Now()
 		if err := k.loadMemoryFiles(ctx, r); err != nil {
@@ -1003,6 +1187,12 @@ func (k *Kernel) LoadFrom(ctx context.Context, r io.Reader, asyncMFLoader *Async
 
 	k.Timekeeper().SetClocks(clocks, k.vdsoParams)
 
+	// The entropy pool was reseeded by afterLoad; make the VDSO discard
+	// getrandom state saved in application memory.
+	if err := k.vdsoParams.SetRNGGeneration(k.entropy.Generation()); err != nil {
+		return fmt.Errorf("failed to update VDSO getrandom generation: %w", err)
+	}
+
 	if timeReady != nil {
 		close(timeReady)
 	}
@@ -1185,6 +1375,10 @@ type CreateProcessArgs struct {
 
 	// TTY is the optional controlling TTY to associate with this process.
 	TTY *TTY
+
+	// Parent is the optional parent of the process. If Parent is nil, the
+	// process has no parent.
+	Parent *ThreadGroup
 }
 
 // NewContext returns a context.Context that represents the task that will be
@@ -1274,7 +1468,8 @@ func (ctx *createProcessContext) getMemoryCgroupID() uint32 {
 }
 
 // CreateProcess creates a new task in a new thread group with the given
-// options. The new task has no parent and is in the root PID namespace.
+// options. The new task has no parent, unless args.Parent is set, and is in
+// the root PID namespace.
 //
 // If k.Start() has already been called, then the created process must be
 // started by calling kernel.StartProcess(tg).
@@ -1382,6 +1577,10 @@ func (k *Kernel) CreateProcess(args CreateProcessArgs) (*ThreadGroup, ThreadID,
 	if se != nil {
 		return nil, 0, errors.New(se.String())
 	}
+	var parent *Task
+	if args.Parent != nil {
+		parent = args.Parent.Leader()
+	}
 	args.FDTable.IncRef()
 
 	// Create the task.
@@ -1389,6 +1588,7 @@ func (k *Kernel) CreateProcess(args CreateProcessArgs) (*ThreadGroup, ThreadID,
 		Kernel:           k,
 		ThreadGroup:      tg,
 		TaskImage:        image,
+		Parent:           parent,
 		FSContext:        fsContext,
 		FDTable:          args.FDTable,
 		Credentials:      newCreds,
@@ -1745,6 +1945,43 @@ func (k *Kernel) Unpause() {
 	k.tasks.EndExternalStop()
 }
 
+// PauseContainer requests that all tasks in the given container temporarily
+// stop executing, and blocks until they have stopped. Tasks created in the
+// container while it is paused start stopped. Unlike Pause, PauseContainer
+// doesn't nest, and doesn't wait for asynchronous I/O operations.
+//
+// Container pauses are not saved; the container's tasks are running after
+// restore.
+func (k *Kernel) PauseContainer(cid string) error {
+	k.extMu.Lock()
+	stopped, ok := k.tasks.BeginContainerExternalStop(cid)
+	k.extMu.Unlock()
+	if !ok {
+		return fmt.Errorf("container %q is already paused", cid)
+	}
+	for _, t := range stopped {
+		t.waitGoroutineStoppedOrExited()
+	}
+	return nil
+}
+
+// IsContainerPaused returns true if the given container is currently paused
+// by PauseContainer.
+func (k *Kernel) IsContainerPaused(cid string) bool {
+	return k.tasks.isContainerExternallyStopped(cid)
+}
+
+// UnpauseContainer ends the effect of a previous call to PauseContainer for
+// the given container.
+func (k *Kernel) UnpauseContainer(cid string) error {
+	k.extMu.Lock()
+	defer k.extMu.Unlock()
+	if !k.tasks.EndContainerExternalStop(cid) {
+		return fmt.Errorf("container %q is not paused", cid)
+	}
+	return nil
+}
+
 // SendExternalSignal injects a signal into the kernel.
 //
 // context is used only for debugging to describe how the signal was received.
@@ -1841,6 +2078,11 @@ func (k *Kernel) Timekeeper() *Timekeeper {
 	return k.timekeeper
 }
 
+// EntropyPool returns the kernel's source of random bytes for applications.
+func (k *Kernel) EntropyPool() *EntropyPool {
+	return k.entropy
+}
+
 // TaskSet returns the TaskSet.
 func (k *Kernel) TaskSet() *TaskSet {
 	return k.tasks
@@ -1911,6 +2153,17 @@ func (k *Kernel) Syslog() *syslog {
 	return &k.syslog
 }
 
+// Sysctls returns the sysctls set by containers.
+func (k *Kernel) Sysctls() *SysctlTable {
+	return &k.sysctls
+}
+
+// CmdLine returns the additional kernel command-line parameters reported in
+// /proc/cmdline.
+func (k *Kernel) CmdLine() string {
+	return k.cmdLine
+}
+
 // GenerateInotifyCookie generates a unique inotify event cookie.
 //
 // Returned values may overlap with previously returned values if the value
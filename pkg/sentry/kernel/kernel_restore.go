@@ -106,6 +106,49 @@ func (k *Kernel) IncCheckpointGenOnRestore() {
 	k.CheckpointWait.signal(k.checkpointGen, nil)
 }
 
+func init() {
+	// Kernel.bootID is generated lazily by BootID when loading state that
+	// doesn't include it.
+	state.RegisterSchemaChange("pkg/sentry/kernel.Kernel", state.SchemaChange{
+		Version:     1,
+		AddedFields: []string{"bootID"},
+	})
+}
+
+// BootID returns the kernel's boot ID, a random UUID.
+func (k *Kernel) BootID() string {
+	k.checkpointMu.Lock()
+	defer k.checkpointMu.Unlock()
+	if k.bootID == "" {
+		// Checkpoints taken by older versions don't include a boot ID.
+		k.bootID = k.newBootID()
+	}
+	return k.bootID
+}
+
+// RegenerateIdentity is called when the kernel is restored as a clone, i.e.
+// into a sandbox other than the one it was checkpointed from, so that clones
+// restored from the same checkpoint can be told apart. It generates a new
+// boot ID, which it returns. The entropy pool is reseeded on every restore, so
+// it isn't changed here.
+func (k *Kernel) RegenerateIdentity() string {
+	k.checkpointMu.Lock()
+	defer k.checkpointMu.Unlock()
+	k.bootID = k.newBootID()
+	return k.bootID
+}
+
+// newBootID returns a new random UUID.
+func (k *Kernel) newBootID() string {
+	var uuid [16]byte
+	if _, err := io.ReadFull(k.entropy, uuid[:]); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes for boot ID: %v", err))
+	}
+	uuid[8] = (uuid[8] & 0x3f) | 0x80 // RFC 4122 UUID
+	uuid[6] = (uuid[6] & 0x0f) | 0x40 // Version 4 (random)
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:])
+}
+
 // OnCheckpointAttempt is called when a checkpoint attempt is completed. err is
 // any checkpoint errors that may have occurred.
 func (k *Kernel) OnCheckpointAttempt(err error) {
@@ -279,18 +322,23 @@ type AsyncMFLoader struct {
 // If timeline is provided, it will be used to track async page loading.
 // It takes ownership of the timeline, and will end it when done loading all
 // pages.
-func NewAsyncMFLoader(pagesMetadata io.ReadCloser, pagesFile stateio.AsyncReader, mainMF *pgalloc.MemoryFile, timeline *timing.Timeline) *AsyncMFLoader {
+//
+// If onDemand is true, pages are only loaded when they are accessed; see
+// pgalloc.AsyncPagesFileLoad.LoadOnDemand. In this case, loading doesn't
+// complete until all pages have been accessed, and callers should not wait
+// for it.
+func NewAsyncMFLoader(pagesMetadata io.ReadCloser, pagesFile stateio.AsyncReader, mainMF *pgalloc.MemoryFile, onDemand bool, timeline *timing.Timeline) *AsyncMFLoader {
 	mfl := &AsyncMFLoader{
 		privateMFsChan: make(chan map[checkpoint.ResourceID]*pgalloc.MemoryFile, 1),
 	}
 	mfl.mainMFStartWg.Add(1)
 	mfl.metadataWg.Add(1)
 	mfl.loadWg.Add(1)
-	go mfl.backgroundGoroutine(pagesMetadata, pagesFile, mainMF, timeline)
+	go mfl.backgroundGoroutine(pagesMetadata, pagesFile, mainMF, onDemand, timeline)
 	return mfl
 }
 
-func (mfl *AsyncMFLoader) backgroundGoroutine(pagesMetadata io.ReadCloser, pagesFile stateio.AsyncReader, mainMF *pgalloc.MemoryFile, timeline *timing.Timeline) {
+func (mfl *AsyncMFLoader) backgroundGoroutine(pagesMetadata io.ReadCloser, pagesFile stateio.AsyncReader, mainMF *pgalloc.MemoryFile, onDemand bool, timeline *timing.Timeline) {
 	defer timeline.End()
 	defer pagesMetadata.Close()
 	cu := cleanup.Make(func() {
@@ -310,6 +358,9 @@ func (mfl *AsyncMFLoader) backgroundGoroutine(pagesMetadata io.ReadCloser, pages
 		return
 	}
 	cu.Add(apfl.MemoryFilesDone)
+	if onDemand {
+		apfl.LoadOnDemand()
+	}
 
 	opts := pgalloc.LoadOpts{
 		PagesFile: apfl,
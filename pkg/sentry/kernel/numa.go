@@ -0,0 +1,104 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernel
+
+import (
+	"fmt"
+
+	"gvisor.dev/gvisor/pkg/log"
+)
+
+// MaxNUMANodes is the maximum number of virtual NUMA nodes. It is bounded by
+// the mempolicy syscalls, which represent a nodemask as a single unsigned
+// long.
+const MaxNUMANodes = 64
+
+// Virtual NUMA topology.
+//
+// The sandbox does not expose the host's NUMA topology. Instead, application
+// CPUs [0, ApplicationCores) are divided into NUMANodes contiguous blocks of
+// (nearly) equal size, and each block is reported as a NUMA node and as a
+// distinct physical package. All interfaces that report topology (getcpu(2),
+// rseq, get_mempolicy(2), /proc/cpuinfo and sysfs) derive it from the methods
+// below, so that applications observe a consistent view.
+
+// initNUMANodes sets the number of virtual NUMA nodes.
+//
+// Preconditions: k.applicationCores has been initialized.
+func (k *Kernel) initNUMANodes(nodes uint) error {
+	if nodes == 0 {
+		nodes = 1
+	}
+	if nodes > MaxNUMANodes {
+		return fmt.Errorf("NUMANodes must be at most %d, got %d", MaxNUMANodes, nodes)
+	}
+	// Every node must contain at least one CPU.
+	if nodes > k.applicationCores {
+		log.Infof("NUMANodes is greater than ApplicationCores: reducing NUMANodes from %d to %d", nodes, k.applicationCores)
+		nodes = k.applicationCores
+	}
+	k.numaNodes = nodes
+	return nil
+}
+
+// NUMANodes returns the number of NUMA nodes visible to sandboxed
+// applications. The set of node IDs is [0, NUMANodes).
+func (k *Kernel) NUMANodes() uint {
+	// numaNodes is 0 in kernels restored from checkpoints taken before it
+	// was added, which had a single node.
+	if k.numaNodes == 0 {
+		return 1
+	}
+	return k.numaNodes
+}
+
+// NUMANodeMask returns a nodemask in which every node visible to sandboxed
+// applications is set.
+func (k *Kernel) NUMANodeMask() uint64 {
+	nodes := k.NUMANodes()
+	if nodes == MaxNUMANodes {
+		return ^uint64(0)
+	}
+	return (uint64(1) << nodes) - 1
+}
+
+// NUMANodeCPUs returns the range of CPUs [start, end) that belong to the given
+// NUMA node.
+//
+// Preconditions: node < k.NUMANodes().
+func (k *Kernel) NUMANodeCPUs(node uint) (start, end uint) {
+	nodes := k.NUMANodes()
+	start = node * k.applicationCores / nodes
+	end = (node + 1) * k.applicationCores / nodes
+	return start, end
+}
+
+// CPUNode returns the NUMA node that contains the given CPU.
+func (k *Kernel) CPUNode(cpu int32) uint {
+	if cpu < 0 {
+		return 0
+	}
+	// CPU numbers reported by the host or platform may exceed
+	// ApplicationCores; attribute them to the last node.
+	nodes := k.NUMANodes()
+	c := uint(cpu)
+	if c >= k.applicationCores {
+		return nodes - 1
+	}
+	// Node n contains CPUs [floor(n*C/N), floor((n+1)*C/N)), so the node
+	// containing c is the largest n such that floor(n*C/N) <= c, which is
+	// floor(((c+1)*N - 1) / C).
+	return ((c+1)*nodes - 1) / k.applicationCores
+}
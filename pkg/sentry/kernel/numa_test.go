@@ -0,0 +1,104 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernel
+
+import (
+	"testing"
+)
+
+func TestNUMANodes(t *testing.T) {
+	for _, test := range []struct {
+		cores uint
+		nodes uint
+		want  uint
+	}{
+		{cores: 4, nodes: 0, want: 1},
+		{cores: 4, nodes: 2, want: 2},
+		{cores: 4, nodes: 8, want: 4},
+		{cores: 128, nodes: MaxNUMANodes, want: MaxNUMANodes},
+	} {
+		k := &Kernel{applicationCores: test.cores}
+		if err := k.initNUMANodes(test.nodes); err != nil {
+			t.Fatalf("initNUMANodes(%d) with %d cores failed: %v", test.nodes, test.cores, err)
+		}
+		if got := k.NUMANodes(); got != test.want {
+			t.Errorf("NUMANodes() with %d cores and %d nodes requested = %d, want %d", test.cores, test.nodes, got, test.want)
+		}
+	}
+
+	k := &Kernel{applicationCores: 128}
+	if err := k.initNUMANodes(MaxNUMANodes + 1); err == nil {
+		t.Errorf("initNUMANodes(%d) succeeded, want error", MaxNUMANodes+1)
+	}
+}
+
+// A kernel whose numaNodes was never initialized, as when restored from an
+// older checkpoint, has a single node.
+func TestNUMANodesUninitialized(t *testing.T) {
+	k := &Kernel{applicationCores: 4}
+	if got := k.NUMANodes(); got != 1 {
+		t.Errorf("NUMANodes() = %d, want 1", got)
+	}
+	if got := k.NUMANodeMask(); got != 1 {
+		t.Errorf("NUMANodeMask() = %#x, want 0x1", got)
+	}
+	if start, end := k.NUMANodeCPUs(0); start != 0 || end != 4 {
+		t.Errorf("NUMANodeCPUs(0) = [%d, %d), want [0, 4)", start, end)
+	}
+	for _, cpu := range []int32{0, 3, 4} {
+		if got := k.CPUNode(cpu); got != 0 {
+			t.Errorf("CPUNode(%d) = %d, want 0", cpu, got)
+		}
+	}
+}
+
+func TestCPUNode(t *testing.T) {
+	for _, test := range []struct {
+		cores uint
+		nodes uint
+	}{
+		{cores: 1, nodes: 1},
+		{cores: 6, nodes: 4},
+		{cores: 7, nodes: 3},
+		{cores: 64, nodes: 8},
+		{cores: 100, nodes: MaxNUMANodes},
+	} {
+		k := &Kernel{applicationCores: test.cores}
+		if err := k.initNUMANodes(test.nodes); err != nil {
+			t.Fatalf("initNUMANodes(%d) failed: %v", test.nodes, err)
+		}
+		// Every CPU must belong to exactly one node, and CPUNode must agree
+		// with NUMANodeCPUs.
+		next := uint(0)
+		for node := uint(0); node < k.NUMANodes(); node++ {
+			start, end := k.NUMANodeCPUs(node)
+			if start != next || end <= start {
+				t.Fatalf("%d cores, %d nodes: NUMANodeCPUs(%d) = [%d, %d), want non-empty range starting at %d", test.cores, test.nodes, node, start, end, next)
+			}
+			for cpu := start; cpu < end; cpu++ {
+				if got := k.CPUNode(int32(cpu)); got != node {
+					t.Errorf("%d cores, %d nodes: CPUNode(%d) = %d, want %d", test.cores, test.nodes, cpu, got, node)
+				}
+			}
+			next = end
+		}
+		if next != test.cores {
+			t.Errorf("%d cores, %d nodes: nodes cover CPUs [0, %d), want [0, %d)", test.cores, test.nodes, next, test.cores)
+		}
+		if got, want := k.CPUNode(int32(test.cores)), k.NUMANodes()-1; got != want {
+			t.Errorf("%d cores, %d nodes: CPUNode(%d) = %d, want %d", test.cores, test.nodes, test.cores, got, want)
+		}
+	}
+}
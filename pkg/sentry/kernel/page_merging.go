@@ -0,0 +1,106 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernel
+
+import (
+	"gvisor.dev/gvisor/pkg/metric"
+	"gvisor.dev/gvisor/pkg/sentry/mm"
+)
+
+var (
+	mergedPages = metric.MustCreateNewUint64Metric("/memory/merged_pages", metric.Uint64Metadata{
+		Cumulative:  true,
+		Description: "Number of private anonymous pages deduplicated by same-page merging.",
+	})
+	stablePages = metric.MustCreateNewUint64Metric("/memory/stable_merged_pages", metric.Uint64Metadata{
+		Cumulative:  false,
+		Description: "Number of distinct pages currently shared by same-page merging.",
+	})
+)
+
+// MergePages performs one pass of same-page merging (see mm.PageMerger) over
+// the private anonymous memory of all tasks in k, and returns the number of
+// pages that were merged. Pages are only merged with pages of the same
+// container, so that containers can't learn each other's memory contents.
+func (k *Kernel) MergePages() uint64 {
+	k.pageMergingMu.Lock()
+	defer k.pageMergingMu.Unlock()
+
+	// Collect MemoryManagers by container, holding a user reference on each
+	// so that they aren't destroyed while being scanned.
+	mms := make(map[string][]*mm.MemoryManager)
+	seen := make(map[*mm.MemoryManager]struct{})
+	k.tasks.mu.RLock()
+	for t := range k.tasks.Root.tids {
+		t.WithMuLocked(func(t *Task) {
+			memMgr := t.MemoryManager()
+			if memMgr == nil {
+				return
+			}
+			if _, ok := seen[memMgr]; ok {
+				return
+			}
+			seen[memMgr] = struct{}{}
+			if memMgr.IncUsers() {
+				cid := t.ContainerID()
+				mms[cid] = append(mms[cid], memMgr)
+			}
+		})
+	}
+	k.tasks.mu.RUnlock()
+
+	// Release the stable pages of containers that no longer have tasks.
+	for cid, pm := range k.pageMergers {
+		if _, ok := mms[cid]; !ok {
+			pm.Release()
+			delete(k.pageMergers, cid)
+		}
+	}
+	if k.pageMergers == nil {
+		k.pageMergers = make(map[string]*mm.PageMerger)
+	}
+
+	ctx := k.SupervisorContext()
+	var merged uint64
+	var stable int
+	for cid, memMgrs := range mms {
+		pm, ok := k.pageMergers[cid]
+		if !ok {
+			pm = mm.NewPageMerger(k.mf)
+			k.pageMergers[cid] = pm
+		}
+		for _, memMgr := range memMgrs {
+			merged += memMgr.MergePages(ctx, pm)
+			memMgr.DecUsers(ctx)
+		}
+		pm.EndPass()
+		stable += pm.StablePages()
+	}
+	mergedPages.IncrementBy(merged)
+	stablePages.Set(uint64(stable))
+	return merged
+}
+
+// releaseMergedPagesLocked releases the references held by same-page merging,
+// which are not saved.
+//
+// Preconditions: k.pageMergingMu must be locked.
+func (k *Kernel) releaseMergedPagesLocked() {
+	for cid, pm := range k.pageMergers {
+		pm.Release()
+		delete(k.pageMergers, cid)
+	}
+	stablePages.Set(0)
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernel
+
+// Precise CPU clocks, see Kernel.SetPreciseCPUClocks.
+//
+// Task goroutines measure the time they spend in TaskGoroutineRunningApp and
+// TaskGoroutineRunningSys with the cycle clock when they change state. Reads
+// of CPU clocks add the time measured so far, including the current state, to
+// the CPU time accumulated before precise CPU clocks were enabled. CPU timers
+// still use the tasks' and thread groups' SyntheticClocks, which the CPU clock
+// ticker advances to the measured time on each tick.
+
+import (
+	"time"
+
+	"gvisor.dev/gvisor/pkg/sentry/ktime"
+	sentrytime "gvisor.dev/gvisor/pkg/sentry/time"
+)
+
+// preciseCPUClock is a CPU clock whose time is measured with the cycle clock.
+// Timers are set on the embedded SyntheticClock, so they may expire up to a
+// CPU clock tick after the measured time reaches their deadline.
+type preciseCPUClock struct {
+	*ktime.SyntheticClock
+	now func() int64
+}
+
+// Now implements ktime.Clock.Now.
+func (c *preciseCPUClock) Now() ktime.Time {
+	return ktime.FromNanoseconds(c.now())
+}
+
+// accountCPUCycles charges the cycles elapsed since gostate was last updated
+// to state, which the task goroutine is leaving.
+//
+// Preconditions:
+//   - The caller must be running on the task goroutine.
+//   - The caller must be in a t.gostateSeq writer critical section.
+func (t *Task) accountCPUCycles(state TaskGoroutineState) {
+	now := int64(sentrytime.Rdtsc())
+	last := t.cpuCycles.Load()
+	t.cpuCycles.Store(now)
+	if last == 0 || now <= last {
+		return
+	}
+	ns := t.k.timekeeper.cyclesToNanoseconds(sentrytime.TSCValue(now - last))
+	switch state {
+	case TaskGoroutineRunningApp:
+		t.appCPUTime.Add(ns)
+	case TaskGoroutineRunningSys:
+		t.sysCPUTime.Add(ns)
+	}
+}
+
+// preciseCPUTime returns the application time and the application+sentry
+// time measured for t since precise CPU clocks were enabled, up to the cycle
+// clock value now.
+func (t *Task) preciseCPUTime(now sentrytime.TSCValue) (app, appSys int64) {
+	for {
+		epoch := t.gostateSeq.BeginRead()
+		state := t.TaskGoroutineState()
+		last := t.cpuCycles.Load()
+		app = t.appCPUTime.Load()
+		sys := t.sysCPUTime.Load()
+		if !t.gostateSeq.ReadOk(epoch) {
+			continue
+		}
+		if last != 0 && int64(now) > last {
+			ns := t.k.timekeeper.cyclesToNanoseconds(now - sentrytime.TSCValue(last))
+			switch state {
+			case TaskGoroutineRunningApp:
+				app += ns
+			case TaskGoroutineRunningSys:
+				sys += ns
+			}
+		}
+		return app, app + sys
+	}
+}
+
+// preciseCPUTimeLocked returns the application time and the
+// application+sentry time measured for all past and present tasks in tg since
+// precise CPU clocks were enabled, up to the cycle clock value now.
+//
+// Preconditions: The TaskSet mutex must be locked.
+func (tg *ThreadGroup) preciseCPUTimeLocked(now sentrytime.TSCValue) (app, appSys int64) {
+	app, appSys = tg.exitedAppCPUTime, tg.exitedAppSysCPUTime
+	for t := tg.tasks.Front(); t != nil; t = t.Next() {
+		taskApp, taskAppSys := t.preciseCPUTime(now)
+		app += taskApp
+		appSys += taskAppSys
+	}
+	return app, appSys
+}
+
+// preciseCPUClocks returns precise CPU clocks measuring the application time
+// and the application+sentry time of t.
+func (t *Task) preciseCPUClocks() (user, userSys *preciseCPUClock) {
+	user = &preciseCPUClock{
+		SyntheticClock: &t.appCPUClock,
+		now: func() int64 {
+			app, _ := t.preciseCPUTime(sentrytime.Rdtsc())
+			return t.appCPUBase + app
+		},
+	}
+	userSys = &preciseCPUClock{
+		SyntheticClock: &t.appSysCPUClock,
+		now: func() int64 {
+			_, appSys := t.preciseCPUTime(sentrytime.Rdtsc())
+			return t.appSysCPUBase + appSys
+		},
+	}
+	return user, userSys
+}
+
+// preciseCPUClocks returns precise CPU clocks measuring the application time
+// and the application+sentry time of all past and present tasks in tg.
+func (tg *ThreadGroup) preciseCPUClocks() (user, userSys *preciseCPUClock) {
+	user = &preciseCPUClock{
+		SyntheticClock: &tg.appCPUClock,
+		now: func() int64 {
+			tg.pidns.owner.mu.RLock()
+			defer tg.pidns.owner.mu.RUnlock()
+			app, _ := tg.preciseCPUTimeLocked(sentrytime.Rdtsc())
+			return tg.appCPUBase + app
+		},
+	}
+	userSys = &preciseCPUClock{
+		SyntheticClock: &tg.appSysCPUClock,
+		now: func() int64 {
+			tg.pidns.owner.mu.RLock()
+			defer tg.pidns.owner.mu.RUnlock()
+			_, appSys := tg.preciseCPUTimeLocked(sentrytime.Rdtsc())
+			return tg.appSysCPUBase + appSys
+		},
+	}
+	return user, userSys
+}
+
+// exitPreciseCPUTimeLocked adds the CPU time measured for t to the CPU time of
+// exited tasks in its thread group, as t leaves it.
+//
+// Preconditions: The TaskSet mutex must be locked for writing.
+func (t *Task) exitPreciseCPUTimeLocked() {
+	app, appSys := t.preciseCPUTime(sentrytime.Rdtsc())
+	t.tg.exitedAppCPUTime += app
+	t.tg.exitedAppSysCPUTime += appSys
+}
+
+// tickPreciseCPUClocks advances the SyntheticClocks of tasks, and of their
+// thread groups, to the CPU time measured for the tasks, firing expired CPU
+// timers. It returns the application time and the application+sentry time
+// added.
+//
+// Preconditions: The caller must be the CPU clock ticker.
+func (k *Kernel) tickPreciseCPUClocks(tasks []*Task) (userNS, userSysNS int64) {
+	now := sentrytime.Rdtsc()
+	for _, t := range tasks {
+		app, appSys := t.preciseCPUTime(now)
+		if d := app - t.appCPUTicked; d > 0 {
+			t.appCPUTicked = app
+			t.appCPUClock.Add(time.Duration(d))
+			t.tg.appCPUClockLast.Store(t)
+			t.tg.appCPUClock.Add(time.Duration(d))
+			userNS += d
+		}
+		if d := appSys - t.appSysCPUTicked; d > 0 {
+			t.appSysCPUTicked = appSys
+			t.appSysCPUClock.Add(time.Duration(d))
+			t.tg.appSysCPUClockLast.Store(t)
+			t.tg.appSysCPUClock.Add(time.Duration(d))
+			userSysNS += d
+		}
+	}
+	return userNS, userSysNS
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernel
+
+import (
+	"fmt"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/fsimpl/timerfd"
+	"gvisor.dev/gvisor/pkg/sentry/ktime"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+)
+
+// RestoreTimerPolicy determines what happens to application timers that
+// expired while a checkpointed kernel wasn't running, i.e. between save and
+// restore. It applies to interval timers (setitimer(ITIMER_REAL)), POSIX
+// timers and timerfds that measure real or monotonic time. Timers that
+// measure CPU time don't advance while the kernel isn't running, so they are
+// not affected.
+type RestoreTimerPolicy int
+
+const (
+	// RestoreTimerFire delivers all expirations that were missed while the
+	// kernel wasn't running as soon as it's restored. This is the same as
+	// what happens when a system wakes up from suspend.
+	RestoreTimerFire RestoreTimerPolicy = iota
+
+	// RestoreTimerRearm delays timers by the time that passed between save
+	// and restore, so that each timer expires after the same amount of time
+	// that it had left when the kernel was saved. Timers that were set to
+	// expire at an absolute real time aren't delayed, since they are meant to
+	// expire at that wall-clock time.
+	RestoreTimerRearm
+
+	// RestoreTimerCancel drops expirations that were missed while the kernel
+	// wasn't running. One-shot timers that expired are disarmed, and periodic
+	// timers are advanced to their next expiration after the restore.
+	RestoreTimerCancel
+)
+
+// String implements fmt.Stringer.
+func (p RestoreTimerPolicy) String() string {
+	switch p {
+	case RestoreTimerFire:
+		return "fire"
+	case RestoreTimerRearm:
+		return "rearm"
+	case RestoreTimerCancel:
+		return "cancel"
+	default:
+		return fmt.Sprintf("RestoreTimerPolicy(%d)", int(p))
+	}
+}
+
+// adjust returns the Setting of a timer with Setting s after a restore that
+// happened gap after the save, given that the timer's clock now reads now.
+// realtime is true if the timer measures real time.
+func (p RestoreTimerPolicy) adjust(gap time.Duration, now ktime.Time, s ktime.Setting, realtime bool) ktime.Setting {
+	switch p {
+	case RestoreTimerRearm:
+		if realtime && s.Absolute {
+			return s
+		}
+		return s.Delay(gap)
+	case RestoreTimerCancel:
+		s, _ = s.At(now)
+		return s
+	default:
+		return s
+	}
+}
+
+// ApplyRestoreTimerPolicy applies p to the application timers of a kernel that
+// was just restored.
+//
+// Preconditions:
+//   - k was restored by LoadFrom.
+//   - k has not been started.
+func (k *Kernel) ApplyRestoreTimerPolicy(p RestoreTimerPolicy) {
+	if p == RestoreTimerFire {
+		return
+	}
+	gap := k.timekeeper.RestoreGap()
+	if gap <= 0 {
+		return
+	}
+	log.Infof("Applying restore timer policy %q for %v between save and restore", p, gap)
+
+	// A timerfd may be reachable from many FDTables, and an FDTable may be
+	// shared by many tasks, but each timer must be adjusted only once.
+	adjusted := make(map[*ktime.SampledTimer]struct{})
+	adjust := func(timer ktime.Timer) {
+		st, ok := timer.(*ktime.SampledTimer)
+		if !ok {
+			return
+		}
+		if _, ok := adjusted[st]; ok {
+			return
+		}
+		adjusted[st] = struct{}{}
+		c := st.Clock()
+		if c != k.timekeeper.realtimeClock && c != k.timekeeper.monotonicClock {
+			return
+		}
+		st.AdjustPaused(func(now ktime.Time, s ktime.Setting) ktime.Setting {
+			return p.adjust(gap, now, s, c == k.timekeeper.realtimeClock)
+		})
+	}
+
+	k.extMu.Lock()
+	defer k.extMu.Unlock()
+	// The kernel hasn't been started, so nothing else can be interacting with
+	// PIDNamespace.tids or FDTable.files, and all timers are still paused; see
+	// pauseTimeLocked.
+	ctx := k.SupervisorContext()
+	for t := range k.tasks.Root.tids {
+		if t == t.tg.leader {
+			adjust(t.tg.itimerRealTimer)
+			for _, it := range t.tg.timers {
+				adjust(it.timer)
+			}
+		}
+		if t.fdTable != nil {
+			t.fdTable.ForEach(ctx, func(_ int32, fd *vfs.FileDescription, _ FDFlags) bool {
+				if tfd, ok := fd.Impl().(*timerfd.TimerFileDescription); ok {
+					adjust(tfd.Timer())
+				}
+				return true
+			})
+		}
+	}
+}
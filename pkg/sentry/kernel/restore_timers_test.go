@@ -0,0 +1,122 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernel
+
+import (
+	"testing"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/sentry/ktime"
+)
+
+func TestRestoreTimerPolicyAdjust(t *testing.T) {
+	// The kernel was saved at 100s and restored at 1100s.
+	const gap = 1000 * time.Second
+	now := ktime.FromSeconds(1100)
+	disabled := ktime.Setting{Period: time.Second}
+	oneShot := ktime.Setting{Enabled: true, Next: ktime.FromSeconds(110)}
+	periodic := ktime.Setting{Enabled: true, Next: ktime.FromSeconds(110), Period: 300 * time.Second}
+	absolute := ktime.Setting{Enabled: true, Next: ktime.FromSeconds(110), Absolute: true}
+
+	for _, test := range []struct {
+		name     string
+		policy   RestoreTimerPolicy
+		setting  ktime.Setting
+		realtime bool
+		want     ktime.Setting
+	}{
+		{
+			name:    "fire one-shot",
+			policy:  RestoreTimerFire,
+			setting: oneShot,
+			want:    oneShot,
+		},
+		{
+			name:    "fire periodic",
+			policy:  RestoreTimerFire,
+			setting: periodic,
+			want:    periodic,
+		},
+		{
+			name:    "rearm disabled",
+			policy:  RestoreTimerRearm,
+			setting: disabled,
+			want:    disabled,
+		},
+		{
+			name:    "rearm one-shot",
+			policy:  RestoreTimerRearm,
+			setting: oneShot,
+			want:    ktime.Setting{Enabled: true, Next: ktime.FromSeconds(1110)},
+		},
+		{
+			name:    "rearm periodic",
+			policy:  RestoreTimerRearm,
+			setting: periodic,
+			want:    ktime.Setting{Enabled: true, Next: ktime.FromSeconds(1110), Period: 300 * time.Second},
+		},
+		{
+			name:     "rearm relative real time",
+			policy:   RestoreTimerRearm,
+			setting:  oneShot,
+			realtime: true,
+			want:     ktime.Setting{Enabled: true, Next: ktime.FromSeconds(1110)},
+		},
+		{
+			name:     "rearm absolute real time",
+			policy:   RestoreTimerRearm,
+			setting:  absolute,
+			realtime: true,
+			want:     absolute,
+		},
+		{
+			name:    "rearm absolute monotonic",
+			policy:  RestoreTimerRearm,
+			setting: absolute,
+			want:    ktime.Setting{Enabled: true, Next: ktime.FromSeconds(1110), Absolute: true},
+		},
+		{
+			name:    "cancel disabled",
+			policy:  RestoreTimerCancel,
+			setting: disabled,
+			want:    disabled,
+		},
+		{
+			name:    "cancel one-shot",
+			policy:  RestoreTimerCancel,
+			setting: oneShot,
+			want:    ktime.Setting{Next: ktime.FromSeconds(110)},
+		},
+		{
+			name:    "cancel periodic",
+			policy:  RestoreTimerCancel,
+			setting: periodic,
+			// Expirations at 110s, 410s, 710s and 1010s are dropped.
+			want: ktime.Setting{Enabled: true, Next: ktime.FromSeconds(1310), Period: 300 * time.Second},
+		},
+		{
+			name:    "cancel one-shot in the future",
+			policy:  RestoreTimerCancel,
+			setting: ktime.Setting{Enabled: true, Next: ktime.FromSeconds(1200)},
+			want:    ktime.Setting{Enabled: true, Next: ktime.FromSeconds(1200)},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.policy.adjust(gap, now, test.setting, test.realtime); got != test.want {
+				t.Errorf("%v.adjust(%v, %v, %+v, %t) = %+v, want %+v", test.policy, gap, now, test.setting, test.realtime, got, test.want)
+			}
+		})
+	}
+}
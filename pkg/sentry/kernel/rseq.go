@@ -60,25 +60,32 @@ func (t *Task) SetRSeq(addr hostarch.Addr, length, signature uint32) error {
 		if t.rseqAddr != addr {
 			return linuxerr.EINVAL
 		}
+		if t.rseqLen != length {
+			return linuxerr.EINVAL
+		}
 		if t.rseqSignature != signature {
 			return linuxerr.EINVAL
 		}
 		return linuxerr.EBUSY
 	}
 
-	// rseq must be aligned and correctly sized.
+	// rseq must be aligned and correctly sized. Lengths larger than the
+	// original structure are accepted so that userspace can register an
+	// area large enough for the extended fields reported in
+	// AT_RSEQ_FEATURE_SIZE.
 	if addr&(linux.AlignOfRSeq-1) != 0 {
 		return linuxerr.EINVAL
 	}
-	if length != linux.SizeOfRSeq {
+	if length < linux.SizeOfRSeq {
 		return linuxerr.EINVAL
 	}
-	if _, ok := t.MemoryManager().CheckIORange(addr, linux.SizeOfRSeq); !ok {
+	if _, ok := t.MemoryManager().CheckIORange(addr, int64(length)); !ok {
 		return linuxerr.EFAULT
 	}
 
 	t.rseqAddr = addr
 	t.rseqSignature = signature
+	t.rseqLen = length
 
 	// Initialize the CPUID.
 	//
@@ -87,6 +94,7 @@ func (t *Task) SetRSeq(addr hostarch.Addr, length, signature uint32) error {
 	if err := t.rseqUpdateCPU(); err != nil {
 		t.rseqAddr = 0
 		t.rseqSignature = 0
+		t.rseqLen = 0
 
 		t.Debugf("Failed to copy CPU to %#x for rseq: %v", t.rseqAddr, err)
 		t.forceSignal(linux.SIGSEGV, false /* unconditional */)
@@ -107,7 +115,7 @@ func (t *Task) ClearRSeq(addr hostarch.Addr, length, signature uint32) error {
 	if t.rseqAddr != addr {
 		return linuxerr.EINVAL
 	}
-	if length != linux.SizeOfRSeq {
+	if t.rseqLen != length {
 		return linuxerr.EINVAL
 	}
 	if t.rseqSignature != signature {
@@ -120,6 +128,7 @@ func (t *Task) ClearRSeq(addr hostarch.Addr, length, signature uint32) error {
 
 	t.rseqAddr = 0
 	t.rseqSignature = 0
+	t.rseqLen = 0
 
 	if t.oldRSeqCPUAddr == 0 {
 		// rseqCPU no longer needed.
@@ -241,7 +250,27 @@ func (t *Task) rseqCopyOutCPU() error {
 	// N.B. This write is not atomic, but since this occurs on the task
 	// goroutine then as long as userspace uses a single-instruction read
 	// it can't see an invalid value.
-	_, err := t.CopyOutBytes(t.rseqAddr, buf)
+	if _, err := t.CopyOutBytes(t.rseqAddr, buf); err != nil {
+		return err
+	}
+
+	// We don't track which threads of an address space are running, so we
+	// can't allocate concurrency IDs the way Linux does. Instead, report the
+	// CPU number as the concurrency ID. This makes it exactly as unique
+	// among concurrently running threads as CPUID, which critical sections
+	// already depend on, but no more so: two threads of the address space
+	// only share a concurrency ID if they also share a CPU number. Like
+	// CPUID, it may be as large as the largest CPU number reported by the
+	// platform or host, rather than being packed close to 0.
+	mmCID := t.rseqCPU
+	if mmCID < 0 {
+		mmCID = 0
+	}
+	buf = t.CopyScratchBuffer(8)
+	// NodeID and MMCID are adjacent fields in linux.RSeq.
+	hostarch.ByteOrder.PutUint32(buf, uint32(t.k.CPUNode(t.rseqCPU))) // NodeID
+	hostarch.ByteOrder.PutUint32(buf[4:], uint32(mmCID))              // MMCID
+	_, err := t.CopyOutBytes(t.rseqAddr+linux.OffsetOfRSeqNodeID, buf)
 	return err
 }
 
@@ -256,7 +285,14 @@ func (t *Task) rseqClearCPU() error {
 	// N.B. This write is not atomic, but since this occurs on the task
 	// goroutine then as long as userspace uses a single-instruction read
 	// it can't see an invalid value.
-	_, err := t.CopyOutBytes(t.rseqAddr, buf)
+	if _, err := t.CopyOutBytes(t.rseqAddr, buf); err != nil {
+		return err
+	}
+
+	buf = t.CopyScratchBuffer(8)
+	hostarch.ByteOrder.PutUint32(buf, 0)     // NodeID
+	hostarch.ByteOrder.PutUint32(buf[4:], 0) // MMCID
+	_, err := t.CopyOutBytes(t.rseqAddr+linux.OffsetOfRSeqNodeID, buf)
 	return err
 }
 
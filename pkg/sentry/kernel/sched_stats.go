@@ -0,0 +1,180 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernel
+
+import (
+	"math"
+	"time"
+)
+
+// schedStatsSampleTicks is the number of CPU clock ticks between samples of
+// per-container scheduler statistics.
+const schedStatsSampleTicks = 10
+
+// loadAvgPeriods are the periods of the load averages in /proc/loadavg.
+var loadAvgPeriods = [3]time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute}
+
+// pressurePeriods are the periods of the avg10, avg60 and avg300 averages in
+// /proc/pressure files.
+var pressurePeriods = [3]time.Duration{10 * time.Second, time.Minute, 5 * time.Minute}
+
+// PressureValues are the values of one line of a /proc/pressure file.
+type PressureValues struct {
+	// Avg is the percentage of time stalled over the last 10, 60 and 300
+	// seconds.
+	Avg [3]float64
+
+	// Total is the total time stalled.
+	Total time.Duration
+}
+
+// advance accounts a period of length dt that was stalled or not.
+func (p *PressureValues) advance(dt time.Duration, stalled bool) {
+	pct := 0.0
+	if stalled {
+		pct = 100
+		p.Total += dt
+	}
+	for i, period := range pressurePeriods {
+		p.Avg[i] = decayAverage(p.Avg[i], pct, dt, period)
+	}
+}
+
+// PressureStats is the pressure stall information for one resource.
+type PressureStats struct {
+	// Some is the time in which at least one task was stalled on the
+	// resource.
+	Some PressureValues
+
+	// Full is the time in which all non-idle tasks were stalled on the
+	// resource at the same time.
+	Full PressureValues
+}
+
+// SchedStats are statistics about the tasks of a container, derived from
+// samples of task states taken by the CPU clock ticker. They back the
+// per-container /proc/loadavg and /proc/pressure files.
+type SchedStats struct {
+	// LoadAvg is the average number of runnable and uninterruptibly blocked
+	// tasks over the last 1, 5 and 15 minutes.
+	LoadAvg [3]float64
+
+	// Tasks is the number of tasks at the last sample.
+	Tasks int
+
+	// Runnable is the number of runnable tasks at the last sample.
+	Runnable int
+
+	// Waiting is the number of runnable tasks that were not accounted CPU
+	// time at the last sample, i.e. that were waiting for a CPU.
+	Waiting int
+
+	// Blocked is the number of tasks in uninterruptible sleep at the last
+	// sample.
+	Blocked int
+
+	// CPU, IO and Memory are the pressure stall information of the tasks.
+	// The sentry doesn't reclaim memory, so Memory is always zero.
+	CPU    PressureStats
+	IO     PressureStats
+	Memory PressureStats
+
+	// lastSample is the time of the last sample, in nanoseconds of the
+	// kernel's monotonic clock.
+	lastSample int64
+}
+
+// advance updates the averages in s to now, assuming that the task states of
+// the last sample persisted until now.
+func (s *SchedStats) advance(now int64) {
+	if now <= s.lastSample {
+		return
+	}
+	dt := time.Duration(now - s.lastSample)
+	s.lastSample = now
+
+	active := float64(s.Runnable + s.Blocked)
+	for i, period := range loadAvgPeriods {
+		s.LoadAvg[i] = decayAverage(s.LoadAvg[i], active, dt, period)
+	}
+	s.CPU.Some.advance(dt, s.Waiting > 0)
+	s.CPU.Full.advance(dt, s.Waiting > 0 && s.Waiting == s.Runnable)
+	s.IO.Some.advance(dt, s.Blocked > 0)
+	s.IO.Full.advance(dt, s.Blocked > 0 && s.Runnable == 0)
+}
+
+// decayAverage returns the exponential moving average with the given period
+// that was avg, after val was observed for dt.
+func decayAverage(avg, val float64, dt, period time.Duration) float64 {
+	e := math.Exp(-float64(dt) / float64(period))
+	return avg*e + val*(1-e)
+}
+
+// sampleSchedStats updates the per-container scheduler statistics from the
+// current states of tasks. ran is the set of tasks that were accounted CPU
+// time in the current CPU clock tick; if it is nil, no runnable task is
+// considered to be waiting for a CPU.
+//
+// Preconditions: The caller must be the CPU clock ticker goroutine.
+func (k *Kernel) sampleSchedStats(tasks []*Task, ran map[*Task]struct{}) {
+	now := k.MonotonicClock().Now().Nanoseconds()
+	k.schedStatsMu.Lock()
+	defer k.schedStatsMu.Unlock()
+	if k.schedStats == nil {
+		k.schedStats = make(map[string]*SchedStats)
+	}
+	for _, s := range k.schedStats {
+		s.advance(now)
+		s.Tasks, s.Runnable, s.Waiting, s.Blocked = 0, 0, 0, 0
+	}
+	for _, t := range tasks {
+		s, ok := k.schedStats[t.ContainerID()]
+		if !ok {
+			s = &SchedStats{lastSample: now}
+			k.schedStats[t.ContainerID()] = s
+		}
+		s.Tasks++
+		switch t.TaskGoroutineState() {
+		case TaskGoroutineRunningApp, TaskGoroutineRunningSys:
+			s.Runnable++
+			if _, ok := ran[t]; ran != nil && !ok {
+				s.Waiting++
+			}
+		case TaskGoroutineBlockedUninterruptible:
+			s.Blocked++
+		}
+	}
+	// Forget containers without tasks; nothing can read their statistics.
+	for cid, s := range k.schedStats {
+		if s.Tasks == 0 {
+			delete(k.schedStats, cid)
+		}
+	}
+}
+
+// ContainerSchedStats returns the scheduler statistics of the tasks in the
+// given container, with averages updated to the current time.
+func (k *Kernel) ContainerSchedStats(cid string) SchedStats {
+	now := k.MonotonicClock().Now().Nanoseconds()
+	k.schedStatsMu.Lock()
+	defer k.schedStatsMu.Unlock()
+	s, ok := k.schedStats[cid]
+	if !ok {
+		return SchedStats{}
+	}
+	stats := *s
+	stats.advance(now)
+	return stats
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernel
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSchedStatsAdvance(t *testing.T) {
+	s := SchedStats{Runnable: 2, Waiting: 1}
+	s.advance(int64(time.Minute))
+
+	// After one period, the 1-minute load average has moved 1-1/e of the way
+	// from 0 to 2.
+	if want := 2 * (1 - math.Exp(-1)); math.Abs(s.LoadAvg[0]-want) > 1e-9 {
+		t.Errorf("LoadAvg[0] = %v, want %v", s.LoadAvg[0], want)
+	}
+	if !(s.LoadAvg[0] > s.LoadAvg[1] && s.LoadAvg[1] > s.LoadAvg[2]) {
+		t.Errorf("LoadAvg = %v, want decreasing averages", s.LoadAvg)
+	}
+	if got, want := s.CPU.Some.Total, time.Minute; got != want {
+		t.Errorf("CPU.Some.Total = %v, want %v", got, want)
+	}
+	if s.CPU.Some.Avg[0] < 99 {
+		t.Errorf("CPU.Some.Avg[0] = %v, want ~100", s.CPU.Some.Avg[0])
+	}
+	// One of two runnable tasks was running.
+	if s.CPU.Full.Total != 0 {
+		t.Errorf("CPU.Full.Total = %v, want 0", s.CPU.Full.Total)
+	}
+	if s.IO.Some.Total != 0 {
+		t.Errorf("IO.Some.Total = %v, want 0", s.IO.Some.Total)
+	}
+
+	// All tasks are blocked on I/O.
+	s.Runnable, s.Waiting, s.Blocked = 0, 0, 1
+	s.advance(int64(2 * time.Minute))
+	if got, want := s.IO.Full.Total, time.Minute; got != want {
+		t.Errorf("IO.Full.Total = %v, want %v", got, want)
+	}
+	if got, want := s.CPU.Some.Total, time.Minute; got != want {
+		t.Errorf("CPU.Some.Total = %v, want %v", got, want)
+	}
+	if s.CPU.Some.Avg[0] > 1 {
+		t.Errorf("CPU.Some.Avg[0] = %v, want ~0", s.CPU.Some.Avg[0])
+	}
+
+	// Time going backwards is ignored.
+	before := s
+	s.advance(int64(time.Minute))
+	if s != before {
+		t.Errorf("advance to the past changed stats from %+v to %+v", before, s)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernel
+
+import (
+	"maps"
+	"strings"
+
+	"gvisor.dev/gvisor/pkg/sync"
+)
+
+// SysctlTable records the sysctls that were set in the sandbox, by container:
+// those set by the OCI spec when the container started and those written by
+// its tasks under /proc/sys. It is saved along with the kernel, so that it
+// survives checkpoint and restore.
+//
+// Keys use the dotted sysctl(8) notation, e.g. "net.ipv4.tcp_sack".
+//
+// +stateify savable
+type SysctlTable struct {
+	// mu protects containers.
+	mu sync.Mutex `state:"nosave"`
+
+	// containers maps container IDs to the sysctls set by them.
+	containers map[string]map[string]string
+}
+
+// NormalizeSysctlValue returns value with its fields separated by a single
+// space, as sysctl(8) prints it. Vector sysctls are read back tab-separated
+// but are usually written space-separated.
+func NormalizeSysctlValue(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// Set records that container cid set key to value.
+func (s *SysctlTable) Set(cid, key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.containers == nil {
+		s.containers = make(map[string]map[string]string)
+	}
+	if s.containers[cid] == nil {
+		s.containers[cid] = make(map[string]string)
+	}
+	s.containers[cid][key] = NormalizeSysctlValue(value)
+}
+
+// Get returns a copy of the sysctls set by container cid.
+func (s *SysctlTable) Get(cid string) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.containers[cid])
+}
+
+// Remove forgets the sysctls set by container cid.
+func (s *SysctlTable) Remove(cid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.containers, cid)
+}
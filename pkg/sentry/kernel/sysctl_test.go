@@ -0,0 +1,56 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernel
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSysctlTable(t *testing.T) {
+	var s SysctlTable
+	if got := s.Get("c1"); len(got) != 0 {
+		t.Errorf("Get on empty table = %v, want empty", got)
+	}
+
+	s.Set("c1", "net.ipv4.tcp_sack", "0\n")
+	s.Set("c1", "net.ipv4.ip_local_port_range", "1024\t 65000\n")
+	s.Set("c2", "net.ipv4.tcp_sack", "1")
+	s.Set("c1", "net.ipv4.tcp_sack", "1")
+
+	want := map[string]string{
+		"net.ipv4.tcp_sack":            "1",
+		"net.ipv4.ip_local_port_range": "1024 65000",
+	}
+	got := s.Get("c1")
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Get(c1) mismatch (-want +got):\n%s", diff)
+	}
+
+	// The returned map must be a copy.
+	got["kernel.yama.ptrace_scope"] = "1"
+	if _, ok := s.Get("c1")["kernel.yama.ptrace_scope"]; ok {
+		t.Errorf("modifying the result of Get modified the table")
+	}
+
+	s.Remove("c1")
+	if got := s.Get("c1"); len(got) != 0 {
+		t.Errorf("Get(c1) after Remove = %v, want empty", got)
+	}
+	if diff := cmp.Diff(map[string]string{"net.ipv4.tcp_sack": "1"}, s.Get("c2")); diff != "" {
+		t.Errorf("Get(c2) mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -131,6 +131,32 @@ type Task struct {
 	// spent in TaskGoroutineRunningApp or TaskGoroutineRunningSys.
 	appSysCPUClock ktime.SyntheticClock
 
+	// The following fields measure the time that the task goroutine spends
+	// running when precise CPU clocks are enabled; see
+	// Kernel.SetPreciseCPUClocks.
+	//
+	// cpuCycles is the value of the cycle clock when gostate was last
+	// updated, or 0 if the task goroutine hasn't started. appCPUTime and
+	// sysCPUTime are the nanoseconds that the task goroutine has spent in
+	// TaskGoroutineRunningApp and TaskGoroutineRunningSys respectively, not
+	// counting the current state. They are owned by the task goroutine and
+	// protected by gostateSeq.
+	cpuCycles  atomicbitops.Int64 `state:"nosave"`
+	appCPUTime atomicbitops.Int64 `state:"nosave"`
+	sysCPUTime atomicbitops.Int64 `state:"nosave"`
+
+	// appCPUBase and appSysCPUBase are the values of appCPUClock and
+	// appSysCPUClock when precise CPU clocks were enabled.
+	appCPUBase    int64 `state:"nosave"`
+	appSysCPUBase int64 `state:"nosave"`
+
+	// appCPUTicked and appSysCPUTicked are the parts of the measured
+	// application and application+sentry time that the CPU clock ticker has
+	// added to appCPUClock and appSysCPUClock. They are only accessed by the
+	// CPU clock ticker.
+	appCPUTicked    int64 `state:"nosave"`
+	appSysCPUTicked int64 `state:"nosave"`
+
 	// yieldCount is the number of times the task goroutine has called
 	// Task.InterruptibleSleepStart, Task.UninterruptibleSleepStart, or
 	// Task.Yield(), voluntarily ceasing execution.
@@ -139,6 +165,39 @@ type Task struct {
 	// owned by the task goroutine.
 	yieldCount atomicbitops.Uint64
 
+	// faultCount is the number of application page faults handled by the
+	// sentry on behalf of the task. Faults that the platform resolves without
+	// the sentry's involvement are not counted.
+	//
+	// faultCount is accessed using atomic memory operations. faultCount is
+	// owned by the task goroutine.
+	faultCount atomicbitops.Uint64
+
+	// majorFaultCount is the number of faults counted by faultCount during
+	// which the task goroutine blocked.
+	//
+	// majorFaultCount is accessed using atomic memory operations.
+	// majorFaultCount is owned by the task goroutine.
+	majorFaultCount atomicbitops.Uint64
+
+	// preemptCount is the number of times the CPU clock ticker has preempted
+	// the task's application code.
+	//
+	// preemptCount is accessed using atomic memory operations.
+	preemptCount atomicbitops.Uint64
+
+	// blkioDelay is the number of nanoseconds that the task goroutine has
+	// spent in TaskGoroutineBlockedUninterruptible.
+	//
+	// blkioDelay is accessed using atomic memory operations. blkioDelay is
+	// owned by the task goroutine.
+	blkioDelay atomicbitops.Int64
+
+	// uninterruptibleCycles is the value of the cycle clock when the task
+	// goroutine entered TaskGoroutineBlockedUninterruptible. It is owned by
+	// the task goroutine.
+	uninterruptibleCycles int64 `state:"nosave"`
+
 	// pendingSignals is the set of pending signals that may be handled only by
 	// this task.
 	//
@@ -547,6 +606,16 @@ type Task struct {
 	// ioprio is protected by mu.
 	ioprio int
 
+	// timerSlack is the task's timer slack in nanoseconds, as set by
+	// prctl(PR_SET_TIMERSLACK). defaultTimerSlack is the value restored when
+	// the timer slack is set to 0. Timers armed by the sentry on behalf of
+	// the task always expire at their deadline, which is within any slack
+	// the task allows, so these are only tracked to be reported back.
+	//
+	// timerSlack and defaultTimerSlack are protected by mu.
+	timerSlack        uint64
+	defaultTimerSlack uint64
+
 	// This is used to track the numa policy for the current thread. This can be
 	// modified through a set_mempolicy(2) syscall. Since we always report a
 	// single numa node, all policies are no-ops. We only track this information
@@ -603,6 +672,12 @@ type Task struct {
 	// rseqSignature is exclusive to the task goroutine.
 	rseqSignature uint32
 
+	// rseqLen is the length of the userspace linux.RSeq structure, as
+	// passed to rseq(2).
+	//
+	// rseqLen is exclusive to the task goroutine.
+	rseqLen uint32
+
 	// copyScratchBuffer is a buffer available to CopyIn/CopyOut
 	// implementations that require an intermediate buffer to copy data
 	// into/out of. It prevents these buffers from being allocated/zeroed in
@@ -283,6 +283,7 @@ func (t *Task) Clone(args *linux.CloneArgs) (ThreadID, *SyscallControl, error) {
 	tg := t.tg
 	rseqAddr := hostarch.Addr(0)
 	rseqSignature := uint32(0)
+	rseqLen := uint32(0)
 	if args.Flags&linux.CLONE_THREAD == 0 {
 		sh := t.tg.signalHandlers
 		if args.Flags&linux.CLONE_SIGHAND == 0 {
@@ -305,6 +306,7 @@ func (t *Task) Clone(args *linux.CloneArgs) (ThreadID, *SyscallControl, error) {
 		tg.coredumpFilter = atomicbitops.FromUint32(t.tg.coredumpFilter.Load())
 		rseqAddr = t.rseqAddr
 		rseqSignature = t.rseqSignature
+		rseqLen = t.rseqLen
 	}
 
 	uc := t.userCounters
@@ -326,6 +328,7 @@ func (t *Task) Clone(args *linux.CloneArgs) (ThreadID, *SyscallControl, error) {
 		Credentials:      childCreds,
 		NoNewPrivs:       t.GetNoNewPrivs(),
 		Niceness:         t.Niceness(),
+		TimerSlack:       t.TimerSlack(),
 		NetworkNamespace: netns,
 		AllowedCPUMask:   t.CPUMask(),
 		UTSNamespace:     utsns,
@@ -334,6 +337,7 @@ func (t *Task) Clone(args *linux.CloneArgs) (ThreadID, *SyscallControl, error) {
 		MountNamespace:   mntns,
 		RSeqAddr:         rseqAddr,
 		RSeqSignature:    rseqSignature,
+		RSeqLen:          rseqLen,
 		ContainerID:      t.ContainerID(),
 		UserCounters:     uc,
 		SessionKeyring:   sessionKeyring,
@@ -339,6 +339,7 @@ func (r *runExecveAfterSiblingExitStop) execute(t *Task) taskRunState {
 	t.rseqCPU = -1
 	t.rseqAddr = 0
 	t.rseqSignature = 0
+	t.rseqLen = 0
 	t.oldRSeqCPUAddr = 0
 	t.tg.oldRSeqCritical.Store(&OldRSeqCriticalRegion{})
 	t.tg.pidns.owner.mu.Unlock()
@@ -773,6 +773,9 @@ func (t *Task) exitNotifyLocked(fromPtraceDetach bool) {
 			ns.deleteTask(t)
 		}
 		t.userCounters.decRLimitNProc()
+		if t.k.preciseCPUClocks {
+			t.exitPreciseCPUTimeLocked()
+		}
 		t.tg.signalHandlers.mu.Lock()
 		t.tg.tasks.Remove(t)
 		t.tg.tasksCount--
@@ -1224,7 +1227,7 @@ func (t *Task) waitCollectZombieLocked(target *Task, opts *WaitOptions, asPtrace
 	if target.parent != nil && target.parent.tg == t.tg && target.exitParentNotified {
 		target.exitParentAcked = true
 		if target == target.tg.leader {
-			t.tg.childCPUStats.Accumulate(target.tg.CPUStats())
+			t.tg.childCPUStats.Accumulate(target.tg.cpuStatsLocked())
 			t.tg.childCPUStats.Accumulate(target.tg.childCPUStats)
 			// Update t's child max resident set size. The size will be the maximum
 			// of this thread's size and all its childrens' sizes.
@@ -68,6 +68,18 @@ func (t *Task) run(threadID uintptr) {
 	t.blockingTimer = ktime.NewSampledTimer(t.k.MonotonicClock(), t.blockingTimerListener)
 	defer t.blockingTimer.Destroy()
 
+	if hook := t.k.taskPanicHook; hook != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				hook(t, r)
+				// Deferred functions run before the stack is unwound, so the
+				// stack trace printed for the repanic still shows where the
+				// original panic happened.
+				panic(r)
+			}
+		}()
+	}
+
 	// If this is a newly-started task, it should check for participation in
 	// group stops. If this is a task resuming after restore, it was
 	// interrupted by saving. In either case, the task is initially
@@ -160,6 +172,13 @@ func (app *runApp) execute(t *Task) taskRunState {
 		}
 	}
 
+	// If the task's container has used its CPU bandwidth quota, wait for the
+	// next period before running application code.
+	if d := t.cpuBandwidthThrottledFor(); d > 0 {
+		t.BlockWithTimeout(nil, true, d)
+		return (*runApp)(nil)
+	}
+
 	// We're about to switch to the application again. If there's still an
 	// unhandled SyscallRestartErrno that wasn't translated to an EINTR,
 	// restart the syscall that was interrupted. If there's a saved signal
@@ -273,11 +292,20 @@ func (app *runApp) execute(t *Task) taskRunState {
 		// normally.
 		if at.Any() {
 			faultCounter.Increment()
+			t.faultCount.Add(1)
+			t.tg.faultCount.Add(1)
 
 			region := trace.StartRegion(t.traceContext, faultRegion)
 			addr := hostarch.Addr(info.Addr())
+			yields := t.yieldCount.Load()
 			err := t.MemoryManager().HandleUserFault(t, addr, at, hostarch.Addr(t.Arch().Stack()))
 			region.End()
+			if t.yieldCount.Load() != yields {
+				// Handling the fault blocked, e.g. to read file contents,
+				// which makes it a major fault.
+				t.majorFaultCount.Add(1)
+				t.tg.majorFaultCount.Add(1)
+			}
 			if err == nil {
 				// The fault was handled appropriately.
 				// We can resume running the application.
@@ -26,9 +26,24 @@ import (
 	"gvisor.dev/gvisor/pkg/sentry/hostcpu"
 	"gvisor.dev/gvisor/pkg/sentry/kernel/sched"
 	"gvisor.dev/gvisor/pkg/sentry/ktime"
+	sentrytime "gvisor.dev/gvisor/pkg/sentry/time"
 	"gvisor.dev/gvisor/pkg/sentry/usage"
+	"gvisor.dev/gvisor/pkg/state"
 )
 
+func init() {
+	// Fault, preemption and blkio delay counts start at zero when loading
+	// state that doesn't include them.
+	state.RegisterSchemaChange("pkg/sentry/kernel.Task", state.SchemaChange{
+		Version:     3,
+		AddedFields: []string{"majorFaultCount", "preemptCount", "blkioDelay"},
+	})
+	state.RegisterSchemaChange("pkg/sentry/kernel.ThreadGroup", state.SchemaChange{
+		Version:     3,
+		AddedFields: []string{"majorFaultCount", "preemptCount", "blkioDelay"},
+	})
+}
+
 // TaskGoroutineState is a coarse representation of the current execution
 // status of a kernel.Task goroutine.
 type TaskGoroutineState uint32
@@ -89,6 +104,9 @@ func (t *Task) accountTaskGoroutineEnter(state TaskGoroutineState) {
 		panic(fmt.Sprintf("Task goroutine switching from state %v (expected %v) to %v", oldState, TaskGoroutineRunningSys, state))
 	}
 	t.gostateSeq.BeginWrite()
+	if t.k.preciseCPUClocks {
+		t.accountCPUCycles(TaskGoroutineRunningSys)
+	}
 	t.gostate.Store(uint32(state))
 	t.touchGostateTime()
 	t.gostateSeq.EndWrite()
@@ -99,6 +117,7 @@ func (t *Task) accountTaskGoroutineEnter(state TaskGoroutineState) {
 	if state == TaskGoroutineBlockedUninterruptible {
 		// Task is entering uninterruptible sleep.
 		t.k.blockedTasks.Add(1)
+		t.uninterruptibleCycles = int64(sentrytime.Rdtsc())
 	}
 }
 
@@ -114,16 +133,36 @@ func (t *Task) accountTaskGoroutineLeave(state TaskGoroutineState) {
 	if state == TaskGoroutineBlockedUninterruptible {
 		// Task is leaving uninterruptible sleep.
 		t.k.blockedTasks.Add(-1)
+		t.accountBlkioDelay()
 	}
 	if oldState := t.TaskGoroutineState(); oldState != state {
 		panic(fmt.Sprintf("Task goroutine switching from state %v (expected %v) to %v", oldState, state, TaskGoroutineRunningSys))
 	}
 	t.gostateSeq.BeginWrite()
+	if t.k.preciseCPUClocks {
+		t.accountCPUCycles(state)
+	}
 	t.gostate.Store(uint32(TaskGoroutineRunningSys))
 	t.touchGostateTime()
 	t.gostateSeq.EndWrite()
 }
 
+// accountBlkioDelay adds the time since the task goroutine entered
+// TaskGoroutineBlockedUninterruptible to its block I/O delay.
+//
+// Preconditions: The caller must be running on the task goroutine.
+func (t *Task) accountBlkioDelay() {
+	since := t.uninterruptibleCycles
+	t.uninterruptibleCycles = 0
+	now := int64(sentrytime.Rdtsc())
+	if since == 0 || now <= since {
+		return
+	}
+	ns := t.k.timekeeper.cyclesToNanoseconds(sentrytime.TSCValue(now - since))
+	t.blkioDelay.Add(ns)
+	t.tg.blkioDelay.Add(ns)
+}
+
 // Preconditions: The caller must be running on the task goroutine.
 func (t *Task) touchGostateTime() {
 	t.gostateTime.Store(t.k.cpuClock.Load())
@@ -138,24 +177,40 @@ func (k *Kernel) CPUClockNow() ktime.Time {
 // UserCPUClock returns a clock measuring the CPU time the task has spent
 // executing application code.
 func (t *Task) UserCPUClock() ktime.Clock {
+	if t.k.preciseCPUClocks {
+		user, _ := t.preciseCPUClocks()
+		return user
+	}
 	return &t.appCPUClock
 }
 
 // CPUClock returns a clock measuring the CPU time the task has spent executing
 // application and "kernel" code.
 func (t *Task) CPUClock() ktime.Clock {
+	if t.k.preciseCPUClocks {
+		_, userSys := t.preciseCPUClocks()
+		return userSys
+	}
 	return &t.appSysCPUClock
 }
 
 // UserCPUClock returns a ktime.Clock that measures the time that a thread
 // group has spent executing.
 func (tg *ThreadGroup) UserCPUClock() ktime.Clock {
+	if tg.leader.k.preciseCPUClocks {
+		user, _ := tg.preciseCPUClocks()
+		return user
+	}
 	return &tg.appCPUClock
 }
 
 // CPUClock returns a ktime.Clock that measures the time that a thread group
 // has spent executing, including sentry time.
 func (tg *ThreadGroup) CPUClock() ktime.Clock {
+	if tg.leader.k.preciseCPUClocks {
+		_, userSys := tg.preciseCPUClocks()
+		return userSys
+	}
 	return &tg.appSysCPUClock
 }
 
@@ -165,29 +220,70 @@ func (t *Task) CPUStats() usage.CPUStats {
 	// it's possible for the former to transiently exceed the latter.
 	appNS := t.appCPUClock.Now().Nanoseconds()
 	appSysNS := t.appSysCPUClock.Now().Nanoseconds()
+	if t.k.preciseCPUClocks {
+		app, appSys := t.preciseCPUTime(sentrytime.Rdtsc())
+		appNS, appSysNS = t.appCPUBase+app, t.appSysCPUBase+appSys
+	}
 	sysNS := max(appSysNS-appNS, 0)
+	faults := t.faultCount.Load()
+	majorFaults := t.majorFaultCount.Load()
 	return usage.CPUStats{
-		UserTime:          time.Duration(appNS),
-		SysTime:           time.Duration(sysNS),
-		VoluntarySwitches: t.yieldCount.Load(),
+		UserTime:            time.Duration(appNS),
+		SysTime:             time.Duration(sysNS),
+		VoluntarySwitches:   t.yieldCount.Load(),
+		InvoluntarySwitches: t.preemptCount.Load(),
+		MinorFaults:         faults - min(majorFaults, faults),
+		MajorFaults:         majorFaults,
+		BlkioDelay:          time.Duration(t.blkioDelay.Load()),
 	}
 }
 
 // CPUStats returns the combined CPU usage statistics of all past and present
 // threads in tg.
 func (tg *ThreadGroup) CPUStats() usage.CPUStats {
+	tg.pidns.owner.mu.RLock()
+	defer tg.pidns.owner.mu.RUnlock()
+	return tg.cpuStatsLocked()
+}
+
+// cpuStatsLocked is equivalent to CPUStats.
+//
+// Preconditions: The TaskSet mutex must be locked.
+func (tg *ThreadGroup) cpuStatsLocked() usage.CPUStats {
 	// The CPU clock ticker advances tg.appCPUClock before tg.appSysCPUClock,
 	// so it's possible for the former to transiently exceed the latter.
 	appNS := tg.appCPUClock.Now().Nanoseconds()
 	appSysNS := tg.appSysCPUClock.Now().Nanoseconds()
+	if tg.leader.k.preciseCPUClocks {
+		app, appSys := tg.preciseCPUTimeLocked(sentrytime.Rdtsc())
+		appNS, appSysNS = tg.appCPUBase+app, tg.appSysCPUBase+appSys
+	}
 	sysNS := max(appSysNS-appNS, 0)
+	faults := tg.faultCount.Load()
+	majorFaults := tg.majorFaultCount.Load()
 	return usage.CPUStats{
-		UserTime:          time.Duration(appNS),
-		SysTime:           time.Duration(sysNS),
-		VoluntarySwitches: tg.yieldCount.Load(),
+		UserTime:            time.Duration(appNS),
+		SysTime:             time.Duration(sysNS),
+		VoluntarySwitches:   tg.yieldCount.Load(),
+		InvoluntarySwitches: tg.preemptCount.Load(),
+		MinorFaults:         faults - min(majorFaults, faults),
+		MajorFaults:         majorFaults,
+		BlkioDelay:          time.Duration(tg.blkioDelay.Load()),
 	}
 }
 
+// PageFaults returns the number of application page faults handled by the
+// sentry on behalf of t.
+func (t *Task) PageFaults() uint64 {
+	return t.faultCount.Load()
+}
+
+// PageFaults returns the number of application page faults handled by the
+// sentry on behalf of all past and present threads in tg.
+func (tg *ThreadGroup) PageFaults() uint64 {
+	return tg.faultCount.Load()
+}
+
 // CPUStats returns the aggregate CPU usage statistics of all tasks in the
 // kernel (including tasks that have since exited), as accumulated by the CPU
 // clock ticker. It is used to implement the aggregate "cpu" line in
@@ -220,12 +316,20 @@ func (k *Kernel) runCPUClockTicker() {
 	var (
 		allTasks []*Task
 		incTasks = make([]*Task, k.applicationCores)
+		ranTasks = make(map[*Task]struct{}, k.applicationCores)
 	)
 	concurrencyCount := k.ConcurrencyCount()
 
-	for {
+	for ticks := uint64(1); ; ticks++ {
 		// Stop CPU clocks while nothing is running.
 		if k.runningTasks.Load() == 0 {
+			// Scheduler statistics aren't sampled while the ticker is stopped,
+			// so record the idle state first.
+			allTasks = k.tasks.Root.TasksAppend(allTasks)
+			k.sampleSchedStats(allTasks, nil)
+			clear(allTasks)
+			allTasks = allTasks[:0]
+
 			k.runningTasksMu.Lock()
 			if k.runningTasks.Load() == 0 {
 				k.cpuClockTickerRunning = false
@@ -301,15 +405,21 @@ func (k *Kernel) runCPUClockTicker() {
 		// below and feed the kernel-wide CPU time accumulators.
 		var userTickInc, userSysTickInc int64
 		for _, t := range incTasks[:numIncTasks] {
-			switch t.TaskGoroutineState() {
+			state := t.TaskGoroutineState()
+			if state == TaskGoroutineRunningApp && preempt {
+				t.p.Preempt()
+				t.preemptCount.Add(1)
+				t.tg.preemptCount.Add(1)
+			}
+			if k.preciseCPUClocks {
+				continue
+			}
+			switch state {
 			case TaskGoroutineRunningApp:
 				t.appCPUClock.Add(linux.ClockTick)
 				t.tg.appCPUClockLast.Store(t)
 				t.tg.appCPUClock.Add(linux.ClockTick)
 				userTickInc++
-				if preempt {
-					t.p.Preempt()
-				}
 				fallthrough
 			case TaskGoroutineRunningSys:
 				t.appSysCPUClock.Add(linux.ClockTick)
@@ -318,11 +428,29 @@ func (k *Kernel) runCPUClockTicker() {
 				userSysTickInc++
 			}
 		}
-		if userTickInc != 0 {
-			k.userCPUClock.Add(userTickInc * linux.ClockTick.Nanoseconds())
+		if k.cpuBandwidthEnabled.Load() {
+			k.chargeCPUBandwidth(allTasks, incTasks[:numIncTasks])
+		}
+		userNS := userTickInc * linux.ClockTick.Nanoseconds()
+		userSysNS := userSysTickInc * linux.ClockTick.Nanoseconds()
+		if k.preciseCPUClocks {
+			// Precise CPU clocks are advanced by the time measured for all
+			// tasks, rather than by sampling running tasks.
+			userNS, userSysNS = k.tickPreciseCPUClocks(allTasks)
 		}
-		if userSysTickInc != 0 {
-			k.userSysCPUClock.Add(userSysTickInc * linux.ClockTick.Nanoseconds())
+		if userNS != 0 {
+			k.userCPUClock.Add(userNS)
+		}
+		if userSysNS != 0 {
+			k.userSysCPUClock.Add(userSysNS)
+		}
+
+		if ticks%schedStatsSampleTicks == 0 {
+			for _, t := range incTasks[:numIncTasks] {
+				ranTasks[t] = struct{}{}
+			}
+			k.sampleSchedStats(allTasks, ranTasks)
+			clear(ranTasks)
 		}
 
 		// Reset storage for the next iteration.
@@ -475,6 +603,28 @@ func (t *Task) SetNiceness(n int) {
 	t.niceness = n
 }
 
+// DefaultTimerSlack is the timer slack in nanoseconds of tasks that don't
+// inherit one. See include/linux/init_task.h.
+const DefaultTimerSlack = 50000
+
+// TimerSlack returns t's timer slack in nanoseconds.
+func (t *Task) TimerSlack() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.timerSlack
+}
+
+// SetTimerSlack sets t's timer slack to ns nanoseconds. If ns is 0, t's
+// timer slack is reset to the value it had when t was created.
+func (t *Task) SetTimerSlack(ns uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ns == 0 {
+		ns = t.defaultTimerSlack
+	}
+	t.timerSlack = ns
+}
+
 // SetIOPrio sets t's ioprio.
 func (t *Task) SetIOPrio(ioprio int) {
 	t.mu.Lock()
@@ -75,6 +75,10 @@ type TaskConfig struct {
 	// Niceness is the niceness of the new task.
 	Niceness int
 
+	// TimerSlack is the timer slack of the new task in nanoseconds. If it is
+	// zero, the new task uses DefaultTimerSlack.
+	TimerSlack uint64
+
 	// NetworkNamespace is the network namespace to be used for the new task.
 	NetworkNamespace *inet.Namespace
 
@@ -100,6 +104,9 @@ type TaskConfig struct {
 	// with.
 	RSeqSignature uint32
 
+	// RSeqLen is the length of the userspace linux.RSeq structure.
+	RSeqLen uint32
+
 	// ContainerID is the container the new task belongs to.
 	ContainerID string
 
@@ -225,6 +232,7 @@ func (ts *TaskSet) newTask(ctx context.Context, cfg *TaskConfig) (*Task, error)
 		rseqCPU:         -1,
 		rseqAddr:        cfg.RSeqAddr,
 		rseqSignature:   cfg.RSeqSignature,
+		rseqLen:         cfg.RSeqLen,
 		futexWaiter:     futex.NewWaiter(),
 		containerID:     cfg.ContainerID,
 		cgroups:         make(map[Cgroup]struct{}),
@@ -236,6 +244,11 @@ func (ts *TaskSet) newTask(ctx context.Context, cfg *TaskConfig) (*Task, error)
 		noNewPrivs:      cfg.NoNewPrivs,
 		cgroup2:         cgroup2,
 	}
+	t.timerSlack = cfg.TimerSlack
+	if t.timerSlack == 0 {
+		t.timerSlack = DefaultTimerSlack
+	}
+	t.defaultTimerSlack = t.timerSlack
 	t.netns = cfg.NetworkNamespace
 	t.creds.Store(cfg.Credentials)
 	t.fsContext.Store(cfg.FSContext)
@@ -396,8 +409,12 @@ func (ts *TaskSet) newTask(ctx context.Context, cfg *TaskConfig) (*Task, error)
 	tg.liveTasks++
 	tg.activeTasks++
 
-	// Propagate external TaskSet stops to the new task.
-	t.stopCount = atomicbitops.FromInt32(ts.stopCount)
+	// Propagate external TaskSet and container stops to the new task.
+	stopCount := ts.stopCount
+	if _, ok := ts.stoppedContainers[t.containerID]; ok {
+		stopCount++
+	}
+	t.stopCount = atomicbitops.FromInt32(stopCount)
 
 	t.mu.Lock()
 	t.cpu = atomicbitops.FromInt32(assignCPU(t.allowedCPUMask, ts.Root.tids[t]))
@@ -241,6 +241,73 @@ func (ts *TaskSet) EndExternalStop() {
 	}
 }
 
+// BeginContainerExternalStop indicates the start of an external stop that
+// applies to all current and future tasks in ts that belong to the given
+// container. It returns the tasks that were stopped, and false if the
+// container was already stopped. BeginContainerExternalStop does not wait
+// for task goroutines to stop.
+func (ts *TaskSet) BeginContainerExternalStop(cid string) ([]*Task, bool) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if _, ok := ts.stoppedContainers[cid]; ok {
+		return nil, false
+	}
+	if ts.stoppedContainers == nil {
+		ts.stoppedContainers = make(map[string]struct{})
+	}
+	ts.stoppedContainers[cid] = struct{}{}
+	if ts.Root == nil {
+		return nil, true
+	}
+	var stopped []*Task
+	for t := range ts.Root.tids {
+		if t.containerID != cid {
+			continue
+		}
+		t.tg.signalHandlers.mu.Lock()
+		t.beginStopLocked()
+		t.tg.signalHandlers.mu.Unlock()
+		t.interrupt()
+		stopped = append(stopped, t)
+	}
+	return stopped, true
+}
+
+// EndContainerExternalStop ends the external stop started by a previous call
+// to TaskSet.BeginContainerExternalStop for the given container. It returns
+// false if the container was not stopped. EndContainerExternalStop does not
+// wait for task goroutines to resume.
+func (ts *TaskSet) EndContainerExternalStop(cid string) bool {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if _, ok := ts.stoppedContainers[cid]; !ok {
+		return false
+	}
+	delete(ts.stoppedContainers, cid)
+	if ts.Root == nil {
+		return true
+	}
+	for t := range ts.Root.tids {
+		if t.containerID != cid {
+			continue
+		}
+		t.tg.signalHandlers.mu.Lock()
+		t.endStopLocked()
+		t.tg.signalHandlers.mu.Unlock()
+	}
+	return true
+}
+
+// isContainerExternallyStopped returns true if BeginContainerExternalStop has
+// been called for the given container, without a corresponding call to
+// EndContainerExternalStop.
+func (ts *TaskSet) isContainerExternallyStopped(cid string) bool {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	_, ok := ts.stoppedContainers[cid]
+	return ok
+}
+
 // isExternallyStopped returns true if BeginExternalStop() has been called on
 // this TaskSet, without a corresponding call to EndExternalStop().
 func (ts *TaskSet) isExternallyStopped() bool {
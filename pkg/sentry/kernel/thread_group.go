@@ -206,10 +206,33 @@ type ThreadGroup struct {
 	// present tasks in the thread group.
 	appSysCPUClock ktime.SyntheticClock
 
+	// appCPUBase and appSysCPUBase are the values of appCPUClock and
+	// appSysCPUClock when precise CPU clocks were enabled, which the CPU
+	// time measured since is added to. See Kernel.SetPreciseCPUClocks.
+	appCPUBase    int64 `state:"nosave"`
+	appSysCPUBase int64 `state:"nosave"`
+
+	// exitedAppCPUTime and exitedAppSysCPUTime are the application and
+	// application+sentry time measured by precise CPU clocks for tasks that
+	// have left the thread group. They are protected by the TaskSet mutex.
+	exitedAppCPUTime    int64 `state:"nosave"`
+	exitedAppSysCPUTime int64 `state:"nosave"`
+
 	// yieldCount is the sum of Task.yieldCount for all past and present tasks
 	// in the thread group.
 	yieldCount atomicbitops.Uint64
 
+	// faultCount is the sum of Task.faultCount for all past and present tasks
+	// in the thread group.
+	faultCount atomicbitops.Uint64
+
+	// majorFaultCount, preemptCount and blkioDelay are the sums of the
+	// corresponding Task fields for all past and present tasks in the thread
+	// group.
+	majorFaultCount atomicbitops.Uint64
+	preemptCount    atomicbitops.Uint64
+	blkioDelay      atomicbitops.Int64
+
 	// childCPUStats is the CPU usage of all joined descendants of this thread
 	// group. childCPUStats is protected by the TaskSet mutex.
 	childCPUStats usage.CPUStats
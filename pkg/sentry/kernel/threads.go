@@ -78,6 +78,14 @@ type TaskSet struct {
 	// always reset to zero after restore.
 	stopCount int32 `state:"nosave"`
 
+	// stoppedContainers is the set of container IDs for which an external stop
+	// applies to all tasks in the container (see
+	// TaskSet.BeginContainerExternalStop). stoppedContainers is protected by
+	// mu.
+	//
+	// stoppedContainers is not saved for the same reason as stopCount.
+	stoppedContainers map[string]struct{} `state:"nosave"`
+
 	// liveTasks is the number of tasks in the TaskSet whose goroutines have
 	// not exited. liveTasks is protected by mu.
 	liveTasks uint32
@@ -417,6 +425,13 @@ func (ns *PIDNamespace) NumTasksPerContainer(cid string) int {
 	return tasks
 }
 
+// LastTID returns the last thread ID allocated in ns.
+func (ns *PIDNamespace) LastTID() ThreadID {
+	ns.owner.mu.RLock()
+	defer ns.owner.mu.RUnlock()
+	return ns.last
+}
+
 // ThreadGroups returns a snapshot of the thread groups in ns.
 func (ns *PIDNamespace) ThreadGroups() []*ThreadGroup {
 	return ns.ThreadGroupsAppend(nil)
@@ -93,6 +93,13 @@ type Timekeeper struct {
 	// monotonicOffset.
 	saveRealtime int64
 
+	// restoreGap is the amount of real time that elapsed between save and
+	// restore, or zero if real time went backwards or this Timekeeper was not
+	// restored.
+	//
+	// It is set only once, by SetClocks.
+	restoreGap time.Duration `state:"nosave"`
+
 	// mu protects destruction with stop and wg.
 	mu sync.Mutex `state:"nosave"`
 
@@ -121,6 +128,12 @@ type Timekeeper struct {
 
 	// vDSO parameter page kept updated by the Timekeeper mechanism
 	params *VDSOParamPage `state:"nosave"`
+
+	// cycleFrequency is the frequency, in Hertz, of the cycle clock backing
+	// the monotonic clock as of the last update, or 0 if it isn't known yet.
+	// It tracks changes in the cycle clock frequency, which the calibrated
+	// clocks measure against the host clock.
+	cycleFrequency atomicbitops.Uint64 `state:"nosave"`
 }
 
 // NewTimekeeper returns a Timekeeper that is automatically kept up-to-date.
@@ -186,6 +199,7 @@ func (t *Timekeeper) SetClocks(c sentrytime.Clocks, params *VDSOParamPage) {
 		elapsed := nowRealtime - t.saveRealtime
 		if elapsed > 0 {
 			wantMonotonic += elapsed
+			t.restoreGap = time.Duration(elapsed)
 		}
 	}
 
@@ -221,6 +235,7 @@ func (t *Timekeeper) update(parked bool) {
 			p.monotonicBaseCycles = int64(monotonicParams.BaseCycles)
 			p.monotonicBaseRef = int64(monotonicParams.BaseRef) + t.monotonicOffset
 			p.monotonicFrequency = monotonicParams.Frequency
+			t.cycleFrequency.Store(monotonicParams.Frequency)
 		}
 		if realtimeOk {
 			p.realtimeReady = 1
@@ -430,6 +445,25 @@ func (t *Timekeeper) BootTime() ktime.Time {
 	return t.bootTime
 }
 
+// RestoreGap returns the amount of real time that elapsed between save and
+// restore. It returns zero if the Timekeeper was not restored.
+//
+// Preconditions: SetClocks has been called.
+func (t *Timekeeper) RestoreGap() time.Duration {
+	return t.restoreGap
+}
+
+// cyclesToNanoseconds converts an interval of the cycle clock to nanoseconds
+// using the most recently calibrated frequency. It returns 0 if the frequency
+// isn't known yet.
+func (t *Timekeeper) cyclesToNanoseconds(cycles sentrytime.TSCValue) int64 {
+	ns, ok := sentrytime.CyclesToNanoseconds(cycles, t.cycleFrequency.Load())
+	if !ok {
+		return 0
+	}
+	return ns
+}
+
 // timekeeperClock is a ktime.SampledClock that reads time from a
 // kernel.Timekeeper-managed clock.
 //
@@ -123,6 +123,9 @@ func TestTimekeeperMonotonicForward(t *testing.T) {
 	if now != 300000 {
 		t.Errorf("GetTime got %d want 300000", now)
 	}
+	if got := tk.RestoreGap(); got != 200000 {
+		t.Errorf("RestoreGap got %d want 200000", got)
+	}
 }
 
 // TestTimekeeperMonotonicJumpBackwards tests that monotonic time does not jump
@@ -152,4 +155,7 @@ func TestTimekeeperMonotonicJumpBackwards(t *testing.T) {
 	if now != 100000 {
 		t.Errorf("GetTime got %d want 100000", now)
 	}
+	if got := tk.RestoreGap(); got != 0 {
+		t.Errorf("RestoreGap got %d want 0", got)
+	}
 }
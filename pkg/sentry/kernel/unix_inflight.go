@@ -0,0 +1,159 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernel
+
+import (
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/metric"
+	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
+	"gvisor.dev/gvisor/pkg/sentry/limits"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+)
+
+// unixInflightTriggerGC is the number of in-flight files above which senders
+// of SCM_RIGHTS messages should run the Unix socket garbage collector before
+// sending more.
+//
+// See net/unix/garbage.c:UNIX_INFLIGHT_TRIGGER_GC.
+const unixInflightTriggerGC = 16000
+
+var (
+	unixInflightFDs = metric.MustCreateNewUint64Metric("/unix/inflight_fds", metric.Uint64Metadata{
+		Description: "Number of file descriptors in flight in SCM_RIGHTS messages on Unix domain sockets.",
+	})
+	unixInflightRejected = metric.MustCreateNewUint64Metric("/unix/inflight_fds_rejected", metric.Uint64Metadata{
+		Cumulative:  true,
+		Description: "Number of SCM_RIGHTS messages rejected because too many file descriptors were in flight.",
+	})
+)
+
+// UnixInflight tracks files that are in flight in SCM_RIGHTS control
+// messages, i.e. that have been sent on a Unix domain socket but not yet
+// received or discarded. It is the sentry's equivalent of the accounting
+// done by net/unix/garbage.c, and is used both to bound the number of files
+// that can be in flight and to find cycles of Unix sockets that are only
+// reachable through their own receive queues.
+//
+// +stateify savable
+type UnixInflight struct {
+	mu unixInflightMutex `state:"nosave"`
+
+	// max is the maximum number of files that may be in flight in the
+	// sandbox at once. If max is 0, only the per-user RLIMIT_NOFILE limit
+	// applies. max is immutable.
+	max int64
+
+	// total is the number of files in flight. It is equal to the sum of the
+	// values in files. total is protected by mu.
+	total int64
+
+	// files maps each in-flight file to the number of times it is in
+	// flight. files is protected by mu.
+	files map[*vfs.FileDescription]int64
+}
+
+// init initializes u.
+func (u *UnixInflight) init(max int64) {
+	u.max = max
+	u.files = make(map[*vfs.FileDescription]int64)
+}
+
+// Add records files as in flight on behalf of the user whose counters are
+// uc. It returns ETOOMANYREFS if the user already has more files in flight
+// than its RLIMIT_NOFILE allows and is not privileged, or if accepting files
+// would exceed the sandbox-wide limit.
+//
+// See net/unix/scm.c:too_many_unix_fds.
+func (u *UnixInflight) Add(ctx context.Context, uc *UserCounters, files []*vfs.FileDescription) error {
+	if len(files) == 0 {
+		return nil
+	}
+	n := int64(len(files))
+	lim := limits.FromContext(ctx).Get(limits.NumberOfFiles)
+	creds := auth.CredentialsFromContext(ctx)
+	if uc.unixInflight.Load() > lim.Cur && !creds.HasRootCapability(linux.CAP_SYS_RESOURCE) && !creds.HasRootCapability(linux.CAP_SYS_ADMIN) {
+		unixInflightRejected.Increment()
+		return linuxerr.ETOOMANYREFS
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.max > 0 && u.total+n > u.max {
+		unixInflightRejected.Increment()
+		return linuxerr.ETOOMANYREFS
+	}
+	for _, f := range files {
+		u.files[f]++
+	}
+	u.total += n
+	uc.unixInflight.Add(uint64(n))
+	unixInflightFDs.Set(uint64(u.total))
+	return nil
+}
+
+// Remove records that files, previously passed to Add with the same uc, are
+// no longer in flight.
+func (u *UnixInflight) Remove(uc *UserCounters, files []*vfs.FileDescription) {
+	if len(files) == 0 {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, f := range files {
+		if u.files[f]--; u.files[f] <= 0 {
+			delete(u.files, f)
+		}
+	}
+	n := int64(len(files))
+	u.total -= n
+	uc.unixInflight.Add(^uint64(n - 1))
+	unixInflightFDs.Set(uint64(u.total))
+}
+
+// ShouldCollect returns true if enough files are in flight that senders
+// should run the Unix socket garbage collector.
+func (u *UnixInflight) ShouldCollect() bool {
+	trigger := int64(unixInflightTriggerGC)
+	if u.max > 0 && u.max/2 < trigger {
+		trigger = u.max / 2
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.total > trigger
+}
+
+// Empty returns true if no files are in flight.
+func (u *UnixInflight) Empty() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.total == 0
+}
+
+// Freeze calls fn with the set of in-flight files, mapped to the number of
+// times each is in flight. No files can be added to or removed from the set
+// while fn runs, so fn must not send, receive or release SCM_RIGHTS
+// messages, and must not retain files.
+func (u *UnixInflight) Freeze(fn func(files map[*vfs.FileDescription]int64)) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	fn(u.files)
+}
+
+// UnixInflight returns the kernel's in-flight SCM_RIGHTS file accounting.
+func (k *Kernel) UnixInflight() *UnixInflight {
+	return &k.unixInflight
+}
@@ -42,6 +42,10 @@ type vdsoParams struct {
 	realtimeFrequency  uint64
 }
 
+// vdsoRNGGenerationOffset is the offset of rngGeneration in the parameter
+// page.
+var vdsoRNGGenerationOffset = 8 + (*vdsoParams)(nil).SizeBytes()
+
 // VDSOParamPage manages a VDSO parameter page.
 //
 // Its memory layout looks like:
@@ -50,11 +54,15 @@ type vdsoParams struct {
 //		// seq is a sequence counter that protects the fields below.
 //		seq uint64
 //		vdsoParams
+//
+//		// rngGeneration is the generation of the kernel's EntropyPool.
+//		// It is not protected by seq.
+//		rngGeneration uint64
 //	}
 //
 // Everything in the struct is 8 bytes for easy alignment.
 //
-// It must be kept in sync with params in vdso/vdso_time.cc.
+// It must be kept in sync with params in vdso/params.h.
 //
 // +stateify savable
 type VDSOParamPage struct {
@@ -162,3 +170,16 @@ func (v *VDSOParamPage) Write(f func() vdsoParams) error {
 	// Write end.
 	return v.incrementSeq(paramPage)
 }
+
+// SetRNGGeneration updates the entropy pool generation exposed to the VDSO.
+// The VDSO implementation of getrandom(2) reseeds its per-thread state from
+// the kernel when the generation changes, and uses the system call while it
+// is zero.
+func (v *VDSOParamPage) SetRNGGeneration(gen uint64) error {
+	paramPage, err := v.access()
+	if err != nil {
+		return err
+	}
+	_, err = safemem.SwapUint64(paramPage.DropFirst(vdsoRNGGenerationOffset), gen)
+	return err
+}
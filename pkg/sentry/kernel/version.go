@@ -31,3 +31,20 @@ type Version struct {
 	//	- TIMESTAMP is the build timestamp as returned by `date`
 	Version string
 }
+
+// KernelVersion returns the version advertised to t: the version of its
+// syscall table, with the fields overridden when the kernel was initialized.
+func (t *Task) KernelVersion() Version {
+	v := t.SyscallTable().Version
+	o := &t.k.versionOverride
+	if o.Sysname != "" {
+		v.Sysname = o.Sysname
+	}
+	if o.Release != "" {
+		v.Release = o.Release
+	}
+	if o.Version != "" {
+		v.Version = o.Version
+	}
+	return v
+}
@@ -23,8 +23,18 @@ import (
 
 	"gvisor.dev/gvisor/pkg/abi/linux"
 	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/state"
 )
 
+func init() {
+	// Settings loaded from state that doesn't include Setting.Absolute are
+	// treated as relative.
+	state.RegisterSchemaChange("pkg/sentry/ktime.Setting", state.SchemaChange{
+		Version:     4,
+		AddedFields: []string{"Absolute"},
+	})
+}
+
 // Time represents an instant in time with nanosecond precision.
 //
 // Time may represent time with respect to any clock and may not have any
@@ -284,6 +294,10 @@ type Setting struct {
 	//
 	// Invariant: Period >= 0.
 	Period time.Duration
+
+	// Absolute is true if the timer was set to expire at an absolute time,
+	// e.g. with TIMER_ABSTIME, rather than after a duration.
+	Absolute bool
 }
 
 // SettingFromSpec converts a (value, interval) pair to a Setting based on a
@@ -318,9 +332,10 @@ func SettingFromAbsSpec(value Time, interval time.Duration) (Setting, error) {
 		return Setting{Period: interval}, nil
 	}
 	return Setting{
-		Enabled: true,
-		Next:    value,
-		Period:  interval,
+		Enabled:  true,
+		Next:     value,
+		Period:   interval,
+		Absolute: true,
 	}, nil
 }
 
@@ -376,6 +391,15 @@ func (s Setting) At(now Time) (Setting, uint64) {
 	return s, exp
 }
 
+// Delay returns a copy of s whose next expiration is d later. If s is
+// disabled, Delay returns s unchanged.
+func (s Setting) Delay(d time.Duration) Setting {
+	if s.Enabled {
+		s.Next = s.Next.Add(d)
+	}
+	return s
+}
+
 // ChannelNotifier is a Listener that sends on a channel.
 //
 // ChannelNotifier cannot be saved or loaded.
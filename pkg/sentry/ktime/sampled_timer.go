@@ -230,6 +230,29 @@ func (t *SampledTimer) SetClock(c SampledClock, s Setting) {
 	t.resetKickerLocked(now)
 }
 
+// AdjustPaused replaces the Setting of a paused SampledTimer with the result
+// of f, which is called with the current time of the SampledTimer's Clock and
+// the current Setting. Unlike Get and Set, AdjustPaused does not advance the
+// Setting to the current time, so expirations that occurred while the
+// SampledTimer was paused are not delivered unless the returned Setting still
+// contains them when the SampledTimer is resumed. If the SampledTimer is
+// destroyed, AdjustPaused has no effect.
+//
+// Preconditions:
+//   - The SampledTimer must not be unpaused.
+//   - The SampledTimer's Clock must be usable.
+func (t *SampledTimer) AdjustPaused(f func(now Time, s Setting) Setting) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch t.pauseState {
+	case timerDestroyed:
+		return
+	case timerUnpaused:
+		panic(fmt.Sprintf("SampledTimer(%p).AdjustPaused called in pause state %v", t, t.pauseState))
+	}
+	t.setting = f(t.clock.Now(), t.setting)
+}
+
 func (t *SampledTimer) runGoroutine() {
 	for {
 		select {
@@ -375,6 +375,11 @@ type loadedELF struct {
 	// end is the end of the ELF.
 	end hostarch.Addr
 
+	// code and data are the bounds of the ELF's code and data, as reported
+	// by /proc/[pid]/stat.
+	code hostarch.AddrRange
+	data hostarch.AddrRange
+
 	// interpter is the path to the ELF interpreter.
 	interpreter string
 
@@ -530,10 +535,23 @@ func loadParsedELF(ctx context.Context, m *mm.MemoryManager, fd *vfs.FileDescrip
 		}
 	}
 
-	// Map PT_LOAD segments.
+	// Map PT_LOAD segments, and find the bounds of code and data as in
+	// fs/binfmt_elf.c:load_elf_binary(). Note that, as in Linux, the data
+	// bounds start at the last segment.
+	code := hostarch.AddrRange{^hostarch.Addr(0), 0}
+	var data hostarch.AddrRange
 	for _, phdr := range info.phdrs {
 		switch phdr.Type {
 		case elf.PT_LOAD:
+			segStart := hostarch.Addr(phdr.Vaddr) + offset
+			segEnd := segStart + hostarch.Addr(phdr.Filesz)
+			code.Start = min(code.Start, segStart)
+			data.Start = max(data.Start, segStart)
+			if phdr.Flags&elf.PF_X != 0 {
+				code.End = max(code.End, segEnd)
+			}
+			data.End = max(data.End, segEnd)
+
 			if phdr.Memsz == 0 {
 				// No need to load segments with size 0, but
 				// they exist in some binaries.
@@ -562,6 +580,8 @@ func loadParsedELF(ctx context.Context, m *mm.MemoryManager, fd *vfs.FileDescrip
 		entry:       info.entry,
 		start:       start,
 		end:         end,
+		code:        code,
+		data:        data,
 		interpreter: interpreter,
 		phdrAddr:    phdrAddr,
 		phdrSize:    info.phdrSize,
@@ -344,6 +344,8 @@ func Load(ctx context.Context, args LoadArgs, extraAuxv []arch.AuxEntry, vdso *V
 		arch.AuxEntry{linux.AT_SYSINFO_EHDR, vdsoAddr},
 		arch.AuxEntry{linux.AT_HWCAP, hostarch.Addr(args.Features.AllowedHWCap1())},
 		arch.AuxEntry{linux.AT_HWCAP2, hostarch.Addr(args.Features.AllowedHWCap2())},
+		arch.AuxEntry{linux.AT_RSEQ_FEATURE_SIZE, linux.RSeqFeatureSize},
+		arch.AuxEntry{linux.AT_RSEQ_ALIGN, linux.AlignOfRSeq},
 	}...)
 
 	sl, err := stack.Load(newArgv, args.Envv, auxv)
@@ -357,6 +359,7 @@ func Load(ctx context.Context, args LoadArgs, extraAuxv []arch.AuxEntry, vdso *V
 	m.SetEnvvStart(sl.EnvvStart)
 	m.SetEnvvEnd(sl.EnvvEnd)
 	m.SetAuxv(auxv)
+	m.SetImageLayout(loaded.code, loaded.data, stack.Bottom)
 	m.SetExecutable(ctx, file)
 	m.SetVDSOSigReturn(uint64(vdsoAddr) + vdsoSigreturnOffset - vdsoPrelink)
 
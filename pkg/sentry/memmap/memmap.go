@@ -333,6 +333,12 @@ type MMapOpts struct {
 	// be replaced. If Unmap is true, Fixed must be true.
 	Unmap bool
 
+	// NoReplace specifies that creating the mapping should fail with EEXIST,
+	// rather than ENOMEM, if the range being mapped is already in use, as for
+	// MAP_FIXED_NOREPLACE. If NoReplace is true, Fixed must be true and Unmap
+	// must be false.
+	NoReplace bool
+
 	// If Map32Bit is true, all addresses in the created mapping must fit in a
 	// 32-bit integer. (Note that the "end address" of the mapping, i.e. the
 	// address of the first byte *after* the mapping, need not fit in a 32-bit
@@ -362,6 +368,11 @@ type MMapOpts struct {
 	// Linux.
 	Stack bool
 
+	// WipeOnFork is true if the mapping should be replaced by zero-filled
+	// memory in child processes, as for MAP_DROPPABLE. WipeOnFork may only
+	// be set for private anonymous mappings.
+	WipeOnFork bool
+
 	// PlatformEffect controls the synchronous effect of this call on the
 	// underlying platform.AddressSpace.
 	PlatformEffect MMapPlatformEffect
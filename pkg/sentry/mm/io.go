@@ -73,7 +73,7 @@ func (mm *MemoryManager) CheckIORange(addr hostarch.Addr, length int64) (hostarc
 	addr = hostarch.UntaggedUserAddr(addr)
 	// Note that access_ok() constrains end even if length == 0.
 	ar, ok := addr.ToRange(uint64(length))
-	return ar, (ok && ar.End <= mm.layout.MaxAddr)
+	return ar, (ok && ar.End <= mm.layout.MaxMappableAddr())
 }
 
 // checkIOVec applies bound checks consistent with Linux's
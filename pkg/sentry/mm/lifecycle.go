@@ -97,6 +97,9 @@ func (mm *MemoryManager) Fork(ctx context.Context) (*MemoryManager, error) {
 		captureInvalidations: true,
 		argv:                 mm.argv,
 		envv:                 mm.envv,
+		code:                 mm.code,
+		data:                 mm.data,
+		startStack:           mm.startStack,
 		auxv:                 append(arch.Auxv(nil), mm.auxv...),
 		// IncRef'd below, once we know that there isn't an error.
 		executable:        mm.executable,
@@ -105,7 +108,9 @@ func (mm *MemoryManager) Fork(ctx context.Context) (*MemoryManager, error) {
 		vdsoSigReturnAddr: mm.vdsoSigReturnAddr,
 	}
 
-	// Copy vmas.
+	// Copy vmas. dontforks is true if any vma's pmas must not be copied,
+	// either because the vma isn't copied (MADV_DONTFORK) or because its
+	// contents aren't (MADV_WIPEONFORK).
 	dontforks := false
 	dstvgap := mm2.vmas.FirstGap()
 	for srcvseg := mm.vmas.FirstSegment(); srcvseg.Ok(); srcvseg = srcvseg.NextSegment() {
@@ -121,6 +126,9 @@ func (mm *MemoryManager) Fork(ctx context.Context) (*MemoryManager, error) {
 			dontforks = true
 			continue
 		}
+		if vma.wipeOnFork {
+			dontforks = true
+		}
 
 		// Inform the Mappable, if any, of the new mapping.
 		if vma.mappable != nil {
@@ -178,7 +186,7 @@ func (mm *MemoryManager) Fork(ctx context.Context) (*MemoryManager, error) {
 			}
 
 			srcpseg = mm.pmas.Isolate(srcpseg, srcvseg.Range())
-			if srcvseg.ValuePtr().dontfork {
+			if vma := srcvseg.ValuePtr(); vma.dontfork || vma.wipeOnFork {
 				continue
 			}
 			pma = srcpseg.ValuePtr()
@@ -0,0 +1,254 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mm
+
+import (
+	"bytes"
+	"hash/maphash"
+
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/hostarch"
+	"gvisor.dev/gvisor/pkg/safemem"
+	"gvisor.dev/gvisor/pkg/sentry/memmap"
+	"gvisor.dev/gvisor/pkg/sentry/pgalloc"
+)
+
+// Same-page merging
+//
+// Same-page merging deduplicates identical private anonymous pages, in the
+// same way as Linux's KSM. A PageMerger scans the private pmas of one or more
+// MemoryManagers in passes. Pages whose contents are found in at least two
+// places are made "stable": the PageMerger holds a reference on each stable
+// page, and all pmas that map a stable page do so copy-on-write. Since the
+// PageMerger's reference prevents MemoryManager.isPMACopyOnWriteLocked() from
+// taking ownership of a stable page, writes to a stable page always break
+// copy-on-write, so the contents of stable pages never change. Other pages
+// with the same contents are then replaced by the stable page.
+//
+// As in KSM, pages whose contents are seen only once in a pass are recorded
+// in an "unstable" set keyed by content hash, which is discarded at the end
+// of each pass; this prevents pages that are frequently written from being
+// repeatedly merged and copied.
+//
+// Since writing to a merged page is slower than writing to an unmerged one,
+// an application can detect whether another MemoryManager scanned by the same
+// PageMerger has a page with given contents. A PageMerger must therefore only
+// be used for MemoryManagers that trust each other; the kernel uses a
+// PageMerger per container.
+
+// mergeBatchPages is the maximum number of pages that MergePages examines
+// before unlocking MemoryManager.activeMu.
+const mergeBatchPages = 512
+
+// PageMerger is the state of same-page merging across MemoryManagers.
+//
+// PageMerger is not safe for concurrent use.
+type PageMerger struct {
+	mf   *pgalloc.MemoryFile
+	seed maphash.Seed
+
+	// stable maps content hashes to stable pages with those contents. The
+	// PageMerger holds a reference on each stable page.
+	stable map[uint64][]memmap.FileRange
+
+	// stableOffsets contains the offset of every stable page.
+	stableOffsets map[uint64]struct{}
+
+	// unstable contains the content hashes of pages seen in the current pass
+	// that are not stable.
+	unstable map[uint64]struct{}
+
+	// buf and cmpBuf are scratch buffers for page contents.
+	buf    []byte
+	cmpBuf []byte
+}
+
+// NewPageMerger returns a PageMerger that merges pages in mf, which must be
+// the MemoryFile used by all MemoryManagers passed to MergePages.
+func NewPageMerger(mf *pgalloc.MemoryFile) *PageMerger {
+	return &PageMerger{
+		mf:            mf,
+		seed:          maphash.MakeSeed(),
+		stable:        make(map[uint64][]memmap.FileRange),
+		stableOffsets: make(map[uint64]struct{}),
+		unstable:      make(map[uint64]struct{}),
+		buf:           make([]byte, hostarch.PageSize),
+		cmpBuf:        make([]byte, hostarch.PageSize),
+	}
+}
+
+// StablePages returns the number of stable pages held by pm.
+func (pm *PageMerger) StablePages() int {
+	return len(pm.stableOffsets)
+}
+
+// EndPass ends a scanning pass. It releases stable pages that are no longer
+// mapped by any MemoryManager, and forgets pages that were seen only once.
+func (pm *PageMerger) EndPass() {
+	for h, frs := range pm.stable {
+		live := frs[:0]
+		for _, fr := range frs {
+			if pm.mf.HasUniqueRef(fr) {
+				// Only pm refers to this page.
+				delete(pm.stableOffsets, fr.Start)
+				pm.mf.DecRef(fr)
+				continue
+			}
+			live = append(live, fr)
+		}
+		if len(live) == 0 {
+			delete(pm.stable, h)
+		} else {
+			pm.stable[h] = live
+		}
+	}
+	clear(pm.unstable)
+}
+
+// Release releases all references held by pm. Pages that are currently merged
+// remain shared copy-on-write, but no further pages will be merged with them.
+//
+// Release must be called before saving pm.mf, since pm is not saved.
+func (pm *PageMerger) Release() {
+	for _, frs := range pm.stable {
+		for _, fr := range frs {
+			pm.mf.DecRef(fr)
+		}
+	}
+	clear(pm.stable)
+	clear(pm.stableOffsets)
+	clear(pm.unstable)
+}
+
+// readPage copies the page in ims to dst.
+func readPage(dst []byte, ims safemem.BlockSeq) bool {
+	n, err := safemem.CopySeq(safemem.BlockSeqOf(safemem.BlockFromSafeSlice(dst)), ims)
+	return err == nil && n == uint64(len(dst))
+}
+
+// findStable returns a stable page with contents equal to pm.buf, whose
+// content hash is h.
+func (pm *PageMerger) findStable(h uint64) (memmap.FileRange, bool) {
+	for _, fr := range pm.stable[h] {
+		ims, err := pm.mf.MapInternal(fr, hostarch.Read)
+		if err != nil || !readPage(pm.cmpBuf, ims) {
+			continue
+		}
+		if bytes.Equal(pm.buf, pm.cmpBuf) {
+			return fr, true
+		}
+	}
+	return memmap.FileRange{}, false
+}
+
+// MergePages performs one scan of mm's private anonymous memory as part of
+// pm's current pass, and returns the number of pages that were merged.
+func (mm *MemoryManager) MergePages(ctx context.Context, pm *PageMerger) uint64 {
+	memCgID := pgalloc.MemoryCgroupIDFromContext(ctx)
+	var merged uint64
+	var addr hostarch.Addr
+	for {
+		mm.activeMu.Lock()
+		for i := 0; i < mergeBatchPages; i++ {
+			pseg := mm.pmas.LowerBoundSegment(addr)
+			for pseg.Ok() && (!pseg.ValuePtr().private || pseg.ValuePtr().huge) {
+				pseg = pseg.NextSegment()
+			}
+			if !pseg.Ok() {
+				mm.activeMu.Unlock()
+				return merged
+			}
+			if addr < pseg.Start() {
+				addr = pseg.Start()
+			}
+			if mm.mergePageLocked(pm, pseg, addr, memCgID) {
+				merged++
+			}
+			addr += hostarch.PageSize
+		}
+		mm.activeMu.Unlock()
+	}
+}
+
+// mergePageLocked attempts to merge the page at addr with an identical stable
+// page. It returns true if the page was merged.
+//
+// Preconditions:
+//   - mm.activeMu must be locked for writing.
+//   - pseg.Range().Contains(addr).
+//   - pseg.ValuePtr().private == true.
+//   - addr must be page-aligned.
+func (mm *MemoryManager) mergePageLocked(pm *PageMerger, pseg pmaIterator, addr hostarch.Addr, memCgID uint32) bool {
+	pageAR := hostarch.AddrRange{addr, addr + hostarch.PageSize}
+	fr := pseg.fileRangeOf(pageAR)
+	if _, ok := pm.stableOffsets[fr.Start]; ok {
+		return false
+	}
+	// Pages that are shared with another MemoryManager (after fork) or that
+	// are pinned may be written through another reference, so they can't be
+	// merged.
+	if !mm.mf.HasUniqueRef(fr) {
+		return false
+	}
+	if err := pseg.getInternalMappingsLocked(); err != nil {
+		return false
+	}
+	if !readPage(pm.buf, mm.internalMappingsLocked(pseg, pageAR)) {
+		return false
+	}
+	h := maphash.Bytes(pm.seed, pm.buf)
+	if _, ok := pm.stable[h]; !ok {
+		if _, ok := pm.unstable[h]; !ok {
+			pm.unstable[h] = struct{}{}
+			return false
+		}
+	}
+
+	// The page is a merge candidate. Prevent the application from writing to
+	// it, then check that its contents didn't change while we were reading
+	// them.
+	mm.unmapASLocked(pageAR)
+	if !readPage(pm.buf, mm.internalMappingsLocked(pseg, pageAR)) {
+		return false
+	}
+	if maphash.Bytes(pm.seed, pm.buf) != h {
+		return false
+	}
+
+	pseg = mm.pmas.Isolate(pseg, pageAR)
+	pma := pseg.ValuePtr()
+	merged := false
+	if sfr, ok := pm.findStable(h); ok {
+		mm.mf.IncRef(sfr, memCgID)
+		mm.mf.DecRef(fr)
+		pma.off = sfr.Start
+		merged = true
+	} else {
+		// Make this page stable, so that the page whose contents were
+		// recorded in pm.unstable is merged with it when it is next seen.
+		mm.mf.IncRef(fr, memCgID)
+		pm.stable[h] = append(pm.stable[h], fr)
+		pm.stableOffsets[fr.Start] = struct{}{}
+		delete(pm.unstable, h)
+	}
+	pma.needCOW = true
+	pma.effectivePerms.Write = false
+	pma.maxPerms.Write = false
+	pma.internalMappings = safemem.BlockSeq{}
+	// Coalesce the page with its neighbors if they are now contiguous, so
+	// that merging doesn't leave a pma per page.
+	mm.pmas.MergeOutsideRange(pageAR)
+	return merged
+}
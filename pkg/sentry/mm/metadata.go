@@ -16,8 +16,10 @@ package mm
 
 import (
 	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/errors/linuxerr"
 	"gvisor.dev/gvisor/pkg/hostarch"
 	"gvisor.dev/gvisor/pkg/sentry/arch"
+	"gvisor.dev/gvisor/pkg/sentry/limits"
 	"gvisor.dev/gvisor/pkg/sentry/vfs"
 )
 
@@ -111,6 +113,112 @@ func (mm *MemoryManager) SetEnvvEnd(a hostarch.Addr) {
 	mm.envv.End = a
 }
 
+// SetImageLayout sets the bounds of the executable's code and data segments
+// and the initial stack pointer.
+func (mm *MemoryManager) SetImageLayout(code, data hostarch.AddrRange, startStack hostarch.Addr) {
+	mm.metadataMu.Lock()
+	defer mm.metadataMu.Unlock()
+	mm.code = code
+	mm.data = data
+	mm.startStack = startStack
+}
+
+// Layout describes the bounds of the regions of an address space that are
+// reported by /proc/[pid]/stat and may be modified by prctl(PR_SET_MM). It is
+// equivalent to the corresponding fields of Linux's struct mm_struct.
+type Layout struct {
+	StartCode  hostarch.Addr
+	EndCode    hostarch.Addr
+	StartData  hostarch.Addr
+	EndData    hostarch.Addr
+	StartBrk   hostarch.Addr
+	Brk        hostarch.Addr
+	StartStack hostarch.Addr
+	ArgStart   hostarch.Addr
+	ArgEnd     hostarch.Addr
+	EnvStart   hostarch.Addr
+	EnvEnd     hostarch.Addr
+}
+
+// validate returns EINVAL if l is not a valid Layout for an address space
+// with the given MmapLayout.
+//
+// See kernel/sys.c:validate_prctl_map_addr().
+func (l *Layout) validate(ctx context.Context, ml *arch.MmapLayout) error {
+	for _, addr := range []hostarch.Addr{
+		l.StartCode, l.EndCode,
+		l.StartData, l.EndData,
+		l.StartBrk, l.Brk,
+		l.StartStack,
+		l.ArgStart, l.ArgEnd,
+		l.EnvStart, l.EnvEnd,
+	} {
+		if addr < ml.MinAddr || addr >= ml.MaxMappableAddr() {
+			return linuxerr.EINVAL
+		}
+	}
+	if l.StartCode >= l.EndCode || l.StartData > l.EndData || l.StartBrk > l.Brk || l.ArgStart > l.ArgEnd || l.EnvStart > l.EnvEnd {
+		return linuxerr.EINVAL
+	}
+	// The heap and data segments may not exceed RLIMIT_DATA; compare
+	// include/linux/mm.h:check_data_rlimit().
+	if lim := limits.FromContext(ctx).Get(limits.Data).Cur; lim != limits.Infinity && uint64(l.Brk-l.StartBrk)+uint64(l.EndData-l.StartData) > lim {
+		return linuxerr.EINVAL
+	}
+	return nil
+}
+
+// Layout returns the current layout of mm.
+func (mm *MemoryManager) Layout() Layout {
+	mm.metadataMu.Lock()
+	defer mm.metadataMu.Unlock()
+	mm.mappingMu.RLock()
+	defer mm.mappingMu.RUnlock()
+	return mm.layoutLocked()
+}
+
+// Preconditions: mm.metadataMu and mm.mappingMu must be locked.
+func (mm *MemoryManager) layoutLocked() Layout {
+	return Layout{
+		StartCode:  mm.code.Start,
+		EndCode:    mm.code.End,
+		StartData:  mm.data.Start,
+		EndData:    mm.data.End,
+		StartBrk:   mm.brk.Start,
+		Brk:        mm.brk.End,
+		StartStack: mm.startStack,
+		ArgStart:   mm.argv.Start,
+		ArgEnd:     mm.argv.End,
+		EnvStart:   mm.envv.Start,
+		EnvEnd:     mm.envv.End,
+	}
+}
+
+// UpdateLayout atomically applies update to the layout of mm, as for Linux's
+// prctl(PR_SET_MM). It returns EINVAL, leaving the layout unchanged, if the
+// updated layout is invalid.
+//
+// Changing the heap bounds only changes the range from which subsequent calls
+// to brk(2) grow or shrink the heap; no memory is mapped or unmapped.
+func (mm *MemoryManager) UpdateLayout(ctx context.Context, update func(l *Layout)) error {
+	mm.metadataMu.Lock()
+	defer mm.metadataMu.Unlock()
+	mm.mappingMu.Lock()
+	defer mm.mappingMu.Unlock()
+	l := mm.layoutLocked()
+	update(&l)
+	if err := l.validate(ctx, &mm.layout); err != nil {
+		return err
+	}
+	mm.code = hostarch.AddrRange{l.StartCode, l.EndCode}
+	mm.data = hostarch.AddrRange{l.StartData, l.EndData}
+	mm.brk = hostarch.AddrRange{l.StartBrk, l.Brk}
+	mm.startStack = l.StartStack
+	mm.argv = hostarch.AddrRange{l.ArgStart, l.ArgEnd}
+	mm.envv = hostarch.AddrRange{l.EnvStart, l.EnvEnd}
+	return nil
+}
+
 // Auxv returns the current map of auxiliary vectors.
 func (mm *MemoryManager) Auxv() arch.Auxv {
 	mm.metadataMu.Lock()
@@ -194,6 +194,16 @@ type MemoryManager struct {
 	// envv is protected by metadataMu.
 	envv hostarch.AddrRange
 
+	// code and data are the bounds of the executable's code and data
+	// segments, and startStack is the initial stack pointer. These are set
+	// up by the loader and may be modified by prctl(PR_SET_MM). They are
+	// informational only and do not affect mappings.
+	//
+	// code, data and startStack are protected by metadataMu.
+	code       hostarch.AddrRange
+	data       hostarch.AddrRange
+	startStack hostarch.Addr
+
 	// auxv is the ELF's auxiliary vector.
 	//
 	// auxv is protected by metadataMu.
@@ -276,6 +286,13 @@ type vma struct {
 	// dontfork is the MADV_DONTFORK setting for this vma configured by madvise().
 	dontfork bool
 
+	// wipeOnFork is the MADV_WIPEONFORK setting for this vma configured by
+	// madvise(). If wipeOnFork is true, the vma is copied to child
+	// MemoryManagers by Fork, but its contents are not, so the child
+	// observes zero-filled memory. wipeOnFork is only set for private
+	// anonymous vmas.
+	wipeOnFork bool
+
 	mlockMode memmap.MLockMode
 
 	// numaPolicy is the NUMA policy for this vma set by mbind().
@@ -313,6 +330,7 @@ func (v *vma) copy() vma {
 		growsDown:      v.growsDown,
 		isStack:        v.isStack,
 		dontfork:       v.dontfork,
+		wipeOnFork:     v.wipeOnFork,
 		mlockMode:      v.mlockMode,
 		numaPolicy:     v.numaPolicy,
 		numaNodemask:   v.numaNodemask,
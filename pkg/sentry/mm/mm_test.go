@@ -146,6 +146,123 @@ func TestBrkDataLimitUpdates(t *testing.T) {
 	}
 }
 
+// testLayout returns a valid Layout in which every region starts at base.
+func testLayout(base hostarch.Addr) Layout {
+	return Layout{
+		StartCode:  base,
+		EndCode:    base + hostarch.PageSize,
+		StartData:  base,
+		EndData:    base,
+		StartBrk:   base,
+		Brk:        base,
+		StartStack: base,
+		ArgStart:   base,
+		ArgEnd:     base,
+		EnvStart:   base,
+		EnvEnd:     base,
+	}
+}
+
+func TestUpdateLayout(t *testing.T) {
+	ctx := contexttest.Context(t)
+	mm := testMemoryManager(ctx, t)
+	defer mm.DecUsers(ctx)
+
+	want := testLayout(mm.layout.MinAddr + 16*hostarch.PageSize)
+	if err := mm.UpdateLayout(ctx, func(l *Layout) { *l = want }); err != nil {
+		t.Fatalf("UpdateLayout(%+v) failed: %v", want, err)
+	}
+	if got := mm.Layout(); got != want {
+		t.Errorf("Layout() got %+v want %+v", got, want)
+	}
+
+	// The end of the code can't precede its start, and invalid updates must
+	// not change the layout.
+	if err := mm.UpdateLayout(ctx, func(l *Layout) { l.EndCode = l.StartCode - 1 }); !linuxerr.Equals(linuxerr.EINVAL, err) {
+		t.Errorf("UpdateLayout with EndCode < StartCode got err %v want EINVAL", err)
+	}
+	if got := mm.Layout(); got != want {
+		t.Errorf("Layout() after invalid update got %+v want %+v", got, want)
+	}
+}
+
+func TestBrkAvoidsExistingMappings(t *testing.T) {
+	ctx := contexttest.Context(t)
+	mm := testMemoryManager(ctx, t)
+	defer mm.DecUsers(ctx)
+
+	addr, err := mm.MMap(ctx, memmap.MMapOpts{
+		Length:  hostarch.PageSize,
+		Addr:    mm.layout.MinAddr + 16*hostarch.PageSize,
+		Fixed:   true,
+		Private: true,
+	})
+	if err != nil {
+		t.Fatalf("MMap got err %v want nil", err)
+	}
+
+	// Move the brk to just below the mapping, and try to grow the heap over
+	// it.
+	oldBrk := addr - hostarch.PageSize
+	if err := mm.UpdateLayout(ctx, func(l *Layout) { *l = testLayout(oldBrk) }); err != nil {
+		t.Fatalf("UpdateLayout failed: %v", err)
+	}
+	if newBrk, err := mm.Brk(ctx, addr+hostarch.PageSize); !linuxerr.Equals(linuxerr.ENOMEM, err) || newBrk != oldBrk {
+		t.Errorf("Brk over existing mapping got (%#x, %v) want (%#x, ENOMEM)", newBrk, err, oldBrk)
+	}
+}
+
+func TestMMapHighAddress(t *testing.T) {
+	ctx := contexttest.Context(t)
+	mm := testMemoryManagerWithMmapDirection(ctx, t, arch.MmapTopDown)
+	defer mm.DecUsers(ctx)
+
+	// Make the upper half of the address space available only to mappings
+	// that request it explicitly.
+	mm.layout.MaxHighAddr = mm.layout.MaxAddr
+	mm.layout.MaxAddr = (mm.layout.MaxAddr / 2).RoundDown()
+	mm.layout.TopDownBase = mm.layout.MaxAddr
+
+	// Mappings without a hint stay below MaxAddr.
+	addr, err := mm.MMap(ctx, memmap.MMapOpts{
+		Length:  hostarch.PageSize,
+		Private: true,
+	})
+	if err != nil {
+		t.Fatalf("MMap got err %v want nil", err)
+	}
+	if addr >= mm.layout.MaxAddr {
+		t.Errorf("MMap without hint got addr %#x want below %#x", addr, mm.layout.MaxAddr)
+	}
+
+	// Mappings with a hint above MaxAddr may be placed there.
+	hint := mm.layout.MaxAddr + 16*hostarch.PageSize
+	addr, err = mm.MMap(ctx, memmap.MMapOpts{
+		Length:  hostarch.PageSize,
+		Addr:    hint,
+		Private: true,
+	})
+	if err != nil {
+		t.Fatalf("MMap got err %v want nil", err)
+	}
+	if addr != hint {
+		t.Errorf("MMap with high hint got addr %#x want %#x", addr, hint)
+	}
+
+	// If the hint is unavailable, another address above MaxAddr is used.
+	addr, err = mm.MMap(ctx, memmap.MMapOpts{
+		Length:  hostarch.PageSize,
+		Addr:    hint,
+		Private: true,
+	})
+	if err != nil {
+		t.Fatalf("MMap got err %v want nil", err)
+	}
+	if addr < mm.layout.MaxAddr || addr == hint {
+		t.Errorf("MMap with unavailable high hint got addr %#x want another address above %#x", addr, mm.layout.MaxAddr)
+	}
+}
+
 // TestIOAfterUnmap ensures that IO fails after unmap.
 func TestIOAfterUnmap(t *testing.T) {
 	ctx := contexttest.Context(t)
@@ -186,6 +303,92 @@ func TestIOAfterUnmap(t *testing.T) {
 	}
 }
 
+func TestMMapNoReplace(t *testing.T) {
+	ctx := contexttest.Context(t)
+	mm := testMemoryManager(ctx, t)
+	defer mm.DecUsers(ctx)
+
+	addr, err := mm.MMap(ctx, memmap.MMapOpts{
+		Length:  hostarch.PageSize,
+		Private: true,
+	})
+	if err != nil {
+		t.Fatalf("MMap got err %v want nil", err)
+	}
+	opts := memmap.MMapOpts{
+		Length:    2 * hostarch.PageSize,
+		Addr:      addr,
+		Fixed:     true,
+		NoReplace: true,
+		Private:   true,
+	}
+	if _, err := mm.MMap(ctx, opts); !linuxerr.Equals(linuxerr.EEXIST, err) {
+		t.Errorf("MMap over existing mapping with NoReplace got err %v want EEXIST", err)
+	}
+	opts.NoReplace = false
+	if _, err := mm.MMap(ctx, opts); !linuxerr.Equals(linuxerr.ENOMEM, err) {
+		t.Errorf("MMap over existing mapping without Unmap got err %v want ENOMEM", err)
+	}
+}
+
+func TestMRemapDontUnmap(t *testing.T) {
+	ctx := contexttest.Context(t)
+	mm := testMemoryManager(ctx, t)
+	defer mm.DecUsers(ctx)
+
+	addr, err := mm.MMap(ctx, memmap.MMapOpts{
+		Length:   hostarch.PageSize,
+		Private:  true,
+		Perms:    hostarch.ReadWrite,
+		MaxPerms: hostarch.AnyAccess,
+	})
+	if err != nil {
+		t.Fatalf("MMap got err %v want nil", err)
+	}
+	if _, err := mm.CopyOut(ctx, addr, []byte{1}, usermem.IOOpts{}); err != nil {
+		t.Fatalf("CopyOut got err %v want nil", err)
+	}
+
+	if _, err := mm.MRemap(ctx, addr, hostarch.PageSize, 2*hostarch.PageSize, MRemapOpts{
+		Move:      MRemapMayMove,
+		DontUnmap: true,
+	}); !linuxerr.Equals(linuxerr.EINVAL, err) {
+		t.Errorf("MRemap with DontUnmap and different sizes got err %v want EINVAL", err)
+	}
+
+	newAddr, err := mm.MRemap(ctx, addr, hostarch.PageSize, hostarch.PageSize, MRemapOpts{
+		Move:      MRemapMayMove,
+		DontUnmap: true,
+	})
+	if err != nil {
+		t.Fatalf("MRemap got err %v want nil", err)
+	}
+	if newAddr == addr {
+		t.Fatalf("MRemap with DontUnmap returned the old address %#x", addr)
+	}
+	if mm.usageAS != mm.realUsageAS() || mm.usageAS != 2*hostarch.PageSize {
+		t.Errorf("usageAS got %d, actually mapped %d, want %d", mm.usageAS, mm.realUsageAS(), 2*hostarch.PageSize)
+	}
+
+	// The contents of the old mapping are moved, and the old mapping is
+	// zero-filled.
+	for _, test := range []struct {
+		addr hostarch.Addr
+		want byte
+	}{
+		{newAddr, 1},
+		{addr, 0},
+	} {
+		b := make([]byte, 1)
+		if _, err := mm.CopyIn(ctx, test.addr, b, usermem.IOOpts{}); err != nil {
+			t.Fatalf("CopyIn(%#x) got err %v want nil", test.addr, err)
+		}
+		if b[0] != test.want {
+			t.Errorf("CopyIn(%#x) got %d want %d", test.addr, b[0], test.want)
+		}
+	}
+}
+
 // TestIOAfterMProtect tests IO interaction with mprotect permissions.
 func TestIOAfterMProtect(t *testing.T) {
 	ctx := contexttest.Context(t)
@@ -343,3 +546,118 @@ func TestGetAllocationDirection(t *testing.T) {
 		})
 	}
 }
+
+func TestMergePages(t *testing.T) {
+	ctx := contexttest.Context(t)
+	mm := testMemoryManager(ctx, t)
+	defer mm.DecUsers(ctx)
+
+	addr, err := mm.MMap(ctx, memmap.MMapOpts{
+		Length:   3 * hostarch.PageSize,
+		Private:  true,
+		Perms:    hostarch.ReadWrite,
+		MaxPerms: hostarch.AnyAccess,
+	})
+	if err != nil {
+		t.Fatalf("MMap got err %v want nil", err)
+	}
+	// Pages 0 and 1 are identical; page 2 differs.
+	for i, c := range []byte{'a', 'a', 'b'} {
+		page := make([]byte, hostarch.PageSize)
+		for j := range page {
+			page[j] = c
+		}
+		if _, err := mm.CopyOut(ctx, addr+hostarch.Addr(i)*hostarch.PageSize, page, usermem.IOOpts{}); err != nil {
+			t.Fatalf("CopyOut got err %v want nil", err)
+		}
+	}
+	fileOffset := func(i int) uint64 {
+		mm.activeMu.RLock()
+		defer mm.activeMu.RUnlock()
+		pageAR := hostarch.AddrRange{addr + hostarch.Addr(i)*hostarch.PageSize, addr + hostarch.Addr(i+1)*hostarch.PageSize}
+		return mm.pmas.FindSegment(pageAR.Start).fileRangeOf(pageAR).Start
+	}
+
+	pm := NewPageMerger(mm.mf)
+	defer pm.Release()
+	// The first pass only finds pages 0 and 1 to be identical; the second
+	// merges them.
+	if n := mm.MergePages(ctx, pm); n != 0 {
+		t.Errorf("first MergePages got %d want 0", n)
+	}
+	pm.EndPass()
+	if n := mm.MergePages(ctx, pm); n != 1 {
+		t.Errorf("second MergePages got %d want 1", n)
+	}
+	pm.EndPass()
+	if fileOffset(0) != fileOffset(1) {
+		t.Errorf("pages 0 and 1 were not merged")
+	}
+	if fileOffset(1) == fileOffset(2) {
+		t.Errorf("pages 1 and 2 were merged")
+	}
+
+	// Writing to a merged page must not affect the other.
+	if _, err := mm.CopyOut(ctx, addr, []byte{'c'}, usermem.IOOpts{}); err != nil {
+		t.Fatalf("CopyOut got err %v want nil", err)
+	}
+	if fileOffset(0) == fileOffset(1) {
+		t.Errorf("write to page 0 did not break copy-on-write")
+	}
+	b := make([]byte, 1)
+	if _, err := mm.CopyIn(ctx, addr+hostarch.PageSize, b, usermem.IOOpts{}); err != nil {
+		t.Fatalf("CopyIn got err %v want nil", err)
+	}
+	if b[0] != 'a' {
+		t.Errorf("page 1 got %q want 'a'", b[0])
+	}
+}
+
+func TestMergePagesCoalescesPMAs(t *testing.T) {
+	ctx := contexttest.Context(t)
+	mm := testMemoryManager(ctx, t)
+	defer mm.DecUsers(ctx)
+
+	// Two mappings with the same two pages. The pages of the second become
+	// stable, and the pages of the first are merged with them.
+	var addrs [2]hostarch.Addr
+	for i := range addrs {
+		addr, err := mm.MMap(ctx, memmap.MMapOpts{
+			Length:   2 * hostarch.PageSize,
+			Private:  true,
+			Perms:    hostarch.ReadWrite,
+			MaxPerms: hostarch.AnyAccess,
+		})
+		if err != nil {
+			t.Fatalf("MMap got err %v want nil", err)
+		}
+		// Write both pages at once, so that they are allocated contiguously.
+		pages := make([]byte, 2*hostarch.PageSize)
+		for j := range pages {
+			pages[j] = 'a' + byte(j/hostarch.PageSize)
+		}
+		if _, err := mm.CopyOut(ctx, addr, pages, usermem.IOOpts{}); err != nil {
+			t.Fatalf("CopyOut got err %v want nil", err)
+		}
+		addrs[i] = addr
+	}
+
+	pm := NewPageMerger(mm.mf)
+	defer pm.Release()
+	mm.MergePages(ctx, pm)
+	pm.EndPass()
+	mm.MergePages(ctx, pm)
+	pm.EndPass()
+
+	mm.activeMu.RLock()
+	defer mm.activeMu.RUnlock()
+	for _, addr := range addrs {
+		pseg := mm.pmas.FindSegment(addr)
+		if !pseg.Ok() {
+			t.Fatalf("no pma at %#x", addr)
+		}
+		if end := addr + 2*hostarch.PageSize; pseg.End() < end {
+			t.Errorf("pma at %#x ends at %#x, want at least %#x", addr, pseg.End(), end)
+		}
+	}
+}
@@ -710,7 +710,7 @@ func (mm *MemoryManager) invalidateLocked(ar hostarch.AddrRange, invalidatePriva
 						unmapAR.End = last.Start()
 					}
 				} else {
-					unmapAR.End = mm.layout.MaxAddr
+					unmapAR.End = mm.layout.MaxMappableAddr()
 				}
 				mm.unmapASLocked(unmapAR)
 				didUnmapAS = true
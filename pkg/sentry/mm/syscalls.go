@@ -110,6 +110,9 @@ func (mm *MemoryManager) MMap(ctx context.Context, opts memmap.MMapOpts) (hostar
 	if opts.Unmap && !opts.Fixed {
 		return 0, linuxerr.EINVAL
 	}
+	if opts.NoReplace && (!opts.Fixed || opts.Unmap) {
+		return 0, linuxerr.EINVAL
+	}
 	if opts.GrowsDown && opts.Mappable != nil {
 		return 0, linuxerr.EINVAL
 	}
@@ -340,6 +343,12 @@ type MRemapOpts struct {
 	// NewAddr is the new address for the remapping. NewAddr is ignored unless
 	// Move is MMRemapMustMove.
 	NewAddr hostarch.Addr
+
+	// If DontUnmap is true, the remapped mapping is always moved, and the
+	// mapping at the old address is left in place after its contents are
+	// moved, as for MREMAP_DONTUNMAP. DontUnmap requires that Move is not
+	// MRemapNoMove and that the old and new sizes are equal.
+	DontUnmap bool
 }
 
 // MRemapMoveMode controls MRemap's moving behavior.
@@ -377,6 +386,10 @@ func (mm *MemoryManager) MRemap(ctx context.Context, oldAddr hostarch.Addr, oldS
 	}
 	newSize = uint64(newSizeAddr)
 
+	if opts.DontUnmap && (opts.Move == MRemapNoMove || oldSize != newSize) {
+		return 0, linuxerr.EINVAL
+	}
+
 	oldEnd, ok := oldAddr.AddLength(oldSize)
 	if !ok {
 		return 0, linuxerr.EINVAL
@@ -425,7 +438,7 @@ func (mm *MemoryManager) MRemap(ctx context.Context, oldAddr hostarch.Addr, oldS
 		}
 	}
 
-	if opts.Move != MRemapMustMove {
+	if opts.Move != MRemapMustMove && !opts.DontUnmap {
 		// Handle no-ops and in-place shrinking. These cases don't care if
 		// [oldAddr, oldEnd) maps to a single vma, or is even mapped at all
 		// (aside from oldAddr).
@@ -542,7 +555,10 @@ func (mm *MemoryManager) MRemap(ctx context.Context, oldAddr hostarch.Addr, oldS
 	}
 
 	// Check against RLIMIT_AS.
-	newUsageAS := mm.usageAS - uint64(oldAR.Length()) + uint64(newAR.Length())
+	newUsageAS := mm.usageAS + uint64(newAR.Length())
+	if !opts.DontUnmap {
+		newUsageAS -= uint64(oldAR.Length())
+	}
 	if limitAS := limits.FromContext(ctx).Get(limits.AS).Cur; newUsageAS > limitAS {
 		return 0, linuxerr.ENOMEM
 	}
@@ -585,6 +601,43 @@ func (mm *MemoryManager) MRemap(ctx context.Context, oldAddr hostarch.Addr, oldS
 		return newAR.Start, nil
 	}
 
+	if opts.DontUnmap {
+		// Handle moving without unmapping. The vma at oldAR is retained, but
+		// its pmas are moved to newAR, so subsequent accesses to oldAR fault
+		// in new pages (zero-filled for anonymous mappings). As in Linux's
+		// mm/mremap.c:move_vma(), the retained vma is no longer locked.
+		vseg = mm.vmas.Isolate(vseg, oldAR)
+		oldVMA := vseg.ValuePtr()
+		vma := oldVMA.copy()
+		if vma.mappable != nil {
+			vma.off = vseg.mappableOffsetAt(oldAR.Start)
+		}
+		if vma.id != nil {
+			vma.id.IncRef()
+		}
+		if oldVMA.mlockMode != memmap.MLockNone {
+			oldVMA.mlockMode = memmap.MLockNone
+			mm.lockedAS -= uint64(oldAR.Length())
+		}
+		vseg = mm.vmas.Insert(mm.vmas.FindGap(newAR.Start), newAR, vma)
+		mm.usageAS += uint64(newAR.Length())
+		if vma.isPrivateDataLocked() {
+			mm.dataAS += uint64(newAR.Length())
+		}
+		if vma.mlockMode != memmap.MLockNone {
+			mm.lockedAS += uint64(newAR.Length())
+		}
+
+		mm.activeMu.Lock()
+		mm.movePMAsLocked(oldAR, newAR)
+		mm.activeMu.Unlock()
+
+		if vma.mlockMode == memmap.MLockEager {
+			mm.populateVMA(ctx, vseg, newAR, memmap.PlatformEffectCommit)
+		}
+		return newAR.Start, nil
+	}
+
 	// Handle moving.
 	//
 	// Remove the existing vma before inserting the new one to minimize
@@ -794,6 +847,13 @@ func (mm *MemoryManager) Brk(ctx context.Context, addr hostarch.Addr) (hostarch.
 
 	switch {
 	case oldbrkpg < newbrkpg:
+		// The heap can't grow over existing mappings, which may exist if the
+		// brk was moved by prctl(PR_SET_MM). Compare Linux's mm/mmap.c:brk().
+		if vseg := mm.vmas.LowerBoundSegment(oldbrkpg); vseg.Ok() && vseg.Start() < newbrkpg {
+			addr = mm.brk.End
+			mm.mappingMu.Unlock()
+			return addr, linuxerr.ENOMEM
+		}
 		vseg, ar, droppedIDs, err = mm.createVMALocked(ctx, memmap.MMapOpts{
 			Length: uint64(newbrkpg - oldbrkpg),
 			Addr:   oldbrkpg,
@@ -1307,6 +1367,24 @@ func (mm *MemoryManager) SetDontFork(addr hostarch.Addr, length uint64, dontfork
 	})
 }
 
+// SetWipeOnFork implements the semantics of madvise MADV_WIPEONFORK and
+// MADV_KEEPONFORK.
+//
+// Preconditions: addr and length are page-aligned.
+func (mm *MemoryManager) SetWipeOnFork(addr hostarch.Addr, length uint64, wipeOnFork bool) error {
+	addr = hostarch.UntaggedUserAddr(addr)
+	return mm.madviseMutateVMAs(addr, length, func(vseg vmaIterator) error {
+		vma := vseg.ValuePtr()
+		// Compare Linux's mm/madvise.c:madvise_vma_behavior(), which only
+		// permits MADV_WIPEONFORK for private anonymous mappings.
+		if wipeOnFork && vma.mappable != nil {
+			return linuxerr.EINVAL
+		}
+		vma.wipeOnFork = wipeOnFork
+		return nil
+	})
+}
+
 // SetVMAAnonName implements the semantics of Linux's
 // prctl(PR_SET_VMA, PR_SET_VMA_ANON_NAME).
 func (mm *MemoryManager) SetVMAAnonName(addr hostarch.Addr, length uint64, name string, nameIsNil bool) error {
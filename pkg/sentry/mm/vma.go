@@ -50,6 +50,7 @@ func (mm *MemoryManager) createVMALocked(ctx context.Context, opts memmap.MMapOp
 		Stack:     opts.Stack,
 		Private:   opts.Private,
 		Unmap:     opts.Unmap,
+		NoReplace: opts.NoReplace,
 		Map32Bit:  opts.Map32Bit,
 	})
 	if err != nil {
@@ -123,6 +124,7 @@ func (mm *MemoryManager) createVMALocked(ctx context.Context, opts memmap.MMapOp
 		private:        opts.Private,
 		growsDown:      opts.GrowsDown,
 		isStack:        opts.Stack,
+		wipeOnFork:     opts.WipeOnFork,
 		mlockMode:      opts.MLockMode,
 		numaPolicy:     linux.MPOL_DEFAULT,
 		id:             opts.MappingIdentity,
@@ -155,6 +157,7 @@ type findAvailableOpts struct {
 	Stack     bool
 	Private   bool
 	Unmap     bool
+	NoReplace bool
 	Map32Bit  bool
 }
 
@@ -192,6 +195,13 @@ func (mm *MemoryManager) findAvailableLocked(length uint64, opts findAvailableOp
 
 	// Fixed mappings accept only the requested address.
 	if opts.Fixed {
+		if opts.NoReplace {
+			// Compare Linux's mm/mmap.c:do_mmap(): MAP_FIXED_NOREPLACE fails
+			// with EEXIST if the requested range is valid but in use.
+			if ar, ok := opts.Addr.ToRange(length); ok && allowedAR.IsSupersetOf(ar) {
+				return 0, linuxerr.EEXIST
+			}
+		}
 		return 0, linuxerr.ENOMEM
 	}
 
@@ -205,14 +215,24 @@ func (mm *MemoryManager) findAvailableLocked(length uint64, opts findAvailableOp
 	if opts.Map32Bit {
 		return mm.findLowestAvailableLocked(length, alignment, allowedAR)
 	}
+
+	// Addresses above MaxAddr are only used if the hint address is above
+	// MaxAddr, in which case the search is extended to the top of the
+	// address space. Compare Linux's
+	// arch/x86/kernel/sys_x86_64.c:arch_get_unmapped_area{,_topdown}().
+	maxAddr, topDownBase := mm.layout.MaxAddr, mm.layout.TopDownBase
+	if opts.Addr > mm.layout.MaxAddr && mm.layout.MaxHighAddr > mm.layout.MaxAddr {
+		maxAddr = mm.layout.MaxHighAddr
+		topDownBase += mm.layout.MaxHighAddr - mm.layout.MaxAddr
+	}
 	if mm.layout.DefaultDirection == arch.MmapBottomUp {
-		return mm.findLowestAvailableLocked(length, alignment, hostarch.AddrRange{mm.layout.BottomUpBase, mm.layout.MaxAddr})
+		return mm.findLowestAvailableLocked(length, alignment, hostarch.AddrRange{mm.layout.BottomUpBase, maxAddr})
 	}
-	return mm.findHighestAvailableLocked(length, alignment, hostarch.AddrRange{mm.layout.MinAddr, mm.layout.TopDownBase})
+	return mm.findHighestAvailableLocked(length, alignment, hostarch.AddrRange{mm.layout.MinAddr, topDownBase})
 }
 
 func (mm *MemoryManager) applicationAddrRange() hostarch.AddrRange {
-	return hostarch.AddrRange{mm.layout.MinAddr, mm.layout.MaxAddr}
+	return hostarch.AddrRange{mm.layout.MinAddr, mm.layout.MaxMappableAddr()}
 }
 
 // Preconditions: mm.mappingMu must be locked.
@@ -472,6 +492,7 @@ func (vmaSetFunctions) Merge(ar1 hostarch.AddrRange, vma1 vma, ar2 hostarch.Addr
 		vma1.numaPolicy != vma2.numaPolicy ||
 		vma1.numaNodemask != vma2.numaNodemask ||
 		vma1.dontfork != vma2.dontfork ||
+		vma1.wipeOnFork != vma2.wipeOnFork ||
 		vma1.id != vma2.id ||
 		vma1.name != vma2.name ||
 		vma1.nameMut != vma2.nameMut {
@@ -122,6 +122,23 @@ type MemoryFile struct {
 	// transitions from false to true.
 	releaseCond sync.Cond
 
+	// wasteSince is the time at which haveWaste last transitioned from false
+	// to true, and lastWaste is the last time at which pages became waste.
+	// They are only maintained if opts.ReleasePolicy is not
+	// ReleaseImmediate.
+	//
+	// If releaseNow is true, waste pages are released regardless of
+	// opts.ReleasePolicy until haveWaste becomes false.
+	//
+	// releaseTimer wakes the releaser goroutine when release is delayed by
+	// opts.ReleasePolicy.
+	//
+	// These fields are protected by mu.
+	wasteSince   time.Time
+	lastWaste    time.Time
+	releaseNow   bool
+	releaseTimer *time.Timer
+
 	// unfreeSmall and unfreeHuge track information for non-free ranges backed
 	// by small/huge pages respectively. Each unfreeSet also contains segments
 	// representing chunks that are backed by a different page size. Gaps in
@@ -384,6 +401,13 @@ type MemoryFileOpts struct {
 	// If DisableMemoryAccounting is true, memory usage observed by the
 	// MemoryFile will not be reported in usage.MemoryAccounting.
 	DisableMemoryAccounting bool
+
+	// ReleasePolicy controls when freed pages are released to the host.
+	ReleasePolicy ReleasePolicy
+
+	// ReleaseDelay is the delay used by ReleasePolicy. If it is 0,
+	// DefaultReleaseDelay is used.
+	ReleaseDelay time.Duration
 }
 
 // DelayedEvictionType is the type of MemoryFileOpts.DelayedEviction.
@@ -432,6 +456,16 @@ func NewMemoryFile(file *os.File, opts MemoryFileOpts) (*MemoryFile, error) {
 		return nil, fmt.Errorf("invalid MemoryFileOpts.DelayedEviction: %v", opts.DelayedEviction)
 	}
 
+	switch opts.ReleasePolicy {
+	case ReleaseImmediate, ReleaseBatched, ReleaseIdle:
+		// ok
+	default:
+		return nil, fmt.Errorf("invalid MemoryFileOpts.ReleasePolicy: %v", opts.ReleasePolicy)
+	}
+	if opts.ReleaseDelay == 0 {
+		opts.ReleaseDelay = DefaultReleaseDelay
+	}
+
 	// Truncate the file to 0 bytes first to ensure that it's empty.
 	if err := file.Truncate(0); err != nil {
 		return nil, err
@@ -531,6 +565,9 @@ func (f *MemoryFile) releaserDestroyLocked() {
 	if !f.destroyed {
 		panic("destroyed is no longer set")
 	}
+	if f.releaseTimer != nil {
+		f.releaseTimer.Stop()
+	}
 
 	if f.opts.DecommitOnDestroy {
 		if chunks := f.chunksLoad(); len(chunks) != 0 {
@@ -803,6 +840,7 @@ func (f *MemoryFile) findAllocatableAndMarkUsed(alloc *allocState) (fr memmap.Fi
 				}
 			}
 			unwaste.Insert(uwgap, fr, unwasteInfo{})
+			recycledBytes.IncrementBy(fr.Length())
 			// Update reference count for these pages from 0 to 1.
 			unfree.MutateFullRange(fr, func(ufseg unfreeIterator) bool {
 				uf := ufseg.ValuePtr()
@@ -1224,6 +1262,7 @@ func (f *MemoryFile) DecRef(fr memmap.FileRange) {
 				// Mark these pages as waste.
 				wasteFR := ufseg.Range()
 				unwaste.RemoveFullRange(wasteFR)
+				f.noteWasteLocked()
 				haveWaste = true
 				// Reclassify waste memory as System until it's recycled or
 				// released.
@@ -1271,6 +1310,11 @@ MainLoop:
 				return
 			}
 			if f.haveWaste {
+				if d := f.releaseDelayLocked(); d > 0 {
+					// Delay release according to f.opts.ReleasePolicy.
+					f.waitReleaseDelayLocked(d)
+					continue
+				}
 				break
 			}
 			if f.opts.DelayedEviction == DelayedEvictionEnabled && !f.opts.UseHostMemcgPressure {
@@ -1297,11 +1341,13 @@ MainLoop:
 					fr.Start = fr.End - maxReleasingBytes
 				}
 				unwaste.Insert(uwgap, fr, unwasteInfo{})
+				releasedBytes.IncrementBy(fr.Length())
 				f.releaseLocked(fr, i == 1)
 				continue MainLoop
 			}
 		}
 		f.haveWaste = false
+		f.releaseNow = false
 	}
 }
 
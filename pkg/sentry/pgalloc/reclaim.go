@@ -0,0 +1,76 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgalloc
+
+import (
+	"gvisor.dev/gvisor/pkg/metric"
+)
+
+var (
+	reclaims = metric.MustCreateNewUint64Metric("/memory/reclaims", metric.Uint64Metadata{
+		Cumulative:  true,
+		Description: "Number of requests to reclaim MemoryFile memory in response to host memory pressure.",
+	})
+	reclaimedBytes = metric.MustCreateNewUint64Metric("/memory/reclaimed_bytes", metric.Uint64Metadata{
+		Cumulative:  true,
+		Description: "Bytes of MemoryFile memory returned to the host by reclaim requests.",
+	})
+)
+
+// Reclaim attempts to return at least target bytes of memory to the host, in
+// response to host memory pressure. If target is 0, Reclaim returns as much
+// memory as possible.
+//
+// Reclaim evicts evictable allocations, such as clean page cache, until the
+// evicted ranges total at least target bytes. It then releases all waste pages
+// and compacts f, as for Compact. It returns the number of bytes by which f's
+// host memory usage decreased, which may be less than target (if f has too
+// little reclaimable memory) or more than target (since eviction is done per
+// EvictableMemoryUser, and compaction returns all waste pages).
+func (f *MemoryFile) Reclaim(target uint64) (uint64, error) {
+	before, err := f.TotalUsage()
+	if err != nil {
+		return 0, err
+	}
+
+	f.mu.Lock()
+	var evicting uint64
+	for user, info := range f.evictable {
+		if target != 0 && evicting >= target {
+			break
+		}
+		for seg := info.ranges.FirstSegment(); seg.Ok(); seg = seg.NextSegment() {
+			evicting += seg.Range().Length()
+		}
+		if !info.evicting {
+			f.startEvictionGoroutineLocked(user, info)
+		}
+	}
+	f.mu.Unlock()
+	f.WaitForEvictions()
+	f.Compact()
+
+	after, err := f.TotalUsage()
+	if err != nil {
+		return 0, err
+	}
+	var reclaimed uint64
+	if after < before {
+		reclaimed = before - after
+	}
+	reclaims.Increment()
+	reclaimedBytes.IncrementBy(reclaimed)
+	return reclaimed, nil
+}
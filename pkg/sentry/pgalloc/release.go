@@ -0,0 +1,211 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgalloc
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/metric"
+	"gvisor.dev/gvisor/pkg/sentry/memmap"
+)
+
+var (
+	releasedBytes = metric.MustCreateNewUint64Metric("/memory/released_bytes", metric.Uint64Metadata{
+		Cumulative:  true,
+		Description: "Bytes of freed MemoryFile pages that were released to the host.",
+	})
+	recycledBytes = metric.MustCreateNewUint64Metric("/memory/recycled_bytes", metric.Uint64Metadata{
+		Cumulative:  true,
+		Description: "Bytes of freed MemoryFile pages that were reused by allocations before being released to the host.",
+	})
+	compactions = metric.MustCreateNewUint64Metric("/memory/compactions", metric.Uint64Metadata{
+		Cumulative:  true,
+		Description: "Number of MemoryFile compaction passes.",
+	})
+	compactedBytes = metric.MustCreateNewUint64Metric("/memory/compacted_bytes", metric.Uint64Metadata{
+		Cumulative:  true,
+		Description: "Bytes of sub-released huge page memory decommitted again by MemoryFile compaction passes.",
+	})
+)
+
+// ReleasePolicy is the type of MemoryFileOpts.ReleasePolicy.
+type ReleasePolicy uint8
+
+const (
+	// ReleaseImmediate releases waste pages to the host as soon as possible.
+	ReleaseImmediate ReleasePolicy = iota
+
+	// ReleaseBatched delays releasing waste pages until
+	// MemoryFileOpts.ReleaseDelay after pages first became waste. This
+	// releases waste in fewer, larger batches, and lets allocations recycle
+	// waste pages in the meantime.
+	ReleaseBatched
+
+	// ReleaseIdle delays releasing waste pages until no pages have become
+	// waste for MemoryFileOpts.ReleaseDelay, but no longer than
+	// maxIdleReleaseDelays times that after pages first became waste, so that
+	// busy sandboxes still release memory.
+	ReleaseIdle
+)
+
+// DefaultReleaseDelay is the default value of MemoryFileOpts.ReleaseDelay.
+const DefaultReleaseDelay = time.Second
+
+// maxIdleReleaseDelays bounds the delay of ReleaseIdle, in units of
+// MemoryFileOpts.ReleaseDelay.
+const maxIdleReleaseDelays = 10
+
+// String implements fmt.Stringer.String.
+func (p ReleasePolicy) String() string {
+	switch p {
+	case ReleaseImmediate:
+		return "immediate"
+	case ReleaseBatched:
+		return "batched"
+	case ReleaseIdle:
+		return "idle"
+	default:
+		return fmt.Sprintf("ReleasePolicy(%d)", p)
+	}
+}
+
+// ParseReleasePolicy parses the string representation of a ReleasePolicy.
+func ParseReleasePolicy(s string) (ReleasePolicy, error) {
+	switch s {
+	case "", "immediate":
+		return ReleaseImmediate, nil
+	case "batched":
+		return ReleaseBatched, nil
+	case "idle":
+		return ReleaseIdle, nil
+	default:
+		return 0, fmt.Errorf("invalid memory release policy %q, must be one of: immediate, batched, idle", s)
+	}
+}
+
+// noteWasteLocked is called when pages become waste.
+//
+// Preconditions: f.mu must be locked.
+func (f *MemoryFile) noteWasteLocked() {
+	if f.opts.ReleasePolicy == ReleaseImmediate {
+		return
+	}
+	now := time.Now()
+	f.lastWaste = now
+	if !f.haveWaste {
+		f.wasteSince = now
+	}
+}
+
+// releaseDelayLocked returns the time until the releaser goroutine may
+// release waste pages under f's release policy, or a non-positive duration if
+// it may release them now.
+//
+// Preconditions: f.mu must be locked. f.haveWaste must be true.
+func (f *MemoryFile) releaseDelayLocked() time.Duration {
+	if f.releaseNow || f.opts.ReleasePolicy == ReleaseImmediate {
+		return 0
+	}
+	delay := f.opts.ReleaseDelay
+	deadline := f.wasteSince.Add(delay)
+	if f.opts.ReleasePolicy == ReleaseIdle {
+		deadline = f.lastWaste.Add(delay)
+		if latest := f.wasteSince.Add(maxIdleReleaseDelays * delay); latest.Before(deadline) {
+			deadline = latest
+		}
+	}
+	return time.Until(deadline)
+}
+
+// waitReleaseDelayLocked blocks the releaser goroutine for up to d, or until
+// f.releaseCond is signaled.
+//
+// Preconditions: f.mu must be locked; it is unlocked while waiting.
+func (f *MemoryFile) waitReleaseDelayLocked(d time.Duration) {
+	if f.releaseTimer == nil {
+		f.releaseTimer = time.AfterFunc(d, func() {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.releaseCond.Signal()
+		})
+	} else {
+		f.releaseTimer.Reset(d)
+	}
+	f.releaseCond.Wait()
+}
+
+// flushWasteLocked releases all waste pages regardless of f's release policy,
+// and waits for the release to complete.
+//
+// Preconditions: f.mu must be locked; it may be unlocked and reacquired.
+func (f *MemoryFile) flushWasteLocked() {
+	if !f.haveWaste {
+		return
+	}
+	f.releaseNow = true
+	f.releaseCond.Signal()
+	for f.haveWaste && !f.destroyed {
+		f.mu.Unlock()
+		runtime.Gosched()
+		f.mu.Lock()
+	}
+}
+
+// Compact releases all waste pages to the host regardless of f's release
+// policy. It then decommits sub-released small pages in huge-page-backed
+// chunks, which the host may have recommitted by collapsing their containing
+// huge pages (see releaseLocked). It returns the number of bytes of
+// sub-released pages that were decommitted.
+//
+// Compact holds f.mu while decommitting, stalling allocations, so it should be
+// called infrequently.
+func (f *MemoryFile) Compact() uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flushWasteLocked()
+	if f.destroyed {
+		return 0
+	}
+
+	var compacted uint64
+	f.forEachChunk(memmap.FileRange{0, uint64(len(f.chunksLoad())) * chunkSize}, func(chunk *chunkInfo, chunkFR memmap.FileRange) bool {
+		if !chunk.huge {
+			return true
+		}
+		for ufseg := f.unfreeHuge.LowerBoundSegment(chunkFR.Start); ufseg.Ok() && ufseg.Start() < chunkFR.End; ufseg = ufseg.NextSegment() {
+			if ufseg.ValuePtr().refs != 0 {
+				continue
+			}
+			// Pages with no references in a huge chunk are either sub-released,
+			// or waste or releasing; the latter still have memory accounting
+			// state, while sub-released pages don't.
+			fr := ufseg.Range().Intersect(chunkFR)
+			for magap := f.memAcct.LowerBoundGap(fr.Start); magap.Ok() && magap.Start() < fr.End; magap = magap.NextGap() {
+				subFR := magap.Range().Intersect(fr)
+				if subFR.Length() == 0 {
+					continue
+				}
+				f.decommitOrManuallyZero(subFR)
+				compacted += subFR.Length()
+			}
+		}
+		return true
+	})
+	compactions.Increment()
+	compactedBytes.IncrementBy(compacted)
+	return compacted
+}
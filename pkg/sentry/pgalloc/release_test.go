@@ -0,0 +1,66 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgalloc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReleasePolicy(t *testing.T) {
+	for _, p := range []ReleasePolicy{ReleaseImmediate, ReleaseBatched, ReleaseIdle} {
+		got, err := ParseReleasePolicy(p.String())
+		if err != nil || got != p {
+			t.Errorf("ParseReleasePolicy(%q) = %v, %v, want %v, nil", p.String(), got, err, p)
+		}
+	}
+	if _, err := ParseReleasePolicy("never"); err == nil {
+		t.Errorf("ParseReleasePolicy(\"never\") succeeded, want error")
+	}
+}
+
+func TestReleaseDelay(t *testing.T) {
+	const delay = time.Hour
+	now := time.Now()
+	for _, tc := range []struct {
+		name       string
+		policy     ReleasePolicy
+		wasteSince time.Time
+		lastWaste  time.Time
+		releaseNow bool
+		wantDelay  bool
+	}{
+		{name: "immediate", policy: ReleaseImmediate, wasteSince: now, lastWaste: now},
+		{name: "batched pending", policy: ReleaseBatched, wasteSince: now, lastWaste: now, wantDelay: true},
+		{name: "batched due", policy: ReleaseBatched, wasteSince: now.Add(-delay), lastWaste: now},
+		{name: "batched flushed", policy: ReleaseBatched, wasteSince: now, lastWaste: now, releaseNow: true},
+		{name: "idle pending", policy: ReleaseIdle, wasteSince: now.Add(-2 * delay), lastWaste: now, wantDelay: true},
+		{name: "idle due", policy: ReleaseIdle, wasteSince: now.Add(-2 * delay), lastWaste: now.Add(-delay)},
+		{name: "idle busy", policy: ReleaseIdle, wasteSince: now.Add(-maxIdleReleaseDelays * delay), lastWaste: now},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &MemoryFile{
+				opts:       MemoryFileOpts{ReleasePolicy: tc.policy, ReleaseDelay: delay},
+				haveWaste:  true,
+				wasteSince: tc.wasteSince,
+				lastWaste:  tc.lastWaste,
+				releaseNow: tc.releaseNow,
+			}
+			if got := f.releaseDelayLocked() > 0; got != tc.wantDelay {
+				t.Errorf("releaseDelayLocked() > 0 is %t, want %t", got, tc.wantDelay)
+			}
+		})
+	}
+}
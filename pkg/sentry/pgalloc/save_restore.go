@@ -20,7 +20,6 @@ import (
 	"fmt"
 	"io"
 	"math"
-	"runtime"
 	"sync/atomic"
 	"time"
 
@@ -104,11 +103,7 @@ func (f *MemoryFile) SaveTo(ctx context.Context, w io.Writer, opts *SaveOpts) er
 	// Wait for memory release.
 	f.mu.Lock()
 	defer f.mu.Unlock()
-	for f.haveWaste {
-		f.mu.Unlock()
-		runtime.Gosched()
-		f.mu.Lock()
-	}
+	f.flushWasteLocked()
 
 	// Ensure that there are no pending evictions.
 	if len(f.evictable) != 0 {
@@ -1137,6 +1132,10 @@ type AsyncPagesFileLoad struct {
 	// loader goroutine.
 	lfStatus syncevent.Waiter
 
+	// If onDemand is true, only pages with waiters are loaded; see
+	// AsyncPagesFileLoad.LoadOnDemand.
+	onDemand atomicbitops.Bool
+
 	// Padding before state used mostly by the async page loader goroutine:
 	_ [hostarch.CacheLineSize]byte
 
@@ -1206,6 +1205,10 @@ type AsyncPagesFileLoad struct {
 const (
 	aplLFPending syncevent.Set = 1 << iota
 	aplLFDone
+
+	// aplDemandPending is notified when there may be new work for the async
+	// page loader goroutine while loading on demand.
+	aplDemandPending
 )
 
 type aplFileRange struct {
@@ -1352,6 +1355,26 @@ func StartAsyncPagesFileLoad(ar stateio.AsyncReader, doneCallback func(error), t
 	return apfl, nil
 }
 
+// LoadOnDemand causes apfl to only load pages when they are accessed, rather
+// than loading all pages in the background. This allows many sandboxes to be
+// restored from the same pages file while each only copies the pages it
+// accesses into its MemoryFiles.
+//
+// Pages are copied on first access, not on first write, so loaded pages are
+// not shared between sandboxes: MemoryFile memory is always backed by its own
+// file, so it can't map clean pages privately from the pages file. Only the
+// host page cache for the pages file is shared.
+//
+// Since pages that are never accessed are never loaded, loading only completes
+// once all pages have been awaited, e.g. by MemoryFile.AwaitLoadAll() before
+// saving, and the pages file remains in use until then.
+//
+// Preconditions: LoadOnDemand must be called before calling LoadFrom() for
+// any MemoryFile loading from apfl.
+func (apfl *AsyncPagesFileLoad) LoadOnDemand() {
+	apfl.onDemand.Store(true)
+}
+
 // MemoryFilesDone must be called after calling LoadFrom() for all MemoryFiles
 // loading from apfl. MemoryFilesDone may be called multiple times; subsequent
 // calls have no effect.
@@ -1423,6 +1446,10 @@ func (amfl *asyncMemoryFileLoad) awaitLoad(fr memmap.FileRange) error {
 		return true
 	})
 	pending := w.pending != 0
+	if pending && apfl.onDemand.Load() {
+		// The async page loader goroutine may be idle.
+		apfl.lfStatus.Notify(aplDemandPending)
+	}
 	if pending {
 		w.timeStart = gohacks.Nanotime()
 		if apfl.numWaiters == 0 {
@@ -1740,8 +1767,9 @@ func (apfl *AsyncPagesFileLoad) main() {
 			}
 		}
 		apfl.mu.Unlock()
-		// Fill remaining queue with reads for pages with no waiters.
-		if apfl.canEnqueue() {
+		// Fill remaining queue with reads for pages with no waiters, unless
+		// loading on demand.
+		if apfl.canEnqueue() && !apfl.onDemand.Load() {
 			apfl.amflsMu.Lock()
 			// Unawaited loads from earlier MemoryFiles are prioritized over
 			// unawaited loads from later MemoryFiles. Callers of
@@ -1800,13 +1828,19 @@ func (apfl *AsyncPagesFileLoad) main() {
 		if apfl.qavail == maxParallel {
 			// We are out of work to do.
 			ev := apfl.lfStatus.Wait()
-			if ev&aplLFPending != 0 {
+			if ev&(aplLFPending|aplDemandPending) != 0 {
 				// We may have raced with MemoryFile.LoadFrom() inserting into
-				// asyncMemoryFileLoad.unloaded.
-				apfl.lfStatus.Ack(aplLFPending)
+				// asyncMemoryFileLoad.unloaded, or with pages being awaited
+				// while loading on demand.
+				apfl.lfStatus.Ack(aplLFPending | aplDemandPending)
 				continue
 			}
 			if ev&aplLFDone != 0 {
+				if apfl.onDemand.Load() && !apfl.allLoaded() {
+					// Wait for pages to be awaited.
+					apfl.lfStatus.WaitFor(aplLFPending | aplDemandPending)
+					continue
+				}
 				// Successfully completed all loading for all MemoryFiles.
 				durTotal := time.Duration(gohacks.Nanotime() - timeStart)
 				apfl.mu.Lock()
@@ -1918,6 +1952,14 @@ func (apfl *AsyncPagesFileLoad) main() {
 	}
 }
 
+// allLoaded returns true if all MemoryFiles loading from apfl have finished
+// loading.
+func (apfl *AsyncPagesFileLoad) allLoaded() bool {
+	apfl.amflsMu.Lock()
+	defer apfl.amflsMu.Unlock()
+	return apfl.amfls.Empty()
+}
+
 // Preconditions:
 // - All pages in fr must be becoming waste pages.
 // - fr must be page-aligned.
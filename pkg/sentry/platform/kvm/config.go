@@ -27,6 +27,17 @@ type Config struct {
 	// UseCPUNums use KVM vCPU numbers as CPU numbers in the sentry.
 	// This is necessary to support features like RSEQ and CPU preemption detection.
 	UseCPUNums bool
+
+	// ConditionalIBPB issues an indirect branch prediction barrier when a
+	// vCPU switches to a different application address space, so that
+	// branch predictions trained by one process can't steer another. This
+	// is only supported on amd64.
+	ConditionalIBPB bool
+
+	// FlushL1D flushes the L1 data cache every time a vCPU switches to
+	// application code, so that sentry data can't be leaked through the L1
+	// data cache (e.g. via L1TF or MDS). This is only supported on amd64.
+	FlushL1D bool
 }
 
 func (*machine) applyConfig(config *Config) error { return nil }
@@ -84,7 +84,7 @@ func OpenDevice(devicePath string) (*fd.FD, error) {
 	}
 	f, err := fd.Open(devicePath, unix.O_RDWR, 0)
 	if err != nil {
-		return nil, fmt.Errorf("error opening KVM device file (%s): %v", devicePath, err)
+		return nil, fmt.Errorf("error opening KVM device file (%s): %w", devicePath, err)
 	}
 	return f, nil
 }
@@ -223,10 +223,12 @@ func (k *KVM) NewContext(pkgcontext.Context) platform.Context {
 type constructor struct{}
 
 func (*constructor) New(opts platform.Options) (platform.Platform, error) {
-	log.Infof("UseCPUNums: %v", opts.UseCPUNums)
+	log.Infof("UseCPUNums: %v, ConditionalIBPB: %v, FlushL1D: %v", opts.UseCPUNums, opts.ConditionalIBPB, opts.FlushL1D)
 	return New(opts.DeviceFile, Config{
 		ApplicationCores: opts.ApplicationCores,
 		UseCPUNums:       opts.UseCPUNums,
+		ConditionalIBPB:  opts.ConditionalIBPB,
+		FlushL1D:         opts.FlushL1D,
 	})
 }
 
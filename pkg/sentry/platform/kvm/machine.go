@@ -91,6 +91,11 @@ type machine struct {
 
 	// useCPUNums indicates whether to enable the use vCPU numbers as CPU numbers.
 	useCPUNums bool
+
+	// conditionalIBPB and flushL1D enable speculative execution mitigations
+	// on switches to application code. See Config.
+	conditionalIBPB bool
+	flushL1D        bool
 }
 
 const (
@@ -281,6 +286,8 @@ func newMachine(vm int, config *Config) (*machine, error) {
 		fd:               vm,
 		applicationCores: config.ApplicationCores,
 		useCPUNums:       config.UseCPUNums,
+		conditionalIBPB:  config.ConditionalIBPB,
+		flushL1D:         config.FlushL1D,
 	}
 	m.available.L = &m.mu
 
@@ -29,6 +29,7 @@ import (
 	"gvisor.dev/gvisor/pkg/hostarch"
 	"gvisor.dev/gvisor/pkg/hostsyscall"
 	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/metric"
 	"gvisor.dev/gvisor/pkg/ring0"
 	"gvisor.dev/gvisor/pkg/ring0/pagetables"
 	"gvisor.dev/gvisor/pkg/sentry/platform"
@@ -48,6 +49,19 @@ func (m *machine) initArchState() error {
 		return errno
 	}
 
+	// Disable speculative execution mitigations that ring0 can't apply with
+	// the CPUID exposed by KVM, so that mitigationCounter only counts those
+	// that are actually applied.
+	fs := cpuid.FeatureSet{Function: &cpuidSupported}
+	if m.conditionalIBPB && !fs.HasIBPB() {
+		log.Warningf("IBPB is not supported by the CPU, ignoring conditional IBPB")
+		m.conditionalIBPB = false
+	}
+	if m.flushL1D && !fs.HasFeature(cpuid.X86FeatureFLUSH_L1D) {
+		log.Warningf("L1D flush is not supported by the CPU, ignoring L1D flush")
+		m.flushL1D = false
+	}
+
 	// Initialize all vCPUs to minimize kvm ioctl-s allowed by seccomp filters.
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -68,8 +82,30 @@ type vCPUArchState struct {
 
 	// signalStack is the signal stack of the last thread bound to this vCPU.
 	signalStack linux.SignalStack
+
+	// lastUserPageTables are the application page tables that this vCPU
+	// last switched to with machine.conditionalIBPB enabled.
+	lastUserPageTables *pagetables.PageTables
 }
 
+// Field values for the mitigations metric.
+var (
+	mitigationIBPB     = metric.FieldValue{"ibpb"}
+	mitigationFlushL1D = metric.FieldValue{"flush_l1d"}
+)
+
+// mitigationCounter counts the speculative execution mitigations applied on
+// switches to application code. Each one has a roughly fixed cost on a given
+// CPU, so this tracks the overhead of enabling them.
+var mitigationCounter = metric.MustCreateNewUint64Metric("/kvm/mitigations",
+	metric.Uint64Metadata{
+		Cumulative:  true,
+		Description: "KVM speculative execution mitigations applied on switches to application code.",
+		Fields: []metric.Field{
+			metric.NewField("mitigation", &mitigationIBPB, &mitigationFlushL1D),
+		},
+	})
+
 const (
 	// fixedKernelPCID is a fixed kernel PCID used for the kernel page
 	// tables. We must start allocating user PCIDs above this in order to
@@ -374,6 +410,19 @@ func (c *vCPU) SwitchToUser(switchOpts ring0.SwitchOpts, info *linux.SignalInfo)
 		switchOpts.Flush = switchOpts.Flush || requireFlushPCID
 	}
 
+	// Apply speculative execution mitigations. IBPB is only needed when
+	// switching to a different address space, since the previous one may
+	// belong to a different process.
+	if c.machine.conditionalIBPB && c.lastUserPageTables != switchOpts.PageTables {
+		c.lastUserPageTables = switchOpts.PageTables
+		switchOpts.IBPB = true
+		mitigationCounter.Increment(&mitigationIBPB)
+	}
+	if c.machine.flushL1D {
+		switchOpts.FlushL1D = true
+		mitigationCounter.Increment(&mitigationFlushL1D)
+	}
+
 	// See below.
 	var vector ring0.Vector
 
@@ -15,11 +15,27 @@
 // Package platform provides a Platform abstraction.
 //
 // See Platform for more information.
+//
+// # Registering platforms
+//
+// Platforms are selected by name (runsc --platform) from the set of platforms
+// registered with Register. A platform package registers a Constructor from
+// an init function, and is linked into runsc by importing it for its side
+// effects; in-tree platforms are imported by package platforms. Platforms
+// maintained outside of the gVisor tree are supported in the same way: they
+// implement the interfaces in this package, register themselves under a name
+// that doesn't collide with an in-tree platform, and are imported by a
+// binary that otherwise wraps runsc/cli/maincli.Main.
+//
+// The contracts that a platform must uphold are described on Platform,
+// AddressSpace and Context. Package platformtest provides a conformance test
+// suite that checks a registered platform against them.
 package platform
 
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"golang.org/x/sys/unix"
 	"gvisor.dev/gvisor/pkg/abi/linux"
@@ -601,6 +617,15 @@ type Options struct {
 	// rseq
 	UseCPUNums bool
 
+	// ConditionalIBPB is used by KVM to issue an indirect branch prediction
+	// barrier whenever a vCPU switches to a different application address
+	// space.
+	ConditionalIBPB bool
+
+	// FlushL1D is used by KVM to flush the L1 data cache every time a vCPU
+	// switches to application code.
+	FlushL1D bool
+
 	// SandboxID is the sandbox identifier, used by slimvm to pass to the
 	// host kernel module for sandbox identification.
 	SandboxID string
@@ -631,8 +656,22 @@ type Constructor interface {
 // platforms contains all available platform types.
 var platforms = map[string]Constructor{}
 
-// Register registers a new platform type.
+// Register registers a new platform type under the given name, which is used
+// to select it with runsc --platform. It must be called from an init function.
+//
+// Register panics if name is empty or contains a comma (which separates
+// platforms in runsc --platform), or if a platform is already registered
+// under the same name.
 func Register(name string, platform Constructor) {
+	if name == "" || strings.Contains(name, ",") {
+		panic(fmt.Sprintf("invalid platform name %q", name))
+	}
+	if platform == nil {
+		panic(fmt.Sprintf("nil constructor for platform %q", name))
+	}
+	if _, ok := platforms[name]; ok {
+		panic(fmt.Sprintf("platform %q registered twice", name))
+	}
 	platforms[name] = platform
 }
 
@@ -0,0 +1,234 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package platformtest provides a conformance test suite for implementations
+// of platform.Platform.
+//
+// The suite checks the parts of the platform contracts that can be exercised
+// without running application code: registration, static properties of the
+// Platform, and AddressSpace mapping and I/O. Context.Switch is exercised by
+// running the syscall tests (//test/syscalls) against the platform.
+package platformtest
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"gvisor.dev/gvisor/pkg/hostarch"
+	"gvisor.dev/gvisor/pkg/safemem"
+	"gvisor.dev/gvisor/pkg/sentry/contexttest"
+	"gvisor.dev/gvisor/pkg/sentry/pgalloc"
+	"gvisor.dev/gvisor/pkg/sentry/platform"
+	"gvisor.dev/gvisor/pkg/sentry/usage"
+)
+
+// testAddr is the address at which the suite maps memory into address spaces.
+// It is well above the minimum user address and well below the stubs that
+// some platforms install near the top of application address spaces.
+const testAddr = hostarch.Addr(0x10_0000_0000)
+
+// RunConformanceTests runs the conformance test suite against the platform
+// registered under the given name, as subtests of t. opts is passed to the
+// platform's Constructor.New; if opts.DeviceFile is nil, the platform's
+// default device is opened, and the suite is skipped if it is unavailable.
+func RunConformanceTests(t *testing.T, name string, opts platform.Options) {
+	c, err := platform.Lookup(name)
+	if err != nil {
+		t.Fatalf("platform %q is not registered: %v", name, err)
+	}
+	t.Run("Constructor", func(t *testing.T) {
+		testConstructor(t, name, c)
+	})
+
+	if opts.DeviceFile == nil {
+		deviceFile, err := c.OpenDevice("")
+		if err != nil {
+			t.Skipf("platform %q is unavailable: %v", name, err)
+		}
+		if deviceFile != nil {
+			defer deviceFile.Close()
+		}
+		opts.DeviceFile = deviceFile
+	}
+	p, err := c.New(opts)
+	if err != nil {
+		t.Fatalf("failed to create platform %q: %v", name, err)
+	}
+
+	t.Run("Properties", func(t *testing.T) {
+		testProperties(t, name, p)
+	})
+	t.Run("AddressSpace", func(t *testing.T) {
+		testAddressSpace(t, p)
+	})
+	t.Run("Context", func(t *testing.T) {
+		testContext(t, p)
+	})
+}
+
+// testConstructor checks the Constructor registered under name.
+func testConstructor(t *testing.T, name string, c platform.Constructor) {
+	found := false
+	for _, n := range platform.List() {
+		if n == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("platform.List() = %v, does not include %q", platform.List(), name)
+	}
+	for _, info := range c.PrecompiledSeccompInfo() {
+		if key := info.ConfigKey(); !strings.Contains(key, name) {
+			t.Errorf("precompiled SeccompInfo.ConfigKey() = %q, does not contain platform name %q", key, name)
+		}
+	}
+}
+
+// testProperties checks the static properties of p.
+func testProperties(t *testing.T, name string, p platform.Platform) {
+	if mu := p.MapUnit(); mu != 0 && (mu%hostarch.PageSize != 0 || mu&(mu-1) != 0) {
+		t.Errorf("MapUnit() = %#x, want 0 or a power-of-2 multiple of the page size", mu)
+	}
+	minAddr, maxAddr := p.MinUserAddress(), p.MaxUserAddress()
+	if !minAddr.IsPageAligned() || !maxAddr.IsPageAligned() {
+		t.Errorf("user address range [%#x, %#x) is not page-aligned", minAddr, maxAddr)
+	}
+	if minAddr >= maxAddr {
+		t.Errorf("MinUserAddress() = %#x >= MaxUserAddress() = %#x", minAddr, maxAddr)
+	}
+	if p.ConcurrencyCount() <= 0 {
+		t.Errorf("ConcurrencyCount() = %d, want > 0", p.ConcurrencyCount())
+	}
+	if p.HasCPUNumbers() && p.NumCPUs() <= 0 {
+		t.Errorf("NumCPUs() = %d, want > 0 since HasCPUNumbers() is true", p.NumCPUs())
+	}
+	if p.HaveGlobalMemoryBarrier() {
+		if err := p.GlobalMemoryBarrier(); err != nil {
+			t.Errorf("GlobalMemoryBarrier() failed: %v", err)
+		}
+	}
+	if key := p.SeccompInfo().ConfigKey(); !strings.Contains(key, name) {
+		t.Errorf("SeccompInfo().ConfigKey() = %q, does not contain platform name %q", key, name)
+	}
+}
+
+// testAddressSpace checks AddressSpace mapping, and AddressSpaceIO if
+// supported by p.
+func testAddressSpace(t *testing.T, p platform.Platform) {
+	if testAddr < p.MinUserAddress() || testAddr+hostarch.PageSize > p.MaxUserAddress() {
+		t.Skipf("test address %#x is outside of the user address range [%#x, %#x)", testAddr, p.MinUserAddress(), p.MaxUserAddress())
+	}
+
+	ctx := contexttest.Context(t)
+	mf := pgalloc.MemoryFileFromContext(ctx)
+	fr, err := mf.Allocate(hostarch.PageSize, pgalloc.AllocOpts{Kind: usage.Anonymous})
+	if err != nil {
+		t.Fatalf("failed to allocate memory: %v", err)
+	}
+	defer mf.DecRef(fr)
+
+	as, err := p.NewAddressSpace()
+	if err != nil {
+		t.Fatalf("NewAddressSpace() failed: %v", err)
+	}
+	defer as.Release()
+
+	if err := as.MapFile(testAddr, mf, fr, hostarch.ReadWrite, false /* precommit */); err != nil {
+		t.Fatalf("MapFile(%#x, %v) failed: %v", testAddr, fr, err)
+	}
+	// Mapping over an existing mapping silently replaces it.
+	if err := as.MapFile(testAddr, mf, fr, hostarch.ReadWrite, true /* precommit */); err != nil {
+		t.Fatalf("MapFile(%#x, %v) over an existing mapping failed: %v", testAddr, fr, err)
+	}
+
+	if p.SupportsAddressSpaceIO() {
+		want := []byte("platform conformance")
+		if n, err := as.CopyOut(testAddr, want); err != nil && !isIOUnavailable(err) {
+			t.Fatalf("CopyOut(%#x) = %d, %v", testAddr, n, err)
+		} else if err == nil {
+			// The write must be visible through the mapped file.
+			got := make([]byte, len(want))
+			ims, err := mf.MapInternal(fr, hostarch.Read)
+			if err != nil {
+				t.Fatalf("MapInternal(%v) failed: %v", fr, err)
+			}
+			if _, err := safemem.CopySeq(safemem.BlockSeqOf(safemem.BlockFromSafeSlice(got)), ims); err != nil {
+				t.Fatalf("failed to read mapped file: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("file contents after CopyOut = %q, want %q", got, want)
+			}
+
+			got = make([]byte, len(want))
+			if n, err := as.CopyIn(testAddr, got); err != nil {
+				t.Fatalf("CopyIn(%#x) = %d, %v", testAddr, n, err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("CopyIn(%#x) = %q, want %q", testAddr, got, want)
+			}
+
+			if old, err := as.CompareAndSwapUint32(testAddr, 0, 1); err != nil {
+				t.Errorf("CompareAndSwapUint32(%#x) failed: %v", testAddr, err)
+			} else if newVal, err := as.LoadUint32(testAddr); err != nil {
+				t.Errorf("LoadUint32(%#x) failed: %v", testAddr, err)
+			} else if newVal != old {
+				// The CAS must not have succeeded, since the value wasn't 0.
+				t.Errorf("LoadUint32(%#x) = %#x after failed CompareAndSwapUint32, want %#x", testAddr, newVal, old)
+			}
+		}
+	}
+
+	as.Unmap(testAddr, hostarch.PageSize)
+
+	if p.SupportsAddressSpaceIO() {
+		// I/O to unmapped memory must fail.
+		var buf [1]byte
+		if _, err := as.CopyIn(testAddr, buf[:]); err == nil {
+			t.Errorf("CopyIn(%#x) after Unmap succeeded, want error", testAddr)
+		} else if !isIOUnavailable(err) {
+			var sf platform.SegmentationFault
+			if !errors.As(err, &sf) {
+				t.Errorf("CopyIn(%#x) after Unmap = %v, want SegmentationFault", testAddr, err)
+			}
+		}
+	}
+
+	// PreFork and PostFork must be callable in pairs.
+	as.PreFork()
+	as.PostFork()
+}
+
+// testContext checks Context lifecycle methods that don't require running
+// application code.
+func testContext(t *testing.T, p platform.Platform) {
+	c := p.NewContext(contexttest.Context(t))
+	if c == nil {
+		t.Fatalf("NewContext() returned nil")
+	}
+	// Interrupt without a concurrent Switch must not block; it may cause the
+	// next Switch to return ErrContextInterrupt.
+	c.Interrupt()
+	c.Release()
+}
+
+// isIOUnavailable returns true if err indicates that the platform declined to
+// perform AddressSpaceIO, which callers are required to handle by falling
+// back to other means.
+func isIOUnavailable(err error) bool {
+	var unavailable platform.AddressSpaceIOUnavailable
+	return errors.As(err, &unavailable)
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtimestats
+
+import (
+	"gvisor.dev/gvisor/pkg/metric"
+	pb "gvisor.dev/gvisor/pkg/metric/metric_go_proto"
+)
+
+// Quantiles exported for histograms.
+var (
+	p50 = metric.FieldValue{"p50"}
+	p90 = metric.FieldValue{"p90"}
+	p99 = metric.FieldValue{"p99"}
+
+	quantiles = map[*metric.FieldValue]float64{
+		&p50: 0.50,
+		&p90: 0.90,
+		&p99: 0.99,
+	}
+)
+
+func uint64Metric(name string) func(...*metric.FieldValue) uint64 {
+	return func(...*metric.FieldValue) uint64 {
+		s := read(name)
+		return uint64Value(&s[0])
+	}
+}
+
+func quantileMetric(name string) func(...*metric.FieldValue) uint64 {
+	return func(fields ...*metric.FieldValue) uint64 {
+		s := read(name)
+		h := histogramValue(&s[0])
+		return uint64(h.Quantile(quantiles[fields[0]]))
+	}
+}
+
+// RegisterMetrics registers metrics that export the Go runtime statistics of
+// the calling process. It must be called before metric.Initialize, and only by
+// the sentry: the statistics types in this package are also used by runsc
+// processes on the host, whose runtime statistics must not be exported as the
+// sentry's.
+func RegisterMetrics() {
+	metric.MustRegisterCustomUint64Metric("/runtime/goroutines", metric.Uint64Metadata{
+		Description: "Number of live goroutines in the sentry.",
+	}, uint64Metric(goroutinesMetric))
+	metric.MustRegisterCustomUint64Metric("/runtime/heap_objects_bytes", metric.Uint64Metadata{
+		Description: "Memory occupied by live and not yet freed heap objects in the sentry.",
+	}, uint64Metric(heapObjectsMetric))
+	metric.MustRegisterCustomUint64Metric("/runtime/total_memory_bytes", metric.Uint64Metadata{
+		Description: "All memory mapped by the Go runtime of the sentry.",
+	}, uint64Metric(totalMemoryMetric))
+	metric.MustRegisterCustomUint64Metric("/runtime/gc_cycles", metric.Uint64Metadata{
+		Cumulative:  true,
+		Description: "Number of completed GC cycles in the sentry.",
+	}, uint64Metric(gcCyclesMetric))
+
+	quantileField := metric.NewField("quantile", &p50, &p90, &p99)
+	metric.MustRegisterCustomUint64Metric("/runtime/gc_pause", metric.Uint64Metadata{
+		Unit:        pb.MetricMetadata_UNITS_NANOSECONDS,
+		Description: "Quantiles of stop-the-world pauses caused by the GC in the sentry.",
+		Fields:      []metric.Field{quantileField},
+	}, quantileMetric(gcPausesMetric))
+	metric.MustRegisterCustomUint64Metric("/runtime/sched_latency", metric.Uint64Metadata{
+		Unit:        pb.MetricMetadata_UNITS_NANOSECONDS,
+		Description: "Quantiles of the time goroutines spent runnable before running in the sentry.",
+		Fields:      []metric.Field{quantileField},
+	}, quantileMetric(schedLatenciesMetric))
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package runtimestats collects Go runtime statistics of the sentry, such as
+// goroutine counts, heap usage, GC pauses and scheduler latencies.
+package runtimestats
+
+import (
+	"math"
+	"runtime/metrics"
+	"time"
+)
+
+// Names of the runtime/metrics samples used below.
+const (
+	goroutinesMetric     = "/sched/goroutines:goroutines"
+	gomaxprocsMetric     = "/sched/gomaxprocs:threads"
+	heapObjectsMetric    = "/memory/classes/heap/objects:bytes"
+	heapGoalMetric       = "/gc/heap/goal:bytes"
+	totalMemoryMetric    = "/memory/classes/total:bytes"
+	gcCyclesMetric       = "/gc/cycles/total:gc-cycles"
+	gcPausesMetric       = "/sched/pauses/total/gc:seconds"
+	schedLatenciesMetric = "/sched/latencies:seconds"
+)
+
+// Stats are the Go runtime statistics of the sentry.
+type Stats struct {
+	// Goroutines is the number of live goroutines.
+	Goroutines uint64 `json:"goroutines"`
+
+	// GOMAXPROCS is the current value of GOMAXPROCS.
+	GOMAXPROCS uint64 `json:"gomaxprocs"`
+
+	// HeapObjectsBytes is the memory occupied by live and not yet freed heap
+	// objects.
+	HeapObjectsBytes uint64 `json:"heap_objects_bytes"`
+
+	// HeapGoalBytes is the heap size target for the end of the GC cycle.
+	HeapGoalBytes uint64 `json:"heap_goal_bytes"`
+
+	// TotalMemoryBytes is all memory mapped by the Go runtime.
+	TotalMemoryBytes uint64 `json:"total_memory_bytes"`
+
+	// GCCycles is the number of completed GC cycles.
+	GCCycles uint64 `json:"gc_cycles"`
+
+	// GCPauses is the distribution of stop-the-world pauses caused by the GC.
+	GCPauses Histogram `json:"gc_pauses"`
+
+	// SchedLatencies is the distribution of the time goroutines spent in the
+	// scheduler in a runnable state before actually running.
+	SchedLatencies Histogram `json:"sched_latencies"`
+}
+
+// Histogram is a distribution of durations. Only non-empty buckets are
+// included.
+type Histogram struct {
+	// UpperBounds is the exclusive upper bound of each bucket. The last
+	// bucket may be unbounded, in which case its bound is math.MaxInt64.
+	UpperBounds []time.Duration `json:"upper_bounds"`
+
+	// Counts is the number of samples in each bucket.
+	Counts []uint64 `json:"counts"`
+}
+
+// Quantile returns an upper bound of the q-quantile of the distribution, with
+// 0 <= q <= 1. It returns 0 if the histogram is empty.
+func (h *Histogram) Quantile(q float64) time.Duration {
+	var total uint64
+	for _, c := range h.Counts {
+		total += c
+	}
+	if total == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(q * float64(total)))
+	var seen uint64
+	for i, c := range h.Counts {
+		seen += c
+		if seen >= rank {
+			return h.UpperBounds[i]
+		}
+	}
+	return h.UpperBounds[len(h.UpperBounds)-1]
+}
+
+// newHistogram converts a runtime/metrics histogram in seconds.
+func newHistogram(rh *metrics.Float64Histogram) Histogram {
+	var h Histogram
+	for i, c := range rh.Counts {
+		if c == 0 {
+			continue
+		}
+		bound := time.Duration(math.MaxInt64)
+		if upper := rh.Buckets[i+1]; !math.IsInf(upper, 1) && upper*float64(time.Second) < math.MaxInt64 {
+			bound = time.Duration(upper * float64(time.Second))
+		}
+		h.UpperBounds = append(h.UpperBounds, bound)
+		h.Counts = append(h.Counts, c)
+	}
+	return h
+}
+
+// read reads the given runtime/metrics samples. Unsupported samples are left
+// with metrics.KindBad.
+func read(names ...string) []metrics.Sample {
+	samples := make([]metrics.Sample, len(names))
+	for i, name := range names {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+	return samples
+}
+
+func uint64Value(s *metrics.Sample) uint64 {
+	if s.Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return s.Value.Uint64()
+}
+
+func histogramValue(s *metrics.Sample) Histogram {
+	if s.Value.Kind() != metrics.KindFloat64Histogram {
+		return Histogram{}
+	}
+	return newHistogram(s.Value.Float64Histogram())
+}
+
+// Read returns the current Go runtime statistics.
+func Read() Stats {
+	s := read(
+		goroutinesMetric,
+		gomaxprocsMetric,
+		heapObjectsMetric,
+		heapGoalMetric,
+		totalMemoryMetric,
+		gcCyclesMetric,
+		gcPausesMetric,
+		schedLatenciesMetric,
+	)
+	return Stats{
+		Goroutines:       uint64Value(&s[0]),
+		GOMAXPROCS:       uint64Value(&s[1]),
+		HeapObjectsBytes: uint64Value(&s[2]),
+		HeapGoalBytes:    uint64Value(&s[3]),
+		TotalMemoryBytes: uint64Value(&s[4]),
+		GCCycles:         uint64Value(&s[5]),
+		GCPauses:         histogramValue(&s[6]),
+		SchedLatencies:   histogramValue(&s[7]),
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtimestats
+
+import (
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"testing"
+	"time"
+)
+
+func TestRead(t *testing.T) {
+	runtime.GC()
+	s := Read()
+	if s.Goroutines == 0 {
+		t.Errorf("Goroutines is 0")
+	}
+	if s.GOMAXPROCS == 0 {
+		t.Errorf("GOMAXPROCS is 0")
+	}
+	if s.HeapObjectsBytes == 0 || s.TotalMemoryBytes < s.HeapObjectsBytes {
+		t.Errorf("unexpected memory stats: heap %d, total %d", s.HeapObjectsBytes, s.TotalMemoryBytes)
+	}
+	if s.GCCycles == 0 {
+		t.Errorf("GCCycles is 0 after runtime.GC()")
+	}
+	if len(s.GCPauses.Counts) == 0 {
+		t.Errorf("GCPauses is empty after runtime.GC()")
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram(&metrics.Float64Histogram{
+		Counts:  []uint64{0, 5, 0, 4, 1},
+		Buckets: []float64{0, 1e-6, 2e-6, 3e-6, 4e-6, math.Inf(1)},
+	})
+	wantBounds := []time.Duration{2 * time.Microsecond, 4 * time.Microsecond, math.MaxInt64}
+	if len(h.UpperBounds) != len(wantBounds) {
+		t.Fatalf("got bounds %v, want %v", h.UpperBounds, wantBounds)
+	}
+	for i := range wantBounds {
+		if h.UpperBounds[i] != wantBounds[i] {
+			t.Errorf("bound %d: got %v, want %v", i, h.UpperBounds[i], wantBounds[i])
+		}
+	}
+
+	for _, tc := range []struct {
+		q    float64
+		want time.Duration
+	}{
+		{q: 0.5, want: 2 * time.Microsecond},
+		{q: 0.9, want: 4 * time.Microsecond},
+		{q: 1, want: math.MaxInt64},
+	} {
+		if got := h.Quantile(tc.q); got != tc.want {
+			t.Errorf("Quantile(%v) = %v, want %v", tc.q, got, tc.want)
+		}
+	}
+
+	var empty Histogram
+	if got := empty.Quantile(0.5); got != 0 {
+		t.Errorf("Quantile of empty histogram = %v, want 0", got)
+	}
+}
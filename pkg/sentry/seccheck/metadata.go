@@ -31,6 +31,7 @@ const (
 	PointExitNotifyParent
 	PointTaskExit
 	PointMmap
+	PointPacketDrop
 
 	// Add new Points above this line.
 	pointLengthBeforeSyscalls
@@ -77,6 +78,13 @@ const (
 	FieldSentryExecveFdInfo
 )
 
+// Fields for sentry/packet_drop point.
+const (
+	// FieldSentryPacketDropHeaders is an optional field to collect the leading
+	// bytes of the dropped packet, starting at the network header.
+	FieldSentryPacketDropHeaders Field = iota
+)
+
 // Points is a map with all the trace points registered in the system.
 var Points = map[string]PointDesc{}
 
@@ -330,6 +338,18 @@ func genericInit() {
 		Name:          "sentry/mmap",
 		ContextFields: defaultContextFields,
 	})
+	// Packet drops are raised from the network stack rather than from a task,
+	// so no context fields are available.
+	registerPoint(PointDesc{
+		ID:   PointPacketDrop,
+		Name: "sentry/packet_drop",
+		OptionalFields: []FieldDesc{
+			{
+				ID:   FieldSentryPacketDropHeaders,
+				Name: "headers",
+			},
+		},
+	})
 }
 
 var initOnce sync.Once
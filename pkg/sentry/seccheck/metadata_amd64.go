@@ -123,6 +123,12 @@ func archInit() {
 			Name: "fd_path",
 		},
 	})
+	addSyscallPoint(82, "rename", []FieldDesc{
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
 	addSyscallPoint(85, "creat", []FieldDesc{
 		{
 			ID:   FieldSyscallPath,
@@ -136,6 +142,50 @@ func archInit() {
 			Name: "fd_path",
 		},
 	})
+	addSyscallPoint(86, "link", []FieldDesc{
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
+	addSyscallPoint(90, "chmod", []FieldDesc{
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
+	addSyscallPoint(91, "fchmod", []FieldDesc{
+		{
+			ID:   FieldSyscallPath,
+			Name: "fd_path",
+		},
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
+	addSyscallPoint(92, "chown", []FieldDesc{
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
+	addSyscallPoint(93, "fchown", []FieldDesc{
+		{
+			ID:   FieldSyscallPath,
+			Name: "fd_path",
+		},
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
+	addSyscallPoint(94, "lchown", []FieldDesc{
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
 	addSyscallPoint(101, "ptrace", nil)
 	addSyscallPoint(105, "setuid", nil)
 	addSyscallPoint(106, "setgid", nil)
@@ -143,6 +193,18 @@ func archInit() {
 	addSyscallPoint(117, "setresuid", nil)
 	addSyscallPoint(119, "setresgid", nil)
 	addSyscallPoint(161, "chroot", nil)
+	addSyscallPoint(165, "mount", []FieldDesc{
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
+	addSyscallPoint(166, "umount2", []FieldDesc{
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
 	addSyscallPoint(253, "inotify_init", nil)
 	addSyscallPoint(254, "inotify_add_watch", []FieldDesc{
 		{
@@ -162,6 +224,46 @@ func archInit() {
 			Name: "fd_path",
 		},
 	})
+	addSyscallPoint(260, "fchownat", []FieldDesc{
+		{
+			ID:   FieldSyscallPath,
+			Name: "fd_path",
+		},
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
+	addSyscallPoint(264, "renameat", []FieldDesc{
+		{
+			ID:   FieldSyscallPath,
+			Name: "fd_path",
+		},
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
+	addSyscallPoint(265, "linkat", []FieldDesc{
+		{
+			ID:   FieldSyscallPath,
+			Name: "fd_path",
+		},
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
+	addSyscallPoint(268, "fchmodat", []FieldDesc{
+		{
+			ID:   FieldSyscallPath,
+			Name: "fd_path",
+		},
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
 	addSyscallPoint(282, "signalfd", []FieldDesc{
 		{
 			ID:   FieldSyscallPath,
@@ -216,6 +318,16 @@ func archInit() {
 		},
 	})
 	addSyscallPoint(302, "prlimit64", nil)
+	addSyscallPoint(316, "renameat2", []FieldDesc{
+		{
+			ID:   FieldSyscallPath,
+			Name: "fd_path",
+		},
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
 	addSyscallPoint(322, "execveat", []FieldDesc{
 		{
 			ID:   FieldSyscallPath,
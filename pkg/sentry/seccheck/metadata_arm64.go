@@ -53,6 +53,38 @@ func archInit() {
 			Name: "fd_path",
 		},
 	})
+	addSyscallPoint(37, "linkat", []FieldDesc{
+		{
+			ID:   FieldSyscallPath,
+			Name: "fd_path",
+		},
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
+	addSyscallPoint(38, "renameat", []FieldDesc{
+		{
+			ID:   FieldSyscallPath,
+			Name: "fd_path",
+		},
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
+	addSyscallPoint(39, "umount2", []FieldDesc{
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
+	addSyscallPoint(40, "mount", []FieldDesc{
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
 	addSyscallPoint(49, "chdir", nil)
 	addSyscallPoint(50, "fchdir", []FieldDesc{
 		{
@@ -61,6 +93,46 @@ func archInit() {
 		},
 	})
 	addSyscallPoint(51, "chroot", nil)
+	addSyscallPoint(52, "fchmod", []FieldDesc{
+		{
+			ID:   FieldSyscallPath,
+			Name: "fd_path",
+		},
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
+	addSyscallPoint(53, "fchmodat", []FieldDesc{
+		{
+			ID:   FieldSyscallPath,
+			Name: "fd_path",
+		},
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
+	addSyscallPoint(54, "fchownat", []FieldDesc{
+		{
+			ID:   FieldSyscallPath,
+			Name: "fd_path",
+		},
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
+	addSyscallPoint(55, "fchown", []FieldDesc{
+		{
+			ID:   FieldSyscallPath,
+			Name: "fd_path",
+		},
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
 	addSyscallPoint(56, "openat", []FieldDesc{
 		{
 			ID:   FieldSyscallPath,
@@ -193,6 +265,16 @@ func archInit() {
 		},
 	})
 	addSyscallPoint(261, "prlimit64", nil)
+	addSyscallPoint(276, "renameat2", []FieldDesc{
+		{
+			ID:   FieldSyscallPath,
+			Name: "fd_path",
+		},
+		{
+			ID:   FieldSyscallResolvedPath,
+			Name: "resolved_path",
+		},
+	})
 	addSyscallPoint(281, "execveat", []FieldDesc{
 		{
 			ID:   FieldSyscallPath,
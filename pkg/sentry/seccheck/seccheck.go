@@ -111,6 +111,7 @@ type Sink interface {
 	ExitNotifyParent(ctx context.Context, fields FieldSet, info *pb.ExitNotifyParentInfo) error
 	TaskExit(context.Context, FieldSet, *pb.TaskExit) error
 	Mmap(context.Context, FieldSet, *pb.MmapInfo) error
+	PacketDrop(context.Context, FieldSet, *pb.PacketDropInfo) error
 
 	ContainerStart(context.Context, FieldSet, *pb.Start) error
 
@@ -177,6 +178,11 @@ func (SinkDefaults) Mmap(context.Context, FieldSet, *pb.MmapInfo) error {
 	return nil
 }
 
+// PacketDrop implements Sink.PacketDrop.
+func (SinkDefaults) PacketDrop(context.Context, FieldSet, *pb.PacketDropInfo) error {
+	return nil
+}
+
 // RawSyscall implements Sink.RawSyscall.
 func (SinkDefaults) RawSyscall(context.Context, FieldSet, *pb.Syscall) error {
 	return nil
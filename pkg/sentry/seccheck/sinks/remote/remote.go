@@ -271,6 +271,12 @@ func (r *remote) Mmap(_ context.Context, _ seccheck.FieldSet, info *pb.MmapInfo)
 	return nil
 }
 
+// PacketDrop implements seccheck.Sink.
+func (r *remote) PacketDrop(_ context.Context, _ seccheck.FieldSet, info *pb.PacketDropInfo) error {
+	r.write(info, pb.MessageType_MESSAGE_SENTRY_PACKET_DROP)
+	return nil
+}
+
 // ContainerStart implements seccheck.Sink.
 func (r *remote) ContainerStart(_ context.Context, _ seccheck.FieldSet, info *pb.Start) error {
 	r.write(info, pb.MessageType_MESSAGE_CONTAINER_START)
@@ -0,0 +1,213 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spool
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"gvisor.dev/gvisor/pkg/sentry/seccheck/sinks/remote/wire"
+)
+
+// The spool file is a fixed size ring buffer preceded by a header:
+//
+//	0 ------ 8 ------- 12 ---- 16 ------- 24 ------- 32 ------ 40 ---- 64
+//	| Magic | Version | Rsvd | DataSize | WriteOff | ReadOff | Rsvd... |
+//	+-------+---------+------+----------+----------+---------+---------+
+//
+// WriteOff and ReadOff are monotonically increasing byte counts; the
+// position of an offset in the ring is offset % DataSize. Only the sink
+// updates WriteOff and only the consumer updates ReadOff, so the amount of
+// data not yet consumed is always WriteOff - ReadOff.
+//
+// Each record in the ring is a 4-byte length followed by that many bytes,
+// which are a wire.Header and the serialized point. Records wrap around the
+// end of the ring. All integers are little-endian.
+const (
+	fileMagic   = 0x6c6f6f7073766700 // "\x00gvspool"
+	fileVersion = 1
+
+	headerSize = 64
+
+	magicOffset    = 0
+	versionOffset  = 8
+	dataSizeOffset = 16
+	writeOffOffset = 24
+	readOffOffset  = 32
+
+	recordLenSize = 4
+)
+
+// fileHeader is the decoded header of a spool file.
+type fileHeader struct {
+	dataSize uint64
+	writeOff uint64
+	readOff  uint64
+}
+
+// readHeader reads and validates the header of a spool file.
+func readHeader(r io.ReaderAt) (fileHeader, error) {
+	var buf [headerSize]byte
+	if _, err := r.ReadAt(buf[:], 0); err != nil {
+		return fileHeader{}, fmt.Errorf("reading spool header: %w", err)
+	}
+	if magic := binary.LittleEndian.Uint64(buf[magicOffset:]); magic != fileMagic {
+		return fileHeader{}, fmt.Errorf("invalid spool magic %#x", magic)
+	}
+	if version := binary.LittleEndian.Uint32(buf[versionOffset:]); version != fileVersion {
+		return fileHeader{}, fmt.Errorf("unsupported spool version %d", version)
+	}
+	hdr := fileHeader{
+		dataSize: binary.LittleEndian.Uint64(buf[dataSizeOffset:]),
+		writeOff: binary.LittleEndian.Uint64(buf[writeOffOffset:]),
+		readOff:  binary.LittleEndian.Uint64(buf[readOffOffset:]),
+	}
+	if hdr.dataSize == 0 {
+		return fileHeader{}, fmt.Errorf("invalid spool data size 0")
+	}
+	if hdr.readOff > hdr.writeOff || hdr.writeOff-hdr.readOff > hdr.dataSize {
+		return fileHeader{}, fmt.Errorf("invalid spool offsets, read: %d, write: %d, size: %d", hdr.readOff, hdr.writeOff, hdr.dataSize)
+	}
+	return hdr, nil
+}
+
+// writeHeader writes a new header for an empty spool with the given data size.
+func writeHeader(w io.WriterAt, dataSize uint64) error {
+	var buf [headerSize]byte
+	binary.LittleEndian.PutUint64(buf[magicOffset:], fileMagic)
+	binary.LittleEndian.PutUint32(buf[versionOffset:], fileVersion)
+	binary.LittleEndian.PutUint64(buf[dataSizeOffset:], dataSize)
+	_, err := w.WriteAt(buf[:], 0)
+	return err
+}
+
+// readOffset reads a single offset field from the header.
+func readOffset(r io.ReaderAt, field int64) (uint64, error) {
+	var buf [8]byte
+	if _, err := r.ReadAt(buf[:], field); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf[:]), nil
+}
+
+// writeOffset updates a single offset field in the header.
+func writeOffset(w io.WriterAt, field int64, off uint64) error {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], off)
+	_, err := w.WriteAt(buf[:], field)
+	return err
+}
+
+// ringReadAt reads len(b) bytes from the ring at offset off, wrapping around
+// the end of the ring as needed.
+func ringReadAt(r io.ReaderAt, dataSize uint64, b []byte, off uint64) error {
+	for len(b) > 0 {
+		pos := off % dataSize
+		n := uint64(len(b))
+		if n > dataSize-pos {
+			n = dataSize - pos
+		}
+		if _, err := r.ReadAt(b[:n], int64(headerSize+pos)); err != nil {
+			return err
+		}
+		b = b[n:]
+		off += n
+	}
+	return nil
+}
+
+// ringWriteAt writes b to the ring at offset off, wrapping around the end of
+// the ring as needed.
+func ringWriteAt(w io.WriterAt, dataSize uint64, b []byte, off uint64) error {
+	for len(b) > 0 {
+		pos := off % dataSize
+		n := uint64(len(b))
+		if n > dataSize-pos {
+			n = dataSize - pos
+		}
+		if _, err := w.WriteAt(b[:n], int64(headerSize+pos)); err != nil {
+			return err
+		}
+		b = b[n:]
+		off += n
+	}
+	return nil
+}
+
+// File is implemented by files that can back a spool, e.g. *os.File.
+type File interface {
+	io.ReaderAt
+	io.WriterAt
+}
+
+// Reader consumes points from a spool file. It is intended to be used by the
+// process draining the spool. Only one Reader may be used with a spool file
+// at a time.
+type Reader struct {
+	file     File
+	dataSize uint64
+}
+
+// NewReader creates a Reader for the given spool file.
+func NewReader(file File) (*Reader, error) {
+	hdr, err := readHeader(file)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{file: file, dataSize: hdr.dataSize}, nil
+}
+
+// Next returns the header and payload of the oldest point in the spool and
+// marks it as consumed. It returns io.EOF if the spool is empty.
+func (r *Reader) Next() (wire.Header, []byte, error) {
+	writeOff, err := readOffset(r.file, writeOffOffset)
+	if err != nil {
+		return wire.Header{}, nil, err
+	}
+	readOff, err := readOffset(r.file, readOffOffset)
+	if err != nil {
+		return wire.Header{}, nil, err
+	}
+	if readOff == writeOff {
+		return wire.Header{}, nil, io.EOF
+	}
+	if readOff > writeOff || writeOff-readOff < recordLenSize {
+		return wire.Header{}, nil, fmt.Errorf("corrupt spool, read: %d, write: %d", readOff, writeOff)
+	}
+
+	var lenBuf [recordLenSize]byte
+	if err := ringReadAt(r.file, r.dataSize, lenBuf[:], readOff); err != nil {
+		return wire.Header{}, nil, err
+	}
+	recLen := uint64(binary.LittleEndian.Uint32(lenBuf[:]))
+	if recLen < wire.HeaderStructSize || recLen > writeOff-readOff-recordLenSize {
+		return wire.Header{}, nil, fmt.Errorf("corrupt spool record of size %d at offset %d", recLen, readOff)
+	}
+	rec := make([]byte, recLen)
+	if err := ringReadAt(r.file, r.dataSize, rec, readOff+recordLenSize); err != nil {
+		return wire.Header{}, nil, err
+	}
+
+	var hdr wire.Header
+	hdr.UnmarshalUnsafe(rec)
+	if uint64(hdr.HeaderSize) < wire.HeaderStructSize || uint64(hdr.HeaderSize) > recLen {
+		return wire.Header{}, nil, fmt.Errorf("corrupt spool record header size %d", hdr.HeaderSize)
+	}
+	if err := writeOffset(r.file, readOffOffset, readOff+recordLenSize+recLen); err != nil {
+		return wire.Header{}, nil, err
+	}
+	return hdr, rec[hdr.HeaderSize:], nil
+}
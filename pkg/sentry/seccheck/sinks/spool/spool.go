@@ -0,0 +1,367 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spool defines a seccheck.Sink that spools points to a local file.
+// Points are serialized using the protobuf format and appended to a size
+// bounded ring buffer on disk, from where another process consumes them at
+// its own pace.
+package spool
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"gvisor.dev/gvisor/pkg/atomicbitops"
+	"gvisor.dev/gvisor/pkg/cleanup"
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/fd"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/seccheck"
+	pb "gvisor.dev/gvisor/pkg/sentry/seccheck/points/points_go_proto"
+	"gvisor.dev/gvisor/pkg/sentry/seccheck/sinks/remote/wire"
+	"gvisor.dev/gvisor/pkg/sync"
+)
+
+const name = "spool"
+
+const (
+	// policyDrop drops points when the spool is full.
+	policyDrop = "drop"
+	// policyBlock waits for the consumer to make room when the spool is full.
+	policyBlock = "block"
+)
+
+// defaultMaxSize is the default size of the spool ring buffer.
+const defaultMaxSize = 64 << 20
+
+func init() {
+	seccheck.RegisterSink(seccheck.SinkDesc{
+		Name:  name,
+		Setup: setupSink,
+		New:   new,
+	})
+}
+
+// spool appends serialized points to a ring buffer in a file shared with a
+// consumer process. Each record corresponds to a single serialized point
+// proto, preceded by a standard header. When the consumer falls behind and
+// the ring is full, the point is either dropped or the writer waits for the
+// consumer to make room, depending on the configured policy.
+type spool struct {
+	file *fd.FD
+
+	// dataSize is the size of the ring buffer. Immutable.
+	dataSize uint64
+
+	// block is true if writers should wait for room when the spool is full.
+	block bool
+	// blockTimeout is the maximum time to wait for room before dropping the
+	// point. Zero means wait indefinitely.
+	blockTimeout   time.Duration
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	droppedCount atomicbitops.Uint32
+
+	// mu serializes writes to the spool.
+	mu sync.Mutex
+
+	// writeOff is the total number of bytes written to the ring. Protected by
+	// mu.
+	writeOff uint64
+}
+
+var _ seccheck.Sink = (*spool)(nil)
+
+// setupSink opens the spool file, creating and initializing it if needed, and
+// returns it. The caller is responsible to close to file.
+func setupSink(config map[string]any) (*os.File, error) {
+	pathOpaque, ok := config["path"]
+	if !ok {
+		return nil, fmt.Errorf("path not present in configuration")
+	}
+	path, ok := pathOpaque.(string)
+	if !ok {
+		return nil, fmt.Errorf("path %q is not a string", pathOpaque)
+	}
+	maxSize := uint64(defaultMaxSize)
+	if sizeOpaque, ok := config["max_size"]; ok {
+		size, ok := sizeOpaque.(float64)
+		if !ok || size <= 0 || size != float64(uint64(size)) {
+			return nil, fmt.Errorf("max_size %v is not a positive int", sizeOpaque)
+		}
+		maxSize = uint64(size)
+	}
+	return setup(path, maxSize)
+}
+
+func setup(path string, maxSize uint64) (*os.File, error) {
+	if maxSize < recordLenSize+wire.HeaderStructSize {
+		return nil, fmt.Errorf("max_size %d is too small", maxSize)
+	}
+	log.Debugf("Spool sink opening %q", path)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	cu := cleanup.Make(func() {
+		_ = f.Close()
+	})
+	defer cu.Clean()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if stat.Size() == 0 {
+		if err := f.Truncate(int64(headerSize + maxSize)); err != nil {
+			return nil, fmt.Errorf("truncate(%q, %d): %w", path, headerSize+maxSize, err)
+		}
+		if err := writeHeader(f, maxSize); err != nil {
+			return nil, fmt.Errorf("initializing spool %q: %w", path, err)
+		}
+	} else {
+		// Reuse the existing spool, so that points that were not yet consumed are
+		// not lost.
+		hdr, err := readHeader(f)
+		if err != nil {
+			return nil, fmt.Errorf("spool %q: %w", path, err)
+		}
+		if hdr.dataSize != maxSize {
+			return nil, fmt.Errorf("spool %q has size %d, but max_size is %d", path, hdr.dataSize, maxSize)
+		}
+		if stat.Size() < int64(headerSize+maxSize) {
+			return nil, fmt.Errorf("spool %q is truncated, size: %d, want: %d", path, stat.Size(), headerSize+maxSize)
+		}
+	}
+
+	cu.Release()
+	return f, nil
+}
+
+func parseDuration(config map[string]any, name string) (bool, time.Duration, error) {
+	opaque, ok := config[name]
+	if !ok {
+		return false, 0, nil
+	}
+	duration, ok := opaque.(string)
+	if !ok {
+		return false, 0, fmt.Errorf("%s %v is not an string", name, opaque)
+	}
+	rv, err := time.ParseDuration(duration)
+	if err != nil {
+		return false, 0, err
+	}
+	return true, rv, nil
+}
+
+// new creates a new Spool sink.
+func new(config map[string]any, file *fd.FD) (seccheck.Sink, error) {
+	if file == nil {
+		return nil, fmt.Errorf("spool sink requires a file")
+	}
+	hdr, err := readHeader(file)
+	if err != nil {
+		return nil, err
+	}
+	s := &spool{
+		file:           file,
+		dataSize:       hdr.dataSize,
+		writeOff:       hdr.writeOff,
+		initialBackoff: 25 * time.Microsecond,
+		maxBackoff:     10 * time.Millisecond,
+	}
+	if policyOpaque, ok := config["policy"]; ok {
+		policy, ok := policyOpaque.(string)
+		if !ok {
+			return nil, fmt.Errorf("policy %v is not a string", policyOpaque)
+		}
+		switch policy {
+		case policyDrop:
+		case policyBlock:
+			s.block = true
+		default:
+			return nil, fmt.Errorf("invalid policy %q, must be %q or %q", policy, policyDrop, policyBlock)
+		}
+	}
+	if ok, timeout, err := parseDuration(config, "block_timeout"); err != nil {
+		return nil, err
+	} else if ok {
+		if !s.block {
+			return nil, fmt.Errorf("block_timeout requires policy %q", policyBlock)
+		}
+		if timeout < 0 {
+			return nil, fmt.Errorf("block_timeout (%v) cannot be negative", timeout)
+		}
+		s.blockTimeout = timeout
+	}
+
+	log.Debugf("Spool sink created, FD: %d, %+v", s.file.FD(), s)
+	return s, nil
+}
+
+func (*spool) Name() string {
+	return name
+}
+
+func (s *spool) Status() seccheck.SinkStatus {
+	return seccheck.SinkStatus{
+		DroppedCount: uint64(s.droppedCount.Load()),
+	}
+}
+
+// Stop implements seccheck.Sink.
+func (s *spool) Stop() {
+	if s.file != nil {
+		// It's possible to race with Point firing, but in the worst case they will
+		// simply fail to be written.
+		s.file.Close()
+	}
+}
+
+// waitForRoom returns true once the consumer has made room for size bytes in
+// the ring. It returns false if the point must be dropped.
+//
+// Preconditions: s.mu is locked.
+func (s *spool) waitForRoom(size uint64) bool {
+	var start time.Time
+	backoff := s.initialBackoff
+	for {
+		readOff, err := readOffset(s.file, readOffOffset)
+		if err != nil {
+			log.Debugf("Reading spool read offset failed, dropping point: %v", err)
+			return false
+		}
+		if readOff > s.writeOff {
+			log.Debugf("Invalid spool read offset %d (write: %d), dropping point", readOff, s.writeOff)
+			return false
+		}
+		if s.writeOff-readOff+size <= s.dataSize {
+			return true
+		}
+		if !s.block {
+			return false
+		}
+		if start.IsZero() {
+			start = time.Now()
+		} else if s.blockTimeout > 0 && time.Since(start) >= s.blockTimeout {
+			log.Debugf("Spool full for %v, dropping point", s.blockTimeout)
+			return false
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > s.maxBackoff {
+			backoff = s.maxBackoff
+		}
+	}
+}
+
+func (s *spool) write(msg proto.Message, msgType pb.MessageType) {
+	out, err := proto.Marshal(msg)
+	if err != nil {
+		log.Debugf("Marshal(%+v): %v", msg, err)
+		return
+	}
+	hdr := wire.Header{
+		HeaderSize:   uint16(wire.HeaderStructSize),
+		DroppedCount: s.droppedCount.Load(),
+		MessageType:  uint16(msgType),
+	}
+	rec := make([]byte, recordLenSize+wire.HeaderStructSize+len(out))
+	binary.LittleEndian.PutUint32(rec, uint32(wire.HeaderStructSize+len(out)))
+	hdr.MarshalUnsafe(rec[recordLenSize:])
+	copy(rec[recordLenSize+wire.HeaderStructSize:], out)
+
+	if uint64(len(rec)) > s.dataSize {
+		log.Debugf("Point of size %d doesn't fit in spool of size %d, dropping point", len(rec), s.dataSize)
+		s.droppedCount.Add(1)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.waitForRoom(uint64(len(rec))) {
+		s.droppedCount.Add(1)
+		return
+	}
+	if err := ringWriteAt(s.file, s.dataSize, rec, s.writeOff); err != nil {
+		log.Debugf("Write failed, dropping point: %v", err)
+		s.droppedCount.Add(1)
+		return
+	}
+	// Publish the record only after it's fully written.
+	if err := writeOffset(s.file, writeOffOffset, s.writeOff+uint64(len(rec))); err != nil {
+		log.Debugf("Updating spool write offset failed, dropping point: %v", err)
+		s.droppedCount.Add(1)
+		return
+	}
+	s.writeOff += uint64(len(rec))
+}
+
+// Clone implements seccheck.Sink.
+func (s *spool) Clone(_ context.Context, _ seccheck.FieldSet, info *pb.CloneInfo) error {
+	s.write(info, pb.MessageType_MESSAGE_SENTRY_CLONE)
+	return nil
+}
+
+// Execve implements seccheck.Sink.
+func (s *spool) Execve(_ context.Context, _ seccheck.FieldSet, info *pb.ExecveInfo) error {
+	s.write(info, pb.MessageType_MESSAGE_SENTRY_EXEC)
+	return nil
+}
+
+// ExitNotifyParent implements seccheck.Sink.
+func (s *spool) ExitNotifyParent(_ context.Context, _ seccheck.FieldSet, info *pb.ExitNotifyParentInfo) error {
+	s.write(info, pb.MessageType_MESSAGE_SENTRY_EXIT_NOTIFY_PARENT)
+	return nil
+}
+
+// TaskExit implements seccheck.Sink.
+func (s *spool) TaskExit(_ context.Context, _ seccheck.FieldSet, info *pb.TaskExit) error {
+	s.write(info, pb.MessageType_MESSAGE_SENTRY_TASK_EXIT)
+	return nil
+}
+
+// Mmap implements seccheck.Sink.
+func (s *spool) Mmap(_ context.Context, _ seccheck.FieldSet, info *pb.MmapInfo) error {
+	s.write(info, pb.MessageType_MESSAGE_SENTRY_MMAP)
+	return nil
+}
+
+// PacketDrop implements seccheck.Sink.
+func (s *spool) PacketDrop(_ context.Context, _ seccheck.FieldSet, info *pb.PacketDropInfo) error {
+	s.write(info, pb.MessageType_MESSAGE_SENTRY_PACKET_DROP)
+	return nil
+}
+
+// ContainerStart implements seccheck.Sink.
+func (s *spool) ContainerStart(_ context.Context, _ seccheck.FieldSet, info *pb.Start) error {
+	s.write(info, pb.MessageType_MESSAGE_CONTAINER_START)
+	return nil
+}
+
+// RawSyscall implements seccheck.Sink.
+func (s *spool) RawSyscall(_ context.Context, _ seccheck.FieldSet, info *pb.Syscall) error {
+	s.write(info, pb.MessageType_MESSAGE_SYSCALL_RAW)
+	return nil
+}
+
+// Syscall implements seccheck.Sink.
+func (s *spool) Syscall(_ context.Context, _ seccheck.FieldSet, _ *pb.ContextData, msgType pb.MessageType, msg proto.Message) error {
+	s.write(msg, msgType)
+	return nil
+}
@@ -0,0 +1,298 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spool
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"gvisor.dev/gvisor/pkg/fd"
+	"gvisor.dev/gvisor/pkg/sentry/seccheck"
+	pb "gvisor.dev/gvisor/pkg/sentry/seccheck/points/points_go_proto"
+	"gvisor.dev/gvisor/pkg/sentry/seccheck/sinks/remote/wire"
+)
+
+// newSpool creates a spool file of the given size along with a sink writing
+// to it and a reader consuming from it.
+func newSpool(t *testing.T, size uint64, config map[string]any) (*spool, *Reader) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "spool")
+	f, err := setup(path, size)
+	if err != nil {
+		t.Fatalf("setup(%q): %v", path, err)
+	}
+	file, err := fd.NewFromFile(f)
+	if err != nil {
+		t.Fatalf("NewFromFile(): %v", err)
+	}
+	_ = f.Close()
+	sink, err := new(config, file)
+	if err != nil {
+		t.Fatalf("new(): %v", err)
+	}
+	t.Cleanup(sink.Stop)
+
+	rf, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("OpenFile(%q): %v", path, err)
+	}
+	t.Cleanup(func() { _ = rf.Close() })
+	r, err := NewReader(rf)
+	if err != nil {
+		t.Fatalf("NewReader(): %v", err)
+	}
+	return sink.(*spool), r
+}
+
+func readPoint(t *testing.T, r *Reader) (wire.Header, *pb.ExitNotifyParentInfo) {
+	t.Helper()
+	hdr, payload, err := r.Next()
+	if err != nil {
+		t.Fatalf("Next(): %v", err)
+	}
+	if want := uint16(pb.MessageType_MESSAGE_SENTRY_EXIT_NOTIFY_PARENT); hdr.MessageType != want {
+		t.Fatalf("wrong message type, got: %v, want: %v", hdr.MessageType, want)
+	}
+	got := &pb.ExitNotifyParentInfo{}
+	if err := proto.Unmarshal(payload, got); err != nil {
+		t.Fatalf("proto.Unmarshal(ExitNotifyParentInfo): %v", err)
+	}
+	return hdr, got
+}
+
+func writePoint(t *testing.T, s *spool, status int32) {
+	t.Helper()
+	info := &pb.ExitNotifyParentInfo{ExitStatus: status}
+	if err := s.ExitNotifyParent(nil, seccheck.FieldSet{}, info); err != nil {
+		t.Fatalf("ExitNotifyParent: %v", err)
+	}
+}
+
+func checkEmpty(t *testing.T, r *Reader) {
+	t.Helper()
+	if _, _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("Next(): got: %v, want: %v", err, io.EOF)
+	}
+}
+
+// TestBasic checks that points are read back in order and that they wrap
+// around the end of the ring.
+func TestBasic(t *testing.T) {
+	s, r := newSpool(t, 100, nil)
+	checkEmpty(t, r)
+
+	// Each record takes more than 10 bytes, so the ring wraps around many times.
+	for i := int32(0); i < 100; i++ {
+		writePoint(t, s, i)
+		writePoint(t, s, i+1000)
+		if _, got := readPoint(t, r); got.ExitStatus != i {
+			t.Fatalf("wrong exit status, got: %d, want: %d", got.ExitStatus, i)
+		}
+		if _, got := readPoint(t, r); got.ExitStatus != i+1000 {
+			t.Fatalf("wrong exit status, got: %d, want: %d", got.ExitStatus, i+1000)
+		}
+		checkEmpty(t, r)
+	}
+	if got := s.Status().DroppedCount; got != 0 {
+		t.Errorf("wrong dropped count, got: %d, want: 0", got)
+	}
+}
+
+// TestDrop checks that points are dropped when the spool is full and that the
+// dropped count is reported to the consumer.
+func TestDrop(t *testing.T) {
+	s, r := newSpool(t, 64, map[string]any{"policy": "drop"})
+
+	// Write until points start being dropped.
+	written := int32(0)
+	for ; s.Status().DroppedCount == 0; written++ {
+		writePoint(t, s, written)
+	}
+	written--
+	if written == 0 {
+		t.Fatalf("no points written before spool became full")
+	}
+	for i := int32(0); i < written; i++ {
+		if _, got := readPoint(t, r); got.ExitStatus != i {
+			t.Fatalf("wrong exit status, got: %d, want: %d", got.ExitStatus, i)
+		}
+	}
+	checkEmpty(t, r)
+
+	writePoint(t, s, 1234)
+	hdr, got := readPoint(t, r)
+	if got.ExitStatus != 1234 {
+		t.Errorf("wrong exit status, got: %d, want: 1234", got.ExitStatus)
+	}
+	if hdr.DroppedCount != 1 {
+		t.Errorf("wrong dropped count, got: %d, want: 1", hdr.DroppedCount)
+	}
+}
+
+// TestBlockTimeout checks that a blocked point is dropped after the timeout.
+func TestBlockTimeout(t *testing.T) {
+	// Only one point fits in the spool.
+	s, r := newSpool(t, 20, map[string]any{
+		"policy":        "block",
+		"block_timeout": "10ms",
+	})
+	writePoint(t, s, 1)
+	writePoint(t, s, 2)
+	if got := s.Status().DroppedCount; got != 1 {
+		t.Fatalf("wrong dropped count, got: %d, want: 1", got)
+	}
+	if _, got := readPoint(t, r); got.ExitStatus != 1 {
+		t.Errorf("wrong exit status, got: %d, want: 1", got.ExitStatus)
+	}
+	checkEmpty(t, r)
+}
+
+// TestBlock checks that a blocked point is written once the consumer makes
+// room for it.
+func TestBlock(t *testing.T) {
+	// Only one point fits in the spool.
+	s, r := newSpool(t, 20, map[string]any{"policy": "block"})
+	writePoint(t, s, 1)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		writePoint(t, s, 2)
+	}()
+	select {
+	case <-done:
+		t.Fatalf("write didn't block on a full spool")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, got := readPoint(t, r); got.ExitStatus != 1 {
+		t.Errorf("wrong exit status, got: %d, want: 1", got.ExitStatus)
+	}
+	<-done
+	if _, got := readPoint(t, r); got.ExitStatus != 2 {
+		t.Errorf("wrong exit status, got: %d, want: 2", got.ExitStatus)
+	}
+	if got := s.Status().DroppedCount; got != 0 {
+		t.Errorf("wrong dropped count, got: %d, want: 0", got)
+	}
+}
+
+// TestReopen checks that points that were not consumed survive reopening the
+// spool.
+func TestReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spool")
+	for i := int32(0); i < 2; i++ {
+		f, err := setup(path, 1024)
+		if err != nil {
+			t.Fatalf("setup(%q): %v", path, err)
+		}
+		file, err := fd.NewFromFile(f)
+		if err != nil {
+			t.Fatalf("NewFromFile(): %v", err)
+		}
+		_ = f.Close()
+		sink, err := new(nil, file)
+		if err != nil {
+			t.Fatalf("new(): %v", err)
+		}
+		writePoint(t, sink.(*spool), i)
+		sink.Stop()
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("OpenFile(%q): %v", path, err)
+	}
+	defer f.Close()
+	r, err := NewReader(f)
+	if err != nil {
+		t.Fatalf("NewReader(): %v", err)
+	}
+	for i := int32(0); i < 2; i++ {
+		if _, got := readPoint(t, r); got.ExitStatus != i {
+			t.Errorf("wrong exit status, got: %d, want: %d", got.ExitStatus, i)
+		}
+	}
+	checkEmpty(t, r)
+
+	if _, err := setup(path, 2048); err == nil || !strings.Contains(err.Error(), "max_size") {
+		t.Errorf("setup(%q) with different size, got: %v, want: max_size error", path, err)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config map[string]any
+		err    string
+	}{
+		{
+			name:   "default",
+			config: map[string]any{},
+		},
+		{
+			name:   "block",
+			config: map[string]any{"policy": "block", "block_timeout": "1s"},
+		},
+		{
+			name:   "invalid-policy",
+			config: map[string]any{"policy": "foo"},
+			err:    "invalid policy",
+		},
+		{
+			name:   "timeout-without-block",
+			config: map[string]any{"block_timeout": "1s"},
+			err:    "requires policy",
+		},
+		{
+			name:   "negative-timeout",
+			config: map[string]any{"policy": "block", "block_timeout": "-1s"},
+			err:    "cannot be negative",
+		},
+		{
+			name:   "invalid-timeout",
+			config: map[string]any{"policy": "block", "block_timeout": "foo"},
+			err:    "invalid duration",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := setup(filepath.Join(t.TempDir(), "spool"), 1024)
+			if err != nil {
+				t.Fatalf("setup(): %v", err)
+			}
+			file, err := fd.NewFromFile(f)
+			if err != nil {
+				t.Fatalf("NewFromFile(): %v", err)
+			}
+			_ = f.Close()
+			defer file.Close()
+
+			_, err = new(tc.config, file)
+			if len(tc.err) == 0 {
+				if err != nil {
+					t.Errorf("new(%q): %v", tc.config, err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("new(%q), got: %v, want: %q", tc.config, err, tc.err)
+			}
+		})
+	}
+}
@@ -69,6 +69,15 @@ const (
 	FieldSyscallExecveEnvv = FieldSyscallPath + 1
 )
 
+// Fields for syscalls that modify the filesystem.
+const (
+	// FieldSyscallResolvedPath is an optional field to collect the absolute
+	// paths that a syscall operates on, resolved against the directory FD or
+	// the working directory. Start after FieldSyscallPath because many of
+	// these syscalls can also collect path from FD.
+	FieldSyscallResolvedPath = FieldSyscallPath + 1
+)
+
 // GetPointForSyscall translates the syscall number to the corresponding Point.
 func GetPointForSyscall(typ SyscallType, sysno uintptr) Point {
 	return Point(sysno)*Point(syscallTypesCount) + Point(typ) + pointLengthBeforeSyscalls
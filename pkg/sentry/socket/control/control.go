@@ -668,6 +668,12 @@ type SCMRights interface {
 	// Returned files are consumed and ownership is transferred to the caller.
 	// Subsequent calls to Files will return the next files.
 	Files(ctx context.Context, max int) (rf RightsFiles, truncated bool)
+
+	// Peek returns the files that have not yet been consumed by Files.
+	//
+	// Ownership of the returned files is not transferred to the caller, and
+	// no references are taken on them.
+	Peek() RightsFiles
 }
 
 // RightsFiles represents a SCM_RIGHTS socket control message. A reference
@@ -689,7 +695,13 @@ func NewSCMRights(t *kernel.Task, fds []primitive.Int32) (SCMRights, error) {
 		}
 		files = append(files, file)
 	}
-	return &files, nil
+	k := t.Kernel()
+	uc := k.GetUserCounters(t.Credentials().RealKUID)
+	if err := k.UnixInflight().Add(t, uc, files); err != nil {
+		files.Release(t)
+		return nil, err
+	}
+	return &inflightRights{RightsFiles: files, k: k, uc: uc}, nil
 }
 
 // Files implements SCMRights.Files.
@@ -706,6 +718,11 @@ func (fs *RightsFiles) Files(ctx context.Context, max int) (RightsFiles, bool) {
 	return rf, trunc
 }
 
+// Peek implements SCMRights.Peek.
+func (fs *RightsFiles) Peek() RightsFiles {
+	return *fs
+}
+
 // Clone implements transport.RightsControlMessage.Clone.
 func (fs *RightsFiles) Clone() transport.RightsControlMessage {
 	nfs := append(RightsFiles(nil), *fs...)
@@ -723,6 +740,41 @@ func (fs *RightsFiles) Release(ctx context.Context) {
 	*fs = nil
 }
 
+// inflightRights is an SCMRights whose files are accounted as in flight in
+// kernel.UnixInflight until they are either received or released.
+//
+// +stateify savable
+type inflightRights struct {
+	RightsFiles
+
+	// k is the kernel in which the files are accounted.
+	k *kernel.Kernel
+
+	// uc holds the counters of the user that sent the files.
+	uc *kernel.UserCounters
+}
+
+// Files implements SCMRights.Files.
+func (r *inflightRights) Files(ctx context.Context, max int) (RightsFiles, bool) {
+	rf, trunc := r.RightsFiles.Files(ctx, max)
+	r.k.UnixInflight().Remove(r.uc, rf)
+	return rf, trunc
+}
+
+// Clone implements transport.RightsControlMessage.Clone.
+//
+// The returned copy is not accounted as in flight; it is only used to hand
+// files to a receiver that peeks at the message.
+func (r *inflightRights) Clone() transport.RightsControlMessage {
+	return r.RightsFiles.Clone()
+}
+
+// Release implements transport.RightsControlMessage.Release.
+func (r *inflightRights) Release(ctx context.Context) {
+	r.k.UnixInflight().Remove(r.uc, r.RightsFiles)
+	r.RightsFiles.Release(ctx)
+}
+
 // rightsFDs gets up to the specified maximum number of FDs.
 func rightsFDs(t *kernel.Task, rights SCMRights, cloexec bool, max int) ([]int32, bool) {
 	files, trunc := rights.Files(t, max)
@@ -178,6 +178,11 @@ func newSocket(t *kernel.Task, family int, stype linux.SockType, protocol int, f
 	return vfsfd, nil
 }
 
+// HostFD returns the host socket FD.
+func (s *Socket) HostFD() int {
+	return s.fd
+}
+
 // Release implements vfs.FileDescriptionImpl.Release.
 func (s *Socket) Release(ctx context.Context) {
 	kernel.KernelFromContext(ctx).DeleteSocket(&s.vfsfd)
@@ -59,6 +59,8 @@ type Stack struct {
 	tcpRecvBufSize  inet.TCPBufferSize
 	tcpSendBufSize  inet.TCPBufferSize
 	tcpSACKEnabled  bool
+	tcpMemLimits    inet.TCPMemoryLimits
+	tcpModerateRcv  bool
 	allowRawSockets bool
 	configured      bool     `state:"nosave"`
 	netDevFile      *os.File `state:"nosave"`
@@ -117,6 +119,20 @@ func (s *Stack) Configure(allowRawSockets bool) error {
 		log.Warningf("Failed to read if TCP SACK if enabled, setting to true")
 	}
 
+	if tcpMem, err := readTCPMemoryLimitsFile("/proc/sys/net/ipv4/tcp_mem"); err == nil {
+		s.tcpMemLimits = tcpMem
+	} else {
+		log.Warningf("Failed to read TCP memory limits: %v", err)
+	}
+
+	// Receive buffer auto-tuning is enabled by default on Linux.
+	s.tcpModerateRcv = true
+	if moderate, err := os.ReadFile("/proc/sys/net/ipv4/tcp_moderate_rcvbuf"); err == nil {
+		s.tcpModerateRcv = strings.TrimSpace(string(moderate)) != "0"
+	} else {
+		log.Warningf("Failed to read if TCP receive buffer auto-tuning is enabled, setting to true")
+	}
+
 	if f, err := os.Open("/proc/net/dev"); err != nil {
 		log.Warningf("Failed to open /proc/net/dev: %v", err)
 	} else {
@@ -155,6 +171,18 @@ func readTCPBufferSizeFile(filename string) (inet.TCPBufferSize, error) {
 	}, nil
 }
 
+func readTCPMemoryLimitsFile(filename string) (inet.TCPMemoryLimits, error) {
+	size, err := readTCPBufferSizeFile(filename)
+	if err != nil {
+		return inet.TCPMemoryLimits{}, err
+	}
+	return inet.TCPMemoryLimits{
+		Min:      size.Min,
+		Pressure: size.Default,
+		Max:      size.Max,
+	}, nil
+}
+
 // Interfaces implements inet.Stack.Interfaces.
 func (s *Stack) Interfaces() map[int32]inet.Interface {
 	ifs, err := getInterfaces()
@@ -306,6 +334,26 @@ func (*Stack) SetTCPRecovery(inet.TCPLossRecovery) error {
 	return linuxerr.EACCES
 }
 
+// TCPMemoryLimits implements inet.Stack.TCPMemoryLimits.
+func (s *Stack) TCPMemoryLimits() (inet.TCPMemoryLimits, error) {
+	return s.tcpMemLimits, nil
+}
+
+// SetTCPMemoryLimits implements inet.Stack.SetTCPMemoryLimits.
+func (*Stack) SetTCPMemoryLimits(inet.TCPMemoryLimits) error {
+	return linuxerr.EACCES
+}
+
+// TCPModerateReceiveBuffer implements inet.Stack.TCPModerateReceiveBuffer.
+func (s *Stack) TCPModerateReceiveBuffer() (bool, error) {
+	return s.tcpModerateRcv, nil
+}
+
+// SetTCPModerateReceiveBuffer implements inet.Stack.SetTCPModerateReceiveBuffer.
+func (*Stack) SetTCPModerateReceiveBuffer(bool) error {
+	return linuxerr.EACCES
+}
+
 // getLine reads one line from proc file, with specified prefix.
 // The last argument, withHeader, specifies if it contains line header.
 func getLine(f *os.File, prefix string, withHeader bool) string {
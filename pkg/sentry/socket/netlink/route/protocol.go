@@ -312,12 +312,24 @@ func (p *Protocol) dumpAddrs(ctx context.Context, s *netlink.Socket, msg *nlmsg.
 			m.Put(&linux.InterfaceAddrMessage{
 				Family:    a.Family,
 				PrefixLen: a.PrefixLen,
+				Flags:     a.Flags,
 				Index:     uint32(id),
 			})
 
 			addr := primitive.ByteSlice([]byte(a.Addr))
 			m.PutAttr(linux.IFA_LOCAL, &addr)
 			m.PutAttr(linux.IFA_ADDRESS, &addr)
+			m.PutAttr(linux.IFA_FLAGS, primitive.AllocateUint32(uint32(a.Flags)))
+
+			cacheInfo := linux.InterfaceAddrCacheInfo{
+				Preferred: linux.INFINITY_LIFE_TIME,
+				Valid:     linux.INFINITY_LIFE_TIME,
+			}
+			if a.Lifetimes != nil {
+				cacheInfo.Preferred = a.Lifetimes.Preferred
+				cacheInfo.Valid = a.Lifetimes.Valid
+			}
+			m.PutAttr(linux.IFA_CACHEINFO, &cacheInfo)
 
 			// TODO(gvisor.dev/issue/578): There are many more attributes.
 		}
@@ -536,6 +548,12 @@ func (p *Protocol) newAddr(ctx context.Context, s *netlink.Socket, msg *nlmsg.Me
 		return syserr.ErrInvalidArgument
 	}
 
+	// The address is added once all attributes have been parsed, as the
+	// attributes affecting it may follow IFA_LOCAL.
+	var (
+		localAddrs [][]byte
+		lifetimes  *inet.InterfaceAddrLifetimes
+	)
 	for !attrs.Empty() {
 		ahdr, value, rest, ok := attrs.ParseFirst()
 		if !ok {
@@ -550,30 +568,53 @@ func (p *Protocol) newAddr(ctx context.Context, s *netlink.Socket, msg *nlmsg.Me
 		// and ignore the IFA_ADDRESS.
 		switch ahdr.Type {
 		case linux.IFA_LOCAL:
-			err := stack.AddInterfaceAddr(int32(ifa.Index), inet.InterfaceAddr{
-				Family:    ifa.Family,
-				PrefixLen: ifa.PrefixLen,
-				Flags:     ifa.Flags,
-				Addr:      value,
-			})
-			if linuxerr.Equals(linuxerr.EEXIST, err) {
-				flags := msg.Header().Flags
-				if flags&linux.NLM_F_EXCL != 0 {
-					return syserr.ErrExists
-				}
-			} else if err != nil {
-				return syserr.ErrInvalidArgument
-			}
+			localAddrs = append(localAddrs, value)
 		case linux.IFA_ADDRESS:
 		case linux.IFA_BROADCAST:
 			// TODO(b/340929168): support IFA_BROADCAST. The standard
 			// broadcast address (the last IP address of the subnet) is
 			// used by default.
+		case linux.IFA_CACHEINFO:
+			if len(value) < linux.SizeOfInterfaceAddrCacheInfo {
+				return syserr.ErrInvalidArgument
+			}
+			var cacheInfo linux.InterfaceAddrCacheInfo
+			cacheInfo.UnmarshalUnsafe(value)
+			// Like Linux, an address must have a valid lifetime and
+			// must not be preferred longer than it is valid.
+			if cacheInfo.Valid == 0 || cacheInfo.Preferred > cacheInfo.Valid {
+				return syserr.ErrInvalidArgument
+			}
+			lifetimes = &inet.InterfaceAddrLifetimes{
+				Preferred: cacheInfo.Preferred,
+				Valid:     cacheInfo.Valid,
+			}
+		case linux.IFA_FLAGS:
+			// Address flags set by userspace (e.g. IFA_F_NODAD) only
+			// affect features netstack doesn't implement.
 		default:
 			ctx.Warningf("Unknown attribute: %v", ahdr.Type)
 			return syserr.ErrNotSupported
 		}
 	}
+
+	for _, addr := range localAddrs {
+		err := stack.AddInterfaceAddr(int32(ifa.Index), inet.InterfaceAddr{
+			Family:    ifa.Family,
+			PrefixLen: ifa.PrefixLen,
+			Flags:     ifa.Flags,
+			Addr:      addr,
+			Lifetimes: lifetimes,
+		})
+		if linuxerr.Equals(linuxerr.EEXIST, err) {
+			flags := msg.Header().Flags
+			if flags&linux.NLM_F_EXCL != 0 {
+				return syserr.ErrExists
+			}
+		} else if err != nil {
+			return syserr.ErrInvalidArgument
+		}
+	}
 	return nil
 }
 
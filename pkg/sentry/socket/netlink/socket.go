@@ -61,8 +61,13 @@ const (
 	// maxBufferSize is the largest size a send buffer can grow to.
 	maxSendBufferSize = 4 << 20 // 4MB
 
-	// supportedGroups is the set of multicast groups that are supported.
-	supportedGroups = 1 << (linux.RTNLGRP_LINK - 1)
+	// routeSupportedGroups is the set of NETLINK_ROUTE multicast groups that
+	// are supported.
+	routeSupportedGroups = 1 << (linux.RTNLGRP_LINK - 1)
+
+	// ueventSupportedGroups is the set of NETLINK_KOBJECT_UEVENT multicast
+	// groups that are supported.
+	ueventSupportedGroups = 1 << (inet.UeventKernelMcastGroup - 1)
 )
 
 var errNoFilter = syserr.New("no filter attached", errno.ENOENT)
@@ -337,20 +342,37 @@ func (s *Socket) bindPortLocked(t *kernel.Task, port int32) *syserr.Error {
 }
 
 func (s *Socket) checkMcastSupport() *syserr.Error {
-	// Currently only ROUTE family sockets support multicast.
-	if s.Protocol() != linux.NETLINK_ROUTE {
+	switch s.Protocol() {
+	case linux.NETLINK_ROUTE:
+		// Not all inet.Stacks relay interface events, currently only netstack/tcpip does.
+		if _, ok := s.Stack().(inet.InterfaceEventPublisher); !ok {
+			return syserr.ErrNotSupported
+		}
+		return nil
+	case linux.NETLINK_KOBJECT_UEVENT:
+		return nil
+	default:
+		// Currently only ROUTE and KOBJECT_UEVENT family sockets support
+		// multicast.
 		return syserr.ErrNotSupported
 	}
-	// Not all inet.Stacks relay interface events, currently only netstack/tcpip does.
-	if _, ok := s.Stack().(inet.InterfaceEventPublisher); !ok {
-		return syserr.ErrNotSupported
+}
+
+// supportedGroups returns the set of multicast groups that s may join.
+func (s *Socket) supportedGroups() uint64 {
+	switch s.Protocol() {
+	case linux.NETLINK_ROUTE:
+		return routeSupportedGroups
+	case linux.NETLINK_KOBJECT_UEVENT:
+		return ueventSupportedGroups
+	default:
+		return 0
 	}
-	return nil
 }
 
 // preconditions: the netlink multicast table is locked.
 func (s *Socket) joinGroups(groups uint64) *syserr.Error {
-	if groups&supportedGroups != groups {
+	if groups&s.supportedGroups() != groups {
 		return syserr.ErrNotSupported
 	}
 	if err := s.checkMcastSupport(); err != nil {
@@ -373,7 +395,7 @@ func (s *Socket) joinGroup(group uint32) *syserr.Error {
 		return syserr.ErrInvalidArgument
 	}
 	groups := uint64(1) << (group - 1)
-	if groups&supportedGroups != groups {
+	if groups&s.supportedGroups() != groups {
 		return syserr.ErrNotSupported
 	}
 	if err := s.checkMcastSupport(); err != nil {
@@ -394,7 +416,7 @@ func (s *Socket) leaveGroup(group uint32) *syserr.Error {
 		return syserr.ErrInvalidArgument
 	}
 	groups := uint64(1) << (group - 1)
-	if groups&supportedGroups != groups {
+	if groups&s.supportedGroups() != groups {
 		return syserr.ErrNotSupported
 	}
 	if err := s.checkMcastSupport(); err != nil {
@@ -444,6 +466,15 @@ func (s *Socket) HandleInterfaceDeleteEvent(ctx context.Context, idx int32, i in
 	s.SendResponse(ctx, ms)
 }
 
+// HandleUevent implements inet.NetlinkSocket.HandleUevent.
+func (s *Socket) HandleUevent(ctx context.Context, msg []byte) {
+	// Uevents are not netlink messages; the datagram is the raw uevent.
+	cms := transport.ControlMessages{
+		Credentials: kernelCreds,
+	}
+	s.sendBufs(ctx, [][]byte{msg}, cms, 0 /* srcPort */)
+}
+
 // Bind implements socket.Socket.Bind.
 func (s *Socket) Bind(t *kernel.Task, sockaddr []byte) *syserr.Error {
 	a, err := ExtractSockAddr(sockaddr)
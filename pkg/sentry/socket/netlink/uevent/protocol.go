@@ -14,8 +14,10 @@
 
 // Package uevent provides a NETLINK_KOBJECT_UEVENT socket protocol.
 //
-// NETLINK_KOBJECT_UEVENT sockets send udev-style device events. gVisor does
-// not support any device events, so these sockets never send any messages.
+// NETLINK_KOBJECT_UEVENT sockets send udev-style device events. The only
+// events gVisor sends are CPU online and offline events, which are delivered
+// to sockets that join the kernel multicast group. See
+// kernel.Kernel.SendUevent.
 package uevent
 
 import (
@@ -0,0 +1,152 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netstack
+
+import (
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/hostarch"
+	"gvisor.dev/gvisor/pkg/sentry/inet"
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
+)
+
+const (
+	// ethtoolDriver and ethtoolDriverVersion are reported by
+	// ETHTOOL_GDRVINFO for netstack devices.
+	ethtoolDriver        = "netstack"
+	ethtoolDriverVersion = "1.0"
+
+	// ethtoolRingSize is the virtual RX and TX ring size reported by
+	// ETHTOOL_GRINGPARAM. Netstack devices don't have rings, so this is only
+	// a plausible value for tools that insist on one.
+	ethtoolRingSize = 256
+)
+
+// ethtoolIoctl implements the SIOCETHTOOL ioctl for the device named in ifr.
+//
+// Only the query commands commonly used by monitoring tools are supported;
+// everything else fails with EOPNOTSUPP. See Linux's net/ethtool/ioctl.c.
+func ethtoolIoctl(ctx context.Context, t *kernel.Task, ifr *linux.IFReq) error {
+	stk := inet.StackFromContext(ctx)
+	if stk == nil {
+		return linuxerr.ENODEV
+	}
+	var (
+		iface inet.Interface
+		found bool
+	)
+	for _, iface = range stk.Interfaces() {
+		if iface.Name == ifr.Name() {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return linuxerr.ENODEV
+	}
+
+	// The command is the first field of the structure pointed to by
+	// ifr.ifr_data, and determines the type of the rest of it.
+	data := hostarch.Addr(hostarch.ByteOrder.Uint64(ifr.Data[:8]))
+	var cmd linux.EthtoolCmd
+	if _, err := cmd.CopyIn(t, data); err != nil {
+		return err
+	}
+
+	// Like Linux, the loopback device has no link settings, driver or
+	// rings.
+	loopback := iface.DeviceType == linux.ARPHRD_LOOPBACK
+
+	switch cmd {
+	case linux.ETHTOOL_GSET:
+		if loopback {
+			return linuxerr.EOPNOTSUPP
+		}
+		settings := linux.EthtoolSettings{
+			Cmd:         uint32(cmd),
+			Speed:       uint16(linux.SPEED_10000 & 0xffff),
+			SpeedHi:     uint16(linux.SPEED_10000 >> 16),
+			Duplex:      linux.DUPLEX_FULL,
+			Port:        linux.PORT_OTHER,
+			Transceiver: linux.XCVR_INTERNAL,
+			Autoneg:     linux.AUTONEG_DISABLE,
+		}
+		_, err := settings.CopyOut(t, data)
+		return err
+
+	case linux.ETHTOOL_GDRVINFO:
+		if loopback {
+			return linuxerr.EOPNOTSUPP
+		}
+		info := linux.EthtoolDrvInfo{Cmd: uint32(cmd)}
+		copy(info.Driver[:], ethtoolDriver)
+		copy(info.Version[:], ethtoolDriverVersion)
+		_, err := info.CopyOut(t, data)
+		return err
+
+	case linux.ETHTOOL_GLINK:
+		link := linux.EthtoolValue{Cmd: uint32(cmd)}
+		if iface.Flags&linux.IFF_RUNNING != 0 {
+			link.Data = 1
+		}
+		_, err := link.CopyOut(t, data)
+		return err
+
+	case linux.ETHTOOL_GRINGPARAM:
+		if loopback {
+			return linuxerr.EOPNOTSUPP
+		}
+		ring := linux.EthtoolRingParam{
+			Cmd:          uint32(cmd),
+			RxMaxPending: ethtoolRingSize,
+			TxMaxPending: ethtoolRingSize,
+			RxPending:    ethtoolRingSize,
+			TxPending:    ethtoolRingSize,
+		}
+		_, err := ring.CopyOut(t, data)
+		return err
+
+	case linux.ETHTOOL_GFEATURES:
+		var gfeatures linux.EthtoolGFeatures
+		if _, err := gfeatures.CopyIn(t, data); err != nil {
+			return err
+		}
+		// Copy out as many blocks as the caller has room for, but report the
+		// number of blocks needed for all features as Linux does.
+		blocks := iface.Features
+		if int(gfeatures.Size) < len(blocks) {
+			blocks = blocks[:gfeatures.Size]
+		}
+		gfeatures.Size = uint32(len(iface.Features))
+		if _, err := gfeatures.CopyOut(t, data); err != nil {
+			return err
+		}
+		next, ok := data.AddLength(uint64(gfeatures.SizeBytes()))
+		for i := range blocks {
+			if !ok {
+				return linuxerr.EFAULT
+			}
+			if _, err := blocks[i].CopyOut(t, next); err != nil {
+				return err
+			}
+			next, ok = next.AddLength(uint64(blocks[i].SizeBytes()))
+		}
+		return nil
+
+	default:
+		return linuxerr.EOPNOTSUPP
+	}
+}
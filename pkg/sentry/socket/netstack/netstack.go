@@ -337,6 +337,8 @@ var Metrics = tcpip.Stats{
 		SpuriousRecovery:                   mustCreateMetric("/netstack/tcp/spurious_recovery", "Number of times the connection entered loss recovery spuriously."),
 		SpuriousRTORecovery:                mustCreateMetric("/netstack/tcp/spurious_rto_recovery", "Number of times the connection entered RTO spuriously."),
 		ForwardMaxInFlightDrop:             mustCreateMetric("/netstack/tcp/forward_max_in_flight_drop", "Number of connection requests dropped due to exceeding in-flight limit."),
+		MemoryPressures:                    mustCreateMetric("/netstack/tcp/memory_pressures", "Number of times TCP entered memory pressure."),
+		MemoryLimitDrops:                   mustCreateMetric("/netstack/tcp/memory_limit_drops", "Number of segments dropped because TCP memory usage exceeded the limit."),
 	},
 	UDP: tcpip.UDPStats{
 		PacketsReceived:          mustCreateMetric("/netstack/udp/packets_received", "Number of UDP datagrams received via HandlePacket."),
@@ -347,6 +349,21 @@ var Metrics = tcpip.Stats{
 		PacketSendErrors:         mustCreateMetric("/netstack/udp/packet_send_errors", "Number of UDP datagrams failed to be sent."),
 		ChecksumErrors:           mustCreateMetric("/netstack/udp/checksum_errors", "Number of UDP datagrams dropped due to bad checksums."),
 	},
+	Drops: tcpip.DropStats{
+		Unknown:                  mustCreateMetric("/netstack/drops/unknown", "Number of packets dropped for an unclassified reason."),
+		NICDisabled:              mustCreateMetric("/netstack/drops/nic_disabled", "Number of packets dropped because the receiving NIC was disabled."),
+		UnknownNetworkProtocol:   mustCreateMetric("/netstack/drops/unknown_network_protocol", "Number of packets dropped because their network protocol is not supported."),
+		UnknownTransportProtocol: mustCreateMetric("/netstack/drops/unknown_transport_protocol", "Number of packets dropped because their transport protocol is not supported."),
+		Malformed:                mustCreateMetric("/netstack/drops/malformed", "Number of packets dropped because they failed header validation."),
+		Checksum:                 mustCreateMetric("/netstack/drops/checksum", "Number of packets dropped due to bad checksums."),
+		InvalidSource:            mustCreateMetric("/netstack/drops/invalid_source", "Number of packets dropped due to an invalid source address."),
+		InvalidDestination:       mustCreateMetric("/netstack/drops/invalid_destination", "Number of packets dropped because they were not addressed to the stack and could not be forwarded."),
+		Filter:                   mustCreateMetric("/netstack/drops/filter", "Number of packets dropped by iptables or nftables."),
+		NoListener:               mustCreateMetric("/netstack/drops/no_listener", "Number of packets dropped because no endpoint was bound to their destination port."),
+		ListenOverflow:           mustCreateMetric("/netstack/drops/listen_overflow", "Number of packets dropped because a listening endpoint's queue was full."),
+		ReceiveBufferFull:        mustCreateMetric("/netstack/drops/receive_buffer_full", "Number of packets dropped because the receiving endpoint's buffer was full."),
+		NetworkPolicy:            mustCreateMetric("/netstack/drops/network_policy", "Number of packets dropped by a container network policy."),
+	},
 }
 
 // DefaultTTL is linux's default TTL. All network protocols in all stacks used
@@ -1178,6 +1195,14 @@ func GetSockOptSocket(t *kernel.Task, s socket.Socket, ep commonEndpoint, family
 
 		v := primitive.Uint32(ep.SocketOptions().GetMark())
 		return &v, nil
+
+	case linux.SO_PRIORITY:
+		if outLen < sizeOfInt32 {
+			return nil, syserr.ErrInvalidArgument
+		}
+
+		v := primitive.Int32(ep.SocketOptions().GetPriority())
+		return &v, nil
 	default:
 		if v, err, handled := getSockOptSocketCustom(t, s, ep, name, outLen); handled {
 			return v, err
@@ -1638,6 +1663,26 @@ func (s *sock) getSockOptIPv6(t *kernel.Task, ep commonEndpoint, name int, outPt
 		}
 		vP := primitive.Int32(v)
 		return &vP, nil
+
+	case linux.IPV6_MULTICAST_HOPS:
+		if outLen < sizeOfInt32 {
+			return nil, syserr.ErrInvalidArgument
+		}
+
+		v, err := ep.GetSockOptInt(tcpip.IPv6MulticastHopLimitOption)
+		if err != nil {
+			return nil, syserr.TranslateNetstackError(err)
+		}
+		vP := primitive.Int32(v)
+		return &vP, nil
+
+	case linux.IPV6_MULTICAST_LOOP:
+		if outLen < sizeOfInt32 {
+			return nil, syserr.ErrInvalidArgument
+		}
+
+		v := primitive.Int32(boolToInt32(ep.SocketOptions().GetIPv6MulticastLoop()))
+		return &v, nil
 	}
 	return nil, syserr.ErrProtocolNotAvailable
 }
@@ -2179,11 +2224,27 @@ func SetSockOptSocket(t *kernel.Task, s socket.Socket, ep commonEndpoint, name i
 		v := hostarch.ByteOrder.Uint32(optVal)
 		ep.SocketOptions().SetMark(v)
 		return nil
+
+	case linux.SO_PRIORITY:
+		if len(optVal) < sizeOfInt32 {
+			return syserr.ErrInvalidArgument
+		}
+
+		v := int32(hostarch.ByteOrder.Uint32(optVal))
+		// Like Linux, setting a priority above interactive is privileged.
+		if v < stack.PriorityBestEffort || v > stack.PriorityInteractive {
+			ns := t.NetworkNamespace().UserNamespace()
+			if !t.HasCapabilityIn(linux.CAP_NET_RAW, ns) &&
+				!t.HasCapabilityIn(linux.CAP_NET_ADMIN, ns) {
+				return syserr.ErrNotPermitted
+			}
+		}
+		ep.SocketOptions().SetPriority(uint32(v))
+		return nil
 	case linux.SO_DEBUG,
 		linux.SO_TYPE,
 		linux.SO_ERROR,
 		linux.SO_DONTROUTE,
-		linux.SO_PRIORITY,
 		linux.SO_BSDCOMPAT,
 		linux.SO_PEERCRED,
 		linux.SO_SNDLOWAT,
@@ -2452,7 +2513,7 @@ func (s *sock) setSockOptIPv6(t *kernel.Task, ep commonEndpoint, name int, optVa
 		return syserr.ErrUnknownProtocolOption
 	}
 
-	family, _, _ := s.Type()
+	family, skType, _ := s.Type()
 	if family != linux.AF_INET6 {
 		return syserr.ErrUnknownProtocolOption
 	}
@@ -2512,6 +2573,35 @@ func (s *sock) setSockOptIPv6(t *kernel.Task, ep commonEndpoint, name int, optVa
 		v := int32(hostarch.ByteOrder.Uint32(optVal))
 		return syserr.TranslateNetstackError(ep.SetSockOptInt(tcpip.IPv6MulticastInterfaceOption, int(v)))
 
+	case linux.IPV6_MULTICAST_HOPS:
+		// Like Linux, the option can't be set on stream sockets.
+		if skType == linux.SOCK_STREAM {
+			return syserr.ErrUnknownProtocolOption
+		}
+		if len(optVal) < sizeOfInt32 {
+			return syserr.ErrInvalidArgument
+		}
+		v := int32(hostarch.ByteOrder.Uint32(optVal))
+		if v < -1 || v > 255 {
+			return syserr.ErrInvalidArgument
+		}
+		if v == -1 {
+			// Linux translates -1 to 1.
+			v = 1
+		}
+		return syserr.TranslateNetstackError(ep.SetSockOptInt(tcpip.IPv6MulticastHopLimitOption, int(v)))
+
+	case linux.IPV6_MULTICAST_LOOP:
+		if len(optVal) < sizeOfInt32 {
+			return syserr.ErrInvalidArgument
+		}
+		v := int32(hostarch.ByteOrder.Uint32(optVal))
+		if v != 0 && v != 1 {
+			return syserr.ErrInvalidArgument
+		}
+		ep.SocketOptions().SetIPv6MulticastLoop(v != 0)
+		return nil
+
 	case linux.IPV6_IPSEC_POLICY,
 		linux.IPV6_JOIN_ANYCAST,
 		linux.IPV6_LEAVE_ANYCAST,
@@ -2620,9 +2710,7 @@ func (s *sock) setSockOptIPv6(t *kernel.Task, ep commonEndpoint, name int, optVa
 	case linux.IP6T_SO_SET_ADD_COUNTERS:
 		log.Infof("IP6T_SO_SET_ADD_COUNTERS is not supported")
 		return nil
-	case linux.IPV6_MULTICAST_LOOP,
-		linux.IPV6_MULTICAST_HOPS,
-		linux.IPV6_MTU,
+	case linux.IPV6_MTU,
 		linux.IPV6_MINHOPCOUNT,
 		linux.IPV6_RECVERR_RFC4884,
 		linux.IPV6_MULTICAST_ALL,
@@ -2843,7 +2931,16 @@ func (s *sock) setSockOptIP(t *kernel.Task, ep commonEndpoint, name int, optVal
 		if err != nil {
 			return err
 		}
-		return syserr.TranslateNetstackError(ep.SetSockOptInt(tcpip.IPv4TOSOption, int(v)))
+		old, _ := ep.GetSockOptInt(tcpip.IPv4TOSOption)
+		if err := ep.SetSockOptInt(tcpip.IPv4TOSOption, int(v)); err != nil {
+			return syserr.TranslateNetstackError(err)
+		}
+		// Like Linux, changing the TOS also changes the priority of the
+		// socket.
+		if tos, _ := ep.GetSockOptInt(tcpip.IPv4TOSOption); tos != old {
+			ep.SocketOptions().SetPriority(stack.TOSToPriority(uint8(tos)))
+		}
+		return nil
 
 	case linux.IP_RECVTOS:
 		v, err := parseIntOrChar(optVal)
@@ -3519,8 +3616,7 @@ func Ioctl(ctx context.Context, ep commonEndpoint, io usermem.IO, sysno uintptr,
 		linux.SIOCGIFMTU,
 		linux.SIOCGIFNAME,
 		linux.SIOCGIFNETMASK,
-		linux.SIOCGIFTXQLEN,
-		linux.SIOCETHTOOL:
+		linux.SIOCGIFTXQLEN:
 
 		var ifr linux.IFReq
 		if _, err := ifr.CopyIn(t, args[2].Pointer()); err != nil {
@@ -3532,6 +3628,13 @@ func Ioctl(ctx context.Context, ep commonEndpoint, io usermem.IO, sysno uintptr,
 		_, err := ifr.CopyOut(t, args[2].Pointer())
 		return 0, err
 
+	case linux.SIOCETHTOOL:
+		var ifr linux.IFReq
+		if _, err := ifr.CopyIn(t, args[2].Pointer()); err != nil {
+			return 0, err
+		}
+		return 0, ethtoolIoctl(ctx, t, &ifr)
+
 	case linux.SIOCGIFCONF:
 		// Return a list of interface addresses or the buffer size
 		// necessary to hold the list.
@@ -3705,14 +3808,6 @@ func interfaceIoctl(ctx context.Context, _ usermem.IO, arg int, ifr *linux.IFReq
 			break
 		}
 
-	case linux.SIOCETHTOOL:
-		// Stubbed out for now, Ideally we should implement the required
-		// sub-commands for ETHTOOL
-		//
-		// See:
-		// https://github.com/torvalds/linux/blob/aa0c9086b40c17a7ad94425b3b70dd1fdd7497bf/net/core/dev_ioctl.c
-		return syserr.ErrEndpointOperation
-
 	default:
 		// Not a valid call.
 		return syserr.ErrInvalidArgument
@@ -0,0 +1,99 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netstack
+
+import (
+	"golang.org/x/time/rate"
+	"gvisor.dev/gvisor/pkg/atomicbitops"
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/sentry/seccheck"
+	pb "gvisor.dev/gvisor/pkg/sentry/seccheck/points/points_go_proto"
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+)
+
+const (
+	// maxPacketDropHeaderBytes is the maximum number of packet bytes reported
+	// by the sentry/packet_drop point.
+	maxPacketDropHeaderBytes = 128
+
+	// packetDropSampleRate is the sustained number of packet drops per second
+	// reported to the sentry/packet_drop point.
+	packetDropSampleRate = 10
+
+	// packetDropSampleBurst is the number of packet drops that can be reported
+	// in a burst above packetDropSampleRate.
+	packetDropSampleBurst = 20
+)
+
+// dropObserver implements stack.DropObserver by reporting dropped packets to
+// the sentry/packet_drop point. Reports are rate limited so that a flood of
+// dropped packets doesn't overwhelm the trace sinks; the number of drops that
+// were not reported is carried in the next report.
+type dropObserver struct {
+	clock      tcpip.Clock
+	limiter    *rate.Limiter
+	suppressed atomicbitops.Uint64
+}
+
+// NewDropObserver returns a stack.DropObserver that samples dropped packets
+// to the sentry/packet_drop point.
+func NewDropObserver(clock tcpip.Clock) stack.DropObserver {
+	return &dropObserver{
+		clock:   clock,
+		limiter: rate.NewLimiter(packetDropSampleRate, packetDropSampleBurst),
+	}
+}
+
+// PacketDropped implements stack.DropObserver.PacketDropped.
+func (o *dropObserver) PacketDropped(nicID tcpip.NICID, reason tcpip.DropReason, pkt *stack.PacketBuffer) {
+	if !seccheck.Global.Enabled(seccheck.PointPacketDrop) {
+		return
+	}
+	if !o.limiter.Allow() {
+		o.suppressed.Add(1)
+		return
+	}
+
+	fields := seccheck.Global.GetFieldSet(seccheck.PointPacketDrop)
+	info := &pb.PacketDropInfo{
+		TimeNs:          o.clock.Now().UnixNano(),
+		Reason:          reason.String(),
+		NicId:           int32(nicID),
+		NetworkProtocol: uint32(pkt.NetworkProtocolNumber),
+		Suppressed:      o.suppressed.Swap(0),
+	}
+	if fields.Local.Contains(seccheck.FieldSentryPacketDropHeaders) {
+		info.Headers = packetDropHeaders(pkt)
+	}
+	_ = seccheck.Global.SentToSinks(func(c seccheck.Sink) error {
+		return c.PacketDrop(context.Background(), fields, info)
+	})
+}
+
+// packetDropHeaders returns up to maxPacketDropHeaderBytes of pkt, starting at
+// the network header if one has been parsed.
+func packetDropHeaders(pkt *stack.PacketBuffer) []byte {
+	b := make([]byte, 0, maxPacketDropHeaderBytes)
+	b = append(b, pkt.NetworkHeader().Slice()...)
+	b = append(b, pkt.TransportHeader().Slice()...)
+	if len(b) < maxPacketDropHeaderBytes {
+		b = append(b, pkt.Data().AsRange().Capped(maxPacketDropHeaderBytes-len(b)).ToSlice()...)
+	}
+	if len(b) > maxPacketDropHeaderBytes {
+		b = b[:maxPacketDropHeaderBytes]
+	}
+	return b
+}
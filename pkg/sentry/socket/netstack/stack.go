@@ -18,20 +18,24 @@ import (
 	"fmt"
 	"maps"
 	"slices"
+	"time"
 
 	"gvisor.dev/gvisor/pkg/abi/linux"
 	"gvisor.dev/gvisor/pkg/context"
 	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/hostarch"
 	"gvisor.dev/gvisor/pkg/log"
 	"gvisor.dev/gvisor/pkg/refs"
 	"gvisor.dev/gvisor/pkg/sentry/inet"
 	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
+	"gvisor.dev/gvisor/pkg/sentry/socket"
 	"gvisor.dev/gvisor/pkg/sentry/socket/netlink/nlmsg"
 	"gvisor.dev/gvisor/pkg/syserr"
 	"gvisor.dev/gvisor/pkg/tcpip"
 	"gvisor.dev/gvisor/pkg/tcpip/header"
 	"gvisor.dev/gvisor/pkg/tcpip/link/ethernet"
 	"gvisor.dev/gvisor/pkg/tcpip/link/packetsocket"
+	"gvisor.dev/gvisor/pkg/tcpip/link/udptunnel"
 	"gvisor.dev/gvisor/pkg/tcpip/link/veth"
 	"gvisor.dev/gvisor/pkg/tcpip/network/ipv4"
 	"gvisor.dev/gvisor/pkg/tcpip/network/ipv6"
@@ -79,10 +83,42 @@ func makeInterfaceInfo(ni *stack.NICInfo) inet.Interface {
 		Flags:      uint32(nicStateFlagsToLinux(ni.Flags)),
 		DeviceType: toLinuxARPHardwareType(ni.ARPHardwareType),
 		MTU:        ni.MTU,
+		Features:   nicFeatures(ni),
 		Master:     uint32(ni.Primary),
 	}
 }
 
+// nicFeatures returns the netdevice features of a NIC as reported by
+// ETHTOOL_GFEATURES. None of the features can be changed, so they are all
+// reported as never changed.
+func nicFeatures(ni *stack.NICInfo) []linux.EthtoolGetFeaturesBlock {
+	// Netstack always supports scatter/gather and software segmentation, and
+	// its devices never need a queue lock.
+	features := uint64(1<<linux.NETIF_F_SG_BIT |
+		1<<linux.NETIF_F_HIGHDMA_BIT |
+		1<<linux.NETIF_F_FRAGLIST_BIT |
+		1<<linux.NETIF_F_GSO_BIT |
+		1<<linux.NETIF_F_LLTX_BIT)
+	if ni.Flags.Loopback {
+		features |= 1<<linux.NETIF_F_HW_CSUM_BIT | 1<<linux.NETIF_F_NETNS_LOCAL_BIT
+	}
+	if ni.Capabilities&stack.CapabilityTXChecksumOffload != 0 {
+		features |= 1 << linux.NETIF_F_HW_CSUM_BIT
+	}
+	if ni.SupportedGSO != stack.GSONotSupported {
+		features |= 1<<linux.NETIF_F_TSO_BIT | 1<<linux.NETIF_F_TSO6_BIT
+	}
+
+	blocks := make([]linux.EthtoolGetFeaturesBlock, linux.ETHTOOL_DEV_FEATURE_WORDS)
+	for i := range blocks {
+		word := uint32(features >> (32 * i))
+		blocks[i].Requested = word
+		blocks[i].Active = word
+		blocks[i].NeverChanged = word
+	}
+	return blocks
+}
+
 func (s *Stack) sendChangeEvent(ctx context.Context, id tcpip.NICID) {
 	if s.eventSubscriber == nil {
 		return
@@ -544,6 +580,96 @@ func (s *Stack) newBridge(ctx context.Context, linkAttrs map[uint16]nlmsg.BytesV
 	return nil
 }
 
+// tunnelAddr parses an IPv4 or IPv6 address attribute of a tunnel device.
+func tunnelAddr(v nlmsg.BytesView, size int) (tcpip.Address, bool) {
+	if len(v) != size {
+		return tcpip.Address{}, false
+	}
+	return tcpip.AddrFromSlice(v), true
+}
+
+// newTunnel creates a VXLAN or Geneve device. Encapsulated packets are sent
+// and received through s, even if the device is moved to another namespace.
+func (s *Stack) newTunnel(ctx context.Context, kind udptunnel.Kind, linkAttrs map[uint16]nlmsg.BytesView, linkInfoAttrs map[uint16]nlmsg.BytesView) *syserr.Error {
+	opts := udptunnel.Options{
+		Kind:  kind,
+		Stack: s.Stack,
+	}
+	hasVNI := false
+	if value, ok := linkInfoAttrs[linux.IFLA_INFO_DATA]; ok {
+		linkInfoData, ok := nlmsg.AttrsView(value).Parse()
+		if !ok {
+			return syserr.ErrInvalidArgument
+		}
+		for attr, v := range linkInfoData {
+			switch {
+			case kind == udptunnel.VXLAN && attr == linux.IFLA_VXLAN_ID,
+				kind == udptunnel.Geneve && attr == linux.IFLA_GENEVE_ID:
+				opts.VNI, ok = v.Uint32()
+				hasVNI = true
+			case kind == udptunnel.VXLAN && attr == linux.IFLA_VXLAN_GROUP,
+				kind == udptunnel.Geneve && attr == linux.IFLA_GENEVE_REMOTE:
+				opts.Remote, ok = tunnelAddr(v, header.IPv4AddressSize)
+			case kind == udptunnel.VXLAN && attr == linux.IFLA_VXLAN_GROUP6,
+				kind == udptunnel.Geneve && attr == linux.IFLA_GENEVE_REMOTE6:
+				opts.Remote, ok = tunnelAddr(v, header.IPv6AddressSize)
+			case kind == udptunnel.VXLAN && attr == linux.IFLA_VXLAN_LOCAL:
+				opts.Local, ok = tunnelAddr(v, header.IPv4AddressSize)
+			case kind == udptunnel.VXLAN && attr == linux.IFLA_VXLAN_LOCAL6:
+				opts.Local, ok = tunnelAddr(v, header.IPv6AddressSize)
+			case kind == udptunnel.VXLAN && attr == linux.IFLA_VXLAN_TTL,
+				kind == udptunnel.Geneve && attr == linux.IFLA_GENEVE_TTL:
+				opts.TTL, ok = v.Uint8()
+			case kind == udptunnel.VXLAN && attr == linux.IFLA_VXLAN_PORT,
+				kind == udptunnel.Geneve && attr == linux.IFLA_GENEVE_PORT:
+				var port uint16
+				port, ok = v.Uint16()
+				opts.Port = socket.Ntohs(port)
+			default:
+				// Other attributes (e.g. learning and ageing) only tune
+				// behaviour we do not implement.
+			}
+			if !ok {
+				return syserr.ErrInvalidArgument
+			}
+		}
+	}
+	// Like Linux, the network identifier is mandatory. Unlike Linux, we do not
+	// support a forwarding database so a remote address is mandatory too.
+	if !hasVNI || opts.Remote.Len() == 0 {
+		return syserr.ErrInvalidArgument
+	}
+	opts.MTU = defaultMTU - udptunnel.Overhead(kind, opts.Remote)
+
+	dstNs, sysErr := s.lockSrcAndDst(ctx, linkAttrs)
+	if sysErr != nil {
+		return sysErr
+	}
+	defer s.unlockSrcAndDst(ctx, dstNs)
+
+	ep, err := udptunnel.New(opts)
+	if err != nil {
+		return syserr.TranslateNetstackError(err)
+	}
+	id := s.Stack.NextNICID()
+	ifname := fmt.Sprintf("%s%d", kind, id)
+	if v, ok := linkAttrs[linux.IFLA_IFNAME]; ok {
+		ifname = v.String()
+	}
+	if err := s.Stack.CreateNICWithOptions(id, packetsocket.New(ethernet.New(ep)), stack.NICOptions{
+		Name: ifname,
+	}); err != nil {
+		ep.Close()
+		return syserr.TranslateNetstackError(err)
+	}
+	if err := s.setLinkLocked(ctx, id, linkAttrs, dstNs); err != nil {
+		s.Stack.RemoveNIC(id)
+		return err
+	}
+
+	return nil
+}
+
 func (s *Stack) newInterface(ctx context.Context, msg *nlmsg.Message, linkAttrs map[uint16]nlmsg.BytesView) *syserr.Error {
 	var (
 		linkInfoAttrs map[uint16]nlmsg.BytesView
@@ -575,6 +701,10 @@ func (s *Stack) newInterface(ctx context.Context, msg *nlmsg.Message, linkAttrs
 		return s.newBridge(ctx, linkAttrs, linkInfoAttrs)
 	case "veth":
 		return s.newVeth(ctx, linkAttrs, linkInfoAttrs)
+	case "vxlan":
+		return s.newTunnel(ctx, udptunnel.VXLAN, linkAttrs, linkInfoAttrs)
+	case "geneve":
+		return s.newTunnel(ctx, udptunnel.Geneve, linkAttrs, linkInfoAttrs)
 	}
 	return syserr.ErrNotSupported
 }
@@ -597,18 +727,83 @@ func (s *Stack) InterfaceAddrs() map[int32][]inet.InterfaceAddr {
 			}
 
 			addrCopy := a.AddressWithPrefix.Address
-			addrs = append(addrs, inet.InterfaceAddr{
+			ifAddr := inet.InterfaceAddr{
 				Family:    family,
 				PrefixLen: uint8(a.AddressWithPrefix.PrefixLen),
 				Addr:      addrCopy.AsSlice(),
-				// TODO(b/68878065): Other fields.
-			})
+			}
+			if info, err := s.Stack.GetAddressInfo(id, addrCopy); err == nil {
+				ifAddr.Flags, ifAddr.Lifetimes = s.addressFlagsAndLifetimes(info)
+			}
+			addrs = append(addrs, ifAddr)
 		}
 		nicAddrs[int32(id)] = addrs
 	}
 	return nicAddrs
 }
 
+// addressFlagsAndLifetimes returns the Linux IFA_F_* flags and the remaining
+// lifetimes of an address.
+func (s *Stack) addressFlagsAndLifetimes(info stack.AddressInfo) (uint8, *inet.InterfaceAddrLifetimes) {
+	var flags uint8
+	if info.Tentative {
+		flags |= linux.IFA_F_TENTATIVE
+	}
+	if info.Temporary {
+		flags |= linux.IFA_F_TEMPORARY
+	}
+	if info.Lifetimes.Deprecated {
+		flags |= linux.IFA_F_DEPRECATED
+	}
+
+	now := s.Stack.Clock().NowMonotonic()
+	lifetimes := &inet.InterfaceAddrLifetimes{
+		Preferred: remainingLifetime(now, info.Lifetimes.PreferredUntil),
+		Valid:     remainingLifetime(now, info.Lifetimes.ValidUntil),
+	}
+	if info.Lifetimes.Deprecated {
+		lifetimes.Preferred = 0
+	}
+	if lifetimes.Valid == linux.INFINITY_LIFE_TIME {
+		// Like Linux, addresses that never expire are permanent.
+		flags |= linux.IFA_F_PERMANENT
+	}
+	return flags, lifetimes
+}
+
+// remainingLifetime returns the number of seconds from now until the lifetime
+// ends. Unknown and infinite lifetimes never end.
+func remainingLifetime(now, until tcpip.MonotonicTime) uint32 {
+	if until == (tcpip.MonotonicTime{}) || until == tcpip.MonotonicTimeInfinite() {
+		return linux.INFINITY_LIFE_TIME
+	}
+	d := until.Sub(now)
+	if d <= 0 {
+		return 0
+	}
+	secs := d / time.Second
+	if secs >= linux.INFINITY_LIFE_TIME {
+		return linux.INFINITY_LIFE_TIME - 1
+	}
+	return uint32(secs)
+}
+
+// lifetimesUntil converts the remaining lifetimes of an address to the times
+// at which they end.
+func lifetimesUntil(now tcpip.MonotonicTime, lifetimes *inet.InterfaceAddrLifetimes) stack.AddressLifetimes {
+	until := func(secs uint32) tcpip.MonotonicTime {
+		if secs == linux.INFINITY_LIFE_TIME {
+			return tcpip.MonotonicTimeInfinite()
+		}
+		return now.Add(time.Duration(secs) * time.Second)
+	}
+	return stack.AddressLifetimes{
+		Deprecated:     lifetimes.Preferred == 0,
+		PreferredUntil: until(lifetimes.Preferred),
+		ValidUntil:     until(lifetimes.Valid),
+	}
+}
+
 // convertAddr converts an InterfaceAddr to a ProtocolAddress.
 func convertAddr(addr inet.InterfaceAddr) (tcpip.ProtocolAddress, error) {
 	var (
@@ -658,7 +853,19 @@ func (s *Stack) AddInterfaceAddr(idx int32, addr inet.InterfaceAddr) error {
 
 	// Attach address to interface.
 	nicID := tcpip.NICID(idx)
-	if err := s.Stack.AddProtocolAddress(nicID, protocolAddress, stack.AddressProperties{}); err != nil {
+	var properties stack.AddressProperties
+	if addr.Lifetimes != nil {
+		// Addresses added by the application don't expire, since nothing
+		// would remove them or deprecate them when their lifetimes end.
+		// Deprecated addresses, with a preferred lifetime of 0, are
+		// supported.
+		if addr.Lifetimes.Valid != linux.INFINITY_LIFE_TIME ||
+			(addr.Lifetimes.Preferred != 0 && addr.Lifetimes.Preferred != linux.INFINITY_LIFE_TIME) {
+			return linuxerr.EOPNOTSUPP
+		}
+		properties.Lifetimes = lifetimesUntil(s.Stack.Clock().NowMonotonic(), addr.Lifetimes)
+	}
+	if err := s.Stack.AddProtocolAddress(nicID, protocolAddress, properties); err != nil {
 		return syserr.TranslateNetstackError(err).ToError()
 	}
 
@@ -777,6 +984,42 @@ func (s *Stack) SetTCPRecovery(recovery inet.TCPLossRecovery) error {
 	return syserr.TranslateNetstackError(s.Stack.SetTransportProtocolOption(tcp.ProtocolNumber, &opt)).ToError()
 }
 
+// TCPMemoryLimits implements inet.Stack.TCPMemoryLimits.
+func (s *Stack) TCPMemoryLimits() (inet.TCPMemoryLimits, error) {
+	var limits tcpip.TCPMemoryLimitsOption
+	if err := s.Stack.TransportProtocolOption(tcp.ProtocolNumber, &limits); err != nil {
+		return inet.TCPMemoryLimits{}, syserr.TranslateNetstackError(err).ToError()
+	}
+	return inet.TCPMemoryLimits{
+		Min:      limits.Min / hostarch.PageSize,
+		Pressure: limits.Pressure / hostarch.PageSize,
+		Max:      limits.Max / hostarch.PageSize,
+	}, nil
+}
+
+// SetTCPMemoryLimits implements inet.Stack.SetTCPMemoryLimits.
+func (s *Stack) SetTCPMemoryLimits(limits inet.TCPMemoryLimits) error {
+	opt := tcpip.TCPMemoryLimitsOption{
+		Min:      limits.Min * hostarch.PageSize,
+		Pressure: limits.Pressure * hostarch.PageSize,
+		Max:      limits.Max * hostarch.PageSize,
+	}
+	return syserr.TranslateNetstackError(s.Stack.SetTransportProtocolOption(tcp.ProtocolNumber, &opt)).ToError()
+}
+
+// TCPModerateReceiveBuffer implements inet.Stack.TCPModerateReceiveBuffer.
+func (s *Stack) TCPModerateReceiveBuffer() (bool, error) {
+	var moderate tcpip.TCPModerateReceiveBufferOption
+	err := s.Stack.TransportProtocolOption(tcp.ProtocolNumber, &moderate)
+	return bool(moderate), syserr.TranslateNetstackError(err).ToError()
+}
+
+// SetTCPModerateReceiveBuffer implements inet.Stack.SetTCPModerateReceiveBuffer.
+func (s *Stack) SetTCPModerateReceiveBuffer(enabled bool) error {
+	opt := tcpip.TCPModerateReceiveBufferOption(enabled)
+	return syserr.TranslateNetstackError(s.Stack.SetTransportProtocolOption(tcp.ProtocolNumber, &opt)).ToError()
+}
+
 // Statistics implements inet.Stack.Statistics.
 func (s *Stack) Statistics(stat any, arg string) error {
 	netStats := s.Stats()
@@ -787,12 +1030,14 @@ func (s *Stack) Statistics(stat any, arg string) error {
 				continue
 			}
 			// TODO(gvisor.dev/issue/2103) Support stubbed stats.
+			rxDropped := ni.Stats.DisabledRx.Packets.Value()
+			txDropped := ni.Stats.TxPacketsDroppedNoBufferSpace.Value()
 			*stats = inet.StatDev{
 				// Receive section.
 				ni.Stats.Rx.Bytes.Value(),   // bytes.
 				ni.Stats.Rx.Packets.Value(), // packets.
 				0,                           // errs.
-				0,                           // drop.
+				rxDropped,                   // drop.
 				0,                           // fifo.
 				0,                           // frame.
 				0,                           // compressed.
@@ -801,7 +1046,7 @@ func (s *Stack) Statistics(stat any, arg string) error {
 				ni.Stats.Tx.Bytes.Value(),   // bytes.
 				ni.Stats.Tx.Packets.Value(), // packets.
 				0,                           // errs.
-				0,                           // drop.
+				txDropped,                   // drop.
 				0,                           // fifo.
 				0,                           // colls.
 				0,                           // carrier.
@@ -899,12 +1144,99 @@ func (s *Stack) Statistics(stat any, arg string) error {
 			udp.ChecksumErrors.Value(),      // Udp/InCsumErrors.
 			0,                               // Udp/IgnoredMulti.
 		}
+	case *inet.StatSNMPIP6:
+		// IPv6 counters are only kept separately from IPv4 counters by the
+		// network endpoints of each NIC.
+		var ip inet.StatSNMPIP6
+		for _, ni := range s.Stack.NICInfo() {
+			v6, ok := ni.NetworkStats[ipv6.ProtocolNumber].(*ipv6.Stats)
+			if !ok {
+				continue
+			}
+			nicIP := v6.IP
+			addrErrors := nicIP.InvalidDestinationAddressesReceived.Value() + nicIP.InvalidSourceAddressesReceived.Value()
+			inDiscards := nicIP.DisabledPacketsReceived.Value() + nicIP.IPTablesPreroutingDropped.Value() + nicIP.IPTablesInputDropped.Value()
+			outDiscards := nicIP.OutgoingPacketErrors.Value() + nicIP.IPTablesOutputDropped.Value() + nicIP.IPTablesPostroutingDropped.Value()
+			for i, v := range [len(ip)]uint64{
+				nicIP.PacketsReceived.Value(),            // Ip6InReceives.
+				nicIP.MalformedPacketsReceived.Value(),   // Ip6InHdrErrors.
+				nicIP.Forwarding.PacketTooBig.Value(),    // Ip6InTooBigErrors.
+				nicIP.Forwarding.Unrouteable.Value(),     // Ip6InNoRoutes.
+				addrErrors,                               // Ip6InAddrErrors.
+				0,                                        // Ip6InUnknownProtos.
+				0,                                        // Ip6InTruncatedPkts.
+				inDiscards,                               // Ip6InDiscards.
+				nicIP.PacketsDelivered.Value(),           // Ip6InDelivers.
+				0,                                        // Ip6OutForwDatagrams.
+				nicIP.PacketsSent.Value(),                // Ip6OutRequests.
+				outDiscards,                              // Ip6OutDiscards.
+				0,                                        // Ip6OutNoRoutes.
+				0,                                        // Ip6ReasmTimeout.
+				0,                                        // Ip6ReasmReqds.
+				0,                                        // Ip6ReasmOKs.
+				nicIP.MalformedFragmentsReceived.Value(), // Ip6ReasmFails.
+				0,                                        // Ip6FragOKs.
+				0,                                        // Ip6FragFails.
+				0,                                        // Ip6FragCreates.
+			} {
+				ip[i] += v
+			}
+		}
+		*stats = ip
+	case *inet.StatSNMPICMP6:
+		in := netStats.ICMP.V6.PacketsReceived
+		out := netStats.ICMP.V6.PacketsSent
+		inTypes := icmpv6TypeStats(in.ICMPv6PacketStats)
+		outTypes := icmpv6TypeStats(out.ICMPv6PacketStats)
+		var inMsgs, outMsgs uint64
+		for i := range inTypes {
+			inMsgs += inTypes[i]
+			outMsgs += outTypes[i]
+		}
+		inMsgs += in.Invalid.Value() + in.Unrecognized.Value()
+		*stats = inet.StatSNMPICMP6{
+			inMsgs,              // Icmp6InMsgs.
+			in.Invalid.Value(),  // Icmp6InErrors.
+			outMsgs,             // Icmp6OutMsgs.
+			out.Dropped.Value(), // Icmp6OutErrors.
+			0,                   // Icmp6InCsumErrors.
+		}
+		copy(stats[5:], inTypes[:])
+		copy(stats[5+len(inTypes):], outTypes[:])
+	case *inet.StatTCPMem:
+		var used tcpip.TCPMemoryUsedOption
+		if err := s.Stack.TransportProtocolOption(tcp.ProtocolNumber, &used); err != nil {
+			return syserr.TranslateNetstackError(err).ToError()
+		}
+		*stats = inet.StatTCPMem((uint64(used) + hostarch.PageSize - 1) / hostarch.PageSize)
 	default:
 		return syserr.ErrEndpointOperation.ToError()
 	}
 	return nil
 }
 
+// icmpv6TypeStats returns the per-type ICMPv6 counters in the order they are
+// reported by /proc/net/snmp6.
+func icmpv6TypeStats(s tcpip.ICMPv6PacketStats) [15]uint64 {
+	return [15]uint64{
+		s.DstUnreachable.Value(),            // DestUnreachs.
+		s.PacketTooBig.Value(),              // PktTooBigs.
+		s.TimeExceeded.Value(),              // TimeExcds.
+		s.ParamProblem.Value(),              // ParmProblems.
+		s.EchoRequest.Value(),               // Echos.
+		s.EchoReply.Value(),                 // EchoReplies.
+		s.MulticastListenerQuery.Value(),    // GroupMembQueries.
+		s.MulticastListenerReport.Value(),   // GroupMembResponses.
+		s.MulticastListenerDone.Value(),     // GroupMembReductions.
+		s.RouterSolicit.Value(),             // RouterSolicits.
+		s.RouterAdvert.Value(),              // RouterAdvertisements.
+		s.NeighborSolicit.Value(),           // NeighborSolicits.
+		s.NeighborAdvert.Value(),            // NeighborAdvertisements.
+		s.RedirectMsg.Value(),               // Redirects.
+		s.MulticastListenerReportV2.Value(), // MLDv2Reports.
+	}
+}
+
 // Stats implements inet.Stack.Stats.
 func (s *Stack) Stats() tcpip.Stats {
 	return s.Stack.Stats()
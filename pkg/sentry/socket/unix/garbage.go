@@ -0,0 +1,145 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unix
+
+import (
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/metric"
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
+	"gvisor.dev/gvisor/pkg/sentry/socket/control"
+	"gvisor.dev/gvisor/pkg/sentry/socket/unix/transport"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+	"gvisor.dev/gvisor/pkg/sync"
+)
+
+var (
+	gcRuns = metric.MustCreateNewUint64Metric("/unix/gc_runs", metric.Uint64Metadata{
+		Cumulative:  true,
+		Description: "Number of times the Unix socket garbage collector has run.",
+	})
+	gcCollected = metric.MustCreateNewUint64Metric("/unix/gc_collected_sockets", metric.Uint64Metadata{
+		Cumulative:  true,
+		Description: "Number of unreachable Unix sockets freed by the Unix socket garbage collector.",
+	})
+)
+
+// gcMu serializes runs of the garbage collector. It is only ever acquired
+// with TryLock, since releasing the garbage found by one run releases
+// sockets, which would otherwise start another run.
+var gcMu sync.Mutex
+
+// collectGarbage frees Unix sockets that are only referenced by SCM_RIGHTS
+// messages pending on the receive queues of other such sockets. Such cycles
+// can't be broken by the application, since no file descriptor refers to any
+// socket in them, so without collection they would be leaked until the
+// sandbox exits.
+//
+// The algorithm follows net/unix/garbage.c:unix_gc:
+//
+//   - Candidates are in-flight Unix sockets whose every reference is held by
+//     an SCM_RIGHTS message.
+//   - A candidate is reachable if some of its in-flight references are held
+//     by messages that are not queued on other candidates (e.g. they are
+//     queued on a socket that still has a file descriptor), or if it is
+//     queued on a reachable candidate.
+//   - The receive queues of the remaining candidates are purged, which drops
+//     the references that keep the cycle alive.
+//
+// Rights queued on connections that have not yet been accepted are not
+// scanned, so sockets only referenced from such connections are
+// conservatively treated as reachable.
+func collectGarbage(ctx context.Context, k *kernel.Kernel) {
+	if !gcMu.TryLock() {
+		return
+	}
+	defer gcMu.Unlock()
+	gcRuns.Increment()
+
+	var held, garbage []*vfs.FileDescription
+	k.UnixInflight().Freeze(func(inflight map[*vfs.FileDescription]int64) {
+		// candidates maps each candidate to the number of its in-flight
+		// references that are not accounted for by the receive queues of
+		// other candidates.
+		candidates := make(map[*vfs.FileDescription]int64)
+		for fd, n := range inflight {
+			if _, ok := fd.Impl().(*Socket); !ok {
+				continue
+			}
+			if !fd.TryIncRef() {
+				continue
+			}
+			held = append(held, fd)
+			if fd.ReadRefs()-1 == n {
+				candidates[fd] = n
+			}
+		}
+
+		for fd := range candidates {
+			forEachQueuedFile(fd, func(qfd *vfs.FileDescription) {
+				if _, ok := candidates[qfd]; ok {
+					candidates[qfd]--
+				}
+			})
+		}
+
+		var reachable []*vfs.FileDescription
+		for fd, n := range candidates {
+			if n > 0 {
+				reachable = append(reachable, fd)
+			}
+		}
+		for len(reachable) > 0 {
+			fd := reachable[len(reachable)-1]
+			reachable = reachable[:len(reachable)-1]
+			if _, ok := candidates[fd]; !ok {
+				continue
+			}
+			delete(candidates, fd)
+			forEachQueuedFile(fd, func(qfd *vfs.FileDescription) {
+				if _, ok := candidates[qfd]; ok {
+					reachable = append(reachable, qfd)
+				}
+			})
+		}
+
+		for fd := range candidates {
+			garbage = append(garbage, fd)
+		}
+	})
+
+	// Purging and dropping references release SCM_RIGHTS messages, so it
+	// must be done after the in-flight set is unfrozen.
+	for _, fd := range garbage {
+		fd.Impl().(*Socket).ep.PurgeRecvQueue(ctx)
+	}
+	for _, fd := range held {
+		fd.DecRef(ctx)
+	}
+	gcCollected.IncrementBy(uint64(len(garbage)))
+}
+
+// forEachQueuedFile calls fn for each file held by SCM_RIGHTS messages that
+// are pending on the receive queue of the Unix socket fd.
+func forEachQueuedFile(fd *vfs.FileDescription, fn func(*vfs.FileDescription)) {
+	fd.Impl().(*Socket).ep.ForEachQueuedRights(func(rm transport.RightsControlMessage) {
+		rights, ok := rm.(control.SCMRights)
+		if !ok {
+			return
+		}
+		for _, f := range rights.Peek() {
+			fn(f)
+		}
+	})
+}
@@ -229,6 +229,18 @@ func (q *queue) Peek() (*message, *syserr.Error) {
 	return q.dataList.Front().Peek(), nil
 }
 
+// forEachRights calls fn for each rights control message in the queue while
+// holding q.mu.
+func (q *queue) forEachRights(fn func(RightsControlMessage)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for cur := q.dataList.Front(); cur != nil; cur = cur.Next() {
+		if cur.Control.Rights != nil {
+			fn(cur.Control.Rights)
+		}
+	}
+}
+
 // QueuedSize returns the number of bytes currently in the queue, that is, the
 // number of readable bytes.
 func (q *queue) QueuedSize() int64 {
@@ -240,6 +240,15 @@ type Endpoint interface {
 	// SocketOptions returns the structure which contains all the socket
 	// level options.
 	SocketOptions() *tcpip.SocketOptions
+
+	// ForEachQueuedRights calls fn for each rights control message that has
+	// been delivered to the endpoint but not yet received. fn is called with
+	// receive queue locks held and must not retain the message.
+	ForEachQueuedRights(fn func(RightsControlMessage))
+
+	// PurgeRecvQueue discards all messages that have been delivered to the
+	// endpoint but not yet received, releasing their control messages.
+	PurgeRecvQueue(ctx context.Context)
 }
 
 // A Credentialer is a socket or endpoint that supports the SO_PASSCRED socket
@@ -494,6 +503,27 @@ func (q *queueReceiver) Release(ctx context.Context) {
 	q.readQueue.DecRef(ctx)
 }
 
+// forEachRights implements rightsReceiver.forEachRights.
+func (q *queueReceiver) forEachRights(fn func(RightsControlMessage)) {
+	q.readQueue.forEachRights(fn)
+}
+
+// purge implements rightsReceiver.purge.
+func (q *queueReceiver) purge(ctx context.Context) {
+	q.readQueue.Reset(ctx)
+	q.readQueue.WriterQueue.Notify(waiter.WritableEvents)
+}
+
+// rightsReceiver is implemented by Receivers whose pending messages may hold
+// rights control messages created in the sentry.
+type rightsReceiver interface {
+	// forEachRights calls fn for each pending rights control message.
+	forEachRights(fn func(RightsControlMessage))
+
+	// purge discards all pending messages.
+	purge(ctx context.Context)
+}
+
 // streamQueueReceiver implements Receiver for stream sockets.
 //
 // +stateify savable
@@ -672,6 +702,27 @@ func (q *streamQueueReceiver) Recv(ctx context.Context, data [][]byte, args Recv
 	return out, notify, nil
 }
 
+// forEachRights implements rightsReceiver.forEachRights.
+func (q *streamQueueReceiver) forEachRights(fn func(RightsControlMessage)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.control.Rights != nil {
+		fn(q.control.Rights)
+	}
+	q.queueReceiver.forEachRights(fn)
+}
+
+// purge implements rightsReceiver.purge.
+func (q *streamQueueReceiver) purge(ctx context.Context) {
+	q.mu.Lock()
+	c := q.control
+	q.buffer = nil
+	q.control = ControlMessages{}
+	q.mu.Unlock()
+	c.Release(ctx)
+	q.queueReceiver.purge(ctx)
+}
+
 // Release implements Receiver.Release.
 func (q *streamQueueReceiver) Release(ctx context.Context) {
 	q.queueReceiver.Release(ctx)
@@ -1107,6 +1158,26 @@ func (e *baseEndpoint) GetRemoteAddress() (Address, tcpip.Error) {
 	return Address{}, &tcpip.ErrNotConnected{}
 }
 
+// ForEachQueuedRights implements Endpoint.ForEachQueuedRights.
+func (e *baseEndpoint) ForEachQueuedRights(fn func(RightsControlMessage)) {
+	e.Lock()
+	r := e.receiver
+	e.Unlock()
+	if rr, ok := r.(rightsReceiver); ok {
+		rr.forEachRights(fn)
+	}
+}
+
+// PurgeRecvQueue implements Endpoint.PurgeRecvQueue.
+func (e *baseEndpoint) PurgeRecvQueue(ctx context.Context) {
+	e.Lock()
+	r := e.receiver
+	e.Unlock()
+	if rr, ok := r.(rightsReceiver); ok {
+		rr.purge(ctx)
+	}
+}
+
 // Release implements BoundEndpoint.Release.
 func (*baseEndpoint) Release(context.Context) {
 	// Binding a baseEndpoint doesn't take a reference.
@@ -135,6 +135,13 @@ func (s *Socket) Release(ctx context.Context) {
 	// Release only decrements a reference on s because s may be referenced in
 	// the abstract socket namespace.
 	s.DecRef(ctx)
+
+	// Releasing a socket may have left a cycle of sockets that are only
+	// referenced by each other's queued SCM_RIGHTS messages. See
+	// net/unix/af_unix.c:unix_release_sock.
+	if k := kernel.KernelFromContext(ctx); k != nil && !k.UnixInflight().Empty() {
+		collectGarbage(ctx, k)
+	}
 }
 
 // GetSockOpt implements the linux syscall getsockopt(2) for sockets backed by
@@ -645,6 +652,12 @@ func (s *Socket) Connect(t *kernel.Task, sockaddr []byte, blocking bool) *syserr
 // SendMsg implements the linux syscall sendmsg(2) for unix sockets backed by
 // a transport.Endpoint.
 func (s *Socket) SendMsg(t *kernel.Task, src usermem.IOSequence, to []byte, flags int, haveDeadline bool, deadline ktime.Time, controlMessages socket.ControlMessages) (int, *syserr.Error) {
+	if controlMessages.Unix.Rights != nil {
+		// See net/unix/garbage.c:wait_for_unix_gc.
+		if k := t.Kernel(); k.UnixInflight().ShouldCollect() {
+			collectGarbage(t, k)
+		}
+	}
 	w := EndpointWriter{
 		Ctx:      t,
 		Endpoint: s.ep,
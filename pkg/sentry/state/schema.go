@@ -0,0 +1,97 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"fmt"
+	"strconv"
+
+	"gvisor.dev/gvisor/pkg/context"
+	pkgstate "gvisor.dev/gvisor/pkg/state"
+)
+
+const (
+	// SchemaVersionKey is the metadata key to store and retrieve the schema
+	// version (see pkg/state.SchemaChange) of the saved kernel state.
+	SchemaVersionKey = "state_schema_version"
+
+	// MinSchemaVersion is the oldest schema version of saved state that can
+	// be restored by a different version of runsc. SchemaChanges with
+	// versions less than or equal to MinSchemaVersion are never applied, and
+	// may be deleted.
+	//
+	// State saved at schema version 0, i.e. before any SchemaChange was
+	// registered, doesn't record how it differs from the current types, so
+	// it can only be restored by the runsc version that saved it.
+	//
+	// This may only be advanced past the schema version of a release once
+	// that release is outside of the supported upgrade window documented in
+	// g3doc/user_guide/checkpoint_restore.md.
+	MinSchemaVersion pkgstate.SchemaVersion = 1
+)
+
+// addSchemaVersionMetadata records the current schema version in m.
+func addSchemaVersionMetadata(m map[string]string) {
+	m[SchemaVersionKey] = strconv.FormatUint(uint64(pkgstate.CurrentSchemaVersion()), 10)
+}
+
+// SavedSchemaVersion returns the schema version of the state described by the
+// given statefile metadata. It returns an error if that state is newer than
+// this version of the sentry.
+func SavedSchemaVersion(metadata map[string]string) (pkgstate.SchemaVersion, error) {
+	// State saved before schema versions were recorded has version 0.
+	var v pkgstate.SchemaVersion
+	if s, ok := metadata[SchemaVersionKey]; ok {
+		n, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid state schema version %q: %w", s, err)
+		}
+		v = pkgstate.SchemaVersion(n)
+	}
+	if cur := pkgstate.CurrentSchemaVersion(); v > cur {
+		return 0, fmt.Errorf("state schema version %d is newer than the current version %d", v, cur)
+	}
+	return v, nil
+}
+
+// CheckCrossVersionRestore returns an error if the state described by the
+// given statefile metadata, saved by a different version of runsc, can't be
+// restored by this version of the sentry.
+func CheckCrossVersionRestore(metadata map[string]string) error {
+	if pkgstate.CurrentSchemaVersion() < MinSchemaVersion {
+		return fmt.Errorf("this runsc version has no state schema version and can only restore its own checkpoints")
+	}
+	if _, ok := metadata[SchemaVersionKey]; !ok {
+		return fmt.Errorf("checkpoint has no state schema version and can only be restored by the runsc version that took it")
+	}
+	v, err := SavedSchemaVersion(metadata)
+	if err != nil {
+		return err
+	}
+	if v < MinSchemaVersion {
+		return fmt.Errorf("state schema version %d is older than the oldest supported version %d", v, MinSchemaVersion)
+	}
+	return nil
+}
+
+// WithSavedSchemaVersion returns a copy of ctx that causes state to be loaded
+// using the schema version recorded in the given statefile metadata.
+func WithSavedSchemaVersion(ctx context.Context, metadata map[string]string) (context.Context, error) {
+	v, err := SavedSchemaVersion(metadata)
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, pkgstate.CtxSavedSchemaVersion, v), nil
+}
@@ -149,6 +149,7 @@ func (opts *SaveOpts) Save(ctx context.Context, k *kernel.Kernel, w *watchdog.Wa
 		opts.Metadata[GvisorWallTimeKey] = wt.String()
 	}
 	addSaveMetadata(opts.Metadata)
+	addSchemaVersionMetadata(opts.Metadata)
 
 	// Open the statefile.
 	wc, err := statefile.NewWriter(opts.Destination, opts.Key, opts.Metadata) // transfers ownership of opts.Destination to wc if err == nil
@@ -204,7 +204,7 @@ var linuxAMD64 = SyscallMap{
 	176: makeSyscallInfo("delete_module", Hex, Hex),
 	177: makeSyscallInfo("get_kernel_syms", Hex),
 	// 178: query_module (only present in Linux < 2.6)
-	179: makeSyscallInfo("quotactl", Hex, Hex, Hex, Hex),
+	179: makeSyscallInfo("quotactl", Hex, Path, Hex, Hex),
 	180: makeSyscallInfo("nfsservctl", Hex, Hex, Hex),
 	// 181: getpmsg (not implemented in the Linux kernel)
 	// 182: putpmsg (not implemented in the Linux kernel)
@@ -373,8 +373,11 @@ var linuxAMD64 = SyscallMap{
 	434: makeSyscallInfo("pidfd_open", Hex, Hex),
 	435: makeSyscallInfo("clone3", Hex, Hex),
 	436: makeSyscallInfo("close_range", FD, FD, CloseRangeFlags),
+	437: makeSyscallInfo("openat2", FD, Path, Hex, Hex),
 	439: makeSyscallInfo("faccessat2", FD, Path, Oct, Hex),
 	441: makeSyscallInfo("epoll_pwait2", FD, EpollEvents, Hex, Timespec, SigSet),
+	442: makeSyscallInfo("mount_setattr", FD, Path, Hex, Hex, Hex),
+	443: makeSyscallInfo("quotactl_fd", FD, Hex, Hex, Hex),
 }
 
 func init() {
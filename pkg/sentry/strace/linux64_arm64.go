@@ -85,7 +85,7 @@ var linuxARM64 = SyscallMap{
 	57:  makeSyscallInfo("close", FD),
 	58:  makeSyscallInfo("vhangup"),
 	59:  makeSyscallInfo("pipe2", PipeFDs, Hex),
-	60:  makeSyscallInfo("quotactl", Hex, Hex, Hex, Hex),
+	60:  makeSyscallInfo("quotactl", Hex, Path, Hex, Hex),
 	61:  makeSyscallInfo("getdents64", FD, Hex, Hex),
 	62:  makeSyscallInfo("lseek", Hex, Hex, Hex),
 	63:  makeSyscallInfo("read", FD, ReadBuffer, Hex),
@@ -314,8 +314,11 @@ var linuxARM64 = SyscallMap{
 	434: makeSyscallInfo("pidfd_open", Hex, Hex),
 	435: makeSyscallInfo("clone3", Hex, Hex),
 	436: makeSyscallInfo("close_range", FD, FD, CloseRangeFlags),
+	437: makeSyscallInfo("openat2", FD, Path, Hex, Hex),
 	439: makeSyscallInfo("faccessat2", FD, Path, Oct, Hex),
 	441: makeSyscallInfo("epoll_pwait2", FD, EpollEvents, Hex, Timespec, SigSet),
+	442: makeSyscallInfo("mount_setattr", FD, Path, Hex, Hex, Hex),
+	443: makeSyscallInfo("quotactl_fd", FD, Hex, Hex, Hex),
 }
 
 func init() {
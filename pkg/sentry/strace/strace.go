@@ -550,6 +550,13 @@ func (i *SyscallInfo) post(t *kernel.Task, args arch.SyscallArguments, rval uint
 	}
 }
 
+// IsLogFormat returns true if format, as passed to log.Emitter.Emit, is the
+// format of a system call entry or exit logged by this package. These log
+// lines contain system call arguments, which may include sensitive data.
+func IsLogFormat(format string) bool {
+	return strings.Contains(format, "%s E %s(") || strings.Contains(format, "%s X %s(")
+}
+
 // printEntry prints the given system call entry.
 func (i *SyscallInfo) printEnter(t *kernel.Task, args arch.SyscallArguments) []string {
 	output := i.pre(t, args, LogMaximumSize)
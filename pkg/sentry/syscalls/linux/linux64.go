@@ -124,19 +124,19 @@ var AMD64 = &kernel.SyscallTable{
 		79:  syscalls.Supported("getcwd", Getcwd),
 		80:  syscalls.SupportedPoint("chdir", Chdir, PointChdir),
 		81:  syscalls.SupportedPoint("fchdir", Fchdir, PointFchdir),
-		82:  syscalls.Supported("rename", Rename),
+		82:  syscalls.SupportedPoint("rename", Rename, PointRename),
 		83:  syscalls.Supported("mkdir", Mkdir),
 		84:  syscalls.Supported("rmdir", Rmdir),
 		85:  syscalls.SupportedPoint("creat", Creat, PointCreat),
-		86:  syscalls.Supported("link", Link),
+		86:  syscalls.SupportedPoint("link", Link, PointLink),
 		87:  syscalls.Supported("unlink", Unlink),
 		88:  syscalls.Supported("symlink", Symlink),
 		89:  syscalls.Supported("readlink", Readlink),
-		90:  syscalls.Supported("chmod", Chmod),
-		91:  syscalls.Supported("fchmod", Fchmod),
-		92:  syscalls.Supported("chown", Chown),
-		93:  syscalls.Supported("fchown", Fchown),
-		94:  syscalls.Supported("lchown", Lchown),
+		90:  syscalls.SupportedPoint("chmod", Chmod, PointChmod),
+		91:  syscalls.SupportedPoint("fchmod", Fchmod, PointFchmod),
+		92:  syscalls.SupportedPoint("chown", Chown, PointChown),
+		93:  syscalls.SupportedPoint("fchown", Fchown, PointFchown),
+		94:  syscalls.SupportedPoint("lchown", Lchown, PointLchown),
 		95:  syscalls.Supported("umask", Umask),
 		96:  syscalls.Supported("gettimeofday", Gettimeofday),
 		97:  syscalls.Supported("getrlimit", Getrlimit),
@@ -207,8 +207,8 @@ var AMD64 = &kernel.SyscallTable{
 		162: syscalls.Supported("sync", Sync),
 		163: syscalls.CapError("acct", linux.CAP_SYS_PACCT, "", nil),
 		164: syscalls.CapError("settimeofday", linux.CAP_SYS_TIME, "", nil),
-		165: syscalls.Supported("mount", Mount),
-		166: syscalls.Supported("umount2", Umount2),
+		165: syscalls.SupportedPoint("mount", Mount, PointMount),
+		166: syscalls.SupportedPoint("umount2", Umount2, PointUmount2),
 		167: syscalls.CapError("swapon", linux.CAP_SYS_ADMIN, "", nil),
 		168: syscalls.CapError("swapoff", linux.CAP_SYS_ADMIN, "", nil),
 		169: syscalls.CapError("reboot", linux.CAP_SYS_BOOT, "", nil),
@@ -221,7 +221,7 @@ var AMD64 = &kernel.SyscallTable{
 		176: syscalls.CapError("delete_module", linux.CAP_SYS_MODULE, "", nil),
 		177: syscalls.Error("get_kernel_syms", linuxerr.ENOSYS, "Not supported in Linux > 2.6.", nil),
 		178: syscalls.Error("query_module", linuxerr.ENOSYS, "Not supported in Linux > 2.6.", nil),
-		179: syscalls.CapError("quotactl", linux.CAP_SYS_ADMIN, "", nil), // requires cap_sys_admin for most operations
+		179: syscalls.PartiallySupported("quotactl", Quotactl, "Only supported for tmpfs mounts with quota options, and overlays with such an upper layer. special must name the mount rather than a block device.", nil),
 		180: syscalls.Error("nfsservctl", linuxerr.ENOSYS, "Removed after Linux 3.1.", nil),
 		181: syscalls.Error("getpmsg", linuxerr.ENOSYS, "Not implemented in Linux.", nil),
 		182: syscalls.Error("putpmsg", linuxerr.ENOSYS, "Not implemented in Linux.", nil),
@@ -302,15 +302,15 @@ var AMD64 = &kernel.SyscallTable{
 		257: syscalls.SupportedPoint("openat", Openat, PointOpenat),
 		258: syscalls.Supported("mkdirat", Mkdirat),
 		259: syscalls.Supported("mknodat", Mknodat),
-		260: syscalls.Supported("fchownat", Fchownat),
+		260: syscalls.SupportedPoint("fchownat", Fchownat, PointFchownat),
 		261: syscalls.Supported("futimesat", Futimesat),
 		262: syscalls.Supported("newfstatat", Newfstatat),
 		263: syscalls.Supported("unlinkat", Unlinkat),
-		264: syscalls.Supported("renameat", Renameat),
-		265: syscalls.Supported("linkat", Linkat),
+		264: syscalls.SupportedPoint("renameat", Renameat, PointRenameat),
+		265: syscalls.SupportedPoint("linkat", Linkat, PointLinkat),
 		266: syscalls.Supported("symlinkat", Symlinkat),
 		267: syscalls.Supported("readlinkat", Readlinkat),
-		268: syscalls.Supported("fchmodat", Fchmodat),
+		268: syscalls.SupportedPoint("fchmodat", Fchmodat, PointFchmodat),
 		269: syscalls.Supported("faccessat", Faccessat),
 		270: syscalls.Supported("pselect6", Pselect6),
 		271: syscalls.Supported("ppoll", Ppoll),
@@ -340,7 +340,7 @@ var AMD64 = &kernel.SyscallTable{
 		295: syscalls.SupportedPoint("preadv", Preadv, PointPreadv),
 		296: syscalls.SupportedPoint("pwritev", Pwritev, PointPwritev),
 		297: syscalls.Supported("rt_tgsigqueueinfo", RtTgsigqueueinfo),
-		298: syscalls.ErrorWithEvent("perf_event_open", linuxerr.ENODEV, "No support for perf counters", nil),
+		298: syscalls.PartiallySupported("perf_event_open", PerfEventOpen, "Only software events are supported, without sampling.", nil),
 		299: syscalls.Supported("recvmmsg", RecvMMsg),
 		300: syscalls.ErrorWithEvent("fanotify_init", linuxerr.ENOSYS, "Needs CONFIG_FANOTIFY", nil),
 		301: syscalls.ErrorWithEvent("fanotify_mark", linuxerr.ENOSYS, "Needs CONFIG_FANOTIFY", nil),
@@ -358,7 +358,7 @@ var AMD64 = &kernel.SyscallTable{
 		313: syscalls.CapError("finit_module", linux.CAP_SYS_MODULE, "", nil),
 		314: syscalls.PartiallySupported("sched_setattr", SchedSetattr, "Stub implementation.", nil),
 		315: syscalls.PartiallySupported("sched_getattr", SchedGetattr, "Stub implementation.", nil),
-		316: syscalls.Supported("renameat2", Renameat2),
+		316: syscalls.SupportedPoint("renameat2", Renameat2, PointRenameat2),
 		317: syscalls.Supported("seccomp", Seccomp),
 		318: syscalls.Supported("getrandom", GetRandom),
 		319: syscalls.Supported("memfd_create", MemfdCreate),
@@ -395,9 +395,12 @@ var AMD64 = &kernel.SyscallTable{
 		434: syscalls.Supported("pidfd_open", PIDFDOpen),
 		435: syscalls.PartiallySupported("clone3", Clone3, "Options CLONE_NEWTIME, CLONE_SYSVSEM and SetTid are not supported.", nil),
 		436: syscalls.Supported("close_range", CloseRange),
+		437: syscalls.PartiallySupported("openat2", Openat2, "RESOLVE_CACHED is not supported and always fails with EAGAIN.", nil),
 		438: syscalls.Supported("pidfd_getfd", PIDFDGetFD),
 		439: syscalls.Supported("faccessat2", Faccessat2),
 		441: syscalls.Supported("epoll_pwait2", EpollPwait2),
+		442: syscalls.PartiallySupported("mount_setattr", MountSetattr, "MOUNT_ATTR_NODIRATIME, MOUNT_ATTR_IDMAP and MOUNT_ATTR_NOSYMFOLLOW are not supported.", nil),
+		443: syscalls.PartiallySupported("quotactl_fd", QuotactlFD, "Only supported for tmpfs mounts with quota options, and overlays with such an upper layer.", nil),
 	},
 	Emulate: map[hostarch.Addr]uintptr{
 		0xffffffffff600000: 96,  // vsyscall gettimeofday(2)
@@ -459,10 +462,10 @@ var ARM64 = &kernel.SyscallTable{
 		34:  syscalls.Supported("mkdirat", Mkdirat),
 		35:  syscalls.Supported("unlinkat", Unlinkat),
 		36:  syscalls.Supported("symlinkat", Symlinkat),
-		37:  syscalls.Supported("linkat", Linkat),
-		38:  syscalls.Supported("renameat", Renameat),
-		39:  syscalls.Supported("umount2", Umount2),
-		40:  syscalls.Supported("mount", Mount),
+		37:  syscalls.SupportedPoint("linkat", Linkat, PointLinkat),
+		38:  syscalls.SupportedPoint("renameat", Renameat, PointRenameat),
+		39:  syscalls.SupportedPoint("umount2", Umount2, PointUmount2),
+		40:  syscalls.SupportedPoint("mount", Mount, PointMount),
 		41:  syscalls.Supported("pivot_root", PivotRoot),
 		42:  syscalls.Error("nfsservctl", linuxerr.ENOSYS, "Removed after Linux 3.1.", nil),
 		43:  syscalls.Supported("statfs", Statfs),
@@ -474,15 +477,15 @@ var ARM64 = &kernel.SyscallTable{
 		49:  syscalls.SupportedPoint("chdir", Chdir, PointChdir),
 		50:  syscalls.SupportedPoint("fchdir", Fchdir, PointFchdir),
 		51:  syscalls.SupportedPoint("chroot", Chroot, PointChroot),
-		52:  syscalls.Supported("fchmod", Fchmod),
-		53:  syscalls.Supported("fchmodat", Fchmodat),
-		54:  syscalls.Supported("fchownat", Fchownat),
-		55:  syscalls.Supported("fchown", Fchown),
+		52:  syscalls.SupportedPoint("fchmod", Fchmod, PointFchmod),
+		53:  syscalls.SupportedPoint("fchmodat", Fchmodat, PointFchmodat),
+		54:  syscalls.SupportedPoint("fchownat", Fchownat, PointFchownat),
+		55:  syscalls.SupportedPoint("fchown", Fchown, PointFchown),
 		56:  syscalls.SupportedPoint("openat", Openat, PointOpenat),
 		57:  syscalls.SupportedPoint("close", Close, PointClose),
 		58:  syscalls.CapError("vhangup", linux.CAP_SYS_TTY_CONFIG, "", nil),
 		59:  syscalls.SupportedPoint("pipe2", Pipe2, PointPipe2),
-		60:  syscalls.CapError("quotactl", linux.CAP_SYS_ADMIN, "", nil), // requires cap_sys_admin for most operations
+		60:  syscalls.PartiallySupported("quotactl", Quotactl, "Only supported for tmpfs mounts with quota options, and overlays with such an upper layer. special must name the mount rather than a block device.", nil),
 		61:  syscalls.Supported("getdents64", Getdents64),
 		62:  syscalls.Supported("lseek", Lseek),
 		63:  syscalls.SupportedPoint("read", Read, PointRead),
@@ -663,7 +666,7 @@ var ARM64 = &kernel.SyscallTable{
 		238: syscalls.CapError("migrate_pages", linux.CAP_SYS_NICE, "", nil),
 		239: syscalls.CapError("move_pages", linux.CAP_SYS_NICE, "", nil), // requires cap_sys_nice (mostly)
 		240: syscalls.Supported("rt_tgsigqueueinfo", RtTgsigqueueinfo),
-		241: syscalls.ErrorWithEvent("perf_event_open", linuxerr.ENODEV, "No support for perf counters", nil),
+		241: syscalls.PartiallySupported("perf_event_open", PerfEventOpen, "Only software events are supported, without sampling.", nil),
 		242: syscalls.SupportedPoint("accept4", Accept4, PointAccept4),
 		243: syscalls.Supported("recvmmsg", RecvMMsg),
 		260: syscalls.Supported("wait4", Wait4),
@@ -682,7 +685,7 @@ var ARM64 = &kernel.SyscallTable{
 		273: syscalls.CapError("finit_module", linux.CAP_SYS_MODULE, "", nil),
 		274: syscalls.PartiallySupported("sched_setattr", SchedSetattr, "Stub implementation.", nil),
 		275: syscalls.PartiallySupported("sched_getattr", SchedGetattr, "Stub implementation.", nil),
-		276: syscalls.Supported("renameat2", Renameat2),
+		276: syscalls.SupportedPoint("renameat2", Renameat2, PointRenameat2),
 		277: syscalls.Supported("seccomp", Seccomp),
 		278: syscalls.Supported("getrandom", GetRandom),
 		279: syscalls.Supported("memfd_create", MemfdCreate),
@@ -717,9 +720,12 @@ var ARM64 = &kernel.SyscallTable{
 		434: syscalls.Supported("pidfd_open", PIDFDOpen),
 		435: syscalls.PartiallySupported("clone3", Clone3, "Options CLONE_NEWTIME, CLONE_SYSVSEM and clone_args.set_tid are not supported.", nil),
 		436: syscalls.Supported("close_range", CloseRange),
+		437: syscalls.PartiallySupported("openat2", Openat2, "RESOLVE_CACHED is not supported and always fails with EAGAIN.", nil),
 		438: syscalls.Supported("pidfd_getfd", PIDFDGetFD),
 		439: syscalls.Supported("faccessat2", Faccessat2),
 		441: syscalls.Supported("epoll_pwait2", EpollPwait2),
+		442: syscalls.PartiallySupported("mount_setattr", MountSetattr, "MOUNT_ATTR_NODIRATIME, MOUNT_ATTR_IDMAP and MOUNT_ATTR_NOSYMFOLLOW are not supported.", nil),
+		443: syscalls.PartiallySupported("quotactl_fd", QuotactlFD, "Only supported for tmpfs mounts with quota options, and overlays with such an upper layer.", nil),
 	},
 	Emulate: map[hostarch.Addr]uintptr{},
 	Missing: func(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr, error) {
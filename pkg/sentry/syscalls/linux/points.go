@@ -16,6 +16,8 @@ package linux
 
 import (
 	"fmt"
+	"path"
+	"strings"
 
 	"google.golang.org/protobuf/proto"
 	"gvisor.dev/gvisor/pkg/abi/linux"
@@ -1065,3 +1067,251 @@ func PointSocketpair(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.Conte
 	p.Exit = newExitMaybe(info)
 	return p, pb.MessageType_MESSAGE_SYSCALL_SOCKETPAIR
 }
+
+// copyInPointString copies in a string syscall argument for a trace point,
+// returning an empty string if addr is NULL or the copy fails.
+func copyInPointString(t *kernel.Task, addr hostarch.Addr, maxLen int) string {
+	if addr == 0 {
+		return ""
+	}
+	s, err := t.CopyInString(addr, maxLen)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// getResolvedPath returns pathname made absolute by resolving it against the
+// directory referred to by fd, or the working directory if fd is AT_FDCWD.
+// Symbolic links aren't followed, since the syscall itself may not follow
+// them. If pathname is empty, the path of fd is returned.
+func getResolvedPath(t *kernel.Task, fd int64, pathname string) string {
+	if path.IsAbs(pathname) {
+		return path.Clean(pathname)
+	}
+	var base string
+	if fd == linux.AT_FDCWD {
+		root := t.FSContext().RootDirectory()
+		if !root.Ok() {
+			return ""
+		}
+		defer root.DecRef(t)
+		wd := t.FSContext().WorkingDirectory()
+		if !wd.Ok() {
+			return ""
+		}
+		defer wd.DecRef(t)
+		var err error
+		base, err = t.Kernel().VFS().PathnameWithDeleted(t, root, wd)
+		if err != nil {
+			return fmt.Sprintf("[err: %v]", err)
+		}
+	} else {
+		base = getFilePath(t, int32(fd))
+		if strings.HasPrefix(base, "[err:") {
+			return base
+		}
+	}
+	if pathname == "" {
+		return base
+	}
+	return path.Join(base, pathname)
+}
+
+// pointRenameHelper converts rename(2), renameat(2) and renameat2(2) syscalls
+// to proto.
+func pointRenameHelper(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo, oldFD int64, oldAddr hostarch.Addr, newFD int64, newAddr hostarch.Addr, flags uint32) (proto.Message, pb.MessageType) {
+	p := &pb.Rename{
+		ContextData: cxtData,
+		Sysno:       uint64(info.Sysno),
+		OldFd:       oldFD,
+		OldPathname: copyInPointString(t, oldAddr, linux.PATH_MAX),
+		NewFd:       newFD,
+		NewPathname: copyInPointString(t, newAddr, linux.PATH_MAX),
+		Flags:       flags,
+	}
+
+	if fields.Local.Contains(seccheck.FieldSyscallPath) {
+		p.OldFdPath = getFilePath(t, int32(p.OldFd))
+		p.NewFdPath = getFilePath(t, int32(p.NewFd))
+	}
+	if fields.Local.Contains(seccheck.FieldSyscallResolvedPath) {
+		p.OldResolvedPath = getResolvedPath(t, p.OldFd, p.OldPathname)
+		p.NewResolvedPath = getResolvedPath(t, p.NewFd, p.NewPathname)
+	}
+
+	p.Exit = newExitMaybe(info)
+	return p, pb.MessageType_MESSAGE_SYSCALL_RENAME
+}
+
+// PointRename calls pointRenameHelper to convert rename(2) syscall to proto.
+func PointRename(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo) (proto.Message, pb.MessageType) {
+	return pointRenameHelper(t, fields, cxtData, info, linux.AT_FDCWD, info.Args[0].Pointer(), linux.AT_FDCWD, info.Args[1].Pointer(), 0)
+}
+
+// PointRenameat calls pointRenameHelper to convert renameat(2) syscall to
+// proto.
+func PointRenameat(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo) (proto.Message, pb.MessageType) {
+	return pointRenameHelper(t, fields, cxtData, info, int64(info.Args[0].Int()), info.Args[1].Pointer(), int64(info.Args[2].Int()), info.Args[3].Pointer(), 0)
+}
+
+// PointRenameat2 calls pointRenameHelper to convert renameat2(2) syscall to
+// proto.
+func PointRenameat2(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo) (proto.Message, pb.MessageType) {
+	return pointRenameHelper(t, fields, cxtData, info, int64(info.Args[0].Int()), info.Args[1].Pointer(), int64(info.Args[2].Int()), info.Args[3].Pointer(), info.Args[4].Uint())
+}
+
+// pointLinkHelper converts link(2) and linkat(2) syscalls to proto.
+func pointLinkHelper(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo, oldFD int64, oldAddr hostarch.Addr, newFD int64, newAddr hostarch.Addr, flags uint32) (proto.Message, pb.MessageType) {
+	p := &pb.Link{
+		ContextData: cxtData,
+		Sysno:       uint64(info.Sysno),
+		OldFd:       oldFD,
+		OldPathname: copyInPointString(t, oldAddr, linux.PATH_MAX),
+		NewFd:       newFD,
+		NewPathname: copyInPointString(t, newAddr, linux.PATH_MAX),
+		Flags:       flags,
+	}
+
+	if fields.Local.Contains(seccheck.FieldSyscallPath) {
+		p.OldFdPath = getFilePath(t, int32(p.OldFd))
+		p.NewFdPath = getFilePath(t, int32(p.NewFd))
+	}
+	if fields.Local.Contains(seccheck.FieldSyscallResolvedPath) {
+		p.OldResolvedPath = getResolvedPath(t, p.OldFd, p.OldPathname)
+		p.NewResolvedPath = getResolvedPath(t, p.NewFd, p.NewPathname)
+	}
+
+	p.Exit = newExitMaybe(info)
+	return p, pb.MessageType_MESSAGE_SYSCALL_LINK
+}
+
+// PointLink calls pointLinkHelper to convert link(2) syscall to proto.
+func PointLink(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo) (proto.Message, pb.MessageType) {
+	return pointLinkHelper(t, fields, cxtData, info, linux.AT_FDCWD, info.Args[0].Pointer(), linux.AT_FDCWD, info.Args[1].Pointer(), 0)
+}
+
+// PointLinkat calls pointLinkHelper to convert linkat(2) syscall to proto.
+func PointLinkat(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo) (proto.Message, pb.MessageType) {
+	return pointLinkHelper(t, fields, cxtData, info, int64(info.Args[0].Int()), info.Args[1].Pointer(), int64(info.Args[2].Int()), info.Args[3].Pointer(), info.Args[4].Uint())
+}
+
+// pointChmodHelper converts chmod(2), fchmod(2) and fchmodat(2) syscalls to
+// proto.
+func pointChmodHelper(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo, fd int64, path hostarch.Addr, mode uint) (proto.Message, pb.MessageType) {
+	p := &pb.Chmod{
+		ContextData: cxtData,
+		Sysno:       uint64(info.Sysno),
+		Fd:          fd,
+		Pathname:    copyInPointString(t, path, linux.PATH_MAX),
+		Mode:        uint32(mode),
+	}
+
+	if fields.Local.Contains(seccheck.FieldSyscallPath) {
+		p.FdPath = getFilePath(t, int32(p.Fd))
+	}
+	if fields.Local.Contains(seccheck.FieldSyscallResolvedPath) {
+		p.ResolvedPath = getResolvedPath(t, p.Fd, p.Pathname)
+	}
+
+	p.Exit = newExitMaybe(info)
+	return p, pb.MessageType_MESSAGE_SYSCALL_CHMOD
+}
+
+// PointChmod calls pointChmodHelper to convert chmod(2) syscall to proto.
+func PointChmod(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo) (proto.Message, pb.MessageType) {
+	return pointChmodHelper(t, fields, cxtData, info, linux.AT_FDCWD, info.Args[0].Pointer(), info.Args[1].ModeT())
+}
+
+// PointFchmod calls pointChmodHelper to convert fchmod(2) syscall to proto.
+func PointFchmod(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo) (proto.Message, pb.MessageType) {
+	return pointChmodHelper(t, fields, cxtData, info, int64(info.Args[0].Int()), 0, info.Args[1].ModeT())
+}
+
+// PointFchmodat calls pointChmodHelper to convert fchmodat(2) syscall to
+// proto.
+func PointFchmodat(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo) (proto.Message, pb.MessageType) {
+	return pointChmodHelper(t, fields, cxtData, info, int64(info.Args[0].Int()), info.Args[1].Pointer(), info.Args[2].ModeT())
+}
+
+// pointChownHelper converts chown(2), fchown(2), lchown(2) and fchownat(2)
+// syscalls to proto.
+func pointChownHelper(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo, fd int64, path hostarch.Addr, uid, gid, flags uint32) (proto.Message, pb.MessageType) {
+	p := &pb.Chown{
+		ContextData: cxtData,
+		Sysno:       uint64(info.Sysno),
+		Fd:          fd,
+		Pathname:    copyInPointString(t, path, linux.PATH_MAX),
+		Uid:         uid,
+		Gid:         gid,
+		Flags:       flags,
+	}
+
+	if fields.Local.Contains(seccheck.FieldSyscallPath) {
+		p.FdPath = getFilePath(t, int32(p.Fd))
+	}
+	if fields.Local.Contains(seccheck.FieldSyscallResolvedPath) {
+		p.ResolvedPath = getResolvedPath(t, p.Fd, p.Pathname)
+	}
+
+	p.Exit = newExitMaybe(info)
+	return p, pb.MessageType_MESSAGE_SYSCALL_CHOWN
+}
+
+// PointChown calls pointChownHelper to convert chown(2) syscall to proto.
+func PointChown(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo) (proto.Message, pb.MessageType) {
+	return pointChownHelper(t, fields, cxtData, info, linux.AT_FDCWD, info.Args[0].Pointer(), info.Args[1].Uint(), info.Args[2].Uint(), 0)
+}
+
+// PointLchown calls pointChownHelper to convert lchown(2) syscall to proto.
+func PointLchown(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo) (proto.Message, pb.MessageType) {
+	return pointChownHelper(t, fields, cxtData, info, linux.AT_FDCWD, info.Args[0].Pointer(), info.Args[1].Uint(), info.Args[2].Uint(), linux.AT_SYMLINK_NOFOLLOW)
+}
+
+// PointFchown calls pointChownHelper to convert fchown(2) syscall to proto.
+func PointFchown(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo) (proto.Message, pb.MessageType) {
+	return pointChownHelper(t, fields, cxtData, info, int64(info.Args[0].Int()), 0, info.Args[1].Uint(), info.Args[2].Uint(), 0)
+}
+
+// PointFchownat calls pointChownHelper to convert fchownat(2) syscall to
+// proto.
+func PointFchownat(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo) (proto.Message, pb.MessageType) {
+	return pointChownHelper(t, fields, cxtData, info, int64(info.Args[0].Int()), info.Args[1].Pointer(), info.Args[2].Uint(), info.Args[3].Uint(), info.Args[4].Uint())
+}
+
+// PointMount converts mount(2) syscall to proto. The data argument is
+// intentionally not collected, since it may contain credentials.
+func PointMount(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo) (proto.Message, pb.MessageType) {
+	// Like mount(2), copy in at most a page worth of data for the source and
+	// type. See fs/namespace.c:copy_mount_string().
+	p := &pb.Mount{
+		ContextData: cxtData,
+		Sysno:       uint64(info.Sysno),
+		Source:      copyInPointString(t, info.Args[0].Pointer(), hostarch.PageSize),
+		Target:      copyInPointString(t, info.Args[1].Pointer(), linux.PATH_MAX),
+		Fstype:      copyInPointString(t, info.Args[2].Pointer(), hostarch.PageSize),
+		Flags:       info.Args[3].Uint64(),
+	}
+	if fields.Local.Contains(seccheck.FieldSyscallResolvedPath) {
+		p.ResolvedTarget = getResolvedPath(t, linux.AT_FDCWD, p.Target)
+	}
+
+	p.Exit = newExitMaybe(info)
+	return p, pb.MessageType_MESSAGE_SYSCALL_MOUNT
+}
+
+// PointUmount2 converts umount2(2) syscall to proto.
+func PointUmount2(t *kernel.Task, fields seccheck.FieldSet, cxtData *pb.ContextData, info kernel.SyscallInfo) (proto.Message, pb.MessageType) {
+	p := &pb.Umount{
+		ContextData: cxtData,
+		Sysno:       uint64(info.Sysno),
+		Target:      copyInPointString(t, info.Args[0].Pointer(), linux.PATH_MAX),
+		Flags:       info.Args[1].Uint(),
+	}
+	if fields.Local.Contains(seccheck.FieldSyscallResolvedPath) {
+		p.ResolvedTarget = getResolvedPath(t, linux.AT_FDCWD, p.Target)
+	}
+
+	p.Exit = newExitMaybe(info)
+	return p, pb.MessageType_MESSAGE_SYSCALL_UMOUNT
+}
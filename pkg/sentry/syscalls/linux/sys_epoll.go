@@ -119,7 +119,10 @@ func EpollCtl(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintpt
 	}
 }
 
-func waitEpoll(t *kernel.Task, epfd int32, eventsAddr hostarch.Addr, maxEvents int, timeoutInNanos int64) (uintptr, *kernel.SyscallControl, error) {
+// waitEpoll implements epoll_wait(2) and its variants. If timeout is
+// negative, waitEpoll blocks until events are available or it is
+// interrupted.
+func waitEpoll(t *kernel.Task, epfd int32, eventsAddr hostarch.Addr, maxEvents int, timeout time.Duration) (uintptr, *kernel.SyscallControl, error) {
 	var _EP_MAX_EVENTS = math.MaxInt32 / sizeofEpollEvent // Linux: fs/eventpoll.c:EP_MAX_EVENTS
 	if maxEvents <= 0 || maxEvents > _EP_MAX_EVENTS {
 		return 0, nil, linuxerr.EINVAL
@@ -135,14 +138,23 @@ func waitEpoll(t *kernel.Task, epfd int32, eventsAddr hostarch.Addr, maxEvents i
 		return 0, nil, linuxerr.EINVAL
 	}
 
-	// Allocate space for a few events on the stack for the common case in
-	// which we don't have too many events.
+	// The deadline is computed before doing any work so that time spent
+	// registering with the epoll instance counts against the timeout.
 	var (
-		eventsArr    [16]linux.EpollEvent
-		ch           chan struct{}
 		haveDeadline bool
 		deadline     ktime.Time
 	)
+	if timeout > 0 {
+		deadline = t.Kernel().MonotonicClock().Now().Add(timeout)
+		haveDeadline = true
+	}
+
+	// Allocate space for a few events on the stack for the common case in
+	// which we don't have too many events.
+	var (
+		eventsArr [16]linux.EpollEvent
+		ch        chan struct{}
+	)
 	for {
 		events := ep.ReadEvents(eventsArr[:0], maxEvents)
 		if len(events) != 0 {
@@ -153,7 +165,7 @@ func waitEpoll(t *kernel.Task, epfd int32, eventsAddr hostarch.Addr, maxEvents i
 			}
 			return 0, nil, err
 		}
-		if timeoutInNanos == 0 {
+		if timeout == 0 {
 			return 0, nil, nil
 		}
 		// In the first iteration of this loop, register with the epoll
@@ -169,12 +181,6 @@ func waitEpoll(t *kernel.Task, epfd int32, eventsAddr hostarch.Addr, maxEvents i
 			}
 			defer epfile.EventUnregister(&w)
 		} else {
-			// Set up the timer if a timeout was specified.
-			if timeoutInNanos > 0 && !haveDeadline {
-				timeoutDur := time.Duration(timeoutInNanos) * time.Nanosecond
-				deadline = t.Kernel().MonotonicClock().Now().Add(timeoutDur)
-				haveDeadline = true
-			}
 			if err := t.BlockWithDeadline(ch, haveDeadline, deadline); err != nil {
 				if linuxerr.Equals(linuxerr.ETIMEDOUT, err) {
 					err = nil
@@ -191,9 +197,10 @@ func EpollWait(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintp
 	epfd := args[0].Int()
 	eventsAddr := args[1].Pointer()
 	maxEvents := int(args[2].Int())
-	timeoutInNanos := int64(args[3].Int()) * 1000000
+	// Any negative timeout means no timeout.
+	timeout := time.Duration(args[3].Int()) * time.Millisecond
 
-	return waitEpoll(t, epfd, eventsAddr, maxEvents, timeoutInNanos)
+	return waitEpoll(t, epfd, eventsAddr, maxEvents, timeout)
 }
 
 // EpollPwait implements Linux syscall epoll_pwait(2).
@@ -208,28 +215,26 @@ func EpollPwait(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uint
 	return EpollWait(t, sysno, args)
 }
 
-// EpollPwait2 implements Linux syscall epoll_pwait(2).
+// EpollPwait2 implements Linux syscall epoll_pwait2(2).
 func EpollPwait2(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr, *kernel.SyscallControl, error) {
 	epfd := args[0].Int()
 	eventsAddr := args[1].Pointer()
 	maxEvents := int(args[2].Int())
-	timeoutPtr := args[3].Pointer()
+	timeoutAddr := args[3].Pointer()
 	maskAddr := args[4].Pointer()
 	maskSize := uint(args[5].Uint())
-	haveTimeout := timeoutPtr != 0
 
-	var timeoutInNanos int64 = -1
-	if haveTimeout {
-		var timeout linux.Timespec
-		if _, err := timeout.CopyIn(t, timeoutPtr); err != nil {
-			return 0, nil, err
-		}
-		timeoutInNanos = timeout.ToNsec()
+	// Unlike epoll_pwait(2), the timeout has nanosecond resolution, and
+	// an invalid timespec is rejected rather than treated as infinite. See
+	// fs/eventpoll.c:do_epoll_pwait and fs/select.c:poll_select_set_timeout.
+	timeout, err := copyTimespecInToDuration(t, timeoutAddr)
+	if err != nil {
+		return 0, nil, err
 	}
 
 	if err := setTempSignalSet(t, maskAddr, maskSize); err != nil {
 		return 0, nil, err
 	}
 
-	return waitEpoll(t, epfd, eventsAddr, maxEvents, timeoutInNanos)
+	return waitEpoll(t, epfd, eventsAddr, maxEvents, timeout)
 }
@@ -115,8 +115,126 @@ func openat(t *kernel.Task, dirfd int32, pathAddr hostarch.Addr, flags uint32, m
 		return 0, nil, err
 	}
 	defer tpop.Release(t)
+	return openPathOperation(t, &tpop.pop, flags, mode)
+}
+
+// validOpenFlags is the set of flags accepted by openat2(2), from
+// include/linux/fcntl.h:VALID_OPEN_FLAGS.
+const validOpenFlags = linux.O_ACCMODE | linux.O_CREAT | linux.O_EXCL | linux.O_NOCTTY | linux.O_TRUNC | linux.O_APPEND | linux.O_NONBLOCK | linux.O_SYNC | linux.O_DSYNC | linux.O_ASYNC | linux.O_DIRECT | linux.O_LARGEFILE | linux.O_DIRECTORY | linux.O_NOFOLLOW | linux.O_NOATIME | linux.O_CLOEXEC | linux.O_PATH | linux.O_TMPFILE
+
+// oPathFlags is the set of flags that openat2(2) accepts with O_PATH, from
+// fs/open.c:O_PATH_FLAGS.
+const oPathFlags = linux.O_DIRECTORY | linux.O_NOFOLLOW | linux.O_PATH | linux.O_CLOEXEC
+
+// validResolveFlags is the set of flags accepted in open_how.resolve.
+const validResolveFlags = linux.RESOLVE_NO_XDEV | linux.RESOLVE_NO_MAGICLINKS | linux.RESOLVE_NO_SYMLINKS | linux.RESOLVE_BENEATH | linux.RESOLVE_IN_ROOT | linux.RESOLVE_CACHED
+
+// Openat2 implements Linux syscall openat2(2).
+func Openat2(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr, *kernel.SyscallControl, error) {
+	dirfd := args[0].Int()
+	pathAddr := args[1].Pointer()
+	howAddr := args[2].Pointer()
+	size := args[3].SizeT()
+
+	how, err := copyInOpenHow(t, howAddr, size)
+	if err != nil {
+		return 0, nil, err
+	}
+	// Unlike openat(2), unknown flags are rejected. See
+	// fs/open.c:build_open_flags().
+	if how.Flags&^validOpenFlags != 0 || how.Resolve&^validResolveFlags != 0 {
+		return 0, nil, linuxerr.EINVAL
+	}
+	flags := uint32(how.Flags)
+	if flags&linux.O_PATH != 0 && flags&^oPathFlags != 0 {
+		// Only flags meaningful for O_PATH file descriptors may be combined
+		// with O_PATH.
+		return 0, nil, linuxerr.EINVAL
+	}
+	if flags&(linux.O_CREAT|linux.O_TMPFILE) != 0 {
+		if how.Mode&^07777 != 0 {
+			return 0, nil, linuxerr.EINVAL
+		}
+	} else if how.Mode != 0 {
+		return 0, nil, linuxerr.EINVAL
+	}
+	scoped := how.Resolve & (linux.RESOLVE_BENEATH | linux.RESOLVE_IN_ROOT)
+	if scoped == linux.RESOLVE_BENEATH|linux.RESOLVE_IN_ROOT {
+		return 0, nil, linuxerr.EINVAL
+	}
+	if how.Resolve&linux.RESOLVE_CACHED != 0 {
+		// Path resolution can't be restricted to cached dentries. Linux also
+		// fails with EAGAIN whenever a lookup can't be completed from the
+		// cache, so callers must be prepared to retry without
+		// RESOLVE_CACHED.
+		return 0, nil, linuxerr.EAGAIN
+	}
+
+	path, err := copyInPath(t, pathAddr)
+	if err != nil {
+		return 0, nil, err
+	}
+	emptyPathCheck := disallowEmptyPath
+	if path.Absolute && scoped != 0 {
+		if scoped == linux.RESOLVE_BENEATH {
+			return 0, nil, linuxerr.EXDEV
+		}
+		// With RESOLVE_IN_ROOT, absolute paths are resolved relative to
+		// dirfd, and "/" refers to dirfd itself.
+		path.Absolute = false
+		emptyPathCheck = allowEmptyPath
+	}
+	// With RESOLVE_NO_XDEV, absolute paths start at the root regardless of
+	// dirfd's mount, as in fs/namei.c:nd_jump_root(). Path resolution then
+	// fails with EXDEV upon crossing a mount point (vfs.ResolvingPath.CheckMount)
+	// or jumping back to the root through a symlink from another mount.
+	tpop, err := getTaskPathOperation(t, dirfd, path, emptyPathCheck, shouldFollowFinalSymlink(flags&linux.O_NOFOLLOW == 0))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tpop.Release(t)
+	tpop.pop.ResolveFlags = how.Resolve
+	if scoped != 0 {
+		// Path resolution is scoped to the starting directory, which serves
+		// as the root.
+		tpop.pop.Root.DecRef(t)
+		tpop.pop.Root = tpop.pop.Start
+		tpop.pop.Root.IncRef()
+	}
+	return openPathOperation(t, &tpop.pop, flags, uint(how.Mode))
+}
+
+// copyInOpenHow copies in a struct open_how of the given size from addr.
+func copyInOpenHow(t *kernel.Task, addr hostarch.Addr, size uint) (linux.OpenHow, error) {
+	var how linux.OpenHow
+	if size < linux.OPEN_HOW_SIZE_VER0 {
+		return how, linuxerr.EINVAL
+	}
+	if size > hostarch.PageSize {
+		return how, linuxerr.E2BIG
+	}
+	if _, err := how.CopyIn(t, addr); err != nil {
+		return how, err
+	}
+	if size > linux.OPEN_HOW_SIZE_VER0 {
+		// Userspace has a newer struct version. Check that the fields we don't
+		// know about are zeroed out.
+		buf := make([]byte, size-linux.OPEN_HOW_SIZE_VER0)
+		if _, err := t.CopyInBytes(addr+linux.OPEN_HOW_SIZE_VER0, buf); err != nil {
+			return how, err
+		}
+		for _, b := range buf {
+			if b != 0 {
+				return how, linuxerr.E2BIG
+			}
+		}
+	}
+	return how, nil
+}
 
-	file, err := t.Kernel().VFS().OpenAt(t, t.Credentials(), &tpop.pop, &vfs.OpenOptions{
+// openPathOperation opens the file at pop and installs it in t's FD table.
+func openPathOperation(t *kernel.Task, pop *vfs.PathOperation, flags uint32, mode uint) (uintptr, *kernel.SyscallControl, error) {
+	file, err := t.Kernel().VFS().OpenAt(t, t.Credentials(), pop, &vfs.OpenOptions{
 		Flags: flags | linux.O_LARGEFILE,
 		Mode:  linux.FileMode(mode & (0777 | linux.S_ISUID | linux.S_ISGID | linux.S_ISVTX) &^ t.FSContext().Umask()),
 	})
@@ -16,6 +16,7 @@ package linux
 
 import (
 	"fmt"
+	"math/bits"
 
 	"gvisor.dev/gvisor/pkg/abi/linux"
 	"gvisor.dev/gvisor/pkg/errors/linuxerr"
@@ -25,12 +26,10 @@ import (
 	"gvisor.dev/gvisor/pkg/usermem"
 )
 
-// We unconditionally report a single NUMA node. This also means that our
-// "nodemask_t" is a single unsigned long (uint64).
-const (
-	maxNodes        = 1
-	allowedNodemask = (1 << maxNodes) - 1
-)
+// We report at most kernel.MaxNUMANodes virtual NUMA nodes, so our
+// "nodemask_t" is a single unsigned long (uint64). Since the sentry does not
+// control which host memory backs application pages, policies are recorded
+// but have no effect on placement.
 
 func copyInNodemask(t *kernel.Task, addr hostarch.Addr, maxnode uint32) (uint64, error) {
 	// "nodemask points to a bit mask of node IDs that contains up to maxnode
@@ -57,7 +56,7 @@ func copyInNodemask(t *kernel.Task, addr hostarch.Addr, maxnode uint32) (uint64,
 	val := hostarch.ByteOrder.Uint64(buf)
 	// Check that only allowed bits in the first unsigned long in the nodemask
 	// are set.
-	if val&^allowedNodemask != 0 {
+	if val&^t.Kernel().NUMANodeMask() != 0 {
 		return 0, linuxerr.EINVAL
 	}
 	// Check that all remaining bits in the nodemask are 0.
@@ -117,7 +116,7 @@ func GetMempolicy(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (ui
 
 	// "EINVAL: The value specified by maxnode is less than the number of node
 	// IDs supported by the system." - get_mempolicy(2)
-	if nodemask != 0 && maxnode < maxNodes {
+	if nodemask != 0 && maxnode < uint32(t.Kernel().NUMANodes()) {
 		return 0, nil, linuxerr.EINVAL
 	}
 
@@ -131,7 +130,7 @@ func GetMempolicy(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (ui
 		if nodeFlag || addrFlag {
 			return 0, nil, linuxerr.EINVAL
 		}
-		if err := copyOutNodemask(t, nodemask, maxnode, allowedNodemask); err != nil {
+		if err := copyOutNodemask(t, nodemask, maxnode, t.Kernel().NUMANodeMask()); err != nil {
 			return 0, nil, err
 		}
 		return 0, nil, nil
@@ -162,7 +161,10 @@ func GetMempolicy(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (ui
 			if err != nil {
 				return 0, nil, err
 			}
-			policy = linux.MPOL_DEFAULT // maxNodes == 1
+			// We don't know where the host placed the page, so report the
+			// node local to the calling thread, as if it had been allocated
+			// on first touch.
+			policy = linux.NumaPolicy(t.Kernel().CPUNode(t.CPU()))
 		}
 		if mode != 0 {
 			if _, err := policy.CopyOut(t, mode); err != nil {
@@ -199,7 +201,9 @@ func GetMempolicy(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (ui
 		if policy&^linux.MPOL_MODE_FLAGS != linux.MPOL_INTERLEAVE {
 			return 0, nil, linuxerr.EINVAL
 		}
-		policy = linux.MPOL_DEFAULT // maxNodes == 1
+		// Interleaving is not implemented, so the next node is always the
+		// first node in the policy's nodemask.
+		policy = linux.NumaPolicy(bits.TrailingZeros64(nodemaskVal))
 	}
 	if mode != 0 {
 		if _, err := policy.CopyOut(t, mode); err != nil {
@@ -252,8 +256,8 @@ func Mbind(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr,
 		return 0, nil, err
 	}
 
-	// Since we claim to have only a single node, all flags can be ignored
-	// (since all pages must already be on that single node).
+	// Since policies don't affect page placement, all flags can be ignored
+	// (since there is nothing to migrate).
 	err = t.MemoryManager().SetNumaPolicy(addr, length, mode, nodemaskVal)
 	return 0, nil, err
 }
@@ -47,10 +47,25 @@ func Mmap(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr, *
 	flags := args[3].Int()
 	fd := args[4].Int()
 	fixed := flags&linux.MAP_FIXED != 0
+	noReplace := flags&linux.MAP_FIXED_NOREPLACE != 0
 	private := flags&linux.MAP_PRIVATE != 0
 	shared := flags&linux.MAP_SHARED != 0
 	anon := flags&linux.MAP_ANONYMOUS != 0
 	map32bit := flags&linux.MAP_32BIT != 0
+	droppable := flags&linux.MAP_DROPPABLE != 0
+
+	if droppable {
+		// MAP_DROPPABLE is a mapping type of its own, exclusive with
+		// MAP_PRIVATE and MAP_SHARED, and only applies to anonymous
+		// memory. Droppable memory may be discarded under memory pressure
+		// and is wiped in child processes; the sentry never discards it,
+		// so it behaves like a private anonymous mapping with
+		// MADV_WIPEONFORK. Compare Linux's mm/mmap.c:do_mmap().
+		if private || shared || !anon {
+			return 0, nil, linuxerr.EINVAL
+		}
+		private = true
+	}
 
 	// Require exactly one of MAP_PRIVATE and MAP_SHARED.
 	if private == shared {
@@ -61,8 +76,8 @@ func Mmap(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr, *
 		Length:   args[1].Uint64(),
 		Offset:   args[5].Uint64(),
 		Addr:     args[0].Pointer(),
-		Fixed:    fixed,
-		Unmap:    fixed,
+		Fixed:    fixed || noReplace,
+		Unmap:    fixed && !noReplace,
 		Map32Bit: map32bit,
 		Private:  private,
 		Perms: hostarch.AccessType{
@@ -70,9 +85,11 @@ func Mmap(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr, *
 			Write:   linux.PROT_WRITE&prot != 0,
 			Execute: linux.PROT_EXEC&prot != 0,
 		},
-		MaxPerms:  hostarch.AnyAccess,
-		GrowsDown: linux.MAP_GROWSDOWN&flags != 0,
-		Stack:     linux.MAP_STACK&flags != 0,
+		MaxPerms:   hostarch.AnyAccess,
+		GrowsDown:  linux.MAP_GROWSDOWN&flags != 0,
+		Stack:      linux.MAP_STACK&flags != 0,
+		NoReplace:  noReplace,
+		WipeOnFork: droppable,
 	}
 	if linux.MAP_POPULATE&flags != 0 {
 		opts.PlatformEffect = memmap.PlatformEffectCommit
@@ -106,7 +123,7 @@ func Mmap(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr, *
 
 		if shared {
 			// Check for any seal violations.
-			if seals, err := tmpfs.GetSeals(file); err == nil && seals&linux.F_SEAL_WRITE != 0 {
+			if seals, err := tmpfs.GetSeals(file); err == nil && seals&(linux.F_SEAL_WRITE|linux.F_SEAL_FUTURE_WRITE) != 0 {
 				if opts.Perms.Write {
 					// Writable shared mapping on write-sealed file is not allowed.
 					return 0, nil, linuxerr.EPERM
@@ -168,11 +185,17 @@ func Mremap(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr,
 	flags := args[3].Uint64()
 	newAddr := args[4].Pointer()
 
-	if flags&^(linux.MREMAP_MAYMOVE|linux.MREMAP_FIXED) != 0 {
+	if flags&^(linux.MREMAP_MAYMOVE|linux.MREMAP_FIXED|linux.MREMAP_DONTUNMAP) != 0 {
 		return 0, nil, linuxerr.EINVAL
 	}
 	mayMove := flags&linux.MREMAP_MAYMOVE != 0
 	fixed := flags&linux.MREMAP_FIXED != 0
+	dontUnmap := flags&linux.MREMAP_DONTUNMAP != 0
+	// "MREMAP_DONTUNMAP ... can be used only with MREMAP_MAYMOVE ... old_size
+	// must be the same as new_size." - mremap(2)
+	if dontUnmap && (!mayMove || oldSize != newSize) {
+		return 0, nil, linuxerr.EINVAL
+	}
 	var moveMode mm.MRemapMoveMode
 	switch {
 	case !mayMove && !fixed:
@@ -188,8 +211,9 @@ func Mremap(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr,
 	}
 
 	rv, err := t.MemoryManager().MRemap(t, oldAddr, oldSize, newSize, mm.MRemapOpts{
-		Move:    moveMode,
-		NewAddr: newAddr,
+		Move:      moveMode,
+		NewAddr:   newAddr,
+		DontUnmap: dontUnmap,
 	})
 	return uintptr(rv), nil, err
 }
@@ -219,6 +243,10 @@ func Madvise(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr
 		return 0, nil, t.MemoryManager().SetDontFork(addr, length, false)
 	case linux.MADV_DONTFORK:
 		return 0, nil, t.MemoryManager().SetDontFork(addr, length, true)
+	case linux.MADV_WIPEONFORK:
+		return 0, nil, t.MemoryManager().SetWipeOnFork(addr, length, true)
+	case linux.MADV_KEEPONFORK:
+		return 0, nil, t.MemoryManager().SetWipeOnFork(addr, length, false)
 	case linux.MADV_HUGEPAGE, linux.MADV_NOHUGEPAGE:
 		fallthrough
 	case linux.MADV_MERGEABLE, linux.MADV_UNMERGEABLE:
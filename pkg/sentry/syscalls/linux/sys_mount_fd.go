@@ -391,3 +391,112 @@ func OpenTree(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintpt
 
 	return uintptr(fd), nil, err
 }
+
+// mountSetattrValidAttrFlags are the attributes accepted by mount_setattr(2).
+// MOUNT_ATTR_NODIRATIME, MOUNT_ATTR_IDMAP and MOUNT_ATTR_NOSYMFOLLOW are valid
+// in Linux but are not supported by gVisor; they may only be cleared, which is
+// a no-op since they are never set.
+const mountSetattrValidAttrFlags = linux.MOUNT_ATTR_RDONLY | linux.MOUNT_ATTR_NOSUID | linux.MOUNT_ATTR_NODEV | linux.MOUNT_ATTR_NOEXEC | linux.MOUNT_ATTR__ATIME | linux.MOUNT_ATTR_NODIRATIME | linux.MOUNT_ATTR_IDMAP | linux.MOUNT_ATTR_NOSYMFOLLOW
+
+// MountSetattr implements Linux syscall mount_setattr(2).
+func MountSetattr(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr, *kernel.SyscallControl, error) {
+	dirfd := args[0].Int()
+	pathAddr := args[1].Pointer()
+	flags := args[2].Uint()
+	attrAddr := args[3].Pointer()
+	size := args[4].SizeT()
+
+	if flags&^(linux.AT_EMPTY_PATH|linux.AT_SYMLINK_NOFOLLOW|linux.AT_RECURSIVE|linux.AT_NO_AUTOMOUNT) != 0 {
+		return 0, nil, linuxerr.EINVAL
+	}
+	attr, err := copyInMountAttr(t, attrAddr, size)
+	if err != nil {
+		return 0, nil, err
+	}
+	// Don't bother looking up the mount if this is a no-op.
+	if attr.AttrSet == 0 && attr.AttrClr == 0 && attr.Propagation == 0 {
+		return 0, nil, nil
+	}
+
+	switch attr.Propagation {
+	case 0, linux.MS_SHARED, linux.MS_PRIVATE, linux.MS_SLAVE, linux.MS_UNBINDABLE:
+	default:
+		return 0, nil, linuxerr.EINVAL
+	}
+	if (attr.AttrSet|attr.AttrClr)&^mountSetattrValidAttrFlags != 0 {
+		return 0, nil, linuxerr.EINVAL
+	}
+	// The atime attributes are an enum rather than a bitmask, so changing
+	// them requires clearing all of MOUNT_ATTR__ATIME.
+	if attr.AttrClr&linux.MOUNT_ATTR__ATIME != 0 {
+		if attr.AttrClr&linux.MOUNT_ATTR__ATIME != linux.MOUNT_ATTR__ATIME {
+			return 0, nil, linuxerr.EINVAL
+		}
+		switch attr.AttrSet & linux.MOUNT_ATTR__ATIME {
+		case linux.MOUNT_ATTR_RELATIME, linux.MOUNT_ATTR_NOATIME, linux.MOUNT_ATTR_STRICTATIME:
+		default:
+			return 0, nil, linuxerr.EINVAL
+		}
+	} else if attr.AttrSet&linux.MOUNT_ATTR__ATIME != 0 {
+		return 0, nil, linuxerr.EINVAL
+	}
+	// Idmapped mounts can't be undone in Linux either.
+	if attr.AttrClr&linux.MOUNT_ATTR_IDMAP != 0 {
+		return 0, nil, linuxerr.EINVAL
+	}
+	if attr.AttrSet&(linux.MOUNT_ATTR_NODIRATIME|linux.MOUNT_ATTR_IDMAP|linux.MOUNT_ATTR_NOSYMFOLLOW) != 0 {
+		t.Kernel().EmitUnimplementedEvent(t, sysno)
+		return 0, nil, linuxerr.EINVAL
+	}
+
+	// Must have CAP_SYS_ADMIN in the current mount namespace's associated user
+	// namespace.
+	creds := t.Credentials()
+	if !creds.HasCapabilityIn(linux.CAP_SYS_ADMIN, t.MountNamespace().Owner) {
+		return 0, nil, linuxerr.EPERM
+	}
+
+	path, err := copyInPath(t, pathAddr)
+	if err != nil {
+		return 0, nil, err
+	}
+	tpop, err := getTaskPathOperation(t, dirfd, path, shouldAllowEmptyPath(flags&linux.AT_EMPTY_PATH != 0), shouldFollowFinalSymlink(flags&linux.AT_SYMLINK_NOFOLLOW == 0))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tpop.Release(t)
+
+	return 0, nil, t.Kernel().VFS().SetMountAttrAt(t, creds, &tpop.pop, &vfs.SetMountAttrOptions{
+		Set:         attr.AttrSet,
+		Clear:       attr.AttrClr,
+		Propagation: uint32(attr.Propagation),
+		Recursive:   flags&linux.AT_RECURSIVE != 0,
+	})
+}
+
+// copyInMountAttr copies in a struct mount_attr of the given size, following
+// the extensible struct rules of copy_struct_from_user() in Linux.
+func copyInMountAttr(t *kernel.Task, addr hostarch.Addr, size uint) (linux.MountAttr, error) {
+	var attr linux.MountAttr
+	if size < linux.MOUNT_ATTR_SIZE_VER0 {
+		return attr, linuxerr.EINVAL
+	}
+	if size > hostarch.PageSize {
+		return attr, linuxerr.E2BIG
+	}
+	if _, err := attr.CopyIn(t, addr); err != nil {
+		return attr, err
+	}
+	if size > linux.MOUNT_ATTR_SIZE_VER0 {
+		buf := make([]byte, size-linux.MOUNT_ATTR_SIZE_VER0)
+		if _, err := t.CopyInBytes(addr+linux.MOUNT_ATTR_SIZE_VER0, buf); err != nil {
+			return attr, err
+		}
+		for _, b := range buf {
+			if b != 0 {
+				return attr, linuxerr.E2BIG
+			}
+		}
+	}
+	return attr, nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linux
+
+import (
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/hostarch"
+	"gvisor.dev/gvisor/pkg/marshal/primitive"
+	"gvisor.dev/gvisor/pkg/sentry/arch"
+	"gvisor.dev/gvisor/pkg/sentry/fsimpl/perfevent"
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
+)
+
+// perfEventOpenFlags is the set of flags accepted by perf_event_open(2).
+const perfEventOpenFlags = linux.PERF_FLAG_FD_NO_GROUP | linux.PERF_FLAG_FD_OUTPUT | linux.PERF_FLAG_PID_CGROUP | linux.PERF_FLAG_FD_CLOEXEC
+
+// PerfEventOpen implements Linux syscall perf_event_open(2).
+func PerfEventOpen(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr, *kernel.SyscallControl, error) {
+	attrAddr := args[0].Pointer()
+	pid := args[1].Int()
+	cpu := args[2].Int()
+	groupFD := args[3].Int()
+	flags := args[4].Uint64()
+
+	if flags&^perfEventOpenFlags != 0 {
+		return 0, nil, linuxerr.EINVAL
+	}
+	attr, err := copyInPerfEventAttr(t, attrAddr)
+	if err != nil {
+		return 0, nil, err
+	}
+	// Bits above sigtrap are reserved.
+	if attr.Flags>>38 != 0 || attr.ReadFormat&^(linux.PERF_FORMAT_MAX-1) != 0 {
+		return 0, nil, linuxerr.EINVAL
+	}
+	if pid == -1 && cpu == -1 {
+		return 0, nil, linuxerr.EINVAL
+	}
+	if cpu < -1 || cpu >= int32(t.Kernel().ApplicationCores()) {
+		return 0, nil, linuxerr.EINVAL
+	}
+
+	// Only software events, whose counters the sentry maintains, are
+	// supported. Linux fails with ENOENT for events that no PMU provides.
+	if attr.Type != linux.PERF_TYPE_SOFTWARE || !perfevent.Supported(attr.Config) {
+		return 0, nil, linuxerr.ENOENT
+	}
+	// Sampling requires overflow interrupts, which Linux also refuses with
+	// EOPNOTSUPP for PMUs that lack them.
+	if attr.SamplePeriod != 0 {
+		t.Kernel().EmitUnimplementedEvent(t, sysno)
+		return 0, nil, linuxerr.EOPNOTSUPP
+	}
+	if flags&linux.PERF_FLAG_PID_CGROUP != 0 || pid == -1 {
+		// System-wide and cgroup events require CAP_PERFMON.
+		if !t.HasRootCapability(linux.CAP_PERFMON) && !t.HasRootCapability(linux.CAP_SYS_ADMIN) {
+			return 0, nil, linuxerr.EACCES
+		}
+		t.Kernel().EmitUnimplementedEvent(t, sysno)
+		return 0, nil, linuxerr.EOPNOTSUPP
+	}
+
+	target := t
+	if pid != 0 {
+		target = t.PIDNamespace().TaskWithID(kernel.ThreadID(pid))
+		if target == nil {
+			return 0, nil, linuxerr.ESRCH
+		}
+		if target != t && !t.CanTrace(target, false) {
+			return 0, nil, linuxerr.EACCES
+		}
+	}
+
+	var leader *perfevent.Event
+	if groupFD != -1 && flags&linux.PERF_FLAG_FD_NO_GROUP == 0 {
+		leaderFile := t.GetFile(groupFD)
+		if leaderFile == nil {
+			return 0, nil, linuxerr.EBADF
+		}
+		defer leaderFile.DecRef(t)
+		var ok bool
+		leader, ok = leaderFile.Impl().(*perfevent.Event)
+		if !ok || !leader.IsLeader() || leader.Target() != target {
+			return 0, nil, linuxerr.EINVAL
+		}
+	}
+
+	file, err := perfevent.New(t, t.Kernel().VFS(), &attr, target, leader, linux.O_RDWR)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer file.DecRef(t)
+	fd, err := t.NewFDFrom(0, file, kernel.FDFlags{
+		CloseOnExec: flags&linux.PERF_FLAG_FD_CLOEXEC != 0,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return uintptr(fd), nil, nil
+}
+
+// copyInPerfEventAttr copies in a struct perf_event_attr, whose size is given
+// by its size field, following perf_copy_attr() in Linux.
+func copyInPerfEventAttr(t *kernel.Task, addr hostarch.Addr) (linux.PerfEventAttr, error) {
+	var attr linux.PerfEventAttr
+	var size primitive.Uint32
+	// size follows the 4-byte type field.
+	if _, err := size.CopyIn(t, addr+4); err != nil {
+		return attr, err
+	}
+	if size == 0 {
+		size = linux.PERF_ATTR_SIZE_VER0
+	}
+	if size < linux.PERF_ATTR_SIZE_VER0 || size > hostarch.PageSize {
+		return attr, perfEventAttrSizeError(t, addr)
+	}
+	buf := make([]byte, max(int(size), attr.SizeBytes()))
+	if _, err := t.CopyInBytes(addr, buf[:size]); err != nil {
+		return attr, err
+	}
+	for _, b := range buf[attr.SizeBytes():] {
+		if b != 0 {
+			return attr, perfEventAttrSizeError(t, addr)
+		}
+	}
+	attr.UnmarshalBytes(buf)
+	attr.Size = uint32(size)
+	return attr, nil
+}
+
+// perfEventAttrSizeError reports the supported size of struct perf_event_attr
+// back to the application, as Linux does for attributes with invalid sizes.
+func perfEventAttrSizeError(t *kernel.Task, addr hostarch.Addr) error {
+	size := primitive.Uint32(linux.PERF_ATTR_SIZE_VER8)
+	if _, err := size.CopyOut(t, addr+4); err != nil {
+		return err
+	}
+	return linuxerr.E2BIG
+}
@@ -114,6 +114,16 @@ func pollBlock(t *kernel.Task, pfd []linux.PollFD, timeout time.Duration) (time.
 		ch = make(chan struct{}, 1)
 	}
 
+	// The deadline is computed before registering for events so that time
+	// spent doing so counts against the timeout, and so that spurious
+	// notifications don't extend it.
+	clock := t.Kernel().MonotonicClock()
+	haveTimeout := timeout > 0
+	var deadline ktime.Time
+	if haveTimeout {
+		deadline = clock.Now().Add(timeout)
+	}
+
 	// Register for event notification in the files involved if we may
 	// block (timeout not zero). Once we find a file that has a non-zero
 	// result, we stop registering for events but still go through all files
@@ -135,12 +145,15 @@ func pollBlock(t *kernel.Task, pfd []linux.PollFD, timeout time.Duration) (time.
 		return timeout, n, nil
 	}
 
-	haveTimeout := timeout >= 0
-
 	for n == 0 {
-		var err error
 		// Wait for a notification.
-		timeout, err = t.BlockWithTimeout(ch, haveTimeout, timeout)
+		err := t.BlockWithDeadline(ch, haveTimeout, deadline)
+		if haveTimeout {
+			timeout = deadline.Sub(clock.Now())
+			if timeout < 0 || linuxerr.Equals(linuxerr.ETIMEDOUT, err) {
+				timeout = 0
+			}
+		}
 		if err != nil {
 			if linuxerr.Equals(linuxerr.ETIMEDOUT, err) {
 				err = nil
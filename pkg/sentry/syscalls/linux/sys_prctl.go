@@ -19,6 +19,7 @@ import (
 
 	"gvisor.dev/gvisor/pkg/abi/linux"
 	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/hostarch"
 	"gvisor.dev/gvisor/pkg/marshal/primitive"
 	"gvisor.dev/gvisor/pkg/sentry/arch"
 	"gvisor.dev/gvisor/pkg/sentry/kernel"
@@ -132,34 +133,39 @@ func Prctl(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr,
 		}
 
 	case linux.PR_SET_MM:
+		opt := args[1].Int()
+		addr := args[2].Pointer()
+		// Only some operations take a fourth argument, and none take a fifth;
+		// see kernel/sys.c:prctl_set_mm().
+		if args[4].Uint64() != 0 || (args[3].Uint64() != 0 && opt != linux.PR_SET_MM_AUXV && opt != linux.PR_SET_MM_MAP && opt != linux.PR_SET_MM_MAP_SIZE) {
+			return 0, nil, linuxerr.EINVAL
+		}
+		if opt == linux.PR_SET_MM_MAP || opt == linux.PR_SET_MM_MAP_SIZE {
+			return 0, nil, prctlSetMMMap(t, opt, addr, args[3].Uint())
+		}
 		if !t.HasRootCapability(linux.CAP_SYS_RESOURCE) {
 			return 0, nil, linuxerr.EPERM
 		}
 
-		switch args[1].Int() {
+		switch opt {
 		case linux.PR_SET_MM_EXE_FILE:
-			fd := args[2].Int()
-
-			file := t.GetFile(fd)
-			if file == nil {
-				return 0, nil, linuxerr.EBADF
-			}
-			defer file.DecRef(t)
-
-			// They trying to set exe to a non-file?
-			stat, err := file.Stat(t, vfs.StatOptions{Mask: linux.STATX_TYPE})
+			file, err := getMMExeFile(t, args[2].Int())
 			if err != nil {
 				return 0, nil, err
 			}
-			if stat.Mask&linux.STATX_TYPE == 0 || stat.Mode&linux.FileTypeMask != linux.ModeRegular {
-				return 0, nil, linuxerr.EBADF
-			}
+			defer file.DecRef(t)
 
 			// Set the underlying executable.
 			t.MemoryManager().SetExecutable(t, file)
 
-		case linux.PR_SET_MM_AUXV,
-			linux.PR_SET_MM_START_CODE,
+		case linux.PR_SET_MM_AUXV:
+			auxv, err := copyInAuxv(t, addr, args[3].Uint64())
+			if err != nil {
+				return 0, nil, err
+			}
+			t.MemoryManager().SetAuxv(auxv)
+
+		case linux.PR_SET_MM_START_CODE,
 			linux.PR_SET_MM_END_CODE,
 			linux.PR_SET_MM_START_DATA,
 			linux.PR_SET_MM_END_DATA,
@@ -171,8 +177,33 @@ func Prctl(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr,
 			linux.PR_SET_MM_ENV_START,
 			linux.PR_SET_MM_ENV_END:
 
-			t.Kernel().EmitUnimplementedEvent(t, sysno)
-			fallthrough
+			return 0, nil, t.MemoryManager().UpdateLayout(t, func(l *mm.Layout) {
+				switch opt {
+				case linux.PR_SET_MM_START_CODE:
+					l.StartCode = addr
+				case linux.PR_SET_MM_END_CODE:
+					l.EndCode = addr
+				case linux.PR_SET_MM_START_DATA:
+					l.StartData = addr
+				case linux.PR_SET_MM_END_DATA:
+					l.EndData = addr
+				case linux.PR_SET_MM_START_STACK:
+					l.StartStack = addr
+				case linux.PR_SET_MM_START_BRK:
+					l.StartBrk = addr
+				case linux.PR_SET_MM_BRK:
+					l.Brk = addr
+				case linux.PR_SET_MM_ARG_START:
+					l.ArgStart = addr
+				case linux.PR_SET_MM_ARG_END:
+					l.ArgEnd = addr
+				case linux.PR_SET_MM_ENV_START:
+					l.EnvStart = addr
+				case linux.PR_SET_MM_ENV_END:
+					l.EnvEnd = addr
+				}
+			})
+
 		default:
 			return 0, nil, linuxerr.EINVAL
 		}
@@ -348,14 +379,19 @@ func Prctl(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr,
 		}
 		return linux.PR_TAGGED_ADDR_ENABLE, nil, nil
 
+	case linux.PR_SET_TIMERSLACK:
+		t.SetTimerSlack(args[1].Uint64())
+		return 0, nil, nil
+
+	case linux.PR_GET_TIMERSLACK:
+		return uintptr(t.TimerSlack()), nil, nil
+
 	case linux.PR_GET_TIMING,
 		linux.PR_SET_TIMING,
 		linux.PR_GET_TSC,
 		linux.PR_SET_TSC,
 		linux.PR_TASK_PERF_EVENTS_DISABLE,
 		linux.PR_TASK_PERF_EVENTS_ENABLE,
-		linux.PR_GET_TIMERSLACK,
-		linux.PR_SET_TIMERSLACK,
 		linux.PR_MCE_KILL,
 		linux.PR_MCE_KILL_GET,
 		linux.PR_GET_TID_ADDRESS,
@@ -372,3 +408,116 @@ func Prctl(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr,
 
 	return 0, nil, nil
 }
+
+// getMMExeFile returns the file referred to by fd, which must be a regular
+// file, for use as the executable of t's MemoryManager. The caller must
+// release the returned reference.
+func getMMExeFile(t *kernel.Task, fd int32) (*vfs.FileDescription, error) {
+	file := t.GetFile(fd)
+	if file == nil {
+		return nil, linuxerr.EBADF
+	}
+
+	// They trying to set exe to a non-file?
+	stat, err := file.Stat(t, vfs.StatOptions{Mask: linux.STATX_TYPE})
+	if err != nil {
+		file.DecRef(t)
+		return nil, err
+	}
+	if stat.Mask&linux.STATX_TYPE == 0 || stat.Mode&linux.FileTypeMask != linux.ModeRegular {
+		file.DecRef(t)
+		return nil, linuxerr.EBADF
+	}
+	return file, nil
+}
+
+// copyInAuxv copies in an auxiliary vector of size bytes at addr, as for
+// prctl(PR_SET_MM, PR_SET_MM_AUXV). The returned vector ends before the first
+// AT_NULL entry.
+func copyInAuxv(t *kernel.Task, addr hostarch.Addr, size uint64) (arch.Auxv, error) {
+	if addr == 0 || size > linux.AT_VECTOR_SIZE*8 {
+		return nil, linuxerr.EINVAL
+	}
+	buf := make([]byte, size)
+	if _, err := t.CopyInBytes(addr, buf); err != nil {
+		return nil, err
+	}
+	// Linux always overwrites the last entry of the saved vector with
+	// AT_NULL, so at most AT_VECTOR_SIZE/2 - 1 entries are kept.
+	var auxv arch.Auxv
+	for len(buf) >= 16 && len(auxv) < linux.AT_VECTOR_SIZE/2-1 {
+		key := hostarch.ByteOrder.Uint64(buf)
+		if key == linux.AT_NULL {
+			break
+		}
+		auxv = append(auxv, arch.AuxEntry{key, hostarch.Addr(hostarch.ByteOrder.Uint64(buf[8:]))})
+		buf = buf[16:]
+	}
+	return auxv, nil
+}
+
+// prctlSetMMMap implements prctl(PR_SET_MM, PR_SET_MM_MAP) and
+// prctl(PR_SET_MM, PR_SET_MM_MAP_SIZE), which checkpoint/restore tools use to
+// replace the whole memory map descriptor at once.
+//
+// See kernel/sys.c:prctl_set_mm_map().
+func prctlSetMMMap(t *kernel.Task, opt int32, addr hostarch.Addr, size uint32) error {
+	if opt == linux.PR_SET_MM_MAP_SIZE {
+		_, err := primitive.CopyUint32Out(t, addr, uint32(linux.SizeOfPrctlMMMap))
+		return err
+	}
+	if int(size) != linux.SizeOfPrctlMMMap {
+		return linuxerr.EINVAL
+	}
+	var prctlMap linux.PrctlMMMap
+	if _, err := prctlMap.CopyIn(t, addr); err != nil {
+		return err
+	}
+
+	var auxv arch.Auxv
+	if prctlMap.AuxvSize != 0 {
+		var err error
+		if auxv, err = copyInAuxv(t, hostarch.Addr(prctlMap.Auxv), uint64(prctlMap.AuxvSize)); err != nil {
+			return err
+		}
+	}
+
+	// An exe_fd of -1 leaves the executable unchanged.
+	var exe *vfs.FileDescription
+	if prctlMap.ExeFD != ^uint32(0) {
+		if !t.HasSelfCapability(linux.CAP_CHECKPOINT_RESTORE) && !t.HasSelfCapability(linux.CAP_SYS_ADMIN) {
+			return linuxerr.EPERM
+		}
+		var err error
+		if exe, err = getMMExeFile(t, int32(prctlMap.ExeFD)); err != nil {
+			return err
+		}
+		defer exe.DecRef(t)
+	}
+
+	m := t.MemoryManager()
+	if err := m.UpdateLayout(t, func(l *mm.Layout) {
+		*l = mm.Layout{
+			StartCode:  hostarch.Addr(prctlMap.StartCode),
+			EndCode:    hostarch.Addr(prctlMap.EndCode),
+			StartData:  hostarch.Addr(prctlMap.StartData),
+			EndData:    hostarch.Addr(prctlMap.EndData),
+			StartBrk:   hostarch.Addr(prctlMap.StartBrk),
+			Brk:        hostarch.Addr(prctlMap.Brk),
+			StartStack: hostarch.Addr(prctlMap.StartStack),
+			ArgStart:   hostarch.Addr(prctlMap.ArgStart),
+			ArgEnd:     hostarch.Addr(prctlMap.ArgEnd),
+			EnvStart:   hostarch.Addr(prctlMap.EnvStart),
+			EnvEnd:     hostarch.Addr(prctlMap.EnvEnd),
+		}
+	}); err != nil {
+		return err
+	}
+	if prctlMap.AuxvSize != 0 {
+		m.SetAuxv(auxv)
+	}
+	if exe != nil {
+		m.SetExecutable(t, exe)
+	}
+	return nil
+}
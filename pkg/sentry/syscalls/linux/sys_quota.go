@@ -0,0 +1,218 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linux
+
+import (
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/hostarch"
+	"gvisor.dev/gvisor/pkg/marshal/primitive"
+	"gvisor.dev/gvisor/pkg/sentry/arch"
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
+	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+)
+
+// Quotactl implements Linux syscall quotactl(2).
+//
+// gVisor has no block devices, so special must instead name a file on the
+// filesystem to operate on, as for quotactl_fd(2).
+func Quotactl(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr, *kernel.SyscallControl, error) {
+	cmd := args[0].Uint()
+	specialAddr := args[1].Pointer()
+	id := args[2].Uint()
+	addr := args[3].Pointer()
+
+	subcmd := cmd >> linux.SUBCMDSHIFT
+	if specialAddr == 0 {
+		// Q_SYNC with a NULL special syncs all filesystems; quota state is
+		// never written back anywhere, so there is nothing to do.
+		if subcmd == linux.Q_SYNC {
+			return 0, nil, nil
+		}
+		return 0, nil, linuxerr.EFAULT
+	}
+	path, err := copyInPath(t, specialAddr)
+	if err != nil {
+		return 0, nil, err
+	}
+	tpop, err := getTaskPathOperation(t, linux.AT_FDCWD, path, disallowEmptyPath, followFinalSymlink)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tpop.Release(t)
+	vd, err := t.Kernel().VFS().GetDentryAt(t, t.Credentials(), &tpop.pop, &vfs.GetDentryOptions{})
+	if err != nil {
+		return 0, nil, err
+	}
+	defer vd.DecRef(t)
+	return 0, nil, quotactl(t, vd, cmd, id, addr)
+}
+
+// QuotactlFD implements Linux syscall quotactl_fd(2).
+func QuotactlFD(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr, *kernel.SyscallControl, error) {
+	fd := args[0].Int()
+	cmd := args[1].Uint()
+	id := args[2].Uint()
+	addr := args[3].Pointer()
+
+	file := t.GetFile(fd)
+	if file == nil {
+		return 0, nil, linuxerr.EBADF
+	}
+	defer file.DecRef(t)
+	return 0, nil, quotactl(t, file.VirtualDentry(), cmd, id, addr)
+}
+
+// quotactl performs quotactl command cmd on the filesystem containing vd.
+// Compare Linux's fs/quota/quota.c:do_quotactl().
+func quotactl(t *kernel.Task, vd vfs.VirtualDentry, cmd, id uint32, addr hostarch.Addr) error {
+	subcmd := cmd >> linux.SUBCMDSHIFT
+	qtype := cmd & linux.SUBCMDMASK
+	if qtype >= linux.MAXQUOTAS {
+		return linuxerr.EINVAL
+	}
+	if err := checkQuotactlPermission(t, subcmd, qtype, id); err != nil {
+		return err
+	}
+
+	// Linux returns ENOSYS for filesystems without quota support. However,
+	// gVisor's filesystems are generally backed by host filesystems that may
+	// support quotas, and applications treat ESRCH ("quotas are not enabled")
+	// more gracefully, so return that instead.
+	var qs *vfs.QuotaSet
+	if qfs, ok := vd.Mount().Filesystem().Impl().(vfs.QuotaFilesystem); ok {
+		qs = qfs.Quotas()
+	}
+	if qs == nil {
+		return linuxerr.ESRCH
+	}
+
+	creds := t.Credentials()
+	now := t.Kernel().RealtimeClock().Now().Seconds()
+	switch subcmd {
+	case linux.Q_SYNC:
+		return nil
+
+	case linux.Q_QUOTAON, linux.Q_QUOTAOFF:
+		return qs.SetEnforced(qtype, subcmd == linux.Q_QUOTAON)
+
+	case linux.Q_GETFMT:
+		format, err := qs.Format(qtype)
+		if err != nil {
+			return err
+		}
+		_, err = primitive.CopyUint32Out(t, addr, format)
+		return err
+
+	case linux.Q_GETINFO:
+		info, err := qs.GetInfo(qtype)
+		if err != nil {
+			return err
+		}
+		_, err = info.CopyOut(t, addr)
+		return err
+
+	case linux.Q_SETINFO:
+		var info linux.IfDqinfo
+		if _, err := info.CopyIn(t, addr); err != nil {
+			return err
+		}
+		return qs.SetInfo(qtype, &info)
+
+	case linux.Q_GETQUOTA:
+		kid, err := quotaKID(creds, qtype, id)
+		if err != nil {
+			return err
+		}
+		dqb, err := qs.GetQuota(qtype, kid)
+		if err != nil {
+			return err
+		}
+		_, err = dqb.CopyOut(t, addr)
+		return err
+
+	case linux.Q_GETNEXTQUOTA:
+		kid, err := quotaKID(creds, qtype, id)
+		if err != nil {
+			return err
+		}
+		dqb, err := qs.GetNextQuota(qtype, kid)
+		if err != nil {
+			return err
+		}
+		if qtype == linux.USRQUOTA {
+			dqb.ID = uint32(creds.UserNamespace.MapFromKUID(auth.KUID(dqb.ID)).OrOverflow())
+		} else {
+			dqb.ID = uint32(creds.UserNamespace.MapFromKGID(auth.KGID(dqb.ID)).OrOverflow())
+		}
+		_, err = dqb.CopyOut(t, addr)
+		return err
+
+	case linux.Q_SETQUOTA:
+		kid, err := quotaKID(creds, qtype, id)
+		if err != nil {
+			return err
+		}
+		var dqb linux.IfDqblk
+		if _, err := dqb.CopyIn(t, addr); err != nil {
+			return err
+		}
+		return qs.SetQuota(now, qtype, kid, &dqb)
+
+	default:
+		return linuxerr.EINVAL
+	}
+}
+
+// checkQuotactlPermission returns an error if the calling task may not
+// perform quotactl subcommand subcmd for the given quota type and ID.
+// Compare Linux's fs/quota/quota.c:check_quotactl_permission().
+func checkQuotactlPermission(t *kernel.Task, subcmd, qtype, id uint32) error {
+	creds := t.Credentials()
+	switch subcmd {
+	case linux.Q_GETFMT, linux.Q_SYNC, linux.Q_GETINFO:
+		return nil
+	case linux.Q_GETQUOTA:
+		if qtype == linux.USRQUOTA && creds.EffectiveKUID == creds.UserNamespace.MapToKUID(auth.UID(id)) {
+			return nil
+		}
+		if qtype == linux.GRPQUOTA && creds.InGroup(creds.UserNamespace.MapToKGID(auth.GID(id))) {
+			return nil
+		}
+	}
+	// Quotas apply to the whole filesystem, so, as in Linux, privileged
+	// subcommands require CAP_SYS_ADMIN in the root user namespace.
+	if !t.HasRootCapability(linux.CAP_SYS_ADMIN) {
+		return linuxerr.EPERM
+	}
+	return nil
+}
+
+// quotaKID returns the KUID or KGID, depending on qtype, that id represents
+// in creds' user namespace.
+func quotaKID(creds *auth.Credentials, qtype, id uint32) (uint32, error) {
+	switch qtype {
+	case linux.USRQUOTA:
+		if kuid := creds.UserNamespace.MapToKUID(auth.UID(id)); kuid.Ok() {
+			return uint32(kuid), nil
+		}
+	case linux.GRPQUOTA:
+		if kgid := creds.UserNamespace.MapToKGID(auth.GID(id)); kgid.Ok() {
+			return uint32(kgid), nil
+		}
+	}
+	return 0, linuxerr.EINVAL
+}
@@ -19,7 +19,6 @@ import (
 
 	"gvisor.dev/gvisor/pkg/errors/linuxerr"
 	"gvisor.dev/gvisor/pkg/hostarch"
-	"gvisor.dev/gvisor/pkg/rand"
 	"gvisor.dev/gvisor/pkg/safemem"
 	"gvisor.dev/gvisor/pkg/sentry/arch"
 	"gvisor.dev/gvisor/pkg/sentry/kernel"
@@ -39,6 +38,9 @@ const (
 // possible. The urandom pool is also expected to have plenty of entropy, thus
 // the GRND_RANDOM and GRND_INSECURE flags are ignored. The GRND_NONBLOCK flag
 // does not apply, as the pool will already be initialized.
+//
+// Data is read from the kernel's EntropyPool, which is also used by the VDSO
+// implementation of getrandom to seed its per-thread state.
 func GetRandom(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr, *kernel.SyscallControl, error) {
 	addr := args[0].Pointer()
 	length := args[1].SizeT()
@@ -57,7 +59,7 @@ func GetRandom(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintp
 		return 0, nil, linuxerr.EFAULT
 	}
 
-	n, err := t.MemoryManager().CopyOutFrom(t, hostarch.AddrRangeSeqOf(ar), safemem.FromIOReader{rand.Reader}, usermem.IOOpts{})
+	n, err := t.MemoryManager().CopyOutFrom(t, hostarch.AddrRangeSeqOf(ar), safemem.FromIOReader{t.Kernel().EntropyPool()}, usermem.IOOpts{})
 	if n > 0 {
 		return uintptr(n), nil, nil
 	}
@@ -45,8 +45,11 @@ func getrusage(t *kernel.Task, which int32) linux.Rusage {
 	return linux.Rusage{
 		UTime:  linux.NsecToTimeval(cs.UserTime.Nanoseconds()),
 		STime:  linux.NsecToTimeval(cs.SysTime.Nanoseconds()),
-		NVCSw:  int64(cs.VoluntarySwitches),
 		MaxRSS: int64(t.MaxRSS(which) / 1024),
+		MinFlt: int64(cs.MinorFaults),
+		MajFlt: int64(cs.MajorFaults),
+		NVCSw:  int64(cs.VoluntarySwitches),
+		NIvCSw: int64(cs.InvoluntarySwitches),
 	}
 }
 
@@ -62,8 +65,8 @@ func getrusage(t *kernel.Task, which int32) linux.Rusage {
 //	*    long   ru_ixrss;         /* integral shared memory size */
 //	*    long   ru_idrss;         /* integral unshared data size */
 //	*    long   ru_isrss;         /* integral unshared stack size */
-//	p    long   ru_minflt;        /* page reclaims (soft page faults) */
-//	p    long   ru_majflt;        /* page faults (hard page faults) */
+//	y    long   ru_minflt;        /* page reclaims (soft page faults) */
+//	y    long   ru_majflt;        /* page faults (hard page faults) */
 //	*    long   ru_nswap;         /* swaps */
 //	p    long   ru_inblock;       /* block input operations */
 //	p    long   ru_oublock;       /* block output operations */
@@ -71,7 +74,7 @@ func getrusage(t *kernel.Task, which int32) linux.Rusage {
 //	*    long   ru_msgrcv;        /* IPC messages received */
 //	*    long   ru_nsignals;      /* signals received */
 //	y    long   ru_nvcsw;         /* voluntary context switches */
-//	p    long   ru_nivcsw;        /* involuntary context switches */
+//	y    long   ru_nivcsw;        /* involuntary context switches */
 func Getrusage(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr, *kernel.SyscallControl, error) {
 	which := args[0].Int()
 	addr := args[1].Pointer()
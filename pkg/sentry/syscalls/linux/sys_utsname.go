@@ -23,7 +23,7 @@ import (
 
 // Uname implements linux syscall uname.
 func Uname(t *kernel.Task, sysno uintptr, args arch.SyscallArguments) (uintptr, *kernel.SyscallControl, error) {
-	version := t.SyscallTable().Version
+	version := t.KernelVersion()
 
 	uts := t.UTSNamespace()
 
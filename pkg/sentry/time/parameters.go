@@ -16,6 +16,7 @@ package time
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"gvisor.dev/gvisor/pkg/log"
@@ -104,6 +105,22 @@ func (p Parameters) ComputeTime(nowCycles TSCValue) (int64, bool) {
 	return int64(uint64(p.BaseRef) + diffNS), ok
 }
 
+// CyclesToNanoseconds returns the number of nanoseconds that elapse during
+// cycles TSC cycles of a cycle clock with the given frequency in Hertz.
+//
+// It returns !ok if cycles is negative, frequency is zero, or the result does
+// not fit in 64 bits.
+func CyclesToNanoseconds(cycles TSCValue, frequency uint64) (int64, bool) {
+	if cycles < 0 {
+		return 0, false
+	}
+	ns, ok := muldiv64(uint64(cycles), uint64(time.Second.Nanoseconds()), frequency)
+	if !ok || ns > math.MaxInt64 {
+		return 0, false
+	}
+	return int64(ns), true
+}
+
 // errorAdjust returns a new Parameters struct "adjusted" that satisfies:
 //
 // 1. adjusted.ComputeTime(now) = prevParams.ComputeTime(now)
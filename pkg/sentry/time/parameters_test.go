@@ -431,6 +431,27 @@ func TestMulDivZero(t *testing.T) {
 	}
 }
 
+func TestCyclesToNanoseconds(t *testing.T) {
+	for _, tc := range []struct {
+		cycles    TSCValue
+		frequency uint64
+		ns        int64
+		ok        bool
+	}{
+		{cycles: 0, frequency: 2000000000, ns: 0, ok: true},
+		{cycles: 2000000000, frequency: 2000000000, ns: int64(time.Second), ok: true},
+		{cycles: 3, frequency: 2, ns: 1500000000, ok: true},
+		{cycles: -1, frequency: 2000000000, ok: false},
+		{cycles: 1, frequency: 0, ok: false},
+		{cycles: math.MaxInt64, frequency: 1, ok: false},
+	} {
+		ns, ok := CyclesToNanoseconds(tc.cycles, tc.frequency)
+		if ok != tc.ok || ns != tc.ns {
+			t.Errorf("CyclesToNanoseconds(%d, %d) = %d, %t, want %d, %t", tc.cycles, tc.frequency, ns, ok, tc.ns, tc.ok)
+		}
+	}
+}
+
 func TestMulDivOverflow(t *testing.T) {
 	testCases := []struct {
 		name string
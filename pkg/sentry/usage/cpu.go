@@ -16,8 +16,19 @@ package usage
 
 import (
 	"time"
+
+	"gvisor.dev/gvisor/pkg/state"
 )
 
+func init() {
+	// The added counts start at zero when loading state that doesn't include
+	// them.
+	state.RegisterSchemaChange("pkg/sentry/usage.CPUStats", state.SchemaChange{
+		Version:     3,
+		AddedFields: []string{"InvoluntarySwitches", "MinorFaults", "MajorFaults", "BlkioDelay"},
+	})
+}
+
 // CPUStats contains the subset of struct rusage fields that relate to CPU
 // scheduling.
 //
@@ -33,9 +44,23 @@ type CPUStats struct {
 	// ceded due to blocking, etc.
 	VoluntarySwitches uint64
 
-	// InvoluntarySwitches (struct rusage::ru_nivcsw) is unsupported, since
-	// "preemptive" scheduling is managed by the Go runtime, which doesn't
-	// provide this information.
+	// InvoluntarySwitches is the number of times application code has been
+	// preempted by the sentry because more tasks were runnable than the
+	// sandbox has CPUs. Preemption by the Go runtime or the host kernel
+	// isn't visible to the sentry and isn't counted.
+	InvoluntarySwitches uint64
+
+	// MinorFaults is the number of application page faults handled by the
+	// sentry without blocking.
+	MinorFaults uint64
+
+	// MajorFaults is the number of application page faults during which the
+	// sentry blocked, e.g. to read file contents.
+	MajorFaults uint64
+
+	// BlkioDelay is the amount of time spent in uninterruptible sleep, which
+	// is mostly waiting for file I/O.
+	BlkioDelay time.Duration
 }
 
 // Accumulate adds s2 to s.
@@ -43,6 +68,10 @@ func (s *CPUStats) Accumulate(s2 CPUStats) {
 	s.UserTime += s2.UserTime
 	s.SysTime += s2.SysTime
 	s.VoluntarySwitches += s2.VoluntarySwitches
+	s.InvoluntarySwitches += s2.InvoluntarySwitches
+	s.MinorFaults += s2.MinorFaults
+	s.MajorFaults += s2.MajorFaults
+	s.BlkioDelay += s2.BlkioDelay
 }
 
 // DifferenceSince computes s - earlierSample.
@@ -50,8 +79,12 @@ func (s *CPUStats) Accumulate(s2 CPUStats) {
 // Precondition: s >= earlierSample.
 func (s *CPUStats) DifferenceSince(earlierSample CPUStats) CPUStats {
 	return CPUStats{
-		UserTime:          s.UserTime - earlierSample.UserTime,
-		SysTime:           s.SysTime - earlierSample.SysTime,
-		VoluntarySwitches: s.VoluntarySwitches - earlierSample.VoluntarySwitches,
+		UserTime:            s.UserTime - earlierSample.UserTime,
+		SysTime:             s.SysTime - earlierSample.SysTime,
+		VoluntarySwitches:   s.VoluntarySwitches - earlierSample.VoluntarySwitches,
+		InvoluntarySwitches: s.InvoluntarySwitches - earlierSample.InvoluntarySwitches,
+		MinorFaults:         s.MinorFaults - earlierSample.MinorFaults,
+		MajorFaults:         s.MajorFaults - earlierSample.MajorFaults,
+		BlkioDelay:          s.BlkioDelay - earlierSample.BlkioDelay,
 	}
 }
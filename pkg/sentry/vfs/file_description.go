@@ -573,9 +573,8 @@ func (fd *FileDescription) Stat(ctx context.Context, opts StatOptions) (linux.St
 	} else {
 		stat, err = fd.impl.Stat(ctx, opts)
 	}
-	if err == nil && opts.Mask&linux.STATX_MNT_ID != 0 {
-		stat.MntID = fd.vd.mount.ID
-		stat.Mask |= linux.STATX_MNT_ID
+	if err == nil {
+		fd.vd.mount.statMntIDTo(opts.Mask, &stat)
 	}
 	return stat, err
 }
@@ -533,6 +533,43 @@ type TarSerializer interface {
 	TarUpperLayer(ctx context.Context, outFD *os.File) error
 }
 
+// UpperLayerUsageReporter is an interface for reporting the space used by
+// the writable upper layer of a filesystem. It is an extension of
+// FilesystemImpl.
+type UpperLayerUsageReporter interface {
+	// UpperLayerUsage returns the space and inodes used by the writable upper
+	// layer of the filesystem.
+	UpperLayerUsage(ctx context.Context) (UpperLayerUsage, error)
+
+	// UpperLayerLargestFiles returns up to n regular files in the writable
+	// upper layer of the filesystem that use the most space, in decreasing
+	// order of space used.
+	UpperLayerLargestFiles(ctx context.Context, n int) ([]FileUsage, error)
+}
+
+// UpperLayerUsage is returned by UpperLayerUsageReporter.UpperLayerUsage.
+type UpperLayerUsage struct {
+	// Bytes is the number of bytes used to store file data.
+	Bytes uint64 `json:"bytes"`
+
+	// Inodes is the number of inodes.
+	Inodes uint64 `json:"inodes"`
+}
+
+// FileUsage describes the space used by a single file.
+type FileUsage struct {
+	// Path is the path to the file, relative to the root of the filesystem.
+	// If the file has multiple links, Path is one of them.
+	Path string `json:"path"`
+
+	// Size is the file size.
+	Size uint64 `json:"size"`
+
+	// Bytes is the number of bytes used to store the file's data, which may
+	// be less than Size for sparse files.
+	Bytes uint64 `json:"bytes"`
+}
+
 // PrependPathAtVFSRootError is returned by implementations of
 // FilesystemImpl.PrependPath() when they encounter the contextual VFS root.
 //
@@ -573,6 +610,14 @@ type HostFDProvider interface {
 	HostFD() int
 }
 
+// MountReleaser is an interface for filesystems that keep state about
+// individual mounts.
+type MountReleaser interface {
+	// ReleaseMount is called when a Mount of the filesystem reaches zero
+	// references, so that state about it can be discarded.
+	ReleaseMount(ctx context.Context, mnt *Mount)
+}
+
 // MountRootPathProvider is an interface for filesystems that customize the
 // root path of a mount as displayed in /proc/<pid>/mountinfo.
 type MountRootPathProvider interface {
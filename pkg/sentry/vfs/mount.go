@@ -97,6 +97,10 @@ type Mount struct {
 	// isShared indicates this mount has the MS_SHARED propagation type.
 	isShared bool
 
+	// isUnbindable indicates this mount has the MS_UNBINDABLE propagation
+	// type. It is mutually exclusive with isShared.
+	isUnbindable bool
+
 	// sharedEntry is an entry in a circular list (ring) of mounts in a shared
 	// peer group.
 	sharedEntry mountEntry
@@ -198,6 +202,20 @@ func (mnt *Mount) MountFlags() uint64 {
 	return flags
 }
 
+// statMntIDTo sets the mount ID in stat if it is requested by mask. Mount IDs
+// are never reused, so the same ID is returned for STATX_MNT_ID_UNIQUE, which
+// takes precedence as in Linux.
+func (mnt *Mount) statMntIDTo(mask uint32, stat *linux.Statx) {
+	switch {
+	case mask&linux.STATX_MNT_ID_UNIQUE != 0:
+		stat.MntID = mnt.ID
+		stat.Mask = (stat.Mask &^ linux.STATX_MNT_ID) | linux.STATX_MNT_ID_UNIQUE
+	case mask&linux.STATX_MNT_ID != 0:
+		stat.MntID = mnt.ID
+		stat.Mask = (stat.Mask &^ linux.STATX_MNT_ID_UNIQUE) | linux.STATX_MNT_ID
+	}
+}
+
 func (mnt *Mount) isFollower() bool {
 	return mnt.leader != nil
 }
@@ -524,6 +542,12 @@ func (vfs *VirtualFilesystem) MoveMountAt(ctx context.Context, creds *auth.Crede
 		}
 	}
 
+	// Don't move a tree containing unbindable mounts under a shared mount,
+	// which would propagate them.
+	if mp.mount.isShared && sourceMnt.treeContainsUnbindableLocked() {
+		return linuxerr.EINVAL
+	}
+
 	// Verify that source is not an ancestor of destination
 	for p := mp.mount; p != nil; p = p.parent() {
 		if p == sourceMnt {
@@ -616,6 +640,7 @@ func (vfs *VirtualFilesystem) cloneMount(mnt *Mount, root *Dentry, mopts *MountO
 		}
 	}
 	clone.isShared = mnt.isShared
+	clone.isUnbindable = mnt.isUnbindable
 	clone.locked = mnt.locked
 	if cloneType&makeFollowerClone != 0 || (cloneType&sharedToFollowerClone != 0 && mnt.isShared) {
 		mnt.followerList.PushFront(clone)
@@ -634,6 +659,7 @@ func (vfs *VirtualFilesystem) cloneMount(mnt *Mount, root *Dentry, mopts *MountO
 	}
 	if cloneType&makeSharedClone != 0 {
 		clone.isShared = true
+		clone.isUnbindable = false
 	}
 	return clone, nil
 }
@@ -666,6 +692,15 @@ func (vfs *VirtualFilesystem) cloneMountTree(ctx context.Context, mnt *Mount, ro
 			if mp := c.getKey(); p.prevMount == mnt && !mp.mount.fs.Impl().IsDescendant(VirtualDentry{mnt, root}, mp) {
 				continue
 			}
+			// Unbindable mounts and their descendants are skipped, unless
+			// the whole mount namespace is being copied.
+			if c.isUnbindable && cloneType&copyUnbindableClone == 0 {
+				if c.locked {
+					vfs.abortUncommitedMount(ctx, clone)
+					return nil, linuxerr.EPERM
+				}
+				continue
+			}
 			m, err := vfs.cloneMount(c, c.root, nil, cloneType)
 			if err != nil {
 				vfs.abortUncommitedMount(ctx, clone)
@@ -751,6 +786,9 @@ func (vfs *VirtualFilesystem) BindAt(ctx context.Context, creds *auth.Credential
 	if !vfs.validInMountNS(ctx, mp.mount) {
 		return linuxerr.EINVAL
 	}
+	if sourceVd.mount.isUnbindable {
+		return linuxerr.EINVAL
+	}
 
 	var clone *Mount
 	if recursive {
@@ -795,6 +833,64 @@ func (vfs *VirtualFilesystem) RemountAt(ctx context.Context, creds *auth.Credent
 	return nil
 }
 
+// SetMountAttrAt changes the attributes of the mount at the given path, and of
+// all mounts below it if opts.Recursive is set. It is the VFS part of
+// mount_setattr(2). Either all mounts or none of them are changed.
+func (vfs *VirtualFilesystem) SetMountAttrAt(ctx context.Context, creds *auth.Credentials, pop *PathOperation, opts *SetMountAttrOptions) error {
+	vd, err := vfs.getMountpoint(ctx, creds, pop)
+	if err != nil {
+		return err
+	}
+	defer vd.DecRef(ctx)
+	vfs.lockMounts()
+	defer vfs.unlockMounts(ctx)
+	mnt := vd.Mount()
+	// Mounts in an anonymous namespace, e.g. created by open_tree(2) or
+	// fsmount(2), may be changed before they are attached.
+	if !vfs.validInMountNS(ctx, mnt) && (mnt.ns == nil || !mnt.ns.anon || mnt.umounted) {
+		return linuxerr.EINVAL
+	}
+	mnts := []*Mount{mnt}
+	if opts.Recursive {
+		mnts = mnt.submountsLocked()
+	}
+
+	if opts.Propagation == linux.MS_SHARED {
+		if err := vfs.allocMountGroupIDs(mnt, opts.Recursive); err != nil {
+			return err
+		}
+	}
+	if (opts.Set|opts.Clear)&linux.MOUNT_ATTR_RDONLY != 0 {
+		ro := opts.Set&linux.MOUNT_ATTR_RDONLY != 0
+		var changed []*Mount
+		for _, m := range mnts {
+			if m.ReadOnlyLocked() == ro {
+				continue
+			}
+			if err := m.setReadOnlyLocked(ro); err != nil {
+				for _, c := range changed {
+					c.setReadOnlyLocked(!ro)
+				}
+				if opts.Propagation == linux.MS_SHARED {
+					vfs.cleanupGroupIDs(mnts)
+				}
+				return err
+			}
+			changed = append(changed, m)
+		}
+	}
+	for _, m := range mnts {
+		m.flags = opts.applyTo(m.flags)
+		if opts.Propagation != 0 {
+			vfs.setPropagation(m, opts.Propagation)
+		}
+	}
+	if mnt.ns != nil {
+		mnt.ns.notify()
+	}
+	return nil
+}
+
 // MountAt creates and mounts a Filesystem configured by the given arguments.
 // The VirtualFilesystem will hold a reference to the Mount until it is
 // unmounted.
@@ -1155,6 +1251,10 @@ func (mnt *Mount) DecRef(ctx context.Context) {
 }
 
 func (mnt *Mount) destroy(ctx context.Context) {
+	if mr, ok := mnt.fs.impl.(MountReleaser); ok {
+		mr.ReleaseMount(ctx, mnt)
+	}
+
 	mnt.vfs.lockMounts()
 	defer mnt.vfs.unlockMounts(ctx)
 	if mnt.parent() != nil {
@@ -1412,13 +1512,12 @@ func (vfs *VirtualFilesystem) PivotRoot(ctx context.Context, creds *auth.Credent
 	if oldRoot.mount.parent() == nil || newRoot.mount.parent() == nil {
 		return newRoot, oldRoot, linuxerr.EINVAL
 	}
-	// Either the mount point at new_root, or the parent mount of that mount
-	// point, has propagation type MS_SHARED.
-	if newRootParent := newRoot.mount.parent(); newRoot.mount.isShared || newRootParent.isShared {
-		return newRoot, oldRoot, linuxerr.EINVAL
-	}
-	// put_old is a mount point and has the propagation type MS_SHARED.
-	if putOld.mount.root == putOld.dentry && putOld.mount.isShared {
+	// Moving mounts out of, or into, a shared mount would need to be
+	// propagated. As in Linux, the mounts that new_root and the current root
+	// are detached from, and the mount that put_old is attached to, must not
+	// have propagation type MS_SHARED. Note that putOld is the topmost mount
+	// at put_old, so this also covers put_old being a shared mount point.
+	if putOld.mount.isShared || newRoot.mount.parent().isShared || oldRoot.mount.parent().isShared {
 		return newRoot, oldRoot, linuxerr.EINVAL
 	}
 	cleanup.Release()
@@ -1440,6 +1539,7 @@ func (vfs *VirtualFilesystem) PivotRoot(ctx context.Context, creds *auth.Credent
 	vfs.connectLocked(newRoot.mount, rootMp, rootMp.mount.ns)
 	rootMp.dentry.mu.Unlock()
 	vfs.mounts.seq.EndWrite()
+	newRoot.mount.ns.notify()
 
 	vfs.delayDecRef(newRoot.mount)
 	vfs.delayDecRef(oldRoot.mount)
@@ -1525,6 +1625,19 @@ func (mnt *Mount) submountsLocked() []*Mount {
 	return mounts
 }
 
+// treeContainsUnbindableLocked returns true if mnt or any of its descendants
+// has the MS_UNBINDABLE propagation type.
+//
+// Precondition: mnt.vfs.mountMu must be held.
+func (mnt *Mount) treeContainsUnbindableLocked() bool {
+	for _, m := range mnt.submountsLocked() {
+		if m.isUnbindable {
+			return true
+		}
+	}
+	return false
+}
+
 // countSubmountsLocked returns mnt's total number of descendants including
 // uncommitted descendants.
 //
@@ -1807,7 +1920,6 @@ func superBlockOpts(mountPath string, mnt *Mount) string {
 func (vfs *VirtualFilesystem) generateOptionalTags(ctx context.Context, mnt *Mount, root VirtualDentry) string {
 	vfs.lockMounts()
 	defer vfs.unlockMounts(ctx)
-	// TODO(b/305893463): Support MS_UNBINDABLE propagation type.
 	var optionalSb strings.Builder
 	if mnt.isShared {
 		fmt.Fprintf(&optionalSb, "shared:%d ", mnt.groupID)
@@ -1829,6 +1941,9 @@ func (vfs *VirtualFilesystem) generateOptionalTags(ctx context.Context, mnt *Mou
 			fmt.Fprintf(&optionalSb, "propagate_from:%d ", dominant.groupID)
 		}
 	}
+	if mnt.isUnbindable {
+		optionalSb.WriteString("unbindable ")
+	}
 	return optionalSb.String()
 }
 
@@ -276,6 +276,50 @@ type UmountOptions struct {
 	Flags uint32
 }
 
+// SetMountAttrOptions contains options to VirtualFilesystem.SetMountAttrAt().
+type SetMountAttrOptions struct {
+	// Set and Clear are the linux.MOUNT_ATTR_* attributes to set and clear.
+	// Attributes in Clear are cleared before those in Set are set. If Clear
+	// contains linux.MOUNT_ATTR__ATIME, the atime bits of Set select the new
+	// atime mode.
+	Set   uint64
+	Clear uint64
+
+	// Propagation is the new propagation type of the mount (one of
+	// linux.MS_SHARED, MS_PRIVATE, MS_SLAVE or MS_UNBINDABLE), or 0 if the
+	// propagation type is unchanged.
+	Propagation uint32
+
+	// Recursive is true if the changes apply to all mounts in the subtree.
+	Recursive bool
+}
+
+// applyTo returns flags with the attributes in opts applied.
+func (opts *SetMountAttrOptions) applyTo(flags MountFlags) MountFlags {
+	if opts.Clear&linux.MOUNT_ATTR_NOSUID != 0 {
+		flags.NoSUID = false
+	}
+	if opts.Clear&linux.MOUNT_ATTR_NODEV != 0 {
+		flags.NoDev = false
+	}
+	if opts.Clear&linux.MOUNT_ATTR_NOEXEC != 0 {
+		flags.NoExec = false
+	}
+	if opts.Clear&linux.MOUNT_ATTR__ATIME != 0 {
+		flags.NoATime = opts.Set&linux.MOUNT_ATTR__ATIME == linux.MOUNT_ATTR_NOATIME
+	}
+	if opts.Set&linux.MOUNT_ATTR_NOSUID != 0 {
+		flags.NoSUID = true
+	}
+	if opts.Set&linux.MOUNT_ATTR_NODEV != 0 {
+		flags.NoDev = true
+	}
+	if opts.Set&linux.MOUNT_ATTR_NOEXEC != 0 {
+		flags.NoExec = true
+	}
+	return flags
+}
+
 // WriteOptions contains options to FileDescription.PWrite(),
 // FileDescriptionImpl.PWrite(), FileDescription.Write(), and
 // FileDescriptionImpl.Write().
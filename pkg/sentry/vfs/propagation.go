@@ -33,6 +33,8 @@ const (
 	makePrivateClone
 	// Analogous to CL_SHARED_TO_SLAVE in Linux.
 	sharedToFollowerClone
+	// Analogous to CL_COPY_UNBINDABLE in Linux.
+	copyUnbindableClone
 
 	propagationFlags = linux.MS_SHARED | linux.MS_PRIVATE | linux.MS_SLAVE | linux.MS_UNBINDABLE
 )
@@ -144,8 +146,10 @@ func (vfs *VirtualFilesystem) SetMountPropagation(mnt *Mount, propFlag uint32, r
 func (vfs *VirtualFilesystem) setPropagation(mnt *Mount, propFlags uint32) {
 	if propFlags == linux.MS_SHARED {
 		mnt.isShared = true
+		mnt.isUnbindable = false
 		return
 	}
+	mnt.isUnbindable = propFlags == linux.MS_UNBINDABLE
 	// pflag is MS_PRIVATE, MS_SLAVE, or MS_UNBINDABLE. The algorithm is the same
 	// for MS_PRIVATE/MS_SLAVE/MS_UNBINDABLE, except that in the
 	// private/unbindable case we clear the leader and followerEntry after the
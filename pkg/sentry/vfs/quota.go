@@ -0,0 +1,605 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vfs
+
+import (
+	"math"
+
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
+	"gvisor.dev/gvisor/pkg/sync"
+)
+
+// QuotaFilesystem is an optional extension to FilesystemImpl for filesystems
+// that maintain disk quotas.
+type QuotaFilesystem interface {
+	// Quotas returns the filesystem's quota state, or nil if the filesystem
+	// was not mounted with quotas enabled.
+	Quotas() *QuotaSet
+}
+
+// QuotaLimits are the limits applied to a single user or group.
+//
+// +stateify savable
+type QuotaLimits struct {
+	// SpaceHard and SpaceSoft are limits on space usage in bytes. A value of
+	// 0 means that the limit is not enforced.
+	SpaceHard uint64
+	SpaceSoft uint64
+
+	// InodesHard and InodesSoft are limits on the number of inodes. A value
+	// of 0 means that the limit is not enforced.
+	InodesHard uint64
+	InodesSoft uint64
+}
+
+// QuotaSet tracks per-user and per-group usage of a filesystem, and enforces
+// limits on that usage. It implements the semantics of the Linux quota
+// subsystem (fs/quota/dquot.c) for filesystems that keep quota information
+// entirely in memory, like Linux's tmpfs.
+//
+// Users and groups are identified by KUID and KGID; translation to and from
+// IDs in a user namespace is the responsibility of the caller.
+//
+// +stateify savable
+type QuotaSet struct {
+	// mu protects the following fields and all QuotaCharges for this
+	// QuotaSet. mu is a leaf lock.
+	mu sync.Mutex `state:"nosave"`
+
+	// types[qtype] is nil if quotas of type qtype are not tracked.
+	types [linux.MAXQUOTAS]*quotaType
+}
+
+// quotaType holds quota state for one quota type (user or group).
+//
+// +stateify savable
+type quotaType struct {
+	// enforced is true if limits are enforced. Usage is accounted
+	// regardless.
+	enforced bool
+
+	// bgrace and igrace are the grace periods, in seconds, after which soft
+	// space and inode limits are enforced as hard limits.
+	bgrace uint64
+	igrace uint64
+
+	// defaults are the limits applied to IDs with no explicitly-set limits.
+	defaults QuotaLimits
+
+	// dquots contains per-ID state for IDs that have any usage or explicitly
+	// set limits.
+	dquots map[uint32]*dquot
+}
+
+// dquot is the quota state for a single ID, equivalent to Linux's struct
+// dquot.
+//
+// +stateify savable
+type dquot struct {
+	limits QuotaLimits
+	space  uint64
+	inodes uint64
+
+	// btime and itime are the times, in seconds since the epoch, at which
+	// soft limits begin to be enforced. They are 0 if the corresponding
+	// soft limit is not exceeded.
+	btime int64
+	itime int64
+}
+
+// QuotaCharge records usage charged to a QuotaSet on behalf of a single file.
+// The zero value is a QuotaCharge that has not been charged.
+//
+// +stateify savable
+type QuotaCharge struct {
+	uid    auth.KUID
+	gid    auth.KGID
+	space  uint64
+	inodes uint64
+}
+
+// NewQuotaSet returns a QuotaSet that tracks user quotas if usr is true and
+// group quotas if grp is true, with the given default limits. Limits are
+// enforced from the start, as for Linux's tmpfs.
+func NewQuotaSet(usr, grp bool, usrDefaults, grpDefaults QuotaLimits) *QuotaSet {
+	qs := &QuotaSet{}
+	if usr {
+		qs.types[linux.USRQUOTA] = newQuotaType(usrDefaults)
+	}
+	if grp {
+		qs.types[linux.GRPQUOTA] = newQuotaType(grpDefaults)
+	}
+	return qs
+}
+
+func newQuotaType(defaults QuotaLimits) *quotaType {
+	return &quotaType{
+		enforced: true,
+		bgrace:   linux.MAX_DQ_TIME,
+		igrace:   linux.MAX_IQ_TIME,
+		defaults: defaults,
+		dquots:   make(map[uint32]*dquot),
+	}
+}
+
+// getType returns the state for qtype.
+//
+// Preconditions: qs.mu must be locked.
+func (qs *QuotaSet) getType(qtype uint32) (*quotaType, error) {
+	if qtype >= linux.MAXQUOTAS {
+		return nil, linuxerr.EINVAL
+	}
+	qt := qs.types[qtype]
+	if qt == nil {
+		return nil, linuxerr.ESRCH
+	}
+	return qt, nil
+}
+
+// ownerID returns the ID that qc is charged to for quota type qtype.
+func (qc *QuotaCharge) ownerID(qtype int) uint32 {
+	if qtype == linux.USRQUOTA {
+		return uint32(qc.uid)
+	}
+	return uint32(qc.gid)
+}
+
+// get returns the dquot for id, creating it if necessary.
+func (qt *quotaType) get(id uint32) *dquot {
+	dq, ok := qt.dquots[id]
+	if !ok {
+		dq = &dquot{limits: qt.defaults}
+		qt.dquots[id] = dq
+	}
+	return dq
+}
+
+// maybeRelease removes the dquot for id if it holds no state that differs
+// from a newly-created dquot.
+func (qt *quotaType) maybeRelease(id uint32, dq *dquot) {
+	if dq.space == 0 && dq.inodes == 0 && dq.limits == qt.defaults {
+		delete(qt.dquots, id)
+	}
+}
+
+// checkSpace returns the number of bytes, at most n, by which dq's space
+// usage may grow at time now. If partial is false, it returns either n or 0.
+// Compare Linux's fs/quota/dquot.c:check_bdq().
+func (qt *quotaType) checkSpace(dq *dquot, now int64, n uint64, partial bool) uint64 {
+	if !qt.enforced {
+		return n
+	}
+	avail := n
+	if hard := dq.limits.SpaceHard; hard != 0 {
+		if dq.space >= hard {
+			return 0
+		}
+		avail = min(avail, hard-dq.space)
+	}
+	if soft := dq.limits.SpaceSoft; soft != 0 && dq.space+avail > soft && dq.btime != 0 && now >= dq.btime {
+		if dq.space >= soft {
+			return 0
+		}
+		avail = min(avail, soft-dq.space)
+	}
+	if !partial && avail != n {
+		return 0
+	}
+	return avail
+}
+
+// checkInodes returns true if dq may be charged n additional inodes at time
+// now. Compare Linux's fs/quota/dquot.c:check_idq().
+func (qt *quotaType) checkInodes(dq *dquot, now int64, n uint64) bool {
+	if !qt.enforced || n == 0 {
+		return true
+	}
+	newInodes := dq.inodes + n
+	if hard := dq.limits.InodesHard; hard != 0 && newInodes > hard {
+		return false
+	}
+	if soft := dq.limits.InodesSoft; soft != 0 && newInodes > soft && dq.itime != 0 && now >= dq.itime {
+		return false
+	}
+	return true
+}
+
+// updateTimes starts or stops dq's grace periods after a change in usage.
+func (qt *quotaType) updateTimes(dq *dquot, now int64) {
+	if soft := dq.limits.SpaceSoft; soft != 0 && dq.space > soft {
+		if dq.btime == 0 {
+			dq.btime = addGrace(now, qt.bgrace)
+		}
+	} else {
+		dq.btime = 0
+	}
+	if soft := dq.limits.InodesSoft; soft != 0 && dq.inodes > soft {
+		if dq.itime == 0 {
+			dq.itime = addGrace(now, qt.igrace)
+		}
+	} else {
+		dq.itime = 0
+	}
+}
+
+// clearTimes stops dq's grace periods if usage has dropped to or below the
+// corresponding soft limits.
+func (dq *dquot) clearTimes() {
+	if dq.space <= dq.limits.SpaceSoft || dq.limits.SpaceSoft == 0 {
+		dq.btime = 0
+	}
+	if dq.inodes <= dq.limits.InodesSoft || dq.limits.InodesSoft == 0 {
+		dq.itime = 0
+	}
+}
+
+// uncharge removes space bytes and inodes inodes from dq's usage. Usage may
+// have been overwritten by quotactl(Q_SETQUOTA), so uncharge saturates at 0
+// rather than underflowing.
+func (dq *dquot) uncharge(space, inodes uint64) {
+	dq.space -= min(dq.space, space)
+	dq.inodes -= min(dq.inodes, inodes)
+	dq.clearTimes()
+}
+
+func addGrace(now int64, grace uint64) int64 {
+	if grace > math.MaxInt64-uint64(now) {
+		return math.MaxInt64
+	}
+	return now + int64(grace)
+}
+
+// ChargeInode charges a new inode owned by uid and gid to qs, recording the
+// charge in qc. now is the current time in seconds since the epoch. If the
+// charge would exceed a quota, ChargeInode returns EDQUOT.
+//
+// Preconditions: qc has not been charged.
+func (qs *QuotaSet) ChargeInode(now int64, qc *QuotaCharge, uid auth.KUID, gid auth.KGID) error {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	qc.uid = uid
+	qc.gid = gid
+	for qtype, qt := range qs.types {
+		if qt == nil {
+			continue
+		}
+		if !qt.checkInodes(qt.get(qc.ownerID(qtype)), now, 1) {
+			qs.releaseUnusedLocked(qc)
+			return linuxerr.EDQUOT
+		}
+	}
+	for qtype, qt := range qs.types {
+		if qt == nil {
+			continue
+		}
+		dq := qt.get(qc.ownerID(qtype))
+		dq.inodes++
+		qt.updateTimes(dq, now)
+	}
+	qc.inodes = 1
+	return nil
+}
+
+// releaseUnusedLocked releases dquots created while checking limits for qc.
+//
+// Preconditions: qs.mu must be locked.
+func (qs *QuotaSet) releaseUnusedLocked(qc *QuotaCharge) {
+	for qtype, qt := range qs.types {
+		if qt == nil {
+			continue
+		}
+		id := qc.ownerID(qtype)
+		if dq, ok := qt.dquots[id]; ok {
+			qt.maybeRelease(id, dq)
+		}
+	}
+}
+
+// ChargeSpace charges n bytes of space to the owner of qc. If partial is
+// true, ChargeSpace charges as many bytes as possible, up to n; otherwise it
+// charges either n bytes or none. It returns the number of bytes charged, and
+// EDQUOT if no bytes could be charged.
+func (qs *QuotaSet) ChargeSpace(now int64, qc *QuotaCharge, n uint64, partial bool) (uint64, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	avail := n
+	for qtype, qt := range qs.types {
+		if qt == nil {
+			continue
+		}
+		avail = qt.checkSpace(qt.get(qc.ownerID(qtype)), now, avail, partial)
+		if avail == 0 {
+			qs.releaseUnusedLocked(qc)
+			return 0, linuxerr.EDQUOT
+		}
+	}
+	for qtype, qt := range qs.types {
+		if qt == nil {
+			continue
+		}
+		dq := qt.get(qc.ownerID(qtype))
+		dq.space += avail
+		qt.updateTimes(dq, now)
+	}
+	qc.space += avail
+	return avail, nil
+}
+
+// UnchargeSpace reverses a previous call to ChargeSpace for n bytes. Space
+// that was never charged to qc, e.g. because it was restored from a
+// checkpoint that did not record quota usage, is ignored.
+func (qs *QuotaSet) UnchargeSpace(qc *QuotaCharge, n uint64) {
+	if n == 0 {
+		return
+	}
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	qs.unchargeLocked(qc, min(n, qc.space), 0)
+}
+
+// Release uncharges all usage recorded in qc.
+func (qs *QuotaSet) Release(qc *QuotaCharge) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	qs.unchargeLocked(qc, qc.space, qc.inodes)
+}
+
+// unchargeLocked uncharges space bytes and inodes inodes from qc.
+//
+// Preconditions: qs.mu must be locked.
+func (qs *QuotaSet) unchargeLocked(qc *QuotaCharge, space, inodes uint64) {
+	for qtype, qt := range qs.types {
+		if qt == nil {
+			continue
+		}
+		id := qc.ownerID(qtype)
+		dq := qt.get(id)
+		dq.uncharge(space, inodes)
+		qt.maybeRelease(id, dq)
+	}
+	qc.space -= space
+	qc.inodes -= inodes
+}
+
+// Transfer moves the usage recorded in qc to a new owner, as on chown(2).
+// If the new owner does not have enough quota, Transfer returns EDQUOT and
+// qc is unchanged. Compare Linux's fs/quota/dquot.c:__dquot_transfer().
+func (qs *QuotaSet) Transfer(now int64, qc *QuotaCharge, uid auth.KUID, gid auth.KGID) error {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	newqc := QuotaCharge{uid: uid, gid: gid}
+	for qtype, qt := range qs.types {
+		if qt == nil || qc.ownerID(qtype) == newqc.ownerID(qtype) {
+			continue
+		}
+		dq := qt.get(newqc.ownerID(qtype))
+		if !qt.checkInodes(dq, now, qc.inodes) || qt.checkSpace(dq, now, qc.space, false) != qc.space {
+			qs.releaseUnusedLocked(&newqc)
+			return linuxerr.EDQUOT
+		}
+	}
+	for qtype, qt := range qs.types {
+		if qt == nil {
+			continue
+		}
+		oldID, newID := qc.ownerID(qtype), newqc.ownerID(qtype)
+		if oldID == newID {
+			continue
+		}
+		oldDq := qt.get(oldID)
+		oldDq.uncharge(qc.space, qc.inodes)
+		qt.maybeRelease(oldID, oldDq)
+		newDq := qt.get(newID)
+		newDq.space += qc.space
+		newDq.inodes += qc.inodes
+		qt.updateTimes(newDq, now)
+	}
+	qc.uid = uid
+	qc.gid = gid
+	return nil
+}
+
+// Format returns the quota format for quota type qtype, as returned by
+// quotactl(Q_GETFMT).
+func (qs *QuotaSet) Format(qtype uint32) (uint32, error) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	if _, err := qs.getType(qtype); err != nil {
+		return 0, err
+	}
+	return linux.QFMT_SHMEM, nil
+}
+
+// SetEnforced enables or disables enforcement of limits for quota type qtype,
+// as for quotactl(Q_QUOTAON) and quotactl(Q_QUOTAOFF). Usage is still
+// accounted while enforcement is disabled.
+func (qs *QuotaSet) SetEnforced(qtype uint32, enforced bool) error {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	qt, err := qs.getType(qtype)
+	if err != nil {
+		return err
+	}
+	if qt.enforced == enforced {
+		// Compare Linux's fs/quota/dquot.c:dquot_quota_enable() and
+		// dquot_quota_disable().
+		if enforced {
+			return linuxerr.EEXIST
+		}
+		return linuxerr.EINVAL
+	}
+	qt.enforced = enforced
+	return nil
+}
+
+// GetInfo implements quotactl(Q_GETINFO).
+func (qs *QuotaSet) GetInfo(qtype uint32) (linux.IfDqinfo, error) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	qt, err := qs.getType(qtype)
+	if err != nil {
+		return linux.IfDqinfo{}, err
+	}
+	return linux.IfDqinfo{
+		BGrace: qt.bgrace,
+		IGrace: qt.igrace,
+		// Quota state is not stored in user-visible files.
+		Flags: linux.DQF_SYS_FILE,
+		Valid: linux.IIF_ALL,
+	}, nil
+}
+
+// SetInfo implements quotactl(Q_SETINFO).
+func (qs *QuotaSet) SetInfo(qtype uint32, info *linux.IfDqinfo) error {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	qt, err := qs.getType(qtype)
+	if err != nil {
+		return err
+	}
+	if info.Valid&^linux.IIF_ALL != 0 {
+		return linuxerr.EINVAL
+	}
+	// DQF_ROOT_SQUASH is only meaningful for QFMT_VFS_OLD; compare Linux's
+	// fs/quota/dquot.c:dquot_set_dqinfo().
+	if info.Valid&linux.IIF_FLAGS != 0 && info.Flags != 0 {
+		return linuxerr.EINVAL
+	}
+	if info.Valid&linux.IIF_BGRACE != 0 {
+		qt.bgrace = info.BGrace
+	}
+	if info.Valid&linux.IIF_IGRACE != 0 {
+		qt.igrace = info.IGrace
+	}
+	return nil
+}
+
+// toIfDqblk converts dq to the representation used by quotactl(2).
+func (dq *dquot) toIfDqblk() linux.IfDqblk {
+	return linux.IfDqblk{
+		BHardlimit: dq.limits.SpaceHard / linux.QIF_DQBLKSIZE,
+		BSoftlimit: dq.limits.SpaceSoft / linux.QIF_DQBLKSIZE,
+		Curspace:   dq.space,
+		IHardlimit: dq.limits.InodesHard,
+		ISoftlimit: dq.limits.InodesSoft,
+		Curinodes:  dq.inodes,
+		Btime:      uint64(dq.btime),
+		Itime:      uint64(dq.itime),
+		Valid:      linux.QIF_ALL,
+	}
+}
+
+// GetQuota implements quotactl(Q_GETQUOTA) for the user or group with the
+// given KUID or KGID.
+func (qs *QuotaSet) GetQuota(qtype, id uint32) (linux.IfDqblk, error) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	qt, err := qs.getType(qtype)
+	if err != nil {
+		return linux.IfDqblk{}, err
+	}
+	if dq, ok := qt.dquots[id]; ok {
+		return dq.toIfDqblk(), nil
+	}
+	dq := dquot{limits: qt.defaults}
+	return dq.toIfDqblk(), nil
+}
+
+// GetNextQuota implements quotactl(Q_GETNEXTQUOTA): it returns the quota for
+// the lowest ID that is greater than or equal to id and has any usage or
+// limits. If no such ID exists, GetNextQuota returns ENOENT.
+func (qs *QuotaSet) GetNextQuota(qtype, id uint32) (linux.IfNextDqblk, error) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	qt, err := qs.getType(qtype)
+	if err != nil {
+		return linux.IfNextDqblk{}, err
+	}
+	var (
+		next   *dquot
+		nextID uint32
+	)
+	for dqID, dq := range qt.dquots {
+		if dqID >= id && (next == nil || dqID < nextID) {
+			next, nextID = dq, dqID
+		}
+	}
+	if next == nil {
+		return linux.IfNextDqblk{}, linuxerr.ENOENT
+	}
+	dqb := next.toIfDqblk()
+	return linux.IfNextDqblk{
+		BHardlimit: dqb.BHardlimit,
+		BSoftlimit: dqb.BSoftlimit,
+		Curspace:   dqb.Curspace,
+		IHardlimit: dqb.IHardlimit,
+		ISoftlimit: dqb.ISoftlimit,
+		Curinodes:  dqb.Curinodes,
+		Btime:      dqb.Btime,
+		Itime:      dqb.Itime,
+		Valid:      dqb.Valid,
+		ID:         nextID,
+	}, nil
+}
+
+// SetQuota implements quotactl(Q_SETQUOTA) for the user or group with the
+// given KUID or KGID. now is the current time in seconds since the epoch.
+// Compare Linux's fs/quota/dquot.c:do_set_dqblk().
+func (qs *QuotaSet) SetQuota(now int64, qtype, id uint32, dqb *linux.IfDqblk) error {
+	if dqb.Valid&^linux.QIF_ALL != 0 {
+		return linuxerr.EINVAL
+	}
+	const maxBlocks = math.MaxUint64 / linux.QIF_DQBLKSIZE
+	if dqb.Valid&linux.QIF_BLIMITS != 0 && (dqb.BHardlimit > maxBlocks || dqb.BSoftlimit > maxBlocks) {
+		return linuxerr.ERANGE
+	}
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	qt, err := qs.getType(qtype)
+	if err != nil {
+		return err
+	}
+	dq := qt.get(id)
+	if dqb.Valid&linux.QIF_BLIMITS != 0 {
+		dq.limits.SpaceHard = dqb.BHardlimit * linux.QIF_DQBLKSIZE
+		dq.limits.SpaceSoft = dqb.BSoftlimit * linux.QIF_DQBLKSIZE
+	}
+	if dqb.Valid&linux.QIF_SPACE != 0 {
+		dq.space = dqb.Curspace
+	}
+	if dqb.Valid&linux.QIF_ILIMITS != 0 {
+		dq.limits.InodesHard = dqb.IHardlimit
+		dq.limits.InodesSoft = dqb.ISoftlimit
+	}
+	if dqb.Valid&linux.QIF_INODES != 0 {
+		dq.inodes = dqb.Curinodes
+	}
+	if dqb.Valid&linux.QIF_BTIME != 0 {
+		dq.btime = int64(min(dqb.Btime, math.MaxInt64))
+	}
+	if dqb.Valid&linux.QIF_ITIME != 0 {
+		dq.itime = int64(min(dqb.Itime, math.MaxInt64))
+	}
+	// Explicitly-set times are only kept while the corresponding soft limit
+	// is exceeded.
+	qt.updateTimes(dq, now)
+	qt.maybeRelease(id, dq)
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vfs
+
+import (
+	"testing"
+
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+)
+
+func TestQuotaHardLimits(t *testing.T) {
+	qs := NewQuotaSet(true /* usr */, false /* grp */, QuotaLimits{SpaceHard: 4096, InodesHard: 1}, QuotaLimits{})
+	var qc QuotaCharge
+	if err := qs.ChargeInode(0, &qc, 1000, 1000); err != nil {
+		t.Fatalf("ChargeInode: got %v, want nil", err)
+	}
+	var qc2 QuotaCharge
+	if err := qs.ChargeInode(0, &qc2, 1000, 1000); err != linuxerr.EDQUOT {
+		t.Errorf("second ChargeInode: got %v, want EDQUOT", err)
+	}
+	if n, err := qs.ChargeSpace(0, &qc, 8192, false /* partial */); n != 0 || err != linuxerr.EDQUOT {
+		t.Errorf("ChargeSpace(8192, partial=false): got (%d, %v), want (0, EDQUOT)", n, err)
+	}
+	if n, err := qs.ChargeSpace(0, &qc, 8192, true /* partial */); n != 4096 || err != nil {
+		t.Errorf("ChargeSpace(8192, partial=true): got (%d, %v), want (4096, nil)", n, err)
+	}
+	dqb, err := qs.GetQuota(linux.USRQUOTA, 1000)
+	if err != nil {
+		t.Fatalf("GetQuota: %v", err)
+	}
+	if dqb.Curspace != 4096 || dqb.Curinodes != 1 || dqb.BHardlimit != 4 {
+		t.Errorf("GetQuota: got %+v, want Curspace=4096, Curinodes=1, BHardlimit=4", dqb)
+	}
+	qs.Release(&qc)
+	if _, err := qs.GetNextQuota(linux.USRQUOTA, 0); err != linuxerr.ENOENT {
+		t.Errorf("GetNextQuota after Release: got %v, want ENOENT", err)
+	}
+	if _, err := qs.GetQuota(linux.GRPQUOTA, 1000); err != linuxerr.ESRCH {
+		t.Errorf("GetQuota(GRPQUOTA): got %v, want ESRCH", err)
+	}
+}
+
+func TestQuotaSoftLimitGrace(t *testing.T) {
+	qs := NewQuotaSet(true /* usr */, false /* grp */, QuotaLimits{}, QuotaLimits{})
+	if err := qs.SetInfo(linux.USRQUOTA, &linux.IfDqinfo{BGrace: 10, Valid: linux.IIF_BGRACE}); err != nil {
+		t.Fatalf("SetInfo: %v", err)
+	}
+	if err := qs.SetQuota(0, linux.USRQUOTA, 1000, &linux.IfDqblk{BSoftlimit: 1, Valid: linux.QIF_BLIMITS}); err != nil {
+		t.Fatalf("SetQuota: %v", err)
+	}
+	var qc QuotaCharge
+	if err := qs.ChargeInode(100, &qc, 1000, 1000); err != nil {
+		t.Fatalf("ChargeInode: %v", err)
+	}
+	// Exceeding the soft limit starts the grace period.
+	if _, err := qs.ChargeSpace(100, &qc, 2048, false /* partial */); err != nil {
+		t.Fatalf("ChargeSpace within grace period: %v", err)
+	}
+	if dqb, _ := qs.GetQuota(linux.USRQUOTA, 1000); dqb.Btime != 110 {
+		t.Errorf("Btime: got %d, want 110", dqb.Btime)
+	}
+	if _, err := qs.ChargeSpace(105, &qc, 1024, false /* partial */); err != nil {
+		t.Errorf("ChargeSpace within grace period: %v", err)
+	}
+	if _, err := qs.ChargeSpace(110, &qc, 1024, false /* partial */); err != linuxerr.EDQUOT {
+		t.Errorf("ChargeSpace after grace period: got %v, want EDQUOT", err)
+	}
+	// Dropping below the soft limit ends the grace period.
+	qs.UnchargeSpace(&qc, 3072)
+	if dqb, _ := qs.GetQuota(linux.USRQUOTA, 1000); dqb.Btime != 0 {
+		t.Errorf("Btime after uncharge: got %d, want 0", dqb.Btime)
+	}
+}
+
+func TestQuotaTransfer(t *testing.T) {
+	qs := NewQuotaSet(true /* usr */, true /* grp */, QuotaLimits{}, QuotaLimits{})
+	if err := qs.SetQuota(0, linux.USRQUOTA, 2000, &linux.IfDqblk{BHardlimit: 1, Valid: linux.QIF_BLIMITS}); err != nil {
+		t.Fatalf("SetQuota: %v", err)
+	}
+	var qc QuotaCharge
+	if err := qs.ChargeInode(0, &qc, 1000, 1000); err != nil {
+		t.Fatalf("ChargeInode: %v", err)
+	}
+	if _, err := qs.ChargeSpace(0, &qc, 4096, false /* partial */); err != nil {
+		t.Fatalf("ChargeSpace: %v", err)
+	}
+	if err := qs.Transfer(0, &qc, 2000, 1000); err != linuxerr.EDQUOT {
+		t.Errorf("Transfer to UID over quota: got %v, want EDQUOT", err)
+	}
+	if err := qs.Transfer(0, &qc, 1000, 3000); err != nil {
+		t.Fatalf("Transfer to new GID: %v", err)
+	}
+	if dqb, _ := qs.GetQuota(linux.GRPQUOTA, 3000); dqb.Curspace != 4096 || dqb.Curinodes != 1 {
+		t.Errorf("GetQuota(GRPQUOTA, 3000): got %+v, want Curspace=4096, Curinodes=1", dqb)
+	}
+	next, err := qs.GetNextQuota(linux.GRPQUOTA, 1)
+	if err != nil {
+		t.Fatalf("GetNextQuota: %v", err)
+	}
+	if next.ID != 3000 {
+		t.Errorf("GetNextQuota: got ID %d, want 3000", next.ID)
+	}
+}
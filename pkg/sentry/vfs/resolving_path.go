@@ -57,6 +57,12 @@ type ResolvingPath struct {
 	nextStart        *Dentry // ref held if not nil
 	absSymlinkTarget fspath.Path
 
+	// renameSeq and mountSeq are the values of VirtualFilesystem.renameSeq
+	// and VirtualFilesystem.mounts.seq when a scoped (RESOLVE_BENEATH or
+	// RESOLVE_IN_ROOT) path resolution started.
+	renameSeq uint64             `state:"nosave"`
+	mountSeq  sync.SeqCountEpoch `state:"nosave"`
+
 	// ResolvingPath tracks relative paths, which is updated whenever a relative
 	// symlink is encountered.
 	parts [1 + linux.MaxSymlinkTraversals]fspath.Iterator
@@ -66,8 +72,25 @@ const (
 	rpflagsHaveMountRef       = 1 << iota // do we hold a reference on mount?
 	rpflagsHaveStartRef                   // do we hold a reference on start?
 	rpflagsFollowFinalSymlink             // same as PathOperation.FollowFinalSymlink
+	rpflagsNoXDev                         // RESOLVE_NO_XDEV
+	rpflagsNoMagicLinks                   // RESOLVE_NO_MAGICLINKS
+	rpflagsNoSymlinks                     // RESOLVE_NO_SYMLINKS
+	rpflagsBeneath                        // RESOLVE_BENEATH
+	rpflagsInRoot                         // RESOLVE_IN_ROOT
 )
 
+// rpflagsForResolveFlags maps RESOLVE_* flags to rpflags.
+var rpflagsForResolveFlags = []struct {
+	resolve uint64
+	rpflag  uint16
+}{
+	{linux.RESOLVE_NO_XDEV, rpflagsNoXDev},
+	{linux.RESOLVE_NO_MAGICLINKS, rpflagsNoMagicLinks},
+	{linux.RESOLVE_NO_SYMLINKS, rpflagsNoSymlinks},
+	{linux.RESOLVE_BENEATH, rpflagsBeneath},
+	{linux.RESOLVE_IN_ROOT, rpflagsInRoot},
+}
+
 func init() {
 	if maxParts := len(ResolvingPath{}.parts); maxParts > 255 {
 		panic(fmt.Sprintf("uint8 is insufficient to accommodate len(ResolvingPath.parts) (%d)", maxParts))
@@ -125,6 +148,17 @@ func (vfs *VirtualFilesystem) getResolvingPath(creds *auth.Credentials, pop *Pat
 	if pop.FollowFinalSymlink {
 		rp.flags |= rpflagsFollowFinalSymlink
 	}
+	if pop.ResolveFlags != 0 {
+		for _, f := range rpflagsForResolveFlags {
+			if pop.ResolveFlags&f.resolve != 0 {
+				rp.flags |= f.rpflag
+			}
+		}
+	}
+	if rp.flags&(rpflagsBeneath|rpflagsInRoot) != 0 {
+		rp.renameSeq = vfs.renameSeq.Load()
+		rp.mountSeq = vfs.mounts.seq.BeginRead()
+	}
 	rp.mustBeDir = pop.Path.Dir
 	rp.symlinks = 0
 	rp.curPart = 0
@@ -286,12 +320,21 @@ func (rp *ResolvingPath) GetComponents(excludeLast bool, emit func(string) bool)
 func (rp *ResolvingPath) CheckRoot(ctx context.Context, d *Dentry) (bool, error) {
 	if d == rp.root.dentry && rp.mount == rp.root.mount {
 		// At contextual VFS root (due to e.g. chroot(2)).
+		if rp.flags&rpflagsBeneath != 0 {
+			// Resolving ".." would escape the directory that path resolution
+			// is scoped to, which is fs/namei.c:follow_dotdot()'s -EXDEV.
+			return false, linuxerr.EXDEV
+		}
 		return true, nil
 	} else if d == rp.mount.root {
 		// At mount root ...
 		vd := rp.vfs.getMountpointAt(ctx, rp.mount, rp.root)
 		if vd.Ok() {
 			// ... of non-root mount.
+			if rp.flags&rpflagsNoXDev != 0 {
+				vd.DecRef(ctx)
+				return false, linuxerr.EXDEV
+			}
 			rp.nextMount = vd.mount
 			rp.nextStart = vd.dentry
 			return false, resolveMountRootOrJumpError{}
@@ -307,16 +350,38 @@ func (rp *ResolvingPath) CheckRoot(ctx context.Context, d *Dentry) (bool, error)
 // another Mount, CheckMount returns a non-nil error. Otherwise, CheckMount
 // returns nil.
 func (rp *ResolvingPath) CheckMount(ctx context.Context, d *Dentry) error {
+	if err := rp.checkScopedDotDot(); err != nil {
+		return err
+	}
 	if !d.isMounted() {
 		return nil
 	}
 	if mnt := rp.vfs.getMountAt(ctx, rp.mount, d); mnt != nil {
+		if rp.flags&rpflagsNoXDev != 0 {
+			mnt.DecRef(ctx)
+			return linuxerr.EXDEV
+		}
 		rp.nextMount = mnt
 		return resolveMountPointError{}
 	}
 	return nil
 }
 
+// checkScopedDotDot is called after resolving a path component. If the path
+// component is "..", path resolution is scoped, and a rename or mount change
+// happened since it started, a directory may have been moved out of the scope
+// so that ".." escaped it; checkScopedDotDot then returns EAGAIN, like
+// fs/namei.c:handle_dots().
+func (rp *ResolvingPath) checkScopedDotDot() error {
+	if rp.flags&(rpflagsBeneath|rpflagsInRoot) == 0 || rp.Done() || rp.Component() != ".." {
+		return nil
+	}
+	if rp.vfs.renameSeq.Load() != rp.renameSeq || !rp.vfs.mounts.seq.ReadOk(rp.mountSeq) {
+		return linuxerr.EAGAIN
+	}
+	return nil
+}
+
 // ShouldFollowSymlink returns true if, supposing that the current path
 // component in pcs represents a symbolic link, the symbolic link should be
 // followed.
@@ -354,11 +419,21 @@ func (rp *ResolvingPath) HandleSymlink(target string) (bool, error) {
 	if rp.symlinks >= linux.MaxSymlinkTraversals {
 		return false, linuxerr.ELOOP
 	}
+	if rp.flags&rpflagsNoSymlinks != 0 {
+		return false, linuxerr.ELOOP
+	}
 	if len(target) == 0 {
 		return false, linuxerr.ENOENT
 	}
-	rp.symlinks++
 	targetPath := fspath.Parse(target)
+	if targetPath.Absolute && rp.flags&(rpflagsBeneath|rpflagsNoXDev) != 0 {
+		// Absolute symlinks jump to the root, which is not allowed in these
+		// cases by fs/namei.c:nd_jump_root().
+		if rp.flags&rpflagsBeneath != 0 || rp.mount != rp.root.mount {
+			return false, linuxerr.EXDEV
+		}
+	}
+	rp.symlinks++
 	if targetPath.Absolute {
 		rp.absSymlinkTarget = targetPath
 		return true, resolveAbsSymlinkError{}
@@ -406,6 +481,18 @@ func (rp *ResolvingPath) HandleJump(target VirtualDentry) (bool, error) {
 	if rp.symlinks >= linux.MaxSymlinkTraversals {
 		return false, linuxerr.ELOOP
 	}
+	// These checks are consistent with fs/namei.c:nd_jump_link().
+	if rp.flags&(rpflagsNoSymlinks|rpflagsNoMagicLinks) != 0 {
+		return false, linuxerr.ELOOP
+	}
+	if rp.flags&rpflagsNoXDev != 0 && target.mount != rp.mount {
+		return false, linuxerr.EXDEV
+	}
+	if rp.flags&(rpflagsBeneath|rpflagsInRoot) != 0 {
+		// Magic links can jump anywhere, so they are not safe to follow in
+		// scoped lookups.
+		return false, linuxerr.EXDEV
+	}
 	rp.symlinks++
 	// Consume the path component that represented the magic link.
 	rp.Advance()
@@ -96,6 +96,14 @@ type VirtualFilesystem struct {
 	// using atomic memory operations.
 	lastMountID atomicbitops.Uint64
 
+	// renameSeq is incremented before and after every rename, so that scoped
+	// path resolution can detect renames that may have moved a directory out
+	// of the scope while it was being walked. See
+	// ResolvingPath.checkScopedDotDot.
+	//
+	// renameSeq is analogous to Linux's rename_lock.
+	renameSeq atomicbitops.Uint64 `state:"nosave"`
+
 	// lastMountNamespaceID is the last allocated mount namespace ID.
 	// lastMountNamespaceID is accessed using atomic memory operations.
 	lastMountNamespaceID atomicbitops.Uint64
@@ -224,6 +232,12 @@ type PathOperation struct {
 	// path component represents a symbolic link, the symbolic link should be
 	// followed.
 	FollowFinalSymlink bool
+
+	// ResolveFlags restricts path resolution as for openat2(2). It is a
+	// combination of linux.RESOLVE_* flags other than RESOLVE_CACHED. If
+	// RESOLVE_BENEATH or RESOLVE_IN_ROOT is set, Root must be the directory
+	// that path resolution is scoped to.
+	ResolveFlags uint64
 }
 
 // AccessAt checks whether a user with creds has access to the file at
@@ -567,6 +581,8 @@ func (vfs *VirtualFilesystem) RenameAt(ctx context.Context, creds *auth.Credenti
 	if oldpop.Path.Dir {
 		renameOpts.MustBeDir = true
 	}
+	vfs.renameSeq.Add(1)
+	defer vfs.renameSeq.Add(1)
 	for {
 		if err := vfs.maybeBlockOnMountPromise(ctx, rp); err != nil {
 			return err
@@ -652,10 +668,7 @@ func (vfs *VirtualFilesystem) StatAt(ctx context.Context, creds *auth.Credential
 		}
 		stat, err := rp.mount.fs.impl.StatAt(ctx, rp, *opts)
 		if err == nil {
-			if opts.Mask&linux.STATX_MNT_ID != 0 {
-				stat.MntID = rp.mount.ID
-				stat.Mask |= linux.STATX_MNT_ID
-			}
+			rp.mount.statMntIDTo(opts.Mask, &stat)
 			return stat, nil
 		}
 		if !rp.handleError(ctx, err) {
@@ -888,6 +901,28 @@ func (vfs *VirtualFilesystem) GetFilesystems() []*Filesystem {
 	return fss
 }
 
+// FilesystemImplHostFDsExtension is an optional extension to FilesystemImpl
+// implemented by filesystems that hold host file descriptors.
+type FilesystemImplHostFDsExtension interface {
+	// HostFDs calls fn for each host FD held by the filesystem, with a short
+	// description of what holds it.
+	HostFDs(fn func(fd int, owner string))
+}
+
+// HostFDs calls fn for each host FD held by a filesystem, with the name of
+// the filesystem's type and a short description of what holds it.
+func (vfs *VirtualFilesystem) HostFDs(ctx context.Context, fn func(fd int, owner string)) {
+	for _, fs := range vfs.GetFilesystems() {
+		if ext, ok := fs.impl.(FilesystemImplHostFDsExtension); ok {
+			name := fs.FilesystemType().Name()
+			ext.HostFDs(func(fd int, owner string) {
+				fn(fd, name+": "+owner)
+			})
+		}
+		fs.DecRef(ctx)
+	}
+}
+
 // MkdirAllAt recursively creates non-existent directories on the given path
 // (including the last component).
 func (vfs *VirtualFilesystem) MkdirAllAt(ctx context.Context, currentPath string, root VirtualDentry, creds *auth.Credentials, mkdirOpts *MkdirOptions, mustBeDir bool) error {
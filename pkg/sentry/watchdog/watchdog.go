@@ -60,6 +60,11 @@ type Opts struct {
 	// StartupTimeoutAction indicates what action to take when
 	// watchdog.Start is not called within the timeout.
 	StartupTimeoutAction Action
+
+	// OnReport, if not nil, is called with the action about to be taken and
+	// the report every time the watchdog detects a problem. With the Panic
+	// action, it is called before panicking.
+	OnReport func(action Action, msg string)
 }
 
 // DefaultOpts is a default set of options for the watchdog.
@@ -371,6 +376,9 @@ func (w *Watchdog) reportStuckWatchdog() {
 // is not always dumped to the log to prevent log flooding. "forceStack"
 // guarantees that the stack will be dumped regardless.
 func (w *Watchdog) doAction(action Action, forceStack bool, stuckTasks map[int64]struct{}, msg *bytes.Buffer) {
+	if w.OnReport != nil {
+		w.OnReport(action, msg.String())
+	}
 	switch action {
 	case LogWarning:
 		// Dump stack only if forced or sometime has passed since the last time a
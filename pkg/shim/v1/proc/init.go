@@ -77,6 +77,13 @@ type Init struct {
 	Sandbox  bool
 	UserLog  string
 	Monitor  ProcessMonitor
+
+	// ExitEvents is set if the exit of the init process is reported from
+	// the sandbox's event stream, instead of by WatchExit.
+	ExitEvents bool
+
+	// watching is true once the exit of the init process is waited for.
+	watching bool
 }
 
 // NewRunsc returns a new runsc instance for a process.
@@ -251,6 +258,25 @@ func (p *Init) start(ctx context.Context, restoreConf *extension.RestoreConfig)
 			return p.runtimeError(err, "OCI runtime restore failed")
 		}
 	}
+	if !p.ExitEvents {
+		p.watchExitLocked(ctx)
+	}
+	return nil
+}
+
+// WatchExit waits for the container to exit with "runsc wait" and reports
+// the exit of the init process on ExitCh, unless it's already waited for.
+func (p *Init) WatchExit(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.watchExitLocked(ctx)
+}
+
+func (p *Init) watchExitLocked(ctx context.Context) {
+	if p.watching {
+		return
+	}
+	p.watching = true
 	go func() {
 		status, err := p.runtime.Wait(context.Background(), p.id)
 		if err != nil {
@@ -264,7 +290,6 @@ func (p *Init) start(ctx context.Context, restoreConf *extension.RestoreConfig)
 			Status:    status,
 		}
 	}()
-	return nil
 }
 
 // Restore restores the container from a snapshot.
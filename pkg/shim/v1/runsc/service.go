@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	cgroups "github.com/containerd/cgroups/v3"
 	cgroup1 "github.com/containerd/cgroups/v3/cgroup1"
@@ -68,6 +69,10 @@ const (
 	configFile = "config.toml"
 
 	cgroupParentAnnotation = "dev.gvisor.spec.cgroup-parent"
+
+	// sandboxEventsOOMInterval is how often "runsc events --subscribe" checks
+	// the sandbox cgroup for OOM kills.
+	sandboxEventsOOMInterval = 5 * time.Second
 )
 
 type oomPoller interface {
@@ -115,6 +120,10 @@ type runscService struct {
 	// containers maps container id to a container.
 	containers map[string]*Container
 
+	// subscribed is true while the shim is subscribed to sandbox events, which
+	// report the exits and OOM kills of containers. See watchSandboxEvents.
+	subscribed bool
+
 	// root is the runsc root directory.
 	root string
 
@@ -210,15 +219,23 @@ func (s *runscService) CreateWithFSRestore(ctx context.Context, rfs *extension.C
 	if err != nil {
 		return nil, err
 	}
-	if initProcess, ok := p.(*proc.Init); ok && initProcess.Sandbox {
+	initProcess, isInit := p.(*proc.Init)
+	if isInit && initProcess.Sandbox {
 		s.root = initProcess.Runtime().Root
 		s.runtime = initProcess.Runtime()
 	}
+	if isInit {
+		if !s.subscribed {
+			s.subscribed = s.watchSandboxEvents(rfs.Create.ID, initProcess.Runtime())
+		}
+		initProcess.ExitEvents = s.subscribed
+	}
 
-	// Set up OOM notification on the sandbox's cgroup. This is done on
-	// sandbox create since the sandbox process will be created here.
+	// Set up OOM notification on the sandbox's cgroup, unless OOM kills are
+	// reported by sandbox events. This is done on sandbox create since the
+	// sandbox process will be created here.
 	pid := p.Pid()
-	if pid > 0 {
+	if pid > 0 && !s.subscribed {
 		var (
 			cg  any
 			err error
@@ -619,6 +636,100 @@ func (s *runscService) checkProcesses(ctx context.Context, e proc.Exit) {
 	})
 }
 
+// watchSandboxEvents subscribes to the events of the sandbox that container
+// id belongs to, and returns whether it succeeded. Exits of the init processes
+// of containers with proc.Init.ExitEvents set are reported from the events,
+// as are OOM kills, which apply to all containers in the sandbox since they
+// share its cgroup. Watchdog and panic events are logged. If the events stop
+// before the sandbox reported a container's exit, e.g. because the sandbox
+// was killed, the container's exit is reported by "runsc wait" instead.
+//
+// Preconditions: s.mu is locked.
+func (s *runscService) watchSandboxEvents(id string, r *runsccmd.Runsc) bool {
+	// The subscription outlives the request, so don't use its context.
+	ctx := context.Background()
+	ch, err := r.Subscribe(ctx, id, sandboxEventsOOMInterval)
+	if err != nil {
+		log.L.WithError(err).Warn("Failed to subscribe to sandbox events, waiting for container exits instead")
+		return false
+	}
+	go func() {
+		// exited holds the containers whose exit was reported from the
+		// events.
+		exited := make(map[string]bool)
+		for ev := range ch {
+			switch {
+			case ev.Err != nil:
+				log.L.WithError(ev.Err).Warn("Error reading sandbox events")
+			case ev.Type == "init-exit" || ev.Type == "container-exit":
+				if !s.exitFromEvents(ev.ContainerID) {
+					log.L.Debugf("Ignoring exit of container %q, which is waited for", ev.ContainerID)
+					continue
+				}
+				exited[ev.ContainerID] = true
+				s.ec <- proc.Exit{
+					Timestamp: ev.Time,
+					ID:        ev.ContainerID,
+					Status:    ev.ExitCode(),
+				}
+			case ev.Type == "oom-kill":
+				log.L.Warnf("Sandbox OOM kill: %s", ev.Message)
+				s.mu.Lock()
+				for cid, c := range s.containers {
+					if ip := initOf(c); ip != nil && ip.ExitEvents && !exited[cid] {
+						s.send(&events.TaskOOM{ContainerID: cid})
+					}
+				}
+				s.mu.Unlock()
+			case ev.Type == "watchdog-warning":
+				log.L.Warnf("Sandbox watchdog warning: %s", ev.Message)
+			case ev.Type == "panic-imminent":
+				log.L.Errorf("Sandbox is about to panic: %s", ev.Message)
+			default:
+				log.L.Debugf("Sandbox event: %+v", ev)
+			}
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.subscribed = false
+		for cid, c := range s.containers {
+			ip := initOf(c)
+			if ip == nil || !ip.ExitEvents || exited[cid] {
+				continue
+			}
+			if ip.ExitedAt().IsZero() {
+				log.L.Infof("Sandbox events stopped before container %q exited, waiting for it", cid)
+				ip.WatchExit(ctx)
+			}
+		}
+	}()
+	return true
+}
+
+// exitFromEvents returns true if the exit of container cid's init process is
+// reported from sandbox events.
+func (s *runscService) exitFromEvents(cid string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.containers[cid]
+	if !ok {
+		return false
+	}
+	ip := initOf(c)
+	return ip != nil && ip.ExitEvents
+}
+
+// initOf returns the init process of c, or nil.
+func initOf(c *Container) *proc.Init {
+	p, err := c.Process("")
+	if err != nil {
+		return nil
+	}
+	ip, _ := p.(*proc.Init)
+	return ip
+}
+
 func (s *runscService) send(event any) {
 	s.events <- event
 }
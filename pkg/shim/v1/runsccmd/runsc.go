@@ -556,6 +556,72 @@ func (r *Runsc) Events(context context.Context, id string, interval time.Duratio
 	return c, nil
 }
 
+// SandboxEvent is an event reported by "runsc events --subscribe". It
+// corresponds to runsc's boot.SandboxEvent.
+type SandboxEvent struct {
+	// Type is the type of event, e.g. "init-exit", "container-exit",
+	// "oom-kill", "watchdog-warning" or "panic-imminent".
+	Type        string    `json:"type"`
+	Time        time.Time `json:"time"`
+	ContainerID string    `json:"containerID,omitempty"`
+	ExitStatus  *uint32   `json:"exitStatus,omitempty"`
+	Message     string    `json:"message,omitempty"`
+
+	// Err is set if the event stream could not be decoded.
+	Err error `json:"-"`
+}
+
+// ExitCode returns the exit code of the container for an exit event, in the
+// format returned by Wait.
+func (e *SandboxEvent) ExitCode() int {
+	if e.ExitStatus == nil {
+		return 0
+	}
+	ws := unix.WaitStatus(*e.ExitStatus)
+	if ws.Signaled() {
+		return 128 + int(ws.Signal())
+	}
+	return ws.ExitStatus()
+}
+
+// Subscribe returns the stream of events of the sandbox that the container
+// belongs to, such as container exits and OOM kills. The channel is closed
+// once the sandbox exits.
+func (r *Runsc) Subscribe(context context.Context, id string, oomInterval time.Duration) (chan *SandboxEvent, error) {
+	cmd := r.command(context, "events", "--subscribe", fmt.Sprintf("--interval=%d", int(oomInterval.Seconds())), id)
+	rd, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	ec, err := Monitor.Start(cmd)
+	if err != nil {
+		rd.Close()
+		return nil, err
+	}
+	var (
+		dec = json.NewDecoder(rd)
+		c   = make(chan *SandboxEvent, 128)
+	)
+	go func() {
+		defer func() {
+			close(c)
+			rd.Close()
+			Monitor.Wait(cmd, ec)
+		}()
+		for {
+			var e SandboxEvent
+			if err := dec.Decode(&e); err != nil {
+				if err != io.EOF {
+					c <- &SandboxEvent{Err: err}
+				}
+				return
+			}
+			c <- &e
+		}
+	}()
+	return c, nil
+}
+
 // Ps lists all the processes inside the container returning their pids.
 func (r *Runsc) Ps(context context.Context, id string) ([]int, error) {
 	data, stderr, err := cmdOutput(r.command(context, "ps", "--format", "json", id), false)
@@ -85,3 +85,22 @@ exit 0
 		t.Fatalf("stdin resources: got %+v, want memory limit %d", got.Memory, limit)
 	}
 }
+
+func TestSandboxEventExitCode(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		status uint32
+		want   int
+	}{
+		{name: "success", status: 0, want: 0},
+		{name: "exit", status: 3 << 8, want: 3},
+		{name: "signal", status: 9, want: 137},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ev := SandboxEvent{Type: "init-exit", ExitStatus: &tc.status}
+			if got := ev.ExitCode(); got != tc.want {
+				t.Errorf("ExitCode() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
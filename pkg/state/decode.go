@@ -363,8 +363,16 @@ func (od *objectDecoder) load(slot int, objPtr reflect.Value, wait bool, fn func
 	// to match what's expected by the decoder. The "slot" parameter here
 	// is in terms of the local type, where the fields in the encoded
 	// object are in terms of the wire object's type, which might be in a
-	// different order (but will have the same fields).
-	v := *od.encoded.Field(od.rte.FieldOrder[slot])
+	// different order (but will have the same fields, unless fields were
+	// added or removed by a SchemaChange).
+	idx := od.rte.FieldOrder[slot]
+	if idx < 0 {
+		// The field was added after the state was saved. Leave it as the
+		// zero value, which is what the SchemaChange.Upgrade function
+		// expects.
+		return
+	}
+	v := *od.encoded.Field(idx)
 	od.ds.decodeObject(od.ods, objPtr.Elem(), v)
 	if wait {
 		// Mark this individual object a blocker.
@@ -405,6 +413,12 @@ func (ds *decodeState) decodeStruct(ods *objectDecodeState, obj reflect.Value, e
 	}
 	ds.stats.start(ods.typ)
 	defer ds.stats.done()
+	// Queue schema upgrades before any callbacks added by the loader, so
+	// that they are executed first.
+	for _, upgrade := range rte.Upgrades {
+		objPtr := obj.Addr().Interface()
+		ods.addCallback(userCallback(func() { upgrade(objPtr) }))
+	}
 	if sl, ok := obj.Addr().Interface().(SaverLoader); ok {
 		// Note: may be a registered empty struct which does not
 		// implement the saver/loader interfaces.
@@ -669,7 +683,12 @@ func (ds *decodeState) Load(obj reflect.Value) {
 		}
 	}
 
-	// Check if we have any deferred objects.
+	// Check if we have any deferred objects. If fields were removed by a
+	// SchemaChange, then objects that were only referred to by those fields
+	// are never loaded; these are discarded.
+	if ds.types.droppedFields {
+		clear(ds.deferred)
+	}
 	numDeferred := 0
 	for id, encoded := range ds.deferred {
 		numDeferred++
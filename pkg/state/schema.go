@@ -0,0 +1,181 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Schema versions
+//
+// By default, a type can only be loaded if its saved fields exactly match its
+// current fields. A SchemaChange registered for a type allows state saved
+// before the change to be loaded: fields added by the change may be missing
+// from saved state, and fields removed by the change may be present in saved
+// state.
+//
+// Each SchemaChange has a version, and the current schema version is the
+// greatest version of any registered SchemaChange. Callers of Save should
+// record the current schema version alongside saved state, and callers of
+// Load should provide it using WithSavedSchemaVersion. Only changes with
+// versions greater than the saved schema version are applied during Load, so
+// that state saved after a change must match the type exactly.
+//
+// SchemaChanges for versions older than the oldest version that must be
+// loadable may be deleted along with the code that handles them.
+
+// SchemaVersion is a version of the saved representation of registered types.
+// Version 0 represents state saved before any SchemaChange.
+type SchemaVersion uint32
+
+// SchemaChange describes a change to the saved fields of a type.
+type SchemaChange struct {
+	// Version is the schema version introduced by the change. It must be
+	// greater than 0.
+	Version SchemaVersion
+
+	// AddedFields are fields added by the change. They are left at their
+	// zero values when loading state saved before the change, unless
+	// Upgrade sets them.
+	AddedFields []string
+
+	// RemovedFields are fields removed by the change. Their values are
+	// discarded when loading state saved before the change, along with any
+	// objects that are only reachable from them.
+	RemovedFields []string
+
+	// If Upgrade is not nil, it is called with a pointer to each object of
+	// the type that is loaded from state saved before the change. It is
+	// called once the object has been loaded, before the object's own
+	// AfterLoad callbacks, and may set AddedFields based on the loaded
+	// values of other fields.
+	Upgrade func(objPtr any)
+}
+
+var (
+	// schemaChanges maps type names to registered SchemaChanges, in
+	// increasing order of version.
+	schemaChanges = map[string][]SchemaChange{}
+
+	// currentSchemaVersion is the greatest version of any registered
+	// SchemaChange.
+	currentSchemaVersion SchemaVersion
+)
+
+// RegisterSchemaChange registers a change to the saved fields of the type
+// with the given name (as returned by Type.StateTypeName).
+//
+// This must be called on init, before any calls to Save or Load.
+func RegisterSchemaChange(typeName string, c SchemaChange) {
+	if c.Version == 0 {
+		panic(fmt.Sprintf("schema change for type %q has version 0", typeName))
+	}
+	if len(c.AddedFields) == 0 && len(c.RemovedFields) == 0 {
+		panic(fmt.Sprintf("schema change for type %q at version %d changes no fields", typeName, c.Version))
+	}
+	changes := schemaChanges[typeName]
+	i, found := slices.BinarySearchFunc(changes, c.Version, func(c SchemaChange, v SchemaVersion) int {
+		return int(c.Version) - int(v)
+	})
+	if found {
+		panic(fmt.Sprintf("duplicate schema change for type %q at version %d", typeName, c.Version))
+	}
+	schemaChanges[typeName] = slices.Insert(changes, i, c)
+	currentSchemaVersion = max(currentSchemaVersion, c.Version)
+}
+
+// CurrentSchemaVersion returns the schema version of state saved by Save.
+func CurrentSchemaVersion() SchemaVersion {
+	return currentSchemaVersion
+}
+
+// contextID is the state package's type for context.Context.Value keys.
+type contextID int
+
+const (
+	// CtxSavedSchemaVersion is a Context.Value key for the SchemaVersion of
+	// state being loaded.
+	CtxSavedSchemaVersion contextID = iota
+)
+
+// WithSavedSchemaVersion returns a copy of ctx that causes Load to apply
+// SchemaChanges with versions greater than v.
+func WithSavedSchemaVersion(ctx context.Context, v SchemaVersion) context.Context {
+	return context.WithValue(ctx, CtxSavedSchemaVersion, v)
+}
+
+// savedSchemaVersionFromContext returns the SchemaVersion of state being
+// loaded with ctx. If none was provided, the state is assumed to be saved by
+// the current schema version, so that no changes are applied.
+func savedSchemaVersionFromContext(ctx context.Context) SchemaVersion {
+	if v := ctx.Value(CtxSavedSchemaVersion); v != nil {
+		return v.(SchemaVersion)
+	}
+	return currentSchemaVersion
+}
+
+// schemaDiff is the difference between the fields of a type in saved state and
+// its current fields, allowed by SchemaChanges.
+type schemaDiff struct {
+	// added contains the added fields.
+	added map[string]struct{}
+
+	// removed contains the removed fields.
+	removed map[string]struct{}
+
+	// upgrades are the Upgrade functions of the applied changes, in order
+	// of version.
+	upgrades []func(any)
+}
+
+// schemaDiffFor returns the schemaDiff for the given type when loading state
+// saved with schema version saved, or nil if no changes apply.
+func schemaDiffFor(typeName string, saved SchemaVersion) *schemaDiff {
+	var d *schemaDiff
+	for _, c := range schemaChanges[typeName] {
+		if c.Version <= saved {
+			continue
+		}
+		if d == nil {
+			d = &schemaDiff{
+				added:   make(map[string]struct{}),
+				removed: make(map[string]struct{}),
+			}
+		}
+		// A field added by one change and removed by a later one (or vice
+		// versa) is neither in saved state nor in the current type, so it
+		// doesn't need to be tracked.
+		for _, f := range c.AddedFields {
+			if _, ok := d.removed[f]; ok {
+				delete(d.removed, f)
+			} else {
+				d.added[f] = struct{}{}
+			}
+		}
+		for _, f := range c.RemovedFields {
+			if _, ok := d.added[f]; ok {
+				delete(d.added, f)
+			} else {
+				d.removed[f] = struct{}{}
+			}
+		}
+		if c.Upgrade != nil {
+			d.upgrades = append(d.upgrades, c.Upgrade)
+		}
+	}
+	return d
+}
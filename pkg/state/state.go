@@ -107,12 +107,16 @@ func Save(ctx context.Context, w io.Writer, rootPtr any) (Stats, error) {
 }
 
 // Load loads a checkpoint.
+//
+// If the checkpoint was saved with an older SchemaVersion, ctx should be
+// derived from WithSavedSchemaVersion so that the corresponding SchemaChanges
+// are applied.
 func Load(ctx context.Context, r io.Reader, rootPtr any) (Stats, error) {
 	// Create the decoding state.
 	ds := decodeState{
 		ctx:      ctx,
 		r:        wire.Reader{Reader: r},
-		types:    makeTypeDecodeDatabase(),
+		types:    makeTypeDecodeDatabase(savedSchemaVersionFromContext(ctx)),
 		deferred: make(map[objectID]wire.Object),
 	}
 
@@ -53,6 +53,7 @@ import (
 	"fmt"
 	"hash"
 	"io"
+	"strconv"
 	"strings"
 	"time"
 
@@ -99,10 +100,47 @@ const (
 	CompressionLevelFlateBestSpeed = CompressionLevel("flate-best-speed")
 	// CompressionLevelNone represents the absence of any compression on an image.
 	CompressionLevelNone = CompressionLevel("none")
+	// CompressionLevelZstd represents zstd algorithm at its default level
+	// (zstdDefaultLevel). Other levels are represented by ZstdCompressionLevel.
+	CompressionLevelZstd = CompressionLevel("zstd")
 	// CompressionLevelDefault represents the default compression level.
 	CompressionLevelDefault = CompressionLevelNone
 )
 
+const (
+	// zstdPrefix is the prefix of zstd compression levels other than
+	// CompressionLevelZstd.
+	zstdPrefix = "zstd-"
+
+	// zstdDefaultLevel is the zstd level used by CompressionLevelZstd, which
+	// is also the default of the zstd command line tool.
+	zstdDefaultLevel = 3
+)
+
+// ZstdCompressionLevel returns the CompressionLevel representing zstd
+// algorithm at the given level, between compressio.MinZstdLevel and
+// compressio.MaxZstdLevel.
+func ZstdCompressionLevel(level int) CompressionLevel {
+	return CompressionLevel(zstdPrefix + strconv.Itoa(level))
+}
+
+// zstdLevel returns the zstd level represented by c, and false if c does not
+// represent zstd compression.
+func (c CompressionLevel) zstdLevel() (int, bool) {
+	if c == CompressionLevelZstd {
+		return zstdDefaultLevel, true
+	}
+	val, ok := strings.CutPrefix(string(c), zstdPrefix)
+	if !ok {
+		return 0, false
+	}
+	level, err := strconv.Atoi(val)
+	if err != nil || level < compressio.MinZstdLevel || level > compressio.MaxZstdLevel {
+		return 0, false
+	}
+	return level, true
+}
+
 func (c CompressionLevel) String() string {
 	return string(c)
 }
@@ -122,6 +160,9 @@ func CompressionLevelFromString(val string) (CompressionLevel, error) {
 	case "":
 		return CompressionLevelDefault, nil
 	default:
+		if _, ok := CompressionLevel(val).zstdLevel(); ok {
+			return CompressionLevel(val), nil
+		}
 		return CompressionLevelNone, ErrInvalidFlags
 	}
 }
@@ -219,6 +260,9 @@ func NewWriter(w io.Writer, key []byte, metadata map[string]string) (io.WriteClo
 	if compression == CompressionLevelFlateBestSpeed {
 		return compressio.NewWriter(w, key, stateFileChunkSize, flate.BestSpeed)
 	}
+	if level, ok := compression.zstdLevel(); ok {
+		return compressio.NewZstdWriter(w, key, stateFileChunkSize, level)
+	}
 
 	return compressio.NewSimpleWriter(w, key, stateFileChunkSize), nil
 }
@@ -330,6 +374,10 @@ func NewReader(r io.ReadCloser, key []byte) (io.ReadCloser, map[string]string, e
 	case CompressionLevelNone:
 		cr = compressio.NewSimpleReader(r, key)
 	default:
+		if _, ok := compression.zstdLevel(); ok {
+			cr, err = compressio.NewZstdReader(r, key)
+			break
+		}
 		// Should never occur, as it has the default path.
 		return nil, nil, fmt.Errorf("metadata contains invalid compression flag value: %v", compression)
 	}
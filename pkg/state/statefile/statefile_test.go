@@ -46,6 +46,8 @@ func TestStatefile(t *testing.T) {
 	compression := map[string]CompressionLevel{
 		"none":       CompressionLevelNone,
 		"compressed": CompressionLevelFlateBestSpeed,
+		"zstd":       CompressionLevelZstd,
+		"zstd-1":     ZstdCompressionLevel(1),
 	}
 
 	cases := []testCase{
@@ -304,3 +306,32 @@ func BenchmarkRead1MNoncompressible(b *testing.B) {
 func init() {
 	runtime.GOMAXPROCS(runtime.NumCPU())
 }
+
+func TestCompressionLevelFromString(t *testing.T) {
+	for _, tc := range []struct {
+		val   string
+		want  CompressionLevel
+		valid bool
+	}{
+		{val: "", want: CompressionLevelDefault, valid: true},
+		{val: "none", want: CompressionLevelNone, valid: true},
+		{val: "flate-best-speed", want: CompressionLevelFlateBestSpeed, valid: true},
+		{val: "zstd", want: CompressionLevelZstd, valid: true},
+		{val: "zstd-1", want: ZstdCompressionLevel(1), valid: true},
+		{val: "zstd-22", want: ZstdCompressionLevel(22), valid: true},
+		{val: "zstd-0", valid: false},
+		{val: "zstd-23", valid: false},
+		{val: "zstd-fast", valid: false},
+		{val: "gzip", valid: false},
+	} {
+		t.Run(tc.val, func(t *testing.T) {
+			got, err := CompressionLevelFromString(tc.val)
+			if (err == nil) != tc.valid {
+				t.Fatalf("CompressionLevelFromString(%q) got err %v, want valid=%t", tc.val, err, tc.valid)
+			}
+			if tc.valid && got != tc.want {
+				t.Errorf("CompressionLevelFromString(%q) = %q, want %q", tc.val, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"fmt"
+
+	"gvisor.dev/gvisor/pkg/state"
+)
+
+// schemaStructFields are the fields saved and loaded by schemaStruct. Tests
+// change this between Save and Load to simulate a change to the type.
+var schemaStructFields = []string{"Kept", "Added"}
+
+// schemaStruct is a type whose saved fields can change. It is not generated
+// by stateify, since its fields are variable.
+type schemaStruct struct {
+	Kept    int
+	Removed *int
+	Added   int
+
+	// addedAtAfterLoad is the value of Added when AfterLoad callbacks run.
+	addedAtAfterLoad int
+}
+
+// StateTypeName implements state.Type.StateTypeName.
+func (*schemaStruct) StateTypeName() string {
+	return "pkg/state/tests.schemaStruct"
+}
+
+// StateFields implements state.Type.StateFields.
+func (*schemaStruct) StateFields() []string {
+	return schemaStructFields
+}
+
+func (s *schemaStruct) fieldPtr(name string) any {
+	switch name {
+	case "Kept":
+		return &s.Kept
+	case "Removed":
+		return &s.Removed
+	case "Added":
+		return &s.Added
+	default:
+		panic(fmt.Sprintf("unknown field %q", name))
+	}
+}
+
+// StateSave implements state.SaverLoader.StateSave.
+func (s *schemaStruct) StateSave(m state.Sink) {
+	for i, name := range schemaStructFields {
+		m.Save(i, s.fieldPtr(name))
+	}
+}
+
+// StateLoad implements state.SaverLoader.StateLoad.
+func (s *schemaStruct) StateLoad(_ context.Context, m state.Source) {
+	for i, name := range schemaStructFields {
+		m.Load(i, s.fieldPtr(name))
+	}
+	m.AfterLoad(func() { s.addedAtAfterLoad = s.Added })
+}
+
+func init() {
+	state.Register((*schemaStruct)(nil))
+	state.RegisterSchemaChange("pkg/state/tests.schemaStruct", state.SchemaChange{
+		Version:       1,
+		AddedFields:   []string{"Added"},
+		RemovedFields: []string{"Removed"},
+		Upgrade: func(objPtr any) {
+			s := objPtr.(*schemaStruct)
+			s.Added = s.Kept + 1
+		},
+	})
+}
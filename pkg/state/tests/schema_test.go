@@ -0,0 +1,99 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"gvisor.dev/gvisor/pkg/state"
+)
+
+// saveSchemaStruct saves s with the given fields.
+func saveSchemaStruct(t *testing.T, s *schemaStruct, fields []string) []byte {
+	t.Helper()
+	old := schemaStructFields
+	schemaStructFields = fields
+	defer func() { schemaStructFields = old }()
+	var buf bytes.Buffer
+	if _, err := state.Save(context.Background(), &buf, s); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestSchemaChange(t *testing.T) {
+	if got := state.CurrentSchemaVersion(); got != 1 {
+		t.Fatalf("CurrentSchemaVersion() = %d, want 1", got)
+	}
+
+	removed := 42
+	oldState := saveSchemaStruct(t, &schemaStruct{Kept: 1, Removed: &removed}, []string{"Kept", "Removed"})
+
+	t.Run("upgrade", func(t *testing.T) {
+		var s schemaStruct
+		ctx := state.WithSavedSchemaVersion(context.Background(), 0)
+		if _, err := state.Load(ctx, bytes.NewReader(oldState), &s); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		want := schemaStruct{Kept: 1, Added: 2, addedAtAfterLoad: 2}
+		if s != want {
+			t.Errorf("got %+v, want %+v", s, want)
+		}
+	})
+
+	t.Run("saved-after-change", func(t *testing.T) {
+		// State claiming to be saved after the change must match exactly.
+		var s schemaStruct
+		ctx := state.WithSavedSchemaVersion(context.Background(), 1)
+		if _, err := state.Load(ctx, bytes.NewReader(oldState), &s); err == nil {
+			t.Errorf("Load succeeded unexpectedly: %+v", s)
+		}
+	})
+
+	t.Run("no-saved-version", func(t *testing.T) {
+		// Without a saved version, state is assumed to be current.
+		var s schemaStruct
+		if _, err := state.Load(context.Background(), bytes.NewReader(oldState), &s); err == nil {
+			t.Errorf("Load succeeded unexpectedly: %+v", s)
+		}
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		// Upgrade is not applied to state that already has the new fields.
+		newState := saveSchemaStruct(t, &schemaStruct{Kept: 1, Added: 10}, []string{"Kept", "Added"})
+		var s schemaStruct
+		ctx := state.WithSavedSchemaVersion(context.Background(), 0)
+		if _, err := state.Load(ctx, bytes.NewReader(newState), &s); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		want := schemaStruct{Kept: 1, Added: 10, addedAtAfterLoad: 10}
+		if s != want {
+			t.Errorf("got %+v, want %+v", s, want)
+		}
+	})
+}
+
+func TestRegisterSchemaChangeDuplicate(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("RegisterSchemaChange with duplicate version did not panic")
+		}
+	}()
+	c := state.SchemaChange{Version: 1, AddedFields: []string{"A"}}
+	state.RegisterSchemaChange("pkg/state/tests.duplicateSchemaChange", c)
+	state.RegisterSchemaChange("pkg/state/tests.duplicateSchemaChange", c)
+}
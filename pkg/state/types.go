@@ -16,6 +16,7 @@ package state
 
 import (
 	"reflect"
+	"slices"
 	"sort"
 
 	"gvisor.dev/gvisor/pkg/state/wire"
@@ -52,8 +53,16 @@ type typeEntry struct {
 // reconciledTypeEntry is a reconciled entry in the typeDatabase.
 type reconciledTypeEntry struct {
 	wire.Type
-	LocalType  reflect.Type
+	LocalType reflect.Type
+
+	// FieldOrder maps each local field to the index of the corresponding
+	// encoded field, or -1 if the field was added by a SchemaChange and is
+	// not encoded.
 	FieldOrder []int
+
+	// Upgrades are the SchemaChange.Upgrade functions to apply to each
+	// decoded object of this type.
+	Upgrades []func(any)
 }
 
 // typeEncodeDatabase is an internal TypeInfo database for encoding.
@@ -82,11 +91,20 @@ type typeDecodeDatabase struct {
 	// used to lookup types by name, since they may not be reconciled and
 	// there's little value to deleting from this map.
 	pending []*wire.Type
+
+	// savedSchemaVersion is the SchemaVersion of the state being decoded.
+	savedSchemaVersion SchemaVersion
+
+	// droppedFields is true if any encoded fields were discarded because
+	// they were removed by a SchemaChange.
+	droppedFields bool
 }
 
 // makeTypeDecodeDatabase makes a typeDatabase.
-func makeTypeDecodeDatabase() typeDecodeDatabase {
-	return typeDecodeDatabase{}
+func makeTypeDecodeDatabase(savedSchemaVersion SchemaVersion) typeDecodeDatabase {
+	return typeDecodeDatabase{
+		savedSchemaVersion: savedSchemaVersion,
+	}
 }
 
 // lookupNameFields extracts the name and fields from an object.
@@ -240,6 +258,13 @@ func (tbd *typeDecodeDatabase) Lookup(id typeID, typ reflect.Type) *reconciledTy
 		},
 		LocalType: typ,
 	}
+	// If the fields differ in a way described by registered SchemaChanges,
+	// reconcile them using the changes.
+	if diff := schemaDiffFor(name, tbd.savedSchemaVersion); diff != nil && !slices.Equal(fields, pending.Fields) {
+		tbd.reconcileSchema(rte, pending, diff)
+		tbd.byID[id-1] = rte
+		return rte
+	}
 	// If there are zero or one fields, then we skip allocating the field
 	// slice. There is special handling for decoding in this case. If the
 	// field name does not match, it will be caught in the general purpose
@@ -288,6 +313,37 @@ func (tbd *typeDecodeDatabase) Lookup(id typeID, typ reflect.Type) *reconciledTy
 	return rte
 }
 
+// reconcileSchema sets rte.FieldOrder for an encoded type whose fields
+// differ from the local type, as allowed by diff.
+func (tbd *typeDecodeDatabase) reconcileSchema(rte *reconciledTypeEntry, pending *wire.Type, diff *schemaDiff) {
+	encoded := make(map[string]int, len(pending.Fields))
+	for j, name := range pending.Fields {
+		if _, ok := diff.removed[name]; ok {
+			tbd.droppedFields = true
+			continue
+		}
+		encoded[name] = j
+	}
+	rte.FieldOrder = make([]int, len(rte.Fields))
+	for i, name := range rte.Fields {
+		if j, ok := encoded[name]; ok {
+			rte.FieldOrder[i] = j
+			delete(encoded, name)
+			continue
+		}
+		if _, ok := diff.added[name]; !ok {
+			Failf("type %q has mismatched fields: %v (decode) and %v (encode), and field %q was not added by a schema change after version %d",
+				rte.Name, rte.Fields, pending.Fields, name, tbd.savedSchemaVersion)
+		}
+		rte.FieldOrder[i] = -1
+	}
+	for name := range encoded {
+		Failf("type %q has mismatched fields: %v (decode) and %v (encode), and field %q was not removed by a schema change after version %d",
+			rte.Name, rte.Fields, pending.Fields, name, tbd.savedSchemaVersion)
+	}
+	rte.Upgrades = diff.upgrades
+}
+
 // interfaceType defines all interfaces.
 const interfaceType = "interface"
 
@@ -45,14 +45,14 @@ const ()
 // +checklocksignore
 func (m *Mutex) Lock() {
 	locking.AddGLock(genericMarkIndex, -1)
-	m.mu.Lock()
+	m.lock(-1)
 }
 
 // NestedLock locks m knowing that another lock of the same type is held.
 // +checklocksignore
 func (m *Mutex) NestedLock(i lockNameIndex) {
 	locking.AddGLock(genericMarkIndex, int(i))
-	m.mu.Lock()
+	m.lock(int(i))
 }
 
 // Unlock unlocks m.
@@ -69,6 +69,18 @@ func (m *Mutex) NestedUnlock(i lockNameIndex) {
 	m.mu.Unlock()
 }
 
+// lock locks m.mu, recording contention if contention profiling is enabled.
+// +checklocksignore
+func (m *Mutex) lock(i int) {
+	if locking.ContentionProfilingEnabled() {
+		if !m.mu.TryLock() {
+			locking.LockContended(genericMarkIndex, i, m.mu.Lock)
+		}
+		return
+	}
+	m.mu.Lock()
+}
+
 // DO NOT REMOVE: The following function is automatically replaced.
 func initLockNames() {}
 
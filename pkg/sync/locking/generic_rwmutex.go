@@ -43,14 +43,14 @@ const ()
 // +checklocksignore
 func (m *RWMutex) Lock() {
 	locking.AddGLock(genericMarkIndex, -1)
-	m.mu.Lock()
+	m.lock(-1)
 }
 
 // NestedLock locks m knowing that another lock of the same type is held.
 // +checklocksignore
 func (m *RWMutex) NestedLock(i lockNameIndex) {
 	locking.AddGLock(genericMarkIndex, int(i))
-	m.mu.Lock()
+	m.lock(int(i))
 }
 
 // Unlock unlocks m.
@@ -71,6 +71,12 @@ func (m *RWMutex) NestedUnlock(i lockNameIndex) {
 // +checklocksignore
 func (m *RWMutex) RLock() {
 	locking.AddGLock(genericMarkIndex, -1)
+	if locking.ContentionProfilingEnabled() {
+		if !m.mu.TryRLock() {
+			locking.LockContended(genericMarkIndex, -1, m.mu.RLock)
+		}
+		return
+	}
 	m.mu.RLock()
 }
 
@@ -99,6 +105,19 @@ func (m *RWMutex) DowngradeLock() {
 	m.mu.DowngradeLock()
 }
 
+// lock locks m.mu for writing, recording contention if contention profiling
+// is enabled.
+// +checklocksignore
+func (m *RWMutex) lock(i int) {
+	if locking.ContentionProfilingEnabled() {
+		if !m.mu.TryLock() {
+			locking.LockContended(genericMarkIndex, i, m.mu.Lock)
+		}
+		return
+	}
+	m.mu.Lock()
+}
+
 var genericMarkIndex *locking.MutexClass
 
 // DO NOT REMOVE: The following function is automatically replaced.
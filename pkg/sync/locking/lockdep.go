@@ -19,11 +19,16 @@ package locking
 
 import (
 	"fmt"
+	"io"
 	"reflect"
+	"sort"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"gvisor.dev/gvisor/pkg/goid"
 	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sync"
 )
 
 // NewMutexClass allocates a new mutex class.
@@ -37,9 +42,20 @@ func NewMutexClass(t reflect.Type, lockNames []string) *MutexClass {
 		c.nestedLockClasses[i] = NewMutexClass(t, nil)
 		c.nestedLockClasses[i].lockName = lockNames[i]
 	}
+	classesMu.Lock()
+	classes = append(classes, c)
+	classesMu.Unlock()
 	return c
 }
 
+// classesMu protects classes. It is a plain mutex, since it can't be validated
+// by itself.
+var classesMu sync.Mutex
+
+// classes are all mutex classes created by NewMutexClass, including nested
+// lock classes.
+var classes []*MutexClass
+
 // MutexClass describes dependencies of a specific class.
 type MutexClass struct {
 	// The type of the mutex.
@@ -59,6 +75,15 @@ type MutexClass struct {
 	// simultaneously.
 	// Maps one-to-one with nestedLockNames.
 	nestedLockClasses []*MutexClass
+
+	// acquisitions is the number of times a lock of this class was taken
+	// while contention profiling was enabled.
+	acquisitions atomic.Uint64
+	// contentions is the number of acquisitions that had to wait for the
+	// lock.
+	contentions atomic.Uint64
+	// waitNanos is the total time spent waiting for contended locks.
+	waitNanos atomic.Int64
 }
 
 func (m *MutexClass) String() string {
@@ -124,6 +149,9 @@ func AddGLock(class *MutexClass, lockNameIndex int) {
 	if lockNameIndex != -1 {
 		class = class.nestedLockClasses[lockNameIndex]
 	}
+	if contentionProfiling.Load() {
+		class.acquisitions.Add(1)
+	}
 	currentLocks := routineLocks.Load(gid)
 	if currentLocks == nil {
 		locks := goroutineLocks(make(map[*MutexClass]bool))
@@ -189,3 +217,109 @@ func DelGLock(class *MutexClass, lockNameIndex int) {
 		routineLocks.Store(gid, nil)
 	}
 }
+
+// contentionProfiling indicates whether lock contention is recorded.
+var contentionProfiling atomic.Bool
+
+// EnableContentionProfiling enables or disables recording of lock contention
+// statistics. Statistics are reset when profiling is enabled.
+func EnableContentionProfiling(enable bool) error {
+	if enable && !contentionProfiling.Load() {
+		classesMu.Lock()
+		for _, c := range classes {
+			c.acquisitions.Store(0)
+			c.contentions.Store(0)
+			c.waitNanos.Store(0)
+		}
+		classesMu.Unlock()
+	}
+	contentionProfiling.Store(enable)
+	return nil
+}
+
+// ContentionProfilingEnabled returns true if lock contention is recorded.
+func ContentionProfilingEnabled() bool {
+	return contentionProfiling.Load()
+}
+
+// LockContended calls lock, which is expected to block, and records the time
+// spent waiting against the lock class.
+func LockContended(class *MutexClass, lockNameIndex int, lock func()) {
+	if lockNameIndex != -1 {
+		class = class.nestedLockClasses[lockNameIndex]
+	}
+	start := time.Now()
+	lock()
+	class.contentions.Add(1)
+	class.waitNanos.Add(int64(time.Since(start)))
+}
+
+// Contention returns the contention statistics of all lock classes that were
+// acquired at least once, sorted by decreasing total wait time.
+func Contention() ([]ContentionStats, error) {
+	classesMu.Lock()
+	defer classesMu.Unlock()
+	var stats []ContentionStats
+	for _, c := range classes {
+		s := ContentionStats{
+			Class:        c.String(),
+			Acquisitions: c.acquisitions.Load(),
+			Contentions:  c.contentions.Load(),
+			Wait:         time.Duration(c.waitNanos.Load()),
+		}
+		if s.Acquisitions == 0 && s.Contentions == 0 {
+			continue
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Wait != stats[j].Wait {
+			return stats[i].Wait > stats[j].Wait
+		}
+		return stats[i].Class < stats[j].Class
+	})
+	return stats, nil
+}
+
+// WriteDOT writes the observed lock ordering graph in DOT format. There is an
+// edge from A to B if a lock of class B was taken while holding a lock of
+// class A. Classes that have never been ordered against another class are
+// omitted. If contention profiling is enabled, nodes are annotated with
+// contention statistics.
+func WriteDOT(w io.Writer) error {
+	classesMu.Lock()
+	defer classesMu.Unlock()
+
+	var edges []string
+	nodes := make(map[*MutexClass]struct{})
+	for _, c := range classes {
+		c.ancestors.RangeRepeatable(func(parent *MutexClass, _ *string) bool {
+			nodes[parent] = struct{}{}
+			nodes[c] = struct{}{}
+			edges = append(edges, fmt.Sprintf("\t%q -> %q;\n", parent, c))
+			return true
+		})
+	}
+	sort.Strings(edges)
+
+	var b strings.Builder
+	b.WriteString("digraph lockdep {\n")
+	var names []string
+	for c := range nodes {
+		label := c.String()
+		if n := c.contentions.Load(); n > 0 {
+			label = fmt.Sprintf("%s\\n%d/%d contended, %v", label, n, c.acquisitions.Load(), time.Duration(c.waitNanos.Load()))
+		}
+		names = append(names, fmt.Sprintf("\t%q [label=%q];\n", c, label))
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		b.WriteString(n)
+	}
+	for _, e := range edges {
+		b.WriteString(e)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -18,7 +18,10 @@
 package locking_test
 
 import (
+	"io"
 	"testing"
+
+	"gvisor.dev/gvisor/pkg/sync/locking"
 )
 
 // Ensure that lockdep is not enabled unless "lockdep" tag is set.
@@ -36,3 +39,15 @@ func TestDummy(t *testing.T) {
 	m.Unlock()
 	m2.Unlock()
 }
+
+func TestExportDisabled(t *testing.T) {
+	if err := locking.EnableContentionProfiling(true); err != locking.ErrLockdepDisabled {
+		t.Errorf("EnableContentionProfiling returned %v, want %v", err, locking.ErrLockdepDisabled)
+	}
+	if _, err := locking.Contention(); err != locking.ErrLockdepDisabled {
+		t.Errorf("Contention returned %v, want %v", err, locking.ErrLockdepDisabled)
+	}
+	if err := locking.WriteDOT(io.Discard); err != locking.ErrLockdepDisabled {
+		t.Errorf("WriteDOT returned %v, want %v", err, locking.ErrLockdepDisabled)
+	}
+}
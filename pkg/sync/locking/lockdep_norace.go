@@ -18,6 +18,7 @@
 package locking
 
 import (
+	"io"
 	"reflect"
 )
 
@@ -40,3 +41,31 @@ func AddGLock(*MutexClass, int) {}
 //
 //go:inline
 func DelGLock(*MutexClass, int) {}
+
+// EnableContentionProfiling returns ErrLockdepDisabled without the lockdep
+// tag.
+func EnableContentionProfiling(bool) error {
+	return ErrLockdepDisabled
+}
+
+// ContentionProfilingEnabled always returns false without the lockdep tag.
+//
+//go:inline
+func ContentionProfilingEnabled() bool {
+	return false
+}
+
+// LockContended calls lock without the lockdep tag.
+func LockContended(_ *MutexClass, _ int, lock func()) {
+	lock()
+}
+
+// Contention returns ErrLockdepDisabled without the lockdep tag.
+func Contention() ([]ContentionStats, error) {
+	return nil, ErrLockdepDisabled
+}
+
+// WriteDOT returns ErrLockdepDisabled without the lockdep tag.
+func WriteDOT(io.Writer) error {
+	return ErrLockdepDisabled
+}
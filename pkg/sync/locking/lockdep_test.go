@@ -18,7 +18,12 @@
 package locking_test
 
 import (
+	"bytes"
+	"strings"
 	"testing"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/sync/locking"
 )
 
 func TestReverse(t *testing.T) {
@@ -157,3 +162,60 @@ func TestUnknownLock(t *testing.T) {
 	m2.NestedUnlock(testLockM2)
 	t.Error("An unknown lock has not been detected.")
 }
+
+func TestWriteDOT(t *testing.T) {
+	m := test3Mutex{}
+	m2 := test2RWMutex{}
+	m2.Lock()
+	m.Lock()
+	m.Unlock()
+	m2.Unlock()
+
+	var b bytes.Buffer
+	if err := locking.WriteDOT(&b); err != nil {
+		t.Fatalf("WriteDOT failed: %v", err)
+	}
+	graph := b.String()
+	if !strings.HasPrefix(graph, "digraph lockdep {") {
+		t.Errorf("unexpected graph header:\n%s", graph)
+	}
+	if want := `"locking_test.test2RWMutex" -> "locking_test.test3Mutex";`; !strings.Contains(graph, want) {
+		t.Errorf("graph doesn't contain %q:\n%s", want, graph)
+	}
+}
+
+func TestContention(t *testing.T) {
+	if err := locking.EnableContentionProfiling(true); err != nil {
+		t.Fatalf("EnableContentionProfiling failed: %v", err)
+	}
+	defer locking.EnableContentionProfiling(false)
+
+	m := testMutex{}
+	locked := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		m.Lock()
+		close(locked)
+		time.Sleep(10 * time.Millisecond)
+		m.Unlock()
+		close(done)
+	}()
+	<-locked
+	m.Lock()
+	m.Unlock()
+	<-done
+
+	stats, err := locking.Contention()
+	if err != nil {
+		t.Fatalf("Contention failed: %v", err)
+	}
+	for _, s := range stats {
+		if s.Class == "locking_test.testMutex" {
+			if s.Acquisitions < 2 || s.Contentions < 1 || s.Wait <= 0 {
+				t.Errorf("unexpected stats: %+v", s)
+			}
+			return
+		}
+	}
+	t.Errorf("testMutex not found in stats: %+v", stats)
+}
@@ -0,0 +1,39 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package locking
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrLockdepDisabled is returned by functions that require the validator when
+// built without the lockdep tag.
+var ErrLockdepDisabled = errors.New("lock validator is disabled, use a binary built with the lockdep tag, e.g. //runsc:runsc-race")
+
+// ContentionStats are the contention statistics of a mutex class.
+type ContentionStats struct {
+	// Class is the name of the mutex class.
+	Class string
+
+	// Acquisitions is the number of times a lock of the class was taken.
+	Acquisitions uint64
+
+	// Contentions is the number of acquisitions that had to wait.
+	Contentions uint64
+
+	// Wait is the total time spent waiting for contended locks.
+	Wait time.Duration
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package header
+
+import "encoding/binary"
+
+const (
+	geneveVerOptLenOffset    = 0
+	geneveFlagsOffset        = 1
+	geneveProtocolOffset     = 2
+	geneveVNIOffset          = 4
+	geneveVersionShift       = 6
+	geneveOptLenMask         = 0x3f
+	geneveOptLenBytesPerUnit = 4
+)
+
+const (
+	// GeneveMinimumSize is the size of a Geneve header without options.
+	GeneveMinimumSize = 8
+
+	// GeneveDefaultPort is the IANA assigned UDP port for Geneve, as per RFC
+	// 8926 section 3.3.
+	GeneveDefaultPort = 6081
+
+	// GeneveMaxVNI is the largest Geneve Virtual Network Identifier.
+	GeneveMaxVNI = 1<<24 - 1
+
+	// GeneveProtocolTransparentEthernetBridging is the Protocol Type
+	// indicating that the payload is an Ethernet frame.
+	GeneveProtocolTransparentEthernetBridging = 0x6558
+
+	// GeneveFlagOAM is the O bit, set on control packets.
+	GeneveFlagOAM = 0x80
+
+	// GeneveFlagCritical is the C bit, set when critical options are present.
+	GeneveFlagCritical = 0x40
+)
+
+// Geneve represents a Generic Network Virtualization Encapsulation header
+// stored in a byte array, as per RFC 8926 section 3.4.
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|Ver|  Opt Len  |O|C|    Rsvd.  |          Protocol Type        |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|        Virtual Network Identifier (VNI)       |    Reserved   |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|                                                               |
+//	~                    Variable-Length Options                    ~
+//	|                                                               |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+type Geneve []byte
+
+// Version returns the Ver field.
+func (b Geneve) Version() uint8 {
+	return b[geneveVerOptLenOffset] >> geneveVersionShift
+}
+
+// HeaderLength returns the total length of the header, including options.
+func (b Geneve) HeaderLength() int {
+	return GeneveMinimumSize + int(b[geneveVerOptLenOffset]&geneveOptLenMask)*geneveOptLenBytesPerUnit
+}
+
+// Flags returns the O and C flags.
+func (b Geneve) Flags() uint8 {
+	return b[geneveFlagsOffset] & (GeneveFlagOAM | GeneveFlagCritical)
+}
+
+// Protocol returns the Protocol Type field.
+func (b Geneve) Protocol() uint16 {
+	return binary.BigEndian.Uint16(b[geneveProtocolOffset:])
+}
+
+// VNI returns the Virtual Network Identifier.
+func (b Geneve) VNI() uint32 {
+	return binary.BigEndian.Uint32(b[geneveVNIOffset:]) >> 8
+}
+
+// Encode encodes an option-less Geneve header carrying an Ethernet frame
+// with the given VNI.
+func (b Geneve) Encode(vni uint32) {
+	clear(b[:GeneveMinimumSize])
+	binary.BigEndian.PutUint16(b[geneveProtocolOffset:], GeneveProtocolTransparentEthernetBridging)
+	binary.BigEndian.PutUint32(b[geneveVNIOffset:], vni<<8)
+}
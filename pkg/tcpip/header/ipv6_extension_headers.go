@@ -77,6 +77,26 @@ const (
 	// within an IPv6RoutingExtHdr.
 	ipv6RoutingExtHdrSegmentsLeftIdx = 1
 
+	// IPv6RoutingExtHdrSegmentsLeftOffset is the offset to the Segments Left
+	// field from the start of a Routing extension header.
+	IPv6RoutingExtHdrSegmentsLeftOffset = 3
+
+	// ipv6RoutingExtHdrRoutingTypeIdx is the index to the Routing Type field
+	// within an IPv6RoutingExtHdr.
+	ipv6RoutingExtHdrRoutingTypeIdx = 0
+
+	// IPv6RoutingTypeSegmentRouting is the Routing Type of a Segment Routing
+	// Header, as per RFC 8754 section 2.
+	IPv6RoutingTypeSegmentRouting = 4
+
+	// ipv6SRHLastEntryIdx is the index to the Last Entry field within an
+	// IPv6RoutingExtHdr holding a Segment Routing Header.
+	ipv6SRHLastEntryIdx = 2
+
+	// ipv6SRHSegmentListIdx is the index to the first entry of the Segment
+	// List within an IPv6RoutingExtHdr holding a Segment Routing Header.
+	ipv6SRHSegmentListIdx = 6
+
 	// IPv6FragmentExtHdrLength is the length of an IPv6 extension header, in
 	// bytes.
 	IPv6FragmentExtHdrLength = 8
@@ -193,6 +213,12 @@ func (i ipv6OptionsExtHdr) Release() {
 	}
 }
 
+// Length returns the length of the extension header in bytes, including the
+// Next Header and Hdr Ext Len fields.
+func (i ipv6OptionsExtHdr) Length() int {
+	return i.buf.Size() + ipv6ExtHdrLenBytesPerUnit - ipv6ExtHdrLenBytesExcluded
+}
+
 // Iter returns an iterator over the IPv6 extension header options held in b.
 func (i ipv6OptionsExtHdr) Iter() IPv6OptionsExtHdrOptionsIterator {
 	it := IPv6OptionsExtHdrOptionsIterator{}
@@ -450,6 +476,35 @@ func (b IPv6RoutingExtHdr) SegmentsLeft() uint8 {
 	return b.Buf.AsSlice()[ipv6RoutingExtHdrSegmentsLeftIdx]
 }
 
+// RoutingType returns the Routing Type field.
+func (b IPv6RoutingExtHdr) RoutingType() uint8 {
+	return b.Buf.AsSlice()[ipv6RoutingExtHdrRoutingTypeIdx]
+}
+
+// SRHLastEntry returns the Last Entry field of a Segment Routing Header.
+//
+// Only meaningful if b's Routing Type is IPv6RoutingTypeSegmentRouting.
+func (b IPv6RoutingExtHdr) SRHLastEntry() uint8 {
+	return b.Buf.AsSlice()[ipv6SRHLastEntryIdx]
+}
+
+// SRHSegmentCapacity returns the number of Segment List entries that fit in
+// the Segment Routing Header held in b.
+func (b IPv6RoutingExtHdr) SRHSegmentCapacity() int {
+	n := b.Buf.Size() - ipv6SRHSegmentListIdx
+	if n < 0 {
+		return 0
+	}
+	return n / IPv6AddressSize
+}
+
+// SRHSegment returns the Segment List entry at index i of a Segment Routing
+// Header. i must be less than b.SRHSegmentCapacity().
+func (b IPv6RoutingExtHdr) SRHSegment(i int) tcpip.Address {
+	start := ipv6SRHSegmentListIdx + i*IPv6AddressSize
+	return tcpip.AddrFrom16Slice(b.Buf.AsSlice()[start:][:IPv6AddressSize])
+}
+
 // IPv6FragmentExtHdr is a buffer holding the Fragment extension header specific
 // data as outlined in RFC 8200 section 4.5.
 //
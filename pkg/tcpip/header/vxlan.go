@@ -0,0 +1,66 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package header
+
+import "encoding/binary"
+
+const (
+	vxlanFlagsOffset = 0
+	vxlanVNIOffset   = 4
+
+	// vxlanFlagVNI is the I flag, which must be set for a valid VNI.
+	vxlanFlagVNI = 0x08
+)
+
+const (
+	// VXLANSize is the size of a VXLAN header.
+	VXLANSize = 8
+
+	// VXLANDefaultPort is the IANA assigned UDP port for VXLAN, as per RFC
+	// 7348 section 5.
+	VXLANDefaultPort = 4789
+
+	// VXLANMaxVNI is the largest VXLAN Network Identifier.
+	VXLANMaxVNI = 1<<24 - 1
+)
+
+// VXLAN represents a Virtual eXtensible Local Area Network header stored in a
+// byte array, as per RFC 7348 section 5.
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|R|R|R|R|I|R|R|R|            Reserved                           |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|                VXLAN Network Identifier (VNI) |   Reserved    |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+type VXLAN []byte
+
+// IsValid returns true if the I flag is set, indicating the VNI is valid.
+func (b VXLAN) IsValid() bool {
+	return len(b) >= VXLANSize && b[vxlanFlagsOffset]&vxlanFlagVNI != 0
+}
+
+// VNI returns the VXLAN Network Identifier.
+func (b VXLAN) VNI() uint32 {
+	return binary.BigEndian.Uint32(b[vxlanVNIOffset:]) >> 8
+}
+
+// Encode encodes a VXLAN header with the given VNI.
+func (b VXLAN) Encode(vni uint32) {
+	clear(b[:VXLANSize])
+	b[vxlanFlagsOffset] = vxlanFlagVNI
+	binary.BigEndian.PutUint32(b[vxlanVNIOffset:], vni<<8)
+}
@@ -47,6 +47,7 @@ import (
 	"golang.org/x/sys/unix"
 	"gvisor.dev/gvisor/pkg/atomicbitops"
 	"gvisor.dev/gvisor/pkg/buffer"
+	"gvisor.dev/gvisor/pkg/log"
 	"gvisor.dev/gvisor/pkg/rawfile"
 	"gvisor.dev/gvisor/pkg/sync"
 	"gvisor.dev/gvisor/pkg/tcpip"
@@ -89,6 +90,12 @@ const (
 	// primary use-case for this is runsc which uses an AF_PACKET FD to
 	// receive packets from the veth device.
 	PacketMMap
+	// PacketMMapV3 enables use of a TPACKET_V3 PACKET_RX_RING to receive
+	// packets from the NIC. Unlike PacketMMap, the kernel fills whole
+	// blocks of variable sized frames, so a single wakeup can deliver many
+	// packets. PacketMMapV3 requires that the underlying FD be an
+	// AF_PACKET; if the ring can't be set up, RecvMMsg is used instead.
+	PacketMMapV3
 )
 
 func (p PacketDispatchMode) String() string {
@@ -99,6 +106,8 @@ func (p PacketDispatchMode) String() string {
 		return "RecvMMsg"
 	case PacketMMap:
 		return "PacketMMap"
+	case PacketMMapV3:
+		return "PacketMMapV3"
 	default:
 		return fmt.Sprintf("unknown packet dispatch mode '%d'", p)
 	}
@@ -381,6 +390,15 @@ func createInboundDispatcher(e *endpoint, fd int, isSocket bool, opts *Options)
 			if err != nil {
 				return nil, fmt.Errorf("newPacketMMapDispatcher(%d, %+v) = %v", fd, e, err)
 			}
+		case PacketMMapV3:
+			inboundDispatcher, err = newPacketMMapV3Dispatcher(fd, e, opts)
+			if err != nil {
+				log.Warningf("newPacketMMapV3Dispatcher(%d) = %v, falling back to %s", fd, err, RecvMMsg)
+				inboundDispatcher, err = newRecvMMsgDispatcher(fd, e, opts)
+				if err != nil {
+					return nil, fmt.Errorf("newRecvMMsgDispatcher(%d, %+v) = %v", fd, e, err)
+				}
+			}
 		case RecvMMsg:
 			// If the provided FD is a socket then we optimize
 			// packet reads by using recvmmsg() instead of read() to
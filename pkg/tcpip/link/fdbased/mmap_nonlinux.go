@@ -17,8 +17,14 @@
 
 package fdbased
 
+import "fmt"
+
 // Stubbed out version for non-linux/non-amd64/non-arm64 platforms.
 
 func newPacketMMapDispatcher(fd int, e *endpoint, opts *Options) (linkDispatcher, error) {
 	return nil, nil
 }
+
+func newPacketMMapV3Dispatcher(fd int, e *endpoint, opts *Options) (linkDispatcher, error) {
+	return nil, fmt.Errorf("TPACKET_V3 rings are not supported on this platform")
+}
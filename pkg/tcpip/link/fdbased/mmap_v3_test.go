@@ -0,0 +1,101 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build (linux && amd64) || (linux && arm64)
+// +build linux,amd64 linux,arm64
+
+package fdbased
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+)
+
+// buildBlock lays out packets in a TPACKET_V3 block the same way the kernel
+// does. A packet is truncated to snapLen bytes if snapLen is non-zero.
+func buildBlock(t *testing.T, pkts [][]byte, snapLen int) tPacketBlockDesc {
+	t.Helper()
+	const (
+		firstPkt = 48
+		macOff   = 66
+	)
+	b := make(tPacketBlockDesc, 4096)
+	binary.LittleEndian.PutUint32(b[tpBlockNumPktsOffset:], uint32(len(pkts)))
+	binary.LittleEndian.PutUint32(b[tpBlockOffsetToFirstOffset:], firstPkt)
+	b.setBlockStatus(tpStatusUser)
+	off := firstPkt
+	for i, pkt := range pkts {
+		hdr := b[off:]
+		data := pkt
+		if snapLen != 0 && snapLen < len(pkt) {
+			data = pkt[:snapLen]
+		}
+		binary.LittleEndian.PutUint32(hdr[tp3SnapLenOffset:], uint32(len(data)))
+		binary.LittleEndian.PutUint32(hdr[tp3LenOffset:], uint32(len(pkt)))
+		binary.LittleEndian.PutUint16(hdr[tp3MacOffset:], macOff)
+		copy(hdr[macOff:], data)
+		next := int(tPacketAlign(uintptr(macOff + len(data))))
+		if i == len(pkts)-1 {
+			next = 0
+		}
+		binary.LittleEndian.PutUint32(hdr[tp3NextOffsetOffset:], uint32(next))
+		off += next
+		if off+macOff >= len(b) {
+			t.Fatalf("packets don't fit in a block")
+		}
+	}
+	return b
+}
+
+func TestTPacketV3AppendPackets(t *testing.T) {
+	want := [][]byte{
+		[]byte("first packet"),
+		[]byte("second, somewhat longer, packet"),
+		{},
+		[]byte("last"),
+	}
+	b := buildBlock(t, want, 0)
+	if got := b.blockStatus(); got != tpStatusUser {
+		t.Fatalf("blockStatus() = %d, want %d", got, tpStatusUser)
+	}
+
+	var pkts stack.PacketBufferList
+	defer pkts.Reset()
+	b.appendPackets(&pkts)
+	if pkts.Len() != len(want) {
+		t.Fatalf("got %d packets, want %d", pkts.Len(), len(want))
+	}
+	for i, pkt := range pkts.AsSlice() {
+		if got := pkt.Data().AsRange().ToSlice(); !bytes.Equal(got, want[i]) {
+			t.Errorf("packet %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestTPacketV3AppendPacketsDropsTruncated(t *testing.T) {
+	b := buildBlock(t, [][]byte{[]byte("0123456789"), []byte("0123")}, 4)
+
+	var pkts stack.PacketBufferList
+	defer pkts.Reset()
+	b.appendPackets(&pkts)
+	if pkts.Len() != 1 {
+		t.Fatalf("got %d packets, want 1", pkts.Len())
+	}
+	if got := pkts.AsSlice()[0].Data().AsRange().ToSlice(); !bytes.Equal(got, []byte("0123")) {
+		t.Errorf("packet = %q, want %q", got, "0123")
+	}
+}
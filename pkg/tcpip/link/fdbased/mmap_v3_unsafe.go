@@ -0,0 +1,93 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build (linux && amd64) || (linux && arm64)
+// +build linux,amd64 linux,arm64
+
+package fdbased
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/atomicbitops"
+	"gvisor.dev/gvisor/pkg/tcpip/link/stopfd"
+)
+
+// blockStatus returns the block status field.
+// The status is concurrently updated by the kernel as a result we must
+// use atomic operations to prevent races.
+func (b tPacketBlockDesc) blockStatus() uint32 {
+	statusPtr := unsafe.Pointer(&b[tpBlockStatusOffset])
+	return (*atomicbitops.Uint32)(statusPtr).Load()
+}
+
+// setBlockStatus sets the block status to the provided status.
+// The status is concurrently updated by the kernel as a result we must
+// use atomic operations to prevent races.
+func (b tPacketBlockDesc) setBlockStatus(status uint32) {
+	statusPtr := unsafe.Pointer(&b[tpBlockStatusOffset])
+	(*atomicbitops.Uint32)(statusPtr).Store(status)
+}
+
+func newPacketMMapV3Dispatcher(fd int, e *endpoint, opts *Options) (linkDispatcher, error) {
+	pageSize := unix.Getpagesize()
+	if tpV3BlockSize%pageSize != 0 {
+		return nil, fmt.Errorf("tpV3BlockSize: %d is not page aligned, pagesize: %d", tpV3BlockSize, pageSize)
+	}
+	version := int32(unix.TPACKET_V3)
+	if err := setsockopt(fd, unix.SOL_PACKET, unix.PACKET_VERSION, unsafe.Pointer(&version), unsafe.Sizeof(version)); err != nil {
+		return nil, fmt.Errorf("failed to set PACKET_VERSION to TPACKET_V3: %v", err)
+	}
+	tReq := unix.TpacketReq3{
+		Block_size:     uint32(tpV3BlockSize),
+		Block_nr:       uint32(tpV3BlockNR),
+		Frame_size:     uint32(tpV3FrameSize),
+		Frame_nr:       uint32(tpV3FrameNR),
+		Retire_blk_tov: tpV3RetireTimeout,
+	}
+	// Setup PACKET_RX_RING.
+	if err := setsockopt(fd, unix.SOL_PACKET, unix.PACKET_RX_RING, unsafe.Pointer(&tReq), unsafe.Sizeof(tReq)); err != nil {
+		return nil, fmt.Errorf("failed to enable PACKET_RX_RING: %v", err)
+	}
+	// releaseRing tears down the ring so that packets are queued on the
+	// socket again, which the fallback dispatcher relies on.
+	releaseRing := func() {
+		var noRing unix.TpacketReq3
+		_ = setsockopt(fd, unix.SOL_PACKET, unix.PACKET_RX_RING, unsafe.Pointer(&noRing), unsafe.Sizeof(noRing))
+	}
+	// Let's mmap the blocks.
+	sz := tpV3BlockSize * tpV3BlockNR
+	buf, err := unix.Mmap(fd, 0, sz, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+	if err != nil {
+		releaseRing()
+		return nil, fmt.Errorf("unix.Mmap(...,0, %v, ...) failed = %v", sz, err)
+	}
+	stopFD, err := stopfd.New()
+	if err != nil {
+		_ = unix.Munmap(buf)
+		releaseRing()
+		return nil, err
+	}
+	d := &packetMMapV3Dispatcher{
+		StopFD:     stopFD,
+		fd:         fd,
+		e:          e,
+		ringBuffer: buf,
+	}
+	d.mgr = newProcessorManager(opts, e)
+	d.mgr.start()
+	return d, nil
+}
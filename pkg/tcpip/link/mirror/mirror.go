@@ -0,0 +1,474 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mirror provides the implementation of a data-link layer endpoint
+// that wraps another endpoint and copies the inbound and outbound packets
+// matching a filter to a secondary sink, such as the FD of a traffic
+// inspection sidecar or a pcap file.
+//
+// Mirrored packets are written asynchronously: a slow or failing sink causes
+// mirrored packets to be dropped, but never delays or drops packets on the
+// primary path.
+package mirror
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/atomicbitops"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sync"
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+	"gvisor.dev/gvisor/pkg/tcpip/link/nested"
+	"gvisor.dev/gvisor/pkg/tcpip/link/sniffer"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+)
+
+const (
+	// DefaultQueueLen is the default number of mirrored packets that may be
+	// waiting to be written to the sink.
+	DefaultQueueLen = 1024
+
+	// DefaultSnapLen is the default maximum number of bytes of each packet
+	// saved in FormatPCAP.
+	DefaultSnapLen = 65535
+
+	// DefaultDrainTimeout is the default maximum time Close waits for queued
+	// mirrored packets to be written.
+	DefaultDrainTimeout = time.Second
+
+	// maxFilterHeaderLen is the number of bytes at the start of each packet,
+	// from its network header, that the filter examines.
+	maxFilterHeaderLen = 256
+)
+
+// Format is the format mirrored packets are written in.
+type Format int
+
+const (
+	// FormatRaw writes each mirrored packet, starting at its network header,
+	// in a single Write call, e.g. to a TUN device or a SOCK_SEQPACKET socket.
+	FormatRaw Format = iota
+
+	// FormatPCAP writes mirrored packets as a pcap file of raw IP packets.
+	FormatPCAP
+)
+
+// Filter selects the packets to mirror by their 5-tuple. Zero-valued fields
+// match any value. Packets that are not IPv4 or IPv6 only match filters with
+// no other field than NetProto set. The transport protocol of IPv6 packets
+// whose extension headers extend beyond the first 256 bytes of the packet is
+// unknown, so such packets never match a filter with TransProto or a port set.
+//
+// +stateify savable
+type Filter struct {
+	// NetProto is the network protocol of the packets.
+	NetProto tcpip.NetworkProtocolNumber
+
+	// TransProto is the transport protocol of the packets.
+	TransProto tcpip.TransportProtocolNumber
+
+	// Src and Dst are the subnets the source and destination addresses of
+	// the packets belong to.
+	Src tcpip.Subnet
+	Dst tcpip.Subnet
+
+	// SrcPort and DstPort are the TCP or UDP source and destination ports of
+	// the packets. Packets without ports, like ICMP packets and non-initial
+	// fragments, never match a filter with a port set.
+	SrcPort uint16
+	DstPort uint16
+
+	// Bidirectional also selects the packets of the reverse flow, i.e. the
+	// packets whose source matches Dst and DstPort and whose destination
+	// matches Src and SrcPort.
+	Bidirectional bool
+}
+
+// isNetProtoOnly returns true if f selects packets by network protocol only.
+func (f *Filter) isNetProtoOnly() bool {
+	return f.TransProto == 0 && f.Src == (tcpip.Subnet{}) && f.Dst == (tcpip.Subnet{}) && f.SrcPort == 0 && f.DstPort == 0
+}
+
+func subnetMatches(s tcpip.Subnet, addr tcpip.Address) bool {
+	return s == (tcpip.Subnet{}) || s.Contains(addr)
+}
+
+func portMatches(want uint16, hasPorts bool, port uint16) bool {
+	return want == 0 || (hasPorts && want == port)
+}
+
+// ipv6TransportHeader skips the IPv6 extension headers at the start of b,
+// which follow an IPv6 header with the given next header value, and returns
+// the transport protocol, the fragment offset and the transport header. ok is
+// false if the extension headers don't fit in b.
+func ipv6TransportHeader(next uint8, b []byte) (transProto tcpip.TransportProtocolNumber, fragOffset uint16, transHdr []byte, ok bool) {
+	for {
+		switch header.IPv6ExtensionHeaderIdentifier(next) {
+		case header.IPv6HopByHopOptionsExtHdrIdentifier, header.IPv6RoutingExtHdrIdentifier, header.IPv6DestinationOptionsExtHdrIdentifier:
+			if len(b) < 2 {
+				return 0, 0, nil, false
+			}
+			n := (int(b[1]) + 1) * 8
+			if len(b) < n {
+				return 0, 0, nil, false
+			}
+			next, b = b[0], b[n:]
+		case header.IPv6FragmentExtHdrIdentifier:
+			if len(b) < header.IPv6FragmentExtHdrLength {
+				return 0, 0, nil, false
+			}
+			fragOffset = binary.BigEndian.Uint16(b[2:]) >> 3
+			next, b = b[0], b[header.IPv6FragmentExtHdrLength:]
+		default:
+			return tcpip.TransportProtocolNumber(next), fragOffset, b, true
+		}
+	}
+}
+
+// matches returns true if f selects the packet whose headers, starting at its
+// network header, are at the start of hdr. hdr may be truncated.
+func (f *Filter) matches(netProto tcpip.NetworkProtocolNumber, hdr []byte) bool {
+	if f.NetProto != 0 && f.NetProto != netProto {
+		return false
+	}
+
+	var (
+		src, dst   tcpip.Address
+		transProto tcpip.TransportProtocolNumber
+		fragOffset uint16
+		transHdr   []byte
+	)
+	switch netProto {
+	case header.IPv4ProtocolNumber:
+		if len(hdr) < header.IPv4MinimumSize {
+			return false
+		}
+		h := header.IPv4(hdr)
+		hl := int(h.HeaderLength())
+		if hl < header.IPv4MinimumSize || hl > len(hdr) {
+			return false
+		}
+		src, dst = h.SourceAddress(), h.DestinationAddress()
+		transProto = h.TransportProtocol()
+		fragOffset = h.FragmentOffset()
+		transHdr = hdr[hl:]
+	case header.IPv6ProtocolNumber:
+		if len(hdr) < header.IPv6MinimumSize {
+			return false
+		}
+		h := header.IPv6(hdr)
+		src, dst = h.SourceAddress(), h.DestinationAddress()
+		var ok bool
+		transProto, fragOffset, transHdr, ok = ipv6TransportHeader(h.NextHeader(), hdr[header.IPv6MinimumSize:])
+		if !ok {
+			// The transport protocol is unknown.
+			transProto, transHdr = 0, nil
+		}
+	default:
+		return f.isNetProtoOnly()
+	}
+	if f.TransProto != 0 && f.TransProto != transProto {
+		return false
+	}
+
+	// The source and destination ports are at the start of both TCP and UDP
+	// headers.
+	var (
+		srcPort, dstPort uint16
+		hasPorts         bool
+	)
+	if fragOffset == 0 && (transProto == header.UDPProtocolNumber || transProto == header.TCPProtocolNumber) && len(transHdr) >= 4 {
+		srcPort, dstPort, hasPorts = binary.BigEndian.Uint16(transHdr), binary.BigEndian.Uint16(transHdr[2:]), true
+	}
+
+	if subnetMatches(f.Src, src) && subnetMatches(f.Dst, dst) && portMatches(f.SrcPort, hasPorts, srcPort) && portMatches(f.DstPort, hasPorts, dstPort) {
+		return true
+	}
+	return f.Bidirectional && subnetMatches(f.Src, dst) && subnetMatches(f.Dst, src) && portMatches(f.SrcPort, hasPorts, dstPort) && portMatches(f.DstPort, hasPorts, srcPort)
+}
+
+// Options are the options of a mirroring endpoint.
+type Options struct {
+	// Filter selects the packets to mirror.
+	Filter Filter
+
+	// Writer is the sink mirrored packets are written to.
+	Writer io.Writer
+
+	// Format is the format mirrored packets are written in.
+	Format Format
+
+	// SnapLen is the maximum number of bytes of each packet saved in
+	// FormatPCAP. Longer packets are truncated. If zero, DefaultSnapLen is
+	// used. Packets are never truncated in FormatRaw.
+	SnapLen uint32
+
+	// QueueLen is the maximum number of mirrored packets waiting to be
+	// written to Writer. Packets mirrored while the queue is full are
+	// dropped. If zero, DefaultQueueLen is used.
+	QueueLen int
+
+	// DrainTimeout is the maximum time Close waits for queued mirrored
+	// packets to be written. Packets that are still queued then are dropped.
+	// If zero, DefaultDrainTimeout is used.
+	DrainTimeout time.Duration
+}
+
+// mirroredPacket is a copy of a packet waiting to be written to the sink.
+type mirroredPacket struct {
+	timestamp time.Time
+	data      []byte
+	size      int
+}
+
+// Endpoint is a link endpoint that mirrors the packets matching a filter.
+//
+// The sink isn't saved. After restore, the endpoint doesn't mirror packets
+// until Reopen is called with a new sink.
+//
+// +stateify savable
+type Endpoint struct {
+	nested.Endpoint
+
+	filter       Filter
+	format       Format
+	snapLen      uint32
+	queueLen     int
+	drainTimeout time.Duration
+
+	// mu protects closed, writer and the sending side of queue.
+	mu     sync.RWMutex `state:"nosave"`
+	closed bool
+
+	// writer is the sink mirrored packets are written to, and queue holds the
+	// mirrored packets waiting to be written to it. Both are nil if the
+	// endpoint has no sink. done is closed when the goroutine writing queue
+	// to writer exits.
+	writer io.Writer           `state:"nosave"`
+	queue  chan mirroredPacket `state:"nosave"`
+	done   chan struct{}       `state:"nosave"`
+
+	// abandoned is set when Close stops waiting for queued packets to be
+	// written; packets that are still queued are then dropped.
+	abandoned atomicbitops.Bool `state:"nosave"`
+
+	mirrored atomicbitops.Uint64
+	dropped  atomicbitops.Uint64
+}
+
+var _ stack.GSOEndpoint = (*Endpoint)(nil)
+var _ stack.LinkEndpoint = (*Endpoint)(nil)
+var _ stack.NetworkDispatcher = (*Endpoint)(nil)
+
+// New creates a new mirroring link endpoint wrapping lower.
+func New(lower stack.LinkEndpoint, opts Options) (*Endpoint, error) {
+	if opts.Writer == nil {
+		return nil, fmt.Errorf("mirror: missing writer")
+	}
+	if opts.SnapLen == 0 {
+		opts.SnapLen = DefaultSnapLen
+	}
+	if opts.QueueLen == 0 {
+		opts.QueueLen = DefaultQueueLen
+	}
+	if opts.DrainTimeout == 0 {
+		opts.DrainTimeout = DefaultDrainTimeout
+	}
+	switch opts.Format {
+	case FormatRaw, FormatPCAP:
+	default:
+		return nil, fmt.Errorf("mirror: unknown format %d", opts.Format)
+	}
+
+	e := &Endpoint{
+		filter:       opts.Filter,
+		format:       opts.Format,
+		snapLen:      opts.SnapLen,
+		queueLen:     opts.QueueLen,
+		drainTimeout: opts.DrainTimeout,
+	}
+	if err := e.startLocked(opts.Writer); err != nil {
+		return nil, err
+	}
+	e.Endpoint.Init(lower, e)
+	return e, nil
+}
+
+// Reopen starts mirroring packets to w, e.g. after restore. It fails if the
+// endpoint already has a sink or is closed.
+func (e *Endpoint) Reopen(w io.Writer) error {
+	if w == nil {
+		return fmt.Errorf("mirror: missing writer")
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return fmt.Errorf("mirror: endpoint is closed")
+	}
+	if e.writer != nil {
+		return fmt.Errorf("mirror: endpoint already has a sink")
+	}
+	return e.startLocked(w)
+}
+
+// startLocked starts writing mirrored packets to w.
+//
+// Preconditions: e.mu must be locked, or e must not be shared yet.
+func (e *Endpoint) startLocked(w io.Writer) error {
+	if e.format == FormatPCAP {
+		if err := sniffer.WritePCAPHeader(w, e.snapLen); err != nil {
+			return fmt.Errorf("mirror: writing pcap header: %w", err)
+		}
+	}
+	e.writer = w
+	e.queue = make(chan mirroredPacket, e.queueLen)
+	e.done = make(chan struct{})
+	e.abandoned.Store(false)
+	go e.writeLoop(w, e.queue, e.done) // S/R-SAFE: the sink isn't saved, see Reopen.
+	return nil
+}
+
+// writeLoop writes mirrored packets from queue to w until queue is closed.
+func (e *Endpoint) writeLoop(w io.Writer, queue <-chan mirroredPacket, done chan<- struct{}) {
+	defer close(done)
+	for p := range queue {
+		if e.abandoned.Load() {
+			e.dropped.Add(1)
+			continue
+		}
+		var b []byte
+		switch e.format {
+		case FormatRaw:
+			b = p.data
+		case FormatPCAP:
+			b = sniffer.MarshalPCAPRecord(p.timestamp, p.data, p.size)
+		}
+		if _, err := w.Write(b); err != nil {
+			log.Debugf("mirror: writing mirrored packet: %v", err)
+			e.dropped.Add(1)
+			continue
+		}
+		e.mirrored.Add(1)
+	}
+}
+
+// filterHeader returns up to maxFilterHeaderLen bytes of pkt, starting at its
+// network header.
+func filterHeader(pkt *stack.PacketBuffer) []byte {
+	hdr := make([]byte, 0, maxFilterHeaderLen)
+	hdr = append(hdr, pkt.NetworkHeader().Slice()...)
+	hdr = append(hdr, pkt.TransportHeader().Slice()...)
+	if len(hdr) > maxFilterHeaderLen {
+		return hdr[:maxFilterHeaderLen]
+	}
+	return append(hdr, pkt.Data().AsRange().Capped(maxFilterHeaderLen-len(hdr)).ToSlice()...)
+}
+
+// mirror queues a copy of pkt to be written to the sink if it matches the
+// filter. Only the headers of packets that don't match are examined; matching
+// packets are copied in full.
+func (e *Endpoint) mirror(protocol tcpip.NetworkProtocolNumber, pkt *stack.PacketBuffer) {
+	if !e.filter.matches(protocol, filterHeader(pkt)) {
+		return
+	}
+
+	buf := pkt.ToBuffer()
+	defer buf.Release()
+	buf.TrimFront(int64(len(pkt.VirtioNetHeader().Slice())))
+	buf.TrimFront(int64(len(pkt.LinkHeader().Slice())))
+	size := int(buf.Size())
+	n := size
+	if e.format == FormatPCAP && n > int(e.snapLen) {
+		n = int(e.snapLen)
+	}
+	p := mirroredPacket{
+		timestamp: time.Now(),
+		data:      make([]byte, n),
+		size:      size,
+	}
+	if _, err := buf.ReadAt(p.data, 0); err != nil && err != io.EOF {
+		e.dropped.Add(1)
+		return
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed || e.queue == nil {
+		return
+	}
+	select {
+	case e.queue <- p:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// DeliverNetworkPacket implements stack.NetworkDispatcher.
+func (e *Endpoint) DeliverNetworkPacket(protocol tcpip.NetworkProtocolNumber, pkt *stack.PacketBuffer) {
+	e.mirror(protocol, pkt)
+	e.Endpoint.DeliverNetworkPacket(protocol, pkt)
+}
+
+// WritePackets implements stack.LinkEndpoint.
+func (e *Endpoint) WritePackets(pkts stack.PacketBufferList) (int, tcpip.Error) {
+	for _, pkt := range pkts.AsSlice() {
+		e.mirror(pkt.NetworkProtocolNumber, pkt)
+	}
+	return e.Endpoint.WritePackets(pkts)
+}
+
+// Close implements stack.LinkEndpoint. It waits up to the endpoint's drain
+// timeout for the queued mirrored packets to be written, and drops those that
+// aren't written by then.
+func (e *Endpoint) Close() {
+	e.Endpoint.Close()
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return
+	}
+	e.closed = true
+	done := e.done
+	if e.queue != nil {
+		close(e.queue)
+	}
+	e.mu.Unlock()
+	if done == nil {
+		return
+	}
+
+	timer := time.NewTimer(e.drainTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		log.Warningf("mirror: sink didn't accept queued packets within %v, dropping them", e.drainTimeout)
+		e.abandoned.Store(true)
+	}
+}
+
+// Mirrored returns the number of packets written to the sink.
+func (e *Endpoint) Mirrored() uint64 {
+	return e.mirrored.Load()
+}
+
+// Dropped returns the number of matching packets that were not written to the
+// sink because the queue was full or the write failed.
+func (e *Endpoint) Dropped() uint64 {
+	return e.dropped.Load()
+}
@@ -0,0 +1,311 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/buffer"
+	"gvisor.dev/gvisor/pkg/refs"
+	"gvisor.dev/gvisor/pkg/sync"
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+	"gvisor.dev/gvisor/pkg/tcpip/link/channel"
+	"gvisor.dev/gvisor/pkg/tcpip/link/mirror"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+	"gvisor.dev/gvisor/pkg/tcpip/testutil"
+)
+
+const (
+	mirroredPort = 1234
+	otherPort    = 4321
+)
+
+var (
+	localAddr  = testutil.MustParse4("10.0.0.1")
+	remoteAddr = testutil.MustParse4("10.0.0.2")
+)
+
+// packetWriter records each Write call as a packet.
+type packetWriter struct {
+	mu      sync.Mutex
+	packets [][]byte
+}
+
+func (w *packetWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.packets = append(w.packets, append([]byte(nil), b...))
+	return len(b), nil
+}
+
+type counterDispatcher struct {
+	count int
+}
+
+func (d *counterDispatcher) DeliverNetworkPacket(tcpip.NetworkProtocolNumber, *stack.PacketBuffer) {
+	d.count++
+}
+
+func (*counterDispatcher) DeliverLinkPacket(tcpip.NetworkProtocolNumber, *stack.PacketBuffer) {
+	panic("not implemented")
+}
+
+func udpPacket(src, dst tcpip.Address, srcPort, dstPort uint16) *stack.PacketBuffer {
+	hdr := make([]byte, header.IPv4MinimumSize+header.UDPMinimumSize)
+	ip := header.IPv4(hdr)
+	ip.Encode(&header.IPv4Fields{
+		TotalLength: uint16(len(hdr)),
+		TTL:         64,
+		Protocol:    uint8(header.UDPProtocolNumber),
+		SrcAddr:     src,
+		DstAddr:     dst,
+	})
+	ip.SetChecksum(^ip.CalculateChecksum())
+	header.UDP(hdr[header.IPv4MinimumSize:]).Encode(&header.UDPFields{
+		SrcPort: srcPort,
+		DstPort: dstPort,
+		Length:  header.UDPMinimumSize,
+	})
+	pkt := stack.NewPacketBuffer(stack.PacketBufferOptions{Payload: buffer.MakeWithData(hdr)})
+	pkt.NetworkProtocolNumber = header.IPv4ProtocolNumber
+	return pkt
+}
+
+func writePacket(t *testing.T, ep stack.LinkEndpoint, pkt *stack.PacketBuffer) {
+	t.Helper()
+	var pkts stack.PacketBufferList
+	pkts.PushBack(pkt)
+	defer pkts.Reset()
+	if _, err := ep.WritePackets(pkts); err != nil {
+		t.Fatalf("ep.WritePackets(_): %s", err)
+	}
+}
+
+func deliverPacket(lower *channel.Endpoint, pkt *stack.PacketBuffer) {
+	lower.InjectInbound(header.IPv4ProtocolNumber, pkt)
+	pkt.DecRef()
+}
+
+func TestMirrorRaw(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter mirror.Filter
+		want   []uint16
+	}{
+		{
+			name: "all",
+			want: []uint16{mirroredPort, otherPort, mirroredPort, otherPort},
+		},
+		{
+			name:   "destination port",
+			filter: mirror.Filter{TransProto: header.UDPProtocolNumber, DstPort: mirroredPort},
+			want:   []uint16{mirroredPort, mirroredPort},
+		},
+		{
+			name:   "bidirectional",
+			filter: mirror.Filter{Dst: remoteAddr.WithPrefix().Subnet(), DstPort: mirroredPort, Bidirectional: true},
+			want:   []uint16{mirroredPort, otherPort},
+		},
+		{
+			name:   "other transport protocol",
+			filter: mirror.Filter{TransProto: header.TCPProtocolNumber},
+		},
+		{
+			name:   "destination subnet",
+			filter: mirror.Filter{Dst: localAddr.WithPrefix().Subnet()},
+			want:   []uint16{mirroredPort, otherPort},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var w packetWriter
+			lower := channel.New(4, header.IPv6MinimumMTU, "")
+			ep, err := mirror.New(lower, mirror.Options{Filter: test.filter, Writer: &w})
+			if err != nil {
+				t.Fatalf("mirror.New(_, _): %s", err)
+			}
+			var disp counterDispatcher
+			ep.Attach(&disp)
+
+			// Outbound packets are sent from localAddr to remoteAddr and inbound
+			// packets from remoteAddr to localAddr, both to port mirroredPort then
+			// otherPort.
+			writePacket(t, ep, udpPacket(localAddr, remoteAddr, otherPort, mirroredPort))
+			writePacket(t, ep, udpPacket(localAddr, remoteAddr, mirroredPort, otherPort))
+			deliverPacket(lower, udpPacket(remoteAddr, localAddr, otherPort, mirroredPort))
+			deliverPacket(lower, udpPacket(remoteAddr, localAddr, mirroredPort, otherPort))
+
+			if got, want := lower.NumQueued(), 2; got != want {
+				t.Errorf("got lower.NumQueued() = %d, want = %d", got, want)
+			}
+			if got, want := disp.count, 2; got != want {
+				t.Errorf("got disp.count = %d, want = %d", got, want)
+			}
+
+			// Close flushes the mirrored packets.
+			ep.Close()
+			if len(w.packets) != len(test.want) {
+				t.Fatalf("got %d mirrored packets, want = %d", len(w.packets), len(test.want))
+			}
+			for i, b := range w.packets {
+				udp := header.UDP(header.IPv4(b).Payload())
+				if got := udp.DestinationPort(); got != test.want[i] {
+					t.Errorf("got mirrored packet #%d destination port = %d, want = %d", i, got, test.want[i])
+				}
+			}
+			if got, want := ep.Mirrored(), uint64(len(test.want)); got != want {
+				t.Errorf("got ep.Mirrored() = %d, want = %d", got, want)
+			}
+		})
+	}
+}
+
+func TestMirrorPCAP(t *testing.T) {
+	const snapLen = header.IPv4MinimumSize
+
+	var w packetWriter
+	lower := channel.New(1, header.IPv6MinimumMTU, "")
+	ep, err := mirror.New(lower, mirror.Options{Writer: &w, Format: mirror.FormatPCAP, SnapLen: snapLen})
+	if err != nil {
+		t.Fatalf("mirror.New(_, _): %s", err)
+	}
+	writePacket(t, ep, udpPacket(localAddr, remoteAddr, otherPort, mirroredPort))
+	ep.Close()
+
+	if len(w.packets) != 2 {
+		t.Fatalf("got %d writes, want = 2", len(w.packets))
+	}
+	if got, want := binary.LittleEndian.Uint32(w.packets[0][0:4]), uint32(0xa1b2c3d4); got != want {
+		t.Errorf("got pcap magic number = %#x, want = %#x", got, want)
+	}
+	record := w.packets[1]
+	if got := binary.LittleEndian.Uint32(record[8:12]); got != snapLen {
+		t.Errorf("got captured length = %d, want = %d", got, snapLen)
+	}
+	if got, want := binary.LittleEndian.Uint32(record[12:16]), uint32(header.IPv4MinimumSize+header.UDPMinimumSize); got != want {
+		t.Errorf("got packet length = %d, want = %d", got, want)
+	}
+	if got := header.IPv4(record[16:]).DestinationAddress(); got != remoteAddr {
+		t.Errorf("got destination address = %s, want = %s", got, remoteAddr)
+	}
+}
+
+// blockingWriter blocks writes until unblocked.
+type blockingWriter struct {
+	unblock chan struct{}
+}
+
+func (w *blockingWriter) Write(b []byte) (int, error) {
+	<-w.unblock
+	return len(b), nil
+}
+
+func TestMirrorQueueFull(t *testing.T) {
+	const numPackets = 3
+
+	w := blockingWriter{unblock: make(chan struct{})}
+	lower := channel.New(numPackets, header.IPv6MinimumMTU, "")
+	ep, err := mirror.New(lower, mirror.Options{Writer: &w, QueueLen: 1})
+	if err != nil {
+		t.Fatalf("mirror.New(_, _): %s", err)
+	}
+	for i := 0; i < numPackets; i++ {
+		writePacket(t, ep, udpPacket(localAddr, remoteAddr, otherPort, mirroredPort))
+	}
+
+	// Mirroring must not hold back the primary path.
+	if got := lower.NumQueued(); got != numPackets {
+		t.Errorf("got lower.NumQueued() = %d, want = %d", got, numPackets)
+	}
+	close(w.unblock)
+	ep.Close()
+
+	// At most one packet is being written while another one is queued.
+	if got := ep.Dropped(); got == 0 {
+		t.Errorf("got ep.Dropped() = 0, want > 0")
+	}
+	if got, want := ep.Mirrored()+ep.Dropped(), uint64(numPackets); got != want {
+		t.Errorf("got ep.Mirrored() + ep.Dropped() = %d, want = %d", got, want)
+	}
+}
+
+func TestMirrorCloseDrainTimeout(t *testing.T) {
+	const numPackets = 3
+
+	w := blockingWriter{unblock: make(chan struct{})}
+	defer close(w.unblock)
+	lower := channel.New(numPackets, header.IPv6MinimumMTU, "")
+	ep, err := mirror.New(lower, mirror.Options{Writer: &w, DrainTimeout: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("mirror.New(_, _): %s", err)
+	}
+	for i := 0; i < numPackets; i++ {
+		writePacket(t, ep, udpPacket(localAddr, remoteAddr, otherPort, mirroredPort))
+	}
+
+	// Close must return even though the sink never accepts the queued
+	// packets.
+	closed := make(chan struct{})
+	go func() {
+		ep.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("ep.Close() didn't return with a blocked sink")
+	}
+	if got := ep.Mirrored(); got != 0 {
+		t.Errorf("got ep.Mirrored() = %d, want = 0", got)
+	}
+}
+
+func TestReopen(t *testing.T) {
+	lower := channel.New(1, header.IPv6MinimumMTU, "")
+	ep, err := mirror.New(lower, mirror.Options{Writer: &packetWriter{}})
+	if err != nil {
+		t.Fatalf("mirror.New(_, _): %s", err)
+	}
+	if err := ep.Reopen(&packetWriter{}); err == nil {
+		t.Error("ep.Reopen(_) succeeded with an existing sink, want error")
+	}
+	ep.Close()
+	if err := ep.Reopen(&packetWriter{}); err == nil {
+		t.Error("ep.Reopen(_) succeeded after Close, want error")
+	}
+}
+
+func TestNewInvalidOptions(t *testing.T) {
+	lower := channel.New(1, header.IPv6MinimumMTU, "")
+	defer lower.Close()
+	if _, err := mirror.New(lower, mirror.Options{}); err == nil {
+		t.Error("mirror.New(_, {}) succeeded, want error")
+	}
+	if _, err := mirror.New(lower, mirror.Options{Writer: &bytes.Buffer{}, Format: mirror.FormatPCAP + 1}); err == nil {
+		t.Error("mirror.New(_, {Format: unknown}) succeeded, want error")
+	}
+}
+
+func TestMain(m *testing.M) {
+	refs.SetLeakMode(refs.LeaksPanic)
+	code := m.Run()
+	refs.DoLeakCheck()
+	os.Exit(code)
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prio_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/buffer"
+	"gvisor.dev/gvisor/pkg/refs"
+	"gvisor.dev/gvisor/pkg/sync"
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/link/qdisc/prio"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+)
+
+var _ stack.LinkWriter = (*recordWriter)(nil)
+
+// recordWriter implements LinkWriter. It records the priority of written
+// packets and blocks the first write until unblocked.
+type recordWriter struct {
+	started chan struct{}
+	unblock chan struct{}
+
+	mu         sync.Mutex
+	priorities []uint32
+	done       chan struct{}
+	wanted     int
+}
+
+func (w *recordWriter) WritePackets(pkts stack.PacketBufferList) (int, tcpip.Error) {
+	w.mu.Lock()
+	first := len(w.priorities) == 0
+	for _, pkt := range pkts.AsSlice() {
+		w.priorities = append(w.priorities, pkt.Priority)
+	}
+	if len(w.priorities) == w.wanted {
+		close(w.done)
+	}
+	w.mu.Unlock()
+	if first {
+		close(w.started)
+		<-w.unblock
+	}
+	return pkts.Len(), nil
+}
+
+func writePacket(t *testing.T, qDisc stack.QueueingDiscipline, priority uint32) {
+	t.Helper()
+	pkt := stack.NewPacketBuffer(stack.PacketBufferOptions{
+		Payload:  buffer.MakeWithData([]byte{1}),
+		Priority: priority,
+	})
+	defer pkt.DecRef()
+	if err := qDisc.WritePacket(pkt); err != nil {
+		t.Fatalf("qDisc.WritePacket(_): %s", err)
+	}
+}
+
+func TestPriorityOrder(t *testing.T) {
+	const numPackets = 4
+
+	w := &recordWriter{
+		started: make(chan struct{}),
+		unblock: make(chan struct{}),
+		done:    make(chan struct{}),
+		wanted:  1 + 3*numPackets,
+	}
+	qDisc := prio.New(w, 1, 1000)
+	defer qDisc.Close()
+
+	// Keep the dispatcher busy writing the first packet while the others are
+	// queued.
+	writePacket(t, qDisc, stack.PriorityBestEffort)
+	<-w.started
+	for i := 0; i < numPackets; i++ {
+		writePacket(t, qDisc, stack.PriorityBulk)
+		writePacket(t, qDisc, stack.PriorityBestEffort)
+		writePacket(t, qDisc, stack.PriorityInteractive)
+	}
+	close(w.unblock)
+
+	select {
+	case <-w.done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %d packets", w.wanted)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	want := []uint32{stack.PriorityBestEffort}
+	for _, priority := range []uint32{stack.PriorityInteractive, stack.PriorityBestEffort, stack.PriorityBulk} {
+		for i := 0; i < numPackets; i++ {
+			want = append(want, priority)
+		}
+	}
+	for i := range want {
+		if w.priorities[i] != want[i] {
+			t.Fatalf("got priorities = %v, want = %v", w.priorities, want)
+		}
+	}
+}
+
+func TestBand(t *testing.T) {
+	tests := []struct {
+		priority uint32
+		want     int
+	}{
+		{priority: stack.PriorityBestEffort, want: 1},
+		{priority: stack.PriorityBulk, want: 2},
+		{priority: stack.PriorityInteractiveBulk, want: 1},
+		{priority: stack.PriorityInteractive, want: 0},
+		{priority: stack.PriorityControl, want: 0},
+		{priority: stack.PriorityMax + 1 + stack.PriorityInteractive, want: 0},
+	}
+	for _, test := range tests {
+		if got := prio.Band(test.priority); got != test.want {
+			t.Errorf("got prio.Band(%d) = %d, want = %d", test.priority, got, test.want)
+		}
+	}
+}
+
+func TestWriteRefusedAfterClosed(t *testing.T) {
+	qDisc := prio.New(nil, 1, 2)
+
+	qDisc.Close()
+	err := qDisc.WritePacket(nil)
+	if _, ok := err.(*tcpip.ErrClosedForSend); !ok {
+		t.Errorf("got err = %s, want %s", err, &tcpip.ErrClosedForSend{})
+	}
+}
+
+func TestMain(m *testing.M) {
+	refs.SetLeakMode(refs.LeaksPanic)
+	code := m.Run()
+	refs.DoLeakCheck()
+	os.Exit(code)
+}
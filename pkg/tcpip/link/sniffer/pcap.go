@@ -62,12 +62,9 @@ func (p *pcapPacket) MarshalBinary() ([]byte, error) {
 	if packetSize < captureLen {
 		captureLen = packetSize
 	}
-	b := make([]byte, 16+captureLen)
-	binary.LittleEndian.PutUint32(b[0:4], uint32(p.timestamp.Unix()))
-	binary.LittleEndian.PutUint32(b[4:8], uint32(p.timestamp.Nanosecond()/1000))
-	binary.LittleEndian.PutUint32(b[8:12], uint32(captureLen))
-	binary.LittleEndian.PutUint32(b[12:16], uint32(packetSize))
-	w := tcpip.SliceWriter(b[16:])
+	b := make([]byte, pcapRecordHeaderSize+captureLen)
+	putPCAPRecordHeader(b, p.timestamp, captureLen, packetSize)
+	w := tcpip.SliceWriter(b[pcapRecordHeaderSize:])
 	for _, v := range pkt.AsSlices() {
 		if captureLen == 0 {
 			break
@@ -83,3 +80,24 @@ func (p *pcapPacket) MarshalBinary() ([]byte, error) {
 	}
 	return b, nil
 }
+
+// pcapRecordHeaderSize is the size of the header of each packet record in a
+// pcap file.
+const pcapRecordHeaderSize = 16
+
+func putPCAPRecordHeader(b []byte, ts time.Time, captureLen, packetSize int) {
+	binary.LittleEndian.PutUint32(b[0:4], uint32(ts.Unix()))
+	binary.LittleEndian.PutUint32(b[4:8], uint32(ts.Nanosecond()/1000))
+	binary.LittleEndian.PutUint32(b[8:12], uint32(captureLen))
+	binary.LittleEndian.PutUint32(b[12:16], uint32(packetSize))
+}
+
+// MarshalPCAPRecord returns the pcap record of a packet captured at ts, of
+// which data holds the first bytes starting at the network header and
+// packetSize is the full size.
+func MarshalPCAPRecord(ts time.Time, data []byte, packetSize int) []byte {
+	b := make([]byte, pcapRecordHeaderSize+len(data))
+	putPCAPRecordHeader(b, ts, len(data), packetSize)
+	copy(b[pcapRecordHeaderSize:], data)
+	return b
+}
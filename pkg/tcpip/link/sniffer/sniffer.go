@@ -99,7 +99,9 @@ func zoneOffset() (int32, error) {
 	return int32(offset), nil
 }
 
-func writePCAPHeader(w io.Writer, maxLen uint32) error {
+// WritePCAPHeader writes the header of a pcap file holding raw IP packets,
+// i.e. packets starting at their network header, truncated to maxLen bytes.
+func WritePCAPHeader(w io.Writer, maxLen uint32) error {
 	offset, err := zoneOffset()
 	if err != nil {
 		return err
@@ -128,7 +130,7 @@ func writePCAPHeader(w io.Writer, maxLen uint32) error {
 // less than or equal to snapLen will be saved in their entirety. Longer
 // packets will be truncated to snapLen.
 func NewWithWriter(lower stack.LinkEndpoint, writer io.Writer, snapLen uint32) (*Endpoint, error) {
-	if err := writePCAPHeader(writer, snapLen); err != nil {
+	if err := WritePCAPHeader(writer, snapLen); err != nil {
 		return nil, err
 	}
 	sniffer := &Endpoint{
@@ -0,0 +1,436 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package udptunnel provides link endpoints that carry Ethernet frames over
+// UDP, as used by the VXLAN (RFC 7348) and Geneve (RFC 8926) overlay network
+// devices.
+//
+// Endpoints expect to be wrapped by an ethernet endpoint: outbound packets
+// already hold an Ethernet header and inbound packets are delivered with the
+// inner Ethernet header still in place.
+package udptunnel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"gvisor.dev/gvisor/pkg/buffer"
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+	"gvisor.dev/gvisor/pkg/tcpip/network/ipv4"
+	"gvisor.dev/gvisor/pkg/tcpip/network/ipv6"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+	"gvisor.dev/gvisor/pkg/tcpip/transport/udp"
+	"gvisor.dev/gvisor/pkg/waiter"
+)
+
+var _ stack.LinkEndpoint = (*Endpoint)(nil)
+
+// Kind is the encapsulation used by a tunnel endpoint.
+type Kind int
+
+const (
+	// VXLAN encapsulates frames as per RFC 7348.
+	VXLAN Kind = iota
+
+	// Geneve encapsulates frames as per RFC 8926.
+	Geneve
+)
+
+// String implements fmt.Stringer.
+func (k Kind) String() string {
+	switch k {
+	case VXLAN:
+		return "vxlan"
+	case Geneve:
+		return "geneve"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// headerSize returns the size of the tunnel header added to each frame.
+func (k Kind) headerSize() int {
+	if k == Geneve {
+		return header.GeneveMinimumSize
+	}
+	return header.VXLANSize
+}
+
+// defaultPort returns the IANA assigned UDP port for the encapsulation.
+func (k Kind) defaultPort() uint16 {
+	if k == Geneve {
+		return header.GeneveDefaultPort
+	}
+	return header.VXLANDefaultPort
+}
+
+// maxVNI returns the largest virtual network identifier of the encapsulation.
+func (k Kind) maxVNI() uint32 {
+	if k == Geneve {
+		return header.GeneveMaxVNI
+	}
+	return header.VXLANMaxVNI
+}
+
+// Options holds the configuration of a tunnel endpoint.
+type Options struct {
+	// Kind is the encapsulation to use.
+	Kind Kind
+
+	// Stack is the stack used to send and receive the encapsulated packets.
+	Stack *stack.Stack
+
+	// VNI is the virtual network identifier of the tunnel.
+	VNI uint32
+
+	// Remote is the address encapsulated packets are sent to. It may be a
+	// multicast group. Required.
+	Remote tcpip.Address
+
+	// Local is the source address of encapsulated packets. If unspecified,
+	// the source address is picked by the stack.
+	Local tcpip.Address
+
+	// Port is the UDP destination port of encapsulated packets and the port
+	// the endpoint listens on. If zero, the IANA assigned port for Kind is
+	// used.
+	Port uint16
+
+	// TTL is the TTL of encapsulated packets. If zero, the stack's default is
+	// used.
+	TTL uint8
+
+	// MTU is the maximum size of a frame, including its Ethernet header.
+	MTU uint32
+
+	// LinkAddress is the link address of the endpoint. If empty, a random
+	// address is generated.
+	LinkAddress tcpip.LinkAddress
+}
+
+// Overhead returns the number of bytes added to each frame sent through a
+// tunnel of the given kind towards remote.
+func Overhead(kind Kind, remote tcpip.Address) uint32 {
+	ipSize := header.IPv4MinimumSize
+	if remote.Len() == header.IPv6AddressSize {
+		ipSize = header.IPv6MinimumSize
+	}
+	return uint32(ipSize + header.UDPMinimumSize + kind.headerSize())
+}
+
+// Endpoint is a link endpoint that tunnels Ethernet frames over UDP.
+//
+// +stateify savable
+type Endpoint struct {
+	kind   Kind
+	vni    uint32
+	remote tcpip.FullAddress
+	ep     tcpip.Endpoint
+	wq     waiter.Queue
+
+	mu endpointRWMutex `state:"nosave"`
+	// +checklocks:mu
+	dispatcher stack.NetworkDispatcher
+	// +checklocks:mu
+	linkAddr tcpip.LinkAddress
+	// +checklocks:mu
+	mtu uint32
+	// +checklocks:mu
+	closed bool
+	// +checklocks:mu
+	onCloseAction func() `state:"nosave"`
+
+	// done is closed when the endpoint is closed to stop the receive loop.
+	done chan struct{} `state:"nosave"`
+}
+
+// New creates a new tunnel endpoint and starts listening for encapsulated
+// packets on the configured UDP port.
+//
+// Only a single tunnel endpoint may use a given local address and port.
+func New(opts Options) (*Endpoint, tcpip.Error) {
+	switch opts.Kind {
+	case VXLAN, Geneve:
+	default:
+		return nil, &tcpip.ErrNotSupported{}
+	}
+	if opts.VNI > opts.Kind.maxVNI() {
+		return nil, &tcpip.ErrInvalidOptionValue{}
+	}
+	var netProto tcpip.NetworkProtocolNumber
+	switch opts.Remote.Len() {
+	case header.IPv4AddressSize:
+		netProto = ipv4.ProtocolNumber
+	case header.IPv6AddressSize:
+		netProto = ipv6.ProtocolNumber
+	default:
+		return nil, &tcpip.ErrBadAddress{}
+	}
+	if opts.Local.Len() != 0 && opts.Local.Len() != opts.Remote.Len() {
+		return nil, &tcpip.ErrBadAddress{}
+	}
+	if opts.Port == 0 {
+		opts.Port = opts.Kind.defaultPort()
+	}
+	if len(opts.LinkAddress) == 0 {
+		opts.LinkAddress = tcpip.GetRandMacAddr()
+	}
+
+	e := &Endpoint{
+		kind:     opts.Kind,
+		vni:      opts.VNI,
+		remote:   tcpip.FullAddress{Addr: opts.Remote, Port: opts.Port},
+		linkAddr: opts.LinkAddress,
+		mtu:      opts.MTU,
+	}
+	ep, err := opts.Stack.NewEndpoint(udp.ProtocolNumber, netProto, &e.wq)
+	if err != nil {
+		return nil, err
+	}
+	if opts.TTL != 0 {
+		ttlOpt := tcpip.IPv4TTLOption
+		if netProto == ipv6.ProtocolNumber {
+			ttlOpt = tcpip.IPv6HopLimitOption
+		}
+		if err := ep.SetSockOptInt(ttlOpt, int(opts.TTL)); err != nil {
+			ep.Close()
+			return nil, err
+		}
+	}
+	if err := ep.Bind(tcpip.FullAddress{Addr: opts.Local, Port: opts.Port}); err != nil {
+		ep.Close()
+		return nil, err
+	}
+	if header.IsV4MulticastAddress(opts.Remote) || header.IsV6MulticastAddress(opts.Remote) {
+		if err := ep.SetSockOpt(&tcpip.AddMembershipOption{MulticastAddr: opts.Remote, InterfaceAddr: opts.Local}); err != nil {
+			ep.Close()
+			return nil, err
+		}
+	}
+	e.ep = ep
+	e.startReceiving()
+	return e, nil
+}
+
+// startReceiving starts the goroutine delivering inbound frames.
+func (e *Endpoint) startReceiving() {
+	e.done = make(chan struct{})
+	go e.receiveLoop() // S/R-SAFE: restarted by afterLoad.
+}
+
+// afterLoad is invoked by stateify.
+func (e *Endpoint) afterLoad(context.Context) {
+	e.startReceiving()
+}
+
+// receiveLoop reads encapsulated packets until the endpoint is closed and
+// delivers the frames they carry.
+func (e *Endpoint) receiveLoop() {
+	waitEntry, notifyCh := waiter.NewChannelEntry(waiter.ReadableEvents)
+	e.wq.EventRegister(&waitEntry)
+	defer e.wq.EventUnregister(&waitEntry)
+
+	var b bytes.Buffer
+	for {
+		b.Reset()
+		_, err := e.ep.Read(&b, tcpip.ReadOptions{})
+		switch err.(type) {
+		case nil:
+			e.deliver(b.Bytes())
+			continue
+		case *tcpip.ErrWouldBlock:
+		default:
+			return
+		}
+		select {
+		case <-notifyCh:
+		case <-e.done:
+			return
+		}
+	}
+}
+
+// deliver decapsulates a frame and delivers it to the attached dispatcher.
+func (e *Endpoint) deliver(b []byte) {
+	var hdrLen int
+	switch e.kind {
+	case VXLAN:
+		h := header.VXLAN(b)
+		if !h.IsValid() || h.VNI() != e.vni {
+			return
+		}
+		hdrLen = header.VXLANSize
+	case Geneve:
+		if len(b) < header.GeneveMinimumSize {
+			return
+		}
+		h := header.Geneve(b)
+		// As per RFC 8926 section 3.4, packets with an unknown version, or
+		// with critical options we do not understand, must be dropped. We
+		// do not support any options.
+		if h.Version() != 0 || h.Flags()&header.GeneveFlagCritical != 0 || h.Flags()&header.GeneveFlagOAM != 0 {
+			return
+		}
+		if h.Protocol() != header.GeneveProtocolTransparentEthernetBridging || h.VNI() != e.vni {
+			return
+		}
+		hdrLen = h.HeaderLength()
+	}
+	if len(b) < hdrLen+header.EthernetMinimumSize {
+		return
+	}
+
+	e.mu.RLock()
+	d := e.dispatcher
+	e.mu.RUnlock()
+	if d == nil {
+		return
+	}
+	pkt := stack.NewPacketBuffer(stack.PacketBufferOptions{
+		Payload: buffer.MakeWithData(b[hdrLen:]),
+	})
+	d.DeliverNetworkPacket(0 /* protocol */, pkt)
+	pkt.DecRef()
+}
+
+// Close closes the endpoint and the UDP endpoint it uses.
+func (e *Endpoint) Close() {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return
+	}
+	e.closed = true
+	action := e.onCloseAction
+	e.onCloseAction = nil
+	e.mu.Unlock()
+
+	close(e.done)
+	e.ep.Close()
+	if action != nil {
+		action()
+	}
+}
+
+// Attach implements stack.LinkEndpoint.Attach.
+func (e *Endpoint) Attach(dispatcher stack.NetworkDispatcher) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.dispatcher = dispatcher
+}
+
+// IsAttached implements stack.LinkEndpoint.IsAttached.
+func (e *Endpoint) IsAttached() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.dispatcher != nil
+}
+
+// MTU implements stack.LinkEndpoint.MTU.
+func (e *Endpoint) MTU() uint32 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.mtu
+}
+
+// SetMTU implements stack.LinkEndpoint.SetMTU.
+func (e *Endpoint) SetMTU(mtu uint32) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.mtu = mtu
+}
+
+// Capabilities implements stack.LinkEndpoint.Capabilities.
+func (*Endpoint) Capabilities() stack.LinkEndpointCapabilities {
+	return stack.CapabilitySaveRestore
+}
+
+// MaxHeaderLength implements stack.LinkEndpoint.MaxHeaderLength. The tunnel
+// header is added to a copy of the frame so no space is reserved for it.
+func (*Endpoint) MaxHeaderLength() uint16 {
+	return 0
+}
+
+// LinkAddress implements stack.LinkEndpoint.LinkAddress.
+func (e *Endpoint) LinkAddress() tcpip.LinkAddress {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.linkAddr
+}
+
+// SetLinkAddress implements stack.LinkEndpoint.SetLinkAddress.
+func (e *Endpoint) SetLinkAddress(addr tcpip.LinkAddress) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.linkAddr = addr
+}
+
+// WritePackets implements stack.LinkEndpoint.WritePackets.
+func (e *Endpoint) WritePackets(pkts stack.PacketBufferList) (int, tcpip.Error) {
+	e.mu.RLock()
+	closed := e.closed
+	e.mu.RUnlock()
+	if closed {
+		return 0, &tcpip.ErrClosedForSend{}
+	}
+
+	hdrLen := e.kind.headerSize()
+	n := 0
+	for _, pkt := range pkts.AsSlice() {
+		frame := pkt.ToView()
+		b := make([]byte, hdrLen+frame.Size())
+		switch e.kind {
+		case VXLAN:
+			header.VXLAN(b).Encode(e.vni)
+		case Geneve:
+			header.Geneve(b).Encode(e.vni)
+		}
+		copy(b[hdrLen:], frame.AsSlice())
+		frame.Release()
+
+		var r bytes.Reader
+		r.Reset(b)
+		if _, err := e.ep.Write(&r, tcpip.WriteOptions{To: &e.remote}); err != nil {
+			if n == 0 {
+				return 0, err
+			}
+			break
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Wait implements stack.LinkEndpoint.Wait.
+func (*Endpoint) Wait() {}
+
+// ARPHardwareType implements stack.LinkEndpoint.ARPHardwareType.
+func (*Endpoint) ARPHardwareType() header.ARPHardwareType {
+	return header.ARPHardwareNone
+}
+
+// AddHeader implements stack.LinkEndpoint.AddHeader.
+func (*Endpoint) AddHeader(*stack.PacketBuffer) {}
+
+// ParseHeader implements stack.LinkEndpoint.ParseHeader.
+func (*Endpoint) ParseHeader(*stack.PacketBuffer) bool { return true }
+
+// SetOnCloseAction implements stack.LinkEndpoint.SetOnCloseAction.
+func (e *Endpoint) SetOnCloseAction(action func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onCloseAction = action
+}
@@ -0,0 +1,187 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udptunnel_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/link/ethernet"
+	"gvisor.dev/gvisor/pkg/tcpip/link/pipe"
+	"gvisor.dev/gvisor/pkg/tcpip/link/udptunnel"
+	"gvisor.dev/gvisor/pkg/tcpip/network/arp"
+	"gvisor.dev/gvisor/pkg/tcpip/network/ipv4"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+	"gvisor.dev/gvisor/pkg/tcpip/transport/udp"
+	"gvisor.dev/gvisor/pkg/waiter"
+)
+
+const (
+	underlayNICID = 1
+	overlayNICID  = 2
+	testPort      = 1234
+	testMTU       = 1500
+)
+
+var (
+	underlayAddrs = [2]tcpip.Address{
+		tcpip.AddrFrom4([4]byte{192, 168, 0, 1}),
+		tcpip.AddrFrom4([4]byte{192, 168, 0, 2}),
+	}
+	overlayAddrs = [2]tcpip.Address{
+		tcpip.AddrFrom4([4]byte{10, 0, 0, 1}),
+		tcpip.AddrFrom4([4]byte{10, 0, 0, 2}),
+	}
+)
+
+func newStack(t *testing.T) *stack.Stack {
+	t.Helper()
+	s := stack.New(stack.Options{
+		NetworkProtocols:   []stack.NetworkProtocolFactory{ipv4.NewProtocol, arp.NewProtocol},
+		TransportProtocols: []stack.TransportProtocolFactory{udp.NewProtocol},
+	})
+	t.Cleanup(func() {
+		s.Close()
+		s.Wait()
+	})
+	return s
+}
+
+func addNIC(t *testing.T, s *stack.Stack, id tcpip.NICID, ep stack.LinkEndpoint, addr tcpip.Address) {
+	t.Helper()
+	if err := s.CreateNIC(id, ep); err != nil {
+		t.Fatalf("s.CreateNIC(%d, _): %s", id, err)
+	}
+	protocolAddr := tcpip.ProtocolAddress{
+		Protocol:          ipv4.ProtocolNumber,
+		AddressWithPrefix: addr.WithPrefix(),
+	}
+	protocolAddr.AddressWithPrefix.PrefixLen = 24
+	if err := s.AddProtocolAddress(id, protocolAddr, stack.AddressProperties{}); err != nil {
+		t.Fatalf("s.AddProtocolAddress(%d, %+v, {}): %s", id, protocolAddr, err)
+	}
+	s.AddRoute(tcpip.Route{Destination: protocolAddr.AddressWithPrefix.Subnet(), NIC: id})
+}
+
+func TestTunnel(t *testing.T) {
+	for _, kind := range []udptunnel.Kind{udptunnel.VXLAN, udptunnel.Geneve} {
+		t.Run(kind.String(), func(t *testing.T) {
+			stacks := [2]*stack.Stack{newStack(t), newStack(t)}
+			a, b := pipe.New(tcpip.GetRandMacAddr(), tcpip.GetRandMacAddr(), testMTU)
+			underlay := [2]stack.LinkEndpoint{ethernet.New(a), ethernet.New(b)}
+
+			for i, s := range stacks {
+				addNIC(t, s, underlayNICID, underlay[i], underlayAddrs[i])
+
+				remote := underlayAddrs[1-i]
+				ep, err := udptunnel.New(udptunnel.Options{
+					Kind:   kind,
+					Stack:  s,
+					VNI:    42,
+					Remote: remote,
+					MTU:    testMTU - udptunnel.Overhead(kind, remote),
+				})
+				if err != nil {
+					t.Fatalf("udptunnel.New(_): %s", err)
+				}
+				addNIC(t, s, overlayNICID, ethernet.New(ep), overlayAddrs[i])
+			}
+
+			var wq waiter.Queue
+			rcv, err := stacks[1].NewEndpoint(udp.ProtocolNumber, ipv4.ProtocolNumber, &wq)
+			if err != nil {
+				t.Fatalf("NewEndpoint(udp, ipv4, _): %s", err)
+			}
+			defer rcv.Close()
+			if err := rcv.Bind(tcpip.FullAddress{Addr: overlayAddrs[1], Port: testPort}); err != nil {
+				t.Fatalf("rcv.Bind(_): %s", err)
+			}
+			waitEntry, notifyCh := waiter.NewChannelEntry(waiter.ReadableEvents)
+			wq.EventRegister(&waitEntry)
+			defer wq.EventUnregister(&waitEntry)
+
+			snd, err := stacks[0].NewEndpoint(udp.ProtocolNumber, ipv4.ProtocolNumber, &waiter.Queue{})
+			if err != nil {
+				t.Fatalf("NewEndpoint(udp, ipv4, _): %s", err)
+			}
+			defer snd.Close()
+			payload := []byte("overlay payload")
+			var r bytes.Reader
+			r.Reset(payload)
+			to := tcpip.FullAddress{Addr: overlayAddrs[1], Port: testPort}
+			if _, err := snd.Write(&r, tcpip.WriteOptions{To: &to}); err != nil {
+				t.Fatalf("snd.Write(_, {To: %+v}): %s", to, err)
+			}
+
+			var got bytes.Buffer
+			for {
+				res, err := rcv.Read(&got, tcpip.ReadOptions{NeedRemoteAddr: true})
+				if _, ok := err.(*tcpip.ErrWouldBlock); ok {
+					select {
+					case <-notifyCh:
+						continue
+					case <-time.After(5 * time.Second):
+						t.Fatal("timed out waiting for the tunneled datagram")
+					}
+				}
+				if err != nil {
+					t.Fatalf("rcv.Read(_, _): %s", err)
+				}
+				if res.RemoteAddr.Addr != overlayAddrs[0] {
+					t.Errorf("got res.RemoteAddr.Addr = %s, want = %s", res.RemoteAddr.Addr, overlayAddrs[0])
+				}
+				break
+			}
+			if !bytes.Equal(got.Bytes(), payload) {
+				t.Errorf("got payload = %q, want = %q", got.Bytes(), payload)
+			}
+		})
+	}
+}
+
+func TestNewInvalidOptions(t *testing.T) {
+	s := newStack(t)
+	tests := []struct {
+		name string
+		opts udptunnel.Options
+	}{
+		{
+			name: "missing remote",
+			opts: udptunnel.Options{Stack: s},
+		},
+		{
+			name: "VXLAN VNI too large",
+			opts: udptunnel.Options{Stack: s, Remote: underlayAddrs[1], VNI: 1 << 24},
+		},
+		{
+			name: "Geneve VNI too large",
+			opts: udptunnel.Options{Stack: s, Remote: underlayAddrs[1], Kind: udptunnel.Geneve, VNI: 1 << 24},
+		},
+		{
+			name: "unknown kind",
+			opts: udptunnel.Options{Stack: s, Remote: underlayAddrs[1], Kind: udptunnel.Geneve + 1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if ep, err := udptunnel.New(test.opts); err == nil {
+				ep.Close()
+				t.Errorf("udptunnel.New(%+v) succeeded, want error", test.opts)
+			}
+		})
+	}
+}
@@ -37,6 +37,7 @@ const (
 
 var _ stack.DuplicateAddressDetector = (*endpoint)(nil)
 var _ stack.LinkAddressResolver = (*endpoint)(nil)
+var _ stack.LinkAddressAnnouncer = (*endpoint)(nil)
 var _ ip.DADProtocol = (*endpoint)(nil)
 
 // ARP endpoints need to implement stack.NetworkEndpoint because the stack
@@ -334,6 +335,19 @@ func (e *endpoint) LinkAddressRequest(targetAddr, localAddr tcpip.Address, remot
 	return e.sendARPRequest(localAddr, targetAddr, remoteLinkAddr)
 }
 
+// AnnounceLinkAddress implements stack.LinkAddressAnnouncer.
+//
+// It broadcasts a gratuitous ARP request, i.e. an ARP Announcement as defined
+// by RFC 5227 section 2.3, with both the sender and target protocol addresses
+// set to localAddr.
+func (e *endpoint) AnnounceLinkAddress(localAddr tcpip.Address) tcpip.Error {
+	if !e.nic.CheckLocalAddress(header.IPv4ProtocolNumber, localAddr) {
+		e.stats.arp.outgoingRequestBadLocalAddressErrors.Increment()
+		return &tcpip.ErrBadLocalAddress{}
+	}
+	return e.sendARPRequest(localAddr, localAddr, header.EthernetBroadcastAddress)
+}
+
 func (e *endpoint) sendARPRequest(localAddr, targetAddr tcpip.Address, remoteLinkAddr tcpip.LinkAddress) tcpip.Error {
 	pkt := stack.NewPacketBuffer(stack.PacketBufferOptions{
 		ReserveHeaderBytes: int(e.MaxHeaderLength()),
@@ -796,6 +796,17 @@ func (e *endpoint) forwardPacketWithRoute(route *stack.Route, pkt *stack.PacketB
 	newHdr := header.IPv4(newPkt.NetworkHeader().Slice())
 	defer newPkt.DecRef()
 
+	// The external packet filter may rewrite the packet, so it only gets our
+	// copy.
+	if f := stk.PacketFilter(); f != nil && !f.CheckForward(newPkt, e.nic.ID(), route, ProtocolNumber) {
+		// The external packet filter is telling us to drop the packet.
+		return nil
+	}
+
+	// Like Linux, queue forwarded packets by the priority of their TOS.
+	tos, _ := h.TOS()
+	newPkt.Priority = stack.TOSToPriority(tos)
+
 	forwardToEp, ok := e.protocol.getEndpointForNIC(route.NICID())
 	if !ok {
 		return &ip.ErrUnknownOutputEndpoint{}
@@ -899,6 +910,11 @@ func (e *endpoint) forwardUnicastPacket(pkt *stack.PacketBuffer) ip.ForwardingEr
 			}
 		}
 
+		if f := stk.PacketFilter(); f != nil && !f.CheckForward(pkt, e.nic.ID(), nil /* route */, ProtocolNumber) {
+			// The external packet filter is telling us to drop the packet.
+			return nil
+		}
+
 		// The packet originally arrived on e so provide its NIC as the input NIC.
 		ep.handleValidatedPacket(h, pkt, e.nic.Name() /* inNICName */)
 		return nil
@@ -940,12 +956,14 @@ func (e *endpoint) HandlePacket(pkt *stack.PacketBuffer) {
 
 	if !e.isEnabled() {
 		stats.DisabledPacketsReceived.Increment()
+		e.recordDrop(tcpip.DropReasonNICDisabled, pkt)
 		return
 	}
 
 	hView, ok := e.protocol.parseAndValidate(pkt)
 	if !ok {
 		stats.MalformedPacketsReceived.Increment()
+		e.recordDrop(tcpip.DropReasonMalformed, pkt)
 		return
 	}
 	h := header.IPv4(hView.AsSlice())
@@ -956,12 +974,14 @@ func (e *endpoint) HandlePacket(pkt *stack.PacketBuffer) {
 			if header.IsV4LoopbackAddress(h.SourceAddress()) {
 				martianPacketLogger.Infof("Martian packet dropped with loopback source address. If your traffic is unexpectedly dropped, you may want to allow martian packets.")
 				stats.InvalidSourceAddressesReceived.Increment()
+				e.recordDrop(tcpip.DropReasonInvalidSource, pkt)
 				return
 			}
 
 			if header.IsV4LoopbackAddress(h.DestinationAddress()) {
 				martianPacketLogger.Infof("Martian packet dropped with loopback destination address. If your traffic is unexpectedly dropped, you may want to allow martian packets.")
 				stats.InvalidDestinationAddressesReceived.Increment()
+				e.recordDrop(tcpip.DropReasonInvalidDestination, pkt)
 				return
 			}
 		}
@@ -974,6 +994,7 @@ func (e *endpoint) HandlePacket(pkt *stack.PacketBuffer) {
 				// a packet like this unless HandleLocal is false or our NIC is the
 				// loopback interface.
 				stats.InvalidSourceAddressesReceived.Increment()
+				e.recordDrop(tcpip.DropReasonInvalidSource, pkt)
 				return
 			}
 		}
@@ -985,12 +1006,14 @@ func (e *endpoint) HandlePacket(pkt *stack.PacketBuffer) {
 		if ok := stk.IPTables().CheckPrerouting(pkt, e, inNicName); !ok {
 			// iptables is telling us to drop the packet.
 			stats.IPTablesPreroutingDropped.Increment()
+			e.recordDrop(tcpip.DropReasonFilter, pkt)
 			return
 		}
 
 		if nft := stk.NFTables(); nft != nil && stk.IsNFTablesConfigured() {
 			if !nft.CheckPrerouting(pkt, nil /* route */, stack.IP) {
 				// nftables is telling us to drop the packet.
+				e.recordDrop(tcpip.DropReasonFilter, pkt)
 				return
 			}
 		}
@@ -1001,6 +1024,11 @@ func (e *endpoint) HandlePacket(pkt *stack.PacketBuffer) {
 	e.handleValidatedPacket(h, pkt, e.nic.Name() /* inNICName */)
 }
 
+// recordDrop records that pkt was discarded by the endpoint for reason.
+func (e *endpoint) recordDrop(reason tcpip.DropReason, pkt *stack.PacketBuffer) {
+	e.protocol.stack.RecordDrop(e.nic.ID(), reason, pkt)
+}
+
 // handleLocalPacket is like HandlePacket except it does not perform the
 // prerouting iptables hook or check for loopback traffic that originated from
 // outside of the netstack (i.e. martian loopback packets).
@@ -1015,6 +1043,7 @@ func (e *endpoint) handleLocalPacket(pkt *stack.PacketBuffer, canSkipRXChecksum
 	hView, ok := e.protocol.parseAndValidate(pkt)
 	if !ok {
 		stats.MalformedPacketsReceived.Increment()
+		e.recordDrop(tcpip.DropReasonMalformed, pkt)
 		return
 	}
 	h := header.IPv4(hView.AsSlice())
@@ -1230,6 +1259,7 @@ func (e *endpoint) handleValidatedPacket(h header.IPv4, pkt *stack.PacketBuffer,
 	//   multicast address).
 	if srcAddr == header.IPv4Broadcast || header.IsV4MulticastAddress(srcAddr) {
 		stats.ip.InvalidSourceAddressesReceived.Increment()
+		e.recordDrop(tcpip.DropReasonInvalidSource, pkt)
 		return
 	}
 	// Make sure the source address is not a subnet-local broadcast address.
@@ -1237,6 +1267,7 @@ func (e *endpoint) handleValidatedPacket(h header.IPv4, pkt *stack.PacketBuffer,
 		subnet := addressEndpoint.Subnet()
 		if subnet.IsBroadcast(srcAddr) {
 			stats.ip.InvalidSourceAddressesReceived.Increment()
+			e.recordDrop(tcpip.DropReasonInvalidSource, pkt)
 			return
 		}
 	}
@@ -1262,6 +1293,7 @@ func (e *endpoint) handleValidatedPacket(h header.IPv4, pkt *stack.PacketBuffer,
 			// Only consider the destination address invalid if we didn't attempt to
 			// forward the pkt and it was not delivered locally.
 			stats.ip.InvalidDestinationAddressesReceived.Increment()
+			e.recordDrop(tcpip.DropReasonInvalidDestination, pkt)
 		}
 		return
 	}
@@ -1280,6 +1312,7 @@ func (e *endpoint) handleValidatedPacket(h header.IPv4, pkt *stack.PacketBuffer,
 		e.handleForwardingError(e.forwardUnicastPacket(pkt))
 	} else {
 		stats.ip.InvalidDestinationAddressesReceived.Increment()
+		e.recordDrop(tcpip.DropReasonInvalidDestination, pkt)
 	}
 }
 
@@ -1333,12 +1366,14 @@ func (e *endpoint) deliverPacketLocally(h header.IPv4, pkt *stack.PacketBuffer,
 	if ok := stk.IPTables().CheckInput(pkt, inNICName); !ok {
 		// iptables is telling us to drop the packet.
 		stats.ip.IPTablesInputDropped.Increment()
+		e.recordDrop(tcpip.DropReasonFilter, pkt)
 		return
 	}
 
 	if nft := stk.NFTables(); nft != nil && stk.IsNFTablesConfigured() {
 		if !nft.CheckInput(pkt, nil /* route */, stack.IP) {
 			// nftables is telling us to drop the packet.
+			e.recordDrop(tcpip.DropReasonFilter, pkt)
 			return
 		}
 	}
@@ -1348,6 +1383,7 @@ func (e *endpoint) deliverPacketLocally(h header.IPv4, pkt *stack.PacketBuffer,
 			// Drop the packet as it's marked as a fragment but has
 			// no payload.
 			stats.ip.MalformedPacketsReceived.Increment()
+			e.recordDrop(tcpip.DropReasonMalformed, pkt)
 			stats.ip.MalformedFragmentsReceived.Increment()
 			return
 		}
@@ -1361,6 +1397,7 @@ func (e *endpoint) deliverPacketLocally(h header.IPv4, pkt *stack.PacketBuffer,
 						pointer: optProblem.Pointer,
 					}, pkt, true /* deliveredLocally */)
 					e.stats.ip.MalformedPacketsReceived.Increment()
+					e.recordDrop(tcpip.DropReasonMalformed, pkt)
 				}
 				return
 			}
@@ -1376,6 +1413,7 @@ func (e *endpoint) deliverPacketLocally(h header.IPv4, pkt *stack.PacketBuffer,
 		// reaching here.
 		if int(start)+pkt.Data().Size() > header.IPv4MaximumPayloadSize {
 			stats.ip.MalformedPacketsReceived.Increment()
+			e.recordDrop(tcpip.DropReasonMalformed, pkt)
 			stats.ip.MalformedFragmentsReceived.Increment()
 			return
 		}
@@ -1398,6 +1436,7 @@ func (e *endpoint) deliverPacketLocally(h header.IPv4, pkt *stack.PacketBuffer,
 		)
 		if err != nil {
 			stats.ip.MalformedPacketsReceived.Increment()
+			e.recordDrop(tcpip.DropReasonMalformed, pkt)
 			stats.ip.MalformedFragmentsReceived.Increment()
 			return
 		}
@@ -1439,6 +1478,7 @@ func (e *endpoint) deliverPacketLocally(h header.IPv4, pkt *stack.PacketBuffer,
 					pointer: optProblem.Pointer,
 				}, pkt, true /* deliveredLocally */)
 				stats.ip.MalformedPacketsReceived.Increment()
+				e.recordDrop(tcpip.DropReasonMalformed, pkt)
 			}
 			return
 		}
@@ -1579,6 +1619,13 @@ func (e *endpoint) PermanentAddresses() []tcpip.AddressWithPrefix {
 	return e.addressableEndpointState.PermanentAddresses()
 }
 
+// GetAddress implements stack.AddressableEndpoint.
+func (e *endpoint) GetAddress(addr tcpip.Address) stack.AddressEndpoint {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.addressableEndpointState.GetAddress(addr)
+}
+
 // JoinGroup implements stack.GroupAddressableEndpoint.
 func (e *endpoint) JoinGroup(addr tcpip.Address) tcpip.Error {
 	e.mu.Lock()
@@ -959,6 +959,59 @@ func (e *endpoint) LinkAddressRequest(targetAddr, localAddr tcpip.Address, remot
 	})
 }
 
+// AnnounceLinkAddress implements stack.LinkAddressAnnouncer.
+//
+// It sends an unsolicited Neighbor Advertisement for localAddr to the
+// all-nodes multicast address, as described in RFC 4861 section 7.2.6.
+func (e *endpoint) AnnounceLinkAddress(localAddr tcpip.Address) tcpip.Error {
+	if !e.checkLocalAddress(localAddr) {
+		return &tcpip.ErrBadLocalAddress{}
+	}
+
+	optsSerializer := header.NDPOptionsSerializer{
+		header.NDPTargetLinkLayerAddressOption(e.nic.LinkAddress()),
+	}
+	icmpView := buffer.NewView(header.ICMPv6NeighborAdvertMinimumSize + optsSerializer.Length())
+	icmpView.Grow(header.ICMPv6NeighborAdvertMinimumSize + optsSerializer.Length())
+	icmp := header.ICMPv6(icmpView.AsSlice())
+	icmp.SetType(header.ICMPv6NeighborAdvert)
+	na := header.NDPNeighborAdvert(icmp.MessageBody())
+	// As per RFC 4861 section 7.2.6, the Solicited flag must be zero in
+	// unsolicited advertisements. The Override flag is set so that neighbors
+	// replace any cached link address.
+	na.SetSolicitedFlag(false)
+	na.SetOverrideFlag(true)
+	na.SetRouterFlag(e.Forwarding())
+	na.SetTargetAddress(localAddr)
+	na.Options().Serialize(optsSerializer)
+	icmp.SetChecksum(header.ICMPv6Checksum(header.ICMPv6ChecksumParams{
+		Header: icmp,
+		Src:    localAddr,
+		Dst:    header.IPv6AllNodesMulticastAddress,
+	}))
+
+	pkt := stack.NewPacketBuffer(stack.PacketBufferOptions{
+		ReserveHeaderBytes: int(e.MaxHeaderLength()),
+		Payload:            buffer.MakeWithView(icmpView),
+	})
+	defer pkt.DecRef()
+
+	if err := addIPHeader(localAddr, header.IPv6AllNodesMulticastAddress, pkt, stack.NetworkHeaderParams{
+		Protocol: header.ICMPv6ProtocolNumber,
+		TTL:      header.NDPHopLimit,
+	}, nil /* extensionHeaders */); err != nil {
+		panic(fmt.Sprintf("failed to add IP header: %s", err))
+	}
+
+	sent := e.stats.icmp.packetsSent
+	if err := e.nic.WritePacketToRemote(header.EthernetAddressFromMulticastIPv6Address(header.IPv6AllNodesMulticastAddress), pkt); err != nil {
+		sent.dropped.Increment()
+		return err
+	}
+	sent.neighborAdvert.Increment()
+	return nil
+}
+
 // ResolveStaticAddress implements stack.LinkAddressResolver.
 func (*endpoint) ResolveStaticAddress(addr tcpip.Address) (tcpip.LinkAddress, bool) {
 	if header.IsV6MulticastAddress(addr) {
@@ -170,6 +170,7 @@ func getLabel(addr tcpip.Address) uint8 {
 
 var _ stack.DuplicateAddressDetector = (*endpoint)(nil)
 var _ stack.LinkAddressResolver = (*endpoint)(nil)
+var _ stack.LinkAddressAnnouncer = (*endpoint)(nil)
 var _ stack.LinkResolvableNetworkEndpoint = (*endpoint)(nil)
 var _ stack.ForwardingNetworkEndpoint = (*endpoint)(nil)
 var _ stack.MulticastForwardingNetworkEndpoint = (*endpoint)(nil)
@@ -1034,6 +1035,11 @@ func (e *endpoint) forwardUnicastPacket(pkt *stack.PacketBuffer) ip.ForwardingEr
 			}
 		}
 
+		if f := stk.PacketFilter(); f != nil && !f.CheckForward(pkt, e.nic.ID(), nil /* route */, ProtocolNumber) {
+			// The external packet filter is telling us to drop the packet.
+			return nil
+		}
+
 		// The packet originally arrived on e so provide its NIC as the input NIC.
 		ep.handleValidatedPacket(h, pkt, e.nic.Name() /* inNICName */)
 		return nil
@@ -1091,6 +1097,13 @@ func (e *endpoint) forwardPacketWithRoute(route *stack.Route, pkt *stack.PacketB
 	defer newPkt.DecRef()
 	newHdr := header.IPv6(newPkt.NetworkHeader().Slice())
 
+	// The external packet filter may rewrite the packet, so it only gets our
+	// copy.
+	if f := stk.PacketFilter(); f != nil && !f.CheckForward(newPkt, e.nic.ID(), route, ProtocolNumber) {
+		// The external packet filter is telling us to drop the packet.
+		return nil
+	}
+
 	// As per RFC 8200 section 3,
 	//
 	//   Hop Limit           8-bit unsigned integer. Decremented by 1 by
@@ -1133,12 +1146,14 @@ func (e *endpoint) HandlePacket(pkt *stack.PacketBuffer) {
 
 	if !e.isEnabled() {
 		stats.DisabledPacketsReceived.Increment()
+		e.recordDrop(tcpip.DropReasonNICDisabled, pkt)
 		return
 	}
 
 	hView, ok := e.protocol.parseAndValidate(pkt)
 	if !ok {
 		stats.MalformedPacketsReceived.Increment()
+		e.recordDrop(tcpip.DropReasonMalformed, pkt)
 		return
 	}
 	defer hView.Release()
@@ -1152,11 +1167,13 @@ func (e *endpoint) HandlePacket(pkt *stack.PacketBuffer) {
 		if !e.protocol.options.AllowExternalLoopbackTraffic {
 			if header.IsV6LoopbackAddress(h.SourceAddress()) {
 				stats.InvalidSourceAddressesReceived.Increment()
+				e.recordDrop(tcpip.DropReasonInvalidSource, pkt)
 				return
 			}
 
 			if header.IsV6LoopbackAddress(h.DestinationAddress()) {
 				stats.InvalidDestinationAddressesReceived.Increment()
+				e.recordDrop(tcpip.DropReasonInvalidDestination, pkt)
 				return
 			}
 		}
@@ -1169,6 +1186,7 @@ func (e *endpoint) HandlePacket(pkt *stack.PacketBuffer) {
 				// a packet like this unless HandleLocal is false or our NIC is the
 				// loopback interface.
 				stats.InvalidSourceAddressesReceived.Increment()
+				e.recordDrop(tcpip.DropReasonInvalidSource, pkt)
 				return
 			}
 		}
@@ -1178,12 +1196,14 @@ func (e *endpoint) HandlePacket(pkt *stack.PacketBuffer) {
 		if ok := stk.IPTables().CheckPrerouting(pkt, e, inNicName); !ok {
 			// iptables is telling us to drop the packet.
 			stats.IPTablesPreroutingDropped.Increment()
+			e.recordDrop(tcpip.DropReasonFilter, pkt)
 			return
 		}
 
 		if nft := stk.NFTables(); nft != nil && stk.IsNFTablesConfigured() {
 			if !nft.CheckPrerouting(pkt, nil, stack.IP6) {
 				// nftables is telling us to drop the packet.
+				e.recordDrop(tcpip.DropReasonFilter, pkt)
 				return
 			}
 		}
@@ -1195,6 +1215,11 @@ func (e *endpoint) HandlePacket(pkt *stack.PacketBuffer) {
 	e.handleValidatedPacket(h, pkt, e.nic.Name() /* inNICName */)
 }
 
+// recordDrop records that pkt was discarded by the endpoint for reason.
+func (e *endpoint) recordDrop(reason tcpip.DropReason, pkt *stack.PacketBuffer) {
+	e.protocol.stack.RecordDrop(e.nic.ID(), reason, pkt)
+}
+
 // handleLocalPacket is like HandlePacket except it does not perform the
 // prerouting iptables hook or check for loopback traffic that originated from
 // outside of the netstack (i.e. martian loopback packets).
@@ -1209,6 +1234,7 @@ func (e *endpoint) handleLocalPacket(pkt *stack.PacketBuffer, canSkipRXChecksum
 	hView, ok := e.protocol.parseAndValidate(pkt)
 	if !ok {
 		stats.MalformedPacketsReceived.Increment()
+		e.recordDrop(tcpip.DropReasonMalformed, pkt)
 		return
 	}
 	defer hView.Release()
@@ -1390,6 +1416,7 @@ func (e *endpoint) handleValidatedPacket(h header.IPv6, pkt *stack.PacketBuffer,
 	//   packets or appear in any Routing header.
 	if header.IsV6MulticastAddress(srcAddr) {
 		stats.InvalidSourceAddressesReceived.Increment()
+		e.recordDrop(tcpip.DropReasonInvalidSource, pkt)
 		return
 	}
 
@@ -1414,6 +1441,7 @@ func (e *endpoint) handleValidatedPacket(h header.IPv6, pkt *stack.PacketBuffer,
 			// Only consider the destination address invalid if we didn't attempt to
 			// forward the pkt and it was not delivered locally.
 			stats.InvalidDestinationAddressesReceived.Increment()
+			e.recordDrop(tcpip.DropReasonInvalidDestination, pkt)
 		}
 
 		return
@@ -1427,6 +1455,7 @@ func (e *endpoint) handleValidatedPacket(h header.IPv6, pkt *stack.PacketBuffer,
 		e.handleForwardingError(e.forwardUnicastPacket(pkt))
 	} else {
 		stats.InvalidDestinationAddressesReceived.Increment()
+		e.recordDrop(tcpip.DropReasonInvalidDestination, pkt)
 	}
 }
 
@@ -1436,12 +1465,14 @@ func (e *endpoint) deliverPacketLocally(h header.IPv6, pkt *stack.PacketBuffer,
 	if ok := stk.IPTables().CheckInput(pkt, inNICName); !ok {
 		// iptables is telling us to drop the packet.
 		stats.IPTablesInputDropped.Increment()
+		e.recordDrop(tcpip.DropReasonFilter, pkt)
 		return
 	}
 
 	if nft := stk.NFTables(); nft != nil && stk.IsNFTablesConfigured() {
 		if !nft.CheckInput(pkt, nil, stack.IP6) {
 			// nftables is telling us to drop the packet.
+			e.recordDrop(tcpip.DropReasonFilter, pkt)
 			return
 		}
 	}
@@ -1491,7 +1522,7 @@ func (e *endpoint) processExtensionHeader(it *header.IPv6PayloadIterator, pkt **
 			return true, err
 		}
 	case header.IPv6RoutingExtHdr:
-		if err := e.processIPv6RoutingExtHeader(&extHdr, it, *pkt); err != nil {
+		if forwarded, err := e.processIPv6RoutingExtHeader(&extHdr, it, *pkt); err != nil || forwarded {
 			return true, err
 		}
 	case header.IPv6FragmentExtHdr:
@@ -1650,7 +1681,10 @@ func (e *endpoint) processIPv6RawPayloadHeader(extHdr *header.IPv6RawPayloadHead
 	}
 }
 
-func (e *endpoint) processIPv6RoutingExtHeader(extHdr *header.IPv6RoutingExtHdr, it *header.IPv6PayloadIterator, pkt *stack.PacketBuffer) error {
+// processIPv6RoutingExtHeader processes a routing extension header. It returns
+// true if the packet was forwarded to the next segment of its route, in which
+// case it must not be processed further.
+func (e *endpoint) processIPv6RoutingExtHeader(extHdr *header.IPv6RoutingExtHdr, it *header.IPv6PayloadIterator, pkt *stack.PacketBuffer) (bool, error) {
 	// As per RFC 8200 section 4.4, if a node encounters a routing header with
 	// an unrecognized routing type value, with a non-zero Segments Left
 	// value, the node must discard the packet and send an ICMP Parameter
@@ -1659,18 +1693,70 @@ func (e *endpoint) processIPv6RoutingExtHeader(extHdr *header.IPv6RoutingExtHdr,
 	//
 	// If the Segments Left is 0, the node must ignore the Routing extension
 	// header and process the next header in the packet.
-	//
-	// Note, the stack does not yet handle any type of routing extension
-	// header, so we just make sure Segments Left is zero before processing
-	// the next extension header.
 	if extHdr.SegmentsLeft() == 0 {
-		return nil
+		return false, nil
+	}
+	if extHdr.RoutingType() == header.IPv6RoutingTypeSegmentRouting && e.Forwarding() && e.protocol.extensionHeaderOptions().SegmentRouting {
+		if err := e.processIPv6SegmentRoutingHeader(extHdr, it, pkt); err != nil {
+			return false, err
+		}
+		return true, nil
 	}
 	_ = e.protocol.returnError(&icmpReasonParameterProblem{
 		code:    header.ICMPv6ErroneousHeader,
 		pointer: it.ParseOffset(),
 	}, pkt, true /* deliveredLocally */)
-	return fmt.Errorf("found unrecognized routing type with non-zero segments left in header = %#v", extHdr)
+	return false, fmt.Errorf("found unrecognized routing type with non-zero segments left in header = %#v", extHdr)
+}
+
+// processIPv6SegmentRoutingHeader performs the End behaviour of a segment
+// endpoint node on a packet carrying a Segment Routing Header with a non-zero
+// Segments Left field, as per RFC 8754 section 4.3.1.
+//
+// If no error is returned, the packet was resubmitted for forwarding towards
+// the next segment and must not be processed locally.
+func (e *endpoint) processIPv6SegmentRoutingHeader(extHdr *header.IPv6RoutingExtHdr, it *header.IPv6PayloadIterator, pkt *stack.PacketBuffer) error {
+	// As per RFC 8754 section 4.3.1.1,
+	//
+	//   max_last_entry = ( Hdr Ext Len /  2 ) - 1
+	//   If ((Last Entry > max_last_entry) or
+	//       (Segments Left is greater than (Last Entry+1)) {
+	//     Send an ICMP Parameter Problem, Code 0, message to the Source
+	//     Address, pointing to the Segments Left field, and discard the
+	//     packet.
+	//   }
+	segmentsLeft := extHdr.SegmentsLeft()
+	lastEntry := int(extHdr.SRHLastEntry())
+	if lastEntry > extHdr.SRHSegmentCapacity()-1 || int(segmentsLeft) > lastEntry+1 {
+		e.stats.ip.MalformedPacketsReceived.Increment()
+		_ = e.protocol.returnError(&icmpReasonParameterProblem{
+			code:    header.ICMPv6ErroneousHeader,
+			pointer: it.HeaderOffset() + header.IPv6RoutingExtHdrSegmentsLeftOffset,
+		}, pkt, true /* deliveredLocally */)
+		return fmt.Errorf("found segment routing header with invalid segments left in header = %#v", extHdr)
+	}
+
+	// The routing header must be held in the network header for us to update
+	// it in place. This is not the case for reassembled fragments.
+	slOffset := int(it.HeaderOffset()) + header.IPv6RoutingExtHdrSegmentsLeftOffset
+	if slOffset >= len(pkt.NetworkHeader().Slice()) {
+		return fmt.Errorf("segment routing header at offset %d is not part of the network header", it.HeaderOffset())
+	}
+
+	//   Decrement Segments Left by 1.
+	//   Copy Segment List[Segments Left] from the SRH to the destination
+	//   address of the IPv6 header.
+	//   Resubmit the packet to the IPv6 module for transmission to the new
+	//   destination.
+	segmentsLeft--
+	newPkt := pkt.DeepCopyForForwarding(0 /* reservedHeaderBytes */)
+	defer newPkt.DecRef()
+	newHdr := header.IPv6(newPkt.NetworkHeader().Slice())
+	newHdr[slOffset] = segmentsLeft
+	newHdr.SetDestinationAddress(extHdr.SRHSegment(int(segmentsLeft)))
+
+	e.handleForwardingError(e.forwardUnicastPacket(newPkt))
+	return nil
 }
 
 func (e *endpoint) processIPv6DestinationOptionsExtHdr(extHdr *header.IPv6DestinationOptionsExtHdr, it *header.IPv6PayloadIterator, pkt *stack.PacketBuffer, dstAddr tcpip.Address) error {
@@ -1748,6 +1834,12 @@ func (e *endpoint) processIPv6HopByHopOptionsExtHdr(extHdr *header.IPv6HopByHopO
 		return fmt.Errorf("found Hop-by-Hop header = %#v with non-zero previous header offset = %d", extHdr, previousHeaderStart)
 	}
 
+	opts := e.protocol.extensionHeaderOptions()
+	if opts.MaxHopByHopLength != 0 && extHdr.Length() > opts.MaxHopByHopLength {
+		stats.MalformedPacketsReceived.Increment()
+		return fmt.Errorf("found Hop-by-Hop header = %#v with length %d exceeding the limit of %d", extHdr, extHdr.Length(), opts.MaxHopByHopLength)
+	}
+
 	optsIt := extHdr.Iter()
 	var uopt *header.IPv6UnknownExtHdrOption
 	defer func() {
@@ -1756,6 +1848,7 @@ func (e *endpoint) processIPv6HopByHopOptionsExtHdr(extHdr *header.IPv6HopByHopO
 		}
 	}()
 
+	numOpts := 0
 	for {
 		opt, done, err := optsIt.Next()
 		if err != nil {
@@ -1769,6 +1862,12 @@ func (e *endpoint) processIPv6HopByHopOptionsExtHdr(extHdr *header.IPv6HopByHopO
 			break
 		}
 
+		numOpts++
+		if opts.MaxHopByHopOptions != 0 && numOpts > opts.MaxHopByHopOptions {
+			stats.MalformedPacketsReceived.Increment()
+			return fmt.Errorf("found Hop-by-Hop header = %#v with more than %d options", extHdr, opts.MaxHopByHopOptions)
+		}
+
 		switch opt := opt.(type) {
 		case *header.IPv6RouterAlertOption:
 			if *routerAlert != nil {
@@ -2297,6 +2396,13 @@ func (e *endpoint) PermanentAddresses() []tcpip.AddressWithPrefix {
 	return e.mu.addressableEndpointState.PermanentAddresses()
 }
 
+// GetAddress implements stack.AddressableEndpoint.
+func (e *endpoint) GetAddress(addr tcpip.Address) stack.AddressEndpoint {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.getAddressRLocked(addr)
+}
+
 // JoinGroup implements stack.GroupAddressableEndpoint.
 func (e *endpoint) JoinGroup(addr tcpip.Address) tcpip.Error {
 	e.mu.Lock()
@@ -2362,6 +2468,9 @@ type protocolMu struct {
 	// an integrator can provide to receive multicast forwarding events. Note
 	// that multicast packets will only be forwarded if this is non-nil.
 	multicastForwardingDisp stack.MulticastForwardingEventDispatcher
+
+	// extHdrOpts configures the processing of extension headers.
+	extHdrOpts tcpip.IPv6ExtensionHeaderOption
 }
 
 // +stateify savable
@@ -2480,6 +2589,14 @@ func (p *protocol) SetOption(option tcpip.SettableNetworkProtocolOption) tcpip.E
 	case *tcpip.DefaultTTLOption:
 		p.SetDefaultTTL(uint8(*v))
 		return nil
+	case *tcpip.IPv6ExtensionHeaderOption:
+		if v.MaxHopByHopOptions < 0 || v.MaxHopByHopLength < 0 {
+			return &tcpip.ErrInvalidOptionValue{}
+		}
+		p.mu.Lock()
+		p.mu.extHdrOpts = *v
+		p.mu.Unlock()
+		return nil
 	default:
 		return &tcpip.ErrUnknownProtocolOption{}
 	}
@@ -2491,11 +2608,21 @@ func (p *protocol) Option(option tcpip.GettableNetworkProtocolOption) tcpip.Erro
 	case *tcpip.DefaultTTLOption:
 		*v = tcpip.DefaultTTLOption(p.DefaultTTL())
 		return nil
+	case *tcpip.IPv6ExtensionHeaderOption:
+		*v = p.extensionHeaderOptions()
+		return nil
 	default:
 		return &tcpip.ErrUnknownProtocolOption{}
 	}
 }
 
+// extensionHeaderOptions returns the extension header processing options.
+func (p *protocol) extensionHeaderOptions() tcpip.IPv6ExtensionHeaderOption {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.mu.extHdrOpts
+}
+
 // SetDefaultTTL sets the default TTL for endpoints created with this protocol.
 func (p *protocol) SetDefaultTTL(ttl uint8) {
 	p.defaultTTL.Store(uint32(ttl))
@@ -3411,6 +3411,236 @@ func TestForwarding(t *testing.T) {
 	}
 }
 
+func TestForwardingExtensionHeaderOptions(t *testing.T) {
+	srh := func(segmentsLeft, lastEntry uint8, segments ...tcpip.Address) func(nextHdr uint8) []byte {
+		return func(nextHdr uint8) []byte {
+			b := []byte{nextHdr, uint8(2 * len(segments)), header.IPv6RoutingTypeSegmentRouting, segmentsLeft, lastEntry, 0, 0, 0}
+			for _, segment := range segments {
+				b = append(b, segment.AsSlice()...)
+			}
+			return b
+		}
+	}
+	hopByHop := func(nextHdr uint8) []byte {
+		return []byte{
+			nextHdr, 1,
+
+			// Skippable unknown.
+			63, 4, 1, 2, 3, 4,
+
+			// Skippable unknown.
+			62, 6, 1, 2, 3, 4, 5, 6,
+		}
+	}
+
+	tests := []struct {
+		name                          string
+		opt                           tcpip.IPv6ExtensionHeaderOption
+		extHdr                        func(nextHdr uint8) []byte
+		extHdrID                      uint8
+		dstAddr                       tcpip.Address
+		expectedDstAddr               tcpip.Address
+		expectedExtensionHeaderErrors uint64
+		expectedICMPError             *icmpError
+	}{
+		{
+			name:            "SRH with segment routing enabled",
+			opt:             tcpip.IPv6ExtensionHeaderOption{SegmentRouting: true},
+			extHdr:          srh(1, 0, remoteIPv6Addr2),
+			extHdrID:        routingExtHdrID,
+			dstAddr:         incomingIPv6Addr.Address,
+			expectedDstAddr: remoteIPv6Addr2,
+		},
+		{
+			name:     "SRH with segment routing disabled",
+			extHdr:   srh(1, 0, remoteIPv6Addr2),
+			extHdrID: routingExtHdrID,
+			dstAddr:  incomingIPv6Addr.Address,
+			expectedICMPError: &icmpError{
+				icmpType: header.ICMPv6ParamProblem,
+				icmpCode: header.ICMPv6ErroneousHeader,
+			},
+		},
+		{
+			name:     "SRH with segments left greater than last entry plus one",
+			opt:      tcpip.IPv6ExtensionHeaderOption{SegmentRouting: true},
+			extHdr:   srh(2, 0, remoteIPv6Addr2),
+			extHdrID: routingExtHdrID,
+			dstAddr:  incomingIPv6Addr.Address,
+			expectedICMPError: &icmpError{
+				icmpType: header.ICMPv6ParamProblem,
+				icmpCode: header.ICMPv6ErroneousHeader,
+			},
+		},
+		{
+			name:     "SRH with last entry beyond segment list",
+			opt:      tcpip.IPv6ExtensionHeaderOption{SegmentRouting: true},
+			extHdr:   srh(1, 1, remoteIPv6Addr2),
+			extHdrID: routingExtHdrID,
+			dstAddr:  incomingIPv6Addr.Address,
+			expectedICMPError: &icmpError{
+				icmpType: header.ICMPv6ParamProblem,
+				icmpCode: header.ICMPv6ErroneousHeader,
+			},
+		},
+		{
+			name:            "Hopbyhop within limits",
+			opt:             tcpip.IPv6ExtensionHeaderOption{MaxHopByHopOptions: 2, MaxHopByHopLength: 16},
+			extHdr:          hopByHop,
+			extHdrID:        hopByHopExtHdrID,
+			dstAddr:         remoteIPv6Addr2,
+			expectedDstAddr: remoteIPv6Addr2,
+		},
+		{
+			name:                          "Hopbyhop exceeding option limit",
+			opt:                           tcpip.IPv6ExtensionHeaderOption{MaxHopByHopOptions: 1},
+			extHdr:                        hopByHop,
+			extHdrID:                      hopByHopExtHdrID,
+			dstAddr:                       remoteIPv6Addr2,
+			expectedExtensionHeaderErrors: 1,
+		},
+		{
+			name:                          "Hopbyhop exceeding length limit",
+			opt:                           tcpip.IPv6ExtensionHeaderOption{MaxHopByHopLength: 8},
+			extHdr:                        hopByHop,
+			extHdrID:                      hopByHopExtHdrID,
+			dstAddr:                       remoteIPv6Addr2,
+			expectedExtensionHeaderErrors: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestContext()
+			defer c.cleanup()
+			s := c.s
+
+			endpoints := make(map[tcpip.NICID]*channel.Endpoint)
+			for nicID, addr := range defaultEndpointConfigs {
+				ep := channel.New(1, header.IPv6MinimumMTU, "")
+				defer ep.Close()
+
+				if err := s.CreateNIC(nicID, ep); err != nil {
+					t.Fatalf("s.CreateNIC(%d, _): %s", nicID, err)
+				}
+				addr := tcpip.ProtocolAddress{Protocol: ProtocolNumber, AddressWithPrefix: addr}
+				if err := s.AddProtocolAddress(nicID, addr, stack.AddressProperties{}); err != nil {
+					t.Fatalf("s.AddProtocolAddress(%d, %+v, {}): %s", nicID, addr, err)
+				}
+				endpoints[nicID] = ep
+			}
+
+			s.SetRouteTable([]tcpip.Route{
+				{
+					Destination: incomingIPv6Addr.Subnet(),
+					NIC:         incomingNICID,
+				},
+				{
+					Destination: outgoingIPv6Addr.Subnet(),
+					NIC:         outgoingNICID,
+				},
+			})
+
+			if err := s.SetForwardingDefaultAndAllNICs(ProtocolNumber, true); err != nil {
+				t.Fatalf("s.SetForwardingDefaultAndAllNICs(%d, true): %s", ProtocolNumber, err)
+			}
+
+			opt := test.opt
+			if err := s.SetNetworkProtocolOption(ProtocolNumber, &opt); err != nil {
+				t.Fatalf("s.SetNetworkProtocolOption(%d, &%#v): %s", ProtocolNumber, opt, err)
+			}
+			var got tcpip.IPv6ExtensionHeaderOption
+			if err := s.NetworkProtocolOption(ProtocolNumber, &got); err != nil {
+				t.Fatalf("s.NetworkProtocolOption(%d, _): %s", ProtocolNumber, err)
+			}
+			if got != test.opt {
+				t.Fatalf("got s.NetworkProtocolOption(%d, _) = %#v, want = %#v", ProtocolNumber, got, test.opt)
+			}
+
+			const ttl = 2
+			extHdrBytes := test.extHdr(uint8(header.ICMPv6ProtocolNumber))
+			payloadLength := header.ICMPv6MinimumSize + len(extHdrBytes)
+			hdr := prependable.New(header.IPv6MinimumSize + payloadLength)
+			icmpH := header.ICMPv6(hdr.Prepend(header.ICMPv6MinimumSize))
+			icmpH.SetIdent(randomIdent)
+			icmpH.SetSequence(randomSequence)
+			icmpH.SetType(header.ICMPv6EchoRequest)
+			icmpH.SetCode(header.ICMPv6UnusedCode)
+			icmpH.SetChecksum(0)
+			icmpH.SetChecksum(header.ICMPv6Checksum(header.ICMPv6ChecksumParams{
+				Header: icmpH,
+				Src:    remoteIPv6Addr1,
+				Dst:    remoteIPv6Addr2,
+			}))
+			copy(hdr.Prepend(len(extHdrBytes)), extHdrBytes)
+			ip := header.IPv6(hdr.Prepend(header.IPv6MinimumSize))
+			ip.Encode(&header.IPv6Fields{
+				PayloadLength:     uint16(payloadLength),
+				TransportProtocol: tcpip.TransportProtocolNumber(test.extHdrID),
+				HopLimit:          ttl,
+				SrcAddr:           remoteIPv6Addr1,
+				DstAddr:           test.dstAddr,
+			})
+			request := stack.NewPacketBuffer(stack.PacketBufferOptions{
+				Payload: buffer.MakeWithData(hdr.View()),
+			})
+
+			incomingEndpoint := endpoints[incomingNICID]
+			outgoingEndpoint := endpoints[outgoingNICID]
+			incomingEndpoint.InjectInbound(ProtocolNumber, request)
+			request.DecRef()
+
+			reply := incomingEndpoint.Read()
+			if test.expectedICMPError != nil {
+				if reply == nil {
+					t.Fatalf("Expected ICMP packet type %d through incoming NIC", test.expectedICMPError.icmpType)
+				}
+				payload := stack.PayloadSince(reply.NetworkHeader())
+				defer payload.Release()
+				checker.IPv6(t, payload,
+					checker.SrcAddr(incomingIPv6Addr.Address),
+					checker.DstAddr(remoteIPv6Addr1),
+					checker.ICMPv6(
+						checker.ICMPv6Type(test.expectedICMPError.icmpType),
+						checker.ICMPv6Code(test.expectedICMPError.icmpCode),
+					),
+				)
+				reply.DecRef()
+			} else if reply != nil {
+				t.Fatalf("Expected no ICMP packet through incoming NIC, instead found: %#v", reply)
+			}
+
+			reply = outgoingEndpoint.Read()
+			if len(test.expectedDstAddr.AsSlice()) != 0 {
+				if reply == nil {
+					t.Fatal("Expected ICMP Echo Request packet through outgoing NIC")
+				}
+				payload := stack.PayloadSince(reply.NetworkHeader())
+				defer payload.Release()
+				h := header.IPv6(payload.AsSlice())
+				if got := h.DestinationAddress(); got != test.expectedDstAddr {
+					t.Errorf("got h.DestinationAddress() = %s, want = %s", got, test.expectedDstAddr)
+				}
+				if got, want := h.HopLimit(), uint8(ttl-1); got != want {
+					t.Errorf("got h.HopLimit() = %d, want = %d", got, want)
+				}
+				if test.extHdrID == routingExtHdrID {
+					if got := h[header.IPv6MinimumSize+header.IPv6RoutingExtHdrSegmentsLeftOffset]; got != 0 {
+						t.Errorf("got Segments Left = %d, want = 0", got)
+					}
+				}
+				reply.DecRef()
+			} else if reply != nil {
+				t.Fatalf("Expected no packet through outgoing NIC, instead found: %#v", reply)
+			}
+
+			if got, want := s.Stats().IP.Forwarding.ExtensionHeaderProblem.Value(), test.expectedExtensionHeaderErrors; got != want {
+				t.Errorf("s.Stats().IP.Forwarding.ExtensionHeaderProblem.Value() = %d, want = %d", got, want)
+			}
+		})
+	}
+}
+
 func TestMulticastForwarding(t *testing.T) {
 	const (
 		multicastRouteMinTTL = 2
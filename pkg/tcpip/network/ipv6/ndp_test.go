@@ -595,6 +595,61 @@ func TestNeighborSolicitationResponse(t *testing.T) {
 // TestNeighborAdvertisementWithTargetLinkLayerOption tests that receiving a
 // valid NDP NA message with the Target Link Layer Address option does not
 // result in a new entry in the neighbor cache for the target of the message.
+func TestAnnounceAddresses(t *testing.T) {
+	const nicID = 1
+	nicAddr := lladdr0
+	nicLinkAddr := linkAddr0
+
+	c := newTestContext()
+	defer c.cleanup()
+	s := c.s
+
+	e := channel.New(1, 1280, nicLinkAddr)
+	defer e.Close()
+	e.LinkEPCapabilities |= stack.CapabilityResolutionRequired
+	if err := s.CreateNIC(nicID, e); err != nil {
+		t.Fatalf("CreateNIC(%d, _) = %s", nicID, err)
+	}
+	protocolAddr := tcpip.ProtocolAddress{
+		Protocol:          ProtocolNumber,
+		AddressWithPrefix: nicAddr.WithPrefix(),
+	}
+	if err := s.AddProtocolAddress(nicID, protocolAddr, stack.AddressProperties{}); err != nil {
+		t.Fatalf("AddProtocolAddress(%d, %+v, {}): %s", nicID, protocolAddr, err)
+	}
+
+	if err := s.AnnounceAddresses(nicID); err != nil {
+		t.Fatalf("s.AnnounceAddresses(%d): %s", nicID, err)
+	}
+
+	p := e.Read()
+	if p == nil {
+		t.Fatal("expected an unsolicited NDP NA")
+	}
+	defer p.DecRef()
+	if want := header.EthernetAddressFromMulticastIPv6Address(header.IPv6AllNodesMulticastAddress); p.EgressRoute.RemoteLinkAddress != want {
+		t.Errorf("got p.EgressRoute.RemoteLinkAddress = %s, want = %s", p.EgressRoute.RemoteLinkAddress, want)
+	}
+
+	payload := stack.PayloadSince(p.NetworkHeader())
+	defer payload.Release()
+	checker.IPv6(t, payload,
+		checker.SrcAddr(nicAddr),
+		checker.DstAddr(header.IPv6AllNodesMulticastAddress),
+		checker.TTL(header.NDPHopLimit),
+		checker.NDPNA(
+			checker.NDPNASolicitedFlag(false),
+			checker.NDPNATargetAddress(nicAddr),
+			checker.NDPNAOptions([]header.NDPOption{
+				header.NDPTargetLinkLayerAddressOption(nicLinkAddr[:]),
+			}),
+		))
+
+	if got := s.Stats().ICMP.V6.PacketsSent.NeighborAdvert.Value(); got != 1 {
+		t.Errorf("got NeighborAdvert = %d, want = 1", got)
+	}
+}
+
 func TestNeighborAdvertisementWithTargetLinkLayerOption(t *testing.T) {
 	const nicID = 1
 
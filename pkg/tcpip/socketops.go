@@ -17,9 +17,22 @@ package tcpip
 import (
 	"gvisor.dev/gvisor/pkg/atomicbitops"
 	"gvisor.dev/gvisor/pkg/buffer"
+	"gvisor.dev/gvisor/pkg/state"
 	"gvisor.dev/gvisor/pkg/sync"
 )
 
+func init() {
+	// IPV6_MULTICAST_LOOP used to share IP_MULTICAST_LOOP's value.
+	state.RegisterSchemaChange("pkg/tcpip.SocketOptions", state.SchemaChange{
+		Version:     5,
+		AddedFields: []string{"ipv6MulticastLoopEnabled"},
+		Upgrade: func(objPtr any) {
+			so := objPtr.(*SocketOptions)
+			so.ipv6MulticastLoopEnabled.Store(so.multicastLoopEnabled.Load())
+		},
+	})
+}
+
 // SocketOptionsHandler holds methods that help define endpoint specific
 // behavior for socket level socket options. These must be implemented by
 // endpoints to get notified when socket level options are set.
@@ -172,6 +185,10 @@ type SocketOptions struct {
 	// non-loopback interface will be looped back.
 	multicastLoopEnabled atomicbitops.Uint32
 
+	// ipv6MulticastLoopEnabled determines whether IPv6 multicast packets sent
+	// over a non-loopback interface will be looped back.
+	ipv6MulticastLoopEnabled atomicbitops.Uint32
+
 	// receiveTOSEnabled is used to specify if the TOS ancillary message is
 	// passed with incoming packets.
 	receiveTOSEnabled atomicbitops.Uint32
@@ -264,6 +281,9 @@ type SocketOptions struct {
 	// mark is the mark value set for the socket.
 	mark atomicbitops.Uint32
 
+	// priority is the queueing priority of the packets sent by the socket.
+	priority atomicbitops.Uint32
+
 	// mu protects the access to the below fields.
 	mu sync.Mutex `state:"nosave"`
 
@@ -367,6 +387,16 @@ func (so *SocketOptions) SetMulticastLoop(v bool) {
 	storeAtomicBool(&so.multicastLoopEnabled, v)
 }
 
+// GetIPv6MulticastLoop gets value for IPV6_MULTICAST_LOOP option.
+func (so *SocketOptions) GetIPv6MulticastLoop() bool {
+	return so.ipv6MulticastLoopEnabled.Load() != 0
+}
+
+// SetIPv6MulticastLoop sets value for IPV6_MULTICAST_LOOP option.
+func (so *SocketOptions) SetIPv6MulticastLoop(v bool) {
+	storeAtomicBool(&so.ipv6MulticastLoopEnabled, v)
+}
+
 // GetReceiveTOS gets value for IP_RECVTOS option.
 func (so *SocketOptions) GetReceiveTOS() bool {
 	return so.receiveTOSEnabled.Load() != 0
@@ -784,3 +814,13 @@ func (so *SocketOptions) GetMark() uint32 {
 func (so *SocketOptions) SetMark(v uint32) {
 	so.mark.Store(v)
 }
+
+// GetPriority gets value for SO_PRIORITY option.
+func (so *SocketOptions) GetPriority() uint32 {
+	return so.priority.Load()
+}
+
+// SetPriority sets value for SO_PRIORITY option.
+func (so *SocketOptions) SetPriority(v uint32) {
+	so.priority.Store(v)
+}
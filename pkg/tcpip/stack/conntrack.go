@@ -966,3 +966,28 @@ func NfConnTrackPriority(hook NFHook) (int, bool) {
 	}
 	return 0, false
 }
+
+// ConnTrackSummary summarizes the connections tracked by a ConnTrack.
+type ConnTrackSummary struct {
+	// Connections is the number of tracked connections, by transport protocol.
+	Connections map[tcpip.TransportProtocolNumber]int
+}
+
+// summary returns a summary of the tracked connections.
+func (ct *ConnTrack) summary() ConnTrackSummary {
+	s := ConnTrackSummary{Connections: make(map[tcpip.TransportProtocolNumber]int)}
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	for i := range ct.buckets {
+		bkt := &ct.buckets[i]
+		bkt.mu.RLock()
+		for tuple := bkt.tuples.Front(); tuple != nil; tuple = tuple.Next() {
+			// Each connection has a tuple in both directions.
+			if !tuple.reply {
+				s.Connections[tuple.tupleID.transProto]++
+			}
+		}
+		bkt.mu.RUnlock()
+	}
+	return s
+}
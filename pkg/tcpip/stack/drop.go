@@ -0,0 +1,41 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"gvisor.dev/gvisor/pkg/tcpip"
+)
+
+// DropObserver is notified of packets discarded by the stack.
+type DropObserver interface {
+	// PacketDropped is called when the stack discards pkt for reason. nicID
+	// is the NIC the packet was received on, or 0 if it is not known.
+	//
+	// PacketDropped is called synchronously from the packet processing path,
+	// so it must not block. pkt must not be modified or retained beyond the
+	// call.
+	PacketDropped(nicID tcpip.NICID, reason tcpip.DropReason, pkt *PacketBuffer)
+}
+
+// RecordDrop records that pkt was discarded for reason. It increments the
+// per-reason drop counter and notifies the stack's DropObserver, if any.
+//
+// pkt may be nil when the packet is no longer available at the drop site.
+func (s *Stack) RecordDrop(nicID tcpip.NICID, reason tcpip.DropReason, pkt *PacketBuffer) {
+	s.stats.Drops.Counter(reason).Increment()
+	if s.dropObserver != nil && pkt != nil {
+		s.dropObserver.PacketDropped(nicID, reason, pkt)
+	}
+}
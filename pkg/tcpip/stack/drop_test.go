@@ -0,0 +1,82 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"strings"
+	"testing"
+
+	"gvisor.dev/gvisor/pkg/buffer"
+	"gvisor.dev/gvisor/pkg/tcpip"
+)
+
+type testDropObserver struct {
+	nicIDs  []tcpip.NICID
+	reasons []tcpip.DropReason
+}
+
+func (o *testDropObserver) PacketDropped(nicID tcpip.NICID, reason tcpip.DropReason, _ *PacketBuffer) {
+	o.nicIDs = append(o.nicIDs, nicID)
+	o.reasons = append(o.reasons, reason)
+}
+
+func TestDropStatsCounter(t *testing.T) {
+	stats := tcpip.Stats{}.FillIn()
+	seen := make(map[*tcpip.StatCounter]tcpip.DropReason)
+	for r := tcpip.DropReasonUnknown; r < tcpip.NumDropReasons; r++ {
+		if strings.HasPrefix(r.String(), "DropReason(") {
+			t.Errorf("reason %d has no name", r)
+		}
+		c := stats.Drops.Counter(r)
+		if other, ok := seen[c]; ok {
+			t.Errorf("reasons %s and %s share a counter", other, r)
+		}
+		seen[c] = r
+	}
+	if got, want := stats.Drops.Counter(tcpip.NumDropReasons), stats.Drops.Unknown; got != want {
+		t.Errorf("got Counter(%d) = %p, want = Drops.Unknown (%p)", tcpip.NumDropReasons, got, want)
+	}
+}
+
+func TestRecordDrop(t *testing.T) {
+	var obs testDropObserver
+	s := New(Options{DropObserver: &obs})
+	defer s.Destroy()
+
+	pkt := NewPacketBuffer(PacketBufferOptions{
+		Payload: buffer.MakeWithData([]byte{1, 2, 3, 4}),
+	})
+	defer pkt.DecRef()
+	s.RecordDrop(1, tcpip.DropReasonChecksum, pkt)
+	s.RecordDrop(2, tcpip.DropReasonNetworkPolicy, pkt)
+	// Drops without a packet are counted but not observed.
+	s.RecordDrop(3, tcpip.DropReasonChecksum, nil)
+
+	if got := s.Stats().Drops.Checksum.Value(); got != 2 {
+		t.Errorf("got Drops.Checksum = %d, want = 2", got)
+	}
+	if got := s.Stats().Drops.NetworkPolicy.Value(); got != 1 {
+		t.Errorf("got Drops.NetworkPolicy = %d, want = 1", got)
+	}
+	if got, want := len(obs.reasons), 2; got != want {
+		t.Fatalf("got %d observed drops, want = %d", got, want)
+	}
+	if obs.nicIDs[0] != 1 || obs.reasons[0] != tcpip.DropReasonChecksum {
+		t.Errorf("got first drop = (%d, %s), want = (1, %s)", obs.nicIDs[0], obs.reasons[0], tcpip.DropReasonChecksum)
+	}
+	if obs.nicIDs[1] != 2 || obs.reasons[1] != tcpip.DropReasonNetworkPolicy {
+		t.Errorf("got second drop = (%d, %s), want = (2, %s)", obs.nicIDs[1], obs.reasons[1], tcpip.DropReasonNetworkPolicy)
+	}
+}
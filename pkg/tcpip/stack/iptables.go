@@ -774,6 +774,11 @@ func (it *IPTables) checkRule(hook Hook, pkt *PacketBuffer, table Table, ruleIdx
 	return rule.Target.Action(pkt, hook, r, addressEP)
 }
 
+// ConnTrackSummary returns a summary of the connections tracked by iptables.
+func (it *IPTables) ConnTrackSummary() ConnTrackSummary {
+	return it.connections.summary()
+}
+
 // OriginalDst returns the original destination of redirected connections. It
 // returns an error if the connection doesn't exist or isn't redirected.
 func (it *IPTables) OriginalDst(epID TransportEndpointID, netProto tcpip.NetworkProtocolNumber, transProto tcpip.TransportProtocolNumber) (tcpip.Address, uint16, tcpip.Error) {
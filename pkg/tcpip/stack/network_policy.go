@@ -0,0 +1,182 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"gvisor.dev/gvisor/pkg/atomicbitops"
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+)
+
+// NetworkPolicyRule allows traffic with a subnet on a range of ports.
+//
+// +stateify savable
+type NetworkPolicyRule struct {
+	// Subnet is the subnet of the peer.
+	Subnet tcpip.Subnet
+
+	// Protocol is the transport protocol the rule applies to. Zero matches
+	// all protocols.
+	Protocol tcpip.TransportProtocolNumber
+
+	// PortStart and PortEnd are the inclusive range of ports the rule applies
+	// to. For ingress rules these are local ports, and for egress rules they
+	// are the ports of the peer. Zero for both matches all ports.
+	PortStart uint16
+	PortEnd   uint16
+}
+
+// matches returns true if the rule allows traffic with addr on port.
+func (r *NetworkPolicyRule) matches(proto tcpip.TransportProtocolNumber, addr tcpip.Address, port uint16) bool {
+	if r.Protocol != 0 && r.Protocol != proto {
+		return false
+	}
+	if (r.PortStart != 0 || r.PortEnd != 0) && (port < r.PortStart || port > r.PortEnd) {
+		return false
+	}
+	return r.Subnet.Contains(addr)
+}
+
+// NetworkPolicy is an allowlist of the TCP and UDP traffic of the endpoints
+// owned by a container. It's enforced independently of iptables, so it can't
+// be altered from within the container.
+//
+// +stateify savable
+type NetworkPolicy struct {
+	// Ingress is the list of rules that allow incoming connections and
+	// datagrams. If nil, all ingress traffic is allowed. If empty, all
+	// ingress traffic is denied.
+	Ingress []NetworkPolicyRule
+
+	// Egress is the list of rules that allow outgoing connections and
+	// datagrams. If nil, all egress traffic is allowed. If empty, all egress
+	// traffic is denied.
+	Egress []NetworkPolicyRule
+}
+
+func allows(rules []NetworkPolicyRule, proto tcpip.TransportProtocolNumber, addr tcpip.Address, port uint16) bool {
+	if rules == nil {
+		return true
+	}
+	// Rules for IPv4 subnets also apply to IPv4-mapped IPv6 addresses.
+	if header.IsV4MappedAddress(addr) {
+		addr = addr.To4()
+	}
+	for i := range rules {
+		if rules[i].matches(proto, addr, port) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsIngress returns true if the policy allows traffic from remoteAddr to
+// localPort.
+func (p *NetworkPolicy) AllowsIngress(proto tcpip.TransportProtocolNumber, remoteAddr tcpip.Address, localPort uint16) bool {
+	return allows(p.Ingress, proto, remoteAddr, localPort)
+}
+
+// AllowsEgress returns true if the policy allows traffic to remoteAddr on
+// remotePort.
+func (p *NetworkPolicy) AllowsEgress(proto tcpip.TransportProtocolNumber, remoteAddr tcpip.Address, remotePort uint16) bool {
+	return allows(p.Egress, proto, remoteAddr, remotePort)
+}
+
+// NetworkPolicyOwner is implemented by packet owners that belong to a
+// container, e.g. kernel.Task. Endpoints are subject to the network policy of
+// the container of their owner.
+type NetworkPolicyOwner interface {
+	// ContainerID returns the ID of the container of the owner.
+	ContainerID() string
+}
+
+// networkPolicies holds the network policies of a stack, keyed by container
+// ID.
+//
+// +stateify savable
+type networkPolicies struct {
+	// enabled is true if there is at least one policy. It allows skipping the
+	// lookup when no policies are set.
+	enabled atomicbitops.Bool
+
+	mu networkPolicyRWMutex `state:"nosave"`
+	// +checklocks:mu
+	policies map[string]*NetworkPolicy
+}
+
+// lookup returns the policy that applies to endpoints owned by owner, or nil
+// if there is none.
+func (n *networkPolicies) lookup(owner tcpip.PacketOwner) *NetworkPolicy {
+	if !n.enabled.Load() {
+		return nil
+	}
+	o, ok := owner.(NetworkPolicyOwner)
+	if !ok {
+		return nil
+	}
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.policies[o.ContainerID()]
+}
+
+// SetNetworkPolicy sets the policy enforced on endpoints owned by the
+// container with the given ID, including endpoints that already exist. A nil
+// policy removes the policy of the container.
+//
+// The policy must not be modified after it's set.
+func (s *Stack) SetNetworkPolicy(containerID string, policy *NetworkPolicy) {
+	n := &s.networkPolicies
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if policy == nil {
+		delete(n.policies, containerID)
+	} else {
+		if n.policies == nil {
+			n.policies = make(map[string]*NetworkPolicy)
+		}
+		n.policies[containerID] = policy
+	}
+	n.enabled.Store(len(n.policies) > 0)
+}
+
+// NetworkPolicy returns the policy of the container with the given ID, or nil
+// if there is none.
+func (s *Stack) NetworkPolicy(containerID string) *NetworkPolicy {
+	n := &s.networkPolicies
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.policies[containerID]
+}
+
+// HasNetworkPolicies returns true if a network policy is set for any
+// container. It allows callers to skip looking up the owner of an endpoint on
+// hot paths.
+func (s *Stack) HasNetworkPolicies() bool {
+	return s.networkPolicies.enabled.Load()
+}
+
+// AllowNetworkPolicyIngress returns true if the network policy that applies to
+// endpoints owned by owner allows traffic from remoteAddr to localPort.
+func (s *Stack) AllowNetworkPolicyIngress(owner tcpip.PacketOwner, proto tcpip.TransportProtocolNumber, remoteAddr tcpip.Address, localPort uint16) bool {
+	p := s.networkPolicies.lookup(owner)
+	return p == nil || p.AllowsIngress(proto, remoteAddr, localPort)
+}
+
+// AllowNetworkPolicyEgress returns true if the network policy that applies to
+// endpoints owned by owner allows traffic to remoteAddr on remotePort.
+func (s *Stack) AllowNetworkPolicyEgress(owner tcpip.PacketOwner, proto tcpip.TransportProtocolNumber, remoteAddr tcpip.Address, remotePort uint16) bool {
+	p := s.networkPolicies.lookup(owner)
+	return p == nil || p.AllowsEgress(proto, remoteAddr, remotePort)
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"testing"
+
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+	"gvisor.dev/gvisor/pkg/tcpip/testutil"
+)
+
+type testPolicyOwner struct {
+	cid string
+}
+
+func (*testPolicyOwner) KUID() uint32 { return 0 }
+
+func (*testPolicyOwner) KGID() uint32 { return 0 }
+
+func (o *testPolicyOwner) ContainerID() string { return o.cid }
+
+func TestNetworkPolicy(t *testing.T) {
+	s := New(Options{})
+	defer s.Destroy()
+
+	s.SetNetworkPolicy("restricted", &NetworkPolicy{
+		Ingress: []NetworkPolicyRule{
+			{
+				Subnet:    testutil.MustParseSubnet4("10.0.0.0/8"),
+				Protocol:  header.TCPProtocolNumber,
+				PortStart: 80,
+				PortEnd:   80,
+			},
+		},
+		Egress: []NetworkPolicyRule{
+			{
+				Subnet:    testutil.MustParseSubnet4("192.168.1.0/24"),
+				PortStart: 1000,
+				PortEnd:   2000,
+			},
+		},
+	})
+	s.SetNetworkPolicy("isolated", &NetworkPolicy{
+		Ingress: []NetworkPolicyRule{},
+		Egress:  []NetworkPolicyRule{},
+	})
+
+	restricted := &testPolicyOwner{cid: "restricted"}
+	isolated := &testPolicyOwner{cid: "isolated"}
+	unrestricted := &testPolicyOwner{cid: "other"}
+
+	for _, tc := range []struct {
+		name    string
+		owner   tcpip.PacketOwner
+		ingress bool
+		proto   tcpip.TransportProtocolNumber
+		addr    tcpip.Address
+		port    uint16
+		want    bool
+	}{
+		{
+			name:    "ingress allowed",
+			owner:   restricted,
+			ingress: true,
+			proto:   header.TCPProtocolNumber,
+			addr:    testutil.MustParse4("10.1.2.3"),
+			port:    80,
+			want:    true,
+		},
+		{
+			name:    "ingress wrong subnet",
+			owner:   restricted,
+			ingress: true,
+			proto:   header.TCPProtocolNumber,
+			addr:    testutil.MustParse4("11.1.2.3"),
+			port:    80,
+		},
+		{
+			name:    "ingress wrong port",
+			owner:   restricted,
+			ingress: true,
+			proto:   header.TCPProtocolNumber,
+			addr:    testutil.MustParse4("10.1.2.3"),
+			port:    81,
+		},
+		{
+			name:    "ingress wrong protocol",
+			owner:   restricted,
+			ingress: true,
+			proto:   header.UDPProtocolNumber,
+			addr:    testutil.MustParse4("10.1.2.3"),
+			port:    80,
+		},
+		{
+			name:    "ingress v4-mapped",
+			owner:   restricted,
+			ingress: true,
+			proto:   header.TCPProtocolNumber,
+			addr:    testutil.MustParse6("::ffff:10.1.2.3"),
+			port:    80,
+			want:    true,
+		},
+		{
+			name:    "ingress ipv6",
+			owner:   restricted,
+			ingress: true,
+			proto:   header.TCPProtocolNumber,
+			addr:    testutil.MustParse6("a::1"),
+			port:    80,
+		},
+		{
+			name:  "egress allowed",
+			owner: restricted,
+			proto: header.UDPProtocolNumber,
+			addr:  testutil.MustParse4("192.168.1.1"),
+			port:  1500,
+			want:  true,
+		},
+		{
+			name:  "egress wrong port",
+			owner: restricted,
+			proto: header.TCPProtocolNumber,
+			addr:  testutil.MustParse4("192.168.1.1"),
+			port:  2001,
+		},
+		{
+			name:  "egress isolated",
+			owner: isolated,
+			proto: header.TCPProtocolNumber,
+			addr:  testutil.MustParse4("192.168.1.1"),
+			port:  1500,
+		},
+		{
+			name:    "ingress isolated",
+			owner:   isolated,
+			ingress: true,
+			proto:   header.TCPProtocolNumber,
+			addr:    testutil.MustParse4("10.1.2.3"),
+			port:    80,
+		},
+		{
+			name:  "no policy",
+			owner: unrestricted,
+			proto: header.TCPProtocolNumber,
+			addr:  testutil.MustParse4("1.2.3.4"),
+			port:  1,
+			want:  true,
+		},
+		{
+			name:  "no owner",
+			proto: header.TCPProtocolNumber,
+			addr:  testutil.MustParse4("1.2.3.4"),
+			port:  1,
+			want:  true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got bool
+			if tc.ingress {
+				got = s.AllowNetworkPolicyIngress(tc.owner, tc.proto, tc.addr, tc.port)
+			} else {
+				got = s.AllowNetworkPolicyEgress(tc.owner, tc.proto, tc.addr, tc.port)
+			}
+			if got != tc.want {
+				t.Errorf("got allowed = %t, want = %t", got, tc.want)
+			}
+		})
+	}
+
+	// Removing the policy allows all traffic again.
+	s.SetNetworkPolicy("isolated", nil)
+	if !s.AllowNetworkPolicyEgress(isolated, header.TCPProtocolNumber, testutil.MustParse4("1.2.3.4"), 1) {
+		t.Errorf("egress denied after removing policy")
+	}
+	if s.NetworkPolicy("isolated") != nil {
+		t.Errorf("NetworkPolicy(%q) != nil after removing policy", "isolated")
+	}
+}
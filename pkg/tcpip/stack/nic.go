@@ -646,6 +646,36 @@ func (n *nic) setAddressLifetimes(addr tcpip.Address, lifetimes AddressLifetimes
 	return &tcpip.ErrBadLocalAddress{}
 }
 
+func (n *nic) addressInfo(addr tcpip.Address) (AddressInfo, tcpip.Error) {
+	for _, ep := range n.networkEndpoints {
+		ep, ok := ep.(AddressableEndpoint)
+		if !ok {
+			continue
+		}
+
+		addressEndpoint := ep.GetAddress(addr)
+		if addressEndpoint == nil {
+			continue
+		}
+		kind := addressEndpoint.GetKind()
+		if !kind.IsPermanent() {
+			continue
+		}
+		lifetimes := addressEndpoint.Lifetimes()
+		lifetimes.Deprecated = addressEndpoint.Deprecated()
+		return AddressInfo{
+			ConfigType: addressEndpoint.ConfigType(),
+			Lifetimes:  lifetimes,
+			Temporary:  addressEndpoint.Temporary(),
+			// Permanent addresses that are not assigned are still undergoing
+			// Duplicate Address Detection.
+			Tentative: kind == PermanentTentative,
+		}, nil
+	}
+
+	return AddressInfo{}, &tcpip.ErrBadLocalAddress{}
+}
+
 func (n *nic) getLinkAddress(addr, localAddr tcpip.Address, protocol tcpip.NetworkProtocolNumber, onResolve func(LinkResolutionResult)) tcpip.Error {
 	linkRes, ok := n.linkAddrResolvers[protocol]
 	if !ok {
@@ -678,6 +708,29 @@ func (n *nic) addStaticNeighbor(addr tcpip.Address, protocol tcpip.NetworkProtoc
 	return &tcpip.ErrNotSupported{}
 }
 
+// announceAddresses announces the primary addresses of the NIC through the
+// link address resolvers that support it.
+func (n *nic) announceAddresses() tcpip.Error {
+	if !n.Enabled() {
+		// The NIC is not attached to its link.
+		return nil
+	}
+	for _, addr := range n.primaryAddresses() {
+		linkRes, ok := n.linkAddrResolvers[addr.Protocol]
+		if !ok {
+			continue
+		}
+		announcer, ok := linkRes.resolver.(LinkAddressAnnouncer)
+		if !ok {
+			continue
+		}
+		if err := announcer.AnnounceLinkAddress(addr.AddressWithPrefix.Address); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (n *nic) removeNeighbor(protocol tcpip.NetworkProtocolNumber, addr tcpip.Address) tcpip.Error {
 	if linkRes, ok := n.linkAddrResolvers[protocol]; ok {
 		if !linkRes.neigh.removeEntry(addr) {
@@ -760,6 +813,7 @@ func (n *nic) DeliverNetworkPacket(protocol tcpip.NetworkProtocolNumber, pkt *Pa
 	if !enabled {
 		n.stats.disabledRx.packets.Increment()
 		n.stats.disabledRx.bytes.IncrementBy(uint64(pkt.Data().Size()))
+		n.stack.RecordDrop(n.id, tcpip.DropReasonNICDisabled, pkt)
 		return
 	}
 
@@ -769,6 +823,7 @@ func (n *nic) DeliverNetworkPacket(protocol tcpip.NetworkProtocolNumber, pkt *Pa
 	networkEndpoint := n.getNetworkEndpoint(protocol)
 	if networkEndpoint == nil {
 		n.stats.unknownL3ProtocolRcvdPacketCounts.Increment(uint64(protocol))
+		n.stack.RecordDrop(n.id, tcpip.DropReasonUnknownNetworkProtocol, pkt)
 		return
 	}
 
@@ -855,6 +910,7 @@ func (n *nic) deliverTransportPacket(protocol tcpip.TransportProtocolNumber, pkt
 	state, ok := n.stack.transportProtocols[protocol]
 	if !ok {
 		n.stats.unknownL4ProtocolRcvdPacketCounts.Increment(uint64(protocol))
+		n.stack.RecordDrop(n.id, tcpip.DropReasonUnknownTransportProtocol, pkt)
 		return TransportPacketProtocolUnreachable, false
 	}
 
@@ -862,12 +918,14 @@ func (n *nic) deliverTransportPacket(protocol tcpip.TransportProtocolNumber, pkt
 
 	if len(pkt.TransportHeader().Slice()) == 0 {
 		n.stats.malformedL4RcvdPackets.Increment()
+		n.stack.RecordDrop(n.id, tcpip.DropReasonMalformed, pkt)
 		return TransportPacketHandled, false
 	}
 
 	srcPort, dstPort, err := transProto.ParsePorts(pkt.TransportHeader().Slice())
 	if err != nil {
 		n.stats.malformedL4RcvdPackets.Increment()
+		n.stack.RecordDrop(n.id, tcpip.DropReasonMalformed, pkt)
 		return TransportPacketHandled, false
 	}
 
@@ -900,8 +958,10 @@ func (n *nic) deliverTransportPacket(protocol tcpip.TransportProtocolNumber, pkt
 	switch res := transProto.HandleUnknownDestinationPacket(id, pkt); res {
 	case UnknownDestinationPacketMalformed:
 		n.stats.malformedL4RcvdPackets.Increment()
+		n.stack.RecordDrop(n.id, tcpip.DropReasonMalformed, pkt)
 		return TransportPacketHandled, false
 	case UnknownDestinationPacketUnhandled:
+		n.stack.RecordDrop(n.id, tcpip.DropReasonNoListener, pkt)
 		return TransportPacketDestinationPortUnreachable, false
 	case UnknownDestinationPacketHandled:
 		return TransportPacketHandled, false
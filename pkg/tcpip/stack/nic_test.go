@@ -168,6 +168,7 @@ func TestDisabledRxStatsWhenNICDisabled(t *testing.T) {
 	// When the NIC is disabled, the only field that matters is the stats field.
 	// This test is limited to stats counter checks.
 	nic := nic{
+		stack: &Stack{stats: tcpip.Stats{}.FillIn()},
 		stats: makeNICStats(tcpip.NICStats{}.FillIn()),
 	}
 
@@ -204,10 +205,14 @@ func TestDisabledRxStatsWhenNICDisabled(t *testing.T) {
 	if got := nic.stats.local.Rx.Bytes.Value(); got != 0 {
 		t.Errorf("got Rx.Bytes = %d, want = 0", got)
 	}
+	if got := nic.stack.stats.Drops.NICDisabled.Value(); got != 1 {
+		t.Errorf("got Drops.NICDisabled = %d, want = 1", got)
+	}
 }
 
 func TestPacketWithUnknownNetworkProtocolNumber(t *testing.T) {
 	nic := nic{
+		stack:   &Stack{stats: tcpip.Stats{}.FillIn()},
 		stats:   makeNICStats(tcpip.NICStats{}.FillIn()),
 		enabled: atomicbitops.FromBool(true),
 	}
@@ -223,11 +228,14 @@ func TestPacketWithUnknownNetworkProtocolNumber(t *testing.T) {
 	if count != 1 {
 		t.Errorf("got UnknownL3ProtocolRcvdPacketCounts[header.IPv4ProtocolNumber] = %d, want = 1", count)
 	}
+	if got := nic.stack.stats.Drops.UnknownNetworkProtocol.Value(); got != 1 {
+		t.Errorf("got Drops.UnknownNetworkProtocol = %d, want = 1", got)
+	}
 }
 
 func TestPacketWithUnknownTransportProtocolNumber(t *testing.T) {
 	nic := nic{
-		stack:   &Stack{},
+		stack:   &Stack{stats: tcpip.Stats{}.FillIn()},
 		stats:   makeNICStats(tcpip.NICStats{}.FillIn()),
 		enabled: atomicbitops.FromBool(true),
 	}
@@ -243,6 +251,9 @@ func TestPacketWithUnknownTransportProtocolNumber(t *testing.T) {
 	if count != 1 {
 		t.Errorf("got UnknownL4ProtocolRcvdPacketCounts[header.UDPProtocolNumber] = %d, want = 1", count)
 	}
+	if got := nic.stack.stats.Drops.UnknownTransportProtocol.Value(); got != 1 {
+		t.Errorf("got Drops.UnknownTransportProtocol = %d, want = 1", got)
+	}
 }
 
 func TestMultiCounterStatsInitialization(t *testing.T) {
@@ -60,6 +60,9 @@ type PacketBufferOptions struct {
 
 	// Mark is the mark value of this packet.
 	Mark uint32
+
+	// Priority is the queueing priority of this packet.
+	Priority uint32
 }
 
 // A PacketBuffer contains all the data of a network packet.
@@ -172,6 +175,12 @@ type PacketBuffer struct {
 	// Mark is the mark value of this packet.
 	Mark uint32
 
+	// Priority is the queueing priority of this packet, used by queueing
+	// disciplines to pick the band an outbound packet is queued in. It is
+	// the SO_PRIORITY value of the sending socket, like Linux's
+	// skb->priority.
+	Priority uint32
+
 	tuple *tuple
 
 	// onRelease is a function to be run when the packet buffer is no longer
@@ -194,6 +203,7 @@ func NewPacketBuffer(opts PacketBufferOptions) *PacketBuffer {
 	pk.NetworkPacketInfo.IsForwardedPacket = opts.IsForwardedPacket
 	pk.onRelease = opts.OnRelease
 	pk.Mark = opts.Mark
+	pk.Priority = opts.Priority
 	pk.InitRefs()
 	return pk
 }
@@ -393,6 +403,7 @@ func (pk *PacketBuffer) Clone() *PacketBuffer {
 	newPk.Hash = pk.Hash
 	newPk.Owner = pk.Owner
 	newPk.Mark = pk.Mark
+	newPk.Priority = pk.Priority
 	newPk.GSOOptions = pk.GSOOptions
 	newPk.EgressRoute = pk.EgressRoute
 	newPk.NetworkProtocolNumber = pk.NetworkProtocolNumber
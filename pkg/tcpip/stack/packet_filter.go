@@ -0,0 +1,81 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"fmt"
+	"sort"
+
+	"gvisor.dev/gvisor/pkg/tcpip"
+)
+
+// PacketFilter is an external rule engine that is consulted for every packet
+// the stack forwards, after iptables and nftables have accepted it. It lets
+// custom policy engines be compiled into the stack without extending the
+// iptables or nftables implementations.
+type PacketFilter interface {
+	// CheckForward is called with each packet that arrived on the NIC with ID
+	// inNICID and is about to be forwarded, and returns false if the packet
+	// must be dropped.
+	//
+	// route is the route the packet will be sent out on, or nil if the
+	// packet is forwarded to an address that belongs to another NIC of the
+	// stack.
+	//
+	// If route is not nil, pkt is the stack's private copy of the packet and
+	// the filter may rewrite its network and transport headers in place, e.g.
+	// to NAT the packet. In that case it is responsible for keeping the
+	// transport checksum valid; the IPv4 header checksum is recalculated when
+	// the packet is sent. Rewriting the destination address does not change
+	// the route the packet is sent out on. If route is nil, pkt is the
+	// received packet, whose buffers may be shared with other consumers, and
+	// the filter must not modify it.
+	//
+	// CheckForward may be called concurrently.
+	CheckForward(pkt *PacketBuffer, inNICID tcpip.NICID, route *Route, netProto tcpip.NetworkProtocolNumber) bool
+}
+
+// PacketFilterFactory creates a PacketFilter for the given stack.
+type PacketFilterFactory func(s *Stack) (PacketFilter, error)
+
+// packetFilters contains all registered packet filters.
+var packetFilters = map[string]PacketFilterFactory{}
+
+// RegisterPacketFilter registers a packet filter under name. It is meant to be
+// called from the init function of the package implementing the filter.
+func RegisterPacketFilter(name string, factory PacketFilterFactory) {
+	if _, ok := packetFilters[name]; ok {
+		panic(fmt.Sprintf("packet filter %q registered twice", name))
+	}
+	packetFilters[name] = factory
+}
+
+// PacketFilters lists the registered packet filters.
+func PacketFilters() (available []string) {
+	for name := range packetFilters {
+		available = append(available, name)
+	}
+	sort.Strings(available)
+	return
+}
+
+// LookupPacketFilter looks up the packet filter factory registered under name.
+func LookupPacketFilter(name string) (PacketFilterFactory, error) {
+	f, ok := packetFilters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown packet filter: %q", name)
+	}
+	return f, nil
+}
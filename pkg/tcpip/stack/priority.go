@@ -0,0 +1,60 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+// Packet priorities with a predefined meaning, as per Linux's TC_PRIO_*
+// constants in include/uapi/linux/pkt_sched.h.
+const (
+	PriorityBestEffort      = 0
+	PriorityFiller          = 1
+	PriorityBulk            = 2
+	PriorityInteractiveBulk = 4
+	PriorityInteractive     = 6
+	PriorityControl         = 7
+
+	// PriorityMax is the largest priority in the priority map of a queueing
+	// discipline. Larger priorities select a band by their lowest bits, i.e.
+	// priority & PriorityMax.
+	PriorityMax = 15
+)
+
+// tosToPriority maps the TOS bits of an IPv4 TOS (or IPv6 traffic class) to a
+// priority, as per Linux's ip_tos2prio in net/ipv4/route.c. The index is
+// (TOS & 0x1e) >> 1; odd entries are ECN_OR_COST variants of even ones.
+var tosToPriority = [16]uint32{
+	PriorityBestEffort,
+	PriorityBestEffort,
+	PriorityBestEffort,
+	PriorityBestEffort,
+	PriorityBulk,
+	PriorityBulk,
+	PriorityBulk,
+	PriorityBulk,
+	PriorityInteractive,
+	PriorityInteractive,
+	PriorityInteractive,
+	PriorityInteractive,
+	PriorityInteractiveBulk,
+	PriorityInteractiveBulk,
+	PriorityInteractiveBulk,
+	PriorityInteractiveBulk,
+}
+
+// TOSToPriority returns the priority of packets sent with the given IPv4 TOS,
+// like Linux's rt_tos2priority. Linux updates the priority of a socket when its
+// IP_TOS changes, and of forwarded IPv4 packets.
+func TOSToPriority(tos uint8) uint32 {
+	return tosToPriority[(tos&0x1e)>>1]
+}
@@ -526,6 +526,22 @@ type AddressProperties struct {
 	Disp      AddressDispatcher
 }
 
+// AddressInfo holds the state of an address assigned to a NIC.
+type AddressInfo struct {
+	// ConfigType is the method that was used to add the address.
+	ConfigType AddressConfigType
+
+	// Lifetimes are the address' lifetimes.
+	Lifetimes AddressLifetimes
+
+	// Temporary is as defined in AddressProperties.
+	Temporary bool
+
+	// Tentative is true if the address is undergoing Duplicate Address
+	// Detection and is not yet assigned.
+	Tentative bool
+}
+
 // AddressAssignmentState is an address' assignment state.
 type AddressAssignmentState int
 
@@ -782,6 +798,12 @@ type AddressableEndpoint interface {
 
 	// PermanentAddresses returns all the permanent addresses.
 	PermanentAddresses() []tcpip.AddressWithPrefix
+
+	// GetAddress returns the AddressEndpoint for the passed address, whatever
+	// its kind, or nil if the address is not associated with the endpoint.
+	//
+	// GetAddress does not increment the address's reference count.
+	GetAddress(addr tcpip.Address) AddressEndpoint
 }
 
 // NDPEndpoint is a network endpoint that supports NDP.
@@ -1422,6 +1444,15 @@ type LinkAddressResolver interface {
 	LinkAddressProtocol() tcpip.NetworkProtocolNumber
 }
 
+// LinkAddressAnnouncer is implemented by link address resolvers that can
+// advertise the link address of a local address without being asked, such as
+// with a gratuitous ARP or an unsolicited Neighbor Advertisement.
+type LinkAddressAnnouncer interface {
+	// AnnounceLinkAddress advertises to all neighbors that localAddr is
+	// reachable at the link address of the NIC.
+	AnnounceLinkAddress(localAddr tcpip.Address) tcpip.Error
+}
+
 // RawFactory produces endpoints for writing various types of raw packets.
 type RawFactory interface {
 	// NewUnassociatedEndpoint produces endpoints for writing packets not
@@ -20,12 +20,32 @@ import (
 	"time"
 
 	cryptorand "gvisor.dev/gvisor/pkg/rand"
+	"gvisor.dev/gvisor/pkg/state"
+	"gvisor.dev/gvisor/pkg/tcpip"
 )
 
+func init() {
+	// Endpoints aren't remapped when loading state that doesn't include
+	// Stack.savedNICAddresses.
+	state.RegisterSchemaChange("pkg/tcpip/stack.Stack", state.SchemaChange{
+		Version:     2,
+		AddedFields: []string{"savedNICAddresses"},
+	})
+}
+
 // beforeSave is invoked by stateify.
 func (s *Stack) beforeSave() {
-	// removeConf will be set only in case of save/restore.
 	s.mu.Lock()
+	// Remember the addresses of the NICs so that endpoints bound to them can
+	// be remapped if the NICs get different addresses at restore.
+	s.savedNICAddresses = make(map[string][]tcpip.ProtocolAddress)
+	for _, nic := range s.nics {
+		if !nic.IsLoopback() {
+			s.savedNICAddresses[nic.Name()] = nic.allPermanentAddresses()
+		}
+	}
+
+	// removeConf will be set only in case of save/restore.
 	if !s.removeConf {
 		s.mu.Unlock()
 		return
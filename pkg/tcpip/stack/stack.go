@@ -126,6 +126,19 @@ type Stack struct {
 	// least one rule on a chain at a network hook.
 	nftablesConfigured atomicbitops.Bool
 
+	// packetFilter is the external packet filter consulted when forwarding
+	// packets, if any. It isn't saved, so it must be set again by
+	// SetPacketFilter after restore.
+	packetFilter PacketFilter `state:"nosave"`
+
+	// networkPolicies are the per-container network policies enforced on
+	// transport endpoints.
+	networkPolicies networkPolicies
+
+	// dropObserver, if not nil, is notified of every packet recorded by
+	// RecordDrop. It is set during Stack creation and is immutable.
+	dropObserver DropObserver `state:"nosave"`
+
 	// restoredEndpoints is a list of endpoints that need to be restored if the
 	// stack is being restored.
 	restoredEndpoints []RestoredEndpoint
@@ -192,6 +205,22 @@ type Stack struct {
 	// during save/restore.
 	allowLiveTCPMigration bool `state:"nosave"`
 
+	// keepaliveRestorePolicy determines how keepalives of migrated TCP
+	// connections resume after restore. It is set by the restorer before
+	// Restore is called.
+	keepaliveRestorePolicy KeepaliveRestorePolicy `state:"nosave"`
+
+	// savedNICAddresses holds the addresses of the non-loopback NICs when
+	// the stack was saved, keyed by NIC name. It's used to remap the
+	// addresses of restored endpoints when the NICs are recreated with
+	// different addresses.
+	savedNICAddresses map[string][]tcpip.ProtocolAddress
+
+	// restoreAddressMap maps local addresses of restored endpoints that
+	// are no longer assigned to the addresses that replace them. It is set
+	// by the restorer before Restore is called.
+	restoreAddressMap map[tcpip.Address]tcpip.Address `state:"nosave"`
+
 	// externalNetworkingDisabled indicates whether external networking is
 	// disabled. This means all non-loopback NICs are disabled.
 	externalNetworkingDisabled bool
@@ -241,6 +270,10 @@ type Options struct {
 	// receive NUD related events.
 	NUDDisp NUDDispatcher
 
+	// DropObserver is an optional observer notified of every packet the
+	// stack discards. See DropObserver for details.
+	DropObserver DropObserver
+
 	// RawFactory produces raw endpoints. Raw endpoints are enabled only if
 	// this is non-nil.
 	RawFactory RawFactory
@@ -426,6 +459,7 @@ func New(opts Options) *Stack {
 		seed:                         secureRNG.Uint32(),
 		nudConfigs:                   opts.NUDConfigs,
 		nudDisp:                      opts.NUDDisp,
+		dropObserver:                 opts.DropObserver,
 		insecureRNG:                  insecureRNG,
 		secureRNG:                    secureRNG,
 		sendBufferSize: tcpip.SendBufferSizeOption{
@@ -1198,6 +1232,13 @@ type NICInfo struct {
 
 	// Primary is the index of the main controlling interface in a bonded setup.
 	Primary tcpip.NICID
+
+	// Capabilities holds the capabilities of the NIC's link endpoint.
+	Capabilities LinkEndpointCapabilities
+
+	// SupportedGSO is the segmentation offloading supported by the NIC's link
+	// endpoint.
+	SupportedGSO SupportedGSO
 }
 
 // HasNIC returns true if the NICID is defined in the stack.
@@ -1250,6 +1291,10 @@ func (s *Stack) nicInfo(nic *nic, id tcpip.NICID) *NICInfo {
 		ARPHardwareType:     nic.NetworkLinkEndpoint.ARPHardwareType(),
 		Forwarding:          make(map[tcpip.NetworkProtocolNumber]bool),
 		MulticastForwarding: make(map[tcpip.NetworkProtocolNumber]bool),
+		Capabilities:        nic.NetworkLinkEndpoint.Capabilities(),
+	}
+	if gso, ok := nic.NetworkLinkEndpoint.(GSOEndpoint); ok {
+		info.SupportedGSO = gso.SupportedGSO()
 	}
 
 	for proto := range s.networkProtocols {
@@ -1348,6 +1393,19 @@ func (s *Stack) SetAddressLifetimes(id tcpip.NICID, addr tcpip.Address, lifetime
 	return &tcpip.ErrUnknownNICID{}
 }
 
+// GetAddressInfo returns the state of the address addr assigned to the NIC
+// identified by id.
+func (s *Stack) GetAddressInfo(id tcpip.NICID, addr tcpip.Address) (AddressInfo, tcpip.Error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if nic, ok := s.nics[id]; ok {
+		return nic.addressInfo(addr)
+	}
+
+	return AddressInfo{}, &tcpip.ErrUnknownNICID{}
+}
+
 // AllAddresses returns a map of NICIDs to their protocol addresses (primary
 // and non-primary).
 func (s *Stack) AllAddresses() map[tcpip.NICID][]tcpip.ProtocolAddress {
@@ -1873,6 +1931,23 @@ func (s *Stack) AddStaticNeighbor(nicID tcpip.NICID, protocol tcpip.NetworkProto
 	return nic.addStaticNeighbor(addr, protocol, linkAddr)
 }
 
+// AnnounceAddresses announces the primary addresses of the NIC to its link,
+// with gratuitous ARP for IPv4 and unsolicited Neighbor Advertisements for
+// IPv6, so that neighbors update their caches. This is useful when the link
+// address or the location of the NIC has changed, e.g. after a restore on
+// another host.
+func (s *Stack) AnnounceAddresses(nicID tcpip.NICID) tcpip.Error {
+	s.mu.RLock()
+	nic, ok := s.nics[nicID]
+	s.mu.RUnlock()
+
+	if !ok {
+		return &tcpip.ErrUnknownNICID{}
+	}
+
+	return nic.announceAddresses()
+}
+
 // RemoveNeighbor removes an IP to MAC address association previously created
 // either automatically or by AddStaticNeighbor. Returns ErrBadAddress if there
 // is no association with the provided address.
@@ -2113,6 +2188,7 @@ func (s *Stack) ReplaceConfig(st *Stack) {
 	// Update iptables and nftables.
 	s.tables = st.IPTables()
 	s.nftables = st.NFTables()
+	s.packetFilter = st.PacketFilter()
 	for id, nic := range nics {
 		nic.stack = s
 		s.nics[id] = nic
@@ -2351,6 +2427,18 @@ func (s *Stack) SetNFTablesConfigured(configured bool) {
 	s.nftablesConfigured.Store(configured)
 }
 
+// PacketFilter returns the stack's external packet filter, or nil if none is
+// set.
+func (s *Stack) PacketFilter() PacketFilter {
+	return s.packetFilter
+}
+
+// SetPacketFilter sets the stack's external packet filter. It must be called
+// before the stack starts forwarding packets.
+func (s *Stack) SetPacketFilter(f PacketFilter) {
+	s.packetFilter = f
+}
+
 // ICMPLimit returns the maximum number of ICMP messages that can be sent
 // in one second.
 func (s *Stack) ICMPLimit() rate.Limit {
@@ -2616,6 +2704,128 @@ func (s *Stack) SetAllowLiveTCPMigration(allow bool) {
 	s.allowLiveTCPMigration = allow
 }
 
+// KeepaliveRestorePolicy determines how keepalives of TCP connections that
+// were established when the stack was saved resume after it's restored.
+type KeepaliveRestorePolicy int
+
+const (
+	// KeepaliveRestoreRearm restarts the keepalive timer when the stack is
+	// restored, as if the connection had been idle since the restore.
+	// Keepalive probes that were unacknowledged when the stack was saved still
+	// count towards the keepalive probe limit.
+	KeepaliveRestoreRearm KeepaliveRestorePolicy = iota
+
+	// KeepaliveRestoreProbe sends a keepalive probe as soon as the stack is
+	// restored, so that connections whose peer went away while the stack
+	// wasn't running are detected without waiting for the keepalive idle
+	// time.
+	KeepaliveRestoreProbe
+
+	// KeepaliveRestoreReset is like KeepaliveRestoreRearm, but also forgets
+	// keepalive probes that were unacknowledged when the stack was saved.
+	KeepaliveRestoreReset
+)
+
+// KeepaliveRestorePolicy returns the policy applied to keepalives of TCP
+// connections when the stack is restored.
+func (s *Stack) KeepaliveRestorePolicy() KeepaliveRestorePolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keepaliveRestorePolicy
+}
+
+// SetKeepaliveRestorePolicy sets the policy applied to keepalives of TCP
+// connections when the stack is restored. It must be called before Restore.
+func (s *Stack) SetKeepaliveRestorePolicy(p KeepaliveRestorePolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keepaliveRestorePolicy = p
+}
+
+// SetRestoreAddressMap sets the addresses that replace the local addresses of
+// restored endpoints that are no longer assigned to a NIC. Endpoints bound to
+// an address that isn't assigned and isn't in m are left as is, and connected
+// endpoints are reset. It must be called before Restore.
+func (s *Stack) SetRestoreAddressMap(m map[tcpip.Address]tcpip.Address) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.restoreAddressMap = m
+}
+
+// RemapRestoredAddress returns the address that replaces addr, a local
+// address of a restored endpoint, and whether addr must be replaced. addr is
+// replaced only if it's no longer assigned to any NIC.
+func (s *Stack) RemapRestoredAddress(protocol tcpip.NetworkProtocolNumber, addr tcpip.Address) (tcpip.Address, bool) {
+	s.mu.RLock()
+	to, ok := s.restoreAddressMap[addr]
+	s.mu.RUnlock()
+	if !ok || !s.IsStaleRestoredAddress(protocol, addr) {
+		return tcpip.Address{}, false
+	}
+	return to, true
+}
+
+// IsStaleRestoredAddress returns true if addr, a local address of a restored
+// endpoint, is no longer assigned to any NIC.
+func (s *Stack) IsStaleRestoredAddress(protocol tcpip.NetworkProtocolNumber, addr tcpip.Address) bool {
+	if addr.BitLen() == 0 || addr.Unspecified() {
+		return false
+	}
+	return s.CheckLocalAddress(0, protocol, addr) == 0
+}
+
+// AutoRestoreAddressMap returns an address map for SetRestoreAddressMap that
+// replaces the addresses that the NICs had when the stack was saved with the
+// addresses they have now. NICs are matched by name, and an address is only
+// mapped if the NIC had a single address of its protocol (ignoring IPv6
+// link-local addresses) that isn't assigned anymore, and now has a single
+// one.
+func (s *Stack) AutoRestoreAddressMap() map[tcpip.Address]tcpip.Address {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m := make(map[tcpip.Address]tcpip.Address)
+	for _, nic := range s.nics {
+		if nic.IsLoopback() {
+			continue
+		}
+		saved, ok := s.savedNICAddresses[nic.Name()]
+		if !ok {
+			continue
+		}
+		old := singleAddressByProtocol(saved)
+		for proto, to := range singleAddressByProtocol(nic.allPermanentAddresses()) {
+			from, ok := old[proto]
+			if !ok || from == to || nic.CheckLocalAddress(proto, from) {
+				continue
+			}
+			m[from] = to
+		}
+	}
+	return m
+}
+
+// singleAddressByProtocol returns the address of each protocol that has a
+// single address in addrs, ignoring IPv6 link-local addresses.
+func singleAddressByProtocol(addrs []tcpip.ProtocolAddress) map[tcpip.NetworkProtocolNumber]tcpip.Address {
+	byProto := make(map[tcpip.NetworkProtocolNumber]tcpip.Address)
+	multiple := make(map[tcpip.NetworkProtocolNumber]bool)
+	for _, a := range addrs {
+		addr := a.AddressWithPrefix.Address
+		if header.IsV6LinkLocalUnicastAddress(addr) {
+			continue
+		}
+		if _, ok := byProto[a.Protocol]; ok {
+			multiple[a.Protocol] = true
+		}
+		byProto[a.Protocol] = addr
+	}
+	for proto := range multiple {
+		delete(byProto, proto)
+	}
+	return byProto
+}
+
 // DisableAllNonLoopbackNICs disables all non-loopback NICs in the stack.
 func (s *Stack) DisableAllNonLoopbackNICs() {
 	s.mu.Lock()
@@ -2456,6 +2456,61 @@ func TestAddProtocolAddress(t *testing.T) {
 	verifyAddresses(t, wantAddresses, gotAddresses)
 }
 
+func TestGetAddressInfo(t *testing.T) {
+	const nicID = 1
+	s := stack.New(stack.Options{
+		NetworkProtocols: []stack.NetworkProtocolFactory{fakeNetFactory},
+	})
+	ep := channel.New(10, defaultMTU, "")
+	if err := s.CreateNIC(nicID, ep); err != nil {
+		t.Fatalf("CreateNIC(%d, _): %s", nicID, err)
+	}
+
+	var addrGen addressGenerator
+	address := addrGen.next(4)
+	protocolAddr := tcpip.ProtocolAddress{
+		Protocol: fakeNetNumber,
+		AddressWithPrefix: tcpip.AddressWithPrefix{
+			Address:   address,
+			PrefixLen: fakeDefaultPrefixLen,
+		},
+	}
+	properties := stack.AddressProperties{
+		ConfigType: stack.AddressConfigSlaac,
+		Lifetimes: stack.AddressLifetimes{
+			PreferredUntil: tcpip.MonotonicTime{}.Add(time.Minute),
+			ValidUntil:     tcpip.MonotonicTime{}.Add(time.Hour),
+		},
+		Temporary: true,
+	}
+	if err := s.AddProtocolAddress(nicID, protocolAddr, properties); err != nil {
+		t.Fatalf("AddProtocolAddress(%d, %+v, %+v): %s", nicID, protocolAddr, properties, err)
+	}
+
+	got, err := s.GetAddressInfo(nicID, address)
+	if err != nil {
+		t.Fatalf("GetAddressInfo(%d, %s): %s", nicID, address, err)
+	}
+	want := stack.AddressInfo{
+		ConfigType: properties.ConfigType,
+		Lifetimes:  properties.Lifetimes,
+		Temporary:  properties.Temporary,
+	}
+	if got != want {
+		t.Errorf("got GetAddressInfo(%d, %s) = %+v, want = %+v", nicID, address, got, want)
+	}
+
+	otherAddress := addrGen.next(4)
+	_, err = s.GetAddressInfo(nicID, otherAddress)
+	if _, ok := err.(*tcpip.ErrBadLocalAddress); !ok {
+		t.Errorf("got GetAddressInfo(%d, %s) = %v, want = %s", nicID, otherAddress, err, &tcpip.ErrBadLocalAddress{})
+	}
+	_, err = s.GetAddressInfo(nicID+1, address)
+	if _, ok := err.(*tcpip.ErrUnknownNICID); !ok {
+		t.Errorf("got GetAddressInfo(%d, %s) = %v, want = %s", nicID+1, address, err, &tcpip.ErrUnknownNICID{})
+	}
+}
+
 func TestCreateNICWithOptions(t *testing.T) {
 	type callArgsAndExpect struct {
 		nicID tcpip.NICID
@@ -5828,3 +5883,21 @@ func TestFindRoute(t *testing.T) {
 		})
 	}
 }
+
+func TestTOSToPriority(t *testing.T) {
+	tests := []struct {
+		tos  uint8
+		want uint32
+	}{
+		{tos: 0, want: stack.PriorityBestEffort},
+		{tos: 0x08 /* IPTOS_THROUGHPUT */, want: stack.PriorityBulk},
+		{tos: 0x10 /* IPTOS_LOWDELAY */, want: stack.PriorityInteractive},
+		{tos: 0x18, want: stack.PriorityInteractiveBulk},
+		{tos: 0x10 | 0x03 /* ECN bits */, want: stack.PriorityInteractive},
+	}
+	for _, test := range tests {
+		if got := stack.TOSToPriority(test.tos); got != test.want {
+			t.Errorf("got stack.TOSToPriority(%#x) = %d, want = %d", test.tos, got, test.want)
+		}
+	}
+}
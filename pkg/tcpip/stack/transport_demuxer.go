@@ -16,6 +16,7 @@ package stack
 
 import (
 	"fmt"
+	"runtime"
 
 	"gvisor.dev/gvisor/pkg/tcpip"
 	"gvisor.dev/gvisor/pkg/tcpip/hash/jenkins"
@@ -29,14 +30,59 @@ type protocolIDs struct {
 	transport tcpip.TransportProtocolNumber
 }
 
+// maxTransportEndpointsShards is the maximum number of shards the endpoints of
+// a given protocol are spread over.
+const maxTransportEndpointsShards = 64
+
+// numTransportEndpointsShards returns the number of shards to use for the
+// endpoints of a given protocol: one per CPU, up to
+// maxTransportEndpointsShards.
+func numTransportEndpointsShards() int {
+	n := runtime.GOMAXPROCS(0)
+	if n > maxTransportEndpointsShards {
+		n = maxTransportEndpointsShards
+	}
+	return n
+}
+
+// transportEndpointsShard holds the endpoints of a given protocol whose IDs
+// hash to the shard.
+//
+// +stateify savable
+type transportEndpointsShard struct {
+	mu transportEndpointsShardRWMutex `state:"nosave"`
+	// +checklocks:mu
+	endpoints map[TransportEndpointID]*endpointsByNIC
+}
+
 // transportEndpoints manages all endpoints of a given protocol. It has its own
-// mutex so as to reduce interference between protocols.
+// locks so as to reduce interference between protocols.
+//
+// Endpoints are spread over shards by a hash of their ID, so that registering
+// an endpoint (e.g. when a listener handles a SYN at a high connection rate)
+// only excludes lookups of IDs that hash to the same shard instead of all
+// packet deliveries for the protocol.
+//
+// The local address is not part of the hash, so that a lookup only has to
+// consult the shard of the full ID and the shard of the ID without its remote
+// part. Both are locked for the duration of the lookup, so that it's atomic
+// with respect to concurrent registrations.
+//
+// Sharding only removes contention in the demuxer: the SYNs received by a
+// listening TCP endpoint are still processed under that endpoint's lock (see
+// tcp.handleListen), so a single listener handles one SYN at a time.
+// Applications that need higher accept rates can spread connections over
+// several listeners with SO_REUSEPORT.
 //
 // +stateify savable
 type transportEndpoints struct {
+	// seed is a random secret for the jenkins hash used to select a shard.
+	seed uint32
+
+	// shards is immutable.
+	shards []transportEndpointsShard
+
 	mu transportEndpointsRWMutex `state:"nosave"`
-	// +checklocks:mu
-	endpoints map[TransportEndpointID]*endpointsByNIC
 	// rawEndpoints contains endpoints for raw sockets, which receive all
 	// traffic of a given protocol regardless of port.
 	//
@@ -44,39 +90,110 @@ type transportEndpoints struct {
 	rawEndpoints []RawTransportEndpoint
 }
 
+// newTransportEndpoints returns an empty transportEndpoints whose shards are
+// selected with the given hash seed.
+//
+// +checklocksignore: we don't have to hold locks during initialization.
+func newTransportEndpoints(seed uint32) *transportEndpoints {
+	eps := &transportEndpoints{
+		seed:   seed,
+		shards: make([]transportEndpointsShard, numTransportEndpointsShards()),
+	}
+	for i := range eps.shards {
+		eps.shards[i].endpoints = make(map[TransportEndpointID]*endpointsByNIC)
+	}
+	return eps
+}
+
+// shardIndex returns the index of the shard that holds the endpoints
+// registered with id.
+func (eps *transportEndpoints) shardIndex(id TransportEndpointID) int {
+	if len(eps.shards) == 1 {
+		return 0
+	}
+	payload := [4]byte{
+		byte(id.LocalPort),
+		byte(id.LocalPort >> 8),
+		byte(id.RemotePort),
+		byte(id.RemotePort >> 8),
+	}
+	h := jenkins.Sum32(eps.seed)
+	h.Write(payload[:])
+	h.Write(id.RemoteAddress.AsSlice())
+	return int(reciprocalScale(h.Sum32(), uint32(len(eps.shards))))
+}
+
+// shard returns the shard that holds the endpoints registered with id.
+func (eps *transportEndpoints) shard(id TransportEndpointID) *transportEndpointsShard {
+	return &eps.shards[eps.shardIndex(id)]
+}
+
 // unregisterEndpoint unregisters the endpoint with the given id such that it
 // won't receive any more packets.
 func (eps *transportEndpoints) unregisterEndpoint(id TransportEndpointID, ep TransportEndpoint, flags ports.Flags, bindToDevice tcpip.NICID) {
-	eps.mu.Lock()
-	defer eps.mu.Unlock()
-	epsByNIC, ok := eps.endpoints[id]
+	shard := eps.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	epsByNIC, ok := shard.endpoints[id]
 	if !ok {
 		return
 	}
 	if !epsByNIC.unregisterEndpoint(bindToDevice, ep, flags) {
 		return
 	}
-	delete(eps.endpoints, id)
+	delete(shard.endpoints, id)
 }
 
 func (eps *transportEndpoints) transportEndpoints() []TransportEndpoint {
-	eps.mu.RLock()
-	defer eps.mu.RUnlock()
-	es := make([]TransportEndpoint, 0, len(eps.endpoints))
-	for _, e := range eps.endpoints {
-		es = append(es, e.transportEndpoints()...)
+	var es []TransportEndpoint
+	for i := range eps.shards {
+		shard := &eps.shards[i]
+		shard.mu.RLock()
+		for _, e := range shard.endpoints {
+			es = append(es, e.transportEndpoints()...)
+		}
+		shard.mu.RUnlock()
 	}
 	return es
 }
 
-// iterEndpointsLocked yields all endpointsByNIC in eps that match id, in
-// descending order of match quality. If a call to yield returns false,
-// iterEndpointsLocked stops iteration and returns immediately.
+// iterEndpoints yields all endpointsByNIC in eps that match id, in descending
+// order of match quality. If a call to yield returns false, iterEndpoints
+// stops iteration and returns immediately.
 //
-// +checklocksread:eps.mu
-func (eps *transportEndpoints) iterEndpointsLocked(id TransportEndpointID, yield func(*endpointsByNIC) bool) {
+// yield is called with the shards that may hold matches read locked, so it
+// must not register or unregister endpoints.
+//
+// +checklocksignore: the shards that are accessed are locked dynamically.
+func (eps *transportEndpoints) iterEndpoints(id TransportEndpointID, yield func(*endpointsByNIC) bool) {
+	// IDs with and without the local address hash to the same shard, so
+	// matches can only be in the shard of id and the shard of id minus the
+	// remote part.
+	wid := id
+	wid.RemoteAddress = tcpip.Address{}
+	wid.RemotePort = 0
+	fullIdx, wildIdx := eps.shardIndex(id), eps.shardIndex(wid)
+	full, wild := &eps.shards[fullIdx], &eps.shards[wildIdx]
+	if fullIdx == wildIdx {
+		full.mu.RLock()
+		defer full.mu.RUnlock()
+	} else {
+		// Lock both shards in index order. Writers only ever hold a single
+		// shard's lock, so this can't deadlock. The lock validator doesn't
+		// allow holding two locks of the same class, so bypass it for the
+		// second one.
+		first, second := full, wild
+		if wildIdx < fullIdx {
+			first, second = wild, full
+		}
+		first.mu.RLock()
+		defer first.mu.RUnlock()
+		second.mu.RLockBypass()
+		defer second.mu.RUnlockBypass()
+	}
+
 	// Try to find a match with the id as provided.
-	if ep, ok := eps.endpoints[id]; ok {
+	if ep, ok := full.endpoints[id]; ok {
 		if !yield(ep) {
 			return
 		}
@@ -84,52 +201,44 @@ func (eps *transportEndpoints) iterEndpointsLocked(id TransportEndpointID, yield
 
 	// Try to find a match with the id minus the local address.
 	nid := id
-
 	nid.LocalAddress = tcpip.Address{}
-	if ep, ok := eps.endpoints[nid]; ok {
+	if ep, ok := full.endpoints[nid]; ok {
 		if !yield(ep) {
 			return
 		}
 	}
 
 	// Try to find a match with the id minus the remote part.
-	nid.LocalAddress = id.LocalAddress
-	nid.RemoteAddress = tcpip.Address{}
-	nid.RemotePort = 0
-	if ep, ok := eps.endpoints[nid]; ok {
+	if ep, ok := wild.endpoints[wid]; ok {
 		if !yield(ep) {
 			return
 		}
 	}
 
 	// Try to find a match with only the local port.
-	nid.LocalAddress = tcpip.Address{}
-	if ep, ok := eps.endpoints[nid]; ok {
+	wid.LocalAddress = tcpip.Address{}
+	if ep, ok := wild.endpoints[wid]; ok {
 		if !yield(ep) {
 			return
 		}
 	}
 }
 
-// findAllEndpointsLocked returns all endpointsByNIC in eps that match id, in
+// findAllEndpoints returns all endpointsByNIC in eps that match id, in
 // descending order of match quality.
-//
-// +checklocksread:eps.mu
-func (eps *transportEndpoints) findAllEndpointsLocked(id TransportEndpointID) []*endpointsByNIC {
+func (eps *transportEndpoints) findAllEndpoints(id TransportEndpointID) []*endpointsByNIC {
 	var matchedEPs []*endpointsByNIC
-	eps.iterEndpointsLocked(id, func(ep *endpointsByNIC) bool {
+	eps.iterEndpoints(id, func(ep *endpointsByNIC) bool {
 		matchedEPs = append(matchedEPs, ep)
 		return true
 	})
 	return matchedEPs
 }
 
-// findEndpointLocked returns the endpoint that most closely matches the given id.
-//
-// +checklocksread:eps.mu
-func (eps *transportEndpoints) findEndpointLocked(id TransportEndpointID) *endpointsByNIC {
+// findEndpoint returns the endpoint that most closely matches the given id.
+func (eps *transportEndpoints) findEndpoint(id TransportEndpointID) *endpointsByNIC {
 	var matchedEP *endpointsByNIC
-	eps.iterEndpointsLocked(id, func(ep *endpointsByNIC) bool {
+	eps.iterEndpoints(id, func(ep *endpointsByNIC) bool {
 		matchedEP = ep
 		return false
 	})
@@ -298,9 +407,7 @@ func newTransportDemuxer(stack *Stack) *transportDemuxer {
 	for netProto := range stack.networkProtocols {
 		for proto := range stack.transportProtocols {
 			protoIDs := protocolIDs{netProto, proto}
-			d.protocol[protoIDs] = &transportEndpoints{
-				endpoints: make(map[TransportEndpointID]*endpointsByNIC),
-			}
+			d.protocol[protoIDs] = newTransportEndpoints(stack.seed)
 			qTransProto, isQueued := (stack.transportProtocols[proto].proto).(queuedTransportProtocol)
 			if isQueued {
 				d.queuedProtocols[protoIDs] = qTransProto
@@ -493,9 +600,10 @@ func (d *transportDemuxer) singleRegisterEndpoint(netProto tcpip.NetworkProtocol
 		return &tcpip.ErrUnknownProtocol{}
 	}
 
-	eps.mu.Lock()
-	defer eps.mu.Unlock()
-	epsByNIC, ok := eps.endpoints[id]
+	shard := eps.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	epsByNIC, ok := shard.endpoints[id]
 	if !ok {
 		epsByNIC = &endpointsByNIC{
 			endpoints: make(map[tcpip.NICID]*multiPortEndpoint),
@@ -507,7 +615,7 @@ func (d *transportDemuxer) singleRegisterEndpoint(netProto tcpip.NetworkProtocol
 	}
 	// Only add this newly created epsByNIC if registerEndpoint succeeded.
 	if !ok {
-		eps.endpoints[id] = epsByNIC
+		shard.endpoints[id] = epsByNIC
 	}
 	return nil
 }
@@ -523,10 +631,11 @@ func (d *transportDemuxer) singleCheckEndpoint(netProto tcpip.NetworkProtocolNum
 		return &tcpip.ErrUnknownProtocol{}
 	}
 
-	eps.mu.RLock()
-	defer eps.mu.RUnlock()
+	shard := eps.shard(id)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
 
-	epsByNIC, ok := eps.endpoints[id]
+	epsByNIC, ok := shard.endpoints[id]
 	if !ok {
 		return nil
 	}
@@ -561,9 +670,7 @@ func (d *transportDemuxer) deliverPacket(protocol tcpip.TransportProtocolNumber,
 	// If the packet is a UDP broadcast or multicast, then find all matching
 	// transport endpoints.
 	if protocol == header.UDPProtocolNumber && isInboundMulticastOrBroadcast(pkt, id.LocalAddress) {
-		eps.mu.RLock()
-		destEPs := eps.findAllEndpointsLocked(id)
-		eps.mu.RUnlock()
+		destEPs := eps.findAllEndpoints(id)
 		// Fail if we didn't find at least one matching transport endpoint.
 		if len(destEPs) == 0 {
 			d.stack.stats.UDP.UnknownPortErrors.Increment()
@@ -591,9 +698,7 @@ func (d *transportDemuxer) deliverPacket(protocol tcpip.TransportProtocolNumber,
 		return true
 	}
 
-	eps.mu.RLock()
-	ep := eps.findEndpointLocked(id)
-	eps.mu.RUnlock()
+	ep := eps.findEndpoint(id)
 	if ep == nil {
 		if protocol == header.UDPProtocolNumber {
 			d.stack.stats.UDP.UnknownPortErrors.Increment()
@@ -645,9 +750,7 @@ func (d *transportDemuxer) deliverError(n *nic, net tcpip.NetworkProtocolNumber,
 		return false
 	}
 
-	eps.mu.RLock()
-	ep := eps.findEndpointLocked(id)
-	eps.mu.RUnlock()
+	ep := eps.findEndpoint(id)
 	if ep == nil {
 		return false
 	}
@@ -663,16 +766,18 @@ func (d *transportDemuxer) findTransportEndpoint(netProto tcpip.NetworkProtocolN
 		return nil
 	}
 
-	eps.mu.RLock()
-	epsByNIC := eps.findEndpointLocked(id)
+	// Lock epsByNIC before the shards are unlocked, so that it can't be
+	// unregistered in between.
+	var epsByNIC *endpointsByNIC
+	eps.iterEndpoints(id, func(ep *endpointsByNIC) bool {
+		epsByNIC = ep
+		epsByNIC.mu.RLock()
+		return false
+	})
 	if epsByNIC == nil {
-		eps.mu.RUnlock()
 		return nil
 	}
 
-	epsByNIC.mu.RLock()
-	eps.mu.RUnlock()
-
 	mpep, ok := epsByNIC.endpoints[nicID]
 	if !ok {
 		if mpep, ok = epsByNIC.endpoints[0]; !ok {
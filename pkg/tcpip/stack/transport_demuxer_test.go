@@ -19,6 +19,7 @@ import (
 	"math"
 	"math/rand"
 	"strconv"
+	"sync"
 	"testing"
 
 	"gvisor.dev/gvisor/pkg/buffer"
@@ -264,6 +265,178 @@ func TestTransportDemuxerRegisterMultiple(t *testing.T) {
 	}
 }
 
+// TestTransportDemuxerConcurrentRegister registers connected endpoints for
+// many 4-tuples concurrently, as a listener does when it handles SYNs, and
+// checks that they are all found, most specific match first.
+func TestTransportDemuxerConcurrentRegister(t *testing.T) {
+	const (
+		numWorkers   = 8
+		perWorker    = 64
+		listenerPort = 80
+	)
+	s := stack.New(stack.Options{
+		NetworkProtocols:   []stack.NetworkProtocolFactory{ipv4.NewProtocol},
+		TransportProtocols: []stack.TransportProtocolFactory{udp.NewProtocol},
+	})
+
+	newEP := func() stack.TransportEndpoint {
+		var wq waiter.Queue
+		ep, err := s.NewEndpoint(udp.ProtocolNumber, ipv4.ProtocolNumber, &wq)
+		if err != nil {
+			t.Fatalf("NewEndpoint failed: %s", err)
+		}
+		return ep.(stack.TransportEndpoint)
+	}
+	netProtos := []tcpip.NetworkProtocolNumber{ipv4.ProtocolNumber}
+	listener := newEP()
+	if err := s.RegisterTransportEndpoint(netProtos, udp.ProtocolNumber, stack.TransportEndpointID{LocalPort: listenerPort}, listener, ports.Flags{}, 0); err != nil {
+		t.Fatalf("RegisterTransportEndpoint(listener) failed: %s", err)
+	}
+
+	connID := func(worker, i int) stack.TransportEndpointID {
+		return stack.TransportEndpointID{
+			LocalPort:     listenerPort,
+			LocalAddress:  testDstAddrV4,
+			RemotePort:    uint16(1024 + worker*perWorker + i),
+			RemoteAddress: testSrcAddrV4,
+		}
+	}
+	eps := make([][]stack.TransportEndpoint, numWorkers)
+	for w := range eps {
+		for i := 0; i < perWorker; i++ {
+			eps[w] = append(eps[w], newEP())
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan tcpip.Error, numWorkers)
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i, ep := range eps[w] {
+				if err := s.RegisterTransportEndpoint(netProtos, udp.ProtocolNumber, connID(w, i), ep, ports.Flags{}, 0); err != nil {
+					errs <- err
+					return
+				}
+				// Lookups of unrelated IDs must keep working while
+				// registrations are in progress.
+				if got := s.FindTransportEndpoint(ipv4.ProtocolNumber, udp.ProtocolNumber, stack.TransportEndpointID{LocalPort: listenerPort, RemotePort: 1}, 1); got != listener {
+					t.Errorf("FindTransportEndpoint(listener) = %v, want %v", got, listener)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("RegisterTransportEndpoint failed: %s", err)
+	}
+
+	for w := range eps {
+		for i, want := range eps[w] {
+			id := connID(w, i)
+			if got := s.FindTransportEndpoint(ipv4.ProtocolNumber, udp.ProtocolNumber, id, 1); got != want {
+				t.Errorf("FindTransportEndpoint(%+v) = %v, want %v", id, got, want)
+			}
+		}
+	}
+	if got, want := len(s.RegisteredEndpoints()), numWorkers*perWorker+1; got != want {
+		t.Errorf("got len(RegisteredEndpoints()) = %d, want %d", got, want)
+	}
+
+	for w := range eps {
+		for i, ep := range eps[w] {
+			s.UnregisterTransportEndpoint(netProtos, udp.ProtocolNumber, connID(w, i), ep, ports.Flags{}, 0)
+			ep.Abort()
+		}
+	}
+	if got := s.FindTransportEndpoint(ipv4.ProtocolNumber, udp.ProtocolNumber, connID(0, 0), 1); got != listener {
+		t.Errorf("FindTransportEndpoint after unregister = %v, want %v", got, listener)
+	}
+	listener.Abort()
+}
+
+// BenchmarkTransportDemuxerFindEndpoint measures lookups of connected
+// endpoints among many others, as done for every received packet, while
+// another goroutine registers and unregisters endpoints.
+func BenchmarkTransportDemuxerFindEndpoint(b *testing.B) {
+	const (
+		numConns     = 1024
+		listenerPort = 80
+	)
+	s := stack.New(stack.Options{
+		NetworkProtocols:   []stack.NetworkProtocolFactory{ipv4.NewProtocol},
+		TransportProtocols: []stack.TransportProtocolFactory{udp.NewProtocol},
+	})
+	netProtos := []tcpip.NetworkProtocolNumber{ipv4.ProtocolNumber}
+	newEP := func() stack.TransportEndpoint {
+		var wq waiter.Queue
+		ep, err := s.NewEndpoint(udp.ProtocolNumber, ipv4.ProtocolNumber, &wq)
+		if err != nil {
+			b.Fatalf("NewEndpoint failed: %s", err)
+		}
+		return ep.(stack.TransportEndpoint)
+	}
+	connID := func(i int) stack.TransportEndpointID {
+		return stack.TransportEndpointID{
+			LocalPort:     listenerPort,
+			LocalAddress:  testDstAddrV4,
+			RemotePort:    uint16(1024 + i),
+			RemoteAddress: testSrcAddrV4,
+		}
+	}
+	var eps []stack.TransportEndpoint
+	for i := 0; i < numConns; i++ {
+		ep := newEP()
+		if err := s.RegisterTransportEndpoint(netProtos, udp.ProtocolNumber, connID(i), ep, ports.Flags{}, 0); err != nil {
+			b.Fatalf("RegisterTransportEndpoint failed: %s", err)
+		}
+		eps = append(eps, ep)
+	}
+
+	// Churn registrations of other IDs concurrently with the lookups.
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ep := newEP()
+		defer ep.Abort()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			id := connID(numConns + i%numConns)
+			if err := s.RegisterTransportEndpoint(netProtos, udp.ProtocolNumber, id, ep, ports.Flags{}, 0); err != nil {
+				b.Errorf("RegisterTransportEndpoint failed: %s", err)
+				return
+			}
+			s.UnregisterTransportEndpoint(netProtos, udp.ProtocolNumber, id, ep, ports.Flags{}, 0)
+		}
+	}()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if s.FindTransportEndpoint(ipv4.ProtocolNumber, udp.ProtocolNumber, connID(i%numConns), 1) == nil {
+				b.Errorf("FindTransportEndpoint(%+v) found no endpoint", connID(i%numConns))
+				return
+			}
+		}
+	})
+	b.StopTimer()
+
+	close(done)
+	wg.Wait()
+	for i, ep := range eps {
+		s.UnregisterTransportEndpoint(netProtos, udp.ProtocolNumber, connID(i), ep, ports.Flags{}, 0)
+		ep.Abort()
+	}
+}
+
 // TestBindToDeviceDistribution injects varied packets on input devices and checks that
 // the distribution of packets received matches expectations.
 func TestBindToDeviceDistribution(t *testing.T) {
@@ -963,6 +963,11 @@ const (
 	// the default TTL value for multicast messages. The default is 1.
 	MulticastTTLOption
 
+	// IPv6MulticastHopLimitOption is used by SetSockOptInt/GetSockOptInt to
+	// control the default hop limit value for IPv6 multicast messages. The
+	// default is 1.
+	IPv6MulticastHopLimitOption
+
 	// ReceiveQueueSizeOption is used in GetSockOptInt to specify that the
 	// number of unread bytes in the input buffer should be returned.
 	ReceiveQueueSizeOption
@@ -1067,6 +1072,32 @@ func (*DefaultTTLOption) isGettableNetworkProtocolOption() {}
 
 func (*DefaultTTLOption) isSettableNetworkProtocolOption() {}
 
+// IPv6ExtensionHeaderOption is used by stack.(*Stack).NetworkProtocolOption to
+// configure how IPv6 extension headers are processed.
+//
+// +stateify savable
+type IPv6ExtensionHeaderOption struct {
+	// SegmentRouting enables processing of Segment Routing Headers (RFC 8754)
+	// on packets addressed to the stack when forwarding is enabled on the
+	// receiving interface. When disabled, routing headers with a non-zero
+	// Segments Left field are rejected as per RFC 8200 section 4.4.
+	SegmentRouting bool
+
+	// MaxHopByHopOptions is the maximum number of options accepted in a
+	// Hop-by-Hop Options header. Packets exceeding the limit are dropped. A
+	// value of zero means there is no limit.
+	MaxHopByHopOptions int
+
+	// MaxHopByHopLength is the maximum length, in bytes, of a Hop-by-Hop
+	// Options header. Packets exceeding the limit are dropped. A value of zero
+	// means there is no limit.
+	MaxHopByHopLength int
+}
+
+func (*IPv6ExtensionHeaderOption) isGettableNetworkProtocolOption() {}
+
+func (*IPv6ExtensionHeaderOption) isSettableNetworkProtocolOption() {}
+
 // GettableTransportProtocolOption is a marker interface for transport protocol
 // options that may be queried.
 type GettableTransportProtocolOption interface {
@@ -1166,6 +1197,33 @@ func (*TCPModerateReceiveBufferOption) isGettableTransportProtocolOption() {}
 
 func (*TCPModerateReceiveBufferOption) isSettableTransportProtocolOption() {}
 
+// TCPMemoryLimitsOption is the amount of memory, in bytes, that all TCP
+// endpoints of a stack may hold in their send and receive buffers. It is the
+// equivalent of Linux's tcp_mem sysctl. A zero Max disables the limits.
+//
+// +stateify savable
+type TCPMemoryLimitsOption struct {
+	// Min is the usage below which TCP leaves memory pressure.
+	Min int
+
+	// Pressure is the usage above which TCP enters memory pressure and stops
+	// growing buffers with auto-tuning.
+	Pressure int
+
+	// Max is the usage above which TCP drops inbound data segments.
+	Max int
+}
+
+func (*TCPMemoryLimitsOption) isGettableTransportProtocolOption() {}
+
+func (*TCPMemoryLimitsOption) isSettableTransportProtocolOption() {}
+
+// TCPMemoryUsedOption is the amount of memory, in bytes, held in the send and
+// receive buffers of all TCP endpoints of a stack.
+type TCPMemoryUsedOption int
+
+func (*TCPMemoryUsedOption) isGettableTransportProtocolOption() {}
+
 // GettableSocketOption is a marker interface for socket options that may be
 // queried.
 type GettableSocketOption interface {
@@ -2291,6 +2349,13 @@ type TCPStats struct {
 	// dropped due to exceeding the maximum number of in-flight connection
 	// requests.
 	ForwardMaxInFlightDrop *StatCounter
+
+	// MemoryPressures is the number of times TCP entered memory pressure.
+	MemoryPressures *StatCounter
+
+	// MemoryLimitDrops is the number of segments dropped because TCP
+	// memory usage exceeded the stack wide limit.
+	MemoryLimitDrops *StatCounter
 }
 
 // UDPStats collects UDP-specific stats.
@@ -2523,6 +2588,185 @@ type Stats struct {
 
 	// UDP holds UDP-specific stats.
 	UDP UDPStats
+
+	// Drops counts packets discarded by the stack, broken down by the reason
+	// they were discarded.
+	Drops DropStats
+}
+
+// DropReason identifies why the stack discarded a packet.
+type DropReason uint32
+
+// Reasons for which the stack discards packets.
+const (
+	// DropReasonUnknown is used when no more specific reason applies.
+	DropReasonUnknown DropReason = iota
+
+	// DropReasonNICDisabled indicates that the packet arrived on a disabled
+	// NIC or network endpoint.
+	DropReasonNICDisabled
+
+	// DropReasonUnknownNetworkProtocol indicates that no network endpoint
+	// exists for the packet's network protocol.
+	DropReasonUnknownNetworkProtocol
+
+	// DropReasonUnknownTransportProtocol indicates that no transport protocol
+	// is registered for the packet's transport protocol.
+	DropReasonUnknownTransportProtocol
+
+	// DropReasonMalformed indicates that the packet failed header validation.
+	DropReasonMalformed
+
+	// DropReasonChecksum indicates that the packet had an invalid checksum.
+	DropReasonChecksum
+
+	// DropReasonInvalidSource indicates that the packet's source address is
+	// not acceptable (e.g. a martian or one of our own addresses).
+	DropReasonInvalidSource
+
+	// DropReasonInvalidDestination indicates that the packet is not addressed
+	// to this stack and could not be forwarded.
+	DropReasonInvalidDestination
+
+	// DropReasonFilter indicates that iptables or nftables dropped the
+	// packet.
+	DropReasonFilter
+
+	// DropReasonNoListener indicates that no endpoint is bound to the
+	// packet's destination port.
+	DropReasonNoListener
+
+	// DropReasonListenOverflow indicates that a listening endpoint's accept
+	// or SYN queue was full.
+	DropReasonListenOverflow
+
+	// DropReasonReceiveBufferFull indicates that the receiving endpoint's
+	// buffer was full.
+	DropReasonReceiveBufferFull
+
+	// DropReasonNetworkPolicy indicates that the packet was denied by a
+	// per-container network policy.
+	DropReasonNetworkPolicy
+
+	// NumDropReasons is the number of drop reasons. It must be last.
+	NumDropReasons
+)
+
+// String implements fmt.Stringer.
+func (r DropReason) String() string {
+	switch r {
+	case DropReasonUnknown:
+		return "unknown"
+	case DropReasonNICDisabled:
+		return "nic_disabled"
+	case DropReasonUnknownNetworkProtocol:
+		return "unknown_network_protocol"
+	case DropReasonUnknownTransportProtocol:
+		return "unknown_transport_protocol"
+	case DropReasonMalformed:
+		return "malformed"
+	case DropReasonChecksum:
+		return "checksum"
+	case DropReasonInvalidSource:
+		return "invalid_source"
+	case DropReasonInvalidDestination:
+		return "invalid_destination"
+	case DropReasonFilter:
+		return "filter"
+	case DropReasonNoListener:
+		return "no_listener"
+	case DropReasonListenOverflow:
+		return "listen_overflow"
+	case DropReasonReceiveBufferFull:
+		return "receive_buffer_full"
+	case DropReasonNetworkPolicy:
+		return "network_policy"
+	default:
+		return fmt.Sprintf("DropReason(%d)", uint32(r))
+	}
+}
+
+// DropStats counts packets discarded by the stack, one counter per
+// DropReason.
+type DropStats struct {
+	// Unknown is the number of packets dropped for DropReasonUnknown.
+	Unknown *StatCounter
+
+	// NICDisabled is the number of packets dropped for DropReasonNICDisabled.
+	NICDisabled *StatCounter
+
+	// UnknownNetworkProtocol is the number of packets dropped for
+	// DropReasonUnknownNetworkProtocol.
+	UnknownNetworkProtocol *StatCounter
+
+	// UnknownTransportProtocol is the number of packets dropped for
+	// DropReasonUnknownTransportProtocol.
+	UnknownTransportProtocol *StatCounter
+
+	// Malformed is the number of packets dropped for DropReasonMalformed.
+	Malformed *StatCounter
+
+	// Checksum is the number of packets dropped for DropReasonChecksum.
+	Checksum *StatCounter
+
+	// InvalidSource is the number of packets dropped for
+	// DropReasonInvalidSource.
+	InvalidSource *StatCounter
+
+	// InvalidDestination is the number of packets dropped for
+	// DropReasonInvalidDestination.
+	InvalidDestination *StatCounter
+
+	// Filter is the number of packets dropped for DropReasonFilter.
+	Filter *StatCounter
+
+	// NoListener is the number of packets dropped for DropReasonNoListener.
+	NoListener *StatCounter
+
+	// ListenOverflow is the number of packets dropped for
+	// DropReasonListenOverflow.
+	ListenOverflow *StatCounter
+
+	// ReceiveBufferFull is the number of packets dropped for
+	// DropReasonReceiveBufferFull.
+	ReceiveBufferFull *StatCounter
+
+	// NetworkPolicy is the number of packets dropped for
+	// DropReasonNetworkPolicy.
+	NetworkPolicy *StatCounter
+}
+
+// Counter returns the counter for the given reason. Unrecognized reasons are
+// counted as DropReasonUnknown.
+func (s *DropStats) Counter(r DropReason) *StatCounter {
+	switch r {
+	case DropReasonNICDisabled:
+		return s.NICDisabled
+	case DropReasonUnknownNetworkProtocol:
+		return s.UnknownNetworkProtocol
+	case DropReasonUnknownTransportProtocol:
+		return s.UnknownTransportProtocol
+	case DropReasonMalformed:
+		return s.Malformed
+	case DropReasonChecksum:
+		return s.Checksum
+	case DropReasonInvalidSource:
+		return s.InvalidSource
+	case DropReasonInvalidDestination:
+		return s.InvalidDestination
+	case DropReasonFilter:
+		return s.Filter
+	case DropReasonNoListener:
+		return s.NoListener
+	case DropReasonListenOverflow:
+		return s.ListenOverflow
+	case DropReasonReceiveBufferFull:
+		return s.ReceiveBufferFull
+	case DropReasonNetworkPolicy:
+		return s.NetworkPolicy
+	default:
+		return s.Unknown
+	}
 }
 
 // ReceiveErrors collects packet receive errors within transport endpoint.
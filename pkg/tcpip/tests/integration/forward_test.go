@@ -708,3 +708,150 @@ func TestPerInterfaceForwarding(t *testing.T) {
 		})
 	}
 }
+
+type testPacketFilter struct {
+	drop    bool
+	natSrc  tcpip.Address
+	inNICID tcpip.NICID
+
+	// gotCopy is true if the filter was called with the stack's copy of the
+	// packet rather than the received packet.
+	gotCopy bool
+}
+
+// CheckForward implements stack.PacketFilter.
+func (f *testPacketFilter) CheckForward(pkt *stack.PacketBuffer, inNICID tcpip.NICID, _ *stack.Route, netProto tcpip.NetworkProtocolNumber) bool {
+	f.inNICID = inNICID
+	f.gotCopy = pkt.NetworkPacketInfo.IsForwardedPacket
+	if f.drop {
+		return false
+	}
+	if f.natSrc.Len() != 0 && netProto == ipv4.ProtocolNumber {
+		header.IPv4(pkt.NetworkHeader().Slice()).SetSourceAddressWithChecksumUpdate(f.natSrc)
+	}
+	return true
+}
+
+func TestPacketFilterForwarding(t *testing.T) {
+	const (
+		nicID1 = 1
+		nicID2 = 2
+	)
+
+	natAddr := testutil.MustParse4("192.168.0.100")
+
+	tests := []struct {
+		name          string
+		filter        testPacketFilter
+		rx            func(*channel.Endpoint, tcpip.Address, tcpip.Address)
+		srcAddr       tcpip.Address
+		dstAddr       tcpip.Address
+		expectForward bool
+		checker       func(*testing.T, *buffer.View)
+	}{
+		{
+			name:          "IPv4 accept",
+			rx:            rxICMPv4EchoRequest,
+			srcAddr:       utils.RemoteIPv4Addr,
+			dstAddr:       utils.Ipv4Addr2.AddressWithPrefix.Address,
+			expectForward: true,
+			checker: func(t *testing.T, v *buffer.View) {
+				forwardedICMPv4EchoRequestChecker(t, v, utils.RemoteIPv4Addr, utils.Ipv4Addr2.AddressWithPrefix.Address)
+			},
+		},
+		{
+			name:          "IPv4 drop",
+			filter:        testPacketFilter{drop: true},
+			rx:            rxICMPv4EchoRequest,
+			srcAddr:       utils.RemoteIPv4Addr,
+			dstAddr:       utils.Ipv4Addr2.AddressWithPrefix.Address,
+			expectForward: false,
+		},
+		{
+			name:          "IPv4 NAT",
+			filter:        testPacketFilter{natSrc: natAddr},
+			rx:            rxICMPv4EchoRequest,
+			srcAddr:       utils.RemoteIPv4Addr,
+			dstAddr:       utils.Ipv4Addr2.AddressWithPrefix.Address,
+			expectForward: true,
+			checker: func(t *testing.T, v *buffer.View) {
+				forwardedICMPv4EchoRequestChecker(t, v, natAddr, utils.Ipv4Addr2.AddressWithPrefix.Address)
+			},
+		},
+		{
+			name:          "IPv6 accept",
+			rx:            rxICMPv6EchoRequest,
+			srcAddr:       utils.RemoteIPv6Addr,
+			dstAddr:       utils.Ipv6Addr2.AddressWithPrefix.Address,
+			expectForward: true,
+			checker: func(t *testing.T, v *buffer.View) {
+				forwardedICMPv6EchoRequestChecker(t, v, utils.RemoteIPv6Addr, utils.Ipv6Addr2.AddressWithPrefix.Address)
+			},
+		},
+		{
+			name:          "IPv6 drop",
+			filter:        testPacketFilter{drop: true},
+			rx:            rxICMPv6EchoRequest,
+			srcAddr:       utils.RemoteIPv6Addr,
+			dstAddr:       utils.Ipv6Addr2.AddressWithPrefix.Address,
+			expectForward: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := stack.New(stack.Options{
+				NetworkProtocols: []stack.NetworkProtocolFactory{ipv4.NewProtocol, ipv6.NewProtocol},
+			})
+			defer s.Destroy()
+			filter := test.filter
+			s.SetPacketFilter(&filter)
+
+			e1 := channel.New(1, header.IPv6MinimumMTU, "")
+			if err := s.CreateNIC(nicID1, e1); err != nil {
+				t.Fatalf("s.CreateNIC(%d, _): %s", nicID1, err)
+			}
+			e2 := channel.New(1, header.IPv6MinimumMTU, "")
+			if err := s.CreateNIC(nicID2, e2); err != nil {
+				t.Fatalf("s.CreateNIC(%d, _): %s", nicID2, err)
+			}
+
+			for _, protocolAddr := range []tcpip.ProtocolAddress{
+				{Protocol: ipv4.ProtocolNumber, AddressWithPrefix: utils.Ipv4Addr},
+				{Protocol: ipv6.ProtocolNumber, AddressWithPrefix: utils.Ipv6Addr},
+			} {
+				if err := s.AddProtocolAddress(nicID2, protocolAddr, stack.AddressProperties{}); err != nil {
+					t.Fatalf("AddProtocolAddress(%d, %+v, {}): %s", nicID2, protocolAddr, err)
+				}
+			}
+			for _, netProto := range []tcpip.NetworkProtocolNumber{ipv4.ProtocolNumber, ipv6.ProtocolNumber} {
+				if err := s.SetForwardingDefaultAndAllNICs(netProto, true); err != nil {
+					t.Fatalf("s.SetForwardingDefaultAndAllNICs(%d, true): %s", netProto, err)
+				}
+			}
+			s.SetRouteTable([]tcpip.Route{
+				{Destination: header.IPv4EmptySubnet, NIC: nicID2},
+				{Destination: header.IPv6EmptySubnet, NIC: nicID2},
+			})
+
+			test.rx(e1, test.srcAddr, test.dstAddr)
+
+			if filter.inNICID != nicID1 {
+				t.Errorf("got filter.inNICID = %d, want = %d", filter.inNICID, nicID1)
+			}
+			if !filter.gotCopy {
+				t.Errorf("filter was called with the received packet, want a copy")
+			}
+			p := e2.Read()
+			if (p != nil) != test.expectForward {
+				t.Fatalf("got e2.Read() = %#v, want = (_ == nil) = %t", p, test.expectForward)
+			}
+			if test.expectForward {
+				payload := stack.PayloadSince(p.NetworkHeader())
+				defer payload.Release()
+				test.checker(t, payload)
+				p.DecRef()
+			}
+		})
+	}
+}
@@ -543,6 +543,8 @@ func TestMulticastLoop(t *testing.T) {
 		localAddr       tcpip.AddressWithPrefix
 		destAddr        tcpip.Address
 		rawSocketHdrLen int
+		setLoop         func(*tcpip.SocketOptions, bool)
+		setOtherLoop    func(*tcpip.SocketOptions, bool)
 	}{
 		{
 			name:            "IPv4",
@@ -550,6 +552,8 @@ func TestMulticastLoop(t *testing.T) {
 			localAddr:       testutil.MustParse4("1.2.3.4").WithPrefix(),
 			destAddr:        header.IPv4AllSystems,
 			rawSocketHdrLen: header.IPv4MinimumSize,
+			setLoop:         (*tcpip.SocketOptions).SetMulticastLoop,
+			setOtherLoop:    (*tcpip.SocketOptions).SetIPv6MulticastLoop,
 		},
 		{
 			name:            "IPv6",
@@ -557,6 +561,8 @@ func TestMulticastLoop(t *testing.T) {
 			localAddr:       testutil.MustParse6("a::1").WithPrefix(),
 			destAddr:        header.IPv6AllNodesMulticastAddress,
 			rawSocketHdrLen: 0,
+			setLoop:         (*tcpip.SocketOptions).SetIPv6MulticastLoop,
+			setOtherLoop:    (*tcpip.SocketOptions).SetMulticastLoop,
 		},
 	} {
 		t.Run(netProto.name, func(t *testing.T) {
@@ -664,10 +670,15 @@ func TestMulticastLoop(t *testing.T) {
 					checkWrite([]byte{1, 2, 3, 4}, true /* withRead */)
 
 					ops := ep.SocketOptions()
-					ops.SetMulticastLoop(false)
+					netProto.setLoop(ops, false)
 					checkWrite([]byte{5, 6, 7, 8}, false /* withRead */)
 
-					ops.SetMulticastLoop(true)
+					// The other protocol's option doesn't affect this one.
+					netProto.setOtherLoop(ops, true)
+					checkWrite([]byte{5, 6, 7, 8}, false /* withRead */)
+
+					netProto.setLoop(ops, true)
+					netProto.setOtherLoop(ops, false)
 					checkWrite([]byte{9, 10, 11, 12}, true /* withRead */)
 				})
 			}
@@ -60,9 +60,10 @@ type Endpoint struct {
 	ipv4TTL uint8
 	// +checklocks:mu
 	ipv6HopLimit int16
-	// TODO(https://gvisor.dev/issue/6389): Use different fields for IPv4/IPv6.
 	// +checklocks:mu
 	multicastTTL uint8
+	// +checklocks:mu
+	ipv6MulticastHopLimit uint8
 	// TODO(https://gvisor.dev/issue/6389): Use different fields for IPv4/IPv6.
 	// +checklocks:mu
 	multicastAddr tcpip.Address
@@ -149,6 +150,7 @@ func (e *Endpoint) Init(s *stack.Stack, netProto tcpip.NetworkProtocolNumber, tr
 
 	// Linux defaults to TTL=1.
 	e.multicastTTL = 1
+	e.ipv6MulticastHopLimit = 1
 	e.multicastMemberships = make(map[multicastMembership]struct{})
 	e.setEndpointState(transport.DatagramEndpointStateInitial)
 }
@@ -207,12 +209,31 @@ func (e *Endpoint) SetOwner(owner tcpip.PacketOwner) {
 	e.owner = owner
 }
 
+// Owner returns the owner of transmitted packets.
+func (e *Endpoint) Owner() tcpip.PacketOwner {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.owner
+}
+
+// multicastLoop returns whether multicast packets of protocol netProto sent by
+// the endpoint are looped back.
+func (e *Endpoint) multicastLoop(netProto tcpip.NetworkProtocolNumber) bool {
+	if netProto == header.IPv6ProtocolNumber {
+		return e.ops.GetIPv6MulticastLoop()
+	}
+	return e.ops.GetMulticastLoop()
+}
+
 // +checklocksread:e.mu
 func (e *Endpoint) calculateTTL(route *stack.Route) uint8 {
 	remoteAddress := route.RemoteAddress()
-	if header.IsV4MulticastAddress(remoteAddress) || header.IsV6MulticastAddress(remoteAddress) {
+	if header.IsV4MulticastAddress(remoteAddress) {
 		return e.multicastTTL
 	}
+	if header.IsV6MulticastAddress(remoteAddress) {
+		return e.ipv6MulticastHopLimit
+	}
 
 	switch netProto := route.NetProto(); netProto {
 	case header.IPv4ProtocolNumber:
@@ -332,6 +353,7 @@ func (c *WriteContext) newPacketBufferLocked(reserveHdrBytes int, data buffer.Bu
 		ReserveHeaderBytes: reserveHdrBytes,
 		Payload:            data,
 		Mark:               mark,
+		Priority:           e.ops.GetPriority(),
 		OnRelease: func() {
 			e.sendBufferSizeInUseMu.Lock()
 			if got := e.sendBufferSizeInUse; got < pktSize {
@@ -654,7 +676,7 @@ func (e *Endpoint) connectRouteRLocked(nicID tcpip.NICID, localAddr tcpip.Addres
 	}
 
 	// Find a route to the desired destination.
-	r, err := e.stack.FindRoute(nicID, localAddr, addr.Addr, netProto, e.ops.GetMulticastLoop())
+	r, err := e.stack.FindRoute(nicID, localAddr, addr.Addr, netProto, e.multicastLoop(netProto))
 	if err != nil {
 		return nil, 0, err
 	}
@@ -699,6 +721,17 @@ func (e *Endpoint) ConnectAndThen(addr tcpip.FullAddress, f func(netProto tcpip.
 		return &tcpip.ErrInvalidEndpointState{}
 	}
 
+	// A socket bound to a device with SO_BINDTODEVICE must only reach the
+	// peer through that device. As with the bound NIC above, Linux fails
+	// connect(2) with EINVAL if a different device is requested (see
+	// net/ipv6/datagram.c:__ip6_datagram_connect()).
+	if bindToDevice := tcpip.NICID(e.ops.GetBindToDevice()); bindToDevice != 0 {
+		if nicID != 0 && nicID != bindToDevice {
+			return &tcpip.ErrInvalidEndpointState{}
+		}
+		nicID = bindToDevice
+	}
+
 	addr, netProto, err := e.checkV4Mapped(addr, false /* bind */)
 	if err != nil {
 		return err
@@ -885,6 +918,11 @@ func (e *Endpoint) SetSockOptInt(opt tcpip.SockOptInt, v int) tcpip.Error {
 		e.multicastTTL = uint8(v)
 		e.mu.Unlock()
 
+	case tcpip.IPv6MulticastHopLimitOption:
+		e.mu.Lock()
+		e.ipv6MulticastHopLimit = uint8(v)
+		e.mu.Unlock()
+
 	case tcpip.IPv4TTLOption:
 		e.mu.Lock()
 		e.ipv4TTL = uint8(v)
@@ -936,6 +974,12 @@ func (e *Endpoint) GetSockOptInt(opt tcpip.SockOptInt) (int, tcpip.Error) {
 		e.mu.Unlock()
 		return v, nil
 
+	case tcpip.IPv6MulticastHopLimitOption:
+		e.mu.Lock()
+		v := int(e.ipv6MulticastHopLimit)
+		e.mu.Unlock()
+		return v, nil
+
 	case tcpip.IPv4TTLOption:
 		e.mu.Lock()
 		v := int(e.ipv4TTL)
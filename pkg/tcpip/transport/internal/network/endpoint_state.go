@@ -50,7 +50,7 @@ func (e *Endpoint) Resume(s *stack.Stack) error {
 		}
 	case transport.DatagramEndpointStateConnected:
 		var err tcpip.Error
-		multicastLoop := e.ops.GetMulticastLoop()
+		multicastLoop := e.multicastLoop(e.effectiveNetProto)
 		// Release the connectedRoute if present.
 		if e.connectedRoute != nil {
 			e.connectedRoute.Release()
@@ -64,3 +64,24 @@ func (e *Endpoint) Resume(s *stack.Stack) error {
 	}
 	return nil
 }
+
+// RemapLocalAddress replaces the local address of a restored endpoint, from,
+// with to, which is assigned to the NIC nicID. It must be called before
+// Resume.
+func (e *Endpoint) RemapLocalAddress(from, to tcpip.Address, nicID tcpip.NICID) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	info := e.Info()
+	if info.ID.LocalAddress != from {
+		return
+	}
+	info.ID.LocalAddress = to
+	if info.BindAddr == from {
+		info.BindAddr = to
+	}
+	if info.RegisterNICID != 0 {
+		info.RegisterNICID = nicID
+	}
+	e.setInfo(info)
+}
@@ -359,6 +359,102 @@ func TestBindNICID(t *testing.T) {
 	}
 }
 
+// bindToDeviceHandler allows binding to any NIC of the stack.
+type bindToDeviceHandler struct {
+	tcpip.DefaultSocketOptionsHandler
+	s *stack.Stack
+}
+
+// HasNIC implements tcpip.SocketOptionsHandler.HasNIC.
+func (h *bindToDeviceHandler) HasNIC(v int32) bool {
+	return h.s.HasNIC(tcpip.NICID(v))
+}
+
+func TestConnectBindToDevice(t *testing.T) {
+	const (
+		nicID1 = 1
+		nicID2 = 2
+	)
+
+	tests := []struct {
+		name              string
+		bindToDevice      tcpip.NICID
+		connectNICID      tcpip.NICID
+		wantRegisterNICID tcpip.NICID
+		wantErr           tcpip.Error
+	}{
+		{
+			name: "not bound to device",
+		},
+		{
+			name:              "bound to device",
+			bindToDevice:      nicID2,
+			wantRegisterNICID: nicID2,
+		},
+		{
+			name:              "bound to device with same connect NIC",
+			bindToDevice:      nicID2,
+			connectNICID:      nicID2,
+			wantRegisterNICID: nicID2,
+		},
+		{
+			name:         "bound to device with different connect NIC",
+			bindToDevice: nicID2,
+			connectNICID: nicID1,
+			wantErr:      &tcpip.ErrInvalidEndpointState{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := stack.New(stack.Options{
+				NetworkProtocols:   []stack.NetworkProtocolFactory{ipv4.NewProtocol},
+				TransportProtocols: []stack.TransportProtocolFactory{udp.NewProtocol},
+				Clock:              &faketime.NullClock{},
+			})
+			defer s.Destroy()
+			for i, nicID := range []tcpip.NICID{nicID1, nicID2} {
+				if err := s.CreateNIC(nicID, channel.New(1, header.IPv6MinimumMTU, "")); err != nil {
+					t.Fatalf("s.CreateNIC(%d, _): %s", nicID, err)
+				}
+				protocolAddr := tcpip.ProtocolAddress{
+					Protocol:          ipv4.ProtocolNumber,
+					AddressWithPrefix: testutil.MustParse4(fmt.Sprintf("10.0.%d.1", i)).WithPrefix(),
+				}
+				if err := s.AddProtocolAddress(nicID, protocolAddr, stack.AddressProperties{}); err != nil {
+					t.Fatalf("s.AddProtocolAddress(%d, %+v, {}): %s", nicID, protocolAddr, err)
+				}
+			}
+			// Both NICs have a default route, the first one is preferred.
+			s.SetRouteTable([]tcpip.Route{
+				{Destination: header.IPv4EmptySubnet, NIC: nicID1},
+				{Destination: header.IPv4EmptySubnet, NIC: nicID2},
+			})
+
+			var ops tcpip.SocketOptions
+			ops.InitHandler(&bindToDeviceHandler{s: s}, s, tcpip.GetStackSendBufferLimits, tcpip.GetStackReceiveBufferLimits)
+			var ep network.Endpoint
+			var wq waiter.Queue
+			ep.Init(s, ipv4.ProtocolNumber, udp.ProtocolNumber, &ops, &wq)
+			defer ep.Close()
+
+			if err := ops.SetBindToDevice(int32(test.bindToDevice)); err != nil {
+				t.Fatalf("ops.SetBindToDevice(%d): %s", test.bindToDevice, err)
+			}
+			connectAddr := tcpip.FullAddress{Addr: ipv4RemoteAddr, NIC: test.connectNICID}
+			if err := ep.Connect(connectAddr); err != test.wantErr {
+				t.Fatalf("got ep.Connect(%#v) = %s, want = %s", connectAddr, err, test.wantErr)
+			}
+			if test.wantErr != nil {
+				return
+			}
+			if got := ep.Info().RegisterNICID; got != test.wantRegisterNICID {
+				t.Errorf("got ep.Info().RegisterNICID = %d, want = %d", got, test.wantRegisterNICID)
+			}
+		})
+	}
+}
+
 func TestMain(m *testing.M) {
 	refs.SetLeakMode(refs.LeaksPanic)
 	code := m.Run()
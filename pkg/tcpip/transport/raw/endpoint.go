@@ -133,6 +133,7 @@ func newEndpoint(s *stack.Stack, netProto tcpip.NetworkProtocolNumber, transProt
 	}
 	e.ops.InitHandler(e, e.stack, tcpip.GetStackSendBufferLimits, tcpip.GetStackReceiveBufferLimits)
 	e.ops.SetMulticastLoop(true)
+	e.ops.SetIPv6MulticastLoop(true)
 	e.ops.SetHeaderIncluded(!associated)
 	e.ops.SetSendBufferSize(32*1024, false /* notify */)
 	e.ops.SetReceiveBufferSize(32*1024, false /* notify */)
@@ -614,6 +615,12 @@ func (e *endpoint) HandlePacket(pkt *stack.PacketBuffer) {
 			return false
 		}
 
+		// If bound to a device with SO_BINDTODEVICE, only accept data
+		// received on that device.
+		if bindToDevice := tcpip.NICID(e.ops.GetBindToDevice()); bindToDevice != 0 && bindToDevice != pkt.NICID {
+			return false
+		}
+
 		net := pkt.Network()
 		dstAddr := net.DestinationAddress()
 		srcAddr := net.SourceAddress()
@@ -363,6 +363,7 @@ func (e *Endpoint) propagateInheritableOptionsLocked(n *Endpoint) {
 	n.boundPortFlags = e.boundPortFlags
 	n.userMSS = e.userMSS
 	n.ops.SetMark(e.ops.GetMark())
+	n.ops.SetPriority(e.ops.GetPriority())
 }
 
 // reserveTupleLocked reserves an accepted endpoint's tuple.
@@ -453,10 +454,16 @@ func (e *Endpoint) handleListenSegment(ctx *listenContext, s *segment) tcpip.Err
 		return nil
 
 	case s.flags.Contains(header.TCPFlagSyn):
+		if !e.stack.AllowNetworkPolicyIngress(e.owner, ProtocolNumber, s.id.RemoteAddress, s.id.LocalPort) {
+			e.stack.Stats().DroppedPackets.Increment()
+			e.stack.RecordDrop(s.pkt.NICID, tcpip.DropReasonNetworkPolicy, s.pkt)
+			return nil
+		}
 		if e.acceptQueueIsFull() {
 			e.stack.Stats().TCP.ListenOverflowSynDrop.Increment()
 			e.stats.ReceiveErrors.ListenOverflowSynDrop.Increment()
 			e.stack.Stats().DroppedPackets.Increment()
+			e.stack.RecordDrop(s.pkt.NICID, tcpip.DropReasonListenOverflow, s.pkt)
 			return nil
 		}
 
@@ -626,6 +633,7 @@ func (e *Endpoint) handleListenSegment(ctx *listenContext, s *segment) tcpip.Err
 			e.stack.Stats().TCP.ListenOverflowAckDrop.Increment()
 			e.stats.ReceiveErrors.ListenOverflowAckDrop.Increment()
 			e.stack.Stats().DroppedPackets.Increment()
+			e.stack.RecordDrop(s.pkt.NICID, tcpip.DropReasonListenOverflow, s.pkt)
 			return nil
 		}
 
@@ -490,6 +490,7 @@ func (h *handshake) synRcvdState(s *segment) tcpip.Error {
 		// https://github.com/torvalds/linux/blob/7acac4b3196/net/ipv4/tcp_minisocks.c#L788
 		if listenEP := h.listenEP; listenEP != nil && listenEP.acceptQueueIsFull() {
 			listenEP.stack.Stats().DroppedPackets.Increment()
+			listenEP.stack.RecordDrop(s.pkt.NICID, tcpip.DropReasonListenOverflow, s.pkt)
 			return nil
 		}
 
@@ -822,6 +823,7 @@ type tcpFields struct {
 	rcvWnd    seqnum.Size
 	opts      []byte
 	txHash    uint32
+	priority  uint32
 	df        bool
 	expOptVal uint16
 }
@@ -848,6 +850,7 @@ func (e *Endpoint) sendSynTCP(r *stack.Route, tf tcpFields, opts header.TCPSynOp
 // This method takes ownership of pkt.
 func (e *Endpoint) sendTCP(r *stack.Route, tf tcpFields, pkt *stack.PacketBuffer, gso stack.GSO) tcpip.Error {
 	tf.txHash = e.txHash
+	tf.priority = e.ops.GetPriority()
 	if err := sendTCP(r, tf, pkt, gso, e.owner); err != nil {
 		e.stats.SendErrors.SegmentSendToNetworkFailed.Increment()
 		return err
@@ -921,6 +924,7 @@ func sendTCPBatch(r *stack.Route, tf tcpFields, pkt *stack.PacketBuffer, gso sta
 		}
 		pkt.Hash = tf.txHash
 		pkt.Owner = owner
+		pkt.Priority = tf.priority
 
 		buildTCPHdr(r, tf, pkt, gso)
 		tf.seq = tf.seq.Add(seqnum.Size(packetSize))
@@ -960,6 +964,7 @@ func sendTCP(r *stack.Route, tf tcpFields, pkt *stack.PacketBuffer, gso stack.GS
 
 	pkt.GSOOptions = gso
 	pkt.Hash = tf.txHash
+	pkt.Priority = tf.priority
 	pkt.Owner = owner
 	buildTCPHdr(r, tf, pkt, gso)
 
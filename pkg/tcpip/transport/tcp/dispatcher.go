@@ -260,6 +260,11 @@ var warnRateLimiter = rate.NewLimiter(rate.Every(time.Second), 1)
 
 // handleListen is responsible for TCP processing for an endpoint in LISTEN
 // state.
+//
+// Segments, including SYNs, are processed with ep.mu held, which serializes
+// connection setup for each listener. Processing SYNs without ep.mu would
+// require moving all the listener state used by handleListenSegment under
+// finer-grained locks, which hasn't been done.
 func handleListen(ep *Endpoint) {
 	if !ep.TryLock() {
 		return
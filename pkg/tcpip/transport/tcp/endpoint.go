@@ -883,6 +883,7 @@ func newEndpoint(s *stack.Stack, protocol *protocol, netProto tcpip.NetworkProto
 	}
 	e.ops.InitHandler(e, e.stack, GetTCPSendBufferLimits, GetTCPReceiveBufferLimits)
 	e.ops.SetMulticastLoop(true)
+	e.ops.SetIPv6MulticastLoop(true)
 	e.ops.SetQuickAck(true)
 	e.ops.SetSendBufferSize(DefaultSendBufferSize, false /* notify */)
 	e.ops.SetReceiveBufferSize(DefaultReceiveBufferSize, false /* notify */)
@@ -1032,6 +1033,7 @@ func (e *Endpoint) purgeWriteQueue() {
 			e.snd.writeList.Remove(s)
 			s.DecRef()
 		}
+		e.protocol.updateMemUsed(-e.sndQueueInfo.SndBufUsed)
 		e.sndQueueInfo.SndBufUsed = 0
 		e.sndQueueInfo.SndClosed = true
 		e.snd.SndNxt = e.snd.SndUna
@@ -1341,9 +1343,10 @@ func (e *Endpoint) ModerateRecvBuf(copied int) {
 
 		// We do not adjust downwards as that can cause the receiver to
 		// reject valid data that might already be in flight as the
-		// acceptable window will shrink.
+		// acceptable window will shrink. Buffers aren't grown either
+		// while the stack is under memory pressure.
 		rcvBufSize := int(e.ops.GetReceiveBufferSize())
-		if rcvWnd > rcvBufSize {
+		if rcvWnd > rcvBufSize && !e.protocol.memoryPressure() {
 			availBefore := wndFromSpace(e.receiveBufferAvailableLocked(rcvBufSize))
 			e.ops.SetReceiveBufferSize(int64(rcvWnd), false /* notify */)
 			availAfter := wndFromSpace(e.receiveBufferAvailableLocked(rcvWnd))
@@ -1650,6 +1653,7 @@ func (e *Endpoint) queueSegment(p tcpip.Payloader, opts tcpip.WriteOptions) (*se
 	size := int(buf.Size())
 	s := newOutgoingSegment(e.TransportEndpointInfo.ID, e.stack.Clock(), buf, e.ops.GetMark())
 	e.sndQueueInfo.SndBufUsed += size
+	e.protocol.updateMemUsed(size)
 	e.snd.writeList.PushBack(s)
 
 	return s, size, nil
@@ -2139,7 +2143,7 @@ func (e *Endpoint) GetSockOptInt(opt tcpip.SockOptInt) (int, tcpip.Error) {
 		e.UnlockUser()
 		return v, nil
 
-	case tcpip.MulticastTTLOption:
+	case tcpip.MulticastTTLOption, tcpip.IPv6MulticastHopLimitOption:
 		return 1, nil
 
 	default:
@@ -2451,6 +2455,19 @@ func (e *Endpoint) connect(addr tcpip.FullAddress, handshake bool) tcpip.Error {
 		return &tcpip.ErrInvalidEndpointState{}
 	}
 
+	if !e.stack.AllowNetworkPolicyEgress(e.owner, ProtocolNumber, addr.Addr, addr.Port) {
+		return &tcpip.ErrNotPermitted{}
+	}
+
+	// A socket bound to a device with SO_BINDTODEVICE must only reach the
+	// peer through that device.
+	if bindToDevice := tcpip.NICID(e.ops.GetBindToDevice()); bindToDevice != 0 {
+		if nicID != 0 && nicID != bindToDevice {
+			return &tcpip.ErrHostUnreachable{}
+		}
+		nicID = bindToDevice
+	}
+
 	// Find a route to the desired destination.
 	r, err := e.stack.FindRoute(nicID, e.TransportEndpointInfo.ID.LocalAddress, addr.Addr, netProto, false /* multicastLoop */)
 	if err != nil {
@@ -2889,11 +2906,21 @@ func (*Endpoint) HandlePacket(stack.TransportEndpointID, *stack.PacketBuffer) {
 }
 
 func (e *Endpoint) enqueueSegment(s *segment) bool {
+	if s.payloadSize() != 0 && e.protocol.memoryLimitExceeded() {
+		// Like Linux when tcp_mem is exceeded, drop segments carrying
+		// data but keep processing ACKs so that memory can be freed.
+		e.stack.Stats().DroppedPackets.Increment()
+		e.stack.Stats().TCP.MemoryLimitDrops.Increment()
+		e.stats.ReceiveErrors.SegmentQueueDropped.Increment()
+		e.stack.RecordDrop(s.pkt.NICID, tcpip.DropReasonReceiveBufferFull, s.pkt)
+		return false
+	}
 	// Send packet to worker goroutine.
 	if !e.segmentQueue.enqueue(s) {
 		// The queue is full, so we drop the segment.
 		e.stack.Stats().DroppedPackets.Increment()
 		e.stats.ReceiveErrors.SegmentQueueDropped.Increment()
+		e.stack.RecordDrop(s.pkt.NICID, tcpip.DropReasonReceiveBufferFull, s.pkt)
 		return false
 	}
 	return true
@@ -3006,6 +3033,7 @@ func (e *Endpoint) updateSndBufferUsage(v int) {
 	e.sndQueueInfo.sndQueueMu.Lock()
 	notify := e.sndQueueInfo.SndBufUsed >= sendBufferSize>>1
 	e.sndQueueInfo.SndBufUsed -= v
+	e.protocol.updateMemUsed(-v)
 
 	// Get the new send buffer size with auto tuning, but do not set it
 	// unless we decide to notify the writers.
@@ -3082,6 +3110,7 @@ func (e *Endpoint) receiveMemUsed() int {
 // updateReceiveMemUsed adds the provided delta to e.rcvMemUsed.
 func (e *Endpoint) updateReceiveMemUsed(delta int) {
 	e.rcvMemUsed.Add(int32(delta))
+	e.protocol.updateMemUsed(delta)
 }
 
 // maxReceiveBufferSize returns the stack wide maximum receive buffer size for
@@ -3346,7 +3375,7 @@ func (e *Endpoint) computeTCPSendBufferSize() int64 {
 	// size should be set to SndCwnd*MSS to accommodate sending of all the
 	// segments.
 	newSndBufSz := int64(numSeg * curMSS * packetOverheadFactor)
-	if newSndBufSz < curSndBufSz {
+	if newSndBufSz < curSndBufSz || e.protocol.memoryPressure() {
 		return curSndBufSz
 	}
 	if ss := GetTCPSendBufferLimits(e.stack); int64(ss.Max) < newSndBufSz {
@@ -23,6 +23,7 @@ import (
 	"gvisor.dev/gvisor/pkg/sync"
 	"gvisor.dev/gvisor/pkg/tcpip"
 	"gvisor.dev/gvisor/pkg/tcpip/header"
+	"gvisor.dev/gvisor/pkg/tcpip/ports"
 	"gvisor.dev/gvisor/pkg/tcpip/seqnum"
 	"gvisor.dev/gvisor/pkg/tcpip/stack"
 )
@@ -140,8 +141,10 @@ func (e *Endpoint) afterLoad(ctx context.Context) {
 	e.stack.RegisterRestoredEndpoint(e)
 }
 
-// Close the endpoint during restore if terminateAtRestore was set for the endpoint.
-func (e *Endpoint) closeEndpointAtRestore() {
+// Close the endpoint during restore if terminateAtRestore was set for the
+// endpoint, or if its connection can't be restored. If err isn't nil, it's
+// reported to the application.
+func (e *Endpoint) closeEndpointAtRestore(err tcpip.Error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -158,6 +161,9 @@ func (e *Endpoint) closeEndpointAtRestore() {
 
 	// Put the endpoint in the error state and do cleanup. Do not
 	// attempt to send RST as route will be nil.
+	if err != nil {
+		e.hardError = err
+	}
 	e.purgeReadQueue()
 	if epState.connected() {
 		e.purgeWriteQueue()
@@ -204,28 +210,29 @@ func (e *Endpoint) Restore(s *stack.Stack) {
 	}
 
 	if terminateAtRestore && !e.stack.AllowLiveTCPMigration() {
-		e.closeEndpointAtRestore()
+		e.closeEndpointAtRestore(nil)
 		return
 	}
 
 	epState := EndpointState(e.origEndpointState)
 	switch {
 	case epState.connected():
+		// The peer of a connection whose local address is gone, e.g. because
+		// the sandbox was restored with a different address, can't reach
+		// it anymore, so reset the connection rather than restoring it.
+		netProto := e.localAddressProtocol()
+		if e.stack.IsStaleRestoredAddress(netProto, id.LocalAddress) {
+			log.Infof("Local address of TCP endpoint is gone, resetting connection %+v", id)
+			e.closeEndpointAtRestore(&tcpip.ErrConnectionReset{})
+			return
+		}
 		if e.stack.AllowLiveTCPMigration() {
-			// Handle dual stack addresses.
-			netProto := e.NetProto
-			switch e.TransportEndpointInfo.ID.LocalAddress.BitLen() {
-			case header.IPv4AddressSizeBits:
-				netProto = header.IPv4ProtocolNumber
-			case header.IPv6AddressSizeBits:
-				netProto = header.IPv6ProtocolNumber
-			}
 			// Get the new local NIC for source IP and do a FindRoute here to
 			// identify if the network config is same. Then only attempt restore,
 			// else close the connection on our end.
 			r, err := e.stack.FindRoute(0, e.TransportEndpointInfo.ID.LocalAddress, e.TransportEndpointInfo.ID.RemoteAddress, netProto, false /* multicastLoop */)
 			if err != nil {
-				e.closeEndpointAtRestore()
+				e.closeEndpointAtRestore(&tcpip.ErrConnectionReset{})
 				log.Infof("Cannot find the route %+v", e.TransportEndpointInfo.ID)
 				return
 			}
@@ -269,6 +276,8 @@ func (e *Endpoint) Restore(s *stack.Stack) {
 			e.finWait2Timer = e.stack.Clock().AfterFunc(e.tcpLingerTimeout, e.finWait2TimerExpired)
 		case StateTimeWait:
 			e.timeWaitTimer = e.stack.Clock().AfterFunc(e.getTimeWaitDuration(), e.timeWaitTimerExpired)
+		default:
+			e.restartConnectionLocked()
 		}
 
 		if e.ops.GetCorkOption() {
@@ -280,6 +289,7 @@ func (e *Endpoint) Restore(s *stack.Stack) {
 		e.requeueOnRestore()
 		connectedLoading.Done()
 	case epState == StateListen:
+		e.remapLocalAddressAtRestore()
 		tcpip.AsyncLoading.Add(1)
 		go func() {
 			connectedLoading.Wait()
@@ -342,6 +352,7 @@ func (e *Endpoint) Restore(s *stack.Stack) {
 			e.requeueOnRestore()
 		}()
 	case epState == StateBound:
+		e.remapLocalAddressAtRestore()
 		tcpip.AsyncLoading.Add(1)
 		go func() {
 			connectedLoading.Wait()
@@ -362,6 +373,109 @@ func (e *Endpoint) Restore(s *stack.Stack) {
 	}
 }
 
+// localAddressProtocol returns the network protocol of the endpoint's local
+// address, which differs from NetProto for IPv4 addresses of dual-stack
+// endpoints.
+func (e *Endpoint) localAddressProtocol() tcpip.NetworkProtocolNumber {
+	switch e.TransportEndpointInfo.ID.LocalAddress.BitLen() {
+	case header.IPv4AddressSizeBits:
+		return header.IPv4ProtocolNumber
+	case header.IPv6AddressSizeBits:
+		return header.IPv6ProtocolNumber
+	}
+	return e.NetProto
+}
+
+// remapLocalAddressAtRestore moves a listening or bound endpoint whose local
+// address was replaced at restore to the new address, so that it keeps
+// receiving connections.
+func (e *Endpoint) remapLocalAddressAtRestore() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	netProto := e.localAddressProtocol()
+	from := e.TransportEndpointInfo.ID.LocalAddress
+	to, ok := e.stack.RemapRestoredAddress(netProto, from)
+	if !ok {
+		return
+	}
+	nic := e.stack.CheckLocalAddress(0, netProto, to)
+	if nic == 0 {
+		log.Warningf("Cannot remap TCP endpoint %+v to %s: address isn't assigned", e.TransportEndpointInfo.ID, to)
+		return
+	}
+
+	oldID := e.TransportEndpointInfo.ID
+	newID := oldID
+	newID.LocalAddress = to
+	portRes := ports.Reservation{
+		Networks:     e.effectiveNetProtos,
+		Transport:    ProtocolNumber,
+		Addr:         to,
+		Port:         oldID.LocalPort,
+		Flags:        e.boundPortFlags,
+		BindToDevice: e.boundBindToDevice,
+		Dest:         e.boundDest,
+	}
+	if _, err := e.stack.ReservePort(e.stack.SecureRNG(), portRes, nil /* testPort */); err != nil {
+		log.Warningf("Cannot remap TCP endpoint %+v to %s: %v", oldID, to, err)
+		return
+	}
+	if e.isRegistered {
+		e.stack.UnregisterTransportEndpoint(e.effectiveNetProtos, ProtocolNumber, oldID, e, e.boundPortFlags, e.boundBindToDevice)
+		if err := e.stack.RegisterTransportEndpoint(e.effectiveNetProtos, ProtocolNumber, newID, e, e.boundPortFlags, e.boundBindToDevice); err != nil {
+			log.Warningf("Cannot remap TCP endpoint %+v to %s: %v", oldID, to, err)
+			_ = e.stack.RegisterTransportEndpoint(e.effectiveNetProtos, ProtocolNumber, oldID, e, e.boundPortFlags, e.boundBindToDevice)
+			e.stack.ReleasePort(portRes)
+			return
+		}
+	}
+	portRes.Addr = from
+	e.stack.ReleasePort(portRes)
+
+	e.TransportEndpointInfo.ID = newID
+	e.BindAddr = to
+	e.boundNICID = nic
+	log.Infof("Remapped TCP endpoint %+v to %s", oldID, to)
+}
+
+// restartConnectionLocked resumes the transmission state of a connection that
+// was established when it was saved. Timers are not saved, so without this a
+// connection with unacknowledged data or a closed peer window would stall
+// after restore instead of recovering from the packets lost while the sandbox
+// was paused.
+//
+// +checklocks:e.mu
+func (e *Endpoint) restartConnectionLocked() {
+	snd := e.snd
+	switch {
+	case snd.writeNext != nil && snd.SndWnd == 0:
+		snd.enableZeroWindowProbing()
+	case snd.SndUna != snd.SndNxt:
+		snd.resendTimer.enable(snd.RTO)
+	}
+	switch e.stack.KeepaliveRestorePolicy() {
+	case stack.KeepaliveRestoreReset:
+		// Treat the restore like the receipt of data, which forgets
+		// unacknowledged keepalive probes.
+		e.resetKeepaliveTimer(true /* receivedData */)
+	case stack.KeepaliveRestoreProbe:
+		e.resetKeepaliveTimer(false /* receivedData */)
+		e.keepalive.Lock()
+		if e.keepalive.timer.enabled() {
+			e.keepalive.timer.enable(0)
+		}
+		e.keepalive.Unlock()
+	default:
+		e.resetKeepaliveTimer(false /* receivedData */)
+	}
+
+	// Like Linux does when a socket leaves TCP_REPAIR mode, send a window
+	// probe so that the peer learns our current window and acknowledges
+	// what it has received, letting both sides retransmit what was lost.
+	snd.sendEmptySegment(header.TCPFlagAck, snd.SndUna-1)
+}
+
 // Resume implements tcpip.ResumableEndpoint.Resume.
 func (e *Endpoint) Resume() {
 	e.segmentQueue.thaw()
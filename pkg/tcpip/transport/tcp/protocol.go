@@ -23,6 +23,7 @@ import (
 	"strings"
 	"time"
 
+	"gvisor.dev/gvisor/pkg/atomicbitops"
 	"gvisor.dev/gvisor/pkg/tcpip"
 	"gvisor.dev/gvisor/pkg/tcpip/header"
 	"gvisor.dev/gvisor/pkg/tcpip/header/parse"
@@ -108,6 +109,21 @@ type protocol struct {
 	synRetries                 uint8
 	dispatcher                 dispatcher
 
+	// memMin, memPressure and memMax are the memory limits set with
+	// tcpip.TCPMemoryLimitsOption. They are read on every segment, so they
+	// are atomics rather than protected by mu.
+	memMin      atomicbitops.Int64
+	memPressure atomicbitops.Int64
+	memMax      atomicbitops.Int64
+
+	// memUsed is the amount of memory held in the send and receive buffers
+	// of all endpoints.
+	memUsed atomicbitops.Int64
+
+	// underMemPressure is set when memUsed rises above memPressure, and is
+	// cleared when it falls below memMin.
+	underMemPressure atomicbitops.Bool
+
 	// probe, if not nil, will be invoked any time an endpoint receives a
 	// TCP segment.
 	//
@@ -177,6 +193,7 @@ func (p *protocol) HandleUnknownDestinationPacket(id stack.TransportEndpointID,
 	if !s.flags.Contains(header.TCPFlagRst) {
 		replyWithReset(p.stack, s, stack.DefaultTOS, tcpip.UseDefaultIPv4TTL, tcpip.UseDefaultIPv6HopLimit)
 	}
+	p.stack.RecordDrop(pkt.NICID, tcpip.DropReasonNoListener, pkt)
 
 	return stack.UnknownDestinationPacketHandled
 }
@@ -316,6 +333,17 @@ func (p *protocol) SetOption(option tcpip.SettableTransportProtocolOption) tcpip
 		p.mu.Unlock()
 		return nil
 
+	case *tcpip.TCPMemoryLimitsOption:
+		if v.Min < 0 || v.Pressure < v.Min || v.Max < v.Pressure {
+			return &tcpip.ErrInvalidOptionValue{}
+		}
+		p.mu.Lock()
+		p.memMin.Store(int64(v.Min))
+		p.memPressure.Store(int64(v.Pressure))
+		p.memMax.Store(int64(v.Max))
+		p.mu.Unlock()
+		return nil
+
 	case *tcpip.TCPLingerTimeoutOption:
 		p.mu.Lock()
 		if *v < 0 {
@@ -446,6 +474,20 @@ func (p *protocol) Option(option tcpip.GettableTransportProtocolOption) tcpip.Er
 		p.mu.RUnlock()
 		return nil
 
+	case *tcpip.TCPMemoryLimitsOption:
+		p.mu.RLock()
+		*v = tcpip.TCPMemoryLimitsOption{
+			Min:      int(p.memMin.Load()),
+			Pressure: int(p.memPressure.Load()),
+			Max:      int(p.memMax.Load()),
+		}
+		p.mu.RUnlock()
+		return nil
+
+	case *tcpip.TCPMemoryUsedOption:
+		*v = tcpip.TCPMemoryUsedOption(p.memUsed.Load())
+		return nil
+
 	case *tcpip.TCPLingerTimeoutOption:
 		p.mu.RLock()
 		*v = tcpip.TCPLingerTimeoutOption(p.lingerTimeout)
@@ -603,6 +645,35 @@ func newProtocol(s *stack.Stack, cc string, probe TCPProbeFunc) stack.TransportP
 	return &p
 }
 
+// updateMemUsed adds delta to the memory held by all endpoints and updates the
+// memory pressure state.
+func (p *protocol) updateMemUsed(delta int) {
+	used := p.memUsed.Add(int64(delta))
+	if p.memMax.Load() == 0 {
+		return
+	}
+	if p.underMemPressure.Load() {
+		if used < p.memMin.Load() {
+			p.underMemPressure.Store(false)
+		}
+		return
+	}
+	if used > p.memPressure.Load() && p.underMemPressure.CompareAndSwap(false, true) {
+		p.stack.Stats().TCP.MemoryPressures.Increment()
+	}
+}
+
+// memoryPressure returns true if endpoints must not grow their buffers.
+func (p *protocol) memoryPressure() bool {
+	return p.underMemPressure.Load()
+}
+
+// memoryLimitExceeded returns true if endpoints must not accept more data.
+func (p *protocol) memoryLimitExceeded() bool {
+	max := p.memMax.Load()
+	return max != 0 && p.memUsed.Load() > max
+}
+
 // protocolFromStack retrieves the tcp.protocol instance from stack s.
 func protocolFromStack(s *stack.Stack) *protocol {
 	return s.TransportProtocolInstance(ProtocolNumber).(*protocol)
@@ -19,10 +19,13 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 	"unsafe"
 
+	"gvisor.dev/gvisor/pkg/buffer"
 	"gvisor.dev/gvisor/pkg/state"
 	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/checker"
 	"gvisor.dev/gvisor/pkg/tcpip/header"
 	"gvisor.dev/gvisor/pkg/tcpip/link/channel"
 	"gvisor.dev/gvisor/pkg/tcpip/network/ipv4"
@@ -173,3 +176,94 @@ func TestTCPMigration(t *testing.T) {
 		})
 	}
 }
+
+func TestTCPRestoreRetransmitsUnacknowledgedData(t *testing.T) {
+	c := testcontext.New(t, e2e.DefaultMTU)
+	defer c.Cleanup()
+
+	c.CreateConnected(testcontext.TestInitialSequenceNumber, 30000, -1 /* epRcvBuf */)
+
+	// Send data that the peer never acknowledges.
+	data := []byte{1, 2, 3}
+	var r bytes.Reader
+	r.Reset(data)
+	if _, err := c.EP.Write(&r, tcpip.WriteOptions{}); err != nil {
+		t.Fatalf("Write failed: %s", err)
+	}
+	c.ReceiveAndCheckPacket(data, 0, len(data))
+
+	// Save the stack.
+	var buf bytes.Buffer
+	if _, err := state.Save(context.Background(), &buf, c.Stack()); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Restore the stack with the same NIC configuration.
+	restoredStack := stack.New(stack.Options{
+		TransportProtocols: []stack.TransportProtocolFactory{tcp.NewProtocol},
+		NetworkProtocols:   []stack.NetworkProtocolFactory{ipv4.NewProtocol, ipv6.NewProtocol},
+	})
+	defer restoredStack.Destroy()
+	if _, err := state.Load(context.Background(), bytes.NewReader(buf.Bytes()), restoredStack); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	ep := channel.New(1000, e2e.DefaultMTU, "")
+	defer ep.Close()
+	if err := restoredStack.CreateNIC(1, ep); err != nil {
+		t.Fatalf("CreateNIC failed: %v", err)
+	}
+	if err := restoredStack.AddProtocolAddress(1, tcpip.ProtocolAddress{
+		Protocol:          header.IPv4ProtocolNumber,
+		AddressWithPrefix: testcontext.StackAddrWithPrefix,
+	}, stack.AddressProperties{}); err != nil {
+		t.Fatalf("AddProtocolAddress failed: %v", err)
+	}
+	restoredStack.SetRouteTable([]tcpip.Route{
+		{
+			Destination: header.IPv4EmptySubnet,
+			NIC:         1,
+		},
+	})
+	restoredStack.Restore()
+
+	readPacket := func() *buffer.View {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		pkt := ep.ReadContext(ctx)
+		if pkt == nil {
+			t.Fatalf("Packet wasn't written out")
+		}
+		defer pkt.DecRef()
+		return pkt.ToView()
+	}
+
+	// The restored connection first sends a window probe, which makes the
+	// peer acknowledge what it has received.
+	probe := readPacket()
+	defer probe.Release()
+	checker.IPv4(t, probe,
+		checker.PayloadLen(header.TCPMinimumSize),
+		checker.TCP(
+			checker.DstPort(testcontext.TestPort),
+			checker.TCPSeqNum(uint32(c.IRS)),
+			checker.TCPFlags(header.TCPFlagAck),
+		),
+	)
+
+	// Without an acknowledgement, the retransmission timer resends the
+	// data.
+	retransmit := readPacket()
+	defer retransmit.Release()
+	checker.IPv4(t, retransmit,
+		checker.PayloadLen(len(data)+header.TCPMinimumSize),
+		checker.TCP(
+			checker.DstPort(testcontext.TestPort),
+			checker.TCPSeqNum(uint32(c.IRS)+1),
+			checker.TCPFlagsMatch(header.TCPFlagAck, ^header.TCPFlagPsh),
+		),
+	)
+	if p := retransmit.AsSlice()[header.IPv4MinimumSize+header.TCPMinimumSize:]; !bytes.Equal(data, p) {
+		t.Fatalf("Retransmitted data is different: got %v, want %v", p, data)
+	}
+}
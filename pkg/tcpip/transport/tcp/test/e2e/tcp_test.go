@@ -5864,6 +5864,117 @@ func TestStackSetCongestionControl(t *testing.T) {
 	}
 }
 
+func TestStackSetTCPMemoryLimits(t *testing.T) {
+	testCases := []struct {
+		limits tcpip.TCPMemoryLimitsOption
+		err    tcpip.Error
+	}{
+		{tcpip.TCPMemoryLimitsOption{}, nil},
+		{tcpip.TCPMemoryLimitsOption{Min: 1 << 20, Pressure: 2 << 20, Max: 3 << 20}, nil},
+		{tcpip.TCPMemoryLimitsOption{Min: -1, Pressure: 2 << 20, Max: 3 << 20}, &tcpip.ErrInvalidOptionValue{}},
+		{tcpip.TCPMemoryLimitsOption{Min: 2 << 20, Pressure: 1 << 20, Max: 3 << 20}, &tcpip.ErrInvalidOptionValue{}},
+		{tcpip.TCPMemoryLimitsOption{Min: 1 << 20, Pressure: 3 << 20, Max: 2 << 20}, &tcpip.ErrInvalidOptionValue{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("SetTransportProtocolOption(.., %+v)", tc.limits), func(t *testing.T) {
+			c := context.New(t, 1500)
+			defer c.Cleanup()
+
+			s := c.Stack()
+
+			var oldLimits tcpip.TCPMemoryLimitsOption
+			if err := s.TransportProtocolOption(tcp.ProtocolNumber, &oldLimits); err != nil {
+				t.Fatalf("s.TransportProtocolOption(%v, %v) = %s", tcp.ProtocolNumber, &oldLimits, err)
+			}
+
+			if err := s.SetTransportProtocolOption(tcp.ProtocolNumber, &tc.limits); err != tc.err {
+				t.Fatalf("s.SetTransportProtocolOption(%d, &%T(%+v)) = %s, want = %s", tcp.ProtocolNumber, tc.limits, tc.limits, err, tc.err)
+			}
+
+			var limits tcpip.TCPMemoryLimitsOption
+			if err := s.TransportProtocolOption(tcp.ProtocolNumber, &limits); err != nil {
+				t.Fatalf("s.TransportProtocolOption(%v, %v) = %v", tcp.ProtocolNumber, &limits, err)
+			}
+
+			want := oldLimits
+			if tc.err == nil {
+				want = tc.limits
+			}
+			if limits != want {
+				t.Fatalf("got memory limits: %+v, want: %+v", limits, want)
+			}
+		})
+	}
+}
+
+// TestTCPMemoryLimitDropsData verifies that data segments are dropped once the
+// memory used by all endpoints exceeds the stack wide limit.
+func TestTCPMemoryLimitDropsData(t *testing.T) {
+	c := context.New(t, e2e.DefaultMTU)
+	defer c.Cleanup()
+
+	s := c.Stack()
+	// Any received data is enough to exceed these limits.
+	limits := tcpip.TCPMemoryLimitsOption{Min: 1, Pressure: 1, Max: 1}
+	if err := s.SetTransportProtocolOption(tcp.ProtocolNumber, &limits); err != nil {
+		t.Fatalf("s.SetTransportProtocolOption(%d, &%T(%+v)) = %s", tcp.ProtocolNumber, limits, limits, err)
+	}
+
+	c.CreateConnected(context.TestInitialSequenceNumber, 30000, -1 /* epRcvBuf */)
+
+	we, ch := waiter.NewChannelEntry(waiter.ReadableEvents)
+	c.WQ.EventRegister(&we)
+	defer c.WQ.EventUnregister(&we)
+
+	data := []byte{1, 2, 3}
+	iss := seqnum.Value(context.TestInitialSequenceNumber).Add(1)
+	c.SendPacket(data, &context.Headers{
+		SrcPort: context.TestPort,
+		DstPort: c.Port,
+		Flags:   header.TCPFlagAck,
+		SeqNum:  iss,
+		AckNum:  c.IRS.Add(1),
+		RcvWnd:  30000,
+	})
+
+	// Wait for receive to be notified.
+	select {
+	case <-ch:
+	case <-time.After(1 * time.Second):
+		t.Fatalf("Timed out waiting for data to arrive")
+	}
+
+	var used tcpip.TCPMemoryUsedOption
+	if err := s.TransportProtocolOption(tcp.ProtocolNumber, &used); err != nil {
+		t.Fatalf("s.TransportProtocolOption(%v, %v) = %s", tcp.ProtocolNumber, &used, err)
+	}
+	if used <= 0 {
+		t.Fatalf("got memory used = %d, want > 0", used)
+	}
+	if got, want := s.Stats().TCP.MemoryPressures.Value(), uint64(1); got != want {
+		t.Errorf("got stats.TCP.MemoryPressures.Value() = %d, want = %d", got, want)
+	}
+
+	// The next segment must be dropped.
+	c.SendPacket(data, &context.Headers{
+		SrcPort: context.TestPort,
+		DstPort: c.Port,
+		Flags:   header.TCPFlagAck,
+		SeqNum:  iss.Add(seqnum.Size(len(data))),
+		AckNum:  c.IRS.Add(1),
+		RcvWnd:  30000,
+	})
+	if got, want := s.Stats().TCP.MemoryLimitDrops.Value(), uint64(1); got != want {
+		t.Errorf("got stats.TCP.MemoryLimitDrops.Value() = %d, want = %d", got, want)
+	}
+
+	ept := endpointTester{c.EP}
+	if v := ept.CheckRead(t); !bytes.Equal(data, v) {
+		t.Fatalf("got data = %v, want = %v", v, data)
+	}
+}
+
 func TestStackAvailableCongestionControl(t *testing.T) {
 	c := context.New(t, 1500)
 	defer c.Cleanup()
@@ -112,6 +112,7 @@ func newEndpoint(s *stack.Stack, netProto tcpip.NetworkProtocolNumber, waiterQue
 	}
 	e.ops.InitHandler(e, e.stack, tcpip.GetStackSendBufferLimits, tcpip.GetStackReceiveBufferLimits)
 	e.ops.SetMulticastLoop(true)
+	e.ops.SetIPv6MulticastLoop(true)
 	e.ops.SetSendBufferSize(32*1024, false /* notify */)
 	e.ops.SetReceiveBufferSize(32*1024, false /* notify */)
 	e.net.Init(s, netProto, header.UDPProtocolNumber, &e.ops, waiterQueue)
@@ -412,6 +413,10 @@ func (e *endpoint) prepareForWrite(p tcpip.Payloader, opts tcpip.WriteOptions) (
 		return udpPacketInfo{}, &tcpip.ErrDestinationRequired{}
 	}
 
+	if e.stack.HasNetworkPolicies() && !e.stack.AllowNetworkPolicyEgress(e.net.Owner(), ProtocolNumber, dst.Addr, dst.Port) {
+		return udpPacketInfo{}, &tcpip.ErrNotPermitted{}
+	}
+
 	ctx, err := e.net.AcquireContextForWrite(opts)
 	if err != nil {
 		return udpPacketInfo{}, err
@@ -915,12 +920,20 @@ func (e *endpoint) HandlePacket(id stack.TransportEndpointID, pkt *stack.PacketB
 		// Malformed packet.
 		e.stack.Stats().UDP.MalformedPacketsReceived.Increment()
 		e.stats.ReceiveErrors.MalformedPacketsReceived.Increment()
+		e.stack.RecordDrop(pkt.NICID, tcpip.DropReasonMalformed, pkt)
 		return
 	}
 
 	if !csumValid {
 		e.stack.Stats().UDP.ChecksumErrors.Increment()
 		e.stats.ReceiveErrors.ChecksumErrors.Increment()
+		e.stack.RecordDrop(pkt.NICID, tcpip.DropReasonChecksum, pkt)
+		return
+	}
+
+	if e.stack.HasNetworkPolicies() && !e.stack.AllowNetworkPolicyIngress(e.net.Owner(), ProtocolNumber, id.RemoteAddress, id.LocalPort) {
+		e.stack.Stats().DroppedPackets.Increment()
+		e.stack.RecordDrop(pkt.NICID, tcpip.DropReasonNetworkPolicy, pkt)
 		return
 	}
 
@@ -942,6 +955,7 @@ func (e *endpoint) HandlePacket(id stack.TransportEndpointID, pkt *stack.PacketB
 		e.rcvMu.Unlock()
 		e.stack.Stats().UDP.ReceiveBufferErrors.Increment()
 		e.stats.ReceiveErrors.ReceiveBufferOverflow.Increment()
+		e.stack.RecordDrop(pkt.NICID, tcpip.DropReasonReceiveBufferFull, pkt)
 		return
 	}
 
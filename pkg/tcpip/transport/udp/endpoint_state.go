@@ -20,7 +20,10 @@ import (
 
 	"gvisor.dev/gvisor/pkg/log"
 	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+	"gvisor.dev/gvisor/pkg/tcpip/ports"
 	"gvisor.dev/gvisor/pkg/tcpip/stack"
+	"gvisor.dev/gvisor/pkg/tcpip/transport"
 )
 
 // saveReceivedAt is invoked by stateify.
@@ -49,6 +52,7 @@ func (e *endpoint) Restore(s *stack.Stack) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	e.remapLocalAddressAtRestore()
 	if err := e.net.Resume(s); err != nil {
 		log.Warningf("Closing the UDP endpoint as it cannot be restored, err: %v", err)
 		e.closeLocked()
@@ -60,6 +64,63 @@ func (e *endpoint) Restore(s *stack.Stack) {
 	e.ops.InitHandler(e, e.stack, tcpip.GetStackSendBufferLimits, tcpip.GetStackReceiveBufferLimits)
 }
 
+// remapLocalAddressAtRestore moves a bound or connected endpoint whose local
+// address was replaced at restore to the new address.
+//
+// +checklocks:e.mu
+func (e *endpoint) remapLocalAddressAtRestore() {
+	switch e.net.State() {
+	case transport.DatagramEndpointStateBound, transport.DatagramEndpointStateConnected:
+	default:
+		return
+	}
+
+	id := e.net.Info().ID
+	id.LocalPort = e.localPort
+	id.RemotePort = e.remotePort
+	netProto := header.IPv6ProtocolNumber
+	if id.LocalAddress.BitLen() == header.IPv4AddressSizeBits {
+		netProto = header.IPv4ProtocolNumber
+	}
+	to, ok := e.stack.RemapRestoredAddress(netProto, id.LocalAddress)
+	if !ok {
+		return
+	}
+	nic := e.stack.CheckLocalAddress(0, netProto, to)
+	if nic == 0 {
+		log.Warningf("Cannot remap UDP endpoint %+v to %s: address isn't assigned", id, to)
+		return
+	}
+
+	newID := id
+	newID.LocalAddress = to
+	portRes := ports.Reservation{
+		Networks:     e.effectiveNetProtos,
+		Transport:    ProtocolNumber,
+		Addr:         to,
+		Port:         id.LocalPort,
+		Flags:        e.boundPortFlags,
+		BindToDevice: e.boundBindToDevice,
+		Dest:         tcpip.FullAddress{},
+	}
+	if _, err := e.stack.ReservePort(e.stack.SecureRNG(), portRes, nil /* testPort */); err != nil {
+		log.Warningf("Cannot remap UDP endpoint %+v to %s: %v", id, to, err)
+		return
+	}
+	e.stack.UnregisterTransportEndpoint(e.effectiveNetProtos, ProtocolNumber, id, e, e.boundPortFlags, e.boundBindToDevice)
+	if err := e.stack.RegisterTransportEndpoint(e.effectiveNetProtos, ProtocolNumber, newID, e, e.boundPortFlags, e.boundBindToDevice); err != nil {
+		log.Warningf("Cannot remap UDP endpoint %+v to %s: %v", id, to, err)
+		_ = e.stack.RegisterTransportEndpoint(e.effectiveNetProtos, ProtocolNumber, id, e, e.boundPortFlags, e.boundBindToDevice)
+		e.stack.ReleasePort(portRes)
+		return
+	}
+	portRes.Addr = id.LocalAddress
+	e.stack.ReleasePort(portRes)
+
+	e.net.RemapLocalAddress(id.LocalAddress, to, nic)
+	log.Infof("Remapped UDP endpoint %+v to %s", id, to)
+}
+
 // Resume implements tcpip.ResumableEndpoint.Resume.
 func (e *endpoint) Resume() {
 	e.thaw()
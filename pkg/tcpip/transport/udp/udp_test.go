@@ -1239,8 +1239,22 @@ func TestSetMulticastTTL(t *testing.T) {
 
 						c.CreateEndpointForFlow(flow, udp.ProtocolNumber)
 
-						if err := c.EP.SetSockOptInt(tcpip.MulticastTTLOption, int(wantTTL)); err != nil {
-							c.T.Fatalf("SetSockOptInt failed: %s", err)
+						var relevantOpt tcpip.SockOptInt
+						var irrelevantOpt tcpip.SockOptInt
+						if flow.IsV4() {
+							relevantOpt = tcpip.MulticastTTLOption
+							irrelevantOpt = tcpip.IPv6MulticastHopLimitOption
+						} else {
+							relevantOpt = tcpip.IPv6MulticastHopLimitOption
+							irrelevantOpt = tcpip.MulticastTTLOption
+						}
+						if err := c.EP.SetSockOptInt(relevantOpt, int(wantTTL)); err != nil {
+							c.T.Fatalf("SetSockOptInt(%d, %d) failed: %s", relevantOpt, wantTTL, err)
+						}
+						// Set a different ttl/hoplimit for the unused protocol, showing that
+						// it does not affect the other protocol.
+						if err := c.EP.SetSockOptInt(irrelevantOpt, int(wantTTL+1)); err != nil {
+							c.T.Fatalf("SetSockOptInt(%d, %d) failed: %s", irrelevantOpt, wantTTL, err)
 						}
 
 						testWriteOpSequenceSucceeds(c, flow, writeOpSequence, checker.TTL(wantTTL))
@@ -395,6 +395,18 @@ func (s *Socket) GetPeerName() ([]byte, error) {
 	}
 }
 
+// GetPeerCred returns the credentials of the peer process at the time it
+// connected, as reported by SO_PEERCRED.
+func (s *Socket) GetPeerCred() (*unix.Ucred, error) {
+	fd, ok := s.enterFD()
+	if !ok {
+		return nil, unix.EBADF
+	}
+	defer s.gate.Leave()
+
+	return unix.GetsockoptUcred(fd, unix.SOL_SOCKET, unix.SO_PEERCRED)
+}
+
 // SocketReader wraps an individual receive operation.
 //
 // This may be used for doing vectorized reads and/or sending additional
@@ -405,6 +417,7 @@ type SocketReader struct {
 	socket   *Socket
 	source   []byte
 	blocking bool
+	peek     bool
 	race     *atomicbitops.Int32
 
 	ControlMessage
@@ -421,6 +434,14 @@ func (s *Socket) Read(p []byte) (int, error) {
 	return r.ReadVec([][]byte{p})
 }
 
+// Peek blocks until data is available and copies it into p without removing it
+// from the socket's receive queue. A subsequent Read returns the same data.
+func (s *Socket) Peek(p []byte) (int, error) {
+	r := s.Reader(true)
+	r.peek = true
+	return r.ReadVec([][]byte{p})
+}
+
 func (s *Socket) shutdown(fd int) error {
 	// Shutdown the socket to cancel any pending accepts.
 	return unix.Shutdown(fd, unix.SHUT_RDWR)
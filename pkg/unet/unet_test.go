@@ -200,6 +200,50 @@ func TestSendRecv(t *testing.T) {
 	}
 }
 
+func TestPeek(t *testing.T) {
+	server, client := socketPair(t, false)
+	defer server.Close()
+	defer client.Close()
+
+	if n, err := client.Write([]byte("ab")); n != 2 || err != nil {
+		t.Fatalf("For client write, got n=%d err=%v, expected n=2 err=nil", n, err)
+	}
+
+	p := make([]byte, 1)
+	if n, err := server.Peek(p); n != 1 || err != nil {
+		t.Fatalf("For server peek, got n=%d err=%v, expected n=1 err=nil", n, err)
+	}
+	if p[0] != 'a' {
+		t.Fatalf("Got bad peek data, got %c, expected a", p[0])
+	}
+
+	// The peeked data must still be readable.
+	b := make([]byte, 2)
+	if n, err := server.Read(b); n != 2 || err != nil {
+		t.Fatalf("For server read, got n=%d err=%v, expected n=2 err=nil", n, err)
+	}
+	if string(b) != "ab" {
+		t.Fatalf("Got bad read data, got %q, expected \"ab\"", b)
+	}
+}
+
+func TestGetPeerCred(t *testing.T) {
+	server, client := socketPair(t, false)
+	defer server.Close()
+	defer client.Close()
+
+	cred, err := server.GetPeerCred()
+	if err != nil {
+		t.Fatalf("GetPeerCred failed: %v", err)
+	}
+	if got, want := int(cred.Pid), os.Getpid(); got != want {
+		t.Errorf("Got peer pid %d, expected %d", got, want)
+	}
+	if got, want := int(cred.Uid), os.Getuid(); got != want {
+		t.Errorf("Got peer uid %d, expected %d", got, want)
+	}
+}
+
 // TestSymmetric exists to assert that the two sockets received from socketPair
 // are interchangeable. They should be, this just provides a basic sanity check
 // by running TestSendRecv "backwards".
@@ -112,6 +112,11 @@ func (r *SocketReader) ReadVec(bufs [][]byte) (int, error) {
 	// n is the bytes received.
 	var n uintptr
 
+	flags := uintptr(unix.MSG_DONTWAIT | unix.MSG_TRUNC)
+	if r.peek {
+		flags |= unix.MSG_PEEK
+	}
+
 	fd, ok := r.socket.enterFD()
 	if !ok {
 		return 0, unix.EBADF
@@ -121,7 +126,7 @@ func (r *SocketReader) ReadVec(bufs [][]byte) (int, error) {
 		var e unix.Errno
 
 		// Try a non-blocking recv first, so we don't give up the go runtime M.
-		n, _, e = unix.RawSyscall(unix.SYS_RECVMSG, uintptr(fd), uintptr(unsafe.Pointer(&msg)), unix.MSG_DONTWAIT|unix.MSG_TRUNC)
+		n, _, e = unix.RawSyscall(unix.SYS_RECVMSG, uintptr(fd), uintptr(unsafe.Pointer(&msg)), flags)
 		if e == 0 {
 			break
 		}
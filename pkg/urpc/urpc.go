@@ -27,6 +27,7 @@ import (
 	"os"
 	"reflect"
 	"runtime"
+	"sort"
 	"time"
 
 	"gvisor.dev/gvisor/pkg/fd"
@@ -45,8 +46,9 @@ var ErrTooManyFiles = errors.New("too many files")
 // ErrUnknownMethod is returned when a method is not known.
 var ErrUnknownMethod = errors.New("unknown method")
 
-// errStopped is an internal error indicating the server has been stopped.
-var errStopped = errors.New("stopped")
+// ErrStopped is returned by Server.Invoke once the server has been stopped. It
+// is also used internally to stop handling clients.
+var ErrStopped = errors.New("stopped")
 
 // RemoteError is an error returned by the remote invocation.
 //
@@ -177,9 +179,12 @@ type Server struct {
 	// clients is a map of clients.
 	clients map[*unet.Socket]clientState
 
-	// wg is a wait group for all outstanding clients.
+	// wg is a wait group for all outstanding clients and in-process calls.
 	wg sync.WaitGroup
 
+	// stopped is set by Stop. In-process calls are rejected once it is set.
+	stopped bool
+
 	// afterRPCCallback is called after each RPC is successfully completed.
 	afterRPCCallback func()
 }
@@ -199,6 +204,10 @@ func NewServerWithCallback(afterRPCCallback func()) *Server {
 	}
 }
 
+// AuthorizeFunc decides whether a client may call the given method. A non-nil
+// error is returned to the client instead of calling the method.
+type AuthorizeFunc func(method string) error
+
 // Stopper is an optional interface, that when implemented, allows an object
 // to have a callback executed when the server is shutting down.
 type Stopper interface {
@@ -281,6 +290,30 @@ func (s *Server) Register(obj any) {
 	}
 }
 
+// Methods returns the sorted names of all registered methods, in the form
+// "Type.Method".
+func (s *Server) Methods() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.methods))
+	for name := range s.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MethodTypes returns the types of the argument and result of the given
+// registered method, i.e. the types that the method's pointer arguments point
+// to.
+func (s *Server) MethodTypes(method string) (arg, result reflect.Type, ok bool) {
+	rm, ok := s.lookup(method)
+	if !ok {
+		return nil, nil, false
+	}
+	return rm.argType.Elem(), rm.resultType.Elem(), true
+}
+
 // lookup looks up the given method.
 func (s *Server) lookup(method string) (registeredMethod, bool) {
 	s.mu.Lock()
@@ -289,8 +322,9 @@ func (s *Server) lookup(method string) (registeredMethod, bool) {
 	return rm, ok
 }
 
-// handleOne handles a single call.
-func (s *Server) handleOne(client *unet.Socket) error {
+// handleOne handles a single call. If authorize is not nil, the call is
+// rejected unless authorize permits it.
+func (s *Server) handleOne(client *unet.Socket, authorize AuthorizeFunc) error {
 	// Unmarshal the call.
 	var c serverCall
 	newFs, err := unmarshal(client, &c)
@@ -314,36 +348,55 @@ func (s *Server) handleOne(client *unet.Socket) error {
 	// Start the request.
 	if !s.clientBeginRequest(client) {
 		// Client is dead; don't process this call.
-		return errStopped
+		return ErrStopped
 	}
 	defer s.clientEndRequest(client)
 
-	// Lookup the method.
-	rm, ok := s.lookup(c.Method)
-	if !ok {
+	if authorize != nil {
+		if err := authorize(c.Method); err != nil {
+			result.Err = err.Error()
+			return marshal(client, &result, nil)
+		}
+	}
+
+	re, fs, err := s.call(c.Method, c.Arg, newFs)
+	if err != nil {
 		// Try to serialize the error.
-		result.Err = ErrUnknownMethod.Error()
+		result.Err = err.Error()
 		return marshal(client, &result, nil)
 	}
 
+	// Marshal the result.
+	result.Success = true
+	result.Result = re
+	return marshal(client, &result, fs)
+}
+
+// call invokes the given method with the JSON-encoded arg and files, and
+// returns the result along with the files in its payload, if any.
+func (s *Server) call(method string, arg json.RawMessage, files []*os.File) (any, []*os.File, error) {
+	// Lookup the method.
+	rm, ok := s.lookup(method)
+	if !ok {
+		return nil, nil, ErrUnknownMethod
+	}
+
 	// Unmarshal the arguments now that we know the type.
 	na := reflect.New(rm.argType.Elem())
-	if err := json.Unmarshal(c.Arg, na.Interface()); err != nil {
-		result.Err = err.Error()
-		return marshal(client, &result, nil)
+	if err := json.Unmarshal(arg, na.Interface()); err != nil {
+		return nil, nil, err
 	}
 
 	// Set the file payload as an argument.
 	if fp, ok := na.Interface().(filePayloader); ok {
-		fp.setFilePayload(newFs)
+		fp.setFilePayload(files)
 	}
 
 	// Call the method.
 	re := reflect.New(rm.resultType.Elem())
 	rValues := rm.fn.Call([]reflect.Value{rm.rcvr, na, re})
 	if errVal := rValues[0].Interface(); errVal != nil {
-		result.Err = errVal.(error).Error()
-		return marshal(client, &result, nil)
+		return nil, nil, errVal.(error)
 	}
 
 	// Set the resulting payload.
@@ -351,16 +404,82 @@ func (s *Server) handleOne(client *unet.Socket) error {
 	if fp, ok := re.Interface().(filePayloader); ok {
 		fs = fp.filePayload()
 		if len(fs) > maxFiles {
-			// Ugh. Send an error to the client, despite success.
-			result.Err = ErrTooManyFiles.Error()
-			return marshal(client, &result, nil)
+			// Ugh. Report an error to the client, despite success.
+			return nil, nil, ErrTooManyFiles
 		}
 	}
+	return re.Interface(), fs, nil
+}
 
-	// Marshal the result.
+// Invoke calls the given registered method in-process, as if it had been
+// received from a client. arg is the JSON encoding of the method's argument
+// and files, if any, are passed as its file payload. Invoke closes files once
+// the method returns.
+//
+// On success, Invoke returns the JSON encoding of the method's result and the
+// files in the result's payload, which the caller must close. Errors returned
+// by the method itself are wrapped in RemoteError, matching Client.Call.
+//
+// Like calls from clients, in-process calls are waited for by Stop, and are
+// rejected with ErrStopped once Stop has been called.
+func (s *Server) Invoke(method string, arg []byte, files []*os.File) ([]byte, []*os.File, error) {
+	defer closeAll(files)
+	if len(arg) == 0 {
+		arg = []byte("null")
+	}
+	c := serverCall{Method: method, Arg: arg}
+	var result callResult
+	log.Debugf("urpc: handling in-process call for method %s", method)
+
+	// Start the request.
+	if !s.invokeBeginRequest() {
+		result.Err = ErrStopped.Error()
+		logRequest(c, &result)
+		return nil, nil, ErrStopped
+	}
+	defer s.invokeEndRequest()
+	defer logRequest(c, &result)
+	if s.afterRPCCallback != nil {
+		defer s.afterRPCCallback()
+	}
+
+	re, fs, err := s.call(method, arg, files)
+	if err != nil {
+		result.Err = err.Error()
+		if err == ErrUnknownMethod || err == ErrTooManyFiles {
+			return nil, nil, err
+		}
+		return nil, nil, RemoteError{Message: err.Error()}
+	}
+	data, err := json.Marshal(re)
+	if err != nil {
+		closeAll(fs)
+		result.Err = err.Error()
+		return nil, nil, err
+	}
 	result.Success = true
-	result.Result = re.Interface()
-	return marshal(client, &result, fs)
+	return data, fs, nil
+}
+
+// invokeBeginRequest begins an in-process request.
+//
+// If true is returned, the request may be processed. If false is returned,
+// then the server has been stopped and the request should be skipped.
+func (s *Server) invokeBeginRequest() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return false
+	}
+	// Stop sets stopped before waiting on wg, so this never races with
+	// wg.Wait.
+	s.wg.Add(1)
+	return true
+}
+
+// invokeEndRequest ends an in-process request.
+func (s *Server) invokeEndRequest() {
+	s.wg.Done()
 }
 
 func logRequest(c serverCall, result *callResult) {
@@ -448,10 +567,10 @@ func (s *Server) clientUnregister(client *unet.Socket) {
 }
 
 // handleRegistered handles calls from a registered client.
-func (s *Server) handleRegistered(client *unet.Socket) error {
+func (s *Server) handleRegistered(client *unet.Socket, authorize AuthorizeFunc) error {
 	for {
 		// Handle one call.
-		if err := s.handleOne(client); err != nil {
+		if err := s.handleOne(client, authorize); err != nil {
 			// Client is dead.
 			return err
 		}
@@ -462,16 +581,22 @@ func (s *Server) handleRegistered(client *unet.Socket) error {
 func (s *Server) Handle(client *unet.Socket) error {
 	s.clientRegister(client)
 	defer s.clientUnregister(client)
-	return s.handleRegistered(client)
+	return s.handleRegistered(client, nil)
 }
 
 // StartHandling creates a goroutine that handles a single client over a
 // connection.
 func (s *Server) StartHandling(client *unet.Socket) {
+	s.StartHandlingWithAuthorizer(client, nil)
+}
+
+// StartHandlingWithAuthorizer is like StartHandling, but each call made by the
+// client is first checked with authorize. A nil authorize permits all calls.
+func (s *Server) StartHandlingWithAuthorizer(client *unet.Socket, authorize AuthorizeFunc) {
 	s.clientRegister(client)
 	go func() { // S/R-SAFE: out of scope
 		defer s.clientUnregister(client)
-		s.handleRegistered(client)
+		s.handleRegistered(client, authorize)
 	}()
 }
 
@@ -486,6 +611,11 @@ func (s *Server) StartHandling(client *unet.Socket) {
 // complete) and closed. Any new RPCs will not be processed. Note that ongoing
 // RPCs are *not* interrupted or cancelled.
 func (s *Server) Stop(timeout time.Duration) {
+	// Reject new in-process calls.
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+
 	// Call any Stop callbacks.
 	for _, stopper := range s.stoppers {
 		stopper.Stop()
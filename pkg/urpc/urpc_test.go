@@ -15,8 +15,11 @@
 package urpc
 
 import (
+	"encoding/json"
 	"errors"
 	"os"
+	"reflect"
+	"sort"
 	"testing"
 
 	"gvisor.dev/gvisor/pkg/unet"
@@ -108,6 +111,97 @@ func TestCall(t *testing.T) {
 	}
 }
 
+func TestMethods(t *testing.T) {
+	s := NewServer()
+	s.Register(test{})
+
+	methods := s.Methods()
+	if !sort.StringsAreSorted(methods) {
+		t.Errorf("methods not sorted: %v", methods)
+	}
+	found := false
+	for _, m := range methods {
+		if m == "test.Func" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("test.Func not found in %v", methods)
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	s := NewServer()
+	s.Register(test{})
+
+	data, _, err := s.Invoke("test.Func", []byte(`{"StringArg": "hello", "IntArg": 1}`), nil)
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	var r testResult
+	if err := json.Unmarshal(data, &r); err != nil {
+		t.Fatalf("error unmarshalling result %q: %v", data, err)
+	}
+	if r.StringResult != "hello" || r.IntResult != 1 {
+		t.Errorf("unexpected result, got %+v expected hello and 1", r)
+	}
+
+	if _, _, err := s.Invoke("test.Err", nil, nil); err == nil || err.Error() != "test error" {
+		t.Errorf("expected test error, got %v", err)
+	}
+	if _, _, err := s.Invoke("test.Unknown", nil, nil); err != ErrUnknownMethod {
+		t.Errorf("expected %v, got %v", ErrUnknownMethod, err)
+	}
+
+	s.Stop(0)
+	if _, _, err := s.Invoke("test.Func", []byte(`{"StringArg": "hello"}`), nil); err != ErrStopped {
+		t.Errorf("expected %v after Stop, got %v", ErrStopped, err)
+	}
+}
+
+func TestMethodTypes(t *testing.T) {
+	s := NewServer()
+	s.Register(test{})
+
+	arg, result, ok := s.MethodTypes("test.Func")
+	if !ok {
+		t.Fatalf("test.Func not found")
+	}
+	if arg != reflect.TypeFor[testArg]() || result != reflect.TypeFor[testResult]() {
+		t.Errorf("got types (%v, %v), want (testArg, testResult)", arg, result)
+	}
+	if _, _, ok := s.MethodTypes("test.Unknown"); ok {
+		t.Errorf("test.Unknown found")
+	}
+}
+
+func TestAuthorizer(t *testing.T) {
+	serverSock, clientSock, err := unet.SocketPair(false)
+	if err != nil {
+		t.Fatalf("error creating socket pair: %v", err)
+	}
+	s := NewServer()
+	s.Register(test{})
+	s.StartHandlingWithAuthorizer(serverSock, func(method string) error {
+		if method != "test.Func" {
+			return errors.New("denied")
+		}
+		return nil
+	})
+	c := NewClient(clientSock)
+	defer c.Close()
+
+	var r testResult
+	if err := c.Call("test.Func", &testArg{StringArg: "hello"}, &r); err != nil {
+		t.Errorf("authorized call failed: %v", err)
+	} else if r.StringResult != "hello" {
+		t.Errorf("unexpected result, got %v expected hello", r.StringResult)
+	}
+	if err := c.Call("test.Err", &testArg{}, &r); err == nil || err.Error() != "denied" {
+		t.Errorf("expected denied, got %v", err)
+	}
+}
+
 func TestUnknownMethod(t *testing.T) {
 	c, err := testClient()
 	if err != nil {
@@ -0,0 +1,232 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+
+	"gvisor.dev/gvisor/pkg/cpuid"
+	"gvisor.dev/gvisor/pkg/sentry/state"
+	"gvisor.dev/gvisor/runsc/specutils"
+)
+
+const (
+	// ArchKey is the key used to save the host architecture in the save
+	// metadata.
+	ArchKey = "arch"
+
+	// PlatformKey is the key used to save the platform in the save metadata.
+	PlatformKey = "platform"
+
+	// CPUFeaturesKey is the key used to save the CPU features exposed to the
+	// sandbox, as a comma-separated list, in the save metadata.
+	CPUFeaturesKey = "cpu_features"
+
+	// NvidiaDriverVersionKey is the key used to save the NVIDIA driver version
+	// used by nvproxy in the save metadata. It is absent if nvproxy is
+	// disabled.
+	NvidiaDriverVersionKey = "nvidia_driver_version"
+
+	// PacketFilterKey is the key used to save the name of the external packet
+	// filter, or the empty string if none is used, in the save metadata.
+	PacketFilterKey = "packet_filter"
+)
+
+// RestoreHost describes the host and runsc configuration on which a
+// checkpoint is to be restored.
+type RestoreHost struct {
+	// RunscVersion is the version of runsc.
+	RunscVersion string
+
+	// Arch is the host architecture, as in runtime.GOARCH.
+	Arch string
+
+	// Platform is the platform that would be used for restore.
+	Platform string
+
+	// CPUFeatures are the host's CPU features.
+	CPUFeatures cpuid.FeatureSet
+
+	// NvidiaDriverVersion is the NVIDIA driver version that nvproxy would use
+	// for restore, or empty if none is available.
+	NvidiaDriverVersion string
+
+	// PacketFilter is the external packet filter that would be used for
+	// restore, or empty if none is configured.
+	PacketFilter string
+}
+
+// CompatibilityIssue is a difference between a checkpoint and the host on
+// which it is to be restored.
+type CompatibilityIssue struct {
+	// Property is the name of the property that differs.
+	Property string `json:"property"`
+
+	// Checkpoint and Host are the values of the property in the checkpoint
+	// and on the host respectively.
+	Checkpoint string `json:"checkpoint"`
+	Host       string `json:"host"`
+
+	// Fatal is true if the difference prevents restore.
+	Fatal bool `json:"fatal"`
+
+	// Reason explains the effect of the difference.
+	Reason string `json:"reason"`
+}
+
+// String implements fmt.Stringer.String.
+func (i *CompatibilityIssue) String() string {
+	return fmt.Sprintf("%s: checkpoint %q, host %q: %s", i.Property, i.Checkpoint, i.Host, i.Reason)
+}
+
+// addCompatibilityMetadata records properties of the sandbox that determine
+// which hosts its checkpoint can be restored on.
+func (l *Loader) addCompatibilityMetadata(m map[string]string) {
+	m[ArchKey] = runtime.GOARCH
+	m[PlatformKey] = l.root.conf.Platform
+	m[CPUFeaturesKey] = featureNames(l.k.FeatureSet())
+	if l.k.NvidiaDriverVersion.Major() > 0 {
+		m[NvidiaDriverVersionKey] = l.k.NvidiaDriverVersion.String()
+	}
+	m[PacketFilterKey] = l.root.conf.PacketFilter
+}
+
+// featureNames returns the names of the CPU features in fs, as a
+// comma-separated list. Features without names are omitted.
+func featureNames(fs cpuid.FeatureSet) string {
+	var names []string
+	for _, f := range cpuid.AllFeatures() {
+		if !fs.HasFeature(f) {
+			continue
+		}
+		if _, ok := cpuid.FeatureFromString(f.String()); ok {
+			names = append(names, f.String())
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// CheckRestoreCompatibility returns the differences between the checkpoint
+// with the given save metadata and the host h. The checkpoint can't be
+// restored on h if any of the returned issues are fatal.
+//
+// Properties that aren't recorded in metadata, because the checkpoint was
+// taken by an older version of runsc, are not checked.
+func CheckRestoreCompatibility(metadata map[string]string, h *RestoreHost) []CompatibilityIssue {
+	var issues []CompatibilityIssue
+
+	if arch, ok := metadata[ArchKey]; ok && arch != h.Arch {
+		issues = append(issues, CompatibilityIssue{
+			Property:   ArchKey,
+			Checkpoint: arch,
+			Host:       h.Arch,
+			Fatal:      true,
+			Reason:     "checkpoints can only be restored on the architecture on which they were taken",
+		})
+	}
+
+	if v := metadata[VersionKey]; v != h.RunscVersion {
+		issue := CompatibilityIssue{
+			Property:   VersionKey,
+			Checkpoint: v,
+			Host:       h.RunscVersion,
+		}
+		if err := state.CheckCrossVersionRestore(metadata); err != nil {
+			issue.Fatal = true
+			issue.Reason = fmt.Sprintf("runsc version does not match: %v", err)
+		} else {
+			issue.Reason = "state schema is compatible"
+		}
+		issues = append(issues, issue)
+	}
+
+	if p, ok := metadata[PlatformKey]; ok && p != h.Platform {
+		issues = append(issues, CompatibilityIssue{
+			Property:   PlatformKey,
+			Checkpoint: p,
+			Host:       h.Platform,
+			Reason:     "checkpoints may be restored on a different platform",
+		})
+	}
+
+	if features, ok := metadata[CPUFeaturesKey]; ok && features != "" {
+		var missing []string
+		for _, name := range strings.Split(features, ",") {
+			f, ok := cpuid.FeatureFromString(name)
+			if !ok || !h.CPUFeatures.HasFeature(f) {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			slices.Sort(missing)
+			issues = append(issues, CompatibilityIssue{
+				Property:   CPUFeaturesKey,
+				Checkpoint: features,
+				Host:       featureNames(h.CPUFeatures),
+				Fatal:      true,
+				Reason: fmt.Sprintf("host is missing CPU features %s; use the %s annotation when creating the sandbox to only expose features available on all restore hosts",
+					strings.Join(missing, ","), specutils.AnnotationCPUFeatures),
+			})
+		}
+	}
+
+	if v, ok := metadata[NvidiaDriverVersionKey]; ok && v != h.NvidiaDriverVersion {
+		host := h.NvidiaDriverVersion
+		if host == "" {
+			host = "none"
+		}
+		issues = append(issues, CompatibilityIssue{
+			Property:   NvidiaDriverVersionKey,
+			Checkpoint: v,
+			Host:       host,
+			Fatal:      true,
+			Reason:     "checkpoints using nvproxy can only be restored with the same NVIDIA driver version",
+		})
+	}
+
+	if f, ok := metadata[PacketFilterKey]; ok && f != h.PacketFilter {
+		issues = append(issues, CompatibilityIssue{
+			Property:   PacketFilterKey,
+			Checkpoint: f,
+			Host:       h.PacketFilter,
+			Fatal:      true,
+			// The packet filter isn't saved. It is reinstalled in the root
+			// network namespace's stack from the restore configuration, but
+			// stacks of other network namespaces are recreated with the
+			// checkpointed configuration.
+			Reason: "checkpoints can only be restored with the same packet filter",
+		})
+	}
+
+	return issues
+}
+
+// compatibilityError returns an error describing the fatal issues in issues,
+// or nil if there are none.
+func compatibilityError(issues []CompatibilityIssue) error {
+	var reasons []string
+	for i := range issues {
+		if issues[i].Fatal {
+			reasons = append(reasons, issues[i].String())
+		}
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+	return fmt.Errorf("checkpoint can't be restored on this host: %s", strings.Join(reasons, "; "))
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"strconv"
+	"testing"
+
+	"gvisor.dev/gvisor/pkg/cpuid"
+	"gvisor.dev/gvisor/pkg/sentry/state"
+	pkgstate "gvisor.dev/gvisor/pkg/state"
+)
+
+func TestCheckRestoreCompatibility(t *testing.T) {
+	host := &RestoreHost{
+		RunscVersion: "release-2",
+		Arch:         "amd64",
+		Platform:     "systrap",
+		CPUFeatures:  cpuid.HostFeatureSet(),
+	}
+	compatible := func() map[string]string {
+		return map[string]string{
+			VersionKey:     "release-2",
+			ArchKey:        "amd64",
+			PlatformKey:    "systrap",
+			CPUFeaturesKey: featureNames(host.CPUFeatures),
+		}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		modify   func(m map[string]string)
+		property string
+		fatal    bool
+	}{
+		{
+			name:   "compatible",
+			modify: func(map[string]string) {},
+		},
+		{
+			name: "old checkpoint",
+			modify: func(m map[string]string) {
+				delete(m, ArchKey)
+				delete(m, PlatformKey)
+				delete(m, CPUFeaturesKey)
+			},
+		},
+		{
+			name:     "arch",
+			modify:   func(m map[string]string) { m[ArchKey] = "arm64" },
+			property: ArchKey,
+			fatal:    true,
+		},
+		{
+			name:     "platform",
+			modify:   func(m map[string]string) { m[PlatformKey] = "kvm" },
+			property: PlatformKey,
+		},
+		{
+			name:     "runsc version without schema",
+			modify:   func(m map[string]string) { m[VersionKey] = "release-1" },
+			property: VersionKey,
+			fatal:    true,
+		},
+		{
+			name: "runsc version with current schema",
+			modify: func(m map[string]string) {
+				m[VersionKey] = "release-1"
+				m[state.SchemaVersionKey] = strconv.FormatUint(uint64(pkgstate.CurrentSchemaVersion()), 10)
+			},
+			property: VersionKey,
+			// Cross-version restore requires registered schema changes.
+			fatal: pkgstate.CurrentSchemaVersion() < state.MinSchemaVersion,
+		},
+		{
+			name: "runsc version with newer schema",
+			modify: func(m map[string]string) {
+				m[VersionKey] = "release-3"
+				m[state.SchemaVersionKey] = "4294967295"
+			},
+			property: VersionKey,
+			fatal:    true,
+		},
+		{
+			name:     "cpu features",
+			modify:   func(m map[string]string) { m[CPUFeaturesKey] += ",no-such-feature" },
+			property: CPUFeaturesKey,
+			fatal:    true,
+		},
+		{
+			name:     "nvidia driver",
+			modify:   func(m map[string]string) { m[NvidiaDriverVersionKey] = "550.54.15" },
+			property: NvidiaDriverVersionKey,
+			fatal:    true,
+		},
+		{
+			name:     "packet filter",
+			modify:   func(m map[string]string) { m[PacketFilterKey] = "policy" },
+			property: PacketFilterKey,
+			fatal:    true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := compatible()
+			tc.modify(m)
+			issues := CheckRestoreCompatibility(m, host)
+			if tc.property == "" {
+				if len(issues) != 0 {
+					t.Fatalf("CheckRestoreCompatibility got issues %+v, want none", issues)
+				}
+				return
+			}
+			if len(issues) != 1 || issues[0].Property != tc.property || issues[0].Fatal != tc.fatal {
+				t.Fatalf("CheckRestoreCompatibility got issues %+v, want one issue for %q with fatal=%t", issues, tc.property, tc.fatal)
+			}
+			if err := compatibilityError(issues); (err != nil) != tc.fatal {
+				t.Errorf("compatibilityError got %v, want error=%t", err, tc.fatal)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"io"
 	"path"
+	"runtime"
 	"strconv"
 	"sync"
 	gtime "time"
@@ -27,7 +28,9 @@ import (
 	"golang.org/x/sys/unix"
 	"gvisor.dev/gvisor/pkg/cleanup"
 	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/control/grpcserver"
 	"gvisor.dev/gvisor/pkg/control/server"
+	"gvisor.dev/gvisor/pkg/cpuid"
 	"gvisor.dev/gvisor/pkg/fd"
 	"gvisor.dev/gvisor/pkg/fspath"
 	"gvisor.dev/gvisor/pkg/log"
@@ -47,15 +50,27 @@ import (
 	"gvisor.dev/gvisor/pkg/urpc"
 	"gvisor.dev/gvisor/runsc/boot/procfs"
 	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/goferauth"
 	"gvisor.dev/gvisor/runsc/specutils"
 	"gvisor.dev/gvisor/runsc/starttime"
 	"gvisor.dev/gvisor/runsc/version"
 )
 
 const (
+	// ContMgrAttachExec attaches a terminal to the session of a detachable
+	// exec'd process.
+	ContMgrAttachExec = "containerManager.AttachExec"
+
+	// ContMgrAttachStdio replaces the destination of relayed stdout and stderr
+	// of a container. Requires --stdio-relay.
+	ContMgrAttachStdio = "containerManager.AttachStdio"
+
 	// ContMgrCheckpoint checkpoints a container.
 	ContMgrCheckpoint = "containerManager.Checkpoint"
 
+	// ContMgrCloseHostFD closes the application FDs that hold a host FD.
+	ContMgrCloseHostFD = "containerManager.CloseHostFD"
+
 	// ContMgrCreateSubcontainer creates a sub-container.
 	ContMgrCreateSubcontainer = "containerManager.CreateSubcontainer"
 
@@ -75,12 +90,22 @@ const (
 	// ContMgrGetSavings gets the savings for restored sandboxes.
 	ContMgrGetSavings = "containerManager.GetSavings"
 
+	// ContMgrHostFDs lists the host FDs held by the sentry, with their owners.
+	ContMgrHostFDs = "containerManager.HostFDs"
+
+	// ContMgrImportCRIU imports the processes of a CRIU dump into a
+	// container.
+	ContMgrImportCRIU = "containerManager.ImportCRIU"
+
 	// ContMgrPortForward starts port forwarding with the sandbox.
 	ContMgrPortForward = "containerManager.PortForward"
 
 	// ContMgrProcesses lists processes running in a container.
 	ContMgrProcesses = "containerManager.Processes"
 
+	// ContMgrReclaim asks the sandbox to return memory to the host.
+	ContMgrReclaim = "containerManager.Reclaim"
+
 	// ContMgrRestore restores a container from a statefile.
 	ContMgrRestore = "containerManager.Restore"
 
@@ -90,19 +115,53 @@ const (
 	// ContMgrPause pauses all tasks, blocking until they are stopped.
 	ContMgrPause = "containerManager.Pause"
 
+	// ContMgrPauseContainer pauses all tasks in a container, blocking until
+	// they are stopped. Tasks in other containers keep running.
+	ContMgrPauseContainer = "containerManager.PauseContainer"
+
 	// ContMgrResume resumes all tasks.
 	ContMgrResume = "containerManager.Resume"
 
+	// ContMgrResumeContainer resumes all tasks in a container paused by
+	// ContMgrPauseContainer.
+	ContMgrResumeContainer = "containerManager.ResumeContainer"
+
+	// ContMgrSetOnlineCPUs sets the number of CPUs that are online in the
+	// sandbox.
+	ContMgrSetOnlineCPUs = "containerManager.SetOnlineCPUs"
+
 	// ContMgrSignal sends a signal to a container.
 	ContMgrSignal = "containerManager.Signal"
 
 	// ContMgrStartSubcontainer starts a sub-container inside a running sandbox.
 	ContMgrStartSubcontainer = "containerManager.StartSubcontainer"
 
+	// ContMgrStreamOutput streams the relayed stdout and stderr of a
+	// container, multiplexed into frames.
+	ContMgrStreamOutput = "containerManager.StreamOutput"
+
+	// ContMgrSubscribeEvents streams sandbox events, such as container exits,
+	// to a socket passed by the caller.
+	ContMgrSubscribeEvents = "containerManager.SubscribeEvents"
+
+	// ContMgrSysctls returns the sysctls set by a container.
+	ContMgrSysctls = "containerManager.Sysctls"
+
+	// ContMgrTaskUsage returns the resource usage of the tasks in a
+	// container.
+	ContMgrTaskUsage = "containerManager.TaskUsage"
+
+	// ContMgrUpdateResources updates the resource limits of a container.
+	ContMgrUpdateResources = "containerManager.UpdateResources"
+
 	// ContMgrWait waits on the init process of the container and returns its
 	// ExitStatus.
 	ContMgrWait = "containerManager.Wait"
 
+	// ContMgrWaitContainers waits, with an optional timeout, for any or all of
+	// a set of containers to exit and returns their ExitStatus.
+	ContMgrWaitContainers = "containerManager.WaitContainers"
+
 	// ContMgrWaitPID waits on a process with a certain PID in the sandbox and
 	// return its ExitStatus.
 	ContMgrWaitPID = "containerManager.WaitPID"
@@ -148,12 +207,22 @@ const (
 	// ContMgrGetNetworkConfig returns the network interfaces and routes applied
 	// during the creation of root container.
 	ContMgrGetNetworkConfig = "containerManager.GetNetworkConfig"
+
+	// ContMgrSetNetworkPolicy sets the network policy of a container.
+	ContMgrSetNetworkPolicy = "containerManager.SetNetworkPolicy"
 )
 
 const (
 	// NetworkInitPluginStack initializes third-party network stack.
 	NetworkInitPluginStack = "Network.InitPluginStack"
 
+	// NetworkResyncLinksAndRoutes applies a new configuration to existing
+	// links.
+	NetworkResyncLinksAndRoutes = "Network.ResyncLinksAndRoutes"
+
+	// NetworkDiagnostics returns a snapshot of the network stack state.
+	NetworkDiagnostics = "Network.Diagnostics"
+
 	// DebugStacks collects sandbox stacks for debugging.
 	DebugStacks = "debug.Stacks"
 )
@@ -167,11 +236,29 @@ const (
 	ProfileTrace = "Profile.Trace"
 )
 
+// Lock validator related commands (see lockdep.go for more details).
+const (
+	LockdepContention = "Lockdep.Contention"
+	LockdepGraph      = "Lockdep.Graph"
+)
+
 // Logging related commands (see logging.go for more details).
 const (
 	LoggingChange = "Logging.Change"
 )
 
+// Control API negotiation and discovery commands (see api.go for more
+// details).
+const (
+	APIHandshake    = "API.Handshake"
+	APICapabilities = "API.Capabilities"
+)
+
+// Go runtime related commands (see runtime.go for more details).
+const (
+	RuntimeStats = "Runtime.Stats"
+)
+
 // Usage related commands (see usage.go for more details).
 const (
 	UsageCollect = "Usage.Collect"
@@ -193,7 +280,10 @@ const (
 // FS-related commands (see fs.go for more details).
 const (
 	FsTarRootfsUpperLayer = "Fs.TarRootfsUpperLayer"
+	FsRootfsUpperUsage    = "Fs.RootfsUpperUsage"
 	FsRead                = "Fs.Read"
+	FsCopyIn              = "Fs.CopyIn"
+	FsCopyOut             = "Fs.CopyOut"
 )
 
 // controller holds the control server, and is used for communication into the
@@ -202,6 +292,10 @@ type controller struct {
 	// srv is the control server.
 	srv *server.Server
 
+	// grpcSrv serves srv's methods over gRPC. It is nil unless
+	// --control-grpc is set.
+	grpcSrv *grpcserver.Server
+
 	// manager holds the containerManager methods.
 	manager *containerManager
 
@@ -212,12 +306,27 @@ type controller struct {
 }
 
 // newController creates a new controller. The caller must call
-// controller.srv.StartServing() to start the controller.
-func newController(fd int, l *Loader) (*controller, error) {
+// controller.srv.StartServing() to start the controller. grantFDs are the
+// sockets for the grants of --control-policy, in the order of
+// server.Policy.Grants.
+func newController(fd int, grantFDs []int, l *Loader) (*controller, error) {
 	srv, err := server.CreateFromFD(fd)
 	if err != nil {
 		return nil, err
 	}
+	policy, err := server.ParsePolicy(l.root.conf.ControlPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --control-policy: %w", err)
+	}
+	grants := policy.Grants()
+	if len(grants) != len(grantFDs) {
+		return nil, fmt.Errorf("--control-policy has %d grants, but got %d control grant FDs", len(grants), len(grantFDs))
+	}
+	for i := range grants {
+		if err := srv.AddRestrictedFD(grantFDs[i], grants[i].Authorize); err != nil {
+			return nil, err
+		}
+	}
 
 	ctrl := &controller{
 		manager: &containerManager{
@@ -228,6 +337,10 @@ func newController(fd int, l *Loader) (*controller, error) {
 		srv:            srv,
 		stopRPCTimeout: l.root.conf.ControlRPCStopTimeout,
 	}
+	if l.root.conf.ControlGRPC {
+		ctrl.grpcSrv = grpcserver.New(srv)
+		srv.SetGRPCHandler(ctrl.grpcSrv.Handle)
+	}
 	ctrl.registerHandlers()
 	return ctrl, nil
 }
@@ -235,11 +348,14 @@ func newController(fd int, l *Loader) (*controller, error) {
 func (c *controller) registerHandlers() {
 	l := c.manager.l
 	c.srv.Register(c.manager)
+	c.srv.Register(&control.API{Methods: c.srv.Methods})
 	c.srv.Register(&control.Cgroups{Kernel: l.k})
 	c.srv.Register(&control.Fs{Kernel: l.k})
 	c.srv.Register(&control.Lifecycle{Kernel: l.k})
+	c.srv.Register(&control.Lockdep{})
 	c.srv.Register(&control.Logging{})
 	c.srv.Register(&control.Proc{Kernel: l.k})
+	c.srv.Register(&control.Runtime{})
 	c.srv.Register(&control.State{Kernel: l.k})
 	c.srv.Register(&control.Usage{Kernel: l.k})
 	c.srv.Register(&control.Metrics{})
@@ -270,6 +386,9 @@ func (c *controller) refreshHandlers() {
 
 func (c *controller) stop() {
 	c.srv.Stop(c.stopRPCTimeout)
+	if c.grpcSrv != nil {
+		c.grpcSrv.Stop()
+	}
 }
 
 // containerManager manages sandbox containers.
@@ -319,6 +438,12 @@ func (cm *containerManager) Processes(cid *string, out *[]*control.Process) erro
 	return control.Processes(cm.l.k, *cid, out)
 }
 
+// TaskUsage retrieves the resource usage of the tasks running in a container.
+func (cm *containerManager) TaskUsage(cid *string, out *control.TaskUsages) error {
+	log.Debugf("containerManager.TaskUsage, cid: %s", *cid)
+	return control.TaskUsageStats(cm.l.k, *cid, out)
+}
+
 // CreateArgs contains arguments to the Create method.
 type CreateArgs struct {
 	// CID is the ID of the container to start.
@@ -368,6 +493,10 @@ type StartArgs struct {
 	// for bind mounts in Spec.Mounts (in the same order).
 	GoferMountConfs []specutils.GoferMountConf
 
+	// GoferAuthKey is the key used to authenticate the container's gofer
+	// connections. If nil, gofer connections are not authenticated.
+	GoferAuthKey goferauth.Key
+
 	// IsRootfsUpperTarFilePresent indicates whether the rootfs upper tar file is present.
 	IsRootfsUpperTarFilePresent bool
 
@@ -494,7 +623,7 @@ func (cm *containerManager) StartSubcontainer(args *StartArgs, _ *struct{}) erro
 		}
 	}()
 
-	if err := cm.l.startSubcontainer(args.Spec, args.Conf, args.CID, stdios, goferFDs, goferFilestoreFDs, devGoferFD, args.GoferMountConfs, rootfsUpperTarFD); err != nil {
+	if err := cm.l.startSubcontainer(args.Spec, args.Conf, args.CID, stdios, goferFDs, goferFilestoreFDs, devGoferFD, args.GoferMountConfs, args.GoferAuthKey, rootfsUpperTarFD); err != nil {
 		log.Debugf("containerManager.StartSubcontainer failed, cid: %s, args: %+v, err: %v", args.CID, args, err)
 		return err
 	}
@@ -522,10 +651,83 @@ func (cm *containerManager) ExecuteAsync(args *control.ExecArgs, pid *int32) err
 	return nil
 }
 
+// AttachExecArgs are arguments to the AttachExec method.
+type AttachExecArgs struct {
+	// ContainerID is the container of the process.
+	ContainerID string
+
+	// PID is the PID of the process in the container's PID namespace, as
+	// returned by ExecuteAsync.
+	PID int32
+
+	// DetachKeys is the sequence of keys that detaches the terminal. If
+	// empty, the terminal stays attached until it goes away or the session
+	// ends.
+	DetachKeys []byte
+
+	// FilePayload contains the terminal that is attached.
+	urpc.FilePayload
+}
+
+// AttachExec attaches a terminal to the session of a process started with
+// control.ExecArgs.Detachable. It returns once the terminal is detached, and
+// sets running to true if the process may still be running.
+func (cm *containerManager) AttachExec(args *AttachExecArgs, running *bool) error {
+	log.Debugf("containerManager.AttachExec, cid: %s, pid: %d", args.ContainerID, args.PID)
+	var err error
+	*running, err = cm.l.attachExec(args)
+	log.Debugf("containerManager.AttachExec returned, cid: %s, pid: %d, running: %t, err: %v", args.ContainerID, args.PID, *running, err)
+	return err
+}
+
+// ImportCRIU imports the processes of a CRIU dump into a container, and
+// starts them.
+func (cm *containerManager) ImportCRIU(args *ImportCRIUArgs, out *[]ImportedProcess) error {
+	log.Debugf("containerManager.ImportCRIU, cid: %s", args.ContainerID)
+	imported, err := cm.l.importCRIU(args)
+	if err != nil {
+		log.Debugf("containerManager.ImportCRIU failed, cid: %s, err: %v", args.ContainerID, err)
+		return err
+	}
+	*out = imported
+	return nil
+}
+
+// HostFDs lists the host FDs held by the sentry that it can attribute to an
+// owner.
+func (cm *containerManager) HostFDs(_ *struct{}, out *[]HostFDOwner) error {
+	log.Debugf("containerManager.HostFDs")
+	*out = cm.l.hostFDs()
+	return nil
+}
+
+// CloseHostFD closes a host FD held by the sentry by closing the application
+// FDs that hold it.
+func (cm *containerManager) CloseHostFD(fd *int32, _ *struct{}) error {
+	log.Debugf("containerManager.CloseHostFD, fd: %d", *fd)
+	return cm.l.closeHostFD(*fd)
+}
+
 // Checkpoint pauses a sandbox and saves its state.
 func (cm *containerManager) Checkpoint(o *control.SaveOpts, _ *struct{}) error {
 	log.Debugf("containerManager.Checkpoint")
-	return cm.l.save(o)
+	if err := cm.l.save(o); err != nil {
+		return err
+	}
+	cm.l.events.lifecycle(SandboxEventCheckpoint, "")
+	return nil
+}
+
+// AttachStdio replaces the destination of a container's relayed output.
+func (cm *containerManager) AttachStdio(args *AttachStdioArgs, _ *struct{}) error {
+	log.Debugf("containerManager.AttachStdio, cid: %s, streams: %v", args.ContainerID, args.Streams)
+	return cm.l.attachStdio(args)
+}
+
+// StreamOutput streams a container's relayed output.
+func (cm *containerManager) StreamOutput(args *StreamOutputArgs, _ *struct{}) error {
+	log.Debugf("containerManager.StreamOutput, cid: %s, replay: %d, follow: %t", args.ContainerID, args.ReplayBytes, args.Follow)
+	return cm.l.streamOutput(args)
 }
 
 // PortForwardOpts contains options for port forwarding to a port in a
@@ -563,6 +765,12 @@ type RestoreOpts struct {
 	HaveDeviceFile bool
 	Background     bool
 
+	// If LoadPagesOnDemand is true, pages are only read from the pages file
+	// when the application accesses them, so that the checkpoint can serve as
+	// a template from which many sandboxes are restored. This implies
+	// Background, and requires a pages file.
+	LoadPagesOnDemand bool `json:"load_pages_on_demand"`
+
 	// If UseCheckpointGofer is true, the first file in FilePayload is a Unix
 	// domain socket connected to a URPC server implementing
 	// stateipc.AsyncFileServer and providing checkpoint files. In this case,
@@ -617,14 +825,18 @@ func (cm *containerManager) Restore(o *RestoreOpts, _ *struct{}) (retErr error)
 	}()
 	timer.Reached("got restore readers")
 
+	if o.LoadPagesOnDemand && pagesFile == nil {
+		return fmt.Errorf("loading pages on demand requires a checkpoint with a separate pages file")
+	}
 	cm.restorer = &restorer{
 		cm:         cm,
-		background: o.Background,
+		background: o.Background || o.LoadPagesOnDemand,
+		onDemand:   o.LoadPagesOnDemand,
 		timer:      timer,
 	}
 
 	// Create the main MemoryFile.
-	cm.restorer.mainMF, err = createMemoryFile(cm.l.root.conf.AppHugePages, cm.l.hostTHP)
+	cm.restorer.mainMF, err = createMemoryFile(cm.l.root.conf, cm.l.hostTHP)
 	if err != nil {
 		return fmt.Errorf("creating memory file: %v", err)
 	}
@@ -632,7 +844,7 @@ func (cm *containerManager) Restore(o *RestoreOpts, _ *struct{}) (retErr error)
 
 	if o.HavePagesFile {
 		// This immediately starts loading the main MemoryFile asynchronously.
-		cm.restorer.asyncMFLoader = kernel.NewAsyncMFLoader(pagesMetadata, pagesFile, cm.restorer.mainMF, timer.Fork("PagesFileLoader")) // transfers ownership
+		cm.restorer.asyncMFLoader = kernel.NewAsyncMFLoader(pagesMetadata, pagesFile, cm.restorer.mainMF, cm.restorer.onDemand, timer.Fork("PagesFileLoader")) // transfers ownership
 		pagesMetadata = nil
 		pagesFile = nil
 		timer.Reached("created async MF loader")
@@ -685,10 +897,25 @@ func (cm *containerManager) Restore(o *RestoreOpts, _ *struct{}) (retErr error)
 	}
 	cm.restorer.checkpointedSpecs = specs
 
-	checkpointVersion := cm.restorer.metadata[VersionKey]
-	currentVersion := version.Version()
-	if checkpointVersion != currentVersion {
-		return fmt.Errorf("runsc version does not match across checkpoint restore, checkpoint: %v current: %v", checkpointVersion, currentVersion)
+	// Check that the checkpoint can be restored here before building the new
+	// kernel, so that incompatibilities are reported clearly rather than as
+	// failures deep inside restore.
+	host := &RestoreHost{
+		RunscVersion: version.Version(),
+		Arch:         runtime.GOARCH,
+		Platform:     cm.l.root.conf.Platform,
+		CPUFeatures:  cpuid.HostFeatureSet(),
+		PacketFilter: cm.l.root.conf.PacketFilter,
+	}
+	if cm.l.k.NvidiaDriverVersion.Major() > 0 {
+		host.NvidiaDriverVersion = cm.l.k.NvidiaDriverVersion.String()
+	}
+	issues := CheckRestoreCompatibility(cm.restorer.metadata, host)
+	if err := compatibilityError(issues); err != nil {
+		return err
+	}
+	for i := range issues {
+		log.Infof("Restoring checkpoint despite difference in %s", issues[i].String())
 	}
 	timer.Reached("restorer initialized")
 	return cm.restorer.restoreContainerInfo(cm.l, &cm.l.root)
@@ -887,7 +1114,7 @@ func (cm *containerManager) RestoreSubcontainer(args *StartArgs, _ *struct{}) (r
 		return fmt.Errorf("error dup'ing gofer files: %w", err)
 	}
 
-	err = cm.restorer.restoreSubcontainer(args.Spec, args.Conf, cm.l, args.CID, stdios, goferFDs, goferFilestoreFDs, devGoferFD, args.GoferMountConfs)
+	err = cm.restorer.restoreSubcontainer(args.Spec, args.Conf, cm.l, args.CID, stdios, goferFDs, goferFilestoreFDs, devGoferFD, args.GoferMountConfs, args.GoferAuthKey)
 	if err != nil {
 		log.Debugf("containerManager.RestoreSubcontainer failed, cid: %s, args: %+v, err: %v", args.CID, args, err)
 		return err
@@ -899,15 +1126,54 @@ func (cm *containerManager) RestoreSubcontainer(args *StartArgs, _ *struct{}) (r
 // Pause pauses all tasks, blocking until they are stopped.
 func (cm *containerManager) Pause(_, _ *struct{}) error {
 	cm.l.k.Pause()
+	cm.l.events.lifecycle(SandboxEventPause, "")
 	return nil
 }
 
 // Resume resumes all tasks.
 func (cm *containerManager) Resume(_, _ *struct{}) error {
 	cm.l.k.Unpause()
+	cm.l.events.lifecycle(SandboxEventResume, "")
 	return control.PostResume(cm.l.k, nil)
 }
 
+// PauseContainer pauses all tasks in the given container.
+func (cm *containerManager) PauseContainer(cid *string, _ *struct{}) error {
+	log.Debugf("containerManager.PauseContainer, cid: %s", *cid)
+	if err := cm.l.pauseContainer(*cid); err != nil {
+		return err
+	}
+	cm.l.events.lifecycle(SandboxEventPause, *cid)
+	return nil
+}
+
+// ResumeContainer resumes all tasks in the given container.
+func (cm *containerManager) ResumeContainer(cid *string, _ *struct{}) error {
+	log.Debugf("containerManager.ResumeContainer, cid: %s", *cid)
+	if err := cm.l.k.UnpauseContainer(*cid); err != nil {
+		return err
+	}
+	cm.l.events.lifecycle(SandboxEventResume, *cid)
+	return nil
+}
+
+// SetOnlineCPUs sets the number of CPUs that are online in the sandbox, e.g.
+// after the sandbox's CPU limit has been changed.
+func (cm *containerManager) SetOnlineCPUs(n *int, _ *struct{}) error {
+	if *n <= 0 {
+		return fmt.Errorf("invalid number of online CPUs: %d", *n)
+	}
+	log.Debugf("containerManager.SetOnlineCPUs, n: %d", *n)
+	cm.l.k.SetOnlineCPUs(cm.l.k.SupervisorContext(), uint(*n))
+	return nil
+}
+
+// UpdateResources updates the resource limits of a running container.
+func (cm *containerManager) UpdateResources(args *UpdateResourcesArgs, _ *struct{}) error {
+	log.Debugf("containerManager.UpdateResources, cid: %s", args.ContainerID)
+	return cm.l.updateResources(args.ContainerID, args.Resources)
+}
+
 // Wait waits for the init process in the given container.
 func (cm *containerManager) Wait(cid *string, waitStatus *uint32) error {
 	log.Debugf("containerManager.Wait, cid: %s", *cid)
@@ -916,6 +1182,47 @@ func (cm *containerManager) Wait(cid *string, waitStatus *uint32) error {
 	return err
 }
 
+// WaitContainersArgs are arguments to the WaitContainers method.
+type WaitContainersArgs struct {
+	// CIDs are the IDs of the containers to wait on.
+	CIDs []string
+
+	// All is true to wait for all containers to exit, instead of the first
+	// one.
+	All bool
+
+	// Timeout is the maximum time to wait. Zero means no timeout.
+	Timeout gtime.Duration
+}
+
+// ContainerExit is the exit status of a container.
+type ContainerExit struct {
+	// CID is the container ID.
+	CID string
+
+	// WaitStatus is the wait status of the container's init process.
+	WaitStatus uint32
+}
+
+// WaitContainersResult is the result of the WaitContainers method.
+type WaitContainersResult struct {
+	// Exited are the containers that exited, in the order they exited. It
+	// contains exactly one container unless WaitContainersArgs.All is set.
+	Exited []ContainerExit
+
+	// TimedOut is true if the timeout expired before the containers exited.
+	// Exited then contains the containers that exited before the timeout.
+	TimedOut bool
+}
+
+// WaitContainers waits for any or all containers in args.CIDs to exit.
+func (cm *containerManager) WaitContainers(args *WaitContainersArgs, res *WaitContainersResult) error {
+	log.Debugf("containerManager.WaitContainers, cids: %v, all: %t, timeout: %v", args.CIDs, args.All, args.Timeout)
+	err := cm.l.waitContainers(args, res)
+	log.Debugf("containerManager.WaitContainers returned, cids: %v, exited: %+v, timed out: %t, err: %v", args.CIDs, res.Exited, res.TimedOut, err)
+	return err
+}
+
 // WaitPIDArgs are arguments to the WaitPID method.
 type WaitPIDArgs struct {
 	// PID is the PID in the container's PID namespace.
@@ -1188,6 +1495,31 @@ func (cm *containerManager) ContainerRuntimeState(cid *string, state *ContainerR
 	return nil
 }
 
+// Sysctls returns the sysctls set by a container, either through its spec or
+// by writing to /proc/sys, keyed by their dotted name.
+func (cm *containerManager) Sysctls(cid *string, sysctls *map[string]string) error {
+	log.Debugf("containerManager.Sysctls: cid: %s", *cid)
+	*sysctls = cm.l.k.Sysctls().Get(*cid)
+	return nil
+}
+
+// SetNetworkPolicyArgs holds arguments to SetNetworkPolicy.
+type SetNetworkPolicyArgs struct {
+	// CID is the container ID.
+	CID string `json:"cid"`
+
+	// Policy is the network policy to enforce. If nil, the network policy of
+	// the container is removed.
+	Policy *NetworkPolicy `json:"policy"`
+}
+
+// SetNetworkPolicy sets the network policy of a container, replacing the one
+// set from its annotations, if any. It applies to existing sockets too.
+func (cm *containerManager) SetNetworkPolicy(args *SetNetworkPolicyArgs, _ *struct{}) error {
+	log.Debugf("containerManager.SetNetworkPolicy, cid: %s, policy: %+v", args.CID, args.Policy)
+	return cm.l.setNetworkPolicy(args.CID, args.Policy)
+}
+
 // FSSaveArgs holds arguments to FSSave.
 type FSSaveArgs struct {
 	// FilePayload contains the following fscheckpoint files in order:
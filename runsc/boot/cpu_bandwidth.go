@@ -0,0 +1,80 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"time"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/runsc/config"
+)
+
+// CPUThrottling contains stats on the CPU bandwidth control of a container.
+type CPUThrottling struct {
+	// Periods is the number of enforcement periods that have elapsed.
+	Periods uint64 `json:"periods,omitempty"`
+	// ThrottledPeriods is the number of periods in which the container used
+	// its quota and was throttled.
+	ThrottledPeriods uint64 `json:"throttledPeriods,omitempty"`
+	// ThrottledTime is the total time for which the container was throttled,
+	// in nanoseconds.
+	ThrottledTime uint64 `json:"throttledTime,omitempty"`
+}
+
+// setCPUBandwidthFromSpec enforces the CPU quota of the given container from
+// its spec in the sentry, if --sentry-cpu-bandwidth is set.
+func (l *Loader) setCPUBandwidthFromSpec(spec *specs.Spec, conf *config.Config, cid string) error {
+	if spec.Linux == nil || spec.Linux.Resources == nil || spec.Linux.Resources.CPU == nil {
+		return nil
+	}
+	cpu := spec.Linux.Resources.CPU
+	if cpu.Quota == nil || *cpu.Quota <= 0 {
+		return nil
+	}
+	return l.setCPUBandwidth(cpu, conf, cid)
+}
+
+// setCPUBandwidth enforces the CPU quota in cpu for the given container in the
+// sentry, if --sentry-cpu-bandwidth is set. If cpu has no quota, any existing
+// limit is removed.
+func (l *Loader) setCPUBandwidth(cpu *specs.LinuxCPU, conf *config.Config, cid string) error {
+	if !conf.SentryCPUBandwidth {
+		return nil
+	}
+	var quota, period time.Duration
+	if cpu != nil && cpu.Quota != nil && *cpu.Quota > 0 {
+		quota = time.Duration(*cpu.Quota) * time.Microsecond
+		if cpu.Period != nil {
+			period = time.Duration(*cpu.Period) * time.Microsecond
+		}
+	}
+	log.Infof("Setting CPU bandwidth for container %q: quota=%v, period=%v", cid, quota, period)
+	return l.k.SetContainerCPUBandwidth(cid, quota, period)
+}
+
+// cpuThrottling returns the CPU bandwidth control stats of the given
+// container, which are empty if the sentry doesn't limit it.
+func (l *Loader) cpuThrottling(cid string) CPUThrottling {
+	stats, ok := l.k.ContainerCPUBandwidthStats(cid)
+	if !ok {
+		return CPUThrottling{}
+	}
+	return CPUThrottling{
+		Periods:          stats.Periods,
+		ThrottledPeriods: stats.ThrottledPeriods,
+		ThrottledTime:    uint64(stats.ThrottledTime.Nanoseconds()),
+	}
+}
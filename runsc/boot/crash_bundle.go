@@ -0,0 +1,313 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/metric"
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
+	"gvisor.dev/gvisor/pkg/sentry/strace"
+	"gvisor.dev/gvisor/pkg/sentry/watchdog"
+	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/version"
+)
+
+// crashBundles is the number of crash bundles written by the sandbox.
+var crashBundles = metric.MustCreateNewUint64Metric("/sandbox/crash_bundles", metric.Uint64Metadata{
+	Cumulative:  true,
+	Description: "Number of crash bundles written because the sentry was about to crash.",
+})
+
+const (
+	// recentLogLines is the number of log lines kept for crash bundles.
+	recentLogLines = 1000
+
+	// redacted replaces values removed from the spec in crash bundles.
+	redacted = "<redacted>"
+)
+
+// crashBundleFiles are the names of the files in a crash bundle, in the order
+// that they are written.
+var crashBundleFiles = []string{"reason.txt", "stacks.txt", "logs.txt", "config.txt", "version.txt", "platform.txt", "spec.json"}
+
+// recentLogs is a log.Emitter that keeps the last recentLogLines log lines.
+// Lines logged by strace are dropped, since system call arguments may contain
+// the same sensitive values that are removed from the spec.
+type recentLogs struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+}
+
+// Emit implements log.Emitter.Emit.
+func (r *recentLogs) Emit(_ int, level log.Level, timestamp time.Time, format string, v ...any) {
+	if strace.IsLogFormat(format) {
+		return
+	}
+	line := fmt.Sprintf("%s %s %s", timestamp.Format(time.RFC3339Nano), level, fmt.Sprintf(format, v...))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.lines) < recentLogLines {
+		r.lines = append(r.lines, line)
+		return
+	}
+	r.lines[r.next] = line
+	r.next = (r.next + 1) % recentLogLines
+}
+
+// String returns the recent log lines, oldest first.
+func (r *recentLogs) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var b strings.Builder
+	for i := range r.lines {
+		b.WriteString(r.lines[(r.next+i)%len(r.lines)])
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// crashBundle writes a tar file with the information needed to debug a sentry
+// crash: stacks of all goroutines, recent logs, the configuration, the version
+// and platform, and the spec with potentially sensitive values removed.
+type crashBundle struct {
+	logs *recentLogs
+
+	// static are the files of the bundle that don't change after boot, keyed
+	// by name. They are collected at boot because the sandbox is more
+	// restricted when it crashes, e.g. uname(2) may not be allowed.
+	static map[string][]byte
+
+	mu sync.Mutex
+
+	// f is the file that the bundle is written to. It's nil once the bundle
+	// has been written.
+	// +checklocks:mu
+	f *os.File
+}
+
+// newCrashBundle creates a crashBundle that is written to f, and starts
+// recording recent logs.
+func newCrashBundle(f *os.File, conf *config.Config, spec *specs.Spec) *crashBundle {
+	b := &crashBundle{
+		logs:   &recentLogs{},
+		static: make(map[string][]byte),
+		f:      f,
+	}
+	b.static["config.txt"] = []byte(strings.Join(conf.ToFlags(), "\n") + "\n")
+	b.static["version.txt"] = crashBundleVersion()
+
+	var platform bytes.Buffer
+	fmt.Fprintf(&platform, "platform: %s\n", conf.Platform)
+	fmt.Fprintf(&platform, "arch: %s\n", runtime.GOARCH)
+	fmt.Fprintf(&platform, "host CPUs: %d\n", runtime.NumCPU())
+	var uts unix.Utsname
+	if err := unix.Uname(&uts); err == nil {
+		fmt.Fprintf(&platform, "host kernel: %s %s\n", unix.ByteSliceToString(uts.Release[:]), unix.ByteSliceToString(uts.Version[:]))
+	}
+	b.static["platform.txt"] = platform.Bytes()
+
+	if spec != nil {
+		if data, err := json.MarshalIndent(anonymizeSpec(spec), "", "  "); err == nil {
+			b.static["spec.json"] = data
+		} else {
+			log.Warningf("Failed to encode spec for crash bundle: %v", err)
+		}
+	}
+
+	// Fatal errors and panics outside of task goroutines can't be
+	// intercepted, so let the Go runtime write its crash report to the bundle
+	// file instead. FinishCrashBundle turns it into a bundle.
+	if err := debug.SetCrashOutput(f, debug.CrashOptions{}); err != nil {
+		log.Warningf("Failed to set the crash output to the crash bundle: %v", err)
+	}
+
+	// Logging has been set up by now, and few goroutines are running.
+	log.SetTarget(&log.MultiEmitter{log.Log().Emitter, b.logs})
+	return b
+}
+
+// crashBundleVersion returns the contents of version.txt.
+func crashBundleVersion() []byte {
+	return []byte(fmt.Sprintf("runsc %s\n%s\n", version.Version(), runtime.Version()))
+}
+
+// anonymizeSpec returns a copy of spec without values that may be sensitive:
+// environment variable values, arguments, annotation values, the hostname and
+// mount sources.
+func anonymizeSpec(spec *specs.Spec) *specs.Spec {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil
+	}
+	var s specs.Spec
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil
+	}
+	if s.Process != nil {
+		for i, env := range s.Process.Env {
+			name, _, _ := strings.Cut(env, "=")
+			s.Process.Env[i] = name + "=" + redacted
+		}
+		// Keep the program name, arguments may contain secrets.
+		for i := 1; i < len(s.Process.Args); i++ {
+			s.Process.Args[i] = redacted
+		}
+		s.Process.CommandLine = ""
+	}
+	for k := range s.Annotations {
+		s.Annotations[k] = redacted
+	}
+	if s.Hostname != "" {
+		s.Hostname = redacted
+	}
+	for i := range s.Mounts {
+		if s.Mounts[i].Source != "" {
+			s.Mounts[i].Source = redacted
+		}
+	}
+	return &s
+}
+
+// write writes the bundle with reason, which describes why the sentry is about
+// to crash, and returns true if it did. Only the first call writes the bundle,
+// since the first crash is usually the interesting one.
+func (b *crashBundle) write(reason string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.f == nil {
+		return false
+	}
+	defer func() {
+		b.f.Close()
+		b.f = nil
+	}()
+	// Don't let the runtime append its crash report to the bundle.
+	if err := debug.SetCrashOutput(nil, debug.CrashOptions{}); err != nil {
+		log.Warningf("Failed to reset the crash output: %v", err)
+	}
+
+	crashBundles.Increment()
+	files := map[string][]byte{
+		"reason.txt": []byte(reason + "\n"),
+		"stacks.txt": log.Stacks(true),
+		"logs.txt":   []byte(b.logs.String()),
+	}
+	for name, data := range b.static {
+		files[name] = data
+	}
+
+	if err := writeCrashBundle(b.f, files); err != nil {
+		log.Warningf("Failed to write crash bundle: %v", err)
+		return false
+	}
+	if err := b.f.Sync(); err != nil {
+		log.Warningf("Failed to sync crash bundle: %v", err)
+	}
+	log.Warningf("Crash bundle written")
+	return true
+}
+
+// writeCrashBundle writes a crash bundle with the given files, keyed by name,
+// to w.
+func writeCrashBundle(w io.Writer, files map[string][]byte) error {
+	now := time.Now()
+	tw := tar.NewWriter(w)
+	for _, name := range crashBundleFiles {
+		data, ok := files[name]
+		if !ok {
+			continue
+		}
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// FinishCrashBundle is called with the path of a crash bundle file after the
+// sandbox exited. If the sentry died without writing a bundle, e.g. because of
+// a fatal error or a panic outside of task goroutines, the file contains the
+// Go runtime's crash report, which FinishCrashBundle replaces with a bundle
+// that contains it. It returns false if the file is empty, i.e. the sentry
+// didn't crash.
+func FinishCrashBundle(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return false, err
+	}
+	if len(data) >= 262 && string(data[257:262]) == "ustar" {
+		// Written by crashBundle.write, see the tar header format.
+		return true, nil
+	}
+
+	var bundle bytes.Buffer
+	if err := writeCrashBundle(&bundle, map[string][]byte{
+		"reason.txt":  []byte("fatal error or panic outside of task goroutines, see stacks.txt\n"),
+		"stacks.txt":  data,
+		"version.txt": crashBundleVersion(),
+	}); err != nil {
+		return true, err
+	}
+	return true, os.WriteFile(path, bundle.Bytes(), 0644)
+}
+
+// watchdogReport implements watchdog.Opts.OnReport.
+func (l *Loader) watchdogReport(action watchdog.Action, msg string) {
+	if action == watchdog.Panic && l.crashBundle != nil {
+		if l.crashBundle.write(fmt.Sprintf("watchdog: %s", msg)) {
+			l.events.publish(SandboxEvent{Type: SandboxEventCrashBundle, Message: "watchdog panic"}, 0)
+		}
+	}
+	// This waits for the panic-imminent event, and thus for the event above,
+	// to be delivered.
+	l.events.watchdogReport(action, msg)
+}
+
+// taskPanic is called by task goroutines that panic, see
+// kernel.Kernel.SetTaskPanicHook.
+func (l *Loader) taskPanic(t *kernel.Task, r any) {
+	msg := fmt.Sprintf("panic in task %d (%s) of container %q: %v", t.ThreadID(), t.Name(), t.ContainerID(), r)
+	if !l.crashBundle.write(msg) {
+		return
+	}
+	l.events.publish(SandboxEvent{Type: SandboxEventCrashBundle, ContainerID: t.ContainerID(), Message: msg}, panicEventTimeout)
+	// Attempt to flush metrics, timeout and move on in case metrics are stuck
+	// as well.
+	metric.EmitMetricUpdateWithTimeout(time.Second)
+}
@@ -0,0 +1,194 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/pkg/log"
+)
+
+func TestAnonymizeSpec(t *testing.T) {
+	spec := &specs.Spec{
+		Hostname: "my-host",
+		Process: &specs.Process{
+			Args: []string{"/bin/app", "--token=secret"},
+			Env:  []string{"PATH=/bin", "PASSWORD=secret"},
+		},
+		Annotations: map[string]string{"key": "secret"},
+		Mounts: []specs.Mount{
+			{Destination: "/data", Source: "/secret/path", Type: "bind"},
+		},
+	}
+	got := anonymizeSpec(spec)
+	if got == nil {
+		t.Fatalf("anonymizeSpec() = nil")
+	}
+	if want := []string{"/bin/app", redacted}; strings.Join(got.Process.Args, " ") != strings.Join(want, " ") {
+		t.Errorf("args = %q, want %q", got.Process.Args, want)
+	}
+	if want := []string{"PATH=" + redacted, "PASSWORD=" + redacted}; strings.Join(got.Process.Env, " ") != strings.Join(want, " ") {
+		t.Errorf("env = %q, want %q", got.Process.Env, want)
+	}
+	if got.Annotations["key"] != redacted {
+		t.Errorf("annotation = %q, want %q", got.Annotations["key"], redacted)
+	}
+	if got.Hostname != redacted {
+		t.Errorf("hostname = %q, want %q", got.Hostname, redacted)
+	}
+	if got.Mounts[0].Source != redacted || got.Mounts[0].Destination != "/data" {
+		t.Errorf("mount = %+v, want source redacted and destination kept", got.Mounts[0])
+	}
+	// The original spec must not be changed.
+	if spec.Process.Env[1] != "PASSWORD=secret" {
+		t.Errorf("original spec changed: %q", spec.Process.Env)
+	}
+}
+
+func TestRecentLogs(t *testing.T) {
+	var r recentLogs
+	for i := 0; i < recentLogLines+10; i++ {
+		r.Emit(0, log.Info, time.Now(), "line %d", i)
+	}
+	lines := strings.Split(strings.TrimSuffix(r.String(), "\n"), "\n")
+	if len(lines) != recentLogLines {
+		t.Fatalf("got %d lines, want %d", len(lines), recentLogLines)
+	}
+	if !strings.HasSuffix(lines[0], "line 10") {
+		t.Errorf("first line = %q, want line 10", lines[0])
+	}
+	if want := fmt.Sprintf("line %d", recentLogLines+9); !strings.HasSuffix(lines[len(lines)-1], want) {
+		t.Errorf("last line = %q, want %s", lines[len(lines)-1], want)
+	}
+}
+
+func TestRecentLogsDropsStrace(t *testing.T) {
+	var r recentLogs
+	r.Emit(0, log.Info, time.Now(), "[   1:   1] %s E %s(%s)", "app", "open", "\"/secret\"")
+	r.Emit(0, log.Info, time.Now(), "[   1:   1] %s X %s(%s) = %s", "app", "open", "\"/secret\"", "3")
+	r.Emit(0, log.Info, time.Now(), "kept")
+	if got, want := r.String(), "kept\n"; !strings.HasSuffix(got, want) || strings.Contains(got, "secret") {
+		t.Errorf("logs = %q, want only the line ending in %q", got, want)
+	}
+}
+
+func TestCrashBundleWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crash.tar")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("os.Create(%q): %v", path, err)
+	}
+	b := &crashBundle{
+		logs:   &recentLogs{},
+		static: map[string][]byte{"version.txt": []byte("test\n")},
+		f:      f,
+	}
+	b.logs.Emit(0, log.Warning, time.Now(), "something went wrong")
+
+	if !b.write("test crash") {
+		t.Fatalf("write() = false, want true")
+	}
+	if b.write("second crash") {
+		t.Errorf("second write() = true, want false")
+	}
+
+	files := readCrashBundle(t, path)
+	if got := files["reason.txt"]; got != "test crash\n" {
+		t.Errorf("reason.txt = %q, want %q", got, "test crash\n")
+	}
+	if got := files["logs.txt"]; !strings.Contains(got, "something went wrong") {
+		t.Errorf("logs.txt = %q, want the emitted log line", got)
+	}
+	if got := files["stacks.txt"]; !strings.Contains(got, "goroutine") {
+		t.Errorf("stacks.txt = %q, want goroutine stacks", got)
+	}
+	if got := files["version.txt"]; got != "test\n" {
+		t.Errorf("version.txt = %q, want %q", got, "test\n")
+	}
+
+	// Bundles written by the sentry are left as is.
+	crashed, err := FinishCrashBundle(path)
+	if err != nil || !crashed {
+		t.Fatalf("FinishCrashBundle() = (%t, %v), want (true, nil)", crashed, err)
+	}
+	if got := readCrashBundle(t, path)["reason.txt"]; got != "test crash\n" {
+		t.Errorf("reason.txt after FinishCrashBundle = %q, want %q", got, "test crash\n")
+	}
+}
+
+func TestFinishCrashBundle(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.tar")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatalf("os.WriteFile(%q): %v", empty, err)
+	}
+	if crashed, err := FinishCrashBundle(empty); err != nil || crashed {
+		t.Errorf("FinishCrashBundle() on an empty file = (%t, %v), want (false, nil)", crashed, err)
+	}
+
+	// The Go runtime writes its crash report to the bundle file on fatal
+	// errors.
+	path := filepath.Join(dir, "crash.tar")
+	const report = "panic: oops\n\ngoroutine 1 [running]:\nmain.main()\n"
+	if err := os.WriteFile(path, []byte(report), 0644); err != nil {
+		t.Fatalf("os.WriteFile(%q): %v", path, err)
+	}
+	crashed, err := FinishCrashBundle(path)
+	if err != nil || !crashed {
+		t.Fatalf("FinishCrashBundle() = (%t, %v), want (true, nil)", crashed, err)
+	}
+	files := readCrashBundle(t, path)
+	if got := files["stacks.txt"]; got != report {
+		t.Errorf("stacks.txt = %q, want %q", got, report)
+	}
+	if _, ok := files["reason.txt"]; !ok {
+		t.Errorf("reason.txt is missing")
+	}
+}
+
+// readCrashBundle returns the files of the crash bundle at path.
+func readCrashBundle(t *testing.T, path string) map[string]string {
+	t.Helper()
+	tf, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("os.Open(%q): %v", path, err)
+	}
+	defer tf.Close()
+	files := make(map[string]string)
+	tr := tar.NewReader(tf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading bundle: %v", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("reading %q: %v", hdr.Name, err)
+		}
+		files[hdr.Name] = string(data)
+	}
+	return files
+}
@@ -0,0 +1,734 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"bytes"
+	"debug/elf"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/cleanup"
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/fspath"
+	"gvisor.dev/gvisor/pkg/hostarch"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/arch"
+	"gvisor.dev/gvisor/pkg/sentry/fsimpl/tmpfs"
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
+	"gvisor.dev/gvisor/pkg/sentry/limits"
+	"gvisor.dev/gvisor/pkg/sentry/memmap"
+	"gvisor.dev/gvisor/pkg/sentry/mm"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+	"gvisor.dev/gvisor/pkg/unet"
+	"gvisor.dev/gvisor/pkg/urpc"
+	"gvisor.dev/gvisor/pkg/usermem"
+	"gvisor.dev/gvisor/runsc/criu"
+	"gvisor.dev/gvisor/runsc/specutils"
+)
+
+// ImportCRIUArgs are the arguments to ContMgrImportCRIU.
+type ImportCRIUArgs struct {
+	// ContainerID is the container that imported processes are added to.
+	ContainerID string
+
+	// FilePayload contains a SOCK_SEQPACKET socket that the images of the
+	// dump are sent on until the sender shuts it down. Each message contains
+	// the name of an image and, as SCM_RIGHTS, the image file. The images are
+	// not in the payload itself, since dumps of a few dozen processes have
+	// more images than a call can carry.
+	urpc.FilePayload
+}
+
+// ImportedProcess maps a process imported from a CRIU dump to its PID in the
+// container.
+type ImportedProcess struct {
+	// DumpPID is the PID of the process when it was dumped.
+	DumpPID int32
+
+	// PID is the PID of the process in the container.
+	PID kernel.ThreadID
+}
+
+// criuCopyChunk is the maximum amount of memory contents that are copied at
+// once from a pages image.
+const criuCopyChunk = 1 << 20
+
+// importCRIU creates the processes of the CRIU dump in args in container
+// args.ContainerID, and starts them.
+func (l *Loader) importCRIU(args *ImportCRIUArgs) ([]ImportedProcess, error) {
+	if len(args.Files) != 1 {
+		return nil, fmt.Errorf("ImportCRIU requires exactly one file, got %d", len(args.Files))
+	}
+	sockFD, err := args.ReleaseFD(0)
+	if err != nil {
+		return nil, err
+	}
+	sock, err := unet.NewSocket(sockFD.Release())
+	if err != nil {
+		return nil, err
+	}
+	images, err := receiveCRIUImages(sock)
+	sock.Close()
+	if err != nil {
+		return nil, fmt.Errorf("receiving CRIU images: %w", err)
+	}
+	defer func() {
+		for _, f := range images {
+			_ = f.Close()
+		}
+	}()
+	open := func(name string) (io.ReadCloser, error) {
+		f, ok := images[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+		}
+		// Images may be read more than once, so don't use the file offset.
+		return io.NopCloser(io.NewSectionReader(f, 0, math.MaxInt64)), nil
+	}
+	dump, err := criu.Load(open)
+	if err != nil {
+		return nil, fmt.Errorf("loading CRIU dump: %w", err)
+	}
+
+	// Importing copies the memory of the dumped processes, which may take a
+	// long time, so l.mu is only held to look up the container and to add the
+	// imported processes to 'processes'.
+	l.mu.Lock()
+	tg, err := l.tryThreadGroupFromIDLocked(execID{cid: args.ContainerID})
+	spec := l.containerSpecs[l.k.ContainerName(args.ContainerID)]
+	l.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if tg == nil {
+		return nil, fmt.Errorf("container %q not started", args.ContainerID)
+	}
+	if spec == nil {
+		return nil, fmt.Errorf("spec of container %q not found", args.ContainerID)
+	}
+	limitSet, err := createLimitSet(spec, specutils.TPUProxyEnabled(spec, l.root.conf))
+	if err != nil {
+		return nil, fmt.Errorf("creating limits: %w", err)
+	}
+	imp := criuImporter{
+		l:       l,
+		k:       l.k,
+		cid:     args.ContainerID,
+		dump:    dump,
+		open:    open,
+		init:    tg.Leader(),
+		limits:  limitSet,
+		shm:     make(map[uint64]*vfs.FileDescription),
+		created: make(map[uint32]*kernel.ThreadGroup),
+	}
+	imported, err := imp.importAll()
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// The container may have been destroyed while its processes were being
+	// imported, in which case the imported processes must not outlive it.
+	if cur, err := l.tryThreadGroupFromIDLocked(execID{cid: args.ContainerID}); err != nil || cur != tg {
+		for _, p := range imported {
+			_ = imp.created[uint32(p.DumpPID)].SendSignal(kernel.SignalInfoPriv(linux.SIGKILL))
+		}
+		return nil, fmt.Errorf("container %q stopped while importing processes", args.ContainerID)
+	}
+	for _, p := range imported {
+		eid := execID{cid: args.ContainerID, pid: p.PID}
+		l.processes[eid] = &execProcess{tg: imp.created[uint32(p.DumpPID)]}
+	}
+	log.Debugf("updated processes: %v", l.processes)
+	return imported, nil
+}
+
+// receiveCRIUImages receives the images of a CRIU dump sent on sock, as
+// described by ImportCRIUArgs, and returns them by name.
+func receiveCRIUImages(sock *unet.Socket) (map[string]*os.File, error) {
+	images := make(map[string]*os.File)
+	cu := cleanup.Make(func() {
+		for _, f := range images {
+			_ = f.Close()
+		}
+	})
+	defer cu.Clean()
+
+	var name [linux.NAME_MAX]byte
+	for {
+		r := sock.Reader(true /* blocking */)
+		r.EnableFDs(1)
+		n, err := r.ReadVec([][]byte{name[:]})
+		if err == io.EOF {
+			cu.Release()
+			return images, nil
+		}
+		if err != nil {
+			r.CloseFDs()
+			return nil, err
+		}
+		fds, err := r.ExtractFDs()
+		if err != nil {
+			return nil, err
+		}
+		image := string(name[:n])
+		if len(fds) != 1 {
+			for _, fd := range fds {
+				_ = unix.Close(fd)
+			}
+			return nil, fmt.Errorf("got %d files for image %q, want 1", len(fds), image)
+		}
+		f := os.NewFile(uintptr(fds[0]), image)
+		if _, ok := images[image]; ok {
+			_ = f.Close()
+			return nil, fmt.Errorf("got image %q more than once", image)
+		}
+		images[image] = f
+	}
+}
+
+// criuImporter imports the processes of a CRIU dump into a container.
+type criuImporter struct {
+	l    *Loader
+	k    *kernel.Kernel
+	cid  string
+	dump *criu.Dump
+	open criu.Opener
+
+	// init is the leader of the container's init process. Imported processes
+	// share its namespaces and credentials.
+	init *kernel.Task
+
+	// limits are the resource limits of the container, as given by its spec.
+	// Each imported process gets a copy.
+	limits *limits.LimitSet
+
+	// shm are the files backing shared anonymous memory, by shmid.
+	shm map[uint64]*vfs.FileDescription
+
+	// created are the processes that were created, by dumped PID.
+	created map[uint32]*kernel.ThreadGroup
+}
+
+// importAll creates all processes of the dump, and starts them once they are
+// all restored. If any process can't be imported, none of them run.
+func (imp *criuImporter) importAll() ([]ImportedProcess, error) {
+	ctx := imp.k.SupervisorContext()
+	defer func() {
+		for _, file := range imp.shm {
+			file.DecRef(ctx)
+		}
+	}()
+
+	var tgs []*kernel.ThreadGroup
+	cu := cleanup.Make(func() {
+		// Processes can't be destroyed before they run, so kill them and let
+		// them exit.
+		for _, tg := range tgs {
+			_ = tg.SendSignal(kernel.SignalInfoPriv(linux.SIGKILL))
+			imp.k.StartProcess(tg)
+		}
+	})
+	defer cu.Clean()
+
+	var imported []ImportedProcess
+	for _, p := range imp.dump.Processes {
+		tg, tid, err := imp.importProcess(p, func(tg *kernel.ThreadGroup) { tgs = append(tgs, tg) })
+		if err != nil {
+			return nil, fmt.Errorf("importing process %d: %w", p.PID, err)
+		}
+		imp.created[p.PID] = tg
+		imported = append(imported, ImportedProcess{DumpPID: int32(p.PID), PID: tid})
+		log.Infof("Imported CRIU process %d (%s) as PID %d in container %q", p.PID, p.Comm, tid, imp.cid)
+	}
+
+	cu.Release()
+	for _, tg := range tgs {
+		imp.k.StartProcess(tg)
+	}
+	return imported, nil
+}
+
+// importProcess creates the process p, and restores its state. created is
+// called once the process is created, even if restoring it fails.
+func (imp *criuImporter) importProcess(p *criu.Process, created func(*kernel.ThreadGroup)) (*kernel.ThreadGroup, kernel.ThreadID, error) {
+	if len(p.Threads) != 1 {
+		return nil, 0, fmt.Errorf("process has %d threads, only single-threaded processes are supported", len(p.Threads))
+	}
+	exe, ok := imp.dump.Files[p.MM.ExeFileID]
+	if !ok {
+		return nil, 0, fmt.Errorf("executable file %d isn't in the dump", p.MM.ExeFileID)
+	}
+	umask := uint(0022)
+	var wd string
+	if p.HasFS {
+		if root, ok := imp.dump.Files[p.RootID]; !ok || root.Name != "/" {
+			return nil, 0, fmt.Errorf("processes with a different root directory are not supported")
+		}
+		cwd, ok := imp.dump.Files[p.CwdID]
+		if !ok {
+			return nil, 0, fmt.Errorf("working directory %d isn't in the dump", p.CwdID)
+		}
+		wd = cwd.Name
+		umask = uint(p.Umask)
+	}
+
+	// The reference is donated to CreateProcess.
+	mntns := imp.init.MountNamespace()
+	if mntns == nil || !mntns.TryIncRef() {
+		return nil, 0, fmt.Errorf("container %q has stopped", imp.cid)
+	}
+	ctx := imp.k.SupervisorContext()
+	fdTable := imp.k.NewFDTable()
+	defer fdTable.DecRef(ctx)
+
+	// The executable is loaded as for execve, and then its memory is replaced
+	// with the dumped memory.
+	tg, tid, err := imp.k.CreateProcess(kernel.CreateProcessArgs{
+		Filename:             exe.Name,
+		Argv:                 []string{exe.Name},
+		WorkingDirectory:     wd,
+		Credentials:          imp.init.Credentials(),
+		FDTable:              fdTable,
+		Umask:                umask,
+		Limits:               imp.limits.GetCopy(),
+		MaxSymlinkTraversals: linux.MaxSymlinkTraversals,
+		UTSNamespace:         imp.init.UTSNamespace(),
+		IPCNamespace:         imp.init.IPCNamespace(),
+		PIDNamespace:         imp.init.PIDNamespace(),
+		MountNamespace:       mntns,
+		ContainerID:          imp.cid,
+		Origin:               kernel.OriginExec,
+		Parent:               imp.created[p.PPID],
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	created(tg)
+
+	t := tg.Leader()
+	tctx := t.AsyncContext()
+	t.SetName(p.Comm)
+	if err := restoreCRIURegs(t, p); err != nil {
+		return nil, 0, fmt.Errorf("restoring registers: %w", err)
+	}
+	if err := restoreCRIUSignals(t, p); err != nil {
+		return nil, 0, fmt.Errorf("restoring signal actions: %w", err)
+	}
+	if err := imp.restoreFDs(tctx, t, fdTable, p); err != nil {
+		return nil, 0, fmt.Errorf("restoring FDs: %w", err)
+	}
+	if err := imp.restoreMemory(tctx, t, p); err != nil {
+		return nil, 0, fmt.Errorf("restoring memory: %w", err)
+	}
+	return tg, tid, nil
+}
+
+// openFile opens the dumped file rf in the mount namespace of t.
+func (imp *criuImporter) openFile(ctx context.Context, t *kernel.Task, rf *criu.RegFile) (*vfs.FileDescription, error) {
+	root := t.FSContext().RootDirectory()
+	defer root.DecRef(ctx)
+	pop := vfs.PathOperation{
+		Root:               root,
+		Start:              root,
+		Path:               fspath.Parse(rf.Name),
+		FollowFinalSymlink: true,
+	}
+	// The file must already exist, and mustn't be changed by reopening it.
+	flags := rf.Flags &^ (linux.O_CREAT | linux.O_EXCL | linux.O_TRUNC | linux.O_NOCTTY)
+	file, err := imp.k.VFS().OpenAt(ctx, t.Credentials(), &pop, &vfs.OpenOptions{Flags: flags})
+	if err != nil {
+		return nil, fmt.Errorf("opening %q: %w", rf.Name, err)
+	}
+	if rf.Pos != 0 {
+		if _, err := file.Seek(ctx, int64(rf.Pos), linux.SEEK_SET); err != nil {
+			file.DecRef(ctx)
+			return nil, fmt.Errorf("seeking %q: %w", rf.Name, err)
+		}
+	}
+	return file, nil
+}
+
+// restoreFDs installs the dumped FDs of p in fdTable.
+//
+// Regular files are reopened by path. Other standard FDs are replaced by the
+// corresponding FDs of the container's init process, since they are usually
+// pipes or terminals set up by the container runtime.
+func (imp *criuImporter) restoreFDs(ctx context.Context, t *kernel.Task, fdTable *kernel.FDTable, p *criu.Process) error {
+	for _, fd := range p.FDs {
+		var file *vfs.FileDescription
+		switch {
+		case fd.Type == criu.FDTypeReg:
+			rf, ok := imp.dump.Files[fd.ID]
+			if !ok {
+				return fmt.Errorf("FD %d: file %d isn't in the dump", fd.FD, fd.ID)
+			}
+			var err error
+			if file, err = imp.openFile(ctx, t, rf); err != nil {
+				return fmt.Errorf("FD %d: %w", fd.FD, err)
+			}
+		case fd.FD <= 2:
+			imp.init.WithMuLocked(func(leader *kernel.Task) {
+				if fdt := leader.FDTable(); fdt != nil {
+					file, _ = fdt.Get(int32(fd.FD))
+				}
+			})
+			if file == nil {
+				log.Warningf("Dropping FD %d of imported process: container %q has no such FD", fd.FD, imp.cid)
+				continue
+			}
+		default:
+			return fmt.Errorf("FD %d has unsupported type %d", fd.FD, fd.Type)
+		}
+		df, err := fdTable.NewFDAt(ctx, int32(fd.FD), file, kernel.FDFlags{CloseOnExec: fd.Flags&linux.FD_CLOEXEC != 0})
+		file.DecRef(ctx)
+		if df != nil {
+			df.DecRef(ctx)
+		}
+		if err != nil {
+			return fmt.Errorf("FD %d: %w", fd.FD, err)
+		}
+	}
+	return nil
+}
+
+// restoreMemory replaces the memory of t, which was set up by loading its
+// executable, with the dumped memory of p.
+func (imp *criuImporter) restoreMemory(ctx context.Context, t *kernel.Task, p *criu.Process) error {
+	m := t.MemoryManager()
+
+	// gVisor's vDSO is kept, since the dumped vDSO is replaced by trampolines
+	// into it.
+	var vdso, vvar hostarch.AddrRange
+	var loaded []hostarch.AddrRange
+	m.ReadMapsDataInto(ctx, func(start, end hostarch.Addr, _ hostarch.AccessType, _ string, _ uint64, _, _ uint32, _ uint64, path string) {
+		ar := hostarch.AddrRange{start, end}
+		switch path {
+		case "[vdso]":
+			vdso = ar
+		case "[vvar]":
+			vvar = ar
+		case "[vsyscall]":
+			// Not a real mapping.
+		default:
+			loaded = append(loaded, ar)
+		}
+	})
+	for _, ar := range loaded {
+		if err := m.MUnmap(ctx, ar.Start, uint64(ar.Length())); err != nil {
+			return fmt.Errorf("unmapping %#x-%#x: %w", ar.Start, ar.End, err)
+		}
+	}
+
+	var oldVDSO hostarch.AddrRange
+	for i := range p.MM.VMAs {
+		vma := &p.MM.VMAs[i]
+		ar := hostarch.AddrRange{hostarch.Addr(vma.Start), hostarch.Addr(vma.End)}
+		if vma.Status&(criu.VMAAreaVVAR|criu.VMAAreaVsyscall) != 0 {
+			// The dumped vDSO doesn't use its data pages once it's replaced
+			// by trampolines, and vsyscall is emulated.
+			continue
+		}
+		if ar.Overlaps(vdso) || ar.Overlaps(vvar) {
+			// gVisor's vDSO is placed randomly, so this is unlikely to
+			// happen again.
+			return fmt.Errorf("mapping %#x-%#x overlaps the vDSO, retry the import", ar.Start, ar.End)
+		}
+		if vma.Status&criu.VMAAreaVDSO != 0 {
+			oldVDSO = ar
+		}
+		if err := imp.mapVMA(ctx, t, vma); err != nil {
+			return fmt.Errorf("mapping %#x-%#x: %w", ar.Start, ar.End, err)
+		}
+	}
+
+	if err := readCRIUPages(imp.open, p.PagesID, p.Pages, func(addr uint64, b []byte) error {
+		_, err := m.CopyOut(ctx, hostarch.Addr(addr), b, usermem.IOOpts{IgnorePermissions: true})
+		return err
+	}); err != nil {
+		return err
+	}
+	if oldVDSO.Length() != 0 {
+		if err := redirectCRIUVDSO(ctx, m, oldVDSO, vdso); err != nil {
+			return fmt.Errorf("redirecting vDSO: %w", err)
+		}
+	}
+
+	if err := m.UpdateLayout(ctx, func(l *mm.Layout) {
+		*l = mm.Layout{
+			StartCode:  hostarch.Addr(p.MM.StartCode),
+			EndCode:    hostarch.Addr(p.MM.EndCode),
+			StartData:  hostarch.Addr(p.MM.StartData),
+			EndData:    hostarch.Addr(p.MM.EndData),
+			StartBrk:   hostarch.Addr(p.MM.StartBrk),
+			Brk:        hostarch.Addr(p.MM.Brk),
+			StartStack: hostarch.Addr(p.MM.StartStack),
+			ArgStart:   hostarch.Addr(p.MM.ArgStart),
+			ArgEnd:     hostarch.Addr(p.MM.ArgEnd),
+			EnvStart:   hostarch.Addr(p.MM.EnvStart),
+			EnvEnd:     hostarch.Addr(p.MM.EnvEnd),
+		}
+	}); err != nil {
+		return fmt.Errorf("setting layout: %w", err)
+	}
+
+	var auxv arch.Auxv
+	for i := 0; i+1 < len(p.MM.Auxv) && p.MM.Auxv[i] != linux.AT_NULL; i += 2 {
+		e := arch.AuxEntry{Key: p.MM.Auxv[i], Value: hostarch.Addr(p.MM.Auxv[i+1])}
+		if e.Key == linux.AT_SYSINFO_EHDR {
+			e.Value = vdso.Start
+		}
+		auxv = append(auxv, e)
+	}
+	m.SetAuxv(auxv)
+	return nil
+}
+
+// mapVMA maps the dumped mapping vma in the memory of t. Its contents, other
+// than those of files, are restored separately.
+func (imp *criuImporter) mapVMA(ctx context.Context, t *kernel.Task, vma *criu.VMA) error {
+	const unsupported = criu.VMAAreaSysVIPC | criu.VMAAreaSocket | criu.VMAAreaAIORing | criu.VMAAreaMemfd | criu.VMAAreaUnsupport
+	if vma.Status&unsupported != 0 || vma.Status&criu.VMAAreaRegular == 0 {
+		return fmt.Errorf("unsupported mapping with status %#x", vma.Status)
+	}
+	shared := vma.Flags&linux.MAP_SHARED != 0
+	opts := memmap.MMapOpts{
+		Length:  vma.End - vma.Start,
+		Addr:    hostarch.Addr(vma.Start),
+		Fixed:   true,
+		Unmap:   true,
+		Private: !shared,
+		Perms: hostarch.AccessType{
+			Read:    linux.PROT_READ&vma.Prot != 0,
+			Write:   linux.PROT_WRITE&vma.Prot != 0,
+			Execute: linux.PROT_EXEC&vma.Prot != 0,
+		},
+		MaxPerms:  hostarch.AnyAccess,
+		GrowsDown: linux.MAP_GROWSDOWN&vma.Flags != 0,
+		Stack:     vma.Status&criu.VMAAreaStack != 0,
+	}
+	defer func() {
+		if opts.MappingIdentity != nil {
+			opts.MappingIdentity.DecRef(ctx)
+		}
+	}()
+
+	switch {
+	case vma.Status&criu.VMAAreaVDSO != 0:
+		// The dumped vDSO is copied into anonymous memory and redirected to
+		// gVisor's vDSO by redirectCRIUVDSO.
+		opts.Private = true
+	case vma.Status&(criu.VMAFilePrivate|criu.VMAFileShared) != 0:
+		rf, ok := imp.dump.Files[uint32(vma.Shmid)]
+		if !ok {
+			return fmt.Errorf("mapped file %d isn't in the dump", vma.Shmid)
+		}
+		flags := uint32(linux.O_RDONLY)
+		if shared && opts.Perms.Write {
+			flags = linux.O_RDWR
+		}
+		file, err := imp.openFile(ctx, t, &criu.RegFile{Name: rf.Name, Flags: flags})
+		if err != nil {
+			return err
+		}
+		defer file.DecRef(ctx)
+		if shared && !file.IsWritable() {
+			opts.MaxPerms.Write = false
+		}
+		opts.Offset = vma.Pgoff
+		if err := file.ConfigureMMap(ctx, &opts); err != nil {
+			return err
+		}
+	case vma.Status&criu.VMAAnonShared != 0:
+		file, err := imp.shmFile(ctx, t, vma.Shmid)
+		if err != nil {
+			return err
+		}
+		opts.Offset = vma.Pgoff
+		if err := file.ConfigureMMap(ctx, &opts); err != nil {
+			return err
+		}
+	default:
+		opts.NameMut = memmap.NameMutAnon
+	}
+	_, err := t.MemoryManager().MMap(ctx, opts)
+	return err
+}
+
+// shmFile returns the file backing the shared anonymous memory identified by
+// shmid, creating it and restoring its contents on first use.
+func (imp *criuImporter) shmFile(ctx context.Context, t *kernel.Task, shmid uint64) (*vfs.FileDescription, error) {
+	if file, ok := imp.shm[shmid]; ok {
+		return file, nil
+	}
+	// The memory must be large enough for all of its mappings, in any
+	// process.
+	var size uint64
+	for _, p := range imp.dump.Processes {
+		for _, vma := range p.MM.VMAs {
+			if vma.Status&criu.VMAAnonShared != 0 && vma.Shmid == shmid {
+				size = max(size, vma.Pgoff+vma.End-vma.Start)
+			}
+		}
+	}
+	file, err := tmpfs.NewZeroFile(ctx, t.Credentials(), imp.k.ShmMount(), size)
+	if err != nil {
+		return nil, err
+	}
+	imp.shm[shmid] = file
+
+	pagesID, entries, err := criu.ReadPagemap(imp.open, criu.ShmemPagemapName(shmid))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			// None of the memory was written.
+			return file, nil
+		}
+		return nil, err
+	}
+	if err := readCRIUPages(imp.open, pagesID, entries, func(off uint64, b []byte) error {
+		_, err := file.PWrite(ctx, usermem.BytesIOSequence(b), int64(off), vfs.WriteOptions{})
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("restoring shared memory %d: %w", shmid, err)
+	}
+	return file, nil
+}
+
+// readCRIUPages reads the contents of the pages described by entries from the
+// pages image with the given ID, and passes them to write with the address of
+// each chunk.
+func readCRIUPages(open criu.Opener, id uint32, entries []criu.PagemapEntry, write func(addr uint64, b []byte) error) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	name := criu.PagesName(id)
+	f, err := open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	buf := make([]byte, criuCopyChunk)
+	for _, e := range entries {
+		addr := e.Vaddr
+		for left := uint64(e.NrPages) * hostarch.PageSize; left > 0; {
+			b := buf[:min(left, uint64(len(buf)))]
+			if _, err := io.ReadFull(f, b); err != nil {
+				return fmt.Errorf("reading %s: %w", name, err)
+			}
+			if err := write(addr, b); err != nil {
+				return fmt.Errorf("writing %#x-%#x: %w", addr, addr+uint64(len(b)), err)
+			}
+			addr += uint64(len(b))
+			left -= uint64(len(b))
+		}
+	}
+	return nil
+}
+
+// redirectCRIUVDSO replaces each function of the dumped vDSO at old with a
+// trampoline into the function of the same name in gVisor's vDSO at vdso.
+// Functions that gVisor's vDSO doesn't have fail with ENOSYS.
+//
+// The imported process keeps pointers into the dumped vDSO, e.g. in the
+// libc, so the dumped vDSO stays where it was.
+func redirectCRIUVDSO(ctx context.Context, m *mm.MemoryManager, old, vdso hostarch.AddrRange) error {
+	oldSyms, err := readVDSOSymbols(ctx, m, old)
+	if err != nil {
+		return fmt.Errorf("dumped vDSO: %w", err)
+	}
+	newSyms, err := readVDSOSymbols(ctx, m, vdso)
+	if err != nil {
+		return fmt.Errorf("gVisor vDSO: %w", err)
+	}
+	for name, off := range oldSyms {
+		code := criuVDSOENOSYS()
+		if newOff, ok := newSyms[name]; ok {
+			code = criuVDSOJump(uint64(vdso.Start) + newOff)
+		} else {
+			log.Warningf("gVisor's vDSO has no %q, calls to it from the imported process fail with ENOSYS", name)
+		}
+		if off+uint64(len(code)) > uint64(old.Length()) {
+			return fmt.Errorf("function %q at offset %#x is out of bounds", name, off)
+		}
+		if _, err := m.CopyOut(ctx, old.Start+hostarch.Addr(off), code, usermem.IOOpts{IgnorePermissions: true}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// criuMaxVDSOSize is the maximum size of a vDSO whose symbols are read. vDSOs
+// are a few pages long.
+const criuMaxVDSOSize = 1 << 20
+
+// readVDSOSymbols returns the offsets of the functions exported by the vDSO
+// mapped at ar.
+func readVDSOSymbols(ctx context.Context, m *mm.MemoryManager, ar hostarch.AddrRange) (map[string]uint64, error) {
+	if ar.Length() > criuMaxVDSOSize {
+		return nil, fmt.Errorf("vDSO size %d exceeds the maximum of %d", ar.Length(), criuMaxVDSOSize)
+	}
+	image := make([]byte, ar.Length())
+	if _, err := m.CopyIn(ctx, ar.Start, image, usermem.IOOpts{IgnorePermissions: true}); err != nil {
+		return nil, err
+	}
+	f, err := elf.NewFile(bytes.NewReader(image))
+	if err != nil {
+		return nil, fmt.Errorf("parsing ELF: %w", err)
+	}
+	// Symbol values are relative to the link address of the first segment.
+	var base uint64
+	for _, prog := range f.Progs {
+		if prog.Type == elf.PT_LOAD {
+			base = prog.Vaddr - prog.Off
+			break
+		}
+	}
+	dynsyms, err := f.DynamicSymbols()
+	if err != nil {
+		return nil, fmt.Errorf("reading symbols: %w", err)
+	}
+	syms := make(map[string]uint64)
+	for _, sym := range dynsyms {
+		if elf.ST_TYPE(sym.Info) != elf.STT_FUNC || sym.Section == elf.SHN_UNDEF || sym.Value < base {
+			continue
+		}
+		syms[sym.Name] = sym.Value - base
+	}
+	return syms, nil
+}
+
+// restoreCRIUSignals restores the signal actions and signal mask of p in t.
+func restoreCRIUSignals(t *kernel.Task, p *criu.Process) error {
+	for sig, act := range p.SigActions {
+		if _, err := t.ThreadGroup().SetSigAction(linux.Signal(sig), &linux.SigAction{
+			Handler:  act.Handler,
+			Flags:    act.Flags,
+			Restorer: act.Restorer,
+			Mask:     linux.SignalSet(act.Mask),
+		}); err != nil {
+			return fmt.Errorf("signal %d: %w", sig, err)
+		}
+	}
+	t.SetSignalMask(linux.SignalSet(p.BlockedSignals))
+	return nil
+}
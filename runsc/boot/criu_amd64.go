@@ -0,0 +1,168 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/abi/linux/errno"
+	"gvisor.dev/gvisor/pkg/cpuid"
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
+	"gvisor.dev/gvisor/runsc/criu"
+)
+
+const (
+	// criuFXSAVESize is the size of the FXSAVE area.
+	criuFXSAVESize = 512
+
+	// criuXSAVEYMMHOffset is the offset of the upper halves of the YMM
+	// registers in the standard format of the XSAVE area, which follows the
+	// FXSAVE area and the 64-byte XSAVE header.
+	criuXSAVEYMMHOffset = criuFXSAVESize + 64
+
+	// criuXSAVESize is the size of the XSAVE area with x87, SSE and AVX
+	// state.
+	criuXSAVESize = criuXSAVEYMMHOffset + 256
+
+	// criuXFeatures are the state components that are imported: x87, SSE
+	// and AVX.
+	criuXFeatures = 0x7
+)
+
+// restoreCRIURegs restores the registers of the main thread of p in t.
+func restoreCRIURegs(t *kernel.Task, p *criu.Process) error {
+	r := p.Regs
+	// A syscall that was interrupted by the dump is restarted, as it would
+	// have been by signal delivery; compare Linux's
+	// arch/x86/kernel/signal.c:arch_do_signal_or_restart().
+	if int64(r.OrigAx) >= 0 {
+		switch int64(r.Ax) {
+		case -errno.ERESTARTSYS, -errno.ERESTARTNOINTR, -errno.ERESTARTNOHAND:
+			r.Ax = r.OrigAx
+			r.IP -= 2 // Length of the syscall instruction.
+		case -errno.ERESTART_RESTARTBLOCK:
+			// The restart block of the syscall was lost with the
+			// kernel that interrupted it.
+			eintr := -int64(errno.EINTR)
+			r.Ax = uint64(eintr)
+		}
+	}
+	regs := linux.PtraceRegs{
+		R15:      r.R15,
+		R14:      r.R14,
+		R13:      r.R13,
+		R12:      r.R12,
+		Rbp:      r.Bp,
+		Rbx:      r.Bx,
+		R11:      r.R11,
+		R10:      r.R10,
+		R9:       r.R9,
+		R8:       r.R8,
+		Rax:      r.Ax,
+		Rcx:      r.Cx,
+		Rdx:      r.Dx,
+		Rsi:      r.Si,
+		Rdi:      r.Di,
+		Orig_rax: r.OrigAx,
+		Rip:      r.IP,
+		Cs:       r.CS,
+		Eflags:   r.Flags,
+		Rsp:      r.SP,
+		Ss:       r.SS,
+		Fs_base:  r.FSBase,
+		Gs_base:  r.GSBase,
+		Ds:       r.DS,
+		Es:       r.ES,
+		Fs:       r.FS,
+		Gs:       r.GS,
+	}
+	buf := make([]byte, regs.SizeBytes())
+	regs.MarshalUnsafe(buf)
+	if _, err := t.Arch().PtraceSetRegs(bytes.NewReader(buf)); err != nil {
+		return err
+	}
+
+	fp := p.FPRegs
+	if fp == nil {
+		return nil
+	}
+	// Build the FXSAVE area; see Intel SDM Vol. 1, Table 10-2 "Format of an
+	// FXSAVE Area".
+	fxsave := make([]byte, criuFXSAVESize)
+	binary.LittleEndian.PutUint16(fxsave[0:], uint16(fp.Cwd))
+	binary.LittleEndian.PutUint16(fxsave[2:], uint16(fp.Swd))
+	fxsave[4] = byte(fp.Twd)
+	binary.LittleEndian.PutUint16(fxsave[6:], uint16(fp.Fop))
+	binary.LittleEndian.PutUint64(fxsave[8:], fp.RIP)
+	binary.LittleEndian.PutUint64(fxsave[16:], fp.RDP)
+	binary.LittleEndian.PutUint32(fxsave[24:], fp.MXCSR)
+	binary.LittleEndian.PutUint32(fxsave[28:], fp.MXCSRMask)
+	for i, v := range fp.STSpace {
+		if off := 32 + 4*i; off < 160 {
+			binary.LittleEndian.PutUint32(fxsave[off:], v)
+		}
+	}
+	for i, v := range fp.XMMSpace {
+		if off := 160 + 4*i; off < 416 {
+			binary.LittleEndian.PutUint32(fxsave[off:], v)
+		}
+	}
+	if fp.Xsave == nil {
+		_, err := t.Arch().FloatingPointData().PtraceSetFPRegs(bytes.NewReader(fxsave), criuFXSAVESize)
+		return err
+	}
+
+	// Only x87, SSE and AVX state is imported. Other components are only
+	// accepted in their initial (all zero) state.
+	for num, vs := range fp.Xsave.Other {
+		for _, v := range vs {
+			if v != 0 {
+				return fmt.Errorf("extended register state %d (XSTATE_BV %#x) is not supported", num, fp.Xsave.XstateBV)
+			}
+		}
+	}
+	xstateBV := fp.Xsave.XstateBV&criuXFeatures | 0x3
+	fs := t.Kernel().FeatureSet()
+	if xstateBV&0x4 != 0 && !fs.HasFeature(cpuid.X86FeatureAVX) {
+		return fmt.Errorf("AVX register state can't be restored without AVX")
+	}
+	xsave := make([]byte, criuXSAVESize)
+	copy(xsave, fxsave)
+	binary.LittleEndian.PutUint64(xsave[criuFXSAVESize:], xstateBV)
+	for i, v := range fp.Xsave.YMMHSpace {
+		if off := criuXSAVEYMMHOffset + 4*i; off < criuXSAVESize {
+			binary.LittleEndian.PutUint32(xsave[off:], v)
+		}
+	}
+	_, err := t.Arch().FloatingPointData().PtraceSetXstateRegs(bytes.NewReader(xsave), criuXSAVESize, fs)
+	return err
+}
+
+// criuVDSOJump returns the code of a trampoline that jumps to addr:
+// "jmp *0(%rip)" followed by addr.
+func criuVDSOJump(addr uint64) []byte {
+	return binary.LittleEndian.AppendUint64([]byte{0xff, 0x25, 0, 0, 0, 0}, addr)
+}
+
+// criuVDSOENOSYS returns the code of a function that fails with ENOSYS:
+// "mov $-ENOSYS, %rax; ret".
+func criuVDSOENOSYS() []byte {
+	enosys := -int32(errno.ENOSYS)
+	code := binary.LittleEndian.AppendUint32([]byte{0x48, 0xc7, 0xc0}, uint32(enosys))
+	return append(code, 0xc3)
+}
@@ -0,0 +1,39 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"fmt"
+
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
+	"gvisor.dev/gvisor/runsc/criu"
+)
+
+// restoreCRIURegs restores the registers of the main thread of p in t.
+//
+// CRIU dumps of x86-64 processes can't be imported on arm64.
+func restoreCRIURegs(t *kernel.Task, p *criu.Process) error {
+	return fmt.Errorf("importing CRIU dumps is not supported on arm64")
+}
+
+// criuVDSOJump is not used on arm64, since restoreCRIURegs fails.
+func criuVDSOJump(addr uint64) []byte {
+	return nil
+}
+
+// criuVDSOENOSYS is not used on arm64, since restoreCRIURegs fails.
+func criuVDSOENOSYS() []byte {
+	return nil
+}
@@ -19,11 +19,20 @@ import (
 	"strconv"
 	"strings"
 
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/fspath"
 	"gvisor.dev/gvisor/pkg/log"
 	"gvisor.dev/gvisor/pkg/sentry/control"
+	"gvisor.dev/gvisor/pkg/sentry/fsimpl/tmpfs"
+	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
 	"gvisor.dev/gvisor/pkg/sentry/usage"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
 )
 
+// shmPath is the mount point of POSIX shared memory in containers.
+const shmPath = "/dev/shm"
+
 // NetworkInterface is the network statistics of the particular network interface
 type NetworkInterface struct {
 	// Name is the name of the network interface.
@@ -61,6 +70,17 @@ type Stats struct {
 	Memory            Memory              `json:"memory"`
 	Pids              Pids                `json:"pids"`
 	NetworkInterfaces []*NetworkInterface `json:"network_interfaces"`
+	Shm               *Shm                `json:"shm,omitempty"`
+	RootfsUpper       *RootfsUpper        `json:"rootfs_upper,omitempty"`
+}
+
+// Shm contains stats on the container's POSIX shared memory, i.e. the tmpfs
+// mounted at /dev/shm.
+type Shm struct {
+	// Usage is the number of bytes used by the container.
+	Usage uint64 `json:"usage"`
+	// Limit is the size limit of the container's /dev/shm in bytes.
+	Limit uint64 `json:"limit"`
 }
 
 // Pids contains stats on processes.
@@ -89,7 +109,8 @@ type Memory struct {
 
 // CPU contains stats on the CPU.
 type CPU struct {
-	Usage CPUUsage `json:"usage"`
+	Usage      CPUUsage      `json:"usage"`
+	Throttling CPUThrottling `json:"throttling,omitempty"`
 }
 
 // CPUUsage contains stats on CPU usage.
@@ -192,6 +213,25 @@ func (cm *containerManager) Event(cid *string, out *EventOut) error {
 	}
 	out.Event.Data.Memory.Usage.Usage = memUsage
 
+	// /dev/shm usage. Exhausting it fails writes with ENOSPC or kills
+	// processes faulting on shared mappings with SIGBUS, so it's reported
+	// separately from memory usage.
+	shm, err := cm.l.shmStats(*cid)
+	if err != nil {
+		log.Warningf("could not get container /dev/shm usage, error: %v", err)
+	}
+	out.Event.Data.Shm = shm
+
+	// Rootfs overlay upper layer usage.
+	rootfsUpper, err := cm.l.overlayUsage.stats(*cid)
+	if err != nil {
+		log.Warningf("could not get container rootfs upper layer usage, error: %v", err)
+	}
+	out.Event.Data.RootfsUpper = rootfsUpper
+
+	// CPU throttling by the sentry, if enabled.
+	out.Event.Data.CPU.Throttling = cm.l.cpuThrottling(*cid)
+
 	// CPU usage by container.
 	out.ContainerUsage, err = cm.getCPUUsageFromCgroups()
 	if err != nil {
@@ -248,3 +288,57 @@ func (cm *containerManager) getCPUUsageFromCgroups() (map[string]uint64, error)
 	}
 	return usage, nil
 }
+
+// shmStats returns stats on the tmpfs mounted at /dev/shm in the given
+// container. It returns nil if the container hasn't started or has no tmpfs
+// mounted there. If /dev/shm is shared with other containers, only the usage
+// and limit of the given container are reported.
+func (l *Loader) shmStats(cid string) (*Shm, error) {
+	l.mu.Lock()
+	tg, err := l.tryThreadGroupFromIDLocked(execID{cid: cid})
+	if err != nil || tg == nil {
+		l.mu.Unlock()
+		return nil, err
+	}
+	// task.MountNamespace() does not take a ref, so we must do so ourselves.
+	mns := tg.Leader().MountNamespace()
+	if mns == nil || !mns.TryIncRef() {
+		l.mu.Unlock()
+		return nil, nil
+	}
+	l.mu.Unlock()
+
+	ctx := l.k.SupervisorContext()
+	defer mns.DecRef(ctx)
+	root := mns.Root(ctx)
+	defer root.DecRef(ctx)
+	creds := auth.NewRootCredentials(l.k.RootUserNamespace())
+	pop := &vfs.PathOperation{
+		Root:               root,
+		Start:              root,
+		Path:               fspath.Parse(shmPath),
+		FollowFinalSymlink: true,
+	}
+	vd, err := l.k.VFS().GetDentryAt(ctx, creds, pop, &vfs.GetDentryOptions{})
+	if err != nil {
+		if linuxerr.Equals(linuxerr.ENOENT, err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer vd.DecRef(ctx)
+	if usage, limit, ok := tmpfs.MountSizeUsage(vd.Mount()); ok {
+		return &Shm{Usage: usage, Limit: limit}, nil
+	}
+	statfs, err := l.k.VFS().StatFSAt(ctx, creds, pop)
+	if err != nil {
+		return nil, err
+	}
+	if statfs.Type != linux.TMPFS_MAGIC {
+		return nil, nil
+	}
+	return &Shm{
+		Usage: (statfs.Blocks - statfs.BlocksFree) * uint64(statfs.BlockSize),
+		Limit: statfs.Blocks * uint64(statfs.BlockSize),
+	}, nil
+}
@@ -25,6 +25,7 @@ import (
 	"golang.org/x/sys/unix"
 	"gvisor.dev/gvisor/pkg/seccomp"
 	"gvisor.dev/gvisor/pkg/seccomp/precompiledseccomp"
+	"gvisor.dev/gvisor/pkg/sentry/devices/hostdev"
 	"gvisor.dev/gvisor/pkg/sentry/devices/nvproxy"
 	"gvisor.dev/gvisor/pkg/sentry/devices/nvproxy/nvconf"
 	"gvisor.dev/gvisor/pkg/sentry/devices/tpuproxy"
@@ -42,7 +43,9 @@ type Options struct {
 	NVProxy               bool
 	NVProxyCaps           nvconf.DriverCaps
 	TPUProxy              bool
+	HostDevices           bool
 	ControllerFD          uint32
+	ControlGrantFDs       []uint32
 	CgoEnabled            bool
 	PluginNetwork         bool
 }
@@ -58,7 +61,9 @@ func isInstrumentationEnabled() bool {
 // time with the same set of `Options` at runtime.
 // As such, it should encompass all fields that change the structure of
 // the seccomp rules, but should not encompass fields that are only known
-// at runtime (e.g. `ControllerFD`).
+// at runtime (e.g. `ControllerFD`). `ControlGrantFDs` is only set when
+// --control-policy is used and is not a variable, so such configurations are
+// never precompiled.
 func (opt Options) ConfigKey() string {
 	var sb strings.Builder
 	fmt.Fprintf(&sb, "GOARCH=%q ", runtime.GOARCH)
@@ -71,8 +76,10 @@ func (opt Options) ConfigKey() string {
 	fmt.Fprintf(&sb, "NVProxy=%t ", opt.NVProxy)
 	fmt.Fprintf(&sb, "NVProxyCaps=%v ", opt.NVProxyCaps)
 	fmt.Fprintf(&sb, "TPUProxy=%t ", opt.TPUProxy)
+	fmt.Fprintf(&sb, "HostDevices=%t ", opt.HostDevices)
 	fmt.Fprintf(&sb, "CgoEnabled=%t ", opt.CgoEnabled)
 	fmt.Fprintf(&sb, "PluginNetwork=%t ", opt.PluginNetwork)
+	fmt.Fprintf(&sb, "ControlGrantFDs=%v ", opt.ControlGrantFDs)
 	return strings.TrimSpace(sb.String())
 }
 
@@ -102,6 +109,9 @@ func Warnings(opt Options) []string {
 	if opt.TPUProxy {
 		warnings = append(warnings, "TPU device proxy enabled: syscall filters less restrictive!")
 	}
+	if opt.HostDevices {
+		warnings = append(warnings, "host device proxy enabled: syscall filters less restrictive!")
+	}
 	if opt.CgoEnabled {
 		warnings = append(warnings, "CGO enabled: syscall filters less restrictive!")
 	}
@@ -134,7 +144,7 @@ func Rules(opt Options) (seccomp.SyscallRules, seccomp.SyscallRules) {
 func rules(opt Options, vars precompiledseccomp.Values) (seccomp.SyscallRules, seccomp.SyscallRules) {
 	s := allowedSyscalls.Copy()
 	s.Merge(selfPIDFilters(vars.GetUint64(selfPIDVarName)))
-	s.Merge(controlServerFilters(vars[controllerFDVarName]))
+	s.Merge(controlServerFilters(vars[controllerFDVarName], opt.ControlGrantFDs))
 
 	// Set of additional filters used by -race and -msan. Returns empty
 	// when not enabled.
@@ -155,6 +165,9 @@ func rules(opt Options, vars precompiledseccomp.Values) (seccomp.SyscallRules, s
 	if opt.TPUProxy {
 		s.Merge(tpuproxy.Filters())
 	}
+	if opt.HostDevices {
+		s.Merge(hostdev.Filters())
+	}
 	if opt.CgoEnabled {
 		s.Merge(cgoFilters())
 	}
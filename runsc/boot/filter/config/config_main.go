@@ -45,8 +45,14 @@ var allowedSyscalls = seccomp.MakeSyscallRules(map[uintptr]seccomp.SyscallRule{
 	},
 	unix.SYS_EXIT:       seccomp.MatchAll{},
 	unix.SYS_EXIT_GROUP: seccomp.MatchAll{},
-	unix.SYS_FALLOCATE:  seccomp.MatchAll{},
-	unix.SYS_FCHMOD:     seccomp.MatchAll{},
+	unix.SYS_FADVISE64: seccomp.PerArg{
+		seccomp.AnyValue{},
+		seccomp.AnyValue{},
+		seccomp.AnyValue{},
+		seccomp.EqualTo(unix.FADV_WILLNEED),
+	},
+	unix.SYS_FALLOCATE: seccomp.MatchAll{},
+	unix.SYS_FCHMOD:    seccomp.MatchAll{},
 	unix.SYS_FCNTL: seccomp.Or{
 		seccomp.PerArg{
 			seccomp.AnyValue{},
@@ -345,8 +351,8 @@ var allowedSyscalls = seccomp.MakeSyscallRules(map[uintptr]seccomp.SyscallRule{
 	},
 })
 
-func controlServerFilters(fd uint32) seccomp.SyscallRules {
-	return seccomp.MakeSyscallRules(map[uintptr]seccomp.SyscallRule{
+func controlServerFilters(fd uint32, grantFDs []uint32) seccomp.SyscallRules {
+	rules := seccomp.MakeSyscallRules(map[uintptr]seccomp.SyscallRule{
 		unix.SYS_ACCEPT4: seccomp.PerArg{
 			seccomp.EqualTo(fd),
 		},
@@ -360,6 +366,18 @@ func controlServerFilters(fd uint32) seccomp.SyscallRules {
 			seccomp.EqualTo(unix.SO_PEERCRED),
 		},
 	})
+	for _, grantFD := range grantFDs {
+		rules.Merge(seccomp.MakeSyscallRules(map[uintptr]seccomp.SyscallRule{
+			unix.SYS_ACCEPT4: seccomp.PerArg{
+				seccomp.EqualTo(grantFD),
+			},
+			unix.SYS_LISTEN: seccomp.PerArg{
+				seccomp.EqualTo(grantFD),
+				seccomp.EqualTo(16 /* unet.backlog */),
+			},
+		}))
+	}
+	return rules
 }
 
 // selfPIDFilters contains syscall filters that depend on the process's PID.
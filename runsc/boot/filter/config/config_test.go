@@ -111,8 +111,10 @@ func TestOptionsConfigKey(t *testing.T) {
 		"NVProxy":               func(opt *Options) { opt.NVProxy = !opt.NVProxy },
 		"NVProxyCaps":           func(opt *Options) { opt.NVProxyCaps = ^opt.NVProxyCaps },
 		"TPUProxy":              func(opt *Options) { opt.TPUProxy = !opt.TPUProxy },
+		"HostDevices":           func(opt *Options) { opt.HostDevices = !opt.HostDevices },
 		"CgoEnabled":            func(opt *Options) { opt.CgoEnabled = !opt.CgoEnabled },
 		"PluginNetwork":         func(opt *Options) { opt.PluginNetwork = !opt.PluginNetwork },
+		"ControlGrantFDs":       func(opt *Options) { opt.ControlGrantFDs = append(opt.ControlGrantFDs, opt.ControllerFD+1) },
 	}
 
 	// Map of `Options` struct field names mapped to a function to mutate them.
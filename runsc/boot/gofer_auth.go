@@ -0,0 +1,89 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"fmt"
+
+	"gvisor.dev/gvisor/pkg/fd"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/unet"
+	"gvisor.dev/gvisor/runsc/goferauth"
+)
+
+// authenticateGofers authenticates the gofers at the other end of info's
+// lisafs sockets, in the order in which they are consumed by the container
+// mounter. It must be called before any of the sockets is used.
+//
+// This is done when the container starts rather than in New, because the
+// root container's gofer may not be serving yet when the sandbox boots.
+func authenticateGofers(info *containerInfo) error {
+	type goferConn struct {
+		fd *fd.FD
+		// mountIdx is the index of the mount in goferMountConfs, or -1 for the
+		// /dev gofer.
+		mountIdx int
+	}
+	var conns []goferConn
+	fdIdx := 0
+	for i, mountConf := range info.goferMountConfs {
+		switch {
+		case mountConf.ShouldUseLisafs():
+			if fdIdx >= len(info.goferFDs) {
+				return fmt.Errorf("no gofer FD found for mount %d of container %q", i, info.cid)
+			}
+			conns = append(conns, goferConn{fd: info.goferFDs[fdIdx], mountIdx: i})
+			fdIdx++
+		case mountConf.ShouldUseErofs():
+			fdIdx++
+		}
+	}
+	if info.devGoferFD != nil {
+		conns = append(conns, goferConn{fd: info.devGoferFD, mountIdx: -1})
+	}
+	if len(conns) == 0 {
+		return nil
+	}
+	if info.goferAuthKey == nil {
+		if info.conf.GoferAuth {
+			return fmt.Errorf("gofer authentication is enabled, but no key was provided for container %q", info.cid)
+		}
+		return nil
+	}
+
+	for i, conn := range conns {
+		mount, err := authenticateGofer(conn.fd, info.goferAuthKey, info.cid)
+		if err != nil {
+			return fmt.Errorf("authenticating gofer connection %d (FD %d) of container %q: %w", i, conn.fd.FD(), info.cid, err)
+		}
+		log.Infof("Authenticated gofer connection %d (FD %d) of container %q, mount index %d, serving %q", i, conn.fd.FD(), info.cid, conn.mountIdx, mount)
+	}
+	// The key is no longer needed.
+	info.goferAuthKey = nil
+	return nil
+}
+
+// authenticateGofer runs the sentry side of the gofer handshake on f, which
+// remains owned by the caller. It returns the mount point that the gofer
+// serves on f.
+func authenticateGofer(f *fd.FD, key goferauth.Key, cid string) (string, error) {
+	sock, err := unet.NewSocket(f.FD())
+	if err != nil {
+		return "", err
+	}
+	// Give the FD back to f without closing it.
+	defer sock.Release()
+	return goferauth.Authenticate(sock, key, cid)
+}
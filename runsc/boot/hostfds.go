@@ -0,0 +1,166 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"fmt"
+	"sort"
+
+	"gvisor.dev/gvisor/pkg/fdnotifier"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+)
+
+// HostFDOwner describes what holds a host FD in the sentry.
+type HostFDOwner struct {
+	// FD is the host FD.
+	FD int32 `json:"fd"`
+
+	// Owner is a short description of what holds FD.
+	Owner string `json:"owner"`
+}
+
+// hostFD is implemented by file descriptions backed by a host FD.
+type hostFD interface {
+	HostFD() int
+}
+
+// hostFDOwners returns the owners of the host FDs held by the sentry that it
+// can attribute, keyed by FD. Host FDs that aren't attributed aren't
+// necessarily leaked, e.g. FDs held by the platform or by the Go runtime
+// aren't attributed.
+func (l *Loader) hostFDOwners() map[int]string {
+	owners := make(map[int]string)
+	add := func(fd int, owner string) {
+		if fd < 0 {
+			return
+		}
+		// More specific owners are added first.
+		if _, ok := owners[fd]; !ok {
+			owners[fd] = owner
+		}
+	}
+
+	add(l.ctrl.srv.FD(), "control server socket")
+	add(l.k.MemoryFile().FD(), "memory file")
+
+	// Application FDs backed by host FDs, e.g. passed stdio, host files and
+	// hostinet sockets.
+	ctx := l.k.SupervisorContext()
+	ts := l.k.TaskSet()
+	for _, tg := range ts.Root.ThreadGroups() {
+		leader := tg.Leader()
+		if leader == nil {
+			continue
+		}
+		pid := ts.Root.IDOfThreadGroup(tg)
+		cid := leader.ContainerID()
+		leader.WithMuLocked(func(t *kernel.Task) {
+			fdTable := t.FDTable()
+			if fdTable == nil {
+				return
+			}
+			fdTable.ForEach(ctx, func(fd int32, file *vfs.FileDescription, _ kernel.FDFlags) bool {
+				if h, ok := file.Impl().(hostFD); ok {
+					add(h.HostFD(), fmt.Sprintf("container %q, PID %d, FD %d", cid, pid, fd))
+				}
+				return true
+			})
+		})
+	}
+
+	l.k.VFS().HostFDs(ctx, add)
+
+	l.mu.Lock()
+	for _, f := range l.saveFDs {
+		add(f.FD(), "checkpoint image")
+	}
+	for _, f := range l.fsSaveFDs {
+		add(f.FD(), "filesystem checkpoint image")
+	}
+	if l.hostinetNetDevFile != nil {
+		add(int(l.hostinetNetDevFile.Fd()), "hostinet /proc/net/dev")
+	}
+	if l.hostinetNetSNMPFile != nil {
+		add(int(l.hostinetNetSNMPFile.Fd()), "hostinet /proc/net/snmp")
+	}
+	l.mu.Unlock()
+
+	epFD, notified := fdnotifier.FDs()
+	add(epFD, "fdnotifier epoll")
+	for _, fd := range notified {
+		add(int(fd), "fdnotifier")
+	}
+	return owners
+}
+
+// hostFDs returns the host FDs held by the sentry that it can attribute,
+// sorted by FD.
+func (l *Loader) hostFDs() []HostFDOwner {
+	owners := l.hostFDOwners()
+	fds := make([]HostFDOwner, 0, len(owners))
+	for fd, owner := range owners {
+		fds = append(fds, HostFDOwner{FD: int32(fd), Owner: owner})
+	}
+	sort.Slice(fds, func(i, j int) bool { return fds[i].FD < fds[j].FD })
+	return fds
+}
+
+// closeHostFD closes a host FD held by the sentry by closing the application
+// FDs that hold it, as if the application had called close(2) on each of
+// them. The host FD itself is closed once the last reference on its file
+// description is dropped. Host FDs held by anything other than application
+// FDs can't be closed, since their owners would be left with a closed or
+// reused FD.
+func (l *Loader) closeHostFD(fd int32) error {
+	owner, ok := l.hostFDOwners()[int(fd)]
+	if !ok {
+		return fmt.Errorf("FD %d isn't attributed to an owner, so it can't be closed safely", fd)
+	}
+
+	ctx := l.k.SupervisorContext()
+	ts := l.k.TaskSet()
+	closed := 0
+	for _, tg := range ts.Root.ThreadGroups() {
+		leader := tg.Leader()
+		if leader == nil {
+			continue
+		}
+		var fdTable *kernel.FDTable
+		leader.WithMuLocked(func(t *kernel.Task) {
+			if fdTable = t.FDTable(); fdTable != nil {
+				fdTable.IncRef()
+			}
+		})
+		if fdTable == nil {
+			continue
+		}
+		// Files are released outside of the task's mutex, as on close(2).
+		fdTable.RemoveIf(ctx, func(file *vfs.FileDescription, _ kernel.FDFlags) bool {
+			if h, ok := file.Impl().(hostFD); ok && h.HostFD() == int(fd) {
+				closed++
+				return true
+			}
+			return false
+		})
+		fdTable.DecRef(ctx)
+	}
+	if closed == 0 {
+		return fmt.Errorf("FD %d is held by %s, not by an application FD", fd, owner)
+	}
+	log.Warningf("Force-closed %d application FD(s) holding host FD %d (%s)", closed, fd, owner)
+	return nil
+}
@@ -18,8 +18,11 @@ package boot
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"reflect"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	gtime "time"
@@ -34,6 +37,7 @@ import (
 	"gvisor.dev/gvisor/pkg/cpuid"
 	"gvisor.dev/gvisor/pkg/fd"
 	"gvisor.dev/gvisor/pkg/gomaxprocs"
+	"gvisor.dev/gvisor/pkg/hostarch"
 	"gvisor.dev/gvisor/pkg/log"
 	"gvisor.dev/gvisor/pkg/memutil"
 	"gvisor.dev/gvisor/pkg/metric"
@@ -82,6 +86,7 @@ import (
 	pf "gvisor.dev/gvisor/runsc/boot/portforward"
 	"gvisor.dev/gvisor/runsc/boot/pprof"
 	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/goferauth"
 	"gvisor.dev/gvisor/runsc/profile"
 	"gvisor.dev/gvisor/runsc/specutils"
 
@@ -149,6 +154,10 @@ type containerInfo struct {
 	// for bind mounts in Spec.Mounts (in the same order).
 	goferMountConfs []specutils.GoferMountConf
 
+	// goferAuthKey is the key used to authenticate the gofer connections in
+	// goferFDs and devGoferFD. It is cleared once they are authenticated.
+	goferAuthKey goferauth.Key
+
 	// nvidiaHostSettings holds information on the Nvidia GPU driver.
 	nvidiaHostSettings *nvconf.HostSettings
 
@@ -220,6 +229,27 @@ type Loader struct {
 
 	watchdog *watchdog.Watchdog
 
+	// events distributes sandbox events to subscribers. See
+	// containerManager.SubscribeEvents.
+	events *sandboxEvents
+
+	// execSessions holds the sessions of detachable exec'd processes.
+	execSessions *control.ExecSessions
+
+	// crashBundle, if not nil, is written when the sentry is about to crash.
+	crashBundle *crashBundle
+
+	// overlayUsage samples the size of the rootfs overlay upper layer of
+	// each container.
+	overlayUsage *overlayUsageMonitor
+
+	// memoryCompactor periodically returns freed application memory to the
+	// host.
+	memoryCompactor *memoryCompactor
+
+	// pageMerger periodically deduplicates identical application memory.
+	pageMerger *pageMerger
+
 	// stopSignalForwarding disables forwarding of signals to the sandboxed
 	// container. It should be called when a sandbox is destroyed.
 	stopSignalForwarding func()
@@ -296,6 +326,18 @@ type Loader struct {
 	// +checklocks:mu
 	portForwardProxies []*pf.Proxy
 
+	// stdioRelays maps container IDs to the relays of their init process
+	// output streams, indexed by app FD. Only used with --stdio-relay.
+	//
+	// +checklocks:mu
+	stdioRelays map[string]map[int]*stdioRelay
+
+	// exitedChans maps thread groups that are being waited on to channels
+	// that are closed when they exit. See threadGroupExited.
+	//
+	// +checklocks:mu
+	exitedChans map[*kernel.ThreadGroup]chan struct{}
+
 	// +checklocks:mu
 	saveFDs []*fd.FD
 
@@ -339,13 +381,14 @@ type execID struct {
 	pid kernel.ThreadID
 }
 
-// execProcess contains the thread group and host TTY of a sentry process.
+// execProcess contains the thread group and controlling TTY of a sentry
+// process.
 type execProcess struct {
 	// tg will be nil for containers that haven't started yet.
 	tg *kernel.ThreadGroup
 
 	// tty will be nil if the process is not attached to a terminal.
-	tty *host.TTYFileDescription
+	tty *control.ProcessTTY
 
 	// pidnsPath is the pid namespace path in spec
 	pidnsPath string
@@ -382,6 +425,10 @@ type Args struct {
 	// ControllerFD is the FD to the URPC controller. The Loader takes ownership
 	// of this FD and may close it at any time.
 	ControllerFD int
+	// ControlGrantFDs are FDs to the URPC controller sockets for the grants of
+	// --control-policy, in the order of server.Policy.Grants. The Loader takes
+	// ownership of these FDs and may close them at any time.
+	ControlGrantFDs []int
 	// Device is an optional argument that is passed to the platform. The Loader
 	// takes ownership of this file and may close it at any time.
 	Device *fd.FD
@@ -406,6 +453,9 @@ type Args struct {
 	// configured. The first entry is for rootfs and the following entries are
 	// for bind mounts in Spec.Mounts (in the same order).
 	GoferMountConfs []specutils.GoferMountConf
+	// GoferAuthKey is the key used to authenticate the root container's
+	// gofer connections. If nil, gofer connections are not authenticated.
+	GoferAuthKey goferauth.Key
 	// NumCPU is the number of CPUs to create inside the sandbox.
 	NumCPU int
 	// CPUQuota and CPUPeriod are the raw host CFS settings that should be
@@ -417,6 +467,9 @@ type Args struct {
 	TotalMem uint64
 	// TotalHostMem is the total memory reported by host /proc/meminfo.
 	TotalHostMem uint64
+	// PlatformFallbacks is the number of preferred platforms that were
+	// unavailable when the sandbox was created.
+	PlatformFallbacks int
 	// UserLogFD is the file descriptor to write user logs to.
 	UserLogFD int
 	// ProductName is the value to show in
@@ -455,6 +508,9 @@ type Args struct {
 	// RootfsUpperTarFD is the file descriptor to the tar file containing the rootfs
 	// upper layer changes.
 	RootfsUpperTarFD int
+	// CrashBundleFD is the file descriptor that a crash bundle is written to
+	// when the sentry is about to crash, or -1.
+	CrashBundleFD int
 }
 
 // HostTHP holds host transparent hugepage settings.
@@ -479,6 +535,12 @@ const (
 	containerSpecsKey = "container_specs"
 )
 
+// platformFallbacks is the number of preferred platforms that were
+// unavailable when the sandbox was created.
+var platformFallbacks = metric.MustCreateNewUint64Metric("/platform/fallbacks", metric.Uint64Metadata{
+	Description: "Number of preferred platforms that were unavailable when the sandbox was created.",
+})
+
 func getRootCredentials(spec *specs.Spec, conf *config.Config, userNs *auth.UserNamespace) *auth.Credentials {
 	// Create capabilities.
 	caps, err := specutils.Capabilities(conf.EnableRaw, spec.Process.Capabilities)
@@ -548,7 +610,15 @@ func New(args Args) (*Loader, error) {
 		saveCheckpointGofer:   args.SaveCheckpointGofer,
 		fsSaveFDs:             args.FSSaveFDs,
 		fsSaveCheckpointGofer: args.FSSaveCheckpointGofer,
+		events:                newSandboxEvents(),
+		execSessions:          control.NewExecSessions(),
 	}
+	if args.CrashBundleFD >= 0 {
+		l.crashBundle = newCrashBundle(os.NewFile(uintptr(args.CrashBundleFD), "crash bundle"), args.Conf, args.Spec)
+	}
+	l.overlayUsage = newOverlayUsageMonitor(l, args.Conf.Overlay2UpperSampleInterval, args.Conf.Overlay2UpperWarnSize)
+	l.memoryCompactor = newMemoryCompactor(l, args.Conf.MemoryCompactionInterval)
+	l.pageMerger = newPageMerger(l, args.Conf.PageMergingInterval)
 
 	if args.NumCPU == 0 {
 		args.NumCPU = runtime.NumCPU()
@@ -577,6 +647,7 @@ func New(args Args) (*Loader, error) {
 		conf:               args.Conf,
 		spec:               args.Spec,
 		goferMountConfs:    args.GoferMountConfs,
+		goferAuthKey:       args.GoferAuthKey,
 		nvidiaHostSettings: args.NvidiaHostSettings,
 		applicationCores:   args.NumCPU,
 	}
@@ -648,7 +719,7 @@ func New(args Args) (*Loader, error) {
 	}
 
 	// Create memory file.
-	mf, err := createMemoryFile(args.Conf.AppHugePages, args.HostTHP)
+	mf, err := createMemoryFile(args.Conf, args.HostTHP)
 	if err != nil {
 		return nil, fmt.Errorf("creating memory file: %w", err)
 	}
@@ -694,6 +765,11 @@ func New(args Args) (*Loader, error) {
 		log.Infof("Setting total memory to %.2f GB", float64(args.TotalMem)/(1<<30))
 	}
 
+	if args.PlatformFallbacks > 0 {
+		log.Infof("Running on platform %q after %d preferred platform(s) were unavailable", args.Conf.Platform, args.PlatformFallbacks)
+		platformFallbacks.IncrementBy(uint64(args.PlatformFallbacks))
+	}
+
 	cpufs := cpuid.HostFeatureSet()
 	if value, ok := args.Spec.Annotations[specutils.AnnotationCPUFeatures]; ok {
 		allowedFeatures := make(map[cpuid.Feature]struct{})
@@ -735,13 +811,21 @@ func New(args Args) (*Loader, error) {
 		RootUserNamespace:    creds.UserNamespace,
 		RootNetworkNamespace: netns,
 		ApplicationCores:     uint(args.NumCPU),
+		NUMANodes:            uint(args.Conf.NUMANodes),
 		Vdso:                 vdso,
 		VdsoParams:           params,
+		EntropySeed:          []byte(args.Conf.TestOnlyEntropySeed),
 		RootUTSNamespace:     kernel.NewUTSNamespace(args.Spec.Hostname, args.Spec.Domainname, creds.UserNamespace),
 		RootIPCNamespace:     kernel.NewIPCNamespace(creds.UserNamespace),
 		RootPIDNamespace:     kernel.NewRootPIDNamespace(creds.UserNamespace),
 		MaxFDLimit:           maxFDLimit,
-		Cgroup2FSInit:        cgroup2fs.NewFilesystem,
+		MaxUnixInflightFDs:   int64(args.Conf.UnixMaxInflightFDs),
+		VersionOverride: kernel.Version{
+			Release: args.Conf.KernelRelease,
+			Version: args.Conf.KernelVersion,
+		},
+		CmdLine:       args.Conf.KernelCmdline,
+		Cgroup2FSInit: cgroup2fs.NewFilesystem,
 	}); err != nil {
 		return nil, fmt.Errorf("initializing kernel: %w", err)
 	}
@@ -764,6 +848,7 @@ func New(args Args) (*Loader, error) {
 	if err := dogOpts.TaskTimeoutAction.Set(args.Conf.WatchdogAction); err != nil {
 		return nil, fmt.Errorf("setting watchdog action: %w", err)
 	}
+	dogOpts.OnReport = l.watchdogReport
 	l.watchdog = watchdog.New(l.k, dogOpts)
 
 	procArgs, err := createProcessArgs(args.ID, args.Spec, args.Conf, creds, l.k, l.k.RootPIDNamespace())
@@ -797,6 +882,15 @@ func New(args Args) (*Loader, error) {
 
 	l.k.RegisterContainerName(args.ID, l.root.containerName)
 	l.k.SetSaver(l)
+	if l.crashBundle != nil {
+		l.k.SetTaskPanicHook(l.taskPanic)
+	}
+	if args.Conf.GuestAPI {
+		l.k.SetGuestAPIHandler(l)
+	}
+	if args.Conf.PreciseCPUClocks {
+		l.k.SetPreciseCPUClocks()
+	}
 
 	// We don't care about child signals; some platforms can generate a
 	// tremendous number of useless ones (I'm looking at you, ptrace).
@@ -814,7 +908,7 @@ func New(args Args) (*Loader, error) {
 	//
 	// This must be done *after* we have initialized the kernel since the
 	// controller is used to configure the kernel's network stack.
-	ctrl, err := newController(args.ControllerFD, l)
+	ctrl, err := newController(args.ControllerFD, args.ControlGrantFDs, l)
 	if err != nil {
 		return nil, fmt.Errorf("creating control server: %w", err)
 	}
@@ -830,6 +924,8 @@ func New(args Args) (*Loader, error) {
 }
 
 // ConfigureNetwork implements inet.NetworkArgs.ConfigureNetwork.
+//
+// +checklocks:l.mu
 func (l *Loader) ConfigureNetwork(s inet.Stack) error {
 	if h, ok := s.(*hostinet.Stack); ok {
 		h.SetFiles(l.hostinetNetDevFile, l.hostinetNetSNMPFile)
@@ -861,6 +957,12 @@ func (l *Loader) ConfigureNetwork(s inet.Stack) error {
 	if nftables.IsNFTablesEnabled() && eps.Stack.NFTables() == nil {
 		eps.Stack.SetNFTables(nftables.NewNFTables(eps.Stack, eps.Stack.Clock(), eps.Stack.SecureRNG()))
 	}
+	if name := l.root.conf.PacketFilter; name != "" && eps.Stack.PacketFilter() == nil {
+		if err := setPacketFilter(eps.Stack, name); err != nil {
+			return err
+		}
+	}
+	eps.Stack.SetKeepaliveRestorePolicy(keepaliveRestorePolicy(l.root.conf.RestoreTimers))
 	n := &Network{
 		Stack:  eps.Stack,
 		Kernel: l.k,
@@ -868,6 +970,29 @@ func (l *Loader) ConfigureNetwork(s inet.Stack) error {
 	if err := n.CreateLinksAndRoutes(networkArgs, nil); err != nil {
 		return err
 	}
+
+	// Sockets bound to addresses that the sandbox lost, e.g. because it was
+	// restored on another host, are moved to the addresses that replace
+	// them.
+	addrMap, err := restoreAddressMap(eps.Stack, l.root.conf.RestoreAddressMap)
+	if err != nil {
+		return err
+	}
+	for from, to := range addrMap {
+		log.Infof("Remapping restored sockets from %s to %s", from, to)
+	}
+	eps.Stack.SetRestoreAddressMap(addrMap)
+
+	// A restored sandbox may be running on another host, so let neighbors
+	// know where its addresses are now. A new sandbox announces its
+	// addresses as usual through duplicate address detection and ARP.
+	if l.state == restoringUnstarted || l.state == restoringStarted {
+		for id := range eps.Stack.NICInfo() {
+			if err := eps.Stack.AnnounceAddresses(id); err != nil {
+				log.Warningf("Failed to announce addresses of NIC %d: %s", id, err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -922,6 +1047,9 @@ func (l *Loader) Destroy() {
 		l.stopSignalForwarding()
 	}
 	l.watchdog.Stop()
+	l.overlayUsage.stopSampling()
+	l.memoryCompactor.stopCompaction()
+	l.pageMerger.stopMerging()
 
 	ctx := l.k.SupervisorContext()
 	l.mu.Lock()
@@ -984,11 +1112,13 @@ func createPlatform(conf *config.Config, numCPU int, deviceFile *fd.FD, sandboxI
 		DisableFastPath:        platformName == "systrap" && conf.SystrapDisableFastPath,
 		ApplicationCores:       numCPU,
 		UseCPUNums:             platformName == "kvm" && conf.UseCPUNums,
+		ConditionalIBPB:        platformName == "kvm" && conf.KVMConditionalIBPB,
+		FlushL1D:               platformName == "kvm" && conf.KVMFlushL1D,
 		SandboxID:              sandboxID,
 	})
 }
 
-func createMemoryFile(appHugePages bool, hostTHP HostTHP) (*pgalloc.MemoryFile, error) {
+func createMemoryFile(conf *config.Config, hostTHP HostTHP) (*pgalloc.MemoryFile, error) {
 	const memfileName = "runsc-memory"
 	memfd, err := memutil.CreateMemFD(memfileName, 0)
 	if err != nil {
@@ -1000,8 +1130,15 @@ func createMemoryFile(appHugePages bool, hostTHP HostTHP) (*pgalloc.MemoryFile,
 		// We can't enable pgalloc.MemoryFileOpts.UseHostMemcgPressure even if
 		// there are memory cgroups specified, because at this point we're already
 		// in a mount namespace in which the relevant cgroupfs is not visible.
+		ReleaseDelay: conf.MemoryReleaseDelay,
+	}
+	policy, err := pgalloc.ParseReleasePolicy(conf.MemoryReleasePolicy)
+	if err != nil {
+		_ = memfile.Close()
+		return nil, err
 	}
-	if appHugePages {
+	mfopts.ReleasePolicy = policy
+	if conf.AppHugePages {
 		switch hostTHP.ShmemEnabled {
 		case "":
 			log.Infof("Disabling application huge pages: host shmem_enabled is unknown")
@@ -1075,6 +1212,10 @@ func (l *Loader) installSeccompFilters() error {
 				return fmt.Errorf("NVIDIA capabilities: %w", err)
 			}
 		}
+		var controlGrantFDs []uint32
+		for _, fd := range l.ctrl.srv.RestrictedFDs() {
+			controlGrantFDs = append(controlGrantFDs, uint32(fd))
+		}
 		opts := filter.Options{
 			Platform:              l.k.Platform.SeccompInfo(),
 			HostNetwork:           hostnet,
@@ -1084,7 +1225,9 @@ func (l *Loader) installSeccompFilters() error {
 			NVProxy:               nvproxyEnabled,
 			NVProxyCaps:           nvproxyCaps,
 			TPUProxy:              specutils.TPUProxyEnabled(l.root.spec, l.root.conf),
+			HostDevices:           l.root.conf.HostDevices,
 			ControllerFD:          uint32(l.ctrl.srv.FD()),
+			ControlGrantFDs:       controlGrantFDs,
 			CgoEnabled:            config.CgoEnabled,
 			PluginNetwork:         l.root.conf.Network == config.NetworkPlugin,
 		}
@@ -1158,6 +1301,8 @@ func (l *Loader) run() error {
 		if err != nil {
 			return err
 		}
+		l.events.watchExit(l.sandboxID, tg, true)
+		l.events.lifecycle(SandboxEventContainerStart, l.sandboxID)
 
 		if seccheck.Global.Enabled(seccheck.PointContainerStart) {
 			evt := pb.Start{
@@ -1213,6 +1358,9 @@ func (l *Loader) run() error {
 
 	log.Infof("Process should have started...")
 	l.watchdog.Start()
+	l.overlayUsage.start()
+	l.memoryCompactor.start()
+	l.pageMerger.start()
 	if err := l.k.Start(); err != nil {
 		return err
 	}
@@ -1243,7 +1391,7 @@ func (l *Loader) createSubcontainer(cid string, tty *fd.FD) error {
 // startSubcontainer starts a child container. It returns the thread group ID of
 // the newly created process. Used FDs are either closed or released. It's safe
 // for the caller to close any remaining files upon return.
-func (l *Loader) startSubcontainer(spec *specs.Spec, conf *config.Config, cid string, stdioFDs, goferFDs, goferFilestoreFDs []*fd.FD, devGoferFD *fd.FD, goferMountConfs []specutils.GoferMountConf, rootfsUpperTarFD *fd.FD) error {
+func (l *Loader) startSubcontainer(spec *specs.Spec, conf *config.Config, cid string, stdioFDs, goferFDs, goferFilestoreFDs []*fd.FD, devGoferFD *fd.FD, goferMountConfs []specutils.GoferMountConf, goferAuthKey goferauth.Key, rootfsUpperTarFD *fd.FD) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -1296,6 +1444,7 @@ func (l *Loader) startSubcontainer(spec *specs.Spec, conf *config.Config, cid st
 		devGoferFD:         devGoferFD,
 		goferFilestoreFDs:  goferFilestoreFDs,
 		goferMountConfs:    goferMountConfs,
+		goferAuthKey:       goferAuthKey,
 		nvidiaHostSettings: l.root.nvidiaHostSettings,
 		nvproxyDevInfo:     l.root.nvproxyDevInfo,
 		rootfsUpperTarFD:   rootfsUpperTarFD,
@@ -1355,19 +1504,62 @@ func (l *Loader) startSubcontainer(spec *specs.Spec, conf *config.Config, cid st
 	}
 
 	l.k.StartProcess(ep.tg)
+	l.events.watchExit(cid, ep.tg, false)
+	l.events.lifecycle(SandboxEventContainerStart, cid)
 	// No more failures from this point on.
 	cu.Release()
 	return nil
 }
 
 // +checklocks:l.mu
-func (l *Loader) createContainerProcess(info *containerInfo) (*kernel.ThreadGroup, *host.TTYFileDescription, error) {
+func (l *Loader) createContainerProcess(info *containerInfo) (*kernel.ThreadGroup, *control.ProcessTTY, error) {
+	// Set the network policy before the container can create any socket.
+	if err := l.setNetworkPolicyFromSpec(info.spec, info.cid); err != nil {
+		return nil, nil, fmt.Errorf("setting network policy: %w", err)
+	}
+	if err := l.setCPUBandwidthFromSpec(info.spec, info.conf, info.cid); err != nil {
+		return nil, nil, fmt.Errorf("setting CPU bandwidth: %w", err)
+	}
+
+	// With --stdio-relay, stdout and stderr are relayed through pipes so that
+	// they can be re-attached later. Take the host FDs out of the set being
+	// imported.
+	stdioFDs := info.stdioFDs
+	relayFDs := make(map[int]*fd.FD)
+	if info.conf.StdioRelay && !info.spec.Process.Terminal && len(stdioFDs) == 3 {
+		stdioFDs = slices.Clone(stdioFDs)
+		for _, appFD := range relayedStreams {
+			if !passesFD(info.passFDs, appFD) {
+				relayFDs[appFD] = stdioFDs[appFD]
+				stdioFDs[appFD] = nil
+			}
+		}
+	}
+
 	// Create the FD map, which will set stdin, stdout, and stderr.
 	ctx := info.procArgs.NewContext(l.k)
-	fdTable, ttyFile, err := createFDTable(ctx, info.spec.Process.Terminal, info.stdioFDs, info.passFDs, info.spec.Process.User, info.containerName)
+	fdTable, ttyFile, err := createFDTable(ctx, info.spec.Process.Terminal, stdioFDs, info.passFDs, info.spec.Process.User, info.containerName)
 	if err != nil {
 		return nil, nil, fmt.Errorf("importing fds: %w", err)
 	}
+	out := newOutputStream(fmt.Sprintf("container %q output", info.cid))
+	for _, appFD := range slices.Sorted(maps.Keys(relayFDs)) {
+		hostFD := relayFDs[appFD]
+		desc := fmt.Sprintf("container %q FD %d", info.cid, appFD)
+		dst := os.NewFile(uintptr(hostFD.Release()), desc)
+		relay, err := newStdioRelay(ctx, l.k, fdTable, int32(appFD), dst, out, desc)
+		if err != nil {
+			fdTable.DecRef(ctx)
+			return nil, nil, fmt.Errorf("relaying FD %d: %w", appFD, err)
+		}
+		if l.stdioRelays == nil {
+			l.stdioRelays = make(map[string]map[int]*stdioRelay)
+		}
+		if l.stdioRelays[info.cid] == nil {
+			l.stdioRelays[info.cid] = make(map[int]*stdioRelay)
+		}
+		l.stdioRelays[info.cid][appFD] = relay
+	}
 	// CreateProcess takes a reference on fdTable if successful. We won't need
 	// ours either way.
 	info.procArgs.FDTable = fdTable
@@ -1408,6 +1600,9 @@ func (l *Loader) createContainerProcess(info *containerInfo) (*kernel.ThreadGrou
 			return nil, nil, err
 		}
 	}
+	if err := authenticateGofers(info); err != nil {
+		return nil, nil, err
+	}
 	// We can share l.sharedMounts with containerMounter since l.mu is locked.
 	// Hence, mntr must only be used within this function (while l.mu is locked).
 	mntr := l.newContainerMounter(info)
@@ -1420,6 +1615,10 @@ func (l *Loader) createContainerProcess(info *containerInfo) (*kernel.ThreadGrou
 		}
 	}()
 
+	if err := l.applySysctls(info); err != nil {
+		return nil, nil, err
+	}
+
 	// Add the HOME environment variable if it is not already set.
 	info.procArgs.Envv, err = user.MaybeAddExecUserHome(ctx, info.procArgs.MountNamespace,
 		info.procArgs.Credentials.RealKUID, info.procArgs.Envv)
@@ -1448,7 +1647,7 @@ func (l *Loader) createContainerProcess(info *containerInfo) (*kernel.ThreadGrou
 		}
 	}
 
-	return tg, ttyFile, nil
+	return tg, control.NewHostProcessTTY(ttyFile), nil
 }
 
 // startGoferMonitor runs a goroutine to monitor gofer's health. It polls on
@@ -1502,6 +1701,19 @@ func (l *Loader) startGoferMonitor(info *containerInfo) {
 	}()
 }
 
+// pauseContainer pauses all tasks in the given container, without affecting
+// other containers in the sandbox.
+func (l *Loader) pauseContainer(cid string) error {
+	l.mu.Lock()
+	_, err := l.tryThreadGroupFromIDLocked(execID{cid: cid})
+	l.mu.Unlock()
+	if err != nil {
+		// Container doesn't exist.
+		return err
+	}
+	return l.k.PauseContainer(cid)
+}
+
 // destroySubcontainer stops a container if it is still running and cleans up
 // its filesystem.
 func (l *Loader) destroySubcontainer(cid string) error {
@@ -1514,6 +1726,13 @@ func (l *Loader) destroySubcontainer(cid string) error {
 		return err
 	}
 
+	// Paused tasks can't exit until they are resumed.
+	if l.k.IsContainerPaused(cid) {
+		if err := l.k.UnpauseContainer(cid); err != nil {
+			return err
+		}
+	}
+
 	// The container exists, but has it been started?
 	if tg != nil {
 		if err := l.signalAllProcesses(cid, int32(linux.SIGKILL)); err != nil {
@@ -1536,8 +1755,17 @@ func (l *Loader) destroySubcontainer(cid string) error {
 			delete(l.processes, key)
 		}
 	}
+	// Relays finish on their own once the last writer is gone.
+	delete(l.stdioRelays, cid)
+	l.k.Sysctls().Remove(cid)
 	// Cleanup the device gofer.
 	l.k.RemoveDevGofer(l.k.ContainerName(cid))
+	if err := l.setNetworkPolicy(cid, nil); err != nil {
+		log.Warningf("Failed to remove network policy of container %q: %v", cid, err)
+	}
+	if err := l.k.SetContainerCPUBandwidth(cid, 0, 0); err != nil {
+		log.Warningf("Failed to remove CPU bandwidth limit of container %q: %v", cid, err)
+	}
 
 	log.Debugf("Container destroyed, cid: %s", cid)
 	return nil
@@ -1597,9 +1825,11 @@ func (l *Loader) executeAsync(args *control.ExecArgs) (kernel.ThreadID, error) {
 		args.SeccompProgram = seccompProgram
 	}
 
+	args.Devpts = l.root.conf.ExecDevpts
+
 	// Start the process.
-	proc := control.Proc{Kernel: l.k}
-	newTG, tgid, ttyFile, err := control.ExecAsync(&proc, args)
+	proc := control.Proc{Kernel: l.k, ExecSessions: l.execSessions}
+	newTG, tgid, tty, err := control.ExecAsync(&proc, args)
 	if err != nil {
 		return 0, err
 	}
@@ -1607,15 +1837,56 @@ func (l *Loader) executeAsync(args *control.ExecArgs) (kernel.ThreadID, error) {
 	eid := execID{cid: args.ContainerID, pid: tgid}
 	l.processes[eid] = &execProcess{
 		tg:  newTG,
-		tty: ttyFile,
+		tty: tty,
 	}
 	log.Debugf("updated processes: %v", l.processes)
 
 	return tgid, nil
 }
 
+// attachExec attaches the terminal in args to the session of a detachable
+// exec'd process. It returns true if the process may still be running once
+// the terminal is detached.
+func (l *Loader) attachExec(args *AttachExecArgs) (bool, error) {
+	if len(args.Files) != 1 {
+		return false, fmt.Errorf("got %d files, want 1", len(args.Files))
+	}
+	session, err := l.execSessions.Get(control.ExecSessionKey{
+		ContainerID: args.ContainerID,
+		PID:         kernel.ThreadID(args.PID),
+	})
+	if err != nil {
+		return false, err
+	}
+	// Files are closed when the call returns, so take our own copy.
+	hostFD, err := args.ReleaseFD(0)
+	if err != nil {
+		return false, err
+	}
+	return session.Attach(l.k, hostFD, args.DetachKeys)
+}
+
 // waitContainer waits for the init process of a container to exit.
 func (l *Loader) waitContainer(cid string, waitStatus *uint32) error {
+	tg, ws, err := l.containerInitToWait(cid)
+	if err != nil {
+		return err
+	}
+	if tg != nil {
+		// If the thread either has already exited or exits during waiting,
+		// consider the container exited.
+		ws = l.wait(tg)
+		l.containerExited(cid)
+	}
+	*waitStatus = ws
+	return nil
+}
+
+// containerInitToWait returns the init process of container cid, which must
+// be waited on to wait for the container to exit. If the container is
+// considered exited without having an init process, it returns a nil thread
+// group and the container's wait status.
+func (l *Loader) containerInitToWait(cid string) (*kernel.ThreadGroup, uint32, error) {
 	l.mu.Lock()
 	state := l.state
 	if state == restoringUnstarted {
@@ -1623,13 +1894,13 @@ func (l *Loader) waitContainer(cid string, waitStatus *uint32) error {
 		l.restoreDone.Wait()
 		l.mu.Unlock()
 		log.Infof("Restore is completed, trying to wait for container %q again.", cid)
-		return l.waitContainer(cid, waitStatus)
+		return l.containerInitToWait(cid)
 	}
 	tg, err := l.tryThreadGroupFromIDLocked(execID{cid: cid})
 	l.mu.Unlock()
 	if err != nil {
 		// The container does not exist.
-		return err
+		return nil, 0, err
 	}
 	if tg == nil {
 		// The container has not been started.
@@ -1637,28 +1908,27 @@ func (l *Loader) waitContainer(cid string, waitStatus *uint32) error {
 		case created, started:
 			// Note that state=started means the root container has been started,
 			// but other containers may not have started yet.
-			return fmt.Errorf("container %q not started", cid)
+			return nil, 0, fmt.Errorf("container %q not started", cid)
 		case restoringStarted, restored:
 			// The container has restored, we *should* have found the init process...
-			return fmt.Errorf("could not find init process of restored container %q in state %q", cid, state)
+			return nil, 0, fmt.Errorf("could not find init process of restored container %q in state %q", cid, state)
 		case restoreFailed:
 			// If restore failed, we should return the a non-zero exit status here to
 			// indicate that the container failed and transition to "stopped" state.
 			log.Warningf("Restore failed, returning from waitContainer with non-zero exit status")
-			*waitStatus = 1
-			return nil
+			return nil, 1, nil
 		case restoringUnstarted:
 			panic("impossible")
 		default:
 			panic(fmt.Sprintf("Invalid state: %s", state))
 		}
 	}
+	return tg, 0, nil
+}
 
-	// If the thread either has already exited or exits during waiting,
-	// consider the container exited.
-	ws := l.wait(tg)
-	*waitStatus = ws
-
+// containerExited is called after waiting for the init process of container
+// cid to exit.
+func (l *Loader) containerExited(cid string) {
 	// Check for leaks and write coverage report after the root container has
 	// exited. This guarantees that the report is written in cases where the
 	// sandbox is killed by a signal after the ContMgrWait request is completed.
@@ -1666,6 +1936,84 @@ func (l *Loader) waitContainer(cid string, waitStatus *uint32) error {
 		// All sentry-created resources should have been released at this point.
 		_ = coverage.Report()
 	}
+}
+
+// threadGroupExited returns a channel that is closed when all task goroutines
+// in tg have exited, see kernel.ThreadGroup.WaitExited. All callers share a
+// single goroutine waiting for tg, so callers that stop waiting early don't
+// leave goroutines behind.
+func (l *Loader) threadGroupExited(tg *kernel.ThreadGroup) <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if ch, ok := l.exitedChans[tg]; ok {
+		return ch
+	}
+	if l.exitedChans == nil {
+		l.exitedChans = make(map[*kernel.ThreadGroup]chan struct{})
+	}
+	ch := make(chan struct{})
+	l.exitedChans[tg] = ch
+	go func() {
+		tg.WaitExited()
+		l.mu.Lock()
+		delete(l.exitedChans, tg)
+		l.mu.Unlock()
+		close(ch)
+	}()
+	return ch
+}
+
+// waitContainers waits for any or all containers in args.CIDs to exit, or for
+// args.Timeout to expire. The timeout doesn't apply to waiting for the sandbox
+// to be restored.
+func (l *Loader) waitContainers(args *WaitContainersArgs, res *WaitContainersResult) error {
+	if len(args.CIDs) == 0 {
+		return fmt.Errorf("no container to wait on")
+	}
+	// Wait for the containers' exit notifications, rather than for each
+	// container in its own goroutine, so that nothing keeps waiting after a
+	// timeout.
+	var (
+		cids  []string
+		tgs   []*kernel.ThreadGroup
+		cases []reflect.SelectCase
+	)
+	for _, cid := range args.CIDs {
+		tg, ws, err := l.containerInitToWait(cid)
+		if err != nil {
+			return fmt.Errorf("waiting on container %q: %w", cid, err)
+		}
+		if tg == nil {
+			res.Exited = append(res.Exited, ContainerExit{CID: cid, WaitStatus: ws})
+			if !args.All {
+				return nil
+			}
+			continue
+		}
+		cids = append(cids, cid)
+		tgs = append(tgs, tg)
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(l.threadGroupExited(tg))})
+	}
+	if args.Timeout > 0 {
+		timer := gtime.NewTimer(args.Timeout)
+		defer timer.Stop()
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(timer.C)})
+	}
+	for len(tgs) > 0 {
+		i, _, _ := reflect.Select(cases)
+		if i == len(tgs) {
+			res.TimedOut = true
+			return nil
+		}
+		res.Exited = append(res.Exited, ContainerExit{CID: cids[i], WaitStatus: l.wait(tgs[i])})
+		l.containerExited(cids[i])
+		if !args.All {
+			return nil
+		}
+		cids = slices.Delete(cids, i, i+1)
+		tgs = slices.Delete(tgs, i, i+1)
+		cases = slices.Delete(cases, i, i+1)
+	}
 	return nil
 }
 
@@ -1761,7 +2109,13 @@ func newRootNetworkNamespace(conf *config.Config, clock tcpip.Clock, userns *aut
 			clock:                    clock,
 			allowPacketEndpointWrite: conf.AllowPacketEndpointWrite,
 			allowLiveTCPMigration:    conf.AllowLiveTCPMigration,
-			uid:                      uid,
+			packetFilter:             conf.PacketFilter,
+			ipv6ExtensionHeaders: tcpip.IPv6ExtensionHeaderOption{
+				SegmentRouting:     conf.IPv6SegmentRouting,
+				MaxHopByHopOptions: conf.IPv6MaxHopByHopOptions,
+				MaxHopByHopLength:  conf.IPv6MaxHopByHopLength,
+			},
+			uid: uid,
 		}
 		s, err := creator.newEmptySandboxNetworkStack()
 		if err != nil {
@@ -1797,12 +2151,19 @@ func (c *sandboxNetstackCreator) newEmptySandboxNetworkStack() (*netstack.Stack,
 		AllowPacketEndpointWrite: c.allowPacketEndpointWrite,
 		AllowLiveTCPMigration:    c.allowLiveTCPMigration,
 		DefaultIPTables:          netfilter.DefaultLinuxTables,
+		DropObserver:             netstack.NewDropObserver(c.clock),
 	}), c.uid.UniqueID())
 
 	if nftables.IsNFTablesEnabled() {
 		s.Stack.SetNFTables(nftables.NewNFTables(s.Stack, c.clock, s.Stack.SecureRNG()))
 	}
 
+	if c.packetFilter != "" {
+		if err := setPacketFilter(s.Stack, c.packetFilter); err != nil {
+			return nil, err
+		}
+	}
+
 	// Enable SACK Recovery.
 	{
 		opt := tcpip.TCPSACKEnabled(true)
@@ -1822,6 +2183,14 @@ func (c *sandboxNetstackCreator) newEmptySandboxNetworkStack() (*netstack.Stack,
 		}
 	}
 
+	// Configure the processing of IPv6 extension headers.
+	{
+		opt := c.ipv6ExtensionHeaders
+		if err := s.Stack.SetNetworkProtocolOption(ipv6.ProtocolNumber, &opt); err != nil {
+			return nil, fmt.Errorf("SetNetworkProtocolOption(%d, &%T(%+v)): %s", ipv6.ProtocolNumber, opt, opt, err)
+		}
+	}
+
 	// Enable Receive Buffer Auto-Tuning.
 	{
 		opt := tcpip.TCPModerateReceiveBufferOption(true)
@@ -1830,9 +2199,42 @@ func (c *sandboxNetstackCreator) newEmptySandboxNetworkStack() (*netstack.Stack,
 		}
 	}
 
+	// Set TCP memory limits. The limits are derived from the sandbox memory
+	// size in the same way that Linux derives tcp_mem from the number of
+	// free buffer pages (see net/ipv4/tcp.c:tcp_init_mem).
+	{
+		limit := int(usage.TotalMemory(0, 0)/hostarch.PageSize) / 16
+		if limit < 128 {
+			limit = 128
+		}
+		opt := tcpip.TCPMemoryLimitsOption{
+			Min:      limit / 4 * 3 * hostarch.PageSize,
+			Pressure: limit * hostarch.PageSize,
+			Max:      limit / 4 * 3 * 2 * hostarch.PageSize,
+		}
+		if err := s.Stack.SetTransportProtocolOption(tcp.ProtocolNumber, &opt); err != nil {
+			return nil, fmt.Errorf("SetTransportProtocolOption(%d, &%T(%+v)): %s", tcp.ProtocolNumber, opt, opt, err)
+		}
+	}
+
 	return s, nil
 }
 
+// setPacketFilter creates the external packet filter registered under name and
+// sets it on s.
+func setPacketFilter(s *stack.Stack, name string) error {
+	newFilter, err := stack.LookupPacketFilter(name)
+	if err != nil {
+		return fmt.Errorf("%w, available packet filters: %v", err, stack.PacketFilters())
+	}
+	f, err := newFilter(s)
+	if err != nil {
+		return fmt.Errorf("creating packet filter %q: %w", name, err)
+	}
+	s.SetPacketFilter(f)
+	return nil
+}
+
 // sandboxNetstackCreator implements kernel.NetworkStackCreator.
 //
 // +stateify savable
@@ -1840,6 +2242,8 @@ type sandboxNetstackCreator struct {
 	clock                    tcpip.Clock
 	allowPacketEndpointWrite bool
 	allowLiveTCPMigration    bool
+	packetFilter             string
+	ipv6ExtensionHeaders     tcpip.IPv6ExtensionHeaderOption
 	uid                      uniqueid.Provider
 }
 
@@ -1964,8 +2368,15 @@ func (l *Loader) signalForegrondProcessGroup(cid string, tgid kernel.ThreadID, s
 	if tty == nil {
 		return fmt.Errorf("no TTY attached")
 	}
+	if signo == int32(linux.SIGWINCH) {
+		// A sandbox pseudo-terminal signals its foreground process group
+		// itself when its window size changes.
+		if synced, err := tty.SyncWindowSize(); synced {
+			return err
+		}
+	}
 	si := &linux.SignalInfo{Signo: signo}
-	ttyTg := tty.ThreadGroup()
+	ttyTg := tty.TTY().ThreadGroup()
 	if ttyTg == nil {
 		// No thread group has been set. Signal the original thread
 		// group.
@@ -2050,7 +2461,7 @@ func (l *Loader) tryThreadGroupFromIDLocked(key execID) (*kernel.ThreadGroup, er
 // execution ID is invalid or if the container cannot be found (maybe it has
 // been deleted).
 // +checklocks:l.mu
-func (l *Loader) ttyFromIDLocked(key execID) (*host.TTYFileDescription, error) {
+func (l *Loader) ttyFromIDLocked(key execID) (*control.ProcessTTY, error) {
 	ep, err := l.findProcessLocked(key)
 	if err != nil {
 		return nil, err
@@ -2062,10 +2473,12 @@ func createFDTable(ctx context.Context, console bool, stdioFDs []*fd.FD, passFDs
 	if len(stdioFDs) != 3 {
 		return nil, nil, fmt.Errorf("stdioFDs should contain exactly 3 FDs (stdin, stdout, and stderr), but %d FDs received", len(stdioFDs))
 	}
-	fdMap := map[int]*fd.FD{
-		0: stdioFDs[0],
-		1: stdioFDs[1],
-		2: stdioFDs[2],
+	fdMap := make(map[int]*fd.FD)
+	for appFD, hostFD := range stdioFDs {
+		// Streams that are set up by the caller are nil.
+		if hostFD != nil {
+			fdMap[appFD] = hostFD
+		}
 	}
 
 	// Create the entries for the host files that were passed to our app.
@@ -2094,6 +2507,95 @@ func createFDTable(ctx context.Context, console bool, stdioFDs []*fd.FD, passFDs
 	return fdTable, ttyFile, nil
 }
 
+// passesFD returns true if appFD is set by one of passFDs.
+func passesFD(passFDs []fdMapping, appFD int) bool {
+	for _, m := range passFDs {
+		if m.guest == appFD {
+			return true
+		}
+	}
+	return false
+}
+
+// hasStdioRelays returns true if output of any container is relayed.
+func (l *Loader) hasStdioRelays() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.stdioRelays) != 0
+}
+
+// attachStdio replaces the destination of relayed output streams of a
+// container.
+func (l *Loader) attachStdio(args *AttachStdioArgs) error {
+	perStream := 1
+	if args.Rotation != nil {
+		// Each file is followed by its directory.
+		perStream = 2
+		if len(args.Rotation.Names) != len(args.Streams) {
+			return fmt.Errorf("got %d file names for %d streams", len(args.Rotation.Names), len(args.Streams))
+		}
+		if !l.root.conf.DirectFS {
+			// Rotation relies on the openat(2) and renameat2(2) syscall filters
+			// that are installed for directfs.
+			return fmt.Errorf("log rotation requires --directfs")
+		}
+		if args.Rotation.MaxBytes <= 0 {
+			return fmt.Errorf("invalid rotation size %d", args.Rotation.MaxBytes)
+		}
+		if args.Rotation.MaxFiles < 0 {
+			return fmt.Errorf("invalid number of rotated files %d", args.Rotation.MaxFiles)
+		}
+		for _, name := range args.Rotation.Names {
+			if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+				return fmt.Errorf("invalid file name %q", name)
+			}
+		}
+	}
+	if len(args.Files) != perStream*len(args.Streams) {
+		return fmt.Errorf("got %d files for %d streams", len(args.Files), len(args.Streams))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.tryThreadGroupFromIDLocked(execID{cid: args.ContainerID}); err != nil {
+		return err
+	}
+	relays := l.stdioRelays[args.ContainerID]
+	for _, stream := range args.Streams {
+		if relays[stream] == nil {
+			return fmt.Errorf("FD %d of container %q is not relayed, --stdio-relay must be set and the container must not use a terminal", stream, args.ContainerID)
+		}
+	}
+
+	// Files are closed when the call returns, so take our own copies.
+	for i, stream := range args.Streams {
+		dst, err := args.ReleaseFD(perStream * i)
+		if err != nil {
+			return err
+		}
+		var rot *logRotation
+		if args.Rotation != nil {
+			dir, err := args.ReleaseFD(perStream*i + 1)
+			if err != nil {
+				dst.Close()
+				return err
+			}
+			rot = &logRotation{
+				dir:      os.NewFile(uintptr(dir.Release()), args.Rotation.Names[i]),
+				name:     args.Rotation.Names[i],
+				maxBytes: args.Rotation.MaxBytes,
+				maxFiles: args.Rotation.MaxFiles,
+			}
+		}
+		f := os.NewFile(uintptr(dst.Release()), fmt.Sprintf("container %q FD %d", args.ContainerID, stream))
+		if err := relays[stream].attach(f, rot); err != nil {
+			return fmt.Errorf("attaching FD %d: %w", stream, err)
+		}
+	}
+	return nil
+}
+
 // portForward implements initiating a portForward connection in the sandbox. portForwardProxies
 // represent a two connections each copying to each other (read ends to write ends) in goroutines.
 // The proxies are stored and can be cleaned up, or clean up after themselves if the connection
@@ -0,0 +1,79 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"sync"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/log"
+)
+
+// memoryCompactor periodically returns freed application memory to the host,
+// as configured by --memory-compaction-interval.
+type memoryCompactor struct {
+	l *Loader
+
+	// interval is the compaction interval. interval is immutable.
+	interval time.Duration
+
+	// startOnce ensures that compaction is started once.
+	startOnce sync.Once
+
+	// stop is closed to stop compaction.
+	stop chan struct{}
+
+	// stopOnce ensures that stop is closed once.
+	stopOnce sync.Once
+}
+
+func newMemoryCompactor(l *Loader, interval time.Duration) *memoryCompactor {
+	return &memoryCompactor{
+		l:        l,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// start starts compaction in the background. It is a no-op if compaction is
+// disabled or already started.
+func (c *memoryCompactor) start() {
+	if c.interval <= 0 {
+		return
+	}
+	c.startOnce.Do(func() {
+		go func() { // S/R-SAFE: does not impact state directly.
+			ticker := time.NewTicker(c.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					// The MemoryFile is replaced on restore, so look it up
+					// on each pass.
+					if n := c.l.k.MemoryFile().Compact(); n != 0 {
+						log.Debugf("Memory compaction returned %d bytes to the host", n)
+					}
+				case <-c.stop:
+					return
+				}
+			}
+		}()
+	})
+}
+
+// stopCompaction stops compaction started by start.
+func (c *memoryCompactor) stopCompaction() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
@@ -17,6 +17,7 @@ package boot
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	specs "github.com/opencontainers/runtime-spec/specs-go"
@@ -102,12 +103,18 @@ func NewPodMountHints(spec *specs.Spec) (*PodMountHints, error) {
 
 	// Validate all the parsed hints.
 	for name, m := range mnts {
-		log.Infof("Mount annotation found, name: %s, source: %q, type: %s, share: %v, suppress_directfs: %t", name, m.Mount.Source, m.Mount.Type, m.Share, m.SuppressDirectFS)
+		log.Infof("Mount annotation found, name: %s, source: %q, type: %s, share: %v, suppress_directfs: %t, quota: %d", name, m.Mount.Source, m.Mount.Type, m.Share, m.SuppressDirectFS, m.Quota)
 		if m.Share == invalid || len(m.Mount.Source) == 0 || len(m.Mount.Type) == 0 {
 			log.Warningf("ignoring mount annotations for %q because of missing required field(s)", name)
 			delete(mnts, name)
 			continue
 		}
+		if m.Quota > 0 && m.Mount.Type != Bind {
+			// Writes to other mount types don't go through the gofer, which is
+			// what enforces quotas. tmpfs mounts can be limited with "size=".
+			log.Warningf("ignoring quota for mount %q because it is only supported for %q mounts", name, Bind)
+			m.Quota = 0
+		}
 
 		// Check for duplicate mount sources.
 		for name2, m2 := range mnts {
@@ -136,6 +143,13 @@ type MountHint struct {
 	// settings (like seccomp filters) that can not be selectively enabled on
 	// containers.
 	SuppressDirectFS bool `json:"suppressDirectFS"`
+
+	// Quota is the maximum number of bytes that each container may add to the
+	// mount, or 0 for no limit. It is enforced by the container's gofer, so
+	// it is only supported for bind mounts and it implies SuppressDirectFS.
+	// Data written by other containers, or by processes outside the sandbox,
+	// is not charged to the container.
+	Quota uint64 `json:"quota,omitempty"`
 }
 
 func (m *MountHint) setField(key, val string) error {
@@ -153,6 +167,8 @@ func (m *MountHint) setField(key, val string) error {
 		m.Mount.Options = specutils.FilterMountOptions(strings.Split(val, ","))
 	case "directfs":
 		return m.setDirectFS(val)
+	case "quota":
+		return m.setQuota(val)
 	default:
 		return fmt.Errorf("invalid mount annotation: %s=%s", key, val)
 	}
@@ -195,6 +211,47 @@ func (m *MountHint) setDirectFS(val string) error {
 	return nil
 }
 
+// setQuota parses a quota given in bytes, with an optional K, M, G or T
+// suffix for binary multiples of bytes.
+func (m *MountHint) setQuota(val string) error {
+	num := val
+	var shift uint
+	if len(num) > 0 {
+		switch num[len(num)-1] {
+		case 'k', 'K':
+			shift = 10
+		case 'm', 'M':
+			shift = 20
+		case 'g', 'G':
+			shift = 30
+		case 't', 'T':
+			shift = 40
+		}
+		if shift != 0 {
+			num = num[:len(num)-1]
+		}
+	}
+	quota, err := strconv.ParseUint(num, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid quota %q: %w", val, err)
+	}
+	if quota == 0 {
+		return fmt.Errorf("invalid quota %q: must be positive", val)
+	}
+	if quota > (1<<64-1)>>shift {
+		return fmt.Errorf("invalid quota %q: too large", val)
+	}
+	m.Quota = quota << shift
+	return nil
+}
+
+// ShouldSuppressDirectFS returns true if the sentry must not access this mount
+// directly, even if directfs is enabled.
+func (m *MountHint) ShouldSuppressDirectFS() bool {
+	// Quotas are enforced by the gofer, so all writes must go through it.
+	return m.SuppressDirectFS || m.Quota > 0
+}
+
 // ShouldShareMount returns true if this mount should be configured as a shared
 // mount that is shared among multiple containers in a pod.
 func (m *MountHint) ShouldShareMount() bool {
@@ -245,6 +245,79 @@ func TestPodMountHintsDirectFS(t *testing.T) {
 	}
 }
 
+func TestPodMountHintsQuota(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		mountType string
+		value     string
+		want      uint64
+	}{
+		{
+			name:      "bytes",
+			mountType: "bind",
+			value:     "4096",
+			want:      4096,
+		},
+		{
+			name:      "suffix",
+			mountType: "bind",
+			value:     "10M",
+			want:      10 << 20,
+		},
+		{
+			name:      "lowercase-suffix",
+			mountType: "bind",
+			value:     "2g",
+			want:      2 << 30,
+		},
+		{
+			name:      "zero",
+			mountType: "bind",
+			value:     "0",
+		},
+		{
+			name:      "invalid",
+			mountType: "bind",
+			value:     "lots",
+		},
+		{
+			name:      "overflow",
+			mountType: "bind",
+			value:     "18446744073709551615T",
+		},
+		{
+			name:      "tmpfs",
+			mountType: "tmpfs",
+			value:     "10M",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &specs.Spec{
+				Annotations: map[string]string{
+					MountPrefix + "mount1.source": "foo",
+					MountPrefix + "mount1.type":   tc.mountType,
+					MountPrefix + "mount1.share":  "pod",
+					MountPrefix + "mount1.quota":  tc.value,
+				},
+			}
+			podHints, err := NewPodMountHints(spec)
+			if err != nil {
+				t.Fatalf("NewPodMountHints failed: %v", err)
+			}
+			hint := podHints.Mounts["mount1"]
+			if hint == nil {
+				t.Fatalf("mount1 hint should be retained")
+			}
+			if hint.Quota != tc.want {
+				t.Errorf("Quota = %d, want %d", hint.Quota, tc.want)
+			}
+			if got, want := hint.ShouldSuppressDirectFS(), tc.want > 0; got != want {
+				t.Errorf("ShouldSuppressDirectFS() = %t, want %t", got, want)
+			}
+		})
+	}
+}
+
 func TestIgnoreInvalidMountOptions(t *testing.T) {
 	spec := &specs.Spec{
 		Annotations: map[string]string{
@@ -29,10 +29,12 @@ import (
 	"gvisor.dev/gvisor/pkg/sentry/socket/netfilter"
 	"gvisor.dev/gvisor/pkg/sentry/socket/plugin"
 	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
 	"gvisor.dev/gvisor/pkg/tcpip/link/ethernet"
 	"gvisor.dev/gvisor/pkg/tcpip/link/fdbased"
 	"gvisor.dev/gvisor/pkg/tcpip/link/loopback"
 	"gvisor.dev/gvisor/pkg/tcpip/link/qdisc/fifo"
+	"gvisor.dev/gvisor/pkg/tcpip/link/qdisc/prio"
 	"gvisor.dev/gvisor/pkg/tcpip/link/qdisc/tbf"
 	"gvisor.dev/gvisor/pkg/tcpip/link/sniffer"
 	"gvisor.dev/gvisor/pkg/tcpip/link/xdp"
@@ -127,6 +129,9 @@ type FDBasedLink struct {
 	// PreConfigured indicates that getsockname and setsockopt(PACKET_FANOUT)
 	// have already been performed on the host FDs.
 	PreConfigured bool
+
+	// DispatchMode controls how inbound packets are read from the FDs.
+	DispatchMode config.NetworkDispatchMode
 }
 
 // BindOpt indicates whether the sentry or runsc process is responsible for
@@ -207,6 +212,32 @@ type CreateLinksAndRoutesArgs struct {
 	IsRestore bool
 }
 
+// LinkConfig is the configuration of an existing link that can be changed at
+// runtime.
+type LinkConfig struct {
+	Name string
+
+	// MTU is the new MTU of the link. Zero leaves it unchanged.
+	MTU int
+
+	// Addresses replace all addresses of the link, except for IPv6 link-local
+	// addresses.
+	Addresses []IPWithPrefix
+
+	// Routes replace all routes through the link.
+	Routes []Route
+}
+
+// ResyncLinksAndRoutesArgs are arguments to ResyncLinksAndRoutes.
+type ResyncLinksAndRoutesArgs struct {
+	Links []LinkConfig
+
+	// Defaultv4Gateway and Defaultv6Gateway are added to the routes of the
+	// link they name, which must be one of Links.
+	Defaultv4Gateway DefaultRoute
+	Defaultv6Gateway DefaultRoute
+}
+
 // InitPluginStackArgs are arguments to InitPluginStack.
 type InitPluginStackArgs struct {
 	urpc.FilePayload
@@ -341,12 +372,17 @@ func (n *Network) CreateLinksAndRoutes(args *CreateLinksAndRoutesArgs, _ *struct
 	// Setup fdbased or XDP links.
 	fdOffset := 0
 	if len(args.FDBasedLinks) > 0 {
-		// Choose a dispatch mode.
-		// Testing has shown that RecvMMsg is the fastest. Attempts to use
-		// the PacketMMap dispatcher have failed to result in higher throughput.
-		dispatchMode := fdbased.RecvMMsg
-
 		for _, link := range args.FDBasedLinks {
+			// Choose a dispatch mode.
+			// Testing has shown that RecvMMsg is the fastest of the
+			// syscall based dispatchers. Attempts to use the PacketMMap
+			// (TPACKET_V1) dispatcher have failed to result in higher
+			// throughput, but TPACKET_V3 rings can be opted into.
+			dispatchMode := fdbased.RecvMMsg
+			if link.DispatchMode == config.NetworkDispatchPacketMMapV3 {
+				dispatchMode = fdbased.PacketMMapV3
+			}
+
 			nicID := n.Stack.NextNICID()
 			nicids[link.Name] = nicID
 
@@ -405,6 +441,9 @@ func (n *Network) CreateLinksAndRoutes(args *CreateLinksAndRoutesArgs, _ *struct
 			case config.QDiscFIFO:
 				log.Infof("Enabling FIFO QDisc on %q", link.Name)
 				qDisc = fifo.New(linkEP, runtime.GOMAXPROCS(0), 1000)
+			case config.QDiscPrio:
+				log.Infof("Enabling priority QDisc on %q", link.Name)
+				qDisc = prio.New(linkEP, runtime.GOMAXPROCS(0), 1000)
 			case config.QDiscTBF:
 				log.Infof("Enabling TBF QDisc on %q rate=%d burst=%d", link.Name, link.TBFRate, link.TBFBurst)
 				var err error
@@ -504,6 +543,9 @@ func (n *Network) CreateLinksAndRoutes(args *CreateLinksAndRoutesArgs, _ *struct
 		case config.QDiscFIFO:
 			log.Infof("Enabling FIFO QDisc on %q", link.Name)
 			qDisc = fifo.New(linkEP, runtime.GOMAXPROCS(0), 1000)
+		case config.QDiscPrio:
+			log.Infof("Enabling priority QDisc on %q", link.Name)
+			qDisc = prio.New(linkEP, runtime.GOMAXPROCS(0), 1000)
 		case config.QDiscTBF:
 			log.Infof("Enabling TBF QDisc on %q rate=%d burst=%d", link.Name, link.TBFRate, link.TBFBurst)
 			var err error
@@ -587,6 +629,121 @@ func (n *Network) CreateLinksAndRoutes(args *CreateLinksAndRoutesArgs, _ *struct
 	return nil
 }
 
+// ResyncLinksAndRoutes applies a new configuration to links that already exist
+// in the network stack, e.g. after a CNI plugin renumbered the pod or changed
+// its MTU. Links and routes that are not part of args are left untouched.
+func (n *Network) ResyncLinksAndRoutes(args *ResyncLinksAndRoutesArgs, _ *struct{}) error {
+	if n.Stack == nil {
+		return fmt.Errorf("resyncing links and routes requires netstack")
+	}
+
+	infos := n.Stack.NICInfo()
+	nicids := make(map[string]tcpip.NICID)
+	for id, info := range infos {
+		nicids[info.Name] = id
+	}
+
+	// Validate the whole configuration before making any change, so that an
+	// invalid request doesn't leave the stack partially configured.
+	resynced := make(map[tcpip.NICID]struct{})
+	var routes []tcpip.Route
+	for _, link := range args.Links {
+		nicID, ok := nicids[link.Name]
+		if !ok {
+			return fmt.Errorf("unknown interface %q", link.Name)
+		}
+		if _, ok := resynced[nicID]; ok {
+			return fmt.Errorf("interface %q specified more than once", link.Name)
+		}
+		resynced[nicID] = struct{}{}
+		if link.MTU < 0 {
+			return fmt.Errorf("invalid MTU %d for interface %q", link.MTU, link.Name)
+		}
+		for _, r := range link.Routes {
+			route, err := r.toTcpipRoute(nicID)
+			if err != nil {
+				return err
+			}
+			routes = append(routes, route)
+		}
+	}
+	for _, gw := range []*DefaultRoute{&args.Defaultv4Gateway, &args.Defaultv6Gateway} {
+		if gw.Route.Empty() {
+			continue
+		}
+		nicID, ok := nicids[gw.Name]
+		if _, resync := resynced[nicID]; !ok || !resync {
+			return fmt.Errorf("invalid interface name %q for default route", gw.Name)
+		}
+		route, err := gw.Route.toTcpipRoute(nicID)
+		if err != nil {
+			return err
+		}
+		routes = append(routes, route)
+	}
+
+	for _, link := range args.Links {
+		nicID := nicids[link.Name]
+		if link.MTU != 0 && uint32(link.MTU) != infos[nicID].MTU {
+			log.Infof("Changing MTU of interface %q from %d to %d", link.Name, infos[nicID].MTU, link.MTU)
+			if err := n.Stack.SetNICMTU(nicID, uint32(link.MTU)); err != nil {
+				return fmt.Errorf("SetNICMTU(%d, %d) failed: %s", nicID, link.MTU, err)
+			}
+		}
+		if err := n.syncAddrs(nicID, infos[nicID].ProtocolAddresses, link.Addresses); err != nil {
+			return err
+		}
+	}
+
+	// Keep the routes through links that were not resynced.
+	for _, r := range n.Stack.GetRouteTable() {
+		if _, ok := resynced[r.NIC]; !ok {
+			routes = append(routes, r)
+		}
+	}
+	log.Infof("Setting routes %+v", routes)
+	n.Stack.SetRouteTable(routes)
+	return nil
+}
+
+// syncAddrs changes the addresses of a NIC from cur to addrs. IPv6 link-local
+// addresses are kept, since they are generated by the stack.
+func (n *Network) syncAddrs(id tcpip.NICID, cur []tcpip.ProtocolAddress, addrs []IPWithPrefix) error {
+	want := make(map[tcpip.ProtocolAddress]struct{})
+	for _, addr := range addrs {
+		proto, tcpipAddr := ipToAddressAndProto(addr.Address)
+		want[tcpip.ProtocolAddress{
+			Protocol: proto,
+			AddressWithPrefix: tcpip.AddressWithPrefix{
+				Address:   tcpipAddr,
+				PrefixLen: addr.PrefixLen,
+			},
+		}] = struct{}{}
+	}
+
+	for _, addr := range cur {
+		if header.IsV6LinkLocalUnicastAddress(addr.AddressWithPrefix.Address) {
+			continue
+		}
+		if _, ok := want[addr]; ok {
+			delete(want, addr)
+			continue
+		}
+		log.Infof("Removing address %s from interface %d", addr.AddressWithPrefix, id)
+		if err := n.Stack.RemoveAddress(id, addr.AddressWithPrefix.Address); err != nil {
+			return fmt.Errorf("RemoveAddress(%d, %s) failed: %s", id, addr.AddressWithPrefix.Address, err)
+		}
+	}
+
+	for addr := range want {
+		log.Infof("Adding address %s to interface %d", addr.AddressWithPrefix, id)
+		if err := n.Stack.AddProtocolAddress(id, addr, stack.AddressProperties{}); err != nil {
+			return fmt.Errorf("AddProtocolAddress(%d, %+v, {}) failed: %s", id, addr, err)
+		}
+	}
+	return nil
+}
+
 // createNICWithAddrs creates a NIC in the network stack and adds the given
 // addresses.
 func (n *Network) createNICWithAddrs(id tcpip.NICID, ep stack.LinkEndpoint, opts stack.NICOptions, addrs []IPWithPrefix) error {
@@ -0,0 +1,278 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+	"gvisor.dev/gvisor/pkg/tcpip/network/ipv4"
+	"gvisor.dev/gvisor/pkg/tcpip/network/ipv6"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+	"gvisor.dev/gvisor/pkg/tcpip/transport/tcp"
+)
+
+// NetworkDiagnostics is a snapshot of the state of the network stack.
+type NetworkDiagnostics struct {
+	Interfaces []InterfaceDiagnostics `json:"interfaces"`
+	Routes     []RouteDiagnostics     `json:"routes"`
+
+	// ConnTrack is the number of connections tracked by iptables, by transport
+	// protocol. It's empty if iptables was never configured.
+	ConnTrack map[string]int `json:"conntrack"`
+
+	Endpoints []EndpointDiagnostics `json:"endpoints"`
+
+	// Drops are the non-zero counters of dropped packets and errors, keyed by
+	// the stat name, e.g. "TCP.ListenOverflowSynDrop".
+	Drops map[string]uint64 `json:"drops"`
+}
+
+// InterfaceDiagnostics describes a network interface.
+type InterfaceDiagnostics struct {
+	ID          int32                 `json:"id"`
+	Name        string                `json:"name"`
+	LinkAddress string                `json:"link_address,omitempty"`
+	MTU         uint32                `json:"mtu"`
+	Up          bool                  `json:"up"`
+	Running     bool                  `json:"running"`
+	Loopback    bool                  `json:"loopback"`
+	Promiscuous bool                  `json:"promiscuous"`
+	Addresses   []string              `json:"addresses"`
+	RxPackets   uint64                `json:"rx_packets"`
+	RxBytes     uint64                `json:"rx_bytes"`
+	TxPackets   uint64                `json:"tx_packets"`
+	TxBytes     uint64                `json:"tx_bytes"`
+	TxNoBuffer  uint64                `json:"tx_dropped_no_buffer_space"`
+	DisabledRx  uint64                `json:"disabled_rx_packets"`
+	Neighbors   []NeighborDiagnostics `json:"neighbors,omitempty"`
+}
+
+// NeighborDiagnostics describes a neighbor cache entry.
+type NeighborDiagnostics struct {
+	Address     string `json:"address"`
+	LinkAddress string `json:"link_address"`
+	State       string `json:"state"`
+}
+
+// RouteDiagnostics describes a route.
+type RouteDiagnostics struct {
+	Destination string `json:"destination"`
+	Gateway     string `json:"gateway,omitempty"`
+	Interface   string `json:"interface"`
+	MTU         uint32 `json:"mtu,omitempty"`
+}
+
+// EndpointDiagnostics describes a transport endpoint and its buffer
+// occupancy.
+type EndpointDiagnostics struct {
+	Protocol      string `json:"protocol"`
+	LocalAddress  string `json:"local_address"`
+	RemoteAddress string `json:"remote_address"`
+	State         string `json:"state"`
+	ReceiveQueue  int    `json:"receive_queue"`
+	SendQueue     int    `json:"send_queue"`
+}
+
+// Diagnostics returns a snapshot of the state of the network stack.
+func (n *Network) Diagnostics(_ *struct{}, out *NetworkDiagnostics) error {
+	if n.Stack == nil {
+		return fmt.Errorf("network diagnostics require netstack")
+	}
+
+	nicNames := make(map[tcpip.NICID]string)
+	for id, info := range n.Stack.NICInfo() {
+		nicNames[id] = info.Name
+		iface := InterfaceDiagnostics{
+			ID:          int32(id),
+			Name:        info.Name,
+			MTU:         info.MTU,
+			Up:          info.Flags.Up,
+			Running:     info.Flags.Running,
+			Loopback:    info.Flags.Loopback,
+			Promiscuous: info.Flags.Promiscuous,
+			RxPackets:   info.Stats.Rx.Packets.Value(),
+			RxBytes:     info.Stats.Rx.Bytes.Value(),
+			TxPackets:   info.Stats.Tx.Packets.Value(),
+			TxBytes:     info.Stats.Tx.Bytes.Value(),
+			TxNoBuffer:  info.Stats.TxPacketsDroppedNoBufferSpace.Value(),
+			DisabledRx:  info.Stats.DisabledRx.Packets.Value(),
+		}
+		if info.LinkAddress != "" {
+			iface.LinkAddress = info.LinkAddress.String()
+		}
+		for _, addr := range info.ProtocolAddresses {
+			iface.Addresses = append(iface.Addresses, addr.AddressWithPrefix.String())
+		}
+		for _, proto := range []tcpip.NetworkProtocolNumber{ipv4.ProtocolNumber, ipv6.ProtocolNumber} {
+			// Not all interfaces have neighbor tables.
+			neighbors, err := n.Stack.Neighbors(id, proto)
+			if err != nil {
+				continue
+			}
+			for _, neigh := range neighbors {
+				iface.Neighbors = append(iface.Neighbors, NeighborDiagnostics{
+					Address:     neigh.Addr.String(),
+					LinkAddress: neigh.LinkAddr.String(),
+					State:       neigh.State.String(),
+				})
+			}
+		}
+		out.Interfaces = append(out.Interfaces, iface)
+	}
+	sort.Slice(out.Interfaces, func(i, j int) bool {
+		return out.Interfaces[i].ID < out.Interfaces[j].ID
+	})
+
+	for _, r := range n.Stack.GetRouteTable() {
+		route := RouteDiagnostics{
+			Destination: r.Destination.String(),
+			Interface:   nicNames[r.NIC],
+			MTU:         r.MTU,
+		}
+		if r.Gateway.Len() > 0 {
+			route.Gateway = r.Gateway.String()
+		}
+		out.Routes = append(out.Routes, route)
+	}
+
+	out.ConnTrack = make(map[string]int)
+	for proto, count := range n.Stack.IPTables().ConnTrackSummary().Connections {
+		out.ConnTrack[transportProtocolName(proto)] = count
+	}
+
+	seen := make(map[stack.TransportEndpoint]struct{})
+	for _, tep := range n.Stack.RegisteredEndpoints() {
+		// Dual-stack endpoints are registered for both IPv4 and IPv6.
+		if _, ok := seen[tep]; ok {
+			continue
+		}
+		seen[tep] = struct{}{}
+		if ep, ok := tep.(tcpip.Endpoint); ok {
+			out.Endpoints = append(out.Endpoints, endpointDiagnostics(ep))
+		}
+	}
+
+	out.Drops = make(map[string]uint64)
+	stats := n.Stack.Stats()
+	out.Drops["DroppedPackets"] = stats.DroppedPackets.Value()
+	for _, group := range []struct {
+		name  string
+		stats any
+	}{
+		{"IP", stats.IP},
+		{"TCP", stats.TCP},
+		{"UDP", stats.UDP},
+		{"ARP", stats.ARP},
+	} {
+		collectDropStats(group.name, reflect.ValueOf(group.stats), out.Drops)
+	}
+	for name, value := range out.Drops {
+		if value == 0 {
+			delete(out.Drops, name)
+		}
+	}
+	return nil
+}
+
+// dropStats are the names of the stats, in any group, that count dropped
+// packets or errors.
+var dropStats = map[string]struct{}{
+	"ChecksumErrors":                      {},
+	"DisabledPacketsReceived":             {},
+	"EstablishedResets":                   {},
+	"FailedConnectionAttempts":            {},
+	"FailedPortReservations":              {},
+	"ForwardMaxInFlightDrop":              {},
+	"InvalidDestinationAddressesReceived": {},
+	"InvalidSegmentsReceived":             {},
+	"InvalidSourceAddressesReceived":      {},
+	"IPTablesForwardDropped":              {},
+	"IPTablesInputDropped":                {},
+	"IPTablesOutputDropped":               {},
+	"IPTablesPostroutingDropped":          {},
+	"IPTablesPreroutingDropped":           {},
+	"ListenOverflowAckDrop":               {},
+	"ListenOverflowSynDrop":               {},
+	"MalformedFragmentsReceived":          {},
+	"MalformedPacketsReceived":            {},
+	"OutgoingPacketErrors":                {},
+	"OutgoingRepliesDropped":              {},
+	"OutgoingRequestsDropped":             {},
+	"PacketSendErrors":                    {},
+	"ReceiveBufferErrors":                 {},
+	"SegmentSendErrors":                   {},
+	"UnknownPortErrors":                   {},
+}
+
+// collectDropStats adds the drop counters of the stats struct v to drops.
+func collectDropStats(prefix string, v reflect.Value, drops map[string]uint64) {
+	counterType := reflect.TypeOf((*tcpip.StatCounter)(nil))
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		name := v.Type().Field(i).Name
+		if f.Type() != counterType || f.IsNil() {
+			continue
+		}
+		if _, ok := dropStats[name]; ok {
+			drops[prefix+"."+name] = f.Interface().(*tcpip.StatCounter).Value()
+		}
+	}
+}
+
+func transportProtocolName(proto tcpip.TransportProtocolNumber) string {
+	switch proto {
+	case header.TCPProtocolNumber:
+		return "tcp"
+	case header.UDPProtocolNumber:
+		return "udp"
+	case header.ICMPv4ProtocolNumber:
+		return "icmp"
+	case header.ICMPv6ProtocolNumber:
+		return "icmpv6"
+	default:
+		return fmt.Sprintf("%d", proto)
+	}
+}
+
+func endpointDiagnostics(ep tcpip.Endpoint) EndpointDiagnostics {
+	var d EndpointDiagnostics
+	if info, ok := ep.Info().(*stack.TransportEndpointInfo); ok {
+		d.Protocol = transportProtocolName(info.TransProto)
+		if info.NetProto == ipv4.ProtocolNumber {
+			d.Protocol += "4"
+		} else {
+			d.Protocol += "6"
+		}
+		d.LocalAddress = fmt.Sprintf("%s:%d", info.ID.LocalAddress, info.ID.LocalPort)
+		d.RemoteAddress = fmt.Sprintf("%s:%d", info.ID.RemoteAddress, info.ID.RemotePort)
+		if info.TransProto == header.TCPProtocolNumber {
+			d.State = tcp.EndpointState(ep.State()).String()
+		}
+	}
+	if d.State == "" {
+		d.State = fmt.Sprintf("%d", ep.State())
+	}
+	if v, err := ep.GetSockOptInt(tcpip.ReceiveQueueSizeOption); err == nil {
+		d.ReceiveQueue = v
+	}
+	if v, err := ep.GetSockOptInt(tcpip.SendQueueSizeOption); err == nil {
+		d.SendQueue = v
+	}
+	return d
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/socket/netstack"
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+	"gvisor.dev/gvisor/pkg/tcpip/transport/tcp"
+	"gvisor.dev/gvisor/pkg/tcpip/transport/udp"
+	"gvisor.dev/gvisor/runsc/specutils"
+)
+
+// NetworkPolicy is an allowlist of the TCP and UDP traffic of a container. It
+// is enforced by netstack independently of iptables, so it can't be changed
+// from inside the sandbox.
+//
+// A nil list of rules allows all traffic in that direction, while an empty
+// list denies all of it.
+type NetworkPolicy struct {
+	// Ingress are the rules for incoming connections and datagrams.
+	Ingress []NetworkPolicyRule `json:"ingress"`
+
+	// Egress are the rules for outgoing connections and datagrams.
+	Egress []NetworkPolicyRule `json:"egress"`
+}
+
+// NetworkPolicyRule allows traffic with a CIDR on a range of ports.
+type NetworkPolicyRule struct {
+	// CIDR is the subnet of the peer, e.g. "10.0.0.0/8".
+	CIDR string `json:"cidr"`
+
+	// Protocol is "tcp", "udp", or empty to match both.
+	Protocol string `json:"protocol,omitempty"`
+
+	// Ports is a port, e.g. "80", or an inclusive range of ports, e.g.
+	// "8000-8080". For ingress rules these are ports of the container, and
+	// for egress rules ports of the peer. Empty matches all ports.
+	Ports string `json:"ports,omitempty"`
+}
+
+// ParseNetworkPolicy parses a network policy in JSON format.
+func ParseNetworkPolicy(val string) (*NetworkPolicy, error) {
+	var policy NetworkPolicy
+	if err := json.Unmarshal([]byte(val), &policy); err != nil {
+		return nil, fmt.Errorf("parsing network policy: %w", err)
+	}
+	return &policy, nil
+}
+
+func (r *NetworkPolicyRule) toStack() (stack.NetworkPolicyRule, error) {
+	var rule stack.NetworkPolicyRule
+	_, ipNet, err := net.ParseCIDR(r.CIDR)
+	if err != nil {
+		return rule, fmt.Errorf("invalid cidr %q: %w", r.CIDR, err)
+	}
+	rule.Subnet, err = tcpip.NewSubnet(ipToAddress(ipNet.IP), ipMaskToAddressMask(ipNet.Mask))
+	if err != nil {
+		return rule, fmt.Errorf("invalid cidr %q: %w", r.CIDR, err)
+	}
+
+	switch r.Protocol {
+	case "":
+	case "tcp":
+		rule.Protocol = tcp.ProtocolNumber
+	case "udp":
+		rule.Protocol = udp.ProtocolNumber
+	default:
+		return rule, fmt.Errorf("invalid protocol %q, must be \"tcp\" or \"udp\"", r.Protocol)
+	}
+
+	if r.Ports != "" {
+		start, end, isRange := strings.Cut(r.Ports, "-")
+		if !isRange {
+			end = start
+		}
+		startPort, err := strconv.ParseUint(start, 10, 16)
+		if err != nil || startPort == 0 {
+			return rule, fmt.Errorf("invalid ports %q", r.Ports)
+		}
+		endPort, err := strconv.ParseUint(end, 10, 16)
+		if err != nil || endPort < startPort {
+			return rule, fmt.Errorf("invalid ports %q", r.Ports)
+		}
+		rule.PortStart = uint16(startPort)
+		rule.PortEnd = uint16(endPort)
+	}
+	return rule, nil
+}
+
+func rulesToStack(rules []NetworkPolicyRule) ([]stack.NetworkPolicyRule, error) {
+	if rules == nil {
+		return nil, nil
+	}
+	rv := make([]stack.NetworkPolicyRule, 0, len(rules))
+	for i := range rules {
+		rule, err := rules[i].toStack()
+		if err != nil {
+			return nil, err
+		}
+		rv = append(rv, rule)
+	}
+	return rv, nil
+}
+
+func (p *NetworkPolicy) toStack() (*stack.NetworkPolicy, error) {
+	ingress, err := rulesToStack(p.Ingress)
+	if err != nil {
+		return nil, fmt.Errorf("ingress: %w", err)
+	}
+	egress, err := rulesToStack(p.Egress)
+	if err != nil {
+		return nil, fmt.Errorf("egress: %w", err)
+	}
+	return &stack.NetworkPolicy{Ingress: ingress, Egress: egress}, nil
+}
+
+// setNetworkPolicy sets the network policy of the given container. A nil
+// policy removes it.
+func (l *Loader) setNetworkPolicy(cid string, policy *NetworkPolicy) error {
+	eps, ok := l.k.RootNetworkNamespace().Stack().(*netstack.Stack)
+	if !ok {
+		if policy == nil {
+			return nil
+		}
+		return fmt.Errorf("network policies require netstack")
+	}
+	if policy == nil {
+		eps.Stack.SetNetworkPolicy(cid, nil)
+		return nil
+	}
+	p, err := policy.toStack()
+	if err != nil {
+		return err
+	}
+	log.Infof("Setting network policy for container %q: %+v", cid, policy)
+	eps.Stack.SetNetworkPolicy(cid, p)
+	return nil
+}
+
+// setNetworkPolicyFromSpec sets the network policy of the given container from
+// its annotations, if present.
+func (l *Loader) setNetworkPolicyFromSpec(spec *specs.Spec, cid string) error {
+	val, ok := spec.Annotations[specutils.AnnotationNetworkPolicy]
+	if !ok {
+		return nil
+	}
+	policy, err := ParseNetworkPolicy(val)
+	if err != nil {
+		return err
+	}
+	return l.setNetworkPolicy(cid, policy)
+}
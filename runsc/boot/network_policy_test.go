@@ -0,0 +1,106 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"strings"
+	"testing"
+
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+	"gvisor.dev/gvisor/pkg/tcpip/testutil"
+)
+
+func TestNetworkPolicy(t *testing.T) {
+	policy, err := ParseNetworkPolicy(`{
+		"ingress": [{"cidr": "10.0.0.0/8", "protocol": "tcp", "ports": "80"}],
+		"egress": [{"cidr": "192.168.0.0/16", "ports": "8000-8080"}, {"cidr": "fd00::/8"}]
+	}`)
+	if err != nil {
+		t.Fatalf("ParseNetworkPolicy(): %v", err)
+	}
+	p, err := policy.toStack()
+	if err != nil {
+		t.Fatalf("toStack(): %v", err)
+	}
+	for _, tc := range []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{
+			name: "ingress allowed",
+			got:  p.AllowsIngress(header.TCPProtocolNumber, testutil.MustParse4("10.0.0.1"), 80),
+			want: true,
+		},
+		{
+			name: "ingress wrong protocol",
+			got:  p.AllowsIngress(header.UDPProtocolNumber, testutil.MustParse4("10.0.0.1"), 80),
+		},
+		{
+			name: "egress in range",
+			got:  p.AllowsEgress(header.UDPProtocolNumber, testutil.MustParse4("192.168.1.1"), 8080),
+			want: true,
+		},
+		{
+			name: "egress out of range",
+			got:  p.AllowsEgress(header.TCPProtocolNumber, testutil.MustParse4("192.168.1.1"), 8081),
+		},
+		{
+			name: "egress ipv6",
+			got:  p.AllowsEgress(header.TCPProtocolNumber, testutil.MustParse6("fd00::1"), 1),
+			want: true,
+		},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s: got allowed = %t, want = %t", tc.name, tc.got, tc.want)
+		}
+	}
+
+	// Missing directions allow everything, empty ones deny everything.
+	policy, err = ParseNetworkPolicy(`{"egress": []}`)
+	if err != nil {
+		t.Fatalf("ParseNetworkPolicy(): %v", err)
+	}
+	if p, err = policy.toStack(); err != nil {
+		t.Fatalf("toStack(): %v", err)
+	}
+	if !p.AllowsIngress(header.TCPProtocolNumber, testutil.MustParse4("1.2.3.4"), 1) {
+		t.Errorf("ingress denied without ingress rules")
+	}
+	if p.AllowsEgress(header.TCPProtocolNumber, testutil.MustParse4("1.2.3.4"), 1) {
+		t.Errorf("egress allowed with empty egress rules")
+	}
+}
+
+func TestNetworkPolicyInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		policy string
+		err    string
+	}{
+		{policy: `{"ingress": [{"cidr": "10.0.0.0"}]}`, err: "invalid cidr"},
+		{policy: `{"ingress": [{"cidr": "10.0.0.0/8", "protocol": "icmp"}]}`, err: "invalid protocol"},
+		{policy: `{"egress": [{"cidr": "10.0.0.0/8", "ports": "0"}]}`, err: "invalid ports"},
+		{policy: `{"egress": [{"cidr": "10.0.0.0/8", "ports": "90-80"}]}`, err: "invalid ports"},
+		{policy: `{"egress": [{"cidr": "10.0.0.0/8", "ports": "65536"}]}`, err: "invalid ports"},
+	} {
+		policy, err := ParseNetworkPolicy(tc.policy)
+		if err != nil {
+			t.Fatalf("ParseNetworkPolicy(%q): %v", tc.policy, err)
+		}
+		if _, err := policy.toStack(); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("toStack(%q), got: %v, want: %q", tc.policy, err, tc.err)
+		}
+	}
+}
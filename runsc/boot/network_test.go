@@ -0,0 +1,185 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"net"
+	"testing"
+
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+	"gvisor.dev/gvisor/pkg/tcpip/link/loopback"
+	"gvisor.dev/gvisor/pkg/tcpip/network/ipv4"
+	"gvisor.dev/gvisor/pkg/tcpip/network/ipv6"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+	"gvisor.dev/gvisor/pkg/tcpip/testutil"
+)
+
+func TestResyncLinksAndRoutes(t *testing.T) {
+	s := stack.New(stack.Options{
+		NetworkProtocols: []stack.NetworkProtocolFactory{ipv4.NewProtocol, ipv6.NewProtocol},
+	})
+	defer s.Destroy()
+	n := &Network{Stack: s}
+
+	const (
+		loID  = 1
+		ethID = 2
+	)
+	if err := n.createNICWithAddrs(loID, loopback.New(), stack.NICOptions{Name: "lo"}, []IPWithPrefix{{Address: net.IPv4(127, 0, 0, 1), PrefixLen: 8}}); err != nil {
+		t.Fatalf("createNICWithAddrs(lo): %v", err)
+	}
+	if err := n.createNICWithAddrs(ethID, loopback.New(), stack.NICOptions{Name: "eth0"}, []IPWithPrefix{{Address: net.IPv4(10, 0, 0, 2), PrefixLen: 24}}); err != nil {
+		t.Fatalf("createNICWithAddrs(eth0): %v", err)
+	}
+	s.SetRouteTable([]tcpip.Route{
+		{Destination: testutil.MustParseSubnet4("127.0.0.0/8"), NIC: loID},
+		{Destination: testutil.MustParseSubnet4("10.0.0.0/24"), NIC: ethID},
+	})
+
+	args := ResyncLinksAndRoutesArgs{
+		Links: []LinkConfig{
+			{
+				Name:      "eth0",
+				MTU:       1400,
+				Addresses: []IPWithPrefix{{Address: net.IPv4(10, 1, 0, 2), PrefixLen: 24}},
+				Routes: []Route{
+					{
+						Destination: net.IPNet{IP: net.IPv4(10, 1, 0, 0), Mask: net.CIDRMask(24, 32)},
+					},
+				},
+			},
+		},
+		Defaultv4Gateway: DefaultRoute{
+			Route: Route{
+				Destination: net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)},
+				Gateway:     net.IPv4(10, 1, 0, 1),
+			},
+			Name: "eth0",
+		},
+	}
+	if err := n.ResyncLinksAndRoutes(&args, nil); err != nil {
+		t.Fatalf("ResyncLinksAndRoutes(): %v", err)
+	}
+
+	info := s.NICInfo()[ethID]
+	if info.MTU != 1400 {
+		t.Errorf("wrong MTU, got: %d, want: 1400", info.MTU)
+	}
+	var addrs []tcpip.AddressWithPrefix
+	for _, addr := range info.ProtocolAddresses {
+		if !header.IsV6LinkLocalUnicastAddress(addr.AddressWithPrefix.Address) {
+			addrs = append(addrs, addr.AddressWithPrefix)
+		}
+	}
+	want := tcpip.AddressWithPrefix{Address: testutil.MustParse4("10.1.0.2"), PrefixLen: 24}
+	if len(addrs) != 1 || addrs[0] != want {
+		t.Errorf("wrong addresses, got: %v, want: [%v]", addrs, want)
+	}
+
+	routes := make(map[string]tcpip.NICID)
+	for _, r := range s.GetRouteTable() {
+		routes[r.Destination.String()] = r.NIC
+	}
+	wantRoutes := map[string]tcpip.NICID{
+		"127.0.0.0/8": loID,
+		"10.1.0.0/24": ethID,
+		"0.0.0.0/0":   ethID,
+	}
+	if len(routes) != len(wantRoutes) {
+		t.Errorf("wrong routes, got: %v, want: %v", routes, wantRoutes)
+	}
+	for dst, nic := range wantRoutes {
+		if got, ok := routes[dst]; !ok || got != nic {
+			t.Errorf("wrong route for %s, got: %v, want: NIC %d", dst, routes, nic)
+		}
+	}
+}
+
+func TestResyncLinksAndRoutesInvalid(t *testing.T) {
+	s := stack.New(stack.Options{
+		NetworkProtocols: []stack.NetworkProtocolFactory{ipv4.NewProtocol},
+	})
+	defer s.Destroy()
+	n := &Network{Stack: s}
+	if err := n.createNICWithAddrs(1, loopback.New(), stack.NICOptions{Name: "eth0"}, []IPWithPrefix{{Address: net.IPv4(10, 0, 0, 2), PrefixLen: 24}}); err != nil {
+		t.Fatalf("createNICWithAddrs(eth0): %v", err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		args ResyncLinksAndRoutesArgs
+	}{
+		{
+			name: "unknown link",
+			args: ResyncLinksAndRoutesArgs{Links: []LinkConfig{{Name: "eth1"}}},
+		},
+		{
+			name: "duplicate link",
+			args: ResyncLinksAndRoutesArgs{Links: []LinkConfig{{Name: "eth0"}, {Name: "eth0"}}},
+		},
+		{
+			name: "gateway on other link",
+			args: ResyncLinksAndRoutesArgs{
+				Defaultv4Gateway: DefaultRoute{
+					Route: Route{Gateway: net.IPv4(10, 0, 0, 1)},
+					Name:  "eth0",
+				},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := n.ResyncLinksAndRoutes(&tc.args, nil); err == nil {
+				t.Errorf("ResyncLinksAndRoutes(%+v) succeeded, want error", tc.args)
+			}
+			// The stack must be left untouched.
+			addrs := s.AllAddresses()[1]
+			if len(addrs) != 1 || addrs[0].AddressWithPrefix.Address != testutil.MustParse4("10.0.0.2") {
+				t.Errorf("addresses changed: %v", addrs)
+			}
+		})
+	}
+}
+
+func TestNetworkDiagnostics(t *testing.T) {
+	s := stack.New(stack.Options{
+		NetworkProtocols: []stack.NetworkProtocolFactory{ipv4.NewProtocol},
+	})
+	defer s.Destroy()
+	n := &Network{Stack: s}
+	if err := n.createNICWithAddrs(1, loopback.New(), stack.NICOptions{Name: "lo"}, []IPWithPrefix{{Address: net.IPv4(127, 0, 0, 1), PrefixLen: 8}}); err != nil {
+		t.Fatalf("createNICWithAddrs(lo): %v", err)
+	}
+	s.SetRouteTable([]tcpip.Route{{Destination: testutil.MustParseSubnet4("127.0.0.0/8"), NIC: 1}})
+	s.Stats().IP.MalformedPacketsReceived.IncrementBy(3)
+
+	var diag NetworkDiagnostics
+	if err := n.Diagnostics(nil, &diag); err != nil {
+		t.Fatalf("Diagnostics(): %v", err)
+	}
+	if len(diag.Interfaces) != 1 {
+		t.Fatalf("wrong interfaces, got: %+v, want: lo", diag.Interfaces)
+	}
+	if iface := diag.Interfaces[0]; iface.Name != "lo" || !iface.Loopback || len(iface.Addresses) != 1 || iface.Addresses[0] != "127.0.0.1/8" {
+		t.Errorf("wrong interface, got: %+v", iface)
+	}
+	if len(diag.Routes) != 1 || diag.Routes[0].Destination != "127.0.0.0/8" || diag.Routes[0].Interface != "lo" {
+		t.Errorf("wrong routes, got: %+v", diag.Routes)
+	}
+	want := map[string]uint64{"IP.MalformedPacketsReceived": 3}
+	if len(diag.Drops) != len(want) || diag.Drops["IP.MalformedPacketsReceived"] != 3 {
+		t.Errorf("wrong drops, got: %v, want: %v", diag.Drops, want)
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/metric"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+)
+
+var (
+	overlayUpperBytes = metric.MustCreateNewUint64Metric("/fs/overlay_upper_bytes", metric.Uint64Metadata{
+		Description: "Space used by the rootfs overlay upper layers of all containers, in bytes, as of the last sample.",
+	})
+	overlayUpperWarnings = metric.MustCreateNewUint64Metric("/fs/overlay_upper_size_warnings", metric.Uint64Metadata{
+		Cumulative:  true,
+		Description: "Number of times a container's rootfs overlay upper layer grew past --overlay2-upper-warn-size.",
+	})
+)
+
+// RootfsUpper contains stats on the container's rootfs overlay upper layer.
+type RootfsUpper struct {
+	// Usage is the number of bytes used as of the last sample.
+	Usage uint64 `json:"usage"`
+	// Inodes is the number of inodes as of the last sample.
+	Inodes uint64 `json:"inodes"`
+	// Peak is the largest number of bytes used in any sample.
+	Peak uint64 `json:"peak,omitempty"`
+	// Growth is the change in Usage between the last two samples, in bytes
+	// per second.
+	Growth int64 `json:"growth,omitempty"`
+}
+
+// overlayUsageSample is the last sample of a container's rootfs overlay upper
+// layer.
+type overlayUsageSample struct {
+	time  time.Time
+	stats RootfsUpper
+
+	// warned is true if a warning was sent since usage last went above the
+	// warning threshold.
+	warned bool
+}
+
+// overlayUsageMonitor periodically samples the space used by the rootfs
+// overlay upper layer of each container, updating metrics and sending an
+// event when a container's upper layer grows past a threshold.
+type overlayUsageMonitor struct {
+	l *Loader
+
+	// interval is the sampling interval. interval is immutable.
+	interval time.Duration
+
+	// warnSize is the warning threshold in bytes, 0 if disabled. warnSize is
+	// immutable.
+	warnSize uint64
+
+	// stop is closed to stop sampling.
+	stop chan struct{}
+
+	// stopOnce ensures that stop is closed once.
+	stopOnce sync.Once
+
+	mu sync.Mutex
+
+	// samples maps container IDs to their last sample.
+	//
+	// +checklocks:mu
+	samples map[string]*overlayUsageSample
+}
+
+func newOverlayUsageMonitor(l *Loader, interval time.Duration, warnSize uint64) *overlayUsageMonitor {
+	return &overlayUsageMonitor{
+		l:        l,
+		interval: interval,
+		warnSize: warnSize,
+		stop:     make(chan struct{}),
+		samples:  make(map[string]*overlayUsageSample),
+	}
+}
+
+// start starts sampling in the background. It is a no-op if sampling is
+// disabled.
+func (m *overlayUsageMonitor) start() {
+	if m.interval <= 0 {
+		return
+	}
+	go func() { // S/R-SAFE: does not impact state directly.
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.sample()
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+}
+
+// stopSampling stops sampling started by start.
+func (m *overlayUsageMonitor) stopSampling() {
+	m.stopOnce.Do(func() { close(m.stop) })
+}
+
+// sample samples the rootfs overlay upper layer of all containers.
+func (m *overlayUsageMonitor) sample() {
+	m.l.mu.Lock()
+	var cids []string
+	for eid := range m.l.processes {
+		if eid.pid == 0 {
+			cids = append(cids, eid.cid)
+		}
+	}
+	m.l.mu.Unlock()
+
+	now := time.Now()
+	var total uint64
+	var warnings []SandboxEvent
+	m.mu.Lock()
+	live := make(map[string]struct{}, len(cids))
+	for _, cid := range cids {
+		usage, ok, err := m.l.rootfsUpperUsage(cid)
+		if err != nil {
+			log.Debugf("Failed to sample rootfs upper layer usage of container %q: %v", cid, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		live[cid] = struct{}{}
+		total += usage.Bytes
+
+		s, ok := m.samples[cid]
+		if !ok {
+			s = &overlayUsageSample{}
+			m.samples[cid] = s
+		} else if elapsed := now.Sub(s.time).Seconds(); elapsed > 0 {
+			s.stats.Growth = int64(float64(int64(usage.Bytes)-int64(s.stats.Usage)) / elapsed)
+		}
+		s.time = now
+		s.stats.Usage = usage.Bytes
+		s.stats.Inodes = usage.Inodes
+		s.stats.Peak = max(s.stats.Peak, usage.Bytes)
+
+		if m.warnSize == 0 {
+			continue
+		}
+		if usage.Bytes < m.warnSize {
+			// Re-arm the warning once usage drops below the threshold.
+			s.warned = false
+		} else if !s.warned {
+			s.warned = true
+			warnings = append(warnings, SandboxEvent{
+				Type:        SandboxEventOverlayUpperWarning,
+				ContainerID: cid,
+				Message:     fmt.Sprintf("rootfs overlay upper layer uses %d bytes, above the warning threshold of %d bytes", usage.Bytes, m.warnSize),
+			})
+		}
+	}
+	for cid := range m.samples {
+		if _, ok := live[cid]; !ok {
+			delete(m.samples, cid)
+		}
+	}
+	m.mu.Unlock()
+
+	overlayUpperBytes.Set(total)
+	for _, ev := range warnings {
+		log.Warningf("Container %q: %s", ev.ContainerID, ev.Message)
+		overlayUpperWarnings.Increment()
+		m.l.events.publish(ev, 0)
+	}
+}
+
+// stats returns stats on the rootfs overlay upper layer of container cid, or
+// nil if the container hasn't started or its rootfs is not an overlay. If
+// sampling is disabled or cid has not been sampled yet, usage is read
+// directly and Peak and Growth are not reported.
+func (m *overlayUsageMonitor) stats(cid string) (*RootfsUpper, error) {
+	m.mu.Lock()
+	s, ok := m.samples[cid]
+	if ok {
+		stats := s.stats
+		m.mu.Unlock()
+		return &stats, nil
+	}
+	m.mu.Unlock()
+
+	usage, ok, err := m.l.rootfsUpperUsage(cid)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &RootfsUpper{Usage: usage.Bytes, Inodes: usage.Inodes}, nil
+}
+
+// rootfsUpperUsage returns the space used by the rootfs overlay upper layer
+// of the given container. ok is false if the container hasn't started or its
+// rootfs is not an overlay.
+func (l *Loader) rootfsUpperUsage(cid string) (usage vfs.UpperLayerUsage, ok bool, err error) {
+	l.mu.Lock()
+	tg, err := l.tryThreadGroupFromIDLocked(execID{cid: cid})
+	if err != nil || tg == nil {
+		l.mu.Unlock()
+		return vfs.UpperLayerUsage{}, false, err
+	}
+	// task.MountNamespace() does not take a ref, so we must do so ourselves.
+	mns := tg.Leader().MountNamespace()
+	if mns == nil || !mns.TryIncRef() {
+		l.mu.Unlock()
+		return vfs.UpperLayerUsage{}, false, nil
+	}
+	l.mu.Unlock()
+
+	ctx := l.k.SupervisorContext()
+	defer mns.DecRef(ctx)
+	root := mns.Root(ctx)
+	defer root.DecRef(ctx)
+	ur, ok := root.Mount().Filesystem().Impl().(vfs.UpperLayerUsageReporter)
+	if !ok {
+		return vfs.UpperLayerUsage{}, false, nil
+	}
+	usage, err = ur.UpperLayerUsage(ctx)
+	if err != nil {
+		return vfs.UpperLayerUsage{}, false, err
+	}
+	return usage, true, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"sync"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/log"
+)
+
+// pageMerger periodically deduplicates identical application memory pages
+// across all containers, as configured by --page-merging-interval.
+type pageMerger struct {
+	l *Loader
+
+	// interval is the interval between merging passes. interval is
+	// immutable.
+	interval time.Duration
+
+	// startOnce ensures that merging is started once.
+	startOnce sync.Once
+
+	// stop is closed to stop merging.
+	stop chan struct{}
+
+	// stopOnce ensures that stop is closed once.
+	stopOnce sync.Once
+}
+
+func newPageMerger(l *Loader, interval time.Duration) *pageMerger {
+	return &pageMerger{
+		l:        l,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// start starts merging in the background. It is a no-op if merging is
+// disabled or already started.
+func (m *pageMerger) start() {
+	if m.interval <= 0 {
+		return
+	}
+	m.startOnce.Do(func() {
+		go func() { // S/R-SAFE: Kernel.SaveTo excludes merging passes.
+			ticker := time.NewTicker(m.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					// The Kernel is replaced on restore, so look it up on
+					// each pass.
+					if n := m.l.k.MergePages(); n != 0 {
+						log.Debugf("Same-page merging merged %d pages", n)
+					}
+				case <-m.stop:
+					return
+				}
+			}
+		}()
+	})
+}
+
+// stopMerging stops merging started by start.
+func (m *pageMerger) stopMerging() {
+	m.stopOnce.Do(func() { close(m.stop) })
+}
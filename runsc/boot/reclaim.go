@@ -0,0 +1,81 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"gvisor.dev/gvisor/pkg/log"
+)
+
+// ReclaimArgs are arguments to the Reclaim method.
+type ReclaimArgs struct {
+	// Bytes is the amount of memory that the sandbox should try to return to
+	// the host. If 0, the sandbox returns as much memory as it can.
+	Bytes uint64 `json:"bytes"`
+}
+
+// ReclaimResult is the result of the Reclaim method.
+type ReclaimResult struct {
+	// MemoryFile is the number of bytes of application memory, including
+	// page cache, returned to the host.
+	MemoryFile uint64 `json:"memory_file"`
+
+	// Heap is the number of bytes of sentry heap returned to the host.
+	Heap uint64 `json:"heap"`
+}
+
+// Reclaim asks the sandbox to return args.Bytes of memory to the host, e.g. in
+// response to host memory pressure. This allows the host to overcommit memory
+// to sandboxes, with sandboxes cooperatively shrinking when memory is scarce.
+//
+// Memory is reclaimed by dropping clean page cache and returning freed
+// application memory to the host. If that is not enough, the sentry's own heap
+// is garbage collected and returned to the host as well. Reclaim is best
+// effort: the amount of memory returned may be more or less than requested.
+func (cm *containerManager) Reclaim(args *ReclaimArgs, out *ReclaimResult) error {
+	log.Debugf("containerManager.Reclaim, bytes: %d", args.Bytes)
+	mf := cm.l.k.MemoryFile()
+	if mf == nil {
+		return fmt.Errorf("sandbox has no MemoryFile")
+	}
+	n, err := mf.Reclaim(args.Bytes)
+	if err != nil {
+		return fmt.Errorf("reclaiming application memory: %w", err)
+	}
+	out.MemoryFile = n
+	if args.Bytes == 0 || n < args.Bytes {
+		out.Heap = freeSentryHeap()
+	}
+	log.Infof("Reclaimed %d bytes of application memory and %d bytes of sentry heap (requested %d bytes)", out.MemoryFile, out.Heap, args.Bytes)
+	return nil
+}
+
+// freeSentryHeap garbage collects the sentry heap and returns as much of it as
+// possible to the host. It returns the number of bytes returned.
+func freeSentryHeap() uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	debug.FreeOSMemory()
+	runtime.ReadMemStats(&after)
+	retainedBefore := before.HeapSys - before.HeapReleased
+	retainedAfter := after.HeapSys - after.HeapReleased
+	if retainedAfter >= retainedBefore {
+		return 0
+	}
+	return retainedBefore - retainedAfter
+}
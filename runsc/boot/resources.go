@@ -0,0 +1,145 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"fmt"
+	"strconv"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/control"
+)
+
+// UpdateResourcesArgs are arguments to the UpdateResources method.
+type UpdateResourcesArgs struct {
+	// ContainerID is the container whose resources are updated.
+	ContainerID string
+
+	// Resources are the container's new resource limits. Limits that are
+	// not set are removed.
+	Resources *specs.LinuxResources
+}
+
+// updateResources applies new resource limits to a running container, as
+// requested by "runsc update".
+//
+// The CPU quota is enforced by the sentry if --sentry-cpu-bandwidth is set.
+// The memory, pids and CPU limits are also written to the container's cgroup
+// in the sandbox's cgroupfs, where pids.max is enforced and the other limits
+// are visible to the application. Since cgroupfs may not be mounted in the
+// sandbox, failures to update the memory and CPU limits are logged rather than
+// returned. Failures to set a pids limit are returned, since the limit
+// wouldn't be enforced otherwise.
+func (l *Loader) updateResources(cid string, res *specs.LinuxResources) error {
+	l.mu.Lock()
+	_, err := l.tryThreadGroupFromIDLocked(execID{cid: cid})
+	l.mu.Unlock()
+	if err != nil {
+		// Container doesn't exist.
+		return err
+	}
+	if res == nil {
+		res = &specs.LinuxResources{}
+	}
+
+	if err := l.setCPUBandwidth(res.CPU, l.root.conf, cid); err != nil {
+		return fmt.Errorf("setting CPU bandwidth: %w", err)
+	}
+
+	args := control.CgroupsWriteArgs{
+		Args: cgroupResourceWrites(cid, res, l.k.Cgroup2FS().EverMounted()),
+	}
+	var out control.CgroupsResults
+	cgroups := control.Cgroups{Kernel: l.k}
+	if err := cgroups.WriteControlFiles(&args, &out); err != nil {
+		return err
+	}
+	for i, r := range out.Results {
+		if err := r.AsError(); err != nil {
+			f := args.Args[i].File
+			if f.Name == "pids.max" && res.Pids != nil && res.Pids.Limit > 0 {
+				return fmt.Errorf("setting pids limit for container %q in cgroup %q: %w", cid, f.Path, err)
+			}
+			log.Warningf("could not set %s for container %q in cgroup %q: %v", f.Name, cid, f.Path, err)
+		}
+	}
+	return nil
+}
+
+// cgroupResourceWrites returns the cgroupfs control file writes that set the
+// memory, pids and CPU limits in res for the given container. If v2 is true,
+// cgroup v2 control files are used.
+func cgroupResourceWrites(cid string, res *specs.LinuxResources, v2 bool) []control.CgroupsWriteArg {
+	path := "/" + cid
+	write := func(controller, name, value string) control.CgroupsWriteArg {
+		return control.CgroupsWriteArg{
+			File: control.CgroupControlFile{
+				Controller: controller,
+				Path:       path,
+				Name:       name,
+			},
+			Value: value,
+		}
+	}
+
+	// Linux, and runc, treat negative limits as unlimited.
+	limit := func(v *int64, unlimited string) string {
+		if v == nil || *v < 0 {
+			return unlimited
+		}
+		return strconv.FormatInt(*v, 10)
+	}
+
+	var memory *int64
+	if res.Memory != nil {
+		memory = res.Memory.Limit
+	}
+	var pids *int64
+	if res.Pids != nil && res.Pids.Limit > 0 {
+		pids = &res.Pids.Limit
+	}
+	var quota *int64
+	var period uint64
+	if res.CPU != nil {
+		if res.CPU.Quota != nil && *res.CPU.Quota > 0 {
+			quota = res.CPU.Quota
+		}
+		if res.CPU.Period != nil {
+			period = *res.CPU.Period
+		}
+	}
+
+	if v2 {
+		cpuMax := limit(quota, "max")
+		if period != 0 {
+			cpuMax += " " + strconv.FormatUint(period, 10)
+		}
+		return []control.CgroupsWriteArg{
+			write("memory", "memory.max", limit(memory, "max")),
+			write("pids", "pids.max", limit(pids, "max")),
+			write("cpu", "cpu.max", cpuMax),
+		}
+	}
+	writes := []control.CgroupsWriteArg{
+		write("memory", "memory.limit_in_bytes", limit(memory, "-1")),
+		write("pids", "pids.max", limit(pids, "max")),
+		write("cpu", "cpu.cfs_quota_us", limit(quota, "-1")),
+	}
+	if period != 0 {
+		writes = append(writes, write("cpu", "cpu.cfs_period_us", strconv.FormatUint(period, 10)))
+	}
+	return writes
+}
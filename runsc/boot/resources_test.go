@@ -0,0 +1,96 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func TestCgroupResourceWrites(t *testing.T) {
+	i64 := func(v int64) *int64 { return &v }
+	u64 := func(v uint64) *uint64 { return &v }
+	limits := &specs.LinuxResources{
+		Memory: &specs.LinuxMemory{Limit: i64(1 << 30)},
+		Pids:   &specs.LinuxPids{Limit: 100},
+		CPU:    &specs.LinuxCPU{Quota: i64(50000), Period: u64(100000)},
+	}
+	unlimited := &specs.LinuxResources{
+		Memory: &specs.LinuxMemory{Limit: i64(-1)},
+		CPU:    &specs.LinuxCPU{Quota: i64(-1)},
+	}
+
+	for _, tc := range []struct {
+		name string
+		res  *specs.LinuxResources
+		v2   bool
+		want map[string]string
+	}{
+		{
+			name: "v1",
+			res:  limits,
+			want: map[string]string{
+				"memory.limit_in_bytes": "1073741824",
+				"pids.max":              "100",
+				"cpu.cfs_quota_us":      "50000",
+				"cpu.cfs_period_us":     "100000",
+			},
+		},
+		{
+			name: "v1 unlimited",
+			res:  unlimited,
+			want: map[string]string{
+				"memory.limit_in_bytes": "-1",
+				"pids.max":              "max",
+				"cpu.cfs_quota_us":      "-1",
+			},
+		},
+		{
+			name: "v2",
+			res:  limits,
+			v2:   true,
+			want: map[string]string{
+				"memory.max": "1073741824",
+				"pids.max":   "100",
+				"cpu.max":    "50000 100000",
+			},
+		},
+		{
+			name: "v2 unlimited",
+			res:  &specs.LinuxResources{},
+			v2:   true,
+			want: map[string]string{
+				"memory.max": "max",
+				"pids.max":   "max",
+				"cpu.max":    "max",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := make(map[string]string)
+			for _, w := range cgroupResourceWrites("foo", tc.res, tc.v2) {
+				if w.File.Path != "/foo" {
+					t.Errorf("write to %s in cgroup %q, want cgroup \"/foo\"", w.File.Name, w.File.Path)
+				}
+				got[w.File.Name] = w.Value
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("cgroupResourceWrites() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	time2 "time"
 
 	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"golang.org/x/sys/unix"
 	"gvisor.dev/gvisor/pkg/abi/linux"
 	"gvisor.dev/gvisor/pkg/cleanup"
 	"gvisor.dev/gvisor/pkg/context"
@@ -43,10 +44,13 @@ import (
 	"gvisor.dev/gvisor/pkg/sentry/watchdog"
 	"gvisor.dev/gvisor/pkg/state/statefile"
 	"gvisor.dev/gvisor/pkg/sync"
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
 	"gvisor.dev/gvisor/pkg/timing"
 	"gvisor.dev/gvisor/pkg/urpc"
 	"gvisor.dev/gvisor/runsc/boot/pprof"
 	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/goferauth"
 	"gvisor.dev/gvisor/runsc/specutils"
 	"gvisor.dev/gvisor/runsc/starttime"
 	"gvisor.dev/gvisor/runsc/version"
@@ -105,6 +109,13 @@ const (
 	// exec binary.
 	annotationSaveRestoreExecTimeout = annotationCheckpointPrefix + "save-restore-exec-timeout"
 
+	// annotationCloneSignal is the signal, as a name or number, that is sent to
+	// all processes in the container when it's restored as a clone, i.e. into
+	// a sandbox with a different ID than the one it was checkpointed from.
+	// Optional, no signal is sent by default. Unlike the other checkpoint
+	// annotations, it is set by users, so it has a public key.
+	annotationCloneSignal = "dev.gvisor.checkpoint.clone-signal"
+
 	networkKey = "network"
 )
 
@@ -131,6 +142,72 @@ func GetAnnotationCheckpointDirect(spec *specs.Spec) bool {
 	return specutils.AnnotationToBool(spec, annotationCheckpointDirect)
 }
 
+// getAnnotationCloneSignal returns the signal specified by the clone-signal
+// annotation, or 0 if there is none.
+func getAnnotationCloneSignal(spec *specs.Spec) (linux.Signal, error) {
+	v, ok := spec.Annotations[annotationCloneSignal]
+	if !ok {
+		return 0, nil
+	}
+	var sig linux.Signal
+	if n, err := strconv.Atoi(v); err == nil {
+		sig = linux.Signal(n)
+	} else {
+		sig = linux.Signal(unix.SignalNum("SIG" + strings.TrimPrefix(strings.ToUpper(v), "SIG")))
+	}
+	if !sig.IsValid() {
+		return 0, fmt.Errorf("invalid %q annotation %q", annotationCloneSignal, v)
+	}
+	return sig, nil
+}
+
+// restoreTimerPolicy returns the kernel's policy for application timers that
+// corresponds to p.
+func restoreTimerPolicy(p config.RestoreTimerPolicy) kernel.RestoreTimerPolicy {
+	switch p {
+	case config.RestoreTimerRearm:
+		return kernel.RestoreTimerRearm
+	case config.RestoreTimerCancel:
+		return kernel.RestoreTimerCancel
+	default:
+		return kernel.RestoreTimerFire
+	}
+}
+
+// keepaliveRestorePolicy returns netstack's policy for TCP keepalives that
+// corresponds to p.
+func keepaliveRestorePolicy(p config.RestoreTimerPolicy) stack.KeepaliveRestorePolicy {
+	switch p {
+	case config.RestoreTimerRearm:
+		return stack.KeepaliveRestoreRearm
+	case config.RestoreTimerCancel:
+		return stack.KeepaliveRestoreReset
+	default:
+		return stack.KeepaliveRestoreProbe
+	}
+}
+
+// restoreAddressMap returns the address map applied to sockets of s that are
+// bound to addresses that are gone after restore, according to the
+// restore-address-map flag value v.
+func restoreAddressMap(s *stack.Stack, v string) (map[tcpip.Address]tcpip.Address, error) {
+	switch v {
+	case "none":
+		return nil, nil
+	case "", "auto":
+		return s.AutoRestoreAddressMap(), nil
+	}
+	pairs, err := config.ParseRestoreAddressMap(v)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[tcpip.Address]tcpip.Address, len(pairs))
+	for from, to := range pairs {
+		m[tcpip.AddrFromSlice(from.Unmap().AsSlice())] = tcpip.AddrFromSlice(to.Unmap().AsSlice())
+	}
+	return m, nil
+}
+
 // SaveAsync starts a goroutine to save the kernel. Implements kernel.Saver.
 func (l *Loader) SaveAsync() (err error) {
 	l.mu.Lock()
@@ -269,6 +346,10 @@ type restorer struct {
 	// restorer.restore() returns.
 	background bool
 
+	// If onDemand is true, pages are only read from pagesFile when accessed,
+	// so loading may never complete. onDemand implies background.
+	onDemand bool
+
 	// mainMF is the main MemoryFile of the sandbox.
 	// It is created as soon as possible, and may be restored to as soon as
 	// the first container is restored, which is earlier than when the sandbox's
@@ -298,7 +379,7 @@ type restorer struct {
 }
 
 // restoreSubcontainer restores a subcontainer.
-func (r *restorer) restoreSubcontainer(spec *specs.Spec, conf *config.Config, l *Loader, cid string, stdioFDs, goferFDs, goferFilestoreFDs []*fd.FD, devGoferFD *fd.FD, goferMountConfs []specutils.GoferMountConf) error {
+func (r *restorer) restoreSubcontainer(spec *specs.Spec, conf *config.Config, l *Loader, cid string, stdioFDs, goferFDs, goferFilestoreFDs []*fd.FD, devGoferFD *fd.FD, goferMountConfs []specutils.GoferMountConf, goferAuthKey goferauth.Key) error {
 	containerName := l.registerContainer(spec, cid)
 	info := &containerInfo{
 		cid:               cid,
@@ -310,6 +391,7 @@ func (r *restorer) restoreSubcontainer(spec *specs.Spec, conf *config.Config, l
 		devGoferFD:        devGoferFD,
 		goferFilestoreFDs: goferFilestoreFDs,
 		goferMountConfs:   goferMountConfs,
+		goferAuthKey:      goferAuthKey,
 	}
 	return r.restoreContainerInfo(l, info)
 }
@@ -369,6 +451,16 @@ func (r *restorer) restore(l *Loader) error {
 	if restoreHost := l.root.conf.Network == config.NetworkHost; savedHost != restoreHost {
 		return fmt.Errorf("checkpoint created with %s networking cannot be restored with %s networking", savedNetwork, l.root.conf.Network)
 	}
+	cloneSignals := make(map[string]linux.Signal)
+	for _, info := range r.containers {
+		sig, err := getAnnotationCloneSignal(info.spec)
+		if err != nil {
+			return err
+		}
+		if sig != 0 {
+			cloneSignals[info.cid] = sig
+		}
+	}
 	r.timer.Reached("specs validated")
 
 	p, err := createPlatform(l.root.conf, l.root.applicationCores, r.deviceFile, l.sandboxID)
@@ -436,6 +528,9 @@ func (r *restorer) restore(l *Loader) error {
 	fdmap := make(map[checkpoint.ResourceID]int)
 	mfmap := make(map[checkpoint.ResourceID]*pgalloc.MemoryFile)
 	for _, cont := range r.containers {
+		if err := authenticateGofers(cont); err != nil {
+			return err
+		}
 		// TODO(b/298078576): Need to process hints here probably
 		mntr := l.newContainerMounter(cont)
 		if err = mntr.configureRestore(fdmap, mfmap); err != nil {
@@ -475,6 +570,10 @@ func (r *restorer) restore(l *Loader) error {
 	if err != nil {
 		return err
 	}
+	ctx, err = state.WithSavedSchemaVersion(ctx, r.metadata)
+	if err != nil {
+		return err
+	}
 
 	// Load the state.
 	r.timer.Reached("loading kernel")
@@ -492,6 +591,7 @@ func (r *restorer) restore(l *Loader) error {
 	if oldNvidiaDriverVersion.Major() > 0 && !l.k.NvidiaDriverVersion.Equals(oldNvidiaDriverVersion) {
 		return fmt.Errorf("nvidia driver version changed during restore: was %v, now %v", oldNvidiaDriverVersion, l.k.NvidiaDriverVersion)
 	}
+	l.k.ApplyRestoreTimerPolicy(restoreTimerPolicy(l.root.conf.RestoreTimers))
 
 	if r.asyncMFLoader != nil {
 		if r.background {
@@ -513,6 +613,7 @@ func (r *restorer) restore(l *Loader) error {
 		return fmt.Errorf("setting watchdog action: %w", err)
 	}
 	dogOpts.StartupTimeout = 3 * time2.Minute // Give extra time for all containers to restore.
+	dogOpts.OnReport = l.watchdogReport
 	dog := watchdog.New(l.k, dogOpts)
 
 	// Change the loader fields to reflect the changes made when restoring.
@@ -524,6 +625,10 @@ func (r *restorer) restore(l *Loader) error {
 	// Update all tasks in the system with:
 	// 1. their respective new container IDs.
 	// 2. the new hostname and domainname.
+	//
+	// If the root container's ID changed, the sandbox is a clone of the one
+	// that was checkpointed.
+	clone := false
 	visitedUTS := make(map[*kernel.UTSNamespace]struct{})
 	for _, task := range l.k.TaskSet().Root.Tasks() {
 		oldCid := task.ContainerID()
@@ -533,6 +638,9 @@ func (r *restorer) restore(l *Loader) error {
 			return fmt.Errorf("unable to remap task with CID %q (name: %q). Available names: %v", task.ContainerID(), name, l.containerIDs)
 		}
 		task.RestoreContainerID(newCid)
+		if newCid == l.root.cid && oldCid != newCid {
+			clone = true
+		}
 
 		if utsns := task.UTSNamespace(); utsns != nil {
 			if _, ok := visitedUTS[utsns]; !ok {
@@ -553,6 +661,8 @@ func (r *restorer) restore(l *Loader) error {
 				return fmt.Errorf("unable to find container root process with CID %q, processes: %v", cid, l.processes)
 			}
 			proc.tg = tg
+			l.events.watchExit(cid, tg, cid == l.sandboxID)
+			l.events.lifecycle(SandboxEventContainerRestore, cid)
 		}
 	}
 
@@ -568,8 +678,40 @@ func (r *restorer) restore(l *Loader) error {
 		}
 	}
 
+	if clone {
+		log.Infof("Restored as a clone, regenerating identity")
+		bootID := l.k.RegenerateIdentity()
+		log.Infof("New boot ID: %s", bootID)
+		for _, tg := range l.k.RootPIDNamespace().ThreadGroups() {
+			leader := tg.Leader()
+			sig, ok := cloneSignals[leader.ContainerID()]
+			if !ok || leader.Origin == kernel.OriginExec {
+				continue
+			}
+			log.Infof("Sending signal %v to PID %d of container %q after restoring as a clone", sig, tg.ID(), leader.ContainerID())
+			if err := l.k.SendExternalSignalThreadGroup(tg, &linux.SignalInfo{Signo: int32(sig)}); err != nil {
+				log.Warningf("Failed to send clone signal to PID %d: %v", tg.ID(), err)
+			}
+		}
+	}
+
 	l.k.RestoreContainerMapping(l.containerIDs)
+	// Sentry CPU bandwidth limits are not saved.
+	for _, info := range r.containers {
+		if err := l.setCPUBandwidthFromSpec(info.spec, info.conf, info.cid); err != nil {
+			log.Warningf("Failed to set CPU bandwidth of container %q: %v", info.cid, err)
+		}
+	}
 	l.k.SetSaver(l)
+	if l.crashBundle != nil {
+		l.k.SetTaskPanicHook(l.taskPanic)
+	}
+	if l.root.conf.GuestAPI {
+		l.k.SetGuestAPIHandler(l)
+	}
+	if l.root.conf.PreciseCPUClocks {
+		l.k.SetPreciseCPUClocks()
+	}
 	l.createRemappedNvproxyDeviceFiles(ctx)
 
 	// Refresh the control server with the newly created kernel.
@@ -617,7 +759,9 @@ func (r *restorer) postRestore(k *kernel.Kernel, timeline *timing.Timeline, time
 	k.IncCheckpointGenOnRestore()
 
 	// Wait for page loading to complete if happening in the background.
-	if r.asyncMFLoader != nil {
+	// Pages loaded on demand may never complete loading, and failures are
+	// reported to the tasks that access them instead.
+	if r.asyncMFLoader != nil && !r.onDemand {
 		if err := r.asyncMFLoader.Wait(); err != nil {
 			r.cm.onRestoreFailed(fmt.Errorf("async MemoryFile loading failed: %w", err))
 			log.Warningf("Killing the sandbox after MemoryFile page loading failed: %v", err)
@@ -634,6 +778,24 @@ func (r *restorer) postRestore(k *kernel.Kernel, timeline *timing.Timeline, time
 		log.Warningf("Failed to calculate walltime savings: %v", err)
 	}
 
+	// Compaction and page merging must not run while pages are still being
+	// loaded.
+	if r.onDemand {
+		// Pages loaded on demand finish loading only once all of them have
+		// been accessed, e.g. by a later checkpoint.
+		go func() { // S/R-SAFE: does not impact state directly.
+			if err := r.asyncMFLoader.Wait(); err != nil {
+				log.Warningf("Not starting memory compaction or page merging after MemoryFile page loading failed: %v", err)
+				return
+			}
+			r.cm.l.memoryCompactor.start()
+			r.cm.l.pageMerger.start()
+		}()
+	} else {
+		r.cm.l.memoryCompactor.start()
+		r.cm.l.pageMerger.start()
+	}
+
 	r.cm.onRestoreDone(s)
 	timeline.Reached("kernel notified")
 	log.Infof("Restore successful")
@@ -693,6 +855,12 @@ func (l *Loader) saveWithOpts(saveOpts *state.SaveOpts, execOpts *control.SaveRe
 		l.k.OnCheckpointAttempt(err)
 	}()
 
+	if l.hasStdioRelays() {
+		// Relays are not restored, leaving containers writing to pipes that
+		// nobody reads.
+		return fmt.Errorf("checkpoint is not supported with --stdio-relay")
+	}
+
 	if saveOpts.Metadata == nil {
 		saveOpts.Metadata = make(map[string]string)
 	}
@@ -701,6 +869,9 @@ func (l *Loader) saveWithOpts(saveOpts *state.SaveOpts, execOpts *control.SaveRe
 	// Save runsc version.
 	saveOpts.Metadata[VersionKey] = version.Version()
 
+	// Save the properties that determine where the checkpoint can be restored.
+	l.addCompatibilityMetadata(saveOpts.Metadata)
+
 	saveOpts.Metadata[networkKey] = l.root.conf.Network.String()
 
 	// Save container specs.
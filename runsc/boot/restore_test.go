@@ -0,0 +1,59 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/pkg/abi/linux"
+)
+
+func TestGetAnnotationCloneSignal(t *testing.T) {
+	for _, tc := range []struct {
+		value   string
+		want    linux.Signal
+		wantErr bool
+	}{
+		{value: "", wantErr: true},
+		{value: "SIGUSR2", want: linux.SIGUSR2},
+		{value: "usr1", want: linux.SIGUSR1},
+		{value: "Hup", want: linux.SIGHUP},
+		{value: "10", want: linux.Signal(10)},
+		{value: "0", wantErr: true},
+		{value: "65", wantErr: true},
+		{value: "SIGFOO", wantErr: true},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			spec := &specs.Spec{
+				Annotations: map[string]string{annotationCloneSignal: tc.value},
+			}
+			got, err := getAnnotationCloneSignal(spec)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("getAnnotationCloneSignal(%q) = %v, want error", tc.value, got)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("getAnnotationCloneSignal(%q) = %v, %v, want %v, nil", tc.value, got, err, tc.want)
+			}
+		})
+	}
+
+	if got, err := getAnnotationCloneSignal(&specs.Spec{}); err != nil || got != 0 {
+		t.Errorf("getAnnotationCloneSignal without annotation = %v, %v, want 0, nil", got, err)
+	}
+}
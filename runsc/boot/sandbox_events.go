@@ -0,0 +1,305 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
+	"gvisor.dev/gvisor/pkg/sentry/watchdog"
+	"gvisor.dev/gvisor/pkg/urpc"
+)
+
+// SandboxEventType is the type of a SandboxEvent.
+type SandboxEventType string
+
+const (
+	// SandboxEventInitExit is sent when the init process of the root
+	// container exits.
+	SandboxEventInitExit SandboxEventType = "init-exit"
+
+	// SandboxEventContainerExit is sent when the init process of a
+	// subcontainer exits.
+	SandboxEventContainerExit SandboxEventType = "container-exit"
+
+	// SandboxEventContainerStart is sent when the init process of a
+	// container is started.
+	SandboxEventContainerStart SandboxEventType = "container-start"
+
+	// SandboxEventContainerRestore is sent when a container is restored from
+	// a checkpoint.
+	SandboxEventContainerRestore SandboxEventType = "container-restore"
+
+	// SandboxEventPause is sent when a container, or the whole sandbox if
+	// ContainerID is empty, is paused.
+	SandboxEventPause SandboxEventType = "pause"
+
+	// SandboxEventResume is sent when a container, or the whole sandbox if
+	// ContainerID is empty, is resumed.
+	SandboxEventResume SandboxEventType = "resume"
+
+	// SandboxEventCheckpoint is sent when the sandbox has been checkpointed.
+	SandboxEventCheckpoint SandboxEventType = "checkpoint"
+
+	// SandboxEventOOMKill is sent when the host OOM killer kills processes in
+	// the sandbox cgroup. The sentry is usually the process killed, so this
+	// event is generated outside the sandbox by "runsc events --stream".
+	SandboxEventOOMKill SandboxEventType = "oom-kill"
+
+	// SandboxEventWatchdogWarning is sent when the watchdog detects stuck
+	// tasks and only logs a warning about it.
+	SandboxEventWatchdogWarning SandboxEventType = "watchdog-warning"
+
+	// SandboxEventPanicImminent is sent right before the sentry panics
+	// because of the watchdog.
+	SandboxEventPanicImminent SandboxEventType = "panic-imminent"
+
+	// SandboxEventCrashBundle is sent when a crash bundle was written because
+	// the sentry is about to crash. See --crash-bundle-dir.
+	SandboxEventCrashBundle SandboxEventType = "crash-bundle"
+
+	// SandboxEventOverlayUpperWarning is sent when the rootfs overlay upper
+	// layer of a container grows past --overlay2-upper-warn-size. It is sent
+	// again only after usage drops below the threshold and grows past it
+	// again.
+	SandboxEventOverlayUpperWarning SandboxEventType = "overlay-upper-size-warning"
+
+	// SandboxEventGuestReady is sent when an agent in a container signals
+	// that the container is ready through the guest API device. See
+	// --guest-api.
+	SandboxEventGuestReady SandboxEventType = "guest-ready"
+)
+
+// SandboxEvent is an event delivered to subscribers of sandbox events. Events
+// are encoded as one JSON object per line.
+type SandboxEvent struct {
+	// Type is the type of event.
+	Type SandboxEventType `json:"type"`
+
+	// Time is when the event happened.
+	Time time.Time `json:"time"`
+
+	// ContainerID is the container that the event refers to, if any.
+	ContainerID string `json:"containerID,omitempty"`
+
+	// ExitStatus is the wait status of the container's init process. It is
+	// set for exit events.
+	ExitStatus *uint32 `json:"exitStatus,omitempty"`
+
+	// Message is a human-readable description of the event, if any.
+	Message string `json:"message,omitempty"`
+}
+
+// SubscribeEventsArgs are arguments to the SubscribeEvents method.
+type SubscribeEventsArgs struct {
+	// FilePayload contains the socket that events are written to.
+	urpc.FilePayload
+}
+
+const (
+	// sandboxEventHistory is the number of past events sent to new
+	// subscribers, so that events that happened before subscribing, e.g. a
+	// container that exited right away, are not lost.
+	sandboxEventHistory = 64
+
+	// sandboxEventQueueLen is the number of events queued for a subscriber
+	// that is not keeping up. Events are dropped once the queue is full.
+	sandboxEventQueueLen = 128
+
+	// panicEventTimeout is how long to wait for the panic-imminent event to
+	// be delivered before letting the sentry panic.
+	panicEventTimeout = time.Second
+)
+
+// queuedEvent is an event waiting to be written to a subscriber.
+type queuedEvent struct {
+	ev SandboxEvent
+
+	// written, if not nil, is closed once ev has been written.
+	written chan struct{}
+}
+
+// eventSubscriber is a connection that sandbox events are written to.
+type eventSubscriber struct {
+	f     *os.File
+	queue chan queuedEvent
+}
+
+// sandboxEvents distributes sandbox events to subscribers.
+type sandboxEvents struct {
+	mu sync.Mutex
+
+	// history holds the last sandboxEventHistory events.
+	history []SandboxEvent
+
+	// subscribers are the active subscribers.
+	subscribers map[*eventSubscriber]struct{}
+}
+
+func newSandboxEvents() *sandboxEvents {
+	return &sandboxEvents{
+		subscribers: make(map[*eventSubscriber]struct{}),
+	}
+}
+
+// subscribe starts writing events, starting with past events, to f until
+// writing fails or the subscriber disconnects. It takes ownership of f.
+func (e *sandboxEvents) subscribe(f *os.File) {
+	s := &eventSubscriber{
+		f:     f,
+		queue: make(chan queuedEvent, sandboxEventQueueLen+sandboxEventHistory),
+	}
+
+	e.mu.Lock()
+	for _, ev := range e.history {
+		s.queue <- queuedEvent{ev: ev}
+	}
+	e.subscribers[s] = struct{}{}
+	e.mu.Unlock()
+
+	go func() { // S/R-SAFE: does not impact state directly.
+		defer func() {
+			e.unsubscribe(s)
+			s.f.Close()
+		}()
+		enc := json.NewEncoder(s.f)
+		for q := range s.queue {
+			err := enc.Encode(&q.ev)
+			if q.written != nil {
+				close(q.written)
+			}
+			if err != nil {
+				log.Infof("Sandbox event subscriber went away: %v", err)
+				return
+			}
+		}
+	}()
+
+	go func() { // S/R-SAFE: does not impact state directly.
+		// Subscribers don't send anything, so reads only return once the
+		// subscriber disconnects, or once f is closed above.
+		var buf [1]byte
+		for {
+			if _, err := s.f.Read(buf[:]); err != nil {
+				break
+			}
+		}
+		e.unsubscribe(s)
+	}()
+}
+
+// unsubscribe stops sending events to s. The goroutine writing to s exits
+// once it has written the events already queued.
+func (e *sandboxEvents) unsubscribe(s *eventSubscriber) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.subscribers[s]; ok {
+		delete(e.subscribers, s)
+		close(s.queue)
+	}
+}
+
+// publish sends ev to all subscribers. If wait is greater than zero, publish
+// waits up to that long for ev to be written.
+func (e *sandboxEvents) publish(ev SandboxEvent, wait time.Duration) {
+	ev.Time = time.Now()
+	log.Debugf("Sandbox event: %+v", ev)
+
+	var pending []chan struct{}
+	e.mu.Lock()
+	e.history = append(e.history, ev)
+	if len(e.history) > sandboxEventHistory {
+		e.history = e.history[1:]
+	}
+	for s := range e.subscribers {
+		q := queuedEvent{ev: ev}
+		if wait > 0 {
+			q.written = make(chan struct{})
+		}
+		select {
+		case s.queue <- q:
+			if q.written != nil {
+				pending = append(pending, q.written)
+			}
+		default:
+			log.Warningf("Sandbox event subscriber is not keeping up, dropping event: %+v", ev)
+		}
+	}
+	e.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+	timeout := time.After(wait)
+	for _, written := range pending {
+		select {
+		case <-written:
+		case <-timeout:
+			return
+		}
+	}
+}
+
+// watchExit publishes an exit event once the init process of container cid,
+// tg, exits.
+func (e *sandboxEvents) watchExit(cid string, tg *kernel.ThreadGroup, isRoot bool) {
+	go func() { // S/R-SAFE: does not impact state directly.
+		tg.WaitExited()
+		ws := uint32(tg.ExitStatus())
+		typ := SandboxEventContainerExit
+		if isRoot {
+			typ = SandboxEventInitExit
+		}
+		e.publish(SandboxEvent{Type: typ, ContainerID: cid, ExitStatus: &ws}, 0)
+	}()
+}
+
+// lifecycle publishes a lifecycle event of type typ for container cid, or for
+// the whole sandbox if cid is empty.
+func (e *sandboxEvents) lifecycle(typ SandboxEventType, cid string) {
+	e.publish(SandboxEvent{Type: typ, ContainerID: cid}, 0)
+}
+
+// watchdogReport implements watchdog.Opts.OnReport.
+func (e *sandboxEvents) watchdogReport(action watchdog.Action, msg string) {
+	switch action {
+	case watchdog.Panic:
+		e.publish(SandboxEvent{Type: SandboxEventPanicImminent, Message: msg}, panicEventTimeout)
+	default:
+		e.publish(SandboxEvent{Type: SandboxEventWatchdogWarning, Message: msg}, 0)
+	}
+}
+
+// SubscribeEvents writes sandbox events to the socket passed in args, one JSON
+// encoded SandboxEvent per line, until the socket is closed. Events that
+// happened shortly before subscribing are sent first.
+func (cm *containerManager) SubscribeEvents(args *SubscribeEventsArgs, _ *struct{}) error {
+	log.Debugf("containerManager.SubscribeEvents")
+	if len(args.Files) != 1 {
+		return fmt.Errorf("SubscribeEvents requires exactly one file, got %d", len(args.Files))
+	}
+	// Files in the payload are closed once the call returns, so take a copy.
+	sock, err := args.ReleaseFD(0)
+	if err != nil {
+		return err
+	}
+	cm.l.events.subscribe(os.NewFile(uintptr(sock.Release()), "sandbox-events"))
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/sentry/watchdog"
+)
+
+// newEventSocketPair returns the subscriber's and the sandbox's end of a
+// connection for sandbox events.
+func newEventSocketPair(t *testing.T) (*os.File, *os.File) {
+	t.Helper()
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		t.Fatalf("unix.Socketpair(): %v", err)
+	}
+	return os.NewFile(uintptr(fds[0]), "subscriber"), os.NewFile(uintptr(fds[1]), "sandbox")
+}
+
+func readSandboxEvent(t *testing.T, sc *bufio.Scanner) SandboxEvent {
+	t.Helper()
+	if !sc.Scan() {
+		t.Fatalf("reading event: %v", sc.Err())
+	}
+	var ev SandboxEvent
+	if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+		t.Fatalf("json.Unmarshal(%q): %v", sc.Bytes(), err)
+	}
+	return ev
+}
+
+func TestSandboxEvents(t *testing.T) {
+	e := newSandboxEvents()
+
+	// Published before subscribing, must be replayed.
+	ws := uint32(0)
+	e.publish(SandboxEvent{Type: SandboxEventContainerExit, ContainerID: "sub", ExitStatus: &ws}, 0)
+
+	r, w := newEventSocketPair(t)
+	defer r.Close()
+	e.subscribe(w)
+	sc := bufio.NewScanner(r)
+
+	ev := readSandboxEvent(t, sc)
+	if ev.Type != SandboxEventContainerExit || ev.ContainerID != "sub" || ev.ExitStatus == nil || *ev.ExitStatus != 0 {
+		t.Errorf("got event %+v, want container-exit for sub with status 0", ev)
+	}
+
+	e.watchdogReport(watchdog.LogWarning, "stuck")
+	ev = readSandboxEvent(t, sc)
+	if ev.Type != SandboxEventWatchdogWarning || ev.Message != "stuck" {
+		t.Errorf("got event %+v, want watchdog-warning with message", ev)
+	}
+	if ev.Time.IsZero() {
+		t.Errorf("event time not set: %+v", ev)
+	}
+
+	e.lifecycle(SandboxEventPause, "sub")
+	ev = readSandboxEvent(t, sc)
+	if ev.Type != SandboxEventPause || ev.ContainerID != "sub" {
+		t.Errorf("got event %+v, want pause for sub", ev)
+	}
+}
+
+func TestSandboxEventsHistoryLimit(t *testing.T) {
+	e := newSandboxEvents()
+	for i := 0; i < 2*sandboxEventHistory; i++ {
+		e.publish(SandboxEvent{Type: SandboxEventWatchdogWarning}, 0)
+	}
+	if got := len(e.history); got != sandboxEventHistory {
+		t.Errorf("got %d events in history, want %d", got, sandboxEventHistory)
+	}
+}
+
+func TestSandboxEventsUnsubscribe(t *testing.T) {
+	e := newSandboxEvents()
+	r, w := newEventSocketPair(t)
+	e.subscribe(w)
+	r.Close()
+
+	// The subscriber is removed once it disconnects, without publishing any
+	// events.
+	for deadline := time.Now().Add(10 * time.Second); ; {
+		e.mu.Lock()
+		n := len(e.subscribers)
+		e.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("subscriber not removed after disconnecting")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
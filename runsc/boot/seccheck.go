@@ -25,6 +25,7 @@ import (
 	// Register supported of sinks.
 	_ "gvisor.dev/gvisor/pkg/sentry/seccheck/sinks/null"
 	_ "gvisor.dev/gvisor/pkg/sentry/seccheck/sinks/remote"
+	_ "gvisor.dev/gvisor/pkg/sentry/seccheck/sinks/spool"
 )
 
 // InitConfig represents the configuration to apply during pod creation. For
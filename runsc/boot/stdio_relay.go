@@ -0,0 +1,292 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/fsimpl/pipefs"
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+	"gvisor.dev/gvisor/pkg/sync"
+	"gvisor.dev/gvisor/pkg/urpc"
+	"gvisor.dev/gvisor/pkg/usermem"
+	"gvisor.dev/gvisor/pkg/waiter"
+)
+
+// relayedStreams are the stdio streams that are relayed with --stdio-relay.
+var relayedStreams = []int{1, 2}
+
+// AttachStdioArgs are arguments to the AttachStdio method.
+type AttachStdioArgs struct {
+	// ContainerID is the container whose output is attached.
+	ContainerID string
+
+	// Streams are the output streams being attached, 1 for stdout and 2 for
+	// stderr. The payload contains one file per stream, in the same order.
+	Streams []int
+
+	// Rotation, if not nil, enables log rotation for the attached files.
+	Rotation *StdioRotation
+
+	// FilePayload contains the files that output is written to, each followed
+	// by its directory if Rotation is set.
+	urpc.FilePayload
+}
+
+// StdioRotation configures rotation of stdio log files. When a log file
+// reaches MaxBytes, "name" is renamed to "name.1", "name.1" to "name.2" and
+// so on, keeping MaxFiles rotated files, and a new "name" is created.
+type StdioRotation struct {
+	// Names are the names of the attached files in their directory, one per
+	// stream.
+	Names []string
+
+	// MaxBytes is the size at which log files are rotated.
+	MaxBytes int64
+
+	// MaxFiles is the number of rotated files kept. If 0, log files are
+	// truncated instead.
+	MaxFiles int
+}
+
+// logRotation is the rotation state of a relayed stream.
+type logRotation struct {
+	dir      *os.File
+	name     string
+	maxBytes int64
+	maxFiles int
+
+	// size is the current size of the log file.
+	size int64
+}
+
+// stdioRelay copies the output that a container writes to one of its stdio
+// streams to a host file, which can be replaced while the container runs.
+// This allows output to be re-attached when its original consumer goes away,
+// instead of the container blocking or failing on writes.
+type stdioRelay struct {
+	ctx  context.Context
+	desc string
+
+	// src is the read end of the pipe that the container writes to.
+	src *vfs.FileDescription
+
+	// stream is the app FD that the container writes to.
+	stream int
+
+	// out, if not nil, receives all output for StreamOutput readers.
+	out *outputStream
+
+	mu sync.Mutex
+
+	// dst is where output is written. It is nil while detached, in which
+	// case output is discarded.
+	//
+	// +checklocks:mu
+	dst *os.File
+
+	// rot is the log rotation of dst, if any.
+	//
+	// +checklocks:mu
+	rot *logRotation
+
+	// dropped is the number of bytes discarded while detached.
+	//
+	// +checklocks:mu
+	dropped uint64
+
+	// done is set once all writers are gone and nothing is relayed anymore.
+	//
+	// +checklocks:mu
+	done bool
+}
+
+// newStdioRelay creates a pipe, installs its write end as appFD in fdTable,
+// and starts relaying its output to dst and out. It takes ownership of dst.
+func newStdioRelay(ctx context.Context, k *kernel.Kernel, fdTable *kernel.FDTable, appFD int32, dst *os.File, out *outputStream, desc string) (*stdioRelay, error) {
+	rfd, wfd, err := pipefs.NewConnectedPipeFDs(ctx, k.PipeMount(), 0 /* flags */)
+	if err != nil {
+		dst.Close()
+		return nil, err
+	}
+	defer wfd.DecRef(ctx)
+	df, err := fdTable.NewFDAt(ctx, appFD, wfd, kernel.FDFlags{})
+	if err != nil {
+		rfd.DecRef(ctx)
+		dst.Close()
+		return nil, err
+	}
+	if df != nil {
+		df.DecRef(ctx)
+	}
+	r := &stdioRelay{
+		ctx:    k.SupervisorContext(),
+		desc:   desc,
+		src:    rfd,
+		stream: int(appFD),
+		out:    out,
+		dst:    dst,
+	}
+	out.acquire()
+	go r.run() // S/R-SAFE: checkpoint is refused while output is relayed.
+	return r, nil
+}
+
+// run relays output until all writers are gone.
+func (r *stdioRelay) run() {
+	defer r.src.DecRef(r.ctx)
+	defer r.out.release()
+	defer func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.detachLocked()
+		r.done = true
+	}()
+
+	var buf [32 * 1024]byte
+	e, ch := waiter.NewChannelEntry(waiter.ReadableEvents | waiter.EventErr | waiter.EventHUp)
+	if err := r.src.EventRegister(&e); err != nil {
+		log.Warningf("Error registering for events from %s: %v", r.desc, err)
+		return
+	}
+	defer r.src.EventUnregister(&e)
+	for {
+		n, err := r.src.Read(r.ctx, usermem.BytesIOSequence(buf[:]), vfs.ReadOptions{})
+		if n != 0 {
+			r.write(buf[:n])
+		}
+		switch {
+		case err == nil:
+		case linuxerr.Equals(linuxerr.ErrWouldBlock, err):
+			<-ch
+		case err == io.EOF:
+			log.Debugf("Finished relaying %s", r.desc)
+			return
+		default:
+			log.Warningf("Error reading %s: %v", r.desc, err)
+			return
+		}
+	}
+}
+
+// write writes b to the current destination, discarding it if there is none.
+// The destination is detached if writing fails.
+func (r *stdioRelay) write(b []byte) {
+	if r.out != nil {
+		r.out.publish(r.stream, b)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.dst == nil {
+		r.dropped += uint64(len(b))
+		return
+	}
+	n, err := r.dst.Write(b)
+	if err != nil {
+		log.Warningf("Error writing %s, detaching it until it is re-attached: %v", r.desc, err)
+		r.dropped += uint64(len(b) - n)
+		r.detachLocked()
+		return
+	}
+	if r.rot == nil {
+		return
+	}
+	r.rot.size += int64(n)
+	if r.rot.size >= r.rot.maxBytes {
+		if err := r.rotateLocked(); err != nil {
+			log.Warningf("Error rotating %s log file, detaching it: %v", r.desc, err)
+			r.detachLocked()
+		}
+	}
+}
+
+// rotateLocked rotates the log file of the current destination.
+//
+// +checklocks:r.mu
+func (r *stdioRelay) rotateLocked() error {
+	rot := r.rot
+	dirFD := int(rot.dir.Fd())
+	if rot.maxFiles > 0 {
+		for i := rot.maxFiles - 1; i >= 1; i-- {
+			from, to := fmt.Sprintf("%s.%d", rot.name, i), fmt.Sprintf("%s.%d", rot.name, i+1)
+			if err := unix.Renameat2(dirFD, from, dirFD, to, 0); err != nil && err != unix.ENOENT {
+				return fmt.Errorf("renaming %q to %q: %w", from, to, err)
+			}
+		}
+		if err := unix.Renameat2(dirFD, rot.name, dirFD, rot.name+".1", 0); err != nil {
+			return fmt.Errorf("renaming %q: %w", rot.name, err)
+		}
+	}
+	fd, err := unix.Openat(dirFD, rot.name, unix.O_WRONLY|unix.O_CREAT|unix.O_TRUNC|unix.O_APPEND|unix.O_CLOEXEC|unix.O_NOFOLLOW, 0o644)
+	if err != nil {
+		return fmt.Errorf("creating %q: %w", rot.name, err)
+	}
+	r.dst.Close()
+	r.dst = os.NewFile(uintptr(fd), rot.name)
+	rot.size = 0
+	return nil
+}
+
+// attach makes dst the destination of the output, replacing the current one.
+// It takes ownership of dst and rot.dir, also on failure.
+func (r *stdioRelay) attach(dst *os.File, rot *logRotation) error {
+	if rot != nil {
+		var st unix.Stat_t
+		if err := unix.Fstat(int(dst.Fd()), &st); err != nil {
+			dst.Close()
+			rot.dir.Close()
+			return err
+		}
+		rot.size = st.Size
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.done {
+		dst.Close()
+		if rot != nil {
+			rot.dir.Close()
+		}
+		return fmt.Errorf("%s is closed", r.desc)
+	}
+	r.detachLocked()
+	r.dst = dst
+	r.rot = rot
+	if r.dropped != 0 {
+		log.Infof("Re-attached %s, %d bytes were discarded while detached", r.desc, r.dropped)
+		r.dropped = 0
+	}
+	return nil
+}
+
+// +checklocks:r.mu
+func (r *stdioRelay) detachLocked() {
+	if r.dst != nil {
+		r.dst.Close()
+		r.dst = nil
+	}
+	if r.rot != nil {
+		r.rot.dir.Close()
+		r.rot = nil
+	}
+}
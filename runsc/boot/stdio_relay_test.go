@@ -0,0 +1,110 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readLog(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%q): %v", path, err)
+	}
+	return string(b)
+}
+
+func TestStdioRelayRotation(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "out.log")
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile(%q): %v", path, err)
+	}
+	dir, err := os.Open(tmp)
+	if err != nil {
+		t.Fatalf("Open(%q): %v", tmp, err)
+	}
+	r := &stdioRelay{desc: "test"}
+	if err := r.attach(dst, &logRotation{dir: dir, name: "out.log", maxBytes: 10, maxFiles: 2}); err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+	defer func() {
+		r.mu.Lock()
+		r.detachLocked()
+		r.mu.Unlock()
+	}()
+
+	for i := 0; i < 4; i++ {
+		r.write([]byte(fmt.Sprintf("line %d\n", i)))
+		r.write([]byte(strings.Repeat("x", 4)))
+	}
+	r.write([]byte("tail"))
+
+	// Every pair of writes reaches 10 bytes and rotates. Only two rotated
+	// files are kept.
+	for name, want := range map[string]string{
+		"out.log":   "tail",
+		"out.log.1": "line 3\nxxxx",
+		"out.log.2": "line 2\nxxxx",
+	} {
+		if got := readLog(t, filepath.Join(tmp, name)); got != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmp, "out.log.3")); !os.IsNotExist(err) {
+		t.Errorf("out.log.3 exists, err: %v", err)
+	}
+}
+
+func TestStdioRelayDetached(t *testing.T) {
+	r := &stdioRelay{desc: "test"}
+	r.write([]byte("lost"))
+	if r.dropped != 4 {
+		t.Errorf("dropped: got %d, want 4", r.dropped)
+	}
+
+	path := filepath.Join(t.TempDir(), "out.log")
+	dst, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Create(%q): %v", path, err)
+	}
+	if err := r.attach(dst, nil); err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+	if r.dropped != 0 {
+		t.Errorf("dropped after attach: got %d, want 0", r.dropped)
+	}
+	r.write([]byte("kept"))
+	if got := readLog(t, path); got != "kept" {
+		t.Errorf("got %q, want %q", got, "kept")
+	}
+
+	r.mu.Lock()
+	r.done = true
+	r.mu.Unlock()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open(%q): %v", path, err)
+	}
+	if err := r.attach(f, nil); err == nil {
+		t.Errorf("attach succeeded after the relay finished")
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sync"
+	"gvisor.dev/gvisor/pkg/urpc"
+)
+
+// StreamOutputArgs are arguments to the StreamOutput method.
+type StreamOutputArgs struct {
+	// ContainerID is the container whose output is streamed.
+	ContainerID string
+
+	// ReplayBytes is the amount of past output sent before new output. At
+	// most maxOutputReplayBytes are kept per container.
+	ReplayBytes int
+
+	// Follow, if true, keeps streaming new output until the container's output
+	// is closed. Otherwise, only past output is sent.
+	Follow bool
+
+	// FilePayload contains the file that framed output is written to.
+	urpc.FilePayload
+}
+
+// OutputFrameHeaderSize is the size of the header of frames written by
+// StreamOutput. Like "docker attach" without a terminal, the first byte of the
+// header is the stream (1 for stdout, 2 for stderr), and the last 4 bytes are
+// the big-endian size of the payload that follows.
+const OutputFrameHeaderSize = 8
+
+const (
+	// maxOutputReplayBytes is the amount of past output kept per container
+	// for readers that ask for it.
+	maxOutputReplayBytes = 256 * 1024
+
+	// outputReaderQueueLen is the number of chunks of output queued for a
+	// reader. Readers that are not keeping up are disconnected, since dropping
+	// output would corrupt the stream.
+	outputReaderQueueLen = 64
+)
+
+// writeOutputFrame writes b as a frame of the given stream.
+func writeOutputFrame(w io.Writer, stream int, b []byte) error {
+	frame := make([]byte, OutputFrameHeaderSize+len(b))
+	frame[0] = byte(stream)
+	binary.BigEndian.PutUint32(frame[4:OutputFrameHeaderSize], uint32(len(b)))
+	copy(frame[OutputFrameHeaderSize:], b)
+	_, err := w.Write(frame)
+	return err
+}
+
+// ReadOutputFrame reads a frame written by StreamOutput. It returns io.EOF if
+// r ends before a frame starts.
+func ReadOutputFrame(r io.Reader) (stream int, payload []byte, err error) {
+	var hdr [OutputFrameHeaderSize]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	payload = make([]byte, binary.BigEndian.Uint32(hdr[4:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, nil, err
+	}
+	return int(hdr[0]), payload, nil
+}
+
+// outputChunk is output written by a container to one of its streams.
+type outputChunk struct {
+	stream int
+	data   []byte
+}
+
+// outputReader is a connection that framed output is written to.
+type outputReader struct {
+	f     *os.File
+	queue chan outputChunk
+}
+
+// outputStream multiplexes the relayed output streams of a container and
+// distributes them to readers.
+type outputStream struct {
+	desc string
+
+	mu sync.Mutex
+
+	// history holds the most recent output, up to maxOutputReplayBytes.
+	//
+	// +checklocks:mu
+	history []outputChunk
+
+	// historySize is the number of bytes in history.
+	//
+	// +checklocks:mu
+	historySize int
+
+	// readers are the readers following new output.
+	//
+	// +checklocks:mu
+	readers map[*outputReader]struct{}
+
+	// writers is the number of relays still producing output.
+	//
+	// +checklocks:mu
+	writers int
+
+	// closed is set once all writers are gone.
+	//
+	// +checklocks:mu
+	closed bool
+}
+
+func newOutputStream(desc string) *outputStream {
+	return &outputStream{
+		desc:    desc,
+		readers: make(map[*outputReader]struct{}),
+	}
+}
+
+// acquire registers a writer of the stream.
+func (o *outputStream) acquire() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.writers++
+}
+
+// release unregisters a writer of the stream. Readers are disconnected once
+// the last writer is gone.
+func (o *outputStream) release() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.writers--
+	if o.writers > 0 {
+		return
+	}
+	o.closed = true
+	for r := range o.readers {
+		close(r.queue)
+		delete(o.readers, r)
+	}
+}
+
+// publish records b as output of the given stream and sends it to readers.
+func (o *outputStream) publish(stream int, b []byte) {
+	c := outputChunk{stream: stream, data: bytes.Clone(b)}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.history = append(o.history, c)
+	o.historySize += len(c.data)
+	for len(o.history) > 1 && o.historySize-len(o.history[0].data) >= maxOutputReplayBytes {
+		o.historySize -= len(o.history[0].data)
+		o.history = o.history[1:]
+	}
+	for r := range o.readers {
+		select {
+		case r.queue <- c:
+		default:
+			log.Warningf("Reader of %s is not keeping up, disconnecting it", o.desc)
+			close(r.queue)
+			delete(o.readers, r)
+		}
+	}
+}
+
+// subscribe writes up to replay bytes of past output to f and, if follow is
+// true, new output until the stream is closed or writing fails. It takes
+// ownership of f.
+func (o *outputStream) subscribe(f *os.File, replay int, follow bool) {
+	o.mu.Lock()
+	var past []outputChunk
+	for i := len(o.history) - 1; i >= 0 && replay > 0; i-- {
+		c := o.history[i]
+		if len(c.data) > replay {
+			c.data = c.data[len(c.data)-replay:]
+		}
+		replay -= len(c.data)
+		past = append(past, c)
+	}
+	r := &outputReader{
+		f:     f,
+		queue: make(chan outputChunk, len(past)+outputReaderQueueLen),
+	}
+	for i := len(past) - 1; i >= 0; i-- {
+		r.queue <- past[i]
+	}
+	if follow && !o.closed {
+		o.readers[r] = struct{}{}
+	} else {
+		close(r.queue)
+	}
+	o.mu.Unlock()
+
+	go func() { // S/R-SAFE: checkpoint is refused while output is relayed.
+		defer r.f.Close()
+		for c := range r.queue {
+			if err := writeOutputFrame(r.f, c.stream, c.data); err != nil {
+				log.Infof("Reader of %s went away: %v", o.desc, err)
+				o.mu.Lock()
+				delete(o.readers, r)
+				o.mu.Unlock()
+				return
+			}
+		}
+	}()
+}
+
+// streamOutput streams the relayed output of a container, framed by stream,
+// to the file in args.
+func (l *Loader) streamOutput(args *StreamOutputArgs) error {
+	if len(args.Files) != 1 {
+		return fmt.Errorf("got %d files, want 1", len(args.Files))
+	}
+	if args.ReplayBytes < 0 {
+		return fmt.Errorf("invalid replay size %d", args.ReplayBytes)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.tryThreadGroupFromIDLocked(execID{cid: args.ContainerID}); err != nil {
+		return err
+	}
+	var out *outputStream
+	for _, relay := range l.stdioRelays[args.ContainerID] {
+		out = relay.out
+	}
+	if out == nil {
+		return fmt.Errorf("output of container %q is not relayed, --stdio-relay must be set and the container must not use a terminal", args.ContainerID)
+	}
+
+	// Files are closed when the call returns, so take our own copy.
+	dst, err := args.ReleaseFD(0)
+	if err != nil {
+		return err
+	}
+	out.subscribe(os.NewFile(uintptr(dst.Release()), out.desc), args.ReplayBytes, args.Follow)
+	return nil
+}
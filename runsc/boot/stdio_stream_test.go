@@ -0,0 +1,124 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// readFrames reads frames from r until EOF and returns the concatenated
+// payloads of each stream.
+func readFrames(t *testing.T, r io.Reader) map[int]string {
+	t.Helper()
+	got := make(map[int]string)
+	for {
+		stream, payload, err := ReadOutputFrame(r)
+		if err == io.EOF {
+			return got
+		}
+		if err != nil {
+			t.Fatalf("ReadOutputFrame: %v", err)
+		}
+		got[stream] += string(payload)
+	}
+}
+
+func newReader(t *testing.T) (*os.File, *os.File) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe: %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r, w
+}
+
+func TestOutputStreamFollow(t *testing.T) {
+	o := newOutputStream("test")
+	o.acquire()
+	o.publish(1, []byte("before"))
+
+	// Two readers, one replaying past output.
+	r1, w1 := newReader(t)
+	o.subscribe(w1, 1024, true)
+	r2, w2 := newReader(t)
+	o.subscribe(w2, 0, true)
+
+	o.publish(1, []byte(" out"))
+	o.publish(2, []byte("err"))
+	o.release()
+
+	for _, tc := range []struct {
+		r    io.Reader
+		want map[int]string
+	}{
+		{r1, map[int]string{1: "before out", 2: "err"}},
+		{r2, map[int]string{1: " out", 2: "err"}},
+	} {
+		got := readFrames(t, tc.r)
+		if len(got) != len(tc.want) || got[1] != tc.want[1] || got[2] != tc.want[2] {
+			t.Errorf("got %v, want %v", got, tc.want)
+		}
+	}
+}
+
+func TestOutputStreamReplay(t *testing.T) {
+	o := newOutputStream("test")
+	o.acquire()
+	defer o.release()
+	o.publish(1, []byte("first"))
+	o.publish(2, []byte("second"))
+
+	// Without follow, only the last 8 bytes are written.
+	r, w := newReader(t)
+	o.subscribe(w, 8, false)
+	got := readFrames(t, r)
+	if got[1] != "st" || got[2] != "second" {
+		t.Errorf("got %v, want stdout %q and stderr %q", got, "st", "second")
+	}
+}
+
+func TestOutputStreamHistoryLimit(t *testing.T) {
+	o := newOutputStream("test")
+	o.acquire()
+	defer o.release()
+	chunk := strings.Repeat("x", maxOutputReplayBytes/4)
+	for i := 0; i < 10; i++ {
+		o.publish(1, []byte(chunk))
+	}
+	o.mu.Lock()
+	size := o.historySize
+	o.mu.Unlock()
+	if size != maxOutputReplayBytes {
+		t.Errorf("history size: got %d, want %d", size, maxOutputReplayBytes)
+	}
+}
+
+func TestOutputStreamClosed(t *testing.T) {
+	o := newOutputStream("test")
+	o.acquire()
+	o.publish(1, []byte("done"))
+	o.release()
+
+	// Following a closed stream only replays past output.
+	r, w := newReader(t)
+	o.subscribe(w, 1024, true)
+	if got := readFrames(t, r); got[1] != "done" {
+		t.Errorf("got %q, want %q", got[1], "done")
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gvisor.dev/gvisor/pkg/abi/linux"
+	"gvisor.dev/gvisor/pkg/context"
+	"gvisor.dev/gvisor/pkg/errors/linuxerr"
+	"gvisor.dev/gvisor/pkg/fspath"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/fsimpl/proc"
+	"gvisor.dev/gvisor/pkg/sentry/kernel"
+	"gvisor.dev/gvisor/pkg/sentry/kernel/auth"
+	"gvisor.dev/gvisor/pkg/sentry/vfs"
+	"gvisor.dev/gvisor/pkg/usermem"
+)
+
+// sysctlsAppliedAtSetup are sysctls that are applied when the sandbox is
+// configured rather than through /proc/sys.
+var sysctlsAppliedAtSetup = map[string]struct{}{
+	// Applied when the network stack is created, see getDisableIPv6.
+	"net.ipv6.conf.all.disable_ipv6": {},
+}
+
+// sysctlPath returns the path of key relative to /proc/sys.
+func sysctlPath(key string) (string, error) {
+	names := strings.Split(key, ".")
+	for _, name := range names {
+		if name == "" || strings.Contains(name, "/") {
+			return "", fmt.Errorf("invalid sysctl name %q", key)
+		}
+	}
+	return strings.Join(names, "/"), nil
+}
+
+// applySysctls writes the sysctls in the spec of container cid to /proc/sys
+// and records them in the kernel's SysctlTable. Unlike Linux, many sysctls are
+// read-only in gVisor. Setting them is only accepted if the value doesn't
+// change.
+//
+// Sysctls are written through a procfs instance mounted internally rather than
+// through the container's mount namespace, which may not mount procfs at
+// /proc, or may have something else there.
+func (l *Loader) applySysctls(info *containerInfo) error {
+	if info.spec.Linux == nil || len(info.spec.Linux.Sysctl) == 0 {
+		return nil
+	}
+
+	// The container user may not be privileged enough to write sysctls.
+	rootProcArgs := info.procArgs
+	rootProcArgs.Credentials = auth.NewRootCredentials(info.procArgs.Credentials.UserNamespace)
+	ctx := rootProcArgs.NewContext(l.k)
+	mnt, err := l.k.VFS().MountDisconnected(ctx, rootProcArgs.Credentials, "" /* source */, proc.Name, &vfs.MountOptions{
+		GetFilesystemOptions: vfs.GetFilesystemOptions{InternalMount: true},
+	})
+	if err != nil {
+		return fmt.Errorf("mounting procfs for sysctls: %w", err)
+	}
+	defer mnt.DecRef(ctx)
+	root := vfs.MakeVirtualDentry(mnt, mnt.Root())
+
+	sysctls := info.spec.Linux.Sysctl
+	for _, key := range slices.Sorted(maps.Keys(sysctls)) {
+		value := sysctls[key]
+		if _, ok := sysctlsAppliedAtSetup[key]; !ok {
+			if err := writeSysctl(ctx, l.k.VFS(), rootProcArgs.Credentials, root, key, value); err != nil {
+				return err
+			}
+		}
+		log.Infof("Container %q: sysctl %s = %q", info.cid, key, value)
+		l.k.Sysctls().Set(info.cid, key, value)
+	}
+	return nil
+}
+
+// writeSysctl sets sysctl key to value in the procfs mounted at root.
+func writeSysctl(ctx context.Context, vfsObj *vfs.VirtualFilesystem, creds *auth.Credentials, root vfs.VirtualDentry, key, value string) error {
+	path, err := sysctlPath(key)
+	if err != nil {
+		return err
+	}
+	pop := vfs.PathOperation{
+		Root:  root,
+		Start: root,
+		Path:  fspath.Parse("/sys/" + path),
+	}
+	fd, err := vfsObj.OpenAt(ctx, creds, &pop, &vfs.OpenOptions{Flags: linux.O_RDWR})
+	switch {
+	case linuxerr.Equals(linuxerr.ENOENT, err), linuxerr.Equals(linuxerr.ENOTDIR, err), linuxerr.Equals(linuxerr.EISDIR, err):
+		return fmt.Errorf("sysctl %q is not supported", key)
+	case err != nil:
+		return fmt.Errorf("opening sysctl %q: %w", key, err)
+	}
+	defer fd.DecRef(ctx)
+
+	if _, err := fd.Write(ctx, usermem.BytesIOSequence([]byte(value)), vfs.WriteOptions{}); err == nil {
+		return nil
+	} else if !linuxerr.Equals(linuxerr.EIO, err) {
+		return fmt.Errorf("setting sysctl %q to %q: %w", key, value, err)
+	}
+
+	// The sysctl is read-only. Accept the value if it is already in effect.
+	buf := make([]byte, 4096)
+	n, err := fd.PRead(ctx, usermem.BytesIOSequence(buf), 0, vfs.ReadOptions{})
+	if err != nil {
+		return fmt.Errorf("reading sysctl %q: %w", key, err)
+	}
+	current := kernel.NormalizeSysctlValue(string(buf[:n]))
+	if current != kernel.NormalizeSysctlValue(value) {
+		return fmt.Errorf("sysctl %q cannot be changed from %q to %q: it is read-only in gVisor", key, current, value)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boot
+
+import (
+	"testing"
+)
+
+func TestSysctlPath(t *testing.T) {
+	for _, tc := range []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{key: "net.ipv4.tcp_sack", want: "net/ipv4/tcp_sack"},
+		{key: "kernel.yama.ptrace_scope", want: "kernel/yama/ptrace_scope"},
+		{key: "fs.nr_open", want: "fs/nr_open"},
+		{key: "", wantErr: true},
+		{key: "net..tcp_sack", wantErr: true},
+		{key: ".net.ipv4", wantErr: true},
+		{key: "net.ipv4.", wantErr: true},
+		{key: "net.ipv4/../../etc", wantErr: true},
+	} {
+		t.Run(tc.key, func(t *testing.T) {
+			got, err := sysctlPath(tc.key)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("sysctlPath(%q) = %q, want error", tc.key, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("sysctlPath(%q) failed: %v", tc.key, err)
+			}
+			if got != tc.want {
+				t.Errorf("sysctlPath(%q) = %q, want %q", tc.key, got, tc.want)
+			}
+		})
+	}
+}
@@ -37,6 +37,8 @@ import (
 	"gvisor.dev/gvisor/pkg/fsutil"
 	"gvisor.dev/gvisor/pkg/log"
 	"gvisor.dev/gvisor/pkg/sentry/checkpoint"
+	"gvisor.dev/gvisor/pkg/sentry/devices/guestapi"
+	"gvisor.dev/gvisor/pkg/sentry/devices/hostdev"
 	"gvisor.dev/gvisor/pkg/sentry/devices/memdev"
 	"gvisor.dev/gvisor/pkg/sentry/devices/nvproxy"
 	"gvisor.dev/gvisor/pkg/sentry/devices/nvproxy/nvconf"
@@ -166,6 +168,10 @@ func registerFilesystems(k *kernel.Kernel, info *containerInfo) error {
 		AllowUserMount: true,
 		AllowUserList:  true,
 	})
+	vfsObj.MustRegisterFilesystemType(tmpfs.HugetlbfsName, &tmpfs.HugetlbfsFilesystemType{}, &vfs.RegisterFilesystemTypeOptions{
+		AllowUserMount: true,
+		AllowUserList:  true,
+	})
 	vfsObj.MustRegisterFilesystemType(mqfs.Name, &mqfs.FilesystemType{}, &vfs.RegisterFilesystemTypeOptions{
 		AllowUserMount: true,
 		AllowUserList:  true,
@@ -192,6 +198,16 @@ func registerFilesystems(k *kernel.Kernel, info *containerInfo) error {
 		return err
 	}
 
+	// The guest API device is optional, so it's registered after the devices
+	// above that have dynamic major numbers, like nvidia-uvm. Otherwise
+	// toggling --guest-api would change their major numbers, which
+	// applications may have observed before a checkpoint.
+	if info.conf.GuestAPI {
+		if err := guestapi.Register(vfsObj); err != nil {
+			return fmt.Errorf("registering guestapi: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -353,6 +369,9 @@ func goferMountData(fd int, fa config.FileAccessType, conf *config.Config, suppr
 	if !conf.HostFifo.AllowOpen() {
 		opts = append(opts, "disable_fifo_open")
 	}
+	if conf.SharePageCache && fa != config.FileAccessShared {
+		opts = append(opts, "share_page_cache")
+	}
 	return opts
 }
 
@@ -374,6 +393,21 @@ func consumeMountOptions(opts []string, allowedKeys ...string) ([]string, []stri
 	return rem, out, nil
 }
 
+// mountOptionValue returns the value of the last "key=value" option in opts
+// with the given key.
+func mountOptionValue(opts []string, key string) (string, bool) {
+	var (
+		val   string
+		found bool
+	)
+	for _, o := range opts {
+		if k, v, ok := strings.Cut(o, "="); ok && k == key {
+			val, found = v, true
+		}
+	}
+	return val, found
+}
+
 func parseMountOption(opt string, allowedKeys ...string) (bool, error) {
 	kv := strings.SplitN(opt, "=", 3)
 	if len(kv) > 2 {
@@ -1051,7 +1085,7 @@ func getMountNameAndOptions(spec *specs.Spec, conf *config.Config, m *mountInfo,
 		if err != nil {
 			return "", nil, err
 		}
-		data = append(data, goferMountData(m.goferFD.Release(), getMountAccessType(conf, m.hint), conf, m.hint != nil && m.hint.SuppressDirectFS)...)
+		data = append(data, goferMountData(m.goferFD.Release(), getMountAccessType(conf, m.hint), conf, m.hint != nil && m.hint.ShouldSuppressDirectFS())...)
 		internalData = gofer.InternalFilesystemOptions{
 			UniqueID: checkpoint.ResourceID{
 				ContainerName: containerName,
@@ -1383,6 +1417,8 @@ func (c *containerMounter) mountCgroupSubmounts(ctx context.Context, spec *specs
 // containers in a pod.
 func (c *containerMounter) mountSharedMaster(ctx context.Context, spec *specs.Spec, conf *config.Config, mntInfo *mountInfo, creds *auth.Credentials) (*vfs.Mount, error) {
 	// Mount the master using the options from the hint (mount annotations).
+	// Size limits requested by each container are applied to its own mount
+	// by mountSharedSubmount.
 	origOpts := mntInfo.mount.Options
 	mntInfo.mount.Options = mntInfo.hint.Mount.Options
 	fsName, opts, err := getMountNameAndOptions(spec, conf, mntInfo, c.l.productName, c.containerName, c.containerID, c.l.fsRestore)
@@ -1407,6 +1443,16 @@ func (c *containerMounter) mountSharedSubmount(ctx context.Context, conf *config
 	opts := ParseMountOptions(mntInfo.mount.Options)
 	newMnt := c.l.k.VFS().NewDisconnectedMount(sharedMount.Filesystem(), sharedMount.Root(), opts)
 	defer newMnt.DecRef(ctx)
+	if mntInfo.hint.Mount.Type == tmpfs.Name {
+		// Hold the container to the size it requested, e.g. the shm size
+		// from the spec for a /dev/shm that is shared within the pod, even
+		// though the filesystem is shared with other containers.
+		if size, ok := mountOptionValue(mntInfo.mount.Options, "size"); ok {
+			if err := tmpfs.SetMountSizeLimit(newMnt, size); err != nil {
+				return nil, fmt.Errorf("limiting size of shared mount %q: %w", mntInfo.mount.Destination, err)
+			}
+		}
+	}
 
 	root := mns.Root(ctx)
 	defer root.DecRef(ctx)
@@ -1630,7 +1676,14 @@ func createDeviceFile(ctx context.Context, creds *auth.Credentials, info *contai
 		return fmt.Errorf("specified device at %q has invalid type %q", devSpec.Path, devSpec.Type)
 	}
 	// Convert host-assigned device major numbers to sentry-assigned ones.
-	if strings.HasPrefix(devSpec.Path, "/dev/vfio") || strings.HasPrefix(devSpec.Path, "/dev/accel") {
+	if specutils.HostDeviceProxied(info.conf, &devSpec) {
+		var err error
+		major, minor, err = hostdev.Register(vfsObj, devSpec.Path)
+		if err != nil {
+			return fmt.Errorf("registering host device %q: %w", devSpec.Path, err)
+		}
+		log.Infof("Proxying host device %v (%d, %d) as (%d, %d)", devSpec.Path, devSpec.Major, devSpec.Minor, major, minor)
+	} else if strings.HasPrefix(devSpec.Path, "/dev/vfio") || strings.HasPrefix(devSpec.Path, "/dev/accel") {
 		if devSpec.Path == "/dev/vfio/vfio" {
 			if err := vfio.Register(vfsObj, true /* useDevGofer */); err != nil {
 				return fmt.Errorf("registering vfio driver: %w", err)
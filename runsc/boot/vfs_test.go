@@ -180,3 +180,24 @@ func TestCgroupfsCPUDefaults(t *testing.T) {
 		})
 	}
 }
+
+func TestMountOptionValue(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		opts      []string
+		wantVal   string
+		wantFound bool
+	}{
+		{name: "none", opts: []string{"rw", "nosuid"}},
+		{name: "size", opts: []string{"rw", "size=65536k"}, wantVal: "65536k", wantFound: true},
+		{name: "last wins", opts: []string{"size=1m", "mode=1777", "size=2m"}, wantVal: "2m", wantFound: true},
+		{name: "prefix only", opts: []string{"sizes=1m", "size"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			val, found := mountOptionValue(tc.opts, "size")
+			if val != tc.wantVal || found != tc.wantFound {
+				t.Errorf("mountOptionValue(%v, \"size\") = (%q, %t), want (%q, %t)", tc.opts, val, found, tc.wantVal, tc.wantFound)
+			}
+		})
+	}
+}
@@ -176,6 +176,34 @@ func writeFile(path string, data []byte, perm os.FileMode) error {
 	return err
 }
 
+// maxMoveRounds is the number of times moveProcs rereads the source cgroup's
+// processes before giving up on processes that keep being created in it.
+const maxMoveRounds = 10
+
+// moveProcs moves all processes in the cgroup directory src to the cgroup
+// directory dst. Processes that are created in src while they are moved are
+// moved as well.
+func moveProcs(src, dst string) error {
+	for i := 0; i < maxMoveRounds; i++ {
+		procs, err := getValue(src, "cgroup.procs")
+		if err != nil {
+			return err
+		}
+		pids := strings.Fields(procs)
+		if len(pids) == 0 {
+			return nil
+		}
+		log.Debugf("Moving processes %v from cgroup %q to %q", pids, src, dst)
+		for _, pid := range pids {
+			// Processes may exit while being moved.
+			if err := setValue(dst, "cgroup.procs", pid); err != nil && !errors.Is(err, unix.ESRCH) {
+				return fmt.Errorf("moving process %s to cgroup %q: %w", pid, dst, err)
+			}
+		}
+	}
+	return fmt.Errorf("processes are still being created in cgroup %q", src)
+}
+
 func getValue(path, name string) (string, error) {
 	fullpath := filepath.Join(path, name)
 	out, err := os.ReadFile(fullpath)
@@ -193,6 +221,27 @@ func getInt(path, name string) (int, error) {
 	return strconv.Atoi(strings.TrimSpace(s))
 }
 
+// getKeyedValue returns the value of key in a flat keyed cgroup file, i.e. a
+// file made of "key value" lines such as memory.events. It returns 0 if the
+// key is not present.
+func getKeyedValue(path, name, key string) (uint64, error) {
+	s, err := getValue(path, name)
+	if err != nil {
+		return 0, err
+	}
+	sc := bufio.NewScanner(strings.NewReader(s))
+	for sc.Scan() {
+		k, v, err := parseKeyValue(sc.Text())
+		if err != nil {
+			return 0, err
+		}
+		if k == key {
+			return v, nil
+		}
+	}
+	return 0, nil
+}
+
 // fillFromAncestor sets the value of a cgroup file from the first ancestor
 // that has content. It does nothing if the file in 'path' has already been set.
 func fillFromAncestor(path string) (string, error) {
@@ -220,10 +269,10 @@ func fillFromAncestor(path string) (string, error) {
 	return val, nil
 }
 
-// countCpuset returns the number of CPU in a string formatted like:
+// CountCpuset returns the number of CPUs in a string formatted like:
 //
 //	"0-2,7,12-14  # bits 0, 1, 2, 7, 12, 13, and 14 set" - man 7 cpuset
-func countCpuset(cpuset string) (int, error) {
+func CountCpuset(cpuset string) (int, error) {
 	var count int
 	for _, p := range strings.Split(cpuset, ",") {
 		interval := strings.Split(p, "-")
@@ -371,7 +420,9 @@ type Cgroup interface {
 	CPUUsage() (uint64, error)
 	NumCPU() (int, error)
 	MemoryLimit() (uint64, error)
+	OOMKillCount() (uint64, error)
 	MakePath(controllerName string) string
+	MoveProcs(src Cgroup) error
 }
 
 // cgroupV1 represents a group inside all controllers. For example:
@@ -729,6 +780,19 @@ func (c *cgroupV1) Join() (func(), error) {
 	return cu.Release(), nil
 }
 
+// MoveProcs moves all processes in src to the cgroup in all controllers.
+func (c *cgroupV1) MoveProcs(src Cgroup) error {
+	for key, ctrlr := range controllers {
+		if err := moveProcs(src.MakePath(key), c.MakePath(key)); err != nil {
+			if ctrlr.optional() && os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
 // CPUQuota returns the raw CFS CPU quota in microseconds.
 // A value of -1 means unlimited.
 func (c *cgroupV1) CPUQuota() (int64, error) {
@@ -767,7 +831,7 @@ func (c *cgroupV1) NumCPU() (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	return countCpuset(strings.TrimSpace(cpuset))
+	return CountCpuset(strings.TrimSpace(cpuset))
 }
 
 // MemoryLimit returns the memory limit.
@@ -780,6 +844,12 @@ func (c *cgroupV1) MemoryLimit() (uint64, error) {
 	return strconv.ParseUint(strings.TrimSpace(limStr), 10, 64)
 }
 
+// OOMKillCount returns the number of processes in the cgroup killed by the
+// OOM killer.
+func (c *cgroupV1) OOMKillCount() (uint64, error) {
+	return getKeyedValue(c.MakePath("memory"), "memory.oom_control", "oom_kill")
+}
+
 // MakePath builds a path to the given controller.
 func (c *cgroupV1) MakePath(controllerName string) string {
 	path := c.Name
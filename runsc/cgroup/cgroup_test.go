@@ -92,17 +92,17 @@ func TestCountCpuset(t *testing.T) {
 		{str: "--", error: true},
 	} {
 		t.Run(tc.str, func(t *testing.T) {
-			got, err := countCpuset(tc.str)
+			got, err := CountCpuset(tc.str)
 			if tc.error {
 				if err == nil {
-					t.Errorf("countCpuset(%q) should have failed", tc.str)
+					t.Errorf("CountCpuset(%q) should have failed", tc.str)
 				}
 			} else {
 				if err != nil {
-					t.Errorf("countCpuset(%q) failed: %v", tc.str, err)
+					t.Errorf("CountCpuset(%q) failed: %v", tc.str, err)
 				}
 				if tc.want != got {
-					t.Errorf("countCpuset(%q) want: %d, got: %d", tc.str, tc.want, got)
+					t.Errorf("CountCpuset(%q) want: %d, got: %d", tc.str, tc.want, got)
 				}
 			}
 		})
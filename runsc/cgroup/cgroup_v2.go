@@ -277,6 +277,11 @@ func (c *cgroupV2) Join() (func(), error) {
 	return cu.Release(), nil
 }
 
+// MoveProcs implements Cgroup.MoveProcs.
+func (c *cgroupV2) MoveProcs(src Cgroup) error {
+	return moveProcs(src.MakePath(""), c.MakePath(""))
+}
+
 // readCPUQuotaAndPeriod reads and parses cpu.max from the given path.
 func readCPUQuotaAndPeriod(path string) (int64, int64, error) {
 	cpuMax, err := getValue(path, cpuLimitCgroup)
@@ -383,7 +388,7 @@ func (c *cgroupV2) NumCPU() (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	return countCpuset(strings.TrimSpace(cpuset))
+	return CountCpuset(strings.TrimSpace(cpuset))
 }
 
 func getMemoryLimit(path string) (string, error) {
@@ -394,6 +399,12 @@ func getMemoryLimit(path string) (string, error) {
 	return strings.TrimSpace(limStr), nil
 }
 
+// OOMKillCount returns the number of processes in the cgroup killed by the
+// OOM killer.
+func (c *cgroupV2) OOMKillCount() (uint64, error) {
+	return getKeyedValue(c.MakePath(""), "memory.events", "oom_kill")
+}
+
 // MemoryLimit returns the memory limit.
 func (c *cgroupV2) MemoryLimit() (uint64, error) {
 	limStr, err := getMemoryLimit(c.MakePath(""))
@@ -468,6 +468,35 @@ func TestInstallPrecreatedCgroupV2SetsMemorySwap(t *testing.T) {
 	}
 }
 
+func TestOOMKillCountCgroupV2(t *testing.T) {
+	dir, err := os.MkdirTemp(testutil.TmpDir(), "cgroup")
+	if err != nil {
+		t.Fatalf("error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cg := &cgroupV2{
+		Mountpoint: dir,
+		Path:       "sandbox",
+	}
+	cgPath := filepath.Join(cg.Mountpoint, cg.Path)
+	if err := os.MkdirAll(cgPath, 0o777); err != nil {
+		t.Fatalf("os.MkdirAll(): %v", err)
+	}
+	events := "low 0\nhigh 0\nmax 3\noom 2\noom_kill 2\noom_group_kill 0\n"
+	if err := os.WriteFile(filepath.Join(cgPath, "memory.events"), []byte(events), 0o666); err != nil {
+		t.Fatalf("os.WriteFile(): %v", err)
+	}
+
+	got, err := cg.OOMKillCount()
+	if err != nil {
+		t.Fatalf("OOMKillCount(): %v", err)
+	}
+	if got != 2 {
+		t.Errorf("OOMKillCount() = %d, want 2", got)
+	}
+}
+
 func TestParseCPUQuotaAndPeriod(t *testing.T) {
 	cases := []struct {
 		quota     string
@@ -28,6 +28,7 @@ import (
 	"gvisor.dev/gvisor/pkg/unet"
 	"gvisor.dev/gvisor/pkg/urpc"
 	"gvisor.dev/gvisor/runsc/checkpointgofer/gcs"
+	"gvisor.dev/gvisor/runsc/checkpointgofer/urlstore"
 	"gvisor.dev/gvisor/runsc/cli"
 	"gvisor.dev/gvisor/runsc/cmd/util"
 	"gvisor.dev/gvisor/runsc/flag"
@@ -60,6 +61,14 @@ type GCSOptions struct {
 	ParallelCompositeUpload string `json:"parallel_composite_upload,omitzero"`
 }
 
+// URLOptions holds options that configure a checkpoint gofer to access
+// checkpoint files at HTTP(S) URLs.
+type URLOptions struct {
+	// URL is the location of checkpoint files. See
+	// urlstore.FileServerOptions.URL.
+	URL string `json:"url"`
+}
+
 // checkpointGoferCmd implements subcommands.Command for the checkpoint gofer.
 type checkpointGoferCmd struct {
 	util.InternalSubCommand
@@ -71,6 +80,7 @@ type checkpointGoferCmd struct {
 
 	sockFD    int
 	gcsOptsFD int
+	urlOptsFD int
 }
 
 // Name implements subcommands.Command.Name.
@@ -85,7 +95,7 @@ func (*checkpointGoferCmd) Synopsis() string {
 
 // Usage implements subcommands.Command.Usage.
 func (*checkpointGoferCmd) Usage() string {
-	return "[-allow-checkpoint-reads|-allow-checkpoint-writes|-allow-fscheckpoint-reads|-allow-fscheckpoint-writes] -sock-fd=<socket fd> -gcs-opts-fd=<options fd>\n"
+	return "[-allow-checkpoint-reads|-allow-checkpoint-writes|-allow-fscheckpoint-reads|-allow-fscheckpoint-writes] -sock-fd=<socket fd> (-gcs-opts-fd=<options fd>|-url-opts-fd=<options fd>)\n"
 }
 
 // SetFlags implements subcommands.Command.SetFlags.
@@ -96,11 +106,12 @@ func (cmd *checkpointGoferCmd) SetFlags(f *flag.FlagSet) {
 	f.BoolVar(&cmd.allowFSCheckpointWrites, "allow-fscheckpoint-writes", false, "enable writing filesystem checkpoint files")
 	f.IntVar(&cmd.sockFD, "sock-fd", -1, "FD for a Unix domain socket that is connected to the sentry")
 	f.IntVar(&cmd.gcsOptsFD, "gcs-opts-fd", -1, "FD for a file containing GCSOptions in JSON")
+	f.IntVar(&cmd.urlOptsFD, "url-opts-fd", -1, "FD for a file containing URLOptions in JSON")
 }
 
 // Execute implements subcommands.Command.Execute.
 func (cmd *checkpointGoferCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
-	if (!cmd.allowCheckpointReads && !cmd.allowCheckpointWrites && !cmd.allowFSCheckpointReads && !cmd.allowFSCheckpointWrites) || cmd.sockFD < 0 || cmd.gcsOptsFD < 0 {
+	if (!cmd.allowCheckpointReads && !cmd.allowCheckpointWrites && !cmd.allowFSCheckpointReads && !cmd.allowFSCheckpointWrites) || cmd.sockFD < 0 || (cmd.gcsOptsFD < 0) == (cmd.urlOptsFD < 0) {
 		f.Usage()
 		return subcommands.ExitUsageError
 	}
@@ -110,14 +121,26 @@ func (cmd *checkpointGoferCmd) Execute(ctx context.Context, f *flag.FlagSet, arg
 		util.Fatalf("Failed to construct unet.Socket: %v", err)
 	}
 
-	gcsOptsFile := os.NewFile(uintptr(cmd.gcsOptsFD), "checkpointgofer.GCSOptions.json")
-	var gcsOpts GCSOptions
-	if err := json.NewDecoder(gcsOptsFile).Decode(&gcsOpts); err != nil {
-		util.Fatalf("Failed to decode options: %v", err)
-	}
-	gcsOptsFile.Close()
-	if err := cmd.runGCS(ctx, sock, &gcsOpts); err != nil {
-		util.Fatalf("%v", err)
+	if cmd.urlOptsFD >= 0 {
+		urlOptsFile := os.NewFile(uintptr(cmd.urlOptsFD), "checkpointgofer.URLOptions.json")
+		var urlOpts URLOptions
+		if err := json.NewDecoder(urlOptsFile).Decode(&urlOpts); err != nil {
+			util.Fatalf("Failed to decode options: %v", err)
+		}
+		urlOptsFile.Close()
+		if err := cmd.runURL(ctx, sock, &urlOpts); err != nil {
+			util.Fatalf("%v", err)
+		}
+	} else {
+		gcsOptsFile := os.NewFile(uintptr(cmd.gcsOptsFD), "checkpointgofer.GCSOptions.json")
+		var gcsOpts GCSOptions
+		if err := json.NewDecoder(gcsOptsFile).Decode(&gcsOpts); err != nil {
+			util.Fatalf("Failed to decode options: %v", err)
+		}
+		gcsOptsFile.Close()
+		if err := cmd.runGCS(ctx, sock, &gcsOpts); err != nil {
+			util.Fatalf("%v", err)
+		}
 	}
 
 	util.Infof("Server stopped, exiting")
@@ -167,6 +190,30 @@ func (cmd *checkpointGoferCmd) runGCS(ctx context.Context, sock *unet.Socket, op
 	return nil
 }
 
+// runURL runs the checkpoint gofer for HTTP(S) URL access on the given
+// socket. It does not return until the client disconnects or a fatal error
+// occurs.
+func (cmd *checkpointGoferCmd) runURL(ctx context.Context, sock *unet.Socket, opts *URLOptions) error {
+	ufs, err := urlstore.NewFileServer(ctx, &urlstore.FileServerOptions{
+		AllowCheckpointReads:    cmd.allowCheckpointReads,
+		AllowCheckpointWrites:   cmd.allowCheckpointWrites,
+		AllowFSCheckpointReads:  cmd.allowFSCheckpointReads,
+		AllowFSCheckpointWrites: cmd.allowFSCheckpointWrites,
+		URL:                     opts.URL,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to construct URL file server: %w", err)
+	}
+	afs, err := stateipc.NewAsyncFileServer(ufs)
+	if err != nil {
+		return fmt.Errorf("failed to construct stateipc server: %w", err)
+	}
+	server := urpc.NewServer()
+	server.Register(afs)
+	server.Handle(sock)
+	return nil
+}
+
 // main is the binary's entry point.
 func main() {
 	cli.Run(&gvisorbinaries.CheckpointGofer, map[util.SubCommand]string{
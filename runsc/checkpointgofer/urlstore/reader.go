@@ -0,0 +1,181 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package urlstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/memmap"
+	"gvisor.dev/gvisor/pkg/sentry/state/stateio"
+	"gvisor.dev/gvisor/pkg/sync"
+)
+
+// Reader implements stateio.AsyncReader for an object at an HTTP(S) URL,
+// using ranged GET requests.
+type Reader struct {
+	stateio.NoRegisterClientFD
+
+	maxReadBytes uint64
+	maxRanges    int
+	client       *http.Client
+	url          string
+	subs         chan readSubmission
+	cmps         chan stateio.Completion
+	cancel       context.CancelCauseFunc
+	workers      sync.WaitGroup
+}
+
+type readSubmission struct {
+	id    int
+	off   int64
+	total uint64
+	dst   stateio.LocalClientRanges
+}
+
+// NewReader returns a Reader that reads from the object at the given URL.
+func NewReader(ctx context.Context, client *http.Client, url string, maxReadBytes uint64, maxRanges, maxParallel int) *Reader {
+	ctx, cancel := context.WithCancelCause(ctx)
+	r := &Reader{
+		maxReadBytes: maxReadBytes,
+		maxRanges:    maxRanges,
+		client:       client,
+		url:          url,
+		subs:         make(chan readSubmission, maxParallel),
+		cmps:         make(chan stateio.Completion, maxParallel),
+		cancel:       cancel,
+	}
+	r.workers.Add(maxParallel)
+	for range maxParallel {
+		go r.workerMain(ctx)
+	}
+	return r
+}
+
+// Close implements stateio.AsyncReader.Close.
+func (r *Reader) Close() error {
+	r.cancel(fmt.Errorf("context canceled by Reader.Close"))
+	r.workers.Wait()
+	return nil
+}
+
+// MaxReadBytes implements stateio.AsyncReader.MaxReadBytes.
+func (r *Reader) MaxReadBytes() uint64 {
+	return r.maxReadBytes
+}
+
+// MaxRanges implements stateio.AsyncReader.MaxRanges.
+func (r *Reader) MaxRanges() int {
+	return r.maxRanges
+}
+
+// MaxParallel implements stateio.AsyncReader.MaxParallel.
+func (r *Reader) MaxParallel() int {
+	return cap(r.subs)
+}
+
+// AddRead implements stateio.AsyncReader.AddRead.
+func (r *Reader) AddRead(id int, off int64, _ stateio.DestinationFile, _ memmap.FileRange, dstMap []byte) {
+	r.subs <- readSubmission{
+		id:    id,
+		off:   off,
+		total: uint64(len(dstMap)),
+		dst:   stateio.LocalClientMapping(dstMap),
+	}
+}
+
+// AddReadv implements stateio.AsyncReader.AddReadv.
+func (r *Reader) AddReadv(id int, off int64, total uint64, _ stateio.DestinationFile, _ []memmap.FileRange, dstMaps []unix.Iovec) {
+	r.subs <- readSubmission{
+		id:    id,
+		off:   off,
+		total: total,
+		dst:   stateio.LocalClientMappings(dstMaps),
+	}
+}
+
+// Wait implements stateio.AsyncReader.Wait.
+func (r *Reader) Wait(cs []stateio.Completion, minCompletions int) ([]stateio.Completion, error) {
+	return stateio.CompletionChanWait(r.cmps, cs, minCompletions)
+}
+
+func (r *Reader) workerMain(ctx context.Context) {
+	defer r.workers.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sub := <-r.subs:
+			n, err := r.read(ctx, sub)
+			r.cmps <- stateio.Completion{
+				ID:  sub.id,
+				N:   n,
+				Err: err,
+			}
+		}
+	}
+}
+
+// read performs a single ranged GET request for sub.
+func (r *Reader) read(ctx context.Context, sub readSubmission) (uint64, error) {
+	if sub.total == 0 {
+		return 0, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", sub.off, sub.off+int64(sub.total)-1))
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("GET failed: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+	case resp.StatusCode == http.StatusOK && sub.off == 0:
+		// The server ignored the Range header and is sending the whole
+		// object; the prefix we want comes first.
+	case resp.StatusCode == http.StatusOK:
+		return 0, fmt.Errorf("server does not support range requests")
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		return 0, io.EOF
+	case isPermissionDeniedCode(resp.StatusCode):
+		log.Infof("urlstore.Reader returning EACCES for status %q", resp.Status)
+		return 0, unix.EACCES
+	default:
+		return 0, fmt.Errorf("GET failed with status %q", resp.Status)
+	}
+	var done uint64
+	for _, dst := range sub.dst.Mappings {
+		n, err := io.ReadFull(resp.Body, dst)
+		done += uint64(n)
+		if err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = io.EOF
+			}
+			return done, err
+		}
+	}
+	return done, nil
+}
+
+func isPermissionDeniedCode(code int) bool {
+	return code == http.StatusForbidden || code == http.StatusUnauthorized
+}
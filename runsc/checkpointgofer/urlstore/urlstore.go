@@ -0,0 +1,212 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package urlstore provides support for state files stored at HTTP(S) URLs,
+// such as objects in S3, GCS or any other object store that supports HTTP PUT
+// uploads and ranged GET downloads.
+//
+// Checkpoint files are streamed to and from the object store, so they never
+// need to be staged on local disk. Since the size of a checkpoint file is not
+// known until it has been completely written, uploads use chunked transfer
+// encoding, which the server must support.
+package urlstore
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/state/checkpointfiles"
+	"gvisor.dev/gvisor/pkg/sentry/state/stateio"
+)
+
+const (
+	// Read tuning parameters:
+	miscFileMaxReadBytes     = 1 << 20 // 1 MiB
+	miscFileMaxReadParallel  = 8
+	pagesFileMaxReadBytes    = 16 << 20 // 16 MiB
+	pagesFileMaxReadParallel = 64
+
+	// Write tuning parameters:
+	miscFileMaxWriteBytes     = 2 << 20 // 2 MiB
+	miscFileMaxWriteParallel  = 4
+	pagesFileMaxWriteBytes    = 32 << 20 // 32 MiB
+	pagesFileMaxWriteParallel = 4
+
+	// contentType is the Content-Type for all objects created by this
+	// package.
+	contentType = "application/octet-stream"
+
+	// NamePlaceholder may appear in FileServerOptions.URL, in which case it
+	// is replaced by each checkpoint file's name.
+	NamePlaceholder = "{name}"
+)
+
+// FileServer implements stateipc.AsyncFileServerImpl for checkpoint files
+// stored at HTTP(S) URLs.
+type FileServer struct {
+	ctx                     context.Context
+	cancel                  context.CancelCauseFunc
+	client                  *http.Client
+	rawURL                  string
+	url                     *url.URL
+	allowCheckpointReads    bool
+	allowCheckpointWrites   bool
+	allowFSCheckpointReads  bool
+	allowFSCheckpointWrites bool
+}
+
+// FileServerOptions provides options to NewFileServer.
+type FileServerOptions struct {
+	// AllowCheckpointReads enables checkpoint reading.
+	AllowCheckpointReads bool
+
+	// AllowCheckpointWrites enables checkpoint writing.
+	AllowCheckpointWrites bool
+
+	// AllowFSCheckpointReads enables filesystem checkpoint reading.
+	AllowFSCheckpointReads bool
+
+	// AllowFSCheckpointWrites enables filesystem checkpoint writing.
+	AllowFSCheckpointWrites bool
+
+	// URL is the location of checkpoint files. If URL contains
+	// NamePlaceholder, each file's URL is formed by replacing NamePlaceholder
+	// with the file's name; this allows file names to appear anywhere in the
+	// URL, e.g. before a query string containing credentials. Otherwise, the
+	// file's name is appended to URL's path.
+	//
+	// Credentials may be included in URL's user info, which is sent using
+	// HTTP basic authentication, or its query string.
+	URL string
+}
+
+// NewFileServer returns a new FileServer.
+func NewFileServer(ctx context.Context, opts *FileServerOptions) (*FileServer, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q, must be http or https", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("URL must include a host")
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &FileServer{
+		ctx:                     ctx,
+		cancel:                  cancel,
+		client:                  &http.Client{},
+		rawURL:                  opts.URL,
+		url:                     u,
+		allowCheckpointReads:    opts.AllowCheckpointReads,
+		allowCheckpointWrites:   opts.AllowCheckpointWrites,
+		allowFSCheckpointReads:  opts.AllowFSCheckpointReads,
+		allowFSCheckpointWrites: opts.AllowFSCheckpointWrites,
+	}, nil
+}
+
+// Destroy implements stateipc.AsyncFileServerImpl.Destroy.
+func (s *FileServer) Destroy() {
+	s.cancel(fmt.Errorf("context canceled by urlstore.FileServer.Destroy"))
+	s.client.CloseIdleConnections()
+}
+
+// fileURL returns the URL of the checkpoint file with the given name.
+func (s *FileServer) fileURL(name string) string {
+	// Substitute into the URL as given, since url.URL.String would escape
+	// NamePlaceholder.
+	if strings.Contains(s.rawURL, NamePlaceholder) {
+		return strings.ReplaceAll(s.rawURL, NamePlaceholder, url.PathEscape(name))
+	}
+	return s.url.JoinPath(name).String()
+}
+
+// redactedFileURL returns fileURL(name) without credentials, for logging.
+func (s *FileServer) redactedFileURL(name string) string {
+	u, err := url.Parse(s.fileURL(name))
+	if err != nil {
+		return name
+	}
+	u.RawQuery = ""
+	return u.Redacted()
+}
+
+// checkAccess returns an error if the checkpoint file with the given name may
+// not be accessed.
+func (s *FileServer) checkAccess(name string, write bool) error {
+	allowCheckpoint, allowFSCheckpoint := s.allowCheckpointReads, s.allowFSCheckpointReads
+	if write {
+		allowCheckpoint, allowFSCheckpoint = s.allowCheckpointWrites, s.allowFSCheckpointWrites
+	}
+	var allowed bool
+	switch name {
+	case checkpointfiles.StateFileName:
+		allowed = allowCheckpoint
+	case checkpointfiles.FSCheckpointManifestFileName, checkpointfiles.FSCheckpointMultiTarFileName:
+		allowed = allowFSCheckpoint
+	case checkpointfiles.PagesMetadataFileName, checkpointfiles.PagesFileName:
+		allowed = allowCheckpoint || allowFSCheckpoint
+	default:
+		log.Warningf("urlstore.FileServer: unknown path %q", name)
+		return fs.ErrPermission
+	}
+	if !allowed {
+		log.Warningf("urlstore.FileServer: attempted to open %q (write=%t) without permission", name, write)
+		return fs.ErrPermission
+	}
+	return nil
+}
+
+// OpenRead implements stateipc.AsyncFileServerImpl.OpenRead.
+func (s *FileServer) OpenRead(path string) (stateio.AsyncReader, error) {
+	if err := s.checkAccess(path, false /* write */); err != nil {
+		return nil, err
+	}
+	log.Infof("Opening %s for reading", s.redactedFileURL(path))
+	if path == checkpointfiles.PagesFileName {
+		// Provision one range per page, which is the most that
+		// pgalloc.MemoryFile restore can require. Since Reader doesn't use
+		// readv, we aren't subject to UIO_MAXIOV.
+		maxRanges := pagesFileMaxReadBytes / os.Getpagesize()
+		return NewReader(s.ctx, s.client, s.fileURL(path), pagesFileMaxReadBytes, maxRanges, pagesFileMaxReadParallel), nil
+	}
+	// Other files are read using stateio.BufReader, which doesn't use
+	// MaxRanges > 1.
+	return NewReader(s.ctx, s.client, s.fileURL(path), miscFileMaxReadBytes, 1 /* maxRanges */, miscFileMaxReadParallel), nil
+}
+
+// OpenWrite implements stateipc.AsyncFileServerImpl.OpenWrite.
+func (s *FileServer) OpenWrite(path string) (stateio.AsyncWriter, error) {
+	if err := s.checkAccess(path, true /* write */); err != nil {
+		return nil, err
+	}
+	log.Infof("Opening %s for writing", s.redactedFileURL(path))
+	if path == checkpointfiles.PagesFileName {
+		// Provision one range per page, which is the most that
+		// pgalloc.MemoryFile saving can require. Since Writer doesn't use
+		// writev, we aren't subject to UIO_MAXIOV.
+		maxRanges := int(pagesFileMaxWriteBytes / uint64(os.Getpagesize()))
+		return NewWriter(s.ctx, s.client, s.fileURL(path), pagesFileMaxWriteBytes, maxRanges, pagesFileMaxWriteParallel), nil
+	}
+	// Other files are written using stateio.BufWriter, which doesn't use
+	// MaxRanges > 1.
+	return NewWriter(s.ctx, s.client, s.fileURL(path), miscFileMaxWriteBytes, 1 /* maxRanges */, miscFileMaxWriteParallel), nil
+}
@@ -0,0 +1,211 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package urlstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/sentry/memmap"
+	"gvisor.dev/gvisor/pkg/sentry/state/checkpointfiles"
+	"gvisor.dev/gvisor/pkg/sentry/state/stateio"
+)
+
+// objectStore is a minimal HTTP object store.
+type objectStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *objectStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.objects[r.URL.Path] = data
+		s.mu.Unlock()
+	case http.MethodGet:
+		data, ok := s.get(r.URL.Path)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	default:
+		http.Error(w, "unsupported method", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *objectStore) get(path string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[path]
+	return data, ok
+}
+
+func newTestServer(t *testing.T) (*objectStore, *httptest.Server) {
+	store := &objectStore{objects: make(map[string][]byte)}
+	srv := httptest.NewServer(store)
+	t.Cleanup(srv.Close)
+	return store, srv
+}
+
+func waitOne(t *testing.T, wait func([]stateio.Completion, int) ([]stateio.Completion, error)) stateio.Completion {
+	t.Helper()
+	cs, err := wait(nil, 1)
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if len(cs) != 1 {
+		t.Fatalf("Wait returned %d completions, want 1", len(cs))
+	}
+	return cs[0]
+}
+
+func TestWriteRead(t *testing.T) {
+	store, srv := newTestServer(t)
+	s, err := NewFileServer(context.Background(), &FileServerOptions{
+		AllowCheckpointReads:  true,
+		AllowCheckpointWrites: true,
+		URL:                   srv.URL + "/images/1",
+	})
+	if err != nil {
+		t.Fatalf("NewFileServer failed: %v", err)
+	}
+	defer s.Destroy()
+
+	chunks := [][]byte{[]byte("hello, "), []byte("world")}
+	w, err := s.OpenWrite(checkpointfiles.StateFileName)
+	if err != nil {
+		t.Fatalf("OpenWrite failed: %v", err)
+	}
+	for i, chunk := range chunks {
+		w.AddWrite(i, nil, memmap.FileRange{}, chunk)
+		if c := waitOne(t, w.Wait); c.Err != nil || c.N != uint64(len(chunk)) {
+			t.Fatalf("write %d completed with %+v", i, c)
+		}
+	}
+	if err := w.Finalize(); err != nil {
+		t.Fatalf("Finalize failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	want := []byte("hello, world")
+	if got, _ := store.get("/images/1/" + checkpointfiles.StateFileName); !bytes.Equal(got, want) {
+		t.Fatalf("stored object is %q, want %q", got, want)
+	}
+
+	r, err := s.OpenRead(checkpointfiles.StateFileName)
+	if err != nil {
+		t.Fatalf("OpenRead failed: %v", err)
+	}
+	defer r.Close()
+	buf := make([]byte, 5)
+	r.AddRead(0, 7, nil, memmap.FileRange{}, buf)
+	if c := waitOne(t, r.Wait); c.Err != nil || c.N != 5 || string(buf) != "world" {
+		t.Errorf("read at offset 7 completed with %+v, data %q", c, buf)
+	}
+	r.AddRead(1, 10, nil, memmap.FileRange{}, buf)
+	if c := waitOne(t, r.Wait); c.Err != io.EOF || c.N != 2 {
+		t.Errorf("read past end completed with %+v, want 2 bytes and EOF", c)
+	}
+	r.AddRead(2, 100, nil, memmap.FileRange{}, buf)
+	if c := waitOne(t, r.Wait); c.Err != io.EOF || c.N != 0 {
+		t.Errorf("read after end completed with %+v, want EOF", c)
+	}
+}
+
+func TestUnfinalizedWriteIsAborted(t *testing.T) {
+	store, srv := newTestServer(t)
+	s, err := NewFileServer(context.Background(), &FileServerOptions{
+		AllowCheckpointWrites: true,
+		URL:                   srv.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewFileServer failed: %v", err)
+	}
+	defer s.Destroy()
+
+	w, err := s.OpenWrite(checkpointfiles.PagesFileName)
+	if err != nil {
+		t.Fatalf("OpenWrite failed: %v", err)
+	}
+	w.AddWrite(0, nil, memmap.FileRange{}, []byte("partial"))
+	waitOne(t, w.Wait)
+	w.Close()
+	if _, ok := store.get("/" + checkpointfiles.PagesFileName); ok {
+		t.Errorf("unfinalized object was stored")
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	s, err := NewFileServer(context.Background(), &FileServerOptions{
+		AllowCheckpointReads: true,
+		URL:                  "https://example.com/ckpt",
+	})
+	if err != nil {
+		t.Fatalf("NewFileServer failed: %v", err)
+	}
+	defer s.Destroy()
+	if _, err := s.OpenWrite(checkpointfiles.StateFileName); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("OpenWrite without write permission returned %v, want %v", err, fs.ErrPermission)
+	}
+	if _, err := s.OpenRead(checkpointfiles.FSCheckpointManifestFileName); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("OpenRead of filesystem checkpoint returned %v, want %v", err, fs.ErrPermission)
+	}
+	if _, err := s.OpenRead("../etc/passwd"); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("OpenRead of unknown file returned %v, want %v", err, fs.ErrPermission)
+	}
+}
+
+func TestFileURL(t *testing.T) {
+	for _, tc := range []struct {
+		url  string
+		want string
+	}{
+		{url: "https://example.com/ckpt", want: "https://example.com/ckpt/checkpoint.img"},
+		{url: "https://example.com/ckpt/", want: "https://example.com/ckpt/checkpoint.img"},
+		{url: "https://example.com/ckpt?token=x", want: "https://example.com/ckpt/checkpoint.img?token=x"},
+		{url: "https://example.com/ckpt-{name}?token=x", want: "https://example.com/ckpt-checkpoint.img?token=x"},
+	} {
+		s, err := NewFileServer(context.Background(), &FileServerOptions{URL: tc.url})
+		if err != nil {
+			t.Fatalf("NewFileServer(%q) failed: %v", tc.url, err)
+		}
+		if got := s.fileURL("checkpoint.img"); got != tc.want {
+			t.Errorf("fileURL with URL %q = %q, want %q", tc.url, got, tc.want)
+		}
+		s.Destroy()
+	}
+	for _, bad := range []string{"ftp://example.com/ckpt", "/local/path", "https:///ckpt"} {
+		if _, err := NewFileServer(context.Background(), &FileServerOptions{URL: bad}); err == nil {
+			t.Errorf("NewFileServer(%q) succeeded, want error", bad)
+		}
+	}
+}
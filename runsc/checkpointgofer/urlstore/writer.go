@@ -0,0 +1,192 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package urlstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/memmap"
+	"gvisor.dev/gvisor/pkg/sentry/state/stateio"
+	"gvisor.dev/gvisor/pkg/sync"
+)
+
+// Writer implements stateio.AsyncWriter for an object at an HTTP(S) URL. The
+// object is uploaded by a single streaming PUT request.
+type Writer struct {
+	stateio.NoRegisterClientFD
+
+	maxWriteBytes uint64
+	maxRanges     int
+	pw            *io.PipeWriter
+	subs          chan writeSubmission
+	cmps          chan stateio.Completion
+	cancel        context.CancelCauseFunc
+	worker        sync.WaitGroup
+
+	// resp receives the result of the PUT request.
+	resp chan error
+
+	// finalized is true if Finalize has been called.
+	finalized bool
+}
+
+type writeSubmission struct {
+	id  int
+	src stateio.LocalClientRanges
+}
+
+// NewWriter returns a Writer that writes to the object at the given URL.
+func NewWriter(ctx context.Context, client *http.Client, url string, maxWriteBytes uint64, maxRanges, maxParallel int) *Writer {
+	ctx, cancel := context.WithCancelCause(ctx)
+	pr, pw := io.Pipe()
+	w := &Writer{
+		maxWriteBytes: maxWriteBytes,
+		maxRanges:     maxRanges,
+		pw:            pw,
+		subs:          make(chan writeSubmission, maxParallel),
+		cmps:          make(chan stateio.Completion, maxParallel),
+		cancel:        cancel,
+		resp:          make(chan error, 1),
+	}
+	go func() {
+		err := put(ctx, client, url, pr)
+		// Unblock writes to the pipe if the request failed early.
+		pr.CloseWithError(fmt.Errorf("PUT request ended: %v", err))
+		w.resp <- err
+	}()
+	w.worker.Add(1)
+	go w.workerMain(ctx)
+	return w
+}
+
+// put uploads the contents of body to url.
+func put(ctx context.Context, client *http.Client, url string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, body)
+	if err != nil {
+		return err
+	}
+	// The size of the upload is unknown, so it is sent using chunked transfer
+	// encoding.
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", contentType)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("PUT failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if isPermissionDeniedCode(resp.StatusCode) {
+		log.Infof("urlstore.Writer returning EACCES for status %q", resp.Status)
+		return unix.EACCES
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("PUT failed with status %q", resp.Status)
+	}
+	return nil
+}
+
+// Close implements stateio.AsyncWriter.Close.
+func (w *Writer) Close() error {
+	w.cancel(fmt.Errorf("context canceled by Writer.Close"))
+	w.worker.Wait()
+	if !w.finalized {
+		// Abort the upload, so that the server doesn't store a truncated
+		// object. The error from the PUT request is irrelevant, since
+		// Finalize was never called.
+		w.pw.CloseWithError(fmt.Errorf("writer closed before being finalized"))
+		<-w.resp
+	}
+	return nil
+}
+
+// MaxWriteBytes implements stateio.AsyncWriter.MaxWriteBytes.
+func (w *Writer) MaxWriteBytes() uint64 {
+	return w.maxWriteBytes
+}
+
+// MaxRanges implements stateio.AsyncWriter.MaxRanges.
+func (w *Writer) MaxRanges() int {
+	return w.maxRanges
+}
+
+// MaxParallel implements stateio.AsyncWriter.MaxParallel.
+func (w *Writer) MaxParallel() int {
+	return cap(w.subs)
+}
+
+// AddWrite implements stateio.AsyncWriter.AddWrite.
+func (w *Writer) AddWrite(id int, _ stateio.SourceFile, _ memmap.FileRange, srcMap []byte) {
+	w.subs <- writeSubmission{
+		id:  id,
+		src: stateio.LocalClientMapping(srcMap),
+	}
+}
+
+// AddWritev implements stateio.AsyncWriter.AddWritev.
+func (w *Writer) AddWritev(id int, total uint64, _ stateio.SourceFile, _ []memmap.FileRange, srcMaps []unix.Iovec) {
+	w.subs <- writeSubmission{
+		id:  id,
+		src: stateio.LocalClientMappings(srcMaps),
+	}
+}
+
+// Wait implements stateio.AsyncWriter.Wait.
+func (w *Writer) Wait(cs []stateio.Completion, minCompletions int) ([]stateio.Completion, error) {
+	return stateio.CompletionChanWait(w.cmps, cs, minCompletions)
+}
+
+// Reserve implements stateio.AsyncWriter.Reserve.
+func (w *Writer) Reserve(n uint64) {
+	// no-op
+}
+
+// Finalize implements stateio.AsyncWriter.Finalize.
+func (w *Writer) Finalize() error {
+	w.finalized = true
+	// Closing the pipe ends the request body, completing the upload.
+	w.pw.Close()
+	return <-w.resp
+}
+
+func (w *Writer) workerMain(ctx context.Context) {
+	defer w.worker.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sub := <-w.subs:
+			var done uint64
+			var doneErr error
+			for _, src := range sub.src.Mappings {
+				n, err := w.pw.Write(src)
+				done += uint64(n)
+				if err != nil {
+					doneErr = err
+					break
+				}
+			}
+			w.cmps <- stateio.Completion{
+				ID:  sub.id,
+				N:   done,
+				Err: doneErr,
+			}
+		}
+	}
+}
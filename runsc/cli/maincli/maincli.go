@@ -66,12 +66,24 @@ func commands() (map[util.SubCommand]string, []subcommands.Command) {
 		new(cmd.Wait):       userGroup,
 
 		// Non-OCI user-facing runsc commands.
+		new(cmd.Attach):       userGroup,
+		new(cmd.AttachStdio):  userGroup,
+		new(cmd.Clone):        userGroup,
+		new(cmd.Compat):       userGroup,
+		new(cmd.Cp):           userGroup,
 		new(cmd.Do):           userGroup,
+		new(cmd.Estimate):     userGroup,
 		new(cmd.FSCheckpoint): userGroup,
+		new(cmd.HostFDs):      userGroup,
+		new(cmd.ImportCRIU):   userGroup,
 		new(cmd.PortForward):  userGroup,
+		new(cmd.Pool):         userGroup,
 		new(cmd.Read):         userGroup,
+		new(cmd.Reclaim):      userGroup,
 		new(cmd.SandboxExec):  userGroup,
+		new(cmd.StreamOutput): userGroup,
 		new(cmd.Tar):          userGroup,
+		new(cmd.Top):          userGroup,
 
 		// Helpers.
 		new(cmd.Install):     helperGroup,
@@ -81,6 +93,7 @@ func commands() (map[util.SubCommand]string, []subcommands.Command) {
 		new(trace.Trace):     helperGroup,
 		new(cmd.CPUFeatures): helperGroup,
 		new(cmd.Features):    helperGroup,
+		new(cmd.Doctor):      helperGroup,
 
 		new(cmd.Debug):        debugGroup,
 		new(cmd.Statefile):    debugGroup,
@@ -0,0 +1,143 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/google/subcommands"
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/control"
+	"gvisor.dev/gvisor/runsc/cmd/util"
+	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/container"
+	"gvisor.dev/gvisor/runsc/flag"
+)
+
+// Attach implements subcommands.Command for the "attach" command.
+type Attach struct {
+	containerLoader
+	detachKeys string
+}
+
+// Name implements subcommands.Command.Name.
+func (*Attach) Name() string {
+	return "attach"
+}
+
+// Synopsis implements subcommands.Command.Synopsis.
+func (*Attach) Synopsis() string {
+	return "attach the terminal to a process started with exec --detach-keys"
+}
+
+// Usage implements subcommands.Command.Usage.
+func (*Attach) Usage() string {
+	return `attach [flags] <container-id> <pid> - attach the terminal to a process started with "exec --detach-keys"
+
+Attaches the terminal of this command to the pseudo-terminal of a process
+started with "runsc exec --detach-keys", after writing up to 64KiB of the
+output of the process written since it started. <pid> is the PID of the
+process in the container, as written to --internal-pid-file by "runsc exec".
+Type the detach keys to detach from the process, which keeps running. Only one
+terminal can be attached to a process at a time. Once the process exits, this
+command exits with its exit status.
+
+EXAMPLE:
+       # runsc exec --detach-keys=ctrl-p,ctrl-q --internal-pid-file=/tmp/pid <container-id> sh
+       # runsc attach <container-id> $(cat /tmp/pid)
+`
+}
+
+// SetFlags implements subcommands.Command.SetFlags.
+func (a *Attach) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&a.detachKeys, "detach-keys", "ctrl-p,ctrl-q", `keys that detach the terminal, e.g. "ctrl-p,ctrl-q", or empty to stay attached until the process exits`)
+}
+
+// Execute implements subcommands.Command.Execute.
+func (a *Attach) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
+	if f.NArg() != 2 {
+		f.Usage()
+		return subcommands.ExitUsageError
+	}
+	pid, err := strconv.ParseInt(f.Arg(1), 10, 32)
+	if err != nil || pid <= 0 {
+		util.Fatalf("invalid PID %q", f.Arg(1))
+	}
+	var detachKeys []byte
+	if a.detachKeys != "" {
+		if detachKeys, err = control.ParseDetachKeys(a.detachKeys); err != nil {
+			util.Fatalf("%v", err)
+		}
+	}
+
+	conf := args[0].(*config.Config)
+	waitStatus := args[1].(*unix.WaitStatus)
+	c, err := a.loadContainer(conf, f, container.LoadOpts{})
+	if err != nil {
+		util.Fatalf("loading container: %v", err)
+	}
+
+	stopForwarding := forwardWindowSize(c, int32(pid))
+	defer stopForwarding()
+	running, err := c.AttachExec(int32(pid), os.Stdin, detachKeys)
+	if err != nil {
+		util.Fatalf("%v", err)
+	}
+	if running {
+		fmt.Fprintf(os.Stderr, "Detached from process %d in container %q\n", pid, c.ID)
+		*waitStatus = 0
+		return subcommands.ExitSuccess
+	}
+
+	ws, err := c.WaitPID(int32(pid))
+	if err != nil {
+		util.Fatalf("waiting on PID %d: %v", pid, err)
+	}
+	*waitStatus = ws
+	return subcommands.ExitSuccess
+}
+
+// forwardWindowSize forwards SIGWINCH received by the current process to the
+// foreground process of the terminal of process pid, which then gets the
+// window size of the attached terminal. Unlike Container.ForwardSignals, other
+// signals, such as SIGHUP when the terminal goes away, are not forwarded since
+// the process outlives its attached terminals. It returns a function that
+// stops forwarding.
+func forwardWindowSize(c *container.Container, pid int32) func() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, unix.SIGWINCH)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ch:
+				if err := c.Sandbox.SignalProcess(c.ID, pid, unix.SIGWINCH, true /* fgProcess */); err != nil {
+					log.Warningf("error forwarding SIGWINCH to container %q: %v", c.ID, err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
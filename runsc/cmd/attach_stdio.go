@@ -0,0 +1,148 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/subcommands"
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/runsc/boot"
+	"gvisor.dev/gvisor/runsc/cmd/util"
+	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/container"
+	"gvisor.dev/gvisor/runsc/flag"
+)
+
+// AttachStdio implements subcommands.Command for the "attach-stdio" command.
+type AttachStdio struct {
+	containerLoader
+	stdout      string
+	stderr      string
+	rotateSize  int64
+	rotateFiles int
+}
+
+// Name implements subcommands.Command.Name.
+func (*AttachStdio) Name() string {
+	return "attach-stdio"
+}
+
+// Synopsis implements subcommands.Command.Synopsis.
+func (*AttachStdio) Synopsis() string {
+	return "redirect stdout and stderr of a running container to new files"
+}
+
+// Usage implements subcommands.Command.Usage.
+func (*AttachStdio) Usage() string {
+	return `attach-stdio [flags] <container-id> - redirect stdout and stderr of a running container to new files
+
+The container's output must be relayed through the sandbox, which requires the
+sandbox to be started with --stdio-relay and the container to run without a
+terminal. Output written while no file is attached, e.g. after the previous
+file failed, is discarded.
+
+With --rotate-size, files are rotated when they reach the given size: "name"
+is renamed to "name.1", "name.1" to "name.2" and so on, up to --rotate-files
+files, and a new "name" is created. Rotation requires --directfs.
+
+EXAMPLE:
+       # runsc attach-stdio --stdout=/var/log/out.log --stderr=/var/log/err.log <container-id>
+       # runsc attach-stdio --stdout=/var/log/out.log --rotate-size=10485760 --rotate-files=5 <container-id>
+`
+}
+
+// SetFlags implements subcommands.Command.SetFlags.
+func (a *AttachStdio) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&a.stdout, "stdout", "", "file to append the container's stdout to")
+	f.StringVar(&a.stderr, "stderr", "", "file to append the container's stderr to")
+	f.Int64Var(&a.rotateSize, "rotate-size", 0, "size in bytes at which files are rotated (0 means no rotation)")
+	f.IntVar(&a.rotateFiles, "rotate-files", 5, "number of rotated files to keep")
+}
+
+// Execute implements subcommands.Command.Execute.
+func (a *AttachStdio) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
+	if f.NArg() != 1 {
+		f.Usage()
+		return subcommands.ExitUsageError
+	}
+	if a.stdout == "" && a.stderr == "" {
+		util.Fatalf("at least one of --stdout and --stderr must be set")
+	}
+	if a.rotateSize < 0 || a.rotateFiles < 0 {
+		util.Fatalf("--rotate-size and --rotate-files must not be negative")
+	}
+
+	conf := args[0].(*config.Config)
+	c, err := a.loadContainer(conf, f, container.LoadOpts{})
+	if err != nil {
+		util.Fatalf("loading container: %v", err)
+	}
+
+	attachArgs := boot.AttachStdioArgs{ContainerID: c.ID}
+	if a.rotateSize > 0 {
+		attachArgs.Rotation = &boot.StdioRotation{
+			MaxBytes: a.rotateSize,
+			MaxFiles: a.rotateFiles,
+		}
+	}
+	defer func() {
+		for _, f := range attachArgs.Files {
+			_ = f.Close()
+		}
+	}()
+	for stream, path := range []string{1: a.stdout, 2: a.stderr} {
+		if path == "" {
+			continue
+		}
+		if err := openStdioStream(&attachArgs, stream, path); err != nil {
+			util.Fatalf("%v", err)
+		}
+	}
+
+	if err := c.Sandbox.AttachStdio(&attachArgs); err != nil {
+		util.Fatalf("%v", err)
+	}
+	return subcommands.ExitSuccess
+}
+
+// openStdioStream opens the file that a stream is attached to and adds it to
+// args.
+func openStdioStream(args *boot.AttachStdioArgs, stream int, path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("opening %q: %w", path, err)
+	}
+	args.Streams = append(args.Streams, stream)
+	args.Files = append(args.Files, f)
+	if args.Rotation == nil {
+		return nil
+	}
+
+	dir, err := os.OpenFile(filepath.Dir(path), unix.O_RDONLY|unix.O_DIRECTORY, 0)
+	if err != nil {
+		return fmt.Errorf("opening directory of %q: %w", path, err)
+	}
+	args.Files = append(args.Files, dir)
+	args.Rotation.Names = append(args.Rotation.Names, filepath.Base(path))
+	return nil
+}
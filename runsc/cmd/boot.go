@@ -37,9 +37,11 @@ import (
 	"gvisor.dev/gvisor/pkg/metric"
 	"gvisor.dev/gvisor/pkg/prometheus"
 	"gvisor.dev/gvisor/pkg/ring0"
+	"gvisor.dev/gvisor/pkg/sentry/devices/hostdev"
 	"gvisor.dev/gvisor/pkg/sentry/devices/nvproxy/nvconf"
 	"gvisor.dev/gvisor/pkg/sentry/hostmm"
 	"gvisor.dev/gvisor/pkg/sentry/platform"
+	"gvisor.dev/gvisor/pkg/sentry/runtimestats"
 	"gvisor.dev/gvisor/pkg/sentry/syscalls/linux"
 	"gvisor.dev/gvisor/pkg/tcpip/nftables"
 	"gvisor.dev/gvisor/runsc/boot"
@@ -47,6 +49,7 @@ import (
 	"gvisor.dev/gvisor/runsc/cmd/util"
 	"gvisor.dev/gvisor/runsc/config"
 	"gvisor.dev/gvisor/runsc/flag"
+	"gvisor.dev/gvisor/runsc/goferauth"
 	"gvisor.dev/gvisor/runsc/profile"
 	"gvisor.dev/gvisor/runsc/specutils"
 )
@@ -92,6 +95,10 @@ type Boot struct {
 	// control server that is donated to this process.
 	controllerFD int
 
+	// controlGrantFDs are the file descriptors of the stream sockets for the
+	// grants of --control-policy, in the order of server.Policy.Grants.
+	controlGrantFDs sandboxsetup.IntFlags
+
 	// deviceFD is the file descriptor for the platform device file.
 	deviceFD int
 
@@ -101,6 +108,10 @@ type Boot struct {
 	// devIoFD is the FD to connect to dev gofer.
 	devIoFD int
 
+	// goferAuthKeyFD is the FD from which the key used to authenticate the
+	// root container's gofer connections is read.
+	goferAuthKeyFD int
+
 	// goferFilestoreFDs are FDs to the regular files that will back the tmpfs or
 	// overlayfs mount for certain gofer mounts.
 	goferFilestoreFDs sandboxsetup.IntFlags
@@ -142,6 +153,10 @@ type Boot struct {
 	// totalHostMem is the total memory reported by host /proc/meminfo.
 	totalHostMem uint64
 
+	// platformFallbacks is the number of preferred platforms that were
+	// unavailable when the sandbox was created.
+	platformFallbacks int
+
 	// userLogFD is the file descriptor to write user logs to.
 	userLogFD int
 
@@ -218,6 +233,10 @@ type Boot struct {
 
 	// rootfsUpperTarFD is the file descriptor to a tar file that has rootfs change at startup.
 	rootfsUpperTarFD int
+
+	// crashBundleFD is the file descriptor to write a crash bundle to when
+	// the sentry is about to crash.
+	crashBundleFD int
 }
 
 // Name implements subcommands.Command.Name.
@@ -253,6 +272,7 @@ func (b *Boot) SetFlags(f *flag.FlagSet) {
 	f.IntVar(&b.syncUsernsFD, "sync-userns-fd", -1, "file descriptor used to synchronize rootless user namespace initialization.")
 	f.Uint64Var(&b.totalMem, "total-memory", 0, "sets the initial amount of total memory to report back to the container")
 	f.Uint64Var(&b.totalHostMem, "total-host-memory", 0, "total memory reported by host /proc/meminfo")
+	f.IntVar(&b.platformFallbacks, "platform-fallbacks", 0, "number of preferred platforms that were unavailable when the sandbox was created")
 	f.BoolVar(&b.attached, "attached", false, "if attached is true, kills the sandbox process when the parent process terminates")
 	f.StringVar(&b.productName, "product-name", "", "value to show in /sys/devices/virtual/dmi/id/product_name")
 	f.StringVar(&b.hostTHP.ShmemEnabled, "host-thp-shmem-enabled", "", "value of /sys/kernel/mm/transparent_hugepage/shmem_enabled on the host")
@@ -263,9 +283,11 @@ func (b *Boot) SetFlags(f *flag.FlagSet) {
 	// Open FDs that are donated to the sandbox.
 	f.IntVar(&b.specFD, "spec-fd", -1, "required fd with the container spec")
 	f.IntVar(&b.controllerFD, "controller-fd", -1, "required FD of a stream socket for the control server that must be donated to this process")
+	f.Var(&b.controlGrantFDs, "control-grant-fds", "ordered list of FDs of stream sockets for the control server, one per grant of --control-policy")
 	f.IntVar(&b.deviceFD, "device-fd", -1, "FD for the platform device file")
 	f.Var(&b.ioFDs, "io-fds", "list of image FDs and/or socket FDs to connect gofer clients. They must follow this order: root first, then mounts as defined in the spec")
 	f.IntVar(&b.devIoFD, "dev-io-fd", -1, "FD to connect dev gofer client")
+	f.IntVar(&b.goferAuthKeyFD, "gofer-auth-key-fd", -1, "FD to read the key used to authenticate the root container's gofer connections from")
 	f.Var(&b.stdioFDs, "stdio-fds", "list of FDs containing sandbox stdin, stdout, and stderr in that order")
 	f.Var(&b.passFDs, "pass-fd", "mapping of host to guest FDs. They must be in M:N format. M is the host and N the guest descriptor.")
 	f.IntVar(&b.execFD, "exec-fd", -1, "host file descriptor used for program execution.")
@@ -283,6 +305,7 @@ func (b *Boot) SetFlags(f *flag.FlagSet) {
 	f.Var(&b.fsRestoreFDs, "fs-restore-fds", "ordered list of file descriptors for filesystem checkpoint restore")
 	f.BoolVar(&b.fsRestoreCheckpointGofer, "fs-restore-checkpoint-gofer", false, "if true, -fs-restore-fds is a socket connected to checkpoint gofer")
 	f.IntVar(&b.rootfsUpperTarFD, "rootfs-upper-tar-fd", -1, "file descriptor to the tar file containing the rootfs upper layer changes.")
+	f.IntVar(&b.crashBundleFD, "crash-bundle-fd", -1, "file descriptor to write a crash bundle to when the sentry is about to crash.")
 
 	// Profiling flags.
 	b.profileFDs.SetFromFlags(f)
@@ -516,17 +539,24 @@ func (b *Boot) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomma
 	// limit >= 0 works. If the limit is lower than the current number of open
 	// files, then Setrlimit will succeed, and the next open will fail.
 	if conf.FDLimit > -1 {
+		fdLimit := conf.FDLimit
+		if conf.HostDevices && fdLimit < hostdev.MinFDLimit {
+			log.Infof("Raising FD limit from %d to %d for proxied host devices", fdLimit, hostdev.MinFDLimit)
+			fdLimit = hostdev.MinFDLimit
+		}
 		rlimit := unix.Rlimit{
-			Cur: uint64(conf.FDLimit),
-			Max: uint64(conf.FDLimit),
+			Cur: uint64(fdLimit),
+			Max: uint64(fdLimit),
 		}
 		switch err := unix.Setrlimit(unix.RLIMIT_NOFILE, &rlimit); err {
 		case nil:
 		case unix.EPERM:
-			log.Warningf("FD limit %d is higher than the current hard limit or system-wide maximum", conf.FDLimit)
+			log.Warningf("FD limit %d is higher than the current hard limit or system-wide maximum", fdLimit)
 		default:
 			util.Fatalf("Failed to set RLIMIT_NOFILE: %v", err)
 		}
+	} else if conf.HostDevices {
+		raiseFDLimit(hostdev.MinFDLimit)
 	}
 
 	// When mountsFD is not provided, there is no cleaning required.
@@ -581,12 +611,21 @@ func (b *Boot) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomma
 
 	linux.SetAFSSyscallPanic(conf.TestOnlyAFSSyscallPanic)
 
+	var goferAuthKey goferauth.Key
+	if b.goferAuthKeyFD >= 0 {
+		goferAuthKey, err = goferauth.ReadKey(b.goferAuthKeyFD)
+		if err != nil {
+			util.Fatalf("%v", err)
+		}
+	}
+
 	// Create the loader.
 	bootArgs := boot.Args{
 		ID:                  f.Arg(0),
 		Spec:                spec,
 		Conf:                conf,
 		ControllerFD:        b.controllerFD,
+		ControlGrantFDs:     b.controlGrantFDs.GetArray(),
 		Device:              fd.New(b.deviceFD),
 		GoferFDs:            b.ioFDs.GetArray(),
 		DevGoferFD:          b.devIoFD,
@@ -595,11 +634,13 @@ func (b *Boot) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomma
 		ExecFD:              b.execFD,
 		GoferFilestoreFDs:   b.goferFilestoreFDs.GetArray(),
 		GoferMountConfs:     b.goferMountConfs.GetArray(),
+		GoferAuthKey:        goferAuthKey,
 		NumCPU:              b.cpuNum,
 		CPUQuota:            b.cpuQuota,
 		CPUPeriod:           b.cpuPeriod,
 		TotalMem:            b.totalMem,
 		TotalHostMem:        b.totalHostMem,
+		PlatformFallbacks:   b.platformFallbacks,
 		UserLogFD:           b.userLogFD,
 		ProductName:         b.productName,
 		PodInitConfigFD:     b.podInitConfigFD,
@@ -619,6 +660,7 @@ func (b *Boot) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomma
 		FSRestoreFDs:             b.fsRestoreFDs.GetFDs(),
 		FSRestoreCheckpointGofer: b.fsRestoreCheckpointGofer,
 		RootfsUpperTarFD:         b.rootfsUpperTarFD,
+		CrashBundleFD:            b.crashBundleFD,
 	}
 	l, err := boot.New(bootArgs)
 	if err != nil {
@@ -641,6 +683,7 @@ func (b *Boot) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomma
 	// This needs to happen after the kernel is initialized (such that all metrics are registered)
 	// but before the start-sync file is notified, as the parent process needs to query for
 	// registered metrics prior to sending the start signal.
+	runtimestats.RegisterMetrics()
 	metric.Initialize()
 	var finalMetricsFile *os.File
 	if b.finalMetricsFD != -1 {
@@ -762,3 +805,21 @@ func exportFinalMetrics(f *os.File) error {
 	}
 	return nil
 }
+
+// raiseFDLimit raises RLIMIT_NOFILE to at least limit, raising the hard limit
+// if needed and permitted.
+func raiseFDLimit(limit uint64) {
+	var rlimit unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_NOFILE, &rlimit); err != nil {
+		log.Warningf("Failed to get RLIMIT_NOFILE: %v", err)
+		return
+	}
+	if rlimit.Cur >= limit {
+		return
+	}
+	rlimit.Cur = limit
+	rlimit.Max = max(rlimit.Max, limit)
+	if err := unix.Setrlimit(unix.RLIMIT_NOFILE, &rlimit); err != nil {
+		log.Warningf("Failed to raise RLIMIT_NOFILE to %d, proxied host devices may fail to open: %v", limit, err)
+	}
+}
@@ -63,14 +63,16 @@ func (*Checkpoint) Synopsis() string {
 
 // Usage implements subcommands.Command.Usage.
 func (*Checkpoint) Usage() string {
-	return "checkpoint [flags] <container id> - save current state of container.\n"
+	return `checkpoint [flags] <container id> - save current state of container.
+checkpoint inspect [-json] <image path> - show checkpoint compatibility information, and whether it can be restored on this host.
+`
 }
 
 // SetFlags implements subcommands.Command.SetFlags.
 func (c *Checkpoint) SetFlags(f *flag.FlagSet) {
-	f.StringVar(&c.imagePath, "image-path", "", "directory path to saved container image")
+	f.StringVar(&c.imagePath, "image-path", "", "directory path to saved container image, or an HTTP(S) URL to which the image is streamed")
 	f.BoolVar(&c.leaveRunning, "leave-running", false, "restart the container after checkpointing")
-	f.Var(newCheckpointCompressionValue(statefile.CompressionLevelDefault, &c.compression), "compression", "compress checkpoint image on disk. Values: none|flate-best-speed.")
+	f.Var(newCheckpointCompressionValue(statefile.CompressionLevelDefault, &c.compression), "compression", "compress checkpoint image on disk. Values: none|flate-best-speed|zstd|zstd-N, where N is a zstd level between 1 and 22 (zstd uses level 3).")
 	f.BoolVar(&c.excludeCommittedZeroPages, "exclude-committed-zero-pages", false, "exclude committed zero-filled pages from checkpoint")
 	f.BoolVar(&c.direct, "direct", false, "use O_DIRECT for writing checkpoint pages file")
 	f.StringVar(&c.cudaCheckpointPath, "cuda-checkpoint-path", "", "path to the cuda-checkpoint binary in the container")
@@ -85,6 +87,10 @@ func (c *Checkpoint) SetFlags(f *flag.FlagSet) {
 
 // FetchSpec implements util.SubCommand.FetchSpec.
 func (c *Checkpoint) FetchSpec(conf *config.Config, f *flag.FlagSet) (string, *specs.Spec, error) {
+	if f.Arg(0) == checkpointInspectCommand {
+		// Inspecting a checkpoint image doesn't involve a container.
+		return "", nil, nil
+	}
 	cont, err := c.loadContainer(conf, f, container.LoadOpts{})
 	if err != nil {
 		return "", nil, fmt.Errorf("loading container: %w", err)
@@ -94,6 +100,9 @@ func (c *Checkpoint) FetchSpec(conf *config.Config, f *flag.FlagSet) (string, *s
 
 // Execute implements subcommands.Command.Execute.
 func (c *Checkpoint) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
+	if f.Arg(0) == checkpointInspectCommand {
+		return (&checkpointInspect{}).execute(args[0].(*config.Config), f.Args()[1:])
+	}
 	if f.NArg() != 1 {
 		f.Usage()
 		return subcommands.ExitUsageError
@@ -110,7 +119,10 @@ func (c *Checkpoint) Execute(_ context.Context, f *flag.FlagSet, args ...any) su
 		util.Fatalf("image-path flag must be provided")
 	}
 
-	if err := os.MkdirAll(c.imagePath, 0755); err != nil {
+	var imageURL string
+	if sandbox.IsImageURL(c.imagePath) {
+		imageURL = c.imagePath
+	} else if err := os.MkdirAll(c.imagePath, 0755); err != nil {
 		util.Fatalf("making directories at path provided: %v", err)
 	}
 
@@ -121,6 +133,7 @@ func (c *Checkpoint) Execute(_ context.Context, f *flag.FlagSet, args ...any) su
 		ExcludeCommittedZeroPages:  c.excludeCommittedZeroPages,
 		CudaCheckpointPath:         c.cudaCheckpointPath,
 		CudaCheckpointSequential:   c.cudaCheckpointSequential,
+		ImageURL:                   imageURL,
 		SaveRestoreExecArgv:        c.saveRestoreExecArgv,
 		SaveRestoreExecTimeout:     c.saveRestoreExecTimeout,
 		SaveRestoreExecContainerID: cont.ID,
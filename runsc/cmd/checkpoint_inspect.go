@@ -0,0 +1,178 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/google/subcommands"
+	"gvisor.dev/gvisor/pkg/cpuid"
+	"gvisor.dev/gvisor/pkg/sentry/devices/nvproxy"
+	"gvisor.dev/gvisor/pkg/sentry/state"
+	"gvisor.dev/gvisor/pkg/sentry/state/checkpointfiles"
+	"gvisor.dev/gvisor/pkg/state/statefile"
+	"gvisor.dev/gvisor/runsc/boot"
+	"gvisor.dev/gvisor/runsc/cmd/util"
+	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/flag"
+	"gvisor.dev/gvisor/runsc/sandbox"
+	"gvisor.dev/gvisor/runsc/version"
+)
+
+// checkpointInspectCommand is the argument to the "checkpoint" command that
+// selects the inspect subcommand.
+const checkpointInspectCommand = "inspect"
+
+// inspectedMetadataKeys are the save metadata keys that are printed by
+// "checkpoint inspect", in order.
+var inspectedMetadataKeys = []string{
+	boot.VersionKey,
+	state.SchemaVersionKey,
+	boot.ArchKey,
+	boot.PlatformKey,
+	boot.NvidiaDriverVersionKey,
+	boot.CPUFeaturesKey,
+	boot.PacketFilterKey,
+}
+
+// checkpointInspection is the result of "checkpoint inspect".
+type checkpointInspection struct {
+	// Metadata contains the checkpoint's compatibility metadata.
+	Metadata map[string]string `json:"metadata"`
+
+	// Issues are the differences between the checkpoint and this host.
+	Issues []boot.CompatibilityIssue `json:"issues"`
+
+	// Restorable is true if none of Issues are fatal.
+	Restorable bool `json:"restorable"`
+}
+
+// checkpointInspect implements "checkpoint inspect", which reports whether a
+// checkpoint image can be restored on this host without attempting a restore.
+type checkpointInspect struct {
+	json bool
+}
+
+// execute runs the inspect subcommand with the given arguments, which follow
+// "inspect" on the command line.
+func (ci *checkpointInspect) execute(conf *config.Config, args []string) subcommands.ExitStatus {
+	f := flag.NewFlagSet("checkpoint inspect", flag.ContinueOnError)
+	f.BoolVar(&ci.json, "json", false, "print the result as JSON")
+	if err := f.Parse(args); err != nil {
+		return subcommands.ExitUsageError
+	}
+	if f.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: checkpoint inspect [-json] <image path>\n")
+		return subcommands.ExitUsageError
+	}
+
+	path := f.Arg(0)
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		path = filepath.Join(path, checkpointfiles.StateFileName)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		util.Fatalf("opening checkpoint image: %v", err)
+	}
+	defer file.Close()
+	metadata, err := statefile.MetadataUnsafe(file)
+	if err != nil {
+		util.Fatalf("reading checkpoint metadata: %v", err)
+	}
+
+	if _, err := sandbox.SelectPlatform(conf); err != nil {
+		util.Fatalf("selecting platform: %v", err)
+	}
+	res := checkpointInspection{
+		Metadata:   make(map[string]string),
+		Issues:     boot.CheckRestoreCompatibility(metadata, restoreHost(conf, metadata)),
+		Restorable: true,
+	}
+	for _, key := range inspectedMetadataKeys {
+		if v, ok := metadata[key]; ok {
+			res.Metadata[key] = v
+		}
+	}
+	for i := range res.Issues {
+		if res.Issues[i].Fatal {
+			res.Restorable = false
+		}
+	}
+
+	if ci.json {
+		if err := json.NewEncoder(os.Stdout).Encode(&res); err != nil {
+			util.Fatalf("encoding result: %v", err)
+		}
+	} else {
+		printCheckpointInspection(os.Stdout, &res)
+	}
+	if !res.Restorable {
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
+
+// restoreHost returns a description of this host, for restoring the checkpoint
+// with the given save metadata using conf.
+func restoreHost(conf *config.Config, metadata map[string]string) *boot.RestoreHost {
+	cpuid.Initialize()
+	host := &boot.RestoreHost{
+		RunscVersion: version.Version(),
+		Arch:         runtime.GOARCH,
+		Platform:     conf.Platform,
+		CPUFeatures:  cpuid.HostFeatureSet(),
+		PacketFilter: conf.PacketFilter,
+	}
+	if _, ok := metadata[boot.NvidiaDriverVersionKey]; ok {
+		if v, err := nvproxy.HostDriverVersion(); err == nil {
+			host.NvidiaDriverVersion = v
+		}
+	}
+	return host
+}
+
+// printCheckpointInspection prints res in human-readable form.
+func printCheckpointInspection(w io.Writer, res *checkpointInspection) {
+	fmt.Fprintf(w, "Checkpoint:\n")
+	for _, key := range inspectedMetadataKeys {
+		v, ok := res.Metadata[key]
+		if !ok {
+			v = "(not recorded)"
+		}
+		fmt.Fprintf(w, "  %s: %s\n", key, v)
+	}
+	if len(res.Issues) == 0 {
+		fmt.Fprintf(w, "No differences from this host.\n")
+	} else {
+		fmt.Fprintf(w, "Differences from this host:\n")
+		for i := range res.Issues {
+			severity := "WARNING"
+			if res.Issues[i].Fatal {
+				severity = "FATAL"
+			}
+			fmt.Fprintf(w, "  [%s] %s\n", severity, res.Issues[i].String())
+		}
+	}
+	if res.Restorable {
+		fmt.Fprintf(w, "Restore on this host: compatible\n")
+	} else {
+		fmt.Fprintf(w, "Restore on this host: incompatible\n")
+	}
+}
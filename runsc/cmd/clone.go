@@ -0,0 +1,125 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/google/subcommands"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/runsc/cmd/util"
+	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/container"
+	"gvisor.dev/gvisor/runsc/flag"
+)
+
+// Clone implements subcommands.Command for the "clone" command.
+type Clone struct {
+	containerLoader
+	imagePath string
+	count     int
+	netns     stringSlice
+}
+
+// Name implements subcommands.Command.Name.
+func (*Clone) Name() string {
+	return "clone"
+}
+
+// Synopsis implements subcommands.Command.Synopsis.
+func (*Clone) Synopsis() string {
+	return "clone a sandbox into new sandboxes sharing its checkpoint image (experimental)"
+}
+
+// Usage implements subcommands.Command.Usage.
+func (*Clone) Usage() string {
+	return `clone --image-path=<path> [flags] <container id> [<clone id>...] - clone a
+running or paused sandbox.
+
+The sandbox is checkpointed to --image-path and left in its current state, and
+a new sandbox is restored from the checkpoint for each clone. Clones copy
+memory pages from the checkpoint when they first access them, so each clone
+only allocates memory for the pages it uses. The image must not be modified or
+removed while any clone is running.
+
+The container must be the only container of its sandbox. If no clone IDs are
+given, --count clones are created with generated IDs. The IDs of the clones
+are printed.
+
+With --network=sandbox, each clone copies the network configuration of the
+network namespace that it joins. If the container's spec names a network
+namespace, --netns must be passed once per clone to give clones network
+identities distinct from the sandbox and each other.
+`
+}
+
+// SetFlags implements subcommands.Command.SetFlags.
+func (c *Clone) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&c.imagePath, "image-path", "", "directory path to which the sandbox is checkpointed, and from which clones are restored")
+	f.IntVar(&c.count, "count", 1, "number of clones to create if no clone IDs are given")
+	f.Var(&c.netns, "netns", "path to the network namespace of a clone. Can be repeated, once for each clone")
+}
+
+// FetchSpec implements util.SubCommand.FetchSpec.
+func (c *Clone) FetchSpec(conf *config.Config, f *flag.FlagSet) (string, *specs.Spec, error) {
+	cont, err := c.loadContainer(conf, f, container.LoadOpts{})
+	if err != nil {
+		return "", nil, fmt.Errorf("loading container: %w", err)
+	}
+	return cont.ID, cont.Spec, nil
+}
+
+// Execute implements subcommands.Command.Execute.
+func (c *Clone) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
+	if f.NArg() < 1 || c.imagePath == "" {
+		f.Usage()
+		return subcommands.ExitUsageError
+	}
+
+	conf := args[0].(*config.Config)
+	if conf.Rootless {
+		return util.Errorf("Rootless mode not supported with %q", c.Name())
+	}
+
+	cont, err := c.loadContainer(conf, f, container.LoadOpts{})
+	if err != nil {
+		util.Fatalf("loading container: %v", err)
+	}
+
+	ids := f.Args()[1:]
+	if len(ids) == 0 {
+		if c.count < 1 {
+			util.Fatalf("--count must be positive")
+		}
+		if ids, err = container.NewCloneIDs(cont.ID, c.count); err != nil {
+			util.Fatalf("generating clone IDs: %v", err)
+		}
+	}
+
+	clones, err := cont.Clone(conf, container.CloneArgs{
+		ImagePath: c.imagePath,
+		IDs:       ids,
+		NetNS:     c.netns,
+	})
+	for _, clone := range clones {
+		fmt.Fprintln(os.Stdout, clone.ID)
+	}
+	if err != nil {
+		util.Fatalf("clone failed: %v", err)
+	}
+	return subcommands.ExitSuccess
+}
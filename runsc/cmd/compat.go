@@ -0,0 +1,336 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/subcommands"
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/runsc/cmd/util"
+	"gvisor.dev/gvisor/runsc/flag"
+)
+
+// Compat implements subcommands.Command for the "compat" command. It runs a
+// workload the same way as "runsc do" and reports every unsupported syscall,
+// ioctl and socket option that the workload hit.
+type Compat struct {
+	Do
+
+	report bool
+	output string
+}
+
+// Name implements subcommands.Command.Name.
+func (*Compat) Name() string {
+	return "compat"
+}
+
+// Synopsis implements subcommands.Command.Synopsis.
+func (*Compat) Synopsis() string {
+	return "run a command inside the sandbox and report unsupported syscalls it used"
+}
+
+// Usage implements subcommands.Command.Usage.
+func (*Compat) Usage() string {
+	return `compat [flags] <cmd> - runs a command and reports compatibility issues.
+
+This command runs the given command like "runsc do" and records every
+unsupported syscall, ioctl and socket option that the command invoked. Once the
+command exits, a summary is printed along with flags that may help the workload
+run. Use --report to get the summary in JSON format.
+`
+}
+
+// SetFlags implements subcommands.Command.SetFlags.
+func (c *Compat) SetFlags(f *flag.FlagSet) {
+	c.Do.SetFlags(f)
+	f.BoolVar(&c.report, "report", false, "emit a machine-readable compatibility report in JSON format")
+	f.StringVar(&c.output, "output", "", "file to write the report to, defaults to stdout")
+}
+
+// Execute implements subcommands.Command.Execute.
+func (c *Compat) Execute(ctx context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
+	if len(f.Args()) == 0 {
+		f.Usage()
+		return subcommands.ExitUsageError
+	}
+	waitStatus := args[1].(*unix.WaitStatus)
+
+	tmpDir, err := os.MkdirTemp("", "runsc-compat")
+	if err != nil {
+		return util.Errorf("Error to create tmp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	c.userLog = filepath.Join(tmpDir, "user.log")
+
+	if ret := c.Do.Execute(ctx, f, args...); ret != subcommands.ExitSuccess {
+		return ret
+	}
+
+	entries := []compatEntry{}
+	logFile, err := os.Open(c.userLog)
+	switch {
+	case err == nil:
+		defer logFile.Close()
+		entries, err = parseCompatLog(logFile)
+		if err != nil {
+			return util.Errorf("parsing user log: %v", err)
+		}
+	case !os.IsNotExist(err):
+		return util.Errorf("opening user log: %v", err)
+	}
+	report := compatReport{
+		Command:     f.Args(),
+		ExitStatus:  waitStatus.ExitStatus(),
+		Unsupported: entries,
+		Suggestions: suggestFlags(entries),
+	}
+
+	out := os.Stdout
+	if c.output != "" {
+		file, err := os.Create(c.output)
+		if err != nil {
+			return util.Errorf("creating report file: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if c.report {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(&report); err != nil {
+			return util.Errorf("writing report: %v", err)
+		}
+	} else {
+		report.writeSummary(out)
+	}
+	return subcommands.ExitSuccess
+}
+
+// compatReport is the report generated by "runsc compat".
+type compatReport struct {
+	// Command is the command that was executed.
+	Command []string `json:"command"`
+
+	// ExitStatus is the exit status of the command.
+	ExitStatus int `json:"exit_status"`
+
+	// Unsupported lists the unsupported syscalls the command invoked.
+	Unsupported []compatEntry `json:"unsupported"`
+
+	// Suggestions lists flags that may improve compatibility.
+	Suggestions []compatSuggestion `json:"suggested_flags,omitempty"`
+}
+
+// compatEntry is a single unsupported syscall hit. Syscalls that multiplex
+// several operations, e.g. ioctl, are reported once per operation.
+type compatEntry struct {
+	// Syscall is the syscall name.
+	Syscall string `json:"syscall"`
+
+	// Kind is one of "syscall", "ioctl" or "sockopt".
+	Kind string `json:"kind"`
+
+	// Detail contains the arguments identifying the operation, e.g. the
+	// ioctl command. It's empty for syscalls that aren't multiplexed.
+	Detail map[string]string `json:"detail,omitempty"`
+
+	// Args are the raw syscall arguments of the first occurrence.
+	Args []uint64 `json:"args"`
+
+	// Link points to the syscall documentation.
+	Link string `json:"link,omitempty"`
+}
+
+// compatSuggestion is a runsc flag that may help the workload run.
+type compatSuggestion struct {
+	Flag    string   `json:"flag"`
+	Reason  string   `json:"reason"`
+	Syscall []string `json:"syscalls"`
+}
+
+// compatArg names a syscall argument that identifies an operation.
+type compatArg struct {
+	name string
+	idx  int
+}
+
+// compatDetails maps syscalls to the arguments used to tell operations
+// apart. Keep in sync with the trackers in runsc/boot/compat.go.
+var compatDetails = map[string][]compatArg{
+	"arch_prctl": {{"cmd", 0}},
+	"prctl":      {{"option", 0}},
+	"ioctl":      {{"cmd", 1}},
+	"epoll_ctl":  {{"op", 1}},
+	"shmctl":     {{"cmd", 1}},
+	"futex":      {{"op", 1}},
+	"fallocate":  {{"mode", 1}},
+	"getsockopt": {{"level", 1}, {"name", 2}},
+	"setsockopt": {{"level", 1}, {"name", 2}},
+	"semctl":     {{"cmd", 2}},
+}
+
+var (
+	unsupportedRE = regexp.MustCompile(`Unsupported syscall ([a-z0-9_]+)\(([^)]*)\)`)
+	linkRE        = regexp.MustCompile(`refer to (\S+) for more information`)
+)
+
+// parseCompatLog parses a user log written in K8s JSON format and returns all
+// unsupported syscalls found in it, sorted by name.
+func parseCompatLog(r io.Reader) ([]compatEntry, error) {
+	entries := []compatEntry{}
+	seen := make(map[string]struct{})
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		var j struct {
+			Log string `json:"log"`
+		}
+		if err := json.Unmarshal([]byte(line), &j); err == nil {
+			line = j.Log
+		}
+		entry, ok := parseUnsupportedSyscall(line)
+		if !ok {
+			continue
+		}
+		key := entry.Syscall
+		for _, arg := range compatDetails[entry.Syscall] {
+			key += "|" + entry.Detail[arg.name]
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Syscall < entries[j].Syscall
+	})
+	return entries, nil
+}
+
+func parseUnsupportedSyscall(line string) (compatEntry, bool) {
+	m := unsupportedRE.FindStringSubmatch(line)
+	if m == nil {
+		return compatEntry{}, false
+	}
+	entry := compatEntry{Syscall: m[1], Kind: "syscall"}
+	for _, a := range strings.Split(m[2], ",") {
+		v, err := strconv.ParseUint(strings.TrimSpace(a), 0, 64)
+		if err != nil {
+			return compatEntry{}, false
+		}
+		entry.Args = append(entry.Args, v)
+	}
+	if l := linkRE.FindStringSubmatch(line); l != nil {
+		entry.Link = l[1]
+	}
+
+	switch entry.Syscall {
+	case "ioctl":
+		entry.Kind = "ioctl"
+	case "getsockopt", "setsockopt":
+		entry.Kind = "sockopt"
+	}
+	for _, arg := range compatDetails[entry.Syscall] {
+		if arg.idx >= len(entry.Args) {
+			continue
+		}
+		if entry.Detail == nil {
+			entry.Detail = make(map[string]string)
+		}
+		entry.Detail[arg.name] = fmt.Sprintf("%#x", entry.Args[arg.idx])
+	}
+	return entry, true
+}
+
+// nvidiaIoctlType is the ioctl type used by the NVIDIA driver, see
+// pkg/abi/nvgpu.
+const nvidiaIoctlType = 'F'
+
+// suggestFlags returns flags that may help with the given unsupported
+// syscalls.
+func suggestFlags(entries []compatEntry) []compatSuggestion {
+	var suggestions []compatSuggestion
+	add := func(flag, reason, syscall string) {
+		for i := range suggestions {
+			if suggestions[i].Flag == flag {
+				for _, s := range suggestions[i].Syscall {
+					if s == syscall {
+						return
+					}
+				}
+				suggestions[i].Syscall = append(suggestions[i].Syscall, syscall)
+				return
+			}
+		}
+		suggestions = append(suggestions, compatSuggestion{Flag: flag, Reason: reason, Syscall: []string{syscall}})
+	}
+
+	for _, e := range entries {
+		switch e.Syscall {
+		case "io_uring_setup", "io_uring_enter", "io_uring_register":
+			add("--iouring", "enables experimental IO_URING support", e.Syscall)
+		case "getsockopt", "setsockopt":
+			add("--network=host", "host networking passes most socket options through to the host", e.Syscall)
+		case "ioctl":
+			if len(e.Args) > 1 && (e.Args[1]>>8)&0xff == nvidiaIoctlType {
+				add("--nvproxy", "enables support for NVIDIA GPU devices", e.Syscall)
+			}
+		}
+	}
+	return suggestions
+}
+
+func (r *compatReport) writeSummary(w io.Writer) {
+	if len(r.Unsupported) == 0 {
+		fmt.Fprintf(w, "No unsupported syscalls found.\n")
+		return
+	}
+	fmt.Fprintf(w, "Unsupported syscalls:\n")
+	for _, e := range r.Unsupported {
+		var details []string
+		for _, arg := range compatDetails[e.Syscall] {
+			if v, ok := e.Detail[arg.name]; ok {
+				details = append(details, arg.name+"="+v)
+			}
+		}
+		if len(details) > 0 {
+			fmt.Fprintf(w, "  %s(%s)\n", e.Syscall, strings.Join(details, ", "))
+		} else {
+			fmt.Fprintf(w, "  %s\n", e.Syscall)
+		}
+	}
+	if len(r.Suggestions) > 0 {
+		fmt.Fprintf(w, "\nSuggested flags:\n")
+		for _, s := range r.Suggestions {
+			fmt.Fprintf(w, "  %s: %s (%s)\n", s.Flag, s.Reason, strings.Join(s.Syscall, ", "))
+		}
+	}
+}
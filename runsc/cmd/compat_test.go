@@ -0,0 +1,86 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+const compatTestLog = `{"log":"compat.go:120] Unsupported syscall ioctl(0x3,0x5401,0x7ffd,0x0,0x0,0x0). It is likely that you can safely ignore this message and that this is not the cause of any error. Please, refer to https://gvisor.dev/c/linux/amd64/ioctl for more information.","level":"info","time":"2026-01-01T00:00:00Z"}
+{"log":"compat.go:120] Unsupported syscall ioctl(0x4,0x5401,0x7ffd,0x0,0x0,0x0). It is likely that you can safely ignore this message and that this is not the cause of any error. Please, refer to https://gvisor.dev/c/linux/amd64/ioctl for more information.","level":"info","time":"2026-01-01T00:00:00Z"}
+{"log":"compat.go:120] Unsupported syscall ioctl(0x5,0xc020462a,0x7ffd,0x0,0x0,0x0). It is likely that you can safely ignore this message and that this is not the cause of any error. Please, refer to https://gvisor.dev/c/linux/amd64/ioctl for more information.","level":"info","time":"2026-01-01T00:00:00Z"}
+{"log":"compat.go:120] Unsupported syscall setsockopt(0x3,0x6,0x1f,0x7ffd,0x4,0x0). It is likely that you can safely ignore this message and that this is not the cause of any error. Please, refer to https://gvisor.dev/c/linux/amd64/setsockopt for more information.","level":"info","time":"2026-01-01T00:00:00Z"}
+{"log":"compat.go:120] Unsupported syscall io_uring_setup(0x8,0x7ffd,0x0,0x0,0x0,0x0). It is likely that you can safely ignore this message and that this is not the cause of any error. Please, refer to https://gvisor.dev/c/linux/amd64/io_uring_setup for more information.","level":"info","time":"2026-01-01T00:00:00Z"}
+{"log":"boot.go:10] Some other message","level":"info","time":"2026-01-01T00:00:00Z"}
+`
+
+func TestParseCompatLog(t *testing.T) {
+	entries, err := parseCompatLog(strings.NewReader(compatTestLog))
+	if err != nil {
+		t.Fatalf("parseCompatLog failed: %v", err)
+	}
+	want := []struct {
+		syscall string
+		kind    string
+		detail  string
+	}{
+		{"io_uring_setup", "syscall", ""},
+		{"ioctl", "ioctl", "0x5401"},
+		{"ioctl", "ioctl", "0xc020462a"},
+		{"setsockopt", "sockopt", "0x6"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Syscall != w.syscall || e.Kind != w.kind {
+			t.Errorf("entry %d: got %s/%s, want %s/%s", i, e.Syscall, e.Kind, w.syscall, w.kind)
+		}
+		var got string
+		switch e.Kind {
+		case "ioctl":
+			got = e.Detail["cmd"]
+		case "sockopt":
+			got = e.Detail["level"]
+		}
+		if got != w.detail {
+			t.Errorf("entry %d: got detail %q, want %q", i, got, w.detail)
+		}
+		if len(e.Args) != 6 {
+			t.Errorf("entry %d: got %d args, want 6", i, len(e.Args))
+		}
+		if want := "https://gvisor.dev/c/linux/amd64/" + w.syscall; e.Link != want {
+			t.Errorf("entry %d: got link %q, want %q", i, e.Link, want)
+		}
+	}
+}
+
+func TestSuggestFlags(t *testing.T) {
+	entries, err := parseCompatLog(strings.NewReader(compatTestLog))
+	if err != nil {
+		t.Fatalf("parseCompatLog failed: %v", err)
+	}
+	got := make(map[string]bool)
+	for _, s := range suggestFlags(entries) {
+		got[s.Flag] = true
+	}
+	for _, flag := range []string{"--iouring", "--network=host", "--nvproxy"} {
+		if !got[flag] {
+			t.Errorf("flag %q not suggested, got: %v", flag, got)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/subcommands"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/control"
+	"gvisor.dev/gvisor/runsc/cmd/util"
+	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/container"
+	"gvisor.dev/gvisor/runsc/flag"
+)
+
+// Cp implements subcommands.Command for the "cp" command.
+type Cp struct{}
+
+// Name implements subcommands.Command.Name.
+func (*Cp) Name() string {
+	return "cp"
+}
+
+// Synopsis implements subcommands.Command.Synopsis.
+func (*Cp) Synopsis() string {
+	return "copy files and directories between a running container and the host"
+}
+
+// Usage implements subcommands.Command.Usage.
+func (*Cp) Usage() string {
+	return `cp <container-id>:<path> <host-path>
+cp <host-path> <container-id>:<path>
+
+Copies a file or directory tree out of or into a running container. Files in
+the container are accessed through the sandbox's filesystem, so the copy has
+the contents seen by the container, including files in overlays and tmpfs
+mounts that don't exist on the host. <path> must be absolute.
+
+If the destination is an existing directory, the source is copied into it.
+Otherwise, the source is copied as the destination, whose parent directory
+must exist. Regular files, directories and symlinks are copied along with
+their mode and modification time. Other file types are skipped. Ownership is
+kept when copying into the container.
+
+EXAMPLE:
+       # runsc cp <container-id>:/var/log/app /tmp/app-logs
+       # runsc cp ./config.yaml <container-id>:/etc/app/
+`
+}
+
+// SetFlags implements subcommands.Command.SetFlags.
+func (*Cp) SetFlags(*flag.FlagSet) {}
+
+// FetchSpec implements util.SubCommand.FetchSpec.
+func (*Cp) FetchSpec(conf *config.Config, f *flag.FlagSet) (string, *specs.Spec, error) {
+	if f.NArg() != 2 {
+		return "", nil, nil
+	}
+	id, _, _, err := parseCpArgs(f.Arg(0), f.Arg(1))
+	if err != nil {
+		return "", nil, err
+	}
+	c, err := container.Load(conf.RootDir, container.FullID{ContainerID: id}, container.LoadOpts{SkipCheck: true})
+	if err != nil {
+		return "", nil, fmt.Errorf("loading container: %w", err)
+	}
+	return c.ID, c.Spec, nil
+}
+
+// Execute implements subcommands.Command.Execute.
+func (*Cp) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
+	if f.NArg() != 2 {
+		f.Usage()
+		return subcommands.ExitUsageError
+	}
+	id, containerPath, copyOut, err := parseCpArgs(f.Arg(0), f.Arg(1))
+	if err != nil {
+		util.Fatalf("%v", err)
+	}
+	hostPath := f.Arg(0)
+	if copyOut {
+		hostPath = f.Arg(1)
+	}
+
+	conf := args[0].(*config.Config)
+	c, err := container.Load(conf.RootDir, container.FullID{ContainerID: id}, container.LoadOpts{})
+	if err != nil {
+		util.Fatalf("loading container: %v", err)
+	}
+	if !c.IsSandboxRunning() {
+		util.Fatalf("sandbox of container %q is not running", c.ID)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		util.Fatalf("creating pipe: %v", err)
+	}
+	if copyOut {
+		done := make(chan error, 1)
+		go func() {
+			err := c.Sandbox.CopyOut(c.ID, containerPath, w)
+			w.Close()
+			done <- err
+		}()
+		extractErr := extractCpArchive(r, hostPath)
+		// Unblock the sandbox if extraction stopped early.
+		r.Close()
+		if err := <-done; err != nil {
+			util.Fatalf("%v", err)
+		}
+		if extractErr != nil {
+			util.Fatalf("copying to %q: %v", hostPath, extractErr)
+		}
+		return subcommands.ExitSuccess
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		err := writeCpArchive(w, hostPath)
+		w.Close()
+		done <- err
+	}()
+	copyErr := c.Sandbox.CopyIn(c.ID, containerPath, r)
+	// Unblock the archive writer if the sandbox stopped reading early.
+	r.Close()
+	if err := <-done; err != nil {
+		util.Fatalf("copying %q: %v", hostPath, err)
+	}
+	if copyErr != nil {
+		util.Fatalf("%v", copyErr)
+	}
+	return subcommands.ExitSuccess
+}
+
+// parseCpArgs returns the container ID and path of the container side of a
+// copy from src to dst, and whether the copy is out of the container.
+func parseCpArgs(src, dst string) (string, string, bool, error) {
+	srcID, srcPath, srcOK := splitCpContainerPath(src)
+	dstID, dstPath, dstOK := splitCpContainerPath(dst)
+	switch {
+	case srcOK && dstOK:
+		return "", "", false, fmt.Errorf("copying between containers is not supported")
+	case srcOK:
+		return srcID, srcPath, true, nil
+	case dstOK:
+		return dstID, dstPath, false, nil
+	default:
+		return "", "", false, fmt.Errorf("one of the paths must be <container-id>:<path>")
+	}
+}
+
+// splitCpContainerPath splits a <container-id>:<path> argument. It returns
+// false if arg is a host path.
+func splitCpContainerPath(arg string) (string, string, bool) {
+	if strings.HasPrefix(arg, "/") || strings.HasPrefix(arg, ".") {
+		return "", "", false
+	}
+	id, p, ok := strings.Cut(arg, ":")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		return "", "", false
+	}
+	return id, p, true
+}
+
+// writeCpArchive writes a tar archive of the host file or directory tree at
+// src to w, with entries named relative to the parent of src.
+func writeCpArchive(w io.Writer, src string) error {
+	tw := tar.NewWriter(w)
+	src = filepath.Clean(src)
+	base := filepath.Base(src)
+	if err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		var link string
+		switch {
+		case info.Mode().IsDir(), info.Mode().IsRegular():
+		case info.Mode()&fs.ModeSymlink != 0:
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		default:
+			log.Warningf("Not copying %q: unsupported file type %v", p, info.Mode().Type())
+			return nil
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = path.Join(base, filepath.ToSlash(rel))
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.CopyN(tw, f, hdr.Size)
+		return err
+	}); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// extractCpArchive extracts a tar archive written by the sandbox to dst on
+// the host. The archive isn't trusted: files are only created beneath the
+// destination directory, and symlinks that escape it are not followed.
+func extractCpArchive(r io.Reader, dst string) error {
+	dir, rename := dst, ""
+	if fi, err := os.Stat(dst); err != nil || !fi.IsDir() {
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		dir, rename = filepath.Dir(dst), filepath.Base(dst)
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading archive: %w", err)
+		}
+		name, err := control.CopyEntryName(hdr.Name, rename)
+		if err != nil {
+			return err
+		}
+		mode := fs.FileMode(hdr.Mode) & fs.ModePerm
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := root.Mkdir(name, mode); err != nil && !errors.Is(err, fs.ErrExist) {
+				return err
+			}
+		case tar.TypeReg:
+			f, err := root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := root.Symlink(hdr.Linkname, name); err != nil {
+				return err
+			}
+			continue
+		default:
+			log.Warningf("Not copying %q: unsupported tar entry type %q", hdr.Name, hdr.Typeflag)
+			continue
+		}
+		if err := root.Chmod(name, mode); err != nil {
+			return err
+		}
+		if err := root.Chtimes(name, hdr.ModTime, hdr.ModTime); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCpArgs(t *testing.T) {
+	for _, tc := range []struct {
+		src, dst string
+		id, path string
+		copyOut  bool
+		wantErr  bool
+	}{
+		{src: "cid:/etc/hosts", dst: "/tmp/hosts", id: "cid", path: "/etc/hosts", copyOut: true},
+		{src: "./file", dst: "cid:/tmp/", id: "cid", path: "/tmp/"},
+		{src: "/a:b", dst: "cid:/tmp", id: "cid", path: "/tmp"},
+		{src: "dir/a:b", dst: "cid:/tmp", id: "cid", path: "/tmp"},
+		{src: "/tmp/a", dst: "/tmp/b", wantErr: true},
+		{src: "c1:/a", dst: "c2:/b", wantErr: true},
+	} {
+		id, path, copyOut, err := parseCpArgs(tc.src, tc.dst)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("parseCpArgs(%q, %q) succeeded, want error", tc.src, tc.dst)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseCpArgs(%q, %q) failed: %v", tc.src, tc.dst, err)
+			continue
+		}
+		if id != tc.id || path != tc.path || copyOut != tc.copyOut {
+			t.Errorf("parseCpArgs(%q, %q) = %q, %q, %t, want %q, %q, %t", tc.src, tc.dst, id, path, copyOut, tc.id, tc.path, tc.copyOut)
+		}
+	}
+}
+
+func TestCpArchiveRoundTrip(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sub", "file"), []byte("hello"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("sub/file", filepath.Join(src, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeCpArchive(&buf, src); err != nil {
+		t.Fatalf("writeCpArchive: %v", err)
+	}
+
+	// Copy into an existing directory.
+	into := t.TempDir()
+	if err := extractCpArchive(bytes.NewReader(buf.Bytes()), into); err != nil {
+		t.Fatalf("extractCpArchive: %v", err)
+	}
+	if got, err := os.ReadFile(filepath.Join(into, "src", "sub", "file")); err != nil || string(got) != "hello" {
+		t.Errorf("copied file = %q, %v, want %q", got, err, "hello")
+	}
+	if fi, err := os.Stat(filepath.Join(into, "src", "sub", "file")); err != nil || fi.Mode().Perm() != 0640 {
+		t.Errorf("copied file mode = %v, %v, want 0640", fi.Mode().Perm(), err)
+	}
+	if got, err := os.Readlink(filepath.Join(into, "src", "link")); err != nil || got != "sub/file" {
+		t.Errorf("copied symlink = %q, %v, want %q", got, err, "sub/file")
+	}
+
+	// Copy as a new name.
+	as := filepath.Join(t.TempDir(), "renamed")
+	if err := extractCpArchive(bytes.NewReader(buf.Bytes()), as); err != nil {
+		t.Fatalf("extractCpArchive: %v", err)
+	}
+	if got, err := os.ReadFile(filepath.Join(as, "sub", "file")); err != nil || string(got) != "hello" {
+		t.Errorf("copied file = %q, %v, want %q", got, err, "hello")
+	}
+}
+
+func TestCpArchiveEscape(t *testing.T) {
+	outside := t.TempDir()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, hdr := range []*tar.Header{
+		{Name: "d/", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "d/link", Typeflag: tar.TypeSymlink, Linkname: outside},
+		{Name: "d/link/file", Typeflag: tar.TypeReg, Mode: 0644},
+	} {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := extractCpArchive(&buf, t.TempDir()); err == nil {
+		t.Errorf("extractCpArchive succeeded, want error writing through a symlink that escapes the destination")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "file")); err == nil {
+		t.Errorf("file was created outside of the destination")
+	}
+}
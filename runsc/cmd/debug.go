@@ -16,6 +16,7 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
@@ -45,6 +46,8 @@ type Debug struct {
 	profileCPU   string
 	profileHeap  string
 	profileMutex string
+	lockGraph    string
+	lockStats    string
 	trace        string
 	strace       string
 	logLevel     string
@@ -52,7 +55,14 @@ type Debug struct {
 	delay        time.Duration
 	duration     time.Duration
 	ps           bool
+	runtimeStats bool
+	networkDiag  bool
+	controlAPI   bool
+	sysctls      bool
 	mount        string
+
+	rootfsUpperUsage bool
+	rootfsUpperTop   int
 }
 
 // Name implements subcommands.Command.
@@ -78,6 +88,8 @@ func (d *Debug) SetFlags(f *flag.FlagSet) {
 	f.StringVar(&d.profileCPU, "profile-cpu", "", "writes CPU profile to the given file.")
 	f.StringVar(&d.profileHeap, "profile-heap", "", "writes heap profile to the given file.")
 	f.StringVar(&d.profileMutex, "profile-mutex", "", "writes mutex profile to the given file.")
+	f.StringVar(&d.lockGraph, "lock-graph", "", "writes the lock ordering graph in DOT format to the given file. Requires a runsc binary built with the lockdep tag.")
+	f.StringVar(&d.lockStats, "lock-contention", "", "writes lock contention statistics collected over --duration to the given file. Requires a runsc binary built with the lockdep tag.")
 	f.DurationVar(&d.delay, "delay", 0, "amount of time to delay for collecting heap and goroutine profiles.")
 	f.DurationVar(&d.duration, "duration", time.Minute, "amount of time to wait for CPU and trace profiles.")
 	f.StringVar(&d.trace, "trace", "", "writes an execution trace to the given file.")
@@ -86,7 +98,13 @@ func (d *Debug) SetFlags(f *flag.FlagSet) {
 	f.StringVar(&d.logLevel, "log-level", "", "The log level to set: warning (0), info (1), or debug (2).")
 	f.StringVar(&d.logPackets, "log-packets", "", "A boolean value to enable or disable packet logging: true or false.")
 	f.BoolVar(&d.ps, "ps", false, "lists processes")
+	f.BoolVar(&d.runtimeStats, "runtime-stats", false, "prints Go runtime statistics of the sandbox, e.g. goroutines, heap, GC pauses and scheduler latencies")
+	f.BoolVar(&d.networkDiag, "network-diag", false, "prints the state of the sandbox network stack: interfaces, neighbors, routes, conntrack, endpoints and drop counters")
+	f.BoolVar(&d.controlAPI, "control-api", false, "prints the control API version and the control methods served by the sandbox")
+	f.BoolVar(&d.sysctls, "sysctls", false, "prints the sysctls set by the container, through its spec or at runtime")
 	f.StringVar(&d.mount, "mount", "", "Mount a filesystem (-mount fstype:source:destination).")
+	f.BoolVar(&d.rootfsUpperUsage, "rootfs-upper-usage", false, "prints the space used by the container's rootfs overlay upper layer and the files using the most of it")
+	f.IntVar(&d.rootfsUpperTop, "rootfs-upper-top", 10, "number of largest files to list with -rootfs-upper-usage")
 }
 
 // FetchSpec implements util.SubCommand.FetchSpec.
@@ -171,6 +189,19 @@ func (d *Debug) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomm
 		}
 		util.Infof("     *** Stack dump ***\n%s", stacks)
 	}
+	if d.lockGraph != "" {
+		f, err := os.OpenFile(d.lockGraph, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return util.Errorf("error opening lock graph output: %v", err)
+		}
+		err = c.Sandbox.LockGraph(f)
+		f.Close()
+		if err != nil {
+			os.Remove(d.lockGraph)
+			return util.Errorf("retrieving lock graph: %v", err)
+		}
+		util.Infof("Lock graph written to %q", d.lockGraph)
+	}
 	if d.strace != "" || len(d.logLevel) != 0 || len(d.logPackets) != 0 {
 		args := control.LoggingArgs{}
 		switch strings.ToLower(d.strace) {
@@ -239,6 +270,66 @@ func (d *Debug) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomm
 		}
 		util.Infof("%s", o)
 	}
+	if d.runtimeStats {
+		util.Infof("Retrieving runtime stats")
+		stats, err := c.Sandbox.RuntimeStats()
+		if err != nil {
+			return util.Errorf("retrieving runtime stats: %v", err)
+		}
+		o, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return util.Errorf("generating JSON: %v", err)
+		}
+		util.Infof("%s", o)
+	}
+	if d.networkDiag {
+		util.Infof("Retrieving network diagnostics")
+		diag, err := c.Sandbox.NetworkDiagnostics()
+		if err != nil {
+			return util.Errorf("retrieving network diagnostics: %v", err)
+		}
+		o, err := json.MarshalIndent(diag, "", "  ")
+		if err != nil {
+			return util.Errorf("generating JSON: %v", err)
+		}
+		util.Infof("%s", o)
+	}
+	if d.controlAPI {
+		util.Infof("Retrieving control API capabilities")
+		caps, err := c.Sandbox.ControlCapabilities()
+		if err != nil {
+			return util.Errorf("retrieving control API capabilities: %v", err)
+		}
+		o, err := json.MarshalIndent(caps, "", "  ")
+		if err != nil {
+			return util.Errorf("generating JSON: %v", err)
+		}
+		util.Infof("%s", o)
+	}
+	if d.sysctls {
+		util.Infof("Retrieving sysctls")
+		sysctls, err := c.Sandbox.Sysctls(c.ID)
+		if err != nil {
+			return util.Errorf("retrieving sysctls: %v", err)
+		}
+		o, err := json.MarshalIndent(sysctls, "", "  ")
+		if err != nil {
+			return util.Errorf("generating JSON: %v", err)
+		}
+		util.Infof("%s", o)
+	}
+	if d.rootfsUpperUsage {
+		util.Infof("Retrieving rootfs upper layer usage")
+		usage, err := c.RootfsUpperUsage(d.rootfsUpperTop)
+		if err != nil {
+			return util.Errorf("retrieving rootfs upper layer usage: %v", err)
+		}
+		o, err := json.MarshalIndent(usage, "", "  ")
+		if err != nil {
+			return util.Errorf("generating JSON: %v", err)
+		}
+		util.Infof("%s", o)
+	}
 	if d.mount != "" {
 		opts := strings.Split(d.mount, ":")
 		if len(opts) != 3 {
@@ -258,6 +349,7 @@ func (d *Debug) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomm
 		cpuFile   *os.File
 		heapFile  *os.File
 		mutexFile *os.File
+		lockFile  *os.File
 		traceFile *os.File
 	)
 	if d.profileBlock != "" {
@@ -292,6 +384,14 @@ func (d *Debug) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomm
 		defer f.Close()
 		mutexFile = f
 	}
+	if d.lockStats != "" {
+		f, err := os.OpenFile(d.lockStats, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return util.Errorf("error opening lock contention output: %v", err)
+		}
+		defer f.Close()
+		lockFile = f
+	}
 	if d.trace != "" {
 		f, err := os.OpenFile(d.trace, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 		if err != nil {
@@ -307,6 +407,7 @@ func (d *Debug) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomm
 		cpuErr   error
 		heapErr  error
 		mutexErr error
+		lockErr  error
 		traceErr error
 	)
 	if blockFile != nil {
@@ -337,6 +438,13 @@ func (d *Debug) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomm
 			mutexErr = c.Sandbox.MutexProfile(mutexFile, d.duration)
 		}()
 	}
+	if lockFile != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lockErr = c.Sandbox.LockContention(lockFile, d.duration)
+		}()
+	}
 	if traceFile != nil {
 		wg.Add(1)
 		go func() {
@@ -394,6 +502,11 @@ func (d *Debug) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomm
 		util.Infof("error collecting mutex profile: %v", mutexErr)
 		os.Remove(mutexFile.Name())
 	}
+	if lockErr != nil {
+		errorCount++
+		util.Infof("error collecting lock contention: %v", lockErr)
+		os.Remove(lockFile.Name())
+	}
 	if traceErr != nil {
 		errorCount++
 		util.Infof("error collecting trace profile: %v", traceErr)
@@ -54,6 +54,10 @@ type Do struct {
 	gidMap  idMapSlice
 	volumes volumes
 
+	// userLog is the file where user-visible logs, e.g. unsupported syscalls,
+	// are written to. It may be empty.
+	userLog string
+
 	// cached values for FetchSpec().
 	spec *specs.Spec
 	cid  string
@@ -272,7 +276,7 @@ func (c *Do) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcommand
 		}
 	}
 
-	return startContainerAndWait(spec, conf, cid, waitStatus)
+	return startContainerAndWait(spec, conf, cid, c.userLog, waitStatus)
 }
 
 func addNamespace(spec *specs.Spec, ns specs.LinuxNamespace) {
@@ -483,7 +487,7 @@ func calculatePeerIP(ip string) (string, error) {
 	return fmt.Sprintf("%s.%s.%s.%d", parts[0], parts[1], parts[2], n), nil
 }
 
-func startContainerAndWait(spec *specs.Spec, conf *config.Config, cid string, waitStatus *unix.WaitStatus) subcommands.ExitStatus {
+func startContainerAndWait(spec *specs.Spec, conf *config.Config, cid, userLog string, waitStatus *unix.WaitStatus) subcommands.ExitStatus {
 	specutils.LogSpecDebug(spec, conf.OCISeccomp)
 
 	out, err := json.Marshal(spec)
@@ -508,6 +512,7 @@ func startContainerAndWait(spec *specs.Spec, conf *config.Config, cid string, wa
 		ID:        cid,
 		Spec:      spec,
 		BundleDir: tmpDir,
+		UserLog:   userLog,
 		Attached:  true,
 	}
 
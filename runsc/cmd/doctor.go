@@ -0,0 +1,354 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/subcommands"
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/sentry/devices/nvproxy"
+	"gvisor.dev/gvisor/pkg/sentry/platform"
+	"gvisor.dev/gvisor/runsc/cgroup"
+	"gvisor.dev/gvisor/runsc/flag"
+)
+
+// Doctor implements subcommands.Command for the "doctor" command.
+type Doctor struct {
+	jsonOutput bool
+}
+
+// Name implements subcommands.Command.Name.
+func (*Doctor) Name() string {
+	return "doctor"
+}
+
+// Synopsis implements subcommands.Command.Synopsis.
+func (*Doctor) Synopsis() string {
+	return "inspect the host and report which gVisor features and platforms will work"
+}
+
+// Usage implements subcommands.Command.Usage.
+func (*Doctor) Usage() string {
+	return `doctor [flags] - inspect the host and report which gVisor features and platforms
+will work on it, recommended flags, and known performance caveats.
+
+Exits with a failure status if a check found a problem that will prevent
+containers from running.
+`
+}
+
+// SetFlags implements subcommands.Command.SetFlags.
+func (d *Doctor) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&d.jsonOutput, "json", false, "print the report in JSON format.")
+}
+
+// Execute implements subcommands.Command.Execute.
+func (d *Doctor) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
+	if f.NArg() != 0 {
+		f.Usage()
+		return subcommands.ExitUsageError
+	}
+
+	findings := diagnoseHost(inspectHost())
+	if d.jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(findings); err != nil {
+			return subcommands.ExitFailure
+		}
+	} else {
+		printFindings(os.Stdout, findings)
+	}
+	for _, f := range findings {
+		if f.Status == doctorFail {
+			return subcommands.ExitFailure
+		}
+	}
+	return subcommands.ExitSuccess
+}
+
+// doctorStatus is the outcome of a doctor check.
+type doctorStatus string
+
+const (
+	// doctorOK indicates that the feature checked is usable.
+	doctorOK doctorStatus = "ok"
+
+	// doctorUnavailable indicates that the feature checked is not usable on
+	// this host, but is optional.
+	doctorUnavailable doctorStatus = "unavailable"
+
+	// doctorWarn indicates that the feature checked is usable, but with
+	// caveats.
+	doctorWarn doctorStatus = "warning"
+
+	// doctorFail indicates that containers are not expected to run on this
+	// host.
+	doctorFail doctorStatus = "fail"
+)
+
+// doctorFinding is the result of a doctor check.
+type doctorFinding struct {
+	Check          string       `json:"check"`
+	Status         doctorStatus `json:"status"`
+	Detail         string       `json:"detail"`
+	Recommendation string       `json:"recommendation,omitempty"`
+}
+
+// hostInfo describes the properties of the host that doctor checks.
+type hostInfo struct {
+	// KernelRelease is the host kernel release, as reported by uname(2).
+	KernelRelease string
+
+	// Arch is the host architecture.
+	Arch string
+
+	// CgroupV2 is true if the host uses the cgroup v2 unified hierarchy only.
+	CgroupV2 bool
+
+	// Platforms maps the names of platforms compiled into runsc to the error
+	// returned when opening the platform's device, or nil if it was opened.
+	Platforms map[string]error
+
+	// XDPErr is the error returned when creating an AF_XDP socket, or nil if
+	// AF_XDP sockets are supported.
+	XDPErr error
+
+	// NvidiaDriver is the version of the host NVIDIA driver. NvidiaErr is the
+	// error returned when querying it.
+	NvidiaDriver string
+	NvidiaErr    error
+
+	// NvidiaSupported is true if NvidiaDriver is supported by nvproxy.
+	NvidiaSupported bool
+}
+
+// inspectHost returns hostInfo for this host.
+func inspectHost() *hostInfo {
+	h := &hostInfo{
+		Arch:      runtime.GOARCH,
+		CgroupV2:  cgroup.IsOnlyV2(),
+		Platforms: make(map[string]error),
+	}
+
+	var uts unix.Utsname
+	if err := unix.Uname(&uts); err == nil {
+		h.KernelRelease = unix.ByteSliceToString(uts.Release[:])
+	}
+
+	for _, name := range platform.List() {
+		p, err := platform.Lookup(name)
+		if err != nil {
+			h.Platforms[name] = err
+			continue
+		}
+		dev, err := p.OpenDevice("")
+		if dev != nil {
+			dev.Close()
+		}
+		h.Platforms[name] = err
+	}
+
+	if fd, err := unix.Socket(unix.AF_XDP, unix.SOCK_RAW, 0); err != nil {
+		h.XDPErr = err
+	} else {
+		unix.Close(fd)
+	}
+
+	h.NvidiaDriver, h.NvidiaErr = nvproxy.HostDriverVersion()
+	if h.NvidiaErr == nil {
+		nvproxy.Init()
+		for _, v := range nvproxy.SupportedDrivers() {
+			if v.String() == h.NvidiaDriver {
+				h.NvidiaSupported = true
+				break
+			}
+		}
+	}
+	return h
+}
+
+const (
+	// doctorMinKernelMajor and doctorMinKernelMinor are the oldest host
+	// kernel version that gVisor is tested against.
+	doctorMinKernelMajor = 4
+	doctorMinKernelMinor = 14
+)
+
+// diagnoseHost returns doctor findings for h.
+func diagnoseHost(h *hostInfo) []doctorFinding {
+	var findings []doctorFinding
+
+	// Kernel version.
+	kernel := doctorFinding{Check: "kernel", Status: doctorOK, Detail: fmt.Sprintf("Linux %s (%s)", h.KernelRelease, h.Arch)}
+	if major, minor, ok := parseKernelRelease(h.KernelRelease); !ok {
+		kernel.Status = doctorWarn
+		kernel.Detail = fmt.Sprintf("unable to parse kernel release %q", h.KernelRelease)
+	} else if major < doctorMinKernelMajor || (major == doctorMinKernelMajor && minor < doctorMinKernelMinor) {
+		kernel.Status = doctorFail
+		kernel.Recommendation = fmt.Sprintf("upgrade the host kernel to Linux %d.%d or later", doctorMinKernelMajor, doctorMinKernelMinor)
+	}
+	findings = append(findings, kernel)
+
+	// Cgroups.
+	if h.CgroupV2 {
+		findings = append(findings, doctorFinding{Check: "cgroups", Status: doctorOK, Detail: "cgroup v2 (unified hierarchy)"})
+	} else {
+		findings = append(findings, doctorFinding{
+			Check:          "cgroups",
+			Status:         doctorOK,
+			Detail:         "cgroup v1",
+			Recommendation: "containers see cgroup v1 unless --mount-cgroup-v2 is set",
+		})
+	}
+
+	// Platforms, in a stable order.
+	names := make([]string, 0, len(h.Platforms))
+	for name := range h.Platforms {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	kvmOK := false
+	for _, name := range names {
+		err := h.Platforms[name]
+		f := doctorFinding{Check: "platform/" + name, Status: doctorOK, Detail: "available"}
+		if err != nil {
+			f.Status = doctorUnavailable
+			f.Detail = err.Error()
+			if name == "kvm" {
+				if errors.Is(err, unix.ENOENT) {
+					f.Recommendation = "enable KVM (or nested virtualization) on the host to use --platform=kvm"
+				} else if errors.Is(err, unix.EACCES) || errors.Is(err, unix.EPERM) {
+					f.Recommendation = "grant the user running runsc access to /dev/kvm to use --platform=kvm"
+				}
+			}
+		} else if name == "kvm" {
+			kvmOK = true
+		}
+		findings = append(findings, f)
+	}
+	if kvmOK {
+		findings = append(findings, doctorFinding{
+			Check:          "performance",
+			Status:         doctorOK,
+			Detail:         "KVM is available",
+			Recommendation: "on bare-metal hosts, --platform=kvm typically has lower syscall and page fault overhead than the default systrap platform",
+		})
+	} else {
+		findings = append(findings, doctorFinding{
+			Check:  "performance",
+			Status: doctorWarn,
+			Detail: "KVM is unavailable, so workloads that are syscall or page fault heavy will run slower than on the kvm platform",
+		})
+	}
+
+	// XDP.
+	if h.XDPErr == nil {
+		findings = append(findings, doctorFinding{
+			Check:          "xdp",
+			Status:         doctorOK,
+			Detail:         "AF_XDP sockets are supported",
+			Recommendation: "--EXPERIMENTAL-xdp may be used for faster networking",
+		})
+	} else {
+		findings = append(findings, doctorFinding{
+			Check:  "xdp",
+			Status: doctorUnavailable,
+			Detail: fmt.Sprintf("AF_XDP sockets are not supported: %v", h.XDPErr),
+		})
+	}
+
+	// NVIDIA GPUs.
+	switch {
+	case h.NvidiaErr != nil:
+		findings = append(findings, doctorFinding{
+			Check:  "nvproxy",
+			Status: doctorUnavailable,
+			Detail: fmt.Sprintf("no NVIDIA driver found: %v", h.NvidiaErr),
+		})
+	case h.NvidiaSupported:
+		findings = append(findings, doctorFinding{
+			Check:  "nvproxy",
+			Status: doctorOK,
+			Detail: fmt.Sprintf("NVIDIA driver %s is supported", h.NvidiaDriver),
+		})
+	default:
+		findings = append(findings, doctorFinding{
+			Check:          "nvproxy",
+			Status:         doctorWarn,
+			Detail:         fmt.Sprintf("NVIDIA driver %s is not supported by nvproxy", h.NvidiaDriver),
+			Recommendation: "install a driver listed by \"runsc nvproxy list-supported-drivers\", or set --nvproxy-driver-version at your own risk",
+		})
+	}
+
+	return findings
+}
+
+// parseKernelRelease returns the major and minor version numbers in a kernel
+// release string such as "5.15.0-91-generic".
+func parseKernelRelease(release string) (major, minor int, ok bool) {
+	fields := strings.SplitN(release, ".", 3)
+	if len(fields) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minorStr := fields[1]
+	if i := strings.IndexFunc(minorStr, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		minorStr = minorStr[:i]
+	}
+	minor, err = strconv.Atoi(minorStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+// printFindings prints findings to w as a table.
+func printFindings(w io.Writer, findings []doctorFinding) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "CHECK\tSTATUS\tDETAIL\n")
+	for _, f := range findings {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", f.Check, f.Status, f.Detail)
+	}
+	tw.Flush()
+
+	var recs []doctorFinding
+	for _, f := range findings {
+		if f.Recommendation != "" {
+			recs = append(recs, f)
+		}
+	}
+	if len(recs) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nRecommendations:\n")
+	for _, f := range recs {
+		fmt.Fprintf(w, "  %s: %s\n", f.Check, f.Recommendation)
+	}
+}
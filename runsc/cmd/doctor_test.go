@@ -0,0 +1,96 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestParseKernelRelease(t *testing.T) {
+	for _, tc := range []struct {
+		release string
+		major   int
+		minor   int
+		ok      bool
+	}{
+		{release: "5.15.0-91-generic", major: 5, minor: 15, ok: true},
+		{release: "6.1.0", major: 6, minor: 1, ok: true},
+		{release: "4.19-rc1", major: 4, minor: 19, ok: true},
+		{release: "6", ok: false},
+		{release: "", ok: false},
+		{release: "x.y.z", ok: false},
+	} {
+		t.Run(tc.release, func(t *testing.T) {
+			major, minor, ok := parseKernelRelease(tc.release)
+			if ok != tc.ok || (ok && (major != tc.major || minor != tc.minor)) {
+				t.Errorf("parseKernelRelease(%q) = %d, %d, %t, want %d, %d, %t", tc.release, major, minor, ok, tc.major, tc.minor, tc.ok)
+			}
+		})
+	}
+}
+
+func findingFor(t *testing.T, findings []doctorFinding, check string) doctorFinding {
+	t.Helper()
+	for _, f := range findings {
+		if f.Check == check {
+			return f
+		}
+	}
+	t.Fatalf("no finding for check %q in %+v", check, findings)
+	return doctorFinding{}
+}
+
+func TestDiagnoseHost(t *testing.T) {
+	h := &hostInfo{
+		KernelRelease: "6.1.0",
+		Arch:          "amd64",
+		Platforms: map[string]error{
+			"systrap": nil,
+			"kvm":     fmt.Errorf("error opening KVM device file: %w", unix.ENOENT),
+		},
+		XDPErr:       unix.EAFNOSUPPORT,
+		NvidiaDriver: "1.2.3",
+		NvidiaErr:    nil,
+	}
+	findings := diagnoseHost(h)
+
+	if f := findingFor(t, findings, "kernel"); f.Status != doctorOK {
+		t.Errorf("kernel finding got %+v, want status %q", f, doctorOK)
+	}
+	if f := findingFor(t, findings, "platform/systrap"); f.Status != doctorOK {
+		t.Errorf("systrap finding got %+v, want status %q", f, doctorOK)
+	}
+	if f := findingFor(t, findings, "platform/kvm"); f.Status != doctorUnavailable || f.Recommendation == "" {
+		t.Errorf("kvm finding got %+v, want status %q with a recommendation", f, doctorUnavailable)
+	}
+	if f := findingFor(t, findings, "performance"); f.Status != doctorWarn {
+		t.Errorf("performance finding got %+v, want status %q", f, doctorWarn)
+	}
+	if f := findingFor(t, findings, "xdp"); f.Status != doctorUnavailable {
+		t.Errorf("xdp finding got %+v, want status %q", f, doctorUnavailable)
+	}
+	if f := findingFor(t, findings, "nvproxy"); f.Status != doctorWarn {
+		t.Errorf("nvproxy finding got %+v, want status %q", f, doctorWarn)
+	}
+
+	// Old kernels fail.
+	h.KernelRelease = "3.10.0-1160.el7.x86_64"
+	if f := findingFor(t, diagnoseHost(h), "kernel"); f.Status != doctorFail {
+		t.Errorf("kernel finding for %q got %+v, want status %q", h.KernelRelease, f, doctorFail)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/google/subcommands"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/runsc/cmd/util"
+	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/flag"
+	"gvisor.dev/gvisor/runsc/sandbox"
+	"gvisor.dev/gvisor/runsc/specutils"
+)
+
+// Estimate implements subcommands.Command for the "estimate" command.
+type Estimate struct {
+	// bundleDir is the path to the bundle directory (defaults to the
+	// current working directory).
+	bundleDir string
+}
+
+// Name implements subcommands.Command.Name.
+func (*Estimate) Name() string {
+	return "estimate"
+}
+
+// Synopsis implements subcommands.Command.Synopsis.
+func (*Estimate) Synopsis() string {
+	return "estimate the resource overhead of running a container in a new sandbox"
+}
+
+// Usage implements subcommands.Command.Usage.
+func (*Estimate) Usage() string {
+	return `estimate [--bundle <bundle>]
+
+Prints, as JSON, the expected memory overhead of the sentry, gofer and
+platform for running the container in the bundle in a new sandbox, given the
+runtime flags. Schedulers can add it to the container's requests when placing
+it on a node. The estimate is approximate and doesn't start a sandbox.
+`
+}
+
+// SetFlags implements subcommands.Command.SetFlags.
+func (e *Estimate) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&e.bundleDir, "bundle", "", "path to the root of the bundle directory, defaults to the current directory")
+}
+
+// FetchSpec implements util.SubCommand.FetchSpec.
+func (e *Estimate) FetchSpec(conf *config.Config, _ *flag.FlagSet) (string, *specs.Spec, error) {
+	if e.bundleDir == "" {
+		e.bundleDir = getwdOrDie()
+	}
+	spec, err := specutils.ReadSpec(e.bundleDir, conf)
+	if err != nil {
+		return "", nil, fmt.Errorf("reading spec: %w", err)
+	}
+	return "", spec, nil
+}
+
+// Execute implements subcommands.Command.Execute.
+func (e *Estimate) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
+	if f.NArg() != 0 {
+		f.Usage()
+		return subcommands.ExitUsageError
+	}
+	conf := args[0].(*config.Config)
+
+	_, spec, err := e.FetchSpec(conf, f)
+	if err != nil {
+		return util.Errorf("%v", err)
+	}
+	est, err := sandbox.EstimateOverhead(conf, spec)
+	if err != nil {
+		return util.Errorf("estimating overhead: %v", err)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(est); err != nil {
+		return util.Errorf("encoding estimate: %v", err)
+	}
+	return subcommands.ExitSuccess
+}
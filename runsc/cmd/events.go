@@ -18,12 +18,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"time"
 
 	"github.com/google/subcommands"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/runsc/boot"
 	"gvisor.dev/gvisor/runsc/cmd/util"
 	"gvisor.dev/gvisor/runsc/config"
 	"gvisor.dev/gvisor/runsc/container"
@@ -37,6 +39,8 @@ type Events struct {
 	intervalSec int
 	// If true, events will print a single group of stats and exit.
 	stats bool
+	// If true, events will stream sandbox events until the sandbox exits.
+	subscribe bool
 }
 
 // Name implements subcommands.Command.Name.
@@ -58,6 +62,11 @@ Where "<container-id>" is the name for the instance of the container.
 The events command displays information about the container. By default the
 information is displayed once every 5 seconds.
 
+With --stream, the events of the container's sandbox are printed as JSON lines
+as they happen instead: container starts, exits, restores, pauses and resumes,
+checkpoints, OOM kills, and watchdog and crash reports. Events that happened
+shortly before the command started are printed first.
+
 OPTIONS:
 `
 }
@@ -66,6 +75,8 @@ OPTIONS:
 func (evs *Events) SetFlags(f *flag.FlagSet) {
 	f.IntVar(&evs.intervalSec, "interval", 5, "set the stats collection interval, in seconds")
 	f.BoolVar(&evs.stats, "stats", false, "display the container's stats then exit")
+	f.BoolVar(&evs.subscribe, "stream", false, "stream sandbox events, such as container lifecycle transitions and OOM kills, as JSON lines until the sandbox exits. The interval sets how often OOM kills are checked.")
+	f.BoolVar(&evs.subscribe, "subscribe", false, "alias for --stream.")
 }
 
 // FetchSpec implements util.SubCommand.FetchSpec.
@@ -91,6 +102,13 @@ func (evs *Events) Execute(_ context.Context, f *flag.FlagSet, args ...any) subc
 		util.Fatalf("loading container: %v", err)
 	}
 
+	if evs.subscribe {
+		if err := evs.subscribeEvents(c); err != nil {
+			util.Fatalf("subscribing to events: %v", err)
+		}
+		return subcommands.ExitSuccess
+	}
+
 	// Repeatedly get stats from the container. Sleep a bit after every loop
 	// except the first one.
 	for dur := time.Duration(evs.intervalSec) * time.Second; true; time.Sleep(dur) {
@@ -120,3 +138,84 @@ func (evs *Events) Execute(_ context.Context, f *flag.FlagSet, args ...any) subc
 	}
 	panic("should never get here")
 }
+
+// subscribeEvents prints the events of c's sandbox until the sandbox exits.
+// OOM kills are not reported by the sandbox itself, which is usually the
+// process killed, so they are detected from the sandbox cgroup.
+func (evs *Events) subscribeEvents(c *container.Container) error {
+	interval := time.Duration(evs.intervalSec) * time.Second
+	if interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %v", interval)
+	}
+	conn, err := c.Sandbox.SubscribeEvents()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	out := make(chan boot.SandboxEvent)
+	done := make(chan struct{})
+	go func() {
+		defer close(out)
+		dec := json.NewDecoder(conn)
+		for {
+			var ev boot.SandboxEvent
+			if err := dec.Decode(&ev); err != nil {
+				if err != io.EOF {
+					log.Warningf("Error decoding sandbox event: %v", err)
+				}
+				return
+			}
+			select {
+			case out <- ev:
+			case <-done:
+				return
+			}
+		}
+	}()
+	defer close(done)
+
+	cg := c.Sandbox.CgroupJSON.Cgroup
+	var ooms uint64
+	if cg != nil {
+		ooms, _ = cg.OOMKillCount()
+	}
+	checkOOM := func(enc *json.Encoder) {
+		if cg == nil {
+			return
+		}
+		n, err := cg.OOMKillCount()
+		if err != nil || n <= ooms {
+			return
+		}
+		ev := boot.SandboxEvent{
+			Type:        boot.SandboxEventOOMKill,
+			Time:        time.Now(),
+			ContainerID: c.ID,
+			Message:     fmt.Sprintf("%d process(es) killed by the OOM killer", n-ooms),
+		}
+		ooms = n
+		if err := enc.Encode(&ev); err != nil {
+			log.Warningf("Error encoding event %+v: %v", ev, err)
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case ev, ok := <-out:
+			if !ok {
+				// The sandbox is gone, check whether it was OOM killed.
+				checkOOM(enc)
+				return nil
+			}
+			if err := enc.Encode(&ev); err != nil {
+				return fmt.Errorf("encoding event %+v: %w", ev, err)
+			}
+		case <-ticker.C:
+			checkOOM(enc)
+		}
+	}
+}
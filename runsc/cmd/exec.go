@@ -64,6 +64,10 @@ type Exec struct {
 
 	// execFD is the host file descriptor used for program execution.
 	execFD int
+
+	// detachKeys, if set, makes the process detachable. See
+	// control.ExecArgs.Detachable.
+	detachKeys string
 }
 
 // Name implements subcommands.Command.Name.
@@ -109,6 +113,7 @@ func (ex *Exec) SetFlags(f *flag.FlagSet) {
 	f.StringVar(&ex.consoleSocket, "console-socket", "", "path to an AF_UNIX socket which will receive a file descriptor referencing the master end of the console's pseudoterminal")
 	f.Var(&ex.passFDs, "pass-fd", "file descriptor passed to the container in M:N format, where M is the host and N is the guest descriptor (can be supplied multiple times)")
 	f.IntVar(&ex.execFD, "exec-fd", -1, "host file descriptor used for program execution")
+	f.StringVar(&ex.detachKeys, "detach-keys", "", `keys that detach the terminal from the process, e.g. "ctrl-p,ctrl-q", which keeps running and can be reattached with "runsc attach". Requires a terminal`)
 }
 
 // FetchSpec implements util.SubCommand.FetchSpec.
@@ -136,14 +141,30 @@ func (ex *Exec) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomm
 		util.Fatalf("parsing process spec: %v", err)
 	}
 
+	var detachKeys []byte
+	if ex.detachKeys != "" {
+		if ex.detach {
+			util.Fatalf("--detach-keys and --detach are mutually exclusive")
+		}
+		if !e.StdioIsPty || !console.StdioIsPty() {
+			util.Fatalf("--detach-keys requires a terminal")
+		}
+		if detachKeys, err = control.ParseDetachKeys(ex.detachKeys); err != nil {
+			util.Fatalf("%v", err)
+		}
+		e.Detachable = true
+	}
+
 	log.Debugf("Exec arguments: %+v", e)
 	log.Debugf("Exec capabilities: %+v", e.Capabilities)
 
-	// Create the file descriptor map for the process in the container.
-	fdMap := map[int]*os.File{
-		0: os.Stdin,
-		1: os.Stdout,
-		2: os.Stderr,
+	// Create the file descriptor map for the process in the container. The
+	// terminal of a detachable process is attached once it started instead.
+	fdMap := map[int]*os.File{}
+	if !e.Detachable {
+		fdMap[0] = os.Stdin
+		fdMap[1] = os.Stdout
+		fdMap[2] = os.Stderr
 	}
 
 	// Add custom file descriptors to the map.
@@ -183,17 +204,21 @@ func (ex *Exec) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomm
 	if ex.detach {
 		return ex.execChildAndWait(waitStatus)
 	}
-	return ex.exec(conf, c, e, waitStatus)
+	return ex.exec(conf, c, e, detachKeys, waitStatus)
 }
 
-func (ex *Exec) exec(conf *config.Config, c *container.Container, e *control.ExecArgs, waitStatus *unix.WaitStatus) subcommands.ExitStatus {
+func (ex *Exec) exec(conf *config.Config, c *container.Container, e *control.ExecArgs, detachKeys []byte, waitStatus *unix.WaitStatus) subcommands.ExitStatus {
 	// Start the new process and get its pid.
 	pid, err := c.Execute(conf, e)
 	if err != nil {
 		return util.Errorf("executing processes for container: %v", err)
 	}
 
-	if e.StdioIsPty {
+	switch {
+	case e.Detachable:
+		stopForwarding := forwardWindowSize(c, pid)
+		defer stopForwarding()
+	case e.StdioIsPty:
 		// Forward signals sent to this process to the foreground
 		// process in the sandbox.
 		stopForwarding := c.ForwardSignals(pid, true /* fgProcess */)
@@ -216,6 +241,18 @@ func (ex *Exec) exec(conf *config.Config, c *container.Container, e *control.Exe
 		}
 	}
 
+	if e.Detachable {
+		running, err := c.AttachExec(pid, os.Stdin, detachKeys)
+		if err != nil {
+			return util.Errorf("attaching to process %d: %v", pid, err)
+		}
+		if running {
+			fmt.Fprintf(os.Stderr, "Detached from process %d, reattach with: runsc attach %s %d\n", pid, c.ID, pid)
+			*waitStatus = 0
+			return subcommands.ExitSuccess
+		}
+	}
+
 	// Wait for the process to exit.
 	ws, err := c.WaitPID(pid)
 	if err != nil {
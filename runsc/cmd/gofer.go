@@ -25,8 +25,10 @@ import (
 	"github.com/google/subcommands"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/coretag"
 	"gvisor.dev/gvisor/pkg/lisafs"
 	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sync"
 	"gvisor.dev/gvisor/pkg/unet"
 	"gvisor.dev/gvisor/pkg/urpc"
 	"gvisor.dev/gvisor/runsc/boot"
@@ -38,6 +40,7 @@ import (
 	"gvisor.dev/gvisor/runsc/fsgofer"
 	"gvisor.dev/gvisor/runsc/fsgofer/extension"
 	"gvisor.dev/gvisor/runsc/fsgofer/filter"
+	"gvisor.dev/gvisor/runsc/goferauth"
 	"gvisor.dev/gvisor/runsc/profile"
 	"gvisor.dev/gvisor/runsc/specutils"
 )
@@ -112,6 +115,7 @@ type Gofer struct {
 	specFD           int
 	mountsFD         int
 	goferToHostRPCFD int
+	authKeyFD        int
 	profileFDs       profile.FDArgs
 	syncFDs          goferSyncFDs
 	stopProfiling    func()
@@ -145,6 +149,7 @@ func (g *Gofer) SetFlags(f *flag.FlagSet) {
 	f.IntVar(&g.specFD, "spec-fd", -1, "required fd with the container spec")
 	f.IntVar(&g.mountsFD, "mounts-fd", -1, "mountsFD is the file descriptor to write list of mounts after they have been resolved (direct paths, no symlinks).")
 	f.IntVar(&g.goferToHostRPCFD, "rpc-fd", -1, "gofer-to-host RPC file descriptor.")
+	f.IntVar(&g.authKeyFD, "auth-key-fd", -1, "optional FD to read the key used to authenticate the sandbox on every connection from.")
 
 	// IDs to run gofer as.
 	f.IntVar(&g.uid, "uid", 0, "User ID")
@@ -241,6 +246,16 @@ func (g *Gofer) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomm
 	// unrelated file.
 	goferToHostRPC.Close()
 
+	// The gofer serves untrusted requests on behalf of the sandbox, so keep it
+	// off of cores used by other sandboxes too. Since the gofer is not started
+	// by the sandbox, it gets its own core tag.
+	if conf.EnableCoreTags {
+		if err := coretag.Enable(); err != nil {
+			util.Fatalf("Failed to core tag gofer: %v", err)
+		}
+		log.Infof("Core tag enabled")
+	}
+
 	// Start profiling. This will be a noop if no profiling arguments were passed.
 	profileOpts := profile.MakeOpts(&g.profileFDs, conf.ProfileGCInterval)
 	g.stopProfiling = profile.Start(profileOpts)
@@ -331,15 +346,18 @@ func (g *Gofer) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomm
 		util.Fatalf("installing seccomp filters: %v", err)
 	}
 
-	return g.serve(spec, conf, root, ruid, euid, rgid, egid)
+	return g.serve(spec, conf, mountHints, containerID, root, ruid, euid, rgid, egid)
 }
 
-func (g *Gofer) serve(spec *specs.Spec, conf *config.Config, root string, ruid int, euid int, rgid int, egid int) subcommands.ExitStatus {
+func (g *Gofer) serve(spec *specs.Spec, conf *config.Config, mountHints *boot.PodMountHints, containerID, root string, ruid int, euid int, rgid int, egid int) subcommands.ExitStatus {
 	type connectionConfig struct {
 		sock      *unet.Socket
 		mountPath string
 		readonly  bool
 		mount     *specs.Mount
+		// quota is the maximum number of bytes that the container may add to
+		// the mount, or 0 for no limit.
+		quota uint64
 	}
 	cfgs := make([]connectionConfig, 0, len(spec.Mounts)+1)
 
@@ -377,13 +395,18 @@ func (g *Gofer) serve(spec *specs.Spec, conf *config.Config, root string, ruid i
 		ioFD := ioFDs[0]
 		ioFDs = ioFDs[1:]
 		readonly := specutils.IsReadonlyMount(m.Options) || mountConf.ShouldUseOverlayfs()
+		var quota uint64
+		if hint := mountHints.FindMount(m.Source); hint != nil && !readonly {
+			quota = hint.Quota
+		}
 		cfgs = append(cfgs, connectionConfig{
 			sock:      sandboxsetup.NewSocket(ioFD),
 			mountPath: m.Destination,
 			readonly:  readonly,
 			mount:     m,
+			quota:     quota,
 		})
-		log.Infof("Serving %q mapped on FD %d (ro: %t)", m.Destination, ioFD, readonly)
+		log.Infof("Serving %q mapped on FD %d (ro: %t, quota: %d)", m.Destination, ioFD, readonly, quota)
 	}
 
 	if len(ioFDs) > 0 {
@@ -398,6 +421,29 @@ func (g *Gofer) serve(spec *specs.Spec, conf *config.Config, root string, ruid i
 		log.Infof("Serving /dev mapped on FD %d (ro: false)", g.devIoFD)
 	}
 
+	// The key is only read here, since the gofer may have re-executed itself
+	// after being started, and that must not consume the key FD.
+	if g.authKeyFD >= 0 {
+		key, err := goferauth.ReadKey(g.authKeyFD)
+		if err != nil {
+			util.Fatalf("%v", err)
+		}
+		// Authenticate all connections concurrently, since the sandbox may
+		// not process them in the order they were set up here.
+		var wg sync.WaitGroup
+		for _, cfg := range cfgs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := goferauth.Serve(cfg.sock, key, containerID, cfg.mountPath); err != nil {
+					util.Fatalf("authenticating sandbox on FD %d for %q: %v", cfg.sock.FD(), cfg.mountPath, err)
+				}
+				log.Infof("Authenticated sandbox of container %q on FD %d for %q", containerID, cfg.sock.FD(), cfg.mountPath)
+			}()
+		}
+		wg.Wait()
+	}
+
 	// These are global fsgofer configurations.
 	fsgoferConf := &fsgofer.Config{
 		HostUDS:            conf.GetHostUDS(),
@@ -425,12 +471,19 @@ func (g *Gofer) serve(spec *specs.Spec, conf *config.Config, root string, ruid i
 					connImpl = impl
 					connOpts = opts
 					log.Infof("Serving %q via extension %s on FD %d", cfg.mountPath, e.Name(), cfg.sock.FD())
+					if cfg.quota > 0 {
+						log.Warningf("Quota for %q is not enforced by extension %s", cfg.mountPath, e.Name())
+					}
 					break
 				}
 			}
 		}
 		if connImpl == nil {
-			connImpl = fsgofer.NewConnectionImpl(fsgoferConf)
+			if cfg.quota > 0 {
+				connImpl = fsgofer.NewConnectionImplWithQuota(fsgoferConf, cfg.quota)
+			} else {
+				connImpl = fsgofer.NewConnectionImpl(fsgoferConf)
+			}
 			connOpts = fsgofer.ConnectionOpts(cfg.readonly)
 		}
 		conn, err := server.CreateConnection(cfg.sock, cfg.mountPath, connOpts, connImpl)
@@ -468,7 +521,7 @@ func lisafsNeededForDirectFSSuppression(spec *specs.Spec, mountHints *boot.PodMo
 		if !mountConf.ShouldUseLisafs() {
 			continue
 		}
-		if hint := mountHints.FindMount(m.Source); hint != nil && hint.SuppressDirectFS {
+		if hint := mountHints.FindMount(m.Source); hint != nil && hint.ShouldSuppressDirectFS() {
 			return true
 		}
 	}
@@ -0,0 +1,146 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/google/subcommands"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/runsc/cmd/util"
+	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/container"
+	"gvisor.dev/gvisor/runsc/flag"
+	"gvisor.dev/gvisor/runsc/sandbox"
+)
+
+// HostFDs implements subcommands.Command for the "host-fds" command.
+type HostFDs struct {
+	containerLoader
+	unattributed bool
+	format       string
+	close        int
+}
+
+// Name implements subcommands.Command.Name.
+func (*HostFDs) Name() string {
+	return "host-fds"
+}
+
+// Synopsis implements subcommands.Command.Synopsis.
+func (*HostFDs) Synopsis() string {
+	return "list the host FDs held by a sandbox, to find leaked FDs"
+}
+
+// Usage implements subcommands.Command.Usage.
+func (*HostFDs) Usage() string {
+	return `host-fds [flags] <container id> - list the host FDs held by the sandbox of
+a container.
+
+For each FD of the sandbox process, the file it refers to is printed along
+with what holds it in the sentry, such as an application FD backed by a host
+file, a gofer connection or the memory file. FDs that the sentry can't
+attribute have no owner. These include FDs held by the platform and the Go
+runtime, so an unattributed FD isn't necessarily leaked, but unattributed FDs
+whose number grows over time likely are.
+
+--close closes the application FDs that hold the given host FD, as if the
+application had closed them, which closes the host FD once nothing else
+references it. Host FDs held by anything else, including unattributed FDs,
+can't be closed.
+`
+}
+
+// SetFlags implements subcommands.Command.SetFlags.
+func (h *HostFDs) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&h.unattributed, "unattributed", false, "only list FDs that the sentry can't attribute to an owner")
+	f.StringVar(&h.format, "format", "table", "output format: table or json")
+	f.IntVar(&h.close, "close", -1, "close the application FDs holding the given host FD instead of listing FDs")
+}
+
+// FetchSpec implements util.SubCommand.FetchSpec.
+func (h *HostFDs) FetchSpec(conf *config.Config, f *flag.FlagSet) (string, *specs.Spec, error) {
+	c, err := h.loadContainer(conf, f, container.LoadOpts{})
+	if err != nil {
+		return "", nil, fmt.Errorf("loading container: %w", err)
+	}
+	return c.ID, c.Spec, nil
+}
+
+// Execute implements subcommands.Command.Execute.
+func (h *HostFDs) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
+	if f.NArg() != 1 {
+		f.Usage()
+		return subcommands.ExitUsageError
+	}
+
+	conf := args[0].(*config.Config)
+
+	c, err := h.loadContainer(conf, f, container.LoadOpts{})
+	if err != nil {
+		util.Fatalf("loading container: %v", err)
+	}
+	if !c.IsSandboxRunning() {
+		util.Fatalf("sandbox of container %q is not running", c.ID)
+	}
+
+	if h.close >= 0 {
+		if err := c.Sandbox.CloseHostFD(h.close); err != nil {
+			util.Fatalf("%v", err)
+		}
+		return subcommands.ExitSuccess
+	}
+
+	fds, err := c.Sandbox.HostFDs()
+	if err != nil {
+		util.Fatalf("listing host FDs: %v", err)
+	}
+	if h.unattributed {
+		var filtered []sandbox.HostFD
+		for _, fd := range fds {
+			if fd.Owner == "" {
+				filtered = append(filtered, fd)
+			}
+		}
+		fds = filtered
+	}
+
+	switch h.format {
+	case "table":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprint(w, "FD\tTARGET\tOWNER\n")
+		for _, fd := range fds {
+			owner := fd.Owner
+			if owner == "" {
+				owner = "-"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", fd.FD, fd.Target, owner)
+		}
+		if err := w.Flush(); err != nil {
+			util.Fatalf("writing output: %v", err)
+		}
+	case "json":
+		if err := json.NewEncoder(os.Stdout).Encode(fds); err != nil {
+			util.Fatalf("writing output: %v", err)
+		}
+	default:
+		util.Fatalf("invalid format %q", h.format)
+	}
+	return subcommands.ExitSuccess
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/subcommands"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/runsc/cmd/util"
+	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/container"
+	"gvisor.dev/gvisor/runsc/flag"
+)
+
+// ImportCRIU implements subcommands.Command for the "import-criu" command.
+type ImportCRIU struct {
+	containerLoader
+	imagePath string
+}
+
+// Name implements subcommands.Command.Name.
+func (*ImportCRIU) Name() string {
+	return "import-criu"
+}
+
+// Synopsis implements subcommands.Command.Synopsis.
+func (*ImportCRIU) Synopsis() string {
+	return "import processes dumped by CRIU into a running container"
+}
+
+// Usage implements subcommands.Command.Usage.
+func (*ImportCRIU) Usage() string {
+	return `import-criu --image-path=<path> <container id> - import the processes of a
+CRIU dump, e.g. made by "runc checkpoint", into a running container.
+
+The container's filesystem must contain the files that the dumped processes had
+open or mapped, at the same paths. Imported processes get new PIDs, and share
+the credentials and namespaces of the container's init process. For each
+imported process, its PID in the dump and its new PID are printed.
+
+Only a subset of CRIU dumps can be imported: single-threaded x86-64 processes
+whose open files are regular files, directories or standard input and output.
+Dumps with sockets, pipes between dumped processes, System V IPC or
+incremental memory images are rejected.
+`
+}
+
+// SetFlags implements subcommands.Command.SetFlags.
+func (i *ImportCRIU) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&i.imagePath, "image-path", "", "directory containing the CRIU images")
+}
+
+// FetchSpec implements util.SubCommand.FetchSpec.
+func (i *ImportCRIU) FetchSpec(conf *config.Config, f *flag.FlagSet) (string, *specs.Spec, error) {
+	c, err := i.loadContainer(conf, f, container.LoadOpts{})
+	if err != nil {
+		return "", nil, fmt.Errorf("loading container: %w", err)
+	}
+	return c.ID, c.Spec, nil
+}
+
+// Execute implements subcommands.Command.Execute.
+func (i *ImportCRIU) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
+	if f.NArg() != 1 || i.imagePath == "" {
+		f.Usage()
+		return subcommands.ExitUsageError
+	}
+
+	conf := args[0].(*config.Config)
+
+	cont, err := i.loadContainer(conf, f, container.LoadOpts{})
+	if err != nil {
+		util.Fatalf("loading container: %v", err)
+	}
+	imported, err := cont.ImportCRIU(i.imagePath)
+	if err != nil {
+		util.Fatalf("import-criu failed: %v", err)
+	}
+	for _, p := range imported {
+		fmt.Fprintf(&util.Writer{}, "%d %d\n", p.DumpPID, p.PID)
+	}
+	return subcommands.ExitSuccess
+}
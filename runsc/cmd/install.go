@@ -86,17 +86,29 @@ func (i *Install) Execute(_ context.Context, f *flag.FlagSet, args ...any) subco
 	i.runtimeArgs = f.Args()
 	conf := args[0].(*config.Config)
 
-	// Check the platform.
-	p, err := platform.Lookup(conf.Platform)
-	if err != nil {
-		log.Fatalf("invalid platform: %v", err)
+	// Check the platforms.
+	candidates := conf.PlatformCandidates()
+	if len(candidates) == 0 {
+		log.Fatalf("invalid platform: no platform specified")
 	}
-	deviceFile, err := p.OpenDevice(conf.PlatformDevicePath)
-	if err != nil {
-		log.Printf("WARNING: unable to open platform, runsc may fail to start: %v", err)
+	available := false
+	for _, name := range candidates {
+		p, err := platform.Lookup(name)
+		if err != nil {
+			log.Fatalf("invalid platform: %v", err)
+		}
+		deviceFile, err := p.OpenDevice(conf.PlatformDevicePath)
+		if err != nil {
+			log.Printf("WARNING: unable to open platform %q: %v", name, err)
+		} else {
+			available = true
+		}
+		if deviceFile != nil {
+			deviceFile.Close()
+		}
 	}
-	if deviceFile != nil {
-		deviceFile.Close()
+	if !available {
+		log.Printf("WARNING: no platform is available, runsc may fail to start")
 	}
 
 	// Extract the executable.
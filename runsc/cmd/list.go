@@ -26,6 +26,7 @@ import (
 	"github.com/google/subcommands"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/sentry/control"
 	"gvisor.dev/gvisor/runsc/cmd/util"
 	"gvisor.dev/gvisor/runsc/config"
 	"gvisor.dev/gvisor/runsc/container"
@@ -37,6 +38,7 @@ type List struct {
 	quiet   bool
 	format  string
 	sandbox bool
+	detail  bool
 }
 
 // Name implements subcommands.command.name.
@@ -59,6 +61,7 @@ func (l *List) SetFlags(f *flag.FlagSet) {
 	f.BoolVar(&l.quiet, "quiet", false, "only list container ids")
 	f.StringVar(&l.format, "format", "text", "output format: 'text' (default) or 'json'")
 	f.BoolVar(&l.sandbox, "sandbox", false, "limit output to sandboxes only")
+	f.BoolVar(&l.detail, "detail", false, "with --format=json, print one object per sandbox with its health, platform, containers, uptime, memory usage and checkpoint status")
 }
 
 // FetchSpec implements util.SubCommand.FetchSpec.
@@ -83,6 +86,10 @@ func (l *List) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomma
 }
 
 func (l *List) execute(rootDir string, out io.Writer) error {
+	if l.detail && l.format != "json" {
+		return fmt.Errorf("--detail requires --format=json")
+	}
+
 	var ids []container.FullID
 	var err error
 	if l.sandbox {
@@ -128,6 +135,12 @@ func (l *List) execute(rootDir string, out io.Writer) error {
 		}
 		_ = w.Flush()
 	case "json":
+		if l.detail {
+			if err := json.NewEncoder(out).Encode(sandboxInfos(containers)); err != nil {
+				return fmt.Errorf("marshaling sandbox info: %w", err)
+			}
+			break
+		}
 		// Print just the states.
 		var states []specs.State
 		for _, c := range containers {
@@ -141,3 +154,107 @@ func (l *List) execute(rootDir string, out io.Writer) error {
 	}
 	return nil
 }
+
+// sandboxInfo is the detailed JSON output of "list" for a sandbox.
+type sandboxInfo struct {
+	// ID is the sandbox ID.
+	ID string `json:"id"`
+
+	// Pid is the PID of the sandbox process, or 0 if it isn't running.
+	Pid int `json:"pid"`
+
+	// Status is the status of the root container.
+	Status specs.ContainerState `json:"status"`
+
+	// Healthy is true if the sandbox process is running and responds on its
+	// control socket.
+	Healthy bool `json:"healthy"`
+
+	// HealthError explains why the sandbox isn't healthy.
+	HealthError string `json:"healthError,omitempty"`
+
+	// Platform is the platform the sandbox runs on, if known.
+	Platform string `json:"platform,omitempty"`
+
+	// StartTime is the time the sandbox was started.
+	StartTime time.Time `json:"startTime"`
+
+	// UptimeSeconds is the time since the sandbox was started, if it is
+	// running.
+	UptimeSeconds float64 `json:"uptimeSeconds,omitempty"`
+
+	// Memory is a snapshot of the memory usage of the sandbox, if it is
+	// healthy.
+	Memory *control.MemoryUsage `json:"memory,omitempty"`
+
+	// Checkpointed is true if the sandbox was checkpointed.
+	Checkpointed bool `json:"checkpointed"`
+
+	// Restored is true if the sandbox was restored from a checkpoint.
+	Restored bool `json:"restored"`
+
+	// ControlSocketPath is the path to the control socket of the sandbox.
+	ControlSocketPath string `json:"controlSocketPath"`
+
+	// Containers holds the state of the containers in the sandbox, root
+	// container first.
+	Containers []specs.State `json:"containers"`
+}
+
+// sandboxInfos groups containers by sandbox and returns the details of each
+// sandbox, in the order in which they first appear in containers.
+func sandboxInfos(containers []*container.Container) []*sandboxInfo {
+	var infos []*sandboxInfo
+	byID := make(map[string]*sandboxInfo)
+	for _, c := range containers {
+		s := c.Sandbox
+		if s == nil {
+			log.Warningf("Skipping container %q without a sandbox", c.ID)
+			continue
+		}
+		info, ok := byID[s.ID]
+		if !ok {
+			info = newSandboxInfo(c)
+			byID[s.ID] = info
+			infos = append(infos, info)
+		}
+		if s.IsRootContainer(c.ID) {
+			info.Status = c.Status
+			info.Containers = append([]specs.State{c.State()}, info.Containers...)
+		} else {
+			info.Containers = append(info.Containers, c.State())
+		}
+	}
+	return infos
+}
+
+// newSandboxInfo returns the details of the sandbox of c, without its
+// containers.
+func newSandboxInfo(c *container.Container) *sandboxInfo {
+	s := c.Sandbox
+	info := &sandboxInfo{
+		ID:                s.ID,
+		Pid:               s.Pid.Load(),
+		Platform:          s.Platform,
+		StartTime:         s.StartTime,
+		Checkpointed:      s.Checkpointed,
+		Restored:          s.Restored,
+		ControlSocketPath: s.GetControlSocketPath(),
+	}
+	if !s.IsRunning() {
+		info.Pid = 0
+		info.HealthError = "sandbox is not running"
+		return info
+	}
+	if !s.StartTime.IsZero() {
+		info.UptimeSeconds = time.Since(s.StartTime).Seconds()
+	}
+	m, err := s.Usage(false)
+	if err != nil {
+		info.HealthError = err.Error()
+		return info
+	}
+	info.Healthy = true
+	info.Memory = &m
+	return info
+}
@@ -20,8 +20,10 @@ import (
 	"strings"
 	"testing"
 
+	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"gvisor.dev/gvisor/pkg/test/testutil"
 	"gvisor.dev/gvisor/runsc/container"
+	"gvisor.dev/gvisor/runsc/sandbox"
 )
 
 func TestList(t *testing.T) {
@@ -146,3 +148,43 @@ func TestList(t *testing.T) {
 		})
 	}
 }
+
+func TestSandboxInfos(t *testing.T) {
+	abc := &sandbox.Sandbox{ID: "abc", Platform: "systrap", Checkpointed: true}
+	def := &sandbox.Sandbox{ID: "def"}
+	newContainer := func(id string, s *sandbox.Sandbox) *container.Container {
+		return &container.Container{
+			ID:      id,
+			Spec:    &specs.Spec{},
+			Status:  container.Stopped,
+			Sandbox: s,
+		}
+	}
+	containers := []*container.Container{
+		newContainer("123", abc),
+		newContainer("abc", abc),
+		newContainer("def", def),
+		newContainer("456", nil),
+	}
+
+	infos := sandboxInfos(containers)
+	if len(infos) != 2 {
+		t.Fatalf("sandboxInfos returned %d sandboxes, want 2: %+v", len(infos), infos)
+	}
+	got := infos[0]
+	if got.ID != "abc" || got.Platform != "systrap" || !got.Checkpointed {
+		t.Errorf("sandbox abc: got %+v", got)
+	}
+	if len(got.Containers) != 2 || got.Containers[0].ID != "abc" || got.Containers[1].ID != "123" {
+		t.Errorf("sandbox abc: got containers %+v, want root container first", got.Containers)
+	}
+	if got.Status != container.Stopped {
+		t.Errorf("sandbox abc: got status %q, want %q", got.Status, container.Stopped)
+	}
+	// The sandboxes aren't running.
+	for _, info := range infos {
+		if info.Healthy || info.HealthError == "" || info.Pid != 0 || info.Memory != nil {
+			t.Errorf("sandbox %s: got %+v, want unhealthy", info.ID, info)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"google.golang.org/protobuf/encoding/prototext"
 	"gvisor.dev/gvisor/pkg/metric"
+	"gvisor.dev/gvisor/pkg/sentry/runtimestats"
 	"gvisor.dev/gvisor/runsc/cmd/util"
 	"gvisor.dev/gvisor/runsc/config"
 	"gvisor.dev/gvisor/runsc/flag"
@@ -58,6 +59,8 @@ func (m *MetricMetadata) FetchSpec(conf *config.Config, f *flag.FlagSet) (string
 
 // Execute implements subcommands.Command.Execute.
 func (m *MetricMetadata) Execute(ctx context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
+	// Metrics that the sentry registers explicitly are part of this build too.
+	runtimestats.RegisterMetrics()
 	if err := metric.Initialize(); err != nil {
 		util.Fatalf("Cannot initialize metrics: %v", err)
 	}
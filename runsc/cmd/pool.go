@@ -0,0 +1,122 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/google/subcommands"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/runsc/cmd/util"
+	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/container"
+	"gvisor.dev/gvisor/runsc/flag"
+	"gvisor.dev/gvisor/runsc/specutils"
+)
+
+// Pool implements subcommands.Command for the "pool" command.
+type Pool struct {
+	bundleDir string
+	size      int
+}
+
+// Name implements subcommands.Command.Name.
+func (*Pool) Name() string {
+	return "pool"
+}
+
+// Synopsis implements subcommands.Command.Synopsis.
+func (*Pool) Synopsis() string {
+	return "manage pools of sandboxes booted ahead of time"
+}
+
+// Usage implements subcommands.Command.Usage.
+func (*Pool) Usage() string {
+	return `pool [flags] fill|list|drain <pool name> - manage a pool of idle sandboxes.
+
+A pool holds sandboxes that are booted ahead of time, each running the
+placeholder container in the bundle passed to "fill", e.g. a pause container.
+A root container created with the "` + specutils.AnnotationSandboxPool + `" annotation set to
+the pool name claims one of these sandboxes and runs in it, instead of booting
+a new sandbox. The claimed sandbox is moved to the container's cgroup, and is
+destroyed with the container. Only sandboxes created with the same runsc flags,
+network namespace and gVisor annotations as the container are claimed.
+
+  fill:  boot sandboxes until the pool has --size idle sandboxes.
+  list:  print the IDs of the pool's idle sandboxes.
+  drain: destroy the pool's idle sandboxes.
+`
+}
+
+// SetFlags implements subcommands.Command.SetFlags.
+func (p *Pool) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&p.bundleDir, "bundle", "", "path to the bundle of the placeholder container run by pooled sandboxes, for fill. Defaults to the current directory")
+	f.IntVar(&p.size, "size", 1, "number of idle sandboxes that the pool should have after fill")
+}
+
+// FetchSpec implements util.SubCommand.FetchSpec.
+func (p *Pool) FetchSpec(conf *config.Config, f *flag.FlagSet) (string, *specs.Spec, error) {
+	// This command does not operate on a single container, so nothing to fetch.
+	return "", nil, nil
+}
+
+// Execute implements subcommands.Command.Execute.
+func (p *Pool) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
+	if f.NArg() != 2 {
+		f.Usage()
+		return subcommands.ExitUsageError
+	}
+	conf := args[0].(*config.Config)
+	if conf.Rootless {
+		return util.Errorf("Rootless mode not supported with %q", p.Name())
+	}
+	name := f.Arg(1)
+
+	switch f.Arg(0) {
+	case "fill":
+		if p.size < 0 {
+			return util.Errorf("--size must not be negative")
+		}
+		bundleDir := p.bundleDir
+		if bundleDir == "" {
+			bundleDir = getwdOrDie()
+		}
+		ids, err := container.FillPool(conf, name, bundleDir, p.size)
+		for _, id := range ids {
+			fmt.Fprintln(os.Stdout, id)
+		}
+		if err != nil {
+			return util.Errorf("filling pool %q: %v", name, err)
+		}
+	case "list":
+		ids, err := container.ListPool(conf.RootDir, name)
+		if err != nil {
+			return util.Errorf("%v", err)
+		}
+		for _, id := range ids {
+			fmt.Fprintln(os.Stdout, id)
+		}
+	case "drain":
+		if err := container.DrainPool(conf, name); err != nil {
+			return util.Errorf("draining pool %q: %v", name, err)
+		}
+	default:
+		f.Usage()
+		return subcommands.ExitUsageError
+	}
+	return subcommands.ExitSuccess
+}
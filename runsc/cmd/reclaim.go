@@ -0,0 +1,98 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/subcommands"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/runsc/cmd/util"
+	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/container"
+	"gvisor.dev/gvisor/runsc/flag"
+)
+
+// Reclaim implements subcommands.Command for the "reclaim" command.
+type Reclaim struct {
+	containerLoader
+	bytes uint64
+}
+
+// Name implements subcommands.Command.Name.
+func (*Reclaim) Name() string {
+	return "reclaim"
+}
+
+// Synopsis implements subcommands.Command.Synopsis.
+func (*Reclaim) Synopsis() string {
+	return "ask a sandbox to return memory to the host"
+}
+
+// Usage implements subcommands.Command.Usage.
+func (*Reclaim) Usage() string {
+	return `reclaim [flags] <container id> - ask the sandbox running the container to
+return memory to the host, e.g. in response to host memory pressure.
+
+The sandbox drops clean page cache, returns freed application memory to the
+host and, if needed, shrinks its own heap. The amount of memory returned, in
+bytes, is printed in JSON format.
+`
+}
+
+// SetFlags implements subcommands.Command.SetFlags.
+func (r *Reclaim) SetFlags(f *flag.FlagSet) {
+	f.Uint64Var(&r.bytes, "bytes", 0, "number of bytes of memory to return to the host. 0 returns as much memory as possible.")
+}
+
+// FetchSpec implements util.SubCommand.FetchSpec.
+func (r *Reclaim) FetchSpec(conf *config.Config, f *flag.FlagSet) (string, *specs.Spec, error) {
+	c, err := r.loadContainer(conf, f, container.LoadOpts{})
+	if err != nil {
+		return "", nil, fmt.Errorf("loading container: %w", err)
+	}
+	return c.ID, c.Spec, nil
+}
+
+// Execute implements subcommands.Command.Execute.
+func (r *Reclaim) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
+	if f.NArg() != 1 {
+		f.Usage()
+		return subcommands.ExitUsageError
+	}
+
+	conf := args[0].(*config.Config)
+
+	cont, err := r.loadContainer(conf, f, container.LoadOpts{})
+	if err != nil {
+		util.Fatalf("loading container: %v", err)
+	}
+	if !cont.IsSandboxRunning() {
+		util.Fatalf("sandbox of container %q is not running", cont.ID)
+	}
+
+	result, err := cont.Sandbox.Reclaim(r.bytes)
+	if err != nil {
+		util.Fatalf("reclaim failed: %v", err)
+	}
+	encoder := json.NewEncoder(&util.Writer{})
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		util.Fatalf("Encode ReclaimResult failed: %v", err)
+	}
+	return subcommands.ExitSuccess
+}
@@ -28,6 +28,7 @@ import (
 	"gvisor.dev/gvisor/runsc/config"
 	"gvisor.dev/gvisor/runsc/container"
 	"gvisor.dev/gvisor/runsc/flag"
+	"gvisor.dev/gvisor/runsc/sandbox"
 	"gvisor.dev/gvisor/runsc/specutils"
 )
 
@@ -57,6 +58,25 @@ type Restore struct {
 	// uncompressed for background to work; if the checkpoint is compressed,
 	// background has no effect.
 	background bool
+
+	// If template is true, the container image is used as a template from
+	// which many sandboxes may be restored. The restore command returns as soon
+	// as the kernel state is loaded, and each memory page is copied from the
+	// image into the sandbox's memory only when it is first accessed, so each
+	// sandbox only allocates memory for the pages it accesses. Sandboxes don't
+	// share memory for the pages they have accessed. The image must not be
+	// modified or removed while such sandboxes are running.
+	template bool
+
+	// netnsConfig is the path to a CNI result whose addresses and routes are
+	// applied to the sandbox network namespace before restoring, e.g. when
+	// the sandbox is restored on a node other than the one it was
+	// checkpointed on.
+	netnsConfig string
+
+	// validate indicates that the checkpoint should only be checked for
+	// restorability, without creating or restoring the container.
+	validate bool
 }
 
 // Name implements subcommands.Command.Name.
@@ -77,10 +97,13 @@ func (*Restore) Usage() string {
 // SetFlags implements subcommands.Command.SetFlags.
 func (r *Restore) SetFlags(f *flag.FlagSet) {
 	r.Create.SetFlags(f)
-	f.StringVar(&r.imagePath, "image-path", "", "directory path to saved container image")
+	f.StringVar(&r.imagePath, "image-path", "", "directory path to saved container image, or an HTTP(S) URL from which the image is streamed")
 	f.BoolVar(&r.detach, "detach", false, "detach from the container's process")
 	f.BoolVar(&r.direct, "direct", false, "use O_DIRECT for reading checkpoint pages file")
 	f.BoolVar(&r.background, "background", false, "allow image loading to continue after restore exits (requires uncompressed checkpoint)")
+	f.BoolVar(&r.template, "template", false, "use the image as a template for many restores: return once the kernel is restored and copy each memory page from the image into sandbox memory only when first accessed (requires uncompressed checkpoint with a separate pages file; incompatible with --direct)")
+	f.BoolVar(&r.validate, "validate", false, "only check that the checkpoint can be restored with the given spec on this host, and print the result of each check, without creating or restoring the container")
+	f.StringVar(&r.netnsConfig, "netns-config", "", "path to a CNI result with the addresses and routes to assign in the sandbox network namespace before restoring (requires --network=sandbox)")
 
 	// Unimplemented flags necessary for compatibility with docker.
 
@@ -138,6 +161,24 @@ func (r *Restore) Execute(_ context.Context, f *flag.FlagSet, args ...any) subco
 	if r.imagePath == "" {
 		return util.Errorf("image-path flag must be provided")
 	}
+	if r.template && r.direct {
+		return util.Errorf("template and direct flags are mutually exclusive")
+	}
+
+	if r.validate {
+		spec := r.spec
+		if c, err := r.loadContainer(conf, f, container.LoadOpts{}); err == nil {
+			spec = c.Spec
+		} else if spec == nil {
+			if spec, err = specutils.ReadSpec(bundleDir, conf); err != nil {
+				return util.Errorf("reading spec: %v", err)
+			}
+		}
+		if !printRestoreChecks(os.Stdout, validateRestore(conf, spec, r.imagePath)) {
+			return subcommands.ExitFailure
+		}
+		return subcommands.ExitSuccess
+	}
 
 	var cu cleanup.Cleanup
 	defer cu.Clean()
@@ -183,8 +224,18 @@ func (r *Restore) Execute(_ context.Context, f *flag.FlagSet, args ...any) subco
 		runArgs.Spec = c.Spec
 	}
 
-	log.Debugf("Restore: %v", r.imagePath)
-	err = c.Restore(conf, r.imagePath, r.direct, r.background, nil /* networkArgs */)
+	if r.netnsConfig != "" && c.IsSandboxRoot() {
+		netnsConf, err := sandbox.LoadNetNSConfig(r.netnsConfig)
+		if err != nil {
+			return util.Errorf("loading network namespace config: %v", err)
+		}
+		if err := c.Sandbox.ApplyNetNSConfig(conf, netnsConf); err != nil {
+			return util.Errorf("applying network namespace config: %v", err)
+		}
+	}
+
+	log.Debugf("Restore: %v", sandbox.RedactImagePath(r.imagePath))
+	err = c.Restore(conf, r.imagePath, r.direct, r.background, r.template, nil /* networkArgs */)
 	if err != nil {
 		return util.Errorf("starting container: %v", err)
 	}
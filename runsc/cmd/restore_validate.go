@@ -0,0 +1,240 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/pkg/sentry/state/checkpointfiles"
+	"gvisor.dev/gvisor/pkg/state/pretty"
+	"gvisor.dev/gvisor/pkg/state/statefile"
+	"gvisor.dev/gvisor/runsc/boot"
+	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/sandbox"
+	"gvisor.dev/gvisor/runsc/specutils"
+)
+
+// restoreCheck is the result of one check performed by "restore --validate".
+type restoreCheck struct {
+	// Name identifies the check.
+	Name string
+
+	// Err is nil if the check passed.
+	Err error
+
+	// Detail describes the result of a successful check.
+	Detail string
+}
+
+// validateRestore checks that the checkpoint at imagePath can be restored
+// into a container with the given spec on this host, without creating a
+// sandbox or starting any tasks. It returns the result of each check.
+func validateRestore(conf *config.Config, spec *specs.Spec, imagePath string) []restoreCheck {
+	if sandbox.IsImageURL(imagePath) {
+		return []restoreCheck{{
+			Name: "image",
+			Err:  fmt.Errorf("validating checkpoint images at URLs is not supported"),
+		}}
+	}
+
+	var checks []restoreCheck
+	metadata, check := validateStatefile(imagePath)
+	checks = append(checks, check)
+	checks = append(checks, validatePagesFiles(imagePath))
+	if metadata != nil {
+		checks = append(checks, validateCompatibility(conf, metadata))
+		checks = append(checks, validateRestoreSpec(conf, spec, metadata))
+	}
+	checks = append(checks, validateMountSources(spec))
+	checks = append(checks, validateDevices(spec))
+	return checks
+}
+
+// validateStatefile reads the entire statefile in imagePath, which checks its
+// integrity and that its object stream is well-formed, and returns its
+// metadata.
+func validateStatefile(imagePath string) (map[string]string, restoreCheck) {
+	check := restoreCheck{Name: "statefile"}
+	f, err := os.Open(filepath.Join(imagePath, checkpointfiles.StateFileName))
+	if err != nil {
+		check.Err = err
+		return nil, check
+	}
+	defer f.Close()
+	r, metadata, err := statefile.NewReader(f, nil)
+	if err != nil {
+		check.Err = fmt.Errorf("reading statefile header: %w", err)
+		return nil, check
+	}
+	defer r.Close()
+	cr := &countingReader{r: r}
+	if err := pretty.PrintText(io.Discard, cr); err != nil {
+		check.Err = fmt.Errorf("decoding statefile: %w", err)
+		return metadata, check
+	}
+	check.Detail = fmt.Sprintf("%d bytes of kernel state decoded", cr.n)
+	return metadata, check
+}
+
+// countingReader is an io.Reader that counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read implements io.Reader.Read.
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// validatePagesFiles checks that the memory files in imagePath, if any, are
+// present together.
+func validatePagesFiles(imagePath string) restoreCheck {
+	check := restoreCheck{Name: "pages"}
+	_, metaErr := os.Stat(filepath.Join(imagePath, checkpointfiles.PagesMetadataFileName))
+	fi, pagesErr := os.Stat(filepath.Join(imagePath, checkpointfiles.PagesFileName))
+	switch {
+	case os.IsNotExist(metaErr) && os.IsNotExist(pagesErr):
+		check.Detail = "memory is stored in the statefile"
+	case metaErr != nil:
+		check.Err = fmt.Errorf("pages file is present, but pages metadata file is not usable: %w", metaErr)
+	case pagesErr != nil:
+		check.Err = fmt.Errorf("pages metadata file is present, but pages file is not usable: %w", pagesErr)
+	default:
+		check.Detail = fmt.Sprintf("%d bytes of memory in %s", fi.Size(), checkpointfiles.PagesFileName)
+	}
+	return check
+}
+
+// validateCompatibility checks that the checkpoint with the given metadata is
+// compatible with this host; see boot.CheckRestoreCompatibility.
+func validateCompatibility(conf *config.Config, metadata map[string]string) restoreCheck {
+	check := restoreCheck{Name: "compatibility"}
+	var fatal, warnings []string
+	for _, issue := range boot.CheckRestoreCompatibility(metadata, restoreHost(conf, metadata)) {
+		if issue.Fatal {
+			fatal = append(fatal, issue.String())
+		} else {
+			warnings = append(warnings, issue.String())
+		}
+	}
+	if len(fatal) > 0 {
+		check.Err = fmt.Errorf("%s", strings.Join(fatal, "; "))
+	} else if len(warnings) > 0 {
+		check.Detail = "compatible, with differences: " + strings.Join(warnings, "; ")
+	} else {
+		check.Detail = "no differences from this host"
+	}
+	return check
+}
+
+// validateRestoreSpec checks spec against the container specs saved in the
+// checkpoint, according to conf.RestoreSpecValidation.
+func validateRestoreSpec(conf *config.Config, spec *specs.Spec, metadata map[string]string) restoreCheck {
+	check := restoreCheck{Name: "spec"}
+	saved, ok := metadata[boot.ContainerSpecsKey]
+	if !ok {
+		check.Err = fmt.Errorf("container specs not found in checkpoint metadata")
+		return check
+	}
+	oldSpecs, err := specutils.GetSpecsFromString(saved)
+	if err != nil {
+		check.Err = err
+		return check
+	}
+	name := specutils.ContainerName(spec)
+	if name == "" {
+		if !specutils.IsRootContainer(spec) {
+			check.Detail = "skipped for unnamed subcontainer, which is matched by creation order"
+			return check
+		}
+		// See Loader.registerContainerLocked.
+		name = "__no_name_0"
+	}
+	if err := specutils.ValidateSpecs(oldSpecs, map[string]*specs.Spec{name: spec}); err != nil {
+		if conf.RestoreSpecValidation == config.RestoreSpecValidationEnforce {
+			check.Err = err
+			return check
+		}
+		check.Detail = fmt.Sprintf("spec differs from checkpoint, allowed by --restore-spec-validation=%s: %v", conf.RestoreSpecValidation, err)
+		return check
+	}
+	check.Detail = fmt.Sprintf("spec for container %q matches checkpoint", name)
+	return check
+}
+
+// validateMountSources checks that the sources of spec's bind mounts exist.
+func validateMountSources(spec *specs.Spec) restoreCheck {
+	check := restoreCheck{Name: "mounts"}
+	var missing []string
+	n := 0
+	for _, m := range spec.Mounts {
+		if !specutils.IsGoferMount(m) {
+			continue
+		}
+		n++
+		if _, err := os.Stat(m.Source); err != nil {
+			missing = append(missing, fmt.Sprintf("%s (for %s): %v", m.Source, m.Destination, err))
+		}
+	}
+	if len(missing) > 0 {
+		check.Err = fmt.Errorf("missing mount sources: %s", strings.Join(missing, "; "))
+		return check
+	}
+	check.Detail = fmt.Sprintf("%d bind mount sources exist", n)
+	return check
+}
+
+// validateDevices checks that the devices in spec exist on the host.
+func validateDevices(spec *specs.Spec) restoreCheck {
+	check := restoreCheck{Name: "devices"}
+	if spec.Linux == nil {
+		check.Detail = "no devices"
+		return check
+	}
+	var missing []string
+	for _, d := range spec.Linux.Devices {
+		if _, err := os.Stat(d.Path); err != nil {
+			missing = append(missing, fmt.Sprintf("%s: %v", d.Path, err))
+		}
+	}
+	if len(missing) > 0 {
+		check.Err = fmt.Errorf("missing devices: %s", strings.Join(missing, "; "))
+		return check
+	}
+	check.Detail = fmt.Sprintf("%d devices exist", len(spec.Linux.Devices))
+	return check
+}
+
+// printRestoreChecks prints checks, and returns true if all passed.
+func printRestoreChecks(w io.Writer, checks []restoreCheck) bool {
+	ok := true
+	for _, c := range checks {
+		if c.Err != nil {
+			ok = false
+			fmt.Fprintf(w, "FAIL %s: %v\n", c.Name, c.Err)
+		} else {
+			fmt.Fprintf(w, "PASS %s: %s\n", c.Name, c.Detail)
+		}
+	}
+	return ok
+}
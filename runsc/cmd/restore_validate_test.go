@@ -0,0 +1,72 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/pkg/sentry/state/checkpointfiles"
+)
+
+func TestValidatePagesFiles(t *testing.T) {
+	dir := t.TempDir()
+	if c := validatePagesFiles(dir); c.Err != nil {
+		t.Errorf("validatePagesFiles with no pages files failed: %v", c.Err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, checkpointfiles.PagesFileName), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if c := validatePagesFiles(dir); c.Err == nil {
+		t.Errorf("validatePagesFiles without pages metadata file succeeded: %s", c.Detail)
+	}
+	if err := os.WriteFile(filepath.Join(dir, checkpointfiles.PagesMetadataFileName), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if c := validatePagesFiles(dir); c.Err != nil {
+		t.Errorf("validatePagesFiles with both pages files failed: %v", c.Err)
+	}
+}
+
+func TestValidateMountsAndDevices(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+	spec := &specs.Spec{
+		Mounts: []specs.Mount{
+			{Destination: "/proc", Type: "proc", Source: "proc"},
+			{Destination: "/data", Type: "bind", Source: dir},
+		},
+		Linux: &specs.Linux{
+			Devices: []specs.LinuxDevice{{Path: "/dev/null"}},
+		},
+	}
+	if c := validateMountSources(spec); c.Err != nil {
+		t.Errorf("validateMountSources failed: %v", c.Err)
+	}
+	if c := validateDevices(spec); c.Err != nil {
+		t.Errorf("validateDevices failed: %v", c.Err)
+	}
+
+	spec.Mounts = append(spec.Mounts, specs.Mount{Destination: "/missing", Type: "none", Source: missing, Options: []string{"rbind"}})
+	spec.Linux.Devices = append(spec.Linux.Devices, specs.LinuxDevice{Path: missing})
+	if c := validateMountSources(spec); c.Err == nil {
+		t.Errorf("validateMountSources with missing source succeeded: %s", c.Detail)
+	}
+	if c := validateDevices(spec); c.Err == nil {
+		t.Errorf("validateDevices with missing device succeeded: %s", c.Detail)
+	}
+}
@@ -519,7 +519,8 @@ func SetupDev(spec *specs.Spec, conf *config.Config, root, procPath string) erro
 	tpuproxyEnabled := specutils.TPUProxyEnabled(spec, conf)
 	for _, dev := range spec.Linux.Devices {
 		shouldMount := (nvproxyEnabled && ShouldExposeNvidiaDevice(dev.Path)) ||
-			(tpuproxyEnabled && ShouldExposeTpuDevice(dev.Path))
+			(tpuproxyEnabled && ShouldExposeTpuDevice(dev.Path)) ||
+			specutils.HostDeviceProxied(conf, &dev)
 		if !shouldMount {
 			continue
 		}
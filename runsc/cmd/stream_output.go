@@ -0,0 +1,133 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/google/subcommands"
+	"gvisor.dev/gvisor/runsc/boot"
+	"gvisor.dev/gvisor/runsc/cmd/util"
+	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/container"
+	"gvisor.dev/gvisor/runsc/flag"
+)
+
+// StreamOutput implements subcommands.Command for the "stream-output" command.
+type StreamOutput struct {
+	containerLoader
+	replay int
+	follow bool
+	raw    bool
+}
+
+// Name implements subcommands.Command.Name.
+func (*StreamOutput) Name() string {
+	return "stream-output"
+}
+
+// Synopsis implements subcommands.Command.Synopsis.
+func (*StreamOutput) Synopsis() string {
+	return "stream stdout and stderr of a running container"
+}
+
+// Usage implements subcommands.Command.Usage.
+func (*StreamOutput) Usage() string {
+	return `stream-output [flags] <container-id> - stream stdout and stderr of a running container
+
+The container's output must be relayed through the sandbox, which requires the
+sandbox to be started with --stdio-relay and the container to run without a
+terminal. Any number of readers can stream output at the same time, and up to
+256KiB of past output can be replayed to each of them.
+
+Output is written to stdout and stderr of this command. With --raw, both
+streams are written to stdout in frames: an 8-byte header, where the first
+byte is the stream (1 for stdout, 2 for stderr) and the last 4 bytes are the
+big-endian size of the payload that follows, as done by "docker attach" for
+containers without a terminal.
+
+EXAMPLE:
+       # runsc stream-output --replay=65536 --follow <container-id>
+`
+}
+
+// SetFlags implements subcommands.Command.SetFlags.
+func (s *StreamOutput) SetFlags(f *flag.FlagSet) {
+	f.IntVar(&s.replay, "replay", 0, "number of bytes of past output to write before new output")
+	f.BoolVar(&s.follow, "follow", false, "keep streaming new output until the container's output is closed")
+	f.BoolVar(&s.raw, "raw", false, "write framed output to stdout instead of demultiplexing it")
+}
+
+// Execute implements subcommands.Command.Execute.
+func (s *StreamOutput) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
+	if f.NArg() != 1 {
+		f.Usage()
+		return subcommands.ExitUsageError
+	}
+	if s.replay < 0 {
+		util.Fatalf("--replay must not be negative")
+	}
+
+	conf := args[0].(*config.Config)
+	c, err := s.loadContainer(conf, f, container.LoadOpts{})
+	if err != nil {
+		util.Fatalf("loading container: %v", err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		util.Fatalf("creating pipe: %v", err)
+	}
+	defer r.Close()
+	streamArgs := boot.StreamOutputArgs{
+		ContainerID: c.ID,
+		ReplayBytes: s.replay,
+		Follow:      s.follow,
+	}
+	streamArgs.Files = []*os.File{w}
+	err = c.Sandbox.StreamOutput(&streamArgs)
+	// The sandbox has its own copy of the write end, which is closed once the
+	// output ends.
+	w.Close()
+	if err != nil {
+		util.Fatalf("%v", err)
+	}
+
+	if s.raw {
+		if _, err := io.Copy(os.Stdout, r); err != nil {
+			util.Fatalf("copying output: %v", err)
+		}
+		return subcommands.ExitSuccess
+	}
+	outs := map[int]*os.File{1: os.Stdout, 2: os.Stderr}
+	for {
+		stream, payload, err := boot.ReadOutputFrame(r)
+		if err == io.EOF {
+			return subcommands.ExitSuccess
+		}
+		if err != nil {
+			util.Fatalf("reading output: %v", err)
+		}
+		out, ok := outs[stream]
+		if !ok {
+			util.Fatalf("output for unknown stream %d", stream)
+		}
+		if _, err := out.Write(payload); err != nil {
+			util.Fatalf("writing output: %v", err)
+		}
+	}
+}
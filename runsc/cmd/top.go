@@ -0,0 +1,126 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/subcommands"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/pkg/sentry/control"
+	"gvisor.dev/gvisor/runsc/cmd/util"
+	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/container"
+	"gvisor.dev/gvisor/runsc/flag"
+)
+
+// Top implements subcommands.Command for the "top" command.
+type Top struct {
+	containerLoader
+	interval   time.Duration
+	iterations int
+	format     string
+}
+
+// Name implements subcommands.Command.Name.
+func (*Top) Name() string {
+	return "top"
+}
+
+// Synopsis implements subcommands.Command.Synopsis.
+func (*Top) Synopsis() string {
+	return "display the CPU and memory usage of the tasks running inside a container"
+}
+
+// Usage implements subcommands.Command.Usage.
+func (*Top) Usage() string {
+	return `top [flags] <container id>
+
+Repeatedly samples the tasks (threads) running in a container and prints their
+state, CPU utilization since the previous sample, total CPU time, resident set
+size in KiB and name, sorted by decreasing CPU utilization. It runs until
+interrupted, or for --iterations samples.
+`
+}
+
+// SetFlags implements subcommands.Command.SetFlags.
+func (t *Top) SetFlags(f *flag.FlagSet) {
+	f.DurationVar(&t.interval, "interval", time.Second, "time between samples.")
+	f.IntVar(&t.iterations, "iterations", 0, "number of samples to print before exiting, or 0 to run until interrupted.")
+	f.StringVar(&t.format, "format", "table", "output format. Select one of: table or json (default: table)")
+}
+
+// FetchSpec implements util.SubCommand.FetchSpec.
+func (t *Top) FetchSpec(conf *config.Config, f *flag.FlagSet) (string, *specs.Spec, error) {
+	c, err := t.loadContainer(conf, f, container.LoadOpts{SkipCheck: true})
+	if err != nil {
+		return "", nil, fmt.Errorf("loading container: %w", err)
+	}
+	return c.ID, c.Spec, nil
+}
+
+// Execute implements subcommands.Command.Execute.
+func (t *Top) Execute(ctx context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
+	if f.NArg() != 1 {
+		f.Usage()
+		return subcommands.ExitUsageError
+	}
+	if t.interval <= 0 {
+		util.Fatalf("--interval must be positive")
+	}
+	if t.format != "table" && t.format != "json" {
+		util.Fatalf("unsupported format: %s", t.format)
+	}
+
+	conf := args[0].(*config.Config)
+	c, err := t.loadContainer(conf, f, container.LoadOpts{SkipCheck: true})
+	if err != nil {
+		util.Fatalf("loading container: %v", err)
+	}
+
+	prev, err := c.TaskUsage()
+	if err != nil {
+		util.Fatalf("getting task usage for container: %v", err)
+	}
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	encoder := json.NewEncoder(os.Stdout)
+	for i := 0; t.iterations == 0 || i < t.iterations; i++ {
+		select {
+		case <-ctx.Done():
+			return subcommands.ExitSuccess
+		case <-ticker.C:
+		}
+		cur, err := c.TaskUsage()
+		if err != nil {
+			util.Fatalf("getting task usage for container: %v", err)
+		}
+		cur.ComputeCPU(prev)
+		switch t.format {
+		case "table":
+			fmt.Printf("%s\n\n", control.TaskUsagesToTable(cur))
+		case "json":
+			if err := encoder.Encode(cur); err != nil {
+				util.Fatalf("generating JSON: %v", err)
+			}
+		}
+		prev = cur
+	}
+	return subcommands.ExitSuccess
+}
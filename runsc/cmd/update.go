@@ -105,6 +105,9 @@ The accepted format is as follows (unchanged values can be omitted):
   },
   "blockIO": {
     "weight": 0
+  },
+  "pids": {
+    "limit": 0
   }
 }
 
@@ -283,7 +286,11 @@ func (u *Update) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcom
 		}
 	}
 
-	if err := c.Update(&r); err != nil {
+	if r.Pids == nil {
+		r.Pids = prev.Pids
+	}
+
+	if err := c.Update(conf, &r); err != nil {
 		return util.Errorf("setting resources: %v", err)
 	}
 
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/google/subcommands"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
@@ -43,6 +44,8 @@ type Wait struct {
 	restore      bool
 	fsCheckpoint bool
 	fsRestore    bool
+	all          bool
+	timeout      time.Duration
 }
 
 // Name implements subcommands.Command.Name.
@@ -52,12 +55,20 @@ func (*Wait) Name() string {
 
 // Synopsis implements subcommands.Command.Synopsis.
 func (*Wait) Synopsis() string {
-	return "wait on a process inside a container"
+	return "wait on a process inside a container, or on containers in a sandbox"
 }
 
 // Usage implements subcommands.Command.Usage.
 func (*Wait) Usage() string {
-	return "wait [flags] <container id>\n"
+	return `wait [flags] <container id> [<container id>...]
+
+Waits for the init process of a container to exit and prints its exit status.
+When several containers of the same sandbox are given, waits for the first one
+to exit, or for all of them with --all, and prints the exit status of each
+container that exited in the order they exited. With --timeout, it fails if the
+containers haven't exited in time, after printing the exit status of those that
+have.
+`
 }
 
 // SetFlags implements subcommands.Command.SetFlags.
@@ -68,6 +79,8 @@ func (wt *Wait) SetFlags(f *flag.FlagSet) {
 	f.BoolVar(&wt.restore, "restore", false, "wait for the restore to complete")
 	f.BoolVar(&wt.fsCheckpoint, "fscheckpoint", false, "wait for the next filesystem checkpoint to complete")
 	f.BoolVar(&wt.fsRestore, "fsrestore", false, "wait for the filesystem restore to complete")
+	f.BoolVar(&wt.all, "all", false, "wait for all given containers to exit instead of the first one")
+	f.DurationVar(&wt.timeout, "timeout", 0, "maximum time to wait for containers to exit, or 0 to wait forever")
 }
 
 // FetchSpec implements util.SubCommand.FetchSpec.
@@ -82,7 +95,7 @@ func (wt *Wait) FetchSpec(conf *config.Config, f *flag.FlagSet) (string, *specs.
 // Execute implements subcommands.Command.Execute. It waits for a process in a
 // container to exit before returning.
 func (wt *Wait) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
-	if f.NArg() != 1 {
+	if f.NArg() < 1 {
 		f.Usage()
 		return subcommands.ExitUsageError
 	}
@@ -94,6 +107,14 @@ func (wt *Wait) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomm
 		util.Fatalf("at most one of -checkpoint, -restore, -fscheckpoint, -fsrestore may be set")
 	}
 
+	if wt.timeout < 0 {
+		util.Fatalf("-timeout must not be negative")
+	}
+	waitProcess := wt.rootPID != unsetPID || wt.pid != unsetPID || wt.checkpoint || wt.restore || wt.fsCheckpoint || wt.fsRestore
+	if waitProcess && (f.NArg() > 1 || wt.timeout != 0 || wt.all) {
+		util.Fatalf("-pid, -rootpid, -checkpoint, -restore, -fscheckpoint and -fsrestore can't be used with several containers, -timeout or -all")
+	}
+
 	conf := args[0].(*config.Config)
 
 	c, err := wt.loadContainer(conf, f, container.LoadOpts{})
@@ -101,6 +122,18 @@ func (wt *Wait) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomm
 		util.Fatalf("loading container: %v", err)
 	}
 
+	if f.NArg() > 1 || wt.timeout != 0 {
+		containers := []*container.Container{c}
+		for _, id := range f.Args()[1:] {
+			other, err := container.Load(conf.RootDir, container.FullID{ContainerID: id}, container.LoadOpts{})
+			if err != nil {
+				util.Fatalf("loading container %q: %v", id, err)
+			}
+			containers = append(containers, other)
+		}
+		return wt.waitContainers(containers)
+	}
+
 	if wt.checkpoint {
 		if wt.rootPID != unsetPID || wt.pid != unsetPID {
 			log.Warningf("waiting for checkpoint to complete, ignoring -pid and -rootpid")
@@ -176,6 +209,29 @@ func (wt *Wait) Execute(_ context.Context, f *flag.FlagSet, args ...any) subcomm
 	return subcommands.ExitSuccess
 }
 
+// waitContainers waits for any or all containers to exit, with a timeout.
+func (wt *Wait) waitContainers(containers []*container.Container) subcommands.ExitStatus {
+	res, err := container.WaitContainers(containers, wt.all, wt.timeout)
+	if err != nil {
+		util.Fatalf("waiting on containers: %v", err)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	for _, exit := range res.Exited {
+		result := waitResult{
+			ID:         exit.CID,
+			ExitStatus: exitStatus(unix.WaitStatus(exit.WaitStatus)),
+		}
+		// Write json-encoded wait result directly to stdout.
+		if err := encoder.Encode(result); err != nil {
+			util.Fatalf("marshaling wait result: %v", err)
+		}
+	}
+	if res.TimedOut {
+		util.Fatalf("timed out after %v waiting on containers", wt.timeout)
+	}
+	return subcommands.ExitSuccess
+}
+
 type waitResult struct {
 	ID         string `json:"id"`
 	ExitStatus int    `json:"exitStatus"`
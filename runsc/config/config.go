@@ -19,6 +19,7 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"path/filepath"
 	"reflect"
 	"regexp"
@@ -84,6 +85,10 @@ type Config struct {
 	// PanicLog is the path to log GO's runtime messages, if not empty.
 	PanicLog string `flag:"panic-log"`
 
+	// CrashBundleDir is the directory where a crash bundle is written when
+	// the sentry is about to crash, if not empty.
+	CrashBundleDir string `flag:"crash-bundle-dir"`
+
 	// CoverageReport is the path to write Go coverage information, if not empty.
 	CoverageReport string `flag:"coverage-report"`
 
@@ -104,6 +109,15 @@ type Config struct {
 	// DO NOT call it directly, use GetOverlay2() instead.
 	Overlay2 Overlay2 `flag:"overlay2"`
 
+	// Overlay2UpperWarnSize is the size, in bytes, of a container's rootfs
+	// overlay upper layer above which an "overlay-upper-size-warning" sandbox
+	// event is sent. 0 disables the warning.
+	Overlay2UpperWarnSize uint64 `flag:"overlay2-upper-warn-size"`
+
+	// Overlay2UpperSampleInterval is how often the size of each container's
+	// rootfs overlay upper layer is sampled. 0 disables sampling.
+	Overlay2UpperSampleInterval time.Duration `flag:"overlay2-upper-sample-interval"`
+
 	// FSGoferHostUDS is deprecated: use host-uds=all.
 	FSGoferHostUDS bool `flag:"fsgofer-host-uds"`
 
@@ -135,6 +149,24 @@ type Config struct {
 	// AllowLiveTCPMigration allows TCP connection state to be migrated.
 	AllowLiveTCPMigration bool `flag:"allow-live-tcp-migration"`
 
+	// PacketFilter is the name of the external packet filter, registered with
+	// stack.RegisterPacketFilter, that netstack consults for every forwarded
+	// packet. Empty means no external packet filter.
+	PacketFilter string `flag:"packet-filter"`
+
+	// IPv6SegmentRouting enables processing of IPv6 Segment Routing Headers
+	// (RFC 8754) addressed to the sandbox on interfaces with forwarding
+	// enabled.
+	IPv6SegmentRouting bool `flag:"ipv6-segment-routing"`
+
+	// IPv6MaxHopByHopOptions is the maximum number of options accepted in an
+	// IPv6 Hop-by-Hop Options header. Zero means no limit.
+	IPv6MaxHopByHopOptions int `flag:"ipv6-max-hop-by-hop-options"`
+
+	// IPv6MaxHopByHopLength is the maximum length, in bytes, of an IPv6
+	// Hop-by-Hop Options header. Zero means no limit.
+	IPv6MaxHopByHopLength int `flag:"ipv6-max-hop-by-hop-length"`
+
 	// HostGSO indicates that host segmentation offload is enabled.
 	HostGSO bool `flag:"gso"`
 
@@ -161,13 +193,20 @@ type Config struct {
 	// TBFBurst is the bucket depth in bytes when QDisc=tbf.
 	TBFBurst uint64 `flag:"qdisc-tbf-burst"`
 
+	// NetworkDispatchMode is the mechanism used to read inbound packets from
+	// AF_PACKET sockets backing fd-based links.
+	NetworkDispatchMode NetworkDispatchMode `flag:"network-dispatch-mode"`
+
 	// LogPackets indicates that all network packets should be logged.
 	LogPackets bool `flag:"log-packets"`
 
 	// PCAP is a file to which network packets should be logged in PCAP format.
 	PCAP string `flag:"pcap-log"`
 
-	// Platform is the platform to run on.
+	// Platform is the platform to run on. It may be a comma-separated list of
+	// platforms in order of preference, in which case the first platform that
+	// is available on the host is selected when the sandbox is created. See
+	// PlatformCandidates.
 	Platform string `flag:"platform"`
 
 	// PlatformDevicePath is the path to the device file used by the platform.
@@ -228,9 +267,10 @@ type Config struct {
 	// disabled. Pardon the double negation, but default to enabled is important.
 	DisableSeccomp bool
 
-	// EnableCoreTags indicates whether the Sentry process and children will be
-	// run in a core tagged process. This isolates the sentry from sharing
-	// physical cores with other core tagged processes. This is useful as a
+	// EnableCoreTags indicates whether the Sentry process and children, as
+	// well as gofer processes, will be run in core tagged processes. This
+	// isolates the sandbox from sharing physical cores with other processes,
+	// including other sandboxes. This is useful as a
 	// mitigation for hyperthreading side channel based attacks. Requires host
 	// linux kernel >= 5.14.
 	EnableCoreTags bool `flag:"enable-core-tags"`
@@ -304,6 +344,29 @@ type Config struct {
 	// E.g. 0.2 CPU quota will result in 1, and 1.9 in 2.
 	CPUNumFromQuota bool `flag:"cpu-num-from-quota"`
 
+	// SentryCPUBandwidth enforces each container's CPU quota
+	// (linux.resources.cpu.quota and period) in the Sentry, in addition to any
+	// host cgroup. This throttles containers when host cgroups are
+	// unavailable, e.g. in rootless mode.
+	SentryCPUBandwidth bool `flag:"sentry-cpu-bandwidth"`
+
+	// NUMANodes is the number of virtual NUMA nodes that the sandbox's CPUs
+	// are divided between.
+	NUMANodes int `flag:"numa-nodes"`
+
+	// KernelRelease overrides the Linux release advertised to applications
+	// by uname(2), /proc/version and /proc/sys/kernel/osrelease, for
+	// applications that gate features on the kernel version.
+	KernelRelease string `flag:"kernel-release"`
+
+	// KernelVersion overrides the Linux version string advertised to
+	// applications by uname(2), /proc/version and /proc/sys/kernel/version.
+	KernelVersion string `flag:"kernel-version"`
+
+	// KernelCmdline holds space-separated kernel command-line parameters
+	// reported in /proc/cmdline.
+	KernelCmdline string `flag:"kernel-cmdline"`
+
 	// Allows overriding of flags in OCI annotations.
 	AllowFlagOverride bool `flag:"allow-flag-override"`
 
@@ -339,6 +402,16 @@ type Config struct {
 	// used.
 	DCache int `flag:"dcache"`
 
+	// SharePageCache enables sharing of sentry page cache pages between
+	// identical files in different gofer mounts of the sandbox, e.g. the
+	// same shared library in the rootfs of several containers.
+	SharePageCache bool `flag:"share-page-cache"`
+
+	// UnixMaxInflightFDs is the maximum number of file descriptors that can be
+	// in flight in SCM_RIGHTS messages on Unix domain sockets across the
+	// sandbox. If zero, only the per-user RLIMIT_NOFILE limit applies.
+	UnixMaxInflightFDs int `flag:"unix-max-inflight-fds"`
+
 	// IOUring enables support for the IO_URING API calls to perform
 	// asynchronous I/O operations.
 	IOUring bool `flag:"iouring"`
@@ -348,9 +421,38 @@ type Config struct {
 	// exists, but is mostly idle. Not supported in rootless mode.
 	DirectFS bool `flag:"directfs"`
 
+	// GoferAuth makes the sentry and each gofer authenticate each other over
+	// their lisafs sockets before serving any file, using a key that is
+	// passed to both processes through inherited FDs.
+	GoferAuth bool `flag:"gofer-auth"`
+
 	// AppHugePages enables support for application huge pages.
 	AppHugePages bool `flag:"app-huge-pages"`
 
+	// MemoryReleasePolicy controls when memory freed by the application is
+	// returned to the host: "immediate", "batched" (at most once per
+	// MemoryReleaseDelay), or "idle" (once no memory has been freed for
+	// MemoryReleaseDelay). Delaying release lets later allocations reuse
+	// freed memory, at the cost of higher host memory usage in the meantime.
+	MemoryReleasePolicy string `flag:"memory-release-policy"`
+
+	// MemoryReleaseDelay is the delay used by MemoryReleasePolicy.
+	MemoryReleaseDelay time.Duration `flag:"memory-release-delay"`
+
+	// MemoryCompactionInterval is the interval between memory compaction
+	// passes, which return all freed memory to the host regardless of
+	// MemoryReleasePolicy, along with memory that the host may have
+	// recommitted by collapsing huge pages. 0 disables compaction.
+	MemoryCompactionInterval time.Duration `flag:"memory-compaction-interval"`
+
+	// PageMergingInterval is the interval between same-page merging passes,
+	// which deduplicate identical private anonymous pages within each
+	// container in the sandbox. Merged pages are copied on write, so the
+	// timing of writes reveals whether another process in the same
+	// container has a page with the same contents. 0 disables same-page
+	// merging.
+	PageMergingInterval time.Duration `flag:"page-merging-interval"`
+
 	// NVProxy enables support for Nvidia GPUs.
 	NVProxy bool `flag:"nvproxy"`
 
@@ -376,6 +478,14 @@ type Config struct {
 	// TPUProxy enables support for TPUs.
 	TPUProxy bool `flag:"tpuproxy"`
 
+	// HostDevices enables proxying of character devices in the OCI spec that
+	// have no device-specific support in the sandbox (see
+	// specutils.HostDeviceProxied) to the corresponding host devices. Only
+	// ioctls allowlisted for each device are forwarded. The sandbox's
+	// RLIMIT_NOFILE is raised, even above FDLimit, so that proxied devices
+	// can be opened.
+	HostDevices bool `flag:"host-devices"`
+
 	// TestOnlyAllowRunAsCurrentUserWithoutChroot should only be used in
 	// tests. It allows runsc to start the sandbox process as the current
 	// user, and without chrooting the sandbox process. This can be
@@ -395,6 +505,12 @@ type Config struct {
 	// called. This is useful for tests exercising gVisor panic-reporting.
 	TestOnlyAFSSyscallPanic bool `flag:"TESTONLY-afs-syscall-panic"`
 
+	// TestOnlyEntropySeed should only be used in tests. If not empty, the
+	// sandbox's getrandom(2), /dev/random and /dev/urandom return a
+	// deterministic stream derived from it, so that tests depending on random
+	// data are reproducible.
+	TestOnlyEntropySeed string `flag:"TESTONLY-entropy-seed"`
+
 	// explicitlySet contains whether a flag was explicitly set on the command-line from which this
 	// Config was constructed. Nil when the Config was not initialized from a FlagSet.
 	explicitlySet map[string]struct{}
@@ -422,6 +538,16 @@ type Config struct {
 	// performed during restore.
 	RestoreSpecValidation RestoreSpecValidationPolicy `flag:"restore-spec-validation"`
 
+	// RestoreTimers determines what happens to application timers and TCP
+	// keepalives that expired while the sandbox was checkpointed, when it's
+	// restored.
+	RestoreTimers RestoreTimerPolicy `flag:"restore-timers"`
+
+	// RestoreAddressMap determines how sockets bound to addresses that the
+	// sandbox no longer has after restore are moved to its new addresses:
+	// "auto", "none" or a comma-separated list of OLD=NEW address pairs.
+	RestoreAddressMap string `flag:"restore-address-map"`
+
 	// GVisorMarkerFile enables the /proc/gvisor/kernel_is_gvisor marker file.
 	GVisorMarkerFile bool `flag:"gvisor-marker-file"`
 
@@ -445,6 +571,14 @@ type Config struct {
 	// sentry. This is necessary to support features like rseq.
 	UseCPUNums bool `flag:"kvm-use-cpu-nums"`
 
+	// KVMConditionalIBPB causes the KVM platform to issue an indirect branch
+	// prediction barrier when switching between application address spaces.
+	KVMConditionalIBPB bool `flag:"kvm-conditional-ibpb"`
+
+	// KVMFlushL1D causes the KVM platform to flush the L1 data cache on every
+	// switch to application code.
+	KVMFlushL1D bool `flag:"kvm-flush-l1d"`
+
 	// PauseExternalNetworking indicates whether external networking should be
 	// disabled on sandbox start. This is only supported with sandbox networking
 	// and can be unpaused manually.
@@ -465,6 +599,35 @@ type Config struct {
 	// once their current RPC finishes. Setting this to 0 closes idle clients
 	// immediately.
 	ControlRPCStopTimeout time.Duration `flag:"control-rpc-stop-timeout"`
+
+	// ControlGRPC additionally serves the sandbox control methods over gRPC
+	// on the control socket. See pkg/control/grpcserver.
+	ControlGRPC bool `flag:"control-grpc"`
+
+	// ControlPolicy grants control methods to host users and groups other
+	// than root and the sandbox owner, through one control socket per grant
+	// that only they may access. See server.ParsePolicy for the format and
+	// server.Policy for how it is enforced. Empty means no grants.
+	ControlPolicy string `flag:"control-policy"`
+
+	// StdioRelay relays stdout and stderr of containers without a terminal
+	// through the sandbox, allowing them to be re-attached to new files while
+	// the container runs. See "runsc attach-stdio".
+	StdioRelay bool `flag:"stdio-relay"`
+
+	// ExecDevpts gives interactive exec sessions a pseudo-terminal of the
+	// sandbox's devpts, relayed to the host terminal, so that concurrent
+	// sessions have independent terminals and job control.
+	ExecDevpts bool `flag:"exec-devpts"`
+
+	// GuestAPI creates the guest API device, /dev/gvisor, through which
+	// privileged agents in the sandbox can query the runtime version, flush
+	// caches, signal readiness and request a checkpoint.
+	GuestAPI bool `flag:"guest-api"`
+
+	// PreciseCPUClocks measures the CPU time of tasks with the platform's
+	// cycle clock instead of sampling running tasks every CPU clock tick.
+	PreciseCPUClocks bool `flag:"precise-cpu-clocks"`
 }
 
 // Validate checks that the Config is in a consistent state, e.g. that no
@@ -485,6 +648,50 @@ func (c *Config) Validate() error {
 	if c.PauseExternalNetworking && c.Network != NetworkSandbox {
 		return fmt.Errorf("pause-external-networking flag is only supported with sandbox networking")
 	}
+	if c.PacketFilter != "" && c.Network != NetworkSandbox {
+		return fmt.Errorf("packet-filter flag is only supported with sandbox networking")
+	}
+	if c.IPv6SegmentRouting && c.Network != NetworkSandbox {
+		return fmt.Errorf("ipv6-segment-routing flag is only supported with sandbox networking")
+	}
+	if c.IPv6MaxHopByHopOptions < 0 {
+		return fmt.Errorf("ipv6-max-hop-by-hop-options must be >= 0, got: %d", c.IPv6MaxHopByHopOptions)
+	}
+	if c.IPv6MaxHopByHopLength < 0 {
+		return fmt.Errorf("ipv6-max-hop-by-hop-length must be >= 0, got: %d", c.IPv6MaxHopByHopLength)
+	}
+	if c.NUMANodes < 1 || c.NUMANodes > maxNUMANodes {
+		return fmt.Errorf("numa-nodes must be between 1 and %d, got: %d", maxNUMANodes, c.NUMANodes)
+	}
+	if err := validateKernelRelease(c.KernelRelease); err != nil {
+		return fmt.Errorf("kernel-release=%q: %w", c.KernelRelease, err)
+	}
+	if err := validateKernelString(c.KernelVersion, maxKernelVersionLen); err != nil {
+		return fmt.Errorf("kernel-version=%q: %w", c.KernelVersion, err)
+	}
+	if err := validateKernelString(c.KernelCmdline, maxKernelCmdlineLen); err != nil {
+		return fmt.Errorf("kernel-cmdline=%q: %w", c.KernelCmdline, err)
+	}
+	if _, err := ParseRestoreAddressMap(c.RestoreAddressMap); err != nil {
+		return fmt.Errorf("restore-address-map=%q: %w", c.RestoreAddressMap, err)
+	}
+	switch c.MemoryReleasePolicy {
+	case "", "immediate", "batched", "idle":
+	default:
+		return fmt.Errorf("memory-release-policy must be one of: immediate, batched, idle, got: %q", c.MemoryReleasePolicy)
+	}
+	if c.MemoryReleaseDelay < 0 {
+		return fmt.Errorf("memory-release-delay must be >= 0, got: %v", c.MemoryReleaseDelay)
+	}
+	if c.MemoryCompactionInterval < 0 {
+		return fmt.Errorf("memory-compaction-interval must be >= 0, got: %v", c.MemoryCompactionInterval)
+	}
+	if c.PageMergingInterval < 0 {
+		return fmt.Errorf("page-merging-interval must be >= 0, got: %v", c.PageMergingInterval)
+	}
+	if c.UnixMaxInflightFDs < 0 {
+		return fmt.Errorf("unix-max-inflight-fds must be >= 0, got: %d", c.UnixMaxInflightFDs)
+	}
 	if c.TBFBurst > maxQDiscTBFBurst {
 		return fmt.Errorf("qdisc-tbf-burst must be <= %d, got: %d", maxQDiscTBFBurst, c.TBFBurst)
 	}
@@ -528,6 +735,38 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// kernelReleaseRE matches Linux release strings, e.g. "5.15.0-gvisor".
+var kernelReleaseRE = regexp.MustCompile(`^[0-9]+\.[0-9]+(\.[0-9]+)?[-+.~_A-Za-z0-9]*$`)
+
+// validateKernelRelease checks that release, if set, is a Linux release
+// string that fits in struct utsname.
+func validateKernelRelease(release string) error {
+	if release == "" {
+		return nil
+	}
+	if err := validateKernelString(release, maxKernelVersionLen); err != nil {
+		return err
+	}
+	if !kernelReleaseRE.MatchString(release) {
+		return fmt.Errorf("must start with MAJOR.MINOR, e.g. \"5.15.0-gvisor\"")
+	}
+	return nil
+}
+
+// validateKernelString checks that s contains only printable ASCII and is at
+// most maxLen bytes long.
+func validateKernelString(s string, maxLen int) error {
+	if len(s) > maxLen {
+		return fmt.Errorf("must be at most %d bytes long, got %d", maxLen, len(s))
+	}
+	for _, r := range s {
+		if r < ' ' || r > '~' {
+			return fmt.Errorf("must only contain printable ASCII characters, got %q", r)
+		}
+	}
+	return nil
+}
+
 // Log logs important aspects of the configuration to the given log function.
 func (c *Config) Log() {
 	log.Infof("Platform: %v", c.Platform)
@@ -629,6 +868,18 @@ var MetricMetadataKeys = []string{
 	"experiment",
 }
 
+// PlatformCandidates returns the platforms listed in Platform, in order of
+// preference.
+func (c *Config) PlatformCandidates() []string {
+	var candidates []string
+	for _, name := range strings.Split(c.Platform, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates
+}
+
 // MetricMetadata returns key-value pairs that are useful to include in metrics
 // exported about the sandbox this config represents.
 // It must return the same set of labels as listed in `MetricMetadataKeys`.
@@ -816,6 +1067,10 @@ const (
 
 	// QDiscTBF applies a Token Bucket Filter queue to the underlying FD.
 	QDiscTBF
+
+	// QDiscPrio applies a queue with priority bands, picked by the
+	// SO_PRIORITY or IP_TOS of the sending socket, to the underlying FD.
+	QDiscPrio
 )
 
 func queueingDisciplinePtr(v QueueingDiscipline) *QueueingDiscipline {
@@ -831,6 +1086,8 @@ func (q *QueueingDiscipline) Set(v string) error {
 		*q = QDiscFIFO
 	case "tbf":
 		*q = QDiscTBF
+	case "prio":
+		*q = QDiscPrio
 	default:
 		return fmt.Errorf("invalid qdisc %q", v)
 	}
@@ -851,10 +1108,58 @@ func (q QueueingDiscipline) String() string {
 		return "fifo"
 	case QDiscTBF:
 		return "tbf"
+	case QDiscPrio:
+		return "prio"
 	}
 	panic(fmt.Sprintf("Invalid qdisc %d", q))
 }
 
+// NetworkDispatchMode is used to specify how inbound packets are read from the
+// host FDs of a FDBasedLink.
+type NetworkDispatchMode int
+
+const (
+	// NetworkDispatchRecvMMsg reads packets in batches with recvmmsg(2).
+	NetworkDispatchRecvMMsg NetworkDispatchMode = iota
+
+	// NetworkDispatchPacketMMapV3 reads packets from a TPACKET_V3 memory
+	// mapped ring. If the ring can't be set up, RecvMMsg is used instead.
+	NetworkDispatchPacketMMapV3
+)
+
+func networkDispatchModePtr(v NetworkDispatchMode) *NetworkDispatchMode {
+	return &v
+}
+
+// Set implements flag.Value. Set(String()) should be idempotent.
+func (m *NetworkDispatchMode) Set(v string) error {
+	switch v {
+	case "recvmmsg":
+		*m = NetworkDispatchRecvMMsg
+	case "packet-mmap-v3":
+		*m = NetworkDispatchPacketMMapV3
+	default:
+		return fmt.Errorf("invalid network dispatch mode %q", v)
+	}
+	return nil
+}
+
+// Get implements flag.Value.
+func (m *NetworkDispatchMode) Get() any {
+	return *m
+}
+
+// String implements flag.Value.
+func (m NetworkDispatchMode) String() string {
+	switch m {
+	case NetworkDispatchRecvMMsg:
+		return "recvmmsg"
+	case NetworkDispatchPacketMMapV3:
+		return "packet-mmap-v3"
+	}
+	panic(fmt.Sprintf("Invalid network dispatch mode %d", m))
+}
+
 func leakModePtr(v refs.LeakMode) *refs.LeakMode {
 	return &v
 }
@@ -1366,6 +1671,100 @@ func (p RestoreSpecValidationPolicy) String() string {
 	}
 }
 
+// RestoreTimerPolicy dictates what happens to timers that expired between
+// checkpoint and restore.
+type RestoreTimerPolicy int
+
+// RestoreTimerPolicy values.
+const (
+	// RestoreTimerFire delivers expirations missed between checkpoint and
+	// restore immediately after restore, and sends TCP keepalive probes
+	// immediately after restore.
+	RestoreTimerFire RestoreTimerPolicy = iota
+
+	// RestoreTimerRearm delays timers by the time between checkpoint and
+	// restore, except for timers set to an absolute real time, and restarts
+	// the TCP keepalive idle period at restore.
+	RestoreTimerRearm
+
+	// RestoreTimerCancel drops expirations missed between checkpoint and
+	// restore, and restarts the TCP keepalive idle period at restore,
+	// forgetting unacknowledged keepalive probes.
+	RestoreTimerCancel
+)
+
+// Set implements flag.Value. Set(String()) should be idempotent.
+func (p *RestoreTimerPolicy) Set(v string) error {
+	switch v {
+	case "fire":
+		*p = RestoreTimerFire
+	case "rearm":
+		*p = RestoreTimerRearm
+	case "cancel":
+		*p = RestoreTimerCancel
+	default:
+		return fmt.Errorf("invalid restore timer policy %q", v)
+	}
+	return nil
+}
+
+// Ptr returns a pointer to `p`.
+// Useful in flag declaration line.
+func (p RestoreTimerPolicy) Ptr() *RestoreTimerPolicy {
+	return &p
+}
+
+// Get implements flag.Get.
+func (p *RestoreTimerPolicy) Get() any {
+	return *p
+}
+
+// String implements flag.String.
+func (p RestoreTimerPolicy) String() string {
+	switch p {
+	case RestoreTimerFire:
+		return "fire"
+	case RestoreTimerRearm:
+		return "rearm"
+	case RestoreTimerCancel:
+		return "cancel"
+	default:
+		panic(fmt.Sprintf("invalid restore timer policy %d", p))
+	}
+}
+
+// ParseRestoreAddressMap parses the value of the restore-address-map flag. It
+// returns nil for "auto" and "none", and the address pairs otherwise.
+func ParseRestoreAddressMap(v string) (map[netip.Addr]netip.Addr, error) {
+	switch v {
+	case "", "auto", "none":
+		return nil, nil
+	}
+	m := make(map[netip.Addr]netip.Addr)
+	for _, pair := range strings.Split(v, ",") {
+		from, to, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid address pair %q, want OLD=NEW", pair)
+		}
+		fromAddr, err := netip.ParseAddr(from)
+		if err != nil {
+			return nil, err
+		}
+		toAddr, err := netip.ParseAddr(to)
+		if err != nil {
+			return nil, err
+		}
+		if fromAddr.Is4() != toAddr.Is4() {
+			return nil, fmt.Errorf("addresses of pair %q are of different families", pair)
+		}
+		if _, ok := m[fromAddr]; ok {
+			return nil, fmt.Errorf("address %s is mapped more than once", fromAddr)
+		}
+		m[fromAddr] = toAddr
+	}
+	return m, nil
+}
+
 // XDP holds configuration for whether and how to use XDP.
 type XDP struct {
 	Mode      XDPMode
@@ -219,6 +219,11 @@ func TestInvalidFlags(t *testing.T) {
 			value: "invalid",
 			error: "invalid qdisc",
 		},
+		{
+			name:  "network-dispatch-mode",
+			value: "invalid",
+			error: "invalid network dispatch mode",
+		},
 		{
 			name:  "ref-leak-mode",
 			value: "invalid",
@@ -344,6 +349,55 @@ func TestValidationFail(t *testing.T) {
 			},
 			error: "overlay flag has been replaced with overlay2 flag",
 		},
+		{
+			name: "kernel-release-format",
+			flags: map[string]string{
+				"kernel-release": "gvisor",
+			},
+			error: "must start with MAJOR.MINOR",
+		},
+		{
+			name: "kernel-release-length",
+			flags: map[string]string{
+				"kernel-release": "5.15.0-" + strings.Repeat("x", 64),
+			},
+			error: "must be at most 64 bytes long",
+		},
+		{
+			name: "kernel-version-newline",
+			flags: map[string]string{
+				"kernel-version": "#1 SMP\n",
+			},
+			error: "printable ASCII",
+		},
+		{
+			name: "kernel-cmdline-length",
+			flags: map[string]string{
+				"kernel-cmdline": strings.Repeat("x", 2048),
+			},
+			error: "must be at most 2047 bytes long",
+		},
+		{
+			name: "memory-release-policy",
+			flags: map[string]string{
+				"memory-release-policy": "never",
+			},
+			error: "memory-release-policy must be one of",
+		},
+		{
+			name: "restore-address-map-mixed-families",
+			flags: map[string]string{
+				"restore-address-map": "10.0.0.1=fd00::1",
+			},
+			error: "different families",
+		},
+		{
+			name: "restore-address-map-no-pair",
+			flags: map[string]string{
+				"restore-address-map": "10.0.0.1",
+			},
+			error: "want OLD=NEW",
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			testFlags := flag.NewFlagSet("test", flag.ContinueOnError)
@@ -951,3 +1005,22 @@ func TestParseSerializeOverlay2(t *testing.T) {
 		}
 	})
 }
+
+func TestPlatformCandidates(t *testing.T) {
+	for _, tc := range []struct {
+		platform string
+		want     []string
+	}{
+		{platform: "systrap", want: []string{"systrap"}},
+		{platform: "kvm,systrap", want: []string{"kvm", "systrap"}},
+		{platform: " kvm , ptrace,,systrap ", want: []string{"kvm", "ptrace", "systrap"}},
+		{platform: "", want: nil},
+	} {
+		t.Run(tc.platform, func(t *testing.T) {
+			c := &Config{Platform: tc.platform}
+			if got := c.PlatformCandidates(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("PlatformCandidates() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
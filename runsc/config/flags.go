@@ -48,6 +48,21 @@ const (
 	flagQDiscTBFRate            = "qdisc-tbf-rate"
 	flagQDiscTBFBurst           = "qdisc-tbf-burst"
 	flagMountCgroupV2           = "mount-cgroup-v2"
+	flagNUMANodes               = "numa-nodes"
+	flagKernelRelease           = "kernel-release"
+	flagKernelVersion           = "kernel-version"
+	flagKernelCmdline           = "kernel-cmdline"
+
+	// maxNUMANodes must match kernel.MaxNUMANodes.
+	maxNUMANodes = 64
+
+	// maxKernelVersionLen is the size of the release and version fields of
+	// struct utsname, minus the NUL terminator.
+	maxKernelVersionLen = 64
+
+	// maxKernelCmdlineLen is Linux's COMMAND_LINE_SIZE on x86, minus the NUL
+	// terminator.
+	maxKernelCmdlineLen = 2047
 
 	maxQDiscTBFBurst     = uint64(1<<32 - 1)
 	defaultQDiscTBFRate  = uint64(0)
@@ -73,6 +88,7 @@ func RegisterFlags(flagSet *flag.FlagSet) {
 	flagSet.String("debug-log", "", "additional location for logs. If it ends with '/', log files are created inside the directory with default names. The following variables are available: %TIMESTAMP%, %COMMAND%.")
 	flagSet.String(flagDebugCommand, "", `comma-separated list of commands to be debugged if --debug-log is also set. Empty means debug all. "!" negates the expression. E.g. "create,start" or "!boot,events"`)
 	flagSet.String("panic-log", "", "file path where panic reports and other Go's runtime messages are written.")
+	flagSet.String("crash-bundle-dir", "", "directory where a tar file with goroutine stacks, recent logs, the configuration, version, platform and anonymized spec is written when the sentry is about to crash.")
 	flagSet.String("coverage-report", "", "file path where Go coverage reports are written. Reports will only be generated if runsc is built with --collect_code_coverage and --instrumentation_filter Bazel flags.")
 	flagSet.Bool("log-packets", false, "enable network packet logging.")
 	flagSet.String("pcap-log", "", "location of PCAP log file.")
@@ -99,7 +115,7 @@ func RegisterFlags(flagSet *flag.FlagSet) {
 	flagSet.Bool("strace-event", false, "send strace to event.")
 
 	// Flags that control sandbox runtime behavior.
-	flagSet.String("platform", "systrap", "specifies which platform to use: systrap (default), ptrace, kvm.")
+	flagSet.String("platform", "systrap", "specifies which platform to use: systrap (default), ptrace, kvm. A comma-separated list of platforms, e.g. kvm,systrap, selects the first platform that is available on the host.")
 	flagSet.String("platform_device_path", "", "path to a platform-specific device file (e.g. /dev/kvm for KVM platform). If unset, will use a sane platform-specific default.")
 	flagSet.String("watchdog-action", "log", "sets what action the watchdog takes when triggered: log (default), panic.")
 	flagSet.Int("panic-signal", -1, "register signal handling that panics. Usually set to SIGUSR2(12) to troubleshoot hangs. -1 disables it.")
@@ -113,22 +129,41 @@ func RegisterFlags(flagSet *flag.FlagSet) {
 	flagSet.Bool("rootless", false, "it allows the sandbox to be started with a user that is not root. Sandbox and Gofer processes may run with same privileges as current user.")
 	flagSet.Var(leakModePtr(refs.NoLeakChecking), "ref-leak-mode", "sets reference leak check mode: disabled (default), log-names, log-traces.")
 	flagSet.Bool("cpu-num-from-quota", true, "set cpu number to cpu quota (least integer greater or equal to quota value, but not less than 2)")
+	flagSet.Bool("sentry-cpu-bandwidth", false, "enforce each container's CPU quota in the sandbox kernel, which works without host cgroups (e.g. in rootless mode).")
+	flagSet.Int(flagNUMANodes, 1, "number of virtual NUMA nodes that the sandbox's CPUs are evenly divided between, as reported by getcpu(2), /proc/cpuinfo and /sys/devices/system/node. Must be between 1 and 64.")
+	flagSet.String(flagKernelRelease, "", "overrides the Linux release reported to applications by uname(2), /proc/version and /proc/sys/kernel/osrelease, e.g. '5.15.0-gvisor'. Does not change the syscalls that are implemented.")
+	flagSet.String(flagKernelVersion, "", "overrides the Linux version string reported to applications by uname(2), /proc/version and /proc/sys/kernel/version.")
+	flagSet.String(flagKernelCmdline, "", "space-separated kernel command-line parameters reported to applications in /proc/cmdline, e.g. 'systemd.unified_cgroup_hierarchy=1'.")
 	flagSet.Bool(flagOCISeccomp, false, "Enables loading OCI seccomp filters inside the sandbox.")
-	flagSet.Bool("enable-core-tags", false, "enables core tagging. Requires host linux kernel >= 5.14.")
+	flagSet.Bool("enable-core-tags", false, "enables core tagging of the sandbox and gofer processes, so that SMT siblings are never shared with other sandboxes. Requires host linux kernel >= 5.14.")
 	flagSet.String("pod-init-config", "", "path to configuration file with additional steps to take during pod creation.")
 	flagSet.Var(HostSettingsCheck.Ptr(), "host-settings", "how to handle non-optimal host kernel settings: check (default, advisory-only), ignore (do not check), adjust (best-effort auto-adjustment), or enforce (auto-adjustment must succeed).")
 	// TODO(gvisor.dev/issue/13718): flip default to `IF_RELEASE_BUILD`.
 	flagSet.Var(SidecarNever.Ptr(), "sidecar-release-enforcement-policy", "when spawned sidecar binaries must match runsc's release: NEVER, ALWAYS, or IF_RELEASE_BUILD. May be overridden by setting GVISOR_ENFORCE_RELEASE=SKIP as env var.")
 	flagSet.Var(RestoreSpecValidationEnforce.Ptr(), "restore-spec-validation", "how to handle spec validation during restore.")
+	flagSet.Var(RestoreTimerFire.Ptr(), "restore-timers", "what to do with application timers and TCP keepalives that expired between checkpoint and restore: fire (default, deliver them immediately), rearm (delay them by the time between checkpoint and restore), or cancel (drop them).")
+	flagSet.String("restore-address-map", "auto", "how to move sockets bound to addresses that the sandbox no longer has after restore: auto (default, map each interface's old address to its new one), none, or a comma-separated list of OLD=NEW address pairs.")
 	flagSet.Bool("systrap-disable-syscall-patching", false, "disables syscall patching when using the Systrap platform. May be necessary to use in case the workload uses the GS register, or uses ptrace within gVisor. Has significant performance implications and is only recommended when the sandbox is known to run otherwise-incompatible workloads. Only relevant for x86.")
 	flagSet.Bool("systrap-disable-fast-path", false, "unconditionally disables the Systrap fast path.")
 	flagSet.Bool("allow-suid", false, "allows ID elevation when executing binaries with the SUID/SGID bits set. The OCI --no-new-privileges flag continues to prevent ID elevation even when this flag is true.")
 	flagSet.Bool("kvm-use-cpu-nums", false, "on KVM use vCPU numbers as CPU numbers in the sentry. This is necessary to support features like rseq.")
+	flagSet.Bool("kvm-conditional-ibpb", false, "on KVM issue an indirect branch prediction barrier when switching between application address spaces (amd64 only). Reported in the /kvm/mitigations metric.")
+	flagSet.Bool("kvm-flush-l1d", false, "on KVM flush the L1 data cache on every switch to application code (amd64 only). Reported in the /kvm/mitigations metric.")
 	flagSet.Bool("allow-rootfs-tar-annotation", false, "allows the rootfs tar annotation to be set.")
 	flagSet.Duration("control-rpc-stop-timeout", 15*time.Second, "grace period given to in-flight RPCs on the sandbox control socket when the sandbox is shutting down. Once this timeout elapses, client connections are closed, and connections still processing an RPC are closed when their current RPC finishes. Set to 0 to close idle clients immediately.")
+	flagSet.Bool("control-grpc", false, "additionally serve the sandbox control methods over gRPC on the control socket.")
+	flagSet.String("control-policy", "", "grants control methods to host users and groups other than root and the sandbox owner, as 'uid=ID:METHODS' or 'gid=ID:METHODS' rules separated by ';'. METHODS is a comma-separated list of method names, 'Object.*' or '*'. Example: 'uid=1000:Usage.*,Metrics.*'. Each grant gets its own control socket that only its user or group (including supplementary members) may access, and the main control socket becomes accessible to the sandbox owner only. Granting to IDs other than the caller's own requires CAP_CHOWN.")
+	flagSet.Bool("stdio-relay", false, "relay stdout and stderr of containers without a terminal through the sandbox, so that they can be re-attached with 'runsc attach-stdio'. Not compatible with checkpoint.")
+	flagSet.Bool("exec-devpts", false, "give each interactive exec session its own pseudo-terminal in the sandbox, relayed to the host terminal, so that concurrent sessions have independent terminals and job control.")
+	flagSet.Bool("guest-api", false, "create /dev/gvisor, through which agents with CAP_SYS_ADMIN in the sandbox can query the runtime version, flush caches, signal readiness and request a checkpoint.")
+	flagSet.Bool("precise-cpu-clocks", false, "measure the CPU time of tasks, as reported by CPU-time clocks, getrusage(2) and procfs, with the platform's cycle clock instead of sampling running tasks every 10ms. Makes CPU time more accurate and fine-grained at a small cost on every task state change.")
 
 	// Flags that control sandbox runtime behavior: MM related.
 	flagSet.Bool("app-huge-pages", true, "enable use of huge pages for application memory; requires /sys/kernel/mm/transparent_hugepage/shmem_enabled = advise")
+	flagSet.String("memory-release-policy", "immediate", "when to return memory freed by the application to the host: immediate, batched (at most once per --memory-release-delay), or idle (after no memory has been freed for --memory-release-delay).")
+	flagSet.Duration("memory-release-delay", time.Second, "delay used by --memory-release-policy=batched and --memory-release-policy=idle.")
+	flagSet.Duration("memory-compaction-interval", 0, "if non-zero, periodically return all freed application memory to the host, including memory recommitted by huge page collapse.")
+	flagSet.Duration("page-merging-interval", 0, "if non-zero, periodically deduplicate identical anonymous memory pages within each container in the sandbox, at the cost of CPU time spent scanning memory. Pages are never merged across containers, but processes in the same container can detect through write timing which pages other processes in it hold.")
 
 	// Flags that control sandbox runtime behavior: FS related.
 	flagSet.Var(fileAccessTypePtr(FileAccessExclusive), "file-access", "specifies which filesystem validation to use for the root mount: exclusive (default), shared.")
@@ -140,6 +175,8 @@ func RegisterFlags(flagSet *flag.FlagSet) {
 		"    'mount' can be 'root' or 'all'\n"+
 		"    'medium' can be 'memory', 'self' or 'dir=/abs/dir/path' in which filestore will be created\n"+
 		"    'size' optional parameter overrides default overlay upper layer size\n")
+	flagSet.Uint64("overlay2-upper-warn-size", 0, "size in bytes of a container's rootfs overlay upper layer above which a warning event is sent. 0 disables the warning.")
+	flagSet.Duration("overlay2-upper-sample-interval", 10*time.Second, "how often to sample the size of each container's rootfs overlay upper layer for metrics and warnings. 0 disables sampling.")
 	flagSet.Var(hostUDSPtr(HostUDSNone), flagHostUDS, "controls permission to access host Unix-domain sockets. Values: none|open|create|all, default: none")
 	flagSet.Var(hostFifoPtr(HostFifoNone), "host-fifo", "controls permission to access host FIFOs (or named pipes). Values: none|open, default: none")
 	flagSet.Bool("gvisor-marker-file", false, "enable the presence of the /proc/gvisor/kernel_is_gvisor file that can be used by applications to detect that gVisor is in use")
@@ -149,8 +186,11 @@ func RegisterFlags(flagSet *flag.FlagSet) {
 	flagSet.Bool(flagMountCgroupV2, false, "EXPERIMENTAL. Mount cgroup v2 instead of cgroup v1 inside the sandbox. cgroup v2 support in gVisor is experimental and incomplete. Do not use for production workloads.")
 	flagSet.Int("fdlimit", -1, "Specifies a limit on the number of host file descriptors that can be open. Applies separately to the sentry and gofer. Note: each file in the sandbox holds more than one host FD open.")
 	flagSet.Int("dcache", -1, "Set the global dentry cache size. This acts as a coarse-grained control on the number of host FDs simultaneously open by the sentry. If negative, per-mount caches are used.")
+	flagSet.Bool("share-page-cache", false, "share sentry page cache pages between identical files in different gofer mounts that are not opened for writing, reducing memory usage when containers in a pod share image layers.")
+	flagSet.Int("unix-max-inflight-fds", 0, "maximum number of file descriptors that can be in flight in SCM_RIGHTS messages on Unix domain sockets across the sandbox. Sends that would exceed it fail with ETOOMANYREFS. If 0, only the per-user RLIMIT_NOFILE limit applies.")
 	flagSet.Bool("iouring", false, "TEST ONLY; Enables io_uring syscalls in the sentry. Support is experimental and very limited.")
 	flagSet.Bool("directfs", true, "directly access the container filesystems from the sentry. Sentry runs with higher privileges.")
+	flagSet.Bool("gofer-auth", false, "mutually authenticate the sentry and the gofers over their sockets before serving files, so that a leaked gofer socket FD cannot be used to impersonate either side.")
 	flagSet.Bool("TESTONLY-nftables", false, "TEST ONLY; Enables nftables support in the sentry.")
 
 	// Flags that control sandbox runtime behavior: network related.
@@ -167,10 +207,15 @@ func RegisterFlags(flagSet *flag.FlagSet) {
 	flagSet.Var(queueingDisciplinePtr(QDiscFIFO), flagQDisc, "specifies which queueing discipline to apply by default to the non loopback nics used by the sandbox.")
 	flagSet.Uint64(flagQDiscTBFRate, defaultQDiscTBFRate, "egress rate limit in bytes/sec when --qdisc=tbf.")
 	flagSet.Uint64(flagQDiscTBFBurst, defaultQDiscTBFBurst, "bucket depth in bytes when --qdisc=tbf.")
+	flagSet.Var(networkDispatchModePtr(NetworkDispatchRecvMMsg), "network-dispatch-mode", "specifies how inbound packets are read from the host: recvmmsg (default) or packet-mmap-v3. packet-mmap-v3 uses TPACKET_V3 memory mapped rings to reduce syscall overhead and falls back to recvmmsg if the rings can't be set up.")
 	flagSet.Int("num-network-channels", 1, "number of underlying channels(FDs) to use for network link endpoints.")
 	flagSet.Int("network-processors-per-channel", 0, "number of goroutines in each channel for processng inbound packets. If 0, the link endpoint will divide GOMAXPROCS evenly among the number of channels specified by num-network-channels.")
 	flagSet.Var(&xdpConfig, "EXPERIMENTAL-xdp", `whether and how to use XDP. Can be one of: "off" (default), "ns", "redirect:<device name>", or "tunnel:<device name>"`)
 	flagSet.Bool("EXPERIMENTAL-xdp-need-wakeup", true, "EXPERIMENTAL. Use XDP_USE_NEED_WAKEUP with XDP sockets.") // TODO(b/240191988): Figure out whether this helps and remove it as a flag.
+	flagSet.String("packet-filter", "", "name of an external packet filter compiled into runsc to consult for every packet forwarded by netstack. Only supported when using the sandbox network type.")
+	flagSet.Bool("ipv6-segment-routing", false, "process IPv6 segment routing headers addressed to the sandbox on interfaces with forwarding enabled. Only supported when using the sandbox network type.")
+	flagSet.Int("ipv6-max-hop-by-hop-options", 0, "maximum number of options accepted in an IPv6 hop-by-hop options header; packets with more are dropped. 0 means no limit.")
+	flagSet.Int("ipv6-max-hop-by-hop-length", 0, "maximum length in bytes of an IPv6 hop-by-hop options header; packets with longer headers are dropped. 0 means no limit.")
 	flagSet.Bool("reproduce-nat", false, "Scrape the host netns NAT table and reproduce it in the sandbox.")
 	flagSet.Bool(flagReproduceNFTables, false, "Attempt to scrape and reproduce nftable rules inside the sandbox. Overrides reproduce-nat when true.")
 	flagSet.Bool(flagNetDisconnectOK, true, "Indicates whether open network connections and open unix domain sockets should be disconnected upon save.")
@@ -184,11 +229,13 @@ func RegisterFlags(flagSet *flag.FlagSet) {
 	flagSet.Bool("nvproxy-allow-unsupported-driver", false, "allow nvproxy to be initialized with an unsupported driver version.")
 	flagSet.String("nvproxy-allowed-driver-capabilities", "utility,compute", "Comma separated list of NVIDIA driver capabilities that are allowed to be requested by the container. If 'all' is specified here, it is resolved to all driver capabilities supported in nvproxy. If 'all' is requested by the container, it is resolved to this list.")
 	flagSet.Bool("tpuproxy", false, "LEGACY: enable support for TPU devices. TPU support gets automatically enabled if TPU devices are present in the OCI spec.")
+	flagSet.Bool("host-devices", false, "proxy character devices from the OCI spec that are not otherwise supported (e.g. /dev/fuse, /dev/net/tun) to the host devices. Reads, writes and a per-device allowlist of ioctls are forwarded to the host device; other ioctls fail with ENOTTY. Raises the sandbox's FD limit to 263168 if needed: syscall filters less restrictive!")
 
 	// Test flags, not to be used outside tests, ever.
 	flagSet.Bool("TESTONLY-unsafe-nonroot", false, "TEST ONLY; do not ever use! This skips many security measures that isolate the host from the sandbox.")
 	flagSet.String("TESTONLY-test-name-env", "", "TEST ONLY; do not ever use! Used for automated tests to improve logging.")
 	flagSet.Bool("TESTONLY-afs-syscall-panic", false, "TEST ONLY; do not ever use! Used for tests exercising gVisor panic reporting.")
+	flagSet.String("TESTONLY-entropy-seed", "", "TEST ONLY; do not ever use! Makes random data returned to applications deterministic, derived from this seed.")
 	flagSet.String("TESTONLY-autosave-image-path", "", "TEST ONLY; enable auto save for syscall tests and set path for state file.")
 	flagSet.Bool("TESTONLY-autosave-resume", false, "TEST ONLY; enable auto save and resume for syscall tests and set path for state file.")
 
@@ -219,6 +266,10 @@ var overrideAllowlist = map[string]struct {
 	flagQDiscTBFRate:            {check: checkQDiscTBFRate},
 	flagQDiscTBFBurst:           {check: checkQDiscTBFBurst},
 	flagMountCgroupV2:           {},
+	flagNUMANodes:               {},
+	flagKernelRelease:           {},
+	flagKernelVersion:           {},
+	flagKernelCmdline:           {},
 }
 
 // checkOverlay2 ensures that overlay2 can only be enabled using "memory" or
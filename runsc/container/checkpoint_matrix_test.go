@@ -0,0 +1,213 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/pkg/state/statefile"
+	"gvisor.dev/gvisor/pkg/test/testutil"
+	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/sandbox"
+)
+
+// crMatrixEntry describes a subsystem exercised by the checkpoint/restore
+// conformance suite. Each entry corresponds to a `test_app cr-feature`
+// feature.
+type crMatrixEntry struct {
+	// feature is the name of the feature passed to `test_app cr-feature`.
+	feature string
+
+	// adjustSpec, if set, is called to prepare the container spec.
+	adjustSpec func(spec *specs.Spec)
+
+	// adjustConf, if set, is called to prepare the sandbox configuration.
+	adjustConf func(conf *config.Config)
+}
+
+// crMatrix is the set of subsystems covered by the checkpoint/restore
+// conformance suite. Keep in sync with the support matrix in
+// g3doc/user_guide/checkpoint_restore.md.
+var crMatrix = []crMatrixEntry{
+	{feature: "epoll"},
+	{feature: "timers"},
+	{
+		feature: "unix",
+		adjustConf: func(conf *config.Config) {
+			conf.NetDisconnectOk = false
+		},
+	},
+	{
+		feature: "netstack",
+		adjustConf: func(conf *config.Config) {
+			conf.NetDisconnectOk = false
+		},
+	},
+	{feature: "shm"},
+	{feature: "inotify"},
+	{
+		feature: "cgroupfs",
+		adjustSpec: func(spec *specs.Spec) {
+			spec.Mounts = append(spec.Mounts, specs.Mount{
+				Destination: "/sys/fs/cgroup",
+				Type:        "cgroup",
+			})
+		},
+	},
+}
+
+// crSkipList lists the feature/network combinations that are known not to
+// survive checkpoint/restore, along with the reason. It is the source of the
+// "unsupported" entries of the published support matrix.
+var crSkipList = map[string]map[config.NetworkType]string{
+	"netstack": {
+		config.NetworkHost: "connected host sockets are reset on restore",
+	},
+}
+
+// crMatrixConfigs returns the configurations the conformance suite runs
+// with: every platform, with both sandbox and host networking.
+func crMatrixConfigs(t *testing.T) map[string]*config.Config {
+	cs := make(map[string]*config.Config)
+	for name, conf := range configs(t, true /* noOverlay */) {
+		cs[name] = conf
+		hostinet := *conf
+		hostinet.Network = config.NetworkHost
+		cs[name+"-hostinet"] = &hostinet
+	}
+	return cs
+}
+
+// TestCheckpointRestoreMatrix checkpoints and restores a container exercising
+// each subsystem listed in crMatrix, and validates that the subsystem keeps
+// behaving correctly after restore.
+func TestCheckpointRestoreMatrix(t *testing.T) {
+	app, err := testutil.FindFile("test/cmd/test_app/test_app")
+	if err != nil {
+		t.Fatal("error finding test_app:", err)
+	}
+
+	for name, conf := range crMatrixConfigs(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, entry := range crMatrix {
+				t.Run(entry.feature, func(t *testing.T) {
+					if reason, ok := crSkipList[entry.feature][conf.Network]; ok {
+						t.Skipf("%s is unsupported with --network=%s: %s", entry.feature, conf.Network, reason)
+					}
+					featureConf := *conf
+					if entry.adjustConf != nil {
+						entry.adjustConf(&featureConf)
+					}
+					testCheckpointRestoreFeature(t, &featureConf, app, entry)
+				})
+			}
+		})
+	}
+}
+
+// testCheckpointRestoreFeature runs `test_app cr-feature` for the given
+// entry, checkpoints the container, restores it into a new container and
+// checks that the feature keeps making progress without failures.
+func testCheckpointRestoreFeature(t *testing.T, conf *config.Config, app string, entry crMatrixEntry) {
+	dir, err := os.MkdirTemp(testutil.TmpDir(), "checkpoint-matrix")
+	if err != nil {
+		t.Fatalf("os.MkdirTemp failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Chmod(dir, 0777); err != nil {
+		t.Fatalf("error chmoding file: %q, %v", dir, err)
+	}
+	imageDir := filepath.Join(dir, "image")
+	scratchDir := filepath.Join(dir, "scratch")
+	for _, d := range []string{imageDir, scratchDir} {
+		if err := os.Mkdir(d, 0777); err != nil {
+			t.Fatalf("os.Mkdir(%q) failed: %v", d, err)
+		}
+		if err := os.Chmod(d, 0777); err != nil {
+			t.Fatalf("error chmoding file: %q, %v", d, err)
+		}
+	}
+
+	logPath := filepath.Join(dir, "cr-feature.log")
+	spec := testutil.NewSpecWithArgs(app, "cr-feature", "--feature", entry.feature, "--file", logPath, "--dir", scratchDir)
+	if entry.adjustSpec != nil {
+		entry.adjustSpec(spec)
+	}
+	_, bundleDir, cleanup, err := testutil.SetupContainer(spec, conf)
+	if err != nil {
+		t.Fatalf("error setting up container: %v", err)
+	}
+	defer cleanup()
+
+	args := Args{
+		ID:        testutil.RandomContainerID(),
+		Spec:      spec,
+		BundleDir: bundleDir,
+	}
+	cont, err := New(conf, args)
+	if err != nil {
+		t.Fatalf("error creating container: %v", err)
+	}
+	defer cont.Destroy()
+	if err := cont.Start(conf); err != nil {
+		t.Fatalf("error starting container: %v", err)
+	}
+
+	if err := waitForHostinetSRLog(logPath, "SETUP_DONE", "COUNT="); err != nil {
+		t.Fatalf("wait for setup: %v", err)
+	}
+	lastCount, err := lastHostinetSRCount(logPath)
+	if err != nil {
+		t.Fatalf("lastHostinetSRCount pre-checkpoint: %v", err)
+	}
+
+	if err := cont.Checkpoint(conf, imageDir, sandbox.CheckpointOpts{Compression: statefile.CompressionLevelFlateBestSpeed}); err != nil {
+		t.Fatalf("error checkpointing container: %v", err)
+	}
+	cont.Destroy()
+
+	args2 := Args{
+		ID:        testutil.RandomContainerID(),
+		Spec:      spec,
+		BundleDir: bundleDir,
+	}
+	cont2, err := New(conf, args2)
+	if err != nil {
+		t.Fatalf("error creating container: %v", err)
+	}
+	defer cont2.Destroy()
+	if err := cont2.Restore(conf, imageDir, false /* direct */, false /* background */, false /* template */, nil /* networkArgs */); err != nil {
+		t.Fatalf("error restoring container: %v", err)
+	}
+
+	// A few iterations after restore are enough to show that the restored
+	// objects are functional.
+	if err := waitForHostinetSRCountAfter(logPath, lastCount+3); err != nil {
+		t.Fatalf("wait for progress after restore: %v", err)
+	}
+	b, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("error reading log file: %v", err)
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.HasPrefix(line, "FAIL") || strings.HasPrefix(line, "SETUP_FAILED") {
+			t.Errorf("%s failed across checkpoint/restore: %s", entry.feature, line)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/pkg/state/statefile"
+	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/sandbox"
+	"gvisor.dev/gvisor/runsc/specutils"
+)
+
+// CloneArgs are arguments to Container.Clone.
+type CloneArgs struct {
+	// ImagePath is the directory to which the sandbox is checkpointed. All
+	// clones are restored from it as a template, so it must not be modified
+	// or removed while any of them is running.
+	ImagePath string
+
+	// IDs are the container IDs of the clones.
+	IDs []string
+
+	// NetNS are the paths of the network namespaces of the clones, one per
+	// clone. If empty, the clones use the network namespace of the spec.
+	NetNS []string
+}
+
+// NewCloneIDs returns n random container IDs for clones of the container with
+// the given ID.
+func NewCloneIDs(id string, n int) ([]string, error) {
+	ids := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		var suffix [8]byte
+		if _, err := rand.Read(suffix[:]); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id+"-"+hex.EncodeToString(suffix[:]))
+	}
+	return ids, nil
+}
+
+// Clone checkpoints the sandbox of c, which must be running only c, and
+// restores each clone from the checkpoint into a new sandbox. c keeps running,
+// or stays paused, after it's checkpointed.
+//
+// Clones are restored with the checkpoint as a template, so they share its
+// memory pages in the host page cache until they modify them, and are
+// therefore much cheaper than independent restores. Each clone is restored
+// with a container ID that differs from c's, so it gets a new boot ID (see
+// Restore).
+//
+// If restoring a clone fails, the clones that were already restored are
+// returned along with the error.
+func (c *Container) Clone(conf *config.Config, args CloneArgs) ([]*Container, error) {
+	log.Debugf("Clone container, cid: %s, clones: %v", c.ID, args.IDs)
+	if err := c.requireStatus("clone", Running, Paused); err != nil {
+		return nil, err
+	}
+	if !c.IsSandboxRoot() {
+		return nil, fmt.Errorf("only the root container of a sandbox can be cloned")
+	}
+	conts, err := LoadSandbox(conf.RootDir, c.Sandbox.ID, LoadOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("loading sandbox containers: %w", err)
+	}
+	if len(conts) != 1 {
+		return nil, fmt.Errorf("sandbox %q has %d containers, only sandboxes with a single container can be cloned", c.Sandbox.ID, len(conts))
+	}
+	if sandbox.IsImageURL(args.ImagePath) {
+		return nil, fmt.Errorf("image path must be a local directory")
+	}
+	if err := validateCloneArgs(conf, c.Spec, args); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(args.ImagePath, 0755); err != nil {
+		return nil, fmt.Errorf("creating image directory %q: %w", args.ImagePath, err)
+	}
+	// Templates must be uncompressed so that pages can be read on demand.
+	opts := sandbox.CheckpointOpts{
+		Compression: statefile.CompressionLevelNone,
+		Resume:      true,
+	}
+	if err := c.Checkpoint(conf, args.ImagePath, opts); err != nil {
+		return nil, fmt.Errorf("checkpointing container: %w", err)
+	}
+
+	clones := make([]*Container, 0, len(args.IDs))
+	for i, id := range args.IDs {
+		var netns string
+		if len(args.NetNS) > 0 {
+			netns = args.NetNS[i]
+		}
+		clone, err := c.restoreClone(conf, args.ImagePath, id, netns)
+		if err != nil {
+			return clones, fmt.Errorf("restoring clone %q: %w", id, err)
+		}
+		clones = append(clones, clone)
+	}
+	return clones, nil
+}
+
+// validateCloneArgs checks args before anything is checkpointed.
+func validateCloneArgs(conf *config.Config, spec *specs.Spec, args CloneArgs) error {
+	if args.ImagePath == "" {
+		return fmt.Errorf("image path must be provided")
+	}
+	if len(args.NetNS) > 0 && len(args.NetNS) != len(args.IDs) {
+		return fmt.Errorf("got %d network namespaces for %d clones", len(args.NetNS), len(args.IDs))
+	}
+	seen := make(map[string]struct{}, len(args.IDs))
+	for _, id := range args.IDs {
+		if err := validateID(id); err != nil {
+			return fmt.Errorf("invalid clone ID: %w", err)
+		}
+		if _, ok := seen[id]; ok {
+			return fmt.Errorf("duplicate clone ID %q", id)
+		}
+		seen[id] = struct{}{}
+	}
+	if len(args.NetNS) == 0 && conf.Network == config.NetworkSandbox {
+		// Clones joining the network namespace of the sandbox, which keeps
+		// running, would claim the same addresses as it and each other.
+		if ns, ok := specutils.GetNS(specs.NetworkNamespace, spec); ok && ns.Path != "" {
+			return fmt.Errorf("clones would share network namespace %q with the sandbox, a network namespace must be given for each clone", ns.Path)
+		}
+	}
+	return nil
+}
+
+// restoreClone creates the container id in a new sandbox, joining the network
+// namespace at netns if it's not empty, and restores it from the template in
+// imagePath.
+func (c *Container) restoreClone(conf *config.Config, imagePath, id, netns string) (*Container, error) {
+	// New modifies the spec, so each clone needs its own copy.
+	spec, err := specutils.ReadSpec(c.BundleDir, conf)
+	if err != nil {
+		return nil, fmt.Errorf("reading spec: %w", err)
+	}
+	if netns != "" {
+		setNetNSPath(spec, netns)
+	}
+	clone, err := New(conf, Args{
+		ID:        id,
+		Spec:      spec,
+		BundleDir: c.BundleDir,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := clone.Restore(conf, imagePath, false /* direct */, false /* background */, true /* template */, nil /* networkArgs */); err != nil {
+		_ = clone.Destroy()
+		return nil, err
+	}
+	log.Infof("Restored clone %q of container %q", id, c.ID)
+	return clone, nil
+}
+
+// setNetNSPath makes spec join the network namespace at path.
+func setNetNSPath(spec *specs.Spec, path string) {
+	if spec.Linux == nil {
+		spec.Linux = &specs.Linux{}
+	}
+	for i, ns := range spec.Linux.Namespaces {
+		if ns.Type == specs.NetworkNamespace {
+			spec.Linux.Namespaces[i].Path = path
+			return
+		}
+	}
+	spec.Linux.Namespaces = append(spec.Linux.Namespaces, specs.LinuxNamespace{Type: specs.NetworkNamespace, Path: path})
+}
@@ -48,6 +48,7 @@ import (
 	"gvisor.dev/gvisor/runsc/config"
 	"gvisor.dev/gvisor/runsc/console"
 	"gvisor.dev/gvisor/runsc/donation"
+	"gvisor.dev/gvisor/runsc/goferauth"
 	"gvisor.dev/gvisor/runsc/profile"
 	"gvisor.dev/gvisor/runsc/sandbox"
 	"gvisor.dev/gvisor/runsc/specutils"
@@ -125,6 +126,11 @@ type Container struct {
 	// container is created and reset when the sandbox is destroyed.
 	Sandbox *sandbox.Sandbox `json:"sandbox"`
 
+	// PooledSandbox is true if the container was created as a root container,
+	// but runs in a sandbox claimed from a sandbox pool. The sandbox is
+	// destroyed with the container.
+	PooledSandbox bool `json:"pooledSandbox,omitempty"`
+
 	// CompatCgroup has the cgroup configuration for the container. For the single
 	// container case, container cgroup is set in `c.Sandbox` only. CompactCgroup
 	// is only set for multi-container, where the `c.Sandbox` cgroup represents
@@ -154,6 +160,14 @@ type Container struct {
 	// This field isn't saved to json, because only a creator of a gofer
 	// process will have it as a child process.
 	goferIsChild bool `nojson:"true"`
+
+	// goferAuthKey is the key shared by the sandbox and the gofer to
+	// authenticate each other. It is set by createGoferProcess when gofer
+	// authentication is enabled.
+	//
+	// This field isn't saved to json, because the key must only be known to
+	// the gofer, the sandbox and the process that created them.
+	goferAuthKey goferauth.Key `nojson:"true"`
 }
 
 // Args is used to configure a new container.
@@ -201,8 +215,9 @@ type Args struct {
 }
 
 // New creates the container in a new Sandbox process, unless the metadata
-// indicates that an existing Sandbox should be used. The caller must call
-// Destroy() on the container.
+// indicates that an existing Sandbox should be used, or the container claims a
+// sandbox from a pool (see specutils.AnnotationSandboxPool). The caller must
+// call Destroy() on the container.
 func New(conf *config.Config, args Args) (*Container, error) {
 	log.Debugf("Create container, cid: %s, rootDir: %q", args.ID, conf.RootDir)
 	if err := validateID(args.ID); err != nil {
@@ -217,6 +232,14 @@ func New(conf *config.Config, args Args) (*Container, error) {
 		return nil, fmt.Errorf("failed to modify spec for directfs: %v", err)
 	}
 
+	pooled := false
+	if specutils.IsRootContainer(args.Spec) && args.FSRestoreImagePath == "" {
+		var err error
+		if pooled, err = claimPooledSandbox(conf, args.Spec); err != nil {
+			return nil, err
+		}
+	}
+
 	sandboxID := args.ID
 	if !specutils.IsRootContainer(args.Spec) {
 		var ok bool
@@ -228,6 +251,12 @@ func New(conf *config.Config, args Args) (*Container, error) {
 			return nil, fmt.Errorf("cannot set FSRestoreImagePath when creating container in existing sandbox")
 		}
 	}
+	var poolCu cleanup.Cleanup
+	defer poolCu.Clean()
+	if pooled {
+		// Destroy the claimed sandbox if the container can't be created in it.
+		poolCu.Add(func() { _ = destroyPooledSandbox(conf.RootDir, sandboxID) })
+	}
 
 	c := &Container{
 		ID:            args.ID,
@@ -237,6 +266,7 @@ func New(conf *config.Config, args Args) (*Container, error) {
 		Status:        Creating,
 		CreatedAt:     time.Now(),
 		Owner:         os.Getenv("USER"),
+		PooledSandbox: pooled,
 		Saver: StateFile{
 			RootDir: conf.RootDir,
 			ID: FullID{
@@ -290,6 +320,11 @@ func New(conf *config.Config, args Args) (*Container, error) {
 		}
 		c.Sandbox = sb.Sandbox
 
+		if pooled {
+			if err := c.joinPooledSandboxCgroup(conf, sb, args.Spec); err != nil {
+				return nil, err
+			}
+		}
 		if err := c.createSubcontainer(conf, args.Spec); err != nil {
 			return nil, err
 		}
@@ -327,6 +362,7 @@ func New(conf *config.Config, args Args) (*Container, error) {
 		}
 	}
 
+	poolCu.Release()
 	cu.Release()
 	return c, nil
 }
@@ -388,6 +424,7 @@ func (c *Container) createRoot(conf *config.Config, args Args, sandboxID string)
 			Attached:            args.Attached,
 			GoferFilestoreFiles: goferFilestores,
 			GoferMountConfs:     c.GoferMountConfs,
+			GoferAuthKey:        c.goferAuthKey,
 			MountHints:          mountHints,
 			PassFiles:           args.PassFiles,
 			ExecFile:            args.ExecFile,
@@ -408,11 +445,15 @@ func (c *Container) createRoot(conf *config.Config, args Args, sandboxID string)
 }
 
 func (c *Container) createSubcontainer(conf *config.Config, spec *specs.Spec) error {
-	subCgroup, err := c.setupCgroupForSubcontainer(conf, spec)
-	if err != nil {
-		return err
+	// Containers in pooled sandboxes get their cgroups in
+	// joinPooledSandboxCgroup.
+	if !c.PooledSandbox {
+		subCgroup, err := c.setupCgroupForSubcontainer(conf, spec)
+		if err != nil {
+			return err
+		}
+		c.CompatCgroup = cgroup.CgroupJSON{Cgroup: subCgroup}
 	}
-	c.CompatCgroup = cgroup.CgroupJSON{Cgroup: subCgroup}
 
 	// If the console control socket file is provided, then create a new
 	// pty master/slave pair and send the TTY to the sandbox process.
@@ -441,17 +482,19 @@ func (c *Container) Start(conf *config.Config) error {
 }
 
 // Restore takes a container and replaces its kernel and file system
-// to restore a container from its state file.
-func (c *Container) Restore(conf *config.Config, imagePath string, direct, background bool, networkArgs *boot.CreateLinksAndRoutesArgs) error {
+// to restore a container from its state file. imagePath may be a local
+// directory or an HTTP(S) URL, in which case the state file is streamed from
+// the URL (see sandbox.IsImageURL).
+func (c *Container) Restore(conf *config.Config, imagePath string, direct, background, template bool, networkArgs *boot.CreateLinksAndRoutesArgs) error {
 	log.Debugf("Restore container, cid: %s", c.ID)
 
 	restore := func(conf *config.Config, spec *specs.Spec) error {
-		return c.Sandbox.Restore(conf, spec, c.ID, imagePath, direct, background, networkArgs)
+		return c.Sandbox.Restore(conf, spec, c.ID, imagePath, direct, background, template, networkArgs)
 	}
 	return c.startImpl(conf, "restore", restore, c.Sandbox.RestoreSubcontainer)
 }
 
-func (c *Container) startImpl(conf *config.Config, action string, startRoot func(conf *config.Config, spec *specs.Spec) error, startSub func(spec *specs.Spec, conf *config.Config, cid string, stdios, goferFiles, goferFilestores []*os.File, devIOFile *os.File, goferConfs []specutils.GoferMountConf) error) error {
+func (c *Container) startImpl(conf *config.Config, action string, startRoot func(conf *config.Config, spec *specs.Spec) error, startSub func(spec *specs.Spec, conf *config.Config, cid string, stdios, goferFiles, goferFilestores []*os.File, devIOFile *os.File, goferConfs []specutils.GoferMountConf, goferAuthKey goferauth.Key) error) error {
 	if err := c.Saver.lock(BlockAcquire); err != nil {
 		return err
 	}
@@ -511,7 +554,7 @@ func (c *Container) startImpl(conf *config.Config, action string, startRoot func
 				stdios = []*os.File{os.Stdin, os.Stdout, os.Stderr}
 			}
 
-			return startSub(c.Spec, conf, c.ID, stdios, goferFiles, goferFilestores, devIOFile, c.GoferMountConfs)
+			return startSub(c.Spec, conf, c.ID, stdios, goferFiles, goferFilestores, devIOFile, c.GoferMountConfs, c.goferAuthKey)
 		}); err != nil {
 			return err
 		}
@@ -576,7 +619,7 @@ func Run(conf *config.Config, args Args) (unix.WaitStatus, error) {
 }
 
 // Update sets the resources of a running container as configured.
-func (c *Container) Update(res *specs.LinuxResources) error {
+func (c *Container) Update(conf *config.Config, res *specs.LinuxResources) error {
 	log.Debugf("Set resources for container, cid: %s", c.ID)
 	if err := c.requireStatus("set resources for", Created, Running); err != nil {
 		return err
@@ -586,7 +629,9 @@ func (c *Container) Update(res *specs.LinuxResources) error {
 		return fmt.Errorf("sandbox cannot be nil")
 	}
 
-	if c.Sandbox.IsRootContainer(c.ID) {
+	// A container that claimed a pooled sandbox owns the sandbox's cgroup, like
+	// a root container.
+	if c.Sandbox.IsRootContainer(c.ID) || c.PooledSandbox {
 		cg := c.Sandbox.CgroupJSON.Cgroup
 		if cg == nil {
 			return fmt.Errorf("cgroup cannot be nil")
@@ -598,6 +643,18 @@ func (c *Container) Update(res *specs.LinuxResources) error {
 			}
 			return err
 		}
+		// Let applications in the sandbox observe the new CPU limit.
+		if err := c.Sandbox.UpdateCPUs(conf); err != nil {
+			return err
+		}
+	}
+	// Subcontainers share the sandbox's cgroup, so their limits can only be
+	// enforced inside the sandbox. Containers that haven't started yet have
+	// nothing to update in the sandbox; they start with the saved spec.
+	if c.Status == Running {
+		if err := c.Sandbox.UpdateResources(c.ID, res); err != nil {
+			return err
+		}
 	}
 
 	c.Spec.Linux.Resources = res
@@ -618,6 +675,27 @@ func (c *Container) Execute(conf *config.Config, args *control.ExecArgs) (int32,
 	return c.Sandbox.Execute(conf, args)
 }
 
+// AttachExec attaches the terminal tty to the session of process pid, which
+// was started with control.ExecArgs.Detachable, until it is detached. It
+// returns true if the process may still be running.
+func (c *Container) AttachExec(pid int32, tty *os.File, detachKeys []byte) (bool, error) {
+	log.Debugf("Attach to exec session of process %d in container, cid: %s", pid, c.ID)
+	if err := c.requireStatus("attach to", Running); err != nil {
+		return false, err
+	}
+	return c.Sandbox.AttachExec(c.ID, pid, tty, detachKeys)
+}
+
+// ImportCRIU imports the processes of the CRIU dump in imageDir into the
+// container, and starts them.
+func (c *Container) ImportCRIU(imageDir string) ([]boot.ImportedProcess, error) {
+	log.Debugf("Import CRIU dump into container, cid: %s, image: %q", c.ID, imageDir)
+	if err := c.requireStatus("import into", Running); err != nil {
+		return nil, err
+	}
+	return c.Sandbox.ImportCRIU(c.ID, imageDir)
+}
+
 // Event returns events for the container.
 func (c *Container) Event() (*boot.EventOut, error) {
 	log.Debugf("Getting events for container, cid: %s", c.ID)
@@ -690,6 +768,34 @@ func (c *Container) Wait() (unix.WaitStatus, error) {
 	return ws, err
 }
 
+// WaitContainers waits for any or all of containers to exit, for at most
+// timeout if it's not zero. All containers must be in the same sandbox. The
+// containers that exited are marked as stopped.
+func WaitContainers(containers []*Container, all bool, timeout time.Duration) (*boot.WaitContainersResult, error) {
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("no container to wait on")
+	}
+	s := containers[0].Sandbox
+	cids := make([]string, 0, len(containers))
+	byID := make(map[string]*Container, len(containers))
+	for _, c := range containers {
+		if c.Sandbox == nil || c.Sandbox.ID != s.ID {
+			return nil, fmt.Errorf("containers %q and %q are not in the same sandbox", containers[0].ID, c.ID)
+		}
+		cids = append(cids, c.ID)
+		byID[c.ID] = c
+	}
+	log.Debugf("Wait on containers %v, all: %t, timeout: %v", cids, all, timeout)
+	res, err := s.WaitContainers(cids, all, timeout)
+	if err != nil {
+		return nil, err
+	}
+	for _, exit := range res.Exited {
+		byID[exit.CID].changeStatus(Stopped)
+	}
+	return res, nil
+}
+
 // WaitRootPID waits for process 'pid' in the sandbox's PID namespace and
 // returns its WaitStatus.
 func (c *Container) WaitRootPID(pid int32) (unix.WaitStatus, error) {
@@ -759,6 +865,17 @@ func (c *Container) TarRootfsUpperLayer(outFD *os.File) error {
 	return c.Sandbox.TarRootfsUpperLayer(c.ID, outFD)
 }
 
+// RootfsUpperUsage returns the space used by the rootfs upper layer of the
+// container, along with up to top files that use the most of it. When the
+// rootfs is not an overlayfs, it returns an error.
+func (c *Container) RootfsUpperUsage(top int) (*control.RootfsUpperUsage, error) {
+	log.Debugf("RootfsUpperUsage, cid: %s", c.ID)
+	if !c.IsSandboxRunning() {
+		return nil, fmt.Errorf("sandbox is not running")
+	}
+	return c.Sandbox.RootfsUpperUsage(c.ID, top)
+}
+
 // SignalContainer sends the signal to the container. If all is true and signal
 // is SIGKILL, then waits for all processes to exit before returning.
 // SignalContainer returns an error if the container is already stopped.
@@ -840,7 +957,10 @@ func (c *Container) FSSave(conf *config.Config, imagePath string, opts sandbox.F
 	return c.Sandbox.FSSave(conf, c.ID, imagePath, opts)
 }
 
-// Pause suspends the container and its kernel.
+// Pause suspends the container. Pausing the root container suspends the whole
+// sandbox, while pausing a subcontainer leaves other containers running. A
+// container that runs in a sandbox claimed from a pool was created as a root
+// container, so pausing it also suspends the whole sandbox.
 // The call only succeeds if the container's status is created or running.
 func (c *Container) Pause() error {
 	log.Debugf("Pausing container, cid: %s", c.ID)
@@ -853,14 +973,15 @@ func (c *Container) Pause() error {
 		return fmt.Errorf("cannot pause container %q in state %v", c.ID, c.Status)
 	}
 
-	if err := c.Sandbox.Pause(c.ID); err != nil {
+	if err := c.Sandbox.Pause(c.sandboxScopeID()); err != nil {
 		return fmt.Errorf("pausing container %q: %v", c.ID, err)
 	}
 	c.changeStatus(Paused)
 	return c.saveLocked()
 }
 
-// Resume unpauses the container and its kernel.
+// Resume unpauses the container, and the whole sandbox if it's the root
+// container.
 // The call only succeeds if the container's status is paused.
 func (c *Container) Resume() error {
 	log.Debugf("Resuming container, cid: %s", c.ID)
@@ -872,13 +993,24 @@ func (c *Container) Resume() error {
 	if c.Status != Paused {
 		return fmt.Errorf("cannot resume container %q in state %v", c.ID, c.Status)
 	}
-	if err := c.Sandbox.Resume(c.ID); err != nil {
+	if err := c.Sandbox.Resume(c.sandboxScopeID()); err != nil {
 		return fmt.Errorf("resuming container: %v", err)
 	}
 	c.changeStatus(Running)
 	return c.saveLocked()
 }
 
+// sandboxScopeID returns the ID of the container that operations scoped to the
+// whole sandbox must target on behalf of c. This is the placeholder root
+// container for containers that run in a sandbox claimed from a pool, and c
+// itself otherwise.
+func (c *Container) sandboxScopeID() string {
+	if c.PooledSandbox {
+		return c.Sandbox.ID
+	}
+	return c.ID
+}
+
 // State returns the metadata of the container.
 func (c *Container) State() specs.State {
 	return specs.State{
@@ -900,6 +1032,14 @@ func (c *Container) Processes() ([]*control.Process, error) {
 	return c.Sandbox.Processes(c.ID)
 }
 
+// TaskUsage retrieves the resource usage of the tasks inside a container.
+func (c *Container) TaskUsage() (*control.TaskUsages, error) {
+	if err := c.requireStatus("get task usage of", Running, Paused); err != nil {
+		return nil, err
+	}
+	return c.Sandbox.TaskUsage(c.ID)
+}
+
 // Destroy stops all processes and frees all resources associated with the
 // container.
 func (c *Container) Destroy() error {
@@ -972,6 +1112,14 @@ func (c *Container) Destroy() error {
 		}
 	}
 
+	if c.PooledSandbox && sb != nil {
+		if err := destroyPooledSandbox(c.Saver.RootDir, sb.ID); err != nil {
+			err = fmt.Errorf("destroying pooled sandbox: %v", err)
+			log.Warningf("%v", err)
+			errs = append(errs, err.Error())
+		}
+	}
+
 	c.changeStatus(Stopped)
 
 	// Adjust oom_score_adj for the sandbox. This must be done after the container
@@ -1333,7 +1481,7 @@ func (c *Container) waitForStopped() error {
 // shouldCreateDeviceGofer indicates whether a device gofer connection should
 // be created.
 func shouldCreateDeviceGofer(spec *specs.Spec, conf *config.Config) bool {
-	return specutils.GPUFunctionalityRequested(spec, conf) || specutils.TPUFunctionalityRequested(spec, conf)
+	return specutils.GPUFunctionalityRequested(spec, conf) || specutils.TPUFunctionalityRequested(spec, conf) || specutils.HostDevicesRequested(spec, conf)
 }
 
 // shouldSpawnGofer indicates whether the gofer process should be spawned.
@@ -1499,6 +1647,19 @@ func (c *Container) createGoferProcess(conf *config.Config, mountHints *boot.Pod
 	}
 	rpcClntFD, _ := rpcClnt.Release()
 	donations.DonateAndClose("rpc-fd", os.NewFile(uintptr(rpcClntFD), "gofer-rpc"))
+
+	if conf.GoferAuth {
+		key, err := goferauth.NewKey()
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		keyFile, err := goferauth.NewKeyFile(key)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("creating gofer authentication key file: %w", err)
+		}
+		donations.DonateAndClose("auth-key-fd", keyFile)
+		c.goferAuthKey = key
+	}
 	rpcPidCh := make(chan int, 1)
 	defer close(rpcPidCh)
 	go func() {
@@ -1249,7 +1249,7 @@ func testCheckpointRestore(t *testing.T, conf *config.Config, compression statef
 	}
 	defer cont2.Destroy()
 
-	if err := cont2.Restore(conf, dir, false /* direct */, false /* background */, nil /* networkArgs */); err != nil {
+	if err := cont2.Restore(conf, dir, false /* direct */, false /* background */, false /* template */, nil /* networkArgs */); err != nil {
 		t.Fatalf("error restoring container: %v", err)
 	}
 
@@ -1300,7 +1300,7 @@ func testCheckpointRestore(t *testing.T, conf *config.Config, compression statef
 	}
 	defer cont3.Destroy()
 
-	if err := cont3.Restore(conf, dir, false /* direct */, false /* background */, nil /* networkArgs */); err != nil {
+	if err := cont3.Restore(conf, dir, false /* direct */, false /* background */, false /* template */, nil /* networkArgs */); err != nil {
 		t.Fatalf("error restoring container: %v", err)
 	}
 
@@ -1342,6 +1342,88 @@ func TestCheckpointRestore(t *testing.T) {
 	}
 }
 
+// TestCheckpointRestoreTemplate checks that multiple sandboxes can be restored
+// from the same checkpoint image used as a template, and that they run
+// independently while their memory is loaded on demand.
+func TestCheckpointRestoreTemplate(t *testing.T) {
+	dir, err := os.MkdirTemp(testutil.TmpDir(), "checkpoint-test")
+	if err != nil {
+		t.Fatalf("os.MkdirTemp failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Chmod(dir, 0777); err != nil {
+		t.Fatalf("error chmoding file: %q, %v", dir, err)
+	}
+
+	spec, conf := sleepSpecConf(t)
+	_, bundleDir, cleanup, err := testutil.SetupContainer(spec, conf)
+	if err != nil {
+		t.Fatalf("error setting up container: %v", err)
+	}
+	defer cleanup()
+
+	args := Args{
+		ID:        testutil.RandomContainerID(),
+		Spec:      spec,
+		BundleDir: bundleDir,
+	}
+	cont, err := New(conf, args)
+	if err != nil {
+		t.Fatalf("error creating container: %v", err)
+	}
+	defer cont.Destroy()
+	if err := cont.Start(conf); err != nil {
+		t.Fatalf("error starting container: %v", err)
+	}
+	if err := waitForProcessCount(cont, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Templates require a separate pages file, which is only written for
+	// uncompressed checkpoints.
+	if err := cont.Checkpoint(conf, dir, sandbox.CheckpointOpts{Compression: statefile.CompressionLevelNone}); err != nil {
+		t.Fatalf("error checkpointing container: %v", err)
+	}
+	cont.Destroy()
+	cont = nil
+
+	var conts []*Container
+	for i := 0; i < 2; i++ {
+		args := Args{
+			ID:        testutil.RandomContainerID(),
+			Spec:      spec,
+			BundleDir: bundleDir,
+		}
+		c, err := New(conf, args)
+		if err != nil {
+			t.Fatalf("error creating container: %v", err)
+		}
+		defer c.Destroy()
+		if err := c.Restore(conf, dir, false /* direct */, false /* background */, true /* template */, nil /* networkArgs */); err != nil {
+			t.Fatalf("error restoring container %d from template: %v", i, err)
+		}
+		conts = append(conts, c)
+	}
+
+	for i, c := range conts {
+		expectedPL := []*control.Process{
+			newProcessBuilder().Cmd("sleep").PID(1).Process(),
+		}
+		if err := waitForProcessList(c, expectedPL); err != nil {
+			t.Errorf("container %d: %v", i, err)
+		}
+		if ws, err := execute(conf, c, "/bin/true"); err != nil || ws.ExitStatus() != 0 {
+			t.Errorf("container %d: exec failed, status: %v, err: %v", i, ws, err)
+		}
+	}
+
+	// Destroying one sandbox must not affect the other.
+	conts[0].Destroy()
+	if ws, err := execute(conf, conts[1], "/bin/true"); err != nil || ws.ExitStatus() != 0 {
+		t.Errorf("exec after destroying other sandbox failed, status: %v, err: %v", ws, err)
+	}
+}
+
 // TestCheckpointRestoreHostname verifies that hostname is updated on restore
 // if it was not changed inside the container, and is NOT updated if it was changed.
 func TestCheckpointRestoreHostname(t *testing.T) {
@@ -1433,7 +1515,7 @@ func TestCheckpointRestoreHostname(t *testing.T) {
 			}
 			defer cont2.Destroy()
 
-			if err := cont2.Restore(conf, dir, false, false, nil); err != nil {
+			if err := cont2.Restore(conf, dir, false, false, false, nil); err != nil {
 				t.Fatalf("error restoring: %v", err)
 			}
 
@@ -1562,7 +1644,7 @@ func testCheckpointRestoreHostinet(t *testing.T, conf *config.Config, app string
 		t.Fatalf("error creating container: %v", err)
 	}
 	defer cont2.Destroy()
-	if err := cont2.Restore(conf, dir, false /* direct */, false /* background */, nil /* networkArgs */); err != nil {
+	if err := cont2.Restore(conf, dir, false /* direct */, false /* background */, false /* template */, nil /* networkArgs */); err != nil {
 		t.Fatalf("error restoring container: %v", err)
 	}
 	if !cont2.Sandbox.Restored {
@@ -1724,7 +1806,7 @@ func TestCheckpointHostinetRestoreNetworkMismatch(t *testing.T) {
 		t.Fatalf("error creating container: %v", err)
 	}
 	defer cont2.Destroy()
-	err = cont2.Restore(&restoreConf, dir, false /* direct */, false /* background */, nil /* networkArgs */)
+	err = cont2.Restore(&restoreConf, dir, false /* direct */, false /* background */, false /* template */, nil /* networkArgs */)
 	if err == nil {
 		t.Fatalf("restore with mismatched network type succeeded, want error")
 	}
@@ -1930,7 +2012,7 @@ func TestCheckpointRestoreExecKilled(t *testing.T) {
 	}
 	defer cont2.Destroy()
 
-	if err := cont2.Restore(conf, dir, false /* direct */, false /* background */, nil /* networkArgs */); err != nil {
+	if err := cont2.Restore(conf, dir, false /* direct */, false /* background */, false /* template */, nil /* networkArgs */); err != nil {
 		t.Fatalf("error restoring container: %v", err)
 	}
 
@@ -2015,7 +2097,7 @@ func TestCheckpointRestoreCreateMountPoint(t *testing.T) {
 	}
 	defer cont2.Destroy()
 
-	if err := cont2.Restore(conf, dir, false /* direct */, false /* background */, nil /* networkArgs */); err != nil {
+	if err := cont2.Restore(conf, dir, false /* direct */, false /* background */, false /* template */, nil /* networkArgs */); err != nil {
 		t.Fatalf("error restoring container: %v", err)
 	}
 
@@ -2127,7 +2209,7 @@ func TestUnixDomainSockets(t *testing.T) {
 			}
 			defer contRestore.Destroy()
 
-			if err := contRestore.Restore(conf, dir, false /* direct */, false /* background */, nil /* networkArgs */); err != nil {
+			if err := contRestore.Restore(conf, dir, false /* direct */, false /* background */, false /* template */, nil /* networkArgs */); err != nil {
 				t.Fatalf("error restoring container: %v", err)
 			}
 
@@ -4137,7 +4219,7 @@ func TestUsageFD(t *testing.T) {
 	}
 	defer cont2.Destroy()
 
-	if err := cont2.Restore(conf, dir, false /* direct */, false /* background */, nil /* networkArgs */); err != nil {
+	if err := cont2.Restore(conf, dir, false /* direct */, false /* background */, false /* template */, nil /* networkArgs */); err != nil {
 		t.Fatalf("error restoring container: %v", err)
 	}
 
@@ -5408,7 +5490,7 @@ func TestSpecValidation(t *testing.T) {
 			}
 			defer cont2.Destroy()
 
-			err = cont2.Restore(conf, dir, false /* direct */, false /* background */, nil /* networkArgs */)
+			err = cont2.Restore(conf, dir, false /* direct */, false /* background */, false /* template */, nil /* networkArgs */)
 			if err == nil {
 				if test.wantErr == "" {
 					return
@@ -5990,3 +6072,55 @@ func TestReadFile(t *testing.T) {
 		t.Errorf("Got %q (%d bytes), want 'Linux' (5 bytes)", string(content2), len(content2))
 	}
 }
+
+// TestValidateCloneArgsNetNS checks that clones of a sandbox with a named
+// network namespace must each be given their own network namespace.
+func TestValidateCloneArgsNetNS(t *testing.T) {
+	spec := testutil.NewSpecWithArgs("true")
+	spec.Linux = &specs.Linux{
+		Namespaces: []specs.LinuxNamespace{
+			{Type: specs.NetworkNamespace, Path: "/var/run/netns/sandbox"},
+		},
+	}
+	conf := &config.Config{Network: config.NetworkSandbox}
+	for _, tc := range []struct {
+		name    string
+		args    CloneArgs
+		wantErr bool
+	}{
+		{
+			name:    "one clone without netns",
+			args:    CloneArgs{ImagePath: "/img", IDs: []string{"clone1"}},
+			wantErr: true,
+		},
+		{
+			name:    "two clones without netns",
+			args:    CloneArgs{ImagePath: "/img", IDs: []string{"clone1", "clone2"}},
+			wantErr: true,
+		},
+		{
+			name: "one netns per clone",
+			args: CloneArgs{
+				ImagePath: "/img",
+				IDs:       []string{"clone1", "clone2"},
+				NetNS:     []string{"/var/run/netns/clone1", "/var/run/netns/clone2"},
+			},
+		},
+		{
+			name: "netns count mismatch",
+			args: CloneArgs{
+				ImagePath: "/img",
+				IDs:       []string{"clone1", "clone2"},
+				NetNS:     []string{"/var/run/netns/clone1"},
+			},
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateCloneArgs(conf, spec, tc.args)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("validateCloneArgs() = %v, want error: %t", err, tc.wantErr)
+			}
+		})
+	}
+}
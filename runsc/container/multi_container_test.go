@@ -23,6 +23,7 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
@@ -261,7 +262,7 @@ func restoreContainers(conf *config.Config, specs []*specs.Spec, ids []string, i
 		cu.Add(func() { cont.Destroy() })
 		containers = append(containers, cont)
 
-		if err := cont.Restore(conf, imagePath, false /* direct */, false /* background */, nil /* networkArgs */); err != nil {
+		if err := cont.Restore(conf, imagePath, false /* direct */, false /* background */, false /* template */, nil /* networkArgs */); err != nil {
 			return nil, nil, fmt.Errorf("error restoring container: %v", err)
 		}
 
@@ -805,6 +806,61 @@ func TestMultiContainerWait(t *testing.T) {
 	}
 }
 
+// TestMultiContainerWaitContainers checks that WaitContainers returns the
+// first container to exit, all of them, or times out.
+func TestMultiContainerWaitContainers(t *testing.T) {
+	rootDir, cleanup, err := testutil.SetupRootDir()
+	if err != nil {
+		t.Fatalf("error creating root dir: %v", err)
+	}
+	defer cleanup()
+
+	conf := testutil.TestConfig(t)
+	conf.RootDir = rootDir
+
+	// The first container should run the entire duration of the test.
+	cmd2 := []string{"sleep", "1"}
+	cmd3 := []string{"sh", "-c", "sleep 3; exit 3"}
+	specs, ids := createSpecs(sleepCmd, cmd2, cmd3)
+
+	containers, cleanup, err := startContainers(conf, specs, ids)
+	if err != nil {
+		t.Fatalf("error starting containers: %v", err)
+	}
+	defer cleanup()
+
+	res, err := WaitContainers(containers, false /* all */, 0 /* timeout */)
+	if err != nil {
+		t.Fatalf("WaitContainers(any) failed: %v", err)
+	}
+	want := []boot.ContainerExit{{CID: ids[1], WaitStatus: 0}}
+	if res.TimedOut || !reflect.DeepEqual(res.Exited, want) {
+		t.Errorf("WaitContainers(any) = %+v, want exited: %+v", res, want)
+	}
+
+	res, err = WaitContainers(containers[:1], false /* all */, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitContainers(root, timeout) failed: %v", err)
+	}
+	if !res.TimedOut || len(res.Exited) != 0 {
+		t.Errorf("WaitContainers(root, timeout) = %+v, want timed out", res)
+	}
+
+	res, err = WaitContainers(containers[1:], true /* all */, 0 /* timeout */)
+	if err != nil {
+		t.Fatalf("WaitContainers(all) failed: %v", err)
+	}
+	want = []boot.ContainerExit{{CID: ids[1], WaitStatus: 0}, {CID: ids[2], WaitStatus: 3 << 8}}
+	if res.TimedOut || !reflect.DeepEqual(res.Exited, want) {
+		t.Errorf("WaitContainers(all) = %+v, want exited: %+v", res, want)
+	}
+	for _, c := range containers[1:] {
+		if c.Status != Stopped {
+			t.Errorf("container %q has status %v, want %v", c.ID, c.Status, Stopped)
+		}
+	}
+}
+
 // TestExecWait ensures what we can wait on containers and individual processes
 // in the sandbox that have already exited.
 func TestExecWait(t *testing.T) {
@@ -1147,6 +1203,222 @@ func TestMultiContainerProcesses(t *testing.T) {
 	}
 }
 
+// TestMultiContainerPauseResume checks that pausing a subcontainer only stops
+// the tasks in that container. Each container keeps touching a file to
+// indicate that it's running.
+func TestMultiContainerPauseResume(t *testing.T) {
+	dir, err := os.MkdirTemp(testutil.TmpDir(), "pause")
+	if err != nil {
+		t.Fatal("os.MkdirTemp failed:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	rootRunning := filepath.Join(dir, "root")
+	subRunning := filepath.Join(dir, "sub")
+	touchLoop := func(file string) []string {
+		return []string{"/bin/bash", "-c", fmt.Sprintf("while [[ true ]]; do touch %q; sleep 0.1; done", file)}
+	}
+	sps, ids := createSpecs(touchLoop(rootRunning), touchLoop(subRunning))
+	for _, spec := range sps {
+		spec.Mounts = append(spec.Mounts, specs.Mount{
+			Source:      dir,
+			Destination: dir,
+			Type:        "bind",
+		})
+	}
+
+	rootDir, cleanup, err := testutil.SetupRootDir()
+	if err != nil {
+		t.Fatalf("error creating root dir: %v", err)
+	}
+	defer cleanup()
+
+	conf := testutil.TestConfig(t)
+	conf.RootDir = rootDir
+
+	containers, cleanup, err := startContainers(conf, sps, ids)
+	if err != nil {
+		t.Fatalf("error starting containers: %v", err)
+	}
+	defer cleanup()
+
+	for _, file := range []string{rootRunning, subRunning} {
+		if err := waitForFileExist(file); err != nil {
+			t.Fatalf("error waiting for container to start: %v", err)
+		}
+	}
+
+	// Pause the subcontainer.
+	if err := containers[1].Pause(); err != nil {
+		t.Fatalf("error pausing container: %v", err)
+	}
+	if got, want := containers[1].Status, Paused; got != want {
+		t.Errorf("container status got %v, want %v", got, want)
+	}
+	for _, file := range []string{rootRunning, subRunning} {
+		if err := os.Remove(file); err != nil {
+			t.Fatalf("os.Remove(%q) failed: %v", file, err)
+		}
+	}
+
+	// The root container must keep running.
+	if err := waitForFileExist(rootRunning); err != nil {
+		t.Fatalf("root container stopped while subcontainer is paused: %v", err)
+	}
+	// The scripts touch the files every 100ms. Give a bit of time for the
+	// subcontainer to run to catch the case that pause didn't work.
+	time.Sleep(200 * time.Millisecond)
+	if _, err := os.Stat(subRunning); !os.IsNotExist(err) {
+		t.Fatalf("subcontainer did not pause: file exist check: %v", err)
+	}
+
+	// Resume the subcontainer.
+	if err := containers[1].Resume(); err != nil {
+		t.Fatalf("error resuming container: %v", err)
+	}
+	if got, want := containers[1].Status, Running; got != want {
+		t.Errorf("container status got %v, want %v", got, want)
+	}
+	if err := waitForFileExist(subRunning); err != nil {
+		t.Fatalf("error resuming container: file exist check: %v", err)
+	}
+}
+
+// TestSandboxPool checks that a root container with the sandbox pool
+// annotation runs in a sandbox from the pool, and that the sandbox is
+// destroyed with the container.
+func TestSandboxPool(t *testing.T) {
+	rootDir, cleanup, err := testutil.SetupRootDir()
+	if err != nil {
+		t.Fatalf("error creating root dir: %v", err)
+	}
+	defer cleanup()
+	conf := testutil.TestConfig(t)
+	conf.RootDir = rootDir
+
+	// Fill the pool with sandboxes running a placeholder container.
+	placeholder := testutil.NewSpecWithArgs("sleep", "1000")
+	placeholderBundle, cleanup, err := testutil.SetupBundleDir(placeholder)
+	if err != nil {
+		t.Fatalf("error setting up bundle: %v", err)
+	}
+	defer cleanup()
+	const pool = "test-pool"
+	added, err := FillPool(conf, pool, placeholderBundle, 2)
+	if err != nil {
+		t.Fatalf("FillPool failed: %v", err)
+	}
+	defer DrainPool(conf, pool)
+	if len(added) != 2 {
+		t.Fatalf("FillPool added %d sandboxes, want 2", len(added))
+	}
+	// The pool is already full.
+	if added, err := FillPool(conf, pool, placeholderBundle, 2); err != nil || len(added) != 0 {
+		t.Fatalf("FillPool on full pool = %v, %v, want no sandboxes added", added, err)
+	}
+
+	spec := testutil.NewSpecWithArgs("sleep", "1000")
+	spec.Annotations = map[string]string{specutils.AnnotationSandboxPool: pool}
+	bundleDir, cleanup, err := testutil.SetupBundleDir(spec)
+	if err != nil {
+		t.Fatalf("error setting up bundle: %v", err)
+	}
+	defer cleanup()
+	args := Args{
+		ID:        testutil.RandomContainerID(),
+		Spec:      spec,
+		BundleDir: bundleDir,
+	}
+	cont, err := New(conf, args)
+	if err != nil {
+		t.Fatalf("error creating container: %v", err)
+	}
+	defer cont.Destroy()
+	if !cont.PooledSandbox {
+		t.Fatalf("container was not created in a pooled sandbox")
+	}
+	sandboxID := cont.Sandbox.ID
+	if !slices.Contains(added, sandboxID) {
+		t.Fatalf("container runs in sandbox %q, want one of the pool's sandboxes %v", sandboxID, added)
+	}
+	if ids, err := ListPool(conf.RootDir, pool); err != nil || len(ids) != 1 || ids[0] == sandboxID {
+		t.Errorf("ListPool after claim = %v, %v, want the other sandbox", ids, err)
+	}
+
+	// A container whose sandbox-level settings differ from the pool's gets a
+	// new sandbox.
+	mismatched := testutil.NewSpecWithArgs("sleep", "1000")
+	mismatched.Annotations = map[string]string{
+		specutils.AnnotationSandboxPool: pool,
+		"dev.gvisor.test-setting":       "1",
+	}
+	mismatchedBundle, cleanup, err := testutil.SetupBundleDir(mismatched)
+	if err != nil {
+		t.Fatalf("error setting up bundle: %v", err)
+	}
+	defer cleanup()
+	mismatchedCont, err := New(conf, Args{
+		ID:        testutil.RandomContainerID(),
+		Spec:      mismatched,
+		BundleDir: mismatchedBundle,
+	})
+	if err != nil {
+		t.Fatalf("error creating container: %v", err)
+	}
+	if mismatchedCont.PooledSandbox {
+		t.Errorf("container with different sandbox annotations was created in a pooled sandbox")
+	}
+	if err := mismatchedCont.Destroy(); err != nil {
+		t.Errorf("error destroying container: %v", err)
+	}
+	if ids, err := ListPool(conf.RootDir, pool); err != nil || len(ids) != 1 {
+		t.Errorf("ListPool after mismatched container = %v, %v, want one sandbox", ids, err)
+	}
+
+	if err := cont.Start(conf); err != nil {
+		t.Fatalf("error starting container: %v", err)
+	}
+	if err := waitForProcessCount(cont, 1); err != nil {
+		t.Errorf("failed to wait for sleep to start: %v", err)
+	}
+	if ws, err := execute(conf, cont, "/bin/true"); err != nil || ws.ExitStatus() != 0 {
+		t.Errorf("exec failed, status: %v, err: %v", ws, err)
+	}
+
+	// Destroying the container destroys its sandbox.
+	if err := cont.Destroy(); err != nil {
+		t.Fatalf("error destroying container: %v", err)
+	}
+	if _, err := Load(conf.RootDir, FullID{SandboxID: sandboxID, ContainerID: sandboxID}, LoadOpts{Exact: true}); !os.IsNotExist(err) {
+		t.Errorf("loading pooled sandbox after destroying its container: got %v, want not exist", err)
+	}
+}
+
+// TestPooledCgroupPath checks that each pooled sandbox gets its own cgroup.
+func TestPooledCgroupPath(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		systemd bool
+		path    string
+		want    string
+	}{
+		{name: "empty", path: "", want: ""},
+		{name: "absolute", path: "/pool/placeholder", want: "/pool/pool-1234"},
+		{name: "top-level", path: "/placeholder", want: "/pool-1234"},
+		{name: "relative", path: "placeholder", want: "pool-1234"},
+		{name: "systemd", path: "system.slice:runsc:placeholder", want: "system.slice:runsc:pool-1234"},
+		{name: "systemd-flag", systemd: true, path: "system.slice:runsc:placeholder", want: "system.slice:runsc:pool-1234"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := testutil.TestConfig(t)
+			conf.SystemdCgroup = tc.systemd
+			if got := pooledCgroupPath(conf, tc.path, "pool-1234"); got != tc.want {
+				t.Errorf("pooledCgroupPath(%q) = %q, want %q", tc.path, got, tc.want)
+			}
+		})
+	}
+}
+
 // TestMultiContainerKillAll checks that all process that belong to a container
 // are killed when SIGKILL is sent to *all* processes in that container.
 func TestMultiContainerKillAll(t *testing.T) {
@@ -0,0 +1,404 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/runsc/cgroup"
+	"gvisor.dev/gvisor/runsc/config"
+	"gvisor.dev/gvisor/runsc/specutils"
+)
+
+// A sandbox pool is a set of sandboxes that are booted ahead of time, so that
+// containers can start without waiting for a sandbox to boot.
+//
+// Each pooled sandbox runs a placeholder root container, typically a "pause"
+// process, which keeps the sandbox running while it's idle. A root container
+// created with the specutils.AnnotationSandboxPool annotation claims one of
+// the pool's sandboxes and runs in it as a subcontainer. The claimed sandbox
+// is destroyed with the container, so pooled sandboxes are never reused.
+//
+// A sandbox is only claimed if the claiming container's sandbox-level settings
+// (see poolSettingsMismatch) match the placeholder's, since they can't be
+// changed once the sandbox is running. Otherwise, the container is created in
+// a new sandbox.
+//
+// Cgroups are not among these settings. Each pooled sandbox runs in its own
+// cgroup, derived from the placeholder's (see pooledCgroupPath), and is moved
+// to the claiming container's cgroup, with the container's resource limits,
+// when it's claimed (see joinPooledSandboxCgroup).
+//
+// Idle sandboxes are recorded as files named after the sandbox ID in the pool
+// directory, containing a poolEntry. A sandbox is claimed by removing its
+// file, which succeeds for only one of concurrent claimers.
+
+// poolsDir is the directory in the root directory containing pool directories.
+const poolsDir = "pools"
+
+// poolEntry is the content of the file that records an idle sandbox in a pool.
+type poolEntry struct {
+	// Flags are the runsc flags that the sandbox was created with.
+	Flags []string `json:"flags"`
+}
+
+// poolDir returns the directory that records the idle sandboxes of the named
+// pool.
+func poolDir(rootDir, name string) string {
+	return filepath.Join(rootDir, poolsDir, name)
+}
+
+// FillPool boots sandboxes running the placeholder container in bundleDir,
+// and adds them to the named pool, until the pool has size idle sandboxes. It
+// returns the IDs of the added sandboxes.
+func FillPool(conf *config.Config, name, bundleDir string, size int) ([]string, error) {
+	if err := validateID(name); err != nil {
+		return nil, fmt.Errorf("invalid pool name: %w", err)
+	}
+	dir := poolDir(conf.RootDir, name)
+	if err := os.MkdirAll(dir, 0711); err != nil {
+		return nil, fmt.Errorf("creating pool directory %q: %w", dir, err)
+	}
+	idle, err := ListPool(conf.RootDir, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var added []string
+	for i := len(idle); i < size; i++ {
+		id, err := newPooledSandbox(conf, name, bundleDir)
+		if err != nil {
+			return added, err
+		}
+		added = append(added, id)
+	}
+	return added, nil
+}
+
+// newPooledSandbox boots a sandbox running the placeholder container and adds
+// it to the named pool.
+func newPooledSandbox(conf *config.Config, name, bundleDir string) (string, error) {
+	// New modifies the spec, so each sandbox needs its own copy.
+	spec, err := specutils.ReadSpec(bundleDir, conf)
+	if err != nil {
+		return "", fmt.Errorf("reading spec: %w", err)
+	}
+	if !specutils.IsRootContainer(spec) {
+		return "", fmt.Errorf("pool placeholder container must be a root container")
+	}
+	if _, ok := specutils.SandboxPool(spec); ok {
+		return "", fmt.Errorf("pool placeholder container can't have the %q annotation", specutils.AnnotationSandboxPool)
+	}
+
+	var suffix [8]byte
+	if _, err := rand.Read(suffix[:]); err != nil {
+		return "", err
+	}
+	id := name + "-" + hex.EncodeToString(suffix[:])
+	if spec.Linux != nil {
+		spec.Linux.CgroupsPath = pooledCgroupPath(conf, spec.Linux.CgroupsPath, id)
+	}
+	// The sandbox cgroup of the claiming container is only set up when the
+	// sandbox is claimed, so the placeholder's is not used.
+	delete(spec.Annotations, cgroupParentAnnotation)
+	c, err := New(conf, Args{
+		ID:        id,
+		Spec:      spec,
+		BundleDir: bundleDir,
+	})
+	if err != nil {
+		return "", fmt.Errorf("creating pooled sandbox: %w", err)
+	}
+	if err := c.Start(conf); err != nil {
+		_ = c.Destroy()
+		return "", fmt.Errorf("starting pooled sandbox: %w", err)
+	}
+	entry, err := json.Marshal(poolEntry{Flags: conf.ToFlags()})
+	if err != nil {
+		_ = c.Destroy()
+		return "", err
+	}
+	f, err := os.OpenFile(filepath.Join(poolDir(conf.RootDir, name), id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		_ = c.Destroy()
+		return "", fmt.Errorf("adding sandbox to pool: %w", err)
+	}
+	_, err = f.Write(entry)
+	f.Close()
+	if err != nil {
+		_ = c.Destroy()
+		return "", fmt.Errorf("adding sandbox to pool: %w", err)
+	}
+	log.Infof("Added sandbox %q to pool %q", id, name)
+	return id, nil
+}
+
+// pooledCgroupPath returns the cgroup path for the pooled sandbox with the
+// given ID, given the cgroup path in the placeholder's spec. Pooled sandboxes
+// must not share a cgroup, since the limits in the spec apply to each of them,
+// and destroying one of them removes its cgroup. The returned path is a
+// sibling of path, named after the sandbox; for systemd paths in the form
+// "slice:prefix:name", the name is replaced.
+func pooledCgroupPath(conf *config.Config, path, id string) string {
+	if path == "" {
+		// createRoot uses a cgroup named after the sandbox.
+		return ""
+	}
+	if conf.SystemdCgroup || cgroup.LikelySystemdPath(path) {
+		if parts := strings.SplitN(path, ":", 3); len(parts) == 3 {
+			return parts[0] + ":" + parts[1] + ":" + id
+		}
+		// Invalid, fail when the cgroup is created.
+		return path
+	}
+	return filepath.Join(filepath.Dir(path), id)
+}
+
+// ListPool returns the IDs of the idle sandboxes in the named pool.
+func ListPool(rootDir, name string) ([]string, error) {
+	entries, err := os.ReadDir(poolDir(rootDir, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading pool %q: %w", name, err)
+	}
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.Name())
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// DrainPool destroys all idle sandboxes in the named pool.
+func DrainPool(conf *config.Config, name string) error {
+	for {
+		c, err := claimFromPool(conf, name, nil /* spec */)
+		if err != nil {
+			return err
+		}
+		if c == nil {
+			return os.RemoveAll(poolDir(conf.RootDir, name))
+		}
+		if err := c.Destroy(); err != nil {
+			return fmt.Errorf("destroying pooled sandbox %q: %w", c.ID, err)
+		}
+	}
+}
+
+// claimFromPool removes a running sandbox from the named pool and returns its
+// placeholder container, or nil if the pool has no suitable running sandboxes.
+// If spec is not nil, only sandboxes whose sandbox-level settings match conf
+// and spec are claimed. Pooled sandboxes that have stopped are destroyed.
+func claimFromPool(conf *config.Config, name string, spec *specs.Spec) (*Container, error) {
+	ids, err := ListPool(conf.RootDir, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		path := filepath.Join(poolDir(conf.RootDir, name), id)
+		c, err := Load(conf.RootDir, FullID{SandboxID: id, ContainerID: id}, LoadOpts{Exact: true})
+		if err != nil {
+			log.Warningf("Skipping pooled sandbox %q: %v", id, err)
+			continue
+		}
+		if spec != nil {
+			var entry poolEntry
+			data, err := os.ReadFile(path)
+			if err == nil {
+				err = json.Unmarshal(data, &entry)
+			}
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					// Claimed concurrently.
+					continue
+				}
+				log.Warningf("Skipping pooled sandbox %q: reading pool entry: %v", id, err)
+				continue
+			}
+			if mismatch := poolSettingsMismatch(conf, spec, entry.Flags, c.Spec); mismatch != "" {
+				log.Infof("Not claiming pooled sandbox %q: %s differs", id, mismatch)
+				continue
+			}
+		}
+		if err := os.Remove(path); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// Claimed concurrently.
+				continue
+			}
+			return nil, fmt.Errorf("claiming sandbox %q from pool %q: %w", id, name, err)
+		}
+		if c.Status != Running || !c.IsSandboxRunning() {
+			log.Warningf("Destroying pooled sandbox %q, which is no longer running", id)
+			_ = c.Destroy()
+			continue
+		}
+		return c, nil
+	}
+	return nil, nil
+}
+
+// claimPooledSandbox claims a sandbox from the pool named by spec's
+// specutils.AnnotationSandboxPool annotation, and modifies spec so that the
+// container is created in it as a subcontainer. It returns false if spec
+// doesn't request a pooled sandbox, or if none with matching sandbox-level
+// settings are available, in which case the container should be created in a
+// new sandbox.
+func claimPooledSandbox(conf *config.Config, spec *specs.Spec) (bool, error) {
+	name, ok := specutils.SandboxPool(spec)
+	if !ok {
+		return false, nil
+	}
+	placeholder, err := claimFromPool(conf, name, spec)
+	if err != nil {
+		return false, err
+	}
+	if placeholder == nil {
+		log.Infof("No suitable idle sandboxes in pool %q, creating a new sandbox", name)
+		return false, nil
+	}
+	log.Infof("Claimed sandbox %q from pool %q", placeholder.Sandbox.ID, name)
+	if spec.Annotations == nil {
+		spec.Annotations = make(map[string]string)
+	}
+	spec.Annotations[specutils.ContainerdContainerTypeAnnotation] = specutils.ContainerdContainerTypeContainer
+	spec.Annotations[specutils.ContainerdSandboxIDAnnotation] = placeholder.Sandbox.ID
+	return true, nil
+}
+
+// poolSettingsMismatch returns a description of the first sandbox-level
+// setting that differs between a container created with conf and spec and a
+// pooled sandbox created with the given runsc flags and placeholder spec, or
+// "" if they all match. These settings apply to the whole sandbox, so a
+// container that claims a pooled sandbox can't change them.
+func poolSettingsMismatch(conf *config.Config, spec *specs.Spec, poolFlags []string, placeholder *specs.Spec) string {
+	if !slices.Equal(conf.ToFlags(), poolFlags) {
+		return "runsc flags"
+	}
+	netns, _ := specutils.GetNS(specs.NetworkNamespace, spec)
+	poolNetns, _ := specutils.GetNS(specs.NetworkNamespace, placeholder)
+	if netns != poolNetns {
+		return "network namespace"
+	}
+	if !maps.Equal(sandboxAnnotations(spec), sandboxAnnotations(placeholder)) {
+		return "sandbox annotations"
+	}
+	return ""
+}
+
+// sandboxAnnotations returns the gVisor annotations in spec, which configure
+// the sandbox, except for the sandbox pool and cgroup parent annotations.
+func sandboxAnnotations(spec *specs.Spec) map[string]string {
+	annotations := make(map[string]string)
+	for k, v := range spec.Annotations {
+		if strings.HasPrefix(k, "dev.gvisor.") && k != specutils.AnnotationSandboxPool && k != cgroupParentAnnotation {
+			annotations[k] = v
+		}
+	}
+	return annotations
+}
+
+// joinPooledSandboxCgroup moves the claimed pooled sandbox that c is created
+// in, whose placeholder container is given, from its own cgroup to the cgroup
+// that c would have been created in as a root container, with the resource
+// limits in spec, like createRoot does. spec has already been modified by
+// claimPooledSandbox.
+func (c *Container) joinPooledSandboxCgroup(conf *config.Config, placeholder *Container, spec *specs.Spec) error {
+	poolCgroup := c.Sandbox.CgroupJSON.Cgroup
+	if poolCgroup == nil {
+		return nil
+	}
+	if spec.Linux == nil {
+		spec.Linux = &specs.Linux{}
+	}
+	if spec.Linux.CgroupsPath == "" {
+		spec.Linux.CgroupsPath = "/" + c.ID
+	}
+	parentPath, hasParent := spec.Annotations[cgroupParentAnnotation]
+	if !hasParent {
+		parentPath = spec.Linux.CgroupsPath
+	}
+	parentCgroup, err := c.createParentCgroup(parentPath, conf)
+	if err != nil {
+		return fmt.Errorf("cannot set up cgroup for pooled sandbox: %w", err)
+	}
+	parentCgroup, err = cgroupInstall(conf, parentCgroup, spec.Linux.Resources)
+	if parentCgroup == nil || err != nil {
+		return err
+	}
+	sandboxCgroup := parentCgroup
+	if hasParent {
+		// As in createRoot, the sandbox cgroup has a child cgroup for the
+		// container, which the sandbox joins if it can't join the parent.
+		subCgroup, err := c.setupCgroupForSubcontainer(conf, spec)
+		if err != nil {
+			return err
+		}
+		if subCgroup != nil && !conf.SystemdCgroup && cgroup.IsOnlyV2() {
+			sandboxCgroup = subCgroup
+		} else {
+			c.CompatCgroup = cgroup.CgroupJSON{Cgroup: subCgroup}
+		}
+	}
+
+	log.Infof("Moving sandbox %q from cgroup %q to %q", c.Sandbox.ID, poolCgroup.MakePath(""), sandboxCgroup.MakePath(""))
+	if err := cgroup.RunInCgroup(sandboxCgroup, func() error {
+		return sandboxCgroup.MoveProcs(poolCgroup)
+	}); err != nil {
+		return fmt.Errorf("moving pooled sandbox to cgroup: %w", err)
+	}
+	c.Sandbox.CgroupJSON = cgroup.CgroupJSON{Cgroup: sandboxCgroup}
+	// The placeholder removes the sandbox cgroup when it's destroyed.
+	if err := placeholder.Saver.lock(BlockAcquire); err != nil {
+		return err
+	}
+	placeholder.Sandbox = c.Sandbox
+	err = placeholder.saveLocked()
+	placeholder.Saver.unlock()
+	if err != nil {
+		return err
+	}
+	if err := poolCgroup.Uninstall(); err != nil {
+		log.Warningf("Failed to remove cgroup of pooled sandbox %q: %v", c.Sandbox.ID, err)
+	}
+	// Let applications in the sandbox observe the new CPU limit.
+	return c.Sandbox.UpdateCPUs(conf)
+}
+
+// destroyPooledSandbox destroys the sandbox with the given ID, which was
+// claimed from a pool, by destroying its placeholder container.
+func destroyPooledSandbox(rootDir, sandboxID string) error {
+	placeholder, err := Load(rootDir, FullID{SandboxID: sandboxID, ContainerID: sandboxID}, LoadOpts{Exact: true})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return placeholder.Destroy()
+}
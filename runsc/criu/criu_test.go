@@ -0,0 +1,311 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package criu
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// msg builds a protobuf message.
+type msg []byte
+
+func (m msg) varint(num protowire.Number, v uint64) msg {
+	m = protowire.AppendTag(m, num, protowire.VarintType)
+	return protowire.AppendVarint(m, v)
+}
+
+func (m msg) bytes(num protowire.Number, b []byte) msg {
+	m = protowire.AppendTag(m, num, protowire.BytesType)
+	return protowire.AppendBytes(m, b)
+}
+
+// image builds an image file with the given entries.
+func image(entries ...msg) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, []uint32{imgCommonMagic, 0x12345678})
+	for _, e := range entries {
+		binary.Write(&buf, binary.LittleEndian, uint32(len(e)))
+		buf.Write(e)
+	}
+	return buf.Bytes()
+}
+
+func opener(images map[string][]byte) Opener {
+	return func(name string) (io.ReadCloser, error) {
+		b, ok := images[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+		}
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+}
+
+func testImages() map[string][]byte {
+	var regs msg
+	for i := 1; i <= 27; i++ {
+		regs = regs.varint(protowire.Number(i), uint64(i*100))
+	}
+	// st_space is packed and xmm_space is not.
+	var st msg
+	for i := 0; i < 32; i++ {
+		st = protowire.AppendVarint(st, uint64(i))
+	}
+	fpregs := msg{}.varint(1, 0x37f).varint(7, 0x1f80).bytes(9, st)
+	for i := 0; i < 64; i++ {
+		fpregs = fpregs.varint(10, uint64(i))
+	}
+	var ymmh msg
+	for i := 0; i < 64; i++ {
+		ymmh = protowire.AppendVarint(ymmh, uint64(i))
+	}
+	fpregs = fpregs.bytes(13, msg{}.varint(1, 0x7).bytes(2, ymmh).varint(5, 1))
+	core := msg{}.
+		varint(1, coreArchX86_64).
+		bytes(2, msg{}.varint(1, 0).bytes(2, regs).bytes(3, fpregs)).
+		bytes(3, msg{}.varint(1, taskAlive).varint(5, 0x4000).bytes(6, []byte("sleep"))).
+		bytes(4, msg{}.varint(1, 7).varint(2, 8))
+	vma := msg{}.varint(1, 0x400000).varint(2, 0x401000).varint(3, 0).varint(4, 3).
+		varint(5, 5).varint(6, 2).varint(7, VMAAreaRegular|VMAFilePrivate)
+	mm := msg{}.varint(1, 0x400000).varint(7, 0x600000).varint(12, 3).
+		varint(13, 33).varint(13, 0x7fff0000).bytes(14, vma)
+	return map[string][]byte{
+		"pstree.img": image(msg{}.varint(1, 42).varint(2, 0).varint(3, 42).varint(4, 42).varint(5, 42)),
+		"files.img": image(
+			msg{}.varint(1, FDTypeReg).varint(2, 3).bytes(3, msg{}.varint(1, 3).varint(2, 0).varint(3, 0).bytes(6, []byte("/bin/sleep"))),
+			msg{}.varint(1, FDTypeReg).varint(2, 4).bytes(3, msg{}.varint(1, 4).varint(2, 1).varint(3, 10).bytes(6, []byte("tmp/log"))),
+		),
+		"core-42.img": image(core),
+		"mm-42.img":   image(mm),
+		"pagemap-42.img": image(
+			msg{}.varint(1, 5),
+			msg{}.varint(1, 0x400000).varint(2, 1).varint(4, pagemapPresent),
+			// Zero pages aren't dumped.
+			msg{}.varint(1, 0x500000).varint(2, 4).varint(4, 0),
+			// Entries of older versions don't have flags.
+			msg{}.varint(1, 0x600000).varint(2, 2),
+		),
+		"fdinfo-8.img": image(msg{}.varint(1, 4).varint(2, 1).varint(3, FDTypeReg).varint(4, 3)),
+		"fs-42.img":    image(msg{}.varint(1, 4).varint(2, 3).varint(3, 0o22)),
+		"sigacts-42.img": image(
+			msg{}.varint(1, 0x1000).varint(2, 0x4000000).varint(3, 0x2000).varint(4, 1),
+			msg{}.varint(1, 1),
+		),
+	}
+}
+
+func TestLoad(t *testing.T) {
+	d, err := Load(opener(testImages()))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	wantFP := &X86FPRegs{Cwd: 0x37f, MXCSR: 0x1f80}
+	for i := 0; i < 32; i++ {
+		wantFP.STSpace = append(wantFP.STSpace, uint32(i))
+	}
+	for i := 0; i < 64; i++ {
+		wantFP.XMMSpace = append(wantFP.XMMSpace, uint32(i))
+	}
+	wantFP.Xsave = &X86Xsave{XstateBV: 0x7, Other: map[int][]uint64{5: {1}}}
+	for i := 0; i < 64; i++ {
+		wantFP.Xsave.YMMHSpace = append(wantFP.Xsave.YMMHSpace, uint32(i))
+	}
+	want := &Dump{
+		Processes: []*Process{{
+			PID:            42,
+			PGID:           42,
+			SID:            42,
+			Threads:        []uint32{42},
+			Comm:           "sleep",
+			BlockedSignals: 0x4000,
+			Regs: X86Regs{
+				R15: 100, R14: 200, R13: 300, R12: 400, Bp: 500, Bx: 600,
+				R11: 700, R10: 800, R9: 900, R8: 1000, Ax: 1100, Cx: 1200,
+				Dx: 1300, Si: 1400, Di: 1500, OrigAx: 1600, IP: 1700, CS: 1800,
+				Flags: 1900, SP: 2000, SS: 2100, FSBase: 2200, GSBase: 2300,
+				DS: 2400, ES: 2500, FS: 2600, GS: 2700,
+			},
+			FPRegs: wantFP,
+			MM: MM{
+				StartCode: 0x400000,
+				Brk:       0x600000,
+				ExeFileID: 3,
+				Auxv:      []uint64{33, 0x7fff0000},
+				VMAs: []VMA{{
+					Start:  0x400000,
+					End:    0x401000,
+					Shmid:  3,
+					Prot:   5,
+					Flags:  2,
+					Status: VMAAreaRegular | VMAFilePrivate,
+				}},
+			},
+			PagesID: 5,
+			Pages: []PagemapEntry{
+				{Vaddr: 0x400000, NrPages: 1},
+				{Vaddr: 0x600000, NrPages: 2},
+			},
+			FDs:    []FD{{ID: 4, Flags: 1, Type: FDTypeReg, FD: 3}},
+			HasFS:  true,
+			CwdID:  4,
+			RootID: 3,
+			Umask:  0o22,
+			SigActions: map[int]SigAction{
+				1: {Handler: 0x1000, Flags: 0x4000000, Restorer: 0x2000, Mask: 1},
+				2: {Handler: 1},
+			},
+		}},
+		Files: map[uint32]*RegFile{
+			3: {ID: 3, Name: "/bin/sleep"},
+			4: {ID: 4, Flags: 1, Pos: 10, Name: "/tmp/log"},
+		},
+	}
+	if diff := cmp.Diff(want, d); diff != "" {
+		t.Errorf("Load returned unexpected dump (-want +got):\n%s", diff)
+	}
+}
+
+func TestImages(t *testing.T) {
+	images := testImages()
+	images["pages-5.img"] = make([]byte, 3*4096)
+	// Files that aren't read to import the dump.
+	images["dump.log"] = []byte("log")
+	images["stats-dump"] = nil
+	images["tty-info.img"] = image()
+
+	got, err := Images(opener(images))
+	if err != nil {
+		t.Fatalf("Images failed: %v", err)
+	}
+	want := []string{
+		"files.img",
+		"pstree.img",
+		"core-42.img",
+		"mm-42.img",
+		"pagemap-42.img",
+		"fdinfo-8.img",
+		"fs-42.img",
+		"sigacts-42.img",
+		"pages-5.img",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Images returned unexpected names (-want +got):\n%s", diff)
+	}
+
+	// The pages image is needed since the process has pages.
+	delete(images, "pages-5.img")
+	if _, err := Images(opener(images)); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Images without pages image: got error %v, want %v", err, fs.ErrNotExist)
+	}
+}
+
+func TestLoadOldFormat(t *testing.T) {
+	images := testImages()
+	// Older versions of CRIU write regular files and IDs to their own
+	// images.
+	delete(images, "files.img")
+	images["reg-files.img"] = image(
+		msg{}.varint(1, 3).bytes(6, []byte("/bin/sleep")),
+		msg{}.varint(1, 4).bytes(6, []byte("/tmp/log")),
+	)
+	core := msg{}.
+		varint(1, coreArchX86_64).
+		bytes(2, msg{}.bytes(2, msg{}.varint(17, 0x401000))).
+		bytes(3, msg{}.varint(1, taskAlive))
+	images["core-42.img"] = image(core)
+	images["ids-42.img"] = image(msg{}.varint(2, 8))
+
+	d, err := Load(opener(images))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	p := d.Processes[0]
+	if p.Regs.IP != 0x401000 {
+		t.Errorf("got IP %#x, want %#x", p.Regs.IP, 0x401000)
+	}
+	if len(p.FDs) != 1 || d.Files[p.FDs[0].ID].Name != "/tmp/log" {
+		t.Errorf("got FDs %+v and files %+v, want FD of /tmp/log", p.FDs, d.Files)
+	}
+}
+
+func TestLoadUnsupported(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		modify func(images map[string][]byte)
+		want   string
+	}{
+		{
+			name: "bad magic",
+			modify: func(images map[string][]byte) {
+				images["pstree.img"] = make([]byte, 8)
+			},
+			want: "bad image magic",
+		},
+		{
+			name: "arch",
+			modify: func(images map[string][]byte) {
+				images["core-42.img"] = image(msg{}.varint(1, 3))
+			},
+			want: "unsupported architecture",
+		},
+		{
+			name: "zombie",
+			modify: func(images map[string][]byte) {
+				images["core-42.img"] = image(msg{}.
+					varint(1, coreArchX86_64).
+					bytes(2, msg{}.bytes(2, msg{})).
+					bytes(3, msg{}.varint(1, 6)).
+					bytes(4, msg{}.varint(2, 8)))
+			},
+			want: "task state 6",
+		},
+		{
+			name: "incremental",
+			modify: func(images map[string][]byte) {
+				images["pagemap-42.img"] = image(
+					msg{}.varint(1, 5),
+					msg{}.varint(1, 0x400000).varint(2, 1).varint(4, pagemapParent),
+				)
+			},
+			want: "incremental dumps",
+		},
+		{
+			name: "missing image",
+			modify: func(images map[string][]byte) {
+				delete(images, "mm-42.img")
+			},
+			want: "mm-42.img",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			images := testImages()
+			test.modify(images)
+			_, err := Load(opener(images))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("Load got error %v, want error containing %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,882 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package criu
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// Values of core_entry.mtype.
+const coreArchX86_64 = 1
+
+// Values of user_x86_regs_entry.mode.
+const x86RegsModeCompat = 2
+
+// taskAlive is the task_state of a task that was running when it was dumped.
+const taskAlive = 1
+
+// Flags in VMA.Status, from CRIU's criu/include/image.h.
+const (
+	VMAAreaRegular   = 1 << 0
+	VMAAreaStack     = 1 << 1
+	VMAAreaVsyscall  = 1 << 2
+	VMAAreaVDSO      = 1 << 3
+	VMAAreaHeap      = 1 << 5
+	VMAFilePrivate   = 1 << 6
+	VMAFileShared    = 1 << 7
+	VMAAnonShared    = 1 << 8
+	VMAAnonPrivate   = 1 << 9
+	VMAAreaSysVIPC   = 1 << 10
+	VMAAreaSocket    = 1 << 11
+	VMAAreaVVAR      = 1 << 12
+	VMAAreaAIORing   = 1 << 13
+	VMAAreaMemfd     = 1 << 14
+	VMAAreaUnsupport = 1 << 31
+)
+
+// Flags of pagemap entries.
+const (
+	pagemapParent  = 1 << 0
+	pagemapLazy    = 1 << 1
+	pagemapPresent = 1 << 2
+)
+
+// FDTypeReg is the FD.Type of regular files and directories. Other types
+// aren't supported.
+const FDTypeReg = 1
+
+// Dump is the part of a CRIU dump needed to import its processes.
+type Dump struct {
+	// Processes are the dumped processes. Parents precede their children.
+	Processes []*Process
+
+	// Files are the dumped regular files and directories, by ID.
+	Files map[uint32]*RegFile
+}
+
+// Process is a dumped process.
+type Process struct {
+	PID     uint32
+	PPID    uint32
+	PGID    uint32
+	SID     uint32
+	Threads []uint32
+
+	// Comm is the process name.
+	Comm string
+
+	// BlockedSignals is the signal mask of the main thread.
+	BlockedSignals uint64
+
+	// Regs and FPRegs are the registers of the main thread.
+	Regs   X86Regs
+	FPRegs *X86FPRegs
+
+	MM MM
+
+	// PagesID identifies the pages image containing the contents of Pages.
+	PagesID uint32
+
+	// Pages are the pagemap entries whose contents are in the pages image,
+	// in the order of their contents.
+	Pages []PagemapEntry
+
+	FDs []FD
+
+	// HasFS is true if CwdID, RootID and Umask are set.
+	HasFS  bool
+	CwdID  uint32
+	RootID uint32
+	Umask  uint32
+
+	// SigActions are the signal actions of the process, indexed by signal
+	// number. SIGKILL and SIGSTOP are never set.
+	SigActions map[int]SigAction
+}
+
+// X86Regs are the general purpose registers of an x86-64 thread, in the
+// order of struct user_regs_struct.
+type X86Regs struct {
+	R15    uint64
+	R14    uint64
+	R13    uint64
+	R12    uint64
+	Bp     uint64
+	Bx     uint64
+	R11    uint64
+	R10    uint64
+	R9     uint64
+	R8     uint64
+	Ax     uint64
+	Cx     uint64
+	Dx     uint64
+	Si     uint64
+	Di     uint64
+	OrigAx uint64
+	IP     uint64
+	CS     uint64
+	Flags  uint64
+	SP     uint64
+	SS     uint64
+	FSBase uint64
+	GSBase uint64
+	DS     uint64
+	ES     uint64
+	FS     uint64
+	GS     uint64
+}
+
+// X86FPRegs is the FXSAVE area of an x86-64 thread.
+type X86FPRegs struct {
+	Cwd       uint32
+	Swd       uint32
+	Twd       uint32
+	Fop       uint32
+	RIP       uint64
+	RDP       uint64
+	MXCSR     uint32
+	MXCSRMask uint32
+	STSpace   []uint32
+	XMMSpace  []uint32
+
+	// Xsave is the extended state of the thread beyond the FXSAVE area, or
+	// nil if the dump doesn't have it.
+	Xsave *X86Xsave
+}
+
+// X86Xsave is the extended state of an x86-64 thread, as saved by XSAVE.
+type X86Xsave struct {
+	// XstateBV is the XSTATE_BV field of the XSAVE header.
+	XstateBV uint64
+
+	// YMMHSpace is the upper halves of the YMM registers.
+	YMMHSpace []uint32
+
+	// Other are the other components of the extended state (MPX, AVX-512),
+	// by field number, if present in the dump.
+	Other map[int][]uint64
+}
+
+// MM is the memory layout of a process.
+type MM struct {
+	StartCode  uint64
+	EndCode    uint64
+	StartData  uint64
+	EndData    uint64
+	StartStack uint64
+	StartBrk   uint64
+	Brk        uint64
+	ArgStart   uint64
+	ArgEnd     uint64
+	EnvStart   uint64
+	EnvEnd     uint64
+	ExeFileID  uint32
+	Auxv       []uint64
+	VMAs       []VMA
+}
+
+// VMA is a memory mapping.
+type VMA struct {
+	Start uint64
+	End   uint64
+	Pgoff uint64
+
+	// Shmid is the ID of the mapped file for file mappings, and identifies
+	// the shared memory object of shared anonymous mappings.
+	Shmid  uint64
+	Prot   uint32
+	Flags  uint32
+	Status uint32
+}
+
+// PagemapEntry describes pages whose contents were dumped.
+type PagemapEntry struct {
+	Vaddr   uint64
+	NrPages uint32
+}
+
+// FD is an open file descriptor.
+type FD struct {
+	// ID is the ID of the open file, shared by duplicated FDs.
+	ID uint32
+
+	// Flags are the FD flags, i.e. FD_CLOEXEC.
+	Flags uint32
+
+	// Type is the type of the open file, e.g. FDTypeReg.
+	Type uint32
+
+	FD uint32
+}
+
+// RegFile is an open regular file or directory.
+type RegFile struct {
+	ID uint32
+
+	// Flags are the file status flags, as passed to open(2).
+	Flags uint32
+
+	// Pos is the file offset.
+	Pos uint64
+
+	// Name is the path of the file in the dumped mount namespace.
+	Name string
+}
+
+// SigAction is the action of a signal.
+type SigAction struct {
+	Handler  uint64
+	Flags    uint64
+	Restorer uint64
+	Mask     uint64
+}
+
+// Opener opens the image file with the given name. If there's no such file,
+// it returns an error wrapping fs.ErrNotExist.
+type Opener func(name string) (io.ReadCloser, error)
+
+// readImage reads the entries of the named image.
+func readImage(open Opener, name string) ([][]byte, error) {
+	f, err := open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := readEntries(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	return entries, nil
+}
+
+// readOneImage reads the single entry of the named image.
+func readOneImage(open Opener, name string) ([]byte, error) {
+	entries, err := readImage(open, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) != 1 {
+		return nil, fmt.Errorf("%s has %d entries, want 1", name, len(entries))
+	}
+	return entries[0], nil
+}
+
+// PagesName returns the name of the pages image with the given ID.
+func PagesName(id uint32) string {
+	return fmt.Sprintf("pages-%d.img", id)
+}
+
+// ShmemPagemapName returns the name of the pagemap image of the shared
+// anonymous memory identified by shmid. Pagemap entries of shared memory are
+// offsets into it rather than addresses.
+func ShmemPagemapName(shmid uint64) string {
+	return fmt.Sprintf("pagemap-shmem-%d.img", shmid)
+}
+
+// Load reads the dump whose images are opened by open.
+func Load(open Opener) (*Dump, error) {
+	d := &Dump{Files: make(map[uint32]*RegFile)}
+	if err := d.loadFiles(open); err != nil {
+		return nil, err
+	}
+	pstree, err := readImage(open, "pstree.img")
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range pstree {
+		p, err := decodePstree(e)
+		if err != nil {
+			return nil, fmt.Errorf("decoding pstree.img: %w", err)
+		}
+		if err := p.load(open); err != nil {
+			return nil, fmt.Errorf("process %d: %w", p.PID, err)
+		}
+		d.Processes = append(d.Processes, p)
+	}
+	if len(d.Processes) == 0 {
+		return nil, fmt.Errorf("dump has no processes")
+	}
+	return d, nil
+}
+
+// Images returns the names of the images of the dump whose images are opened
+// by open that are read to import it: the images read by Load, and the
+// pagemaps and pages images of shared anonymous memory and the pages images
+// of processes, which are read when the memory is restored. Other files in the
+// image directory are not needed.
+func Images(open Opener) ([]string, error) {
+	var names []string
+	seen := make(map[string]bool)
+	record := func(name string) (io.ReadCloser, error) {
+		f, err := open(name)
+		if err == nil && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+		return f, err
+	}
+	require := func(name string) error {
+		f, err := record(name)
+		if err != nil {
+			return err
+		}
+		return f.Close()
+	}
+
+	d, err := Load(record)
+	if err != nil {
+		return nil, err
+	}
+	shmids := make(map[uint64]bool)
+	for _, p := range d.Processes {
+		if len(p.Pages) > 0 {
+			if err := require(PagesName(p.PagesID)); err != nil {
+				return nil, err
+			}
+		}
+		for _, vma := range p.MM.VMAs {
+			if vma.Status&VMAAnonShared == 0 || shmids[vma.Shmid] {
+				continue
+			}
+			shmids[vma.Shmid] = true
+			pagesID, entries, err := ReadPagemap(record, ShmemPagemapName(vma.Shmid))
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					// None of the memory was written.
+					continue
+				}
+				return nil, err
+			}
+			if len(entries) > 0 {
+				if err := require(PagesName(pagesID)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return names, nil
+}
+
+// loadFiles reads regular files from files.img or, for dumps made by older
+// versions of CRIU, reg-files.img.
+func (d *Dump) loadFiles(open Opener) error {
+	entries, err := readImage(open, "files.img")
+	if err == nil {
+		for _, e := range entries {
+			if err := forEachField(e, func(f field) error {
+				if f.num == 3 { // reg
+					rf, err := decodeRegFile(f.b)
+					if err != nil {
+						return err
+					}
+					d.Files[rf.ID] = rf
+				}
+				return nil
+			}); err != nil {
+				return fmt.Errorf("decoding files.img: %w", err)
+			}
+		}
+		return nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	entries, err = readImage(open, "reg-files.img")
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		rf, err := decodeRegFile(e)
+		if err != nil {
+			return fmt.Errorf("decoding reg-files.img: %w", err)
+		}
+		d.Files[rf.ID] = rf
+	}
+	return nil
+}
+
+// load reads the images of p other than pstree.img.
+func (p *Process) load(open Opener) error {
+	core, err := readOneImage(open, fmt.Sprintf("core-%d.img", p.PID))
+	if err != nil {
+		return err
+	}
+	filesID, hasIDs, err := p.decodeCore(core)
+	if err != nil {
+		return fmt.Errorf("decoding core: %w", err)
+	}
+	if !hasIDs {
+		// Older versions of CRIU write IDs to their own image.
+		ids, err := readOneImage(open, fmt.Sprintf("ids-%d.img", p.PID))
+		if err != nil {
+			return err
+		}
+		if filesID, err = decodeIDs(ids); err != nil {
+			return fmt.Errorf("decoding IDs: %w", err)
+		}
+	}
+
+	mm, err := readOneImage(open, fmt.Sprintf("mm-%d.img", p.PID))
+	if err != nil {
+		return err
+	}
+	if err := p.MM.decode(mm); err != nil {
+		return fmt.Errorf("decoding mm: %w", err)
+	}
+
+	if p.PagesID, p.Pages, err = ReadPagemap(open, fmt.Sprintf("pagemap-%d.img", p.PID)); err != nil {
+		return err
+	}
+
+	fdinfo, err := readImage(open, fmt.Sprintf("fdinfo-%d.img", filesID))
+	if err != nil {
+		return err
+	}
+	for _, e := range fdinfo {
+		var fd FD
+		if err := forEachField(e, func(f field) error {
+			switch f.num {
+			case 1:
+				fd.ID = uint32(f.v)
+			case 2:
+				fd.Flags = uint32(f.v)
+			case 3:
+				fd.Type = uint32(f.v)
+			case 4:
+				fd.FD = uint32(f.v)
+			}
+			return nil
+		}); err != nil {
+			return fmt.Errorf("decoding fdinfo: %w", err)
+		}
+		p.FDs = append(p.FDs, fd)
+	}
+
+	fsEntry, err := readOneImage(open, fmt.Sprintf("fs-%d.img", p.PID))
+	switch {
+	case err == nil:
+		p.HasFS = true
+		if err := forEachField(fsEntry, func(f field) error {
+			switch f.num {
+			case 1:
+				p.CwdID = uint32(f.v)
+			case 2:
+				p.RootID = uint32(f.v)
+			case 3:
+				p.Umask = uint32(f.v)
+			}
+			return nil
+		}); err != nil {
+			return fmt.Errorf("decoding fs: %w", err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+
+	sigacts, err := readImage(open, fmt.Sprintf("sigacts-%d.img", p.PID))
+	switch {
+	case err == nil:
+		if err := p.decodeSigActions(sigacts); err != nil {
+			return fmt.Errorf("decoding sigacts: %w", err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+	return nil
+}
+
+// ReadPagemap reads the named pagemap image. It returns the ID of the pages
+// image that holds the contents of the dumped pages, and the entries of the
+// pages in that image.
+func ReadPagemap(open Opener, name string) (uint32, []PagemapEntry, error) {
+	entries, err := readImage(open, name)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(entries) == 0 {
+		return 0, nil, fmt.Errorf("%s has no header", name)
+	}
+	var pagesID uint32
+	if err := forEachField(entries[0], func(f field) error {
+		if f.num == 1 {
+			pagesID = uint32(f.v)
+		}
+		return nil
+	}); err != nil {
+		return 0, nil, fmt.Errorf("decoding %s: %w", name, err)
+	}
+	var pages []PagemapEntry
+	for _, e := range entries[1:] {
+		var (
+			pe       PagemapEntry
+			inParent bool
+			flags    uint32
+			hasFlags bool
+		)
+		if err := forEachField(e, func(f field) error {
+			switch f.num {
+			case 1:
+				pe.Vaddr = f.v
+			case 2:
+				pe.NrPages = uint32(f.v)
+			case 3:
+				inParent = f.v != 0
+			case 4:
+				flags = uint32(f.v)
+				hasFlags = true
+			}
+			return nil
+		}); err != nil {
+			return 0, nil, fmt.Errorf("decoding %s: %w", name, err)
+		}
+		if !hasFlags {
+			// Older versions of CRIU don't write flags.
+			flags = pagemapPresent
+			if inParent {
+				flags = pagemapParent
+			}
+		}
+		switch {
+		case flags&pagemapParent != 0:
+			return 0, nil, fmt.Errorf("%s: incremental dumps are not supported", name)
+		case flags&pagemapLazy != 0:
+			return 0, nil, fmt.Errorf("%s: lazy pages are not supported", name)
+		case flags&pagemapPresent != 0:
+			pages = append(pages, pe)
+		}
+	}
+	return pagesID, pages, nil
+}
+
+func decodePstree(b []byte) (*Process, error) {
+	p := &Process{}
+	err := forEachField(b, func(f field) error {
+		switch f.num {
+		case 1:
+			p.PID = uint32(f.v)
+		case 2:
+			p.PPID = uint32(f.v)
+		case 3:
+			p.PGID = uint32(f.v)
+		case 4:
+			p.SID = uint32(f.v)
+		case 5:
+			vs, err := f.varints()
+			if err != nil {
+				return err
+			}
+			for _, v := range vs {
+				p.Threads = append(p.Threads, uint32(v))
+			}
+		}
+		return nil
+	})
+	return p, err
+}
+
+// decodeCore decodes core_entry. It returns the files ID of the process, if
+// the entry has IDs.
+func (p *Process) decodeCore(b []byte) (filesID uint32, hasIDs bool, err error) {
+	var (
+		arch     uint64
+		hasTC    bool
+		hasRegs  bool
+		state    uint64
+		regsMode uint64
+	)
+	err = forEachField(b, func(f field) error {
+		switch f.num {
+		case 1: // mtype
+			arch = f.v
+		case 2: // thread_info
+			return forEachField(f.b, func(f field) error {
+				switch f.num {
+				case 2: // gpregs
+					hasRegs = true
+					var err error
+					p.Regs, regsMode, err = decodeX86Regs(f.b)
+					return err
+				case 3: // fpregs
+					fp, err := decodeX86FPRegs(f.b)
+					if err != nil {
+						return err
+					}
+					p.FPRegs = fp
+				}
+				return nil
+			})
+		case 3: // tc
+			hasTC = true
+			return forEachField(f.b, func(f field) error {
+				switch f.num {
+				case 1:
+					state = f.v
+				case 5:
+					p.BlockedSignals = f.v
+				case 6:
+					p.Comm = string(f.b)
+				}
+				return nil
+			})
+		case 4: // ids
+			hasIDs = true
+			var err error
+			filesID, err = decodeIDs(f.b)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	if arch != coreArchX86_64 {
+		return 0, false, fmt.Errorf("unsupported architecture %d, only x86-64 is supported", arch)
+	}
+	if !hasTC || !hasRegs {
+		return 0, false, fmt.Errorf("missing task core or registers")
+	}
+	if regsMode == x86RegsModeCompat {
+		return 0, false, fmt.Errorf("32-bit processes are not supported")
+	}
+	if state != taskAlive {
+		return 0, false, fmt.Errorf("task state %d is not supported, only running tasks are", state)
+	}
+	return filesID, hasIDs, nil
+}
+
+// decodeIDs decodes task_kobj_ids_entry, and returns the files ID.
+func decodeIDs(b []byte) (uint32, error) {
+	var filesID uint32
+	err := forEachField(b, func(f field) error {
+		if f.num == 2 {
+			filesID = uint32(f.v)
+		}
+		return nil
+	})
+	return filesID, err
+}
+
+func decodeX86Regs(b []byte) (X86Regs, uint64, error) {
+	var (
+		r    X86Regs
+		mode uint64
+	)
+	fields := []*uint64{
+		&r.R15, &r.R14, &r.R13, &r.R12, &r.Bp, &r.Bx, &r.R11, &r.R10, &r.R9,
+		&r.R8, &r.Ax, &r.Cx, &r.Dx, &r.Si, &r.Di, &r.OrigAx, &r.IP, &r.CS,
+		&r.Flags, &r.SP, &r.SS, &r.FSBase, &r.GSBase, &r.DS, &r.ES, &r.FS,
+		&r.GS,
+	}
+	err := forEachField(b, func(f field) error {
+		if i := int(f.num) - 1; i >= 0 && i < len(fields) {
+			*fields[i] = f.v
+		} else if f.num == 28 {
+			mode = f.v
+		}
+		return nil
+	})
+	return r, mode, err
+}
+
+func decodeX86FPRegs(b []byte) (*X86FPRegs, error) {
+	fp := &X86FPRegs{}
+	err := forEachField(b, func(f field) error {
+		switch f.num {
+		case 1:
+			fp.Cwd = uint32(f.v)
+		case 2:
+			fp.Swd = uint32(f.v)
+		case 3:
+			fp.Twd = uint32(f.v)
+		case 4:
+			fp.Fop = uint32(f.v)
+		case 5:
+			fp.RIP = f.v
+		case 6:
+			fp.RDP = f.v
+		case 7:
+			fp.MXCSR = uint32(f.v)
+		case 8:
+			fp.MXCSRMask = uint32(f.v)
+		case 9, 10:
+			vs, err := f.varints()
+			if err != nil {
+				return err
+			}
+			for _, v := range vs {
+				if f.num == 9 {
+					fp.STSpace = append(fp.STSpace, uint32(v))
+				} else {
+					fp.XMMSpace = append(fp.XMMSpace, uint32(v))
+				}
+			}
+		case 13:
+			xsave, err := decodeX86Xsave(f.b)
+			if err != nil {
+				return err
+			}
+			fp.Xsave = xsave
+		}
+		return nil
+	})
+	return fp, err
+}
+
+func decodeX86Xsave(b []byte) (*X86Xsave, error) {
+	xsave := &X86Xsave{}
+	err := forEachField(b, func(f field) error {
+		switch f.num {
+		case 1:
+			xsave.XstateBV = f.v
+		default:
+			vs, err := f.varints()
+			if err != nil {
+				return err
+			}
+			if f.num == 2 {
+				for _, v := range vs {
+					xsave.YMMHSpace = append(xsave.YMMHSpace, uint32(v))
+				}
+				return nil
+			}
+			if xsave.Other == nil {
+				xsave.Other = make(map[int][]uint64)
+			}
+			xsave.Other[int(f.num)] = append(xsave.Other[int(f.num)], vs...)
+		}
+		return nil
+	})
+	return xsave, err
+}
+
+func (mm *MM) decode(b []byte) error {
+	fields := []*uint64{
+		&mm.StartCode, &mm.EndCode, &mm.StartData, &mm.EndData,
+		&mm.StartStack, &mm.StartBrk, &mm.Brk, &mm.ArgStart, &mm.ArgEnd,
+		&mm.EnvStart, &mm.EnvEnd,
+	}
+	return forEachField(b, func(f field) error {
+		switch {
+		case int(f.num) <= len(fields):
+			*fields[f.num-1] = f.v
+		case f.num == 12:
+			mm.ExeFileID = uint32(f.v)
+		case f.num == 13:
+			vs, err := f.varints()
+			if err != nil {
+				return err
+			}
+			mm.Auxv = append(mm.Auxv, vs...)
+		case f.num == 14:
+			var vma VMA
+			if err := forEachField(f.b, func(f field) error {
+				switch f.num {
+				case 1:
+					vma.Start = f.v
+				case 2:
+					vma.End = f.v
+				case 3:
+					vma.Pgoff = f.v
+				case 4:
+					vma.Shmid = f.v
+				case 5:
+					vma.Prot = uint32(f.v)
+				case 6:
+					vma.Flags = uint32(f.v)
+				case 7:
+					vma.Status = uint32(f.v)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			mm.VMAs = append(mm.VMAs, vma)
+		case f.num == 16:
+			return fmt.Errorf("AIO rings are not supported")
+		}
+		return nil
+	})
+}
+
+func decodeRegFile(b []byte) (*RegFile, error) {
+	rf := &RegFile{}
+	err := forEachField(b, func(f field) error {
+		switch f.num {
+		case 1:
+			rf.ID = uint32(f.v)
+		case 2:
+			rf.Flags = uint32(f.v)
+		case 3:
+			rf.Pos = f.v
+		case 6:
+			rf.Name = string(f.b)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Names are relative to the root of the mount namespace.
+	if !strings.HasPrefix(rf.Name, "/") {
+		rf.Name = "/" + rf.Name
+	}
+	return rf, nil
+}
+
+// sigKill and sigStop are the signals whose actions aren't dumped.
+const (
+	sigKill = 9
+	sigStop = 19
+	sigMax  = 64
+)
+
+// decodeSigActions decodes the entries of sigacts-<pid>.img, which are the
+// actions of every signal except SIGKILL and SIGSTOP, in order.
+func (p *Process) decodeSigActions(entries [][]byte) error {
+	p.SigActions = make(map[int]SigAction)
+	sig := 1
+	for _, e := range entries {
+		for sig == sigKill || sig == sigStop {
+			sig++
+		}
+		if sig > sigMax {
+			return fmt.Errorf("too many signal actions")
+		}
+		var sa SigAction
+		if err := forEachField(e, func(f field) error {
+			switch f.num {
+			case 1:
+				sa.Handler = f.v
+			case 2:
+				sa.Flags = f.v
+			case 3:
+				sa.Restorer = f.v
+			case 4:
+				sa.Mask = f.v
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		p.SigActions[sig] = sa
+		sig++
+	}
+	return nil
+}
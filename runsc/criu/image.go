@@ -0,0 +1,133 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package criu reads the images written by "criu dump".
+//
+// Only the subset of the image format needed to import simple processes into
+// gVisor is supported: the process tree, registers, memory mappings and
+// contents, and open regular files. Messages are decoded directly from the
+// protobuf wire format, by field number, so that the CRIU protobuf
+// definitions aren't needed. Field numbers are those of the .proto files in
+// CRIU's images/ directory.
+package criu
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Magic numbers that start every protobuf image. The second 32-bit word of an
+// image identifies its type, which isn't checked since the file name already
+// does.
+const (
+	imgCommonMagic  = 0x54564319
+	imgServiceMagic = 0x55105940
+)
+
+// maxEntrySize is the maximum size of an image entry that is accepted.
+const maxEntrySize = 64 << 20
+
+// readEntries reads the protobuf entries of the image in r.
+func readEntries(r io.Reader) ([][]byte, error) {
+	var magic [2]uint32
+	if err := binary.Read(r, binary.LittleEndian, &magic); err != nil {
+		return nil, fmt.Errorf("reading image magic: %w", err)
+	}
+	if magic[0] != imgCommonMagic && magic[0] != imgServiceMagic {
+		return nil, fmt.Errorf("bad image magic %#x", magic[0])
+	}
+	var entries [][]byte
+	for {
+		var size uint32
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			if errors.Is(err, io.EOF) {
+				return entries, nil
+			}
+			return nil, fmt.Errorf("reading entry size: %w", err)
+		}
+		if size > maxEntrySize {
+			return nil, fmt.Errorf("entry of %d bytes is too large", size)
+		}
+		entry := make([]byte, size)
+		if _, err := io.ReadFull(r, entry); err != nil {
+			return nil, fmt.Errorf("reading entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// field is a decoded protobuf field. Varint and fixed-size fields are stored
+// in v, length-delimited fields in b.
+type field struct {
+	num protowire.Number
+	typ protowire.Type
+	v   uint64
+	b   []byte
+}
+
+// forEachField calls fn for every field of the protobuf message in b.
+func forEachField(b []byte, fn func(f field) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		f := field{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.v, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			f.v = uint64(v)
+		case protowire.Fixed64Type:
+			f.v, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			f.b, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return fmt.Errorf("field %d: %w", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// varints returns the values of a repeated varint field, which may be packed
+// or not.
+func (f field) varints() ([]uint64, error) {
+	if f.typ != protowire.BytesType {
+		return []uint64{f.v}, nil
+	}
+	var vs []uint64
+	for b := f.b; len(b) > 0; {
+		v, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		vs = append(vs, v)
+		b = b[n:]
+	}
+	return vs, nil
+}
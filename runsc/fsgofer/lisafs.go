@@ -103,10 +103,27 @@ func NewConnectionImpl(config *Config) lisafs.ConnectionImpl {
 	return &connectionImpl{config: config}
 }
 
+// NewConnectionImplWithQuota returns a new lisafs.ConnectionImpl for fsgofer
+// that fails writes with EDQUOT once the connection has added limit bytes to
+// the files of its mount.
+//
+// Host FDs that allow writes are never donated to the client, so that all
+// writes go through the gofer.
+func NewConnectionImplWithQuota(config *Config, limit uint64) lisafs.ConnectionImpl {
+	return &connectionImpl{
+		config: config,
+		quota:  &quota{limit: limit},
+	}
+}
+
 // connectionImpl implements lisafs.ConnectionImpl for fsgofer.
 type connectionImpl struct {
 	// config is the global configuration for the gofer.
 	config *Config
+
+	// quota limits the data written through this connection. It is nil if
+	// there is no limit. quota is immutable.
+	quota *quota
 }
 
 var _ lisafs.ConnectionImpl = (*connectionImpl)(nil)
@@ -136,7 +153,7 @@ func (i *connectionImpl) Mount(c *lisafs.Connection, mountNode *lisafs.Node) (*l
 	}
 
 	clientHostFD := -1
-	if i.config.DonateMountPointFD {
+	if i.config.DonateMountPointFD && i.quota == nil {
 		clientHostFD, err = unix.Dup(rootHostFD)
 		if err != nil {
 			return nil, lisafs.Statx{}, -1, err
@@ -337,7 +354,7 @@ func (fd *controlFDLisa) SetStat(stat lisafs.SetStatReq) (failureMask uint32, fa
 		// ftruncate(2) requires the FD to be open for writing.
 		writableFD, err := fd.getWritableFD()
 		if err == nil {
-			err = unix.Ftruncate(writableFD, int64(stat.Size))
+			err = fd.truncate(writableFD, int64(stat.Size))
 		}
 		if err != nil {
 			log.Warningf("SetStat ftruncate failed %q, err: %v", fd.Node().FilePath(), err)
@@ -416,6 +433,29 @@ func (fd *controlFDLisa) SetStat(stat lisafs.SetStatReq) (failureMask uint32, fa
 	return
 }
 
+// truncate truncates the file open for writing as writableFD to size, and
+// charges the change to the connection's quota.
+func (fd *controlFDLisa) truncate(writableFD int, size int64) error {
+	q := fd.quota()
+	if q == nil {
+		return unix.Ftruncate(writableFD, size)
+	}
+	before, err := fileSize(writableFD)
+	if err != nil {
+		return err
+	}
+	reserved := growth(before, uint64(size))
+	if err := q.reserve(reserved); err != nil {
+		return err
+	}
+	if err := unix.Ftruncate(writableFD, size); err != nil {
+		q.release(reserved)
+		return err
+	}
+	q.settle(reserved, before, size)
+	return nil
+}
+
 // Walk implements lisafs.ControlFDImpl.Walk.
 func (fd *controlFDLisa) Walk(name string) (*lisafs.ControlFD, lisafs.Statx, error) {
 	childHostFD, err := tryOpen(func(flags int) (int, error) {
@@ -529,15 +569,28 @@ func (fd *controlFDLisa) Open(flags uint32) (*lisafs.OpenFD, int, error) {
 			return nil, -1, unix.EPERM
 		}
 	}
+	var truncated int64
+	if impl.quota != nil && ftype == unix.S_IFREG && flags&unix.O_TRUNC != 0 {
+		size, err := fileSize(fd.hostFD)
+		if err != nil {
+			return nil, -1, err
+		}
+		truncated = size
+	}
 	flags |= openFlags
 	openHostFD, err := unix.Openat(int(procSelfFD.FD()), strconv.Itoa(fd.hostFD), int(flags)&^unix.O_NOFOLLOW, 0)
 	if err != nil {
 		return nil, -1, err
 	}
+	impl.quota.release(uint64(truncated))
 
 	hostFDToDonate := -1
 	switch {
 	case ftype == unix.S_IFREG:
+		// Writes must go through the gofer to be charged to the quota.
+		if impl.quota != nil && flags&unix.O_ACCMODE != unix.O_RDONLY {
+			break
+		}
 		// Best effort to donate file to the Sentry (for performance only).
 		hostFDToDonate, _ = unix.Dup(openHostFD)
 
@@ -604,9 +657,14 @@ func (fd *controlFDLisa) OpenCreate(mode linux.FileMode, uid lisafs.UID, gid lis
 	// Since FD donation is a destructive operation, we should duplicate the
 	// to-be-donated FD. Eat the error if one occurs, it is better to have an FD
 	// without a host FD, than failing the Open attempt.
+	//
+	// Writable FDs are not donated if there is a quota, since writes must go
+	// through the gofer to be charged to it.
 	hostOpenFD := -1
-	if dupFD, err := unix.Dup(newFD.hostFD); err == nil {
-		hostOpenFD = dupFD
+	if fd.quota() == nil || flags&unix.O_ACCMODE == unix.O_RDONLY {
+		if dupFD, err := unix.Dup(newFD.hostFD); err == nil {
+			hostOpenFD = dupFD
+		}
 	}
 
 	return childFD.FD(), childStat, newFD.FD(), hostOpenFD, nil
@@ -1001,17 +1059,48 @@ func (fd *controlFDLisa) BindAt(name string, sockType uint32, mode linux.FileMod
 
 // Unlink implements lisafs.ControlFDImpl.Unlink.
 func (fd *controlFDLisa) Unlink(name string, flags uint32) error {
-	return unix.Unlinkat(fd.hostFD, name, int(flags))
+	q := fd.quota()
+	var freed uint64
+	if q != nil && flags&unix.AT_REMOVEDIR == 0 {
+		freed = removedSize(fd.hostFD, name)
+	}
+	if err := unix.Unlinkat(fd.hostFD, name, int(flags)); err != nil {
+		return err
+	}
+	q.release(freed)
+	return nil
 }
 
 // RenameAt implements lisafs.ControlFDImpl.RenameAt.
 func (fd *controlFDLisa) RenameAt(oldName string, newDir lisafs.ControlFDImpl, newName string) error {
-	return fsutil.RenameAt(fd.hostFD, oldName, newDir.(*controlFDLisa).hostFD, newName)
+	newDirFD := newDir.(*controlFDLisa).hostFD
+	q := fd.quota()
+	var freed uint64
+	if q != nil {
+		// A regular file at newName is replaced.
+		freed = removedSize(newDirFD, newName)
+	}
+	if err := fsutil.RenameAt(fd.hostFD, oldName, newDirFD, newName); err != nil {
+		return err
+	}
+	q.release(freed)
+	return nil
 }
 
 // RenameAt2 implements lisafs.ControlFDImpl.RenameAt2.
 func (fd *controlFDLisa) RenameAt2(oldName string, newDir lisafs.ControlFDImpl, newName string, flags uint32) error {
-	return fsutil.RenameAt2(fd.hostFD, oldName, newDir.(*controlFDLisa).hostFD, newName, flags)
+	newDirFD := newDir.(*controlFDLisa).hostFD
+	q := fd.quota()
+	var freed uint64
+	if q != nil && flags&(unix.RENAME_EXCHANGE|unix.RENAME_NOREPLACE) == 0 {
+		// A regular file at newName is replaced.
+		freed = removedSize(newDirFD, newName)
+	}
+	if err := fsutil.RenameAt2(fd.hostFD, oldName, newDirFD, newName, flags); err != nil {
+		return err
+	}
+	q.release(freed)
+	return nil
 }
 
 // Renamed implements lisafs.ControlFDImpl.Renamed.
@@ -1148,8 +1237,27 @@ func (fd *openFDLisa) Sync() error {
 
 // Write implements lisafs.OpenFDImpl.Write.
 func (fd *openFDLisa) Write(buf []byte, off uint64) (uint64, error) {
+	q := fd.quota()
+	var (
+		size     int64
+		reserved uint64
+	)
+	if q != nil {
+		var err error
+		if size, err = fileSize(fd.hostFD); err != nil {
+			return 0, err
+		}
+		reserved = growth(size, off+uint64(len(buf)))
+		if err := q.reserve(reserved); err != nil {
+			return 0, err
+		}
+	}
 	rw := rwfd.NewReadWriter(fd.hostFD)
 	n, err := rw.WriteAt(buf, int64(off))
+	if q != nil {
+		// Release the reservation for bytes that were not written.
+		q.release(reserved - growth(size, off+uint64(n)))
+	}
 	return uint64(n), err
 }
 
@@ -1165,7 +1273,42 @@ func (fd *openFDLisa) Read(buf []byte, off uint64) (uint64, error) {
 
 // Allocate implements lisafs.OpenFDImpl.Allocate.
 func (fd *openFDLisa) Allocate(mode, off, length uint64) error {
-	return unix.Fallocate(fd.hostFD, uint32(mode), int64(off), int64(length))
+	q := fd.quota()
+	if q == nil {
+		return unix.Fallocate(fd.hostFD, uint32(mode), int64(off), int64(length))
+	}
+	if mode&unix.FALLOC_FL_KEEP_SIZE != 0 && mode&unix.FALLOC_FL_PUNCH_HOLE == 0 {
+		// Quotas are measured in file sizes, so space allocated beyond the end
+		// of the file can't be accounted for.
+		return unix.EOPNOTSUPP
+	}
+	before, err := fileSize(fd.hostFD)
+	if err != nil {
+		return err
+	}
+	var reserved uint64
+	switch {
+	case mode&unix.FALLOC_FL_KEEP_SIZE != 0:
+		// The file size doesn't change.
+	case mode&unix.FALLOC_FL_INSERT_RANGE != 0:
+		reserved = length
+	default:
+		reserved = growth(before, off+length)
+	}
+	if err := q.reserve(reserved); err != nil {
+		return err
+	}
+	if err := unix.Fallocate(fd.hostFD, uint32(mode), int64(off), int64(length)); err != nil {
+		q.release(reserved)
+		return err
+	}
+	after, err := fileSize(fd.hostFD)
+	if err != nil {
+		// Keep the reservation, since the actual change is unknown.
+		return nil
+	}
+	q.settle(reserved, before, after)
+	return nil
 }
 
 // Flush implements lisafs.OpenFDImpl.Flush.
@@ -0,0 +1,133 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fsgofer
+
+import (
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/atomicbitops"
+)
+
+// quota limits the number of bytes that a connection may add to the files of
+// its mount.
+//
+// Usage is measured in file sizes: growing a file through the connection
+// charges the growth to it, and shrinking or removing a file through the
+// connection releases the difference, never below zero. Changes made
+// through other connections, e.g. by other containers sharing the same host
+// directory, are not charged to this one.
+type quota struct {
+	// limit is the maximum value of used. It is immutable.
+	limit uint64
+
+	// used is the number of bytes currently charged to the connection.
+	used atomicbitops.Uint64
+}
+
+// reserve charges n bytes to q before they are written. It fails with
+// EDQUOT if that would exceed the limit. q may be nil, in which case reserve
+// always succeeds.
+func (q *quota) reserve(n uint64) error {
+	if q == nil || n == 0 {
+		return nil
+	}
+	for {
+		used := q.used.Load()
+		if n > q.limit-used {
+			return unix.EDQUOT
+		}
+		if q.used.CompareAndSwap(used, used+n) {
+			return nil
+		}
+	}
+}
+
+// release releases up to n bytes charged to q.
+func (q *quota) release(n uint64) {
+	if q == nil || n == 0 {
+		return
+	}
+	for {
+		used := q.used.Load()
+		if q.used.CompareAndSwap(used, used-min(used, n)) {
+			return
+		}
+	}
+}
+
+// settle accounts for a file size change from before to after, for which
+// reserved bytes were charged in advance.
+func (q *quota) settle(reserved uint64, before, after int64) {
+	if q == nil {
+		return
+	}
+	if after <= before {
+		q.release(reserved + uint64(before-after))
+		return
+	}
+	if grown := uint64(after - before); grown <= reserved {
+		q.release(reserved - grown)
+	} else {
+		// The file grew more than expected, e.g. because of a concurrent
+		// write. The data is already on disk, so charge it even if that
+		// exceeds the limit: later writes will fail until usage goes down.
+		q.used.Add(grown - reserved)
+	}
+}
+
+// growth returns by how many bytes a file of the given size grows if it is
+// extended to end.
+func growth(size int64, end uint64) uint64 {
+	if end <= uint64(size) {
+		return 0
+	}
+	return end - uint64(size)
+}
+
+// fileSize returns the size of the file referred to by hostFD.
+func fileSize(hostFD int) (int64, error) {
+	// fstat(2) is not allowed by the gofer's seccomp filters, use
+	// fstatat(2) instead.
+	var st unix.Stat_t
+	if err := unix.Fstatat(hostFD, "", &st, unix.AT_EMPTY_PATH); err != nil {
+		return 0, err
+	}
+	return st.Size, nil
+}
+
+// removedSize returns the number of bytes freed if name in dirFD is removed
+// or replaced, which is only the case for regular files that have no other
+// links.
+func removedSize(dirFD int, name string) uint64 {
+	var st unix.Stat_t
+	if err := unix.Fstatat(dirFD, name, &st, unix.AT_SYMLINK_NOFOLLOW); err != nil {
+		return 0
+	}
+	if st.Mode&unix.S_IFMT != unix.S_IFREG || st.Nlink != 1 {
+		return 0
+	}
+	return uint64(st.Size)
+}
+
+// quota returns the quota of the connection that fd belongs to, or nil if
+// there is none.
+func (fd *controlFDLisa) quota() *quota {
+	return fd.Conn().Impl().(*connectionImpl).quota
+}
+
+// quota returns the quota of the connection that fd belongs to, or nil if
+// there is none.
+func (fd *openFDLisa) quota() *quota {
+	return fd.ControlFD().(*controlFDLisa).quota()
+}
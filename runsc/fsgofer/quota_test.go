@@ -0,0 +1,106 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fsgofer
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestQuota(t *testing.T) {
+	q := &quota{limit: 100}
+	if err := q.reserve(60); err != nil {
+		t.Fatalf("reserve(60): %v", err)
+	}
+	if err := q.reserve(50); !errors.Is(err, unix.EDQUOT) {
+		t.Fatalf("reserve(50) over the limit got error %v, want EDQUOT", err)
+	}
+	if err := q.reserve(40); err != nil {
+		t.Fatalf("reserve(40): %v", err)
+	}
+	q.release(30)
+	if got, want := q.used.Load(), uint64(70); got != want {
+		t.Errorf("used = %d, want %d", got, want)
+	}
+	// Releases never take usage below zero.
+	q.release(1000)
+	if got := q.used.Load(); got != 0 {
+		t.Errorf("used = %d, want 0", got)
+	}
+}
+
+func TestQuotaSettle(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		used     uint64
+		reserved uint64
+		before   int64
+		after    int64
+		want     uint64
+	}{
+		{
+			name:     "grew as reserved",
+			used:     10,
+			reserved: 10,
+			before:   0,
+			after:    10,
+			want:     10,
+		},
+		{
+			name:     "grew less than reserved",
+			used:     10,
+			reserved: 10,
+			before:   0,
+			after:    4,
+			want:     4,
+		},
+		{
+			name:     "grew more than reserved",
+			used:     10,
+			reserved: 10,
+			before:   0,
+			after:    15,
+			want:     15,
+		},
+		{
+			name:     "shrank",
+			used:     20,
+			reserved: 0,
+			before:   15,
+			after:    5,
+			want:     10,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			q := &quota{limit: 100}
+			q.used.Store(tc.used)
+			q.settle(tc.reserved, tc.before, tc.after)
+			if got := q.used.Load(); got != tc.want {
+				t.Errorf("used = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNilQuota(t *testing.T) {
+	var q *quota
+	if err := q.reserve(1 << 40); err != nil {
+		t.Errorf("reserve on nil quota: %v", err)
+	}
+	q.release(1)
+	q.settle(1, 0, 2)
+}
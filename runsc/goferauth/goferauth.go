@@ -0,0 +1,258 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package goferauth implements mutual authentication between the sentry and
+// a gofer over a lisafs socket, before any lisafs message is exchanged.
+//
+// Both sides share a random key that is generated by the runsc process
+// that creates the gofer and donated to the gofer and the sandbox through
+// inherited FDs. The key never crosses the lisafs socket. Each side proves
+// knowledge of the key with an HMAC over fresh nonces from both sides and
+// the identity of the connection (the container ID and the mount served by
+// the gofer), so a process that obtains a leaked socket FD cannot
+// impersonate either side, and a valid exchange cannot be replayed.
+//
+// The handshake is:
+//
+//	sentry -> gofer: nonceS, cid
+//	gofer -> sentry: nonceG, mount, HMAC(key, "gofer", nonceS, nonceG, cid, mount)
+//	sentry -> gofer: HMAC(key, "sentry", nonceS, nonceG, cid, mount)
+//	gofer -> sentry: ack
+//
+// Variable-length fields are prefixed with their length as a 32-bit
+// little-endian integer.
+package goferauth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// KeySize is the size of authentication keys in bytes.
+	KeySize = 32
+
+	// nonceSize is the size of the nonces exchanged by both sides.
+	nonceSize = 32
+
+	// maxFieldLen is the maximum length of the variable-length fields
+	// exchanged during the handshake.
+	maxFieldLen = 4096
+
+	// ack is sent by the gofer once it has authenticated the sentry.
+	ack = byte(1)
+
+	goferLabel  = "gofer"
+	sentryLabel = "sentry"
+)
+
+// Key is a key shared by the sentry and a gofer.
+type Key []byte
+
+// String implements fmt.Stringer. It keeps keys out of logs, since structures
+// holding them are commonly logged with %+v.
+func (Key) String() string {
+	return "<redacted>"
+}
+
+// NewKey returns a new random authentication key.
+func NewKey() (Key, error) {
+	key := make(Key, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generating gofer authentication key: %w", err)
+	}
+	return key, nil
+}
+
+// NewKeyFile returns a file from which key can be read exactly once. The file
+// is meant to be donated to a child process, which reads it with ReadKey.
+func NewKeyFile(key Key) (*os.File, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer w.Close()
+	// KeySize is much smaller than the pipe buffer, so this doesn't block.
+	if _, err := w.Write(key); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// ReadKey reads an authentication key from fd and closes it.
+//
+// The FD is read with raw syscalls rather than through an os.File, because
+// the pipe is non-blocking and os.File would register it with the runtime
+// poller, which may be disallowed by seccomp filters.
+func ReadKey(fd int) (Key, error) {
+	defer unix.Close(fd)
+	key := make(Key, KeySize)
+	for read := 0; read < KeySize; {
+		n, err := unix.Read(fd, key[read:])
+		if err != nil {
+			return nil, fmt.Errorf("reading gofer authentication key: %w", err)
+		}
+		if n == 0 {
+			return nil, fmt.Errorf("reading gofer authentication key: %w", io.ErrUnexpectedEOF)
+		}
+		read += n
+	}
+	return key, nil
+}
+
+// Authenticate runs the sentry side of the handshake on conn. cid is the ID
+// of the container that the connection is expected to belong to. It returns
+// the mount point that the authenticated gofer serves on conn.
+func Authenticate(conn io.ReadWriter, key Key, cid string) (string, error) {
+	nonceS, err := newNonce()
+	if err != nil {
+		return "", err
+	}
+	if err := writeAll(conn, nonceS, field(cid)); err != nil {
+		return "", fmt.Errorf("sending challenge: %w", err)
+	}
+
+	nonceG := make([]byte, nonceSize)
+	if _, err := io.ReadFull(conn, nonceG); err != nil {
+		return "", fmt.Errorf("reading gofer challenge: %w", err)
+	}
+	mount, err := readField(conn)
+	if err != nil {
+		return "", fmt.Errorf("reading gofer mount: %w", err)
+	}
+	mac := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(conn, mac); err != nil {
+		return "", fmt.Errorf("reading gofer response: %w", err)
+	}
+	if !hmac.Equal(mac, sum(key, goferLabel, nonceS, nonceG, cid, mount)) {
+		return "", fmt.Errorf("gofer for container %q failed to authenticate", cid)
+	}
+
+	if err := writeAll(conn, sum(key, sentryLabel, nonceS, nonceG, cid, mount)); err != nil {
+		return "", fmt.Errorf("sending response: %w", err)
+	}
+	var b [1]byte
+	if _, err := io.ReadFull(conn, b[:]); err != nil {
+		return "", fmt.Errorf("gofer for container %q rejected the sandbox: %w", cid, err)
+	}
+	if b[0] != ack {
+		return "", fmt.Errorf("gofer for container %q rejected the sandbox: unexpected reply %d", cid, b[0])
+	}
+	return mount, nil
+}
+
+// Serve runs the gofer side of the handshake on conn. cid is the ID of the
+// container that the gofer serves and mount is the mount point served on
+// conn. The connection must not be used if Serve fails.
+func Serve(conn io.ReadWriter, key Key, cid, mount string) error {
+	nonceS := make([]byte, nonceSize)
+	if _, err := io.ReadFull(conn, nonceS); err != nil {
+		return fmt.Errorf("reading sandbox challenge: %w", err)
+	}
+	peerCID, err := readField(conn)
+	if err != nil {
+		return fmt.Errorf("reading sandbox container ID: %w", err)
+	}
+	if peerCID != cid {
+		return fmt.Errorf("connection is for container %q, but the gofer serves container %q", peerCID, cid)
+	}
+
+	nonceG, err := newNonce()
+	if err != nil {
+		return err
+	}
+	if err := writeAll(conn, nonceG, field(mount), sum(key, goferLabel, nonceS, nonceG, cid, mount)); err != nil {
+		return fmt.Errorf("sending response: %w", err)
+	}
+
+	mac := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(conn, mac); err != nil {
+		return fmt.Errorf("reading sandbox response: %w", err)
+	}
+	if !hmac.Equal(mac, sum(key, sentryLabel, nonceS, nonceG, cid, mount)) {
+		return fmt.Errorf("sandbox failed to authenticate for container %q", cid)
+	}
+	if err := writeAll(conn, []byte{ack}); err != nil {
+		return fmt.Errorf("sending ack: %w", err)
+	}
+	return nil
+}
+
+func newNonce() ([]byte, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+	return nonce, nil
+}
+
+// sum returns the HMAC of the given handshake parameters. The label binds the
+// MAC to the side that produced it, so that one side's response cannot be
+// reflected back as the other's.
+func sum(key Key, label string, nonceS, nonceG []byte, cid, mount string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(field(label))
+	h.Write(nonceS)
+	h.Write(nonceG)
+	h.Write(field(cid))
+	h.Write(field(mount))
+	return h.Sum(nil)
+}
+
+// field returns s encoded as a length-prefixed field.
+func field(s string) []byte {
+	b := binary.LittleEndian.AppendUint32(make([]byte, 0, 4+len(s)), uint32(len(s)))
+	return append(b, s...)
+}
+
+// readField reads a length-prefixed field from r.
+func readField(r io.Reader) (string, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return "", err
+	}
+	n := binary.LittleEndian.Uint32(hdr[:])
+	if n > maxFieldLen {
+		return "", fmt.Errorf("field too long: %d bytes", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// writeAll writes the concatenation of bufs to w.
+func writeAll(w io.Writer, bufs ...[]byte) error {
+	var msg []byte
+	for _, b := range bufs {
+		msg = append(msg, b...)
+	}
+	for len(msg) > 0 {
+		n, err := w.Write(msg)
+		if err != nil {
+			return err
+		}
+		msg = msg[n:]
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goferauth
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/unet"
+)
+
+func handshake(t *testing.T, sentryKey, goferKey Key, sentryCID, goferCID string) (string, error, error) {
+	t.Helper()
+	sentry, gofer, err := unet.SocketPair(false)
+	if err != nil {
+		t.Fatalf("SocketPair: %v", err)
+	}
+	defer gofer.Close()
+
+	goferErr := make(chan error, 1)
+	go func() {
+		err := Serve(gofer, goferKey, goferCID, "/mnt")
+		if err != nil {
+			// Unblock the sentry side, as a gofer would by exiting.
+			gofer.Shutdown()
+		}
+		goferErr <- err
+	}()
+	mount, sentryErr := Authenticate(sentry, sentryKey, sentryCID)
+	// Unblock the gofer side if the sentry gave up.
+	sentry.Close()
+	return mount, sentryErr, <-goferErr
+}
+
+func mustKey(t *testing.T) Key {
+	t.Helper()
+	key, err := NewKey()
+	if err != nil {
+		t.Fatalf("NewKey: %v", err)
+	}
+	return key
+}
+
+func TestHandshake(t *testing.T) {
+	key := mustKey(t)
+	mount, sentryErr, goferErr := handshake(t, key, key, "foo", "foo")
+	if sentryErr != nil {
+		t.Errorf("Authenticate: %v", sentryErr)
+	}
+	if goferErr != nil {
+		t.Errorf("Serve: %v", goferErr)
+	}
+	if mount != "/mnt" {
+		t.Errorf("Authenticate returned mount %q, want %q", mount, "/mnt")
+	}
+}
+
+func TestHandshakeWrongKey(t *testing.T) {
+	_, sentryErr, goferErr := handshake(t, mustKey(t), mustKey(t), "foo", "foo")
+	if sentryErr == nil {
+		t.Errorf("Authenticate succeeded with the wrong key")
+	}
+	if goferErr == nil {
+		t.Errorf("Serve succeeded with the wrong key")
+	}
+}
+
+func TestHandshakeWrongContainer(t *testing.T) {
+	key := mustKey(t)
+	_, sentryErr, goferErr := handshake(t, key, key, "foo", "bar")
+	if sentryErr == nil {
+		t.Errorf("Authenticate succeeded for the wrong container")
+	}
+	if goferErr == nil {
+		t.Errorf("Serve succeeded for the wrong container")
+	}
+}
+
+func TestKeyFile(t *testing.T) {
+	key := mustKey(t)
+	f, err := NewKeyFile(key)
+	if err != nil {
+		t.Fatalf("NewKeyFile: %v", err)
+	}
+	// ReadKey takes ownership of the FD.
+	fd, err := unix.Dup(int(f.Fd()))
+	f.Close()
+	if err != nil {
+		t.Fatalf("dup: %v", err)
+	}
+	got, err := ReadKey(fd)
+	if err != nil {
+		t.Fatalf("ReadKey: %v", err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Errorf("ReadKey returned %x, want %x", []byte(got), []byte(key))
+	}
+}
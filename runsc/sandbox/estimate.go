@@ -0,0 +1,176 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sandbox
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/runsc/cgroup"
+	"gvisor.dev/gvisor/runsc/config"
+)
+
+// The following are approximate costs of running a sandbox, used to estimate
+// its overhead before it is started. They are rounded up from measurements of
+// typical workloads and are meant for bin-packing, not for accounting.
+const (
+	// sentryBaselineMemoryBytes is the memory used by an idle sentry: its
+	// binary, Go runtime and kernel data structures.
+	sentryBaselineMemoryBytes = 40 << 20
+
+	// goferBaselineMemoryBytes is the memory used by an idle gofer.
+	goferBaselineMemoryBytes = 8 << 20
+
+	// perFDMemoryBytes is the sentry memory used by an open file
+	// description, including its dentry and inode.
+	perFDMemoryBytes = 1 << 10
+
+	// perSocketMemoryBytes is the sentry memory used by an idle TCP socket
+	// in netstack, excluding its send and receive buffers.
+	perSocketMemoryBytes = 8 << 10
+
+	// defaultPerVCPUMemoryBytes is used for platforms not listed in
+	// perVCPUMemoryBytes.
+	defaultPerVCPUMemoryBytes = 1 << 20
+)
+
+// perVCPUMemoryBytes is the memory used by each vCPU of a platform: its
+// stub threads and shared regions for systrap and ptrace, and its vCPU
+// state and page tables for KVM.
+var perVCPUMemoryBytes = map[string]uint64{
+	"kvm":     4 << 20,
+	"ptrace":  256 << 10,
+	"systrap": 512 << 10,
+}
+
+// OverheadEstimate is the expected resource overhead of running a container
+// in a new sandbox, on top of the resources used by the application itself.
+type OverheadEstimate struct {
+	// Platform is the platform the estimate is for.
+	Platform string `json:"platform"`
+
+	// CPUs is the number of vCPUs the sandbox would have.
+	CPUs int `json:"cpus"`
+
+	// MaxFDsPerProcess is the RLIMIT_NOFILE of the container process.
+	MaxFDsPerProcess uint64 `json:"max_fds_per_process"`
+
+	// BaselineMemoryBytes is the memory used by the sentry and gofer before
+	// the application runs.
+	BaselineMemoryBytes uint64 `json:"baseline_memory_bytes"`
+
+	// PerVCPUMemoryBytes is the platform memory used by each vCPU.
+	PerVCPUMemoryBytes uint64 `json:"per_vcpu_memory_bytes"`
+
+	// PerFDMemoryBytes is the sentry memory used by each open file.
+	PerFDMemoryBytes uint64 `json:"per_fd_memory_bytes"`
+
+	// PerSocketMemoryBytes is the sentry memory used by each socket, excluding
+	// its buffers.
+	PerSocketMemoryBytes uint64 `json:"per_socket_memory_bytes"`
+
+	// FixedMemoryBytes is the memory overhead that doesn't depend on the
+	// application: the baseline and the memory of all vCPUs.
+	FixedMemoryBytes uint64 `json:"fixed_memory_bytes"`
+
+	// MemoryLimitBytes is the memory limit of the container in its spec, or
+	// zero if it has none.
+	MemoryLimitBytes uint64 `json:"memory_limit_bytes,omitempty"`
+
+	// RequiredMemoryBytes is the memory to reserve for the sandbox: the
+	// container's memory limit plus the fixed overhead. It is zero if the
+	// container has no memory limit.
+	RequiredMemoryBytes uint64 `json:"required_memory_bytes,omitempty"`
+
+	// RootfsOverlayInMemory is true if writes to the root filesystem are
+	// stored in memory, counting towards the memory limit of the sandbox.
+	RootfsOverlayInMemory bool `json:"rootfs_overlay_in_memory"`
+}
+
+// EstimateOverhead returns the expected overhead of running a container with
+// the given spec in a new sandbox. Like New, it selects the platform that the
+// sandbox would run on; see SelectPlatform.
+func EstimateOverhead(conf *config.Config, spec *specs.Spec) (*OverheadEstimate, error) {
+	cpus, err := specCPUs(conf, spec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := SelectPlatform(conf); err != nil {
+		return nil, err
+	}
+	perVCPU, ok := perVCPUMemoryBytes[conf.Platform]
+	if !ok {
+		perVCPU = defaultPerVCPUMemoryBytes
+	}
+	est := &OverheadEstimate{
+		Platform:             conf.Platform,
+		CPUs:                 cpus,
+		MaxFDsPerProcess:     specNOFILE(spec),
+		BaselineMemoryBytes:  sentryBaselineMemoryBytes + goferBaselineMemoryBytes,
+		PerVCPUMemoryBytes:   perVCPU,
+		PerFDMemoryBytes:     perFDMemoryBytes,
+		PerSocketMemoryBytes: perSocketMemoryBytes,
+	}
+	est.FixedMemoryBytes = est.BaselineMemoryBytes + uint64(cpus)*perVCPU
+	if spec.Linux != nil && spec.Linux.Resources != nil && spec.Linux.Resources.Memory != nil {
+		if limit := spec.Linux.Resources.Memory.Limit; limit != nil && *limit > 0 {
+			est.MemoryLimitBytes = uint64(*limit)
+			est.RequiredMemoryBytes = est.MemoryLimitBytes + est.FixedMemoryBytes
+		}
+	}
+	overlay := conf.GetOverlay2()
+	est.RootfsOverlayInMemory = overlay.RootOverlayMedium() == config.MemoryOverlay
+	return est, nil
+}
+
+// specCPUs returns the number of vCPUs of a sandbox started with spec, like
+// Sandbox.cgroupCPUNum does once the sandbox cgroup exists.
+func specCPUs(conf *config.Config, spec *specs.Spec) (int, error) {
+	cpus := runtime.NumCPU()
+	if spec.Linux == nil || spec.Linux.Resources == nil || spec.Linux.Resources.CPU == nil {
+		return cpus, nil
+	}
+	cpu := spec.Linux.Resources.CPU
+	if cpu.Cpus != "" {
+		n, err := cgroup.CountCpuset(cpu.Cpus)
+		if err != nil {
+			return 0, fmt.Errorf("invalid cpuset %q: %w", cpu.Cpus, err)
+		}
+		cpus = n
+	}
+	if conf.CPUNumFromQuota && cpu.Quota != nil && *cpu.Quota > 0 && cpu.Period != nil && *cpu.Period > 0 {
+		const minCPUs = 2
+		n := int(math.Ceil(float64(*cpu.Quota) / float64(*cpu.Period)))
+		n = max(n, minCPUs)
+		cpus = min(cpus, n)
+	}
+	return cpus, nil
+}
+
+// specNOFILE returns the RLIMIT_NOFILE of the process in spec.
+func specNOFILE(spec *specs.Spec) uint64 {
+	// See the defaults in boot/limits.go.
+	const defaultNOFILE = 1048576
+	if spec.Process != nil {
+		for _, rl := range spec.Process.Rlimits {
+			if rl.Type == "RLIMIT_NOFILE" {
+				return rl.Hard
+			}
+		}
+	}
+	return defaultNOFILE
+}
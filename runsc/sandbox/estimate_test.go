@@ -0,0 +1,77 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sandbox
+
+import (
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"gvisor.dev/gvisor/runsc/config"
+)
+
+func TestEstimateOverhead(t *testing.T) {
+	quota := int64(150000)
+	period := uint64(100000)
+	limit := int64(512 << 20)
+	spec := &specs.Spec{
+		Process: &specs.Process{
+			Rlimits: []specs.POSIXRlimit{{Type: "RLIMIT_NOFILE", Hard: 4096, Soft: 1024}},
+		},
+		Linux: &specs.Linux{
+			Resources: &specs.LinuxResources{
+				CPU: &specs.LinuxCPU{
+					Cpus:   "0-7",
+					Quota:  &quota,
+					Period: &period,
+				},
+				Memory: &specs.LinuxMemory{Limit: &limit},
+			},
+		},
+	}
+	conf := &config.Config{Platform: "systrap", CPUNumFromQuota: true}
+
+	est, err := EstimateOverhead(conf, spec)
+	if err != nil {
+		t.Fatalf("EstimateOverhead failed: %v", err)
+	}
+	// A quota of 1.5 CPUs is rounded up, and to no less than 2 CPUs.
+	if est.CPUs != 2 {
+		t.Errorf("CPUs = %d, want 2", est.CPUs)
+	}
+	if est.MaxFDsPerProcess != 4096 {
+		t.Errorf("MaxFDsPerProcess = %d, want 4096", est.MaxFDsPerProcess)
+	}
+	if want := est.BaselineMemoryBytes + 2*perVCPUMemoryBytes["systrap"]; est.FixedMemoryBytes != want {
+		t.Errorf("FixedMemoryBytes = %d, want %d", est.FixedMemoryBytes, want)
+	}
+	if want := uint64(limit) + est.FixedMemoryBytes; est.RequiredMemoryBytes != want {
+		t.Errorf("RequiredMemoryBytes = %d, want %d", est.RequiredMemoryBytes, want)
+	}
+
+	// Without quota, the cpuset determines the number of CPUs.
+	conf.CPUNumFromQuota = false
+	est, err = EstimateOverhead(conf, spec)
+	if err != nil {
+		t.Fatalf("EstimateOverhead failed: %v", err)
+	}
+	if est.CPUs != 8 {
+		t.Errorf("CPUs = %d, want 8", est.CPUs)
+	}
+
+	spec.Linux.Resources.CPU.Cpus = "0-"
+	if _, err := EstimateOverhead(conf, spec); err == nil {
+		t.Errorf("EstimateOverhead with invalid cpuset succeeded")
+	}
+}
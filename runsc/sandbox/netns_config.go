@@ -0,0 +1,218 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sandbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/vishvananda/netlink"
+	"gvisor.dev/gvisor/pkg/log"
+	"gvisor.dev/gvisor/runsc/config"
+)
+
+// NetNSConfig is the network configuration that "runsc restore --netns-config"
+// applies to the network namespace of a sandbox before restoring it, so that a
+// sandbox restored on another node picks up the addresses and routes that it
+// was assigned there.
+//
+// It is read from the result of a CNI ADD command, of which only the fields
+// below are used.
+type NetNSConfig struct {
+	Interfaces []NetNSInterface `json:"interfaces"`
+	IPs        []NetNSIPConfig  `json:"ips"`
+	Routes     []NetNSRoute     `json:"routes"`
+}
+
+// NetNSInterface is an interface of a CNI result.
+type NetNSInterface struct {
+	Name string `json:"name"`
+}
+
+// NetNSIPConfig is an IP configuration of a CNI result.
+type NetNSIPConfig struct {
+	// Interface is the index in NetNSConfig.Interfaces of the interface that
+	// the address is assigned to.
+	Interface *int `json:"interface"`
+
+	// Address is the address with its prefix length, e.g. "10.0.0.2/24".
+	Address string `json:"address"`
+
+	// Gateway is the default gateway of the address family, if any.
+	Gateway string `json:"gateway"`
+}
+
+// NetNSRoute is a route of a CNI result.
+type NetNSRoute struct {
+	Dst string `json:"dst"`
+	GW  string `json:"gw"`
+	MTU int    `json:"mtu"`
+}
+
+// LoadNetNSConfig reads a NetNSConfig from the CNI result at path.
+func LoadNetNSConfig(path string) (*NetNSConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg NetNSConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %q: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// netNSLinkConfig is the configuration of a single link in a NetNSConfig.
+type netNSLinkConfig struct {
+	name     string
+	addrs    []*netlink.Addr
+	gateways []net.IP
+}
+
+// links groups the addresses of cfg by interface and validates them.
+func (cfg *NetNSConfig) links() ([]*netNSLinkConfig, error) {
+	links := make([]*netNSLinkConfig, len(cfg.Interfaces))
+	for i, iface := range cfg.Interfaces {
+		links[i] = &netNSLinkConfig{name: iface.Name}
+	}
+	for _, ip := range cfg.IPs {
+		if ip.Interface == nil || *ip.Interface < 0 || *ip.Interface >= len(links) {
+			return nil, fmt.Errorf("address %q is not assigned to a valid interface", ip.Address)
+		}
+		addr, err := netlink.ParseAddr(ip.Address)
+		if err != nil {
+			return nil, fmt.Errorf("parsing address %q: %w", ip.Address, err)
+		}
+		link := links[*ip.Interface]
+		link.addrs = append(link.addrs, addr)
+		if ip.Gateway != "" {
+			gw := net.ParseIP(ip.Gateway)
+			if gw == nil {
+				return nil, fmt.Errorf("parsing gateway %q", ip.Gateway)
+			}
+			link.gateways = append(link.gateways, gw)
+		}
+	}
+	return links, nil
+}
+
+// routeLink returns the link through which gw is reachable.
+func routeLink(links []*netNSLinkConfig, gw net.IP) (*netNSLinkConfig, bool) {
+	for _, link := range links {
+		for _, addr := range link.addrs {
+			if addr.IPNet.Contains(gw) {
+				return link, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// ApplyNetNSConfig replaces the addresses of the interfaces listed in cfg with
+// the ones assigned to them in cfg, and adds the routes of cfg, in the network
+// namespace of the sandbox. The sandbox network is then scraped from the
+// namespace as usual when it is restored.
+func (s *Sandbox) ApplyNetNSConfig(conf *config.Config, cfg *NetNSConfig) error {
+	if conf.Network != config.NetworkSandbox {
+		return fmt.Errorf("network namespace configuration requires --network=%s, got %s", config.NetworkSandbox, conf.Network)
+	}
+	links, err := cfg.links()
+	if err != nil {
+		return err
+	}
+
+	nsPath := filepath.Join("/proc", strconv.Itoa(s.Pid.Load()), "ns/net")
+	restore, err := joinNetNS(nsPath)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	for _, l := range links {
+		if len(l.addrs) == 0 {
+			continue
+		}
+		link, err := netlink.LinkByName(l.name)
+		if err != nil {
+			return fmt.Errorf("getting link for interface %q: %w", l.name, err)
+		}
+		old, err := netlink.AddrList(link, netlink.FAMILY_ALL)
+		if err != nil {
+			return fmt.Errorf("listing addresses of interface %q: %w", l.name, err)
+		}
+		for i := range old {
+			// Link-local addresses are derived from the link, not assigned.
+			if old[i].IP.IsLinkLocalUnicast() {
+				continue
+			}
+			if err := netlink.AddrDel(link, &old[i]); err != nil {
+				return fmt.Errorf("removing address %s from interface %q: %w", old[i].IPNet, l.name, err)
+			}
+		}
+		for _, addr := range l.addrs {
+			log.Infof("Assigning address %s to interface %q", addr.IPNet, l.name)
+			if err := netlink.AddrAdd(link, addr); err != nil {
+				return fmt.Errorf("assigning address %s to interface %q: %w", addr.IPNet, l.name, err)
+			}
+		}
+	}
+
+	for _, r := range cfg.Routes {
+		_, dst, err := net.ParseCIDR(r.Dst)
+		if err != nil {
+			return fmt.Errorf("parsing route destination %q: %w", r.Dst, err)
+		}
+		var gw net.IP
+		if r.GW != "" {
+			if gw = net.ParseIP(r.GW); gw == nil {
+				return fmt.Errorf("parsing gateway %q of route to %s", r.GW, r.Dst)
+			}
+		} else {
+			// Like CNI, use the gateway of the matching address family.
+			for _, l := range links {
+				for _, g := range l.gateways {
+					if (g.To4() == nil) == (dst.IP.To4() == nil) {
+						gw = g
+					}
+				}
+			}
+			if gw == nil {
+				return fmt.Errorf("no gateway for route to %s", r.Dst)
+			}
+		}
+		l, ok := routeLink(links, gw)
+		if !ok {
+			return fmt.Errorf("gateway %s of route to %s is not on any interface", gw, r.Dst)
+		}
+		link, err := netlink.LinkByName(l.name)
+		if err != nil {
+			return fmt.Errorf("getting link for interface %q: %w", l.name, err)
+		}
+		log.Infof("Adding route to %s via %s on interface %q", dst, gw, l.name)
+		if err := netlink.RouteReplace(&netlink.Route{
+			LinkIndex: link.Attrs().Index,
+			Dst:       dst,
+			Gw:        gw,
+			MTU:       r.MTU,
+		}); err != nil {
+			return fmt.Errorf("adding route to %s via %s: %w", dst, gw, err)
+		}
+	}
+	return nil
+}
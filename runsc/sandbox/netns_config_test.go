@@ -0,0 +1,111 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// cniResult is a CNI ADD result, as written by e.g. the bridge plugin.
+const cniResult = `{
+  "cniVersion": "1.0.0",
+  "interfaces": [
+    {"name": "cni0", "mac": "aa:bb:cc:dd:ee:ff"},
+    {"name": "eth0", "mac": "11:22:33:44:55:66", "sandbox": "/var/run/netns/test"}
+  ],
+  "ips": [
+    {"interface": 1, "address": "10.0.0.2/24", "gateway": "10.0.0.1"},
+    {"interface": 1, "address": "fd00::2/64"}
+  ],
+  "routes": [
+    {"dst": "0.0.0.0/0"}
+  ],
+  "dns": {}
+}`
+
+func TestLoadNetNSConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "result.json")
+	if err := os.WriteFile(path, []byte(cniResult), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadNetNSConfig(path)
+	if err != nil {
+		t.Fatalf("LoadNetNSConfig failed: %v", err)
+	}
+	if len(cfg.Routes) != 1 || cfg.Routes[0].Dst != "0.0.0.0/0" || cfg.Routes[0].GW != "" {
+		t.Errorf("LoadNetNSConfig got routes %+v, want one default route without gateway", cfg.Routes)
+	}
+
+	links, err := cfg.links()
+	if err != nil {
+		t.Fatalf("links failed: %v", err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("links got %d links, want 2", len(links))
+	}
+	if l := links[0]; l.name != "cni0" || len(l.addrs) != 0 {
+		t.Errorf("links got %+v for the host interface, want no addresses", l)
+	}
+	eth0 := links[1]
+	if eth0.name != "eth0" || len(eth0.addrs) != 2 || len(eth0.gateways) != 1 {
+		t.Fatalf("links got %+v for eth0, want 2 addresses and 1 gateway", eth0)
+	}
+	if got, want := eth0.addrs[0].IPNet.String(), "10.0.0.2/24"; got != want {
+		t.Errorf("first address of eth0 got %s, want %s", got, want)
+	}
+	if got, want := eth0.addrs[1].IPNet.String(), "fd00::2/64"; got != want {
+		t.Errorf("second address of eth0 got %s, want %s", got, want)
+	}
+	if l, ok := routeLink(links, eth0.gateways[0]); !ok || l != eth0 {
+		t.Errorf("routeLink(%s) got (%+v, %t), want eth0", eth0.gateways[0], l, ok)
+	}
+}
+
+func TestNetNSConfigLinksErrors(t *testing.T) {
+	one := 1
+	for _, tc := range []struct {
+		name string
+		ip   NetNSIPConfig
+	}{
+		{
+			name: "no interface",
+			ip:   NetNSIPConfig{Address: "10.0.0.2/24"},
+		},
+		{
+			name: "interface out of range",
+			ip:   NetNSIPConfig{Interface: &one, Address: "10.0.0.2/24"},
+		},
+		{
+			name: "invalid address",
+			ip:   NetNSIPConfig{Interface: new(int), Address: "10.0.0.300/24"},
+		},
+		{
+			name: "invalid gateway",
+			ip:   NetNSIPConfig{Interface: new(int), Address: "10.0.0.2/24", Gateway: "gateway"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &NetNSConfig{
+				Interfaces: []NetNSInterface{{Name: "eth0"}},
+				IPs:        []NetNSIPConfig{tc.ip},
+			}
+			if _, err := cfg.links(); err == nil {
+				t.Errorf("links succeeded, want error")
+			}
+		})
+	}
+}
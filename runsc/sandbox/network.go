@@ -292,6 +292,7 @@ func collectLinksAndRoutes(conf *config.Config, disableIPv6 bool) (boot.CreateLi
 				LinkAddress:          linkAddress,
 				Addresses:            addresses,
 				GVisorGRO:            conf.GVisorGRO,
+				DispatchMode:         conf.NetworkDispatchMode,
 			}
 			args.FDBasedLinks = append(args.FDBasedLinks, link)
 		}
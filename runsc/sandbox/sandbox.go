@@ -23,6 +23,7 @@ import (
 	"io"
 	"io/fs"
 	"math"
+	"net/url"
 	"os"
 	"os/exec"
 	"path"
@@ -52,6 +53,7 @@ import (
 	"gvisor.dev/gvisor/pkg/sentry/devices/nvproxy/nvconf"
 	"gvisor.dev/gvisor/pkg/sentry/fsimpl/erofs"
 	"gvisor.dev/gvisor/pkg/sentry/platform"
+	"gvisor.dev/gvisor/pkg/sentry/runtimestats"
 	"gvisor.dev/gvisor/pkg/sentry/seccheck"
 	"gvisor.dev/gvisor/pkg/sentry/state/checkpointfiles"
 	"gvisor.dev/gvisor/pkg/state/statefile"
@@ -62,7 +64,9 @@ import (
 	"gvisor.dev/gvisor/runsc/cgroup"
 	"gvisor.dev/gvisor/runsc/config"
 	"gvisor.dev/gvisor/runsc/console"
+	"gvisor.dev/gvisor/runsc/criu"
 	"gvisor.dev/gvisor/runsc/donation"
+	"gvisor.dev/gvisor/runsc/goferauth"
 	"gvisor.dev/gvisor/runsc/gvisorbinaries"
 	"gvisor.dev/gvisor/runsc/hostsettings"
 	"gvisor.dev/gvisor/runsc/profile"
@@ -86,6 +90,30 @@ const (
 	checkpointGCSOptsFileName = "gcs_opts.json"
 )
 
+// IsImageURL returns true if imagePath is an HTTP(S) URL rather than a local
+// directory. Checkpoint files at such URLs are accessed via the checkpoint
+// gofer; see runsc/checkpointgofer/urlstore.
+func IsImageURL(imagePath string) bool {
+	return strings.HasPrefix(imagePath, "http://") || strings.HasPrefix(imagePath, "https://")
+}
+
+// RedactImagePath returns imagePath with any user information and query
+// parameters removed if it is an image URL, since these may contain
+// credentials. It should be used whenever imagePath is logged.
+func RedactImagePath(imagePath string) string {
+	if !IsImageURL(imagePath) {
+		return imagePath
+	}
+	u, err := url.Parse(imagePath)
+	if err != nil {
+		return "<invalid image URL>"
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.ForceQuery = false
+	return u.String()
+}
+
 func controlSocketName(id string) string {
 	return fmt.Sprintf("runsc-%s.sock", id)
 }
@@ -112,6 +140,38 @@ func createControlSocket(rootDir, id string) (string, int, error) {
 	return "", -1, fmt.Errorf("unable to find location to write socket file")
 }
 
+// createControlGrantSockets restricts the control socket to the owner of the
+// sandbox and creates the sockets for the grants of policy next to it.
+//
+// The control server can't authorize peers itself, because their host
+// credentials aren't visible from the sandbox's user namespace. Instead, the
+// host kernel checks that peers may access the socket they connect to, and
+// the control server restricts each socket to the methods of its grant.
+func (s *Sandbox) createControlGrantSockets(policy string) ([]*os.File, error) {
+	p, err := server.ParsePolicy(policy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --control-policy: %w", err)
+	}
+	if err := os.Chmod(s.ControlSocketPath, 0600); err != nil {
+		return nil, fmt.Errorf("restricting control socket %q: %w", s.ControlSocketPath, err)
+	}
+	grants := p.Grants()
+	files := make([]*os.File, 0, len(grants))
+	for i := range grants {
+		path := s.ControlSocketPath + grants[i].SocketSuffix()
+		fd, err := server.CreateGrantSocket(path, &grants[i])
+		if err != nil {
+			for _, f := range files {
+				f.Close()
+			}
+			return nil, fmt.Errorf("creating control socket for --control-policy: %w", err)
+		}
+		s.ControlGrantSocketPaths = append(s.ControlGrantSocketPaths, path)
+		files = append(files, os.NewFile(uintptr(fd), "control_server_grant_socket"))
+	}
+	return files, nil
+}
+
 // Pid is an atomic type that implements JSON marshal/unmarshal interfaces.
 type Pid struct {
 	val atomicbitops.Int64
@@ -204,6 +264,12 @@ type Sandbox struct {
 	// DO NOT access this directly, use getControlSocketPath() instead.
 	ControlSocketPath string `json:"controlSocketPath"`
 
+	// ControlGrantSocketPaths are the paths to the sockets for the grants of
+	// --control-policy. Only users that a grant applies to may connect to its
+	// socket, and only the owner of the sandbox may connect to
+	// ControlSocketPath when a policy is set.
+	ControlGrantSocketPaths []string `json:"controlGrantSocketPaths,omitempty"`
+
 	// MountHints provides extra information about container mounts that apply
 	// to the entire pod.
 	MountHints *boot.PodMountHints `json:"mountHints"`
@@ -211,6 +277,19 @@ type Sandbox struct {
 	// StartTime is the time the sandbox was started.
 	StartTime time.Time `json:"startTime"`
 
+	// Platform is the platform the sandbox runs on. It is empty for
+	// sandboxes created by older versions of runsc.
+	Platform string `json:"platform"`
+
+	// CrashBundle is the path of the file that the sandbox writes a crash
+	// bundle to when it's about to crash, if --crash-bundle-dir is set. It's
+	// removed when the sandbox is destroyed if nothing was written to it.
+	CrashBundle string `json:"crashBundle,omitempty"`
+
+	// platformFallbacks is the number of preferred platforms that were
+	// unavailable when the sandbox was created. See SelectPlatform.
+	platformFallbacks int
+
 	// rootDir is the same as config.Config.RootDir. It represents the runtime
 	// root directory being used by the current runsc invocation. It's not saved
 	// to json, because the RootDir can change across runsc invocations.
@@ -285,6 +364,11 @@ type Args struct {
 	// for bind mounts in Spec.Mounts (in the same order).
 	GoferMountConfs specutils.GoferMountConfFlags
 
+	// GoferAuthKey is the key shared with the root container's gofer to
+	// authenticate the IOFiles and DevIOFile connections. If nil, the
+	// connections are not authenticated.
+	GoferAuthKey goferauth.Key
+
 	// MountHints provides extra information about containers mounts that apply
 	// to the entire pod.
 	MountHints *boot.PodMountHints
@@ -322,6 +406,10 @@ type Args struct {
 // New creates the sandbox process. The caller must call Destroy() on the
 // sandbox.
 func New(conf *config.Config, args *Args) (*Sandbox, error) {
+	platformFallbacks, err := SelectPlatform(conf)
+	if err != nil {
+		return nil, err
+	}
 	s := &Sandbox{
 		ID: args.ID,
 		CgroupJSON: cgroup.CgroupJSON{
@@ -333,6 +421,8 @@ func New(conf *config.Config, args *Args) (*Sandbox, error) {
 		MetricServerAddress: conf.MetricServer,
 		MountHints:          args.MountHints,
 		StartTime:           starttime.Get(),
+		Platform:            conf.Platform,
+		platformFallbacks:   platformFallbacks,
 	}
 	if args.Spec != nil && args.Spec.Annotations != nil {
 		s.PodName = args.Spec.Annotations[podNameAnnotation]
@@ -477,7 +567,7 @@ func (s *Sandbox) StartRoot(conf *config.Config, spec *specs.Spec) error {
 }
 
 // StartSubcontainer starts running a sub-container inside the sandbox.
-func (s *Sandbox) StartSubcontainer(spec *specs.Spec, conf *config.Config, cid string, stdios, goferFiles, goferFilestores []*os.File, devIOFile *os.File, goferConfs []specutils.GoferMountConf) error {
+func (s *Sandbox) StartSubcontainer(spec *specs.Spec, conf *config.Config, cid string, stdios, goferFiles, goferFilestores []*os.File, devIOFile *os.File, goferConfs []specutils.GoferMountConf, goferAuthKey goferauth.Key) error {
 	log.Debugf("Start sub-container %q in sandbox %q, PID: %d", cid, s.ID, s.Pid.Load())
 
 	if err := s.configureStdios(conf, stdios); err != nil {
@@ -522,6 +612,7 @@ func (s *Sandbox) StartSubcontainer(spec *specs.Spec, conf *config.Config, cid s
 		NumGoferFilestoreFDs:        len(goferFilestores),
 		IsDevIoFilePresent:          devIOFile != nil,
 		GoferMountConfs:             goferConfs,
+		GoferAuthKey:                goferAuthKey,
 		IsRootfsUpperTarFilePresent: rootfsUpperTarFile != nil,
 		FilePayload:                 payload,
 	}
@@ -531,16 +622,25 @@ func (s *Sandbox) StartSubcontainer(spec *specs.Spec, conf *config.Config, cid s
 	return nil
 }
 
-// Restore sends the restore call for a container in the sandbox.
-func (s *Sandbox) Restore(conf *config.Config, spec *specs.Spec, cid string, imagePath string, direct, background bool, networkArgs *boot.CreateLinksAndRoutesArgs) error {
+// Restore sends the restore call for a container in the sandbox. If template
+// is true, the image is used as a template shared by many sandboxes: memory
+// pages are only read from it when accessed, and it must remain unchanged for
+// the sandbox's lifetime.
+func (s *Sandbox) Restore(conf *config.Config, spec *specs.Spec, cid string, imagePath string, direct, background, template bool, networkArgs *boot.CreateLinksAndRoutesArgs) error {
 	if err := hostsettings.Handle(conf); err != nil {
 		return fmt.Errorf("host settings: %w (use --host-settings=ignore to bypass)", err)
 	}
+	if template && direct {
+		// O_DIRECT bypasses the host page cache, which is what allows sandboxes
+		// restored from the same template to share its pages.
+		return fmt.Errorf("restoring from a template can't use O_DIRECT")
+	}
 
-	log.Debugf("Restore sandbox %q from path %q", s.ID, imagePath)
+	log.Debugf("Restore sandbox %q from path %q", s.ID, RedactImagePath(imagePath))
 
 	opt := boot.RestoreOpts{
-		Background: background,
+		Background:        background,
+		LoadPagesOnDemand: template,
 	}
 	defer func() {
 		for _, f := range opt.FilePayload.Files {
@@ -602,7 +702,7 @@ func (s *Sandbox) setRestoreOpts(conf *config.Config, imagePath string, direct b
 	if clientSockFile == nil {
 		return s.setRestoreOptsForLocalCheckpointFiles(conf, imagePath, direct, opt)
 	}
-	log.Infof("Restoring from GCS via checkpoint gofer")
+	log.Infof("Restoring from remote storage via checkpoint gofer")
 	opt.FilePayload.Files = append(opt.FilePayload.Files, clientSockFile)
 	opt.UseCheckpointGofer = true
 	return nil
@@ -642,7 +742,7 @@ func (s *Sandbox) setRestoreOptsForLocalCheckpointFiles(conf *config.Config, ima
 }
 
 // RestoreSubcontainer sends the restore call for a sub-container in the sandbox.
-func (s *Sandbox) RestoreSubcontainer(spec *specs.Spec, conf *config.Config, cid string, stdios, goferFiles, goferFilestoreFiles []*os.File, devIOFile *os.File, goferMountConf []specutils.GoferMountConf) error {
+func (s *Sandbox) RestoreSubcontainer(spec *specs.Spec, conf *config.Config, cid string, stdios, goferFiles, goferFilestoreFiles []*os.File, devIOFile *os.File, goferMountConf []specutils.GoferMountConf, goferAuthKey goferauth.Key) error {
 	log.Debugf("Restore sub-container %q in sandbox %q, PID: %d", cid, s.ID, s.Pid.Load())
 
 	if err := s.configureStdios(conf, stdios); err != nil {
@@ -671,6 +771,7 @@ func (s *Sandbox) RestoreSubcontainer(spec *specs.Spec, conf *config.Config, cid
 		NumGoferFilestoreFDs: len(goferFilestoreFiles),
 		IsDevIoFilePresent:   devIOFile != nil,
 		GoferMountConfs:      goferMountConf,
+		GoferAuthKey:         goferAuthKey,
 		FilePayload:          payload,
 	}
 	if err := s.call(boot.ContMgrRestoreSubcontainer, &args, nil); err != nil {
@@ -690,6 +791,17 @@ func (s *Sandbox) Processes(cid string) ([]*control.Process, error) {
 	return pl, nil
 }
 
+// TaskUsage retrieves the resource usage of the tasks of a given container in
+// this sandbox.
+func (s *Sandbox) TaskUsage(cid string) (*control.TaskUsages, error) {
+	log.Debugf("Getting task usage for container %q in sandbox %q", cid, s.ID)
+	var u control.TaskUsages
+	if err := s.call(boot.ContMgrTaskUsage, &cid, &u); err != nil {
+		return nil, fmt.Errorf("retrieving task usage from sandbox: %v", err)
+	}
+	return &u, nil
+}
+
 // CreateTraceSession creates a new trace session.
 func (s *Sandbox) CreateTraceSession(config *seccheck.SessionConfig, force bool) error {
 	log.Debugf("Creating trace session in sandbox %q", s.ID)
@@ -778,6 +890,125 @@ func (s *Sandbox) Execute(conf *config.Config, args *control.ExecArgs) (int32, e
 	return pid, nil
 }
 
+// ImportCRIU imports the processes of the CRIU dump in imageDir into
+// container cid, and starts them.
+func (s *Sandbox) ImportCRIU(cid, imageDir string) ([]boot.ImportedProcess, error) {
+	log.Debugf("Importing CRIU dump %q into container %q in sandbox %q", imageDir, cid, s.ID)
+	open := func(name string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(imageDir, name))
+	}
+	names, err := criu.Images(open)
+	if err != nil {
+		return nil, fmt.Errorf("reading CRIU dump: %w", err)
+	}
+
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_SEQPACKET|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("creating CRIU image socket pair: %w", err)
+	}
+	theirs := os.NewFile(uintptr(fds[1]), "criu-images-sentry")
+	sent := make(chan error, 1)
+	go func() {
+		// Closing the socket tells the sandbox that all images were sent.
+		defer unix.Close(fds[0])
+		sent <- sendCRIUImages(fds[0], imageDir, names)
+	}()
+
+	args := boot.ImportCRIUArgs{
+		ContainerID: cid,
+		FilePayload: urpc.FilePayload{Files: []*os.File{theirs}},
+	}
+	var imported []boot.ImportedProcess
+	err = s.call(boot.ContMgrImportCRIU, &args, &imported)
+	// If the sandbox stopped reading images, this makes sending them fail.
+	theirs.Close()
+	if sendErr := <-sent; sendErr != nil && err == nil {
+		err = sendErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("importing CRIU dump: %w", err)
+	}
+	return imported, nil
+}
+
+// sendCRIUImages sends the named images in imageDir on the socket sock, as
+// described by boot.ImportCRIUArgs.
+func sendCRIUImages(sock int, imageDir string, names []string) error {
+	for _, name := range names {
+		f, err := os.Open(filepath.Join(imageDir, name))
+		if err != nil {
+			return fmt.Errorf("opening CRIU image: %w", err)
+		}
+		err = unix.Sendmsg(sock, []byte(name), unix.UnixRights(int(f.Fd())), nil, 0)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("sending CRIU image %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// HostFD is a host FD held by the sandbox process.
+type HostFD struct {
+	// FD is the FD number in the sandbox process.
+	FD int `json:"fd"`
+
+	// Target is what FD refers to, as shown by /proc/[pid]/fd.
+	Target string `json:"target"`
+
+	// Owner is what holds FD in the sentry. It's empty if the sentry can't
+	// attribute FD, in which case FD may have been leaked.
+	Owner string `json:"owner,omitempty"`
+}
+
+// HostFDs returns the host FDs held by the sandbox process, sorted by FD.
+func (s *Sandbox) HostFDs() ([]HostFD, error) {
+	log.Debugf("Listing host FDs of sandbox %q", s.ID)
+	var owners []boot.HostFDOwner
+	if err := s.call(boot.ContMgrHostFDs, nil, &owners); err != nil {
+		return nil, fmt.Errorf("getting host FD owners: %w", err)
+	}
+	ownerByFD := make(map[int]string, len(owners))
+	for _, o := range owners {
+		ownerByFD[int(o.FD)] = o.Owner
+	}
+
+	// Read the FD list after getting owners, so that FDs opened in between
+	// are reported as unattributed rather than omitted.
+	dir := fmt.Sprintf("/proc/%d/fd", s.Pid.Load())
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading host FDs: %w", err)
+	}
+	fds := make([]HostFD, 0, len(entries))
+	for _, e := range entries {
+		fd, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		target, err := os.Readlink(filepath.Join(dir, e.Name()))
+		if err != nil {
+			// The FD was closed after the directory was read.
+			continue
+		}
+		fds = append(fds, HostFD{FD: fd, Target: target, Owner: ownerByFD[fd]})
+	}
+	slices.SortFunc(fds, func(a, b HostFD) int { return a.FD - b.FD })
+	return fds, nil
+}
+
+// CloseHostFD closes a host FD held by the sandbox process by closing the
+// application FDs that hold it. The sentry refuses to close host FDs that
+// aren't held by application FDs.
+func (s *Sandbox) CloseHostFD(fd int) error {
+	log.Debugf("Closing host FD %d of sandbox %q", fd, s.ID)
+	fd32 := int32(fd)
+	if err := s.call(boot.ContMgrCloseHostFD, &fd32, nil); err != nil {
+		return fmt.Errorf("closing host FD %d: %w", fd, err)
+	}
+	return nil
+}
+
 // Event retrieves stats about the sandbox such as memory and CPU utilization.
 func (s *Sandbox) Event(cid string) (*boot.EventOut, error) {
 	log.Debugf("Getting events for container %q in sandbox %q", cid, s.ID)
@@ -788,6 +1019,29 @@ func (s *Sandbox) Event(cid string) (*boot.EventOut, error) {
 	return &e, nil
 }
 
+// SubscribeEvents subscribes to sandbox events such as container exits. It
+// returns a connection from which boot.SandboxEvent objects can be decoded as
+// JSON, one per line, until the sandbox exits. The caller must close it.
+func (s *Sandbox) SubscribeEvents() (*os.File, error) {
+	log.Debugf("Subscribing to events of sandbox %q", s.ID)
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("creating events socket pair: %w", err)
+	}
+	ours := os.NewFile(uintptr(fds[0]), "sandbox-events")
+	theirs := os.NewFile(uintptr(fds[1]), "sandbox-events-sentry")
+	defer theirs.Close()
+
+	args := boot.SubscribeEventsArgs{
+		FilePayload: urpc.FilePayload{Files: []*os.File{theirs}},
+	}
+	if err := s.call(boot.ContMgrSubscribeEvents, &args, nil); err != nil {
+		ours.Close()
+		return nil, fmt.Errorf("subscribing to sandbox events: %w", err)
+	}
+	return ours, nil
+}
+
 // PortForward starts port forwarding to the sandbox.
 func (s *Sandbox) PortForward(opts *boot.PortForwardOpts) error {
 	log.Debugf("Requesting port forward for container %q in sandbox %q: %+v", opts.ContainerID, s.ID, opts)
@@ -841,6 +1095,16 @@ func (s *Sandbox) sandboxConnect() (*urpc.Client, error) {
 	if path == "" {
 		return nil, fmt.Errorf("no control socket found for sandbox %q", s.ID)
 	}
+	if unix.Access(path, unix.W_OK) != nil {
+		// Users other than the owner connect through the socket of a grant
+		// that applies to them.
+		for _, grantPath := range s.ControlGrantSocketPaths {
+			if unix.Access(grantPath, unix.W_OK) == nil {
+				path = grantPath
+				break
+			}
+		}
+	}
 	if len(path) >= linux.UnixPathMax {
 		// This is not an abstract socket path. It is a filesystem path.
 		// UDS connect fails when the len(socket path) >= UNIX_PATH_MAX. Instead
@@ -930,6 +1194,12 @@ func (s *Sandbox) createSandboxProcess(conf *config.Config, args *Args, startSyn
 	if err := donations.DonateDebugLogFile("panic-log-fd", conf.PanicLog, lfOpts); err != nil {
 		return fmt.Errorf("donating panic log file: %w", err)
 	}
+	if conf.CrashBundleDir != "" {
+		s.CrashBundle = lfOpts.Build(filepath.Join(conf.CrashBundleDir, "runsc-crash.%ID%.%TIMESTAMP%.tar"))
+		if err := donations.DonateLogFile("crash-bundle-fd", s.CrashBundle, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, lfOpts); err != nil {
+			return fmt.Errorf("donating crash bundle file: %w", err)
+		}
+	}
 	covFilename := conf.CoverageReport
 	if covFilename == "" {
 		covFilename = os.Getenv("GO_COVERAGE_FILE")
@@ -980,6 +1250,13 @@ func (s *Sandbox) createSandboxProcess(conf *config.Config, args *Args, startSyn
 	donations.DonateAndClose("dev-io-fd", args.DevIOFile)
 	donations.DonateAndClose("gofer-filestore-fds", args.GoferFilestoreFiles...)
 	donations.DonateAndClose("mounts-fd", args.MountsFile)
+	if args.GoferAuthKey != nil {
+		keyFile, err := goferauth.NewKeyFile(args.GoferAuthKey)
+		if err != nil {
+			return fmt.Errorf("creating gofer authentication key file: %w", err)
+		}
+		donations.DonateAndClose("gofer-auth-key-fd", keyFile)
+	}
 	donations.Donate("start-sync-fd", startSyncFile)
 	if err := donations.DonateLogFile("user-log-fd", args.UserLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, lfOpts); err != nil {
 		return err
@@ -1005,6 +1282,13 @@ func (s *Sandbox) createSandboxProcess(conf *config.Config, args *Args, startSyn
 	s.ControlSocketPath = controlSocketPath
 	log.Infof("Control socket path: %q", s.ControlSocketPath)
 	donations.DonateAndClose("controller-fd", os.NewFile(uintptr(sockFD), "control_server_socket"))
+	if conf.ControlPolicy != "" {
+		grantFiles, err := s.createControlGrantSockets(conf.ControlPolicy)
+		if err != nil {
+			return err
+		}
+		donations.DonateAndClose("control-grant-fds", grantFiles...)
+	}
 
 	specFile, err := specutils.OpenSpec(args.BundleDir)
 	if err != nil {
@@ -1293,34 +1577,9 @@ func (s *Sandbox) createSandboxProcess(conf *config.Config, args *Args, startSyn
 
 	mem := totalSysMem
 	if s.CgroupJSON.Cgroup != nil {
-		cpuNum, err := s.CgroupJSON.Cgroup.NumCPU()
+		cpuNum, cpuQuota, cpuPeriod, err := s.cgroupCPUNum(conf)
 		if err != nil {
-			return fmt.Errorf("getting cpu count from cgroups: %v", err)
-		}
-		cpuQuota, err := s.CgroupJSON.Cgroup.CPUQuota()
-		if err != nil {
-			return fmt.Errorf("getting raw cpu quota from cgroups: %v", err)
-		}
-		cpuPeriod, err := s.CgroupJSON.Cgroup.CPUPeriod()
-		if err != nil {
-			return fmt.Errorf("getting raw cpu period from cgroups: %v", err)
-		}
-		if conf.CPUNumFromQuota && cpuQuota > 0 && cpuPeriod > 0 {
-			// Dropping below 2 CPUs can trigger application to disable
-			// locks that can lead do hard to debug errors, so just
-			// leaving two cores as reasonable default.
-			const minCPUs = 2
-
-			quota := float64(cpuQuota) / float64(cpuPeriod)
-			if n := int(math.Ceil(quota)); n > 0 {
-				if n < minCPUs {
-					n = minCPUs
-				}
-				if n < cpuNum {
-					// Only lower the cpu number.
-					cpuNum = n
-				}
-			}
+			return err
 		}
 		cmd.Args = append(cmd.Args, "--cpu-num", strconv.Itoa(cpuNum))
 		if cpuQuota > 0 {
@@ -1340,6 +1599,10 @@ func (s *Sandbox) createSandboxProcess(conf *config.Config, args *Args, startSyn
 	}
 	cmd.Args = append(cmd.Args, "--total-memory", strconv.FormatUint(mem, 10))
 
+	if s.platformFallbacks > 0 {
+		cmd.Args = append(cmd.Args, "--platform-fallbacks", strconv.Itoa(s.platformFallbacks))
+	}
+
 	if args.Attached {
 		// Kill sandbox if parent process exits in attached mode.
 		cmd.SysProcAttr.Pdeathsig = unix.SIGKILL
@@ -1470,6 +1733,30 @@ func (s *Sandbox) Wait(cid string) (unix.WaitStatus, error) {
 	return s.status, nil
 }
 
+// WaitContainers waits for any or all of the given containers in the sandbox
+// to exit, for at most timeout if it's not zero.
+func (s *Sandbox) WaitContainers(cids []string, all bool, timeout time.Duration) (*boot.WaitContainersResult, error) {
+	log.Debugf("Waiting for containers %v in sandbox %q, all: %t, timeout: %v", cids, s.ID, all, timeout)
+	args := boot.WaitContainersArgs{
+		CIDs:    cids,
+		All:     all,
+		Timeout: timeout,
+	}
+	var res boot.WaitContainersResult
+	if err := s.call(boot.ContMgrWaitContainers, &args, &res); err != nil {
+		return nil, fmt.Errorf("waiting on containers %v in sandbox %q: %w", cids, s.ID, err)
+	}
+	for _, exit := range res.Exited {
+		if s.IsRootContainer(exit.CID) {
+			// Like Wait, don't return before the sandbox process has exited.
+			if err := s.waitForStopped(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &res, nil
+}
+
 // WaitPID waits for process 'pid' in the container's sandbox and returns its
 // WaitStatus.
 func (s *Sandbox) WaitPID(cid string, pid int32) (unix.WaitStatus, error) {
@@ -1531,6 +1818,11 @@ func (s *Sandbox) destroy() error {
 			log.Warningf("failed to delete control socket file %q: %v", controlSocketPath, err)
 		}
 	}
+	for _, path := range s.ControlGrantSocketPaths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Warningf("failed to delete control socket file %q: %v", path, err)
+		}
+	}
 	pid := s.Pid.Load()
 	if pid != 0 {
 		log.Debugf("Killing sandbox %q", s.ID)
@@ -1541,10 +1833,33 @@ func (s *Sandbox) destroy() error {
 			return fmt.Errorf("waiting sandbox %q stop: %w", s.ID, err)
 		}
 	}
+	s.finishCrashBundle()
 
 	return nil
 }
 
+// finishCrashBundle completes the crash bundle file of the sandbox if the
+// sentry crashed, see boot.FinishCrashBundle, and removes it otherwise.
+func (s *Sandbox) finishCrashBundle() {
+	if s.CrashBundle == "" {
+		return
+	}
+	crashed, err := boot.FinishCrashBundle(s.CrashBundle)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warningf("Failed to finish crash bundle %q: %v", s.CrashBundle, err)
+		}
+		return
+	}
+	if crashed {
+		log.Warningf("Sandbox %q crashed, crash bundle written to %q", s.ID, s.CrashBundle)
+		return
+	}
+	if err := os.Remove(s.CrashBundle); err != nil {
+		log.Warningf("Failed to remove empty crash bundle %q: %v", s.CrashBundle, err)
+	}
+}
+
 // SignalContainer sends the signal to a container in the sandbox. If all is
 // true and signal is SIGKILL, then waits for all processes to exit before
 // returning.
@@ -1616,6 +1931,14 @@ type CheckpointOpts struct {
 	CudaCheckpointPath        string
 	CudaCheckpointSequential  bool
 
+	// ImageURL, if set, is an HTTP(S) URL to which checkpoint files are
+	// streamed using HTTP PUT, instead of being written to the image path
+	// passed to Sandbox.Checkpoint. This avoids staging checkpoint files on
+	// local disk before uploading them to object storage. See
+	// urlstore.FileServerOptions.URL for how file URLs are derived from
+	// ImageURL.
+	ImageURL string
+
 	// Save/restore exec options.
 	SaveRestoreExecArgv        string
 	SaveRestoreExecTimeout     time.Duration
@@ -1625,7 +1948,12 @@ type CheckpointOpts struct {
 // Checkpoint sends the checkpoint call for a container in the sandbox.
 // The statefile will be written to f.
 func (s *Sandbox) Checkpoint(conf *config.Config, cid string, imagePath string, opts CheckpointOpts) error {
-	log.Debugf("Checkpoint sandbox %q, imagePath %q, opts %+v", s.ID, imagePath, opts)
+	if opts.ImageURL != "" {
+		imagePath = opts.ImageURL
+	}
+	logOpts := opts
+	logOpts.ImageURL = RedactImagePath(opts.ImageURL)
+	log.Debugf("Checkpoint sandbox %q, imagePath %q, opts %+v", s.ID, RedactImagePath(imagePath), logOpts)
 
 	opt := control.SaveOpts{
 		Metadata:                       opts.Compression.ToMetadata(),
@@ -1663,7 +1991,7 @@ func (s *Sandbox) setCheckpointOptsFiles(conf *config.Config, imagePath string,
 	if clientSockFile == nil {
 		return setCheckpointOptsFilesForLocalCheckpoint(conf, imagePath, opts, opt)
 	}
-	log.Infof("Saving to GCS via checkpoint gofer")
+	log.Infof("Saving to remote storage via checkpoint gofer")
 	opt.FilePayload.Files = append(opt.FilePayload.Files, clientSockFile)
 	opt.UseCheckpointGofer = true
 	opt.HavePagesFile = opts.Compression == statefile.CompressionLevelNone
@@ -1729,7 +2057,7 @@ func (s *Sandbox) openFSRestoreFiles(conf *config.Config, imagePath string, dire
 		return openFSRestoreFilesForLocalCheckpoint(imagePath, direct)
 	}
 	cmd.Args = append(cmd.Args, "-fs-restore-checkpoint-gofer")
-	log.Infof("Restoring filesystem checkpoint from GCS via checkpoint gofer")
+	log.Infof("Restoring filesystem checkpoint from remote storage via checkpoint gofer")
 	return []*os.File{clientSockFile}, nil
 }
 
@@ -1754,7 +2082,7 @@ type FSSaveOpts struct {
 
 // FSSave sends the filesystem checkpointing call to the sandbox.
 func (s *Sandbox) FSSave(conf *config.Config, cid string, imagePath string, opts FSSaveOpts) error {
-	log.Debugf("Checkpoint filesystem for sandbox %q, imagePath %q, opts %+v", s.ID, imagePath, opts)
+	log.Debugf("Checkpoint filesystem for sandbox %q, imagePath %q, opts %+v", s.ID, RedactImagePath(imagePath), opts)
 
 	args := boot.FSSaveArgs{
 		ExitAfterSaving: opts.ExitAfterSaving,
@@ -1783,7 +2111,7 @@ func (s *Sandbox) setFSSaveArgs(conf *config.Config, imagePath string, direct bo
 	if clientSockFile == nil {
 		return setFSSaveArgsForLocalCheckpointFiles(conf, imagePath, direct, args)
 	}
-	log.Infof("Saving filesystem checkpoint to GCS via checkpoint gofer")
+	log.Infof("Saving filesystem checkpoint to remote storage via checkpoint gofer")
 	args.FilePayload.Files = append(args.FilePayload.Files, clientSockFile)
 	args.UseCheckpointGofer = true
 	return nil
@@ -1867,10 +2195,19 @@ func openFSCheckpointLocalFiles(imagePath string, openFlags int, direct bool) ([
 }
 
 // maybeStartCheckpointGoferAndGetSocket checks if use of a checkpoint gofer is
-// specified by configuration files in imagePath. If so, it starts the
+// specified by imagePath, which is either an HTTP(S) URL or a directory
+// containing checkpoint gofer configuration files. If so, it starts the
 // checkpoint gofer and returns an os.File representing a socket connected to
 // it. Otherwise, it returns nil.
 func (s *Sandbox) maybeStartCheckpointGoferAndGetSocket(conf *config.Config, cg cgroup.Cgroup, imagePath string, extraFlags ...string) (*os.File, error) {
+	if IsImageURL(imagePath) {
+		urlOptsFile, err := createCheckpointURLOptsFile(imagePath)
+		if err != nil {
+			return nil, err
+		}
+		defer urlOptsFile.Close()
+		return s.startCheckpointGofer(conf, cg, "-url-opts-fd", urlOptsFile, extraFlags...)
+	}
 	gcsOptsPath := path.Join(imagePath, checkpointGCSOptsFileName)
 	gcsOptsFile, err := os.Open(gcsOptsPath)
 	if err != nil {
@@ -1880,6 +2217,38 @@ func (s *Sandbox) maybeStartCheckpointGoferAndGetSocket(conf *config.Config, cg
 		return nil, nil
 	}
 	defer gcsOptsFile.Close()
+	return s.startCheckpointGofer(conf, cg, "-gcs-opts-fd", gcsOptsFile, extraFlags...)
+}
+
+// createCheckpointURLOptsFile returns an anonymous file containing the
+// checkpoint gofer's URLOptions for imageURL. Options are passed in a file
+// rather than on the command line since URLs may contain credentials.
+func createCheckpointURLOptsFile(imageURL string) (*os.File, error) {
+	opts, err := json.Marshal(struct {
+		URL string `json:"url"`
+	}{URL: imageURL})
+	if err != nil {
+		return nil, err
+	}
+	fd, err := unix.MemfdCreate("checkpointgofer-url-opts", unix.MFD_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint gofer options file: %w", err)
+	}
+	f := os.NewFile(uintptr(fd), "checkpointgofer-url-opts")
+	if _, err := f.Write(opts); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write checkpoint gofer options: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to rewind checkpoint gofer options: %w", err)
+	}
+	return f, nil
+}
+
+// startCheckpointGofer starts the checkpoint gofer, passing it optsFile using
+// optsFlag, and returns an os.File representing a socket connected to it.
+func (s *Sandbox) startCheckpointGofer(conf *config.Config, cg cgroup.Cgroup, optsFlag string, optsFile *os.File, extraFlags ...string) (*os.File, error) {
 	// Pass /dev/null as the checkpoint gofer's standard streams to avoid
 	// polluting this process' logs. The checkpoint gofer accepts flags like
 	// -debug-log, which are plumbed through to it by passing conf.ToFlags()
@@ -1897,7 +2266,7 @@ func (s *Sandbox) maybeStartCheckpointGoferAndGetSocket(conf *config.Config, cg
 	clientSockFile := os.NewFile(uintptr(socketFDs[0]), "checkpointgofer-socket")
 	err = cgroup.RunInCgroup(cg, func() error {
 		argv := append([]string{"runsc-checkpointgofer"}, conf.ToFlags()...)
-		extraFiles := []uintptr{devNullFile.Fd(), devNullFile.Fd(), devNullFile.Fd(), uintptr(socketFDs[1]), gcsOptsFile.Fd()}
+		extraFiles := []uintptr{devNullFile.Fd(), devNullFile.Fd(), devNullFile.Fd(), uintptr(socketFDs[1]), optsFile.Fd()}
 
 		// Add log file FDs.
 		if conf.LogFilename != "" {
@@ -1925,7 +2294,7 @@ func (s *Sandbox) maybeStartCheckpointGoferAndGetSocket(conf *config.Config, cg
 			argv = append(argv, fmt.Sprintf("--debug-log-fd=%d", len(extraFiles)-1))
 		}
 
-		argv = append(argv, "checkpointgofer", "-sock-fd=3", "-gcs-opts-fd=4")
+		argv = append(argv, "checkpointgofer", "-sock-fd=3", optsFlag+"=4")
 		argv = append(argv, extraFlags...)
 		// Don't forward GOMAXPROCS defaults that apply to this process (in
 		// particular, containerd-shim-runsc-v1 passes GOMAXPROCS=2 in
@@ -1973,7 +2342,7 @@ func (s *Sandbox) maybeConfigureSandboxProcessForWorkloadTriggerSave(conf *confi
 	if clientSockFile != nil {
 		donations.DonateAndClose("save-fds", clientSockFile)
 		cmd.Args = append(cmd.Args, "-save-checkpoint-gofer")
-		log.Infof("Enabling workload-trigger saving to GCS via checkpoint gofer")
+		log.Infof("Enabling workload-trigger saving to remote storage via checkpoint gofer")
 	} else {
 		files, err := createSaveFiles(path, direct, comp)
 		if err != nil {
@@ -1998,7 +2367,7 @@ func (s *Sandbox) maybeConfigureSandboxProcessForWorkloadTriggerFSSave(conf *con
 	if clientSockFile != nil {
 		donations.DonateAndClose("fs-save-fds", clientSockFile)
 		cmd.Args = append(cmd.Args, "-fs-save-checkpoint-gofer")
-		log.Infof("Enabling workload-trigger filesystem checkpoint saving to GCS via checkpoint gofer")
+		log.Infof("Enabling workload-trigger filesystem checkpoint saving to remote storage via checkpoint gofer")
 	} else {
 		files, err := openFSCheckpointLocalFiles(path, os.O_CREATE|os.O_EXCL|os.O_RDWR, boot.GetAnnotationFSCheckpointDirect(args.Spec))
 		if err != nil {
@@ -2010,8 +2379,87 @@ func (s *Sandbox) maybeConfigureSandboxProcessForWorkloadTriggerFSSave(conf *con
 	return nil
 }
 
-// Pause sends the pause call for a container in the sandbox.
+// cgroupCPUNum returns the number of CPUs that the sandbox's cgroup allows,
+// along with the cgroup's raw CPU quota and period.
+//
+// Preconditions: s.CgroupJSON.Cgroup != nil.
+func (s *Sandbox) cgroupCPUNum(conf *config.Config) (cpuNum int, cpuQuota, cpuPeriod int64, err error) {
+	cpuNum, err = s.CgroupJSON.Cgroup.NumCPU()
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("getting cpu count from cgroups: %v", err)
+	}
+	cpuQuota, err = s.CgroupJSON.Cgroup.CPUQuota()
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("getting raw cpu quota from cgroups: %v", err)
+	}
+	cpuPeriod, err = s.CgroupJSON.Cgroup.CPUPeriod()
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("getting raw cpu period from cgroups: %v", err)
+	}
+	if conf.CPUNumFromQuota && cpuQuota > 0 && cpuPeriod > 0 {
+		// Dropping below 2 CPUs can trigger application to disable
+		// locks that can lead do hard to debug errors, so just
+		// leaving two cores as reasonable default.
+		const minCPUs = 2
+
+		quota := float64(cpuQuota) / float64(cpuPeriod)
+		if n := int(math.Ceil(quota)); n > 0 {
+			if n < minCPUs {
+				n = minCPUs
+			}
+			if n < cpuNum {
+				// Only lower the cpu number.
+				cpuNum = n
+			}
+		}
+	}
+	return cpuNum, cpuQuota, cpuPeriod, nil
+}
+
+// UpdateCPUs brings the number of CPUs that are online in the sandbox in line
+// with the CPU limits of the sandbox's cgroup, e.g. after they were changed by
+// "runsc update". The sandbox can't bring more CPUs online than it was started
+// with.
+func (s *Sandbox) UpdateCPUs(conf *config.Config) error {
+	if s.CgroupJSON.Cgroup == nil {
+		return nil
+	}
+	cpuNum, _, _, err := s.cgroupCPUNum(conf)
+	if err != nil {
+		return err
+	}
+	log.Debugf("Setting online CPUs in sandbox %q to %d", s.ID, cpuNum)
+	if err := s.call(boot.ContMgrSetOnlineCPUs, &cpuNum, nil); err != nil {
+		return fmt.Errorf("setting online CPUs in sandbox: %w", err)
+	}
+	return nil
+}
+
+// UpdateResources applies new resource limits to the given container inside
+// the sandbox.
+func (s *Sandbox) UpdateResources(cid string, res *specs.LinuxResources) error {
+	log.Debugf("Updating resources of container %q in sandbox %q", cid, s.ID)
+	args := boot.UpdateResourcesArgs{
+		ContainerID: cid,
+		Resources:   res,
+	}
+	if err := s.call(boot.ContMgrUpdateResources, &args, nil); err != nil {
+		return fmt.Errorf("updating resources of container %q: %w", cid, err)
+	}
+	return nil
+}
+
+// Pause sends the pause call for a container in the sandbox. Pausing the root
+// container pauses the whole sandbox; pausing a subcontainer only pauses the
+// tasks in that container.
 func (s *Sandbox) Pause(cid string) error {
+	if !s.IsRootContainer(cid) {
+		log.Debugf("Pause container %q in sandbox %q", cid, s.ID)
+		if err := s.call(boot.ContMgrPauseContainer, &cid, nil); err != nil {
+			return fmt.Errorf("pausing container %q: %w", cid, err)
+		}
+		return nil
+	}
 	log.Debugf("Pause sandbox %q", s.ID)
 	if err := s.call(boot.ContMgrPause, nil, nil); err != nil {
 		return fmt.Errorf("pausing container %q: %w", cid, err)
@@ -2019,8 +2467,16 @@ func (s *Sandbox) Pause(cid string) error {
 	return nil
 }
 
-// Resume sends the resume call for a container in the sandbox.
+// Resume sends the resume call for a container in the sandbox. It undoes a
+// previous call to Pause for the same container.
 func (s *Sandbox) Resume(cid string) error {
+	if !s.IsRootContainer(cid) {
+		log.Debugf("Resume container %q in sandbox %q", cid, s.ID)
+		if err := s.call(boot.ContMgrResumeContainer, &cid, nil); err != nil {
+			return fmt.Errorf("resuming container %q: %w", cid, err)
+		}
+		return nil
+	}
 	log.Debugf("Resume sandbox %q", s.ID)
 	if err := s.call(boot.ContMgrResume, nil, nil); err != nil {
 		return fmt.Errorf("resuming container %q: %w", cid, err)
@@ -2028,6 +2484,18 @@ func (s *Sandbox) Resume(cid string) error {
 	return nil
 }
 
+// Reclaim asks the sandbox to return the given number of bytes of memory to
+// the host, or as much as possible if bytes is 0.
+func (s *Sandbox) Reclaim(bytes uint64) (*boot.ReclaimResult, error) {
+	log.Debugf("Reclaim %d bytes from sandbox %q", bytes, s.ID)
+	args := boot.ReclaimArgs{Bytes: bytes}
+	var result boot.ReclaimResult
+	if err := s.call(boot.ContMgrReclaim, &args, &result); err != nil {
+		return nil, fmt.Errorf("reclaiming memory from sandbox %q: %w", s.ID, err)
+	}
+	return &result, nil
+}
+
 // Usage sends the collect call for a container in the sandbox.
 func (s *Sandbox) Usage(Full bool) (control.MemoryUsage, error) {
 	log.Debugf("Usage sandbox %q", s.ID)
@@ -2095,6 +2563,16 @@ func (s *Sandbox) ExportMetrics(opts control.MetricsExportOpts) (*prometheus.Sna
 	return data.Snapshot, nil
 }
 
+// RuntimeStats returns the Go runtime statistics of the sandbox.
+func (s *Sandbox) RuntimeStats() (*runtimestats.Stats, error) {
+	log.Debugf("Runtime stats sandbox %q", s.ID)
+	var stats runtimestats.Stats
+	if err := s.call(boot.RuntimeStats, nil, &stats); err != nil {
+		return nil, fmt.Errorf("getting sandbox %q runtime stats: %w", s.ID, err)
+	}
+	return &stats, nil
+}
+
 // IsRunning returns true if the sandbox or gofer process is running.
 func (s *Sandbox) IsRunning() bool {
 	pid := s.Pid.Load()
@@ -2169,6 +2647,26 @@ func (s *Sandbox) Trace(f *os.File, duration time.Duration) error {
 	return s.call(boot.ProfileTrace, &opts, nil)
 }
 
+// LockContention writes lock contention statistics collected over the given
+// duration to the given file.
+func (s *Sandbox) LockContention(f *os.File, duration time.Duration) error {
+	log.Debugf("Lock contention %q", s.ID)
+	opts := control.LockContentionOpts{
+		FilePayload: urpc.FilePayload{Files: []*os.File{f}},
+		Duration:    duration,
+	}
+	return s.call(boot.LockdepContention, &opts, nil)
+}
+
+// LockGraph writes the lock ordering graph in DOT format to the given file.
+func (s *Sandbox) LockGraph(f *os.File) error {
+	log.Debugf("Lock graph %q", s.ID)
+	opts := control.LockGraphOpts{
+		FilePayload: urpc.FilePayload{Files: []*os.File{f}},
+	}
+	return s.call(boot.LockdepGraph, &opts, nil)
+}
+
 // ChangeLogging changes logging options.
 func (s *Sandbox) ChangeLogging(args control.LoggingArgs) error {
 	log.Debugf("Change logging start %q", s.ID)
@@ -2275,6 +2773,44 @@ func deviceFileForPlatform(name, devicePath string) (*fd.FD, error) {
 	return f, nil
 }
 
+// SelectPlatform selects the platform to run a new sandbox on. If
+// conf.Platform lists multiple platforms, each is probed in order of preference
+// by opening its device, and conf.Platform is replaced by the first platform
+// that is available. It returns the number of platforms that were skipped.
+// Callers that read conf.Platform must call SelectPlatform first, after which
+// conf.Platform names a single platform.
+//
+// If only one platform is listed, it is selected without probing, so that
+// errors are reported when the sandbox is started.
+func SelectPlatform(conf *config.Config) (int, error) {
+	candidates := conf.PlatformCandidates()
+	switch len(candidates) {
+	case 0:
+		return 0, fmt.Errorf("no platform specified")
+	case 1:
+		conf.Platform = candidates[0]
+		return 0, nil
+	}
+	var errs []error
+	for i, name := range candidates {
+		deviceFile, err := deviceFileForPlatform(name, conf.PlatformDevicePath)
+		if err != nil {
+			log.Warningf("Platform %q is unavailable: %v", name, err)
+			errs = append(errs, err)
+			continue
+		}
+		if deviceFile != nil {
+			deviceFile.Close()
+		}
+		if i > 0 {
+			log.Warningf("Falling back to platform %q, since preferred platforms %v are unavailable", name, candidates[:i])
+		}
+		conf.Platform = name
+		return i, nil
+	}
+	return 0, fmt.Errorf("none of the platforms %v are available: %w", candidates, errors.Join(errs...))
+}
+
 // getNvproxyDriverVersion returns the NVIDIA driver ABI version to use by
 // nvproxy.
 func getNvproxyDriverVersion(conf *config.Config) (string, error) {
@@ -2522,6 +3058,78 @@ func (s *Sandbox) ContainerRuntimeState(cid string) (boot.ContainerRuntimeState,
 	return state, nil
 }
 
+// Sysctls returns the sysctls set by a container.
+func (s *Sandbox) Sysctls(cid string) (map[string]string, error) {
+	log.Debugf("Sysctls, sandbox: %q, cid: %q", s.ID, cid)
+	var sysctls map[string]string
+	if err := s.call(boot.ContMgrSysctls, &cid, &sysctls); err != nil {
+		return nil, fmt.Errorf("getting sysctls (CID: %q): %w", cid, err)
+	}
+	return sysctls, nil
+}
+
+// NetworkDiagnostics returns a snapshot of the state of the sandbox network
+// stack.
+func (s *Sandbox) NetworkDiagnostics() (*boot.NetworkDiagnostics, error) {
+	log.Debugf("NetworkDiagnostics, sandbox: %q", s.ID)
+	var diag boot.NetworkDiagnostics
+	if err := s.call(boot.NetworkDiagnostics, nil, &diag); err != nil {
+		return nil, fmt.Errorf("getting network diagnostics: %w", err)
+	}
+	return &diag, nil
+}
+
+// ControlHandshake negotiates the control API version to use with the sandbox.
+// minVersion and maxVersion are the range of versions supported by the
+// caller.
+func (s *Sandbox) ControlHandshake(minVersion, maxVersion int) (int, error) {
+	log.Debugf("ControlHandshake, sandbox: %q, versions: [%d, %d]", s.ID, minVersion, maxVersion)
+	args := control.HandshakeArgs{
+		MinVersion: minVersion,
+		MaxVersion: maxVersion,
+	}
+	var res control.HandshakeResult
+	if err := s.call(boot.APIHandshake, &args, &res); err != nil {
+		return 0, fmt.Errorf("negotiating control API version: %w", err)
+	}
+	return res.Version, nil
+}
+
+// ControlCapabilities lists the control methods served by the sandbox and
+// their versions.
+func (s *Sandbox) ControlCapabilities() (*control.CapabilitiesResult, error) {
+	log.Debugf("ControlCapabilities, sandbox: %q", s.ID)
+	var caps control.CapabilitiesResult
+	if err := s.call(boot.APICapabilities, nil, &caps); err != nil {
+		return nil, fmt.Errorf("listing control capabilities: %w", err)
+	}
+	return &caps, nil
+}
+
+// ResyncNetwork applies a new address, route and MTU configuration to the
+// existing links of the sandbox.
+func (s *Sandbox) ResyncNetwork(args *boot.ResyncLinksAndRoutesArgs) error {
+	log.Debugf("ResyncNetwork, sandbox: %q, args: %+v", s.ID, args)
+	if err := s.call(boot.NetworkResyncLinksAndRoutes, args, nil); err != nil {
+		return fmt.Errorf("resyncing network: %w", err)
+	}
+	return nil
+}
+
+// SetNetworkPolicy sets the network policy of a container. A nil policy
+// removes it.
+func (s *Sandbox) SetNetworkPolicy(cid string, policy *boot.NetworkPolicy) error {
+	log.Debugf("SetNetworkPolicy, sandbox: %q, cid: %q, policy: %+v", s.ID, cid, policy)
+	args := boot.SetNetworkPolicyArgs{
+		CID:    cid,
+		Policy: policy,
+	}
+	if err := s.call(boot.ContMgrSetNetworkPolicy, &args, nil); err != nil {
+		return fmt.Errorf("setting network policy (CID: %q): %w", cid, err)
+	}
+	return nil
+}
+
 // TarRootfsUpperLayer serializes the rootfs upper layer of a given
 // container to a tar file. When the rootfs is not an overlayfs, it
 // returns an error. It writes the tar file to outFD.
@@ -2537,6 +3145,22 @@ func (s *Sandbox) TarRootfsUpperLayer(containerID string, outFD *os.File) error
 	return nil
 }
 
+// RootfsUpperUsage returns the space used by the rootfs upper layer of a given
+// container, along with up to top files that use the most of it. When the
+// rootfs is not an overlayfs, it returns an error.
+func (s *Sandbox) RootfsUpperUsage(containerID string, top int) (*control.RootfsUpperUsage, error) {
+	log.Debugf("RootfsUpperUsage, sandbox: %q, container: %q, top: %d", s.ID, containerID, top)
+	opts := control.RootfsUpperUsageOpts{
+		ContainerID: containerID,
+		Top:         top,
+	}
+	var usage control.RootfsUpperUsage
+	if err := s.call(boot.FsRootfsUpperUsage, &opts, &usage); err != nil {
+		return nil, fmt.Errorf("getting rootfs upper layer usage: %w", err)
+	}
+	return &usage, nil
+}
+
 // ReadFile reads a file of the sandbox from the given container (or root container if containerID is empty) up to the specified size.
 func (s *Sandbox) ReadFile(containerID, path string, size int64, outFD *os.File) error {
 	log.Debugf("ReadFile, sandbox: %q, container: %q, path: %q, size: %d", s.ID, containerID, path, size)
@@ -2552,6 +3176,74 @@ func (s *Sandbox) ReadFile(containerID, path string, size int64, outFD *os.File)
 	return nil
 }
 
+// CopyOut writes a tar archive of the file or directory at path in the given
+// container to outFD, as seen by the container.
+func (s *Sandbox) CopyOut(containerID, path string, outFD *os.File) error {
+	log.Debugf("CopyOut, sandbox: %q, container: %q, path: %q", s.ID, containerID, path)
+	opts := control.CopyOutOpts{
+		ContainerID: containerID,
+		Path:        path,
+		FilePayload: urpc.FilePayload{Files: []*os.File{outFD}},
+	}
+	if err := s.call(boot.FsCopyOut, &opts, nil); err != nil {
+		return fmt.Errorf("copying %q out of container: %w", path, err)
+	}
+	return nil
+}
+
+// CopyIn extracts the tar archive read from inFD to path in the given
+// container.
+func (s *Sandbox) CopyIn(containerID, path string, inFD *os.File) error {
+	log.Debugf("CopyIn, sandbox: %q, container: %q, path: %q", s.ID, containerID, path)
+	opts := control.CopyInOpts{
+		ContainerID: containerID,
+		Path:        path,
+		FilePayload: urpc.FilePayload{Files: []*os.File{inFD}},
+	}
+	if err := s.call(boot.FsCopyIn, &opts, nil); err != nil {
+		return fmt.Errorf("copying to %q in container: %w", path, err)
+	}
+	return nil
+}
+
+// AttachStdio replaces the destination of relayed output streams of a
+// container. args.Files are owned by the caller.
+func (s *Sandbox) AttachStdio(args *boot.AttachStdioArgs) error {
+	log.Debugf("AttachStdio, sandbox: %q, container: %q, streams: %v", s.ID, args.ContainerID, args.Streams)
+	if err := s.call(boot.ContMgrAttachStdio, args, nil); err != nil {
+		return fmt.Errorf("attaching stdio: %w", err)
+	}
+	return nil
+}
+
+// AttachExec attaches the terminal tty to the session of the detachable
+// process pid in container cid, until it is detached. It returns true if the
+// process may still be running. tty is owned by the caller.
+func (s *Sandbox) AttachExec(cid string, pid int32, tty *os.File, detachKeys []byte) (bool, error) {
+	log.Debugf("AttachExec, sandbox: %q, container: %q, pid: %d", s.ID, cid, pid)
+	args := boot.AttachExecArgs{
+		ContainerID: cid,
+		PID:         pid,
+		DetachKeys:  detachKeys,
+	}
+	args.Files = []*os.File{tty}
+	var running bool
+	if err := s.call(boot.ContMgrAttachExec, &args, &running); err != nil {
+		return false, fmt.Errorf("attaching to exec session of PID %d in container %q: %w", pid, cid, err)
+	}
+	return running, nil
+}
+
+// StreamOutput streams the relayed output of a container, framed by stream,
+// to the file in args.
+func (s *Sandbox) StreamOutput(args *boot.StreamOutputArgs) error {
+	log.Debugf("StreamOutput, sandbox: %q, container: %q, replay: %d, follow: %t", s.ID, args.ContainerID, args.ReplayBytes, args.Follow)
+	if err := s.call(boot.ContMgrStreamOutput, args, nil); err != nil {
+		return fmt.Errorf("streaming output: %w", err)
+	}
+	return nil
+}
+
 func setCloExeOnAllFDs() error {
 	f, err := os.Open("/proc/self/fd")
 	if err != nil {
@@ -488,8 +488,9 @@ func validateSpecForContainer(oSpec, nSpec *specs.Spec, cName string) error {
 	return nil
 }
 
-// Validate OCI specs before restoring the containers.
-func validateSpecs(oldSpecs, newSpecs map[string]*specs.Spec) error {
+// ValidateSpecs returns an error if any of newSpecs differs from the spec for
+// the same container in oldSpecs in a way that restore doesn't allow.
+func ValidateSpecs(oldSpecs, newSpecs map[string]*specs.Spec) error {
 	for cName, newSpec := range newSpecs {
 		oldSpec, ok := oldSpecs[cName]
 		if !ok {
@@ -510,12 +511,12 @@ func RestoreValidateSpec(oldSpecs, newSpecs map[string]*specs.Spec, conf *config
 		return nil
 	case config.RestoreSpecValidationWarning:
 		// Log a warning if the spec validation fails.
-		if err := validateSpecs(oldSpecs, newSpecs); err != nil {
+		if err := ValidateSpecs(oldSpecs, newSpecs); err != nil {
 			log.Warningf("Failed to validate restore spec (ignoring error as per configuration): %v", err)
 		}
 	case config.RestoreSpecValidationEnforce:
 		// Restoring containers will be aborted if spec validation fails.
-		if err := validateSpecs(oldSpecs, newSpecs); err != nil {
+		if err := ValidateSpecs(oldSpecs, newSpecs); err != nil {
 			return fmt.Errorf("failed to validate restore spec: %w", err)
 		}
 	default:
@@ -87,6 +87,24 @@ const (
 	// AnnotationCPUFeatures is the annotation used to control cpu features
 	// that exposed to user apps.
 	AnnotationCPUFeatures = "dev.gvisor.internal.cpufeatures"
+
+	// AnnotationNetworkPolicy sets the ingress and egress allowlist of a
+	// container, in JSON format. See boot.NetworkPolicy for details.
+	//
+	// Usage:
+	//	"dev.gvisor.net.policy": "{\"egress\": [{\"cidr\": \"10.0.0.0/8\", \"ports\": \"443\"}]}"
+	AnnotationNetworkPolicy = "dev.gvisor.net.policy"
+
+	// AnnotationSandboxPool makes a root container run in an idle sandbox from
+	// the named pool, which was booted ahead of time with "runsc pool fill".
+	// If the pool has no idle sandboxes whose sandbox-level settings (runsc
+	// flags, network namespace and gVisor annotations) match the container's,
+	// a new sandbox is created. The claimed sandbox is moved to the container's
+	// cgroup.
+	//
+	// Usage:
+	//	"dev.gvisor.sandbox-pool": "<pool name>"
+	AnnotationSandboxPool = "dev.gvisor.sandbox-pool"
 )
 
 // LINT.ThenChange(:Features)
@@ -709,6 +727,41 @@ func TPUFunctionalityRequested(spec *specs.Spec, conf *config.Config) bool {
 	return false
 }
 
+// HostDeviceProxied returns true if the sentry should proxy dev, a device from
+// the spec, to the corresponding host device. This is the case for character
+// devices when conf.HostDevices is set, except for GPU and TPU devices, which
+// are handled by nvproxy and tpuproxy, and for memory and TTY devices, which
+// are always implemented by the sentry.
+func HostDeviceProxied(conf *config.Config, dev *specs.LinuxDevice) bool {
+	if !conf.HostDevices || (dev.Type != "c" && dev.Type != "u") {
+		return false
+	}
+	if strings.HasPrefix(dev.Path, "/dev/nvidia") || AcceleratorFunctionalityRequested(dev) || VFIOFunctionalityRequested(dev) {
+		return false
+	}
+	switch dev.Major {
+	case linux.MEM_MAJOR, linux.TTYAUX_MAJOR:
+		return false
+	}
+	if dev.Major >= linux.UNIX98_PTY_MASTER_MAJOR && dev.Major < linux.UNIX98_PTY_REPLICA_MAJOR+linux.UNIX98_PTY_MAJOR_COUNT {
+		return false
+	}
+	return true
+}
+
+// HostDevicesRequested returns true if any device in the spec should be
+// proxied to the host (see HostDeviceProxied).
+func HostDevicesRequested(spec *specs.Spec, conf *config.Config) bool {
+	if spec.Linux != nil {
+		for _, dev := range spec.Linux.Devices {
+			if HostDeviceProxied(conf, &dev) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // SafeSetupAndMount creates the mount point and calls Mount with the given
 // flags. procPath is the path to procfs. If it is "", procfs is assumed to be
 // mounted at /proc.
@@ -906,6 +959,13 @@ func RootfsTarUpperPath(spec *specs.Spec) string {
 	return spec.Annotations[AnnotationRootfsUpperTar]
 }
 
+// SandboxPool returns the name of the sandbox pool specified by the
+// AnnotationSandboxPool annotation, and whether one was found.
+func SandboxPool(spec *specs.Spec) (string, bool) {
+	name, ok := spec.Annotations[AnnotationSandboxPool]
+	return name, ok && name != ""
+}
+
 // AnnotationToBool parses the annotation value as a bool. On failure, it logs a warning and
 // returns false.
 func AnnotationToBool(spec *specs.Spec, annotation string) bool {
@@ -990,6 +1050,8 @@ func Features() *features.Features {
 		annotations["dev.gvisor.internal.seccomp.cont"] = "RuntimeDefault"
 		annotations[AnnotationTPU] = ""
 		annotations[AnnotationCPUFeatures] = ""
+		annotations[AnnotationNetworkPolicy] = ""
+		annotations[AnnotationSandboxPool] = ""
 		// LINT.ThenChange(:features_annotations)
 		feat.Annotations = annotations
 
@@ -1007,6 +1069,7 @@ func Features() *features.Features {
 			"dev.gvisor.internal.seccomp.",
 			AnnotationTPU,
 			AnnotationCPUFeatures,
+			AnnotationSandboxPool,
 		)
 		// LINT.ThenChange(:features_annotations)
 
@@ -841,3 +841,73 @@ func TestTPUProxyEnabled(t *testing.T) {
 		})
 	}
 }
+
+func TestHostDeviceProxied(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		dev         specs.LinuxDevice
+		hostDevices bool
+		want        bool
+	}{
+		{
+			name:        "fuse",
+			dev:         specs.LinuxDevice{Path: "/dev/fuse", Type: "c", Major: 10, Minor: 229},
+			hostDevices: true,
+			want:        true,
+		},
+		{
+			name:        "tun",
+			dev:         specs.LinuxDevice{Path: "/dev/net/tun", Type: "c", Major: 10, Minor: 200},
+			hostDevices: true,
+			want:        true,
+		},
+		{
+			name: "disabled",
+			dev:  specs.LinuxDevice{Path: "/dev/fuse", Type: "c", Major: 10, Minor: 229},
+			want: false,
+		},
+		{
+			name:        "block",
+			dev:         specs.LinuxDevice{Path: "/dev/sda", Type: "b", Major: 8, Minor: 0},
+			hostDevices: true,
+			want:        false,
+		},
+		{
+			name:        "null",
+			dev:         specs.LinuxDevice{Path: "/dev/null", Type: "c", Major: 1, Minor: 3},
+			hostDevices: true,
+			want:        false,
+		},
+		{
+			name:        "ptmx",
+			dev:         specs.LinuxDevice{Path: "/dev/ptmx", Type: "c", Major: 5, Minor: 2},
+			hostDevices: true,
+			want:        false,
+		},
+		{
+			name:        "pts",
+			dev:         specs.LinuxDevice{Path: "/dev/pts/0", Type: "c", Major: 136, Minor: 0},
+			hostDevices: true,
+			want:        false,
+		},
+		{
+			name:        "nvidia",
+			dev:         specs.LinuxDevice{Path: "/dev/nvidiactl", Type: "c", Major: 195, Minor: 255},
+			hostDevices: true,
+			want:        false,
+		},
+		{
+			name:        "vfio",
+			dev:         specs.LinuxDevice{Path: "/dev/vfio/vfio", Type: "c", Major: 10, Minor: 196},
+			hostDevices: true,
+			want:        false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := config.Config{HostDevices: tc.hostDevices}
+			if got := HostDeviceProxied(&conf, &tc.dev); got != tc.want {
+				t.Errorf("HostDeviceProxied() got: %v, want: %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,421 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/subcommands"
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/runsc/flag"
+)
+
+// crChecker exercises a single subsystem for the cr-feature command.
+type crChecker interface {
+	// setup creates the kernel objects that are exercised by check. dir is a
+	// writable scratch directory.
+	setup(dir string) error
+
+	// check exercises the objects created by setup. It is called repeatedly,
+	// including after the sandbox has been checkpointed and restored.
+	check(iteration int) error
+}
+
+// crCheckers maps feature names to constructors of their checkers.
+var crCheckers = map[string]func() crChecker{
+	"epoll":    func() crChecker { return &crEpoll{} },
+	"timers":   func() crChecker { return &crTimers{} },
+	"unix":     func() crChecker { return &crUnix{} },
+	"netstack": func() crChecker { return &crNetstack{} },
+	"shm":      func() crChecker { return &crShm{} },
+	"inotify":  func() crChecker { return &crInotify{} },
+	"cgroupfs": func() crChecker { return &crCgroupfs{} },
+}
+
+// crFeatureNames returns the sorted list of features supported by cr-feature.
+func crFeatureNames() []string {
+	names := make([]string, 0, len(crCheckers))
+	for name := range crCheckers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// crFeature continuously exercises a subsystem and logs the result of each
+// iteration, so that tests can validate its behavior across
+// checkpoint/restore.
+type crFeature struct {
+	feature string
+	file    string
+	dir     string
+}
+
+// Name implements subcommands.Command.Name.
+func (*crFeature) Name() string {
+	return "cr-feature"
+}
+
+// Synopsis implements subcommands.Command.Synopsis.
+func (*crFeature) Synopsis() string {
+	return "exercises a subsystem continuously for checkpoint/restore tests"
+}
+
+// Usage implements subcommands.Command.Usage.
+func (*crFeature) Usage() string {
+	return fmt.Sprintf("cr-feature --feature={%s} --file=<path> --dir=<path>", strings.Join(crFeatureNames(), "|"))
+}
+
+// SetFlags implements subcommands.Command.SetFlags.
+func (c *crFeature) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&c.feature, "feature", "", "subsystem to exercise")
+	f.StringVar(&c.file, "file", "", "file for test output")
+	f.StringVar(&c.dir, "dir", "", "writable scratch directory")
+}
+
+// Execute implements subcommands.Command.Execute.
+func (c *crFeature) Execute(ctx context.Context, f *flag.FlagSet, args ...any) subcommands.ExitStatus {
+	if c.file == "" || c.dir == "" {
+		log.Fatalf("--file and --dir are required")
+	}
+	newChecker, ok := crCheckers[c.feature]
+	if !ok {
+		log.Fatalf("unknown feature %q, must be one of: %v", c.feature, crFeatureNames())
+	}
+	checker := newChecker()
+	if err := checker.setup(c.dir); err != nil {
+		c.logf("SETUP_FAILED: %v", err)
+		return subcommands.ExitFailure
+	}
+	c.logf("SETUP_DONE")
+	for i := 0; ; i++ {
+		if err := checker.check(i); err != nil {
+			c.logf("FAIL: iteration %d: %v", i, err)
+		} else {
+			c.logf("COUNT=%d", i)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// logf appends a line to the output file.
+func (c *crFeature) logf(format string, args ...any) {
+	f, err := os.OpenFile(c.file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		log.Fatalf("OpenFile(%q): %v", c.file, err)
+	}
+	defer f.Close()
+	fmt.Fprintf(f, format+"\n", args...)
+}
+
+// crEpoll checks that an eventfd registered with epoll keeps delivering
+// readiness events.
+type crEpoll struct {
+	epfd int
+	evfd int
+}
+
+func (e *crEpoll) setup(string) error {
+	var err error
+	if e.epfd, err = unix.EpollCreate1(0); err != nil {
+		return fmt.Errorf("epoll_create1: %w", err)
+	}
+	if e.evfd, err = unix.Eventfd(0, unix.EFD_NONBLOCK); err != nil {
+		return fmt.Errorf("eventfd: %w", err)
+	}
+	ev := unix.EpollEvent{Events: unix.EPOLLIN, Fd: int32(e.evfd)}
+	if err := unix.EpollCtl(e.epfd, unix.EPOLL_CTL_ADD, e.evfd, &ev); err != nil {
+		return fmt.Errorf("epoll_ctl: %w", err)
+	}
+	return nil
+}
+
+func (e *crEpoll) check(int) error {
+	events := make([]unix.EpollEvent, 1)
+	if n, err := unix.EpollWait(e.epfd, events, 0); err != nil || n != 0 {
+		return fmt.Errorf("epoll_wait before write: got (%d, %v), want (0, nil)", n, err)
+	}
+	var buf [8]byte
+	binary.NativeEndian.PutUint64(buf[:], 1)
+	if _, err := unix.Write(e.evfd, buf[:]); err != nil {
+		return fmt.Errorf("write(eventfd): %w", err)
+	}
+	n, err := unix.EpollWait(e.epfd, events, 1000)
+	if err != nil || n != 1 {
+		return fmt.Errorf("epoll_wait after write: got (%d, %v), want (1, nil)", n, err)
+	}
+	if events[0].Fd != int32(e.evfd) || events[0].Events&unix.EPOLLIN == 0 {
+		return fmt.Errorf("epoll_wait returned unexpected event %+v", events[0])
+	}
+	if _, err := unix.Read(e.evfd, buf[:]); err != nil {
+		return fmt.Errorf("read(eventfd): %w", err)
+	}
+	return nil
+}
+
+// crTimers checks that an armed periodic timerfd keeps expiring and that
+// CLOCK_MONOTONIC never goes backwards.
+type crTimers struct {
+	tfd  int
+	last unix.Timespec
+}
+
+func (t *crTimers) setup(string) error {
+	var err error
+	if t.tfd, err = unix.TimerfdCreate(unix.CLOCK_MONOTONIC, 0); err != nil {
+		return fmt.Errorf("timerfd_create: %w", err)
+	}
+	interval := unix.NsecToTimespec((10 * time.Millisecond).Nanoseconds())
+	spec := unix.ItimerSpec{Interval: interval, Value: interval}
+	if err := unix.TimerfdSettime(t.tfd, 0, &spec, nil); err != nil {
+		return fmt.Errorf("timerfd_settime: %w", err)
+	}
+	return unix.ClockGettime(unix.CLOCK_MONOTONIC, &t.last)
+}
+
+func (t *crTimers) check(int) error {
+	var buf [8]byte
+	if _, err := unix.Read(t.tfd, buf[:]); err != nil {
+		return fmt.Errorf("read(timerfd): %w", err)
+	}
+	if exp := binary.NativeEndian.Uint64(buf[:]); exp == 0 {
+		return fmt.Errorf("timerfd returned zero expirations")
+	}
+	var now unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &now); err != nil {
+		return fmt.Errorf("clock_gettime: %w", err)
+	}
+	if now.Nano() < t.last.Nano() {
+		return fmt.Errorf("CLOCK_MONOTONIC went backwards: %d -> %d", t.last.Nano(), now.Nano())
+	}
+	t.last = now
+	return nil
+}
+
+// crUnix checks that a connected unix socket pair keeps passing data.
+type crUnix struct {
+	fds [2]int
+}
+
+func (u *crUnix) setup(string) error {
+	var err error
+	u.fds, err = unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM, 0)
+	return err
+}
+
+func (u *crUnix) check(iteration int) error {
+	return pingPong(u.fds[0], u.fds[1], iteration)
+}
+
+// crNetstack checks that an established loopback TCP connection keeps passing
+// data.
+type crNetstack struct {
+	client net.Conn
+	server net.Conn
+}
+
+func (n *crNetstack) setup(string) error {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	defer l.Close()
+	if n.client, err = net.Dial("tcp", l.Addr().String()); err != nil {
+		return fmt.Errorf("dial: %w", err)
+	}
+	n.server, err = l.Accept()
+	return err
+}
+
+func (n *crNetstack) check(iteration int) error {
+	want := []byte(strconv.Itoa(iteration))
+	if _, err := n.client.Write(want); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	n.server.SetReadDeadline(time.Now().Add(5 * time.Second))
+	got := make([]byte, len(want))
+	if _, err := io.ReadFull(n.server, got); err != nil {
+		return fmt.Errorf("read: %w", err)
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("read %q, want %q", got, want)
+	}
+	return nil
+}
+
+// crShm checks that the contents of a System V shared memory segment are
+// preserved.
+type crShm struct {
+	mem []byte
+}
+
+func (s *crShm) setup(string) error {
+	id, err := unix.SysvShmGet(unix.IPC_PRIVATE, os.Getpagesize(), unix.IPC_CREAT|0600)
+	if err != nil {
+		return fmt.Errorf("shmget: %w", err)
+	}
+	if s.mem, err = unix.SysvShmAttach(id, 0, 0); err != nil {
+		return fmt.Errorf("shmat: %w", err)
+	}
+	// Mark the segment for removal; it stays alive while attached.
+	if _, err := unix.SysvShmCtl(id, unix.IPC_RMID, nil); err != nil {
+		return fmt.Errorf("shmctl(IPC_RMID): %w", err)
+	}
+	return nil
+}
+
+func (s *crShm) check(iteration int) error {
+	if iteration > 0 {
+		if got, want := binary.NativeEndian.Uint64(s.mem), uint64(iteration-1); got != want {
+			return fmt.Errorf("shm contains %d, want %d", got, want)
+		}
+	}
+	binary.NativeEndian.PutUint64(s.mem, uint64(iteration))
+	return nil
+}
+
+// crInotify checks that an inotify watch keeps reporting events.
+type crInotify struct {
+	dir string
+	fd  int
+}
+
+func (i *crInotify) setup(dir string) error {
+	i.dir = filepath.Join(dir, "inotify")
+	if err := os.Mkdir(i.dir, 0777); err != nil {
+		return err
+	}
+	var err error
+	if i.fd, err = unix.InotifyInit1(0); err != nil {
+		return fmt.Errorf("inotify_init1: %w", err)
+	}
+	if _, err := unix.InotifyAddWatch(i.fd, i.dir, unix.IN_CREATE); err != nil {
+		return fmt.Errorf("inotify_add_watch: %w", err)
+	}
+	return nil
+}
+
+func (i *crInotify) check(iteration int) error {
+	name := fmt.Sprintf("file%d", iteration)
+	path := filepath.Join(i.dir, name)
+	if err := os.WriteFile(path, nil, 0666); err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	pfd := []unix.PollFd{{Fd: int32(i.fd), Events: unix.POLLIN}}
+	if n, err := unix.Poll(pfd, 5000); err != nil || n != 1 {
+		return fmt.Errorf("poll(inotify): got (%d, %v), want (1, nil)", n, err)
+	}
+	buf := make([]byte, unix.SizeofInotifyEvent+unix.PathMax+1)
+	n, err := unix.Read(i.fd, buf)
+	if err != nil {
+		return fmt.Errorf("read(inotify): %w", err)
+	}
+	// The file created by this iteration must be reported. Events for
+	// earlier iterations must have been consumed already.
+	if got := inotifyNames(buf[:n]); len(got) != 1 || got[0] != name {
+		return fmt.Errorf("inotify reported %v, want [%s]", got, name)
+	}
+	return nil
+}
+
+// inotifyNames returns the names contained in a buffer of inotify events.
+func inotifyNames(buf []byte) []string {
+	var names []string
+	for len(buf) >= unix.SizeofInotifyEvent {
+		nameLen := int(binary.NativeEndian.Uint32(buf[12:16]))
+		end := unix.SizeofInotifyEvent + nameLen
+		if end > len(buf) {
+			break
+		}
+		names = append(names, string(bytes.TrimRight(buf[unix.SizeofInotifyEvent:end], "\x00")))
+		buf = buf[end:]
+	}
+	return names
+}
+
+// crCgroupfs checks that cgroup membership and control files are preserved.
+type crCgroupfs struct {
+	path string
+}
+
+// crCgroupName is the name of the cgroup created by crCgroupfs.
+const crCgroupName = "crtest"
+
+func (c *crCgroupfs) setup(string) error {
+	c.path = filepath.Join("/sys/fs/cgroup/cpu", crCgroupName)
+	if err := os.Mkdir(c.path, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(c.path, "cpu.shares"), []byte("512"), 0); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(c.path, "cgroup.procs"), []byte(strconv.Itoa(os.Getpid())), 0)
+}
+
+func (c *crCgroupfs) check(int) error {
+	b, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(string(b), "/"+crCgroupName) {
+		return fmt.Errorf("/proc/self/cgroup does not contain %q:\n%s", crCgroupName, b)
+	}
+	b, err = os.ReadFile(filepath.Join(c.path, "cpu.shares"))
+	if err != nil {
+		return err
+	}
+	if got := strings.TrimSpace(string(b)); got != "512" {
+		return fmt.Errorf("cpu.shares = %q, want 512", got)
+	}
+	return nil
+}
+
+// pingPong writes a message derived from iteration to wfd and checks that it
+// is read back unmodified from rfd.
+func pingPong(wfd, rfd, iteration int) error {
+	want := []byte(strconv.Itoa(iteration))
+	if _, err := unix.Write(wfd, want); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	got := make([]byte, len(want))
+	for read := 0; read < len(got); {
+		n, err := unix.Read(rfd, got[read:])
+		if err != nil {
+			return fmt.Errorf("read: %w", err)
+		}
+		if n == 0 {
+			return io.ErrUnexpectedEOF
+		}
+		read += n
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("read %q, want %q", got, want)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package difffuzz
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Mismatch is a call whose result differs between Linux and the sentry.
+type Mismatch struct {
+	// Index is the index of the call in the program.
+	Index     int
+	Native    Result
+	Sandboxed Result
+}
+
+// String implements fmt.Stringer.
+func (m Mismatch) String() string {
+	return fmt.Sprintf("call %d %s: native %s, sandboxed %s", m.Index, m.Native.Call, describe(m.Native), describe(m.Sandboxed))
+}
+
+func describe(r Result) string {
+	if r.Errno != "" {
+		return r.Errno
+	}
+	if r.Data != "" {
+		return fmt.Sprintf("%d [%s]", r.Ret, r.Data)
+	}
+	return fmt.Sprintf("%d", r.Ret)
+}
+
+// Diff compares the results of the same program executed natively and inside
+// of the sandbox.
+func Diff(native, sandboxed []Result) ([]Mismatch, error) {
+	if len(native) != len(sandboxed) {
+		return nil, fmt.Errorf("result count mismatch: native %d, sandboxed %d", len(native), len(sandboxed))
+	}
+	var ms []Mismatch
+	for i := range native {
+		if native[i] != sandboxed[i] {
+			ms = append(ms, Mismatch{Index: i, Native: native[i], Sandboxed: sandboxed[i]})
+		}
+	}
+	return ms, nil
+}
+
+// ExecutorArgs returns the arguments to pass to the executor binary to run
+// the program.
+func (p *Program) ExecutorArgs() []string {
+	return []string{"--program", hex.EncodeToString(p.Encode())}
+}
+
+// RunNative runs the program with the executor binary in a new user
+// namespace, where the executor runs as root like it does inside the sandbox.
+func RunNative(executor string, p *Program) ([]Result, error) {
+	cmd := exec.Command(executor, p.ExecutorArgs()...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: unix.CLONE_NEWUSER,
+		UidMappings: []syscall.SysProcIDMap{
+			{ContainerID: 0, HostID: os.Getuid(), Size: 1},
+		},
+		GidMappings: []syscall.SysProcIDMap{
+			{ContainerID: 0, HostID: os.Getgid(), Size: 1},
+		},
+	}
+	return runExecutor(cmd)
+}
+
+// RunSandboxed runs the program with the executor binary inside of runsc.
+func RunSandboxed(runsc, executor string, p *Program) ([]Result, error) {
+	args := []string{
+		"--rootless", "--network=none", "--TESTONLY-unsafe-nonroot",
+		"do", executor,
+	}
+	cmd := exec.Command(runsc, append(args, p.ExecutorArgs()...)...)
+	return runExecutor(cmd)
+}
+
+func runExecutor(cmd *exec.Cmd) ([]Result, error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("running %q: %v, stderr: %s", strings.Join(cmd.Args, " "), err, stderr.String())
+	}
+	var results []Result
+	if err := json.Unmarshal(out, &results); err != nil {
+		return nil, fmt.Errorf("parsing executor output %q: %v", out, err)
+	}
+	return results, nil
+}
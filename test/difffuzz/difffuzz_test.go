@@ -0,0 +1,116 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package difffuzz
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"gvisor.dev/gvisor/pkg/test/testutil"
+)
+
+// seeds are programs that cover every operation at least once.
+var seeds = [][]byte{
+	// openat(a, O_RDWR|O_CREAT); write; lseek(SEEK_SET); read; fstat; close.
+	{0, 0, 2, 0, 3, 0, 16, 'x', 4, 0, 32, 0, 2, 0, 63, 0, 9, 0, 0, 0, 1, 0, 0, 0},
+	// pipe2; write; read; close write end; read EOF.
+	{6, 0, 0, 0, 3, 1, 8, 'p', 2, 0, 8, 0, 1, 1, 0, 0, 2, 0, 8, 0},
+	// eventfd2; read; write; read; fcntl_getfl.
+	{7, 3, 0, 0, 2, 0, 8, 0, 3, 0, 8, 1, 2, 0, 8, 0, 13, 0, 0, 0},
+	// mkdirat(a); openat(a, O_DIRECTORY); renameat(a, b); unlinkat(b, AT_REMOVEDIR).
+	{10, 0, 0, 0, 0, 0, 6, 0, 12, 0, 1, 0, 11, 1, 1, 0},
+	// openat(b, O_RDWR|O_CREAT|O_EXCL) twice; ftruncate; dup; fstat.
+	{0, 1, 3, 0, 0, 1, 3, 0, 8, 0, 100, 0, 5, 0, 0, 0, 9, 1, 0, 0},
+	// socketpair(SOCK_SEQPACKET); write; shutdown; read.
+	{14, 2, 0, 0, 3, 0, 10, 's', 15, 0, 1, 0, 2, 1, 16, 0},
+}
+
+// FuzzDifferential executes fuzzer-generated programs natively and inside of
+// runsc, and fails on any difference in results.
+//
+// It can be run with `go test -fuzz=FuzzDifferential`; without -fuzz, only
+// the seed corpus is executed.
+func FuzzDifferential(f *testing.F) {
+	runsc, err := testutil.FindFile("runsc/runsc")
+	if err != nil {
+		f.Skipf("runsc not found: %v", err)
+	}
+	executor, err := testutil.FindFile("test/difffuzz/executor/executor")
+	if err != nil {
+		f.Fatalf("executor not found: %v", err)
+	}
+	if _, err := os.Stat("/proc/self/ns/user"); err != nil {
+		f.Skipf("user namespaces not supported: %v", err)
+	}
+
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		p := Decode(data)
+		if len(p.Calls) == 0 {
+			return
+		}
+		native, err := RunNative(executor, p)
+		if err != nil {
+			t.Fatalf("native execution of %s: %v", p, err)
+		}
+		sandboxed, err := RunSandboxed(runsc, executor, p)
+		if err != nil {
+			t.Fatalf("sandboxed execution of %s: %v", p, err)
+		}
+		ms, err := Diff(native, sandboxed)
+		if err != nil {
+			t.Fatalf("program %s: %v", p, err)
+		}
+		for _, m := range ms {
+			t.Errorf("program %s: %s", p, m)
+		}
+	})
+}
+
+func TestDecodeEncode(t *testing.T) {
+	for _, seed := range seeds {
+		p := Decode(seed)
+		if got := p.Encode(); !bytes.Equal(got, seed) {
+			t.Errorf("Decode(%v).Encode() = %v, want %v", seed, got, seed)
+		}
+	}
+
+	// Any input must decode, and op indexes must be in range.
+	p := Decode([]byte{255, 1, 2, 3, 254, 4, 5})
+	if len(p.Calls) != 1 {
+		t.Fatalf("Decode returned %d calls, want 1", len(p.Calls))
+	}
+	if p.Calls[0].Op >= len(ops) {
+		t.Errorf("op %d out of range", p.Calls[0].Op)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	native := []Result{{Call: "close(0, 0, 0)", Ret: -1, Errno: "EBADF"}, {Call: "dup(0, 0, 0)", Ret: 1}}
+	sandboxed := []Result{{Call: "close(0, 0, 0)", Ret: -1, Errno: "EBADF"}, {Call: "dup(0, 0, 0)", Ret: -1, Errno: "EMFILE"}}
+	ms, err := Diff(native, sandboxed)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if len(ms) != 1 || ms[0].Index != 1 {
+		t.Errorf("Diff returned %v, want a single mismatch at index 1", ms)
+	}
+	if _, err := Diff(native, sandboxed[:1]); err == nil {
+		t.Errorf("Diff with different lengths succeeded")
+	}
+}
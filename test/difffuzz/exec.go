@@ -0,0 +1,225 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package difffuzz
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// Result is the outcome of a single call.
+type Result struct {
+	// Call is the human-readable call.
+	Call string `json:"call"`
+
+	// Ret is the call return value. File descriptors are replaced by their
+	// handle index, since fd numbers differ between environments.
+	Ret int64 `json:"ret"`
+
+	// Errno is the name of the error returned by the call, if any.
+	Errno string `json:"errno,omitempty"`
+
+	// Data is any additional output of the call, e.g. the bytes read.
+	Data string `json:"data,omitempty"`
+}
+
+// state is the execution state of a program.
+type state struct {
+	// dirfd is the scratch directory all paths are relative to.
+	dirfd int
+
+	// handles are the file descriptors created by the program. Closed
+	// handles are set to -1.
+	handles []int
+}
+
+// fd returns the file descriptor for the handle selected by arg.
+func (s *state) fd(arg uint8) int {
+	if len(s.handles) == 0 {
+		return -1
+	}
+	return s.handles[int(arg)%len(s.handles)]
+}
+
+// add registers a new file descriptor and returns its handle index.
+func (s *state) add(fd int) int64 {
+	s.handles = append(s.handles, fd)
+	return int64(len(s.handles) - 1)
+}
+
+// op is an operation that can be part of a program.
+type op struct {
+	name string
+	fn   func(s *state, args [callSize - 1]uint8) (Result, error)
+}
+
+var (
+	names     = []string{"a", "b", "c", "d"}
+	openFlags = []int{
+		unix.O_RDONLY,
+		unix.O_WRONLY | unix.O_CREAT,
+		unix.O_RDWR | unix.O_CREAT,
+		unix.O_RDWR | unix.O_CREAT | unix.O_EXCL,
+		unix.O_RDWR | unix.O_TRUNC,
+		unix.O_WRONLY | unix.O_APPEND | unix.O_CREAT,
+		unix.O_RDONLY | unix.O_DIRECTORY,
+		unix.O_PATH,
+	}
+	sockTypes = []int{unix.SOCK_STREAM, unix.SOCK_DGRAM, unix.SOCK_SEQPACKET}
+)
+
+func name(arg uint8) string {
+	return names[int(arg)%len(names)]
+}
+
+// ops is the set of operations programs are made of. New operations must be
+// appended to keep existing corpus entries meaningful.
+var ops = []op{
+	{"openat", func(s *state, args [3]uint8) (Result, error) {
+		fd, err := unix.Openat(s.dirfd, name(args[0]), openFlags[int(args[1])%len(openFlags)]|unix.O_CLOEXEC, 0644)
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Ret: s.add(fd)}, nil
+	}},
+	{"close", func(s *state, args [3]uint8) (Result, error) {
+		if len(s.handles) == 0 {
+			return Result{}, unix.Close(-1)
+		}
+		i := int(args[0]) % len(s.handles)
+		err := unix.Close(s.handles[i])
+		s.handles[i] = -1
+		return Result{}, err
+	}},
+	{"read", func(s *state, args [3]uint8) (Result, error) {
+		buf := make([]byte, int(args[1])%64)
+		n, err := unix.Read(s.fd(args[0]), buf)
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Ret: int64(n), Data: hex.EncodeToString(buf[:n])}, nil
+	}},
+	{"write", func(s *state, args [3]uint8) (Result, error) {
+		buf := make([]byte, int(args[1])%64)
+		for i := range buf {
+			buf[i] = args[2] + byte(i)
+		}
+		n, err := unix.Write(s.fd(args[0]), buf)
+		return Result{Ret: int64(n)}, err
+	}},
+	{"lseek", func(s *state, args [3]uint8) (Result, error) {
+		off, err := unix.Seek(s.fd(args[0]), int64(args[1])-32, int(args[2])%3)
+		return Result{Ret: off}, err
+	}},
+	{"dup", func(s *state, args [3]uint8) (Result, error) {
+		fd, err := unix.Dup(s.fd(args[0]))
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Ret: s.add(fd)}, nil
+	}},
+	{"pipe2", func(s *state, args [3]uint8) (Result, error) {
+		var fds [2]int
+		flags := unix.O_CLOEXEC
+		if args[0]%2 == 1 {
+			flags |= unix.O_NONBLOCK
+		}
+		if err := unix.Pipe2(fds[:], flags); err != nil {
+			return Result{}, err
+		}
+		s.add(fds[0])
+		return Result{Ret: s.add(fds[1])}, nil
+	}},
+	{"eventfd2", func(s *state, args [3]uint8) (Result, error) {
+		flags := unix.EFD_CLOEXEC | unix.EFD_NONBLOCK
+		if args[1]%2 == 1 {
+			flags |= unix.EFD_SEMAPHORE
+		}
+		fd, err := unix.Eventfd(uint(args[0]), flags)
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Ret: s.add(fd)}, nil
+	}},
+	{"ftruncate", func(s *state, args [3]uint8) (Result, error) {
+		return Result{}, unix.Ftruncate(s.fd(args[0]), int64(args[1]))
+	}},
+	{"fstat", func(s *state, args [3]uint8) (Result, error) {
+		var st unix.Stat_t
+		if err := unix.Fstat(s.fd(args[0]), &st); err != nil {
+			return Result{}, err
+		}
+		// Only compare fields that don't depend on the environment.
+		return Result{Ret: st.Size, Data: fmt.Sprintf("mode=%#o nlink=%d", st.Mode, st.Nlink)}, nil
+	}},
+	{"mkdirat", func(s *state, args [3]uint8) (Result, error) {
+		return Result{}, unix.Mkdirat(s.dirfd, name(args[0]), 0755)
+	}},
+	{"unlinkat", func(s *state, args [3]uint8) (Result, error) {
+		flags := 0
+		if args[1]%2 == 1 {
+			flags = unix.AT_REMOVEDIR
+		}
+		return Result{}, unix.Unlinkat(s.dirfd, name(args[0]), flags)
+	}},
+	{"renameat", func(s *state, args [3]uint8) (Result, error) {
+		return Result{}, unix.Renameat(s.dirfd, name(args[0]), s.dirfd, name(args[1]))
+	}},
+	{"fcntl_getfl", func(s *state, args [3]uint8) (Result, error) {
+		fl, err := unix.FcntlInt(uintptr(s.fd(args[0])), unix.F_GETFL, 0)
+		return Result{Ret: int64(fl)}, err
+	}},
+	{"socketpair", func(s *state, args [3]uint8) (Result, error) {
+		fds, err := unix.Socketpair(unix.AF_UNIX, sockTypes[int(args[0])%len(sockTypes)]|unix.SOCK_CLOEXEC, 0)
+		if err != nil {
+			return Result{}, err
+		}
+		s.add(fds[0])
+		return Result{Ret: s.add(fds[1])}, nil
+	}},
+	{"shutdown", func(s *state, args [3]uint8) (Result, error) {
+		return Result{}, unix.Shutdown(s.fd(args[0]), int(args[1])%3)
+	}},
+}
+
+// Run executes the program with all paths relative to dirfd. It must only be
+// called from a dedicated process, since file descriptors created by the
+// program are not closed.
+func (p *Program) Run(dirfd int) []Result {
+	s := &state{dirfd: dirfd}
+	results := make([]Result, 0, len(p.Calls))
+	for _, c := range p.Calls {
+		r, err := ops[c.Op].fn(s, c.Args)
+		r.Call = c.String()
+		if err != nil {
+			r.Ret = -1
+			r.Errno = errnoName(err)
+			r.Data = ""
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func errnoName(err error) string {
+	if errno, ok := err.(unix.Errno); ok {
+		if name := unix.ErrnoName(errno); name != "" {
+			return name
+		}
+	}
+	return err.Error()
+}
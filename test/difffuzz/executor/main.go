@@ -0,0 +1,56 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary executor runs a difffuzz program and prints the results in JSON
+// format to stdout.
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/runsc/flag"
+	"gvisor.dev/gvisor/test/difffuzz"
+)
+
+var program = flag.String("program", "", "hex-encoded program to run")
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "executor: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	data, err := hex.DecodeString(*program)
+	if err != nil {
+		return fmt.Errorf("decoding program: %v", err)
+	}
+	dir, err := os.MkdirTemp("", "difffuzz")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	dirfd, err := unix.Open(dir, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("opening %q: %v", dir, err)
+	}
+	results := difffuzz.Decode(data).Run(dirfd)
+	return json.NewEncoder(os.Stdout).Encode(results)
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package difffuzz implements differential fuzzing of the sentry against
+// Linux.
+//
+// Fuzz inputs are decoded into a sequence of syscalls (a Program) that is
+// executed both natively in a user namespace and inside of runsc. The results
+// and errnos of each call are then compared, and any difference is reported
+// as a compatibility gap.
+//
+// Programs only operate on file descriptors they create and on files inside
+// of a scratch directory, so they are safe to run on the host.
+package difffuzz
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxCalls is the maximum number of calls in a program.
+const MaxCalls = 64
+
+// callSize is the number of input bytes consumed for each call.
+const callSize = 4
+
+// Call is a single syscall in a program. The meaning of the arguments depends
+// on the operation.
+type Call struct {
+	// Op is the index of the operation in ops.
+	Op int
+
+	// Args are the raw operation arguments.
+	Args [callSize - 1]uint8
+}
+
+// String implements fmt.Stringer.
+func (c Call) String() string {
+	return fmt.Sprintf("%s(%d, %d, %d)", ops[c.Op].name, c.Args[0], c.Args[1], c.Args[2])
+}
+
+// Program is a sequence of syscalls.
+type Program struct {
+	Calls []Call
+}
+
+// Decode decodes a fuzzer input into a program. Any input is a valid program;
+// trailing bytes that don't form a full call are ignored.
+func Decode(data []byte) *Program {
+	p := &Program{}
+	for len(data) >= callSize && len(p.Calls) < MaxCalls {
+		c := Call{Op: int(data[0]) % len(ops)}
+		copy(c.Args[:], data[1:callSize])
+		p.Calls = append(p.Calls, c)
+		data = data[callSize:]
+	}
+	return p
+}
+
+// Encode returns the canonical encoding of the program, which can be passed
+// to Decode.
+func (p *Program) Encode() []byte {
+	data := make([]byte, 0, len(p.Calls)*callSize)
+	for _, c := range p.Calls {
+		data = append(data, byte(c.Op))
+		data = append(data, c.Args[:]...)
+	}
+	return data
+}
+
+// String implements fmt.Stringer.
+func (p *Program) String() string {
+	calls := make([]string, 0, len(p.Calls))
+	for _, c := range p.Calls {
+		calls = append(calls, c.String())
+	}
+	return strings.Join(calls, "; ")
+}
@@ -203,6 +203,53 @@ TEST(EpollTest, EpollPwait2Timeout) {
   EXPECT_GT(ns_elapsed(begin, end), kTimeoutNs - 1);
 }
 
+TEST(EpollTest, EpollPwait2InvalidTimeout) {
+  auto epollfd = ASSERT_NO_ERRNO_AND_VALUE(NewEpollFD());
+  struct epoll_event result[kFDsPerEpoll];
+
+  struct timespec timeout = {};
+  SKIP_IF(!IsRunningOnGvisor() &&
+          test_epoll_pwait2(epollfd.get(), result, kFDsPerEpoll, &timeout,
+                            nullptr) < 0 &&
+          errno == ENOSYS);
+
+  timeout.tv_sec = -1;
+  EXPECT_THAT(test_epoll_pwait2(epollfd.get(), result, kFDsPerEpoll, &timeout,
+                                nullptr),
+              SyscallFailsWithErrno(EINVAL));
+
+  timeout.tv_sec = 0;
+  timeout.tv_nsec = 1000000000;
+  EXPECT_THAT(test_epoll_pwait2(epollfd.get(), result, kFDsPerEpoll, &timeout,
+                                nullptr),
+              SyscallFailsWithErrno(EINVAL));
+}
+
+TEST(EpollTest, EpollPwait2SubmillisecondTimeout) {
+  auto epollfd = ASSERT_NO_ERRNO_AND_VALUE(NewEpollFD());
+  struct epoll_event result[kFDsPerEpoll];
+
+  struct timespec timeout = {};
+  SKIP_IF(!IsRunningOnGvisor() &&
+          test_epoll_pwait2(epollfd.get(), result, kFDsPerEpoll, &timeout,
+                            nullptr) < 0 &&
+          errno == ENOSYS);
+
+  // A timeout shorter than a millisecond must not be rounded down to zero
+  // (returning without blocking) nor up to epoll_wait(2)'s resolution.
+  constexpr int kTimeoutNs = 100000;
+  const DisableSave ds;  // Timing-related.
+  struct timespec begin;
+  struct timespec end;
+  EXPECT_THAT(clock_gettime(CLOCK_MONOTONIC, &begin), SyscallSucceeds());
+  timeout.tv_nsec = kTimeoutNs;
+  ASSERT_THAT(RetryEINTR(test_epoll_pwait2)(epollfd.get(), result,
+                                            kFDsPerEpoll, &timeout, nullptr),
+              SyscallSucceedsWithValue(0));
+  EXPECT_THAT(clock_gettime(CLOCK_MONOTONIC, &end), SyscallSucceeds());
+  EXPECT_GE(ns_elapsed(begin, end), kTimeoutNs);
+}
+
 void* writer(void* arg) {
   int fd = *reinterpret_cast<int*>(arg);
   uint64_t tmp = 1;
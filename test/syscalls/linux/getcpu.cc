@@ -13,10 +13,14 @@
 // limitations under the License.
 
 #include <sched.h>
+#include <sys/syscall.h>
+#include <unistd.h>
 
 #include "gtest/gtest.h"
+#include "absl/strings/str_cat.h"
 #include "absl/time/clock.h"
 #include "absl/time/time.h"
+#include "test/util/fs_util.h"
 #include "test/util/test_util.h"
 
 namespace gvisor {
@@ -54,6 +58,33 @@ TEST(GetcpuTest, IsValidCpu) {
   }
 }
 
+// The NUMA node reported by getcpu(2) is the node that sysfs reports for the
+// CPU.
+TEST(GetcpuTest, NodeMatchesSysfs) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(Exists("/sys/devices/system/node")));
+
+  const int num_cpus = NumCPUs();
+  cpu_set_t orig_set;
+  ASSERT_THAT(sched_getaffinity(getpid(), sizeof(orig_set), &orig_set),
+              SyscallSucceeds());
+  for (int i = 0; i < num_cpus; i++) {
+    if (CPU_ISSET(i, &orig_set) == 0) continue;
+    cpu_set_t set = {};
+    CPU_SET(i, &set);
+    ASSERT_THAT(sched_setaffinity(getpid(), sizeof(set), &set),
+                SyscallSucceeds());
+    unsigned int cpu, node;
+    ASSERT_THAT(syscall(SYS_getcpu, &cpu, &node, nullptr), SyscallSucceeds());
+    EXPECT_TRUE(ASSERT_NO_ERRNO_AND_VALUE(
+        Exists(absl::StrCat("/sys/devices/system/cpu/cpu", cpu, "/node", node))))
+        << "cpu " << cpu << " node " << node;
+    EXPECT_TRUE(ASSERT_NO_ERRNO_AND_VALUE(
+        Exists(absl::StrCat("/sys/devices/system/node/node", node))));
+  }
+  ASSERT_THAT(sched_setaffinity(getpid(), sizeof(orig_set), &orig_set),
+              SyscallSucceeds());
+}
+
 }  // namespace
 
 }  // namespace testing
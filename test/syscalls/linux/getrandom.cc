@@ -12,11 +12,20 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+#include <elf.h>
+#include <string.h>
+#include <sys/auxv.h>
+#include <sys/mman.h>
 #include <sys/syscall.h>
 #include <sys/types.h>
 #include <unistd.h>
 
+#include <vector>
+
 #include "gtest/gtest.h"
+#include "test/util/file_descriptor.h"
+#include "test/util/memory_util.h"
+#include "test/util/multiprocess_util.h"
 #include "test/util/test_util.h"
 
 namespace gvisor {
@@ -57,6 +66,161 @@ TEST(GetrandomTest, IsRandom) {
   EXPECT_TRUE(SomeByteIsNonZero(random_bytes, n));
 }
 
+// vgetrandom_opaque_params from Linux's include/uapi/linux/random.h.
+struct VgetrandomOpaqueParams {
+  uint32_t size_of_opaque_state;
+  uint32_t mmap_prot;
+  uint32_t mmap_flags;
+  uint32_t reserved[13];
+};
+
+using VgetrandomFn = ssize_t (*)(void* buffer, size_t len, unsigned int flags,
+                                 void* opaque_state, size_t opaque_len);
+
+// VdsoSymbol returns the address of the named dynamic symbol of the VDSO, or
+// nullptr if it isn't found.
+void* VdsoSymbol(const char* name) {
+  uintptr_t const base = getauxval(AT_SYSINFO_EHDR);
+  if (!base) {
+    return nullptr;
+  }
+  auto const* ehdr = reinterpret_cast<const Elf64_Ehdr*>(base);
+  auto const* phdr = reinterpret_cast<const Elf64_Phdr*>(base + ehdr->e_phoff);
+  uintptr_t load_offset = 0;
+  bool found_load = false;
+  const Elf64_Dyn* dyn = nullptr;
+  for (int i = 0; i < ehdr->e_phnum; i++) {
+    if (phdr[i].p_type == PT_LOAD && !found_load) {
+      load_offset = base + phdr[i].p_offset - phdr[i].p_vaddr;
+      found_load = true;
+    } else if (phdr[i].p_type == PT_DYNAMIC) {
+      dyn = reinterpret_cast<const Elf64_Dyn*>(base + phdr[i].p_offset);
+    }
+  }
+  if (!found_load || !dyn) {
+    return nullptr;
+  }
+
+  const Elf64_Sym* symtab = nullptr;
+  const char* strtab = nullptr;
+  const Elf64_Word* hash = nullptr;
+  for (; dyn->d_tag != DT_NULL; dyn++) {
+    switch (dyn->d_tag) {
+      case DT_SYMTAB:
+        symtab =
+            reinterpret_cast<const Elf64_Sym*>(load_offset + dyn->d_un.d_ptr);
+        break;
+      case DT_STRTAB:
+        strtab =
+            reinterpret_cast<const char*>(load_offset + dyn->d_un.d_ptr);
+        break;
+      case DT_HASH:
+        hash = reinterpret_cast<const Elf64_Word*>(load_offset +
+                                                   dyn->d_un.d_ptr);
+        break;
+    }
+  }
+  if (!symtab || !strtab || !hash) {
+    return nullptr;
+  }
+
+  // The second word of the hash table is the number of symbols.
+  for (Elf64_Word i = 0; i < hash[1]; i++) {
+    if (symtab[i].st_shndx != SHN_UNDEF &&
+        strcmp(strtab + symtab[i].st_name, name) == 0) {
+      return reinterpret_cast<void*>(load_offset + symtab[i].st_value);
+    }
+  }
+  return nullptr;
+}
+
+VgetrandomFn VdsoGetrandom() {
+#if defined(__x86_64__)
+  return reinterpret_cast<VgetrandomFn>(VdsoSymbol("__vdso_getrandom"));
+#elif defined(__aarch64__)
+  return reinterpret_cast<VgetrandomFn>(VdsoSymbol("__kernel_getrandom"));
+#else
+  return nullptr;
+#endif
+}
+
+class VdsoGetrandomTest : public ::testing::Test {
+ protected:
+  void SetUp() override {
+    vgetrandom_ = VdsoGetrandom();
+    // The VDSO implements getrandom since Linux 6.11.
+    if (IsRunningOnGvisor()) {
+      ASSERT_NE(vgetrandom_, nullptr);
+    }
+    SKIP_IF(vgetrandom_ == nullptr);
+
+    ASSERT_EQ(vgetrandom_(nullptr, 0, 0, &params_, ~0UL), 0);
+    state_ = ASSERT_NO_ERRNO_AND_VALUE(
+        Mmap(nullptr, params_.size_of_opaque_state, params_.mmap_prot,
+             params_.mmap_flags, -1, 0));
+  }
+
+  ssize_t Getrandom(void* buffer, size_t len, unsigned int flags) {
+    return vgetrandom_(buffer, len, flags, state_.ptr(),
+                       params_.size_of_opaque_state);
+  }
+
+  VgetrandomFn vgetrandom_ = nullptr;
+  VgetrandomOpaqueParams params_ = {};
+  Mapping state_;
+};
+
+TEST_F(VdsoGetrandomTest, Params) {
+  EXPECT_GT(params_.size_of_opaque_state, 0u);
+  EXPECT_EQ(params_.mmap_prot, static_cast<uint32_t>(PROT_READ | PROT_WRITE));
+  EXPECT_NE(params_.mmap_flags & MAP_ANONYMOUS, 0u);
+}
+
+TEST_F(VdsoGetrandomTest, IsRandom) {
+  // Cover sizes below, within and beyond the buffered batch.
+  for (size_t len : {1, 16, 63, 64, 100, 257, 4096}) {
+    std::vector<char> a(len), b(len);
+    ASSERT_EQ(Getrandom(a.data(), len, 0), static_cast<ssize_t>(len));
+    ASSERT_EQ(Getrandom(b.data(), len, 0), static_cast<ssize_t>(len));
+    if (len >= 16) {
+      EXPECT_TRUE(SomeByteIsNonZero(a.data(), len));
+      EXPECT_NE(memcmp(a.data(), b.data(), len), 0);
+    }
+  }
+  EXPECT_EQ(Getrandom(nullptr, 0, 0), 0);
+}
+
+TEST_F(VdsoGetrandomTest, InvalidFlags) {
+  char buf[16];
+  EXPECT_EQ(Getrandom(buf, sizeof(buf), ~0U), -EINVAL);
+}
+
+TEST_F(VdsoGetrandomTest, ForkedChildDiverges) {
+  char parent[32];
+  ASSERT_EQ(Getrandom(parent, sizeof(parent), 0),
+            static_cast<ssize_t>(sizeof(parent)));
+
+  // The child's copy of the state is wiped, so the child must not produce
+  // the parent's next output.
+  int fds[2];
+  ASSERT_THAT(pipe(fds), SyscallSucceeds());
+  FileDescriptor rfd(fds[0]);
+  FileDescriptor wfd(fds[1]);
+  const auto rest = [&] {
+    char child[32];
+    TEST_CHECK(Getrandom(child, sizeof(child), 0) == sizeof(child));
+    TEST_CHECK(WriteFd(wfd.get(), child, sizeof(child)) == sizeof(child));
+  };
+  EXPECT_THAT(InForkedProcess(rest), IsPosixErrorOkAndHolds(0));
+
+  char child[32];
+  ASSERT_THAT(ReadFd(rfd.get(), child, sizeof(child)),
+              SyscallSucceedsWithValue(sizeof(child)));
+  ASSERT_EQ(Getrandom(parent, sizeof(parent), 0),
+            static_cast<ssize_t>(sizeof(parent)));
+  EXPECT_NE(memcmp(parent, child, sizeof(child)), 0);
+}
+
 }  // namespace
 
 }  // namespace testing
@@ -335,6 +335,55 @@ TEST(MadviseDontforkTest, DontforkAnonPrivate) {
   ExpectAllMappingBytes(mp3, 3);
 }
 
+TEST(MadviseWipeonforkTest, WipeonforkAnonPrivate) {
+  // Mmap three anonymous pages and MADV_WIPEONFORK the middle page.
+  Mapping m = ASSERT_NO_ERRNO_AND_VALUE(
+      MmapAnon(kPageSize * 3, PROT_READ | PROT_WRITE, MAP_PRIVATE));
+  const Mapping mp1 = Mapping(reinterpret_cast<void*>(m.addr()), kPageSize);
+  const Mapping mp2 =
+      Mapping(reinterpret_cast<void*>(m.addr() + kPageSize), kPageSize);
+  const Mapping mp3 =
+      Mapping(reinterpret_cast<void*>(m.addr() + 2 * kPageSize), kPageSize);
+  m.release();
+
+  memset(mp1.ptr(), 1, kPageSize);
+  memset(mp2.ptr(), 2, kPageSize);
+  memset(mp3.ptr(), 3, kPageSize);
+  ASSERT_THAT(madvise(mp2.ptr(), kPageSize, MADV_WIPEONFORK),
+              SyscallSucceeds());
+
+  const auto rest = [&] {
+    // The second page is mapped in the child, but zero-filled; the other
+    // pages are inherited as usual.
+    CheckAllMappingBytes(mp1, 1);
+    TEST_CHECK(IsMapped(mp2.addr()));
+    CheckAllMappingBytes(mp2, 0);
+    memset(mp2.ptr(), 12, kPageSize);
+    CheckAllMappingBytes(mp3, 3);
+  };
+  EXPECT_THAT(InForkedProcess(rest), IsPosixErrorOkAndHolds(0));
+
+  // The parent's mappings are unaffected.
+  ExpectAllMappingBytes(mp1, 1);
+  ExpectAllMappingBytes(mp2, 2);
+  ExpectAllMappingBytes(mp3, 3);
+
+  // After MADV_KEEPONFORK, the child inherits the page's contents again.
+  ASSERT_THAT(madvise(mp2.ptr(), kPageSize, MADV_KEEPONFORK),
+              SyscallSucceeds());
+  const auto keep = [&] { CheckAllMappingBytes(mp2, 2); };
+  EXPECT_THAT(InForkedProcess(keep), IsPosixErrorOkAndHolds(0));
+}
+
+TEST(MadviseWipeonforkTest, WipeonforkShared) {
+  Mapping const ms = ASSERT_NO_ERRNO_AND_VALUE(
+      MmapAnon(kPageSize, PROT_READ | PROT_WRITE, MAP_SHARED));
+  EXPECT_THAT(madvise(ms.ptr(), kPageSize, MADV_WIPEONFORK),
+              SyscallFailsWithErrno(EINVAL));
+  // MADV_KEEPONFORK is permitted for any mapping.
+  EXPECT_THAT(madvise(ms.ptr(), kPageSize, MADV_KEEPONFORK), SyscallSucceeds());
+}
+
 }  // namespace
 
 }  // namespace testing
@@ -18,6 +18,7 @@
 #include <linux/unistd.h>
 #include <string.h>
 #include <sys/mman.h>
+#include <sys/stat.h>
 #include <sys/syscall.h>
 
 #include <vector>
@@ -50,6 +51,8 @@ namespace {
 #define F_SEAL_SHRINK 0x0002
 #define F_SEAL_GROW 0x0004
 #define F_SEAL_WRITE 0x0008
+#define F_SEAL_FUTURE_WRITE 0x0010
+#define F_SEAL_EXEC 0x0020
 
 using ::gvisor::testing::IsTmpfs;
 using ::testing::StartsWith;
@@ -208,6 +211,22 @@ TEST(MemfdTest, SealGrowWithTruncate) {
   ASSERT_THAT(ftruncate(memfd.get(), kPageSize), SyscallFailsWithErrno(EPERM));
 }
 
+// F_SEAL_GROW prevents a memfd from being grown using fallocate.
+TEST(MemfdTest, SealGrowWithFallocate) {
+  const FileDescriptor memfd =
+      ASSERT_NO_ERRNO_AND_VALUE(MemfdCreate(kMemfdName, MFD_ALLOW_SEALING));
+  ASSERT_THAT(ftruncate(memfd.get(), kPageSize), SyscallSucceeds());
+  ASSERT_THAT(fcntl(memfd.get(), F_ADD_SEALS, F_SEAL_GROW), SyscallSucceeds());
+
+  EXPECT_THAT(fallocate(memfd.get(), 0, 0, 2 * kPageSize),
+              SyscallFailsWithErrno(EPERM));
+  EXPECT_THAT(fallocate(memfd.get(), 0, 0, kPageSize), SyscallSucceeds());
+
+  struct stat st;
+  ASSERT_THAT(fstat(memfd.get(), &st), SyscallSucceeds());
+  EXPECT_EQ(st.st_size, kPageSize);
+}
+
 // F_SEAL_GROW prevents a memfd from being grown using the write syscall.
 TEST(MemfdTest, SealGrowWithWrite) {
   const FileDescriptor memfd =
@@ -382,6 +401,121 @@ TEST(MemfdTest, SealWriteWithMmap) {
                        memfd.get(), 0));
 }
 
+// F_SEAL_FUTURE_WRITE prevents a memfd from being written to through a write
+// syscall.
+TEST(MemfdTest, SealFutureWriteWithWrite) {
+  const FileDescriptor memfd =
+      ASSERT_NO_ERRNO_AND_VALUE(MemfdCreate(kMemfdName, MFD_ALLOW_SEALING));
+  const std::vector<char> buf(kPageSize);
+  ASSERT_THAT(write(memfd.get(), buf.data(), buf.size()),
+              SyscallSucceedsWithValue(kPageSize));
+  ASSERT_THAT(fcntl(memfd.get(), F_ADD_SEALS, F_SEAL_FUTURE_WRITE),
+              SyscallSucceeds());
+  EXPECT_THAT(fcntl(memfd.get(), F_GET_SEALS),
+              SyscallSucceedsWithValue(F_SEAL_FUTURE_WRITE));
+
+  EXPECT_THAT(write(memfd.get(), buf.data(), 1), SyscallFailsWithErrno(EPERM));
+  EXPECT_THAT(pwrite(memfd.get(), buf.data(), 1, 0),
+              SyscallFailsWithErrno(EPERM));
+}
+
+// Unlike F_SEAL_WRITE, F_SEAL_FUTURE_WRITE can be added while writable shared
+// mappings exist, and those mappings can still be used to modify the memfd.
+// New writable shared mappings are rejected.
+TEST(MemfdTest, SealFutureWriteWithMmap) {
+  const FileDescriptor memfd =
+      ASSERT_NO_ERRNO_AND_VALUE(MemfdCreate(kMemfdName, MFD_ALLOW_SEALING));
+  ASSERT_THAT(ftruncate(memfd.get(), kPageSize), SyscallSucceeds());
+  const Mapping m = ASSERT_NO_ERRNO_AND_VALUE(Mmap(
+      nullptr, kPageSize, PROT_READ | PROT_WRITE, MAP_SHARED, memfd.get(), 0));
+  ASSERT_THAT(fcntl(memfd.get(), F_ADD_SEALS, F_SEAL_FUTURE_WRITE),
+              SyscallSucceeds());
+
+  // The existing mapping is still writable.
+  *static_cast<char*>(m.ptr()) = 'a';
+  char c = 0;
+  ASSERT_THAT(pread(memfd.get(), &c, 1, 0), SyscallSucceedsWithValue(1));
+  EXPECT_EQ(c, 'a');
+
+  // New writable shared mappings are rejected.
+  EXPECT_THAT(Mmap(nullptr, kPageSize, PROT_READ | PROT_WRITE, MAP_SHARED,
+                   memfd.get(), 0),
+              PosixErrorIs(EPERM, ::testing::_));
+
+  // Read-only shared mappings are allowed, but can't be made writable.
+  const Mapping ro = ASSERT_NO_ERRNO_AND_VALUE(
+      Mmap(nullptr, kPageSize, PROT_READ, MAP_SHARED, memfd.get(), 0));
+  EXPECT_EQ(*static_cast<char*>(ro.ptr()), 'a');
+  EXPECT_THAT(mprotect(ro.ptr(), kPageSize, PROT_READ | PROT_WRITE),
+              SyscallFailsWithErrno(EACCES));
+
+  // Private mappings are ok.
+  EXPECT_NO_ERRNO(Mmap(nullptr, kPageSize, PROT_READ | PROT_WRITE, MAP_PRIVATE,
+                       memfd.get(), 0));
+}
+
+// Writable shared mappings that existed before F_SEAL_FUTURE_WRITE was added
+// can still be copied by fork and moved by mremap.
+TEST(MemfdTest, SealFutureWriteExistingMappingForkAndMremap) {
+  const FileDescriptor memfd =
+      ASSERT_NO_ERRNO_AND_VALUE(MemfdCreate(kMemfdName, MFD_ALLOW_SEALING));
+  ASSERT_THAT(ftruncate(memfd.get(), kPageSize), SyscallSucceeds());
+  Mapping m = ASSERT_NO_ERRNO_AND_VALUE(Mmap(
+      nullptr, kPageSize, PROT_READ | PROT_WRITE, MAP_SHARED, memfd.get(), 0));
+  ASSERT_THAT(fcntl(memfd.get(), F_ADD_SEALS, F_SEAL_FUTURE_WRITE),
+              SyscallSucceeds());
+
+  // The child inherits the writable mapping and can write through it.
+  char* const p = static_cast<char*>(m.ptr());
+  const auto rest = [&] { *p = 'b'; };
+  EXPECT_THAT(InForkedProcess(rest), IsPosixErrorOkAndHolds(0));
+  EXPECT_EQ(*p, 'b');
+
+  // Moving the mapping keeps it writable.
+  Mapping dst =
+      ASSERT_NO_ERRNO_AND_VALUE(MmapAnon(kPageSize, PROT_NONE, MAP_PRIVATE));
+  void* const moved = mremap(m.ptr(), kPageSize, kPageSize,
+                             MREMAP_MAYMOVE | MREMAP_FIXED, dst.ptr());
+  ASSERT_NE(moved, MAP_FAILED) << "mremap failed: errno " << errno;
+  m.release();
+  dst.release();
+  const Mapping mm(moved, kPageSize);
+  *static_cast<char*>(mm.ptr()) = 'c';
+  char c = 0;
+  ASSERT_THAT(pread(memfd.get(), &c, 1, 0), SyscallSucceedsWithValue(1));
+  EXPECT_EQ(c, 'c');
+}
+
+// Unknown seals are rejected.
+TEST(MemfdTest, UnknownSeal) {
+  const FileDescriptor memfd =
+      ASSERT_NO_ERRNO_AND_VALUE(MemfdCreate(kMemfdName, MFD_ALLOW_SEALING));
+  EXPECT_THAT(fcntl(memfd.get(), F_ADD_SEALS, 0x1000),
+              SyscallFailsWithErrno(EINVAL));
+  EXPECT_THAT(fcntl(memfd.get(), F_GET_SEALS), SyscallSucceedsWithValue(0));
+}
+
+// F_SEAL_EXEC prevents changes to a memfd's exec bits.
+TEST(MemfdTest, SealExec) {
+  // F_SEAL_EXEC was added in Linux 6.3.
+  if (!IsRunningOnGvisor()) {
+    auto version = ASSERT_NO_ERRNO_AND_VALUE(GetKernelVersion());
+    SKIP_IF(version.major < 6 || (version.major == 6 && version.minor < 3));
+  }
+
+  const FileDescriptor memfd =
+      ASSERT_NO_ERRNO_AND_VALUE(MemfdCreate(kMemfdName, MFD_ALLOW_SEALING));
+  struct stat st;
+  ASSERT_THAT(fstat(memfd.get(), &st), SyscallSucceeds());
+  const mode_t mode = st.st_mode & 07777;
+  ASSERT_THAT(fcntl(memfd.get(), F_ADD_SEALS, F_SEAL_EXEC), SyscallSucceeds());
+
+  EXPECT_THAT(fchmod(memfd.get(), mode ^ 0111), SyscallFailsWithErrno(EPERM));
+  EXPECT_THAT(fchmod(memfd.get(), mode ^ 0002), SyscallSucceeds());
+  ASSERT_THAT(fstat(memfd.get(), &st), SyscallSucceeds());
+  EXPECT_EQ(st.st_mode & 07777, mode ^ 0002);
+}
+
 // Adding F_SEAL_WRITE fails when there are outstanding writable mappings to a
 // memfd.
 TEST(MemfdTest, SealWriteWithOutstandingWritbleMapping) {
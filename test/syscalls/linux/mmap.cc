@@ -385,6 +385,30 @@ TEST_F(MMapTest, MapFixed64) {
 }
 #endif
 
+#if defined(__x86_64__)
+// Addresses above 47 bits are only returned to mappings that ask for them,
+// and only if the CPU supports 5-level paging. Either way, a mapping made
+// with a high hint address must be usable.
+TEST_F(MMapTest, HighHintAddress) {
+  constexpr uintptr_t kDefaultWindow = uintptr_t{1} << 47;
+
+  uintptr_t low = 0;
+  ASSERT_THAT(low = Map(0, kPageSize, PROT_READ | PROT_WRITE,
+                        MAP_PRIVATE | MAP_ANONYMOUS, -1, 0),
+              SyscallSucceeds());
+  EXPECT_LT(low, kDefaultWindow);
+  ASSERT_THAT(Unmap(), SyscallSucceeds());
+
+  uintptr_t addr = 0;
+  ASSERT_THAT(addr = Map(uintptr_t{1} << 50, kPageSize, PROT_READ | PROT_WRITE,
+                         MAP_PRIVATE | MAP_ANONYMOUS, -1, 0),
+              SyscallSucceeds());
+  volatile char* p = reinterpret_cast<volatile char*>(addr);
+  *p = 42;
+  EXPECT_EQ(*p, 42);
+}
+#endif
+
 // MAP_STACK allowed.
 // There isn't a good way to verify it did anything.
 TEST_F(MMapTest, MapStack) {
@@ -1788,6 +1812,107 @@ TEST(MMapNoFixtureTest, MapReadOnlyAfterCreateWriteOnly) {
             static_cast<char*>(mapping.endptr()), buf.data());
 }
 
+// Read-only private mappings of a file with separate read-only and write-only
+// FDs should reflect writes through the write-only FD.
+TEST(MMapNoFixtureTest, MapPrivateReadOnlyCoherentWithWriteOnlyFd) {
+  std::string filename = NewTempAbsPath();
+
+  auto const ro_fd = ASSERT_NO_ERRNO_AND_VALUE(
+      Open(filename, O_RDONLY | O_CREAT | O_EXCL, 0666));
+  auto const wo_fd = ASSERT_NO_ERRNO_AND_VALUE(Open(filename, O_WRONLY));
+  std::string bufA(kPageSize, 'a');
+  ASSERT_THAT(PwriteFd(wo_fd.get(), bufA.c_str(), bufA.size(), 0),
+              SyscallSucceedsWithValue(bufA.size()));
+
+  auto maybe_mapping =
+      Mmap(nullptr, kPageSize, PROT_READ, MAP_PRIVATE, ro_fd.get(), 0);
+  // Does FS support mmap?
+  SKIP_IF(maybe_mapping.error().errno_value() == ENODEV);
+  auto const mapping = ASSERT_NO_ERRNO_AND_VALUE(std::move(maybe_mapping));
+  EXPECT_EQ(0, memcmp(mapping.ptr(), bufA.c_str(), kPageSize));
+
+  // The mapping has not been written to, so it should observe the write.
+  std::string bufB(kPageSize, 'b');
+  ASSERT_THAT(PwriteFd(wo_fd.get(), bufB.c_str(), bufB.size(), 0),
+              SyscallSucceedsWithValue(bufB.size()));
+  EXPECT_EQ(0, memcmp(mapping.ptr(), bufB.c_str(), kPageSize));
+}
+
+TEST(MMapNoFixtureTest, MapFixedNoReplace) {
+  Mapping const m = ASSERT_NO_ERRNO_AND_VALUE(
+      MmapAnon(2 * kPageSize, PROT_READ | PROT_WRITE, MAP_PRIVATE));
+  memset(m.ptr(), 'a', kPageSize);
+  void* const second = reinterpret_cast<void*>(m.addr() + kPageSize);
+  ASSERT_THAT(munmap(second, kPageSize), SyscallSucceeds());
+
+  // Ranges that overlap an existing mapping are rejected, even if MAP_FIXED
+  // is also specified, and the existing mapping is left intact.
+  EXPECT_THAT(Mmap(m.ptr(), 2 * kPageSize, PROT_READ,
+                   MAP_PRIVATE | MAP_ANONYMOUS | MAP_FIXED_NOREPLACE, -1, 0),
+              PosixErrorIs(EEXIST, ::testing::_));
+  EXPECT_THAT(
+      Mmap(m.ptr(), kPageSize, PROT_READ,
+           MAP_PRIVATE | MAP_ANONYMOUS | MAP_FIXED | MAP_FIXED_NOREPLACE, -1,
+           0),
+      PosixErrorIs(EEXIST, ::testing::_));
+  EXPECT_EQ(*static_cast<char*>(m.ptr()), 'a');
+
+  // Free ranges are mapped at exactly the requested address. m will unmap
+  // the new mapping on destruction.
+  Mapping n = ASSERT_NO_ERRNO_AND_VALUE(
+      Mmap(second, kPageSize, PROT_READ,
+           MAP_PRIVATE | MAP_ANONYMOUS | MAP_FIXED_NOREPLACE, -1, 0));
+  EXPECT_EQ(n.ptr(), second);
+  n.release();
+
+  // Unaligned addresses are rejected as for MAP_FIXED.
+  EXPECT_THAT(Mmap(reinterpret_cast<void*>(m.addr() + 1), kPageSize, PROT_READ,
+                   MAP_PRIVATE | MAP_ANONYMOUS | MAP_FIXED_NOREPLACE, -1, 0),
+              PosixErrorIs(EINVAL, ::testing::_));
+}
+
+#ifndef MAP_DROPPABLE
+#define MAP_DROPPABLE 0x08
+#endif
+
+TEST(MMapNoFixtureTest, MapDroppable) {
+  auto m = Mmap(nullptr, kPageSize, PROT_READ | PROT_WRITE,
+                MAP_DROPPABLE | MAP_ANONYMOUS, -1, 0);
+  // MAP_DROPPABLE is supported since Linux 6.11.
+  SKIP_IF(!IsRunningOnGvisor() && !m.ok());
+  Mapping const mapping = ASSERT_NO_ERRNO_AND_VALUE(std::move(m));
+  memset(mapping.ptr(), 'a', kPageSize);
+
+  // Droppable memory is wiped in the child.
+  const auto rest = [&] {
+    TEST_CHECK(*static_cast<char*>(mapping.ptr()) == 0);
+  };
+  EXPECT_THAT(InForkedProcess(rest), IsPosixErrorOkAndHolds(0));
+  EXPECT_EQ(*static_cast<char*>(mapping.ptr()), 'a');
+}
+
+TEST(MMapNoFixtureTest, MapDroppableInvalid) {
+  SKIP_IF(!IsRunningOnGvisor() &&
+          !Mmap(nullptr, kPageSize, PROT_READ, MAP_DROPPABLE | MAP_ANONYMOUS,
+                -1, 0)
+               .ok());
+
+  // MAP_DROPPABLE is a mapping type; it can't be combined with the others.
+  EXPECT_THAT(Mmap(nullptr, kPageSize, PROT_READ,
+                   MAP_DROPPABLE | MAP_PRIVATE | MAP_ANONYMOUS, -1, 0),
+              PosixErrorIs(EINVAL, ::testing::_));
+  EXPECT_THAT(Mmap(nullptr, kPageSize, PROT_READ,
+                   MAP_DROPPABLE | MAP_SHARED | MAP_ANONYMOUS, -1, 0),
+              PosixErrorIs(EINVAL, ::testing::_));
+
+  // File mappings can't be droppable.
+  const FileDescriptor fd =
+      ASSERT_NO_ERRNO_AND_VALUE(Open("/dev/zero", O_RDONLY));
+  EXPECT_THAT(
+      Mmap(nullptr, kPageSize, PROT_READ, MAP_DROPPABLE, fd.get(), 0),
+      PosixErrorIs(EINVAL, ::testing::_));
+}
+
 // Conditional on MAP_32BIT.
 // This flag is supported only on x86-64, for 64-bit programs.
 #ifdef __x86_64__
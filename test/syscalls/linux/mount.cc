@@ -705,8 +705,45 @@ TEST(MountTest, MountMoveSuccess) {
               IsPosixErrorOkAndHolds(true));
 }
 
-// TODO(b/305893463): When support for MS_UNBINDABLE is added, moving a mount
-// tree with unbindable children to an MS_SHARED destination should EINVAL.
+TEST(MountTest, MountMoveUnbindableToShared) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
+
+  auto const parent = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+  auto const parent_mnt = ASSERT_NO_ERRNO_AND_VALUE(
+      Mount("source", parent.path(), kTmpfs, 0, "", 0));
+  auto const src =
+      ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDirIn(parent.path()));
+  auto const dst =
+      ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDirIn(parent.path()));
+  auto const src_mnt =
+      ASSERT_NO_ERRNO_AND_VALUE(Mount("source", src.path(), kTmpfs, 0, "", 0));
+  auto const child =
+      ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDirIn(src.path()));
+  auto const child_mnt = ASSERT_NO_ERRNO_AND_VALUE(
+      Mount("source", child.path(), kTmpfs, 0, "", 0));
+  ASSERT_THAT(mount("", child.path().c_str(), "", MS_UNBINDABLE, nullptr),
+              SyscallSucceeds());
+
+  auto const shared = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+  auto const shared_mnt = ASSERT_NO_ERRNO_AND_VALUE(
+      Mount("source", shared.path(), kTmpfs, 0, "", 0));
+  ASSERT_THAT(mount("", shared.path().c_str(), "", MS_SHARED, nullptr),
+              SyscallSucceeds());
+  auto const target =
+      ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDirIn(shared.path()));
+
+  EXPECT_THAT(mount(src.path().c_str(), target.path().c_str(), "", MS_MOVE,
+                    nullptr),
+              SyscallFailsWithErrno(EINVAL));
+
+  // Moving to a private destination is allowed.
+  EXPECT_THAT(
+      mount(src.path().c_str(), dst.path().c_str(), "", MS_MOVE, nullptr),
+      SyscallSucceeds());
+  EXPECT_THAT(
+      mount(dst.path().c_str(), src.path().c_str(), "", MS_MOVE, nullptr),
+      SyscallSucceeds());
+}
 
 TEST(MountTest, MountMoveSharedParent) {
   SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
@@ -1396,6 +1433,69 @@ TEST(MountTest, ArgumentsAreIgnored) {
   EXPECT_NE(optionals[dir.path()][0].shared, 0);
 }
 
+TEST(MountTest, MakeUnbindable) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
+
+  auto const dir = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+  auto const mnt =
+      ASSERT_NO_ERRNO_AND_VALUE(Mount("", dir.path(), kTmpfs, 0, "", 0));
+  ASSERT_THAT(mount("", dir.path().c_str(), "", MS_SHARED, 0),
+              SyscallSucceeds());
+  ASSERT_THAT(mount("", dir.path().c_str(), "", MS_UNBINDABLE, 0),
+              SyscallSucceeds());
+
+  auto optionals = ASSERT_NO_ERRNO_AND_VALUE(MountOptionals());
+  ASSERT_FALSE(optionals[dir.path()].empty());
+  EXPECT_EQ(optionals[dir.path()][0].shared, 0);
+  EXPECT_TRUE(optionals[dir.path()][0].unbindable);
+
+  // Making the mount private clears MS_UNBINDABLE.
+  ASSERT_THAT(mount("", dir.path().c_str(), "", MS_PRIVATE, 0),
+              SyscallSucceeds());
+  optionals = ASSERT_NO_ERRNO_AND_VALUE(MountOptionals());
+  ASSERT_FALSE(optionals[dir.path()].empty());
+  EXPECT_FALSE(optionals[dir.path()][0].unbindable);
+}
+
+TEST(MountTest, BindUnbindableFails) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
+
+  auto const dir = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+  auto const mnt =
+      ASSERT_NO_ERRNO_AND_VALUE(Mount("", dir.path(), kTmpfs, 0, "", 0));
+  ASSERT_THAT(mount("", dir.path().c_str(), "", MS_UNBINDABLE, 0),
+              SyscallSucceeds());
+
+  auto const target = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+  EXPECT_THAT(
+      mount(dir.path().c_str(), target.path().c_str(), "", MS_BIND, nullptr),
+      SyscallFailsWithErrno(EINVAL));
+}
+
+TEST(MountTest, RecursiveBindSkipsUnbindable) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
+
+  auto const a = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+  auto const a_mnt =
+      ASSERT_NO_ERRNO_AND_VALUE(Mount("", a.path(), kTmpfs, 0, "", 0));
+  auto const b = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDirIn(a.path()));
+  auto const b_mnt =
+      ASSERT_NO_ERRNO_AND_VALUE(Mount("", b.path(), kTmpfs, 0, "", 0));
+  ASSERT_NO_ERRNO(CreateWithContents(JoinPath(b.path(), "foo"), "bar", 0666));
+  ASSERT_THAT(mount("", b.path().c_str(), "", MS_UNBINDABLE, 0),
+              SyscallSucceeds());
+
+  auto const c = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+  auto const c_mnt = ASSERT_NO_ERRNO_AND_VALUE(
+      Mount(a.path(), c.path(), "", MS_BIND | MS_REC, "", MNT_DETACH));
+
+  // The unbindable submount is not copied, so its mountpoint in the copy is
+  // the empty directory underneath it.
+  const std::string copy = JoinPath(c.path(), Basename(b.path()));
+  EXPECT_THAT(Exists(copy), IsPosixErrorOkAndHolds(true));
+  EXPECT_THAT(Exists(JoinPath(copy, "foo")), IsPosixErrorOkAndHolds(false));
+}
+
 TEST(MountTest, MultiplePropagationFlagsFails) {
   SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
 
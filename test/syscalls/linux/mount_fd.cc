@@ -31,6 +31,7 @@
 #include "test/util/cleanup.h"
 #include "test/util/fs_util.h"
 #include "test/util/linux_capability_util.h"
+#include "test/util/mount_util.h"
 #include "test/util/posix_error.h"
 #include "test/util/temp_path.h"
 #include "test/util/test_util.h"
@@ -70,6 +71,20 @@
 #define MOUNT_ATTR_NODIRATIME 0x00000080
 #endif
 
+#ifndef MOUNT_ATTR_IDMAP
+#define MOUNT_ATTR_IDMAP 0x00100000
+#endif
+
+#ifndef MOUNT_ATTR_SIZE_VER0
+#define MOUNT_ATTR_SIZE_VER0 32
+struct mount_attr {
+  uint64_t attr_set;
+  uint64_t attr_clr;
+  uint64_t propagation;
+  uint64_t userns_fd;
+};
+#endif
+
 #ifndef MOVE_MOUNT_F_SYMLINKS
 #define MOVE_MOUNT_F_SYMLINKS 0x00000001
 #define MOVE_MOUNT_F_AUTOMOUNTS 0x00000002
@@ -98,6 +113,7 @@
 #define SYS_fsconfig 431
 #define SYS_fsmount 432
 #define SYS_fspick 433
+#define SYS_mount_setattr 442
 #elif defined(__aarch64__)
 #define SYS_open_tree 428
 #define SYS_move_mount 429
@@ -105,6 +121,7 @@
 #define SYS_fsconfig 431
 #define SYS_fsmount 432
 #define SYS_fspick 433
+#define SYS_mount_setattr 442
 #else
 #error "Unknown architecture"
 #endif
@@ -137,6 +154,10 @@ inline int move_mount(int from_dirfd, const char* from_pathname, int to_dirfd,
 inline int open_tree(int dirfd, const char* pathname, unsigned int flags) {
   return syscall(SYS_open_tree, dirfd, pathname, flags);
 }
+inline int mount_setattr(int dirfd, const char* pathname, unsigned int flags,
+                         struct mount_attr* attr, size_t size) {
+  return syscall(SYS_mount_setattr, dirfd, pathname, flags, attr, size);
+}
 
 namespace gvisor {
 namespace testing {
@@ -952,6 +973,197 @@ TEST(OpenTreeTest, OpenTreeCloneRecursiveOnDetached) {
   EXPECT_THAT(faccessat(treefd, "sub/marker_sub", F_OK, 0), SyscallSucceeds());
 }
 
+// mount_setattr(2) tests
+
+TEST(MountSetattrTest, InvalidArguments) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
+
+  auto const dir = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+  auto const mount = ASSERT_NO_ERRNO_AND_VALUE(
+      Mount("", dir.path(), kTmpfs, 0, "", 0 /* umountflags */));
+
+  struct mount_attr attr = {};
+  attr.attr_set = MOUNT_ATTR_RDONLY;
+  EXPECT_THAT(mount_setattr(AT_FDCWD, dir.path().c_str(), 1u << 30, &attr,
+                            sizeof(attr)),
+              SyscallFailsWithErrno(EINVAL));
+  EXPECT_THAT(mount_setattr(AT_FDCWD, dir.path().c_str(), 0, &attr,
+                            MOUNT_ATTR_SIZE_VER0 - 1),
+              SyscallFailsWithErrno(EINVAL));
+
+  // A larger struct is accepted only if the unknown tail is zeroed.
+  char buf[MOUNT_ATTR_SIZE_VER0 + 8] = {};
+  memcpy(buf, &attr, sizeof(attr));
+  buf[MOUNT_ATTR_SIZE_VER0] = 1;
+  EXPECT_THAT(
+      mount_setattr(AT_FDCWD, dir.path().c_str(), 0,
+                    reinterpret_cast<struct mount_attr*>(buf), sizeof(buf)),
+      SyscallFailsWithErrno(E2BIG));
+
+  // More than one propagation type.
+  attr = {};
+  attr.propagation = MS_SHARED | MS_PRIVATE;
+  EXPECT_THAT(mount_setattr(AT_FDCWD, dir.path().c_str(), 0, &attr,
+                            sizeof(attr)),
+              SyscallFailsWithErrno(EINVAL));
+
+  // Changing the atime mode requires clearing MOUNT_ATTR__ATIME.
+  attr = {};
+  attr.attr_set = MOUNT_ATTR_NOATIME;
+  EXPECT_THAT(mount_setattr(AT_FDCWD, dir.path().c_str(), 0, &attr,
+                            sizeof(attr)),
+              SyscallFailsWithErrno(EINVAL));
+  attr.attr_clr = MOUNT_ATTR_NOATIME;
+  EXPECT_THAT(mount_setattr(AT_FDCWD, dir.path().c_str(), 0, &attr,
+                            sizeof(attr)),
+              SyscallFailsWithErrno(EINVAL));
+
+  // Idmapped mounts can't be cleared.
+  attr = {};
+  attr.attr_clr = MOUNT_ATTR_IDMAP;
+  EXPECT_THAT(mount_setattr(AT_FDCWD, dir.path().c_str(), 0, &attr,
+                            sizeof(attr)),
+              SyscallFailsWithErrno(EINVAL));
+}
+
+TEST(MountSetattrTest, NotMountRoot) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
+
+  auto const dir = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+  auto const mount = ASSERT_NO_ERRNO_AND_VALUE(
+      Mount("", dir.path(), kTmpfs, 0, "", 0 /* umountflags */));
+  auto const subdir =
+      ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDirIn(dir.path()));
+
+  struct mount_attr attr = {};
+  attr.attr_set = MOUNT_ATTR_RDONLY;
+  EXPECT_THAT(mount_setattr(AT_FDCWD, subdir.path().c_str(), 0, &attr,
+                            sizeof(attr)),
+              SyscallFailsWithErrno(EINVAL));
+}
+
+TEST(MountSetattrTest, RequiresCapSysAdmin) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
+
+  auto const dir = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+  auto const mount = ASSERT_NO_ERRNO_AND_VALUE(
+      Mount("", dir.path(), kTmpfs, 0, "", 0 /* umountflags */));
+
+  ScopedThread([&]() {
+    EXPECT_NO_ERRNO(SetCapability(CAP_SYS_ADMIN, false));
+    struct mount_attr attr = {};
+    attr.attr_set = MOUNT_ATTR_RDONLY;
+    EXPECT_THAT(mount_setattr(AT_FDCWD, dir.path().c_str(), 0, &attr,
+                              sizeof(attr)),
+                SyscallFailsWithErrno(EPERM));
+  });
+}
+
+TEST(MountSetattrTest, SetAndClearAttributes) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
+
+  auto const dir = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+  auto const mount = ASSERT_NO_ERRNO_AND_VALUE(
+      Mount("", dir.path(), kTmpfs, 0, "", 0 /* umountflags */));
+  const std::string file = JoinPath(dir.path(), "file");
+
+  struct mount_attr attr = {};
+  attr.attr_set = MOUNT_ATTR_RDONLY | MOUNT_ATTR_NOEXEC;
+  ASSERT_THAT(mount_setattr(AT_FDCWD, dir.path().c_str(), 0, &attr,
+                            sizeof(attr)),
+              SyscallSucceeds());
+
+  struct statfs st;
+  ASSERT_THAT(statfs(dir.path().c_str(), &st), SyscallSucceeds());
+  EXPECT_EQ(st.f_flags & (ST_NOEXEC | ST_RDONLY), ST_NOEXEC | ST_RDONLY);
+  EXPECT_THAT(open(file.c_str(), O_CREAT | O_RDWR, 0644),
+              SyscallFailsWithErrno(EROFS));
+
+  attr = {};
+  attr.attr_clr = MOUNT_ATTR_RDONLY;
+  ASSERT_THAT(mount_setattr(AT_FDCWD, dir.path().c_str(), 0, &attr,
+                            sizeof(attr)),
+              SyscallSucceeds());
+
+  ASSERT_THAT(statfs(dir.path().c_str(), &st), SyscallSucceeds());
+  EXPECT_EQ(st.f_flags & (ST_NOEXEC | ST_RDONLY), ST_NOEXEC);
+  int fd = open(file.c_str(), O_CREAT | O_RDWR, 0644);
+  ASSERT_THAT(fd, SyscallSucceeds());
+  EXPECT_THAT(close(fd), SyscallSucceeds());
+}
+
+TEST(MountSetattrTest, ReadOnlyWithWritersIsBusy) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
+
+  auto const dir = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+  auto const mount = ASSERT_NO_ERRNO_AND_VALUE(
+      Mount("", dir.path(), kTmpfs, 0, "", 0 /* umountflags */));
+
+  int fd = open(JoinPath(dir.path(), "file").c_str(), O_CREAT | O_RDWR, 0644);
+  ASSERT_THAT(fd, SyscallSucceeds());
+  auto cleanup = Cleanup([&]() { close(fd); });
+
+  struct mount_attr attr = {};
+  attr.attr_set = MOUNT_ATTR_RDONLY;
+  EXPECT_THAT(mount_setattr(AT_FDCWD, dir.path().c_str(), 0, &attr,
+                            sizeof(attr)),
+              SyscallFailsWithErrno(EBUSY));
+
+  struct statfs st;
+  ASSERT_THAT(statfs(dir.path().c_str(), &st), SyscallSucceeds());
+  EXPECT_EQ(st.f_flags & ST_RDONLY, 0);
+}
+
+TEST(MountSetattrTest, Recursive) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
+
+  auto const dir = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+  auto const mount = ASSERT_NO_ERRNO_AND_VALUE(
+      Mount("", dir.path(), kTmpfs, 0, "", 0 /* umountflags */));
+  auto const subdir =
+      ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDirIn(dir.path()));
+  auto const submount = ASSERT_NO_ERRNO_AND_VALUE(
+      Mount("", subdir.path(), kTmpfs, 0, "", 0 /* umountflags */));
+
+  struct mount_attr attr = {};
+  attr.attr_set = MOUNT_ATTR_NODEV;
+  ASSERT_THAT(mount_setattr(AT_FDCWD, dir.path().c_str(), 0, &attr,
+                            sizeof(attr)),
+              SyscallSucceeds());
+  struct statfs st;
+  ASSERT_THAT(statfs(subdir.path().c_str(), &st), SyscallSucceeds());
+  EXPECT_EQ(st.f_flags & ST_NODEV, 0);
+
+  ASSERT_THAT(mount_setattr(AT_FDCWD, dir.path().c_str(), AT_RECURSIVE, &attr,
+                            sizeof(attr)),
+              SyscallSucceeds());
+  ASSERT_THAT(statfs(subdir.path().c_str(), &st), SyscallSucceeds());
+  EXPECT_EQ(st.f_flags & ST_NODEV, ST_NODEV);
+}
+
+TEST(MountSetattrTest, DetachedMount) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
+
+  int fsfd = fsopen(kTmpfs, 0);
+  ASSERT_THAT(fsfd, SyscallSucceeds());
+  auto cleanup_fs = Cleanup([&]() { close(fsfd); });
+  EXPECT_THAT(fsconfig(fsfd, FSCONFIG_CMD_CREATE, NULL, NULL, 0),
+              SyscallSucceeds());
+
+  int mntfd = fsmount(fsfd, 0, 0);
+  ASSERT_THAT(mntfd, SyscallSucceeds());
+  auto cleanup_mnt = Cleanup([&]() { close(mntfd); });
+
+  struct mount_attr attr = {};
+  attr.attr_set = MOUNT_ATTR_NOEXEC;
+  ASSERT_THAT(mount_setattr(mntfd, "", AT_EMPTY_PATH, &attr, sizeof(attr)),
+              SyscallSucceeds());
+
+  struct statfs st;
+  ASSERT_THAT(fstatfs(mntfd, &st), SyscallSucceeds());
+  EXPECT_EQ(st.f_flags & ST_NOEXEC, ST_NOEXEC);
+}
+
 }  // namespace
 }  // namespace testing
 }  // namespace gvisor
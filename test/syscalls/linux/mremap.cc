@@ -461,6 +461,51 @@ TEST(MremapTest, ExpansionPreservesCOWPagesAndExposesNewFilePages) {
   ExpectAllBytesAre(v.substr(2 * kPageSize, kPageSize), 'c');
 }
 
+TEST(MremapTest, DontUnmap_RequiresMayMoveAndSameSize) {
+  Mapping const m = ASSERT_NO_ERRNO_AND_VALUE(
+      MmapAnon(2 * kPageSize, PROT_READ | PROT_WRITE, MAP_PRIVATE));
+  EXPECT_THAT(Mremap(m.ptr(), kPageSize, kPageSize, MREMAP_DONTUNMAP, nullptr),
+              PosixErrorIs(EINVAL, _));
+  EXPECT_THAT(Mremap(m.ptr(), kPageSize, 2 * kPageSize,
+                     MREMAP_MAYMOVE | MREMAP_DONTUNMAP, nullptr),
+              PosixErrorIs(EINVAL, _));
+}
+
+TEST(MremapTest, DontUnmap_PrivateAnon) {
+  Mapping const src = ASSERT_NO_ERRNO_AND_VALUE(
+      MmapAnon(kPageSize, PROT_READ | PROT_WRITE, MAP_PRIVATE));
+  memset(src.ptr(), 'a', kPageSize);
+  MaybeSave();
+
+  void* const ptr = ASSERT_NO_ERRNO_AND_VALUE(Mremap(
+      src.ptr(), kPageSize, kPageSize, MREMAP_MAYMOVE | MREMAP_DONTUNMAP,
+      nullptr));
+  Mapping const dst(ptr, kPageSize);
+  EXPECT_NE(dst.ptr(), src.ptr());
+
+  // The contents of src were moved to dst, but src is still mapped and is
+  // refaulted as zero-filled memory.
+  ExpectAllBytesAre(dst.view(), 'a');
+  EXPECT_TRUE(IsMapped(src.addr()));
+  ExpectAllBytesAre(src.view(), '\0');
+}
+
+TEST(MremapTest, DontUnmap_Fixed) {
+  Mapping const src = ASSERT_NO_ERRNO_AND_VALUE(
+      MmapAnon(kPageSize, PROT_READ | PROT_WRITE, MAP_PRIVATE));
+  Mapping const dst = ASSERT_NO_ERRNO_AND_VALUE(
+      MmapAnon(kPageSize, PROT_NONE, MAP_PRIVATE));
+  memset(src.ptr(), 'a', kPageSize);
+  MaybeSave();
+
+  ASSERT_THAT(Mremap(src.ptr(), kPageSize, kPageSize,
+                     MREMAP_MAYMOVE | MREMAP_FIXED | MREMAP_DONTUNMAP,
+                     dst.ptr()),
+              IsPosixErrorOkAndHolds(dst.ptr()));
+  ExpectAllBytesAre(dst.view(), 'a');
+  ExpectAllBytesAre(src.view(), '\0');
+}
+
 TEST(MremapDeathTest, SharedAnon) {
   SetupGvisorDeathTest();
 
@@ -0,0 +1,364 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+#include <errno.h>
+#include <fcntl.h>
+#include <sys/stat.h>
+#include <sys/syscall.h>
+#include <unistd.h>
+
+#include <atomic>
+#include <cstdint>
+#include <string>
+
+#include "gtest/gtest.h"
+#include "absl/strings/str_cat.h"
+#include "test/util/file_descriptor.h"
+#include "test/util/fs_util.h"
+#include "test/util/posix_error.h"
+#include "test/util/temp_path.h"
+#include "test/util/temp_umask.h"
+#include "test/util/test_util.h"
+#include "test/util/thread_util.h"
+
+namespace gvisor {
+namespace testing {
+
+namespace {
+
+#ifndef SYS_openat2
+#if defined(__x86_64__) || defined(__aarch64__)
+#define SYS_openat2 437
+#else
+#error "Unknown architecture"
+#endif
+#endif  // SYS_openat2
+
+#ifndef RESOLVE_NO_XDEV
+#define RESOLVE_NO_XDEV 0x01
+#define RESOLVE_NO_MAGICLINKS 0x02
+#define RESOLVE_NO_SYMLINKS 0x04
+#define RESOLVE_BENEATH 0x08
+#define RESOLVE_IN_ROOT 0x10
+#define RESOLVE_CACHED 0x20
+#endif  // RESOLVE_NO_XDEV
+
+// struct kernel_open_how is a Linux open_how struct. Old versions of glibc do
+// not expose it. See include/uapi/linux/openat2.h.
+struct kernel_open_how {
+  uint64_t flags;
+  uint64_t mode;
+  uint64_t resolve;
+};
+
+int openat2(int dirfd, const char* pathname, struct kernel_open_how* how,
+            size_t size) {
+  return syscall(SYS_openat2, dirfd, pathname, how, size);
+}
+
+int openat2(int dirfd, const std::string& pathname, uint64_t flags,
+            uint64_t resolve) {
+  struct kernel_open_how how = {};
+  how.flags = flags;
+  how.resolve = resolve;
+  return openat2(dirfd, pathname.c_str(), &how, sizeof(how));
+}
+
+class Openat2Test : public ::testing::Test {
+ protected:
+  void SetUp() override {
+    struct kernel_open_how how = {};
+    SKIP_IF(!IsRunningOnGvisor() &&
+            openat2(AT_FDCWD, "/", &how, sizeof(how)) < 0 && errno == ENOSYS);
+
+    // Layout:
+    //   root/
+    //     file
+    //     dir/
+    //     abs -> /
+    //     rel -> dir
+    //     up -> ..
+    root_ = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+    ASSERT_NO_ERRNO(
+        CreateWithContents(JoinPath(root_.path(), "file"), "file", 0644));
+    ASSERT_THAT(mkdir(JoinPath(root_.path(), "dir").c_str(), 0755),
+                SyscallSucceeds());
+    ASSERT_THAT(symlink("/", JoinPath(root_.path(), "abs").c_str()),
+                SyscallSucceeds());
+    ASSERT_THAT(symlink("dir", JoinPath(root_.path(), "rel").c_str()),
+                SyscallSucceeds());
+    ASSERT_THAT(symlink("..", JoinPath(root_.path(), "up").c_str()),
+                SyscallSucceeds());
+    rootfd_ = ASSERT_NO_ERRNO_AND_VALUE(
+        Open(root_.path(), O_RDONLY | O_DIRECTORY));
+  }
+
+  // IsRoot returns true if fd refers to the test's root directory.
+  PosixErrorOr<bool> IsRoot(int fd) {
+    struct stat got, want;
+    RETURN_ERROR_IF_SYSCALL_FAIL(fstat(fd, &got));
+    RETURN_ERROR_IF_SYSCALL_FAIL(fstat(rootfd_.get(), &want));
+    return got.st_dev == want.st_dev && got.st_ino == want.st_ino;
+  }
+
+  TempPath root_;
+  FileDescriptor rootfd_;
+};
+
+TEST_F(Openat2Test, InvalidArguments) {
+  struct kernel_open_how how = {};
+  EXPECT_THAT(openat2(rootfd_.get(), "file", &how, 0),
+              SyscallFailsWithErrno(EINVAL));
+  EXPECT_THAT(openat2(rootfd_.get(), "file", &how, sizeof(how) - 1),
+              SyscallFailsWithErrno(EINVAL));
+
+  // Unknown flags are rejected.
+  EXPECT_THAT(openat2(rootfd_.get(), "file", uint64_t{1} << 40, 0),
+              SyscallFailsWithErrno(EINVAL));
+  EXPECT_THAT(openat2(rootfd_.get(), "file", O_RDONLY, 0x1000),
+              SyscallFailsWithErrno(EINVAL));
+  EXPECT_THAT(openat2(rootfd_.get(), "file", O_RDONLY,
+                      RESOLVE_BENEATH | RESOLVE_IN_ROOT),
+              SyscallFailsWithErrno(EINVAL));
+
+  // Only O_DIRECTORY, O_NOFOLLOW and O_CLOEXEC can be combined with O_PATH.
+  EXPECT_THAT(openat2(rootfd_.get(), "file", O_PATH | O_RDWR, 0),
+              SyscallFailsWithErrno(EINVAL));
+  EXPECT_THAT(openat2(rootfd_.get(), "file", O_PATH | O_CREAT, 0),
+              SyscallFailsWithErrno(EINVAL));
+  int fd;
+  ASSERT_THAT(fd = openat2(rootfd_.get(), "dir",
+                           O_PATH | O_DIRECTORY | O_NOFOLLOW | O_CLOEXEC, 0),
+              SyscallSucceeds());
+  EXPECT_THAT(close(fd), SyscallSucceeds());
+
+  // The mode can only be set when creating files.
+  how.flags = O_RDONLY;
+  how.mode = 0644;
+  EXPECT_THAT(openat2(rootfd_.get(), "file", &how, sizeof(how)),
+              SyscallFailsWithErrno(EINVAL));
+  how.flags = O_RDWR | O_CREAT;
+  how.mode = 010000;
+  EXPECT_THAT(openat2(rootfd_.get(), "new", &how, sizeof(how)),
+              SyscallFailsWithErrno(EINVAL));
+}
+
+TEST_F(Openat2Test, ExtendedStruct) {
+  struct {
+    struct kernel_open_how how;
+    uint64_t extra;
+  } ext = {};
+  ext.how.flags = O_RDONLY;
+  int fd;
+  ASSERT_THAT(fd = openat2(rootfd_.get(), "file", &ext.how, sizeof(ext)),
+              SyscallSucceeds());
+  EXPECT_THAT(close(fd), SyscallSucceeds());
+
+  // Unknown fields must be zero.
+  ext.extra = 1;
+  EXPECT_THAT(openat2(rootfd_.get(), "file", &ext.how, sizeof(ext)),
+              SyscallFailsWithErrno(E2BIG));
+}
+
+TEST_F(Openat2Test, CreateWithMode) {
+  const TempUmask mask(0);
+  struct kernel_open_how how = {};
+  how.flags = O_RDWR | O_CREAT | O_EXCL;
+  how.mode = 0600;
+  int fd;
+  ASSERT_THAT(fd = openat2(rootfd_.get(), "new", &how, sizeof(how)),
+              SyscallSucceeds());
+  FileDescriptor f(fd);
+  struct stat st;
+  ASSERT_THAT(fstat(f.get(), &st), SyscallSucceeds());
+  EXPECT_EQ(st.st_mode & 0777, 0600);
+}
+
+TEST_F(Openat2Test, Beneath) {
+  int fd;
+  ASSERT_THAT(fd = openat2(rootfd_.get(), "dir/../file", O_RDONLY,
+                           RESOLVE_BENEATH),
+              SyscallSucceeds());
+  EXPECT_THAT(close(fd), SyscallSucceeds());
+  ASSERT_THAT(fd = openat2(rootfd_.get(), "rel", O_RDONLY, RESOLVE_BENEATH),
+              SyscallSucceeds());
+  EXPECT_THAT(close(fd), SyscallSucceeds());
+
+  EXPECT_THAT(openat2(rootfd_.get(), "..", O_RDONLY, RESOLVE_BENEATH),
+              SyscallFailsWithErrno(EXDEV));
+  EXPECT_THAT(openat2(rootfd_.get(), "dir/../../file", O_RDONLY,
+                      RESOLVE_BENEATH),
+              SyscallFailsWithErrno(EXDEV));
+  EXPECT_THAT(openat2(rootfd_.get(), "up", O_RDONLY, RESOLVE_BENEATH),
+              SyscallFailsWithErrno(EXDEV));
+  EXPECT_THAT(openat2(rootfd_.get(), "abs", O_RDONLY, RESOLVE_BENEATH),
+              SyscallFailsWithErrno(EXDEV));
+  EXPECT_THAT(openat2(rootfd_.get(), JoinPath(root_.path(), "file"), O_RDONLY,
+                      RESOLVE_BENEATH),
+              SyscallFailsWithErrno(EXDEV));
+}
+
+TEST_F(Openat2Test, InRoot) {
+  for (const std::string path : {"/", "..", "../..", "up", "abs", "abs/.."}) {
+    SCOPED_TRACE(path);
+    int fd;
+    ASSERT_THAT(fd = openat2(rootfd_.get(), path, O_RDONLY, RESOLVE_IN_ROOT),
+                SyscallSucceeds());
+    FileDescriptor f(fd);
+    EXPECT_TRUE(ASSERT_NO_ERRNO_AND_VALUE(IsRoot(f.get())));
+  }
+
+  int fd;
+  ASSERT_THAT(
+      fd = openat2(rootfd_.get(), "/abs/file", O_RDONLY, RESOLVE_IN_ROOT),
+      SyscallSucceeds());
+  EXPECT_THAT(close(fd), SyscallSucceeds());
+}
+
+// Resolving ".." must not escape the scope when a concurrent rename moves the
+// directory being walked out of it.
+TEST_F(Openat2Test, ScopedDotDotRacingRename) {
+  // Layout (in addition to SetUp's):
+  //   root/
+  //     scope/
+  //       sub/    (moved back and forth to root/outside/sub)
+  //     outside/
+  const std::string scope = JoinPath(root_.path(), "scope");
+  const std::string outside = JoinPath(root_.path(), "outside");
+  const std::string in = JoinPath(scope, "sub");
+  const std::string out = JoinPath(outside, "sub");
+  ASSERT_THAT(mkdir(scope.c_str(), 0755), SyscallSucceeds());
+  ASSERT_THAT(mkdir(in.c_str(), 0755), SyscallSucceeds());
+  ASSERT_THAT(mkdir(outside.c_str(), 0755), SyscallSucceeds());
+  const FileDescriptor scopefd =
+      ASSERT_NO_ERRNO_AND_VALUE(Open(scope, O_RDONLY | O_DIRECTORY));
+  struct stat want;
+  ASSERT_THAT(fstat(scopefd.get(), &want), SyscallSucceeds());
+
+  std::atomic<bool> done(false);
+  ScopedThread renamer([&] {
+    while (!done.load()) {
+      TEST_PCHECK(rename(in.c_str(), out.c_str()) == 0);
+      TEST_PCHECK(rename(out.c_str(), in.c_str()) == 0);
+    }
+  });
+
+  for (int i = 0; i < 10000; i++) {
+    for (const uint64_t resolve : {RESOLVE_BENEATH, RESOLVE_IN_ROOT}) {
+      const int fd =
+          openat2(scopefd.get(), "sub/..", O_RDONLY | O_DIRECTORY, resolve);
+      if (fd < 0) {
+        // sub was outside of the scope, or a rename raced with "..".
+        EXPECT_TRUE(errno == ENOENT || errno == EAGAIN) << "errno " << errno;
+        continue;
+      }
+      FileDescriptor f(fd);
+      struct stat got;
+      ASSERT_THAT(fstat(f.get(), &got), SyscallSucceeds());
+      EXPECT_TRUE(got.st_dev == want.st_dev && got.st_ino == want.st_ino)
+          << "resolve " << resolve << " escaped the scope";
+    }
+  }
+  done.store(true);
+}
+
+TEST_F(Openat2Test, NoSymlinks) {
+  EXPECT_THAT(openat2(rootfd_.get(), "rel", O_RDONLY, RESOLVE_NO_SYMLINKS),
+              SyscallFailsWithErrno(ELOOP));
+  EXPECT_THAT(openat2(rootfd_.get(), "up/file", O_RDONLY, RESOLVE_NO_SYMLINKS),
+              SyscallFailsWithErrno(ELOOP));
+
+  // Symlinks that are not followed are fine.
+  int fd;
+  ASSERT_THAT(fd = openat2(rootfd_.get(), "rel", O_PATH | O_NOFOLLOW,
+                           RESOLVE_NO_SYMLINKS),
+              SyscallSucceeds());
+  EXPECT_THAT(close(fd), SyscallSucceeds());
+}
+
+TEST_F(Openat2Test, NoMagicLinks) {
+  const std::string magic = absl::StrCat("/proc/self/fd/", rootfd_.get());
+  int fd;
+  ASSERT_THAT(fd = openat2(AT_FDCWD, magic, O_RDONLY, 0), SyscallSucceeds());
+  EXPECT_THAT(close(fd), SyscallSucceeds());
+
+  EXPECT_THAT(openat2(AT_FDCWD, magic, O_RDONLY, RESOLVE_NO_MAGICLINKS),
+              SyscallFailsWithErrno(ELOOP));
+  EXPECT_THAT(openat2(AT_FDCWD, magic, O_RDONLY, RESOLVE_NO_SYMLINKS),
+              SyscallFailsWithErrno(ELOOP));
+
+  // Ordinary symlinks are still followed.
+  ASSERT_THAT(
+      fd = openat2(rootfd_.get(), "rel", O_RDONLY, RESOLVE_NO_MAGICLINKS),
+      SyscallSucceeds());
+  EXPECT_THAT(close(fd), SyscallSucceeds());
+}
+
+TEST_F(Openat2Test, MagicLinksInScopedLookup) {
+  const FileDescriptor proc =
+      ASSERT_NO_ERRNO_AND_VALUE(Open("/proc", O_RDONLY | O_DIRECTORY));
+  const std::string magic = absl::StrCat("self/fd/", rootfd_.get());
+  EXPECT_THAT(openat2(proc.get(), magic, O_RDONLY, RESOLVE_BENEATH),
+              SyscallFailsWithErrno(EXDEV));
+  EXPECT_THAT(openat2(proc.get(), magic, O_RDONLY, RESOLVE_IN_ROOT),
+              SyscallFailsWithErrno(EXDEV));
+}
+
+TEST_F(Openat2Test, NoXDev) {
+  const FileDescriptor root =
+      ASSERT_NO_ERRNO_AND_VALUE(Open("/", O_RDONLY | O_DIRECTORY));
+  EXPECT_THAT(openat2(root.get(), "proc/self", O_RDONLY, RESOLVE_NO_XDEV),
+              SyscallFailsWithErrno(EXDEV));
+
+  const FileDescriptor proc =
+      ASSERT_NO_ERRNO_AND_VALUE(Open("/proc", O_RDONLY | O_DIRECTORY));
+  EXPECT_THAT(openat2(proc.get(), "..", O_RDONLY, RESOLVE_NO_XDEV),
+              SyscallFailsWithErrno(EXDEV));
+
+  int fd;
+  ASSERT_THAT(
+      fd = openat2(rootfd_.get(), "dir/../file", O_RDONLY, RESOLVE_NO_XDEV),
+      SyscallSucceeds());
+  EXPECT_THAT(close(fd), SyscallSucceeds());
+
+  // Absolute paths may start at the root from any dirfd, but can't cross
+  // mount points after that.
+  EXPECT_THAT(openat2(proc.get(), "/proc/self", O_RDONLY, RESOLVE_NO_XDEV),
+              SyscallFailsWithErrno(EXDEV));
+  EXPECT_THAT(openat2(AT_FDCWD, "/proc/self", O_RDONLY, RESOLVE_NO_XDEV),
+              SyscallFailsWithErrno(EXDEV));
+  ASSERT_THAT(fd = openat2(proc.get(), "/", O_RDONLY, RESOLVE_NO_XDEV),
+              SyscallSucceeds());
+  EXPECT_THAT(close(fd), SyscallSucceeds());
+}
+
+TEST_F(Openat2Test, Cached) {
+  // Lookups that would modify files can't be done from the cache.
+  EXPECT_THAT(openat2(rootfd_.get(), "file", O_RDWR | O_TRUNC, RESOLVE_CACHED),
+              SyscallFailsWithErrno(EAGAIN));
+
+  // Other lookups may fail with EAGAIN if they can't be completed from the
+  // cache.
+  int fd = openat2(rootfd_.get(), "file", O_RDONLY, RESOLVE_CACHED);
+  if (fd < 0) {
+    EXPECT_EQ(errno, EAGAIN);
+  } else {
+    EXPECT_THAT(close(fd), SyscallSucceeds());
+  }
+}
+
+}  // namespace
+
+}  // namespace testing
+}  // namespace gvisor
@@ -0,0 +1,285 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+#include <fcntl.h>
+#include <linux/perf_event.h>
+#include <sys/ioctl.h>
+#include <sys/mman.h>
+#include <sys/syscall.h>
+#include <unistd.h>
+
+#include <cerrno>
+#include <cstdint>
+#include <utility>
+#include <vector>
+
+#include "gmock/gmock.h"
+#include "gtest/gtest.h"
+#include "absl/time/clock.h"
+#include "absl/time/time.h"
+#include "test/util/file_descriptor.h"
+#include "test/util/memory_util.h"
+#include "test/util/posix_error.h"
+#include "test/util/test_util.h"
+
+namespace gvisor {
+namespace testing {
+
+namespace {
+
+PosixErrorOr<FileDescriptor> PerfEventOpen(struct perf_event_attr* attr,
+                                           pid_t pid, int cpu, int group_fd,
+                                           unsigned long flags) {
+  int fd = syscall(SYS_perf_event_open, attr, pid, cpu, group_fd, flags);
+  MaybeSave();
+  if (fd < 0) {
+    return PosixError(errno, "perf_event_open");
+  }
+  return FileDescriptor(fd);
+}
+
+struct perf_event_attr SoftwareAttr(uint64_t config) {
+  struct perf_event_attr attr = {};
+  attr.type = PERF_TYPE_SOFTWARE;
+  attr.size = sizeof(attr);
+  attr.config = config;
+  return attr;
+}
+
+PosixErrorOr<uint64_t> ReadCount(const FileDescriptor& fd) {
+  uint64_t count;
+  if (read(fd.get(), &count, sizeof(count)) != sizeof(count)) {
+    return PosixError(errno, "read");
+  }
+  return count;
+}
+
+void Spin(absl::Duration d) {
+  const absl::Time deadline = absl::Now() + d;
+  volatile uint64_t sink = 0;
+  while (absl::Now() < deadline) {
+    for (int i = 0; i < 100000; i++) {
+      sink += i;
+    }
+  }
+}
+
+class PerfEventTest : public ::testing::Test {
+ protected:
+  void SetUp() override {
+    // Linux may restrict perf events with perf_event_paranoid.
+    if (!IsRunningOnGvisor()) {
+      struct perf_event_attr attr = SoftwareAttr(PERF_COUNT_SW_TASK_CLOCK);
+      attr.exclude_kernel = 1;
+      auto fd = PerfEventOpen(&attr, 0, -1, -1, 0);
+      SKIP_IF(!fd.ok());
+    }
+  }
+};
+
+TEST_F(PerfEventTest, TaskClockCounts) {
+  struct perf_event_attr attr = SoftwareAttr(PERF_COUNT_SW_TASK_CLOCK);
+  attr.exclude_kernel = 1;
+  FileDescriptor fd =
+      ASSERT_NO_ERRNO_AND_VALUE(PerfEventOpen(&attr, 0, -1, -1, 0));
+  Spin(absl::Milliseconds(100));
+  EXPECT_GT(ASSERT_NO_ERRNO_AND_VALUE(ReadCount(fd)), 0);
+}
+
+TEST_F(PerfEventTest, EnableDisable) {
+  struct perf_event_attr attr = SoftwareAttr(PERF_COUNT_SW_TASK_CLOCK);
+  attr.exclude_kernel = 1;
+  attr.disabled = 1;
+  FileDescriptor fd =
+      ASSERT_NO_ERRNO_AND_VALUE(PerfEventOpen(&attr, 0, -1, -1, 0));
+
+  Spin(absl::Milliseconds(50));
+  EXPECT_EQ(ASSERT_NO_ERRNO_AND_VALUE(ReadCount(fd)), 0);
+
+  ASSERT_THAT(ioctl(fd.get(), PERF_EVENT_IOC_ENABLE, 0), SyscallSucceeds());
+  Spin(absl::Milliseconds(100));
+  ASSERT_THAT(ioctl(fd.get(), PERF_EVENT_IOC_DISABLE, 0), SyscallSucceeds());
+  uint64_t count = ASSERT_NO_ERRNO_AND_VALUE(ReadCount(fd));
+  EXPECT_GT(count, 0);
+
+  Spin(absl::Milliseconds(50));
+  EXPECT_EQ(ASSERT_NO_ERRNO_AND_VALUE(ReadCount(fd)), count);
+
+  ASSERT_THAT(ioctl(fd.get(), PERF_EVENT_IOC_RESET, 0), SyscallSucceeds());
+  EXPECT_EQ(ASSERT_NO_ERRNO_AND_VALUE(ReadCount(fd)), 0);
+}
+
+TEST_F(PerfEventTest, ContextSwitches) {
+  // Linux counts context switches in kernel mode, so they can't be measured
+  // with exclude_kernel.
+  struct perf_event_attr attr =
+      SoftwareAttr(PERF_COUNT_SW_CONTEXT_SWITCHES);
+  auto fd_or = PerfEventOpen(&attr, 0, -1, -1, 0);
+  SKIP_IF(!IsRunningOnGvisor() && !fd_or.ok());
+  FileDescriptor fd = ASSERT_NO_ERRNO_AND_VALUE(std::move(fd_or));
+  for (int i = 0; i < 10; i++) {
+    absl::SleepFor(absl::Milliseconds(1));
+  }
+  EXPECT_GT(ASSERT_NO_ERRNO_AND_VALUE(ReadCount(fd)), 0);
+}
+
+TEST_F(PerfEventTest, PageFaults) {
+  struct perf_event_attr attr = SoftwareAttr(PERF_COUNT_SW_PAGE_FAULTS);
+  attr.exclude_kernel = 1;
+  FileDescriptor fd =
+      ASSERT_NO_ERRNO_AND_VALUE(PerfEventOpen(&attr, 0, -1, -1, 0));
+  constexpr int kPages = 16;
+  Mapping m = ASSERT_NO_ERRNO_AND_VALUE(
+      MmapAnon(kPages * kPageSize, PROT_READ | PROT_WRITE, MAP_PRIVATE));
+  char* p = reinterpret_cast<char*>(m.ptr());
+  for (int i = 0; i < kPages; i++) {
+    p[i * kPageSize] = 1;
+  }
+  EXPECT_GT(ASSERT_NO_ERRNO_AND_VALUE(ReadCount(fd)), 0);
+}
+
+TEST_F(PerfEventTest, ReadFormat) {
+  struct perf_event_attr attr = SoftwareAttr(PERF_COUNT_SW_TASK_CLOCK);
+  attr.exclude_kernel = 1;
+  attr.read_format = PERF_FORMAT_TOTAL_TIME_ENABLED |
+                     PERF_FORMAT_TOTAL_TIME_RUNNING | PERF_FORMAT_ID;
+  FileDescriptor fd =
+      ASSERT_NO_ERRNO_AND_VALUE(PerfEventOpen(&attr, 0, -1, -1, 0));
+  Spin(absl::Milliseconds(50));
+
+  uint64_t id;
+  ASSERT_THAT(ioctl(fd.get(), PERF_EVENT_IOC_ID, &id), SyscallSucceeds());
+
+  uint64_t buf[4];
+  ASSERT_THAT(read(fd.get(), buf, sizeof(buf)),
+              SyscallSucceedsWithValue(sizeof(buf)));
+  EXPECT_GT(buf[0], 0);
+  EXPECT_GT(buf[1], 0);
+  EXPECT_GT(buf[2], 0);
+  EXPECT_EQ(buf[3], id);
+
+  // The buffer must fit all values.
+  EXPECT_THAT(read(fd.get(), buf, sizeof(buf) - 1),
+              SyscallFailsWithErrno(ENOSPC));
+}
+
+TEST_F(PerfEventTest, Group) {
+  struct perf_event_attr attr = SoftwareAttr(PERF_COUNT_SW_TASK_CLOCK);
+  attr.exclude_kernel = 1;
+  attr.disabled = 1;
+  attr.read_format = PERF_FORMAT_GROUP | PERF_FORMAT_ID;
+  FileDescriptor leader =
+      ASSERT_NO_ERRNO_AND_VALUE(PerfEventOpen(&attr, 0, -1, -1, 0));
+
+  // Siblings only count while the leader is enabled.
+  attr.config = PERF_COUNT_SW_CPU_CLOCK;
+  attr.disabled = 0;
+  FileDescriptor sibling = ASSERT_NO_ERRNO_AND_VALUE(
+      PerfEventOpen(&attr, 0, -1, leader.get(), 0));
+  Spin(absl::Milliseconds(50));
+
+  ASSERT_THAT(ioctl(leader.get(), PERF_EVENT_IOC_ENABLE, 0),
+              SyscallSucceeds());
+  Spin(absl::Milliseconds(100));
+  ASSERT_THAT(ioctl(leader.get(), PERF_EVENT_IOC_DISABLE, 0),
+              SyscallSucceeds());
+
+  uint64_t leader_id, sibling_id;
+  ASSERT_THAT(ioctl(leader.get(), PERF_EVENT_IOC_ID, &leader_id),
+              SyscallSucceeds());
+  ASSERT_THAT(ioctl(sibling.get(), PERF_EVENT_IOC_ID, &sibling_id),
+              SyscallSucceeds());
+  EXPECT_NE(leader_id, sibling_id);
+
+  // nr, {value, id} for the leader and the sibling.
+  uint64_t buf[5];
+  ASSERT_THAT(read(leader.get(), buf, sizeof(buf)),
+              SyscallSucceedsWithValue(sizeof(buf)));
+  EXPECT_EQ(buf[0], 2);
+  EXPECT_GT(buf[1], 0);
+  EXPECT_EQ(buf[2], leader_id);
+  EXPECT_GT(buf[3], 0);
+  EXPECT_EQ(buf[4], sibling_id);
+  // Both counted for the same period, so they shouldn't differ by much;
+  // in particular, the sibling didn't count before the leader was enabled.
+  EXPECT_LT(buf[3], buf[1] + absl::ToInt64Nanoseconds(absl::Milliseconds(30)));
+
+  // Siblings can't lead groups.
+  EXPECT_THAT(PerfEventOpen(&attr, 0, -1, sibling.get(), 0),
+              PosixErrorIs(EINVAL));
+}
+
+TEST_F(PerfEventTest, InvalidArguments) {
+  struct perf_event_attr attr = SoftwareAttr(PERF_COUNT_SW_TASK_CLOCK);
+  attr.exclude_kernel = 1;
+  EXPECT_THAT(PerfEventOpen(&attr, -1, -1, -1, 0), PosixErrorIs(EINVAL));
+  EXPECT_THAT(PerfEventOpen(&attr, 0, -1, -1, 1 << 20), PosixErrorIs(EINVAL));
+
+  // group_fd must refer to a perf event.
+  FileDescriptor fd = ASSERT_NO_ERRNO_AND_VALUE(Open("/dev/null", O_RDONLY));
+  EXPECT_THAT(PerfEventOpen(&attr, 0, -1, fd.get(), 0), PosixErrorIs(EINVAL));
+
+  attr.read_format = 1 << 20;
+  EXPECT_THAT(PerfEventOpen(&attr, 0, -1, -1, 0), PosixErrorIs(EINVAL));
+}
+
+TEST_F(PerfEventTest, AttrSize) {
+  std::vector<char> buf(2 * kPageSize);
+  struct perf_event_attr* attr =
+      reinterpret_cast<struct perf_event_attr*>(buf.data());
+  *attr = SoftwareAttr(PERF_COUNT_SW_TASK_CLOCK);
+  attr->exclude_kernel = 1;
+
+  // Nonzero bytes beyond the kernel's struct are rejected, and the size of
+  // the kernel's struct is reported back.
+  attr->size = kPageSize;
+  buf[kPageSize - 1] = 1;
+  EXPECT_THAT(PerfEventOpen(attr, 0, -1, -1, 0), PosixErrorIs(E2BIG));
+  EXPECT_GE(attr->size, PERF_ATTR_SIZE_VER0);
+  EXPECT_LT(attr->size, kPageSize);
+
+  // Zero bytes are accepted.
+  attr->size = kPageSize;
+  buf[kPageSize - 1] = 0;
+  EXPECT_NO_ERRNO(PerfEventOpen(attr, 0, -1, -1, 0));
+
+  // Sizes below the first version are rejected.
+  attr->size = PERF_ATTR_SIZE_VER0 - 8;
+  EXPECT_THAT(PerfEventOpen(attr, 0, -1, -1, 0), PosixErrorIs(E2BIG));
+
+  // Size 0 means the first version.
+  attr->size = 0;
+  EXPECT_NO_ERRNO(PerfEventOpen(attr, 0, -1, -1, 0));
+}
+
+TEST_F(PerfEventTest, UnsupportedEvents) {
+  // Hardware counters and sampling aren't available in gVisor, and are
+  // reported with the same errors as on Linux hosts that lack them.
+  SKIP_IF(!IsRunningOnGvisor());
+
+  struct perf_event_attr attr = SoftwareAttr(PERF_COUNT_HW_CPU_CYCLES);
+  attr.type = PERF_TYPE_HARDWARE;
+  attr.exclude_kernel = 1;
+  EXPECT_THAT(PerfEventOpen(&attr, 0, -1, -1, 0), PosixErrorIs(ENOENT));
+
+  attr = SoftwareAttr(PERF_COUNT_SW_CPU_CLOCK);
+  attr.exclude_kernel = 1;
+  attr.sample_period = 1000000;
+  EXPECT_THAT(PerfEventOpen(&attr, 0, -1, -1, 0), PosixErrorIs(EOPNOTSUPP));
+}
+
+}  // namespace
+
+}  // namespace testing
+}  // namespace gvisor
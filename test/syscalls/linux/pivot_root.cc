@@ -518,6 +518,74 @@ TEST(PivotRootTest, OnSharedPutOldMountpoint) {
   EXPECT_THAT(InForkedProcess(rest), IsPosixErrorOkAndHolds(0));
 }
 
+TEST(PivotRootTest, OnSharedCurrentRootParent) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_CHROOT)));
+
+  auto outer = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+  EXPECT_THAT(mount("", outer.path().c_str(), "tmpfs", 0, "mode=0700"),
+              SyscallSucceeds());
+  auto root = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDirIn(outer.path()));
+  EXPECT_THAT(mount("", root.path().c_str(), "tmpfs", 0, "mode=0700"),
+              SyscallSucceeds());
+  auto new_root = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDirIn(root.path()));
+  EXPECT_THAT(mount("", new_root.path().c_str(), "tmpfs", 0, "mode=0700"),
+              SyscallSucceeds());
+  const std::string new_root_path = JoinPath("/", Basename(new_root.path()));
+  auto put_old =
+      ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDirIn(new_root.path()));
+  const std::string put_old_path =
+      JoinPath(new_root_path, "/", Basename(put_old.path()));
+
+  // Fails because the current root is mounted on a shared mount.
+  EXPECT_THAT(
+      mount(nullptr, outer.path().c_str(), nullptr, MS_SHARED, nullptr),
+      SyscallSucceeds());
+  const auto rest = [&] {
+    TEST_CHECK_SUCCESS(chroot(root.path().c_str()));
+    TEST_CHECK_ERRNO(
+        syscall(__NR_pivot_root, new_root_path.c_str(), put_old_path.c_str()),
+        EINVAL);
+  };
+  EXPECT_THAT(InForkedProcess(rest), IsPosixErrorOkAndHolds(0));
+}
+
+TEST(PivotRootTest, SharedNewRootWithPrivatePutOld) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_CHROOT)));
+
+  auto root = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+  EXPECT_THAT(mount("", root.path().c_str(), "tmpfs", 0, "mode=0700"),
+              SyscallSucceeds());
+  auto new_root = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDirIn(root.path()));
+  EXPECT_THAT(mount("", new_root.path().c_str(), "tmpfs", 0, "mode=0700"),
+              SyscallSucceeds());
+  EXPECT_THAT(
+      mount(nullptr, new_root.path().c_str(), nullptr, MS_SHARED, nullptr),
+      SyscallSucceeds());
+  const std::string new_root_path = JoinPath("/", Basename(new_root.path()));
+  auto put_old_mnt =
+      ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDirIn(new_root.path()));
+  EXPECT_THAT(mount("", put_old_mnt.path().c_str(), "tmpfs", 0, "mode=0700"),
+              SyscallSucceeds());
+  // Mounts created under a shared mount are shared too. Make put_old's mount
+  // private, so that only new_root itself is shared.
+  EXPECT_THAT(
+      mount(nullptr, put_old_mnt.path().c_str(), nullptr, MS_PRIVATE, nullptr),
+      SyscallSucceeds());
+  auto put_old =
+      ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDirIn(put_old_mnt.path()));
+  const std::string put_old_path = JoinPath(
+      new_root_path, Basename(put_old_mnt.path()), Basename(put_old.path()));
+
+  const auto rest = [&] {
+    TEST_CHECK_SUCCESS(chroot(root.path().c_str()));
+    TEST_CHECK_SUCCESS(
+        syscall(__NR_pivot_root, new_root_path.c_str(), put_old_path.c_str()));
+  };
+  EXPECT_THAT(InForkedProcess(rest), IsPosixErrorOkAndHolds(0));
+}
+
 TEST(PivotRootTest, UnreachableNewRootFails) {
   SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
   SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_CHROOT)));
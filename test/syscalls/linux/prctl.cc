@@ -12,12 +12,14 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+#include <fcntl.h>
 #include <sys/prctl.h>
 #include <sys/ptrace.h>
 #include <sys/types.h>
 #include <sys/wait.h>
 #include <unistd.h>
 
+#include <cstring>
 #include <string>
 
 #include "gtest/gtest.h"
@@ -193,6 +195,58 @@ TEST(PrctlTest, InvalidPrSetMM) {
   ASSERT_THAT(prctl(PR_SET_MM, 0, 0, 0, 0), SyscallFailsWithErrno(EPERM));
 }
 
+// sizeof(struct prctl_mm_map), which can't be included alongside
+// <sys/prctl.h>.
+constexpr unsigned int kPrctlMMMapSize = 104;
+
+// PR_SET_MM_MAP_SIZE doesn't require any capabilities.
+TEST(PrctlTest, SetMMMapSize) {
+  AutoCapability cap(CAP_SYS_RESOURCE, false);
+  unsigned int size = 0;
+  ASSERT_THAT(prctl(PR_SET_MM, PR_SET_MM_MAP_SIZE, &size, 0, 0),
+              SyscallSucceeds());
+  EXPECT_EQ(size, kPrctlMMMapSize);
+}
+
+// Only PR_SET_MM_AUXV and PR_SET_MM_MAP{,_SIZE} take a fourth argument.
+TEST(PrctlTest, SetMMExtraArgument) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_RESOURCE)));
+  EXPECT_THAT(prctl(PR_SET_MM, PR_SET_MM_START_CODE, 0x10000, 1, 0),
+              SyscallFailsWithErrno(EINVAL));
+}
+
+// The end of the code segment can't precede its start.
+TEST(PrctlTest, SetMMInvalidOrder) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_RESOURCE)));
+  EXPECT_THAT(prctl(PR_SET_MM, PR_SET_MM_END_CODE, 0x10000, 0, 0),
+              SyscallFailsWithErrno(EINVAL));
+}
+
+// Moving the argument vector changes /proc/self/cmdline.
+TEST(PrctlTest, SetMMArgs) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_RESOURCE)));
+
+  static constexpr char kArgs[] = "foo\0bar";
+  const auto rest = [] {
+    // The new arguments are below the stack, so setting the start first
+    // keeps the bounds ordered.
+    TEST_PCHECK(prctl(PR_SET_MM, PR_SET_MM_ARG_START, kArgs, 0, 0) == 0);
+    TEST_PCHECK(prctl(PR_SET_MM, PR_SET_MM_ARG_END, kArgs + sizeof(kArgs), 0,
+                      0) == 0);
+
+    char buf[sizeof(kArgs) + 1] = {};
+    int fd = open("/proc/self/cmdline", O_RDONLY);
+    TEST_PCHECK(fd >= 0);
+    ssize_t n = read(fd, buf, sizeof(buf));
+    TEST_PCHECK(n >= 0);
+    close(fd);
+    TEST_CHECK(n == sizeof(kArgs));
+    TEST_CHECK(memcmp(buf, kArgs, sizeof(kArgs)) == 0);
+  };
+
+  EXPECT_THAT(InForkedProcess(rest), IsPosixErrorOkAndHolds(0));
+}
+
 // Sanity check that dumpability is remembered.
 TEST(PrctlTest, SetGetDumpability) {
   int before;
@@ -307,6 +361,53 @@ TEST(PrctlTest, OrphansReparentedToSubreaper) {
   EXPECT_TRUE(got_sigchild);
 }
 
+TEST(PrctlTest, SetGetTimerSlack) {
+  const int orig = prctl(PR_GET_TIMERSLACK, 0, 0, 0, 0);
+  ASSERT_THAT(orig, SyscallSucceeds());
+  ASSERT_GT(orig, 0);
+  auto restore = Cleanup([orig] {
+    EXPECT_THAT(prctl(PR_SET_TIMERSLACK, orig, 0, 0, 0), SyscallSucceeds());
+  });
+
+  ASSERT_THAT(prctl(PR_SET_TIMERSLACK, 1, 0, 0, 0), SyscallSucceeds());
+  EXPECT_THAT(prctl(PR_GET_TIMERSLACK, 0, 0, 0, 0),
+              SyscallSucceedsWithValue(1));
+
+  // Setting the timer slack to 0 restores the value inherited at fork.
+  ASSERT_THAT(prctl(PR_SET_TIMERSLACK, 0, 0, 0, 0), SyscallSucceeds());
+  EXPECT_THAT(prctl(PR_GET_TIMERSLACK, 0, 0, 0, 0),
+              SyscallSucceedsWithValue(orig));
+}
+
+TEST(PrctlTest, TimerSlackInheritedAcrossFork) {
+  const int orig = prctl(PR_GET_TIMERSLACK, 0, 0, 0, 0);
+  ASSERT_THAT(orig, SyscallSucceeds());
+  auto restore = Cleanup([orig] {
+    EXPECT_THAT(prctl(PR_SET_TIMERSLACK, orig, 0, 0, 0), SyscallSucceeds());
+  });
+
+  constexpr int kSlack = 4321;
+  ASSERT_THAT(prctl(PR_SET_TIMERSLACK, kSlack, 0, 0, 0), SyscallSucceeds());
+
+  pid_t child_pid = fork();
+  TEST_PCHECK(child_pid >= 0);
+  if (child_pid == 0) {
+    TEST_CHECK(prctl(PR_GET_TIMERSLACK, 0, 0, 0, 0) == kSlack);
+    // The child's default is the slack it inherited, not the parent's
+    // default.
+    TEST_PCHECK(prctl(PR_SET_TIMERSLACK, 1, 0, 0, 0) == 0);
+    TEST_PCHECK(prctl(PR_SET_TIMERSLACK, 0, 0, 0, 0) == 0);
+    TEST_CHECK(prctl(PR_GET_TIMERSLACK, 0, 0, 0, 0) == kSlack);
+    _exit(0);
+  }
+
+  int status;
+  ASSERT_THAT(waitpid(child_pid, &status, 0),
+              SyscallSucceedsWithValue(child_pid));
+  EXPECT_TRUE(WIFEXITED(status) && WEXITSTATUS(status) == 0)
+      << "status =" << status;
+}
+
 TEST(PrctlTest, TaggedAddrCtrl) {
 #if defined(__aarch64__)
   EXPECT_THAT(prctl(PR_SET_TAGGED_ADDR_CTRL, PR_TAGGED_ADDR_ENABLE, 0, 0, 0),
@@ -1667,6 +1667,68 @@ TEST(ProcLoadavg, Fields) {
   EXPECT_TRUE(absl::SimpleAtoi(fields[5], &val2)) << proc_loadvg;
 }
 
+TEST(ProcLoadavg, TaskCounts) {
+  // Stay runnable for a while so that at least one scheduler sample sees
+  // this task.
+  const absl::Time deadline = absl::Now() + absl::Milliseconds(200);
+  volatile uint64_t sink = 0;
+  while (absl::Now() < deadline) {
+    for (int i = 0; i < 100000; i++) {
+      sink += i;
+    }
+  }
+
+  std::string proc_loadvg =
+      ASSERT_NO_ERRNO_AND_VALUE(GetContents("/proc/loadavg"));
+  std::vector<std::string> fields =
+      absl::StrSplit(absl::StripAsciiWhitespace(proc_loadvg),
+                     absl::ByAnyChar(" /"), absl::SkipWhitespace());
+  ASSERT_EQ(fields.size(), 6) << proc_loadvg;
+
+  uint64_t running, total, last_pid;
+  ASSERT_TRUE(absl::SimpleAtoi(fields[3], &running)) << proc_loadvg;
+  ASSERT_TRUE(absl::SimpleAtoi(fields[4], &total)) << proc_loadvg;
+  ASSERT_TRUE(absl::SimpleAtoi(fields[5], &last_pid)) << proc_loadvg;
+  EXPECT_GE(total, 1) << proc_loadvg;
+  EXPECT_LE(running, total) << proc_loadvg;
+  EXPECT_GE(last_pid, getpid()) << proc_loadvg;
+}
+
+TEST(ProcPressure, Format) {
+  // /proc/pressure requires CONFIG_PSI on Linux.
+  SKIP_IF(!IsRunningOnGvisor() && access("/proc/pressure", F_OK) != 0);
+
+  for (const char* resource : {"cpu", "io", "memory"}) {
+    std::string path = absl::StrCat("/proc/pressure/", resource);
+    std::string contents = ASSERT_NO_ERRNO_AND_VALUE(GetContents(path));
+    std::vector<std::string> lines =
+        absl::StrSplit(contents, '\n', absl::SkipEmpty());
+    ASSERT_EQ(lines.size(), 2) << path << ": " << contents;
+    for (int i = 0; i < 2; i++) {
+      std::vector<std::string> fields = absl::StrSplit(lines[i], ' ');
+      ASSERT_EQ(fields.size(), 5) << path << ": " << contents;
+      EXPECT_EQ(fields[0], i == 0 ? "some" : "full");
+      const char* keys[] = {"avg10", "avg60", "avg300", "total"};
+      for (int j = 0; j < 4; j++) {
+        std::vector<std::string> kv = absl::StrSplit(fields[j + 1], '=');
+        ASSERT_EQ(kv.size(), 2) << path << ": " << contents;
+        EXPECT_EQ(kv[0], keys[j]);
+        double avg;
+        uint64_t total;
+        if (j < 3) {
+          ASSERT_TRUE(absl::SimpleAtod(kv[1], &avg))
+              << path << ": " << contents;
+          EXPECT_GE(avg, 0);
+          EXPECT_LE(avg, 100);
+        } else {
+          EXPECT_TRUE(absl::SimpleAtoi(kv[1], &total))
+              << path << ": " << contents;
+        }
+      }
+    }
+  }
+}
+
 // NOTE: Tests in priority.cc also check certain priority related fields in
 // /proc/self/stat.
 
@@ -1977,6 +2039,33 @@ TEST(ProcPidStatusTest, StateSleeping) {
   ASSERT_NO_ERRNO(res);
 }
 
+// Cpus_allowed_list reflects the thread's CPU affinity.
+TEST(ProcPidStatusTest, CpusAllowedList) {
+  const pid_t tid = syscall(SYS_gettid);
+  cpu_set_t orig_set;
+  ASSERT_THAT(sched_getaffinity(tid, sizeof(orig_set), &orig_set),
+              SyscallSucceeds());
+  int cpu = 0;
+  while (CPU_ISSET(cpu, &orig_set) == 0) cpu++;
+  cpu_set_t set = {};
+  CPU_SET(cpu, &set);
+  ASSERT_THAT(sched_setaffinity(tid, sizeof(set), &set), SyscallSucceeds());
+  // Platforms that report real CPU numbers ignore the affinity.
+  cpu_set_t got_set;
+  ASSERT_THAT(sched_getaffinity(tid, sizeof(got_set), &got_set),
+              SyscallSucceeds());
+  SKIP_IF(!CPU_EQUAL(&got_set, &set));
+
+  std::string status_str = ASSERT_NO_ERRNO_AND_VALUE(
+      GetContents(absl::StrCat("/proc/", tid, "/status")));
+  EXPECT_THAT(ParseProcStatus(status_str),
+              IsPosixErrorOkAndHolds(
+                  Contains(Pair("Cpus_allowed_list", absl::StrCat(cpu)))));
+
+  ASSERT_THAT(sched_setaffinity(tid, sizeof(orig_set), &orig_set),
+              SyscallSucceeds());
+}
+
 TEST(ProcPidStatusTest, ValuesAreTabDelimited) {
   std::string status_str =
       ASSERT_NO_ERRNO_AND_VALUE(GetContents("/proc/self/status"));
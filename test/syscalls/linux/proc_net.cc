@@ -23,6 +23,7 @@
 #include <vector>
 
 #include "gtest/gtest.h"
+#include "absl/strings/match.h"
 #include "absl/strings/numbers.h"
 #include "absl/strings/str_cat.h"
 #include "absl/strings/str_split.h"
@@ -541,6 +542,74 @@ TEST(ProcNetSnmp, CheckSnmp) {
   EXPECT_EQ(value_count, 1);
 }
 
+TEST(ProcNetSnmp6, Format) {
+  SKIP_IF(IsRunningWithHostinet());
+
+  std::string contents =
+      ASSERT_NO_ERRNO_AND_VALUE(GetContents("/proc/net/snmp6"));
+
+  bool found = false;
+  for (absl::string_view line : absl::StrSplit(contents, '\n')) {
+    if (line.empty()) {
+      continue;
+    }
+    std::vector<absl::string_view> fields =
+        absl::StrSplit(line, absl::ByAnyChar("\t "), absl::SkipWhitespace());
+    ASSERT_EQ(fields.size(), 2) << "malformed line: '" << line << "'";
+    uint64_t val;
+    EXPECT_TRUE(absl::SimpleAtoi(fields[1], &val)) << line;
+    if (fields[0] == "Ip6InReceives") {
+      found = true;
+    }
+  }
+  EXPECT_TRUE(found);
+}
+
+// GetSockstatValue returns the value following key on the line of
+// /proc/net/sockstat that starts with prefix.
+PosixErrorOr<int> GetSockstatValue(const std::string& contents,
+                                   absl::string_view prefix,
+                                   absl::string_view key) {
+  for (absl::string_view line : absl::StrSplit(contents, '\n')) {
+    if (!absl::StartsWith(line, absl::StrCat(prefix, ":"))) {
+      continue;
+    }
+    std::vector<absl::string_view> fields =
+        absl::StrSplit(line, ' ', absl::SkipWhitespace());
+    for (size_t i = 1; i + 1 < fields.size(); ++i) {
+      int val;
+      if (fields[i] == key && absl::SimpleAtoi(fields[i + 1], &val)) {
+        return val;
+      }
+    }
+  }
+  return PosixError(EINVAL, absl::StrCat("failed to find ", prefix, "/", key,
+                                         " in: ", contents));
+}
+
+TEST(ProcNetSockstat, UdpInUse) {
+  SKIP_IF(IsRunningWithHostinet());
+
+  std::string contents =
+      ASSERT_NO_ERRNO_AND_VALUE(GetContents("/proc/net/sockstat"));
+  int before = ASSERT_NO_ERRNO_AND_VALUE(
+      GetSockstatValue(contents, "UDP", "inuse"));
+
+  FileDescriptor sock =
+      ASSERT_NO_ERRNO_AND_VALUE(Socket(AF_INET, SOCK_DGRAM, 0));
+  struct sockaddr_in addr = {};
+  addr.sin_family = AF_INET;
+  addr.sin_addr.s_addr = htonl(INADDR_LOOPBACK);
+  ASSERT_THAT(
+      bind(sock.get(), reinterpret_cast<struct sockaddr*>(&addr), sizeof(addr)),
+      SyscallSucceeds());
+
+  contents = ASSERT_NO_ERRNO_AND_VALUE(GetContents("/proc/net/sockstat"));
+  EXPECT_EQ(ASSERT_NO_ERRNO_AND_VALUE(
+                GetSockstatValue(contents, "UDP", "inuse")),
+            before + 1);
+}
+
 TEST(ProcSysNetIpv4Recovery, Exists) {
   EXPECT_THAT(open("/proc/sys/net/ipv4/tcp_recovery", O_RDONLY),
               SyscallSucceeds());
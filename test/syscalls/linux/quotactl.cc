@@ -0,0 +1,229 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+#include <errno.h>
+#include <fcntl.h>
+#include <linux/quota.h>
+#include <sys/mount.h>
+#include <sys/syscall.h>
+#include <unistd.h>
+
+#include <cstdint>
+#include <string>
+#include <vector>
+
+#include "gtest/gtest.h"
+#include "test/util/cleanup.h"
+#include "test/util/file_descriptor.h"
+#include "test/util/fs_util.h"
+#include "test/util/linux_capability_util.h"
+#include "test/util/mount_util.h"
+#include "test/util/multiprocess_util.h"
+#include "test/util/posix_error.h"
+#include "test/util/temp_path.h"
+#include "test/util/test_util.h"
+
+namespace gvisor {
+namespace testing {
+
+namespace {
+
+#ifndef QFMT_SHMEM
+#define QFMT_SHMEM 5
+#endif
+
+#ifndef SYS_quotactl_fd
+#define SYS_quotactl_fd 443
+#endif
+
+constexpr uid_t kNobody = 65534;
+
+// Quotactl calls quotactl_fd(2) on the filesystem containing path. Linux's
+// quotactl(2) requires a block device, which tmpfs does not have.
+int Quotactl(int cmd, const std::string& path, int id, void* addr) {
+  int fd = open(path.c_str(), O_RDONLY | O_DIRECTORY);
+  if (fd < 0) {
+    return fd;
+  }
+  int ret = syscall(SYS_quotactl_fd, fd, cmd, id, addr);
+  int err = errno;
+  close(fd);
+  errno = err;
+  return ret;
+}
+
+class QuotactlTest : public ::testing::Test {
+ protected:
+  void SetUp() override {
+    SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
+    dir_ = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+    auto mount =
+        Mount("", dir_.path(), "tmpfs", 0, "mode=0777,usrquota,grpquota", 0);
+    // Linux supports tmpfs quotas since 6.6.
+    SKIP_IF(!mount.ok() && mount.error().errno_value() == EINVAL);
+    mount_ = std::move(mount).ValueOrDie();
+  }
+
+  TempPath dir_;
+  Cleanup mount_;
+};
+
+TEST_F(QuotactlTest, GetFmt) {
+  uint32_t fmt = 0;
+  ASSERT_THAT(Quotactl(QCMD(Q_GETFMT, USRQUOTA), dir_.path(), 0, &fmt),
+              SyscallSucceeds());
+  EXPECT_EQ(fmt, QFMT_SHMEM);
+
+  // Project quotas were not enabled.
+  EXPECT_THAT(Quotactl(QCMD(Q_GETFMT, PRJQUOTA), dir_.path(), 0, &fmt),
+              SyscallFailsWithErrno(ESRCH));
+}
+
+TEST_F(QuotactlTest, GetInfo) {
+  struct if_dqinfo info = {};
+  ASSERT_THAT(Quotactl(QCMD(Q_GETINFO, GRPQUOTA), dir_.path(), 0, &info),
+              SyscallSucceeds());
+  EXPECT_GT(info.dqi_bgrace, 0);
+  EXPECT_GT(info.dqi_igrace, 0);
+
+  info.dqi_bgrace = 100;
+  info.dqi_valid = IIF_BGRACE;
+  ASSERT_THAT(Quotactl(QCMD(Q_SETINFO, GRPQUOTA), dir_.path(), 0, &info),
+              SyscallSucceeds());
+  info = {};
+  ASSERT_THAT(Quotactl(QCMD(Q_GETINFO, GRPQUOTA), dir_.path(), 0, &info),
+              SyscallSucceeds());
+  EXPECT_EQ(info.dqi_bgrace, 100);
+}
+
+TEST_F(QuotactlTest, UsageIsAccounted) {
+  struct if_dqblk before = {};
+  ASSERT_THAT(
+      Quotactl(QCMD(Q_GETQUOTA, USRQUOTA), dir_.path(), geteuid(), &before),
+      SyscallSucceeds());
+
+  const std::string path = JoinPath(dir_.path(), "file");
+  const FileDescriptor fd =
+      ASSERT_NO_ERRNO_AND_VALUE(Open(path, O_CREAT | O_RDWR, 0644));
+  const std::vector<char> buf(8192, 'a');
+  ASSERT_THAT(WriteFd(fd.get(), buf.data(), buf.size()),
+              SyscallSucceedsWithValue(buf.size()));
+
+  struct if_dqblk after = {};
+  ASSERT_THAT(
+      Quotactl(QCMD(Q_GETQUOTA, USRQUOTA), dir_.path(), geteuid(), &after),
+      SyscallSucceeds());
+  EXPECT_EQ(after.dqb_curinodes, before.dqb_curinodes + 1);
+  EXPECT_GE(after.dqb_curspace, before.dqb_curspace + buf.size());
+
+  // Ownership changes move usage to the new owner.
+  ASSERT_THAT(fchown(fd.get(), kNobody, -1), SyscallSucceeds());
+  struct if_dqblk nobody = {};
+  ASSERT_THAT(
+      Quotactl(QCMD(Q_GETQUOTA, USRQUOTA), dir_.path(), kNobody, &nobody),
+      SyscallSucceeds());
+  EXPECT_EQ(nobody.dqb_curinodes, 1);
+  EXPECT_GE(nobody.dqb_curspace, buf.size());
+
+  struct if_nextdqblk next = {};
+  ASSERT_THAT(Quotactl(QCMD(Q_GETNEXTQUOTA, USRQUOTA), dir_.path(),
+                       geteuid() + 1, &next),
+              SyscallSucceeds());
+  EXPECT_LE(next.dqb_id, kNobody);
+}
+
+TEST_F(QuotactlTest, HardLimitEnforced) {
+  struct if_dqblk dqb = {};
+  dqb.dqb_bhardlimit = 16;  // In units of 1KB.
+  dqb.dqb_ihardlimit = 2;
+  dqb.dqb_valid = QIF_LIMITS;
+  ASSERT_THAT(Quotactl(QCMD(Q_SETQUOTA, USRQUOTA), dir_.path(), kNobody, &dqb),
+              SyscallSucceeds());
+  dqb = {};
+  ASSERT_THAT(Quotactl(QCMD(Q_GETQUOTA, USRQUOTA), dir_.path(), kNobody, &dqb),
+              SyscallSucceeds());
+  EXPECT_EQ(dqb.dqb_bhardlimit, 16);
+  EXPECT_EQ(dqb.dqb_ihardlimit, 2);
+
+  const std::string dir = dir_.path();
+  const auto rest = [&] {
+    // Use syscall instead of glibc setuid wrapper, since we don't want to
+    // change the credentials of other threads.
+    TEST_PCHECK(syscall(SYS_setresuid, kNobody, kNobody, kNobody) == 0);
+
+    int fd = open(JoinPath(dir, "a").c_str(), O_CREAT | O_WRONLY, 0644);
+    TEST_PCHECK(fd >= 0);
+    const std::vector<char> buf(64 << 10, 'a');
+    ssize_t n = write(fd, buf.data(), buf.size());
+    // The write is either short or fails outright.
+    TEST_CHECK(n < static_cast<ssize_t>(buf.size()));
+    TEST_CHECK(write(fd, buf.data(), buf.size()) < 0 && errno == EDQUOT);
+
+    TEST_PCHECK(open(JoinPath(dir, "b").c_str(), O_CREAT | O_WRONLY, 0644) >=
+                0);
+    TEST_CHECK(open(JoinPath(dir, "c").c_str(), O_CREAT | O_WRONLY, 0644) <
+                   0 &&
+               errno == EDQUOT);
+  };
+  EXPECT_THAT(InForkedProcess(rest), IsPosixErrorOkAndHolds(0));
+}
+
+TEST_F(QuotactlTest, GetQuotaRequiresPrivilegeForOtherIDs) {
+  AutoCapability cap(CAP_SYS_ADMIN, false);
+  struct if_dqblk dqb = {};
+  EXPECT_THAT(
+      Quotactl(QCMD(Q_GETQUOTA, USRQUOTA), dir_.path(), geteuid(), &dqb),
+      SyscallSucceeds());
+  EXPECT_THAT(
+      Quotactl(QCMD(Q_GETQUOTA, USRQUOTA), dir_.path(), geteuid() + 1, &dqb),
+      SyscallFailsWithErrno(EPERM));
+  EXPECT_THAT(
+      Quotactl(QCMD(Q_SETQUOTA, USRQUOTA), dir_.path(), geteuid(), &dqb),
+      SyscallFailsWithErrno(EPERM));
+}
+
+TEST_F(QuotactlTest, PathNamesMount) {
+  // gVisor has no block devices, so quotactl(2) accepts the path of any file
+  // on the filesystem instead.
+  SKIP_IF(!IsRunningOnGvisor());
+  uint32_t fmt = 0;
+  ASSERT_THAT(syscall(SYS_quotactl, QCMD(Q_GETFMT, USRQUOTA),
+                      dir_.path().c_str(), 0, &fmt),
+              SyscallSucceeds());
+  EXPECT_EQ(fmt, QFMT_SHMEM);
+}
+
+TEST(QuotactlNoQuotaTest, TmpfsWithoutQuotaOptions) {
+  // Linux returns ENOSYS for filesystems without quota support.
+  SKIP_IF(!IsRunningOnGvisor());
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_ADMIN)));
+
+  auto const dir = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+  auto const mount =
+      ASSERT_NO_ERRNO_AND_VALUE(Mount("", dir.path(), "tmpfs", 0, "", 0));
+  uint32_t fmt = 0;
+  EXPECT_THAT(Quotactl(QCMD(Q_GETFMT, USRQUOTA), dir.path(), 0, &fmt),
+              SyscallFailsWithErrno(ESRCH));
+}
+
+TEST(QuotactlNoQuotaTest, SyncAll) {
+  EXPECT_THAT(
+      syscall(SYS_quotactl, QCMD(Q_SYNC, USRQUOTA), nullptr, 0, nullptr),
+      SyscallSucceeds());
+}
+
+}  // namespace
+
+}  // namespace testing
+}  // namespace gvisor
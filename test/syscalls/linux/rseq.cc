@@ -155,6 +155,24 @@ TEST(RseqTest, CPU) {
   RunChildTest(kRseqTestCPU, 0);
 }
 
+// The NUMA node ID and concurrency ID are initialized.
+TEST(RseqTest, ExtendedFields) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(RSeqSupported()));
+  // node_id and mm_cid were added in Linux 6.3.
+  SKIP_IF(!IsRunningOnGvisor());
+
+  RunChildTest(kRseqTestExtendedFields, 0);
+}
+
+// Registration accepts areas larger than struct rseq.
+TEST(RseqTest, RegisterExtended) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(RSeqSupported()));
+  // Extended rseq areas were added in Linux 6.3.
+  SKIP_IF(!IsRunningOnGvisor());
+
+  RunChildTest(kRseqTestRegisterExtended, 0);
+}
+
 // Critical section is eventually aborted.
 TEST(RseqTest, Abort) {
   SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(RSeqSupported()));
@@ -215,6 +215,56 @@ int TestCPU() {
   return 0;
 }
 
+// The NUMA node ID and concurrency ID are initialized.
+int TestExtendedFields() {
+  struct rseq r = {};
+  r.node_id = 0xffffffff;
+  r.mm_cid = 0xffffffff;
+
+  auto reg = RSeqRegister(&r, sizeof(r), 0, 0);
+  if (reg.errno() != 0) {
+    return 1;
+  }
+
+  if (__atomic_load_n(&r.node_id, __ATOMIC_RELAXED) == 0xffffffff) {
+    return 1;
+  }
+  if (__atomic_load_n(&r.mm_cid, __ATOMIC_RELAXED) == 0xffffffff) {
+    return 1;
+  }
+
+  return 0;
+}
+
+// Registration accepts areas larger than struct rseq, and unregistration must
+// use the same length.
+int TestRegisterExtended() {
+  struct rseq_extended e = {};
+
+  int ret = sys_rseq(&e.r, sizeof(e), 0, 0);
+  if (sys_errno(ret) != 0) {
+    return 1;
+  }
+
+  // Re-registration with a different length is invalid.
+  ret = sys_rseq(&e.r, sizeof(e.r), 0, 0);
+  if (sys_errno(ret) != EINVAL) {
+    return 1;
+  }
+
+  ret = sys_rseq(&e.r, sizeof(e.r), kRseqFlagUnregister, 0);
+  if (sys_errno(ret) != EINVAL) {
+    return 1;
+  }
+
+  ret = sys_rseq(&e.r, sizeof(e), kRseqFlagUnregister, 0);
+  if (sys_errno(ret) != 0) {
+    return 1;
+  }
+
+  return 0;
+}
+
 // Critical section is eventually aborted.
 int TestAbort() {
   struct rseq r = {};
@@ -467,6 +517,12 @@ extern "C" int main(int argc, char** argv, char** envp) {
   if (strcmp(argv[1], kRseqTestCPU) == 0) {
     return TestCPU();
   }
+  if (strcmp(argv[1], kRseqTestExtendedFields) == 0) {
+    return TestExtendedFields();
+  }
+  if (strcmp(argv[1], kRseqTestRegisterExtended) == 0) {
+    return TestRegisterExtended();
+  }
   if (strcmp(argv[1], kRseqTestAbort) == 0) {
     return TestAbort();
   }
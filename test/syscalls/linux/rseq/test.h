@@ -28,6 +28,8 @@ constexpr char kRseqTestUnregisterDifferentPtr[] = "unregister-different-ptr";
 constexpr char kRseqTestUnregisterDifferentSignature[] =
     "unregister-different-signature";
 constexpr char kRseqTestCPU[] = "cpu";
+constexpr char kRseqTestExtendedFields[] = "extended-fields";
+constexpr char kRseqTestRegisterExtended[] = "register-extended";
 constexpr char kRseqTestAbort[] = "abort";
 constexpr char kRseqTestAbortBefore[] = "abort-before";
 constexpr char kRseqTestAbortSignature[] = "abort-signature";
@@ -42,6 +42,15 @@ struct rseq {
   uint32_t cpu_id;
   struct rseq_cs* rseq_cs;
   uint32_t flags;
+  uint32_t node_id;
+  uint32_t mm_cid;
+} __attribute__((aligned(4 * sizeof(uint64_t))));
+
+// An rseq area larger than struct rseq, as registered by userspace that
+// reserves space for fields beyond AT_RSEQ_FEATURE_SIZE.
+struct rseq_extended {
+  struct rseq r;
+  char reserved[32];
 } __attribute__((aligned(4 * sizeof(uint64_t))));
 
 constexpr int kRseqFlagUnregister = 1 << 0;
@@ -15,6 +15,8 @@
 // Subset of socket tests that need Linux-specific headers (compared to POSIX
 // headers).
 
+#include <netinet/ip.h>
+
 #include <cerrno>
 
 #include "gtest/gtest.h"
@@ -82,5 +84,52 @@ TEST(SocketTest, SetMarkNeedsNetAdminOrRaw) {
   EXPECT_EQ(get_mark, mark);
 }
 
+TEST(SocketTest, SetPriorityAboveInteractiveNeedsNetAdminOrRaw) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_NET_ADMIN)) &&
+          !ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_NET_RAW)));
+
+  FileDescriptor s =
+      ASSERT_NO_ERRNO_AND_VALUE(Socket(AF_INET, SOCK_STREAM, 0));
+
+  int priority = 7;
+  EXPECT_THAT(
+      setsockopt(s.get(), SOL_SOCKET, SO_PRIORITY, &priority, sizeof(priority)),
+      SyscallSucceeds());
+
+  AutoCapability cap_admin(CAP_NET_ADMIN, false);
+  AutoCapability cap_raw(CAP_NET_RAW, false);
+  int high_priority = 8;
+  EXPECT_THAT(setsockopt(s.get(), SOL_SOCKET, SO_PRIORITY, &high_priority,
+                         sizeof(high_priority)),
+              SyscallFailsWithErrno(EPERM));
+
+  int low_priority = 6;
+  EXPECT_THAT(setsockopt(s.get(), SOL_SOCKET, SO_PRIORITY, &low_priority,
+                         sizeof(low_priority)),
+              SyscallSucceeds());
+
+  int get_priority = 0;
+  socklen_t optlen = sizeof(get_priority);
+  EXPECT_THAT(
+      getsockopt(s.get(), SOL_SOCKET, SO_PRIORITY, &get_priority, &optlen),
+      SyscallSucceeds());
+  EXPECT_EQ(get_priority, low_priority);
+}
+
+TEST(SocketTest, SetTOSUpdatesPriority) {
+  FileDescriptor s = ASSERT_NO_ERRNO_AND_VALUE(Socket(AF_INET, SOCK_DGRAM, 0));
+
+  int tos = IPTOS_LOWDELAY;
+  ASSERT_THAT(setsockopt(s.get(), SOL_IP, IP_TOS, &tos, sizeof(tos)),
+              SyscallSucceeds());
+
+  int priority = 0;
+  socklen_t optlen = sizeof(priority);
+  EXPECT_THAT(getsockopt(s.get(), SOL_SOCKET, SO_PRIORITY, &priority, &optlen),
+              SyscallSucceeds());
+  // rt_tos2priority(IPTOS_LOWDELAY) is TC_PRIO_INTERACTIVE.
+  EXPECT_EQ(priority, 6);
+}
+
 }  // namespace testing
 }  // namespace gvisor
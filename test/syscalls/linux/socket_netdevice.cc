@@ -218,6 +218,69 @@ TEST(NetdeviceTest, EthtoolGetTSInfo) {
   ASSERT_THAT(ioctl(sock.get(), SIOCETHTOOL, &ifr), SyscallSucceeds());
 }
 
+TEST(NetdeviceTest, EthtoolGetLink) {
+  // Hostinet only supports ETHTOOL_GFEATURES.
+  SKIP_IF(IsRunningWithHostinet());
+
+  FileDescriptor sock =
+      ASSERT_NO_ERRNO_AND_VALUE(Socket(AF_INET, SOCK_DGRAM, 0));
+
+  struct ethtool_value link = {};
+  link.cmd = ETHTOOL_GLINK;
+
+  // Prepare the request.
+  struct ifreq ifr = {};
+  snprintf(ifr.ifr_name, IFNAMSIZ, "lo");
+  ifr.ifr_data = (void*)&link;
+
+  ASSERT_THAT(ioctl(sock.get(), SIOCETHTOOL, &ifr), SyscallSucceeds());
+  EXPECT_EQ(link.data, 1);
+}
+
+TEST(NetdeviceTest, EthtoolGetFeatures) {
+  FileDescriptor sock =
+      ASSERT_NO_ERRNO_AND_VALUE(Socket(AF_INET, SOCK_DGRAM, 0));
+
+  constexpr int kBlocks = 8;
+  struct {
+    struct ethtool_gfeatures hdr;
+    struct ethtool_get_features_block blocks[kBlocks];
+  } features = {};
+  features.hdr.cmd = ETHTOOL_GFEATURES;
+  features.hdr.size = kBlocks;
+
+  // Prepare the request.
+  struct ifreq ifr = {};
+  snprintf(ifr.ifr_name, IFNAMSIZ, "lo");
+  ifr.ifr_data = (void*)&features;
+
+  ASSERT_THAT(ioctl(sock.get(), SIOCETHTOOL, &ifr), SyscallSucceeds());
+  ASSERT_GT(features.hdr.size, 0);
+  ASSERT_LE(features.hdr.size, kBlocks);
+
+  // Scatter/gather (NETIF_F_SG, bit 0) is always active on loopback.
+  EXPECT_NE(features.blocks[0].active & 1, 0);
+}
+
+TEST(NetdeviceTest, EthtoolGetSettingsLoopback) {
+  SKIP_IF(IsRunningWithHostinet());
+
+  FileDescriptor sock =
+      ASSERT_NO_ERRNO_AND_VALUE(Socket(AF_INET, SOCK_DGRAM, 0));
+
+  struct ethtool_cmd settings = {};
+  settings.cmd = ETHTOOL_GSET;
+
+  // Prepare the request.
+  struct ifreq ifr = {};
+  snprintf(ifr.ifr_name, IFNAMSIZ, "lo");
+  ifr.ifr_data = (void*)&settings;
+
+  // The loopback device has no link settings.
+  EXPECT_THAT(ioctl(sock.get(), SIOCETHTOOL, &ifr),
+              SyscallFailsWithErrno(EOPNOTSUPP));
+}
+
 }  // namespace
 
 }  // namespace testing
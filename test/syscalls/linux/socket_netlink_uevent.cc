@@ -26,16 +26,16 @@
 
 // Tests for NETLINK_KOBJECT_UEVENT sockets.
 //
-// gVisor never sends any messages on these sockets, so we don't test the events
-// themselves.
+// gVisor only sends CPU hotplug events on these sockets, which can't be
+// triggered from inside the sandbox, so we don't test the events themselves.
 
 namespace gvisor {
 namespace testing {
 
 namespace {
 
-// SO_PASSCRED can be enabled. Since no messages are sent in gVisor, we don't
-// actually test receiving credentials.
+// SO_PASSCRED can be enabled. Since no messages can be triggered in gVisor, we
+// don't actually test receiving credentials.
 TEST(NetlinkUeventTest, PassCred) {
   FileDescriptor fd =
       ASSERT_NO_ERRNO_AND_VALUE(NetlinkBoundSocket(NETLINK_KOBJECT_UEVENT));
@@ -77,6 +77,19 @@ TEST(NetlinkUeventTest, AttachFilter) {
       SyscallSucceeds());
 }
 
+// Unprivileged sockets can join the kernel uevent multicast group.
+TEST(NetlinkUeventTest, JoinKernelGroup) {
+  FileDescriptor fd = ASSERT_NO_ERRNO_AND_VALUE(
+      Socket(AF_NETLINK, SOCK_RAW, NETLINK_KOBJECT_UEVENT));
+
+  struct sockaddr_nl addr = {};
+  addr.nl_family = AF_NETLINK;
+  addr.nl_groups = 1;
+  EXPECT_THAT(
+      bind(fd.get(), reinterpret_cast<struct sockaddr*>(&addr), sizeof(addr)),
+      SyscallSucceeds());
+}
+
 }  // namespace
 
 }  // namespace testing
@@ -0,0 +1,150 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+#include <errno.h>
+#include <fcntl.h>
+#include <sys/resource.h>
+#include <sys/socket.h>
+#include <sys/un.h>
+#include <unistd.h>
+
+#include <cstring>
+
+#include "gtest/gtest.h"
+#include "test/syscalls/linux/unix_domain_socket_test_util.h"
+#include "test/util/capability_util.h"
+#include "test/util/file_descriptor.h"
+#include "test/util/posix_error.h"
+#include "test/util/rlimit_util.h"
+#include "test/util/socket_util.h"
+#include "test/util/test_util.h"
+
+namespace gvisor {
+namespace testing {
+
+namespace {
+
+// kInflightLimit is the RLIMIT_NOFILE used by these tests, which bounds the
+// number of FDs a user may have in flight.
+constexpr int kInflightLimit = 64;
+
+// SendFD sends fd over sock in an SCM_RIGHTS message, returning the result of
+// sendmsg(2).
+int SendFD(int sock, int fd) {
+  char data = 'a';
+  struct iovec iov = {&data, sizeof(data)};
+  char control[CMSG_SPACE(sizeof(int))] = {};
+  struct msghdr msg = {};
+  msg.msg_iov = &iov;
+  msg.msg_iovlen = 1;
+  msg.msg_control = control;
+  msg.msg_controllen = sizeof(control);
+
+  struct cmsghdr* cmsg = CMSG_FIRSTHDR(&msg);
+  cmsg->cmsg_len = CMSG_LEN(sizeof(int));
+  cmsg->cmsg_level = SOL_SOCKET;
+  cmsg->cmsg_type = SCM_RIGHTS;
+  memcpy(CMSG_DATA(cmsg), &fd, sizeof(int));
+
+  return sendmsg(sock, &msg, MSG_DONTWAIT);
+}
+
+// RecvFD receives a single FD from sock.
+PosixErrorOr<FileDescriptor> RecvFD(int sock) {
+  char data;
+  struct iovec iov = {&data, sizeof(data)};
+  char control[CMSG_SPACE(sizeof(int))] = {};
+  struct msghdr msg = {};
+  msg.msg_iov = &iov;
+  msg.msg_iovlen = 1;
+  msg.msg_control = control;
+  msg.msg_controllen = sizeof(control);
+
+  RETURN_ERROR_IF_SYSCALL_FAIL(recvmsg(sock, &msg, MSG_DONTWAIT));
+  struct cmsghdr* cmsg = CMSG_FIRSTHDR(&msg);
+  if (cmsg == nullptr || cmsg->cmsg_type != SCM_RIGHTS) {
+    return PosixError(EINVAL, "no SCM_RIGHTS message received");
+  }
+  int fd;
+  memcpy(&fd, CMSG_DATA(cmsg), sizeof(int));
+  return FileDescriptor(fd);
+}
+
+TEST(UnixSocketInflightTest, TooManyInflightFDs) {
+  AutoCapability resource(CAP_SYS_RESOURCE, false);
+  AutoCapability admin(CAP_SYS_ADMIN, false);
+  auto rlimit = ASSERT_NO_ERRNO_AND_VALUE(
+      ScopedSetSoftRlimit(RLIMIT_NOFILE, kInflightLimit));
+
+  auto sockets =
+      ASSERT_NO_ERRNO_AND_VALUE(UnixDomainSocketPair(SOCK_STREAM).Create());
+  const FileDescriptor fd =
+      ASSERT_NO_ERRNO_AND_VALUE(Open("/dev/null", O_RDONLY));
+
+  // Linux rejects a send only once the user already has more FDs in flight
+  // than RLIMIT_NOFILE.
+  int sent = 0;
+  while (SendFD(sockets->first_fd(), fd.get()) >= 0) {
+    sent++;
+    ASSERT_LE(sent, kInflightLimit + 1);
+  }
+  EXPECT_EQ(errno, ETOOMANYREFS);
+  EXPECT_EQ(sent, kInflightLimit + 1);
+
+  // Receiving an FD takes it out of flight, which allows another send.
+  ASSERT_NO_ERRNO(RecvFD(sockets->second_fd()));
+  EXPECT_THAT(SendFD(sockets->first_fd(), fd.get()), SyscallSucceeds());
+}
+
+TEST(UnixSocketInflightTest, PrivilegedUserIgnoresLimit) {
+  SKIP_IF(!ASSERT_NO_ERRNO_AND_VALUE(HaveCapability(CAP_SYS_RESOURCE)));
+  auto rlimit = ASSERT_NO_ERRNO_AND_VALUE(
+      ScopedSetSoftRlimit(RLIMIT_NOFILE, kInflightLimit));
+
+  auto sockets =
+      ASSERT_NO_ERRNO_AND_VALUE(UnixDomainSocketPair(SOCK_STREAM).Create());
+  const FileDescriptor fd =
+      ASSERT_NO_ERRNO_AND_VALUE(Open("/dev/null", O_RDONLY));
+
+  for (int i = 0; i < 2 * kInflightLimit; i++) {
+    ASSERT_THAT(SendFD(sockets->first_fd(), fd.get()), SyscallSucceeds());
+  }
+}
+
+TEST(UnixSocketInflightTest, UnreachableCycleIsCollected) {
+  // Linux runs the garbage collector asynchronously, so the in-flight count
+  // may not drop immediately after the cycle becomes unreachable.
+  SKIP_IF(!IsRunningOnGvisor());
+
+  AutoCapability resource(CAP_SYS_RESOURCE, false);
+  AutoCapability admin(CAP_SYS_ADMIN, false);
+  auto rlimit = ASSERT_NO_ERRNO_AND_VALUE(
+      ScopedSetSoftRlimit(RLIMIT_NOFILE, kInflightLimit));
+
+  // Each iteration leaves a socket whose only reference is an SCM_RIGHTS
+  // message queued on itself. Unless such cycles are collected, the user
+  // runs out of in-flight FDs well before the loop ends.
+  for (int i = 0; i < 4 * kInflightLimit; i++) {
+    int fds[2];
+    ASSERT_THAT(socketpair(AF_UNIX, SOCK_STREAM, 0, fds), SyscallSucceeds());
+    ASSERT_THAT(SendFD(fds[1], fds[0]), SyscallSucceeds());
+    ASSERT_THAT(close(fds[0]), SyscallSucceeds());
+    ASSERT_THAT(close(fds[1]), SyscallSucceeds());
+  }
+}
+
+}  // namespace
+
+}  // namespace testing
+}  // namespace gvisor
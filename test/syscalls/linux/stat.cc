@@ -688,6 +688,14 @@ TEST(SimpleStatTest, AnonDeviceAllocatesUniqueInodesAcrossSaveRestore) {
 #define STATX_MNT_ID 0x00001000U
 #endif  // STATX_MNT_ID
 
+#ifndef STATX_DIOALIGN
+#define STATX_DIOALIGN 0x00002000U
+#endif  // STATX_DIOALIGN
+
+#ifndef STATX_MNT_ID_UNIQUE
+#define STATX_MNT_ID_UNIQUE 0x00004000U
+#endif  // STATX_MNT_ID_UNIQUE
+
 // struct kernel_statx_timestamp is a Linux statx_timestamp struct.
 struct kernel_statx_timestamp {
   int64_t tv_sec;
@@ -719,7 +727,9 @@ struct kernel_statx {
   uint32_t stx_dev_major;
   uint32_t stx_dev_minor;
   uint64_t stx_mnt_id;
-  uint64_t __spare2[14];
+  uint32_t stx_dio_mem_align;
+  uint32_t stx_dio_offset_align;
+  uint64_t __spare3[12];
 };
 
 int statx(int dirfd, const char* pathname, int flags, unsigned int mask,
@@ -891,6 +901,56 @@ TEST_F(StatTest, StatxMntIdBindMount) {
   EXPECT_NE(stx1.stx_mnt_id, stx2.stx_mnt_id);
 }
 
+TEST_F(StatTest, StatxMntIdUnique) {
+  SKIP_IF(!IsRunningOnGvisor() && statx(-1, nullptr, 0, 0, nullptr) < 0 &&
+          errno == ENOSYS);
+
+  struct kernel_statx stx;
+  ASSERT_THAT(statx(AT_FDCWD, test_file_name_.c_str(), 0,
+                    STATX_MNT_ID | STATX_MNT_ID_UNIQUE, &stx),
+              SyscallSucceeds());
+  // Older kernels don't support unique mount IDs.
+  SKIP_IF(!IsRunningOnGvisor() && !(stx.stx_mask & STATX_MNT_ID_UNIQUE));
+
+  // The unique mount ID takes precedence over the old one.
+  EXPECT_TRUE(stx.stx_mask & STATX_MNT_ID_UNIQUE);
+  EXPECT_FALSE(stx.stx_mask & STATX_MNT_ID);
+  EXPECT_NE(stx.stx_mnt_id, 0);
+
+  // Check that two files in the same mount have the same mount ID.
+  TempPath file2 = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateFile());
+  struct kernel_statx stx2;
+  ASSERT_THAT(
+      statx(AT_FDCWD, file2.path().c_str(), 0, STATX_MNT_ID_UNIQUE, &stx2),
+      SyscallSucceeds());
+  EXPECT_TRUE(stx2.stx_mask & STATX_MNT_ID_UNIQUE);
+  EXPECT_EQ(stx.stx_mnt_id, stx2.stx_mnt_id);
+}
+
+TEST_F(StatTest, StatxDioAlign) {
+  SKIP_IF(!IsRunningOnGvisor() && statx(-1, nullptr, 0, 0, nullptr) < 0 &&
+          errno == ENOSYS);
+
+  struct kernel_statx stx;
+  ASSERT_THAT(
+      statx(AT_FDCWD, test_file_name_.c_str(), 0, STATX_DIOALIGN, &stx),
+      SyscallSucceeds());
+  // Not all filesystems support direct I/O.
+  SKIP_IF(!(stx.stx_mask & STATX_DIOALIGN));
+
+  // Alignments are powers of two.
+  EXPECT_NE(stx.stx_dio_mem_align, 0);
+  EXPECT_EQ(stx.stx_dio_mem_align & (stx.stx_dio_mem_align - 1), 0);
+  EXPECT_NE(stx.stx_dio_offset_align, 0);
+  EXPECT_EQ(stx.stx_dio_offset_align & (stx.stx_dio_offset_align - 1), 0);
+
+  // Direct I/O alignment is only reported for regular files.
+  const TempPath dir = ASSERT_NO_ERRNO_AND_VALUE(TempPath::CreateDir());
+  ASSERT_THAT(statx(AT_FDCWD, dir.path().c_str(), 0, STATX_DIOALIGN, &stx),
+              SyscallSucceeds());
+  EXPECT_FALSE(stx.stx_mask & STATX_DIOALIGN);
+}
+
 }  // namespace
 
 }  // namespace testing
@@ -0,0 +1,102 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+#include <set>
+#include <string>
+#include <vector>
+
+#include "gtest/gtest.h"
+#include "absl/strings/numbers.h"
+#include "absl/strings/str_cat.h"
+#include "absl/strings/str_split.h"
+#include "absl/strings/string_view.h"
+#include "absl/strings/strip.h"
+#include "test/util/fs_util.h"
+#include "test/util/posix_error.h"
+#include "test/util/test_util.h"
+
+namespace gvisor {
+namespace testing {
+
+namespace {
+
+// ParseCPUList parses a CPU list in the format used by
+// /sys/devices/system/cpu/online, e.g. "0-3,5".
+PosixErrorOr<std::set<int>> ParseCPUList(absl::string_view list) {
+  std::set<int> cpus;
+  list = absl::StripTrailingAsciiWhitespace(list);
+  for (absl::string_view range : absl::StrSplit(list, ',', absl::SkipEmpty())) {
+    std::vector<absl::string_view> bounds = absl::StrSplit(range, '-');
+    int first, last;
+    if (bounds.size() > 2 || !absl::SimpleAtoi(bounds[0], &first)) {
+      return PosixError(EINVAL, absl::StrCat("invalid CPU list: ", list));
+    }
+    last = first;
+    if (bounds.size() == 2 && !absl::SimpleAtoi(bounds[1], &last)) {
+      return PosixError(EINVAL, absl::StrCat("invalid CPU list: ", list));
+    }
+    for (int cpu = first; cpu <= last; cpu++) {
+      cpus.insert(cpu);
+    }
+  }
+  return cpus;
+}
+
+PosixErrorOr<std::set<int>> ReadCPUList(const std::string& path) {
+  ASSIGN_OR_RETURN_ERRNO(std::string contents, GetContents(path));
+  return ParseCPUList(contents);
+}
+
+// The online and offline CPUs partition the possible CPUs.
+TEST(SysfsCPUTest, OnlineAndOfflinePartitionPossible) {
+  const std::set<int> possible = ASSERT_NO_ERRNO_AND_VALUE(
+      ReadCPUList("/sys/devices/system/cpu/possible"));
+  const std::set<int> online =
+      ASSERT_NO_ERRNO_AND_VALUE(ReadCPUList("/sys/devices/system/cpu/online"));
+  const std::set<int> offline =
+      ASSERT_NO_ERRNO_AND_VALUE(ReadCPUList("/sys/devices/system/cpu/offline"));
+
+  EXPECT_FALSE(online.empty());
+  std::set<int> all = online;
+  for (int cpu : offline) {
+    EXPECT_EQ(online.count(cpu), 0) << "CPU " << cpu << " is online and offline";
+    all.insert(cpu);
+  }
+  EXPECT_EQ(all, possible);
+}
+
+// Per-CPU online files agree with /sys/devices/system/cpu/online.
+TEST(SysfsCPUTest, PerCPUOnlineMatchesOnline) {
+  const std::set<int> possible = ASSERT_NO_ERRNO_AND_VALUE(
+      ReadCPUList("/sys/devices/system/cpu/possible"));
+  const std::set<int> online =
+      ASSERT_NO_ERRNO_AND_VALUE(ReadCPUList("/sys/devices/system/cpu/online"));
+
+  for (int cpu : possible) {
+    const std::string path =
+        absl::StrCat("/sys/devices/system/cpu/cpu", cpu, "/online");
+    // Not all CPUs can be taken offline, and those that can't have no online
+    // file.
+    if (!ASSERT_NO_ERRNO_AND_VALUE(Exists(path))) {
+      continue;
+    }
+    const std::string contents = ASSERT_NO_ERRNO_AND_VALUE(GetContents(path));
+    EXPECT_EQ(contents, online.count(cpu) ? "1\n" : "0\n") << path;
+  }
+}
+
+}  // namespace
+
+}  // namespace testing
+}  // namespace gvisor
@@ -107,6 +107,7 @@ func matchPoints(t *testing.T, msgs []test.Message) map[pb.MessageType]*checkers
 		pb.MessageType_MESSAGE_SENTRY_EXIT_NOTIFY_PARENT: {checker: checkSentryExitNotifyParent},
 		pb.MessageType_MESSAGE_SENTRY_TASK_EXIT:          {checker: checkSentryTaskExit},
 		pb.MessageType_MESSAGE_SENTRY_MMAP:               {checker: checkSentryMmap},
+		pb.MessageType_MESSAGE_SENTRY_PACKET_DROP:        {checker: checkSentryPacketDrop},
 		pb.MessageType_MESSAGE_SYSCALL_CLOSE:             {checker: checkSyscallClose},
 		pb.MessageType_MESSAGE_SYSCALL_CONNECT:           {checker: checkSyscallConnect},
 		pb.MessageType_MESSAGE_SYSCALL_EXECVE:            {checker: checkSyscallExecve},
@@ -136,6 +137,12 @@ func matchPoints(t *testing.T, msgs []test.Message) map[pb.MessageType]*checkers
 		pb.MessageType_MESSAGE_SYSCALL_CLONE:             {checker: checkSyscallClone},
 		pb.MessageType_MESSAGE_SYSCALL_MMAP:              {checker: checkSyscallMmap},
 		pb.MessageType_MESSAGE_SYSCALL_LISTEN:            {checker: checkSyscallListen},
+		pb.MessageType_MESSAGE_SYSCALL_RENAME:            {checker: checkSyscallRename},
+		pb.MessageType_MESSAGE_SYSCALL_LINK:              {checker: checkSyscallLink},
+		pb.MessageType_MESSAGE_SYSCALL_CHMOD:             {checker: checkSyscallChmod},
+		pb.MessageType_MESSAGE_SYSCALL_CHOWN:             {checker: checkSyscallChown},
+		pb.MessageType_MESSAGE_SYSCALL_MOUNT:             {checker: checkSyscallMount},
+		pb.MessageType_MESSAGE_SYSCALL_UMOUNT:            {checker: checkSyscallUmount},
 	}
 	return matchers
 }
@@ -284,6 +291,23 @@ func checkSentryMmap(msg test.Message) error {
 	return nil
 }
 
+func checkSentryPacketDrop(msg test.Message) error {
+	p := pb.PacketDropInfo{}
+	if err := proto.Unmarshal(msg.Msg, &p); err != nil {
+		return err
+	}
+	if err := checkTimeNs(p.TimeNs); err != nil {
+		return err
+	}
+	if len(p.Reason) == 0 {
+		return fmt.Errorf("empty reason: %+v", &p)
+	}
+	if len(p.Headers) == 0 {
+		return fmt.Errorf("empty headers: %+v", &p)
+	}
+	return nil
+}
+
 func checkSyscallRaw(msg test.Message) error {
 	p := pb.Syscall{}
 	if err := proto.Unmarshal(msg.Msg, &p); err != nil {
@@ -986,3 +1010,130 @@ func checkSyscallInotifyInitRmWatch(msg test.Message) error {
 	}
 	return nil
 }
+
+func checkSyscallRename(msg test.Message) error {
+	p := pb.Rename{}
+	if err := proto.Unmarshal(msg.Msg, &p); err != nil {
+		return err
+	}
+	if err := checkContextData(p.ContextData); err != nil {
+		return err
+	}
+	if p.OldFd != unix.AT_FDCWD || p.NewFd != unix.AT_FDCWD {
+		return fmt.Errorf("invalid FDs, got: %d, %d, want: %d", p.OldFd, p.NewFd, unix.AT_FDCWD)
+	}
+	if want := "rename_trace_test."; !strings.Contains(p.OldPathname, want) || !strings.Contains(p.NewPathname, want) {
+		return fmt.Errorf("wrong pathnames, got: %q, %q, want substring: %q", p.OldPathname, p.NewPathname, want)
+	}
+	if err := checkResolvedPath(p.OldResolvedPath, p.OldPathname); err != nil {
+		return err
+	}
+	return checkResolvedPath(p.NewResolvedPath, p.NewPathname)
+}
+
+func checkSyscallLink(msg test.Message) error {
+	p := pb.Link{}
+	if err := proto.Unmarshal(msg.Msg, &p); err != nil {
+		return err
+	}
+	if err := checkContextData(p.ContextData); err != nil {
+		return err
+	}
+	if p.OldFd != unix.AT_FDCWD || p.NewFd != unix.AT_FDCWD {
+		return fmt.Errorf("invalid FDs, got: %d, %d, want: %d", p.OldFd, p.NewFd, unix.AT_FDCWD)
+	}
+	if want := "link_trace_test.abc"; !strings.Contains(p.OldPathname, want) {
+		return fmt.Errorf("wrong old pathname, got: %q, want substring: %q", p.OldPathname, want)
+	}
+	if want := "link_trace_test."; !strings.Contains(p.NewPathname, want) {
+		return fmt.Errorf("wrong new pathname, got: %q, want substring: %q", p.NewPathname, want)
+	}
+	if err := checkResolvedPath(p.OldResolvedPath, p.OldPathname); err != nil {
+		return err
+	}
+	return checkResolvedPath(p.NewResolvedPath, p.NewPathname)
+}
+
+func checkSyscallChmod(msg test.Message) error {
+	p := pb.Chmod{}
+	if err := proto.Unmarshal(msg.Msg, &p); err != nil {
+		return err
+	}
+	if err := checkContextData(p.ContextData); err != nil {
+		return err
+	}
+	want := "chmod_trace_test.abc"
+	if p.Fd == unix.AT_FDCWD {
+		if !strings.Contains(p.Pathname, want) {
+			return fmt.Errorf("wrong pathname, got: %q, want substring: %q", p.Pathname, want)
+		}
+	} else if !strings.Contains(p.FdPath, want) {
+		return fmt.Errorf("wrong FD path, got: %q, want substring: %q", p.FdPath, want)
+	}
+	if p.Mode&^0777 != 0 || p.Mode&0600 != 0600 {
+		return fmt.Errorf("invalid mode: %#o", p.Mode)
+	}
+	return checkResolvedPath(p.ResolvedPath, want)
+}
+
+func checkSyscallChown(msg test.Message) error {
+	p := pb.Chown{}
+	if err := proto.Unmarshal(msg.Msg, &p); err != nil {
+		return err
+	}
+	if err := checkContextData(p.ContextData); err != nil {
+		return err
+	}
+	want := "chown_trace_test.abc"
+	if p.Fd == unix.AT_FDCWD {
+		if !strings.Contains(p.Pathname, want) {
+			return fmt.Errorf("wrong pathname, got: %q, want substring: %q", p.Pathname, want)
+		}
+	} else if !strings.Contains(p.FdPath, want) {
+		return fmt.Errorf("wrong FD path, got: %q, want substring: %q", p.FdPath, want)
+	}
+	if p.Flags&^unix.AT_SYMLINK_NOFOLLOW != 0 {
+		return fmt.Errorf("invalid flags: %#x", p.Flags)
+	}
+	return checkResolvedPath(p.ResolvedPath, want)
+}
+
+func checkSyscallMount(msg test.Message) error {
+	p := pb.Mount{}
+	if err := proto.Unmarshal(msg.Msg, &p); err != nil {
+		return err
+	}
+	if err := checkContextData(p.ContextData); err != nil {
+		return err
+	}
+	if want := "mount_trace_test.abc"; !strings.Contains(p.Target, want) {
+		return fmt.Errorf("wrong target, got: %q, want substring: %q", p.Target, want)
+	}
+	if want := "tmpfs"; p.Fstype != want {
+		return fmt.Errorf("wrong fstype, got: %q, want: %q", p.Fstype, want)
+	}
+	return checkResolvedPath(p.ResolvedTarget, p.Target)
+}
+
+func checkSyscallUmount(msg test.Message) error {
+	p := pb.Umount{}
+	if err := proto.Unmarshal(msg.Msg, &p); err != nil {
+		return err
+	}
+	if err := checkContextData(p.ContextData); err != nil {
+		return err
+	}
+	if want := "mount_trace_test.abc"; !strings.Contains(p.Target, want) {
+		return fmt.Errorf("wrong target, got: %q, want substring: %q", p.Target, want)
+	}
+	return checkResolvedPath(p.ResolvedTarget, p.Target)
+}
+
+// checkResolvedPath checks that resolved is an absolute path that ends with
+// the last component of pathname.
+func checkResolvedPath(resolved, pathname string) error {
+	if !filepath.IsAbs(resolved) || filepath.Base(resolved) != filepath.Base(pathname) {
+		return fmt.Errorf("wrong resolved path, got: %q, want absolute path ending with: %q", resolved, filepath.Base(pathname))
+	}
+	return nil
+}
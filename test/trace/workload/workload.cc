@@ -15,11 +15,13 @@
 #include <bits/types/struct_itimerspec.h>
 #include <err.h>
 #include <fcntl.h>
+#include <netinet/in.h>
 #include <sched.h>
 #include <stdlib.h>
 #include <sys/eventfd.h>
 #include <sys/inotify.h>
 #include <sys/mman.h>
+#include <sys/mount.h>
 #include <sys/resource.h>
 #include <sys/signalfd.h>
 #include <sys/socket.h>
@@ -422,6 +424,29 @@ void runBind() {
   }
 }
 
+// Sends a UDP datagram to a loopback port with no listener, which netstack
+// drops.
+void runPacketDrop() {
+  int fd = socket(AF_INET, SOCK_DGRAM, 0);
+  if (fd < 0) {
+    err(1, "socket");
+  }
+  auto sock_closer = absl::MakeCleanup([fd] { close(fd); });
+
+  struct sockaddr_in addr = {};
+  addr.sin_family = AF_INET;
+  addr.sin_addr.s_addr = htonl(INADDR_LOOPBACK);
+  // Discard protocol port, which nothing in the sandbox listens on.
+  addr.sin_port = htons(9);
+
+  char buf = 'A';
+  if (sendto(fd, &buf, sizeof(buf), 0,
+             reinterpret_cast<struct sockaddr*>(&addr),
+             sizeof(addr)) != sizeof(buf)) {
+    err(1, "sendto");
+  }
+}
+
 void runAccept() {
   auto path = absl::StrCat(std::string("\0", 1), "trace_test.abc");
 
@@ -696,6 +721,96 @@ void runInotifyRmWatch() {
   rmdir(pathname);
 }
 
+void runRename() {
+  const auto oldpath = "rename_trace_test.abc";
+  const auto newpath = "rename_trace_test.def";
+  int fd = open(oldpath, O_CREAT | O_WRONLY, 0644);
+  if (fd < 0) {
+    err(1, "open");
+  }
+  close(fd);
+  if (rename(oldpath, newpath) != 0) {
+    err(1, "rename");
+  }
+  if (renameat(AT_FDCWD, newpath, AT_FDCWD, oldpath) != 0) {
+    err(1, "renameat");
+  }
+  unlink(oldpath);
+}
+
+void runLink() {
+  const auto oldpath = "link_trace_test.abc";
+  const auto newpath = "link_trace_test.def";
+  const auto newpath2 = "link_trace_test.ghi";
+  int fd = open(oldpath, O_CREAT | O_WRONLY, 0644);
+  if (fd < 0) {
+    err(1, "open");
+  }
+  close(fd);
+  if (link(oldpath, newpath) != 0) {
+    err(1, "link");
+  }
+  if (linkat(AT_FDCWD, oldpath, AT_FDCWD, newpath2, 0) != 0) {
+    err(1, "linkat");
+  }
+  unlink(newpath2);
+  unlink(newpath);
+  unlink(oldpath);
+}
+
+void runChmod() {
+  const auto pathname = "chmod_trace_test.abc";
+  int fd = open(pathname, O_CREAT | O_WRONLY, 0644);
+  if (fd < 0) {
+    err(1, "open");
+  }
+  auto fd_closer = absl::MakeCleanup([fd] { close(fd); });
+  if (chmod(pathname, 0600) != 0) {
+    err(1, "chmod");
+  }
+  if (fchmod(fd, 0640) != 0) {
+    err(1, "fchmod");
+  }
+  if (fchmodat(AT_FDCWD, pathname, 0644, 0) != 0) {
+    err(1, "fchmodat");
+  }
+  unlink(pathname);
+}
+
+void runChown() {
+  const auto pathname = "chown_trace_test.abc";
+  int fd = open(pathname, O_CREAT | O_WRONLY, 0644);
+  if (fd < 0) {
+    err(1, "open");
+  }
+  auto fd_closer = absl::MakeCleanup([fd] { close(fd); });
+  // Changing ownership to the current IDs is always permitted.
+  if (chown(pathname, getuid(), getgid()) != 0) {
+    err(1, "chown");
+  }
+  if (fchown(fd, getuid(), getgid()) != 0) {
+    err(1, "fchown");
+  }
+  if (fchownat(AT_FDCWD, pathname, getuid(), getgid(), AT_SYMLINK_NOFOLLOW) !=
+      0) {
+    err(1, "fchownat");
+  }
+  unlink(pathname);
+}
+
+void runMount() {
+  const auto pathname = "mount_trace_test.abc";
+  static constexpr mode_t kDefaultDirMode = 0755;
+  if (mkdir(pathname, kDefaultDirMode) != 0) {
+    err(1, "mkdir");
+  }
+  // The workload may lack CAP_SYS_ADMIN, in which case both calls fail. The
+  // points are generated regardless.
+  mount("tmpfs", pathname, "tmpfs", 0, nullptr);
+  umount2(pathname, 0);
+  rmdir(pathname);
+}
+
 }  // namespace testing
 }  // namespace gvisor
 
@@ -718,6 +833,7 @@ int main(int argc, char** argv) {
   ::gvisor::testing::runEventfd();
   ::gvisor::testing::runEventfd2();
   ::gvisor::testing::runBind();
+  ::gvisor::testing::runPacketDrop();
   ::gvisor::testing::runAccept();
   ::gvisor::testing::runAccept4();
   ::gvisor::testing::runSignalfd4();
@@ -732,6 +848,11 @@ int main(int argc, char** argv) {
   ::gvisor::testing::runInotifyInit1();
   ::gvisor::testing::runInotifyAddWatch();
   ::gvisor::testing::runInotifyRmWatch();
+  ::gvisor::testing::runRename();
+  ::gvisor::testing::runLink();
+  ::gvisor::testing::runChmod();
+  ::gvisor::testing::runChown();
+  ::gvisor::testing::runMount();
 // signalfd(2), fork(2), and vfork(2) system calls are not supported in arm
 // architecture.
 #ifdef __x86_64__
@@ -193,6 +193,7 @@ MountOptionals() {
     opt.shared = 0;
     opt.master = 0;
     opt.propagate_from = 0;
+    opt.unbindable = false;
     std::vector<std::string_view> tags = absl::StrSplit(e.optional, ' ');
 
     for (std::string_view tag : tags) {
@@ -209,6 +210,10 @@ MountOptionals() {
 }
 
 PosixError ParseOptionalTag(std::string_view tag, MountOptional* opt) {
+  if (tag == "unbindable") {
+    opt->unbindable = true;
+    return PosixError(0);
+  }
   std::vector<absl::string_view> key_value =
       absl::StrSplit(absl::string_view(tag.data(), tag.size()), ':');
   if (key_value.size() != 2) return PosixError(0);
@@ -14,8 +14,8 @@
 
 // System call support for the VDSO.
 //
-// Provides fallback system call interfaces for getcpu()
-// and clock_gettime().
+// Provides fallback system call interfaces for getcpu(),
+// clock_gettime() and getrandom().
 
 #ifndef VDSO_SYSCALLS_H_
 #define VDSO_SYSCALLS_H_
@@ -54,6 +54,16 @@ static inline int sys_getcpu(unsigned* cpu, unsigned* node,
   return num;
 }
 
+static inline ssize_t sys_getrandom(void* buf, size_t len,
+                                    unsigned int flags) {
+  long num = __NR_getrandom;
+  asm volatile("syscall\n"
+               : "+a"(num)
+               : "D"(buf), "S"(len), "d"(flags)
+               : "rcx", "r11", "memory");
+  return num;
+}
+
 static inline void sys_rt_sigreturn(void) {
   asm volatile("movl $" __stringify(__NR_rt_sigreturn)", %eax \n"
                "syscall \n");
@@ -87,6 +97,21 @@ static inline int sys_clock_getres(clockid_t _clkid, struct timespec* _ts) {
   return ret;
 }
 
+static inline ssize_t sys_getrandom(void* _buf, size_t _len,
+                                    unsigned int _flags) {
+  register unsigned int flags asm("x2") = _flags;
+  register size_t len asm("x1") = _len;
+  register void* buf asm("x0") = _buf;
+  register long ret asm("x0");
+  register long nr asm("x8") = __NR_getrandom;
+
+  asm volatile("svc #0\n"
+               : "=r"(ret)
+               : "r"(buf), "r"(len), "r"(flags), "r"(nr)
+               : "memory");
+  return ret;
+}
+
 static inline void sys_rt_sigreturn(void) {
   asm volatile("mov x8, #" __stringify(__NR_rt_sigreturn)" \n"
                "svc #0 \n");
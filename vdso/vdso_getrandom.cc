@@ -0,0 +1,271 @@
+// Copyright 2026 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// getrandom() for the VDSO, compatible with the Linux vgetrandom interface.
+//
+// Each thread keeps a ChaCha20 key, seeded by the getrandom() system call, in
+// an opaque state that the application allocates as described by
+// vgetrandom_opaque_params. Output is generated from the key in batches, and
+// the key is replaced after every batch so that earlier output can't be
+// recovered from the state. The state is reseeded whenever the generation of
+// the sentry's entropy pool changes, e.g. after restore.
+
+#include "vdso/vdso_getrandom.h"
+
+#include <stddef.h>
+#include <stdint.h>
+#include <sys/mman.h>
+#include <sys/types.h>
+
+#include "vdso/barrier.h"
+#include "vdso/compiler.h"
+#include "vdso/params.h"
+#include "vdso/syscalls.h"
+
+#ifndef MAP_DROPPABLE
+#define MAP_DROPPABLE 0x08
+#endif
+
+namespace vdso {
+namespace {
+
+constexpr unsigned int kGrndNonblock = 0x1;
+constexpr unsigned int kGrndRandom = 0x2;
+constexpr unsigned int kGrndInsecure = 0x4;
+
+constexpr size_t kChaChaBlockSize = 64;
+constexpr size_t kChaChaKeySize = 32;
+
+// kBatchSize is the number of bytes of output buffered in a state.
+constexpr size_t kBatchSize = kChaChaBlockSize * 3 / 2;
+
+// kMaxRWCount is Linux's MAX_RW_COUNT for 4K pages.
+constexpr size_t kMaxRWCount = 0x7ffff000;
+
+// struct state is the per-thread state. Compare Linux's
+// include/vdso/getrandom.h:struct vgetrandom_state.
+struct state {
+  // batch_key holds buffered output in its first kBatchSize bytes, followed
+  // by the key. Both are refilled at once by two ChaCha20 blocks.
+  uint8_t batch_key[kChaChaBlockSize * 2];
+
+  // generation is the entropy pool generation that the key was seeded from.
+  uint64_t generation;
+
+  // pos is the offset of the first unused byte in the batch.
+  uint8_t pos;
+
+  // in_use is set while the state is used, so that signal handlers that
+  // call getrandom() fall back to the system call instead of reusing it.
+  bool in_use;
+};
+
+// struct opaque_params is Linux's struct vgetrandom_opaque_params.
+struct opaque_params {
+  uint32_t size_of_opaque_state;
+  uint32_t mmap_prot;
+  uint32_t mmap_flags;
+  uint32_t reserved[13];
+};
+
+inline uint8_t* state_key(struct state* s) { return s->batch_key + kBatchSize; }
+
+inline uint32_t rotl32(uint32_t v, int c) { return (v << c) | (v >> (32 - c)); }
+
+inline uint32_t load_le32(const uint8_t* p) {
+  return uint32_t(p[0]) | uint32_t(p[1]) << 8 | uint32_t(p[2]) << 16 |
+         uint32_t(p[3]) << 24;
+}
+
+inline void store_le32(uint8_t* p, uint32_t v) {
+  p[0] = v;
+  p[1] = v >> 8;
+  p[2] = v >> 16;
+  p[3] = v >> 24;
+}
+
+inline void quarter_round(uint32_t* x, int a, int b, int c, int d) {
+  x[a] += x[b];
+  x[d] = rotl32(x[d] ^ x[a], 16);
+  x[c] += x[d];
+  x[b] = rotl32(x[b] ^ x[c], 12);
+  x[a] += x[b];
+  x[d] = rotl32(x[d] ^ x[a], 8);
+  x[c] += x[d];
+  x[b] = rotl32(x[b] ^ x[c], 7);
+}
+
+// chacha20_blocks writes nblocks blocks of ChaCha20 output for key and a zero
+// nonce to dst, starting at block *counter, and advances *counter. The key is
+// read before anything is written, so dst may overlap it.
+void chacha20_blocks(uint8_t* dst, const uint8_t* key, uint64_t* counter,
+                     size_t nblocks) {
+  uint32_t input[16];
+  input[0] = 0x61707865;  // "expand 32-byte k"
+  input[1] = 0x3320646e;
+  input[2] = 0x79622d32;
+  input[3] = 0x6b206574;
+  for (int i = 0; i < 8; i++) {
+    input[4 + i] = load_le32(key + 4 * i);
+  }
+  input[14] = 0;
+  input[15] = 0;
+
+  for (; nblocks > 0; nblocks--) {
+    input[12] = uint32_t(*counter);
+    input[13] = uint32_t(*counter >> 32);
+
+    uint32_t x[16];
+    for (int i = 0; i < 16; i++) {
+      x[i] = input[i];
+    }
+    for (int i = 0; i < 10; i++) {
+      quarter_round(x, 0, 4, 8, 12);
+      quarter_round(x, 1, 5, 9, 13);
+      quarter_round(x, 2, 6, 10, 14);
+      quarter_round(x, 3, 7, 11, 15);
+      quarter_round(x, 0, 5, 10, 15);
+      quarter_round(x, 1, 6, 11, 12);
+      quarter_round(x, 2, 7, 8, 13);
+      quarter_round(x, 3, 4, 9, 14);
+    }
+    for (int i = 0; i < 16; i++) {
+      store_le32(dst + 4 * i, x[i] + input[i]);
+    }
+
+    dst += kChaChaBlockSize;
+    (*counter)++;
+  }
+}
+
+// copy_and_zero_src copies len bytes from src to dst and zeroes them in src.
+//
+// The VDSO doesn't link against libc, so the accesses are volatile to
+// prevent the compiler from replacing the loop with memcpy() and memset().
+void copy_and_zero_src(uint8_t* dst, uint8_t* src, size_t len) {
+  volatile uint8_t* vsrc = src;
+  for (size_t i = 0; i < len; i++) {
+    dst[i] = vsrc[i];
+    vsrc[i] = 0;
+  }
+}
+
+inline uint64_t read_once(const uint64_t* p) {
+  return *static_cast<const volatile uint64_t*>(p);
+}
+
+}  // namespace
+
+// GetRandom() is the VDSO implementation of getrandom(). Compare Linux's
+// lib/vdso/getrandom.c:__cvdso_getrandom_data().
+ssize_t GetRandom(void* buffer, size_t len, unsigned int flags,
+                  void* opaque_state, size_t opaque_len) {
+  struct params* params = get_params();
+  struct state* state = static_cast<struct state*>(opaque_state);
+
+  // A query for the parameters of the state allocation.
+  if (unlikely(opaque_len == ~0UL && !buffer && !len && !flags)) {
+    volatile struct opaque_params* p =
+        static_cast<struct opaque_params*>(opaque_state);
+    p->size_of_opaque_state = sizeof(struct state);
+    p->mmap_prot = PROT_READ | PROT_WRITE;
+    p->mmap_flags = MAP_DROPPABLE | MAP_ANONYMOUS;
+    for (int i = 0; i < 13; i++) {
+      p->reserved[i] = 0;
+    }
+    return 0;
+  }
+
+  // Leave anything unusual to the system call.
+  if (unlikely(opaque_len != sizeof(struct state) || !state ||
+               (flags & ~(kGrndNonblock | kGrndRandom | kGrndInsecure)))) {
+    return sys_getrandom(buffer, len, flags);
+  }
+  if (unlikely(read_once(&params->rng_generation) == 0)) {
+    return sys_getrandom(buffer, len, flags);
+  }
+  if (unlikely(!len)) {
+    return 0;
+  }
+
+  // Interrupted by a signal handler while the state is in use.
+  bool in_use = state->in_use;
+  state->in_use = true;
+  barrier();
+  if (unlikely(in_use)) {
+    return sys_getrandom(buffer, len, flags);
+  }
+
+  if (len > kMaxRWCount) {
+    len = kMaxRWCount;
+  }
+  uint8_t* const orig_buffer = static_cast<uint8_t*>(buffer);
+  const size_t orig_len = len;
+  uint8_t* out;
+  uint64_t current_generation;
+
+retry_generation:
+  out = orig_buffer;
+  len = orig_len;
+  current_generation = read_once(&params->rng_generation);
+  if (unlikely(state->generation != current_generation)) {
+    // Reseed the key from the sentry and discard the batch.
+    if (sys_getrandom(state_key(state), kChaChaKeySize, 0) !=
+        static_cast<ssize_t>(kChaChaKeySize)) {
+      state->in_use = false;
+      return sys_getrandom(buffer, orig_len, flags);
+    }
+    state->generation = current_generation;
+    state->pos = kBatchSize;
+  }
+
+  for (;;) {
+    // Use buffered output first.
+    size_t batch_len = kBatchSize - state->pos;
+    if (batch_len > len) {
+      batch_len = len;
+    }
+    copy_and_zero_src(out, state->batch_key + state->pos, batch_len);
+    state->pos += batch_len;
+    out += batch_len;
+    len -= batch_len;
+
+    if (!len) {
+      barrier();
+      // If the state was wiped by fork or the pool was reseeded while the
+      // output was generated, start over with a new key.
+      if (unlikely(read_once(&state->generation) !=
+                   read_once(&params->rng_generation))) {
+        goto retry_generation;
+      }
+      state->in_use = false;
+      return orig_len;
+    }
+
+    // Generate whole blocks directly into the output, then refill the batch
+    // and replace the key using the same counter.
+    uint64_t counter = 0;
+    size_t nblocks = len / kChaChaBlockSize;
+    if (nblocks) {
+      chacha20_blocks(out, state_key(state), &counter, nblocks);
+      out += nblocks * kChaChaBlockSize;
+      len -= nblocks * kChaChaBlockSize;
+    }
+    chacha20_blocks(state->batch_key, state_key(state), &counter,
+                    sizeof(state->batch_key) / kChaChaBlockSize);
+    state->pos = 0;
+  }
+}
+
+}  // namespace vdso